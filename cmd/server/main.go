@@ -2,14 +2,20 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"net"
 	"os"
 	"os/signal"
+	"sync/atomic"
 	"syscall"
 	"time"
 
+	"github.com/annel0/mmo-game/internal/anticheat"
 	"github.com/annel0/mmo-game/internal/api"
+	"github.com/annel0/mmo-game/internal/api/replay"
+	"github.com/annel0/mmo-game/internal/app"
 	"github.com/annel0/mmo-game/internal/auth"
 	"github.com/annel0/mmo-game/internal/config"
 	"github.com/annel0/mmo-game/internal/eventbus"
@@ -17,10 +23,16 @@ import (
 	"github.com/annel0/mmo-game/internal/network"
 	"github.com/annel0/mmo-game/internal/observability"
 	"github.com/annel0/mmo-game/internal/regional"
+	"github.com/annel0/mmo-game/internal/storage_adapter"
+	"github.com/annel0/mmo-game/internal/storage_interface"
 	"github.com/annel0/mmo-game/internal/sync"
+	"github.com/annel0/mmo-game/internal/tlsreload"
+	"github.com/annel0/mmo-game/internal/vec"
 	"github.com/annel0/mmo-game/internal/world"
 	"github.com/annel0/mmo-game/internal/world/block"
+	_ "github.com/annel0/mmo-game/internal/world/block/implementations" // Регистрация встроенных блоков
 	"github.com/annel0/mmo-game/internal/world/entity"
+	"google.golang.org/grpc"
 )
 
 func main() {
@@ -66,7 +78,13 @@ func main() {
 	natsURL := "nats://127.0.0.1:4222"
 	streamName := "EVENTS"
 	retention := 24
+	natsRequired := false
+	natsNamespace := ""
+	eventLogMinutes := 0
 	if cfg != nil {
+		natsRequired = cfg.EventBus.Required
+		eventLogMinutes = cfg.EventBus.EventLogMinutes
+		natsNamespace = cfg.EventBus.Namespace
 		if cfg.EventBus.URL != "" {
 			natsURL = cfg.EventBus.URL
 		}
@@ -81,22 +99,40 @@ func main() {
 	logging.Info("📡 Конфигурация сервера: TCP=%s, UDP=%s, REST API=%s", tcpAddr, udpAddr, restAddr)
 
 	// === ИНИЦИАЛИЗАЦИЯ EVENTBUS ===
-	bus, err := eventbus.NewJetStreamBus(natsURL, streamName, time.Duration(retention)*time.Hour)
+	bus, err := eventbus.ConnectOrFallback(natsURL, natsNamespace, streamName, time.Duration(retention)*time.Hour, natsRequired)
 	if err != nil {
 		logging.Error("❌ Не удалось инициализировать JetStreamBus: %v", err)
 		log.Fatalf("EventBus init failed: %v", err)
 	}
 
 	eventbus.Init(bus)
-	logging.Info("✅ JetStreamBus подключён %s", natsURL)
+	if _, local := bus.(*eventbus.LocalBus); local {
+		logging.Warn("⚠️ Сервер работает в режиме одиночного узла: события не покидают процесс")
+	} else {
+		logging.Info("✅ JetStreamBus подключён %s (namespace=%q)", natsURL, natsNamespace)
+	}
 
 	// Запускаем internal listener и Prometheus metrics
 	if err := eventbus.StartLoggingListener(bus); err != nil {
 		logging.Warn("Не удалось запустить LoggingListener: %v", err)
 	}
 
+	var metricsCfg config.MetricsConfig
+	if cfg != nil {
+		metricsCfg = cfg.Metrics
+	}
 	exporter := eventbus.NewMetricsExporter(bus)
-	exporter.StartHTTP(metricsAddr)
+	if !metricsCfg.DisableScrape {
+		exporter.StartHTTP(metricsAddr)
+	}
+	if metricsCfg.Push.Enabled {
+		exporter.StartPush(observability.MetricsPushConfig{
+			Endpoint:    metricsCfg.Push.Endpoint,
+			Interval:    time.Duration(metricsCfg.Push.IntervalSeconds) * time.Second,
+			ServiceName: "mmo_server",
+			Headers:     metricsCfg.Push.Headers,
+		})
+	}
 
 	// === ИНИЦИАЛИЗАЦИЯ SYNC ===
 	syncCfg := sync.SyncConfig{
@@ -105,6 +141,7 @@ func main() {
 		BatchSize:    100,
 		FlushEvery:   3 * time.Second,
 		UseGzipCompr: true,
+		Namespace:    natsNamespace,
 	}
 	if cfg != nil && cfg.Sync.RegionID != "" {
 		syncCfg.RegionID = cfg.Sync.RegionID
@@ -123,8 +160,31 @@ func main() {
 	}
 
 	// === ИНИЦИАЛИЗАЦИЯ REGIONAL NODE ===
+	// ID сущностей содержат номер региона, поэтому не пересекаются между регионами
+	var regionNumber uint16
+	if cfg != nil {
+		regionNumber = cfg.Sync.RegionNumber
+	}
+	entityIDs := entity.NewEntityIDAllocator(regionNumber)
+
 	// Создаём локальный мир для регионального узла
 	localWorld := world.NewWorldManager(time.Now().Unix()) // Используем timestamp как seed
+	localWorld.SetEntityIDAllocator(entityIDs)
+
+	// Мир и область региона кодируются в subject событий: узел получает из
+	// JetStream только события блоков своей области
+	var syncSettings config.SyncConfig
+	if cfg != nil {
+		syncSettings = cfg.Sync
+	}
+	worldID := syncSettings.GetWorldID()
+	var regionArea *eventbus.Bounds
+	for _, r := range syncSettings.Regions {
+		if r.ID == syncCfg.RegionID {
+			regionArea = &eventbus.Bounds{MinX: r.MinX, MinY: r.MinY, MaxX: r.MaxX, MaxY: r.MaxY}
+		}
+	}
+	localWorld.SetWorldID(worldID)
 
 	// Получаем BatchManager из SyncManager
 	var batchManager *sync.BatchManager
@@ -134,6 +194,10 @@ func main() {
 		batchManager = sync.NewBatchManager(bus, syncCfg.RegionID, syncCfg.BatchSize, syncCfg.FlushEvery, nil)
 	}
 
+	// REST API создаётся позже: обработчики событий, зарегистрированные до него,
+	// отправляют webhook через менеджер, опубликованный атомарно после создания API
+	var webhooks atomic.Pointer[api.OutboundWebhookManager]
+
 	// Конфигурация регионального узла
 	regionalCfg := regional.NodeConfig{
 		RegionID:     syncCfg.RegionID,
@@ -141,6 +205,45 @@ func main() {
 		EventBus:     bus,
 		BatchManager: batchManager,
 		Resolver:     nil, // Будет использован LWWResolver по умолчанию
+		Namespace:    natsNamespace,
+		WorldID:      worldID,
+		Area:         regionArea,
+
+		OnIntegrityMismatch: func(report regional.IntegrityReport) {
+			hooks := webhooks.Load()
+			if hooks == nil {
+				return
+			}
+			hooks.SendEvent("world.integrity_mismatch", map[string]interface{}{
+				"region_id":       report.RegionID,
+				"world_hash":      report.WorldHash,
+				"event_hash":      report.EventHash,
+				"diverged_chunks": report.DivergedChunks,
+				"checked_chunks":  report.CheckedChunks,
+			})
+		},
+		OnReplicationLag: func(alert regional.LagAlert) {
+			hooks := webhooks.Load()
+			if hooks == nil {
+				return
+			}
+			event := "region.recovered"
+			if alert.Lagging {
+				event = "region.lagging"
+			}
+			hooks.SendEvent(event, map[string]interface{}{
+				"region_id":      alert.RegionID,
+				"average_lag_ms": alert.AverageLag.Milliseconds(),
+				"threshold_ms":   alert.Threshold.Milliseconds(),
+				"window_ms":      alert.Window.Milliseconds(),
+				"since":          alert.Since,
+				"stale_regions":  alert.StaleRegions,
+			})
+		},
+	}
+	if cfg != nil {
+		regionalCfg.LagThreshold = time.Duration(cfg.Sync.LagThresholdSeconds) * time.Second
+		regionalCfg.LagWindow = time.Duration(cfg.Sync.LagWindowSeconds) * time.Second
 	}
 
 	// Создаём региональный узел
@@ -161,13 +264,55 @@ func main() {
 	// Создаем менеджер сущностей
 	logging.Debug("Создание менеджера сущностей...")
 	entityManager := entity.NewEntityManager()
-	entityManager.RegisterDefaultBehaviors()
+
+	// Параметры мобов из JSON (если каталог существует), иначе - значения из кода
+	if err := entityManager.RegisterBehaviorsFromConfig("assets/entities"); err != nil {
+		if !os.IsNotExist(err) {
+			logging.Error("Ошибка загрузки параметров сущностей, используются значения по умолчанию: %v", err)
+		}
+		entityManager.RegisterDefaultBehaviors()
+	}
 
 	// Загружаем JSON-описания блоков (если каталог существует)
 	if err := block.LoadJSONBlocks("assets/blocks"); err != nil && !os.IsNotExist(err) {
 		logging.Error("Ошибка загрузки JSON-блоков: %v", err)
 	}
 
+	// Проверка учётных данных: общая для входа в игру и в REST API
+	var authCfg config.AuthConfig
+	if cfg != nil {
+		authCfg = cfg.Auth
+	}
+	authProvider, err := auth.NewAuthProvider(authCfg.Provider, auth.OAuthConfig{
+		IntrospectionURL: authCfg.OAuth.IntrospectionURL,
+		ClientID:         authCfg.OAuth.ClientID,
+		ClientSecret:     authCfg.OAuth.ClientSecret,
+		CreateUsers:      authCfg.OAuth.CreateUsers,
+		Timeout:          time.Duration(authCfg.OAuth.TimeoutMs) * time.Millisecond,
+	})
+	if err != nil {
+		log.Fatalf("❌ Неверная конфигурация auth: %v", err)
+	}
+
+	// Подбор пароля к учётной записи блокирует её и на вход в игру, и в REST API
+	authProvider = auth.NewLockoutProvider(authProvider, auth.NewAccountLockout(auth.LockoutConfig{
+		Threshold: authCfg.LockoutThreshold,
+		Window:    time.Duration(authCfg.LockoutWindowSeconds) * time.Second,
+		OnLockout: func(event auth.LockoutEvent) {
+			logging.Warn("🔒 Учётная запись %s заблокирована до %s после %d неудачных попыток входа",
+				event.Username, event.Until.Format(time.RFC3339), event.Failures)
+			hooks := webhooks.Load()
+			if hooks == nil {
+				return
+			}
+			hooks.SendEvent("anticheat.bruteforce", map[string]interface{}{
+				"username":     event.Username,
+				"failures":     event.Failures,
+				"locked_until": event.Until,
+			})
+		},
+	}))
+
 	// Конфигурация REST API с поддержкой MariaDB
 	apiConfig := api.IntegrationConfig{
 		RestPort: restAddr,
@@ -180,6 +325,7 @@ func main() {
 		},
 		EntityManager: entityManager,
 		UseMariaDB:    false, // Установите true для использования MariaDB
+		AuthProvider:  authProvider,
 
 		// Конфигурация хранилища позиций игроков
 		PositionStorage: api.PositionStorageConfig{
@@ -188,6 +334,25 @@ func main() {
 			FallbackToMemory: true, // Fallback к памяти, если MariaDB недоступна
 		},
 	}
+	if cfg != nil {
+		cors := cfg.CORS
+		apiConfig.CORS = api.CORSPolicy{
+			AllowedOrigins:   cors.AllowedOrigins,
+			AllowedMethods:   cors.AllowedMethods,
+			AllowedHeaders:   cors.AllowedHeaders,
+			AllowCredentials: cors.AllowCredentials,
+			MaxAge:           time.Duration(cors.MaxAgeSeconds) * time.Second,
+		}
+		apiConfig.AdminCORS = apiConfig.CORS
+		apiConfig.AdminCORS.AllowedOrigins = cors.AdminAllowedOrigins
+
+		apiConfig.TLS = tlsreload.Config{
+			CertFile:     cfg.TLS.CertFile,
+			KeyFile:      cfg.TLS.KeyFile,
+			ClientCAFile: cfg.TLS.ClientCAFile,
+			Required:     cfg.TLS.Required,
+		}
+	}
 
 	// Создаем интеграцию REST API
 	logging.Debug("Создание REST API интеграции...")
@@ -196,6 +361,77 @@ func main() {
 		logging.Error("❌ Ошибка создания REST API интеграции: %v", err)
 		log.Fatalf("❌ Ошибка создания REST API интеграции: %v", err)
 	}
+	webhooks.Store(apiIntegration.GetOutboundWebhooks())
+
+	// Журнал событий /api/admin/events: узел хранит события шины в памяти
+	var (
+		replayGRPC  *grpc.Server
+		replayCerts *tlsreload.Reloader // nil - gRPC без TLS
+	)
+	if eventLogMinutes >= 0 {
+		eventLogRetention := api.DefaultEventLogRetention
+		if eventLogMinutes > 0 {
+			eventLogRetention = time.Duration(eventLogMinutes) * time.Minute
+		}
+		eventLog := replay.NewMemoryEventStore(eventLogRetention)
+		if _, err := eventLog.IngestFromBus(context.Background(), bus, syncCfg.RegionID); err != nil {
+			logging.Warn("⚠️ Журнал событий недоступен: %v", err)
+		} else {
+			replayService := replay.NewReplayService(eventLog)
+			if jetStream, ok := bus.(*eventbus.JetStreamBus); ok {
+				// Health сервиса отражает доступность NATS, а не только журнала в памяти
+				replayService.SetHealthChecker(replay.NewJetStreamHealthChecker(jetStream))
+			}
+			apiIntegration.GetRestServer().SetEventLogSource(replayService)
+
+			// gRPC сервис воспроизведения (event-cli): только администраторы с JWT
+			if replayPort := serverCfg.GetReplayGRPCPort(); replayPort > 0 {
+				replayTLS := replay.TLSConfig{
+					CertFile:     apiConfig.TLS.CertFile,
+					KeyFile:      apiConfig.TLS.KeyFile,
+					ClientCAFile: apiConfig.TLS.ClientCAFile,
+					Required:     apiConfig.TLS.Required,
+				}
+				grpcServer, certs, err := replay.NewGRPCServer(replayService, replay.AuthConfig{}, replayTLS)
+				if err != nil {
+					log.Fatalf("❌ Ошибка создания gRPC сервиса воспроизведения: %v", err)
+				}
+				ln, err := net.Listen("tcp", fmt.Sprintf(":%d", replayPort))
+				if err != nil {
+					log.Fatalf("❌ Не удалось открыть порт gRPC сервиса воспроизведения: %v", err)
+				}
+				go func() {
+					if err := grpcServer.Serve(ln); err != nil {
+						logging.Error("❌ gRPC сервис воспроизведения остановлен: %v", err)
+					}
+				}()
+				replayGRPC, replayCerts = grpcServer, certs
+				logging.Info("📼 gRPC сервис воспроизведения событий: %s (TLS: %v)", ln.Addr(), certs != nil)
+			}
+		}
+	}
+
+	if regionalNode != nil {
+		apiIntegration.GetRestServer().SetWorldHashProvider(func() api.WorldHashInfo {
+			hash, chunks := regionalNode.WorldStateHash()
+			return api.WorldHashInfo{
+				RegionID:   syncCfg.RegionID,
+				Hash:       hash,
+				Chunks:     chunks,
+				ComputedAt: time.Now(),
+			}
+		})
+	}
+
+	// Проверка готовности: NATS (в режиме одиночного узла шина всегда доступна)
+	if jetStream, ok := bus.(*eventbus.JetStreamBus); ok {
+		apiIntegration.GetRestServer().RegisterHealthCheck("eventbus", func(ctx context.Context) error {
+			if health := jetStream.Health(); !health.Connected {
+				return errors.New(health.Error)
+			}
+			return nil
+		})
+	}
 
 	// Запускаем REST API сервер
 	logging.Debug("Запуск REST API сервера...")
@@ -204,6 +440,30 @@ func main() {
 		log.Fatalf("❌ Ошибка запуска REST API: %v", err)
 	}
 
+	restScheme := "http"
+	if apiConfig.TLS.CertFile != "" {
+		restScheme = "https"
+	}
+
+	// SIGHUP перечитывает сертификаты TLS (например, после продления)
+	hupCh := make(chan os.Signal, 1)
+	signal.Notify(hupCh, syscall.SIGHUP)
+	go func() {
+		for range hupCh {
+			if err := apiIntegration.ReloadTLS(); err != nil {
+				logging.Error("❌ Не удалось перечитать сертификаты TLS, используются прежние: %v", err)
+				continue
+			}
+			if replayCerts != nil {
+				if err := replayCerts.Reload(); err != nil {
+					logging.Error("❌ Не удалось перечитать сертификаты gRPC сервиса воспроизведения, используются прежние: %v", err)
+					continue
+				}
+			}
+			logging.Info("🔐 Сертификаты TLS перечитаны")
+		}
+	}()
+
 	// Создаем KCP игровой сервер (вместо TCP)
 	logging.Debug("Создание KCP игрового сервера...")
 	kcpAddr := fmt.Sprintf(":%d", tcpPort) // Используем тот же порт что был для TCP
@@ -213,30 +473,245 @@ func main() {
 		log.Fatalf("❌ Ошибка создания KCP игрового сервера: %v", err)
 	}
 
-	// Получаем репозиторий позиций из интеграции API
-	positionRepo := apiIntegration.GetPositionRepository()
-	logging.Info("✅ Инициализирован репозиторий позиций игроков")
-	// TODO: В будущем также интегрировать общий userRepo для игры и REST API
+	// KCP работает поверх UDP, поэтому TCP-порт с тем же номером свободен для
+	// клиентов, у которых UDP блокируется сетью
+	tcpFallback := "выключен"
+	if serverCfg.TCPFallbackPort >= 0 {
+		fallbackPort := serverCfg.TCPFallbackPort
+		if fallbackPort == 0 {
+			fallbackPort = tcpPort
+		}
+		tcpFallback = fmt.Sprintf(":%d", fallbackPort)
+		if err := gameServer.EnableTCPFallback(tcpFallback); err != nil {
+			logging.Error("❌ Ошибка создания TCP fallback: %v", err)
+			log.Fatalf("❌ Ошибка создания TCP fallback: %v", err)
+		}
+	}
+	// Отладочные клиенты TCP могут выбрать кодек JSON первым сообщением
+	gameServer.SetJSONCodecAllowed(serverCfg.AllowJSONCodec)
+
+	// Игровой сервер работает с теми же учётными записями, позициями и прогрессом,
+	// что и REST API: вход через REST и авторизация в игре дают один UserID
+	gameServer.SetPlayerStore(apiIntegration.GetPlayerStore())
+	gameServer.SetAuthProvider(authProvider)
+	logging.Info("✅ Игровой сервер подключен к общим хранилищам игроков")
 
-	// Устанавливаем репозиторий позиций в игровой сервер
-	gameServer.SetPositionRepo(positionRepo)
-	logging.Debug("Репозиторий позиций передан в игровой сервер")
+	// Игровой сервер выдаёт ID сущностей из того же аллокатора региона
+	gameServer.SetEntityIDAllocator(entityIDs)
+	gameServer.SetWorldID(worldID)
+
+	// Точка спавна и приваты хранятся вместе с миром и переживают перезапуск
+	worldMeta, err := storage_adapter.NewFileStorageAdapter(serverCfg.GetWorldMetaDir(), false)
+	if err != nil {
+		log.Fatalf("❌ Ошибка хранилища метаданных мира: %v", err)
+	}
+	if err := gameServer.SetWorldMetaStore(worldMeta); err != nil {
+		log.Fatalf("❌ Ошибка загрузки метаданных мира: %v", err)
+	}
+	if spawn := serverCfg.WorldSpawn; spawn != nil {
+		if err := gameServer.SetWorldSpawn(vec.Vec2{X: spawn.X, Y: spawn.Y}); err != nil {
+			logging.Warn("Точка спавна из конфигурации не сохранена в метаданных мира: %v", err)
+		}
+	}
+	spawn := gameServer.GetWorldSpawn()
+	logging.Info("🏠 Точка спавна мира: (%d, %d)", spawn.X, spawn.Y)
+
+	// Диапазон поддерживаемых версий протокола клиентов
+	gameServer.SetProtocolVersionRange(network.ProtocolVersionRange{
+		Min: serverCfg.MinProtocolVersion,
+		Max: serverCfg.MaxProtocolVersion,
+	})
+
+	// Отключение неактивных игроков (отрицательное значение в конфиге выключает проверку)
+	idleTimeout, idleWarning := network.DefaultIdleTimeout, network.DefaultIdleWarning
+	if serverCfg.IdleTimeoutSeconds != 0 {
+		idleTimeout = time.Duration(max(serverCfg.IdleTimeoutSeconds, 0)) * time.Second
+	}
+	if serverCfg.IdleWarningSeconds != 0 {
+		idleWarning = time.Duration(max(serverCfg.IdleWarningSeconds, 0)) * time.Second
+	}
+	gameServer.SetIdleTimeout(idleTimeout, idleWarning)
+
+	// Отсрочка сохранения позиции, чтобы частые переподключения не нагружали хранилище
+	gameServer.SetReconnectSaveGrace(time.Duration(serverCfg.ReconnectSaveGraceMs) * time.Millisecond)
+
+	// Верхняя граница дальности видимости, запрашиваемой клиентами
+	gameServer.SetMaxViewDistance(serverCfg.MaxViewDistance)
+
+	// Крупные обновления сущностей делятся на несколько ENTITY_MOVE
+	gameServer.SetEntityUpdateLimits(serverCfg.MaxEntitiesPerUpdate, serverCfg.MaxEntityUpdateMessages)
+
+	// Каждый спавн рассылается всем клиентам: всплеск мировых спавнов ограничивается
+	gameServer.SetWorldSpawnRateLimit(serverCfg.WorldSpawnRate, serverCfg.WorldSpawnBurst)
+
+	// Лимит одновременных подключений: лишние клиенты получают SERVER_MESSAGE server_full
+	gameServer.SetMaxConnections(serverCfg.MaxConnections)
+
+	// Параллельная сериализация запрошенных клиентами чанков
+	gameServer.SetChunkWorkers(serverCfg.ChunkWorkers)
+
+	// Защита от потока запросов чанков: частота и отбрасывание повторов
+	gameServer.SetChunkRequestLimits(serverCfg.ChunkRequestRate, time.Duration(serverCfg.ChunkResendWindowMs)*time.Millisecond)
+
+	// Дистанция взаимодействия с блоками
+	gameServer.SetMaxReachDistance(serverCfg.MaxReachDistance)
+
+	// Сглаживание ввода движения при неровной доставке
+	gameServer.SetMoveInputBuffer(serverCfg.MoveInputBuffer)
+
+	// Режим игры по умолчанию и разрешённые в режимах действия с блоками
+	if err := gameServer.SetDefaultGameMode(network.GameMode(serverCfg.DefaultGameMode)); err != nil {
+		log.Fatalf("❌ Неверный default_game_mode: %v", err)
+	}
+	for mode, actions := range serverCfg.GameModeActions {
+		if err := gameServer.SetGameModeActions(network.GameMode(mode), actions); err != nil {
+			log.Fatalf("❌ Неверный game_mode_actions: %v", err)
+		}
+	}
+	for mode, mine := range serverCfg.GameModeMineOnBreak {
+		if err := gameServer.SetGameModeMineOnBreak(network.GameMode(mode), mine); err != nil {
+			log.Fatalf("❌ Неверный game_mode_mine_on_break: %v", err)
+		}
+	}
+
+	// Игроки, оставшиеся без опоры под ногами
+	if err := gameServer.SetVoidRules(network.VoidRules{
+		Policy:      network.VoidPolicy(serverCfg.VoidPolicy),
+		FallDamage:  serverCfg.VoidFallDamage,
+		SpawnHealth: serverCfg.VoidSpawnHealth,
+	}); err != nil {
+		log.Fatalf("❌ Неверный void_policy: %v", err)
+	}
+
+	// Граница мира: дальше неё игроки не проходят и не строят
+	if serverCfg.WorldBorderRadius > 0 {
+		if err := gameServer.SetWorldBorder(&storage_interface.WorldBorder{
+			Center:  vec.Vec2{X: serverCfg.WorldBorderCenterX, Y: serverCfg.WorldBorderCenterY},
+			Radius:  serverCfg.WorldBorderRadius,
+			Warning: serverCfg.WorldBorderWarning,
+		}); err != nil {
+			log.Fatalf("❌ Неверная граница мира: %v", err)
+		}
+	}
+
+	// Время добычи блоков в зависимости от прочности и инструмента
+	gameServer.SetMiningRules(network.MiningRules{
+		Disabled:      serverCfg.DisableMiningTime,
+		HitInterval:   time.Duration(serverCfg.MineHitIntervalMs) * time.Millisecond,
+		ResetAfter:    time.Duration(serverCfg.MineResetMs) * time.Millisecond,
+		ToolTierBonus: serverCfg.MineToolTierBonus,
+		ToolTiers:     serverCfg.MineToolTiers,
+	})
+
+	// Предметы на земле исчезают и объединяются в стопки
+	gameServer.SetItemDropRules(network.ItemDropRules{
+		Lifetime:    time.Duration(serverCfg.ItemLifetimeSeconds) * time.Second,
+		MergeRadius: serverCfg.ItemMergeRadius,
+	})
+
+	// Античит: правила из конфигурации, нарушения уходят в webhook anticheat.violation
+	var anticheatCfg config.AnticheatConfig
+	if cfg != nil {
+		anticheatCfg = cfg.Anticheat
+	}
+	if anticheatCfg.MaxReachDistance <= 0 {
+		anticheatCfg.MaxReachDistance = serverCfg.MaxReachDistance
+	}
+	anticheatEngine := anticheat.NewEngine(anticheat.Config{
+		Disabled:            anticheatCfg.DisabledRules,
+		MaxSpeed:            anticheatCfg.MaxSpeed,
+		MaxReach:            anticheatCfg.MaxReachDistance,
+		MaxBlockEdits:       anticheatCfg.MaxBlockEdits,
+		BlockEditWindow:     time.Duration(anticheatCfg.BlockEditWindowMs) * time.Millisecond,
+		MaxTeleportDistance: anticheatCfg.MaxTeleportDistance,
+		EvidenceWindow:      time.Duration(anticheatCfg.EvidenceWindowMs) * time.Millisecond,
+		EvidenceMaxEvents:   anticheatCfg.EvidenceMaxEvents,
+	})
+	anticheatEngine.SetReporter(func(v anticheat.Violation) {
+		apiIntegration.GetOutboundWebhooks().SendEvent("anticheat.violation", v.WebhookData())
+	})
+	gameServer.SetAnticheat(anticheatEngine)
+
+	// Ограничение размера входящих сообщений и отключение за повторные нарушения
+	gameServer.SetMaxPayloadSize(serverCfg.MaxPayloadBytes)
+	if serverCfg.MaxOversizedMessages != 0 {
+		gameServer.SetMaxOversizedMessages(max(serverCfg.MaxOversizedMessages, 0))
+	}
+	if serverCfg.MaxUnknownMessages != 0 {
+		gameServer.SetMaxUnknownMessages(max(serverCfg.MaxUnknownMessages, 0))
+	}
+	gameServer.SetMoveTimestamps(!serverCfg.DisableMoveTimestamps)
+	gameServer.SetMessageTimeout(time.Duration(serverCfg.MessageTimeoutMs) * time.Millisecond)
+	gameServer.SetSlowHandlerThreshold(time.Duration(serverCfg.SlowHandlerThresholdMs) * time.Millisecond)
+
+	// Очередь событий мира: размер буфера и ожидание для изменений блоков при переполнении
+	if err := gameServer.SetWorldEventBufferSize(serverCfg.WorldEventBuffer); err != nil {
+		logging.Warn("Не удалось изменить буфер событий мира: %v", err)
+	}
+	gameServer.SetCriticalEventTimeout(time.Duration(serverCfg.CriticalEventTimeoutMs) * time.Millisecond)
+	gameServer.SetEntityCaps(serverCfg.MaxEntitiesPerBigChunk, serverCfg.MaxWorldEntities)
+	if err := gameServer.SetBigChunkWorkers(serverCfg.BigChunkWorkers); err != nil {
+		logging.Warn("Не удалось включить пул воркеров BigChunk: %v", err)
+	} else if serverCfg.BigChunkWorkers > 0 {
+		logging.Info("🧵 Симуляция BigChunk на пуле из %d воркеров", serverCfg.BigChunkWorkers)
+	}
+	gameServer.SetBigChunkTickPolicy(serverCfg.IdleBigChunkTickRate, time.Duration(serverCfg.IdleBigChunkAfterMs)*time.Millisecond)
+	gameServer.SetBlockUpdateWindow(time.Duration(serverCfg.BlockUpdateWindowMs) * time.Millisecond)
+	gameServer.SetMaxBlockMetadataBytes(serverCfg.MaxBlockMetadataBytes)
+
+	// Параметры, изменённые через /api/admin/runtime, переживают перезапуск
+	runtimeOverrides := serverCfg.GetRuntimeOverridesFile()
+	if overrides, err := network.LoadRuntimeOverrides(runtimeOverrides); err != nil {
+		logging.Warn("Не удалось прочитать переопределения параметров: %v", err)
+	} else if len(overrides) > 0 {
+		if err := gameServer.SetRuntimeParams(overrides.Apply(gameServer.RuntimeParams())); err != nil {
+			logging.Warn("Переопределения параметров из %s отклонены: %v", runtimeOverrides, err)
+		} else {
+			logging.Info("🎛️ Применены переопределения параметров из %s", runtimeOverrides)
+		}
+	}
+	apiIntegration.GetRestServer().SetRuntimeTuner(gameServer, runtimeOverrides)
+	apiIntegration.GetRestServer().SetDrainController(gameServer)
+	apiIntegration.GetRestServer().SetTeleporter(gameServer)
+	apiIntegration.GetRestServer().SetStealthController(gameServer)
+	apiIntegration.GetRestServer().SetGameModeController(gameServer)
+	apiIntegration.GetRestServer().SetRegionSnapshotter(gameServer)
+	apiIntegration.GetRestServer().SetWorldEventBroadcaster(gameServer)
+	apiIntegration.GetRestServer().SetClaimManager(gameServer)
+	apiIntegration.GetRestServer().SetSpawnManager(gameServer)
+
+	// Перенаправление игроков в регион, владеющий их позицией
+	if cfg != nil && len(cfg.Sync.Regions) > 0 {
+		routes := make([]network.RegionRoute, 0, len(cfg.Sync.Regions))
+		for _, r := range cfg.Sync.Regions {
+			routes = append(routes, network.RegionRoute{
+				ID:          r.ID,
+				GameAddress: r.GameAddress,
+				Bounds:      eventbus.Bounds{MinX: r.MinX, MinY: r.MinY, MaxX: r.MaxX, MaxY: r.MaxY},
+			})
+		}
+		if err := gameServer.SetRegionRouting(syncCfg.RegionID, routes); err != nil {
+			log.Fatalf("❌ Неверная таблица регионов: %v", err)
+		}
+		logging.Info("🔀 Маршрутизация регионов: %d регионов, текущий %s", len(routes), syncCfg.RegionID)
+	}
 
 	// Запускаем игровой сервер
 	logging.Debug("Запуск игрового сервера...")
 	gameServer.Start()
+	apiIntegration.GetRestServer().RegisterHealthCheck("world", gameServer.CheckWorld)
 
 	logging.Info("✅ Все сервисы запущены и готовы принимать соединения")
-	logging.Info("   🎮 Игровой трафик: KCP %s, UDP %s (fallback)", kcpAddr, udpAddr)
-	logging.Info("   🌐 REST API: http://localhost%s", restAddr)
+	logging.Info("   🎮 Игровой трафик: KCP %s, UDP %s (fallback), TCP %s (fallback)", kcpAddr, udpAddr, tcpFallback)
+	logging.Info("   🌐 REST API: %s://localhost%s", restScheme, restAddr)
 	logging.Info("   🔐 JWT аутентификация активирована")
-	logging.Info("   ❤️  Health check: http://localhost%s/health", restAddr)
+	logging.Info("   ❤️  Health check: %s://localhost%s/health", restScheme, restAddr)
 	logging.Debug("KCP игровой сервер полностью инициализирован и работает")
 
 	// Примеры использования REST API
 	logging.Info("💡 Примеры использования REST API:")
-	logging.Info("   curl http://localhost%s/health", restAddr)
-	logging.Info("   curl -X POST http://localhost%s/api/auth/login -H 'Content-Type: application/json' -d '{\"username\":\"admin\",\"password\":\"ChangeMe123!\"}'", restAddr)
+	logging.Info("   curl %s://localhost%s/health", restScheme, restAddr)
+	logging.Info("   curl -X POST %s://localhost%s/api/auth/login -H 'Content-Type: application/json' -d '{\"username\":\"admin\",\"password\":\"ChangeMe123!\"}'", restScheme, restAddr)
 
 	// Канал для получения сигналов ОС
 	sigCh := make(chan os.Signal, 1)
@@ -248,32 +723,43 @@ func main() {
 	sig := <-sigCh
 	logging.Info("📡 Получен сигнал %v, завершение работы...", sig)
 
-	// === GRACEFUL SHUTDOWN ===
-	logging.Debug("Остановка сервисов...")
-
-	// Останавливаем KCP игровой сервер
-	logging.Debug("Остановка KCP игрового сервера...")
-	gameServer.Stop()
-
-	// Останавливаем REST API
-	logging.Debug("Остановка REST API...")
-	if err := apiIntegration.Stop(); err != nil {
-		logging.Error("❌ Ошибка остановки REST API: %v", err)
+	if replayGRPC != nil {
+		replayGRPC.Stop()
 	}
 
-	if shutdownTel != nil {
-		_ = shutdownTel(context.Background())
+	// === GRACEFUL SHUTDOWN ===
+	// Компоненты останавливаются по этапам, чтобы EventBus закрылся только
+	// после того, как игра, мир и синхронизация отдали свои события
+	server := &app.Server{
+		Game:   gameServer,
+		API:    apiIntegration,
+		Worlds: []app.WorldSaver{gameServer, localWorld},
+		Bus:    bus,
+		Telemetry: []func(context.Context) error{
+			func(context.Context) error {
+				// При push отправляется последний пакет метрик
+				exporter.Stop()
+				return nil
+			},
+		},
+	}
+	if regionalNode != nil {
+		server.Regional = regionalNode
 	}
-
 	if syncManager != nil {
-		syncManager.Stop()
+		server.Sync = append(server.Sync, syncManager)
+	}
+	if batchManager != nil {
+		server.Sync = append(server.Sync, batchManager)
+	}
+	if shutdownTel != nil {
+		server.Telemetry = append(server.Telemetry, shutdownTel)
 	}
 
-	// Останавливаем региональный узел
-	if regionalNode != nil {
-		if err := regionalNode.Stop(); err != nil {
-			logging.Error("❌ Ошибка остановки RegionalNode: %v", err)
-		}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		logging.Error("❌ Ошибки при остановке сервера: %v", err)
 	}
 
 	logging.Info("👋 Сервер успешно остановлен")
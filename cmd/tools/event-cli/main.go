@@ -2,79 +2,224 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"log"
+	"maps"
+	"net/http"
 	"os"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
+	apireplay "github.com/annel0/mmo-game/internal/api/replay"
 	"github.com/annel0/mmo-game/internal/protocol/events"
-	"github.com/annel0/mmo-game/internal/protocol/replay"
+	replaypb "github.com/annel0/mmo-game/internal/protocol/replay"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
-// MockReplayServiceClient - заглушка для gRPC клиента
-type MockReplayServiceClient struct{}
+// ReplayServiceClient - клиент gRPC сервиса воспроизведения сервера
+type ReplayServiceClient struct {
+	addr string
+	conn *grpc.ClientConn
+	rpc  replaypb.ReplayServiceClient
 
-func NewReplayServiceClient() *MockReplayServiceClient {
-	return &MockReplayServiceClient{}
+	// REST API сервера: сводка по типам берётся из журнала событий сервера
+	// (/api/admin/events/types). Пусто - через gRPC
+	apiURL string
+	token  string
 }
 
-func (c *MockReplayServiceClient) StreamEvents(ctx context.Context, filter *replay.ReplayFilter) ([]events.Event, error) {
-	// Возвращаем тестовые события
-	return []events.Event{
-		{
-			Type:      events.EventTypeSystem,
-			Timestamp: time.Now().Unix(),
-			Data:      map[string]interface{}{"component": "server", "action": "started"},
-		},
-		{
-			Type:      events.EventTypeWorld,
-			Timestamp: time.Now().Unix(),
-			Data:      map[string]interface{}{"chunk_x": 0, "chunk_y": 0, "action": "loaded"},
-		},
-		{
-			Type:      events.EventTypeBlock,
-			Timestamp: time.Now().Unix(),
-			Data:      map[string]interface{}{"x": 10, "y": 20, "block_id": 1, "action": "placed"},
-		},
-		{
-			Type:      events.EventTypeChat,
-			Timestamp: time.Now().Unix(),
-			Data:      map[string]interface{}{"player_id": 123, "message": "Hello world!", "channel": "global"},
-		},
-	}, nil
+// NewReplayServiceClient подключается к сервису воспроизведения addr: токен
+// передаётся в метаданных каждого вызова, при useTLS соединение шифруется.
+// Недоступность сервера обнаруживается при первом вызове ("not connected")
+func NewReplayServiceClient(addr, token string, useTLS bool, tlsCfg apireplay.TLSConfig) (*ReplayServiceClient, error) {
+	dialOpts, err := apireplay.DialOptions(token, useTLS, tlsCfg)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := grpc.Dial(addr, dialOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("not connected to %s: %w", addr, err)
+	}
+	return &ReplayServiceClient{addr: addr, conn: conn, rpc: replaypb.NewReplayServiceClient(conn), token: token}, nil
+}
+
+// Close закрывает соединение
+func (c *ReplayServiceClient) Close() error {
+	return c.conn.Close()
+}
+
+// rpcError поясняет ошибку вызова: недоступный сервер - "not connected"
+func (c *ReplayServiceClient) rpcError(err error) error {
+	if status.Code(err) == codes.Unavailable {
+		return fmt.Errorf("not connected to %s: %s", c.addr, status.Convert(err).Message())
+	}
+	return err
+}
+
+// replayRequest переводит фильтр командной строки в запрос Replay
+func replayRequest(filter *replaypb.ReplayFilter) *replaypb.ReplayRequest {
+	req := &replaypb.ReplayRequest{}
+	for _, t := range filter.EventTypes {
+		req.EventTypes = append(req.EventTypes, string(t))
+	}
+	if filter.Region != "" {
+		req.RegionIds = []string{filter.Region}
+	}
+	if filter.PlayerID != 0 {
+		req.PlayerIds = []string{strconv.FormatUint(filter.PlayerID, 10)}
+	}
+	if filter.StartTime != nil {
+		req.StartTime = timestamppb.New(*filter.StartTime)
+	}
+	return req
+}
+
+// StreamEvents возвращает последние limit событий по фильтру в порядке времени
+func (c *ReplayServiceClient) StreamEvents(ctx context.Context, filter *replaypb.ReplayFilter, limit int) ([]*events.EventEnvelope, error) {
+	req := replayRequest(filter)
+	req.Limit = int32(limit)
+	req.SortOrder = replaypb.ReplayRequest_SORT_ORDER_DESC
+
+	stream, err := c.rpc.Replay(ctx, req)
+	if err != nil {
+		return nil, c.rpcError(err)
+	}
+	var result []*events.EventEnvelope
+	for {
+		envelope, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, c.rpcError(err)
+		}
+		result = append(result, envelope)
+	}
+	slices.Reverse(result)
+	return result, nil
+}
+
+// GetEventStats возвращает количество событий по фильтру с разбивкой по типам
+func (c *ReplayServiceClient) GetEventStats(ctx context.Context, filter *replaypb.ReplayFilter) (*replaypb.EventStatsResponse, error) {
+	return c.eventStats(ctx, filter, replaypb.EventStatsRequest_STATS_GROUP_BY_EVENT_TYPE)
 }
 
-func (c *MockReplayServiceClient) GetEventStats(ctx context.Context, filter *replay.ReplayFilter) (map[string]interface{}, error) {
-	return map[string]interface{}{
-		"total_events": 1234,
-		"event_types": map[string]int{
-			"system": 45,
-			"world":  567,
-			"block":  890,
-			"chat":   234,
-		},
-		"time_range": map[string]interface{}{
-			"start": time.Now().Add(-24 * time.Hour).Format(time.RFC3339),
-			"end":   time.Now().Format(time.RFC3339),
-		},
-	}, nil
+func (c *ReplayServiceClient) eventStats(ctx context.Context, filter *replaypb.ReplayFilter, groupBy apireplay.StatsGroupBy) (*replaypb.EventStatsResponse, error) {
+	query := replayRequest(filter)
+	resp, err := c.rpc.GetEventStats(ctx, &replaypb.EventStatsRequest{
+		StartTime:  query.StartTime,
+		EventTypes: query.EventTypes,
+		RegionIds:  query.RegionIds,
+		GroupBy:    groupBy,
+	})
+	if err != nil {
+		return nil, c.rpcError(err)
+	}
+	return resp, nil
 }
 
-func (c *MockReplayServiceClient) GetEventTypes(ctx context.Context) ([]string, error) {
-	return []string{"system", "world", "block", "chat"}, nil
+// GroupedEventStats возвращает статистику сервера, сгруппированную по типу,
+// региону или интервалу времени
+func (c *ReplayServiceClient) GroupedEventStats(ctx context.Context, filter *replaypb.ReplayFilter, groupBy apireplay.StatsGroupBy) ([]apireplay.StatsBucket, error) {
+	resp, err := c.eventStats(ctx, filter, groupBy)
+	if err != nil {
+		return nil, err
+	}
+	buckets := make([]apireplay.StatsBucket, len(resp.GetStats()))
+	for i, stat := range resp.GetStats() {
+		buckets[i] = apireplay.StatsBucket{
+			Key:        stat.GetGroupKey(),
+			Count:      stat.GetEventCount(),
+			EventTypes: stat.GetEventTypeCounts(),
+		}
+		if stat.GetPeriodStart() != nil {
+			buckets[i].PeriodStart = stat.GetPeriodStart().AsTime()
+			buckets[i].PeriodEnd = stat.GetPeriodEnd().AsTime()
+		}
+	}
+	return buckets, nil
+}
+
+// GetEventTypeInfo возвращает сводку по типам из каталога журнала событий
+// сервера: через REST API, если задан -api, иначе через gRPC
+func (c *ReplayServiceClient) GetEventTypeInfo(ctx context.Context) ([]apireplay.EventTypeInfo, error) {
+	if c.apiURL == "" {
+		resp, err := c.rpc.GetEventTypes(ctx, &replaypb.EventTypesRequest{})
+		if err != nil {
+			return nil, c.rpcError(err)
+		}
+		infos := make([]apireplay.EventTypeInfo, len(resp.GetEventTypes()))
+		for i, info := range resp.GetEventTypes() {
+			infos[i] = apireplay.EventTypeInfo{
+				EventType: info.GetEventType(),
+				Count:     info.GetCount(),
+				FirstSeen: info.GetFirstSeen().AsTime(),
+				LastSeen:  info.GetLastSeen().AsTime(),
+				Regions:   info.GetRegions(),
+			}
+		}
+		return infos, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(c.apiURL, "/")+"/api/admin/events/types", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		Message string                    `json:"message"`
+		Data    []apireplay.EventTypeInfo `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("%s: %w", resp.Status, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", resp.Status, body.Message)
+	}
+	return body.Data, nil
+}
+
+// Health возвращает состояние сервиса воспроизведения и бэкенда событий
+func (c *ReplayServiceClient) Health(ctx context.Context) (*replaypb.HealthResponse, error) {
+	resp, err := c.rpc.Health(ctx, &replaypb.HealthRequest{})
+	if err != nil {
+		return nil, c.rpcError(err)
+	}
+	return resp, nil
 }
 
 func main() {
 	var (
 		serverAddr = flag.String("server", "localhost:9090", "gRPC server address")
-		command    = flag.String("command", "tail", "Command to execute: tail, stats, types")
+		command    = flag.String("command", "tail", "Command to execute: tail, stats, types, health")
 		eventTypes = flag.String("types", "", "Comma-separated event types to filter")
 		region     = flag.String("region", "", "Region to filter events")
 		playerID   = flag.Uint64("player", 0, "Player ID to filter events")
 		follow     = flag.Bool("follow", false, "Follow mode (like tail -f)")
 		limit      = flag.Int("limit", 100, "Maximum number of events to show")
+		checkFirst = flag.Bool("check-health", false, "Check server and event backend health before the command")
+		token      = flag.String("token", os.Getenv("REPLAY_TOKEN"), "JWT for the replay service (default $REPLAY_TOKEN)")
+		useTLS     = flag.Bool("tls", false, "Connect over TLS")
+		tlsCA      = flag.String("tls-ca", "", "CA certificate (PEM) to verify the server, default system roots")
+		tlsCert    = flag.String("tls-cert", "", "Client certificate (PEM) when the server requires mutual TLS")
+		tlsKey     = flag.String("tls-key", "", "Client certificate key (PEM)")
+		groupBy    = flag.String("group-by", "", "Group stats by: type, region, hour, day")
+		apiURL     = flag.String("api", "", "Server REST API URL (e.g. http://localhost:8088) to read event types from its event log")
 	)
 	flag.Parse()
 
@@ -82,11 +227,21 @@ func main() {
 	fmt.Printf("Server: %s\n", *serverAddr)
 	fmt.Printf("Command: %s\n\n", *command)
 
-	// Создаем клиент (заглушка)
-	client := NewReplayServiceClient()
+	if *token == "" {
+		fmt.Printf("⚠️  No -token given, the replay service will reject the calls\n\n")
+	} else if !*useTLS {
+		fmt.Printf("⚠️  Sending the token without -tls, use only for local debugging\n\n")
+	}
+
+	client, err := NewReplayServiceClient(*serverAddr, *token, *useTLS, apireplay.TLSConfig{CAFile: *tlsCA, CertFile: *tlsCert, KeyFile: *tlsKey})
+	if err != nil {
+		log.Fatalf("Failed to configure client: %v", err)
+	}
+	defer client.Close()
+	client.apiURL = *apiURL
 
 	// Создаем фильтр
-	filter := &replay.ReplayFilter{
+	filter := &replaypb.ReplayFilter{
 		Region:   *region,
 		PlayerID: *playerID,
 	}
@@ -101,6 +256,17 @@ func main() {
 
 	ctx := context.Background()
 
+	if *checkFirst && *command != "health" {
+		health, err := client.Health(ctx)
+		if err != nil {
+			log.Fatalf("Health check failed: %v", err)
+		}
+		if !health.GetHealthy() {
+			printHealth(health)
+			log.Fatalf("Replay service is not healthy, aborting %s", *command)
+		}
+	}
+
 	switch *command {
 	case "tail":
 		err := tailEvents(ctx, client, filter, *follow, *limit)
@@ -109,7 +275,7 @@ func main() {
 		}
 
 	case "stats":
-		err := showStats(ctx, client, filter)
+		err := showStats(ctx, client, filter, *groupBy)
 		if err != nil {
 			log.Fatalf("Failed to get stats: %v", err)
 		}
@@ -120,14 +286,24 @@ func main() {
 			log.Fatalf("Failed to get event types: %v", err)
 		}
 
+	case "health":
+		health, err := client.Health(ctx)
+		if err != nil {
+			log.Fatalf("Failed to check health: %v", err)
+		}
+		printHealth(health)
+		if !health.GetHealthy() {
+			os.Exit(1)
+		}
+
 	default:
 		fmt.Printf("Unknown command: %s\n", *command)
-		fmt.Printf("Available commands: tail, stats, types\n")
+		fmt.Printf("Available commands: tail, stats, types, health\n")
 		os.Exit(1)
 	}
 }
 
-func tailEvents(ctx context.Context, client *MockReplayServiceClient, filter *replay.ReplayFilter, follow bool, limit int) error {
+func tailEvents(ctx context.Context, client *ReplayServiceClient, filter *replaypb.ReplayFilter, follow bool, limit int) error {
 	fmt.Printf("📡 Tailing events...\n")
 	if len(filter.EventTypes) > 0 {
 		fmt.Printf("Types: %v\n", filter.EventTypes)
@@ -140,135 +316,172 @@ func tailEvents(ctx context.Context, client *MockReplayServiceClient, filter *re
 	}
 	fmt.Printf("\n")
 
+	// ID событий с временем последнего выведенного: следующий запрос в режиме
+	// follow начинается с этого времени, и их нельзя вывести повторно
+	seen := make(map[string]bool)
 	for {
-		events, err := client.StreamEvents(ctx, filter)
+		envelopes, err := client.StreamEvents(ctx, filter, limit)
 		if err != nil {
 			return fmt.Errorf("failed to stream events: %w", err)
 		}
 
-		count := 0
-		for _, event := range events {
-			if count >= limit {
-				break
+		for _, envelope := range envelopes {
+			if !seen[envelope.GetEventId()] {
+				printEvent(envelope)
 			}
-
-			// Применяем фильтры
-			if len(filter.EventTypes) > 0 {
-				found := false
-				for _, t := range filter.EventTypes {
-					if event.Type == t {
-						found = true
-						break
-					}
-				}
-				if !found {
-					continue
-				}
-			}
-
-			printEvent(event)
-			count++
 		}
 
 		if !follow {
 			break
 		}
 
+		if n := len(envelopes); n > 0 {
+			since := envelopes[n-1].GetTimestamp().AsTime()
+			filter.StartTime = &since
+			seen = make(map[string]bool)
+			for _, envelope := range envelopes {
+				if envelope.GetTimestamp().AsTime().Equal(since) {
+					seen[envelope.GetEventId()] = true
+				}
+			}
+		}
+
 		time.Sleep(1 * time.Second)
 	}
 
 	return nil
 }
 
-func showStats(ctx context.Context, client *MockReplayServiceClient, filter *replay.ReplayFilter) error {
+func showStats(ctx context.Context, client *ReplayServiceClient, filter *replaypb.ReplayFilter, groupBy string) error {
 	fmt.Printf("📊 Event Statistics\n")
 	if filter.Region != "" {
 		fmt.Printf("Region: %s\n", filter.Region)
 	}
 	fmt.Printf("\n")
 
+	if groupBy != "" {
+		mode, err := apireplay.ParseStatsGroupBy(groupBy)
+		if err != nil {
+			return err
+		}
+		buckets, err := client.GroupedEventStats(ctx, filter, mode)
+		if err != nil {
+			return fmt.Errorf("failed to get grouped stats: %w", err)
+		}
+		printStatsBuckets(groupBy, buckets)
+		return nil
+	}
+
 	stats, err := client.GetEventStats(ctx, filter)
 	if err != nil {
 		return fmt.Errorf("failed to get stats: %w", err)
 	}
 
-	fmt.Printf("Total Events: %v\n", stats["total_events"])
+	fmt.Printf("Total Events: %d\n", stats.GetTotalEvents())
 	fmt.Printf("\nEvent Types:\n")
-
-	if eventTypes, ok := stats["event_types"].(map[string]int); ok {
-		for eventType, count := range eventTypes {
-			fmt.Printf("  %s: %d\n", eventType, count)
-		}
+	for _, stat := range stats.GetStats() {
+		fmt.Printf("  %s: %d\n", stat.GetGroupKey(), stat.GetEventCount())
 	}
 
-	if timeRange, ok := stats["time_range"].(map[string]interface{}); ok {
+	if stats.GetTotalEvents() > 0 {
 		fmt.Printf("\nTime Range:\n")
-		fmt.Printf("  Start: %v\n", timeRange["start"])
-		fmt.Printf("  End: %v\n", timeRange["end"])
+		fmt.Printf("  Start: %s\n", stats.GetOldestEvent().AsTime().Format(time.RFC3339))
+		fmt.Printf("  End: %s\n", stats.GetNewestEvent().AsTime().Format(time.RFC3339))
 	}
 
 	return nil
 }
 
-func showEventTypes(ctx context.Context, client *MockReplayServiceClient) error {
+func printStatsBuckets(groupBy string, buckets []apireplay.StatsBucket) {
+	var total int64
+	for _, bucket := range buckets {
+		total += bucket.Count
+	}
+	fmt.Printf("Total Events: %d\n", total)
+	fmt.Printf("\nBy %s:\n", groupBy)
+
+	for _, bucket := range buckets {
+		types := make([]string, 0, len(bucket.EventTypes))
+		for eventType := range bucket.EventTypes {
+			types = append(types, eventType)
+		}
+		sort.Strings(types)
+		details := make([]string, len(types))
+		for i, eventType := range types {
+			details[i] = fmt.Sprintf("%s=%d", eventType, bucket.EventTypes[eventType])
+		}
+		fmt.Printf("  %-25s %6d  (%s)\n", bucket.Key, bucket.Count, strings.Join(details, ", "))
+	}
+}
+
+func showEventTypes(ctx context.Context, client *ReplayServiceClient) error {
 	fmt.Printf("📋 Available Event Types\n\n")
 
-	types, err := client.GetEventTypes(ctx)
+	types, err := client.GetEventTypeInfo(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to get event types: %w", err)
 	}
 
-	for i, eventType := range types {
-		fmt.Printf("%d. %s\n", i+1, eventType)
+	for i, info := range types {
+		fmt.Printf("%d. %-12s %6d events  first %s  last %s", i+1, info.EventType, info.Count,
+			info.FirstSeen.Format(time.RFC3339), info.LastSeen.Format(time.RFC3339))
+		if len(info.Regions) > 0 {
+			fmt.Printf("  regions: %s", strings.Join(info.Regions, ", "))
+		}
+		fmt.Println()
 	}
 
 	fmt.Printf("\nUsage examples:\n")
 	fmt.Printf("  event-cli -command=tail -types=world,block\n")
 	fmt.Printf("  event-cli -command=stats -region=eu-west\n")
+	fmt.Printf("  event-cli -command=stats -group-by=hour\n")
 	fmt.Printf("  event-cli -command=tail -player=123 -follow\n")
+	fmt.Printf("  event-cli -command=health\n")
+	fmt.Printf("  event-cli -command=tail -token=$REPLAY_TOKEN -tls -tls-ca=ca.pem\n")
 
 	return nil
 }
 
-func printEvent(event events.Event) {
-	timestamp := time.Unix(event.Timestamp, 0).Format("15:04:05")
+func printHealth(health *replaypb.HealthResponse) {
+	state := "✅ healthy"
+	if !health.GetHealthy() {
+		state = "❌ unhealthy"
+	}
+	fmt.Printf("🩺 Replay service: %s\n", state)
 
-	fmt.Printf("[%s] %s: ", timestamp, event.Type)
+	backend := health.GetBackend()
+	fmt.Printf("Backend: %s (connected: %v)\n", backend.GetBackend(), backend.GetConnected())
+	if backend.GetError() != "" {
+		fmt.Printf("  Error: %s\n", backend.GetError())
+	}
+	if backend.GetStream() != "" {
+		fmt.Printf("  Stream: %s, %d messages, %d bytes\n", backend.GetStream(), backend.GetMessages(), backend.GetBytes())
+	}
+	if backend.GetRetentionSeconds() > 0 {
+		fmt.Printf("  Retention: %s\n", time.Duration(backend.GetRetentionSeconds())*time.Second)
+	}
+	if backend.GetFirstEvent() != nil {
+		fmt.Printf("  Events: %s .. %s\n", backend.GetFirstEvent().AsTime().Format(time.RFC3339), backend.GetLastEvent().AsTime().Format(time.RFC3339))
+	}
 
-	switch event.Type {
-	case events.EventTypeSystem:
-		if component, ok := event.Data["component"].(string); ok {
-			if action, ok := event.Data["action"].(string); ok {
-				fmt.Printf("Component %s %s\n", component, action)
-			}
+	if health.GetHealthy() {
+		fmt.Printf("Total Events: %d\n", health.GetTotalEvents())
+		counts := health.GetEventTypeCounts()
+		for _, eventType := range slices.Sorted(maps.Keys(counts)) {
+			fmt.Printf("  %s: %d\n", eventType, counts[eventType])
 		}
-	case events.EventTypeWorld:
-		if x, okX := event.Data["chunk_x"]; okX {
-			if y, okY := event.Data["chunk_y"]; okY {
-				if action, okA := event.Data["action"].(string); okA {
-					fmt.Printf("Chunk (%v,%v) %s\n", x, y, action)
-				}
-			}
-		}
-	case events.EventTypeBlock:
-		if x, okX := event.Data["x"]; okX {
-			if y, okY := event.Data["y"]; okY {
-				if blockID, okB := event.Data["block_id"]; okB {
-					if action, okA := event.Data["action"].(string); okA {
-						fmt.Printf("Block at (%v,%v) ID=%v %s\n", x, y, blockID, action)
-					}
-				}
-			}
-		}
-	case events.EventTypeChat:
-		if playerID, okP := event.Data["player_id"]; okP {
-			if message, okM := event.Data["message"].(string); okM {
-				if channel, okC := event.Data["channel"].(string); okC {
-					fmt.Printf("Player %v in %s: %s\n", playerID, channel, message)
-				}
-			}
-		}
-	default:
-		fmt.Printf("%v\n", event.Data)
 	}
 }
+
+func printEvent(envelope *events.EventEnvelope) {
+	timestamp := envelope.GetTimestamp().AsTime().Local().Format("15:04:05")
+
+	fmt.Printf("[%s] %s", timestamp, envelope.GetEventType())
+	if envelope.GetRegionId() != "" {
+		fmt.Printf(" region=%s", envelope.GetRegionId())
+	}
+	if envelope.GetSourceNode() != "" {
+		fmt.Printf(" source=%s", envelope.GetSourceNode())
+	}
+	fmt.Printf(" id=%s\n", envelope.GetEventId())
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+
+	"github.com/annel0/mmo-game/internal/storage"
+	"github.com/annel0/mmo-game/internal/vec"
+)
+
+// filePositionStore - позиции в JSON-файле {"<userID>": {"X":..,"Y":..,"Z":..}}.
+// Используется как выгрузка для переноса между серверами и как источник
+// или назначение без базы данных. Файл перезаписывается после каждой пачки
+type filePositionStore struct {
+	path      string
+	positions map[uint64]vec.Vec3
+}
+
+// openFilePositionStore читает файл позиций; отсутствующий файл - пустое хранилище
+func openFilePositionStore(path string) (*filePositionStore, error) {
+	store := &filePositionStore{path: path, positions: make(map[uint64]vec.Vec3)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var raw map[string]vec.Vec3
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	for key, pos := range raw {
+		userID, err := strconv.ParseUint(key, 10, 64)
+		if err != nil || userID == 0 {
+			return nil, fmt.Errorf("parse %s: invalid user id %q", path, key)
+		}
+		store.positions[userID] = pos
+	}
+	return store, nil
+}
+
+func (s *filePositionStore) ListPositions(ctx context.Context, afterUserID uint64, limit int) ([]storage.UserPosition, error) {
+	var page []storage.UserPosition
+	for userID, pos := range s.positions {
+		if userID > afterUserID {
+			page = append(page, storage.UserPosition{UserID: userID, Pos: pos})
+		}
+	}
+	slices.SortFunc(page, func(a, b storage.UserPosition) int { return cmp.Compare(a.UserID, b.UserID) })
+	if limit > 0 && len(page) > limit {
+		page = page[:limit]
+	}
+	return page, nil
+}
+
+func (s *filePositionStore) Load(ctx context.Context, userID uint64) (vec.Vec3, bool, error) {
+	pos, ok := s.positions[userID]
+	return pos, ok, nil
+}
+
+func (s *filePositionStore) BatchSave(ctx context.Context, positions map[uint64]vec.Vec3) error {
+	if len(positions) == 0 {
+		return nil
+	}
+	for userID, pos := range positions {
+		s.positions[userID] = pos
+	}
+
+	raw := make(map[string]vec.Vec3, len(s.positions))
+	for userID, pos := range s.positions {
+		raw[strconv.FormatUint(userID, 10)] = pos
+	}
+	data, err := json.MarshalIndent(raw, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Запись через временный файл: прерванный перенос не портит выгрузку
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
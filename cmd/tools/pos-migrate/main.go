@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/storage"
+)
+
+// Переносит сохранённые позиции игроков между хранилищами при смене
+// position_storage.type, например:
+//
+//	pos-migrate -from file -from-addr positions.json -to mariadb -to-addr 'user:pass@tcp(db:3306)/mmo'
+func main() {
+	var (
+		fromType = flag.String("from", "", "Source backend: file or mariadb")
+		fromAddr = flag.String("from-addr", "", "Source file path or MariaDB DSN")
+		toType   = flag.String("to", "", "Destination backend: file or mariadb")
+		toAddr   = flag.String("to-addr", "", "Destination file path or MariaDB DSN")
+		batch    = flag.Int("batch", DefaultBatchSize, "Positions read and written per batch")
+		policy   = flag.String("on-conflict", string(policySkip), "Existing destination position: skip or overwrite")
+		after    = flag.Uint64("after", 0, "Resume after this user ID (printed by an interrupted run)")
+	)
+	flag.Parse()
+
+	src, err := openBackend(*fromType, *fromAddr)
+	if err != nil {
+		log.Fatalf("Failed to open source: %v", err)
+	}
+	lister, ok := src.(storage.PositionLister)
+	if !ok {
+		log.Fatalf("Backend %q cannot list positions", *fromType)
+	}
+	dst, err := openBackend(*toType, *toAddr)
+	if err != nil {
+		log.Fatalf("Failed to open destination: %v", err)
+	}
+
+	// Ctrl+C прерывает перенос; запуск с -after продолжит с места остановки
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Printf("📦 Migrating positions %s -> %s (batch %d, on conflict: %s)\n", *fromType, *toType, *batch, *policy)
+
+	lastReport := time.Now()
+	stats, err := migrate(ctx, lister, dst, migrateOptions{
+		BatchSize:   *batch,
+		Policy:      conflictPolicy(*policy),
+		AfterUserID: *after,
+	}, func(s migrateStats) {
+		if time.Since(lastReport) >= time.Second {
+			lastReport = time.Now()
+			fmt.Printf("  %d read, %d written, %d skipped (last user %d)\n", s.Read, s.Written, s.Skipped, s.LastUserID)
+		}
+	})
+
+	fmt.Printf("Read: %d, written: %d, skipped: %d, time: %s\n",
+		stats.Read, stats.Written, stats.Skipped, stats.Duration.Round(time.Millisecond))
+
+	if errors.Is(err, context.Canceled) {
+		fmt.Printf("⚠️  Interrupted, run again with -after %d to resume\n", stats.LastUserID)
+		return
+	}
+	if err != nil {
+		log.Fatalf("Migration failed (resume with -after %d): %v", stats.LastUserID, err)
+	}
+}
+
+// openBackend открывает хранилище позиций. In-memory хранилище сервера не
+// переживает его перезапуск, поэтому переносить из него или в него нечего
+func openBackend(kind, addr string) (positionSink, error) {
+	if addr == "" {
+		return nil, fmt.Errorf("address for backend %q is required", kind)
+	}
+	switch kind {
+	case "file":
+		return openFilePositionStore(addr)
+	case "mariadb":
+		return storage.NewMariaPositionRepo(addr)
+	case "memory":
+		return nil, errors.New("memory backend keeps positions only inside the server process, nothing to migrate")
+	default:
+		return nil, fmt.Errorf("unknown backend %q (want file or mariadb)", kind)
+	}
+}
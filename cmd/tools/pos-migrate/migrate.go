@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/storage"
+	"github.com/annel0/mmo-game/internal/vec"
+)
+
+// DefaultBatchSize - позиций, читаемых и записываемых за один шаг
+const DefaultBatchSize = 500
+
+// positionSink - хранилище, в которое переносятся позиции (storage.PositionRepo
+// или файл выгрузки)
+type positionSink interface {
+	Load(ctx context.Context, userID uint64) (vec.Vec3, bool, error)
+	BatchSave(ctx context.Context, positions map[uint64]vec.Vec3) error
+}
+
+// conflictPolicy - что делать, если у игрока уже есть позиция в назначении
+type conflictPolicy string
+
+const (
+	policySkip      conflictPolicy = "skip"      // Оставить позицию назначения
+	policyOverwrite conflictPolicy = "overwrite" // Заменить позицией источника
+)
+
+// migrateOptions задаёт перенос позиций
+type migrateOptions struct {
+	BatchSize   int            // 0 - DefaultBatchSize
+	Policy      conflictPolicy // "" - policySkip
+	AfterUserID uint64         // Продолжить прерванный перенос после этого пользователя
+}
+
+// migrateStats - итоги переноса
+type migrateStats struct {
+	Read       int           // Прочитано из источника
+	Written    int           // Записано в назначение
+	Skipped    int           // Пропущено: позиция в назначении уже есть (policySkip)
+	LastUserID uint64        // Последний обработанный пользователь
+	Duration   time.Duration // Общее время работы
+}
+
+// migrate переносит все позиции из src в dst пачками по возрастанию userID.
+// С policySkip повторный запуск ничего не меняет, с policyOverwrite записывает
+// те же значения, поэтому перенос можно безопасно повторить или продолжить
+// с AfterUserID. onProgress вызывается после каждой пачки
+func migrate(ctx context.Context, src storage.PositionLister, dst positionSink, opts migrateOptions, onProgress func(migrateStats)) (migrateStats, error) {
+	start := time.Now()
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultBatchSize
+	}
+	switch opts.Policy {
+	case "":
+		opts.Policy = policySkip
+	case policySkip, policyOverwrite:
+	default:
+		return migrateStats{}, fmt.Errorf("unknown conflict policy %q (want skip or overwrite)", opts.Policy)
+	}
+
+	stats := migrateStats{LastUserID: opts.AfterUserID}
+	for {
+		if err := ctx.Err(); err != nil {
+			stats.Duration = time.Since(start)
+			return stats, err
+		}
+
+		page, err := src.ListPositions(ctx, stats.LastUserID, opts.BatchSize)
+		if err != nil {
+			stats.Duration = time.Since(start)
+			return stats, fmt.Errorf("read positions after user %d: %w", stats.LastUserID, err)
+		}
+		if len(page) == 0 {
+			break
+		}
+
+		batch := make(map[uint64]vec.Vec3, len(page))
+		for _, entry := range page {
+			if opts.Policy == policySkip {
+				_, exists, err := dst.Load(ctx, entry.UserID)
+				if err != nil {
+					stats.Duration = time.Since(start)
+					return stats, fmt.Errorf("check destination for user %d: %w", entry.UserID, err)
+				}
+				if exists {
+					stats.Skipped++
+					continue
+				}
+			}
+			batch[entry.UserID] = entry.Pos
+		}
+
+		if err := dst.BatchSave(ctx, batch); err != nil {
+			stats.Duration = time.Since(start)
+			return stats, fmt.Errorf("write %d positions after user %d: %w", len(batch), stats.LastUserID, err)
+		}
+
+		stats.Read += len(page)
+		stats.Written += len(batch)
+		stats.LastUserID = page[len(page)-1].UserID
+		if onProgress != nil {
+			onProgress(stats)
+		}
+	}
+
+	stats.Duration = time.Since(start)
+	return stats, nil
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/annel0/mmo-game/internal/storage"
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingSink - назначение переноса, запоминающее пачки записи
+type recordingSink struct {
+	positions map[uint64]vec.Vec3
+	batches   []int
+}
+
+func (s *recordingSink) Load(_ context.Context, userID uint64) (vec.Vec3, bool, error) {
+	pos, ok := s.positions[userID]
+	return pos, ok, nil
+}
+
+func (s *recordingSink) BatchSave(_ context.Context, positions map[uint64]vec.Vec3) error {
+	s.batches = append(s.batches, len(positions))
+	for userID, pos := range positions {
+		s.positions[userID] = pos
+	}
+	return nil
+}
+
+func TestMigrate_CopiesInBatchesAndIsIdempotent(t *testing.T) {
+	ctx := context.Background()
+	src := storage.NewMemoryPositionRepo()
+	for userID := uint64(1); userID <= 7; userID++ {
+		require.NoError(t, src.Save(ctx, userID, vec.Vec3{X: int(userID) * 10, Y: -int(userID), Z: 1}))
+	}
+
+	// У пользователя 3 в назначении уже есть более новая позиция
+	dst := &recordingSink{positions: map[uint64]vec.Vec3{3: {X: 999, Y: 999, Z: 2}}}
+
+	var progress []uint64
+	stats, err := migrate(ctx, src, dst, migrateOptions{BatchSize: 3}, func(s migrateStats) {
+		progress = append(progress, s.LastUserID)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 7, stats.Read)
+	assert.Equal(t, 6, stats.Written)
+	assert.Equal(t, 1, stats.Skipped)
+	assert.Equal(t, []uint64{3, 6, 7}, progress, "пачки по возрастанию userID")
+	assert.Equal(t, []int{2, 3, 1}, dst.batches)
+	assert.Equal(t, vec.Vec3{X: 999, Y: 999, Z: 2}, dst.positions[3], "skip сохраняет позицию назначения")
+	assert.Equal(t, vec.Vec3{X: 70, Y: -7, Z: 1}, dst.positions[7])
+
+	// Повторный запуск ничего не записывает
+	dst.batches = nil
+	stats, err = migrate(ctx, src, dst, migrateOptions{BatchSize: 3}, nil)
+	require.NoError(t, err)
+	assert.Zero(t, stats.Written)
+	assert.Equal(t, 7, stats.Skipped)
+
+	// overwrite заменяет позиции назначения, продолжение - только после AfterUserID
+	stats, err = migrate(ctx, src, dst, migrateOptions{BatchSize: 3, Policy: policyOverwrite, AfterUserID: 2}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 5, stats.Written)
+	assert.Equal(t, vec.Vec3{X: 30, Y: -3, Z: 1}, dst.positions[3])
+
+	_, err = migrate(ctx, src, dst, migrateOptions{Policy: "merge"}, nil)
+	assert.Error(t, err)
+}
+
+func TestFilePositionStore_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "positions.json")
+
+	src := storage.NewMemoryPositionRepo()
+	require.NoError(t, src.Save(ctx, 42, vec.Vec3{X: 5, Y: 6, Z: 1}))
+	require.NoError(t, src.Save(ctx, 7, vec.Vec3{X: -1, Y: 2, Z: 0}))
+
+	file, err := openFilePositionStore(path)
+	require.NoError(t, err)
+	_, err = migrate(ctx, src, file, migrateOptions{}, nil)
+	require.NoError(t, err)
+
+	reopened, err := openFilePositionStore(path)
+	require.NoError(t, err)
+	page, err := reopened.ListPositions(ctx, 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []storage.UserPosition{
+		{UserID: 7, Pos: vec.Vec3{X: -1, Y: 2, Z: 0}},
+		{UserID: 42, Pos: vec.Vec3{X: 5, Y: 6, Z: 1}},
+	}, page)
+}
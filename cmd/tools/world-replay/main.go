@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	apireplay "github.com/annel0/mmo-game/internal/api/replay"
+	"github.com/annel0/mmo-game/internal/protocol/events"
+)
+
+// eventSource - источник событий для восстановления (ReplayService или его мок)
+type eventSource interface {
+	StreamEvents(ctx context.Context, filter *apireplay.ReplayFilter) ([]events.Event, error)
+}
+
+// fileEventStore - EventStore поверх JSON-выгрузки событий (массив EventEnvelope)
+type fileEventStore struct {
+	envelopes []*apireplay.EventEnvelope
+}
+
+// loadFileEventStore читает выгрузку событий из файла
+func loadFileEventStore(path string) (*fileEventStore, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var envelopes []*apireplay.EventEnvelope
+	if err := json.Unmarshal(data, &envelopes); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return &fileEventStore{envelopes: envelopes}, nil
+}
+
+func (s *fileEventStore) QueryEvents(ctx context.Context, query apireplay.EventQuery) ([]*apireplay.EventEnvelope, error) {
+	types := make(map[string]bool, len(query.EventTypes))
+	for _, t := range query.EventTypes {
+		types[t] = true
+	}
+
+	var result []*apireplay.EventEnvelope
+	for _, env := range s.envelopes {
+		if len(types) > 0 && !types[env.EventType] {
+			continue
+		}
+		if query.Region != "" && env.RegionID != query.Region {
+			continue
+		}
+		if query.StartTime != nil && env.Timestamp.Before(*query.StartTime) {
+			continue
+		}
+		if query.EndTime != nil && env.Timestamp.After(*query.EndTime) {
+			continue
+		}
+		result = append(result, env)
+	}
+	return result, nil
+}
+
+func (s *fileEventStore) GetEventStats(ctx context.Context, query apireplay.EventQuery) (*apireplay.EventStats, error) {
+	stats := &apireplay.EventStats{EventTypes: make(map[string]int)}
+	for _, env := range s.envelopes {
+		stats.TotalEvents++
+		stats.EventTypes[env.EventType]++
+	}
+	return stats, nil
+}
+
+func (s *fileEventStore) GetEventTypes(ctx context.Context) ([]string, error) {
+	return []string{string(events.EventTypeBlock)}, nil
+}
+
+func main() {
+	var (
+		input   = flag.String("input", "", "JSON export of event envelopes (required unless -mock)")
+		mock    = flag.Bool("mock", false, "Replay built-in sample events instead of -input (for trying the tool out)")
+		worldID = flag.String("world", "", "World ID to reconstruct (empty = all worlds)")
+		region  = flag.String("region", "", "Region to filter events")
+		from    = flag.String("from", "", "Start of the time range (RFC3339)")
+		to      = flag.String("to", "", "End of the time range (RFC3339), state is dumped at this time")
+		seed    = flag.Int64("seed", 0, "World generator seed used as the base state")
+		diff    = flag.Bool("diff", false, "Print only blocks that differ from the base state")
+	)
+	flag.Parse()
+
+	if *input == "" && !*mock {
+		fmt.Fprintln(os.Stderr, "world-replay: -input is required (or -mock to replay built-in sample events)")
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *input != "" && *mock {
+		log.Fatalf("-input and -mock are mutually exclusive")
+	}
+
+	opts := replayOptions{WorldID: *worldID, Seed: *seed}
+	var err error
+	if opts.Start, err = parseTime(*from); err != nil {
+		log.Fatalf("Invalid -from: %v", err)
+	}
+	if opts.End, err = parseTime(*to); err != nil {
+		log.Fatalf("Invalid -to: %v", err)
+	}
+
+	var source eventSource
+	if *mock {
+		fmt.Printf("⚠️  -mock: replaying built-in SAMPLE events, not data from any server\n\n")
+		source = apireplay.NewMockReplayService()
+	} else {
+		store, err := loadFileEventStore(*input)
+		if err != nil {
+			log.Fatalf("Failed to load events: %v", err)
+		}
+		source = apireplay.NewReplayService(store)
+	}
+
+	filter := &apireplay.ReplayFilter{
+		EventTypes: []events.EventType{events.EventTypeBlock},
+		Region:     *region,
+	}
+	if !opts.Start.IsZero() {
+		filter.StartTime = &opts.Start
+	}
+	if !opts.End.IsZero() {
+		filter.EndTime = &opts.End
+	}
+
+	evts, err := source.StreamEvents(context.Background(), filter)
+	if err != nil {
+		log.Fatalf("Failed to stream events: %v", err)
+	}
+
+	fmt.Printf("🎬 World replay: %d events\n", len(evts))
+	r := reconstruct(evts, opts)
+
+	printStats(r.stats)
+	printChanges(os.Stdout, r.changes(*diff))
+}
+
+// parseTime разбирает время в формате RFC3339 (пустая строка - нулевое время)
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+func printStats(stats replayStats) {
+	fmt.Printf("Applied: %d\n", stats.Applied)
+	if stats.Reordered > 0 {
+		fmt.Printf("Out-of-order events re-sorted: %d\n", stats.Reordered)
+	}
+	if stats.OutOfRange > 0 {
+		fmt.Printf("Outside time range: %d\n", stats.OutOfRange)
+	}
+	if stats.OtherWorld > 0 {
+		fmt.Printf("Other worlds: %d\n", stats.OtherWorld)
+	}
+	if stats.Malformed > 0 {
+		fmt.Printf("⚠️  Malformed events skipped: %d\n", stats.Malformed)
+	}
+	fmt.Println()
+}
+
+func printChanges(w io.Writer, changes []blockChange) {
+	if len(changes) == 0 {
+		fmt.Fprintln(w, "No block changes")
+		return
+	}
+	for _, c := range changes {
+		fmt.Fprintf(w, "(%d,%d) layer %d: %d -> %d\n", c.Pos.X, c.Pos.Y, c.Layer, c.Before, c.After)
+	}
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/protocol/events"
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/annel0/mmo-game/internal/world"
+	"github.com/annel0/mmo-game/internal/world/block"
+)
+
+// blockKey идентифицирует блок по мировым координатам и слою
+type blockKey struct {
+	Pos   vec.Vec2
+	Layer world.BlockLayer
+}
+
+// replayOptions задаёт параметры восстановления
+type replayOptions struct {
+	WorldID string    // Пустая строка - события любого мира
+	Start   time.Time // Нулевое значение - без нижней границы
+	End     time.Time // Нулевое значение - без верхней границы
+	Seed    int64     // Сид генератора, из которого берётся базовое состояние
+}
+
+// replayStats - итоги восстановления
+type replayStats struct {
+	Applied    int // Применено событий
+	OutOfRange int // Вне временного диапазона
+	OtherWorld int // Относятся к другому миру
+	Malformed  int // Без координат или ID блока
+	Reordered  int // Пришли с нарушением порядка времени
+}
+
+// blockChange - итоговое изменение блока относительно базового состояния
+type blockChange struct {
+	Pos    vec.Vec2
+	Layer  world.BlockLayer
+	Before block.BlockID
+	After  block.BlockID
+}
+
+// reconstruction - мир, восстановленный по событиям блоков
+type reconstruction struct {
+	world *world.WorldManager
+	base  map[blockKey]block.BlockID // Состояние блока до первого события
+	stats replayStats
+}
+
+// reconstruct применяет события блоков по порядку времени к новому WorldManager.
+// Базовым состоянием служит сгенерированный по сиду мир: снимков мира в шине нет,
+// поэтому блоки, не затронутые событиями, совпадают с генератором.
+func reconstruct(evts []events.Event, opts replayOptions) *reconstruction {
+	r := &reconstruction{
+		world: world.NewWorldManager(opts.Seed),
+		base:  make(map[blockKey]block.BlockID),
+	}
+
+	// Шина не гарантирует порядок доставки - сортируем по времени, сохраняя порядок равных
+	for i := 1; i < len(evts); i++ {
+		if evts[i].Timestamp < evts[i-1].Timestamp {
+			r.stats.Reordered++
+		}
+	}
+	ordered := make([]events.Event, len(evts))
+	copy(ordered, evts)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].Timestamp < ordered[j].Timestamp
+	})
+
+	for _, evt := range ordered {
+		if evt.Type != events.EventTypeBlock {
+			continue
+		}
+		if !inRange(evt.Timestamp, opts) {
+			r.stats.OutOfRange++
+			continue
+		}
+		if opts.WorldID != "" {
+			if worldID, ok := evt.Data["world_id"]; ok && fmt.Sprint(worldID) != opts.WorldID {
+				r.stats.OtherWorld++
+				continue
+			}
+		}
+		if err := r.apply(evt); err != nil {
+			r.stats.Malformed++
+			continue
+		}
+		r.stats.Applied++
+	}
+
+	return r
+}
+
+// apply применяет одно событие блока
+func (r *reconstruction) apply(evt events.Event) error {
+	x, okX := intField(evt.Data, "x")
+	y, okY := intField(evt.Data, "y")
+	if !okX || !okY {
+		return fmt.Errorf("block event without coordinates")
+	}
+
+	layer := world.LayerActive
+	if z, ok := intField(evt.Data, "z"); ok {
+		if z < 0 || z >= int(world.MaxLayers) {
+			return fmt.Errorf("invalid layer %d", z)
+		}
+		layer = world.BlockLayer(z)
+	}
+
+	action, _ := evt.Data["action"].(string)
+	var id block.BlockID
+	switch action {
+	case "broken", "mined":
+		id = block.AirBlockID
+	default:
+		blockID, ok := intField(evt.Data, "block_id")
+		if !ok || blockID < 0 || blockID > int(^block.BlockID(0)) {
+			return fmt.Errorf("block event without valid block_id")
+		}
+		id = block.BlockID(blockID)
+	}
+
+	pos := vec.Vec2{X: x, Y: y}
+	key := blockKey{Pos: pos, Layer: layer}
+	current := r.world.GetBlockLayer(pos, layer)
+	if _, seen := r.base[key]; !seen {
+		r.base[key] = current.ID
+	}
+
+	next := world.NewBlock(id)
+	if metadata, ok := evt.Data["metadata"].(map[string]interface{}); ok {
+		next.Payload = metadata
+	} else if id == current.ID {
+		// "updated" без метаданных не должно стирать ранее восстановленные значения
+		next.Payload = current.Payload
+	}
+	r.world.SetBlockLayer(pos, layer, next)
+	return nil
+}
+
+// changes возвращает затронутые событиями блоки, отсортированные по координатам.
+// При onlyDiff блоки, вернувшиеся в базовое состояние, пропускаются.
+func (r *reconstruction) changes(onlyDiff bool) []blockChange {
+	result := make([]blockChange, 0, len(r.base))
+	for key, before := range r.base {
+		after := r.world.GetBlockLayer(key.Pos, key.Layer).ID
+		if onlyDiff && before == after {
+			continue
+		}
+		result = append(result, blockChange{Pos: key.Pos, Layer: key.Layer, Before: before, After: after})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.Pos.Y != b.Pos.Y {
+			return a.Pos.Y < b.Pos.Y
+		}
+		if a.Pos.X != b.Pos.X {
+			return a.Pos.X < b.Pos.X
+		}
+		return a.Layer < b.Layer
+	})
+	return result
+}
+
+// inRange проверяет попадание времени события (Unix, секунды) в диапазон
+func inRange(timestamp int64, opts replayOptions) bool {
+	if !opts.Start.IsZero() && timestamp < opts.Start.Unix() {
+		return false
+	}
+	if !opts.End.IsZero() && timestamp > opts.End.Unix() {
+		return false
+	}
+	return true
+}
+
+// intField извлекает целое из данных события (JSON даёт float64 или json.Number)
+func intField(data map[string]interface{}, key string) (int, bool) {
+	switch v := data[key].(type) {
+	case int:
+		return v, true
+	case int32:
+		return int(v), true
+	case int64:
+		return int(v), true
+	case uint32:
+		return int(v), true
+	case uint64:
+		return int(v), true
+	case float64:
+		if v != float64(int(v)) {
+			return 0, false
+		}
+		return int(v), true
+	case json.Number:
+		n, err := v.Int64()
+		return int(n), err == nil
+	default:
+		return 0, false
+	}
+}
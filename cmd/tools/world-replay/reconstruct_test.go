@@ -0,0 +1,63 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/protocol/events"
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/annel0/mmo-game/internal/world"
+	"github.com/annel0/mmo-game/internal/world/block"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func blockEvent(ts int64, data map[string]interface{}) events.Event {
+	return events.Event{Type: events.EventTypeBlock, Timestamp: ts, Data: data}
+}
+
+func TestReconstruct_SyntheticSequence(t *testing.T) {
+	base := time.Unix(1_700_000_000, 0)
+	evts := []events.Event{
+		blockEvent(base.Unix()+10, map[string]interface{}{"x": 1, "y": 1, "block_id": float64(block.StoneBlockID), "action": "placed", "world_id": "main"}),
+		// Пришло раньше по времени, чем предыдущее - должно примениться первым
+		blockEvent(base.Unix()+5, map[string]interface{}{"x": 1, "y": 1, "block_id": float64(block.DirtBlockID), "action": "placed", "world_id": "main"}),
+		blockEvent(base.Unix()+20, map[string]interface{}{"x": 2, "y": 1, "z": 0, "block_id": float64(block.SandBlockID), "action": "placed"}),
+		blockEvent(base.Unix()+30, map[string]interface{}{"x": 3, "y": 3, "block_id": float64(block.StoneBlockID), "action": "placed", "world_id": "main"}),
+		blockEvent(base.Unix()+40, map[string]interface{}{"x": 3, "y": 3, "action": "broken", "world_id": "main"}),
+		// После конца диапазона
+		blockEvent(base.Unix()+100, map[string]interface{}{"x": 1, "y": 1, "block_id": float64(block.WaterBlockID), "action": "placed", "world_id": "main"}),
+		// Чужой мир и событие без координат
+		blockEvent(base.Unix()+15, map[string]interface{}{"x": 5, "y": 5, "block_id": float64(block.StoneBlockID), "world_id": "other"}),
+		blockEvent(base.Unix()+16, map[string]interface{}{"block_id": float64(block.StoneBlockID)}),
+		// Не блоковые события игнорируются
+		{Type: events.EventTypeChat, Timestamp: base.Unix() + 1, Data: map[string]interface{}{"message": "hi"}},
+	}
+
+	r := reconstruct(evts, replayOptions{WorldID: "main", End: base.Add(50 * time.Second), Seed: 42})
+
+	assert.Equal(t, replayStats{Applied: 5, OutOfRange: 1, OtherWorld: 1, Malformed: 1, Reordered: 3}, r.stats)
+
+	assert.Equal(t, block.StoneBlockID, r.world.GetBlockLayer(vec.Vec2{X: 1, Y: 1}, world.LayerActive).ID,
+		"побеждает событие с более поздним временем")
+	assert.Equal(t, block.SandBlockID, r.world.GetBlockLayer(vec.Vec2{X: 2, Y: 1}, world.LayerFloor).ID)
+	assert.Equal(t, block.AirBlockID, r.world.GetBlockLayer(vec.Vec2{X: 3, Y: 3}, world.LayerActive).ID)
+
+	all := r.changes(false)
+	require.Len(t, all, 3)
+	assert.Equal(t, vec.Vec2{X: 1, Y: 1}, all[0].Pos)
+	assert.Equal(t, block.StoneBlockID, all[0].After)
+	assert.Equal(t, vec.Vec2{X: 3, Y: 3}, all[2].Pos)
+	assert.Equal(t, block.AirBlockID, all[2].After)
+
+	// Базовое состояние берётся из генератора, поэтому diff не содержит блоков, совпадающих с ним
+	for _, c := range r.changes(true) {
+		assert.NotEqual(t, c.Before, c.After)
+	}
+}
+
+func TestReconstruct_EmptyInput(t *testing.T) {
+	r := reconstruct(nil, replayOptions{})
+	assert.Equal(t, replayStats{}, r.stats)
+	assert.Empty(t, r.changes(false))
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/storage_adapter"
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/annel0/mmo-game/internal/world"
+)
+
+func main() {
+	var (
+		seed     = flag.Int64("seed", 0, "World generator seed (must match the server's seed)")
+		radius   = flag.Int("radius", 8, "Radius in chunks around the center")
+		centerX  = flag.Int("center-x", 0, "Center X in chunk coordinates")
+		centerY  = flag.Int("center-y", 0, "Center Y in chunk coordinates")
+		out      = flag.String("out", "data/chunks", "Directory for persisted chunks")
+		parallel = flag.Int("parallel", world.DefaultPrefetchParallelism, "Chunks generated concurrently")
+	)
+	flag.Parse()
+
+	store, err := storage_adapter.NewFileStorageAdapter(*out, false)
+	if err != nil {
+		log.Fatalf("Failed to open storage: %v", err)
+	}
+
+	// Ctrl+C прерывает генерацию; повторный запуск продолжит с места остановки
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	wm := world.NewWorldManager(*seed)
+	defer wm.Stop()
+
+	opts := pregenOptions{
+		Center:      vec.Vec2{X: *centerX, Y: *centerY},
+		Radius:      *radius,
+		Parallelism: *parallel,
+	}
+	fmt.Printf("🌍 Pre-generating chunks around (%d,%d), radius %d, seed %d -> %s\n",
+		opts.Center.X, opts.Center.Y, opts.Radius, *seed, *out)
+
+	lastReport := time.Now()
+	stats, err := pregenerate(ctx, wm, store, opts, func(done, total int) {
+		if done == total || time.Since(lastReport) >= time.Second {
+			lastReport = time.Now()
+			fmt.Printf("  %d/%d chunks (%.1f%%)\n", done, total, float64(done)*100/float64(total))
+		}
+	})
+
+	fmt.Printf("Total: %d, generated: %d, already persisted: %d\n", stats.Total, stats.Generated, stats.Skipped)
+	if stats.Generated > 0 {
+		fmt.Printf("Time: %s (%s per chunk)\n", stats.Duration.Round(time.Millisecond), (stats.Duration / time.Duration(stats.Generated)).Round(time.Microsecond))
+	}
+
+	if errors.Is(err, context.Canceled) {
+		fmt.Println("⚠️  Interrupted, run again with the same flags to resume")
+		return
+	}
+	if err != nil {
+		log.Fatalf("Pre-generation failed: %v", err)
+	}
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/storage_adapter"
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/annel0/mmo-game/internal/world"
+)
+
+// chunkStore - хранилище, в которое сохраняются сгенерированные чанки
+type chunkStore interface {
+	HasChunk(chunkCoords vec.Vec2) bool
+	SaveChunk(chunkCoords vec.Vec2, blocks []storage_adapter.BlockData) error
+}
+
+// pregenOptions задаёт область предварительной генерации
+type pregenOptions struct {
+	Center      vec.Vec2 // Центр области в координатах чанков
+	Radius      int      // Радиус в чанках: генерируется квадрат (2*Radius+1)^2
+	Parallelism int      // Сколько чанков генерируется одновременно
+}
+
+// pregenStats - итоги предварительной генерации
+type pregenStats struct {
+	Total     int           // Чанков в области
+	Skipped   int           // Уже были сохранены (продолжение прерванного запуска)
+	Generated int           // Сгенерировано и сохранено
+	Duration  time.Duration // Общее время работы
+}
+
+// pregenerate генерирует все чанки области через WorldManager (тем же путём,
+// что и живой сервер) и сохраняет их в store. Уже сохранённые чанки
+// пропускаются, поэтому прерванный запуск можно продолжить.
+// onProgress вызывается последовательно после каждого обработанного чанка
+func pregenerate(ctx context.Context, wm *world.WorldManager, store chunkStore, opts pregenOptions, onProgress func(done, total int)) (pregenStats, error) {
+	start := time.Now()
+	if opts.Radius < 0 {
+		return pregenStats{}, fmt.Errorf("radius must not be negative, got %d", opts.Radius)
+	}
+	if opts.Parallelism <= 0 {
+		opts.Parallelism = world.DefaultPrefetchParallelism
+	}
+
+	var pending []vec.Vec2
+	stats := pregenStats{}
+	for x := opts.Center.X - opts.Radius; x <= opts.Center.X+opts.Radius; x++ {
+		for y := opts.Center.Y - opts.Radius; y <= opts.Center.Y+opts.Radius; y++ {
+			stats.Total++
+			coords := vec.Vec2{X: x, Y: y}
+			if store.HasChunk(coords) {
+				stats.Skipped++
+				continue
+			}
+			pending = append(pending, coords)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		firstErr error
+		wg       sync.WaitGroup
+	)
+	done := stats.Skipped
+	jobs := make(chan vec.Vec2)
+
+	for i := 0; i < opts.Parallelism; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for coords := range jobs {
+				chunk := wm.GetChunk(coords)
+				err := store.SaveChunk(coords, chunkBlocks(chunk))
+
+				mu.Lock()
+				if err != nil {
+					if firstErr == nil {
+						firstErr = fmt.Errorf("save chunk %v: %w", coords, err)
+						cancel()
+					}
+				} else {
+					stats.Generated++
+					done++
+					if onProgress != nil {
+						onProgress(done, stats.Total)
+					}
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+feed:
+	for _, coords := range pending {
+		select {
+		case <-ctx.Done():
+			break feed
+		case jobs <- coords:
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	stats.Duration = time.Since(start)
+	if firstErr != nil {
+		return stats, firstErr
+	}
+	return stats, ctx.Err()
+}
+
+// chunkBlocks переводит слой ACTIVE чанка в формат хранилища (индекс y*16+x).
+// Как и WorldStorage, хранилище сохраняет только активный слой: остальные
+// слои детерминированно восстанавливаются генератором по сиду
+func chunkBlocks(chunk *world.Chunk) []storage_adapter.BlockData {
+	blocks := make([]storage_adapter.BlockData, 16*16)
+	for y := 0; y < 16; y++ {
+		for x := 0; x < 16; x++ {
+			local := vec.Vec2{X: x, Y: y}
+			blocks[y*16+x] = storage_adapter.BlockData{
+				ID:       uint32(chunk.GetBlockLayer(world.LayerActive, local)),
+				Metadata: chunk.GetBlockMetadataLayer(world.LayerActive, local),
+			}
+		}
+	}
+	return blocks
+}
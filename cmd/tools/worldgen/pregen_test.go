@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/annel0/mmo-game/internal/storage_adapter"
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/annel0/mmo-game/internal/world"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPregenerate_PersistsAreaAndResumes(t *testing.T) {
+	dir := t.TempDir()
+	store, err := storage_adapter.NewFileStorageAdapter(dir, false)
+	require.NoError(t, err)
+
+	wm := world.NewWorldManager(1234)
+	opts := pregenOptions{Center: vec.Vec2{X: 2, Y: -1}, Radius: 2, Parallelism: 3}
+
+	var progressCalls int
+	stats, err := pregenerate(context.Background(), wm, store, opts, func(done, total int) {
+		progressCalls++
+		assert.Equal(t, 25, total)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 25, stats.Total)
+	assert.Equal(t, 25, stats.Generated)
+	assert.Zero(t, stats.Skipped)
+	assert.Equal(t, 25, progressCalls)
+
+	files, err := filepath.Glob(filepath.Join(dir, "chunk_*.json"))
+	require.NoError(t, err)
+	assert.Len(t, files, 25)
+
+	// Сохранённый чанк совпадает с тем, что генерирует живой сервер
+	coords := vec.Vec2{X: 4, Y: 1}
+	blocks, err := store.LoadChunk(coords)
+	require.NoError(t, err)
+	live := world.NewWorldManager(1234).GetChunk(coords)
+	assert.Equal(t, chunkBlocks(live)[5*16+7].ID, blocks[5*16+7].ID)
+
+	// Прерванный запуск: удаляем два чанка, повторный запуск догенерирует только их
+	require.NoError(t, os.Remove(filepath.Join(dir, "chunk_0_-3.json")))
+	require.NoError(t, os.Remove(filepath.Join(dir, "chunk_4_1.json")))
+
+	resumedStore, err := storage_adapter.NewFileStorageAdapter(dir, false)
+	require.NoError(t, err)
+	stats, err = pregenerate(context.Background(), world.NewWorldManager(1234), resumedStore, opts, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, stats.Generated)
+	assert.Equal(t, 23, stats.Skipped)
+
+	files, err = filepath.Glob(filepath.Join(dir, "chunk_*.json"))
+	require.NoError(t, err)
+	assert.Len(t, files, 25)
+}
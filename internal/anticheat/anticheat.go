@@ -0,0 +1,111 @@
+// Package anticheat обнаруживает подозрительные действия игроков.
+//
+// Каждое действие (перемещение, изменение блока) проверяется набором правил;
+// сработавшее правило создаёт запись о нарушении с уровнем серьёзности,
+// которая передаётся обработчику (например, в webhook anticheat.violation).
+// Движок только фиксирует нарушения: отклонение действий остаётся за
+// игровым обработчиком.
+package anticheat
+
+import (
+	"time"
+
+	"github.com/annel0/mmo-game/internal/vec"
+)
+
+// Имена встроенных правил (используются для включения/отключения в конфигурации)
+const (
+	RuleSpeed         = "speed"
+	RuleReach         = "reach"
+	RuleBlockEditRate = "block_edit_rate"
+	RuleTeleport      = "teleport"
+)
+
+// ActionType - вид действия игрока
+type ActionType int
+
+const (
+	ActionMove ActionType = iota
+	ActionBlockEdit
+)
+
+// String возвращает имя действия для логов и webhook'ов
+func (a ActionType) String() string {
+	switch a {
+	case ActionMove:
+		return "move"
+	case ActionBlockEdit:
+		return "block_edit"
+	default:
+		return "unknown"
+	}
+}
+
+// Action - действие игрока, проверяемое правилами
+type Action struct {
+	PlayerID uint64
+	Username string
+	Type     ActionType
+	Time     time.Time
+	Position vec.Vec2Float // Позиция игрока после действия (для перемещения - новая)
+	Target   vec.Vec2Float // Цель действия (блок) для ActionBlockEdit
+
+	// SpeedMultiplier - множитель скорости игрока от эффектов (0 - без эффектов).
+	// Предел SpeedRule умножается на него
+	SpeedMultiplier float64
+}
+
+// Severity - серьёзность нарушения по шкале 1-10
+type Severity int
+
+const (
+	SeverityLow      Severity = 3
+	SeverityMedium   Severity = 5
+	SeverityHigh     Severity = 8
+	SeverityCritical Severity = 10
+)
+
+// Violation - запись о нарушении
+type Violation struct {
+	PlayerID uint64
+	Username string
+	Rule     string
+	Action   ActionType
+	Severity Severity
+	Value    float64 // Измеренное значение (скорость, дистанция, число правок)
+	Limit    float64 // Допустимый предел
+	Details  string
+	Time     time.Time
+}
+
+// WebhookData возвращает поля события anticheat.violation
+func (v Violation) WebhookData() map[string]interface{} {
+	return map[string]interface{}{
+		"player_id":      v.PlayerID,
+		"username":       v.Username,
+		"violation_type": v.Rule,
+		"action":         v.Action.String(),
+		"severity":       int(v.Severity),
+		"value":          v.Value,
+		"limit":          v.Limit,
+		"details":        v.Details,
+		"time":           v.Time.Unix(),
+	}
+}
+
+// PlayerState - история игрока, которую правила используют между действиями.
+// Изменяется только движком под его блокировкой.
+type PlayerState struct {
+	LastPosition vec.Vec2Float
+	LastMoveTime time.Time
+	HasPosition  bool
+	BlockEdits   []time.Time     // Время недавних правок блоков (в пределах окна правила)
+	Recent       []EvidenceEvent // Недавние действия для пакета доказательств
+}
+
+// Rule - правило проверки действий. Check вызывается до обновления PlayerState
+// текущим действием и возвращает nil, если нарушения нет.
+type Rule interface {
+	Name() string
+	Check(action Action, state *PlayerState) *Violation
+}
@@ -0,0 +1,256 @@
+package anticheat
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/eventbus"
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var violationsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "anticheat",
+	Name:      "violations_total",
+	Help:      "Нарушения, обнаруженные правилами античита.",
+}, []string{"rule"})
+
+func init() {
+	prometheus.MustRegister(violationsTotal)
+}
+
+// Config - настройки встроенных правил (нулевые значения = по умолчанию)
+type Config struct {
+	Disabled            []string // Имена отключённых правил
+	MaxSpeed            float64
+	MaxReach            float64
+	MaxBlockEdits       int
+	BlockEditWindow     time.Duration
+	MaxTeleportDistance float64
+
+	EvidenceWindow    time.Duration // Сколько секунд действий попадает в пакет доказательств
+	EvidenceMaxEvents int           // Максимум действий в буфере игрока
+}
+
+// withDefaults подставляет значения по умолчанию вместо нулевых
+func (c Config) withDefaults() Config {
+	if c.MaxSpeed <= 0 {
+		c.MaxSpeed = DefaultMaxSpeed
+	}
+	if c.MaxReach <= 0 {
+		c.MaxReach = DefaultMaxReach
+	}
+	if c.MaxBlockEdits <= 0 {
+		c.MaxBlockEdits = DefaultMaxBlockEdits
+	}
+	if c.BlockEditWindow <= 0 {
+		c.BlockEditWindow = DefaultBlockEditWindow
+	}
+	if c.MaxTeleportDistance <= 0 {
+		c.MaxTeleportDistance = DefaultMaxTeleportDistance
+	}
+	if c.EvidenceWindow <= 0 {
+		c.EvidenceWindow = DefaultEvidenceWindow
+	}
+	if c.EvidenceMaxEvents <= 0 {
+		c.EvidenceMaxEvents = DefaultEvidenceMaxEvents
+	}
+	return c
+}
+
+// Engine проверяет действия игроков набором правил
+type Engine struct {
+	mu          sync.Mutex
+	rules       []Rule
+	disabled    map[string]bool
+	players     map[uint64]*PlayerState
+	editHistory time.Duration // Сколько хранить время правок блоков
+	reporter    func(Violation)
+	now         func() time.Time
+
+	evidenceWindow time.Duration
+	evidenceMax    int
+	publish        func(ctx context.Context, ev *eventbus.Envelope) error // Публикация доказательств
+}
+
+// NewEngine создаёт движок со встроенными правилами по конфигурации
+func NewEngine(cfg Config) *Engine {
+	cfg = cfg.withDefaults()
+
+	e := &Engine{
+		disabled:    make(map[string]bool),
+		players:     make(map[uint64]*PlayerState),
+		editHistory: cfg.BlockEditWindow,
+		now:         time.Now,
+
+		evidenceWindow: cfg.EvidenceWindow,
+		evidenceMax:    cfg.EvidenceMaxEvents,
+		publish:        eventbus.Publish,
+	}
+	e.rules = []Rule{
+		&SpeedRule{MaxSpeed: cfg.MaxSpeed},
+		&ReachRule{MaxDistance: cfg.MaxReach},
+		&BlockEditRateRule{MaxEdits: cfg.MaxBlockEdits, Window: cfg.BlockEditWindow},
+		&TeleportRule{MaxDistance: cfg.MaxTeleportDistance},
+	}
+	for _, name := range cfg.Disabled {
+		e.disabled[name] = true
+	}
+	return e
+}
+
+// AddRule подключает дополнительное правило
+func (e *Engine) AddRule(rule Rule) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.rules = append(e.rules, rule)
+}
+
+// SetRuleEnabled включает или отключает правило по имени
+func (e *Engine) SetRuleEnabled(name string, enabled bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if enabled {
+		delete(e.disabled, name)
+	} else {
+		e.disabled[name] = true
+	}
+}
+
+// RuleEnabled сообщает, включено ли правило
+func (e *Engine) RuleEnabled(name string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return !e.disabled[name]
+}
+
+// BlockEditLimit возвращает предел правил частоты правок блоков
+func (e *Engine) BlockEditLimit() (maxEdits int, window time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, rule := range e.rules {
+		if r, ok := rule.(*BlockEditRateRule); ok {
+			return r.MaxEdits, r.Window
+		}
+	}
+	return 0, 0
+}
+
+// SetBlockEditLimit меняет предел частоты правок блоков без перезапуска
+func (e *Engine) SetBlockEditLimit(maxEdits int, window time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, rule := range e.rules {
+		if r, ok := rule.(*BlockEditRateRule); ok {
+			r.MaxEdits = maxEdits
+			r.Window = window
+		}
+	}
+	e.editHistory = window
+}
+
+// SetReporter задаёт получателя нарушений (вызывается вне блокировки движка)
+func (e *Engine) SetReporter(reporter func(Violation)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.reporter = reporter
+}
+
+// Evaluate проверяет действие всеми включёнными правилами, запоминает его
+// в истории игрока и возвращает обнаруженные нарушения
+func (e *Engine) Evaluate(action Action) []Violation {
+	e.mu.Lock()
+	if action.Time.IsZero() {
+		action.Time = e.now()
+	}
+
+	state, ok := e.players[action.PlayerID]
+	if !ok {
+		state = &PlayerState{}
+		e.players[action.PlayerID] = state
+	}
+
+	var violations []Violation
+	for _, rule := range e.rules {
+		if e.disabled[rule.Name()] {
+			continue
+		}
+		v := rule.Check(action, state)
+		if v == nil {
+			continue
+		}
+		v.PlayerID = action.PlayerID
+		v.Username = action.Username
+		v.Action = action.Type
+		v.Time = action.Time
+		violations = append(violations, *v)
+	}
+
+	e.record(action, state)
+	e.remember(action, state)
+
+	var evidence Evidence
+	if len(violations) > 0 {
+		evidence = e.takeEvidence(action, state, violations)
+	}
+	reporter := e.reporter
+	e.mu.Unlock()
+
+	if len(violations) > 0 {
+		e.publishEvidence(evidence)
+	}
+
+	for _, v := range violations {
+		violationsTotal.WithLabelValues(v.Rule).Inc()
+		log.Printf("🚨 Античит: игрок %d (%s) нарушил правило %s (серьёзность %d): %s",
+			v.PlayerID, v.Username, v.Rule, v.Severity, v.Details)
+		if reporter != nil {
+			reporter(v)
+		}
+	}
+	return violations
+}
+
+// record обновляет историю игрока действием. Вызывается под e.mu.
+func (e *Engine) record(action Action, state *PlayerState) {
+	switch action.Type {
+	case ActionMove:
+		state.LastPosition = action.Position
+		state.LastMoveTime = action.Time
+		state.HasPosition = true
+	case ActionBlockEdit:
+		since := action.Time.Add(-e.editHistory)
+		edits := state.BlockEdits[:0]
+		for _, t := range state.BlockEdits {
+			if t.After(since) {
+				edits = append(edits, t)
+			}
+		}
+		state.BlockEdits = append(edits, action.Time)
+	}
+}
+
+// ResetPosition задаёт исходную позицию игрока без проверки правилами.
+// Вызывается при появлении и легальных перемещениях сервером (респаун, телепорт).
+func (e *Engine) ResetPosition(playerID uint64, pos vec.Vec2Float) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	state, ok := e.players[playerID]
+	if !ok {
+		state = &PlayerState{}
+		e.players[playerID] = state
+	}
+	state.LastPosition = pos
+	state.LastMoveTime = e.now()
+	state.HasPosition = true
+}
+
+// Forget удаляет историю игрока (при отключении)
+func (e *Engine) Forget(playerID uint64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.players, playerID)
+}
@@ -0,0 +1,135 @@
+package anticheat
+
+import (
+	"testing"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var start = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func move(playerID uint64, x float64, at time.Duration) Action {
+	return Action{PlayerID: playerID, Type: ActionMove, Time: start.Add(at), Position: vec.Vec2Float{X: x}}
+}
+
+func blockEdit(playerID uint64, player, target vec.Vec2Float, at time.Duration) Action {
+	return Action{PlayerID: playerID, Type: ActionBlockEdit, Time: start.Add(at), Position: player, Target: target}
+}
+
+func rules(violations []Violation) []string {
+	names := make([]string, 0, len(violations))
+	for _, v := range violations {
+		names = append(names, v.Rule)
+	}
+	return names
+}
+
+func TestEngine_SpeedHack(t *testing.T) {
+	engine := NewEngine(Config{MaxSpeed: 10})
+
+	var reported []Violation
+	engine.SetReporter(func(v Violation) { reported = append(reported, v) })
+
+	// Честная ходьба: 5 блоков/с
+	assert.Empty(t, engine.Evaluate(move(1, 0, 0)))
+	assert.Empty(t, engine.Evaluate(move(1, 1, 200*time.Millisecond)))
+	assert.Empty(t, engine.Evaluate(move(1, 2, 400*time.Millisecond)))
+
+	// Спидхак: 8 блоков за 200 мс = 40 блоков/с
+	violations := engine.Evaluate(move(1, 10, 600*time.Millisecond))
+	require.Equal(t, []string{RuleSpeed}, rules(violations))
+	assert.Equal(t, SeverityHigh, violations[0].Severity)
+	assert.InDelta(t, 40, violations[0].Value, 0.001)
+	assert.Equal(t, uint64(1), violations[0].PlayerID)
+	assert.Equal(t, violations, reported, "нарушения передаются получателю")
+	assert.Equal(t, RuleSpeed, reported[0].WebhookData()["violation_type"])
+}
+
+func TestEngine_SpeedLimitFollowsMultiplier(t *testing.T) {
+	engine := NewEngine(Config{MaxSpeed: 10})
+
+	// 3 блока за 200 мс = 15 блоков/с: с ускорением 1.5 - в пределе
+	assert.Empty(t, engine.Evaluate(move(1, 0, 0)))
+	hasted := move(1, 3, 200*time.Millisecond)
+	hasted.SpeedMultiplier = 1.5
+	assert.Empty(t, engine.Evaluate(hasted))
+
+	// Без ускорения та же скорость - нарушение
+	violations := engine.Evaluate(move(1, 6, 400*time.Millisecond))
+	require.Equal(t, []string{RuleSpeed}, rules(violations))
+	assert.Equal(t, 10.0, violations[0].Limit)
+}
+
+func TestEngine_ExcessiveReach(t *testing.T) {
+	engine := NewEngine(Config{MaxReach: 5})
+	player := vec.Vec2Float{X: 0, Y: 0}
+
+	assert.Empty(t, engine.Evaluate(blockEdit(1, player, vec.Vec2Float{X: 3, Y: 4}, 0)), "ровно на пределе")
+
+	violations := engine.Evaluate(blockEdit(1, player, vec.Vec2Float{X: 30, Y: 40}, time.Second))
+	require.Equal(t, []string{RuleReach}, rules(violations))
+	assert.Equal(t, SeverityHigh, violations[0].Severity)
+	assert.InDelta(t, 50, violations[0].Value, 0.001)
+	assert.Equal(t, 5.0, violations[0].Limit)
+	assert.Equal(t, ActionBlockEdit, violations[0].Action)
+}
+
+func TestEngine_Teleport(t *testing.T) {
+	engine := NewEngine(Config{MaxTeleportDistance: 20, Disabled: []string{RuleSpeed}})
+
+	engine.Evaluate(move(1, 0, 0))
+	violations := engine.Evaluate(move(1, 100, 10*time.Second))
+	assert.Equal(t, []string{RuleTeleport}, rules(violations), "телепорт обнаруживается независимо от времени")
+
+	// Серверный респаун не считается телепортом
+	engine.ResetPosition(1, vec.Vec2Float{X: 500})
+	assert.Empty(t, engine.Evaluate(move(1, 501, 11*time.Second)))
+}
+
+func TestEngine_BlockEditRate(t *testing.T) {
+	engine := NewEngine(Config{MaxBlockEdits: 3, BlockEditWindow: time.Second})
+	pos := vec.Vec2Float{}
+
+	for i := 0; i < 3; i++ {
+		assert.Empty(t, engine.Evaluate(blockEdit(1, pos, pos, time.Duration(i)*100*time.Millisecond)))
+	}
+	assert.Equal(t, []string{RuleBlockEditRate}, rules(engine.Evaluate(blockEdit(1, pos, pos, 300*time.Millisecond))))
+
+	// После окна старые правки не учитываются
+	assert.Empty(t, engine.Evaluate(blockEdit(1, pos, pos, 2*time.Second)))
+}
+
+func TestEngine_RuleToggle(t *testing.T) {
+	engine := NewEngine(Config{MaxReach: 5, Disabled: []string{RuleReach}})
+	far := blockEdit(1, vec.Vec2Float{}, vec.Vec2Float{X: 100}, 0)
+
+	assert.False(t, engine.RuleEnabled(RuleReach))
+	assert.Empty(t, engine.Evaluate(far))
+
+	engine.SetRuleEnabled(RuleReach, true)
+	far.Time = start.Add(time.Second)
+	assert.Equal(t, []string{RuleReach}, rules(engine.Evaluate(far)))
+}
+
+// maxYRule - пример подключаемого правила
+type maxYRule struct{}
+
+func (maxYRule) Name() string { return "max_y" }
+func (maxYRule) Check(action Action, state *PlayerState) *Violation {
+	if action.Position.Y > 1000 {
+		return &Violation{Rule: "max_y", Severity: SeverityLow}
+	}
+	return nil
+}
+
+func TestEngine_CustomRule(t *testing.T) {
+	engine := NewEngine(Config{Disabled: []string{RuleSpeed, RuleTeleport}})
+	engine.AddRule(maxYRule{})
+
+	violations := engine.Evaluate(Action{PlayerID: 2, Type: ActionMove, Position: vec.Vec2Float{Y: 2000}})
+	assert.Equal(t, []string{"max_y"}, rules(violations))
+	assert.False(t, violations[0].Time.IsZero(), "время действия подставляется движком")
+}
@@ -0,0 +1,131 @@
+package anticheat
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/eventbus"
+	"github.com/annel0/mmo-game/internal/protocol/events"
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/google/uuid"
+)
+
+// EventTypeEvidence - тип события EventBus с действиями игрока перед нарушением
+const EventTypeEvidence = string(events.EventTypeAnticheatEvidence)
+
+// Значения буфера доказательств по умолчанию
+const (
+	DefaultEvidenceWindow    = 10 * time.Second
+	DefaultEvidenceMaxEvents = 256
+)
+
+// EvidenceEvent - действие игрока в буфере доказательств
+type EvidenceEvent struct {
+	Action   string         `json:"action"`
+	Time     time.Time      `json:"time"`
+	Position vec.Vec2Float  `json:"position"`
+	Target   *vec.Vec2Float `json:"target,omitempty"`
+}
+
+// EvidenceViolation - нарушение, вызвавшее выгрузку доказательств
+type EvidenceViolation struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	Value    float64  `json:"value"`
+	Limit    float64  `json:"limit"`
+	Details  string   `json:"details"`
+}
+
+// Evidence - пакет доказательств, публикуемый при нарушении.
+// Rule и Severity относятся к самому серьёзному нарушению действия.
+type Evidence struct {
+	PlayerID   uint64              `json:"player_id"`
+	Username   string              `json:"username"`
+	Rule       string              `json:"rule"`
+	Severity   Severity            `json:"severity"`
+	Time       time.Time           `json:"time"`
+	Window     time.Duration       `json:"window"`
+	Violations []EvidenceViolation `json:"violations"`
+	Events     []EvidenceEvent     `json:"events"` // От старых к новым, последнее - нарушившее действие
+}
+
+// evidenceEvent преобразует действие в запись буфера
+func evidenceEvent(action Action) EvidenceEvent {
+	ev := EvidenceEvent{Action: action.Type.String(), Time: action.Time, Position: action.Position}
+	if action.Type == ActionBlockEdit {
+		target := action.Target
+		ev.Target = &target
+	}
+	return ev
+}
+
+// remember добавляет действие в буфер игрока, отбрасывая записи старше окна
+// и сверх лимита. Вызывается под e.mu.
+func (e *Engine) remember(action Action, state *PlayerState) {
+	since := action.Time.Add(-e.evidenceWindow)
+	recent := state.Recent[:0]
+	for _, ev := range state.Recent {
+		if ev.Time.After(since) {
+			recent = append(recent, ev)
+		}
+	}
+	recent = append(recent, evidenceEvent(action))
+	if over := len(recent) - e.evidenceMax; over > 0 {
+		recent = append(recent[:0], recent[over:]...)
+	}
+	state.Recent = recent
+}
+
+// takeEvidence собирает пакет доказательств по нарушениям действия и очищает
+// буфер игрока, чтобы следующий пакет содержал только новые действия.
+// Вызывается под e.mu.
+func (e *Engine) takeEvidence(action Action, state *PlayerState, violations []Violation) Evidence {
+	evidence := Evidence{
+		PlayerID: action.PlayerID,
+		Username: action.Username,
+		Time:     action.Time,
+		Window:   e.evidenceWindow,
+		Events:   append([]EvidenceEvent(nil), state.Recent...),
+	}
+	for _, v := range violations {
+		evidence.Violations = append(evidence.Violations, EvidenceViolation{
+			Rule:     v.Rule,
+			Severity: v.Severity,
+			Value:    v.Value,
+			Limit:    v.Limit,
+			Details:  v.Details,
+		})
+		if v.Severity > evidence.Severity {
+			evidence.Rule = v.Rule
+			evidence.Severity = v.Severity
+		}
+	}
+	state.Recent = state.Recent[:0]
+	return evidence
+}
+
+// publishEvidence отправляет пакет доказательств в EventBus.
+// player_id и rule дублируются в метаданных для фильтрации в сервисе воспроизведения.
+func (e *Engine) publishEvidence(evidence Evidence) {
+	payload, err := json.Marshal(evidence)
+	if err != nil {
+		return
+	}
+
+	_ = e.publish(context.Background(), &eventbus.Envelope{
+		ID:        uuid.NewString(),
+		Timestamp: evidence.Time.UTC(),
+		Source:    "anticheat",
+		EventType: EventTypeEvidence,
+		Version:   1,
+		Priority:  8,
+		Payload:   payload,
+		Metadata: map[string]string{
+			"player_id": strconv.FormatUint(evidence.PlayerID, 10),
+			"rule":      evidence.Rule,
+			"severity":  strconv.Itoa(int(evidence.Severity)),
+		},
+	})
+}
@@ -0,0 +1,72 @@
+package anticheat
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/eventbus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// capturePublished подменяет публикацию движка и возвращает опубликованные события
+func capturePublished(e *Engine) *[]*eventbus.Envelope {
+	published := &[]*eventbus.Envelope{}
+	e.publish = func(ctx context.Context, ev *eventbus.Envelope) error {
+		*published = append(*published, ev)
+		return nil
+	}
+	return published
+}
+
+func TestEngine_EvidenceBundle(t *testing.T) {
+	engine := NewEngine(Config{MaxSpeed: 10, EvidenceWindow: time.Second, EvidenceMaxEvents: 3})
+	published := capturePublished(engine)
+
+	// Честная ходьба: первые шаги выпадут из окна или лимита буфера
+	for i, at := range []time.Duration{0, 500, 1200, 1400, 1600} {
+		assert.Empty(t, engine.Evaluate(move(1, float64(i), at*time.Millisecond)))
+	}
+	assert.Empty(t, *published, "без нарушений доказательства не публикуются")
+
+	// Спидхак: 6 блоков за 100 мс
+	violations := engine.Evaluate(move(1, 10, 1700*time.Millisecond))
+	require.Len(t, violations, 1)
+	require.Len(t, *published, 1)
+
+	env := (*published)[0]
+	assert.Equal(t, EventTypeEvidence, env.EventType)
+	assert.Equal(t, "1", env.Metadata["player_id"])
+	assert.Equal(t, RuleSpeed, env.Metadata["rule"])
+
+	var evidence Evidence
+	require.NoError(t, json.Unmarshal(env.Payload, &evidence))
+	assert.Equal(t, uint64(1), evidence.PlayerID)
+	assert.Equal(t, RuleSpeed, evidence.Rule)
+	assert.Equal(t, SeverityHigh, evidence.Severity)
+	require.Len(t, evidence.Violations, 1)
+
+	// В пакете - последние действия в пределах окна и лимита, включая нарушившее
+	require.Len(t, evidence.Events, 3)
+	var xs []float64
+	for _, ev := range evidence.Events {
+		assert.Equal(t, "move", ev.Action)
+		xs = append(xs, ev.Position.X)
+	}
+	assert.Equal(t, []float64{3, 4, 10}, xs)
+	assert.True(t, evidence.Events[2].Time.Equal(start.Add(1700*time.Millisecond)))
+
+	// Следующий пакет содержит только новые действия
+	engine.Evaluate(blockEdit(1, evidence.Events[2].Position, evidence.Events[2].Position, 1800*time.Millisecond))
+	engine.Evaluate(move(1, 40, 1900*time.Millisecond))
+	require.Len(t, *published, 2)
+
+	var next Evidence
+	require.NoError(t, json.Unmarshal((*published)[1].Payload, &next))
+	require.Len(t, next.Events, 2)
+	assert.Equal(t, "block_edit", next.Events[0].Action)
+	require.NotNil(t, next.Events[0].Target)
+	assert.Equal(t, RuleSpeed, next.Rule)
+}
@@ -0,0 +1,150 @@
+package anticheat
+
+import (
+	"fmt"
+	"time"
+)
+
+// Значения правил по умолчанию
+const (
+	DefaultMaxSpeed            = 12.0 // блоков в секунду
+	DefaultMaxReach            = 10.0 // блоков
+	DefaultMaxBlockEdits       = 20   // правок за окно
+	DefaultBlockEditWindow     = time.Second
+	DefaultMaxTeleportDistance = 32.0 // блоков за одно перемещение
+)
+
+// minSpeedInterval - минимальный интервал между перемещениями для расчёта скорости.
+// Пакеты, пришедшие почти одновременно, сравниваются с этим интервалом, чтобы
+// сетевая пачка не выглядела как бесконечная скорость.
+const minSpeedInterval = 50 * time.Millisecond
+
+// SpeedRule обнаруживает перемещение быстрее MaxSpeed блоков в секунду
+// (с учётом множителя скорости от эффектов)
+type SpeedRule struct {
+	MaxSpeed float64
+}
+
+func (r *SpeedRule) Name() string { return RuleSpeed }
+
+func (r *SpeedRule) Check(action Action, state *PlayerState) *Violation {
+	if action.Type != ActionMove || !state.HasPosition {
+		return nil
+	}
+
+	elapsed := action.Time.Sub(state.LastMoveTime)
+	if elapsed < minSpeedInterval {
+		elapsed = minSpeedInterval
+	}
+	speed := state.LastPosition.DistanceTo(action.Position) / elapsed.Seconds()
+	limit := r.MaxSpeed
+	if action.SpeedMultiplier > 0 {
+		limit *= action.SpeedMultiplier
+	}
+	if speed <= limit {
+		return nil
+	}
+
+	severity := SeverityMedium
+	if speed > 2*limit {
+		severity = SeverityHigh
+	}
+	return &Violation{
+		Rule:     RuleSpeed,
+		Severity: severity,
+		Value:    speed,
+		Limit:    limit,
+		Details:  fmt.Sprintf("скорость %.1f блоков/с при пределе %.1f", speed, limit),
+	}
+}
+
+// ReachRule обнаруживает взаимодействие с блоком дальше MaxDistance
+type ReachRule struct {
+	MaxDistance float64
+}
+
+func (r *ReachRule) Name() string { return RuleReach }
+
+func (r *ReachRule) Check(action Action, state *PlayerState) *Violation {
+	if action.Type != ActionBlockEdit {
+		return nil
+	}
+
+	distance := action.Position.DistanceTo(action.Target)
+	if distance <= r.MaxDistance {
+		return nil
+	}
+
+	severity := SeverityLow
+	if distance > 2*r.MaxDistance {
+		severity = SeverityHigh
+	}
+	return &Violation{
+		Rule:     RuleReach,
+		Severity: severity,
+		Value:    distance,
+		Limit:    r.MaxDistance,
+		Details:  fmt.Sprintf("дистанция до блока %.1f при пределе %.1f", distance, r.MaxDistance),
+	}
+}
+
+// BlockEditRateRule обнаруживает больше MaxEdits правок блоков за Window
+type BlockEditRateRule struct {
+	MaxEdits int
+	Window   time.Duration
+}
+
+func (r *BlockEditRateRule) Name() string { return RuleBlockEditRate }
+
+func (r *BlockEditRateRule) Check(action Action, state *PlayerState) *Violation {
+	if action.Type != ActionBlockEdit {
+		return nil
+	}
+
+	// Текущая правка тоже учитывается
+	edits := 1
+	since := action.Time.Add(-r.Window)
+	for _, t := range state.BlockEdits {
+		if t.After(since) {
+			edits++
+		}
+	}
+	if edits <= r.MaxEdits {
+		return nil
+	}
+
+	return &Violation{
+		Rule:     RuleBlockEditRate,
+		Severity: SeverityMedium,
+		Value:    float64(edits),
+		Limit:    float64(r.MaxEdits),
+		Details:  fmt.Sprintf("%d правок блоков за %v при пределе %d", edits, r.Window, r.MaxEdits),
+	}
+}
+
+// TeleportRule обнаруживает перемещение дальше MaxDistance за один шаг,
+// независимо от прошедшего времени
+type TeleportRule struct {
+	MaxDistance float64
+}
+
+func (r *TeleportRule) Name() string { return RuleTeleport }
+
+func (r *TeleportRule) Check(action Action, state *PlayerState) *Violation {
+	if action.Type != ActionMove || !state.HasPosition {
+		return nil
+	}
+
+	distance := state.LastPosition.DistanceTo(action.Position)
+	if distance <= r.MaxDistance {
+		return nil
+	}
+
+	return &Violation{
+		Rule:     RuleTeleport,
+		Severity: SeverityCritical,
+		Value:    distance,
+		Limit:    r.MaxDistance,
+		Details:  fmt.Sprintf("перемещение на %.1f блоков за один шаг при пределе %.1f", distance, r.MaxDistance),
+	}
+}
@@ -0,0 +1,151 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/annel0/mmo-game/internal/auth"
+	"github.com/annel0/mmo-game/internal/storage_interface"
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/annel0/mmo-game/internal/world"
+	"github.com/gin-gonic/gin"
+)
+
+// ClaimManager управляет приватами мира
+type ClaimManager interface {
+	AddClaim(ownerID uint64, name string, from, to vec.Vec2) (storage_interface.Claim, error)
+	RemoveClaim(id uint64) error
+	Claims() []storage_interface.Claim
+}
+
+// ClaimRequest - тело POST /api/admin/claims. Углы задаются в координатах чанков
+type ClaimRequest struct {
+	Owner string   `json:"owner"` // Имя владельца; пусто - строят только администраторы
+	Name  string   `json:"name"`
+	From  vec.Vec2 `json:"from" binding:"required"`
+	To    vec.Vec2 `json:"to" binding:"required"`
+}
+
+// claimAdmin обслуживает /api/admin/claims
+type claimAdmin struct {
+	mu      sync.Mutex
+	manager ClaimManager
+}
+
+// SetClaimManager подключает /api/admin/claims к миру игрового сервера
+func (rs *RestServer) SetClaimManager(manager ClaimManager) {
+	rs.claims.mu.Lock()
+	defer rs.claims.mu.Unlock()
+	rs.claims.manager = manager
+}
+
+func (rs *RestServer) claimManager(c *gin.Context) (ClaimManager, bool) {
+	rs.claims.mu.Lock()
+	manager := rs.claims.manager
+	rs.claims.mu.Unlock()
+
+	if manager == nil {
+		c.JSON(http.StatusServiceUnavailable, GenericResponse{
+			Success: false,
+			Message: "Приваты недоступны",
+		})
+		return nil, false
+	}
+	return manager, true
+}
+
+// handleListClaims возвращает приваты мира (только для админов)
+func (rs *RestServer) handleListClaims(c *gin.Context) {
+	manager, ok := rs.claimManager(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, GenericResponse{
+		Success: true,
+		Data:    manager.Claims(),
+	})
+}
+
+// handleCreateClaim создаёт приват (только для админов)
+func (rs *RestServer) handleCreateClaim(c *gin.Context) {
+	manager, ok := rs.claimManager(c)
+	if !ok {
+		return
+	}
+
+	var req ClaimRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, GenericResponse{
+			Success: false,
+			Message: "Неверный формат запроса: " + err.Error(),
+		})
+		return
+	}
+
+	var ownerID uint64
+	if req.Owner != "" {
+		user, err := rs.userRepo.GetUserByUsername(req.Owner)
+		if errors.Is(err, auth.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, GenericResponse{Success: false, Message: "Пользователь не найден: " + req.Owner})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, GenericResponse{Success: false, Message: err.Error()})
+			return
+		}
+		ownerID = user.ID
+	}
+
+	claim, err := manager.AddClaim(ownerID, req.Name, req.From, req.To)
+	switch {
+	case errors.Is(err, world.ErrClaimOverlap):
+		c.JSON(http.StatusConflict, GenericResponse{Success: false, Message: err.Error()})
+		return
+	case errors.Is(err, world.ErrInvalidClaim):
+		c.JSON(http.StatusBadRequest, GenericResponse{Success: false, Message: err.Error()})
+		return
+	case err != nil:
+		// Приват действует, но не сохранён вместе с миром
+		log.Printf("⚠️ Приват %d создан, но не сохранён: %v", claim.ID, err)
+	}
+
+	log.Printf("🛡️ Создан приват %d (%d,%d)-(%d,%d), владелец %q", claim.ID, claim.From.X, claim.From.Y, claim.To.X, claim.To.Y, req.Owner)
+	c.JSON(http.StatusCreated, GenericResponse{
+		Success: true,
+		Message: "Приват создан",
+		Data:    claim,
+	})
+}
+
+// handleDeleteClaim удаляет приват (только для админов)
+func (rs *RestServer) handleDeleteClaim(c *gin.Context) {
+	manager, ok := rs.claimManager(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, GenericResponse{Success: false, Message: "ID привата должен быть целым числом"})
+		return
+	}
+
+	err = manager.RemoveClaim(id)
+	switch {
+	case errors.Is(err, world.ErrClaimNotFound):
+		c.JSON(http.StatusNotFound, GenericResponse{Success: false, Message: err.Error()})
+		return
+	case err != nil:
+		log.Printf("⚠️ Приват %d удалён, но метаданные мира не сохранены: %v", id, err)
+	}
+
+	log.Printf("🛡️ Удалён приват %d", id)
+	c.JSON(http.StatusOK, GenericResponse{
+		Success: true,
+		Message: "Приват удалён",
+	})
+}
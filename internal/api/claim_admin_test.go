@@ -0,0 +1,52 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/annel0/mmo-game/internal/storage_interface"
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/annel0/mmo-game/internal/world"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClaimAdmin_CreateListDelete(t *testing.T) {
+	wm := world.NewWorldManager(1234)
+	rs := testRestServer()
+	rs.SetClaimManager(wm)
+	t.Cleanup(func() { rs.SetClaimManager(nil) })
+
+	rec := adminRequest(t, rs, http.MethodPost, "/api/admin/claims", `{"owner": "admin", "name": "Спавн", "from": {"x": -1, "y": -1}, "to": {"x": 1, "y": 1}}`)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var created struct {
+		Data storage_interface.Claim `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+	assert.Equal(t, uint64(1), created.Data.OwnerID)
+	assert.Equal(t, vec.Vec2{X: 1, Y: 1}, created.Data.To)
+
+	claim, ok := wm.ClaimAt(vec.Vec2{X: 0, Y: 0})
+	require.True(t, ok)
+	assert.Equal(t, created.Data.ID, claim.ID)
+
+	rec = adminRequest(t, rs, http.MethodPost, "/api/admin/claims", `{"name": "Поверх", "from": {"x": 0, "y": 0}, "to": {"x": 3, "y": 3}}`)
+	assert.Equal(t, http.StatusConflict, rec.Code, "пересечение с существующим приватом")
+	rec = adminRequest(t, rs, http.MethodPost, "/api/admin/claims", `{"owner": "nobody", "from": {"x": 5, "y": 5}, "to": {"x": 5, "y": 5}}`)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = adminRequest(t, rs, http.MethodGet, "/api/admin/claims", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var listed struct {
+		Data []storage_interface.Claim `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &listed))
+	assert.Len(t, listed.Data, 1)
+
+	rec = adminRequest(t, rs, http.MethodDelete, "/api/admin/claims/1", "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Empty(t, wm.Claims())
+	rec = adminRequest(t, rs, http.MethodDelete, "/api/admin/claims/1", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
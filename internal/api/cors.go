@@ -0,0 +1,137 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// adminPathPrefix - эндпоинты, на которые распространяется административная CORS-политика
+const adminPathPrefix = "/api/admin"
+
+var (
+	defaultCORSMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
+	defaultCORSHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization"}
+)
+
+// CORSPolicy описывает, каким сайтам браузер разрешит обращаться к API.
+// Пустой AllowedOrigins запрещает кросс-доменные запросы
+type CORSPolicy struct {
+	AllowedOrigins   []string      // Точные origin ("https://admin.example.com") или "*"
+	AllowedMethods   []string      // Пусто - GET, POST, PUT, DELETE, OPTIONS
+	AllowedHeaders   []string      // Пусто - Origin, Content-Type, Accept, Authorization
+	AllowCredentials bool          // Разрешить cookies и заголовок Authorization от браузера
+	MaxAge           time.Duration // Кэширование preflight браузером (0 - не указывать)
+}
+
+// allowsOrigin проверяет, разрешён ли origin политикой
+func (p CORSPolicy) allowsOrigin(origin string) bool {
+	for _, allowed := range p.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowsMethod проверяет метод из Access-Control-Request-Method
+func (p CORSPolicy) allowsMethod(method string) bool {
+	return slices.ContainsFunc(p.AllowedMethods, func(m string) bool { return strings.EqualFold(m, method) })
+}
+
+// allowsHeaders проверяет заголовки из Access-Control-Request-Headers
+func (p CORSPolicy) allowsHeaders(requested string) bool {
+	for _, header := range strings.Split(requested, ",") {
+		header = strings.TrimSpace(header)
+		if header == "" {
+			continue
+		}
+		if !slices.ContainsFunc(p.AllowedHeaders, func(h string) bool { return strings.EqualFold(h, header) }) {
+			return false
+		}
+	}
+	return true
+}
+
+// withDefaults подставляет методы и заголовки по умолчанию
+func (p CORSPolicy) withDefaults() CORSPolicy {
+	if len(p.AllowedMethods) == 0 {
+		p.AllowedMethods = defaultCORSMethods
+	}
+	if len(p.AllowedHeaders) == 0 {
+		p.AllowedHeaders = defaultCORSHeaders
+	}
+	return p
+}
+
+// newCORSMiddleware применяет admin к /api/admin и public ко всем остальным
+// маршрутам. Запросы без заголовка Origin (не из браузера) пропускаются как есть.
+// Для административной политики "*" не допускается: разрешены только перечисленные origin
+func newCORSMiddleware(public, admin CORSPolicy) gin.HandlerFunc {
+	public = public.withDefaults()
+	admin = admin.withDefaults()
+	if slices.Contains(admin.AllowedOrigins, "*") {
+		log.Printf("⚠️ CORS: \"*\" не допускается для %s и игнорируется", adminPathPrefix)
+		admin.AllowedOrigins = slices.DeleteFunc(slices.Clone(admin.AllowedOrigins), func(o string) bool { return o == "*" })
+	}
+
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+
+		policy := public
+		if strings.HasPrefix(c.Request.URL.Path, adminPathPrefix) {
+			policy = admin
+		}
+
+		requestedMethod := c.GetHeader("Access-Control-Request-Method")
+		preflight := c.Request.Method == http.MethodOptions && requestedMethod != ""
+
+		c.Header("Vary", "Origin")
+		if !policy.allowsOrigin(origin) {
+			if preflight {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			// Без CORS-заголовков браузер не отдаст ответ странице
+			c.Next()
+			return
+		}
+
+		if preflight && (!policy.allowsMethod(requestedMethod) ||
+			!policy.allowsHeaders(c.GetHeader("Access-Control-Request-Headers"))) {
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+
+		// С credentials браузер не принимает "*", поэтому origin возвращается явно
+		allowOrigin := origin
+		if slices.Contains(policy.AllowedOrigins, "*") && !policy.AllowCredentials {
+			allowOrigin = "*"
+		}
+		c.Header("Access-Control-Allow-Origin", allowOrigin)
+		if policy.AllowCredentials {
+			c.Header("Access-Control-Allow-Credentials", "true")
+		}
+
+		if preflight {
+			c.Header("Access-Control-Allow-Methods", strings.Join(policy.AllowedMethods, ", "))
+			c.Header("Access-Control-Allow-Headers", strings.Join(policy.AllowedHeaders, ", "))
+			if policy.MaxAge > 0 {
+				c.Header("Access-Control-Max-Age", strconv.Itoa(int(policy.MaxAge/time.Second)))
+			}
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// newCORSTestRouter создаёт роутер с CORS-middleware и публичным и административным маршрутами
+func newCORSTestRouter(public, admin CORSPolicy) *gin.Engine {
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+	router.Use(newCORSMiddleware(public, admin))
+	router.GET("/api/stats", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.GET("/api/admin/users", func(c *gin.Context) { c.Status(http.StatusOK) })
+	return router
+}
+
+func corsRequest(router *gin.Engine, method, path, origin string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	req.Header.Set("Origin", origin)
+	if method == http.MethodOptions {
+		req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+		req.Header.Set("Access-Control-Request-Headers", "Authorization")
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestCORS_AllowedOrigin(t *testing.T) {
+	router := newCORSTestRouter(
+		CORSPolicy{AllowedOrigins: []string{"*"}},
+		CORSPolicy{AllowedOrigins: []string{"https://admin.example.com"}, AllowCredentials: true},
+	)
+
+	rec := corsRequest(router, http.MethodOptions, "/api/admin/users", "https://admin.example.com")
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "https://admin.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", rec.Header().Get("Access-Control-Allow-Credentials"))
+	assert.Contains(t, rec.Header().Get("Access-Control-Allow-Headers"), "Authorization")
+
+	rec = corsRequest(router, http.MethodGet, "/api/stats", "https://fan-site.example.org")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "*", rec.Header().Get("Access-Control-Allow-Origin"), "публичные эндпоинты открыты явно")
+}
+
+func TestCORS_DisallowedOrigin(t *testing.T) {
+	router := newCORSTestRouter(
+		CORSPolicy{AllowedOrigins: []string{"*"}},
+		CORSPolicy{AllowedOrigins: []string{"https://admin.example.com", "*"}},
+	)
+
+	// "*" для /api/admin игнорируется: чужой сайт не проходит preflight
+	rec := corsRequest(router, http.MethodOptions, "/api/admin/users", "https://evil.example.net")
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+
+	rec = corsRequest(router, http.MethodGet, "/api/admin/users", "https://evil.example.net")
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"), "ответ не открывается чужому сайту")
+
+	// Политика по умолчанию запрещает кросс-доменные запросы
+	defaults := newCORSTestRouter(CORSPolicy{}, CORSPolicy{})
+	rec = corsRequest(defaults, http.MethodOptions, "/api/stats", "https://fan-site.example.org")
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	// Запросы не из браузера (без Origin) не затрагиваются
+	req := httptest.NewRequest(http.MethodGet, "/api/stats", nil)
+	rec = httptest.NewRecorder()
+	defaults.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
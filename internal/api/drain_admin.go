@@ -0,0 +1,113 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/network"
+	"github.com/gin-gonic/gin"
+)
+
+// DrainController выводит игровой сервер из работы перед остановкой
+type DrainController interface {
+	StartDrain(timeout time.Duration) network.DrainStatus
+	StopDrain() network.DrainStatus
+	DrainStatus() network.DrainStatus
+}
+
+// DrainRequest - тело POST /api/admin/drain
+type DrainRequest struct {
+	TimeoutSeconds int `json:"timeout_seconds"` // Крайний срок вывода (0 - ждать всех игроков)
+}
+
+// drainAdmin обслуживает /api/admin/drain
+type drainAdmin struct {
+	mu         sync.Mutex
+	controller DrainController
+}
+
+// SetDrainController подключает /api/admin/drain к игровому серверу
+func (rs *RestServer) SetDrainController(controller DrainController) {
+	rs.drain.mu.Lock()
+	defer rs.drain.mu.Unlock()
+	rs.drain.controller = controller
+}
+
+func (rs *RestServer) drainController(c *gin.Context) (DrainController, bool) {
+	rs.drain.mu.Lock()
+	controller := rs.drain.controller
+	rs.drain.mu.Unlock()
+
+	if controller == nil {
+		c.JSON(http.StatusServiceUnavailable, GenericResponse{
+			Success: false,
+			Message: "Вывод сервера из работы недоступен",
+		})
+		return nil, false
+	}
+	return controller, true
+}
+
+// handleStartDrain включает режим вывода из работы (только для админов)
+func (rs *RestServer) handleStartDrain(c *gin.Context) {
+	controller, ok := rs.drainController(c)
+	if !ok {
+		return
+	}
+
+	var req DrainRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, GenericResponse{
+			Success: false,
+			Message: "Неверный формат запроса: " + err.Error(),
+		})
+		return
+	}
+	if req.TimeoutSeconds < 0 {
+		c.JSON(http.StatusBadRequest, GenericResponse{
+			Success: false,
+			Message: "timeout_seconds не может быть отрицательным",
+		})
+		return
+	}
+
+	status := controller.StartDrain(time.Duration(req.TimeoutSeconds) * time.Second)
+	log.Printf("🚧 Вывод сервера из работы запущен через API (осталось игроков: %d)", status.RemainingPlayers)
+	c.JSON(http.StatusOK, GenericResponse{
+		Success: true,
+		Message: "Новые входы отклоняются",
+		Data:    status,
+	})
+}
+
+// handleDrainStatus возвращает ход вывода из работы (только для админов)
+func (rs *RestServer) handleDrainStatus(c *gin.Context) {
+	controller, ok := rs.drainController(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, GenericResponse{
+		Success: true,
+		Message: "Состояние вывода из работы",
+		Data:    controller.DrainStatus(),
+	})
+}
+
+// handleStopDrain отменяет вывод из работы (только для админов)
+func (rs *RestServer) handleStopDrain(c *gin.Context) {
+	controller, ok := rs.drainController(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, GenericResponse{
+		Success: true,
+		Message: "Сервер снова принимает игроков",
+		Data:    controller.StopDrain(),
+	})
+}
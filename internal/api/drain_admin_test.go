@@ -0,0 +1,46 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/annel0/mmo-game/internal/network"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decodeDrainStatus(t *testing.T, body []byte) network.DrainStatus {
+	t.Helper()
+
+	var resp struct {
+		Data network.DrainStatus `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(body, &resp))
+	return resp.Data
+}
+
+func TestDrainAdmin_StartReportAndStop(t *testing.T) {
+	rs, handler, _ := newRuntimeTestHandler(t)
+	rs.SetDrainController(handler)
+	t.Cleanup(func() { rs.SetDrainController(nil) })
+
+	rec := adminRequest(t, rs, http.MethodPost, "/api/admin/drain", `{"timeout_seconds": 300}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	status := decodeDrainStatus(t, rec.Body.Bytes())
+	assert.True(t, status.Draining)
+	assert.NotNil(t, status.Deadline)
+	assert.Equal(t, 0, status.RemainingPlayers)
+	assert.True(t, status.SafeToShutdown)
+
+	rec = adminRequest(t, rs, http.MethodGet, "/api/admin/drain", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.True(t, decodeDrainStatus(t, rec.Body.Bytes()).Draining)
+
+	rec = adminRequest(t, rs, http.MethodDelete, "/api/admin/drain", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.False(t, handler.DrainStatus().Draining)
+
+	rec = adminRequest(t, rs, http.MethodPost, "/api/admin/drain", `{"timeout_seconds": -1}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
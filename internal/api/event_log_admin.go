@@ -0,0 +1,185 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/api/replay"
+	replaypb "github.com/annel0/mmo-game/internal/protocol/replay"
+	"github.com/gin-gonic/gin"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// DefaultEventLogPageSize - размер страницы /api/admin/events без параметра limit
+	DefaultEventLogPageSize = 100
+	// MaxEventLogPageSize - наибольший размер страницы /api/admin/events
+	MaxEventLogPageSize = 1000
+	// DefaultEventLogRetention - сколько узел хранит события для /api/admin/events
+	DefaultEventLogRetention = time.Hour
+)
+
+// eventLogAdmin обслуживает /api/admin/events
+type eventLogAdmin struct {
+	mu     sync.Mutex
+	source replay.EventStreamer
+}
+
+// eventTypeInfoSource - источник журнала, отдающий сводку по типам событий
+// (replay.ReplayService)
+type eventTypeInfoSource interface {
+	GetEventTypeInfo(ctx context.Context) ([]replay.EventTypeInfo, error)
+}
+
+// SetEventLogSource подключает /api/admin/events к сервису воспроизведения событий
+func (rs *RestServer) SetEventLogSource(source replay.EventStreamer) {
+	rs.eventLog.mu.Lock()
+	defer rs.eventLog.mu.Unlock()
+	rs.eventLog.source = source
+}
+
+// eventLogRequest собирает запрос Replay из параметров:
+// type (через запятую или повторяясь), region, player, from, to (RFC3339),
+// order (asc, desc), limit, cursor
+func eventLogRequest(c *gin.Context) (*replaypb.ReplayRequest, string) {
+	req := &replaypb.ReplayRequest{
+		Cursor:    c.Query("cursor"),
+		Limit:     DefaultEventLogPageSize,
+		SortOrder: replaypb.ReplayRequest_SORT_ORDER_ASC,
+	}
+
+	for _, value := range c.QueryArray("type") {
+		for _, t := range strings.Split(value, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				req.EventTypes = append(req.EventTypes, t)
+			}
+		}
+	}
+	if region := c.Query("region"); region != "" {
+		req.RegionIds = []string{region}
+	}
+	if player := c.Query("player"); player != "" {
+		req.PlayerIds = []string{player}
+	}
+
+	for name, target := range map[string]**timestamppb.Timestamp{"from": &req.StartTime, "to": &req.EndTime} {
+		value := c.Query(name)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, "Параметр " + name + " должен быть временем в формате RFC3339"
+		}
+		*target = timestamppb.New(t)
+	}
+
+	switch strings.ToLower(c.Query("order")) {
+	case "", "asc":
+	case "desc":
+		req.SortOrder = replaypb.ReplayRequest_SORT_ORDER_DESC
+	default:
+		return nil, "Параметр order должен быть asc или desc"
+	}
+
+	if value := c.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > MaxEventLogPageSize {
+			return nil, "Параметр limit должен быть числом от 1 до " + strconv.Itoa(MaxEventLogPageSize)
+		}
+		req.Limit = int32(limit)
+	}
+
+	return req, ""
+}
+
+// handleQueryEvents отдаёт страницу журнала событий сервера (только для админов).
+// Использует тот же запрос Replay, что и gRPC-сервис, поэтому фильтры совпадают
+func (rs *RestServer) handleQueryEvents(c *gin.Context) {
+	source := rs.eventLogSource(c)
+	if source == nil {
+		return
+	}
+
+	req, problem := eventLogRequest(c)
+	if problem != "" {
+		c.JSON(http.StatusBadRequest, GenericResponse{Success: false, Message: problem})
+		return
+	}
+
+	page, err := replay.QueryEvents(c.Request.Context(), source, req)
+	if errors.Is(err, replay.ErrInvalidQuery) {
+		c.JSON(http.StatusBadRequest, GenericResponse{
+			Success: false,
+			Message: "Некорректный запрос: " + err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadGateway, GenericResponse{
+			Success: false,
+			Message: "Не удалось получить события: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, GenericResponse{
+		Success: true,
+		Message: "Журнал событий",
+		Data:    page,
+	})
+}
+
+// handleEventTypes отдаёт сводку по типам событий журнала: число событий,
+// первое и последнее время, регионы (только для админов). Хранилище журнала
+// ведёт каталог типов, поэтому события не просматриваются
+func (rs *RestServer) handleEventTypes(c *gin.Context) {
+	source := rs.eventLogSource(c)
+	if source == nil {
+		return
+	}
+	index, ok := source.(eventTypeInfoSource)
+	if !ok {
+		c.JSON(http.StatusNotImplemented, GenericResponse{
+			Success: false,
+			Message: "Журнал событий не ведёт сводку по типам",
+		})
+		return
+	}
+
+	infos, err := index.GetEventTypeInfo(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusBadGateway, GenericResponse{
+			Success: false,
+			Message: "Не удалось получить типы событий: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, GenericResponse{
+		Success: true,
+		Message: "Типы событий",
+		Data:    infos,
+	})
+}
+
+// eventLogSource возвращает подключённый журнал событий; если его нет,
+// отвечает 503 и возвращает nil
+func (rs *RestServer) eventLogSource(c *gin.Context) replay.EventStreamer {
+	rs.eventLog.mu.Lock()
+	source := rs.eventLog.source
+	rs.eventLog.mu.Unlock()
+
+	if source == nil {
+		c.JSON(http.StatusServiceUnavailable, GenericResponse{
+			Success: false,
+			Message: "Журнал событий недоступен",
+		})
+	}
+	return source
+}
@@ -0,0 +1,207 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/api/replay"
+	"github.com/annel0/mmo-game/internal/auth"
+	"github.com/annel0/mmo-game/internal/protocol/events"
+	replaypb "github.com/annel0/mmo-game/internal/protocol/replay"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// eventLogStore - хранилище событий в памяти с фильтрами EventQuery
+type eventLogStore struct {
+	envelopes []*replay.EventEnvelope
+}
+
+func (s *eventLogStore) QueryEvents(ctx context.Context, query replay.EventQuery) ([]*replay.EventEnvelope, error) {
+	var result []*replay.EventEnvelope
+	for _, envelope := range s.envelopes {
+		if len(query.EventTypes) > 0 && !slices.Contains(query.EventTypes, envelope.EventType) {
+			continue
+		}
+		if query.Region != "" && envelope.RegionID != query.Region {
+			continue
+		}
+		if query.PlayerID != 0 && envelope.Metadata["player_id"] != float64(query.PlayerID) {
+			continue
+		}
+		if query.StartTime != nil && envelope.Timestamp.Before(*query.StartTime) {
+			continue
+		}
+		if query.EndTime != nil && envelope.Timestamp.After(*query.EndTime) {
+			continue
+		}
+		result = append(result, envelope)
+	}
+	return result, nil
+}
+
+func (s *eventLogStore) GetEventStats(ctx context.Context, query replay.EventQuery) (*replay.EventStats, error) {
+	return &replay.EventStats{}, nil
+}
+
+func (s *eventLogStore) GetEventTypes(ctx context.Context) ([]string, error) {
+	return nil, nil
+}
+
+// newEventLogService - сервис воспроизведения с событиями блоков, чата и мира в двух регионах
+func newEventLogService() *replay.ReplayService {
+	base := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	store := &eventLogStore{}
+	for i := range 12 {
+		envelope := &replay.EventEnvelope{
+			EventType: []string{"block", "chat", "world"}[i%3],
+			RegionID:  []string{"eu-west", "us-east"}[i%2],
+			Timestamp: base.Add(time.Duration(11-i) * time.Minute),
+			Metadata:  map[string]interface{}{"player_id": float64(100 + i%4), "seq": float64(i)},
+		}
+		store.envelopes = append(store.envelopes, envelope)
+	}
+	return replay.NewReplayService(store)
+}
+
+// eventLogPage - ответ /api/admin/events
+type eventLogPage struct {
+	Success bool             `json:"success"`
+	Data    replay.EventPage `json:"data"`
+}
+
+func getEventLogPage(t *testing.T, rs *RestServer, query url.Values) eventLogPage {
+	t.Helper()
+	rec := adminRequest(t, rs, http.MethodGet, "/api/admin/events?"+query.Encode(), "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var page eventLogPage
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+	require.True(t, page.Success)
+	return page
+}
+
+func TestEventLogAdmin_MatchesReplayQuery(t *testing.T) {
+	rs := testRestServer()
+
+	rec := adminRequest(t, rs, http.MethodGet, "/api/admin/events", "")
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code, "без сервиса воспроизведения эндпоинт недоступен")
+
+	service := newEventLogService()
+	rs.SetEventLogSource(service)
+	t.Cleanup(func() { rs.SetEventLogSource(nil) })
+
+	from := time.Date(2024, 5, 1, 10, 1, 0, 0, time.UTC)
+	expected, err := replay.QueryEvents(context.Background(), service, &replaypb.ReplayRequest{
+		EventTypes: []string{"block", "chat"},
+		RegionIds:  []string{"eu-west"},
+		StartTime:  timestamppb.New(from),
+		SortOrder:  replaypb.ReplayRequest_SORT_ORDER_ASC,
+	})
+	require.NoError(t, err)
+	require.Len(t, expected.Events, 4)
+
+	// Листаем REST-страницами по 2 события тем же фильтром
+	query := url.Values{
+		"type":   {"block,chat"},
+		"region": {"eu-west"},
+		"from":   {from.Format(time.RFC3339)},
+		"limit":  {"2"},
+	}
+	var got []events.Event
+	for pages := 0; ; pages++ {
+		require.Less(t, pages, 3, "курсор должен заканчиваться")
+		page := getEventLogPage(t, rs, query)
+		got = append(got, page.Data.Events...)
+		if page.Data.NextCursor == "" {
+			break
+		}
+		query.Set("cursor", page.Data.NextCursor)
+	}
+
+	assert.Equal(t, expected.Events, got)
+	for i := 1; i < len(got); i++ {
+		assert.LessOrEqual(t, got[i-1].Timestamp, got[i].Timestamp, "события по возрастанию времени")
+	}
+
+	// Фильтр по игроку и обратный порядок
+	expected, err = replay.QueryEvents(context.Background(), service, &replaypb.ReplayRequest{
+		PlayerIds: []string{"101"},
+		SortOrder: replaypb.ReplayRequest_SORT_ORDER_DESC,
+	})
+	require.NoError(t, err)
+	page := getEventLogPage(t, rs, url.Values{"player": {"101"}, "order": {"desc"}})
+	assert.Equal(t, expected.Events, page.Data.Events)
+	assert.Len(t, page.Data.Events, 3)
+}
+
+func TestEventLogAdmin_RejectsBadQueries(t *testing.T) {
+	rs := testRestServer()
+	rs.SetEventLogSource(newEventLogService())
+	t.Cleanup(func() { rs.SetEventLogSource(nil) })
+
+	for _, query := range []string{
+		"from=yesterday",
+		"limit=0",
+		"limit=5000",
+		"order=random",
+		"player=abc",
+		"cursor=%21%21",
+		"from=2024-05-02T00:00:00Z&to=2024-05-01T00:00:00Z",
+	} {
+		rec := adminRequest(t, rs, http.MethodGet, "/api/admin/events?"+query, "")
+		assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+	}
+}
+
+func TestEventLogAdmin_RequiresAdmin(t *testing.T) {
+	rs := testRestServer()
+	rs.SetEventLogSource(newEventLogService())
+	t.Cleanup(func() { rs.SetEventLogSource(nil) })
+
+	token, err := auth.GenerateJWT(&auth.User{ID: 7, Username: "player"})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/events", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	rs.router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	rec = httptest.NewRecorder()
+	rs.router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/admin/events", nil))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestEventLogAdmin_EventTypes(t *testing.T) {
+	rs := testRestServer()
+	rec := adminRequest(t, rs, http.MethodGet, "/api/admin/events/types", "")
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	// Сводка берётся из каталога хранилища журнала, как на сервере
+	store := replay.NewMemoryEventStore(0)
+	base := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	store.Ingest(&replay.EventEnvelope{EventType: "block", RegionID: "eu-west", Timestamp: base})
+	store.Ingest(&replay.EventEnvelope{EventType: "block", RegionID: "us-east", Timestamp: base.Add(time.Minute)})
+	store.Ingest(&replay.EventEnvelope{EventType: "chat", RegionID: "eu-west", Timestamp: base.Add(2 * time.Minute)})
+	rs.SetEventLogSource(replay.NewReplayService(store))
+	t.Cleanup(func() { rs.SetEventLogSource(nil) })
+
+	rec = adminRequest(t, rs, http.MethodGet, "/api/admin/events/types", "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var response struct {
+		Data []replay.EventTypeInfo `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, []replay.EventTypeInfo{
+		{EventType: "block", Count: 2, FirstSeen: base, LastSeen: base.Add(time.Minute), Regions: []string{"eu-west", "us-east"}},
+		{EventType: "chat", Count: 1, FirstSeen: base.Add(2 * time.Minute), LastSeen: base.Add(2 * time.Minute), Regions: []string{"eu-west"}},
+	}, response.Data)
+}
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"sync"
+
+	"github.com/annel0/mmo-game/internal/network"
+	"github.com/gin-gonic/gin"
+)
+
+// GameModeController переключает режим игры игроков в сети
+type GameModeController interface {
+	SetPlayerGameMode(username string, mode network.GameMode) error
+}
+
+// GameModeRequest - тело POST /api/admin/gamemode
+type GameModeRequest struct {
+	User string `json:"user" binding:"required"` // Имя игрока в сети
+	Mode string `json:"mode" binding:"required"` // survival, creative или adventure
+}
+
+// gameModeAdmin обслуживает /api/admin/gamemode
+type gameModeAdmin struct {
+	mu         sync.Mutex
+	controller GameModeController
+}
+
+// SetGameModeController подключает /api/admin/gamemode к игровому серверу
+func (rs *RestServer) SetGameModeController(controller GameModeController) {
+	rs.gameMode.mu.Lock()
+	defer rs.gameMode.mu.Unlock()
+	rs.gameMode.controller = controller
+}
+
+// handleGameMode переключает режим игры игрока в сети (только для админов).
+// Режим действует до конца сессии; новые сессии получают режим по умолчанию
+func (rs *RestServer) handleGameMode(c *gin.Context) {
+	rs.gameMode.mu.Lock()
+	controller := rs.gameMode.controller
+	rs.gameMode.mu.Unlock()
+
+	if controller == nil {
+		c.JSON(http.StatusServiceUnavailable, GenericResponse{
+			Success: false,
+			Message: "Смена режима игры недоступна",
+		})
+		return
+	}
+
+	var req GameModeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, GenericResponse{
+			Success: false,
+			Message: "Неверный формат запроса: " + err.Error(),
+		})
+		return
+	}
+
+	mode, err := network.ParseGameMode(req.Mode)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, GenericResponse{Success: false, Message: err.Error()})
+		return
+	}
+
+	err = controller.SetPlayerGameMode(req.User, mode)
+	switch {
+	case errors.Is(err, network.ErrPlayerOffline):
+		c.JSON(http.StatusNotFound, GenericResponse{Success: false, Message: err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, GenericResponse{Success: false, Message: err.Error()})
+		return
+	}
+
+	log.Printf("🎮 Режим игры %s через API: %s", req.User, mode)
+	c.JSON(http.StatusOK, GenericResponse{
+		Success: true,
+		Message: "Режим игры изменён",
+		Data:    gin.H{"user": req.User, "mode": mode},
+	})
+}
@@ -0,0 +1,43 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/annel0/mmo-game/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGameModeAdmin_AdventureForbidsBuilding(t *testing.T) {
+	h, _, player := newPlayerAdminHarness(t)
+	rs := testRestServer()
+	rs.SetGameModeController(h.Handler)
+	t.Cleanup(func() { rs.SetGameModeController(nil) })
+
+	rec := adminRequest(t, rs, http.MethodPost, "/api/admin/gamemode", `{"user": "bob", "mode": "adventure"}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	player.PlaceBlock(1, 1, 1)
+	player.ExpectError(protocol.ErrorCode_FORBIDDEN)
+}
+
+func TestGameModeAdmin_RejectsInvalidRequests(t *testing.T) {
+	h, _, _ := newPlayerAdminHarness(t)
+	rs := testRestServer()
+
+	rec := adminRequest(t, rs, http.MethodPost, "/api/admin/gamemode", `{"user": "bob", "mode": "creative"}`)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code, "сервер не подключён")
+
+	rs.SetGameModeController(h.Handler)
+	t.Cleanup(func() { rs.SetGameModeController(nil) })
+
+	rec = adminRequest(t, rs, http.MethodPost, "/api/admin/gamemode", `{"user": "bob", "mode": "hardcore"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code, "неизвестный режим")
+
+	rec = adminRequest(t, rs, http.MethodPost, "/api/admin/gamemode", `{"user": "nobody", "mode": "creative"}`)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = adminRequest(t, rs, http.MethodPost, "/api/admin/gamemode", `{"user": "bob"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code, "без mode")
+}
@@ -0,0 +1,174 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// healthCheckTimeout - время на проверку одной подсистемы
+const healthCheckTimeout = 2 * time.Second
+
+// HealthCheck проверяет подсистему (EventBus, БД, мир); nil - подсистема работает
+type HealthCheck func(ctx context.Context) error
+
+// SubsystemHealth - результат проверки подсистемы в ответе /health
+type SubsystemHealth struct {
+	Status    string `json:"status"` // "ok" или "fail"
+	Error     string `json:"error,omitempty"`
+	LatencyMs int64  `json:"latency_ms"`
+}
+
+// healthChecks - зарегистрированные проверки готовности
+type healthChecks struct {
+	mu       sync.RWMutex
+	checks   map[string]HealthCheck
+	degraded []DegradedComponent // Компоненты, запущенные с заменой (см. StartupReport)
+}
+
+// setDegraded задаёт компоненты, работающие с заменой, для ответа /health
+func (rs *RestServer) setDegraded(components []DegradedComponent) {
+	rs.health.mu.Lock()
+	rs.health.degraded = append([]DegradedComponent(nil), components...)
+	rs.health.mu.Unlock()
+}
+
+// RegisterHealthCheck добавляет проверку подсистемы в /health.
+// Повторная регистрация заменяет проверку, nil удаляет её
+func (rs *RestServer) RegisterHealthCheck(name string, check HealthCheck) {
+	rs.health.mu.Lock()
+	defer rs.health.mu.Unlock()
+
+	if check == nil {
+		delete(rs.health.checks, name)
+		return
+	}
+	if rs.health.checks == nil {
+		rs.health.checks = make(map[string]HealthCheck)
+	}
+	rs.health.checks[name] = check
+}
+
+// runHealthChecks параллельно выполняет все проверки с ограничением по времени
+func (rs *RestServer) runHealthChecks(ctx context.Context) (map[string]SubsystemHealth, bool) {
+	rs.health.mu.RLock()
+	names := make([]string, 0, len(rs.health.checks))
+	checks := make([]HealthCheck, 0, len(rs.health.checks))
+	for name, check := range rs.health.checks {
+		names = append(names, name)
+		checks = append(checks, check)
+	}
+	rs.health.mu.RUnlock()
+
+	results := make([]SubsystemHealth, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check HealthCheck) {
+			defer wg.Done()
+			results[i] = runHealthCheck(ctx, check)
+		}(i, check)
+	}
+	wg.Wait()
+
+	healthy := true
+	report := make(map[string]SubsystemHealth, len(names))
+	for i, name := range names {
+		report[name] = results[i]
+		healthy = healthy && results[i].Status == "ok"
+	}
+	return report, healthy
+}
+
+// runHealthCheck выполняет проверку; зависшая проверка считается неудачной по таймауту
+func runHealthCheck(ctx context.Context, check HealthCheck) SubsystemHealth {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() { done <- check(ctx) }()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	result := SubsystemHealth{Status: "ok", LatencyMs: time.Since(start).Milliseconds()}
+	if err != nil {
+		result.Status = "fail"
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// handleHealth - проверка готовности для балансировщика: 503, если хотя бы
+// одна подсистема недоступна, с результатами по каждой подсистеме. Сервер с
+// компонентами на замене (например, память вместо MariaDB) обслуживает
+// запросы, поэтому отвечает 200 со статусом "degraded"
+func (rs *RestServer) handleHealth(c *gin.Context) {
+	report, healthy := rs.runHealthChecks(c.Request.Context())
+
+	rs.health.mu.RLock()
+	degraded := rs.health.degraded
+	rs.health.mu.RUnlock()
+
+	status, code := "ok", http.StatusOK
+	switch {
+	case !healthy:
+		status, code = "unavailable", http.StatusServiceUnavailable
+	case len(degraded) > 0:
+		status = "degraded"
+	}
+
+	body := gin.H{
+		"status": status,
+		"time":   time.Now().Unix(),
+		"checks": report,
+	}
+	if len(degraded) > 0 {
+		body["degraded"] = degraded
+	}
+	c.JSON(code, body)
+}
+
+// pinger - хранилище, умеющее проверять доступность БД
+type pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// registerDatabaseHealth добавляет проверку "database", если хотя бы один
+// репозиторий работает с БД (in-memory репозитории не проверяются)
+func registerDatabaseHealth(rs *RestServer, repos ...interface{}) {
+	var dbs []pinger
+	for _, repo := range repos {
+		if p, ok := repo.(pinger); ok {
+			dbs = append(dbs, p)
+		}
+	}
+	if len(dbs) == 0 {
+		return
+	}
+
+	rs.RegisterHealthCheck("database", func(ctx context.Context) error {
+		for _, db := range dbs {
+			if err := db.Ping(ctx); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// handleLivez - проверка живости процесса: не обращается к зависимостям
+func (rs *RestServer) handleLivez(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status": "ok",
+		"time":   time.Now().Unix(),
+	})
+}
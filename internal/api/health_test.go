@@ -0,0 +1,67 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func getHealth(t *testing.T, rs *RestServer, path string) (int, map[string]SubsystemHealth) {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	rs.router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+	var body struct {
+		Status string                     `json:"status"`
+		Checks map[string]SubsystemHealth `json:"checks"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	return rec.Code, body.Checks
+}
+
+func TestHealth_DownDependencyReturns503(t *testing.T) {
+	rs := testRestServer()
+	dbDown := errors.New("dial tcp 127.0.0.1:3306: connection refused")
+	rs.RegisterHealthCheck("world", func(ctx context.Context) error { return nil })
+	rs.RegisterHealthCheck("database", func(ctx context.Context) error { return dbDown })
+	t.Cleanup(func() {
+		rs.RegisterHealthCheck("world", nil)
+		rs.RegisterHealthCheck("database", nil)
+	})
+
+	code, checks := getHealth(t, rs, "/health")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "ok", checks["world"].Status)
+	assert.Equal(t, "fail", checks["database"].Status)
+	assert.Equal(t, dbDown.Error(), checks["database"].Error)
+
+	// Живость процесса не зависит от подсистем
+	code, _ = getHealth(t, rs, "/livez")
+	assert.Equal(t, http.StatusOK, code)
+
+	// После восстановления БД сервер снова готов
+	rs.RegisterHealthCheck("database", func(ctx context.Context) error { return nil })
+	code, checks = getHealth(t, rs, "/health")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Len(t, checks, 2)
+}
+
+func TestHealth_HangingCheckTimesOut(t *testing.T) {
+	rs := testRestServer()
+	rs.RegisterHealthCheck("eventbus", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	t.Cleanup(func() { rs.RegisterHealthCheck("eventbus", nil) })
+
+	code, checks := getHealth(t, rs, "/health")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Contains(t, checks["eventbus"].Error, "deadline exceeded")
+}
@@ -4,21 +4,23 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"time"
 
 	"github.com/annel0/mmo-game/internal/auth"
 	"github.com/annel0/mmo-game/internal/storage"
+	"github.com/annel0/mmo-game/internal/tlsreload"
 	"github.com/annel0/mmo-game/internal/world/entity"
 )
 
 // ServerIntegration управляет интеграцией REST API с игровым сервером
 type ServerIntegration struct {
 	restServer    *RestServer
-	userRepo      auth.UserRepository
-	positionRepo  storage.PositionRepo
+	players       *storage.PlayerStore // Общие с игровым сервером учётные записи и данные игроков
 	entityManager *entity.EntityManager
 	httpServer    *http.Server
+	startup       StartupReport
 	ctx           context.Context
 	cancel        context.CancelFunc
 }
@@ -41,6 +43,16 @@ type IntegrationConfig struct {
 
 	// PositionStorageConfig конфигурация хранилища позиций
 	PositionStorage PositionStorageConfig
+
+	// CORS для публичных эндпоинтов и для /api/admin
+	CORS      CORSPolicy
+	AdminCORS CORSPolicy
+
+	// Проверка учётных данных при входе (nil - пароль из репозитория пользователей)
+	AuthProvider auth.AuthProvider
+
+	// TLS для REST API (пустой CertFile - открытым текстом)
+	TLS tlsreload.Config
 }
 
 // PositionStorageConfig содержит настройки для хранилища позиций игроков
@@ -57,69 +69,42 @@ type PositionStorageConfig struct {
 
 // NewServerIntegration создает новую интеграцию REST API с игровым сервером
 func NewServerIntegration(config IntegrationConfig) (*ServerIntegration, error) {
-	ctx, cancel := context.WithCancel(context.Background())
-
-	// Инициализируем репозиторий пользователей
-	var userRepo auth.UserRepository
-	var err error
-
-	if config.UseMariaDB {
-		// Используем MariaDB
-		userRepo, err = auth.NewMariaUserRepo(config.MariaConfig)
-		if err != nil {
-			cancel()
-			return nil, fmt.Errorf("не удалось подключиться к MariaDB: %w", err)
-		}
-		log.Println("✅ MariaDB подключена успешно")
-	} else {
-		// Используем in-memory репозиторий для разработки/тестирования
-		userRepo, err = auth.NewMemoryUserRepo()
-		if err != nil {
-			cancel()
-			return nil, fmt.Errorf("не удалось создать in-memory репозиторий: %w", err)
-		}
-		log.Println("⚠️  Используется in-memory репозиторий пользователей")
+	// Сертификаты загружаются до подключения к базам: ошибка в путях видна сразу
+	certs, err := tlsreload.New(config.TLS)
+	if err != nil {
+		return nil, err
+	}
+	if certs == nil {
+		log.Println("⚠️  REST API работает без TLS (только для локальной отладки)")
 	}
 
-	// Инициализируем репозиторий позиций
-	var positionRepo storage.PositionRepo
-
-	switch config.PositionStorage.Type {
-	case "mariadb":
-		// Пытаемся подключиться к MariaDB
-		mariaRepo, err := storage.NewMariaPositionRepo(config.PositionStorage.MariaDBDSN)
-		if err != nil {
-			if config.PositionStorage.FallbackToMemory {
-				log.Printf("⚠️ Не удалось подключиться к MariaDB для позиций, используем память: %v", err)
-				positionRepo = storage.NewMemoryPositionRepo()
-			} else {
-				cancel()
-				return nil, fmt.Errorf("не удалось инициализировать репозиторий позиций MariaDB: %w", err)
-			}
-		} else {
-			positionRepo = mariaRepo
-			log.Println("✅ MariaDB репозиторий позиций подключен успешно")
-		}
-
-	case "memory":
-		fallthrough
-	default:
-		positionRepo = storage.NewMemoryPositionRepo()
-		log.Println("⚠️ Используется in-memory репозиторий позиций (данные не сохраняются)")
+	// Компоненты открываются независимо: недоступная БД с настроенной заменой
+	// не мешает запуску, а попадает в отчёт и в /health
+	players, report, err := openPlayerStore(config)
+	if err != nil {
+		return nil, err
 	}
 
+	ctx, cancel := context.WithCancel(context.Background())
+
 	// Создаем REST сервер
 	restServer := NewRestServer(Config{
 		Port:          config.RestPort,
-		UserRepo:      userRepo,
+		UserRepo:      players.Users,
 		EntityManager: config.EntityManager,
+		CORS:          config.CORS,
+		AdminCORS:     config.AdminCORS,
+		AuthProvider:  config.AuthProvider,
+		TLS:           certs,
 	})
+	registerDatabaseHealth(restServer, players.Users, players.Positions, players.States)
+	restServer.setDegraded(report.Degraded)
 
 	integration := &ServerIntegration{
 		restServer:    restServer,
-		userRepo:      userRepo,
-		positionRepo:  positionRepo,
+		players:       players,
 		entityManager: config.EntityManager,
+		startup:       report,
 		ctx:           ctx,
 		cancel:        cancel,
 	}
@@ -127,24 +112,31 @@ func NewServerIntegration(config IntegrationConfig) (*ServerIntegration, error)
 	return integration, nil
 }
 
-// Start запускает REST API сервер
+// Start запускает REST API сервер. Порт занимается сразу: ошибка (например,
+// порт уже занят) возвращается вызывающему, а не только пишется в журнал
 func (si *ServerIntegration) Start() error {
 	log.Printf("Запуск REST API сервера на порту %s", si.restServer.port)
 
-	// Создаем HTTP сервер для graceful shutdown
-	si.httpServer = &http.Server{
-		Addr:    si.restServer.port,
-		Handler: si.restServer.router,
+	ln, err := net.Listen("tcp", si.restServer.port)
+	if err != nil {
+		return fmt.Errorf("REST API: %w", err)
 	}
 
+	// Создаем HTTP сервер для graceful shutdown
+	si.httpServer = si.restServer.newHTTPServer()
+
 	// Запускаем сервер в отдельной горутине
+	server := si.httpServer
 	go func() {
-		if err := si.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		if err := si.restServer.serve(server, ln); err != nil && err != http.ErrServerClosed {
 			log.Printf("❌ Ошибка REST API сервера: %v", err)
 		}
 	}()
 
-	log.Printf("✅ REST API сервер запущен на http://localhost%s", si.restServer.port)
+	log.Printf("✅ REST API сервер запущен на %s://localhost%s", si.restServer.scheme(), si.restServer.port)
+	for _, degraded := range si.startup.Degraded {
+		log.Printf("⚠️  Деградированный режим: %s заменён (%s)", degraded.Component, degraded.Fallback)
+	}
 	log.Printf("📋 Доступные эндпоинты:")
 	log.Printf("   GET  /health           - Проверка состояния")
 	log.Printf("   POST /api/auth/login   - Вход в систему")
@@ -152,6 +144,10 @@ func (si *ServerIntegration) Start() error {
 	log.Printf("   GET  /api/server       - Информация о сервере (требует JWT)")
 	log.Printf("   POST /api/admin/register - Регистрация пользователя (только админы)")
 	log.Printf("   GET  /api/admin/users  - Список пользователей (только админы)")
+	log.Printf("   GET/PUT /api/admin/runtime - Параметры сервера без перезапуска (только админы)")
+	log.Printf("   POST/GET/DELETE /api/admin/drain - Вывод сервера из работы перед остановкой (только админы)")
+	log.Printf("   GET/PUT /api/admin/world/region - Выгрузка и загрузка снимка области мира (только админы)")
+	log.Printf("   POST /api/admin/world/event - Событие мира для игроков (только админы)")
 	log.Printf("   POST /api/webhook      - Webhook эндпоинт")
 
 	return nil
@@ -173,23 +169,8 @@ func (si *ServerIntegration) Stop() error {
 		}
 	}
 
-	// Закрываем репозиторий пользователей
-	if si.userRepo != nil {
-		if closer, ok := si.userRepo.(interface{ Close() error }); ok {
-			if err := closer.Close(); err != nil {
-				log.Printf("❌ Ошибка при закрытии репозитория пользователей: %v", err)
-			}
-		}
-	}
-
-	// Закрываем репозиторий позиций
-	if si.positionRepo != nil {
-		if closer, ok := si.positionRepo.(interface{ Close() error }); ok {
-			if err := closer.Close(); err != nil {
-				log.Printf("❌ Ошибка при закрытии репозитория позиций: %v", err)
-			}
-		}
-	}
+	// Закрываем репозитории игроков
+	closePlayerStore(si.players)
 
 	// Отменяем контекст
 	si.cancel()
@@ -198,14 +179,35 @@ func (si *ServerIntegration) Stop() error {
 	return nil
 }
 
+// StartupReport возвращает компоненты, запущенные с заменой из-за ошибки
+func (si *ServerIntegration) StartupReport() StartupReport {
+	return si.startup
+}
+
+// ReloadTLS перечитывает сертификаты REST API без перезапуска (SIGHUP).
+// При ошибке продолжает использоваться прежний сертификат
+func (si *ServerIntegration) ReloadTLS() error {
+	return si.restServer.ReloadTLS()
+}
+
+// GetPlayerStore возвращает общие хранилища игроков для подключения к игровому серверу
+func (si *ServerIntegration) GetPlayerStore() *storage.PlayerStore {
+	return si.players
+}
+
 // GetUserRepository возвращает репозиторий пользователей (для использования в игровом сервере)
 func (si *ServerIntegration) GetUserRepository() auth.UserRepository {
-	return si.userRepo
+	return si.players.Users
 }
 
 // GetPositionRepository возвращает репозиторий позиций (для использования в игровом сервере)
 func (si *ServerIntegration) GetPositionRepository() storage.PositionRepo {
-	return si.positionRepo
+	return si.players.Positions
+}
+
+// GetPlayerStateRepository возвращает репозиторий прогресса игроков (уровень, опыт, эффекты)
+func (si *ServerIntegration) GetPlayerStateRepository() storage.PlayerStateRepo {
+	return si.players.States
 }
 
 // GetRestServer возвращает REST сервер (для дополнительной настройки)
@@ -228,7 +230,7 @@ func (si *ServerIntegration) IsHealthy() bool {
 	}
 
 	// Проверяем подключение к БД (если MariaDB)
-	if mariaRepo, ok := si.userRepo.(*auth.MariaUserRepo); ok {
+	if mariaRepo, ok := si.players.Users.(*auth.MariaUserRepo); ok {
 		// Простая проверка - попытка получить статистику
 		_, err := mariaRepo.GetUserStats()
 		return err == nil
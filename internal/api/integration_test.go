@@ -0,0 +1,103 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/annel0/mmo-game/internal/auth"
+	"github.com/annel0/mmo-game/internal/network/testharness"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServerIntegration_RestAndGameShareIdentity(t *testing.T) {
+	integration := testIntegration()
+	players := integration.GetPlayerStore()
+
+	// Второй пользователь получает ID 2 только в общем репозитории: отдельный
+	// in-memory репозиторий игры его бы не знал
+	hash, err := auth.HashPassword("secret-pass")
+	require.NoError(t, err)
+	if _, err := players.Users.CreateUser("alice", hash, false); !errors.Is(err, auth.ErrUserExists) {
+		require.NoError(t, err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/auth/login", strings.NewReader(`{"username": "alice", "password": "secret-pass"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	integration.GetRestServer().router.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var login LoginResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &login))
+	require.True(t, login.Success)
+
+	h := testharness.New(t)
+	h.Handler.SetPlayerStore(players)
+	resp := h.Connect("alice-conn").Auth("alice", "secret-pass")
+
+	gameUserID, valid, _ := auth.ValidateJWT(resp.GetJwtToken())
+	require.True(t, valid)
+	assert.Equal(t, login.UserID, gameUserID)
+	assert.True(t, h.Handler.KickPlayer(login.UserID), "игровая сессия должна принадлежать тому же пользователю")
+}
+
+// tokenProvider - провайдер аутентификации, принимающий один токен
+type tokenProvider struct{ token string }
+
+func (p tokenProvider) Authenticate(_ context.Context, users auth.UserRepository, username, secret string) (*auth.User, error) {
+	if secret != p.token {
+		return nil, auth.ErrInvalidCredentials
+	}
+	return users.GetUserByUsername(username)
+}
+
+func TestLogin_RoutesThroughAuthProvider(t *testing.T) {
+	rs := testRestServer()
+	rs.authProvider = tokenProvider{token: "valid-token"}
+	t.Cleanup(func() { rs.authProvider = auth.LocalProvider{} })
+
+	login := func(password string) *httptest.ResponseRecorder {
+		body := fmt.Sprintf(`{"username": "admin", "password": %q}`, password)
+		req := httptest.NewRequest(http.MethodPost, "/api/auth/login", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		rs.router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, login("ChangeMe123!").Code, "пароль больше не проверяется локально")
+
+	rec := login("valid-token")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var resp LoginResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.True(t, resp.Success)
+	assert.NotEmpty(t, resp.Token)
+}
+
+func TestLogin_LockedAccountGetsTooManyRequests(t *testing.T) {
+	rs := testRestServer()
+	rs.authProvider = auth.NewLockoutProvider(tokenProvider{token: "valid-token"}, auth.NewAccountLockout(auth.LockoutConfig{Threshold: 2}))
+	t.Cleanup(func() { rs.authProvider = auth.LocalProvider{} })
+
+	login := func(password string) *httptest.ResponseRecorder {
+		body := fmt.Sprintf(`{"username": "admin", "password": %q}`, password)
+		req := httptest.NewRequest(http.MethodPost, "/api/auth/login", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		rs.router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, login("wrong-1").Code)
+	assert.Equal(t, http.StatusUnauthorized, login("wrong-2").Code)
+	rec := login("valid-token")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code, "верные данные не принимаются, пока учётная запись заблокирована")
+	assert.Contains(t, rec.Body.String(), "Слишком много неудачных попыток")
+}
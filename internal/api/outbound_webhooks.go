@@ -304,8 +304,12 @@ func (owm *OutboundWebhookManager) GetEventTypes() []string {
 		"player.kicked",
 		"anticheat.violation",
 		"anticheat.ban",
+		"anticheat.bruteforce",
 		"world.saved",
 		"world.load_error",
+		"world.integrity_mismatch",
+		"region.lagging",
+		"region.recovered",
 		"chat.message",
 		"admin.command",
 		"security.alert",
@@ -0,0 +1,118 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/gin-gonic/gin"
+)
+
+// maxRegionSnapshotBody ограничивает размер загружаемого снимка области
+const maxRegionSnapshotBody = 64 << 20
+
+// RegionSnapshotter выгружает и загружает снимки областей мира
+type RegionSnapshotter interface {
+	ExportRegion(topLeft, bottomRight vec.Vec2) ([]byte, error)
+	ImportRegion(data []byte) error
+}
+
+// regionSnapshotAdmin обслуживает /api/admin/world/region
+type regionSnapshotAdmin struct {
+	mu          sync.Mutex
+	snapshotter RegionSnapshotter
+}
+
+// SetRegionSnapshotter подключает /api/admin/world/region к миру игрового сервера
+func (rs *RestServer) SetRegionSnapshotter(snapshotter RegionSnapshotter) {
+	rs.regionSnapshots.mu.Lock()
+	defer rs.regionSnapshots.mu.Unlock()
+	rs.regionSnapshots.snapshotter = snapshotter
+}
+
+func (rs *RestServer) regionSnapshotter(c *gin.Context) (RegionSnapshotter, bool) {
+	rs.regionSnapshots.mu.Lock()
+	snapshotter := rs.regionSnapshots.snapshotter
+	rs.regionSnapshots.mu.Unlock()
+
+	if snapshotter == nil {
+		c.JSON(http.StatusServiceUnavailable, GenericResponse{
+			Success: false,
+			Message: "Снимки областей мира недоступны",
+		})
+		return nil, false
+	}
+	return snapshotter, true
+}
+
+// handleExportRegion отдаёт снимок области x1,y1 - x2,y2 файлом (только для админов)
+func (rs *RestServer) handleExportRegion(c *gin.Context) {
+	snapshotter, ok := rs.regionSnapshotter(c)
+	if !ok {
+		return
+	}
+
+	var coords [4]int
+	for i, name := range []string{"x1", "y1", "x2", "y2"} {
+		value, err := strconv.Atoi(c.Query(name))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, GenericResponse{
+				Success: false,
+				Message: "Параметры x1, y1, x2, y2 должны быть целыми числами",
+			})
+			return
+		}
+		coords[i] = value
+	}
+	topLeft := vec.Vec2{X: coords[0], Y: coords[1]}
+	bottomRight := vec.Vec2{X: coords[2], Y: coords[3]}
+
+	data, err := snapshotter.ExportRegion(topLeft, bottomRight)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, GenericResponse{
+			Success: false,
+			Message: "Не удалось выгрузить область: " + err.Error(),
+		})
+		return
+	}
+
+	log.Printf("📦 Выгружена область мира (%d,%d)-(%d,%d): %d байт", topLeft.X, topLeft.Y, bottomRight.X, bottomRight.Y, len(data))
+	filename := fmt.Sprintf("region_%d_%d_%d_%d.json", topLeft.X, topLeft.Y, bottomRight.X, bottomRight.Y)
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Data(http.StatusOK, "application/json", data)
+}
+
+// handleImportRegion заменяет область мира загруженным снимком (только для админов)
+func (rs *RestServer) handleImportRegion(c *gin.Context) {
+	snapshotter, ok := rs.regionSnapshotter(c)
+	if !ok {
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxRegionSnapshotBody))
+	if err != nil {
+		c.JSON(http.StatusRequestEntityTooLarge, GenericResponse{
+			Success: false,
+			Message: "Снимок слишком большой или не прочитан: " + err.Error(),
+		})
+		return
+	}
+
+	if err := snapshotter.ImportRegion(data); err != nil {
+		c.JSON(http.StatusBadRequest, GenericResponse{
+			Success: false,
+			Message: "Снимок отклонён: " + err.Error(),
+		})
+		return
+	}
+
+	log.Printf("📦 Загружен снимок области мира (%d байт)", len(data))
+	c.JSON(http.StatusOK, GenericResponse{
+		Success: true,
+		Message: "Область мира заменена снимком",
+	})
+}
@@ -0,0 +1,42 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/annel0/mmo-game/internal/world"
+	"github.com/annel0/mmo-game/internal/world/block"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegionSnapshotAdmin_DownloadAndUpload(t *testing.T) {
+	rs := testRestServer()
+
+	rec := adminRequest(t, rs, http.MethodGet, "/api/admin/world/region?x1=0&y1=0&x2=3&y2=3", "")
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code, "без мира эндпоинт недоступен")
+
+	src := world.NewWorldManager(1)
+	pos := vec.Vec2{X: 2, Y: 2}
+	src.SetBlockLayer(pos, world.LayerActive, world.NewBlock(block.StoneBlockID))
+	rs.SetRegionSnapshotter(src)
+	t.Cleanup(func() { rs.SetRegionSnapshotter(nil) })
+
+	rec = adminRequest(t, rs, http.MethodGet, "/api/admin/world/region?x1=0&y1=0&x2=3&y2=3", "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Contains(t, rec.Header().Get("Content-Disposition"), "region_0_0_3_3.json")
+	snapshot := rec.Body.String()
+
+	rec = adminRequest(t, rs, http.MethodGet, "/api/admin/world/region?x1=0&y1=0&x2=abc&y2=3", "")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	dst := world.NewWorldManager(2)
+	rs.SetRegionSnapshotter(dst)
+	rec = adminRequest(t, rs, http.MethodPut, "/api/admin/world/region", snapshot)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, block.StoneBlockID, dst.GetBlockLayer(pos, world.LayerActive).ID)
+
+	rec = adminRequest(t, rs, http.MethodPut, "/api/admin/world/region", `{"version": 99}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
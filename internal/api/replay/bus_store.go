@@ -0,0 +1,73 @@
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	"github.com/annel0/mmo-game/internal/eventbus"
+)
+
+// Ключи метаданных событий шины, по которым фильтрует MemoryEventStore
+const (
+	metaRegion   = "region"
+	metaPlayerID = "player_id"
+)
+
+// EnvelopeFromBus переводит событие EventBus в запись хранилища. Метаданные -
+// поля JSON-полезной нагрузки (если это объект) и метаданные конверта поверх
+// них. Регион берётся из метаданных "region", иначе - регион узла regionID
+func EnvelopeFromBus(ev *eventbus.Envelope, regionID string) *EventEnvelope {
+	metadata := make(map[string]interface{}, len(ev.Metadata))
+	if len(ev.Payload) > 0 {
+		var payload map[string]interface{}
+		if err := json.Unmarshal(ev.Payload, &payload); err == nil {
+			for key, value := range payload {
+				metadata[key] = value
+			}
+		}
+	}
+	for key, value := range ev.Metadata {
+		metadata[key] = value
+	}
+
+	region := regionID
+	if r, ok := ev.Metadata[metaRegion]; ok && r != "" {
+		region = r
+	}
+
+	return &EventEnvelope{
+		EventID:    ev.ID,
+		EventType:  ev.EventType,
+		Timestamp:  ev.Timestamp,
+		RegionID:   region,
+		SourceNode: ev.Source,
+		Metadata:   metadata,
+	}
+}
+
+// IngestFromBus подписывает хранилище на все события шины. Подписка
+// действует до Unsubscribe
+func (s *MemoryEventStore) IngestFromBus(ctx context.Context, bus eventbus.EventBus, regionID string) (eventbus.Subscription, error) {
+	return bus.Subscribe(ctx, eventbus.Filter{}, func(_ context.Context, ev *eventbus.Envelope) {
+		s.Ingest(EnvelopeFromBus(ev, regionID))
+	})
+}
+
+// envelopePlayerID возвращает ID игрока из метаданных записи (0 - нет)
+func envelopePlayerID(env *EventEnvelope) uint64 {
+	switch v := env.Metadata[metaPlayerID].(type) {
+	case uint64:
+		return v
+	case int:
+		return uint64(max(v, 0))
+	case float64:
+		if v > 0 {
+			return uint64(v)
+		}
+	case string:
+		id, _ := strconv.ParseUint(v, 10, 64)
+		return id
+	}
+	return 0
+}
@@ -0,0 +1,54 @@
+package replay
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/eventbus"
+	replaypb "github.com/annel0/mmo-game/internal/protocol/replay"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryEventStore_IngestsBusEvents(t *testing.T) {
+	bus := eventbus.NewLocalBus(eventbus.LocalBusConfig{})
+	store := NewMemoryEventStore(0)
+	sub, err := store.IngestFromBus(context.Background(), bus, "eu-west")
+	require.NoError(t, err)
+	t.Cleanup(sub.Unsubscribe)
+
+	base := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	require.NoError(t, bus.Publish(context.Background(), &eventbus.Envelope{
+		ID:        "audit-1",
+		Timestamp: base,
+		Source:    "game_handler",
+		EventType: "BlockAudit",
+		Payload:   []byte(`{"player_id": 7, "action": "place"}`),
+		Metadata:  map[string]string{"region": "us-east"},
+	}))
+	require.NoError(t, bus.Publish(context.Background(), &eventbus.Envelope{
+		ID:        "block-1",
+		Timestamp: base.Add(time.Second),
+		Source:    "world_manager",
+		EventType: "BlockEvent",
+		Payload:   []byte(`{"EventType": 1}`),
+	}))
+	require.Eventually(t, func() bool {
+		infos, _ := store.EventTypeInfos(context.Background())
+		return len(infos) == 2
+	}, time.Second, 5*time.Millisecond)
+
+	service := NewReplayService(store)
+	page, err := QueryEvents(context.Background(), service, &replaypb.ReplayRequest{PlayerIds: []string{"7"}})
+	require.NoError(t, err)
+	require.Len(t, page.Events, 1)
+	assert.Equal(t, "BlockAudit", string(page.Events[0].Type))
+	assert.Equal(t, "place", page.Events[0].Data["action"])
+
+	// Регион события - из метаданных конверта, иначе регион узла
+	page, err = QueryEvents(context.Background(), service, &replaypb.ReplayRequest{RegionIds: []string{"eu-west"}})
+	require.NoError(t, err)
+	require.Len(t, page.Events, 1)
+	assert.Equal(t, "BlockEvent", string(page.Events[0].Type))
+}
@@ -0,0 +1,202 @@
+package replay
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/annel0/mmo-game/internal/auth"
+	"github.com/annel0/mmo-game/internal/tlsreload"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// AuthMetadataKey - ключ метаданных gRPC с токеном вида "Bearer <jwt>"
+const AuthMetadataKey = "authorization"
+
+// TokenValidator проверяет JWT и возвращает ID игрока и его права.
+// По умолчанию используется auth.ValidateJWT
+type TokenValidator func(token string) (playerID uint64, valid bool, isAdmin bool)
+
+// AuthConfig задаёт проверку доступа к сервису воспроизведения
+type AuthConfig struct {
+	Validator TokenValidator // nil - auth.ValidateJWT
+	// AllowNonAdmin разрешает доступ любому игроку с действительным токеном.
+	// По умолчанию события (включая чат) доступны только администраторам
+	AllowNonAdmin bool
+}
+
+// Caller - проверенный вызывающий, доступен обработчикам через CallerFromContext
+type Caller struct {
+	PlayerID uint64
+	IsAdmin  bool
+}
+
+type callerKey struct{}
+
+// CallerFromContext возвращает вызывающего, прошедшего проверку токена
+func CallerFromContext(ctx context.Context) (Caller, bool) {
+	caller, ok := ctx.Value(callerKey{}).(Caller)
+	return caller, ok
+}
+
+// authenticate извлекает токен из метаданных и проверяет права вызывающего
+func (cfg AuthConfig) authenticate(ctx context.Context) (context.Context, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "missing metadata")
+	}
+
+	values := md.Get(AuthMetadataKey)
+	if len(values) == 0 {
+		return nil, status.Error(codes.Unauthenticated, "missing authorization token")
+	}
+	token, found := strings.CutPrefix(values[0], "Bearer ")
+	if !found || token == "" {
+		return nil, status.Error(codes.Unauthenticated, "authorization must be a bearer token")
+	}
+
+	validate := cfg.Validator
+	if validate == nil {
+		validate = auth.ValidateJWT
+	}
+	playerID, valid, isAdmin := validate(token)
+	if !valid {
+		return nil, status.Error(codes.Unauthenticated, "invalid or expired token")
+	}
+	if !isAdmin && !cfg.AllowNonAdmin {
+		return nil, status.Error(codes.PermissionDenied, "admin privileges required")
+	}
+
+	return context.WithValue(ctx, callerKey{}, Caller{PlayerID: playerID, IsAdmin: isAdmin}), nil
+}
+
+// UnaryAuthInterceptor проверяет токен для обычных RPC
+func UnaryAuthInterceptor(cfg AuthConfig) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := cfg.authenticate(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamAuthInterceptor проверяет токен для потоковых RPC (Replay)
+func StreamAuthInterceptor(cfg AuthConfig) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := cfg.authenticate(ss.Context())
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// authenticatedStream подменяет контекст потока контекстом с Caller
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+// TLSConfig - файлы сертификатов для TLS. Пустой CertFile - без TLS
+type TLSConfig struct {
+	CertFile string // Сертификат сервера (PEM); для клиента - сертификат для mTLS
+	KeyFile  string // Ключ к CertFile (PEM)
+	CAFile   string // Для клиента: CA для проверки сервера (пусто - системные)
+
+	// Для сервера: CA клиентских сертификатов. Если задан, клиенты без
+	// подписанного им сертификата не проходят рукопожатие (mTLS)
+	ClientCAFile string
+	// Для сервера: запрещает запуск без сертификата
+	Required bool
+}
+
+// ServerOptions возвращает опции gRPC-сервера с проверкой токенов и, если
+// задан сертификат, с TLS. Возвращаемый Reloader (nil без TLS) перечитывает
+// сертификаты без перезапуска сервера
+func ServerOptions(authCfg AuthConfig, tlsCfg TLSConfig) ([]grpc.ServerOption, *tlsreload.Reloader, error) {
+	opts := []grpc.ServerOption{
+		grpc.UnaryInterceptor(UnaryAuthInterceptor(authCfg)),
+		grpc.StreamInterceptor(StreamAuthInterceptor(authCfg)),
+	}
+
+	certs, err := tlsreload.New(tlsreload.Config{
+		CertFile:     tlsCfg.CertFile,
+		KeyFile:      tlsCfg.KeyFile,
+		ClientCAFile: tlsCfg.ClientCAFile,
+		Required:     tlsCfg.Required,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("load TLS certificate: %w", err)
+	}
+	if certs != nil {
+		config := certs.ServerConfig(tls.RequireAndVerifyClientCert, "h2")
+		opts = append(opts, grpc.Creds(credentials.NewTLS(config)))
+	}
+
+	return opts, certs, nil
+}
+
+// tokenCredentials передаёт токен в метаданных каждого RPC
+type tokenCredentials struct {
+	token  string
+	secure bool
+}
+
+func (c tokenCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{AuthMetadataKey: "Bearer " + c.token}, nil
+}
+
+func (c tokenCredentials) RequireTransportSecurity() bool {
+	return c.secure
+}
+
+// DialOptions возвращает опции клиента: токен в метаданных и TLS, если
+// useTLS. Без TLS токен передаётся открытым текстом - только для локальной
+// отладки
+func DialOptions(token string, useTLS bool, tlsCfg TLSConfig) ([]grpc.DialOption, error) {
+	var opts []grpc.DialOption
+
+	if useTLS {
+		config := &tls.Config{MinVersion: tls.VersionTLS12}
+		if tlsCfg.CAFile != "" {
+			pem, err := os.ReadFile(tlsCfg.CAFile)
+			if err != nil {
+				return nil, fmt.Errorf("read CA file: %w", err)
+			}
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("no certificates in %s", tlsCfg.CAFile)
+			}
+			config.RootCAs = pool
+		}
+		if tlsCfg.CertFile != "" {
+			cert, err := tls.LoadX509KeyPair(tlsCfg.CertFile, tlsCfg.KeyFile)
+			if err != nil {
+				return nil, fmt.Errorf("load client certificate: %w", err)
+			}
+			config.Certificates = []tls.Certificate{cert}
+		}
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(config)))
+	} else {
+		opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	}
+
+	if token != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(tokenCredentials{token: token, secure: useTLS}))
+	}
+
+	return opts, nil
+}
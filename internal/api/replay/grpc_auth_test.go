@@ -0,0 +1,128 @@
+package replay
+
+import (
+	"context"
+	"testing"
+
+	"github.com/annel0/mmo-game/internal/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func tokenFor(t *testing.T, user *auth.User) string {
+	t.Helper()
+	token, err := auth.GenerateJWT(user)
+	require.NoError(t, err)
+	return token
+}
+
+func incomingWithToken(token string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs(AuthMetadataKey, "Bearer "+token))
+}
+
+// callUnary вызывает перехватчик с обработчиком, запоминающим Caller
+func callUnary(ctx context.Context, cfg AuthConfig) (Caller, bool, error) {
+	var (
+		caller Caller
+		found  bool
+	)
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		caller, found = CallerFromContext(ctx)
+		return "ok", nil
+	}
+	_, err := UnaryAuthInterceptor(cfg)(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/replay.ReplayService/Health"}, handler)
+	return caller, found, err
+}
+
+func TestUnaryAuth_AdminAuthorized(t *testing.T) {
+	token := tokenFor(t, &auth.User{ID: 1, Username: "admin", IsAdmin: true})
+
+	caller, found, err := callUnary(incomingWithToken(token), AuthConfig{})
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.Equal(t, Caller{PlayerID: 1, IsAdmin: true}, caller)
+}
+
+func TestUnaryAuth_RejectsUnauthenticated(t *testing.T) {
+	tests := []struct {
+		name string
+		ctx  context.Context
+	}{
+		{"no metadata", context.Background()},
+		{"no token", metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-other", "1"))},
+		{"not bearer", metadata.NewIncomingContext(context.Background(), metadata.Pairs(AuthMetadataKey, "Basic abc"))},
+		{"garbage token", incomingWithToken("not-a-jwt")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, found, err := callUnary(tt.ctx, AuthConfig{})
+			assert.Equal(t, codes.Unauthenticated, status.Code(err))
+			assert.False(t, found, "обработчик не должен вызываться")
+		})
+	}
+}
+
+func TestUnaryAuth_NonAdmin(t *testing.T) {
+	token := tokenFor(t, &auth.User{ID: 7, Username: "player"})
+
+	_, found, err := callUnary(incomingWithToken(token), AuthConfig{})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.False(t, found)
+
+	caller, found, err := callUnary(incomingWithToken(token), AuthConfig{AllowNonAdmin: true})
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.Equal(t, Caller{PlayerID: 7}, caller)
+}
+
+// fakeServerStream - поток с заданным контекстом
+type fakeServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *fakeServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestStreamAuth(t *testing.T) {
+	interceptor := StreamAuthInterceptor(AuthConfig{})
+	info := &grpc.StreamServerInfo{FullMethod: "/replay.ReplayService/ReplayEvents", IsServerStream: true}
+
+	var caller Caller
+	handler := func(srv interface{}, stream grpc.ServerStream) error {
+		caller, _ = CallerFromContext(stream.Context())
+		return nil
+	}
+
+	token := tokenFor(t, &auth.User{ID: 3, Username: "admin", IsAdmin: true})
+	err := interceptor(nil, &fakeServerStream{ctx: incomingWithToken(token)}, info, handler)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), caller.PlayerID)
+
+	err = interceptor(nil, &fakeServerStream{ctx: context.Background()}, info, handler)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestTokenCredentials(t *testing.T) {
+	creds := tokenCredentials{token: "abc", secure: true}
+
+	md, err := creds.GetRequestMetadata(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer abc", md[AuthMetadataKey])
+	assert.True(t, creds.RequireTransportSecurity())
+}
+
+func TestDialOptions_MissingCAFile(t *testing.T) {
+	_, err := DialOptions("abc", true, TLSConfig{CAFile: "/nonexistent/ca.pem"})
+	assert.Error(t, err)
+
+	opts, err := DialOptions("abc", false, TLSConfig{})
+	require.NoError(t, err)
+	assert.Len(t, opts, 2)
+}
@@ -0,0 +1,132 @@
+package replay
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/annel0/mmo-game/internal/protocol/events"
+	replaypb "github.com/annel0/mmo-game/internal/protocol/replay"
+	"github.com/annel0/mmo-game/internal/tlsreload"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// NextCursorTrailer - трейлер потока Replay с курсором следующей страницы
+// (отсутствует, если событий больше нет)
+const NextCursorTrailer = "next-cursor"
+
+// grpcReplayServer обслуживает RPC ReplayService поверх ReplayService
+type grpcReplayServer struct {
+	replaypb.UnimplementedReplayServiceServer
+	service *ReplayService
+}
+
+// NewGRPCServer создаёт gRPC-сервер ReplayService с проверкой токенов
+// (AuthConfig) и, если задан сертификат, с TLS. Возвращаемый Reloader
+// (nil без TLS) перечитывает сертификаты без перезапуска сервера
+func NewGRPCServer(service *ReplayService, authCfg AuthConfig, tlsCfg TLSConfig) (*grpc.Server, *tlsreload.Reloader, error) {
+	opts, certs, err := ServerOptions(authCfg, tlsCfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	server := grpc.NewServer(opts...)
+	replaypb.RegisterReplayServiceServer(server, &grpcReplayServer{service: service})
+	return server, certs, nil
+}
+
+// Replay отправляет страницу событий по фильтру, курсор следующей страницы
+// передаётся в трейлере NextCursorTrailer. Типизированная полезная нагрузка
+// не восстанавливается: конверт содержит ID, тип, время, регион и источник
+func (s *grpcReplayServer) Replay(req *replaypb.ReplayRequest, stream replaypb.ReplayService_ReplayServer) error {
+	filter, offset, err := parseQuery(req)
+	if err != nil {
+		return rpcError(err)
+	}
+
+	all, err := s.service.QueryEnvelopes(stream.Context(), filter)
+	if err != nil {
+		return rpcError(err)
+	}
+
+	descending := req.GetSortOrder() == replaypb.ReplayRequest_SORT_ORDER_DESC
+	sort.SliceStable(all, func(i, j int) bool {
+		if descending {
+			return all[i].Timestamp.After(all[j].Timestamp)
+		}
+		return all[i].Timestamp.Before(all[j].Timestamp)
+	})
+
+	page, next := paginate(all, offset, req.GetLimit())
+	if next != "" {
+		stream.SetTrailer(metadata.Pairs(NextCursorTrailer, next))
+	}
+	for _, envelope := range page {
+		if err := stream.Send(&events.EventEnvelope{
+			EventId:    envelope.EventID,
+			EventType:  envelope.EventType,
+			Timestamp:  timestamppb.New(envelope.Timestamp),
+			RegionId:   envelope.RegionID,
+			SourceNode: envelope.SourceNode,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetEventStats возвращает статистику, сгруппированную по req.GroupBy
+func (s *grpcReplayServer) GetEventStats(ctx context.Context, req *replaypb.EventStatsRequest) (*replaypb.EventStatsResponse, error) {
+	resp, err := s.service.EventStats(ctx, req)
+	if err != nil {
+		return nil, rpcError(err)
+	}
+	return resp, nil
+}
+
+// GetEventTypes возвращает сводку по типам событий. Интервал запроса
+// отбирает типы, встречавшиеся в нём; счётчики - за всё время хранения
+func (s *grpcReplayServer) GetEventTypes(ctx context.Context, req *replaypb.EventTypesRequest) (*replaypb.EventTypesResponse, error) {
+	infos, err := s.service.GetEventTypeInfo(ctx)
+	if err != nil {
+		return nil, rpcError(err)
+	}
+
+	resp := &replaypb.EventTypesResponse{}
+	for _, info := range infos {
+		if req.GetStartTime() != nil && info.LastSeen.Before(req.GetStartTime().AsTime()) {
+			continue
+		}
+		if req.GetEndTime() != nil && info.FirstSeen.After(req.GetEndTime().AsTime()) {
+			continue
+		}
+		resp.EventTypes = append(resp.EventTypes, &replaypb.EventTypeInfo{
+			EventType: info.EventType,
+			Count:     info.Count,
+			FirstSeen: timestamppb.New(info.FirstSeen),
+			LastSeen:  timestamppb.New(info.LastSeen),
+			Regions:   info.Regions,
+		})
+	}
+	return resp, nil
+}
+
+// Health возвращает состояние сервиса и бэкенда событий
+func (s *grpcReplayServer) Health(ctx context.Context, req *replaypb.HealthRequest) (*replaypb.HealthResponse, error) {
+	health, err := s.service.Health(ctx)
+	if err != nil {
+		return nil, rpcError(err)
+	}
+	return health.Proto(), nil
+}
+
+// rpcError переводит ошибку сервиса в статус gRPC
+func rpcError(err error) error {
+	if errors.Is(err, ErrInvalidQuery) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
@@ -0,0 +1,126 @@
+package replay
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	replaypb "github.com/annel0/mmo-game/internal/protocol/replay"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// startReplayServer запускает ReplayService поверх хранилища в памяти и
+// возвращает клиент с токеном token ("admin-token" - администратор)
+func startReplayServer(t *testing.T, store *MemoryEventStore, token string) replaypb.ReplayServiceClient {
+	t.Helper()
+	authCfg := AuthConfig{Validator: func(token string) (uint64, bool, bool) {
+		return 1, token == "admin-token", true
+	}}
+	server, certs, err := NewGRPCServer(NewReplayService(store), authCfg, TLSConfig{})
+	require.NoError(t, err)
+	assert.Nil(t, certs)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = server.Serve(ln) }()
+	t.Cleanup(server.Stop)
+
+	opts, err := DialOptions(token, false, TLSConfig{})
+	require.NoError(t, err)
+	conn, err := grpc.Dial(ln.Addr().String(), opts...)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return replaypb.NewReplayServiceClient(conn)
+}
+
+func testEventStore() *MemoryEventStore {
+	store := NewMemoryEventStore(time.Hour)
+	base := time.Now().Add(-10 * time.Minute)
+	store.Ingest(&EventEnvelope{EventID: "1", EventType: "block", RegionID: "eu-west", Timestamp: base})
+	store.Ingest(&EventEnvelope{EventID: "2", EventType: "chat", RegionID: "eu-west", Timestamp: base.Add(time.Minute)})
+	store.Ingest(&EventEnvelope{EventID: "3", EventType: "block", RegionID: "us-east", Timestamp: base.Add(2 * time.Minute)})
+	return store
+}
+
+func TestGRPCServer_RejectsCallsWithoutAdminToken(t *testing.T) {
+	client := startReplayServer(t, testEventStore(), "wrong-token")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	_, err := client.Health(ctx, &replaypb.HealthRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	stream, err := client.Replay(ctx, &replaypb.ReplayRequest{})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestGRPCServer_ServesStoreEvents(t *testing.T) {
+	client := startReplayServer(t, testEventStore(), "admin-token")
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	health, err := client.Health(ctx, &replaypb.HealthRequest{})
+	require.NoError(t, err)
+	assert.True(t, health.GetHealthy())
+	assert.EqualValues(t, 3, health.GetTotalEvents())
+
+	stats, err := client.GetEventStats(ctx, &replaypb.EventStatsRequest{
+		GroupBy: replaypb.EventStatsRequest_STATS_GROUP_BY_REGION,
+	})
+	require.NoError(t, err)
+	require.Len(t, stats.GetStats(), 2)
+	assert.Equal(t, "eu-west", stats.GetStats()[0].GetGroupKey())
+	assert.EqualValues(t, 2, stats.GetStats()[0].GetEventCount())
+
+	types, err := client.GetEventTypes(ctx, &replaypb.EventTypesRequest{})
+	require.NoError(t, err)
+	require.Len(t, types.GetEventTypes(), 2)
+	assert.Equal(t, "block", types.GetEventTypes()[0].GetEventType())
+	assert.EqualValues(t, 2, types.GetEventTypes()[0].GetCount())
+}
+
+func TestGRPCServer_ReplayPagesWithTrailerCursor(t *testing.T) {
+	client := startReplayServer(t, testEventStore(), "admin-token")
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	replay := func(cursor string) ([]string, string) {
+		stream, err := client.Replay(ctx, &replaypb.ReplayRequest{Limit: 2, Cursor: cursor})
+		require.NoError(t, err)
+		var ids []string
+		for {
+			envelope, err := stream.Recv()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+			ids = append(ids, envelope.GetEventId())
+		}
+		next := stream.Trailer().Get(NextCursorTrailer)
+		if len(next) == 0 {
+			return ids, ""
+		}
+		return ids, next[0]
+	}
+
+	ids, next := replay("")
+	assert.Equal(t, []string{"1", "2"}, ids)
+	require.NotEmpty(t, next)
+
+	ids, next = replay(next)
+	assert.Equal(t, []string{"3"}, ids)
+	assert.Empty(t, next)
+
+	stream, err := client.Replay(ctx, &replaypb.ReplayRequest{Cursor: "%%%"})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
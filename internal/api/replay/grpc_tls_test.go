@@ -0,0 +1,121 @@
+package replay
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	replaypb "github.com/annel0/mmo-game/internal/protocol/replay"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+// writeTestCertificate записывает самоподписанный сертификат для 127.0.0.1
+// (годится и как сертификат клиента, и как его CA) и возвращает пути файлов
+func writeTestCertificate(t *testing.T, dir, name string) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile = filepath.Join(dir, name+".pem")
+	keyFile = filepath.Join(dir, name+"-key.pem")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return certFile, keyFile
+}
+
+// startTLSServer запускает ReplayService с проверкой токена "admin-token"
+// так же, как сервер: через NewGRPCServer
+func startTLSServer(t *testing.T, tlsCfg TLSConfig) string {
+	t.Helper()
+	authCfg := AuthConfig{Validator: func(token string) (uint64, bool, bool) {
+		return 1, token == "admin-token", true
+	}}
+	server, certs, err := NewGRPCServer(NewReplayService(NewMemoryEventStore(time.Hour)), authCfg, tlsCfg)
+	require.NoError(t, err)
+	require.NotNil(t, certs)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = server.Serve(ln) }()
+	t.Cleanup(server.Stop)
+	return ln.Addr().String()
+}
+
+// checkHealth вызывает ReplayService/Health через соединение с заданными опциями
+func checkHealth(t *testing.T, addr string, opts []grpc.DialOption) error {
+	t.Helper()
+	conn, err := grpc.Dial(addr, opts...)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	_, err = replaypb.NewReplayServiceClient(conn).Health(ctx, &replaypb.HealthRequest{})
+	return err
+}
+
+func TestServerOptions_PlaintextFallback(t *testing.T) {
+	_, certs, err := ServerOptions(AuthConfig{}, TLSConfig{})
+	require.NoError(t, err)
+	assert.Nil(t, certs)
+
+	_, _, err = ServerOptions(AuthConfig{}, TLSConfig{Required: true})
+	assert.Error(t, err, "без сертификата при Required запуск запрещён")
+}
+
+func TestServerOptions_ServesTLSAndRejectsPlaintext(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeTestCertificate(t, dir, "server")
+	addr := startTLSServer(t, TLSConfig{CertFile: certFile, KeyFile: keyFile, Required: true})
+
+	secure, err := DialOptions("admin-token", true, TLSConfig{CAFile: certFile})
+	require.NoError(t, err)
+	assert.NoError(t, checkHealth(t, addr, secure))
+
+	plaintext, err := DialOptions("admin-token", false, TLSConfig{})
+	require.NoError(t, err)
+	assert.Error(t, checkHealth(t, addr, plaintext), "соединение без TLS должно отклоняться")
+}
+
+func TestServerOptions_MutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeTestCertificate(t, dir, "server")
+	clientCertFile, clientKeyFile := writeTestCertificate(t, dir, "client")
+	addr := startTLSServer(t, TLSConfig{CertFile: certFile, KeyFile: keyFile, ClientCAFile: clientCertFile})
+
+	withoutCert, err := DialOptions("admin-token", true, TLSConfig{CAFile: certFile})
+	require.NoError(t, err)
+	assert.Error(t, checkHealth(t, addr, withoutCert), "без сертификата клиента рукопожатие отклоняется")
+
+	withCert, err := DialOptions("admin-token", true, TLSConfig{CAFile: certFile, CertFile: clientCertFile, KeyFile: clientKeyFile})
+	require.NoError(t, err)
+	assert.NoError(t, checkHealth(t, addr, withCert))
+}
@@ -0,0 +1,144 @@
+package replay
+
+import (
+	"context"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/eventbus"
+	replaypb "github.com/annel0/mmo-game/internal/protocol/replay"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// BackendHealth описывает состояние бэкенда событий (NATS JetStream и т.п.)
+type BackendHealth struct {
+	Backend    string        `json:"backend"`
+	Connected  bool          `json:"connected"`
+	Error      string        `json:"error,omitempty"`
+	Stream     string        `json:"stream,omitempty"`
+	Messages   uint64        `json:"messages"`
+	Bytes      uint64        `json:"bytes"`
+	Retention  time.Duration `json:"retention"`
+	FirstEvent time.Time     `json:"first_event,omitempty"`
+	LastEvent  time.Time     `json:"last_event,omitempty"`
+}
+
+// HealthChecker проверяет доступность бэкенда событий. EventStore может
+// реализовать его сам, либо проверка задаётся через SetHealthChecker
+type HealthChecker interface {
+	Health(ctx context.Context) BackendHealth
+}
+
+// HealthCheckerFunc позволяет использовать функцию как HealthChecker
+type HealthCheckerFunc func(ctx context.Context) BackendHealth
+
+// Health вызывает f(ctx)
+func (f HealthCheckerFunc) Health(ctx context.Context) BackendHealth {
+	return f(ctx)
+}
+
+// NewJetStreamHealthChecker проверяет доступность NATS и стрима JetStream
+func NewJetStreamHealthChecker(bus *eventbus.JetStreamBus) HealthChecker {
+	return HealthCheckerFunc(func(ctx context.Context) BackendHealth {
+		stream := bus.Health()
+		return BackendHealth{
+			Backend:    "nats-jetstream",
+			Connected:  stream.Connected,
+			Error:      stream.Error,
+			Stream:     stream.Stream,
+			Messages:   stream.Messages,
+			Bytes:      stream.Bytes,
+			Retention:  stream.MaxAge,
+			FirstEvent: stream.FirstEvent,
+			LastEvent:  stream.LastEvent,
+		}
+	})
+}
+
+// HealthStatus - состояние сервиса воспроизведения
+type HealthStatus struct {
+	Healthy     bool           `json:"healthy"`
+	Backend     BackendHealth  `json:"backend"`
+	TotalEvents int64          `json:"total_events"`
+	EventTypes  map[string]int `json:"event_types,omitempty"`
+	CheckedAt   time.Time      `json:"checked_at"`
+}
+
+// SetHealthChecker задаёт проверку бэкенда событий для Health
+func (s *ReplayService) SetHealthChecker(checker HealthChecker) {
+	s.healthChecker = checker
+}
+
+// Health проверяет бэкенд событий и, если он доступен, возвращает сводку
+// по количеству событий. Недоступный бэкенд не считается ошибкой вызова:
+// он отражается в HealthStatus, чтобы клиент мог показать причину
+func (s *ReplayService) Health(ctx context.Context) (*HealthStatus, error) {
+	status := &HealthStatus{CheckedAt: time.Now()}
+
+	if s.eventStore == nil {
+		status.Backend = BackendHealth{Backend: "none", Error: "event store not configured"}
+		return status, nil
+	}
+
+	checker := s.healthChecker
+	if checker == nil {
+		checker, _ = s.eventStore.(HealthChecker)
+	}
+	if checker != nil {
+		status.Backend = checker.Health(ctx)
+	} else {
+		// Хранилище без проверки доступности считаем подключённым,
+		// а реальную доступность покажет запрос статистики ниже
+		status.Backend = BackendHealth{Backend: "event-store", Connected: true}
+	}
+
+	if !status.Backend.Connected {
+		if status.Backend.Error == "" {
+			status.Backend.Error = "backend unavailable"
+		}
+		return status, nil
+	}
+
+	stats, err := s.eventStore.GetEventStats(ctx, EventQuery{})
+	if err != nil {
+		status.Backend.Connected = false
+		status.Backend.Error = err.Error()
+		return status, nil
+	}
+
+	status.Healthy = true
+	status.TotalEvents = stats.TotalEvents
+	status.EventTypes = stats.EventTypes
+	return status, nil
+}
+
+// Proto преобразует состояние в ответ RPC ReplayService.Health
+func (h *HealthStatus) Proto() *replaypb.HealthResponse {
+	backend := &replaypb.BackendStatus{
+		Backend:          h.Backend.Backend,
+		Connected:        h.Backend.Connected,
+		Error:            h.Backend.Error,
+		Stream:           h.Backend.Stream,
+		Messages:         h.Backend.Messages,
+		Bytes:            h.Backend.Bytes,
+		RetentionSeconds: int64(h.Backend.Retention / time.Second),
+	}
+	if !h.Backend.FirstEvent.IsZero() {
+		backend.FirstEvent = timestamppb.New(h.Backend.FirstEvent)
+	}
+	if !h.Backend.LastEvent.IsZero() {
+		backend.LastEvent = timestamppb.New(h.Backend.LastEvent)
+	}
+
+	counts := make(map[string]int64, len(h.EventTypes))
+	for eventType, count := range h.EventTypes {
+		counts[eventType] = int64(count)
+	}
+
+	return &replaypb.HealthResponse{
+		Healthy:         h.Healthy,
+		Backend:         backend,
+		TotalEvents:     h.TotalEvents,
+		EventTypeCounts: counts,
+		CheckedAt:       timestamppb.New(h.CheckedAt),
+	}
+}
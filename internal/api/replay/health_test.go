@@ -0,0 +1,96 @@
+package replay
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubEventStore - хранилище событий с фиксированной статистикой
+type stubEventStore struct {
+	stats      *EventStats
+	statsErr   error
+	statsCalls int
+}
+
+func (s *stubEventStore) QueryEvents(ctx context.Context, query EventQuery) ([]*EventEnvelope, error) {
+	return nil, nil
+}
+
+func (s *stubEventStore) GetEventStats(ctx context.Context, query EventQuery) (*EventStats, error) {
+	s.statsCalls++
+	return s.stats, s.statsErr
+}
+
+func (s *stubEventStore) GetEventTypes(ctx context.Context) ([]string, error) {
+	return nil, nil
+}
+
+func TestHealth_DisconnectedBackend(t *testing.T) {
+	store := &stubEventStore{stats: &EventStats{TotalEvents: 10}}
+	service := NewReplayService(store)
+	service.SetHealthChecker(HealthCheckerFunc(func(ctx context.Context) BackendHealth {
+		return BackendHealth{Backend: "nats-jetstream", Stream: "EVENTS", Error: "nats: not connected"}
+	}))
+
+	status, err := service.Health(context.Background())
+	require.NoError(t, err)
+	assert.False(t, status.Healthy)
+	assert.False(t, status.Backend.Connected)
+	assert.Equal(t, "nats: not connected", status.Backend.Error)
+	assert.Zero(t, store.statsCalls, "статистика не запрашивается у недоступного бэкенда")
+
+	resp := status.Proto()
+	assert.False(t, resp.GetHealthy())
+	assert.Equal(t, "nats: not connected", resp.GetBackend().GetError())
+	assert.Equal(t, "EVENTS", resp.GetBackend().GetStream())
+}
+
+func TestHealth_ConnectedBackendReportsSummary(t *testing.T) {
+	store := &stubEventStore{stats: &EventStats{TotalEvents: 12, EventTypes: map[string]int{"block": 9, "chat": 3}}}
+	service := NewReplayService(store)
+	first := time.Unix(1_700_000_000, 0)
+	service.SetHealthChecker(HealthCheckerFunc(func(ctx context.Context) BackendHealth {
+		return BackendHealth{
+			Backend:    "nats-jetstream",
+			Connected:  true,
+			Stream:     "EVENTS",
+			Messages:   12,
+			Retention:  24 * time.Hour,
+			FirstEvent: first,
+			LastEvent:  first.Add(time.Hour),
+		}
+	}))
+
+	status, err := service.Health(context.Background())
+	require.NoError(t, err)
+	assert.True(t, status.Healthy)
+	assert.Equal(t, int64(12), status.TotalEvents)
+
+	resp := status.Proto()
+	assert.True(t, resp.GetHealthy())
+	assert.Equal(t, int64(86400), resp.GetBackend().GetRetentionSeconds())
+	assert.Equal(t, first.Unix(), resp.GetBackend().GetFirstEvent().GetSeconds())
+	assert.Equal(t, map[string]int64{"block": 9, "chat": 3}, resp.GetEventTypeCounts())
+}
+
+func TestHealth_StoreErrorMarksBackendDown(t *testing.T) {
+	service := NewReplayService(&stubEventStore{statsErr: errors.New("connection refused")})
+
+	status, err := service.Health(context.Background())
+	require.NoError(t, err)
+	assert.False(t, status.Healthy)
+	assert.False(t, status.Backend.Connected)
+	assert.Equal(t, "connection refused", status.Backend.Error)
+}
+
+func TestHealth_NoEventStore(t *testing.T) {
+	status, err := NewReplayService(nil).Health(context.Background())
+	require.NoError(t, err)
+	assert.False(t, status.Healthy)
+	assert.Equal(t, "event store not configured", status.Backend.Error)
+}
@@ -0,0 +1,176 @@
+package replay
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryEventStore - EventStore в памяти с ограниченным сроком хранения.
+// Каталог типов (см. TypeCatalog) обновляется при поступлении и устаревании
+// событий, поэтому GetEventTypes и EventTypeInfos не просматривают события
+type MemoryEventStore struct {
+	mu        sync.Mutex
+	events    []*EventEnvelope // В порядке поступления
+	retention time.Duration    // 0 - без ограничения
+	catalog   *TypeCatalog
+	now       func() time.Time
+}
+
+// NewMemoryEventStore создаёт хранилище; события старше retention
+// удаляются (0 - хранятся без ограничения)
+func NewMemoryEventStore(retention time.Duration) *MemoryEventStore {
+	return &MemoryEventStore{
+		retention: retention,
+		catalog:   NewTypeCatalog(),
+		now:       time.Now,
+	}
+}
+
+// Ingest сохраняет событие и учитывает его в каталоге типов
+func (s *MemoryEventStore) Ingest(env *EventEnvelope) {
+	if env.Timestamp.IsZero() {
+		env.Timestamp = s.now().UTC()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.events = append(s.events, env)
+	s.catalog.Observe(env)
+	s.expireLocked()
+}
+
+// expireLocked удаляет события старше срока хранения, уменьшая счётчики каталога
+func (s *MemoryEventStore) expireLocked() {
+	if s.retention <= 0 {
+		return
+	}
+
+	cutoff := s.now().Add(-s.retention)
+	drop := 0
+	for drop < len(s.events) && s.events[drop].Timestamp.Before(cutoff) {
+		s.catalog.Expire(s.events[drop])
+		drop++
+	}
+	if drop > 0 {
+		clear(s.events[:drop])
+		s.events = s.events[drop:]
+	}
+}
+
+// QueryEvents возвращает события по фильтру в порядке поступления
+func (s *MemoryEventStore) QueryEvents(ctx context.Context, query EventQuery) ([]*EventEnvelope, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expireLocked()
+
+	types := make(map[string]bool, len(query.EventTypes))
+	for _, t := range query.EventTypes {
+		types[t] = true
+	}
+
+	var result []*EventEnvelope
+	for _, env := range s.events {
+		if len(types) > 0 && !types[env.EventType] {
+			continue
+		}
+		if query.Region != "" && env.RegionID != query.Region {
+			continue
+		}
+		if query.PlayerID != 0 && envelopePlayerID(env) != query.PlayerID {
+			continue
+		}
+		if query.StartTime != nil && env.Timestamp.Before(*query.StartTime) {
+			continue
+		}
+		if query.EndTime != nil && env.Timestamp.After(*query.EndTime) {
+			continue
+		}
+		result = append(result, env)
+		if query.Limit > 0 && len(result) >= query.Limit {
+			break
+		}
+	}
+	return result, nil
+}
+
+// GetEventStats возвращает число событий по типам: без фильтров - из
+// каталога, с фильтрами - по подходящим событиям
+func (s *MemoryEventStore) GetEventStats(ctx context.Context, query EventQuery) (*EventStats, error) {
+	stats := &EventStats{EventTypes: make(map[string]int)}
+
+	if len(query.EventTypes) == 0 && query.Region == "" && query.PlayerID == 0 && query.StartTime == nil && query.EndTime == nil {
+		infos, _ := s.EventTypeInfos(ctx)
+		for _, info := range infos {
+			stats.TotalEvents += info.Count
+			stats.EventTypes[info.EventType] = int(info.Count)
+		}
+		return stats, nil
+	}
+
+	envelopes, _ := s.QueryEvents(ctx, query)
+	for _, env := range envelopes {
+		stats.TotalEvents++
+		stats.EventTypes[env.EventType]++
+	}
+	return stats, nil
+}
+
+// GetEventTypes возвращает типы хранимых событий из каталога
+func (s *MemoryEventStore) GetEventTypes(ctx context.Context) ([]string, error) {
+	s.expire()
+	return s.catalog.Names(), nil
+}
+
+// EventTypeInfos возвращает сводку по типам из каталога. Крайние времена
+// типов, потерявших при устаревании первое или последнее событие,
+// пересчитываются одним проходом по хранимым событиям
+func (s *MemoryEventStore) EventTypeInfos(ctx context.Context) ([]EventTypeInfo, error) {
+	s.mu.Lock()
+	s.expireLocked()
+	if stale := s.catalog.Stale(); len(stale) > 0 {
+		s.refreshCatalogLocked(stale)
+	}
+	s.mu.Unlock()
+
+	return s.catalog.Types(), nil
+}
+
+// refreshCatalogLocked пересчитывает крайние времена типов stale (под s.mu)
+func (s *MemoryEventStore) refreshCatalogLocked(stale []string) {
+	type bounds struct{ first, last time.Time }
+	found := make(map[string]*bounds, len(stale))
+	for _, eventType := range stale {
+		found[eventType] = nil
+	}
+
+	for _, env := range s.events {
+		b, ok := found[env.EventType]
+		if !ok {
+			continue
+		}
+		if b == nil {
+			found[env.EventType] = &bounds{first: env.Timestamp, last: env.Timestamp}
+			continue
+		}
+		if env.Timestamp.Before(b.first) {
+			b.first = env.Timestamp
+		}
+		if env.Timestamp.After(b.last) {
+			b.last = env.Timestamp
+		}
+	}
+	for eventType, b := range found {
+		if b != nil {
+			s.catalog.Refresh(eventType, b.first, b.last)
+		}
+	}
+}
+
+// expire удаляет устаревшие события перед чтением каталога
+func (s *MemoryEventStore) expire() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expireLocked()
+}
@@ -91,3 +91,47 @@ func (m *MockReplayService) GetEventTypes(ctx context.Context) ([]string, error)
 		string(events.EventTypeChat),
 	}, nil
 }
+
+// GetEventTypeInfo возвращает сводку по типам тестовых событий StreamEvents
+func (m *MockReplayService) GetEventTypeInfo(ctx context.Context) ([]EventTypeInfo, error) {
+	testEvents, err := m.StreamEvents(ctx, &ReplayFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	catalog := NewTypeCatalog()
+	for _, event := range testEvents {
+		region, _ := event.Data["region"].(string)
+		catalog.Observe(&EventEnvelope{
+			EventType: string(event.Type),
+			Timestamp: time.Unix(event.Timestamp, 0),
+			RegionID:  region,
+		})
+	}
+	return catalog.Types(), nil
+}
+
+// Health возвращает тестовое состояние с доступным бэкендом
+func (m *MockReplayService) Health(ctx context.Context) (*HealthStatus, error) {
+	now := time.Now()
+	return &HealthStatus{
+		Healthy: true,
+		Backend: BackendHealth{
+			Backend:    "mock",
+			Connected:  true,
+			Stream:     "EVENTS",
+			Messages:   1234,
+			Retention:  7 * 24 * time.Hour,
+			FirstEvent: now.Add(-24 * time.Hour),
+			LastEvent:  now,
+		},
+		TotalEvents: 1234,
+		EventTypes: map[string]int{
+			"system": 45,
+			"world":  567,
+			"block":  890,
+			"chat":   234,
+		},
+		CheckedAt: now,
+	}, nil
+}
@@ -0,0 +1,149 @@
+package replay
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/annel0/mmo-game/internal/protocol/events"
+	replaypb "github.com/annel0/mmo-game/internal/protocol/replay"
+)
+
+// ErrInvalidQuery - запрос Replay с некорректными параметрами (фильтр, курсор, лимит)
+var ErrInvalidQuery = errors.New("invalid replay query")
+
+// EventStreamer - источник событий по фильтру (ReplayService или MockReplayService)
+type EventStreamer interface {
+	StreamEvents(ctx context.Context, filter *ReplayFilter) ([]events.Event, error)
+}
+
+// EventPage - страница результата запроса событий
+type EventPage struct {
+	Events     []events.Event `json:"events"`
+	NextCursor string         `json:"next_cursor,omitempty"` // Пусто - событий больше нет
+}
+
+// FilterFromRequest переводит запрос Replay в фильтр сервиса. Один запрос
+// фильтрует не больше чем по одному региону и одному игроку
+func FilterFromRequest(req *replaypb.ReplayRequest) (*ReplayFilter, error) {
+	filter := &ReplayFilter{}
+	for _, t := range req.GetEventTypes() {
+		filter.EventTypes = append(filter.EventTypes, events.EventType(t))
+	}
+
+	switch regions := req.GetRegionIds(); len(regions) {
+	case 0:
+	case 1:
+		filter.Region = regions[0]
+	default:
+		return nil, fmt.Errorf("%w: only one region per query is supported, got %d", ErrInvalidQuery, len(regions))
+	}
+
+	switch players := req.GetPlayerIds(); len(players) {
+	case 0:
+	case 1:
+		playerID, err := strconv.ParseUint(players[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid player id %q", ErrInvalidQuery, players[0])
+		}
+		filter.PlayerID = playerID
+	default:
+		return nil, fmt.Errorf("%w: only one player per query is supported, got %d", ErrInvalidQuery, len(players))
+	}
+
+	if req.GetStartTime() != nil {
+		start := req.GetStartTime().AsTime()
+		filter.StartTime = &start
+	}
+	if req.GetEndTime() != nil {
+		end := req.GetEndTime().AsTime()
+		filter.EndTime = &end
+	}
+	if filter.StartTime != nil && filter.EndTime != nil && filter.EndTime.Before(*filter.StartTime) {
+		return nil, fmt.Errorf("%w: end time is before start time", ErrInvalidQuery)
+	}
+
+	return filter, nil
+}
+
+// QueryEvents выполняет запрос Replay: фильтрует события источника, упорядочивает
+// их по времени и возвращает страницу после req.Cursor размером не больше req.Limit
+// (0 - все оставшиеся). Обслуживает и gRPC, и REST, поэтому результаты совпадают
+func QueryEvents(ctx context.Context, source EventStreamer, req *replaypb.ReplayRequest) (*EventPage, error) {
+	filter, offset, err := parseQuery(req)
+	if err != nil {
+		return nil, err
+	}
+
+	all, err := source.StreamEvents(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	descending := req.GetSortOrder() == replaypb.ReplayRequest_SORT_ORDER_DESC
+	sort.SliceStable(all, func(i, j int) bool {
+		if descending {
+			return all[i].Timestamp > all[j].Timestamp
+		}
+		return all[i].Timestamp < all[j].Timestamp
+	})
+
+	page := &EventPage{}
+	page.Events, page.NextCursor = paginate(all, offset, req.GetLimit())
+	return page, nil
+}
+
+// parseQuery проверяет запрос Replay и возвращает фильтр и позицию курсора
+func parseQuery(req *replaypb.ReplayRequest) (*ReplayFilter, int, error) {
+	filter, err := FilterFromRequest(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	offset, err := decodeCursor(req.GetCursor())
+	if err != nil {
+		return nil, 0, err
+	}
+	if req.GetLimit() < 0 {
+		return nil, 0, fmt.Errorf("%w: limit must not be negative", ErrInvalidQuery)
+	}
+	return filter, offset, nil
+}
+
+// paginate возвращает не больше limit элементов после offset (0 - все
+// оставшиеся) и курсор следующей страницы ("" - это последняя)
+func paginate[T any](all []T, offset int, limit int32) ([]T, string) {
+	if offset >= len(all) {
+		return []T{}, ""
+	}
+	end := len(all)
+	next := ""
+	if limit > 0 && offset+int(limit) < end {
+		end = offset + int(limit)
+		next = encodeCursor(end)
+	}
+	return all[offset:end], next
+}
+
+// encodeCursor кодирует позицию следующей страницы в непрозрачный курсор
+func encodeCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(offset)))
+}
+
+// decodeCursor возвращает позицию, закодированную курсором ("" - начало)
+func decodeCursor(cursor string) (int, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, fmt.Errorf("%w: malformed cursor", ErrInvalidQuery)
+	}
+	offset, err := strconv.Atoi(string(raw))
+	if err != nil || offset < 0 {
+		return 0, fmt.Errorf("%w: malformed cursor", ErrInvalidQuery)
+	}
+	return offset, nil
+}
@@ -53,7 +53,8 @@ type EventStats struct {
 
 // ReplayService представляет сервис воспроизведения событий
 type ReplayService struct {
-	eventStore EventStore
+	eventStore    EventStore
+	healthChecker HealthChecker // Проверка бэкенда событий (по умолчанию - сам eventStore, если умеет)
 }
 
 // NewReplayService создает новый сервис воспроизведения
@@ -65,6 +66,27 @@ func NewReplayService(eventStore EventStore) *ReplayService {
 
 // StreamEvents возвращает поток событий по фильтру
 func (s *ReplayService) StreamEvents(ctx context.Context, filter *ReplayFilter) ([]events.Event, error) {
+	eventEnvelopes, err := s.QueryEnvelopes(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// Конвертируем в простые события
+	result := make([]events.Event, len(eventEnvelopes))
+	for i, envelope := range eventEnvelopes {
+		result[i] = events.Event{
+			Type:      events.EventType(envelope.EventType),
+			Timestamp: envelope.Timestamp.Unix(),
+			Data:      envelope.Metadata,
+		}
+	}
+
+	return result, nil
+}
+
+// QueryEnvelopes возвращает записи хранилища по фильтру вместе с ID,
+// регионом и узлом-источником события
+func (s *ReplayService) QueryEnvelopes(ctx context.Context, filter *ReplayFilter) ([]*EventEnvelope, error) {
 	if s.eventStore == nil {
 		return nil, fmt.Errorf("event store not configured")
 	}
@@ -87,18 +109,7 @@ func (s *ReplayService) StreamEvents(ctx context.Context, filter *ReplayFilter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query events: %w", err)
 	}
-
-	// Конвертируем в простые события
-	result := make([]events.Event, len(eventEnvelopes))
-	for i, envelope := range eventEnvelopes {
-		result[i] = events.Event{
-			Type:      events.EventType(envelope.EventType),
-			Timestamp: envelope.Timestamp.Unix(),
-			Data:      envelope.Metadata,
-		}
-	}
-
-	return result, nil
+	return eventEnvelopes, nil
 }
 
 // GetEventStats возвращает статистику событий
@@ -124,12 +135,25 @@ func (s *ReplayService) GetEventStats(ctx context.Context, filter *ReplayFilter)
 	}, nil
 }
 
-// GetEventTypes возвращает доступные типы событий
+// GetEventTypes возвращает доступные типы событий (из каталога хранилища,
+// если оно его ведёт, см. EventTypeIndex)
 func (s *ReplayService) GetEventTypes(ctx context.Context) ([]string, error) {
 	if s.eventStore == nil {
 		return nil, fmt.Errorf("event store not configured")
 	}
 
+	if _, indexed := s.eventStore.(EventTypeIndex); indexed {
+		infos, err := s.GetEventTypeInfo(ctx)
+		if err != nil {
+			return nil, err
+		}
+		names := make([]string, len(infos))
+		for i, info := range infos {
+			names[i] = info.EventType
+		}
+		return names, nil
+	}
+
 	types, err := s.eventStore.GetEventTypes(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get event types: %w", err)
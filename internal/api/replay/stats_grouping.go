@@ -0,0 +1,196 @@
+package replay
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	replaypb "github.com/annel0/mmo-game/internal/protocol/replay"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// StatsGroupBy - режим группировки статистики событий
+type StatsGroupBy = replaypb.EventStatsRequest_StatsGroupBy
+
+// UnassignedRegion - ключ группы для событий без региона
+const UnassignedRegion = "unassigned"
+
+// StatsBucket - количество событий в одной группе
+type StatsBucket struct {
+	Key         string           `json:"key"`
+	Count       int64            `json:"count"`
+	PeriodStart time.Time        `json:"period_start,omitempty"` // Только для группировки по времени
+	PeriodEnd   time.Time        `json:"period_end,omitempty"`
+	EventTypes  map[string]int64 `json:"event_types"` // Детализация по типам
+}
+
+// ParseStatsGroupBy разбирает режим группировки из командной строки:
+// type, region, hour, day
+func ParseStatsGroupBy(s string) (StatsGroupBy, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "type", "event_type":
+		return replaypb.EventStatsRequest_STATS_GROUP_BY_EVENT_TYPE, nil
+	case "region":
+		return replaypb.EventStatsRequest_STATS_GROUP_BY_REGION, nil
+	case "hour":
+		return replaypb.EventStatsRequest_STATS_GROUP_BY_HOUR, nil
+	case "day":
+		return replaypb.EventStatsRequest_STATS_GROUP_BY_DAY, nil
+	default:
+		return 0, fmt.Errorf("unknown group-by %q (expected type, region, hour or day)", s)
+	}
+}
+
+// GroupEvents раскладывает события по группам. Группы по типу и региону
+// упорядочены по ключу, временные интервалы - по возрастанию времени (UTC)
+func GroupEvents(envelopes []*EventEnvelope, groupBy StatsGroupBy) []StatsBucket {
+	var period time.Duration
+	switch groupBy {
+	case replaypb.EventStatsRequest_STATS_GROUP_BY_HOUR:
+		period = time.Hour
+	case replaypb.EventStatsRequest_STATS_GROUP_BY_DAY:
+		period = 24 * time.Hour
+	}
+
+	buckets := make(map[string]*StatsBucket)
+	for _, envelope := range envelopes {
+		var (
+			key   string
+			start time.Time
+		)
+		switch {
+		case period > 0:
+			start = envelope.Timestamp.UTC().Truncate(period)
+			key = start.Format(time.RFC3339)
+		case groupBy == replaypb.EventStatsRequest_STATS_GROUP_BY_REGION:
+			key = envelope.RegionID
+			if key == "" {
+				key = UnassignedRegion
+			}
+		default:
+			key = envelope.EventType
+		}
+
+		bucket, ok := buckets[key]
+		if !ok {
+			bucket = &StatsBucket{Key: key, EventTypes: make(map[string]int64)}
+			if period > 0 {
+				bucket.PeriodStart = start
+				bucket.PeriodEnd = start.Add(period)
+			}
+			buckets[key] = bucket
+		}
+		bucket.Count++
+		bucket.EventTypes[envelope.EventType]++
+	}
+
+	result := make([]StatsBucket, 0, len(buckets))
+	for _, bucket := range buckets {
+		result = append(result, *bucket)
+	}
+	// Ключи временных групп в RFC3339 UTC, поэтому сортировка по ключу
+	// совпадает с хронологической
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Key < result[j].Key
+	})
+	return result
+}
+
+// GroupedEventStats возвращает количество событий по фильтру, сгруппированное
+// по типу, региону или интервалу времени
+func (s *ReplayService) GroupedEventStats(ctx context.Context, filter *ReplayFilter, groupBy StatsGroupBy) ([]StatsBucket, error) {
+	if s.eventStore == nil {
+		return nil, fmt.Errorf("event store not configured")
+	}
+
+	query := EventQuery{
+		EventTypes: make([]string, len(filter.EventTypes)),
+		Region:     filter.Region,
+		PlayerID:   filter.PlayerID,
+		StartTime:  filter.StartTime,
+		EndTime:    filter.EndTime,
+	}
+	for i, t := range filter.EventTypes {
+		query.EventTypes[i] = string(t)
+	}
+
+	envelopes, err := s.eventStore.QueryEvents(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query events: %w", err)
+	}
+
+	return GroupEvents(envelopes, groupBy), nil
+}
+
+// EventStats обрабатывает RPC ReplayService.GetEventStats
+func (s *ReplayService) EventStats(ctx context.Context, req *replaypb.EventStatsRequest) (*replaypb.EventStatsResponse, error) {
+	if s.eventStore == nil {
+		return nil, fmt.Errorf("event store not configured")
+	}
+
+	query := EventQuery{EventTypes: req.GetEventTypes()}
+	if req.GetStartTime() != nil {
+		start := req.GetStartTime().AsTime()
+		query.StartTime = &start
+	}
+	if req.GetEndTime() != nil {
+		end := req.GetEndTime().AsTime()
+		query.EndTime = &end
+	}
+	if len(req.GetRegionIds()) == 1 {
+		query.Region = req.GetRegionIds()[0]
+	}
+
+	envelopes, err := s.eventStore.QueryEvents(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query events: %w", err)
+	}
+	envelopes = filterRegions(envelopes, req.GetRegionIds())
+
+	resp := &replaypb.EventStatsResponse{TotalEvents: int64(len(envelopes))}
+	for _, bucket := range GroupEvents(envelopes, req.GetGroupBy()) {
+		stat := &replaypb.EventStat{
+			GroupKey:        bucket.Key,
+			EventCount:      bucket.Count,
+			EventTypeCounts: bucket.EventTypes,
+		}
+		if !bucket.PeriodStart.IsZero() {
+			stat.PeriodStart = timestamppb.New(bucket.PeriodStart)
+			stat.PeriodEnd = timestamppb.New(bucket.PeriodEnd)
+		}
+		resp.Stats = append(resp.Stats, stat)
+	}
+
+	for i, envelope := range envelopes {
+		if i == 0 || envelope.Timestamp.Before(resp.OldestEvent.AsTime()) {
+			resp.OldestEvent = timestamppb.New(envelope.Timestamp)
+		}
+		if i == 0 || envelope.Timestamp.After(resp.NewestEvent.AsTime()) {
+			resp.NewestEvent = timestamppb.New(envelope.Timestamp)
+		}
+	}
+
+	return resp, nil
+}
+
+// filterRegions оставляет события из указанных регионов (пустой список - все)
+func filterRegions(envelopes []*EventEnvelope, regions []string) []*EventEnvelope {
+	if len(regions) == 0 {
+		return envelopes
+	}
+
+	allowed := make(map[string]bool, len(regions))
+	for _, region := range regions {
+		allowed[region] = true
+	}
+
+	filtered := envelopes[:0:0]
+	for _, envelope := range envelopes {
+		if allowed[envelope.RegionID] {
+			filtered = append(filtered, envelope)
+		}
+	}
+	return filtered
+}
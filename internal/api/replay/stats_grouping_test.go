@@ -0,0 +1,137 @@
+package replay
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	replaypb "github.com/annel0/mmo-game/internal/protocol/replay"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryEventStore возвращает заданные события, фильтруя по региону и типам
+type memoryEventStore struct {
+	stubEventStore
+	events []*EventEnvelope
+}
+
+func (s *memoryEventStore) QueryEvents(ctx context.Context, query EventQuery) ([]*EventEnvelope, error) {
+	var result []*EventEnvelope
+	for _, envelope := range s.events {
+		if query.Region != "" && envelope.RegionID != query.Region {
+			continue
+		}
+		if len(query.EventTypes) > 0 && !contains(query.EventTypes, envelope.EventType) {
+			continue
+		}
+		result = append(result, envelope)
+	}
+	return result, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// knownEvents: 3 события в eu-west и 2 в us-east в 10:xx, 1 без региона в 12:xx
+func knownEvents() []*EventEnvelope {
+	base := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	return []*EventEnvelope{
+		{EventType: "block", RegionID: "eu-west", Timestamp: base.Add(5 * time.Minute)},
+		{EventType: "block", RegionID: "eu-west", Timestamp: base.Add(59 * time.Minute)},
+		{EventType: "chat", RegionID: "eu-west", Timestamp: base.Add(70 * time.Minute)},
+		{EventType: "world", RegionID: "us-east", Timestamp: base.Add(10 * time.Minute)},
+		{EventType: "chat", RegionID: "us-east", Timestamp: base.Add(75 * time.Minute)},
+		{EventType: "system", Timestamp: base.Add(2*time.Hour + 30*time.Minute)},
+	}
+}
+
+func TestGroupEvents_ByRegion(t *testing.T) {
+	buckets := GroupEvents(knownEvents(), replaypb.EventStatsRequest_STATS_GROUP_BY_REGION)
+
+	require.Len(t, buckets, 3)
+	assert.Equal(t, "eu-west", buckets[0].Key)
+	assert.Equal(t, int64(3), buckets[0].Count)
+	assert.Equal(t, map[string]int64{"block": 2, "chat": 1}, buckets[0].EventTypes)
+	assert.Equal(t, UnassignedRegion, buckets[1].Key)
+	assert.Equal(t, int64(1), buckets[1].Count)
+	assert.Equal(t, "us-east", buckets[2].Key)
+	assert.Equal(t, int64(2), buckets[2].Count)
+}
+
+func TestGroupEvents_ByHour(t *testing.T) {
+	buckets := GroupEvents(knownEvents(), replaypb.EventStatsRequest_STATS_GROUP_BY_HOUR)
+
+	require.Len(t, buckets, 3)
+	counts := make([]int64, len(buckets))
+	for i, bucket := range buckets {
+		counts[i] = bucket.Count
+		assert.Equal(t, time.Hour, bucket.PeriodEnd.Sub(bucket.PeriodStart))
+	}
+	assert.Equal(t, []int64{3, 2, 1}, counts)
+	assert.Equal(t, "2024-05-01T10:00:00Z", buckets[0].Key)
+	assert.Equal(t, "2024-05-01T11:00:00Z", buckets[1].Key)
+	assert.Equal(t, "2024-05-01T12:00:00Z", buckets[2].Key)
+	assert.Equal(t, map[string]int64{"chat": 2}, buckets[1].EventTypes)
+}
+
+func TestGroupEvents_StableOrder(t *testing.T) {
+	events := knownEvents()
+	first := GroupEvents(events, replaypb.EventStatsRequest_STATS_GROUP_BY_EVENT_TYPE)
+
+	// Обратный порядок входа не меняет порядок групп
+	for i, j := 0, len(events)-1; i < j; i, j = i+1, j-1 {
+		events[i], events[j] = events[j], events[i]
+	}
+	second := GroupEvents(events, replaypb.EventStatsRequest_STATS_GROUP_BY_EVENT_TYPE)
+
+	assert.Equal(t, first, second)
+	assert.Equal(t, "block", first[0].Key)
+}
+
+func TestEventStats_RPC(t *testing.T) {
+	service := NewReplayService(&memoryEventStore{events: knownEvents()})
+
+	resp, err := service.EventStats(context.Background(), &replaypb.EventStatsRequest{
+		RegionIds: []string{"eu-west", "us-east"},
+		GroupBy:   replaypb.EventStatsRequest_STATS_GROUP_BY_HOUR,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(5), resp.GetTotalEvents())
+	require.Len(t, resp.GetStats(), 2)
+	assert.Equal(t, int64(3), resp.GetStats()[0].GetEventCount())
+	assert.Equal(t, int64(2), resp.GetStats()[1].GetEventCount())
+	assert.Equal(t, time.Date(2024, 5, 1, 11, 0, 0, 0, time.UTC), resp.GetStats()[1].GetPeriodStart().AsTime())
+	assert.Equal(t, time.Date(2024, 5, 1, 10, 5, 0, 0, time.UTC), resp.GetOldestEvent().AsTime())
+	assert.Equal(t, time.Date(2024, 5, 1, 11, 15, 0, 0, time.UTC), resp.GetNewestEvent().AsTime())
+}
+
+func TestGroupedEventStats_FiltersRegion(t *testing.T) {
+	service := NewReplayService(&memoryEventStore{events: knownEvents()})
+
+	buckets, err := service.GroupedEventStats(context.Background(), &ReplayFilter{Region: "us-east"}, replaypb.EventStatsRequest_STATS_GROUP_BY_HOUR)
+	require.NoError(t, err)
+	require.Len(t, buckets, 2)
+	assert.Equal(t, int64(1), buckets[0].Count)
+	assert.Equal(t, int64(1), buckets[1].Count)
+}
+
+func TestParseStatsGroupBy(t *testing.T) {
+	mode, err := ParseStatsGroupBy("Region")
+	require.NoError(t, err)
+	assert.Equal(t, replaypb.EventStatsRequest_STATS_GROUP_BY_REGION, mode)
+
+	mode, err = ParseStatsGroupBy("hour")
+	require.NoError(t, err)
+	assert.Equal(t, replaypb.EventStatsRequest_STATS_GROUP_BY_HOUR, mode)
+
+	_, err = ParseStatsGroupBy("week")
+	assert.Error(t, err)
+}
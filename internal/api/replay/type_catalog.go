@@ -0,0 +1,180 @@
+package replay
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// EventTypeInfo - сводка по типу событий в хранилище
+type EventTypeInfo struct {
+	EventType string    `json:"event_type"`
+	Count     int64     `json:"count"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+	Regions   []string  `json:"regions,omitempty"` // По алфавиту
+}
+
+// EventTypeIndex - хранилище, которое ведёт каталог типов событий само и
+// отдаёт его без просмотра событий. Необязательное расширение EventStore
+type EventTypeIndex interface {
+	EventTypeInfos(ctx context.Context) ([]EventTypeInfo, error)
+}
+
+// catalogEntry - события одного типа в каталоге
+type catalogEntry struct {
+	count     int64
+	firstSeen time.Time
+	lastSeen  time.Time
+	regions   map[string]int // Событий по регионам
+	stale     bool           // Устарело крайнее событие: firstSeen/lastSeen пересчитываются при чтении
+}
+
+// TypeCatalog - каталог типов событий, обновляемый при поступлении и
+// устаревании событий: сводка по типам строится без просмотра хранилища.
+// Для типа хранятся только счётчики и крайние времена; если устарело
+// крайнее событие, времена пересчитываются при следующем чтении (Refresh)
+type TypeCatalog struct {
+	mu      sync.RWMutex
+	entries map[string]*catalogEntry
+}
+
+// NewTypeCatalog создаёт пустой каталог
+func NewTypeCatalog() *TypeCatalog {
+	return &TypeCatalog{entries: make(map[string]*catalogEntry)}
+}
+
+// Observe учитывает новое событие
+func (c *TypeCatalog) Observe(env *EventEnvelope) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[env.EventType]
+	if !ok {
+		entry = &catalogEntry{regions: make(map[string]int), firstSeen: env.Timestamp, lastSeen: env.Timestamp}
+		c.entries[env.EventType] = entry
+	}
+	entry.count++
+	if env.Timestamp.Before(entry.firstSeen) {
+		entry.firstSeen = env.Timestamp
+	}
+	if env.Timestamp.After(entry.lastSeen) {
+		entry.lastSeen = env.Timestamp
+	}
+	if env.RegionID != "" {
+		entry.regions[env.RegionID]++
+	}
+}
+
+// Expire убирает устаревшее событие, учтённое Observe. Тип без событий
+// удаляется из каталога
+func (c *TypeCatalog) Expire(env *EventEnvelope) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[env.EventType]
+	if !ok {
+		return
+	}
+	if entry.count--; entry.count <= 0 {
+		delete(c.entries, env.EventType)
+		return
+	}
+	if !env.Timestamp.After(entry.firstSeen) || !env.Timestamp.Before(entry.lastSeen) {
+		entry.stale = true
+	}
+	if env.RegionID != "" {
+		if entry.regions[env.RegionID]--; entry.regions[env.RegionID] <= 0 {
+			delete(entry.regions, env.RegionID)
+		}
+	}
+}
+
+// Stale возвращает типы, крайние времена которых нужно пересчитать
+func (c *TypeCatalog) Stale() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var stale []string
+	for eventType, entry := range c.entries {
+		if entry.stale {
+			stale = append(stale, eventType)
+		}
+	}
+	return stale
+}
+
+// Refresh задаёт пересчитанные крайние времена типа
+func (c *TypeCatalog) Refresh(eventType string, firstSeen, lastSeen time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if entry, ok := c.entries[eventType]; ok {
+		entry.firstSeen, entry.lastSeen = firstSeen, lastSeen
+		entry.stale = false
+	}
+}
+
+// Types возвращает сводку по типам в алфавитном порядке
+func (c *TypeCatalog) Types() []EventTypeInfo {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	infos := make([]EventTypeInfo, 0, len(c.entries))
+	for eventType, entry := range c.entries {
+		info := EventTypeInfo{
+			EventType: eventType,
+			Count:     entry.count,
+			FirstSeen: entry.firstSeen,
+			LastSeen:  entry.lastSeen,
+		}
+		for region := range entry.regions {
+			info.Regions = append(info.Regions, region)
+		}
+		sort.Strings(info.Regions)
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].EventType < infos[j].EventType })
+	return infos
+}
+
+// Names возвращает типы событий в алфавитном порядке
+func (c *TypeCatalog) Names() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	names := make([]string, 0, len(c.entries))
+	for eventType := range c.entries {
+		names = append(names, eventType)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetEventTypeInfo возвращает сводку по типам событий: из каталога
+// хранилища (EventTypeIndex) или, если его нет, просмотром всех событий
+func (s *ReplayService) GetEventTypeInfo(ctx context.Context) ([]EventTypeInfo, error) {
+	if s.eventStore == nil {
+		return nil, fmt.Errorf("event store not configured")
+	}
+
+	if index, ok := s.eventStore.(EventTypeIndex); ok {
+		infos, err := index.EventTypeInfos(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get event types: %w", err)
+		}
+		return infos, nil
+	}
+
+	envelopes, err := s.eventStore.QueryEvents(ctx, EventQuery{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query events: %w", err)
+	}
+	catalog := NewTypeCatalog()
+	for _, env := range envelopes {
+		catalog.Observe(env)
+	}
+	return catalog.Types(), nil
+}
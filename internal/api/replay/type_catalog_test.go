@@ -0,0 +1,112 @@
+package replay
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// noScanStore запрещает просмотр событий: сводка по типам должна браться из каталога
+type noScanStore struct {
+	*MemoryEventStore
+	t *testing.T
+}
+
+func (s noScanStore) QueryEvents(ctx context.Context, query EventQuery) ([]*EventEnvelope, error) {
+	s.t.Fatal("сводка по типам не должна просматривать события")
+	return nil, nil
+}
+
+func TestTypeCatalog_ReflectsIngestedTypesWithoutScan(t *testing.T) {
+	store := NewMemoryEventStore(0)
+	service := NewReplayService(noScanStore{MemoryEventStore: store, t: t})
+	ctx := context.Background()
+	base := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	store.Ingest(&EventEnvelope{EventType: "block", RegionID: "eu-west", Timestamp: base})
+	store.Ingest(&EventEnvelope{EventType: "block", RegionID: "us-east", Timestamp: base.Add(time.Minute)})
+
+	infos, err := service.GetEventTypeInfo(ctx)
+	require.NoError(t, err)
+	require.Len(t, infos, 1)
+	assert.Equal(t, EventTypeInfo{
+		EventType: "block",
+		Count:     2,
+		FirstSeen: base,
+		LastSeen:  base.Add(time.Minute),
+		Regions:   []string{"eu-west", "us-east"},
+	}, infos[0])
+
+	// Новый тип виден сразу после поступления события
+	store.Ingest(&EventEnvelope{EventType: "chat", RegionID: "eu-west", Timestamp: base.Add(2 * time.Minute)})
+	types, err := service.GetEventTypes(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"block", "chat"}, types)
+
+	infos, err = service.GetEventTypeInfo(ctx)
+	require.NoError(t, err)
+	require.Len(t, infos, 2)
+	assert.Equal(t, int64(1), infos[1].Count)
+	assert.Equal(t, base.Add(2*time.Minute), infos[1].FirstSeen)
+}
+
+func TestTypeCatalog_ExpiredEventsLeaveCatalog(t *testing.T) {
+	store := NewMemoryEventStore(time.Hour)
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	store.Ingest(&EventEnvelope{EventType: "world", RegionID: "us-east", Timestamp: now})
+	store.Ingest(&EventEnvelope{EventType: "block", RegionID: "eu-west", Timestamp: now.Add(10 * time.Minute)})
+	store.Ingest(&EventEnvelope{EventType: "block", RegionID: "us-east", Timestamp: now.Add(40 * time.Minute)})
+
+	// Через час с небольшим устарели первые два события
+	now = now.Add(61 * time.Minute)
+	infos, err := store.EventTypeInfos(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []EventTypeInfo{{
+		EventType: "block",
+		Count:     2,
+		FirstSeen: now.Add(-51 * time.Minute),
+		LastSeen:  now.Add(-21 * time.Minute),
+		Regions:   []string{"eu-west", "us-east"},
+	}}, infos, "устарело только событие world")
+
+	now = now.Add(10 * time.Minute)
+	infos, err = store.EventTypeInfos(ctx)
+	require.NoError(t, err)
+	require.Len(t, infos, 1)
+	assert.Equal(t, int64(1), infos[0].Count)
+	assert.Equal(t, []string{"us-east"}, infos[0].Regions)
+	assert.Equal(t, infos[0].LastSeen, infos[0].FirstSeen)
+
+	stats, err := store.GetEventStats(ctx, EventQuery{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), stats.TotalEvents)
+}
+
+func TestTypeCatalog_RecomputesBoundsAfterExpiry(t *testing.T) {
+	store := NewMemoryEventStore(time.Hour)
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	// Событие с более ранним временем пришло последним
+	store.Ingest(&EventEnvelope{EventType: "block", Timestamp: now})
+	store.Ingest(&EventEnvelope{EventType: "block", Timestamp: now.Add(30 * time.Minute)})
+	store.Ingest(&EventEnvelope{EventType: "block", Timestamp: now.Add(10 * time.Minute)})
+
+	// Устарело первое событие: первое время берётся из оставшихся, а не
+	// из порядка поступления
+	now = now.Add(61 * time.Minute)
+	infos, err := store.EventTypeInfos(ctx)
+	require.NoError(t, err)
+	require.Len(t, infos, 1)
+	assert.Equal(t, int64(2), infos[0].Count)
+	assert.Equal(t, now.Add(-51*time.Minute), infos[0].FirstSeen)
+	assert.Equal(t, now.Add(-31*time.Minute), infos[0].LastSeen)
+	assert.Empty(t, store.catalog.Stale(), "пересчёт выполняется один раз")
+}
@@ -1,7 +1,9 @@
 package api
 
 import (
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"strconv"
 	"strings"
@@ -9,6 +11,7 @@ import (
 
 	"github.com/annel0/mmo-game/internal/auth"
 	"github.com/annel0/mmo-game/internal/middleware"
+	"github.com/annel0/mmo-game/internal/tlsreload"
 	"github.com/annel0/mmo-game/internal/world/entity"
 	"github.com/gin-gonic/gin"
 	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
@@ -18,18 +21,54 @@ import (
 type RestServer struct {
 	router           *gin.Engine
 	userRepo         auth.UserRepository
+	authProvider     auth.AuthProvider
 	entityManager    *entity.EntityManager
 	port             string
+	tls              *tlsreload.Reloader // nil - без TLS
 	metrics          *ServerMetrics
 	webhookConfig    WebhookConfig
 	outboundWebhooks *OutboundWebhookManager
+	worldHash        WorldHashProvider
+	runtime          runtimeAdmin
+	health           healthChecks
+	drain            drainAdmin
+	teleport         teleportAdmin
+	stealth          stealthAdmin
+	gameMode         gameModeAdmin
+	claims           claimAdmin
+	spawn            spawnAdmin
+	regionSnapshots  regionSnapshotAdmin
+	eventLog         eventLogAdmin
+	worldEvents      worldEventAdmin
 }
 
+// WorldHashInfo описывает хэш состояния мира региона
+type WorldHashInfo struct {
+	RegionID   string    `json:"region_id"`
+	Hash       string    `json:"hash"`
+	Chunks     int       `json:"chunks"`
+	ComputedAt time.Time `json:"computed_at"`
+}
+
+// WorldHashProvider вычисляет хэш текущего состояния мира
+type WorldHashProvider func() WorldHashInfo
+
 // Config содержит конфигурацию для REST сервера
 type Config struct {
 	Port          string                // порт для запуска сервера
 	UserRepo      auth.UserRepository   // репозиторий пользователей
 	EntityManager *entity.EntityManager // менеджер сущностей
+
+	// CORS для публичных эндпоинтов и для /api/admin (по умолчанию кросс-доменные запросы запрещены)
+	CORS      CORSPolicy
+	AdminCORS CORSPolicy
+
+	// Проверка учётных данных при входе (nil - пароль из репозитория пользователей)
+	AuthProvider auth.AuthProvider
+
+	// Сертификаты TLS (nil - открытым текстом, только для локальной отладки).
+	// С CA клиентских сертификатов /api/admin требует сертификат клиента
+	TLS *tlsreload.Reloader
 }
 
 // NewRestServer создает новый REST API сервер
@@ -37,6 +76,9 @@ func NewRestServer(config Config) *RestServer {
 	if config.Port == "" {
 		config.Port = ":8080"
 	}
+	if config.AuthProvider == nil {
+		config.AuthProvider = auth.LocalProvider{}
+	}
 
 	// Устанавливаем режим релиза для gin
 	gin.SetMode(gin.ReleaseMode)
@@ -58,8 +100,10 @@ func NewRestServer(config Config) *RestServer {
 	server := &RestServer{
 		router:        router,
 		userRepo:      config.UserRepo,
+		authProvider:  config.AuthProvider,
 		entityManager: config.EntityManager,
 		port:          config.Port,
+		tls:           config.TLS,
 		metrics:       NewServerMetrics(),
 		webhookConfig: WebhookConfig{
 			SecretKey:        "", // Можно настроить через переменные окружения
@@ -69,6 +113,9 @@ func NewRestServer(config Config) *RestServer {
 		outboundWebhooks: NewOutboundWebhookManager("game_server_01", "development"),
 	}
 
+	// CORS до маршрутов: preflight-запросы не попадают ни в один обработчик
+	router.Use(newCORSMiddleware(config.CORS, config.AdminCORS))
+
 	// Настраиваем маршруты
 	server.setupRoutes()
 
@@ -77,20 +124,6 @@ func NewRestServer(config Config) *RestServer {
 
 // setupRoutes настраивает маршруты REST API
 func (rs *RestServer) setupRoutes() {
-	// Middleware для CORS
-	rs.router.Use(func(c *gin.Context) {
-		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Authorization")
-
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
-			return
-		}
-
-		c.Next()
-	})
-
 	// Группа API
 	api := rs.router.Group("/api")
 
@@ -110,7 +143,7 @@ func (rs *RestServer) setupRoutes() {
 
 		// Административные эндпоинты (только для админов)
 		admin := protected.Group("/admin")
-		admin.Use(rs.adminMiddleware())
+		admin.Use(rs.adminClientCertMiddleware(), rs.adminMiddleware())
 		{
 			admin.POST("/register", rs.handleAdminRegister)
 			admin.GET("/users", rs.handleGetUsers)
@@ -126,14 +159,54 @@ func (rs *RestServer) setupRoutes() {
 			admin.POST("/webhooks/:id/test", rs.handleTestOutboundWebhook)
 			admin.GET("/webhooks/events", rs.handleGetWebhookEventTypes)
 			admin.POST("/events/send", rs.handleSendEvent)
+
+			// Журнал событий сервера (REST-доступ к сервису воспроизведения)
+			admin.GET("/events", rs.handleQueryEvents)
+			admin.GET("/events/types", rs.handleEventTypes)
+
+			// Целостность мира
+			admin.GET("/world/hash", rs.handleWorldHash)
+			admin.GET("/world/region", rs.handleExportRegion)
+			admin.PUT("/world/region", rs.handleImportRegion)
+
+			// События мира для игроков (метеоритный дождь, босс, объявления)
+			admin.POST("/world/event", rs.handleWorldEvent)
+
+			// Параметры сервера без перезапуска
+			admin.GET("/runtime", rs.handleGetRuntime)
+			admin.PUT("/runtime", rs.handleUpdateRuntime)
+
+			// Вывод сервера из работы перед остановкой
+			admin.POST("/drain", rs.handleStartDrain)
+			admin.GET("/drain", rs.handleDrainStatus)
+			admin.DELETE("/drain", rs.handleStopDrain)
+
+			// Перемещение игрока с предзагрузкой чанков
+			admin.POST("/teleport", rs.handleTeleport)
+
+			// Невидимость администратора для обычных игроков
+			admin.POST("/stealth", rs.handleStealth)
+
+			// Режим игры игрока в сети
+			admin.POST("/gamemode", rs.handleGameMode)
+
+			// Приваты: области, где строят только владелец и администраторы
+			admin.GET("/claims", rs.handleListClaims)
+			admin.POST("/claims", rs.handleCreateClaim)
+			admin.DELETE("/claims/:id", rs.handleDeleteClaim)
+
+			// Точка спавна мира
+			admin.GET("/spawn", rs.handleGetSpawn)
+			admin.PUT("/spawn", rs.handleSetSpawn)
 		}
 	}
 
 	// Webhook (без аутентификации, но с валидацией)
 	api.POST("/webhook", rs.HandleWebhook)
 
-	// Health check
+	// Готовность (проверяет подсистемы) и живость процесса
 	rs.router.GET("/health", rs.handleHealth)
+	rs.router.GET("/livez", rs.handleLivez)
 }
 
 // LoginRequest представляет запрос на вход
@@ -176,9 +249,16 @@ func (rs *RestServer) handleLogin(c *gin.Context) {
 		return
 	}
 
-	// Получаем пользователя из БД
-	user, err := rs.userRepo.GetUserByUsername(req.Username)
-	if err == auth.ErrUserNotFound {
+	// Проверяем пароль (или токен) через провайдер аутентификации
+	user, err := rs.authProvider.Authenticate(c.Request.Context(), rs.userRepo, req.Username, req.Password)
+	if errors.Is(err, auth.ErrAccountLocked) {
+		c.JSON(http.StatusTooManyRequests, LoginResponse{
+			Success: false,
+			Message: "Слишком много неудачных попыток входа, попробуйте позже",
+		})
+		return
+	}
+	if errors.Is(err, auth.ErrUserNotFound) || errors.Is(err, auth.ErrInvalidCredentials) {
 		c.JSON(http.StatusUnauthorized, LoginResponse{
 			Success: false,
 			Message: "Неверное имя пользователя или пароль",
@@ -193,15 +273,6 @@ func (rs *RestServer) handleLogin(c *gin.Context) {
 		return
 	}
 
-	// Проверяем пароль
-	if !auth.CheckPassword(user.PasswordHash, req.Password) {
-		c.JSON(http.StatusUnauthorized, LoginResponse{
-			Success: false,
-			Message: "Неверное имя пользователя или пароль",
-		})
-		return
-	}
-
 	// Генерируем JWT токен
 	token, err := auth.GenerateJWT(user)
 	if err != nil {
@@ -355,6 +426,28 @@ func (rs *RestServer) handleServerInfo(c *gin.Context) {
 	})
 }
 
+// SetWorldHashProvider задаёт источник хэша состояния мира для /api/admin/world/hash
+func (rs *RestServer) SetWorldHashProvider(provider WorldHashProvider) {
+	rs.worldHash = provider
+}
+
+// handleWorldHash возвращает хэш загруженных чанков мира (только для админов)
+func (rs *RestServer) handleWorldHash(c *gin.Context) {
+	if rs.worldHash == nil {
+		c.JSON(http.StatusServiceUnavailable, GenericResponse{
+			Success: false,
+			Message: "Хэш мира недоступен",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, GenericResponse{
+		Success: true,
+		Message: "Хэш состояния мира",
+		Data:    rs.worldHash(),
+	})
+}
+
 // handleGetUsers возвращает список пользователей (только для админов)
 func (rs *RestServer) handleGetUsers(c *gin.Context) {
 	// Параметры пагинации
@@ -435,17 +528,13 @@ func (rs *RestServer) handleWebhook(c *gin.Context) {
 	})
 }
 
-// handleHealth проверка состояния сервера
-func (rs *RestServer) handleHealth(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{
-		"status": "ok",
-		"time":   time.Now().Unix(),
-	})
-}
-
 // Start запускает REST сервер
 func (rs *RestServer) Start() error {
-	return rs.router.Run(rs.port)
+	ln, err := net.Listen("tcp", rs.port)
+	if err != nil {
+		return err
+	}
+	return rs.serve(rs.newHTTPServer(), ln)
 }
 
 // Stop останавливает REST сервер (заглушка для graceful shutdown)
@@ -0,0 +1,137 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+
+	"github.com/annel0/mmo-game/internal/network"
+	"github.com/gin-gonic/gin"
+)
+
+// RuntimeTuner читает и меняет параметры работающего игрового сервера
+type RuntimeTuner interface {
+	RuntimeParams() network.RuntimeParams
+	SetRuntimeParams(params network.RuntimeParams) error
+}
+
+// runtimeAdmin обслуживает /api/admin/runtime
+type runtimeAdmin struct {
+	mu            sync.Mutex // Последовательные PUT: чтение, применение и сохранение
+	tuner         RuntimeTuner
+	overridesPath string // Файл переопределений ("" - не сохранять)
+}
+
+// SetRuntimeTuner подключает /api/admin/runtime к игровому серверу.
+// Принятые изменения сохраняются в overridesPath и применяются при следующем запуске
+func (rs *RestServer) SetRuntimeTuner(tuner RuntimeTuner, overridesPath string) {
+	rs.runtime.mu.Lock()
+	defer rs.runtime.mu.Unlock()
+	rs.runtime.tuner = tuner
+	rs.runtime.overridesPath = overridesPath
+}
+
+// handleGetRuntime возвращает текущие настраиваемые параметры (только для админов)
+func (rs *RestServer) handleGetRuntime(c *gin.Context) {
+	rs.runtime.mu.Lock()
+	tuner := rs.runtime.tuner
+	rs.runtime.mu.Unlock()
+
+	if tuner == nil {
+		c.JSON(http.StatusServiceUnavailable, GenericResponse{
+			Success: false,
+			Message: "Настройка параметров недоступна",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, GenericResponse{
+		Success: true,
+		Message: "Параметры сервера",
+		Data:    tuner.RuntimeParams(),
+	})
+}
+
+// handleUpdateRuntime применяет переданные параметры; отсутствующие в
+// запросе поля сохраняют текущие значения (только для админов)
+func (rs *RestServer) handleUpdateRuntime(c *gin.Context) {
+	rs.runtime.mu.Lock()
+	defer rs.runtime.mu.Unlock()
+
+	tuner := rs.runtime.tuner
+	if tuner == nil {
+		c.JSON(http.StatusServiceUnavailable, GenericResponse{
+			Success: false,
+			Message: "Настройка параметров недоступна",
+		})
+		return
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, GenericResponse{
+			Success: false,
+			Message: "Не удалось прочитать запрос: " + err.Error(),
+		})
+		return
+	}
+
+	before := tuner.RuntimeParams()
+	params, err := network.DecodeRuntimeParams(before, body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, GenericResponse{
+			Success: false,
+			Message: "Неверный формат параметров: " + err.Error(),
+		})
+		return
+	}
+
+	if err := tuner.SetRuntimeParams(params); err != nil {
+		status := http.StatusInternalServerError
+		var rangeErr *network.RuntimeParamError
+		if errors.As(err, &rangeErr) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, GenericResponse{
+			Success: false,
+			Message: "Параметры отклонены: " + err.Error(),
+		})
+		return
+	}
+
+	// В файл попадают только изменённые параметры: остальные при
+	// перезапуске по-прежнему берутся из конфигурации
+	applied := tuner.RuntimeParams()
+	if path := rs.runtime.overridesPath; path != "" {
+		if err := saveRuntimeChanges(path, network.DiffRuntimeParams(before, applied)); err != nil {
+			log.Printf("⚠️ Параметры применены, но не сохранены в %s: %v", path, err)
+			c.JSON(http.StatusOK, GenericResponse{
+				Success: true,
+				Message: "Параметры применены, но не сохранены: " + err.Error(),
+				Data:    applied,
+			})
+			return
+		}
+	}
+
+	log.Printf("🎛️ Параметры сервера изменены: %+v", applied)
+	c.JSON(http.StatusOK, GenericResponse{
+		Success: true,
+		Message: "Параметры применены",
+		Data:    applied,
+	})
+}
+
+// saveRuntimeChanges дописывает изменённые параметры к сохранённым переопределениям
+func saveRuntimeChanges(path string, changed network.RuntimeOverrides) error {
+	if len(changed) == 0 {
+		return nil
+	}
+	saved, err := network.LoadRuntimeOverrides(path)
+	if err != nil {
+		return err
+	}
+	return network.SaveRuntimeOverrides(path, saved.Merge(changed))
+}
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/annel0/mmo-game/internal/anticheat"
+	"github.com/annel0/mmo-game/internal/auth"
+	"github.com/annel0/mmo-game/internal/network"
+	"github.com/annel0/mmo-game/internal/world"
+	"github.com/annel0/mmo-game/internal/world/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	sharedIntegration     *ServerIntegration
+	sharedIntegrationOnce sync.Once
+)
+
+// testIntegration возвращает общую in-memory интеграцию: метрики middleware
+// REST-сервера регистрируются в Prometheus один раз на процесс
+func testIntegration() *ServerIntegration {
+	sharedIntegrationOnce.Do(func() {
+		integration, err := NewServerIntegration(IntegrationConfig{RestPort: ":0"})
+		if err != nil {
+			panic(err)
+		}
+		sharedIntegration = integration
+	})
+	return sharedIntegration
+}
+
+// testRestServer возвращает REST-сервер общей интеграции
+func testRestServer() *RestServer {
+	return testIntegration().GetRestServer()
+}
+
+// newRuntimeTestHandler создаёт игровой обработчик с античитом и подключает его к REST-серверу
+func newRuntimeTestHandler(t *testing.T) (*RestServer, *network.GameHandlerPB, string) {
+	t.Helper()
+
+	users, err := auth.NewMemoryUserRepo()
+	require.NoError(t, err)
+	handler := network.NewGameHandlerPB(world.NewWorldManager(1234), entity.NewEntityManager(), users)
+	handler.SetAnticheat(anticheat.NewEngine(anticheat.Config{}))
+
+	overrides := filepath.Join(t.TempDir(), "runtime_overrides.json")
+	rs := testRestServer()
+	rs.SetRuntimeTuner(handler, overrides)
+	t.Cleanup(func() { rs.SetRuntimeTuner(nil, "") })
+	return rs, handler, overrides
+}
+
+func adminRequest(t *testing.T, rs *RestServer, method, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	token, err := auth.GenerateJWT(&auth.User{ID: 1, Username: "admin", IsAdmin: true})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	rs.router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestRuntimeAdmin_TuneAppliesAndPersists(t *testing.T) {
+	rs, handler, overrides := newRuntimeTestHandler(t)
+	before := handler.RuntimeParams()
+
+	rec := adminRequest(t, rs, http.MethodPut, "/api/admin/runtime", `{"world_update_interval": 5, "max_view_distance": 12, "max_block_edits": 40}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	applied := handler.RuntimeParams()
+	assert.Equal(t, 5, applied.WorldUpdateInterval)
+	assert.Equal(t, 12, applied.MaxViewDistance)
+	assert.Equal(t, 40, applied.MaxBlockEdits)
+	assert.Equal(t, before.AutoSaveSeconds, applied.AutoSaveSeconds, "поля без значения в запросе не меняются")
+	assert.Equal(t, before.BlockEditWindowMs, applied.BlockEditWindowMs)
+
+	// Сохраняются только изменённые параметры
+	saved, err := network.LoadRuntimeOverrides(overrides)
+	require.NoError(t, err)
+	assert.Equal(t, network.DiffRuntimeParams(before, applied), saved)
+	assert.NotContains(t, saved, "autosave_interval_seconds")
+	assert.NotContains(t, saved, "block_edit_window_ms")
+
+	// Следующая правка дополняет файл, не теряя прежних значений
+	rec = adminRequest(t, rs, http.MethodPut, "/api/admin/runtime", `{"autosave_interval_seconds": 90}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	saved, err = network.LoadRuntimeOverrides(overrides)
+	require.NoError(t, err)
+	assert.Equal(t, 90, saved["autosave_interval_seconds"])
+	assert.Equal(t, 12, saved["max_view_distance"])
+	assert.Equal(t, handler.RuntimeParams(), saved.Apply(before))
+
+	rec = adminRequest(t, rs, http.MethodGet, "/api/admin/runtime", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"max_view_distance":12`)
+}
+
+func TestRuntimeAdmin_RejectsOutOfRange(t *testing.T) {
+	rs, handler, overrides := newRuntimeTestHandler(t)
+	before := handler.RuntimeParams()
+
+	rec := adminRequest(t, rs, http.MethodPut, "/api/admin/runtime", `{"world_update_interval": 5, "max_view_distance": 500}`)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "max_view_distance")
+
+	// Ни один параметр не изменился и ничего не сохранено
+	assert.Equal(t, before, handler.RuntimeParams())
+	saved, err := network.LoadRuntimeOverrides(overrides)
+	require.NoError(t, err)
+	assert.Nil(t, saved)
+
+	rec = adminRequest(t, rs, http.MethodPut, "/api/admin/runtime", `{"tick_rate": 30}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code, "неизвестные параметры отклоняются")
+}
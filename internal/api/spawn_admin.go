@@ -0,0 +1,93 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"sync"
+
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/gin-gonic/gin"
+)
+
+// SpawnManager управляет точкой спавна мира
+type SpawnManager interface {
+	GetWorldSpawn() vec.Vec2
+	SetWorldSpawn(pos vec.Vec2) error
+}
+
+// SpawnRequest - тело PUT /api/admin/spawn в координатах блоков
+type SpawnRequest struct {
+	X *int `json:"x" binding:"required"`
+	Y *int `json:"y" binding:"required"`
+}
+
+// spawnAdmin обслуживает /api/admin/spawn
+type spawnAdmin struct {
+	mu      sync.Mutex
+	manager SpawnManager
+}
+
+// SetSpawnManager подключает /api/admin/spawn к миру игрового сервера
+func (rs *RestServer) SetSpawnManager(manager SpawnManager) {
+	rs.spawn.mu.Lock()
+	defer rs.spawn.mu.Unlock()
+	rs.spawn.manager = manager
+}
+
+func (rs *RestServer) spawnManager(c *gin.Context) (SpawnManager, bool) {
+	rs.spawn.mu.Lock()
+	manager := rs.spawn.manager
+	rs.spawn.mu.Unlock()
+
+	if manager == nil {
+		c.JSON(http.StatusServiceUnavailable, GenericResponse{
+			Success: false,
+			Message: "Точка спавна недоступна",
+		})
+		return nil, false
+	}
+	return manager, true
+}
+
+// handleGetSpawn возвращает точку спавна мира (только для админов)
+func (rs *RestServer) handleGetSpawn(c *gin.Context) {
+	manager, ok := rs.spawnManager(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, GenericResponse{
+		Success: true,
+		Data:    manager.GetWorldSpawn(),
+	})
+}
+
+// handleSetSpawn задаёт точку спавна мира (только для админов)
+func (rs *RestServer) handleSetSpawn(c *gin.Context) {
+	manager, ok := rs.spawnManager(c)
+	if !ok {
+		return
+	}
+
+	var req SpawnRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, GenericResponse{
+			Success: false,
+			Message: "Неверный формат запроса: " + err.Error(),
+		})
+		return
+	}
+
+	pos := vec.Vec2{X: *req.X, Y: *req.Y}
+	if err := manager.SetWorldSpawn(pos); err != nil {
+		// Точка действует, но не сохранена вместе с миром
+		log.Printf("⚠️ Точка спавна (%d, %d) задана, но не сохранена: %v", pos.X, pos.Y, err)
+	}
+
+	log.Printf("🏠 Точка спавна мира: (%d, %d)", pos.X, pos.Y)
+	c.JSON(http.StatusOK, GenericResponse{
+		Success: true,
+		Message: "Точка спавна задана",
+		Data:    pos,
+	})
+}
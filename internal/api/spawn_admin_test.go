@@ -0,0 +1,35 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/annel0/mmo-game/internal/world"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpawnAdmin_GetAndSet(t *testing.T) {
+	wm := world.NewWorldManager(1234)
+	rs := testRestServer()
+	rs.SetSpawnManager(wm)
+	t.Cleanup(func() { rs.SetSpawnManager(nil) })
+
+	rec := adminRequest(t, rs, http.MethodGet, "/api/admin/spawn", "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var got struct {
+		Data vec.Vec2 `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+	assert.Equal(t, wm.GetWorldSpawn(), got.Data)
+
+	rec = adminRequest(t, rs, http.MethodPut, "/api/admin/spawn", `{"x": 0, "y": -25}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, vec.Vec2{X: 0, Y: -25}, wm.GetWorldSpawn())
+
+	rec = adminRequest(t, rs, http.MethodPut, "/api/admin/spawn", `{"x": 5}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code, "обе координаты обязательны")
+	assert.Equal(t, vec.Vec2{X: 0, Y: -25}, wm.GetWorldSpawn())
+}
@@ -0,0 +1,146 @@
+package api
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/annel0/mmo-game/internal/auth"
+	"github.com/annel0/mmo-game/internal/storage"
+)
+
+// Компоненты хранилища игроков в отчёте о запуске
+const (
+	ComponentPositions    = "positions"
+	ComponentPlayerStates = "player_states"
+)
+
+// FallbackMemory - компонент заменён in-memory реализацией (данные не сохраняются)
+const FallbackMemory = "memory"
+
+// DegradedComponent - компонент, запущенный с заменой из-за ошибки
+type DegradedComponent struct {
+	Component string `json:"component"`
+	Fallback  string `json:"fallback"`
+	Error     string `json:"error"`
+}
+
+// StartupReport - итог запуска REST API. Недоступный компонент с
+// настроенной заменой не мешает запуску, а попадает в отчёт и в /health
+type StartupReport struct {
+	Degraded []DegradedComponent `json:"degraded,omitempty"`
+}
+
+// IsDegraded сообщает, работает ли хотя бы один компонент с заменой
+func (r StartupReport) IsDegraded() bool {
+	return len(r.Degraded) > 0
+}
+
+// degrade записывает замену компонента в отчёт
+func (r *StartupReport) degrade(component string, err error) {
+	log.Printf("⚠️ %s: MariaDB недоступна, используется память (данные не сохраняются): %v", component, err)
+	r.Degraded = append(r.Degraded, DegradedComponent{Component: component, Fallback: FallbackMemory, Error: err.Error()})
+}
+
+// openPlayerStore открывает репозитории игроков независимо друг от друга.
+// Ошибка возвращается только для компонента без разрешённой замены; уже
+// открытые к этому моменту репозитории закрываются
+func openPlayerStore(config IntegrationConfig) (*storage.PlayerStore, StartupReport, error) {
+	var report StartupReport
+	players := &storage.PlayerStore{}
+
+	users, err := openUserRepo(config)
+	if err != nil {
+		return nil, report, err
+	}
+	players.Users = users
+
+	positions, err := openPositionRepo(config.PositionStorage, &report)
+	if err != nil {
+		closePlayerStore(players)
+		return nil, report, err
+	}
+	players.Positions = positions
+
+	// Прогресс игроков хранится в той же базе, что и позиции
+	states, err := openPlayerStateRepo(config.PositionStorage, &report)
+	if err != nil {
+		closePlayerStore(players)
+		return nil, report, err
+	}
+	players.States = states
+
+	return players, report, nil
+}
+
+// openUserRepo открывает репозиторий пользователей. Замены у него нет:
+// in-memory репозиторий создаёт администратора с известным паролем и выдаёт
+// ID заново, а позиции и прогресс по этим ID читаются из MariaDB
+func openUserRepo(config IntegrationConfig) (auth.UserRepository, error) {
+	if config.UseMariaDB {
+		repo, err := auth.NewMariaUserRepo(config.MariaConfig)
+		if err != nil {
+			return nil, fmt.Errorf("не удалось подключиться к MariaDB: %w", err)
+		}
+		log.Println("✅ MariaDB подключена успешно")
+		return repo, nil
+	}
+	log.Println("⚠️  Используется in-memory репозиторий пользователей")
+
+	// Используем in-memory репозиторий для разработки/тестирования
+	repo, err := auth.NewMemoryUserRepo()
+	if err != nil {
+		return nil, fmt.Errorf("не удалось создать in-memory репозиторий: %w", err)
+	}
+	return repo, nil
+}
+
+// openPositionRepo открывает репозиторий позиций
+func openPositionRepo(config PositionStorageConfig, report *StartupReport) (storage.PositionRepo, error) {
+	if config.Type != "mariadb" {
+		log.Println("⚠️ Используется in-memory репозиторий позиций (данные не сохраняются)")
+		return storage.NewMemoryPositionRepo(), nil
+	}
+
+	repo, err := storage.NewMariaPositionRepo(config.MariaDBDSN)
+	if err == nil {
+		log.Println("✅ MariaDB репозиторий позиций подключен успешно")
+		return repo, nil
+	}
+	if !config.FallbackToMemory {
+		return nil, fmt.Errorf("не удалось инициализировать репозиторий позиций MariaDB: %w", err)
+	}
+	report.degrade(ComponentPositions, err)
+	return storage.NewMemoryPositionRepo(), nil
+}
+
+// openPlayerStateRepo открывает репозиторий прогресса игроков
+func openPlayerStateRepo(config PositionStorageConfig, report *StartupReport) (storage.PlayerStateRepo, error) {
+	if config.Type != "mariadb" {
+		return storage.NewMemoryPlayerStateRepo(), nil
+	}
+
+	repo, err := storage.NewMariaPlayerStateRepo(config.MariaDBDSN)
+	if err == nil {
+		return repo, nil
+	}
+	if !config.FallbackToMemory {
+		return nil, fmt.Errorf("не удалось инициализировать репозиторий прогресса MariaDB: %w", err)
+	}
+	report.degrade(ComponentPlayerStates, err)
+	return storage.NewMemoryPlayerStateRepo(), nil
+}
+
+// closePlayerStore закрывает открытые репозитории игроков
+func closePlayerStore(players *storage.PlayerStore) {
+	for name, repo := range map[string]interface{}{
+		"пользователей": players.Users,
+		"позиций":       players.Positions,
+		"прогресса":     players.States,
+	} {
+		if closer, ok := repo.(interface{ Close() error }); ok {
+			if err := closer.Close(); err != nil {
+				log.Printf("❌ Ошибка при закрытии репозитория %s: %v", name, err)
+			}
+		}
+	}
+}
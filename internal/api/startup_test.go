@@ -0,0 +1,92 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/annel0/mmo-game/internal/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// unreachableMariaDB возвращает адрес, на котором никто не слушает
+func unreachableMariaDB(t *testing.T) (string, int) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr().(*net.TCPAddr)
+	require.NoError(t, ln.Close())
+	return addr.IP.String(), addr.Port
+}
+
+func TestNewServerIntegration_MariaDBDownFallsBackToMemory(t *testing.T) {
+	host, port := unreachableMariaDB(t)
+	integration, err := NewServerIntegration(IntegrationConfig{
+		RestPort: "127.0.0.1:0",
+		PositionStorage: PositionStorageConfig{
+			Type:             "mariadb",
+			MariaDBDSN:       fmt.Sprintf("game:secret@tcp(%s:%d)/blockverse", host, port),
+			FallbackToMemory: true,
+		},
+	})
+	require.NoError(t, err, "недоступная MariaDB с заменой не должна мешать запуску")
+	require.NoError(t, integration.Start())
+	t.Cleanup(func() { _ = integration.Stop() })
+
+	report := integration.StartupReport()
+	require.True(t, report.IsDegraded())
+	var components []string
+	for _, degraded := range report.Degraded {
+		components = append(components, degraded.Component)
+		assert.Equal(t, FallbackMemory, degraded.Fallback)
+		assert.NotEmpty(t, degraded.Error)
+	}
+	assert.Equal(t, []string{ComponentPositions, ComponentPlayerStates}, components)
+
+	// Сервер обслуживает запросы, но сообщает о деградации
+	rec := httptest.NewRecorder()
+	integration.GetRestServer().router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var body struct {
+		Status   string              `json:"status"`
+		Degraded []DegradedComponent `json:"degraded"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, "degraded", body.Status)
+	assert.Len(t, body.Degraded, 2)
+}
+
+func TestNewServerIntegration_RequiredComponentFails(t *testing.T) {
+	host, port := unreachableMariaDB(t)
+
+	_, err := NewServerIntegration(IntegrationConfig{
+		RestPort:    "127.0.0.1:0",
+		UseMariaDB:  true,
+		MariaConfig: auth.MariaConfig{Host: host, Port: port},
+	})
+	assert.Error(t, err, "недоступная база пользователей всегда прерывает запуск")
+
+	_, err = NewServerIntegration(IntegrationConfig{
+		RestPort: "127.0.0.1:0",
+		PositionStorage: PositionStorageConfig{
+			Type:       "mariadb",
+			MariaDBDSN: fmt.Sprintf("game:secret@tcp(%s:%d)/blockverse", host, port),
+		},
+	})
+	assert.Error(t, err)
+}
+
+func TestServerIntegration_StartFailsOnBusyPort(t *testing.T) {
+	busy, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { busy.Close() })
+
+	integration, err := NewServerIntegration(IntegrationConfig{RestPort: busy.Addr().String()})
+	require.NoError(t, err)
+	assert.False(t, integration.StartupReport().IsDegraded())
+	assert.Error(t, integration.Start(), "занятый порт должен возвращаться ошибкой запуска")
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"sync"
+
+	"github.com/annel0/mmo-game/internal/network"
+	"github.com/gin-gonic/gin"
+)
+
+// StealthController переключает невидимость администраторов в игре
+type StealthController interface {
+	SetPlayerStealth(username string, enabled bool) error
+}
+
+// StealthRequest - тело POST /api/admin/stealth
+type StealthRequest struct {
+	User    string `json:"user" binding:"required"` // Имя администратора в сети
+	Enabled *bool  `json:"enabled" binding:"required"`
+}
+
+// stealthAdmin обслуживает /api/admin/stealth
+type stealthAdmin struct {
+	mu         sync.Mutex
+	controller StealthController
+}
+
+// SetStealthController подключает /api/admin/stealth к игровому серверу
+func (rs *RestServer) SetStealthController(controller StealthController) {
+	rs.stealth.mu.Lock()
+	defer rs.stealth.mu.Unlock()
+	rs.stealth.controller = controller
+}
+
+// handleStealth включает или выключает невидимость администратора в игре
+// (только для админов): его сущность пропадает из обновлений мира обычных игроков
+func (rs *RestServer) handleStealth(c *gin.Context) {
+	rs.stealth.mu.Lock()
+	controller := rs.stealth.controller
+	rs.stealth.mu.Unlock()
+
+	if controller == nil {
+		c.JSON(http.StatusServiceUnavailable, GenericResponse{
+			Success: false,
+			Message: "Режим невидимости недоступен",
+		})
+		return
+	}
+
+	var req StealthRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, GenericResponse{
+			Success: false,
+			Message: "Неверный формат запроса: " + err.Error(),
+		})
+		return
+	}
+
+	err := controller.SetPlayerStealth(req.User, *req.Enabled)
+	switch {
+	case errors.Is(err, network.ErrPlayerOffline):
+		c.JSON(http.StatusNotFound, GenericResponse{Success: false, Message: err.Error()})
+		return
+	case errors.Is(err, network.ErrStealthNotAdmin):
+		c.JSON(http.StatusConflict, GenericResponse{Success: false, Message: err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, GenericResponse{Success: false, Message: err.Error()})
+		return
+	}
+
+	log.Printf("👻 Невидимость %s через API: %v", req.User, *req.Enabled)
+	c.JSON(http.StatusOK, GenericResponse{
+		Success: true,
+		Message: "Режим невидимости изменён",
+		Data:    gin.H{"user": req.User, "enabled": *req.Enabled},
+	})
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/annel0/mmo-game/internal/auth"
+	"github.com/annel0/mmo-game/internal/network/testharness"
+	"github.com/annel0/mmo-game/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newPlayerAdminHarness входит в игру администратором и обычным игроком
+func newPlayerAdminHarness(t *testing.T) (*testharness.Harness, *testharness.Conn, *testharness.Conn) {
+	t.Helper()
+
+	h := testharness.New(t)
+	hash, err := auth.HashPassword("player-pass")
+	require.NoError(t, err)
+	_, err = h.Users.CreateUser("bob", hash, false)
+	require.NoError(t, err)
+
+	admin := h.Connect("admin-conn")
+	admin.Auth("admin", "ChangeMe123!")
+	player := h.Connect("player-conn")
+	player.Auth("bob", "player-pass")
+	return h, admin, player
+}
+
+func TestStealthAdmin_HidesAdminFromPlayers(t *testing.T) {
+	h, admin, player := newPlayerAdminHarness(t)
+	rs := testRestServer()
+	rs.SetStealthController(h.Handler)
+	t.Cleanup(func() { rs.SetStealthController(nil) })
+
+	rec := adminRequest(t, rs, http.MethodPost, "/api/admin/stealth", `{"user": "admin", "enabled": true}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	despawn := &protocol.EntityDespawnMessage{}
+	player.Expect(protocol.MessageType_ENTITY_DESPAWN, despawn)
+	assert.Equal(t, admin.PlayerID, despawn.EntityId, "игрок перестаёт видеть администратора")
+
+	rec = adminRequest(t, rs, http.MethodPost, "/api/admin/stealth", `{"user": "admin", "enabled": false}`)
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+}
+
+func TestStealthAdmin_RejectsInvalidRequests(t *testing.T) {
+	h, _, _ := newPlayerAdminHarness(t)
+	rs := testRestServer()
+
+	rec := adminRequest(t, rs, http.MethodPost, "/api/admin/stealth", `{"user": "admin", "enabled": true}`)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code, "сервер не подключён")
+
+	rs.SetStealthController(h.Handler)
+	t.Cleanup(func() { rs.SetStealthController(nil) })
+
+	rec = adminRequest(t, rs, http.MethodPost, "/api/admin/stealth", `{"user": "bob", "enabled": true}`)
+	assert.Equal(t, http.StatusConflict, rec.Code, "невидимость только для администраторов")
+
+	rec = adminRequest(t, rs, http.MethodPost, "/api/admin/stealth", `{"user": "nobody", "enabled": true}`)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = adminRequest(t, rs, http.MethodPost, "/api/admin/stealth", `{"user": "admin"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code, "без enabled")
+}
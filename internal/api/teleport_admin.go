@@ -0,0 +1,87 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"sync"
+
+	"github.com/annel0/mmo-game/internal/network"
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/annel0/mmo-game/internal/world"
+	"github.com/gin-gonic/gin"
+)
+
+// Teleporter перемещает игроков по команде администратора
+type Teleporter interface {
+	TeleportPlayer(username string, dest vec.Vec3) (network.TeleportResult, error)
+}
+
+// TeleportRequest - тело POST /api/admin/teleport
+type TeleportRequest struct {
+	User  string `json:"user" binding:"required"` // Имя пользователя
+	X     *int   `json:"x" binding:"required"`
+	Y     *int   `json:"y" binding:"required"`
+	Layer *int   `json:"layer"` // Слой: игроки бывают только на ACTIVE (по умолчанию), другие отклоняются
+}
+
+// teleportAdmin обслуживает /api/admin/teleport
+type teleportAdmin struct {
+	mu         sync.Mutex
+	teleporter Teleporter
+}
+
+// SetTeleporter подключает /api/admin/teleport к игровому серверу
+func (rs *RestServer) SetTeleporter(teleporter Teleporter) {
+	rs.teleport.mu.Lock()
+	defer rs.teleport.mu.Unlock()
+	rs.teleport.teleporter = teleporter
+}
+
+// handleTeleport перемещает игрока в указанную точку (только для админов)
+func (rs *RestServer) handleTeleport(c *gin.Context) {
+	rs.teleport.mu.Lock()
+	teleporter := rs.teleport.teleporter
+	rs.teleport.mu.Unlock()
+
+	if teleporter == nil {
+		c.JSON(http.StatusServiceUnavailable, GenericResponse{
+			Success: false,
+			Message: "Телепортация недоступна",
+		})
+		return
+	}
+
+	var req TeleportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, GenericResponse{
+			Success: false,
+			Message: "Неверный формат запроса: " + err.Error(),
+		})
+		return
+	}
+	layer := int(world.LayerActive)
+	if req.Layer != nil {
+		layer = *req.Layer
+	}
+
+	result, err := teleporter.TeleportPlayer(req.User, vec.Vec3{X: *req.X, Y: *req.Y, Z: layer})
+	switch {
+	case errors.Is(err, network.ErrPlayerOffline):
+		c.JSON(http.StatusNotFound, GenericResponse{Success: false, Message: err.Error()})
+		return
+	case errors.Is(err, network.ErrInvalidTeleportDestination):
+		c.JSON(http.StatusBadRequest, GenericResponse{Success: false, Message: err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, GenericResponse{Success: false, Message: err.Error()})
+		return
+	}
+
+	log.Printf("🌀 Игрок %s телепортирован через API в (%d, %d, слой %d)", req.User, result.To.X, result.To.Y, result.To.Z)
+	c.JSON(http.StatusOK, GenericResponse{
+		Success: true,
+		Message: "Игрок перемещён",
+		Data:    result,
+	})
+}
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/annel0/mmo-game/internal/network"
+	"github.com/annel0/mmo-game/internal/network/testharness"
+	"github.com/annel0/mmo-game/internal/protocol"
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+// newTeleportTestHarness подключает /api/admin/teleport к обработчику харнесса
+// и входит в игру администратором
+func newTeleportTestHarness(t *testing.T) (*RestServer, *testharness.Conn) {
+	t.Helper()
+
+	h := testharness.New(t)
+	rs := testRestServer()
+	rs.SetTeleporter(h.Handler)
+	t.Cleanup(func() { rs.SetTeleporter(nil) })
+
+	player := h.Connect("admin-conn")
+	player.Auth("admin", "ChangeMe123!")
+	return rs, player
+}
+
+func TestTeleportAdmin_ChunksArriveBeforeCorrection(t *testing.T) {
+	rs, player := newTeleportTestHarness(t)
+	before := len(player.Messages())
+
+	rec := adminRequest(t, rs, http.MethodPost, "/api/admin/teleport", `{"user": "admin", "x": 5000, "y": -3000}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var resp struct {
+		Data network.TeleportResult `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, vec.Vec3{X: 5000, Y: -3000, Z: 1}, resp.Data.To)
+	assert.Greater(t, resp.Data.PreloadedChunks, 0)
+
+	// Чанк назначения должен прийти раньше корректировки позиции
+	dest := vec.Vec2{X: 5000, Y: -3000}.ToChunkCoords()
+	chunkAt, correctionAt := -1, -1
+	for i, msg := range player.Messages()[before:] {
+		switch msg.Type {
+		case protocol.MessageType_CHUNK_DATA:
+			chunk := &protocol.ChunkData{}
+			require.NoError(t, proto.Unmarshal(msg.Payload, chunk))
+			if chunkAt < 0 && int(chunk.ChunkX) == dest.X && int(chunk.ChunkY) == dest.Y {
+				chunkAt = i
+			}
+		case protocol.MessageType_ENTITY_MOVE:
+			move := &protocol.EntityMoveMessage{}
+			require.NoError(t, proto.Unmarshal(msg.Payload, move))
+			for _, e := range move.Entities {
+				if correctionAt < 0 && e.Id == resp.Data.EntityID && e.Position.X == 5000 && e.Position.Y == -3000 {
+					correctionAt = i
+				}
+			}
+		}
+	}
+	require.GreaterOrEqual(t, chunkAt, 0, "чанк назначения не отправлен")
+	require.GreaterOrEqual(t, correctionAt, 0, "корректировка позиции не отправлена")
+	assert.Less(t, chunkAt, correctionAt)
+}
+
+func TestTeleportAdmin_RejectsInvalidRequests(t *testing.T) {
+	rs, _ := newTeleportTestHarness(t)
+
+	rec := adminRequest(t, rs, http.MethodPost, "/api/admin/teleport", `{"user": "admin", "x": 1, "y": 1, "layer": 7}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code, "несуществующий слой")
+
+	rec = adminRequest(t, rs, http.MethodPost, "/api/admin/teleport", `{"user": "admin", "x": 1, "y": 1, "layer": 0}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code, "игрока нельзя поместить на слой пола")
+
+	rec = adminRequest(t, rs, http.MethodPost, "/api/admin/teleport", `{"user": "admin", "x": 1}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code, "без координаты y")
+
+	rec = adminRequest(t, rs, http.MethodPost, "/api/admin/teleport", `{"user": "nobody", "x": 1, "y": 1}`)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newHTTPServer создаёт HTTP-сервер REST API: с TLS, если задан сертификат.
+// Сертификат клиента запрашивается, но проверяется только для /api/admin
+func (rs *RestServer) newHTTPServer() *http.Server {
+	server := &http.Server{
+		Addr:    rs.port,
+		Handler: rs.router,
+	}
+	if rs.tls != nil {
+		server.TLSConfig = rs.tls.ServerConfig(tls.VerifyClientCertIfGiven, "h2", "http/1.1")
+	}
+	return server
+}
+
+// serve обслуживает ln открытым текстом или с TLS
+func (rs *RestServer) serve(server *http.Server, ln net.Listener) error {
+	if rs.tls == nil {
+		return server.Serve(ln)
+	}
+	// Сертификат берётся из TLSConfig, файлы здесь не нужны
+	return server.ServeTLS(ln, "", "")
+}
+
+// scheme возвращает схему URL REST API
+func (rs *RestServer) scheme() string {
+	if rs.tls == nil {
+		return "http"
+	}
+	return "https"
+}
+
+// ReloadTLS перечитывает сертификаты REST API. Без TLS ничего не делает
+func (rs *RestServer) ReloadTLS() error {
+	if rs.tls == nil {
+		return nil
+	}
+	return rs.tls.Reload()
+}
+
+// adminClientCertMiddleware требует проверенный сертификат клиента для
+// /api/admin, если задан CA клиентских сертификатов (mTLS)
+func (rs *RestServer) adminClientCertMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if rs.tls == nil || !rs.tls.MutualTLS() {
+			c.Next()
+			return
+		}
+		if c.Request.TLS == nil || len(c.Request.TLS.VerifiedChains) == 0 {
+			c.JSON(http.StatusForbidden, GenericResponse{
+				Success: false,
+				Message: "Требуется сертификат клиента",
+			})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
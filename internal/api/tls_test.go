@@ -0,0 +1,146 @@
+package api
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/auth"
+	"github.com/annel0/mmo-game/internal/tlsreload"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestCertificate записывает самоподписанный сертификат для 127.0.0.1
+// (годится и как сертификат клиента, и как его CA) и возвращает пути файлов
+func writeTestCertificate(t *testing.T, dir, name string) (certFile, keyFile string, cert *x509.Certificate) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err = x509.ParseCertificate(der)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile = filepath.Join(dir, name+".pem")
+	keyFile = filepath.Join(dir, name+"-key.pem")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return certFile, keyFile, cert
+}
+
+// startTLSRestServer запускает REST-сервер с TLS на случайном порту
+func startTLSRestServer(t *testing.T, config tlsreload.Config) (*RestServer, string) {
+	t.Helper()
+	certs, err := tlsreload.New(config)
+	require.NoError(t, err)
+
+	rs := testRestServer()
+	rs.tls = certs
+	t.Cleanup(func() { rs.tls = nil })
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := rs.newHTTPServer()
+	go func() { _ = server.ServeTLS(ln, "", "") }()
+	t.Cleanup(func() { server.Close() })
+	return rs, ln.Addr().String()
+}
+
+// httpsClient доверяет только сертификату сервера и предъявляет clientCerts
+func httpsClient(serverCert *x509.Certificate, clientCerts ...tls.Certificate) *http.Client {
+	roots := x509.NewCertPool()
+	roots.AddCert(serverCert)
+	return &http.Client{
+		Timeout: 5 * time.Second,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{RootCAs: roots, Certificates: clientCerts},
+		},
+	}
+}
+
+func TestRestServer_ServesTLSAndRejectsPlaintext(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile, serverCert := writeTestCertificate(t, dir, "server")
+	rs, addr := startTLSRestServer(t, tlsreload.Config{CertFile: certFile, KeyFile: keyFile, Required: true})
+	assert.Equal(t, "https", rs.scheme())
+
+	resp, err := httpsClient(serverCert).Get("https://" + addr + "/health")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.NotNil(t, resp.TLS)
+
+	// Запрос открытым текстом на TLS-порт не доходит до обработчиков
+	resp, err = http.Get("http://" + addr + "/health")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Nil(t, resp.TLS)
+
+	// Продлённый сертификат подхватывается без перезапуска
+	_, _, renewed := writeTestCertificate(t, dir, "server")
+	require.NoError(t, rs.ReloadTLS())
+	resp, err = httpsClient(renewed).Get("https://" + addr + "/health")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, renewed.Raw, resp.TLS.PeerCertificates[0].Raw)
+}
+
+func TestRestServer_AdminRequiresClientCertificate(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile, serverCert := writeTestCertificate(t, dir, "server")
+	clientCertFile, clientKeyFile, _ := writeTestCertificate(t, dir, "admin-client")
+	_, addr := startTLSRestServer(t, tlsreload.Config{CertFile: certFile, KeyFile: keyFile, ClientCAFile: clientCertFile})
+
+	token, err := auth.GenerateJWT(&auth.User{ID: 1, Username: "admin", IsAdmin: true})
+	require.NoError(t, err)
+	get := func(client *http.Client, path string) int {
+		req, err := http.NewRequest(http.MethodGet, "https://"+addr+path, nil)
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	withoutCert := httpsClient(serverCert)
+	assert.Equal(t, http.StatusOK, get(withoutCert, "/health"), "публичные эндпоинты доступны без сертификата клиента")
+	assert.Equal(t, http.StatusForbidden, get(withoutCert, "/api/admin/users"))
+
+	clientCert, err := tls.LoadX509KeyPair(clientCertFile, clientKeyFile)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, get(httpsClient(serverCert, clientCert), "/api/admin/users"))
+
+	// Сертификат, не подписанный CA клиентов, доступа к /api/admin не даёт
+	strangerFile, strangerKeyFile, _ := writeTestCertificate(t, dir, "stranger")
+	stranger, err := tls.LoadX509KeyPair(strangerFile, strangerKeyFile)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, get(httpsClient(serverCert, stranger), "/api/admin/users"))
+}
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/annel0/mmo-game/internal/eventbus"
+	"github.com/annel0/mmo-game/internal/network"
+	"github.com/gin-gonic/gin"
+)
+
+// WorldEventBroadcaster рассылает игрокам события мира (метеоритный дождь,
+// появление босса, объявления)
+type WorldEventBroadcaster interface {
+	BroadcastWorldEvent(eventType string, data map[string]interface{}, target network.WorldEventTarget) (network.WorldEventResult, error)
+}
+
+// WorldEventArea - область получателей события в координатах блоков (границы включены)
+type WorldEventArea struct {
+	MinX int `json:"min_x"`
+	MinY int `json:"min_y"`
+	MaxX int `json:"max_x"`
+	MaxY int `json:"max_y"`
+}
+
+// WorldEventRequest - тело POST /api/admin/world/event
+type WorldEventRequest struct {
+	Type   string                 `json:"type" binding:"required"` // См. network.WorldEventTypes
+	Data   map[string]interface{} `json:"data"`                    // Передаётся клиентам как JSON
+	Region string                 `json:"region"`                  // Только игроки в области региона
+	Area   *WorldEventArea        `json:"area"`                    // Только игроки в области
+}
+
+// worldEventAdmin обслуживает /api/admin/world/event
+type worldEventAdmin struct {
+	mu          sync.Mutex
+	broadcaster WorldEventBroadcaster
+}
+
+// SetWorldEventBroadcaster подключает /api/admin/world/event к игровому серверу
+func (rs *RestServer) SetWorldEventBroadcaster(broadcaster WorldEventBroadcaster) {
+	rs.worldEvents.mu.Lock()
+	defer rs.worldEvents.mu.Unlock()
+	rs.worldEvents.broadcaster = broadcaster
+}
+
+// handleWorldEvent рассылает событие мира игрокам и записывает его в журнал
+// событий (только для админов)
+func (rs *RestServer) handleWorldEvent(c *gin.Context) {
+	rs.worldEvents.mu.Lock()
+	broadcaster := rs.worldEvents.broadcaster
+	rs.worldEvents.mu.Unlock()
+
+	if broadcaster == nil {
+		c.JSON(http.StatusServiceUnavailable, GenericResponse{
+			Success: false,
+			Message: "События мира недоступны",
+		})
+		return
+	}
+
+	var req WorldEventRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, GenericResponse{
+			Success: false,
+			Message: "Неверный формат запроса: " + err.Error(),
+		})
+		return
+	}
+
+	target := network.WorldEventTarget{RegionID: req.Region}
+	if req.Area != nil {
+		target.Area = &eventbus.Bounds{MinX: req.Area.MinX, MinY: req.Area.MinY, MaxX: req.Area.MaxX, MaxY: req.Area.MaxY}
+	}
+
+	result, err := broadcaster.BroadcastWorldEvent(req.Type, req.Data, target)
+	switch {
+	case errors.Is(err, network.ErrUnknownWorldEvent):
+		c.JSON(http.StatusBadRequest, GenericResponse{
+			Success: false,
+			Message: err.Error() + ", допустимые: " + strings.Join(network.WorldEventTypes(), ", "),
+		})
+		return
+	case errors.Is(err, network.ErrInvalidWorldEvent):
+		c.JSON(http.StatusBadRequest, GenericResponse{Success: false, Message: err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, GenericResponse{Success: false, Message: err.Error()})
+		return
+	}
+
+	log.Printf("🌠 Событие мира %s запущено через API: %d получателей", result.EventType, result.Recipients)
+	c.JSON(http.StatusOK, GenericResponse{
+		Success: true,
+		Message: "Событие мира отправлено",
+		Data:    result,
+	})
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/annel0/mmo-game/internal/network"
+	"github.com/annel0/mmo-game/internal/network/testharness"
+	"github.com/annel0/mmo-game/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorldEventAdmin_ReachesConnectedPlayers(t *testing.T) {
+	h := testharness.New(t)
+	rs := testRestServer()
+	rs.SetWorldEventBroadcaster(h.Handler)
+	t.Cleanup(func() { rs.SetWorldEventBroadcaster(nil) })
+
+	player := h.Connect("player-conn")
+	player.Auth("admin", "ChangeMe123!")
+
+	rec := adminRequest(t, rs, http.MethodPost, "/api/admin/world/event",
+		`{"type": "boss_spawn", "data": {"boss": "dragon", "hp": 5000}}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var resp struct {
+		Data network.WorldEventResult `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, 1, resp.Data.Recipients)
+	assert.NotEmpty(t, resp.Data.EventID)
+
+	var event protocol.WorldEventMessage
+	player.Expect(protocol.MessageType_WORLD_EVENT, &event)
+	assert.Equal(t, network.WorldEventBossSpawn, event.EventType)
+	assert.JSONEq(t, `{"boss": "dragon", "hp": 5000}`, event.Metadata.JsonData)
+
+	// Область без игроков: событие принято, но никому не доставлено
+	rec = adminRequest(t, rs, http.MethodPost, "/api/admin/world/event",
+		`{"type": "meteor_shower", "area": {"min_x": 90000, "min_y": 90000, "max_x": 90100, "max_y": 90100}}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Zero(t, resp.Data.Recipients)
+}
+
+func TestWorldEventAdmin_RejectsInvalidEvents(t *testing.T) {
+	h := testharness.New(t)
+	rs := testRestServer()
+
+	rec := adminRequest(t, rs, http.MethodPost, "/api/admin/world/event", `{"type": "announcement"}`)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	rs.SetWorldEventBroadcaster(h.Handler)
+	t.Cleanup(func() { rs.SetWorldEventBroadcaster(nil) })
+
+	rec = adminRequest(t, rs, http.MethodPost, "/api/admin/world/event", `{"type": "alien_invasion"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), network.WorldEventMeteorShower, "ответ перечисляет допустимые типы")
+
+	rec = adminRequest(t, rs, http.MethodPost, "/api/admin/world/event", `{"type": "weather", "region": "unknown"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = adminRequest(t, rs, http.MethodPost, "/api/admin/world/event", `{"data": {}}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -3,26 +3,19 @@ package app
 import (
 	"encoding/json"
 	"fmt"
-	"log"
 	"path/filepath"
+	"sort"
 	"sync"
 
+	"github.com/annel0/mmo-game/internal/storage_interface"
 	"github.com/annel0/mmo-game/internal/vec"
 	"github.com/dgraph-io/badger/v3"
 )
 
-// EntityData содержит данные о сущности для хранения
-type EntityData struct {
-	ID       uint64                 `json:"id"`       // Уникальный ID сущности
-	Type     uint16                 `json:"type"`     // Тип сущности
-	Position vec.Vec2               `json:"position"` // Позиция в мире
-	Payload  map[string]interface{} `json:"payload"`  // Метаданные сущности
-}
-
 // BigChunkEntities содержит информацию о сущностях в BigChunk
 type BigChunkEntities struct {
-	Coords   vec.Vec2              `json:"coords"`   // Координаты BigChunk
-	Entities map[uint64]EntityData `json:"entities"` // Карта сущностей по ID
+	Coords   vec.Vec2                                    `json:"coords"`   // Координаты BigChunk
+	Entities map[uint64]storage_interface.EntitySnapshot `json:"entities"` // Снимки сущностей по ID
 }
 
 // EntityStorage управляет хранением сущностей в BadgerDB
@@ -52,8 +45,8 @@ func NewEntityStorage(dataPath string) (*EntityStorage, error) {
 	return storage, nil
 }
 
-// SaveEntities сохраняет данные о сущностях из BigChunk
-func (s *EntityStorage) SaveEntities(bigChunkCoords vec.Vec2, entities map[uint64]interface{}) error {
+// SaveEntities сохраняет снимки сущностей BigChunk
+func (s *EntityStorage) SaveEntities(bigChunkCoords vec.Vec2, entities []storage_interface.EntitySnapshot) error {
 	s.mutex.RLock()
 	defer s.mutex.RUnlock()
 
@@ -64,40 +57,10 @@ func (s *EntityStorage) SaveEntities(bigChunkCoords vec.Vec2, entities map[uint6
 	// Создаем структуру для сохранения
 	delta := BigChunkEntities{
 		Coords:   bigChunkCoords,
-		Entities: make(map[uint64]EntityData),
+		Entities: make(map[uint64]storage_interface.EntitySnapshot, len(entities)),
 	}
-
-	// Преобразуем сущности в формат для сохранения
-	for id, entity := range entities {
-		if entityMap, ok := entity.(map[string]interface{}); ok {
-			// Получаем значения из карты
-			position := vec.Vec2{X: 0, Y: 0}
-			if posMap, ok := entityMap["Position"].(map[string]interface{}); ok {
-				if x, ok := posMap["X"].(int); ok {
-					position.X = x
-				}
-				if y, ok := posMap["Y"].(int); ok {
-					position.Y = y
-				}
-			}
-
-			entityType := uint16(0)
-			if typeVal, ok := entityMap["Type"].(uint16); ok {
-				entityType = typeVal
-			}
-
-			payload := make(map[string]interface{})
-			if pl, ok := entityMap["Metadata"].(map[string]interface{}); ok {
-				payload = pl
-			}
-
-			delta.Entities[id] = EntityData{
-				ID:       id,
-				Type:     entityType,
-				Position: position,
-				Payload:  payload,
-			}
-		}
+	for _, entity := range entities {
+		delta.Entities[entity.ID] = entity
 	}
 
 	// Если нет сущностей для сохранения, пропускаем
@@ -126,8 +89,8 @@ func (s *EntityStorage) SaveEntities(bigChunkCoords vec.Vec2, entities map[uint6
 	return nil
 }
 
-// LoadEntities загружает данные о сущностях для BigChunk
-func (s *EntityStorage) LoadEntities(bigChunkCoords vec.Vec2) (*BigChunkEntities, error) {
+// LoadEntities загружает снимки сущностей BigChunk, упорядоченные по ID
+func (s *EntityStorage) LoadEntities(bigChunkCoords vec.Vec2) ([]storage_interface.EntitySnapshot, error) {
 	s.mutex.RLock()
 	defer s.mutex.RUnlock()
 
@@ -151,12 +114,9 @@ func (s *EntityStorage) LoadEntities(bigChunkCoords vec.Vec2) (*BigChunkEntities
 		})
 	})
 
-	// Если сущности не найдены, возвращаем пустую структуру
+	// Если сущности не найдены, возвращаем пустой список
 	if err == badger.ErrKeyNotFound {
-		return &BigChunkEntities{
-			Coords:   bigChunkCoords,
-			Entities: make(map[uint64]EntityData),
-		}, nil
+		return []storage_interface.EntitySnapshot{}, nil
 	}
 
 	if err != nil {
@@ -169,30 +129,16 @@ func (s *EntityStorage) LoadEntities(bigChunkCoords vec.Vec2) (*BigChunkEntities
 		return nil, fmt.Errorf("ошибка десериализации сущностей: %w", err)
 	}
 
-	return &delta, nil
-}
-
-// ApplyEntityDataToMap применяет загруженные данные о сущностях к карте сущностей
-func (s *EntityStorage) ApplyEntityDataToMap(entities map[uint64]interface{}, data *BigChunkEntities) {
-	if data == nil || len(data.Entities) == 0 {
-		return
-	}
-
-	// Применяем данные к карте сущностей
-	for id, entityDelta := range data.Entities {
-		// Создаем карту данных для сущности
-		entityData := map[string]interface{}{
-			"ID":       entityDelta.ID,
-			"Type":     entityDelta.Type,
-			"Position": entityDelta.Position,
-			"Metadata": entityDelta.Payload,
+	entities := make([]storage_interface.EntitySnapshot, 0, len(delta.Entities))
+	for id, entity := range delta.Entities {
+		if entity.ID == 0 {
+			entity.ID = id
 		}
-
-		// Добавляем в карту сущностей
-		entities[id] = entityData
+		entities = append(entities, entity)
 	}
+	sort.Slice(entities, func(i, j int) bool { return entities[i].ID < entities[j].ID })
 
-	log.Printf("Загружено %d сущностей для BigChunk %v", len(data.Entities), data.Coords)
+	return entities, nil
 }
 
 // Close закрывает хранилище
@@ -0,0 +1,165 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/eventbus"
+	"github.com/annel0/mmo-game/internal/logging"
+	"github.com/annel0/mmo-game/internal/network"
+)
+
+// ShutdownStage - этап остановки сервера
+type ShutdownStage string
+
+// Этапы остановки в порядке выполнения: каждый следующий этап начинается,
+// когда предыдущие компоненты уже не порождают новых событий
+const (
+	StageInput     ShutdownStage = "input"     // Перестать принимать игроков и запросы REST API
+	StageGame      ShutdownStage = "game"      // Остановить игровой сервер и тики
+	StageWorld     ShutdownStage = "world"     // Сохранить миры
+	StageSync      ShutdownStage = "sync"      // Отправить накопленные пакеты синхронизации
+	StageEventBus  ShutdownStage = "eventbus"  // Доставить опубликованные события
+	StageTelemetry ShutdownStage = "telemetry" // Отправить последние метрики и трейсы
+)
+
+// shutdownOrder - порядок этапов и их сроки по умолчанию
+var shutdownOrder = []struct {
+	stage   ShutdownStage
+	timeout time.Duration
+}{
+	{StageInput, 30 * time.Second},
+	{StageGame, 10 * time.Second},
+	{StageWorld, 30 * time.Second},
+	{StageSync, 10 * time.Second},
+	{StageEventBus, 10 * time.Second},
+	{StageTelemetry, 5 * time.Second},
+}
+
+// GameServer - игровой сервер, выводимый из работы при остановке
+type GameServer interface {
+	StartDrain(timeout time.Duration) network.DrainStatus
+	Stop()
+}
+
+// WorldSaver - мир, сохраняемый при остановке
+type WorldSaver interface {
+	SaveWorld(force bool)
+}
+
+// Server владеет компонентами процесса и останавливает их в фиксированном
+// порядке, чтобы ни один компонент не закрылся раньше, чем отдаст свои события.
+// Неиспользуемые поля можно оставить пустыми
+type Server struct {
+	Game      GameServer
+	API       interface{ Stop() error }
+	Worlds    []WorldSaver
+	Regional  interface{ Stop() error }
+	Sync      []interface{ Stop() } // SyncManager, BatchManager: Stop отправляет остаток
+	Bus       eventbus.EventBus
+	Telemetry []func(ctx context.Context) error
+
+	// Переопределение сроков этапов (0 - срок по умолчанию)
+	StageTimeouts map[ShutdownStage]time.Duration
+
+	once sync.Once
+	err  error
+}
+
+// Shutdown останавливает компоненты этапами: приём игроков → игровой сервер →
+// сохранение мира → пакеты синхронизации → EventBus → телеметрия. Каждый этап
+// ограничен своим сроком и ctx; этап, не успевший за срок, не задерживает
+// следующие. Возвращает ошибки всех этапов. Повторные вызовы возвращают
+// результат первого
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.once.Do(func() {
+		start := time.Now()
+		logging.Info("🛑 Остановка сервера...")
+
+		var errs []error
+		for _, st := range shutdownOrder {
+			timeout := st.timeout
+			if override := s.StageTimeouts[st.stage]; override > 0 {
+				timeout = override
+			}
+			if err := s.runStage(ctx, st.stage, timeout); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		s.err = errors.Join(errs...)
+
+		if s.err != nil {
+			logging.Warn("⚠️ Сервер остановлен с ошибками за %v: %v", time.Since(start), s.err)
+		} else {
+			logging.Info("✅ Все этапы остановки завершены за %v", time.Since(start))
+		}
+	})
+	return s.err
+}
+
+// runStage выполняет этап с его сроком
+func (s *Server) runStage(ctx context.Context, stage ShutdownStage, timeout time.Duration) error {
+	stageCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	logging.Info("🛑 Остановка [%s]...", stage)
+
+	done := make(chan error, 1)
+	go func() { done <- s.stage(stageCtx, stage) }()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			logging.Warn("⚠️ Остановка [%s]: %v", stage, err)
+			return fmt.Errorf("%s: %w", stage, err)
+		}
+		logging.Info("✅ Остановка [%s] завершена за %v", stage, time.Since(start))
+		return nil
+	case <-stageCtx.Done():
+		logging.Warn("⏱️ Остановка [%s] не уложилась в %v, переходим к следующему этапу", stage, timeout)
+		return fmt.Errorf("%s: %w", stage, stageCtx.Err())
+	}
+}
+
+// stage останавливает компоненты одного этапа
+func (s *Server) stage(ctx context.Context, stage ShutdownStage) error {
+	var errs []error
+	switch stage {
+	case StageInput:
+		if s.Game != nil {
+			s.Game.StartDrain(0)
+		}
+		if s.API != nil {
+			errs = append(errs, s.API.Stop())
+		}
+	case StageGame:
+		if s.Game != nil {
+			s.Game.Stop()
+		}
+	case StageWorld:
+		for _, w := range s.Worlds {
+			w.SaveWorld(true)
+		}
+	case StageSync:
+		// Узел больше не принимает чужие пакеты, затем уходят собственные
+		if s.Regional != nil {
+			errs = append(errs, s.Regional.Stop())
+		}
+		for _, flusher := range s.Sync {
+			flusher.Stop()
+		}
+	case StageEventBus:
+		if s.Bus != nil {
+			errs = append(errs, eventbus.Drain(ctx, s.Bus))
+		}
+	case StageTelemetry:
+		for _, flush := range s.Telemetry {
+			errs = append(errs, flush(ctx))
+		}
+	}
+	return errors.Join(errs...)
+}
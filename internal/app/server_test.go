@@ -0,0 +1,123 @@
+package app
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/eventbus"
+	"github.com/annel0/mmo-game/internal/network"
+	syncpkg "github.com/annel0/mmo-game/internal/sync"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// callLog запоминает порядок остановки компонентов
+type callLog struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (l *callLog) add(call string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.calls = append(l.calls, call)
+}
+
+func (l *callLog) get() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.calls...)
+}
+
+type fakeGame struct{ log *callLog }
+
+func (g fakeGame) StartDrain(time.Duration) network.DrainStatus {
+	g.log.add("game.drain")
+	return network.DrainStatus{Draining: true}
+}
+
+func (g fakeGame) Stop() { g.log.add("game.stop") }
+
+type fakeWorld struct{ log *callLog }
+
+func (w fakeWorld) SaveWorld(force bool) { w.log.add("world.save") }
+
+type fakeStopper struct {
+	log  *callLog
+	name string
+}
+
+func (s fakeStopper) Stop() error { s.log.add(s.name); return nil }
+
+func TestServerShutdown_FlushesSyncBeforeBusCloses(t *testing.T) {
+	bus := eventbus.NewMemoryBus(16)
+
+	var mu sync.Mutex
+	var delivered []syncpkg.Change
+	_, err := bus.Subscribe(context.Background(), eventbus.Filter{Types: []string{"SyncBatch"}}, func(_ context.Context, ev *eventbus.Envelope) {
+		changes, err := syncpkg.NewPassthroughCompressor().Decompress(ev.Payload)
+		require.NoError(t, err)
+		time.Sleep(20 * time.Millisecond) // медленный подписчик
+		mu.Lock()
+		delivered = append(delivered, changes...)
+		mu.Unlock()
+	})
+	require.NoError(t, err)
+
+	// Неполный пакет без Shutdown ушёл бы только через час
+	bm := syncpkg.NewBatchManager(bus, "region-a", 100, time.Hour, nil)
+	bm.AddChange(syncpkg.Change{Data: []byte("a")})
+	bm.AddChange(syncpkg.Change{Data: []byte("b")})
+
+	srv := &Server{Sync: []interface{ Stop() }{bm}, Bus: bus}
+	require.NoError(t, srv.Shutdown(context.Background()))
+
+	mu.Lock()
+	assert.Len(t, delivered, 2, "накопленные изменения доставлены до закрытия шины")
+	mu.Unlock()
+	assert.ErrorIs(t, bus.Publish(context.Background(), &eventbus.Envelope{EventType: "SyncBatch"}), eventbus.ErrBusClosed)
+}
+
+func TestServerShutdown_StageOrderAndDeadlines(t *testing.T) {
+	log := &callLog{}
+	blocked := make(chan struct{})
+	defer close(blocked)
+
+	srv := &Server{
+		Game:     fakeGame{log},
+		API:      fakeStopper{log, "api.stop"},
+		Worlds:   []WorldSaver{fakeWorld{log}},
+		Regional: fakeStopper{log, "regional.stop"},
+		Telemetry: []func(context.Context) error{func(context.Context) error {
+			log.add("telemetry.flush")
+			return nil
+		}},
+		StageTimeouts: map[ShutdownStage]time.Duration{StageWorld: 20 * time.Millisecond},
+	}
+	// Зависшее сохранение мира не задерживает остальные этапы
+	srv.Worlds = append(srv.Worlds, hangingWorld(blocked))
+
+	err := srv.Shutdown(context.Background())
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Contains(t, err.Error(), string(StageWorld))
+
+	assert.Equal(t, []string{
+		"game.drain", "api.stop",
+		"game.stop",
+		"world.save",
+		"regional.stop",
+		"telemetry.flush",
+	}, log.get())
+
+	// Повторный вызов ничего не останавливает повторно
+	assert.Equal(t, err, srv.Shutdown(context.Background()))
+	assert.Len(t, log.get(), 6)
+}
+
+// hangingWorld - мир, сохранение которого не завершается до закрытия release
+type hangingWorld chan struct{}
+
+func (w hangingWorld) SaveWorld(bool) { <-w }
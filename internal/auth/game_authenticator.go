@@ -1,6 +1,8 @@
 package auth
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -10,6 +12,7 @@ import (
 // GameAuthenticator handles game authentication with JWT support
 type GameAuthenticator struct {
 	userRepo  UserRepository
+	provider  AuthProvider // Проверка учётных данных (по умолчанию LocalProvider)
 	jwtSecret []byte
 }
 
@@ -27,27 +30,36 @@ type AuthResult struct {
 func NewGameAuthenticator(userRepo UserRepository, jwtSecret []byte) *GameAuthenticator {
 	return &GameAuthenticator{
 		userRepo:  userRepo,
+		provider:  LocalProvider{},
 		jwtSecret: jwtSecret,
 	}
 }
 
-// AuthenticateUser authenticates a user with username and password
-func (ga *GameAuthenticator) AuthenticateUser(username, password string) (*AuthResult, error) {
-	// Find user by username
-	user, err := ga.userRepo.GetUserByUsername(username)
-	if err != nil {
+// AuthenticateUser authenticates a user with username and password (or token,
+// depending on the AuthProvider)
+func (ga *GameAuthenticator) AuthenticateUser(ctx context.Context, username, password string) (*AuthResult, error) {
+	user, err := ga.provider.Authenticate(ctx, ga.userRepo, username, password)
+	switch {
+	case errors.Is(err, ErrUserNotFound):
 		return &AuthResult{
 			Success: false,
 			Message: "User not found",
 		}, nil
-	}
-
-	// Verify password
-	if !CheckPassword(user.PasswordHash, password) {
+	case errors.Is(err, ErrInvalidCredentials):
 		return &AuthResult{
 			Success: false,
 			Message: "Invalid credentials",
 		}, nil
+	case errors.Is(err, ErrAccountLocked):
+		return &AuthResult{
+			Success: false,
+			Message: "Too many failed login attempts, try again later",
+		}, nil
+	case err != nil:
+		return &AuthResult{
+			Success: false,
+			Message: "Authentication provider unavailable",
+		}, err
 	}
 
 	// Генерируем JWT токен с помощью общего метода, чтобы Claims совпадали с ValidateJWT
@@ -116,3 +128,15 @@ func (ga *GameAuthenticator) generateJWT(userID uint64, username string) (string
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString(ga.jwtSecret)
 }
+
+// WithUserRepository возвращает аутентификатор с тем же секретом JWT,
+// проверяющий учётные записи в другом репозитории
+func (ga *GameAuthenticator) WithUserRepository(userRepo UserRepository) *GameAuthenticator {
+	return &GameAuthenticator{userRepo: userRepo, provider: ga.provider, jwtSecret: ga.jwtSecret}
+}
+
+// WithProvider возвращает аутентификатор с тем же репозиторием и секретом,
+// проверяющий учётные данные через provider
+func (ga *GameAuthenticator) WithProvider(provider AuthProvider) *GameAuthenticator {
+	return &GameAuthenticator{userRepo: ga.userRepo, provider: provider, jwtSecret: ga.jwtSecret}
+}
@@ -0,0 +1,179 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrAccountLocked - вход в учётную запись временно запрещён после серии
+// неудачных попыток
+var ErrAccountLocked = errors.New("too many failed login attempts")
+
+const (
+	// DefaultLockoutThreshold - неудачных попыток входа подряд до блокировки
+	DefaultLockoutThreshold = 5
+
+	// DefaultLockoutWindow - окно подсчёта неудачных попыток и длительность блокировки
+	DefaultLockoutWindow = 15 * time.Minute
+
+	// maxTrackedAccounts - сверх этого числа записей устаревшие удаляются при
+	// каждой неудачной попытке: перебор несуществующих имён не растит память
+	maxTrackedAccounts = 100000
+)
+
+// LockoutConfig - параметры блокировки учётной записи при подборе пароля
+type LockoutConfig struct {
+	Threshold int           // 0 - DefaultLockoutThreshold, отрицательное значение - выключено
+	Window    time.Duration // 0 - DefaultLockoutWindow
+
+	// Вызывается при каждой новой блокировке (например, для отправки webhook)
+	OnLockout func(LockoutEvent)
+}
+
+// LockoutEvent - учётная запись заблокирована
+type LockoutEvent struct {
+	Username string    `json:"username"`
+	Failures int       `json:"failures"`
+	Until    time.Time `json:"locked_until"`
+}
+
+// failedLogins - неудачные попытки входа в учётную запись
+type failedLogins struct {
+	count       int
+	first       time.Time // Первая неудачная попытка в текущем окне
+	lockedUntil time.Time
+}
+
+// AccountLockout считает неудачные попытки входа по имени пользователя (без
+// учёта регистра) и блокирует учётную запись на Window после Threshold
+// попыток в пределах Window. Дополняет ограничение частоты по IP: подбор
+// пароля к одной учётной записи с многих адресов тоже останавливается
+type AccountLockout struct {
+	mu        sync.Mutex
+	threshold int
+	window    time.Duration
+	onLockout func(LockoutEvent)
+	now       func() time.Time
+	accounts  map[string]*failedLogins
+}
+
+// NewAccountLockout создаёт счётчик неудачных попыток входа
+func NewAccountLockout(config LockoutConfig) *AccountLockout {
+	if config.Threshold == 0 {
+		config.Threshold = DefaultLockoutThreshold
+	}
+	if config.Window <= 0 {
+		config.Window = DefaultLockoutWindow
+	}
+	return &AccountLockout{
+		threshold: config.Threshold,
+		window:    config.Window,
+		onLockout: config.OnLockout,
+		now:       time.Now,
+		accounts:  make(map[string]*failedLogins),
+	}
+}
+
+// Locked сообщает, заблокирована ли учётная запись, и до какого времени
+func (l *AccountLockout) Locked(username string) (time.Time, bool) {
+	if l.threshold < 0 {
+		return time.Time{}, false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entry, ok := l.accounts[lockoutKey(username)]
+	if !ok || !l.now().Before(entry.lockedUntil) {
+		return time.Time{}, false
+	}
+	return entry.lockedUntil, true
+}
+
+// RecordFailure учитывает неудачную попытку входа. Возвращает true, если
+// попытка привела к блокировке
+func (l *AccountLockout) RecordFailure(username string) bool {
+	if l.threshold < 0 {
+		return false
+	}
+	now := l.now()
+	key := lockoutKey(username)
+
+	l.mu.Lock()
+	if len(l.accounts) >= maxTrackedAccounts {
+		l.pruneLocked(now)
+	}
+	entry, ok := l.accounts[key]
+	if !ok || now.Sub(entry.first) >= l.window && !now.Before(entry.lockedUntil) {
+		entry = &failedLogins{first: now}
+		l.accounts[key] = entry
+	}
+	entry.count++
+	locked := entry.count == l.threshold
+	if locked {
+		entry.lockedUntil = now.Add(l.window)
+	}
+	event := LockoutEvent{Username: username, Failures: entry.count, Until: entry.lockedUntil}
+	l.mu.Unlock()
+
+	if locked && l.onLockout != nil {
+		l.onLockout(event)
+	}
+	return locked
+}
+
+// RecordSuccess сбрасывает счётчик после успешного входа
+func (l *AccountLockout) RecordSuccess(username string) {
+	l.mu.Lock()
+	delete(l.accounts, lockoutKey(username))
+	l.mu.Unlock()
+}
+
+// pruneLocked удаляет записи с истёкшим окном и блокировкой. Вызывается под l.mu
+func (l *AccountLockout) pruneLocked(now time.Time) {
+	for key, entry := range l.accounts {
+		if now.Sub(entry.first) >= l.window && !now.Before(entry.lockedUntil) {
+			delete(l.accounts, key)
+		}
+	}
+}
+
+func lockoutKey(username string) string {
+	return strings.ToLower(username)
+}
+
+// LockoutProvider - AuthProvider, отклоняющий вход в заблокированную учётную
+// запись без обращения к inner. Один экземпляр используется и для входа в
+// игру, и для REST API, поэтому попытки считаются вместе
+type LockoutProvider struct {
+	inner   AuthProvider
+	lockout *AccountLockout
+}
+
+// NewLockoutProvider оборачивает provider блокировкой учётных записей
+func NewLockoutProvider(inner AuthProvider, lockout *AccountLockout) *LockoutProvider {
+	return &LockoutProvider{inner: inner, lockout: lockout}
+}
+
+// Authenticate реализует AuthProvider. Для заблокированной учётной записи
+// возвращает ErrAccountLocked; сбой самого провайдера попыткой не считается.
+// Вход только по токену (без имени) не с чем сопоставить до проверки токена,
+// поэтому такие неудачные попытки не учитываются
+func (p *LockoutProvider) Authenticate(ctx context.Context, users UserRepository, username, secret string) (*User, error) {
+	if _, locked := p.lockout.Locked(username); locked && username != "" {
+		return nil, ErrAccountLocked
+	}
+
+	user, err := p.inner.Authenticate(ctx, users, username, secret)
+	switch {
+	case errors.Is(err, ErrInvalidCredentials) || errors.Is(err, ErrUserNotFound):
+		if username != "" {
+			p.lockout.RecordFailure(username)
+		}
+	case err == nil:
+		p.lockout.RecordSuccess(user.Username)
+	}
+	return user, err
+}
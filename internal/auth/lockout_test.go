@@ -0,0 +1,86 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLockoutProvider_LocksAfterFailuresAndUnlocksAfterWindow(t *testing.T) {
+	users, err := NewMemoryUserRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash, err := HashPassword("correct-horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := users.CreateUser("victim", hash, false); err != nil {
+		t.Fatal(err)
+	}
+
+	var events []LockoutEvent
+	lockout := NewAccountLockout(LockoutConfig{
+		Threshold: 3,
+		Window:    time.Minute,
+		OnLockout: func(event LockoutEvent) { events = append(events, event) },
+	})
+	now := time.Now()
+	lockout.now = func() time.Time { return now }
+	provider := NewLockoutProvider(LocalProvider{}, lockout)
+	ctx := context.Background()
+
+	// Успешный вход сбрасывает счётчик
+	for i := 0; i < 2; i++ {
+		if _, err := provider.Authenticate(ctx, users, "victim", "guess"); !errors.Is(err, ErrInvalidCredentials) {
+			t.Fatalf("Попытка %d: ожидалась ErrInvalidCredentials, получено %v", i+1, err)
+		}
+	}
+	if _, err := provider.Authenticate(ctx, users, "victim", "correct-horse"); err != nil {
+		t.Fatalf("Вход с верным паролем до блокировки: %v", err)
+	}
+
+	// Три неудачные попытки подряд (имя без учёта регистра) - блокировка
+	for _, name := range []string{"victim", "Victim", "VICTIM"} {
+		if _, err := provider.Authenticate(ctx, users, name, "guess"); !errors.Is(err, ErrInvalidCredentials) {
+			t.Fatalf("Ожидалась ErrInvalidCredentials, получено %v", err)
+		}
+	}
+	if len(events) != 1 || events[0].Failures != 3 || !events[0].Until.Equal(now.Add(time.Minute)) {
+		t.Fatalf("Ожидалось одно событие блокировки после 3 попыток, получено %+v", events)
+	}
+
+	// Во время блокировки отклоняется и верный пароль
+	if _, err := provider.Authenticate(ctx, users, "victim", "correct-horse"); !errors.Is(err, ErrAccountLocked) {
+		t.Fatalf("Ожидалась ErrAccountLocked, получено %v", err)
+	}
+	result, err := NewGameAuthenticator(users, nil).WithProvider(provider).AuthenticateUser(ctx, "victim", "correct-horse")
+	if err != nil || result.Success || result.Message != "Too many failed login attempts, try again later" {
+		t.Fatalf("Игровой вход: ожидалось сообщение о блокировке, получено %+v, %v", result, err)
+	}
+	if _, locked := lockout.Locked("someone-else"); locked {
+		t.Error("Блокировка не должна затрагивать другие учётные записи")
+	}
+
+	// По истечении окна учётная запись разблокируется сама
+	now = now.Add(time.Minute)
+	if _, err := provider.Authenticate(ctx, users, "victim", "correct-horse"); err != nil {
+		t.Fatalf("Вход после окна блокировки: %v", err)
+	}
+	if len(events) != 1 {
+		t.Errorf("Повторных событий блокировки быть не должно, получено %d", len(events))
+	}
+}
+
+func TestAccountLockout_Disabled(t *testing.T) {
+	lockout := NewAccountLockout(LockoutConfig{Threshold: -1})
+	for i := 0; i < 100; i++ {
+		if lockout.RecordFailure("victim") {
+			t.Fatal("Выключенная блокировка не должна срабатывать")
+		}
+	}
+	if _, locked := lockout.Locked("victim"); locked {
+		t.Fatal("Выключенная блокировка не должна срабатывать")
+	}
+}
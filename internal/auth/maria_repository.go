@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"strings"
@@ -229,6 +230,11 @@ func (m *MariaUserRepo) GetUserStats() (map[string]interface{}, error) {
 	return stats, nil
 }
 
+// Ping проверяет доступность БД
+func (m *MariaUserRepo) Ping(ctx context.Context) error {
+	return m.db.PingContext(ctx)
+}
+
 // Close закрывает подключение к БД
 func (m *MariaUserRepo) Close() error {
 	return m.db.Close()
@@ -0,0 +1,133 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultOAuthTimeout - предел ожидания ответа сервера авторизации
+const DefaultOAuthTimeout = 5 * time.Second
+
+// OAuthConfig - проверка токенов доступа через introspection (RFC 7662)
+type OAuthConfig struct {
+	IntrospectionURL string // Эндпоинт introspection сервера авторизации
+
+	// Учётные данные сервера игры для introspection (HTTP Basic)
+	ClientID     string
+	ClientSecret string
+
+	CreateUsers bool          // Создавать локальную учётную запись при первом входе
+	Timeout     time.Duration // 0 - DefaultOAuthTimeout
+}
+
+// OAuthProvider принимает вместо пароля токен доступа OAuth 2.0. Токен
+// проверяется сервером авторизации, имя пользователя берётся из ответа
+// (username, иначе sub)
+type OAuthProvider struct {
+	config OAuthConfig
+	client *http.Client
+}
+
+// introspectionResponse - поля ответа introspection, нужные серверу
+type introspectionResponse struct {
+	Active   bool   `json:"active"`
+	Username string `json:"username"`
+	Subject  string `json:"sub"`
+}
+
+// NewOAuthProvider создаёт провайдер токенов OAuth
+func NewOAuthProvider(config OAuthConfig) (*OAuthProvider, error) {
+	endpoint, err := url.Parse(config.IntrospectionURL)
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return nil, fmt.Errorf("oauth: некорректный introspection_url %q", config.IntrospectionURL)
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultOAuthTimeout
+	}
+	return &OAuthProvider{config: config, client: &http.Client{Timeout: config.Timeout}}, nil
+}
+
+// Authenticate реализует AuthProvider. Если клиент указал имя пользователя,
+// оно должно совпадать с владельцем токена
+func (p *OAuthProvider) Authenticate(ctx context.Context, users UserRepository, username, token string) (*User, error) {
+	if token == "" {
+		return nil, ErrInvalidCredentials
+	}
+	identity, err := p.introspect(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if username != "" && !strings.EqualFold(username, identity) {
+		return nil, ErrInvalidCredentials
+	}
+
+	user, err := users.GetUserByUsername(identity)
+	if !errors.Is(err, ErrUserNotFound) || !p.config.CreateUsers {
+		return user, err
+	}
+
+	// Пароль внешних пользователей неизвестен никому: войти можно только с токеном
+	user, err = users.CreateUser(identity, unusablePasswordHash(), false)
+	if errors.Is(err, ErrUserExists) {
+		return users.GetUserByUsername(identity) // Одновременный первый вход
+	}
+	return user, err
+}
+
+// introspect проверяет токен и возвращает имя его владельца
+func (p *OAuthProvider) introspect(ctx context.Context, token string) (string, error) {
+	form := url.Values{"token": {token}, "token_type_hint": {"access_token"}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.config.IntrospectionURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.config.ClientID != "" {
+		req.SetBasicAuth(p.config.ClientID, p.config.ClientSecret)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("oauth: introspection: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("oauth: introspection вернул %s", resp.Status)
+	}
+
+	var result introspectionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("oauth: некорректный ответ introspection: %w", err)
+	}
+	if !result.Active {
+		return "", ErrInvalidCredentials
+	}
+	identity := result.Username
+	if identity == "" {
+		identity = result.Subject
+	}
+	if identity == "" {
+		return "", errors.New("oauth: в ответе introspection нет username и sub")
+	}
+	return identity, nil
+}
+
+// unusablePasswordHash возвращает хэш случайного пароля, который нигде не сохраняется
+func unusablePasswordHash() string {
+	secret := make([]byte, 32)
+	_, _ = rand.Read(secret)
+	hash, err := HashPassword(hex.EncodeToString(secret))
+	if err != nil {
+		return "!" // Не является bcrypt-хэшем: CheckPassword всегда false
+	}
+	return hash
+}
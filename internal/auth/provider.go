@@ -0,0 +1,54 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidCredentials - пароль или токен не прошли проверку
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// Провайдеры аутентификации, выбираемые конфигурацией
+const (
+	ProviderLocal = "local" // Пароль из репозитория пользователей
+	ProviderOAuth = "oauth" // Токен доступа OAuth 2.0, проверяемый сервером авторизации
+)
+
+// AuthProvider проверяет учётные данные игрока. secret - пароль или токен,
+// в зависимости от провайдера. Провайдер возвращает учётную запись из users:
+// внешняя личность сопоставляется локальному пользователю, чтобы позиции и
+// прогресс хранились под постоянным UserID.
+//
+// Неверные данные - ErrInvalidCredentials или ErrUserNotFound; другие ошибки
+// означают сбой самого провайдера
+type AuthProvider interface {
+	Authenticate(ctx context.Context, users UserRepository, username, secret string) (*User, error)
+}
+
+// LocalProvider проверяет пароль по bcrypt-хэшу из репозитория пользователей
+type LocalProvider struct{}
+
+// Authenticate реализует AuthProvider
+func (LocalProvider) Authenticate(_ context.Context, users UserRepository, username, password string) (*User, error) {
+	user, err := users.GetUserByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+	if !CheckPassword(user.PasswordHash, password) {
+		return nil, ErrInvalidCredentials
+	}
+	return user, nil
+}
+
+// NewAuthProvider создаёт провайдер по имени из конфигурации ("" - ProviderLocal)
+func NewAuthProvider(name string, oauth OAuthConfig) (AuthProvider, error) {
+	switch name {
+	case "", ProviderLocal:
+		return LocalProvider{}, nil
+	case ProviderOAuth:
+		return NewOAuthProvider(oauth)
+	default:
+		return nil, fmt.Errorf("неизвестный провайдер аутентификации %q", name)
+	}
+}
@@ -0,0 +1,94 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newIntrospectionServer - сервер авторизации, считающий активным только
+// токен valid-token пользователя oauth-player
+func newIntrospectionServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id, secret, ok := r.BasicAuth(); !ok || id != "game" || secret != "s3cret" {
+			http.Error(w, "unauthorized client", http.StatusUnauthorized)
+			return
+		}
+		resp := map[string]interface{}{"active": false}
+		if r.PostFormValue("token") == "valid-token" {
+			resp = map[string]interface{}{"active": true, "sub": "oauth-player"}
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestOAuthProvider_AcceptsOnlyActiveTokens(t *testing.T) {
+	server := newIntrospectionServer(t)
+	users, err := NewMemoryUserRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider, err := NewOAuthProvider(OAuthConfig{
+		IntrospectionURL: server.URL,
+		ClientID:         "game",
+		ClientSecret:     "s3cret",
+		CreateUsers:      true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	if _, err := provider.Authenticate(ctx, users, "oauth-player", "expired-token"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("Неактивный токен: ожидалась ErrInvalidCredentials, получено %v", err)
+	}
+	if _, err := users.GetUserByUsername("oauth-player"); !errors.Is(err, ErrUserNotFound) {
+		t.Fatal("Учётная запись не должна создаваться для неактивного токена")
+	}
+
+	user, err := provider.Authenticate(ctx, users, "", "valid-token")
+	if err != nil {
+		t.Fatalf("Ошибка входа с активным токеном: %v", err)
+	}
+	if user.Username != "oauth-player" || user.IsAdmin {
+		t.Errorf("Ожидался обычный пользователь oauth-player, получен %+v", user)
+	}
+	again, err := provider.Authenticate(ctx, users, "OAuth-Player", "valid-token")
+	if err != nil || again.ID != user.ID {
+		t.Errorf("Повторный вход должен вернуть ту же учётную запись: %+v, %v", again, err)
+	}
+
+	// Токен принадлежит другому пользователю
+	if _, err := provider.Authenticate(ctx, users, "admin", "valid-token"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Чужой токен: ожидалась ErrInvalidCredentials, получено %v", err)
+	}
+
+	// Внешний пользователь не может войти по паролю
+	if _, err := (LocalProvider{}).Authenticate(ctx, users, "oauth-player", ""); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Вход по паролю внешнего пользователя должен отклоняться, получено %v", err)
+	}
+
+	// Сбой сервера авторизации - не то же самое, что неверный токен
+	broken, _ := NewOAuthProvider(OAuthConfig{IntrospectionURL: server.URL})
+	if _, err := broken.Authenticate(ctx, users, "", "valid-token"); err == nil || errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Ожидалась ошибка провайдера, получено %v", err)
+	}
+}
+
+func TestNewAuthProvider_SelectsByName(t *testing.T) {
+	if provider, err := NewAuthProvider("", OAuthConfig{}); err != nil || provider != (LocalProvider{}) {
+		t.Errorf("По умолчанию ожидался LocalProvider, получено %T, %v", provider, err)
+	}
+	if _, err := NewAuthProvider(ProviderOAuth, OAuthConfig{IntrospectionURL: "not a url"}); err == nil {
+		t.Error("Ожидалась ошибка для некорректного introspection_url")
+	}
+	if _, err := NewAuthProvider("ldap", OAuthConfig{}); err == nil {
+		t.Error("Ожидалась ошибка для неизвестного провайдера")
+	}
+}
@@ -12,22 +12,67 @@ import (
 // Пока содержит только EventBus; может расширяться.
 
 type Config struct {
-	EventBus EventBusConfig `yaml:"eventbus"`
-	Sync     SyncConfig     `yaml:"sync"`
-	Server   ServerConfig   `yaml:"server"`
+	EventBus  EventBusConfig  `yaml:"eventbus"`
+	Sync      SyncConfig      `yaml:"sync"`
+	Server    ServerConfig    `yaml:"server"`
+	Anticheat AnticheatConfig `yaml:"anticheat"`
+	Metrics   MetricsConfig   `yaml:"metrics"`
+	CORS      CORSConfig      `yaml:"cors"`
+	Auth      AuthConfig      `yaml:"auth"`
+	TLS       TLSConfig       `yaml:"tls"`
 }
 
 type EventBusConfig struct {
 	URL       string `yaml:"url"`
 	Stream    string `yaml:"stream"`
 	Retention int    `yaml:"retention_hours"`
+	// Required запрещает запуск без NATS (production). Иначе при недоступном
+	// NATS используется in-memory шина без межрегиональной синхронизации
+	Required bool `yaml:"required"`
+	// Namespace изолирует окружение (prod, staging) на общем NATS: префикс
+	// subjects и имени стрима. Пусто - без префикса
+	Namespace string `yaml:"namespace"`
+	// Журнал событий /api/admin/events: сколько минут узел хранит события
+	// шины в памяти (0 = по умолчанию, -1 = выключен)
+	EventLogMinutes int `yaml:"event_log_minutes"`
 }
 
 type SyncConfig struct {
-	RegionID     string `yaml:"region_id"`
-	BatchSize    int    `yaml:"batch_size"`
-	FlushEvery   int    `yaml:"flush_every_seconds"`
-	UseGzipCompr bool   `yaml:"use_gzip_compression"`
+	RegionID string `yaml:"region_id"`
+	// Пакет изменений отправляется при BatchSize изменений или через
+	// FlushEvery секунд после первого изменения - что наступит раньше
+	BatchSize    int  `yaml:"batch_size"`
+	FlushEvery   int  `yaml:"flush_every_seconds"`
+	UseGzipCompr bool `yaml:"use_gzip_compression"`
+	// Уникальный номер региона, кодируется в старших битах ID сущностей
+	RegionNumber uint16 `yaml:"region_number"`
+	// ID мира в subject событий EventBus: узел подписывается только на
+	// события своего мира и области (пусто - "main")
+	WorldID string `yaml:"world_id"`
+	// Регионы и области мира, которыми они владеют. Игрок, чья позиция
+	// принадлежит другому региону, перенаправляется туда при входе
+	Regions []RegionRouteConfig `yaml:"regions"`
+	// Тревога об отставании региона: средняя задержка репликации выше
+	// LagThresholdSeconds дольше LagWindowSeconds (0 = по умолчанию,
+	// отрицательный порог - контроль выключен)
+	LagThresholdSeconds int `yaml:"lag_threshold_seconds"`
+	LagWindowSeconds    int `yaml:"lag_window_seconds"`
+}
+
+// SpawnPointConfig координаты точки спавна в блоках
+type SpawnPointConfig struct {
+	X int `yaml:"x"`
+	Y int `yaml:"y"`
+}
+
+// RegionRouteConfig игровой адрес региона и его область в координатах блоков (границы включены)
+type RegionRouteConfig struct {
+	ID          string `yaml:"id"`
+	GameAddress string `yaml:"game_address"` // host:port игрового сервера региона
+	MinX        int    `yaml:"min_x"`
+	MinY        int    `yaml:"min_y"`
+	MaxX        int    `yaml:"max_x"`
+	MaxY        int    `yaml:"max_y"`
 }
 
 type ServerConfig struct {
@@ -35,6 +80,211 @@ type ServerConfig struct {
 	UDPPort     int `yaml:"udp_port"`
 	RESTPort    int `yaml:"rest_port"`
 	MetricsPort int `yaml:"metrics_port"`
+	// Порт gRPC сервиса воспроизведения событий (0 = 9090, -1 = выключен)
+	ReplayGRPCPort int `yaml:"replay_grpc_port"`
+
+	// Лимит одновременных подключений (0 = по умолчанию, -1 = без ограничения)
+	MaxConnections int `yaml:"max_connections"`
+	// TCP-порт для клиентов, у которых блокируется KCP/UDP (0 = порт KCP из tcp_port, -1 = выключено)
+	TCPFallbackPort int `yaml:"tcp_fallback_port"`
+	// Разрешить отладочным клиентам кодек JSON на TCP (false = только Protocol Buffers)
+	AllowJSONCodec bool `yaml:"allow_json_codec"`
+
+	// Диапазон поддерживаемых версий протокола клиентов (0 = текущая версия сервера)
+	MinProtocolVersion uint32 `yaml:"min_protocol_version"`
+	MaxProtocolVersion uint32 `yaml:"max_protocol_version"`
+
+	// Отключение неактивных игроков (0 = значения по умолчанию, -1 = выключено)
+	IdleTimeoutSeconds int `yaml:"idle_timeout_seconds"`
+	IdleWarningSeconds int `yaml:"idle_warning_seconds"`
+	// Отсрочка сохранения позиции при отключении: переподключение в её пределах
+	// не пишет и не читает хранилище позиций, мс (0 = по умолчанию, -1 = сохранять сразу)
+	ReconnectSaveGraceMs int `yaml:"reconnect_save_grace_ms"`
+
+	// Максимальная дальность видимости в чанках, которую может запросить клиент (0 = по умолчанию)
+	MaxViewDistance int `yaml:"max_view_distance"`
+	// Сущностей в одном ENTITY_MOVE обновления мира и таких сообщений игроку за
+	// обновление; ближайшие сущности идут первыми, остальные откладываются
+	// (0 = по умолчанию, -1 = без ограничения)
+	MaxEntitiesPerUpdate    int `yaml:"max_entities_per_update"`
+	MaxEntityUpdateMessages int `yaml:"max_entity_update_messages"`
+	// Мировых спавнов (животные, NPC, предметы) в секунду и их начальный запас;
+	// спавны сверх лимита отклоняются, игроки не ограничиваются
+	// (0 = по умолчанию, -1 = без ограничения)
+	WorldSpawnRate  int `yaml:"world_spawn_rate"`
+	WorldSpawnBurst int `yaml:"world_spawn_burst"`
+	// Горутин сериализации запрошенных чанков (0 = по числу CPU, -1 = в обработчике сообщений)
+	ChunkWorkers int `yaml:"chunk_workers"`
+	// Запрошенных клиентом чанков в секунду сверх запаса на область видимости (0 = по умолчанию, -1 = без ограничения)
+	ChunkRequestRate int `yaml:"chunk_request_rate"`
+	// Окно, в течение которого повторный запрос отправленного чанка отбрасывается, мс (0 = по умолчанию, -1 = выключено)
+	ChunkResendWindowMs int `yaml:"chunk_resend_window_ms"`
+
+	// Максимальная дистанция взаимодействия игрока с блоками (0 = по умолчанию)
+	MaxReachDistance float64 `yaml:"max_reach_distance"`
+	// Глубина буфера ввода движения в тиках: сглаживает рывки ценой задержки (0 = применять сразу)
+	MoveInputBuffer int `yaml:"move_input_buffer"`
+
+	// Режим игры новых игроков: survival, creative, adventure ("" = survival)
+	DefaultGameMode string `yaml:"default_game_mode"`
+	// Разрешённые действия с блоками по режимам (переопределяют встроенные списки)
+	GameModeActions map[string][]string `yaml:"game_mode_actions"`
+	// Добывает ли break блок в режиме, как mine (true), или сразу убирает его (false);
+	// без режима - встроенное правило
+	GameModeMineOnBreak map[string]bool `yaml:"game_mode_mine_on_break"`
+
+	// Игрок над пропастью: none, spawn, damage ("" = spawn)
+	VoidPolicy string `yaml:"void_policy"`
+	// Урон за секунду над пропастью (0 = по умолчанию) и здоровье, при котором
+	// игрок возвращается на спавн; только для void_policy: damage
+	VoidFallDamage  int `yaml:"void_fall_damage"`
+	VoidSpawnHealth int `yaml:"void_spawn_health"`
+
+	// Граница мира: квадрат с центром (world_border_center_x, world_border_center_y)
+	// и половиной стороны world_border_radius блоков (0 = граница из метаданных
+	// мира, если задана) и зона предупреждения у края в блоках
+	WorldBorderCenterX int `yaml:"world_border_center_x"`
+	WorldBorderCenterY int `yaml:"world_border_center_y"`
+	WorldBorderRadius  int `yaml:"world_border_radius"`
+	WorldBorderWarning int `yaml:"world_border_warning"`
+
+	// Точка спавна мира; не задана - сохранённая в метаданных мира или
+	// выведенная из сида. Заданная точка сохраняется в метаданных мира
+	WorldSpawn *SpawnPointConfig `yaml:"world_spawn"`
+
+	// Время добычи: true = прочность уменьшает поведение блока за каждый удар
+	DisableMiningTime bool `yaml:"disable_mining_time"`
+	// Минимальный интервал между засчитываемыми ударами (0 = по умолчанию, -1 = без ограничения)
+	MineHitIntervalMs int `yaml:"mine_hit_interval_ms"`
+	// Перерыв в ударах, после которого прогресс добычи сбрасывается (0 = по умолчанию)
+	MineResetMs int `yaml:"mine_reset_ms"`
+	// Прибавка к силе удара за уровень инструмента (0 = по умолчанию)
+	MineToolTierBonus float64 `yaml:"mine_tool_tier_bonus"`
+	// Уровень инструмента по ID предмета инвентаря (пусто = по умолчанию)
+	MineToolTiers map[string]int `yaml:"mine_tool_tiers"`
+
+	// Срок жизни предмета на земле, с (0 = по умолчанию, -1 = не исчезают)
+	ItemLifetimeSeconds int `yaml:"item_lifetime_seconds"`
+	// Расстояние, на котором одинаковые предметы объединяются в стопку (0 = по умолчанию, -1 = не объединяются)
+	ItemMergeRadius float64 `yaml:"item_merge_radius"`
+
+	// Максимальный размер входящего сообщения в байтах (0 = 1MB)
+	MaxPayloadBytes int `yaml:"max_payload_bytes"`
+	// Число слишком больших сообщений до отключения клиента (0 = по умолчанию, -1 = не отключать)
+	MaxOversizedMessages int `yaml:"max_oversized_messages"`
+	// Число сообщений неизвестного типа до отключения клиента (0 = по умолчанию, -1 = не отключать)
+	MaxUnknownMessages int `yaml:"max_unknown_messages"`
+	// Не добавлять в ENTITY_MOVE тик и время сервера для интерполяции (экономия трафика)
+	DisableMoveTimestamps bool `yaml:"disable_move_timestamps"`
+	// Предел обработки одного сообщения клиента, мс (0 = по умолчанию, -1 = без ограничения)
+	MessageTimeoutMs int `yaml:"message_timeout_ms"`
+	// Порог медленной обработки сообщения для предупреждений в логе, мс (0 = по умолчанию, -1 = без предупреждений)
+	SlowHandlerThresholdMs int `yaml:"slow_handler_threshold_ms"`
+
+	// Размер буфера глобальных событий мира (0 = по умолчанию)
+	WorldEventBuffer int `yaml:"world_event_buffer"`
+	// Ожидание места в переполненной очереди для изменений блоков, мс (0 = по умолчанию, -1 = не ждать)
+	CriticalEventTimeoutMs int `yaml:"critical_event_timeout_ms"`
+	// Предел сущностей в одном BigChunk и во всём мире (0 = по умолчанию, -1 = без ограничения)
+	MaxEntitiesPerBigChunk int `yaml:"max_entities_per_bigchunk"`
+	MaxWorldEntities       int `yaml:"max_world_entities"`
+	// Воркеров симуляции BigChunk: BigChunk с событиями или тиком обслуживаются
+	// пулом вместо горутины на каждый (0 = горутина на каждый BigChunk)
+	BigChunkWorkers int `yaml:"bigchunk_workers"`
+	// Частота тиков BigChunk без игроков, тиков/с (0 = по умолчанию, -1 = всегда полная частота)
+	IdleBigChunkTickRate float64 `yaml:"idle_bigchunk_tick_rate"`
+	// Время без активности до перехода BigChunk на пониженную частоту, мс (0 = по умолчанию)
+	IdleBigChunkAfterMs int `yaml:"idle_bigchunk_after_ms"`
+	// Окно накопления изменений блоков перед рассылкой, мс (0 = по умолчанию, -1 = рассылать сразу)
+	BlockUpdateWindowMs int `yaml:"block_update_window_ms"`
+	// Предел суммарного размера метаданных одного блока, байт (0 = по умолчанию, -1 = без ограничения)
+	MaxBlockMetadataBytes int `yaml:"max_block_metadata_bytes"`
+
+	// Файл параметров, изменённых через /api/admin/runtime ("" = data/runtime_overrides.json)
+	RuntimeOverridesFile string `yaml:"runtime_overrides_file"`
+
+	// Каталог метаданных мира: точка спавна, приваты, граница ("" = data/world)
+	WorldMetaDir string `yaml:"world_meta_dir"`
+}
+
+// AnticheatConfig настройки правил античита (0 = значения по умолчанию)
+type AnticheatConfig struct {
+	// Отключённые правила: speed, reach, block_edit_rate, teleport
+	DisabledRules []string `yaml:"disabled_rules"`
+
+	MaxSpeed            float64 `yaml:"max_speed"`             // Блоков в секунду
+	MaxReachDistance    float64 `yaml:"max_reach_distance"`    // 0 = server.max_reach_distance
+	MaxBlockEdits       int     `yaml:"max_block_edits"`       // Правок блоков за окно
+	BlockEditWindowMs   int     `yaml:"block_edit_window_ms"`  // Окно подсчёта правок, мс
+	MaxTeleportDistance float64 `yaml:"max_teleport_distance"` // Блоков за одно перемещение
+
+	// Действия игрока, публикуемые как anticheat.evidence при нарушении
+	EvidenceWindowMs  int `yaml:"evidence_window_ms"`  // Окно перед нарушением, мс
+	EvidenceMaxEvents int `yaml:"evidence_max_events"` // Максимум действий в пакете
+}
+
+// CORSConfig политика кросс-доменных запросов к REST API. Без настройки
+// браузерам с чужих доменов доступ запрещён
+type CORSConfig struct {
+	AllowedOrigins   []string `yaml:"allowed_origins"` // Публичные эндпоинты; "*" = любой сайт
+	AllowedMethods   []string `yaml:"allowed_methods"` // Пусто = GET, POST, PUT, DELETE, OPTIONS
+	AllowedHeaders   []string `yaml:"allowed_headers"` // Пусто = Origin, Content-Type, Accept, Authorization
+	AllowCredentials bool     `yaml:"allow_credentials"`
+	MaxAgeSeconds    int      `yaml:"max_age_seconds"` // Кэширование preflight браузером
+
+	// /api/admin: только перечисленные origin ("*" не допускается)
+	AdminAllowedOrigins []string `yaml:"admin_allowed_origins"`
+}
+
+// AuthConfig проверка учётных данных при входе в игру и в REST API
+type AuthConfig struct {
+	Provider string          `yaml:"provider"` // local, oauth ("" = local)
+	OAuth    OAuthAuthConfig `yaml:"oauth"`
+
+	// Блокировка учётной записи после серии неудачных попыток входа
+	// (0 = по умолчанию, -1 = выключено); окно подсчёта равно длительности блокировки
+	LockoutThreshold     int `yaml:"lockout_threshold"`
+	LockoutWindowSeconds int `yaml:"lockout_window_seconds"`
+}
+
+// TLSConfig сертификаты REST API и сервиса воспроизведения (PEM). Без
+// cert_file сервер работает открытым текстом - только для локальной отладки.
+// Сертификаты перечитываются по SIGHUP
+type TLSConfig struct {
+	CertFile string `yaml:"cert_file"`
+	KeyFile  string `yaml:"key_file"`
+	// CA сертификатов администраторов: /api/admin и gRPC сервис
+	// воспроизведения требуют подписанный им сертификат клиента (mTLS).
+	// Пусто = только JWT
+	ClientCAFile string `yaml:"client_ca_file"`
+	// Required запрещает запуск без cert_file (production)
+	Required bool `yaml:"required"`
+}
+
+// OAuthAuthConfig проверка токенов доступа через introspection сервера авторизации.
+// Клиент передаёт токен вместо пароля
+type OAuthAuthConfig struct {
+	IntrospectionURL string `yaml:"introspection_url"`
+	ClientID         string `yaml:"client_id"`
+	ClientSecret     string `yaml:"client_secret"`
+	CreateUsers      bool   `yaml:"create_users"` // Создавать учётную запись при первом входе
+	TimeoutMs        int    `yaml:"timeout_ms"`   // 0 = по умолчанию
+}
+
+// MetricsConfig способ экспорта метрик. По умолчанию Prometheus опрашивает
+// /metrics на server.metrics_port; короткоживущие узлы за NAT могут
+// отправлять метрики сами
+type MetricsConfig struct {
+	DisableScrape bool              `yaml:"disable_scrape"` // Не поднимать /metrics
+	Push          MetricsPushConfig `yaml:"push"`
+}
+
+// MetricsPushConfig периодическая отправка метрик в OTLP-коллектор
+type MetricsPushConfig struct {
+	Enabled         bool              `yaml:"enabled"`
+	Endpoint        string            `yaml:"endpoint"`         // "" = http://localhost:4318/v1/metrics
+	IntervalSeconds int               `yaml:"interval_seconds"` // 0 = 15
+	Headers         map[string]string `yaml:"headers"`
 }
 
 // GetTCPPort возвращает TCP порт с поддержкой fallback значений
@@ -57,6 +307,38 @@ func (s *ServerConfig) GetMetricsPort() int {
 	return getPortWithEnvFallback(s.MetricsPort, "GAME_METRICS_PORT", 2112)
 }
 
+// GetReplayGRPCPort возвращает порт gRPC сервиса воспроизведения (-1 = выключен)
+func (s *ServerConfig) GetReplayGRPCPort() int {
+	if s.ReplayGRPCPort < 0 {
+		return -1
+	}
+	return getPortWithEnvFallback(s.ReplayGRPCPort, "GAME_REPLAY_GRPC_PORT", 9090)
+}
+
+// GetRuntimeOverridesFile возвращает путь файла переопределений параметров сервера
+func (s *ServerConfig) GetRuntimeOverridesFile() string {
+	if s.RuntimeOverridesFile != "" {
+		return s.RuntimeOverridesFile
+	}
+	return "data/runtime_overrides.json"
+}
+
+// GetWorldMetaDir возвращает каталог метаданных игрового мира
+func (s *ServerConfig) GetWorldMetaDir() string {
+	if s.WorldMetaDir != "" {
+		return s.WorldMetaDir
+	}
+	return "data/world"
+}
+
+// GetWorldID возвращает ID мира, которым помечаются события EventBus
+func (s *SyncConfig) GetWorldID() string {
+	if s.WorldID != "" {
+		return s.WorldID
+	}
+	return "main"
+}
+
 // getPortWithEnvFallback возвращает порт с приоритетом: config -> env -> default
 func getPortWithEnvFallback(configPort int, envVar string, defaultPort int) int {
 	// Если порт задан в конфиге и больше 0, используем его
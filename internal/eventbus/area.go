@@ -0,0 +1,78 @@
+package eventbus
+
+import "strconv"
+
+// Ключи Envelope.Metadata, по которым работают фильтры WorldIDs и Bounds.
+// События без этих ключей не привязаны к миру или области (например,
+// SyncBatch) и проходят соответствующий фильтр.
+const (
+	MetaWorldID = "world_id"
+	MetaX       = "x"
+	MetaY       = "y"
+)
+
+// Bounds - прямоугольная область мировых координат блоков, границы включены.
+type Bounds struct {
+	MinX, MinY int
+	MaxX, MaxY int
+}
+
+// Contains проверяет, лежит ли точка в области.
+func (b Bounds) Contains(x, y int) bool {
+	return x >= b.MinX && x <= b.MaxX && y >= b.MinY && y <= b.MaxY
+}
+
+// SetPosition записывает мир и координаты события в Metadata.
+// Пустой worldID не записывается.
+func (ev *Envelope) SetPosition(worldID string, x, y int) {
+	if ev.Metadata == nil {
+		ev.Metadata = make(map[string]string, 3)
+	}
+	if worldID != "" {
+		ev.Metadata[MetaWorldID] = worldID
+	}
+	ev.Metadata[MetaX] = strconv.Itoa(x)
+	ev.Metadata[MetaY] = strconv.Itoa(y)
+}
+
+// Position возвращает координаты события, если они заданы.
+func (ev *Envelope) Position() (x, y int, ok bool) {
+	xs, okX := ev.Metadata[MetaX]
+	ys, okY := ev.Metadata[MetaY]
+	if !okX || !okY {
+		return 0, 0, false
+	}
+	x, errX := strconv.Atoi(xs)
+	y, errY := strconv.Atoi(ys)
+	if errX != nil || errY != nil {
+		return 0, 0, false
+	}
+	return x, y, true
+}
+
+func matchWorld(ev *Envelope, worldIDs []string) bool {
+	if len(worldIDs) == 0 {
+		return true
+	}
+	worldID, ok := ev.Metadata[MetaWorldID]
+	if !ok {
+		return true
+	}
+	for _, id := range worldIDs {
+		if id == worldID {
+			return true
+		}
+	}
+	return false
+}
+
+func matchBounds(ev *Envelope, bounds *Bounds) bool {
+	if bounds == nil {
+		return true
+	}
+	x, y, ok := ev.Position()
+	if !ok {
+		return true
+	}
+	return bounds.Contains(x, y)
+}
@@ -0,0 +1,77 @@
+package eventbus
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func blockEvent(id, worldID string, x, y int) *Envelope {
+	ev := &Envelope{ID: id, EventType: "BlockEvent", Source: "world_manager"}
+	ev.SetPosition(worldID, x, y)
+	return ev
+}
+
+func TestFilter_BoundedSubscriptionSkipsOutOfRangeEvents(t *testing.T) {
+	bus := NewMemoryBus(16)
+	ctx := context.Background()
+
+	area := collect(t, bus, Filter{
+		Types:  []string{"BlockEvent"},
+		Bounds: &Bounds{MinX: 0, MinY: 0, MaxX: 99, MaxY: 99},
+	})
+	all := collect(t, bus, Filter{})
+
+	require.NoError(t, bus.Publish(ctx, blockEvent("inside", "", 10, 20)))
+	require.NoError(t, bus.Publish(ctx, blockEvent("edge", "", 99, 0)))
+	require.NoError(t, bus.Publish(ctx, blockEvent("outside", "", 100, 5)))
+	require.NoError(t, bus.Publish(ctx, blockEvent("negative", "", -1, 5)))
+	require.NoError(t, bus.Publish(ctx, &Envelope{ID: "batch", EventType: "BlockEvent"}))
+
+	// Пустой фильтр по-прежнему получает все события
+	assert.Len(t, all(5), 5)
+
+	var ids []string
+	for _, ev := range area(3) {
+		ids = append(ids, ev.ID)
+	}
+	// Событие без координат не привязано к области и доставляется
+	assert.ElementsMatch(t, []string{"inside", "edge", "batch"}, ids)
+}
+
+func TestFilter_WorldIDs(t *testing.T) {
+	bus := NewMemoryBus(16)
+	ctx := context.Background()
+
+	overworld := collect(t, bus, Filter{WorldIDs: []string{"overworld"}})
+	all := collect(t, bus, Filter{})
+
+	require.NoError(t, bus.Publish(ctx, blockEvent("1", "overworld", 0, 0)))
+	require.NoError(t, bus.Publish(ctx, blockEvent("2", "nether", 0, 0)))
+	require.NoError(t, bus.Publish(ctx, blockEvent("3", "overworld", 5, 5)))
+
+	// Фильтр проверяется при рассылке, поэтому после доставки всех событий
+	// в общую подписку лишнее событие уже не появится
+	all(3)
+	received := overworld(2)
+	require.Len(t, received, 2)
+	for _, ev := range received {
+		assert.Equal(t, "overworld", ev.Metadata[MetaWorldID])
+	}
+}
+
+func TestEnvelope_Position(t *testing.T) {
+	ev := blockEvent("1", "w", -12, 34)
+	x, y, ok := ev.Position()
+	require.True(t, ok)
+	assert.Equal(t, -12, x)
+	assert.Equal(t, 34, y)
+
+	_, _, ok = (&Envelope{}).Position()
+	assert.False(t, ok)
+
+	_, _, ok = (&Envelope{Metadata: map[string]string{MetaX: "a", MetaY: "1"}}).Position()
+	assert.False(t, ok)
+}
@@ -2,7 +2,9 @@ package eventbus
 
 import (
 	"context"
+	"errors"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -22,9 +24,12 @@ type Envelope struct {
 }
 
 // Filter позволяет подписаться только на нужные события.
+// Пустой фильтр пропускает все события.
 type Filter struct {
-	Types   []string // Если пусто — все типы.
-	Sources []string // Если пусто — все источники.
+	Types    []string // Если пусто — все типы.
+	Sources  []string // Если пусто — все источники.
+	WorldIDs []string // Если пусто — все миры (см. MetaWorldID).
+	Bounds   *Bounds  // Если nil — любые координаты (см. MetaX/MetaY).
 }
 
 // Subscription возвращается при подписке; позволяет отписаться.
@@ -43,6 +48,23 @@ type Stats struct {
 	InFlight  int
 }
 
+// ErrBusClosed возвращается Publish после Drain.
+var ErrBusClosed = errors.New("eventbus closed")
+
+// Drainer - шина, которая при остановке дожидается доставки опубликованных событий.
+type Drainer interface {
+	Drain(ctx context.Context) error
+}
+
+// Drain останавливает шину, дождавшись доставки событий (если шина это
+// поддерживает). После этого Publish возвращает ErrBusClosed.
+func Drain(ctx context.Context, bus EventBus) error {
+	if d, ok := bus.(Drainer); ok {
+		return d.Drain(ctx)
+	}
+	return nil
+}
+
 // EventBus определяет абстракцию шины событий.
 // В дальнейшем может иметь разные реализации (JetStream, Kafka, Redis).
 type EventBus interface {
@@ -60,6 +82,10 @@ type memoryBus struct {
 	stats       Stats
 	buffer      chan *Envelope
 	capacity    int
+
+	closed   atomic.Bool    // После Drain новые события не принимаются
+	pending  atomic.Int64   // События в буфере, ещё не переданные обработчикам
+	handlers sync.WaitGroup // Выполняющиеся обработчики
 }
 
 type subscriber struct {
@@ -81,6 +107,11 @@ func NewMemoryBus(capacity int) EventBus {
 }
 
 func (mb *memoryBus) Publish(ctx context.Context, ev *Envelope) error {
+	if mb.closed.Load() {
+		return ErrBusClosed
+	}
+	mb.pending.Add(1)
+
 	select {
 	case mb.buffer <- ev:
 		mb.mu.Lock()
@@ -90,6 +121,7 @@ func (mb *memoryBus) Publish(ctx context.Context, ev *Envelope) error {
 	default:
 		// Буфер заполнен — дропаём низкий приоритет (<5)
 		if ev.Priority < 5 {
+			mb.pending.Add(-1)
 			mb.mu.Lock()
 			mb.stats.Dropped++
 			mb.mu.Unlock()
@@ -103,6 +135,7 @@ func (mb *memoryBus) Publish(ctx context.Context, ev *Envelope) error {
 			mb.mu.Unlock()
 			return nil
 		case <-ctx.Done():
+			mb.pending.Add(-1)
 			return ctx.Err()
 		}
 	}
@@ -142,7 +175,9 @@ func (mb *memoryBus) dispatchLoop() {
 				continue
 			}
 			// Передаём копию в handler
+			mb.handlers.Add(1)
 			go func(s subscriber) {
+				defer mb.handlers.Done()
 				select {
 				case <-s.ctx.Done():
 					return
@@ -154,6 +189,35 @@ func (mb *memoryBus) dispatchLoop() {
 				}
 			}(sub)
 		}
+		mb.pending.Add(-1)
+	}
+}
+
+// Drain перестаёт принимать события и ждёт, пока обработчики получат и
+// обработают уже опубликованные
+func (mb *memoryBus) Drain(ctx context.Context) error {
+	mb.closed.Store(true)
+
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for mb.pending.Load() > 0 {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		mb.handlers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
 }
 
@@ -169,7 +233,8 @@ func matchFilter(ev *Envelope, f Filter) bool {
 		}
 		return false
 	}
-	return match(ev.EventType, f.Types) && match(ev.Source, f.Sources)
+	return match(ev.EventType, f.Types) && match(ev.Source, f.Sources) &&
+		matchWorld(ev, f.WorldIDs) && matchBounds(ev, f.Bounds)
 }
 
 type memSub struct {
@@ -3,7 +3,9 @@ package eventbus
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"slices"
 	"sync/atomic"
 	"time"
 
@@ -15,17 +17,24 @@ type JetStreamBus struct {
 	nc        *nats.Conn
 	js        nats.JetStreamContext
 	stream    string
+	namespace string
 	published uint64
 	consumed  uint64
 	dropped   uint64
 }
 
 // NewJetStreamBus подключается к кластеру NATS и гарантирует наличие стрима.
-// url: nats://127.0.0.1:4222, stream: "EVENTS".
-func NewJetStreamBus(url, stream string, retention time.Duration) (*JetStreamBus, error) {
+// url: nats://127.0.0.1:4222, stream: "EVENTS". Непустой namespace изолирует
+// окружение на общем NATS: стрим "<namespace>_EVENTS", subjects
+// "<namespace>.events.>" (см. ValidateNamespace).
+func NewJetStreamBus(url, namespace, stream string, retention time.Duration) (*JetStreamBus, error) {
+	if err := ValidateNamespace(namespace); err != nil {
+		return nil, err
+	}
 	if stream == "" {
 		stream = "EVENTS"
 	}
+	stream = StreamName(namespace, stream)
 
 	nc, err := nats.Connect(url)
 	if err != nil {
@@ -38,12 +47,13 @@ func NewJetStreamBus(url, stream string, retention time.Duration) (*JetStreamBus
 		return nil, fmt.Errorf("jetstream: %w", err)
 	}
 
-	// Ensure stream exists (subjects: events.*)
-	_, err = js.StreamInfo(stream)
+	// Ensure stream exists (subjects: <prefix>.>, см. EventSubject)
+	subjects := []string{SubjectPrefix(namespace) + ".>"}
+	info, err := js.StreamInfo(stream)
 	if err != nil {
 		_, err = js.AddStream(&nats.StreamConfig{
 			Name:      stream,
-			Subjects:  []string{"events.*"},
+			Subjects:  subjects,
 			Retention: nats.LimitsPolicy,
 			MaxAge:    retention,
 			Storage:   nats.FileStorage,
@@ -52,14 +62,27 @@ func NewJetStreamBus(url, stream string, retention time.Duration) (*JetStreamBus
 			nc.Drain()
 			return nil, fmt.Errorf("add stream: %w", err)
 		}
+	} else if !slices.Equal(info.Config.Subjects, subjects) {
+		// Стрим создан до кодирования мира и области в subject
+		cfg := info.Config
+		cfg.Subjects = subjects
+		if _, err := js.UpdateStream(&cfg); err != nil {
+			nc.Drain()
+			return nil, fmt.Errorf("update stream subjects: %w", err)
+		}
 	}
 
-	return &JetStreamBus{nc: nc, js: js, stream: stream}, nil
+	return &JetStreamBus{nc: nc, js: js, stream: stream, namespace: namespace}, nil
 }
 
-// Publish сериализует Envelope в JSON и публикует в subject events.<type>.
+// Namespace возвращает пространство имён шины
+func (jb *JetStreamBus) Namespace() string { return jb.namespace }
+
+// Publish сериализует Envelope в JSON и публикует в subject
+// <prefix>.<type>.<world>.<cell> (см. EventSubject).
 func (jb *JetStreamBus) Publish(ctx context.Context, ev *Envelope) error {
-	subj := fmt.Sprintf("events.%s", ev.EventType)
+	subj := EventSubject(jb.namespace, ev)
+	ev.SetNamespace(jb.namespace)
 	data, err := json.Marshal(ev)
 	if err != nil {
 		return err
@@ -72,26 +95,29 @@ func (jb *JetStreamBus) Publish(ctx context.Context, ev *Envelope) error {
 }
 
 // Subscribe создаёт durable consumer и вызывает handler асинхронно.
+// Тип, мир и область фильтра отбираются сервером по subject (см.
+// FilterSubjects), поэтому подписчик не получает чужие события.
 func (jb *JetStreamBus) Subscribe(ctx context.Context, f Filter, h Handler) (Subscription, error) {
-	subj := "events.*"
-	if len(f.Types) == 1 {
-		subj = fmt.Sprintf("events.%s", f.Types[0])
-	}
-
 	durable := nats.Durable(fmt.Sprintf("sub_%d", time.Now().UnixNano()))
+	opts := []nats.SubOpt{nats.ManualAck(), durable, nats.AckWait(30 * time.Second)}
+
+	subj := ""
+	if subjects := FilterSubjects(jb.namespace, f); len(subjects) == 1 {
+		subj = subjects[0]
+	} else {
+		opts = append(opts, nats.BindStream(jb.stream), nats.ConsumerFilterSubjects(subjects...))
+	}
 
 	natSub, err := jb.js.Subscribe(subj, func(msg *nats.Msg) {
 		var ev Envelope
-		if err := json.Unmarshal(msg.Data, &ev); err == nil {
+		// Subject отбирает события с точностью до ячейки области, источник
+		// и точные границы проверяем до вызова обработчика
+		if err := json.Unmarshal(msg.Data, &ev); err == nil && matchFilter(&ev, f) {
 			h(ctx, &ev)
 			atomic.AddUint64(&jb.consumed, 1)
 		}
 		_ = msg.Ack()
-	}, nats.ManualAck(), durable, nats.AckWait(30*time.Second))
-	if err != nil {
-		return nil, err
-	}
-
+	}, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -108,6 +134,26 @@ func (j *jetSub) Unsubscribe() {
 	_ = j.s.Unsubscribe()
 }
 
+// Drain отправляет буферизованные публикации, дожидается обработки уже
+// полученных сообщений подписчиками и закрывает соединение с NATS.
+func (jb *JetStreamBus) Drain(ctx context.Context) error {
+	if err := jb.nc.Drain(); err != nil && !errors.Is(err, nats.ErrConnectionClosed) {
+		return fmt.Errorf("nats drain: %w", err)
+	}
+
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for !jb.nc.IsClosed() {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			jb.nc.Close()
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
 // Metrics возвращает текущие метрики.
 func (jb *JetStreamBus) Metrics() Stats {
 	return Stats{
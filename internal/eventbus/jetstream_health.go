@@ -0,0 +1,42 @@
+package eventbus
+
+import (
+	"fmt"
+	"time"
+)
+
+// StreamHealth описывает доступность NATS и состояние стрима событий.
+type StreamHealth struct {
+	Connected  bool          // Соединение с NATS установлено и стрим доступен
+	Error      string        // Причина недоступности
+	Stream     string        // Имя стрима
+	Messages   uint64        // Сообщений в стриме
+	Bytes      uint64        // Объём стрима в байтах
+	MaxAge     time.Duration // Срок хранения событий (0 = без ограничения)
+	FirstEvent time.Time     // Время самого старого сообщения
+	LastEvent  time.Time     // Время самого нового сообщения
+}
+
+// Health проверяет соединение с NATS и запрашивает информацию о стриме.
+func (jb *JetStreamBus) Health() StreamHealth {
+	health := StreamHealth{Stream: jb.stream}
+
+	if jb.nc == nil || !jb.nc.IsConnected() {
+		health.Error = "nats: not connected"
+		return health
+	}
+
+	info, err := jb.js.StreamInfo(jb.stream)
+	if err != nil {
+		health.Error = fmt.Sprintf("stream info: %v", err)
+		return health
+	}
+
+	health.Connected = true
+	health.Messages = info.State.Msgs
+	health.Bytes = info.State.Bytes
+	health.MaxAge = info.Config.MaxAge
+	health.FirstEvent = info.State.FirstTime
+	health.LastEvent = info.State.LastTime
+	return health
+}
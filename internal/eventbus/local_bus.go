@@ -0,0 +1,156 @@
+package eventbus
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/logging"
+)
+
+const (
+	// DefaultLocalBusCapacity - буфер доставки подписчикам
+	DefaultLocalBusCapacity = 1024
+	// DefaultLocalBusMaxEvents - сколько событий LocalBus хранит для Replay
+	DefaultLocalBusMaxEvents = 10000
+)
+
+// LocalBusConfig настройки in-memory шины одиночного узла
+type LocalBusConfig struct {
+	Capacity  int           // Буфер доставки (0 = DefaultLocalBusCapacity)
+	Retention time.Duration // Максимальный возраст хранимых событий (0 = без ограничения)
+	MaxEvents int           // Максимум хранимых событий (0 = DefaultLocalBusMaxEvents)
+	Namespace string        // Пространство имён событий (см. WithNamespace)
+}
+
+// LocalBus - in-memory EventBus, используемый вместо JetStream, когда NATS
+// недоступен. Доставляет события подписчикам этого процесса и хранит
+// ограниченную историю для Replay, но не синхронизирует регионы
+type LocalBus struct {
+	EventBus // memoryBus: Publish/Subscribe/Metrics
+
+	cfg     LocalBusConfig
+	mu      sync.RWMutex
+	history []*Envelope // По возрастанию времени публикации
+	now     func() time.Time
+	closed  atomic.Bool
+}
+
+// NewLocalBus создаёт in-memory шину с ограниченным хранением
+func NewLocalBus(cfg LocalBusConfig) *LocalBus {
+	if cfg.Capacity <= 0 {
+		cfg.Capacity = DefaultLocalBusCapacity
+	}
+	if cfg.MaxEvents <= 0 {
+		cfg.MaxEvents = DefaultLocalBusMaxEvents
+	}
+	return &LocalBus{
+		EventBus: NewMemoryBus(cfg.Capacity),
+		cfg:      cfg,
+		now:      time.Now,
+	}
+}
+
+// Publish сохраняет событие в истории и доставляет подписчикам
+func (lb *LocalBus) Publish(ctx context.Context, ev *Envelope) error {
+	if lb.closed.Load() {
+		return ErrBusClosed
+	}
+	if ev.Timestamp.IsZero() {
+		ev.Timestamp = lb.now().UTC()
+	}
+	ev.SetNamespace(lb.cfg.Namespace)
+
+	lb.mu.Lock()
+	lb.history = append(lb.history, ev)
+	lb.trimLocked()
+	lb.mu.Unlock()
+
+	return lb.EventBus.Publish(ctx, ev)
+}
+
+// Namespace возвращает пространство имён шины
+func (lb *LocalBus) Namespace() string { return lb.cfg.Namespace }
+
+// Drain перестаёт принимать события и ждёт доставки опубликованных
+func (lb *LocalBus) Drain(ctx context.Context) error {
+	lb.closed.Store(true)
+	return Drain(ctx, lb.EventBus)
+}
+
+// Replay возвращает сохранённые события не старше since, подходящие под
+// фильтр, в порядке публикации
+func (lb *LocalBus) Replay(f Filter, since time.Time) []*Envelope {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+	lb.trimLocked()
+
+	var result []*Envelope
+	for _, ev := range lb.history {
+		if ev.Timestamp.Before(since) || !matchFilter(ev, f) {
+			continue
+		}
+		result = append(result, ev)
+	}
+	return result
+}
+
+// Health описывает LocalBus в терминах StreamHealth: шина всегда доступна
+func (lb *LocalBus) Health() StreamHealth {
+	lb.mu.RLock()
+	defer lb.mu.RUnlock()
+
+	health := StreamHealth{
+		Connected: true,
+		Stream:    "local",
+		Messages:  uint64(len(lb.history)),
+		MaxAge:    lb.cfg.Retention,
+	}
+	if len(lb.history) > 0 {
+		health.FirstEvent = lb.history[0].Timestamp
+		health.LastEvent = lb.history[len(lb.history)-1].Timestamp
+	}
+	return health
+}
+
+// trimLocked удаляет события сверх лимита и старше срока хранения
+func (lb *LocalBus) trimLocked() {
+	drop := len(lb.history) - lb.cfg.MaxEvents
+	if drop < 0 {
+		drop = 0
+	}
+	if lb.cfg.Retention > 0 {
+		cutoff := lb.now().Add(-lb.cfg.Retention)
+		for drop < len(lb.history) && lb.history[drop].Timestamp.Before(cutoff) {
+			drop++
+		}
+	}
+	if drop > 0 {
+		// Обнуляем ссылки, чтобы старые события собрал GC; при следующем
+		// росте append скопирует только оставшуюся часть
+		clear(lb.history[:drop])
+		lb.history = lb.history[drop:]
+	}
+}
+
+// ConnectOrFallback подключается к NATS JetStream. Если NATS недоступен и
+// required == false, возвращает LocalBus: одиночный узел продолжает работу,
+// но межрегиональная синхронизация отключена. Недопустимый namespace -
+// ошибка в любом случае
+func ConnectOrFallback(url, namespace, stream string, retention time.Duration, required bool) (EventBus, error) {
+	if err := ValidateNamespace(namespace); err != nil {
+		return nil, err
+	}
+	bus, err := NewJetStreamBus(url, namespace, stream, retention)
+	if err == nil {
+		return bus, nil
+	}
+	if required {
+		return nil, fmt.Errorf("NATS required but unavailable: %w", err)
+	}
+
+	logging.Warn("⚠️ NATS недоступен (%v): используется in-memory EventBus, межрегиональная синхронизация ОТКЛЮЧЕНА", err)
+	return NewLocalBus(LocalBusConfig{Retention: retention, Namespace: namespace}), nil
+}
@@ -0,0 +1,126 @@
+package eventbus
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// collect подписывается на шину и возвращает функцию ожидания n событий
+func collect(t *testing.T, bus EventBus, f Filter) func(n int) []*Envelope {
+	t.Helper()
+	var (
+		mu       sync.Mutex
+		received []*Envelope
+	)
+	sub, err := bus.Subscribe(context.Background(), f, func(ctx context.Context, ev *Envelope) {
+		mu.Lock()
+		received = append(received, ev)
+		mu.Unlock()
+	})
+	require.NoError(t, err)
+	t.Cleanup(sub.Unsubscribe)
+
+	return func(n int) []*Envelope {
+		require.Eventually(t, func() bool {
+			mu.Lock()
+			defer mu.Unlock()
+			return len(received) >= n
+		}, time.Second, 5*time.Millisecond)
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]*Envelope(nil), received...)
+	}
+}
+
+func TestLocalBus_PublishSubscribe(t *testing.T) {
+	bus := NewLocalBus(LocalBusConfig{})
+	ctx := context.Background()
+
+	allEvents := collect(t, bus, Filter{})
+	chatOnly := collect(t, bus, Filter{Types: []string{"ChatEvent"}})
+
+	require.NoError(t, bus.Publish(ctx, &Envelope{ID: "1", EventType: "BlockEvent", Source: "world"}))
+	require.NoError(t, bus.Publish(ctx, &Envelope{ID: "2", EventType: "ChatEvent", Source: "chat"}))
+
+	assert.Len(t, allEvents(2), 2)
+	chat := chatOnly(1)
+	require.Len(t, chat, 1)
+	assert.Equal(t, "2", chat[0].ID)
+
+	assert.Eventually(t, func() bool {
+		stats := bus.Metrics()
+		return stats.Published == 2 && stats.Consumed == 3
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestLocalBus_Unsubscribe(t *testing.T) {
+	bus := NewLocalBus(LocalBusConfig{})
+	ctx := context.Background()
+
+	received := make(chan *Envelope, 4)
+	sub, err := bus.Subscribe(ctx, Filter{}, func(ctx context.Context, ev *Envelope) { received <- ev })
+	require.NoError(t, err)
+
+	require.NoError(t, bus.Publish(ctx, &Envelope{ID: "1", EventType: "BlockEvent"}))
+	<-received
+	sub.Unsubscribe()
+
+	require.NoError(t, bus.Publish(ctx, &Envelope{ID: "2", EventType: "BlockEvent"}))
+	select {
+	case ev := <-received:
+		t.Fatalf("событие %s доставлено после отписки", ev.ID)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestLocalBus_ReplayRespectsRetention(t *testing.T) {
+	bus := NewLocalBus(LocalBusConfig{Retention: time.Hour, MaxEvents: 3})
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	bus.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	publishAt := func(id, eventType string, at time.Time) {
+		require.NoError(t, bus.Publish(ctx, &Envelope{ID: id, EventType: eventType, Timestamp: at}))
+	}
+	publishAt("old", "BlockEvent", now.Add(-2*time.Hour))
+	publishAt("a", "BlockEvent", now.Add(-30*time.Minute))
+	publishAt("b", "ChatEvent", now.Add(-20*time.Minute))
+	publishAt("c", "BlockEvent", now.Add(-10*time.Minute))
+	publishAt("d", "BlockEvent", now)
+
+	ids := func(events []*Envelope) []string {
+		result := make([]string, len(events))
+		for i, ev := range events {
+			result[i] = ev.ID
+		}
+		return result
+	}
+
+	// "old" старше срока хранения, "a" вытеснено лимитом MaxEvents
+	assert.Equal(t, []string{"b", "c", "d"}, ids(bus.Replay(Filter{}, time.Time{})))
+	assert.Equal(t, []string{"c", "d"}, ids(bus.Replay(Filter{Types: []string{"BlockEvent"}}, time.Time{})))
+	assert.Equal(t, []string{"d"}, ids(bus.Replay(Filter{}, now.Add(-5*time.Minute))))
+
+	// Со временем события устаревают и без новых публикаций
+	now = now.Add(55 * time.Minute)
+	assert.Equal(t, []string{"d"}, ids(bus.Replay(Filter{}, time.Time{})))
+
+	health := bus.Health()
+	assert.True(t, health.Connected)
+	assert.Equal(t, uint64(1), health.Messages)
+}
+
+func TestConnectOrFallback(t *testing.T) {
+	// На этом порту NATS заведомо нет
+	bus, err := ConnectOrFallback("nats://127.0.0.1:1", "", "EVENTS", time.Hour, false)
+	require.NoError(t, err)
+	assert.IsType(t, &LocalBus{}, bus)
+
+	_, err = ConnectOrFallback("nats://127.0.0.1:1", "", "EVENTS", time.Hour, true)
+	assert.Error(t, err)
+}
@@ -1,10 +1,13 @@
 package eventbus
 
 import (
+	"errors"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/annel0/mmo-game/internal/logging"
+	"github.com/annel0/mmo-game/internal/observability"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
@@ -19,6 +22,9 @@ type MetricsExporter struct {
 	bus  EventBus
 	quit chan struct{}
 	done chan struct{}
+	// Цикл обновления общий для /metrics и push и запускается один раз
+	loopOnce sync.Once
+	pusher   *observability.MetricsPusher
 	// Prometheus metrics
 	published prometheus.Counter
 	consumed  prometheus.Counter
@@ -54,11 +60,28 @@ func NewMetricsExporter(bus EventBus) *MetricsExporter {
 		}),
 	}
 
-	// Регистрируем метрики в глобальном регистре Prometheus.
-	prometheus.MustRegister(me.published, me.consumed, me.dropped, me.inflight)
+	// Регистрируем метрики в глобальном регистре Prometheus. Повторно
+	// созданный экспортер использует уже зарегистрированные метрики.
+	me.published = registerOnce(me.published).(prometheus.Counter)
+	me.consumed = registerOnce(me.consumed).(prometheus.Counter)
+	me.dropped = registerOnce(me.dropped).(prometheus.Counter)
+	me.inflight = registerOnce(me.inflight).(prometheus.Gauge)
 	return me
 }
 
+// registerOnce регистрирует коллектор или возвращает ранее зарегистрированный
+// с тем же описанием.
+func registerOnce(c prometheus.Collector) prometheus.Collector {
+	if err := prometheus.Register(c); err != nil {
+		var already prometheus.AlreadyRegisteredError
+		if errors.As(err, &already) {
+			return already.ExistingCollector
+		}
+		panic(err)
+	}
+	return c
+}
+
 // StartHTTP запускает HTTP-эндпоинт Prometheus на указанном адресе (например, ":2112").
 // Метод неблокирующий: HTTP-сервер стартует в отдельной горутине.
 func (m *MetricsExporter) StartHTTP(addr string) {
@@ -68,14 +91,32 @@ func (m *MetricsExporter) StartHTTP(addr string) {
 			logging.Error("Ошибка Prometheus HTTP сервера: %v", err)
 		}
 	}()
-	go m.loop()
+	m.startLoop()
 }
 
-// Stop останавливает обновление метрик. HTTP-сервер при этом не завершается
+// StartPush периодически отправляет метрики в OTLP-коллектор – для
+// короткоживущих узлов, которые Prometheus не может опросить. Может
+// работать вместе со StartHTTP: метрики и цикл обновления общие.
+func (m *MetricsExporter) StartPush(cfg observability.MetricsPushConfig) {
+	m.startLoop()
+	m.pusher = observability.NewMetricsPusher(prometheus.DefaultGatherer, cfg)
+	m.pusher.Start()
+}
+
+func (m *MetricsExporter) startLoop() {
+	m.loopOnce.Do(func() { go m.loop() })
+}
+
+// Stop останавливает обновление и отправку метрик (последний пакет
+// отправляется). HTTP-сервер при этом не завершается
 // (для упрощения – можно запустить на отдельном порте и убить процесс целиком).
 func (m *MetricsExporter) Stop() {
+	m.startLoop() // loop закрывает done, даже если экспортер не запускался
 	close(m.quit)
 	<-m.done
+	if m.pusher != nil {
+		m.pusher.Stop()
+	}
 }
 
 func (m *MetricsExporter) loop() {
@@ -0,0 +1,34 @@
+package eventbus
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/observability"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsExporter_ScrapeAndPushShareMetrics(t *testing.T) {
+	var pushes atomic.Int32
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pushes.Add(1)
+	}))
+	defer collector.Close()
+
+	bus := NewMemoryBus(16)
+
+	// Повторное создание экспортера не паникует из-за повторной регистрации
+	first := NewMetricsExporter(bus)
+	second := NewMetricsExporter(bus)
+	assert.Same(t, first.published, second.published)
+
+	second.StartHTTP("127.0.0.1:0")
+	second.StartPush(observability.MetricsPushConfig{Endpoint: collector.URL, Interval: time.Hour})
+	second.Stop()
+	first.Stop()
+
+	assert.Equal(t, int32(1), pushes.Load(), "при остановке отправляется последний пакет")
+}
@@ -0,0 +1,126 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// MetaNamespace - ключ Envelope.Metadata с пространством имён окружения,
+// в котором опубликовано событие (см. WithNamespace)
+const MetaNamespace = "namespace"
+
+// MaxNamespaceLength - максимальная длина пространства имён
+const MaxNamespaceLength = 32
+
+var (
+	// ErrInvalidNamespace - пространство имён нельзя использовать в subject и имени стрима NATS
+	ErrInvalidNamespace = errors.New("недопустимое пространство имён EventBus")
+	// ErrNamespaceMismatch - шина уже привязана к другому пространству имён
+	ErrNamespaceMismatch = errors.New("шина EventBus привязана к другому пространству имён")
+)
+
+// namespacePattern - токен subject NATS, допустимый и в имени стрима
+var namespacePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// ValidateNamespace проверяет пространство имён окружения (например,
+// "prod" или "staging-eu"). Пустое значение допустимо: события публикуются
+// без префикса, как до введения пространств имён
+func ValidateNamespace(namespace string) error {
+	if namespace == "" {
+		return nil
+	}
+	if len(namespace) > MaxNamespaceLength {
+		return fmt.Errorf("%w: %q длиннее %d символов", ErrInvalidNamespace, namespace, MaxNamespaceLength)
+	}
+	if !namespacePattern.MatchString(namespace) {
+		return fmt.Errorf("%w: %q (допустимы a-z, 0-9, '_' и '-', первый символ - буква или цифра)", ErrInvalidNamespace, namespace)
+	}
+	return nil
+}
+
+// SubjectPrefix возвращает префикс subject событий пространства имён:
+// "events" или "<namespace>.events"
+func SubjectPrefix(namespace string) string {
+	if namespace == "" {
+		return "events"
+	}
+	return namespace + ".events"
+}
+
+// StreamName возвращает имя стрима JetStream пространства имён:
+// stream или "<namespace>_<stream>"
+func StreamName(namespace, stream string) string {
+	if namespace == "" {
+		return stream
+	}
+	return namespace + "_" + stream
+}
+
+// Namespaced - шина, привязанная к пространству имён
+type Namespaced interface {
+	Namespace() string
+}
+
+// NamespaceOf возвращает пространство имён шины ("" - не привязана)
+func NamespaceOf(bus EventBus) string {
+	if n, ok := bus.(Namespaced); ok {
+		return n.Namespace()
+	}
+	return ""
+}
+
+// WithNamespace возвращает шину, изолированную в пространстве имён: события
+// помечаются MetaNamespace при публикации, а подписчики получают только
+// события своего пространства. Шина, уже привязанная к namespace,
+// возвращается как есть, к другому - ErrNamespaceMismatch. Пустой namespace
+// изоляцию не включает
+func WithNamespace(bus EventBus, namespace string) (EventBus, error) {
+	if err := ValidateNamespace(namespace); err != nil {
+		return nil, err
+	}
+	if namespace == "" {
+		return bus, nil
+	}
+	if current := NamespaceOf(bus); current != "" {
+		if current != namespace {
+			return nil, fmt.Errorf("%w: %q, требуется %q", ErrNamespaceMismatch, current, namespace)
+		}
+		return bus, nil
+	}
+	return &namespacedBus{EventBus: bus, namespace: namespace}, nil
+}
+
+// SetNamespace записывает пространство имён события в Metadata
+func (ev *Envelope) SetNamespace(namespace string) {
+	if namespace == "" {
+		return
+	}
+	if ev.Metadata == nil {
+		ev.Metadata = make(map[string]string, 1)
+	}
+	ev.Metadata[MetaNamespace] = namespace
+}
+
+// namespacedBus изолирует пространство имён поверх общей шины (например,
+// in-memory шины, разделяемой несколькими узлами)
+type namespacedBus struct {
+	EventBus
+	namespace string
+}
+
+func (nb *namespacedBus) Namespace() string { return nb.namespace }
+
+func (nb *namespacedBus) Publish(ctx context.Context, ev *Envelope) error {
+	ev.SetNamespace(nb.namespace)
+	return nb.EventBus.Publish(ctx, ev)
+}
+
+func (nb *namespacedBus) Subscribe(ctx context.Context, f Filter, h Handler) (Subscription, error) {
+	return nb.EventBus.Subscribe(ctx, f, func(ctx context.Context, ev *Envelope) {
+		if ev.Metadata[MetaNamespace] == nb.namespace {
+			h(ctx, ev)
+		}
+	})
+}
@@ -0,0 +1,85 @@
+package eventbus
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateNamespace(t *testing.T) {
+	for _, ns := range []string{"", "prod", "staging-eu", "env_2"} {
+		assert.NoError(t, ValidateNamespace(ns), ns)
+	}
+	for _, ns := range []string{"Prod", "staging.eu", "events.*", "a>b", "-prod", "with space", strings.Repeat("a", MaxNamespaceLength+1)} {
+		assert.ErrorIs(t, ValidateNamespace(ns), ErrInvalidNamespace, ns)
+	}
+}
+
+func TestNamespace_SubjectsAndStream(t *testing.T) {
+	assert.Equal(t, "events", SubjectPrefix(""))
+	assert.Equal(t, "EVENTS", StreamName("", "EVENTS"))
+
+	assert.Equal(t, "staging.events", SubjectPrefix("staging"))
+	assert.Equal(t, "staging_EVENTS", StreamName("staging", "EVENTS"))
+}
+
+func TestWithNamespace_IsolatesSharedBus(t *testing.T) {
+	shared := NewMemoryBus(100)
+	prod, err := WithNamespace(shared, "prod")
+	require.NoError(t, err)
+	staging, err := WithNamespace(shared, "staging")
+	require.NoError(t, err)
+
+	prodEvents := collect(t, prod, Filter{})
+	stagingEvents := collect(t, staging, Filter{})
+
+	require.NoError(t, staging.Publish(context.Background(), &Envelope{ID: "s1", EventType: "SyncBatch"}))
+	require.NoError(t, shared.Publish(context.Background(), &Envelope{ID: "plain", EventType: "SyncBatch"}))
+	require.NoError(t, prod.Publish(context.Background(), &Envelope{ID: "p1", EventType: "SyncBatch"}))
+
+	// Доставка упорядочена: после p1 остальные события уже обработаны
+	prodEvents(1)
+	time.Sleep(50 * time.Millisecond)
+	received := prodEvents(1)
+	require.Len(t, received, 1, "события staging и без пространства имён отброшены")
+	assert.Equal(t, "p1", received[0].ID)
+	assert.Equal(t, "prod", received[0].Metadata[MetaNamespace])
+
+	received = stagingEvents(1)
+	require.Len(t, received, 1)
+	assert.Equal(t, "s1", received[0].ID)
+}
+
+func TestWithNamespace_BoundBuses(t *testing.T) {
+	local := NewLocalBus(LocalBusConfig{Namespace: "prod"})
+
+	same, err := WithNamespace(local, "prod")
+	require.NoError(t, err)
+	assert.Same(t, local, same, "шина уже в нужном пространстве имён")
+
+	_, err = WithNamespace(local, "staging")
+	assert.ErrorIs(t, err, ErrNamespaceMismatch)
+
+	plain := NewMemoryBus(1)
+	unchanged, err := WithNamespace(plain, "")
+	require.NoError(t, err)
+	assert.Equal(t, plain, unchanged)
+
+	// LocalBus помечает события своим пространством имён
+	events := collect(t, local, Filter{})
+	require.NoError(t, local.Publish(context.Background(), &Envelope{EventType: "BlockEvent"}))
+	assert.Equal(t, "prod", events(1)[0].Metadata[MetaNamespace])
+}
+
+func TestConnectOrFallback_RejectsInvalidNamespace(t *testing.T) {
+	_, err := ConnectOrFallback("nats://127.0.0.1:1", "prod.eu", "EVENTS", time.Hour, false)
+	assert.ErrorIs(t, err, ErrInvalidNamespace, "неверное пространство имён не маскируется заменой на LocalBus")
+
+	bus, err := ConnectOrFallback("nats://127.0.0.1:1", "staging", "EVENTS", time.Hour, false)
+	require.NoError(t, err)
+	assert.Equal(t, "staging", NamespaceOf(bus))
+}
@@ -0,0 +1,122 @@
+package eventbus
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Subject события в JetStream: "<prefix>.<type>.<world>.<cell>". Мир и ячейка
+// области позволяют подписке с фильтром WorldIDs/Bounds получать от сервера
+// только свои события, а не отбрасывать чужие после доставки
+const (
+	// AreaCellSize - сторона ячейки области в subject, в блоках
+	AreaCellSize = 256
+
+	// noSubjectToken - токен мира или ячейки события без мира или координат
+	noSubjectToken = "_"
+
+	// maxSubjectFilters - предел числа subject у одной подписки. Если фильтр
+	// даёт больше, ячейки области заменяются "*" и отбираются после доставки
+	maxSubjectFilters = 256
+)
+
+// subjectToken приводит значение к токену subject NATS: символы, кроме
+// a-z, A-Z, 0-9, '_' и '-', заменяются '_'. Совпадения токенов разных значений
+// допустимы - после доставки событие всё равно проверяется matchFilter
+func subjectToken(value string) string {
+	if value == "" {
+		return noSubjectToken
+	}
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '-':
+			return r
+		}
+		return '_'
+	}, value)
+}
+
+// areaCell возвращает ячейку области, в которой лежит точка
+func areaCell(x, y int) (int, int) {
+	return floorDiv(x, AreaCellSize), floorDiv(y, AreaCellSize)
+}
+
+func floorDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
+
+func cellToken(cx, cy int) string {
+	return strconv.Itoa(cx) + "_" + strconv.Itoa(cy)
+}
+
+// EventSubject возвращает subject, в который публикуется событие
+func EventSubject(namespace string, ev *Envelope) string {
+	world := noSubjectToken
+	if id, ok := ev.Metadata[MetaWorldID]; ok {
+		world = subjectToken(id)
+	}
+	cell := noSubjectToken
+	if x, y, ok := ev.Position(); ok {
+		cell = cellToken(areaCell(x, y))
+	}
+	return SubjectPrefix(namespace) + "." + subjectToken(ev.EventType) + "." + world + "." + cell
+}
+
+// FilterSubjects возвращает subjects, которыми подписка отбирает события по
+// фильтру на стороне сервера. События без мира или координат проходят
+// фильтры WorldIDs и Bounds, поэтому их токен "_" входит в каждый набор.
+// Источник в subject не кодируется и проверяется после доставки
+func FilterSubjects(namespace string, f Filter) []string {
+	types := []string{"*"}
+	if len(f.Types) > 0 {
+		types = types[:0]
+		for _, t := range f.Types {
+			types = append(types, subjectToken(t))
+		}
+	}
+
+	worlds := []string{"*"}
+	if len(f.WorldIDs) > 0 {
+		worlds = []string{noSubjectToken}
+		for _, id := range f.WorldIDs {
+			worlds = append(worlds, subjectToken(id))
+		}
+	}
+
+	cells := []string{"*"}
+	if f.Bounds != nil && f.Bounds.MinX <= f.Bounds.MaxX && f.Bounds.MinY <= f.Bounds.MaxY {
+		minX, minY := areaCell(f.Bounds.MinX, f.Bounds.MinY)
+		maxX, maxY := areaCell(f.Bounds.MaxX, f.Bounds.MaxY)
+		width, height := maxX-minX+1, maxY-minY+1
+		if width <= maxSubjectFilters && height <= maxSubjectFilters &&
+			(width*height+1)*len(types)*len(worlds) <= maxSubjectFilters {
+			cells = []string{noSubjectToken}
+			for cx := minX; cx <= maxX; cx++ {
+				for cy := minY; cy <= maxY; cy++ {
+					cells = append(cells, cellToken(cx, cy))
+				}
+			}
+		}
+	}
+
+	prefix := SubjectPrefix(namespace)
+	seen := make(map[string]struct{})
+	subjects := make([]string, 0, len(types)*len(worlds)*len(cells))
+	for _, t := range types {
+		for _, w := range worlds {
+			for _, c := range cells {
+				subject := prefix + "." + t + "." + w + "." + c
+				if _, dup := seen[subject]; dup {
+					continue
+				}
+				seen[subject] = struct{}{}
+				subjects = append(subjects, subject)
+			}
+		}
+	}
+	return subjects
+}
@@ -0,0 +1,73 @@
+package eventbus
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// subjectMatches проверяет subject по шаблону NATS с "*" в токенах
+func subjectMatches(pattern, subject string) bool {
+	p, s := strings.Split(pattern, "."), strings.Split(subject, ".")
+	if len(p) != len(s) {
+		return false
+	}
+	for i := range p {
+		if p[i] != "*" && p[i] != s[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func anyMatches(patterns []string, subject string) bool {
+	for _, p := range patterns {
+		if subjectMatches(p, subject) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestEventSubject_EncodesWorldAndCell(t *testing.T) {
+	ev := &Envelope{EventType: "BlockEvent"}
+	assert.Equal(t, "events.BlockEvent._._", EventSubject("", ev))
+
+	ev.SetPosition("main", 10, -1)
+	assert.Equal(t, "prod.events.BlockEvent.main.0_-1", EventSubject("prod", ev))
+
+	ev.SetPosition("world.2 *", AreaCellSize, -AreaCellSize-1)
+	assert.Equal(t, "events.BlockEvent.world_2__.1_-2", EventSubject("", ev))
+}
+
+func TestFilterSubjects_SelectWorldAndArea(t *testing.T) {
+	filter := Filter{
+		Types:    []string{"BlockEvent"},
+		WorldIDs: []string{"main"},
+		Bounds:   &Bounds{MinX: -10, MinY: 0, MaxX: 300, MaxY: 100},
+	}
+	subjects := FilterSubjects("", filter)
+	assert.Len(t, subjects, 8, "миры main и '_' на ячейки -1_0, 0_0, 1_0 и '_'")
+
+	event := func(worldID string, x, y int) string {
+		ev := &Envelope{EventType: "BlockEvent"}
+		ev.SetPosition(worldID, x, y)
+		return EventSubject("", ev)
+	}
+	assert.True(t, anyMatches(subjects, event("main", 0, 0)))
+	assert.True(t, anyMatches(subjects, event("main", -10, 100)))
+	assert.True(t, anyMatches(subjects, event("", 300, 0)), "событие без мира проходит фильтр мира")
+	assert.True(t, anyMatches(subjects, EventSubject("", &Envelope{EventType: "BlockEvent"})))
+
+	assert.False(t, anyMatches(subjects, event("other", 0, 0)), "событие другого мира отсекается сервером")
+	assert.False(t, anyMatches(subjects, event("main", 1000, 0)), "событие другой области отсекается сервером")
+	assert.False(t, anyMatches(subjects, EventSubject("", &Envelope{EventType: "ChatEvent"})))
+}
+
+func TestFilterSubjects_WideAreaFallsBackToWildcard(t *testing.T) {
+	assert.Equal(t, []string{"events.*.*.*"}, FilterSubjects("", Filter{}))
+
+	subjects := FilterSubjects("", Filter{Bounds: &Bounds{MinX: -100000, MinY: -100000, MaxX: 100000, MaxY: 100000}})
+	assert.Equal(t, []string{"events.*.*.*"}, subjects, "слишком много ячеек - область отбирается после доставки")
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"errors"
 	"strconv"
 	"time"
 
@@ -48,10 +49,28 @@ func NewPrometheusMiddleware(service string) *PrometheusMiddleware {
 		}, []string{"method", "path", "status"}),
 	}
 
-	prometheus.MustRegister(pm.reqDuration, pm.reqInflight, pm.reqErrors)
+	pm.reqDuration = registerOrExisting(pm.reqDuration)
+	pm.reqInflight = registerOrExisting(pm.reqInflight)
+	pm.reqErrors = registerOrExisting(pm.reqErrors)
 	return pm
 }
 
+// registerOrExisting регистрирует коллектор, а если такой уже зарегистрирован
+// (повторное создание сервера в том же процессе), возвращает существующий
+func registerOrExisting[T prometheus.Collector](collector T) T {
+	err := prometheus.Register(collector)
+	if err == nil {
+		return collector
+	}
+	var registered prometheus.AlreadyRegisteredError
+	if errors.As(err, &registered) {
+		if existing, ok := registered.ExistingCollector.(T); ok {
+			return existing
+		}
+	}
+	panic(err)
+}
+
 // Handler возвращает gin.HandlerFunc, которую нужно добавить через router.Use().
 func (pm *PrometheusMiddleware) Handler() gin.HandlerFunc {
 	return func(c *gin.Context) {
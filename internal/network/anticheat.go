@@ -0,0 +1,45 @@
+package network
+
+import (
+	"github.com/annel0/mmo-game/internal/anticheat"
+	"github.com/annel0/mmo-game/internal/vec"
+)
+
+// SetAnticheat подключает движок античита (nil отключает проверки).
+// Предел правок блоков, заданный SetRuntimeParams, переносится в новый движок
+func (gh *GameHandlerPB) SetAnticheat(engine *anticheat.Engine) {
+	gh.mu.Lock()
+	gh.anticheat = engine
+	gh.applyBlockEditLimitLocked()
+	gh.mu.Unlock()
+}
+
+// checkAnticheat передаёт действие игрока движку античита и возвращает нарушения
+func (gh *GameHandlerPB) checkAnticheat(connID string, action anticheat.Action) []anticheat.Violation {
+	gh.mu.RLock()
+	engine := gh.anticheat
+	if session, ok := gh.sessions[connID]; ok {
+		action.Username = session.Username
+	}
+	gh.mu.RUnlock()
+
+	if engine == nil {
+		return nil
+	}
+	action.Time = gh.now()
+	return engine.Evaluate(action)
+}
+
+// resetAnticheatLocked задаёт исходную позицию игрока после появления в мире. Вызывается под gh.mu.
+func (gh *GameHandlerPB) resetAnticheatLocked(entityID uint64, pos vec.Vec2) {
+	if gh.anticheat != nil {
+		gh.anticheat.ResetPosition(entityID, vec.FromVec2(pos))
+	}
+}
+
+// forgetAnticheatLocked удаляет историю игрока при отключении. Вызывается под gh.mu.
+func (gh *GameHandlerPB) forgetAnticheatLocked(entityID uint64) {
+	if gh.anticheat != nil {
+		gh.anticheat.Forget(entityID)
+	}
+}
@@ -0,0 +1,121 @@
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/eventbus"
+	"github.com/annel0/mmo-game/internal/logging"
+	"github.com/annel0/mmo-game/internal/protocol/events"
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/annel0/mmo-game/internal/world"
+	"github.com/google/uuid"
+)
+
+// EventTypeBlockAudit - тип события EventBus для изменений блоков игроками.
+// Совпадает с events.EventTypeBlock, поэтому журнал воспроизведения
+// (event-cli, world-replay) читает эти события как обычные события блоков
+const EventTypeBlockAudit = string(events.EventTypeBlock)
+
+// Действия в событии аудита блока (поле action, как ожидает world-replay)
+const (
+	blockAuditPlaced = "placed" // Блок установлен
+	blockAuditBroken = "broken" // Блок разрушен
+)
+
+// blockAuditRecord - полезная нагрузка события аудита блока. Поля x, y, z,
+// block_id, action и world_id совпадают с событиями блоков журнала воспроизведения
+type blockAuditRecord struct {
+	PlayerID   uint64                 `json:"player_id"`
+	Username   string                 `json:"username,omitempty"`
+	EntityID   uint64                 `json:"entity_id"`
+	X          int                    `json:"x"`
+	Y          int                    `json:"y"`
+	Layer      int                    `json:"z"`
+	OldBlockID uint16                 `json:"old_block_id"`
+	BlockID    uint16                 `json:"block_id"` // Новый ID блока
+	Action     string                 `json:"action"`
+	Metadata   map[string]interface{} `json:"metadata,omitempty"` // Метаданные нового блока
+	WorldID    string                 `json:"world_id,omitempty"`
+	Region     string                 `json:"region,omitempty"`
+	Timestamp  time.Time              `json:"timestamp"`
+}
+
+// blockAuditAction переводит действие запроса в действие события аудита
+func blockAuditAction(action string) string {
+	switch action {
+	case "place":
+		return blockAuditPlaced
+	case "mine", "break":
+		return blockAuditBroken
+	default:
+		return action
+	}
+}
+
+// publishBlockAudit записывает в EventBus изменение блока игроком: кто, где и
+// что было до и после. Вызывается один раз на применённое изменение - из
+// BLOCK_UPDATE и действий строительства (build_place, build_break);
+// WorldManager для правок игроков событий не публикует.
+// Запрос, не изменивший блок (удар по прочному блоку), не записывается
+func (gh *GameHandlerPB) publishBlockAudit(connID string, entityID uint64, pos vec.Vec2, layer world.BlockLayer, action string, before, after world.Block) {
+	if before.ID == after.ID && samePayload(before.Payload, after.Payload) {
+		return
+	}
+
+	gh.mu.RLock()
+	session := gh.sessions[connID]
+	route, _ := gh.regions.owner(pos)
+	gh.mu.RUnlock()
+
+	record := blockAuditRecord{
+		EntityID:   entityID,
+		X:          pos.X,
+		Y:          pos.Y,
+		Layer:      int(layer),
+		OldBlockID: uint16(before.ID),
+		BlockID:    uint16(after.ID),
+		Action:     blockAuditAction(action),
+		Metadata:   after.Payload,
+		WorldID:    gh.worldManager.WorldID(),
+		Region:     route.ID,
+		Timestamp:  time.Now().UTC(),
+	}
+	if session != nil {
+		record.PlayerID = session.UserID
+		record.Username = session.Username
+	}
+
+	payload, err := json.Marshal(record)
+	if err != nil {
+		logging.Warn("Аудит блока %v не записан: %v", pos, err)
+		return
+	}
+	envelope := &eventbus.Envelope{
+		ID:        uuid.NewString(),
+		Timestamp: record.Timestamp,
+		Source:    "game_handler",
+		EventType: EventTypeBlockAudit,
+		Version:   1,
+		Priority:  5,
+		Payload:   payload,
+		Metadata:  map[string]string{"action": record.Action},
+	}
+	if record.Region != "" {
+		envelope.Metadata["region"] = record.Region
+	}
+	envelope.SetPosition(record.WorldID, pos.X, pos.Y)
+	if err := gh.publish(context.Background(), envelope); err != nil {
+		logging.Warn("Аудит блока %v не записан в EventBus: %v", pos, err)
+	}
+}
+
+// samePayload сравнивает метаданные блоков; nil и пустые метаданные равны
+func samePayload(a, b map[string]interface{}) bool {
+	if len(a) == 0 && len(b) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
@@ -0,0 +1,97 @@
+package network
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/annel0/mmo-game/internal/eventbus"
+	"github.com/annel0/mmo-game/internal/protocol"
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/annel0/mmo-game/internal/world"
+	"github.com/annel0/mmo-game/internal/world/block"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blockAudits возвращает события аудита блоков из журнала публикаций
+func blockAudits(t *testing.T, published []*eventbus.Envelope) []blockAuditRecord {
+	t.Helper()
+
+	var records []blockAuditRecord
+	for _, ev := range published {
+		if ev.EventType != EventTypeBlockAudit {
+			continue
+		}
+		var record blockAuditRecord
+		require.NoError(t, json.Unmarshal(ev.Payload, &record))
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestBlockAudit_BreakPublishesOldAndNewIDs(t *testing.T) {
+	gh := newTestGameHandler(t)
+	published := recordPublished(gh)
+	registerTestBlock(t, block.DoorBlockID, "door")
+	require.NoError(t, gh.SetRegionRouting("eu-1", nil))
+	client := connectTestClient(t, gh, "conn-1")
+	addTestSession(gh, "conn-1", 7, 70, vec.Vec2{X: 0, Y: 0})
+
+	pos := vec.Vec2{X: 1, Y: 2}
+	gh.worldManager.SetBlockLayer(pos, world.LayerActive, world.NewBlock(block.DoorBlockID))
+
+	resp := breakBlock(t, gh, client, "conn-1", pos)
+	require.True(t, resp.Success, resp.Message)
+
+	log := published()
+	records := blockAudits(t, log)
+	require.Len(t, records, 1, "одно изменение - одно событие аудита")
+	record := records[0]
+	assert.Equal(t, uint16(block.DoorBlockID), record.OldBlockID)
+	assert.Equal(t, uint16(block.AirBlockID), record.BlockID)
+	assert.Equal(t, blockAuditBroken, record.Action)
+	assert.Equal(t, uint64(7), record.PlayerID)
+	assert.Equal(t, "conn-1", record.Username)
+	assert.Equal(t, uint64(70), record.EntityID)
+	assert.Equal(t, []int{1, 2, int(world.LayerActive)}, []int{record.X, record.Y, record.Layer})
+	assert.Equal(t, "eu-1", record.Region)
+	assert.False(t, record.Timestamp.IsZero())
+
+	x, y, ok := log[0].Position()
+	require.True(t, ok)
+	assert.Equal(t, []int{1, 2}, []int{x, y})
+	assert.Equal(t, "eu-1", log[0].Metadata["region"])
+
+	// Повторный запрос по воздуху блок не меняет и не записывается
+	breakBlock(t, gh, client, "conn-1", pos)
+	assert.Len(t, blockAudits(t, published()), 1)
+}
+
+func TestBlockAudit_BuildActionsArePublished(t *testing.T) {
+	gh := newTestGameHandler(t)
+	published := recordPublished(gh)
+	registerTestBlock(t, block.StoneBlockID, "stone")
+	connectTestClient(t, gh, "conn-1")
+	addTestSession(gh, "conn-1", 7, 70, vec.Vec2{X: 0, Y: 0})
+	actor := playerEntityFor(t, gh, "conn-1")
+
+	pos := vec.Vec2{X: 1, Y: 2}
+	gh.worldManager.SetBlockLayer(pos, world.LayerActive, world.NewBlock(block.AirBlockID))
+	target := &protocol.EntityActionRequest{Position: &protocol.Vec2{X: int32(pos.X), Y: int32(pos.Y)}}
+
+	ok, message, _ := gh.handleBuildPlaceAction(actor, target)
+	require.True(t, ok, message)
+	ok, message, _ = gh.handleBuildBreakAction(actor, target)
+	require.True(t, ok, message)
+
+	records := blockAudits(t, published())
+	require.Len(t, records, 2)
+	assert.Equal(t, blockAuditPlaced, records[0].Action)
+	assert.Equal(t, uint16(block.AirBlockID), records[0].OldBlockID)
+	assert.Equal(t, uint16(block.StoneBlockID), records[0].BlockID)
+	assert.Equal(t, blockAuditBroken, records[1].Action)
+	assert.Equal(t, uint16(block.StoneBlockID), records[1].OldBlockID)
+	assert.Equal(t, uint16(block.AirBlockID), records[1].BlockID)
+	assert.Equal(t, uint64(7), records[1].PlayerID)
+	assert.Equal(t, uint64(70), records[1].EntityID)
+}
@@ -0,0 +1,134 @@
+package network
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/protocol"
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/annel0/mmo-game/internal/world"
+)
+
+// DefaultBlockUpdateWindow - окно, в течение которого изменения блоков
+// накапливаются перед рассылкой (один тик при 20 TPS)
+const DefaultBlockUpdateWindow = 50 * time.Millisecond
+
+// blockUpdateBuffer накапливает изменения блоков, чтобы массовые правки
+// (BatchUpdate, взрывы, жидкости) уходили одним BLOCK_UPDATE на чанк
+type blockUpdateBuffer struct {
+	mu      sync.Mutex
+	window  time.Duration                    // <= 0 - рассылать сразу
+	pending map[vec.Vec2]*protocol.BlockData // Позиция -> последнее состояние блока
+	timer   *time.Timer                      // Запланированная рассылка (nil - буфер пуст)
+}
+
+// SetBlockUpdateWindow задаёт окно накопления изменений блоков.
+// 0 - DefaultBlockUpdateWindow, отрицательное значение - рассылать каждое изменение сразу
+func (gh *GameHandlerPB) SetBlockUpdateWindow(window time.Duration) {
+	if window == 0 {
+		window = DefaultBlockUpdateWindow
+	}
+
+	gh.blockUpdates.mu.Lock()
+	gh.blockUpdates.window = window
+	gh.blockUpdates.mu.Unlock()
+
+	if window < 0 {
+		gh.flushBlockUpdates()
+	}
+}
+
+// SendBlockUpdate ставит изменение блока в очередь рассылки. Блок, изменённый
+// несколько раз за окно, рассылается один раз в последнем состоянии
+func (gh *GameHandlerPB) SendBlockUpdate(blockPos vec.Vec2, block world.Block) {
+	data := blockDataFor(blockPos, block)
+
+	buf := &gh.blockUpdates
+	buf.mu.Lock()
+	if buf.window <= 0 {
+		buf.mu.Unlock()
+		data.Version = gh.worldManager.GetBlockVersion(blockPos, world.LayerActive)
+		gh.broadcastMessage(protocol.MessageType_BLOCK_UPDATE, &protocol.BlockUpdateMessage{
+			Blocks: []*protocol.BlockData{data},
+		})
+		return
+	}
+
+	if buf.pending == nil {
+		buf.pending = make(map[vec.Vec2]*protocol.BlockData)
+	}
+	buf.pending[blockPos] = data
+	if buf.timer == nil {
+		buf.timer = time.AfterFunc(buf.window, gh.flushBlockUpdates)
+	}
+	buf.mu.Unlock()
+}
+
+// flushBlockUpdates рассылает накопленные изменения: одно сообщение на чанк
+func (gh *GameHandlerPB) flushBlockUpdates() {
+	buf := &gh.blockUpdates
+	buf.mu.Lock()
+	pending := buf.pending
+	buf.pending = nil
+	if buf.timer != nil {
+		buf.timer.Stop()
+		buf.timer = nil
+	}
+	buf.mu.Unlock()
+
+	if len(pending) == 0 {
+		return
+	}
+
+	// Версия читается при рассылке, когда изменение обычно уже применено в
+	// BigChunk. Если ещё нет, версия окажется старше, и правка клиента по ней
+	// будет отклонена (CONFLICT), а не перезапишет изменение
+	byChunk := make(map[vec.Vec2][]*protocol.BlockData)
+	for pos, data := range pending {
+		data.Version = gh.worldManager.GetBlockVersion(pos, world.LayerActive)
+		chunkPos := pos.ToChunkCoords()
+		byChunk[chunkPos] = append(byChunk[chunkPos], data)
+	}
+
+	chunks := make([]vec.Vec2, 0, len(byChunk))
+	for chunkPos := range byChunk {
+		chunks = append(chunks, chunkPos)
+	}
+	sort.Slice(chunks, func(i, j int) bool { return lessVec2(chunks[i], chunks[j]) })
+
+	for _, chunkPos := range chunks {
+		blocks := byChunk[chunkPos]
+		sort.Slice(blocks, func(i, j int) bool {
+			a, b := blocks[i].Position, blocks[j].Position
+			return a.Y < b.Y || (a.Y == b.Y && a.X < b.X)
+		})
+		gh.broadcastMessage(protocol.MessageType_BLOCK_UPDATE, &protocol.BlockUpdateMessage{Blocks: blocks})
+	}
+}
+
+// blockDataFor преобразует блок мира в BlockData протокола
+func blockDataFor(blockPos vec.Vec2, block world.Block) *protocol.BlockData {
+	blockData := &protocol.BlockData{
+		Position: &protocol.Vec2{
+			X: int32(blockPos.X),
+			Y: int32(blockPos.Y),
+		},
+		BlockId: uint32(block.ID),
+	}
+
+	// Добавляем метаданные, если они есть
+	if len(block.Payload) > 0 {
+		jsonStr, err := protocol.MapToJsonMetadata(block.Payload)
+		if err == nil {
+			blockData.Metadata = &protocol.JsonMetadata{
+				JsonData: jsonStr,
+			}
+		}
+	}
+	return blockData
+}
+
+func lessVec2(a, b vec.Vec2) bool {
+	return a.Y < b.Y || (a.Y == b.Y && a.X < b.X)
+}
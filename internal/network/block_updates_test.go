@@ -0,0 +1,64 @@
+package network
+
+import (
+	"testing"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/protocol"
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/annel0/mmo-game/internal/world"
+	"github.com/annel0/mmo-game/internal/world/block"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockUpdates_RapidEditsFlushAsOneMessage(t *testing.T) {
+	gh := newTestGameHandler(t)
+	client := connectTestClient(t, gh, "conn-1")
+	gh.SetBlockUpdateWindow(20 * time.Millisecond)
+
+	// 100 правок в пределах одного чанка
+	for x := 0; x < 10; x++ {
+		for y := 0; y < 10; y++ {
+			gh.SendBlockUpdate(vec.Vec2{X: x, Y: y}, world.Block{ID: 3})
+		}
+	}
+
+	update := &protocol.BlockUpdateMessage{}
+	client.expect(t, protocol.MessageType_BLOCK_UPDATE, update)
+	assert.Len(t, update.Blocks, 100)
+	assert.Zero(t, client.drain(protocol.MessageType_BLOCK_UPDATE), "все правки ушли одним сообщением")
+}
+
+func TestBlockUpdates_RepeatedEditSendsFinalState(t *testing.T) {
+	gh := newTestGameHandler(t)
+	client := connectTestClient(t, gh, "conn-1")
+	gh.SetBlockUpdateWindow(20 * time.Millisecond)
+
+	pos := vec.Vec2{X: 5, Y: 7}
+	for id := block.BlockID(1); id <= 5; id++ {
+		gh.SendBlockUpdate(pos, world.Block{ID: id})
+	}
+	// Блок в соседнем чанке уходит отдельным сообщением
+	gh.SendBlockUpdate(vec.Vec2{X: 40, Y: 7}, world.Block{ID: 2})
+
+	first := &protocol.BlockUpdateMessage{}
+	client.expect(t, protocol.MessageType_BLOCK_UPDATE, first)
+	require.Len(t, first.Blocks, 1)
+	assert.EqualValues(t, 5, first.Blocks[0].BlockId, "рассылается последнее состояние")
+
+	second := &protocol.BlockUpdateMessage{}
+	client.expect(t, protocol.MessageType_BLOCK_UPDATE, second)
+	require.Len(t, second.Blocks, 1)
+	assert.EqualValues(t, 40, second.Blocks[0].Position.X)
+}
+
+func TestBlockUpdates_DisabledWindowSendsImmediately(t *testing.T) {
+	gh := newTestGameHandler(t)
+	client := connectTestClient(t, gh, "conn-1")
+	gh.SetBlockUpdateWindow(-1)
+
+	gh.SendBlockUpdate(vec.Vec2{X: 1, Y: 1}, world.Block{ID: 3})
+	gh.SendBlockUpdate(vec.Vec2{X: 2, Y: 1}, world.Block{ID: 3})
+	assert.Equal(t, 2, client.drain(protocol.MessageType_BLOCK_UPDATE))
+}
@@ -0,0 +1,101 @@
+package network
+
+import (
+	"testing"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/protocol"
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/annel0/mmo-game/internal/world"
+	"github.com/annel0/mmo-game/internal/world/block"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockUpdate_StaleBaseVersionRejected(t *testing.T) {
+	gh := newTestGameHandler(t)
+	alice := connectTestClient(t, gh, "conn-1")
+	bob := connectTestClient(t, gh, "conn-2")
+	addTestSession(gh, "conn-1", 1, 1, vec.Vec2{X: 0, Y: 0})
+	addTestSession(gh, "conn-2", 2, 2, vec.Vec2{X: 1, Y: 0})
+	registerTestBlock(t, block.StoneBlockID, "stone")
+	registerTestBlock(t, block.DoorBlockID, "door")
+
+	target := vec.Vec2{X: 2, Y: 2}
+	base := gh.worldManager.GetBlockVersion(target, world.LayerActive)
+	place := func(connID string, id block.BlockID, baseVersion uint64) {
+		gh.HandleMessage(connID, newGameMessage(t, protocol.MessageType_BLOCK_UPDATE, &protocol.BlockUpdateRequest{
+			Position:    &protocol.Vec2{X: int32(target.X), Y: int32(target.Y)},
+			BlockId:     uint32(id),
+			Layer:       protocol.BlockLayer_ACTIVE,
+			Action:      "place",
+			BaseVersion: baseVersion,
+		}))
+	}
+
+	// Оба игрока видят блок в одной версии; первая правка принимается
+	place("conn-1", block.StoneBlockID, base)
+	applied := &protocol.BlockUpdateResponseMessage{}
+	alice.expect(t, protocol.MessageType_BLOCK_UPDATE_RESPONSE, applied)
+	require.True(t, applied.Success, applied.Message)
+	assert.Greater(t, applied.Version, base)
+	assert.Equal(t, applied.Version, gh.worldManager.GetBlockVersion(target, world.LayerActive))
+
+	// Вторая правка по той же версии отклоняется с текущим состоянием блока
+	place("conn-2", block.DoorBlockID, base)
+	stale := &protocol.BlockUpdateResponseMessage{}
+	bob.expect(t, protocol.MessageType_BLOCK_UPDATE_RESPONSE, stale)
+	assert.False(t, stale.Success)
+	assert.Equal(t, applied.Version, stale.Version)
+	assert.Equal(t, uint32(block.StoneBlockID), stale.BlockId)
+	errMsg := &protocol.ErrorMessage{}
+	bob.expect(t, protocol.MessageType_ERROR, errMsg)
+	assert.Equal(t, protocol.ErrorCode_CONFLICT, errMsg.Code)
+	assert.Equal(t, protocol.MessageType_BLOCK_UPDATE, errMsg.RefType)
+	assert.Equal(t, block.StoneBlockID, gh.worldManager.GetBlockLayer(target, world.LayerActive).ID, "правка не должна перезаписать блок")
+
+	// Повтор по актуальной версии принимается
+	place("conn-2", block.DoorBlockID, stale.Version)
+	retried := &protocol.BlockUpdateResponseMessage{}
+	bob.expect(t, protocol.MessageType_BLOCK_UPDATE_RESPONSE, retried)
+	require.True(t, retried.Success, retried.Message)
+	assert.Greater(t, retried.Version, stale.Version)
+	assert.Equal(t, block.DoorBlockID, gh.worldManager.GetBlockLayer(target, world.LayerActive).ID)
+}
+
+func TestBlockUpdate_ChunkVersionAsBase(t *testing.T) {
+	gh := newTestGameHandler(t)
+	client := connectTestClient(t, gh, "conn-1")
+	addTestSession(gh, "conn-1", 1, 1, vec.Vec2{X: 0, Y: 0})
+	registerTestBlock(t, block.StoneBlockID, "stone")
+
+	// Клиент основывает правку на версии полученного чанка
+	target := vec.Vec2{X: 3, Y: 1}
+	chunkVersion := gh.worldManager.GetChunk(target.ToChunkCoords()).Version
+	gh.HandleMessage("conn-1", newGameMessage(t, protocol.MessageType_BLOCK_UPDATE, &protocol.BlockUpdateRequest{
+		Position:    &protocol.Vec2{X: int32(target.X), Y: int32(target.Y)},
+		BlockId:     uint32(block.StoneBlockID),
+		Layer:       protocol.BlockLayer_ACTIVE,
+		Action:      "place",
+		BaseVersion: chunkVersion,
+	}))
+
+	resp := &protocol.BlockUpdateResponseMessage{}
+	client.expect(t, protocol.MessageType_BLOCK_UPDATE_RESPONSE, resp)
+	assert.True(t, resp.Success, resp.Message)
+}
+
+func TestBlockUpdates_BroadcastCarriesBlockVersion(t *testing.T) {
+	gh := newTestGameHandler(t)
+	client := connectTestClient(t, gh, "conn-1")
+	gh.SetBlockUpdateWindow(20 * time.Millisecond)
+
+	pos := vec.Vec2{X: 4, Y: 4}
+	gh.worldManager.SetBlock(pos, world.NewBlock(block.StoneBlockID))
+	gh.SendBlockUpdate(pos, world.NewBlock(block.StoneBlockID))
+
+	update := &protocol.BlockUpdateMessage{}
+	client.expect(t, protocol.MessageType_BLOCK_UPDATE, update)
+	require.Len(t, update.Blocks, 1)
+	assert.Equal(t, gh.worldManager.GetBlockVersion(pos, world.LayerActive), update.Blocks[0].Version)
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/annel0/mmo-game/internal/logging"
 	"github.com/annel0/mmo-game/internal/protocol"
 	"github.com/xtaci/kcp-go/v5"
+	"google.golang.org/protobuf/proto"
 )
 
 // ChannelServer представляет сервер каналов
@@ -22,6 +23,7 @@ type ChannelServer struct {
 	// Клиенты
 	clients   map[string]*ClientChannel
 	clientsMu sync.RWMutex
+	limits    connectionLimiter
 
 	// Обработчики
 	onConnect    func(clientID string, channel NetChannel)
@@ -62,6 +64,7 @@ func NewChannelServer(addr string, config *ChannelConfig) *ChannelServer {
 		addr:      addr,
 		config:    config,
 		clients:   make(map[string]*ClientChannel),
+		limits:    connectionLimiter{transport: "kcp"},
 		converter: converter,
 		logger:    logger,
 	}
@@ -170,6 +173,14 @@ func (cs *ChannelServer) handleConnection(conn net.Conn) {
 	logger := logging.GetNetworkLogger()
 	channel := NewKCPChannelFromConn(kcpConn, cs.config, logger)
 
+	// Проверяем лимит одновременных подключений
+	if !cs.limits.acquire() {
+		cs.logger.Warn("🚫 Подключение %s отклонено: достигнут лимит %d", conn.RemoteAddr(), cs.limits.limit())
+		cs.limits.reject(ServerMessageServerFull)
+		cs.rejectChannel(channel, ServerMessageServerFull)
+		return
+	}
+
 	// Генерируем ID клиента
 	clientID := fmt.Sprintf("client-%s-%d", conn.RemoteAddr(), time.Now().UnixNano())
 
@@ -284,6 +295,7 @@ func (cs *ChannelServer) disconnectClient(clientID string) {
 	}
 	delete(cs.clients, clientID)
 	cs.clientsMu.Unlock()
+	cs.limits.release()
 
 	// Закрываем канал
 	client.Channel.Close()
@@ -353,6 +365,44 @@ func (cs *ChannelServer) Broadcast(msg *protocol.GameMessage, flags ChannelFlags
 	wg.Wait()
 }
 
+// rejectChannel отправляет отклонённому клиенту SERVER_MESSAGE с причиной
+// и закрывает канал. Доставка не гарантируется
+func (cs *ChannelServer) rejectChannel(channel NetChannel, reason string) {
+	defer channel.Close()
+
+	payload, err := proto.Marshal(&protocol.ServerMessage{
+		Code:      reason,
+		Message:   rejectionText(reason),
+		Timestamp: time.Now().UnixNano(),
+	})
+	if err != nil {
+		return
+	}
+	msg := &protocol.GameMessage{Type: protocol.MessageType_SERVER_MESSAGE, Payload: payload}
+	netMsg, err := cs.converter.GameToNet(msg)
+	if err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), rejectWriteTimeout)
+	defer cancel()
+	if channel.Send(ctx, netMsg, cs.converter.GetSendOptions(msg)) == nil {
+		// Даём каналу отправить сообщение до закрытия соединения
+		time.Sleep(rejectFlushDelay)
+	}
+}
+
+// SetMaxConnections задаёт лимит одновременных подключений
+// (0 - DefaultMaxConnections, отрицательное значение - без ограничения)
+func (cs *ChannelServer) SetMaxConnections(limit int) {
+	cs.limits.setMax(limit)
+}
+
+// ConnectionLimitStats возвращает счётчики подключений и отказов
+func (cs *ChannelServer) ConnectionLimitStats() ConnectionLimitStats {
+	return cs.limits.stats()
+}
+
 // GetClientCount возвращает количество подключенных клиентов
 func (cs *ChannelServer) GetClientCount() int {
 	cs.clientsMu.RLock()
@@ -0,0 +1,59 @@
+package network
+
+import (
+	"errors"
+	"hash/crc32"
+	"log"
+
+	"github.com/annel0/mmo-game/internal/protocol"
+	"github.com/annel0/mmo-game/internal/world"
+)
+
+// ChunkDeltaChangeType - тип изменения в ChunkBlockDelta, построенной по
+// версии клиента: блок передаётся в текущем состоянии
+const ChunkDeltaChangeType = "set"
+
+// deliverChunkDelta отправляет клиенту изменения чанка после его версии.
+// Возвращает false, если дельта недоступна и нужен весь чанк
+func (gh *GameHandlerPB) deliverChunkDelta(job chunkJob) bool {
+	diff, err := gh.worldManager.ChunkDiffSince(job.pos, job.knownVersion)
+	if err != nil {
+		if !errors.Is(err, world.ErrChunkDiffUnavailable) {
+			log.Printf("Ошибка построения дельты чанка %v для %s: %v", job.pos, job.connID, err)
+		}
+		return false
+	}
+
+	gh.sendTCPMessage(job.connID, protocol.MessageType_CHUNK_BLOCK_DELTA, encodeChunkDelta(diff))
+	gh.rememberChunk(job.connID, job.pos, diff.Hash)
+	return true
+}
+
+// encodeChunkDelta преобразует дельту чанка в ChunkBlockDelta (формат
+// examples/delta-demo). delta_version - версия чанка после применения,
+// crc32 - CRC32 канонического хэша этой версии: клиент сверяет его с хэшем
+// своего чанка после применения и при расхождении запрашивает чанк целиком
+func encodeChunkDelta(diff *world.ChunkDiff) *protocol.ChunkBlockDelta {
+	delta := &protocol.ChunkBlockDelta{
+		ChunkCoords:  &protocol.Vec2{X: int32(diff.Coords.X), Y: int32(diff.Coords.Y)},
+		BlockChanges: make([]*protocol.BlockChange, 0, len(diff.Changes)),
+		DeltaVersion: diff.Version,
+		Crc32:        crc32.ChecksumIEEE(diff.Hash[:]),
+	}
+
+	for _, change := range diff.Changes {
+		blockChange := &protocol.BlockChange{
+			LocalPos:   &protocol.Vec2{X: int32(change.Pos.X), Y: int32(change.Pos.Y)},
+			Layer:      uint32(change.Layer),
+			BlockId:    uint32(change.BlockID),
+			ChangeType: ChunkDeltaChangeType,
+		}
+		if len(change.Metadata) > 0 {
+			if jsonStr, err := protocol.MapToJsonMetadata(change.Metadata); err == nil {
+				blockChange.Metadata = &protocol.JsonMetadata{JsonData: jsonStr}
+			}
+		}
+		delta.BlockChanges = append(delta.BlockChanges, blockChange)
+	}
+	return delta
+}
@@ -0,0 +1,68 @@
+package network
+
+import (
+	"hash/crc32"
+	"testing"
+
+	"github.com/annel0/mmo-game/internal/protocol"
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/annel0/mmo-game/internal/world"
+	"github.com/annel0/mmo-game/internal/world/block"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChunkRequest_KnownVersionGetsDelta(t *testing.T) {
+	gh := newTestGameHandler(t)
+	client := connectTestClient(t, gh, "conn-1")
+	addTestSession(gh, "conn-1", 1, 1, vec.Vec2{})
+
+	gh.HandleMessage("conn-1", newGameMessage(t, protocol.MessageType_CHUNK_REQUEST, &protocol.ChunkRequest{ChunkX: 3, ChunkY: -2}))
+	full := &protocol.ChunkData{}
+	client.expect(t, protocol.MessageType_CHUNK_DATA, full)
+	require.NotZero(t, full.Version)
+
+	// Пока клиент был отключён, в чанке изменился один блок
+	chunk := gh.worldManager.GetChunk(vec.Vec2{X: 3, Y: -2})
+	chunk.SetBlockLayer(world.LayerActive, vec.Vec2{X: 1, Y: 2}, block.StoneBlockID)
+	chunk.SetBlockMetadata(vec.Vec2{X: 1, Y: 2}, "owner", "alice")
+
+	// После переподключения клиент сообщает свою версию и получает только изменения
+	resumed := connectTestClient(t, gh, "conn-2")
+	addTestSession(gh, "conn-2", 1, 2, vec.Vec2{})
+	gh.HandleMessage("conn-2", newGameMessage(t, protocol.MessageType_CHUNK_REQUEST,
+		&protocol.ChunkRequest{ChunkX: 3, ChunkY: -2, KnownVersion: full.Version}))
+
+	delta := &protocol.ChunkBlockDelta{}
+	resumed.expect(t, protocol.MessageType_CHUNK_BLOCK_DELTA, delta)
+	assert.Equal(t, int32(3), delta.ChunkCoords.X)
+	assert.Equal(t, int32(-2), delta.ChunkCoords.Y)
+	require.Len(t, delta.BlockChanges, 1)
+	change := delta.BlockChanges[0]
+	assert.Equal(t, int32(1), change.LocalPos.X)
+	assert.Equal(t, int32(2), change.LocalPos.Y)
+	assert.Equal(t, uint32(world.LayerActive), change.Layer)
+	assert.Equal(t, uint32(block.StoneBlockID), change.BlockId)
+	assert.Contains(t, change.Metadata.JsonData, `"owner":"alice"`)
+
+	version, hash := chunk.VersionedHash()
+	assert.Equal(t, version, delta.DeltaVersion)
+	assert.Equal(t, crc32.ChecksumIEEE(hash[:]), delta.Crc32)
+	assert.Zero(t, resumed.drain(protocol.MessageType_CHUNK_DATA), "полный чанк не отправляется")
+}
+
+func TestChunkRequest_UnknownVersionGetsFullChunk(t *testing.T) {
+	gh := newTestGameHandler(t)
+	client := connectTestClient(t, gh, "conn-1")
+	addTestSession(gh, "conn-1", 1, 1, vec.Vec2{})
+
+	// Версия, которой у сервера нет (например, до перезапуска)
+	gh.HandleMessage("conn-1", newGameMessage(t, protocol.MessageType_CHUNK_REQUEST,
+		&protocol.ChunkRequest{ChunkX: 0, ChunkY: 4, KnownVersion: 1}))
+
+	full := &protocol.ChunkData{}
+	client.expect(t, protocol.MessageType_CHUNK_DATA, full)
+	assert.Equal(t, int32(4), full.ChunkY)
+	assert.NotZero(t, full.Version)
+	assert.Zero(t, client.drain(protocol.MessageType_CHUNK_BLOCK_DELTA))
+}
@@ -0,0 +1,195 @@
+package network
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/protocol"
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// DefaultChunkRequestRate - сколько запрошенных чанков в секунду обслуживается
+	// одному соединению после начального запаса
+	DefaultChunkRequestRate = 20
+
+	// DefaultChunkResendWindow - повторный запрос чанка в течение окна после
+	// отправки отбрасывается: чанк уже есть у клиента
+	DefaultChunkResendWindow = 5 * time.Second
+)
+
+var throttledChunkRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "network",
+	Name:      "throttled_chunk_requests_total",
+	Help:      "Запрошенные клиентами чанки, отброшенные ограничением частоты (rate) или как недавно отправленные (duplicate).",
+}, []string{"reason"})
+
+func init() {
+	prometheus.MustRegister(throttledChunkRequests)
+}
+
+// chunkRequestLimits ограничивает запросы чанков (CHUNK_REQUEST и
+// CHUNK_BATCH_REQUEST): каждый запрос заставляет сервер загрузить или
+// сгенерировать чанк и сериализовать его. Запас ведра покрывает загрузку всей
+// области видимости, затем запас восполняется со скоростью rate
+type chunkRequestLimits struct {
+	mu           sync.Mutex
+	rate         float64       // Чанков в секунду (0 - без ограничения)
+	resendWindow time.Duration // 0 - повторные запросы не отбрасываются
+	byConn       map[string]*chunkRequestBucket
+}
+
+// chunkRequestBucket - запас запросов чанков соединения
+type chunkRequestBucket struct {
+	tokens  float64
+	last    time.Time
+	sent    map[vec.Vec2]time.Time // Недавно отправленные чанки
+	pending map[vec.Vec2]struct{}  // Принятые, но ещё не отправленные чанки
+}
+
+// SetChunkRequestLimits задаёт частоту запросов чанков и окно отбрасывания
+// повторных запросов. 0 - значения по умолчанию, отрицательное значение -
+// без ограничения
+func (gh *GameHandlerPB) SetChunkRequestLimits(rate int, resendWindow time.Duration) {
+	if rate == 0 {
+		rate = DefaultChunkRequestRate
+	}
+	if resendWindow == 0 {
+		resendWindow = DefaultChunkResendWindow
+	}
+
+	l := &gh.chunkRequests
+	l.mu.Lock()
+	l.rate = float64(max(rate, 0))
+	l.resendWindow = max(resendWindow, 0)
+	l.mu.Unlock()
+}
+
+// chunkRequestBurst - запас запросов нового соединения: вся область видимости
+// при максимальной дальности
+func (gh *GameHandlerPB) chunkRequestBurst() float64 {
+	gh.mu.RLock()
+	side := 2*gh.maxViewDistance + 1
+	gh.mu.RUnlock()
+	return float64(side * side)
+}
+
+// admitChunkRequests отбирает чанки, которые можно отправить по запросу.
+// Недавно отправленные и ещё ожидающие отправки чанки пропускаются (dedup =
+// false - не пропускаются: клиент сообщил версию и ждёт дельту) и
+// возвращаются в duplicates; throttled - запас исчерпан и оставшиеся чанки
+// запроса отброшены. Принятый чанк ожидает отправки до finishChunkRequest
+func (gh *GameHandlerPB) admitChunkRequests(connID string, chunks []vec.Vec2, dedup bool) (admitted, duplicates []vec.Vec2, throttled bool) {
+	burst := gh.chunkRequestBurst()
+	now := gh.now()
+
+	l := &gh.chunkRequests
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	bucket, ok := l.byConn[connID]
+	if !ok {
+		bucket = &chunkRequestBucket{
+			tokens:  burst,
+			last:    now,
+			sent:    make(map[vec.Vec2]time.Time),
+			pending: make(map[vec.Vec2]struct{}),
+		}
+		l.byConn[connID] = bucket
+	}
+	bucket.tokens = min(burst, bucket.tokens+now.Sub(bucket.last).Seconds()*l.rate)
+	bucket.last = now
+	for pos, sentAt := range bucket.sent {
+		if now.Sub(sentAt) >= l.resendWindow {
+			delete(bucket.sent, pos)
+		}
+	}
+
+	for i, pos := range chunks {
+		if dedup && bucket.recent(pos) {
+			duplicates = append(duplicates, pos)
+			continue
+		}
+		if l.rate > 0 {
+			if bucket.tokens < 1 {
+				throttled = true
+				throttledChunkRequests.WithLabelValues("rate").Add(float64(len(chunks) - i))
+				break
+			}
+			bucket.tokens--
+		}
+		if l.resendWindow > 0 {
+			bucket.pending[pos] = struct{}{}
+		}
+		admitted = append(admitted, pos)
+	}
+
+	if len(duplicates) > 0 {
+		throttledChunkRequests.WithLabelValues("duplicate").Add(float64(len(duplicates)))
+	}
+	return admitted, duplicates, throttled
+}
+
+// recent сообщает, что чанк отправлен недавно или ожидает отправки
+func (b *chunkRequestBucket) recent(pos vec.Vec2) bool {
+	if _, ok := b.pending[pos]; ok {
+		return true
+	}
+	_, ok := b.sent[pos]
+	return ok
+}
+
+// finishChunkRequest завершает ожидание отправки чанка. Только доставленный
+// чанк (sent) отмечается отправленным: повторные запросы в окне resendWindow
+// будут отброшены; недоставленный можно сразу запросить снова
+func (gh *GameHandlerPB) finishChunkRequest(connID string, pos vec.Vec2, sent bool) {
+	l := &gh.chunkRequests
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	bucket, ok := l.byConn[connID]
+	if !ok {
+		return
+	}
+	delete(bucket.pending, pos)
+	if sent && l.resendWindow > 0 {
+		bucket.sent[pos] = gh.now()
+	}
+}
+
+// unmarkChunksSent снимает отметку отправки с чанков, выгруженных клиентом:
+// вернувшись в зону видимости, они запрашиваются заново
+func (gh *GameHandlerPB) unmarkChunksSent(connID string, chunks []vec.Vec2) {
+	l := &gh.chunkRequests
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if bucket, ok := l.byConn[connID]; ok {
+		for _, pos := range chunks {
+			delete(bucket.sent, pos)
+		}
+	}
+}
+
+// rejectChunkRequests сообщает клиенту, что часть запрошенных чанков не отправлена
+func (gh *GameHandlerPB) rejectChunkRequests(connID string, refType protocol.MessageType) {
+	log.Printf("⏳ Клиент %s превысил частоту запросов чанков", connID)
+	gh.sendError(connID, protocol.ErrorCode_RATE_LIMITED, refType, "Chunk request rate exceeded")
+}
+
+// rejectDuplicateChunks сообщает клиенту, что чанки отправлены недавно и
+// повторно не высылаются: их можно запросить с known_version или после окна
+func (gh *GameHandlerPB) rejectDuplicateChunks(connID string, refType protocol.MessageType, chunks []vec.Vec2) {
+	gh.sendError(connID, protocol.ErrorCode_RATE_LIMITED, refType,
+		fmt.Sprintf("%d chunk(s) already sent recently, request with known_version to refresh", len(chunks)))
+}
+
+// forgetChunkRequests удаляет запас запросов чанков отключившегося клиента
+func (gh *GameHandlerPB) forgetChunkRequests(connID string) {
+	gh.chunkRequests.mu.Lock()
+	delete(gh.chunkRequests.byConn, connID)
+	gh.chunkRequests.mu.Unlock()
+}
@@ -0,0 +1,97 @@
+package network
+
+import (
+	"testing"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/protocol"
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChunkRequests_RapidDuplicatesAreDeduped(t *testing.T) {
+	gh := newTestGameHandler(t)
+	now := time.Now()
+	gh.now = func() time.Time { return now }
+	client := connectTestClient(t, gh, "conn-1")
+	addTestSession(gh, "conn-1", 1, 1, vec.Vec2{})
+
+	for i := 0; i < 10; i++ {
+		gh.HandleMessage("conn-1", newGameMessage(t, protocol.MessageType_CHUNK_REQUEST, &protocol.ChunkRequest{ChunkX: 3, ChunkY: -2}))
+	}
+	assert.Equal(t, 1, client.drain(protocol.MessageType_CHUNK_DATA), "повторы в окне не сериализуются заново")
+
+	// Отброшенный повтор получает ответ
+	gh.HandleMessage("conn-1", newGameMessage(t, protocol.MessageType_CHUNK_REQUEST, &protocol.ChunkRequest{ChunkX: 3, ChunkY: -2}))
+	errMsg := &protocol.ErrorMessage{}
+	client.expect(t, protocol.MessageType_ERROR, errMsg)
+	assert.Equal(t, protocol.ErrorCode_RATE_LIMITED, errMsg.Code)
+	assert.Equal(t, protocol.MessageType_CHUNK_REQUEST, errMsg.RefType)
+
+	// После окна чанк снова отправляется
+	now = now.Add(DefaultChunkResendWindow)
+	gh.HandleMessage("conn-1", newGameMessage(t, protocol.MessageType_CHUNK_REQUEST, &protocol.ChunkRequest{ChunkX: 3, ChunkY: -2}))
+	assert.Equal(t, 1, client.drain(protocol.MessageType_CHUNK_DATA))
+}
+
+func TestChunkRequests_FloodIsThrottledAfterInitialBurst(t *testing.T) {
+	gh := newTestGameHandler(t)
+	gh.SetMaxViewDistance(1) // Запас - область 3x3
+	gh.SetChunkRequestLimits(2, -1)
+	now := time.Now()
+	gh.now = func() time.Time { return now }
+	client := connectTestClient(t, gh, "conn-1")
+	addTestSession(gh, "conn-1", 1, 1, vec.Vec2{})
+
+	// Начальная загрузка области видимости проходит целиком
+	batch := func(fromX, count int) *protocol.GameMessage {
+		req := &protocol.ChunkBatchRequest{}
+		for x := fromX; x < fromX+count; x++ {
+			req.Chunks = append(req.Chunks, &protocol.Vec2{X: int32(x), Y: 0})
+		}
+		return newGameMessage(t, protocol.MessageType_CHUNK_BATCH_REQUEST, req)
+	}
+	gh.HandleMessage("conn-1", batch(0, 9))
+	assert.Equal(t, 9, client.drain(protocol.MessageType_CHUNK_DATA))
+
+	// Сверх запаса - ошибка RATE_LIMITED и ни одного лишнего чанка
+	gh.HandleMessage("conn-1", batch(100, 5))
+	errMsg := &protocol.ErrorMessage{}
+	client.expect(t, protocol.MessageType_ERROR, errMsg)
+	assert.Equal(t, protocol.ErrorCode_RATE_LIMITED, errMsg.Code)
+	assert.Equal(t, protocol.MessageType_CHUNK_BATCH_REQUEST, errMsg.RefType)
+	assert.Equal(t, 0, client.drain(protocol.MessageType_CHUNK_DATA))
+
+	// Запас восполняется со временем
+	now = now.Add(time.Second)
+	gh.HandleMessage("conn-1", batch(200, 5))
+	assert.Equal(t, 2, client.drain(protocol.MessageType_CHUNK_DATA))
+}
+
+func TestChunkRequests_DedupOnlyAfterDelivery(t *testing.T) {
+	gh := newTestGameHandler(t)
+	now := time.Now()
+	gh.now = func() time.Time { return now }
+	client := connectTestClient(t, gh, "conn-1")
+	addTestSession(gh, "conn-1", 1, 1, vec.Vec2{})
+	request := newGameMessage(t, protocol.MessageType_CHUNK_REQUEST, &protocol.ChunkRequest{ChunkX: 3, ChunkY: -2})
+
+	// Недоставленный чанк (клиент отключился) не считается отправленным
+	admitted, _, _ := gh.admitChunkRequests("conn-1", []vec.Vec2{{X: 3, Y: -2}}, true)
+	require.Len(t, admitted, 1)
+	gh.finishChunkRequest("conn-1", vec.Vec2{X: 3, Y: -2}, false)
+	gh.HandleMessage("conn-1", request)
+	assert.Equal(t, 1, client.drain(protocol.MessageType_CHUNK_DATA))
+
+	// Запрос с известной версией ждёт дельту и не отбрасывается
+	gh.HandleMessage("conn-1", newGameMessage(t, protocol.MessageType_CHUNK_REQUEST, &protocol.ChunkRequest{ChunkX: 3, ChunkY: -2, KnownVersion: 1}))
+	assert.Equal(t, 1, client.drain(protocol.MessageType_CHUNK_DATA)+client.drain(protocol.MessageType_CHUNK_BLOCK_DELTA))
+
+	// Выгруженный клиентом чанк можно сразу запросить снова
+	gh.HandleMessage("conn-1", request)
+	assert.Zero(t, client.drain(protocol.MessageType_CHUNK_DATA))
+	gh.sendChunkUnload("conn-1", []vec.Vec2{{X: 3, Y: -2}})
+	gh.HandleMessage("conn-1", request)
+	assert.Equal(t, 1, client.drain(protocol.MessageType_CHUNK_DATA))
+}
@@ -0,0 +1,145 @@
+package network
+
+import (
+	"context"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/protocol"
+	"github.com/annel0/mmo-game/internal/vec"
+)
+
+// chunkStreamInterval - пауза между отправкой чанков, чтобы не перегружать клиента
+const chunkStreamInterval = 10 * time.Millisecond
+
+// chunkStream - фоновая отправка чанков вокруг игрока, ближайшие чанки уходят первыми
+type chunkStream struct {
+	center vec.Vec2 // Чанк, вокруг которого строилась очередь
+	radius int      // Радиус в чанках
+	cancel context.CancelFunc
+	done   chan struct{} // Закрывается после завершения или отмены отправки
+}
+
+// chunksByDistance возвращает координаты чанков в квадрате радиуса radius,
+// упорядоченные по удалённости от центра (ближайшие первыми)
+func chunksByDistance(center vec.Vec2, radius int) []vec.Vec2 {
+	side := 2*radius + 1
+	chunks := make([]vec.Vec2, 0, side*side)
+	for y := center.Y - radius; y <= center.Y+radius; y++ {
+		for x := center.X - radius; x <= center.X+radius; x++ {
+			chunks = append(chunks, vec.Vec2{X: x, Y: y})
+		}
+	}
+
+	distSq := func(p vec.Vec2) int {
+		dx, dy := p.X-center.X, p.Y-center.Y
+		return dx*dx + dy*dy
+	}
+	// Стабильная сортировка сохраняет построчный порядок среди равноудалённых чанков
+	sort.SliceStable(chunks, func(i, j int) bool {
+		return distSq(chunks[i]) < distSq(chunks[j])
+	})
+	return chunks
+}
+
+// startChunkStream отменяет текущую отправку чанков игроку и запускает новую вокруг center.
+// Чанки, вышедшие из зоны видимости, клиенту предлагается выгрузить (CHUNK_UNLOAD)
+func (gh *GameHandlerPB) startChunkStream(connID string, center vec.Vec2, radius int) *chunkStream {
+	if prev := gh.cancelChunkStream(connID); prev != nil {
+		<-prev.done
+	}
+
+	gh.sendChunkUnload(connID, gh.recenterKnownChunks(connID, center, radius))
+
+	// Отправка прерывается и при отключении клиента (см. cancelConnContext)
+	ctx, cancel := context.WithCancel(gh.connContext(connID))
+	stream := &chunkStream{
+		center: center,
+		radius: radius,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+
+	gh.streamsMu.Lock()
+	gh.chunkStreams[connID] = stream
+	gh.streamsMu.Unlock()
+
+	go func() {
+		defer close(stream.done)
+		gh.streamChunks(ctx, connID, chunksByDistance(center, radius))
+
+		gh.streamsMu.Lock()
+		if gh.chunkStreams[connID] == stream {
+			delete(gh.chunkStreams, connID)
+		}
+		gh.streamsMu.Unlock()
+	}()
+
+	return stream
+}
+
+// cancelChunkStream прерывает отправку чанков игроку (не дожидаясь её остановки)
+func (gh *GameHandlerPB) cancelChunkStream(connID string) *chunkStream {
+	gh.streamsMu.Lock()
+	stream, ok := gh.chunkStreams[connID]
+	delete(gh.chunkStreams, connID)
+	gh.streamsMu.Unlock()
+
+	if !ok {
+		return nil
+	}
+	stream.cancel()
+	return stream
+}
+
+// updateChunkStream перезапускает отправку чанков, если игрок перешёл в другой чанк.
+// Чанки, которые уже есть у клиента без изменений, повторно не отправляются,
+// поэтому шаг на соседний чанк досылает только полосу ставших видимыми чанков.
+// Возвращает новую отправку или nil, если игрок остался в прежнем чанке
+func (gh *GameHandlerPB) updateChunkStream(connID string, pos vec.Vec2) *chunkStream {
+	center, radius, ok := gh.viewCenter(connID)
+	if !ok {
+		return nil
+	}
+
+	chunkPos := pos.ToChunkCoords()
+	if chunkPos == center {
+		return nil
+	}
+
+	if chunkDistance(chunkPos, center) > radius {
+		log.Printf("🔁 Игрок %s покинул область загрузки, перезапуск отправки чанков от (%d,%d)", connID, chunkPos.X, chunkPos.Y)
+	}
+	return gh.startChunkStream(connID, chunkPos, radius)
+}
+
+// streamChunks последовательно отправляет чанки, пока контекст не отменён.
+// Чанки, уже доставленные клиенту с тем же хэшем, пропускаются
+func (gh *GameHandlerPB) streamChunks(ctx context.Context, connID string, chunks []vec.Vec2) {
+	for i, chunkPos := range chunks {
+		if ctx.Err() != nil {
+			log.Printf("⏹️ Отправка чанков %s прервана: доставлено %d из %d", connID, i, len(chunks))
+			return
+		}
+
+		// Получаем данные чанка из мира
+		chunk := gh.worldManager.GetChunk(chunkPos)
+		if chunk == nil {
+			continue
+		}
+
+		version, hash := chunk.VersionedHash()
+		if gh.isChunkKnown(connID, chunkPos, hash) {
+			continue
+		}
+
+		gh.sendTCPMessage(connID, protocol.MessageType_CHUNK_DATA, encodeChunkData(chunkPos, chunk, version, hash))
+		gh.rememberChunk(connID, chunkPos, hash)
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(chunkStreamInterval):
+		}
+	}
+}
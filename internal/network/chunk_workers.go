@@ -0,0 +1,164 @@
+package network
+
+import (
+	"context"
+	"runtime"
+	"sync"
+
+	"github.com/annel0/mmo-game/internal/protocol"
+	"github.com/annel0/mmo-game/internal/vec"
+)
+
+// DefaultChunkQueueSize - ёмкость очереди запросов чанков; при заполнении
+// обработчик сообщений ждёт освобождения места (обратное давление)
+const DefaultChunkQueueSize = 256
+
+// chunkJob - запрос на сборку и отправку одного чанка
+type chunkJob struct {
+	ctx          context.Context // Контекст соединения: после отключения клиента чанк не собирается
+	connID       string
+	pos          vec.Vec2
+	knownVersion uint64 // Версия чанка у клиента: если дельта доступна, отправляется она
+}
+
+// chunkWorkerPool выполняет сборку ChunkData и сериализацию сообщений в
+// ограниченном числе горутин. Чанки одного пакета могут прийти клиенту в
+// любом порядке: каждый ChunkData несёт собственные координаты.
+type chunkWorkerPool struct {
+	workers int
+	jobs    chan chunkJob
+	handle  func(chunkJob)
+
+	mu      sync.RWMutex // Защищает закрытие jobs от параллельного submit
+	closed  bool
+	started sync.Once
+	wg      sync.WaitGroup
+}
+
+// newChunkWorkerPool создаёт пул; горутины запускаются при первом запросе
+func newChunkWorkerPool(workers int, handle func(chunkJob)) *chunkWorkerPool {
+	if workers < 1 {
+		workers = runtime.NumCPU()
+	}
+	return &chunkWorkerPool{
+		workers: workers,
+		jobs:    make(chan chunkJob, DefaultChunkQueueSize),
+		handle:  handle,
+	}
+}
+
+// submit ставит задание в очередь, ожидая места не дольше жизни ctx.
+// Возвращает false, если пул остановлен или ctx отменён раньше
+func (p *chunkWorkerPool) submit(ctx context.Context, job chunkJob) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return false
+	}
+
+	p.started.Do(p.start)
+	select {
+	case p.jobs <- job:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (p *chunkWorkerPool) start() {
+	p.wg.Add(p.workers)
+	for i := 0; i < p.workers; i++ {
+		go func() {
+			defer p.wg.Done()
+			for job := range p.jobs {
+				p.handle(job)
+			}
+		}()
+	}
+}
+
+// stop дожидается отправки уже принятых заданий и останавливает горутины
+func (p *chunkWorkerPool) stop() {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return
+	}
+	p.closed = true
+	close(p.jobs)
+	p.mu.Unlock()
+
+	p.wg.Wait()
+}
+
+// SetChunkWorkers задаёт число горутин сериализации чанков: 0 - по числу
+// CPU, отрицательное значение - сериализация в горутине обработчика.
+// Задания прежнего пула дорабатываются до возврата.
+func (gh *GameHandlerPB) SetChunkWorkers(workers int) {
+	var pool *chunkWorkerPool
+	if workers >= 0 {
+		pool = newChunkWorkerPool(workers, gh.deliverChunk)
+	}
+
+	gh.chunkPoolMu.Lock()
+	prev := gh.chunkPool
+	gh.chunkPool = pool
+	gh.chunkPoolMu.Unlock()
+
+	if prev != nil {
+		prev.stop()
+	}
+}
+
+// StopChunkWorkers останавливает пул сериализации чанков; дальнейшие
+// запросы чанков обрабатываются в горутине обработчика
+func (gh *GameHandlerPB) StopChunkWorkers() {
+	gh.SetChunkWorkers(-1)
+}
+
+// sendChunkToClient отправляет чанк клиенту через пул сериализации.
+// ctx ограничивает ожидание места в очереди; сама отправка прерывается,
+// только если клиент отключился
+func (gh *GameHandlerPB) sendChunkToClient(ctx context.Context, connID string, chunkX, chunkY int) {
+	gh.submitChunk(ctx, chunkJob{ctx: gh.connContext(connID), connID: connID, pos: vec.Vec2{X: chunkX, Y: chunkY}})
+}
+
+// submitChunk передаёт задание пулу или, без пула, выполняет его сразу
+func (gh *GameHandlerPB) submitChunk(ctx context.Context, job chunkJob) {
+	gh.chunkPoolMu.RLock()
+	pool := gh.chunkPool
+	gh.chunkPoolMu.RUnlock()
+
+	if pool != nil && pool.submit(ctx, job) {
+		return
+	}
+	if ctx.Err() != nil {
+		gh.finishChunkRequest(job.connID, job.pos, false)
+		return
+	}
+	gh.deliverChunk(job)
+}
+
+// deliverChunk собирает ChunkData (или дельту к версии клиента) и отправляет
+// в соединение клиента
+func (gh *GameHandlerPB) deliverChunk(job chunkJob) {
+	sent := false
+	defer func() { gh.finishChunkRequest(job.connID, job.pos, sent) }()
+
+	if job.ctx != nil && job.ctx.Err() != nil {
+		return // Клиент отключился, пока чанк ждал в очереди
+	}
+	if job.knownVersion != 0 && gh.deliverChunkDelta(job) {
+		sent = true
+		return
+	}
+
+	chunk := gh.worldManager.GetChunk(job.pos)
+	if chunk == nil {
+		return
+	}
+	version, hash := chunk.VersionedHash()
+	gh.sendTCPMessage(job.connID, protocol.MessageType_CHUNK_DATA, encodeChunkData(job.pos, chunk, version, hash))
+	gh.rememberChunk(job.connID, job.pos, hash)
+	sent = true
+}
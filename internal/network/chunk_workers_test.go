@@ -0,0 +1,141 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/annel0/mmo-game/internal/auth"
+	"github.com/annel0/mmo-game/internal/protocol"
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/annel0/mmo-game/internal/world"
+	"github.com/annel0/mmo-game/internal/world/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+// chunkBatch возвращает координаты size x size чанков, начиная с (0, 0)
+func chunkBatch(size int) []*protocol.Vec2 {
+	chunks := make([]*protocol.Vec2, 0, size*size)
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			chunks = append(chunks, &protocol.Vec2{X: int32(x), Y: int32(y)})
+		}
+	}
+	return chunks
+}
+
+func TestChunkBatch_PoolDeliversEveryChunk(t *testing.T) {
+	for _, workers := range []int{-1, 1, 4} {
+		t.Run(fmt.Sprintf("workers=%d", workers), func(t *testing.T) {
+			gh := newTestGameHandler(t)
+			gh.SetChunkWorkers(workers)
+			t.Cleanup(gh.StopChunkWorkers)
+			client := connectTestClient(t, gh, "conn-1")
+
+			batch := chunkBatch(3)
+			gh.handleChunkBatchRequest(context.Background(), "conn-1", newGameMessage(t, protocol.MessageType_CHUNK_BATCH_REQUEST,
+				&protocol.ChunkBatchRequest{Chunks: batch}))
+
+			// Порядок не гарантирован: сверяем набор координат
+			received := make(map[vec.Vec2]bool)
+			for range batch {
+				data := &protocol.ChunkData{}
+				client.expect(t, protocol.MessageType_CHUNK_DATA, data)
+				require.Len(t, data.Layers, 2)
+				received[vec.Vec2{X: int(data.ChunkX), Y: int(data.ChunkY)}] = true
+			}
+			for _, pos := range batch {
+				assert.True(t, received[vec.Vec2{X: int(pos.X), Y: int(pos.Y)}], "чанк (%d,%d) не доставлен", pos.X, pos.Y)
+			}
+		})
+	}
+}
+
+func TestChunkWorkers_StopFallsBackToInline(t *testing.T) {
+	gh := newTestGameHandler(t)
+	client := connectTestClient(t, gh, "conn-1")
+	gh.StopChunkWorkers()
+
+	gh.sendChunkToClient(context.Background(), "conn-1", 5, -2)
+
+	data := &protocol.ChunkData{}
+	client.expect(t, protocol.MessageType_CHUNK_DATA, data)
+	assert.Equal(t, int32(5), data.ChunkX)
+	assert.Equal(t, int32(-2), data.ChunkY)
+
+	hash := gh.worldManager.GetChunk(vec.Vec2{X: 5, Y: -2}).Hash()
+	assert.Equal(t, hash[:], data.Hash)
+	assert.Equal(t, world.ChunkHashVersion, data.HashVersion)
+}
+
+// benchSender сериализует сообщения, как TCP-соединение, и отмечает доставку
+type benchSender struct {
+	delivered *sync.WaitGroup
+}
+
+func (s benchSender) SendToClient(_ string, msgType protocol.MessageType, payload proto.Message) {
+	data, _ := proto.Marshal(payload)
+	_, _ = proto.Marshal(&protocol.GameMessage{Type: msgType, Payload: data})
+	s.delivered.Done()
+}
+
+func (benchSender) Broadcast(protocol.MessageType, proto.Message) {}
+func (benchSender) ConnectionIDs() []string                       { return nil }
+func (benchSender) BindPlayer(string, uint64)                     {}
+func (benchSender) Disconnect(string, DisconnectReason)           {}
+
+// BenchmarkChunkBatch сравнивает сериализацию чанков в горутине обработчика
+// и в пуле при одновременных пакетных запросах нескольких клиентов.
+// Выигрыш пула растёт с числом CPU сверх числа клиентов
+func BenchmarkChunkBatch(b *testing.B) {
+	const clients = 2
+	batch := chunkBatch(5)
+
+	for _, workers := range []int{-1, 0} {
+		name := "inline"
+		if workers >= 0 {
+			name = "pool"
+		}
+		b.Run(name, func(b *testing.B) {
+			userRepo, err := auth.NewMemoryUserRepo()
+			require.NoError(b, err)
+			worldManager := world.NewWorldManager(1234)
+			b.Cleanup(worldManager.Stop)
+
+			gh := NewGameHandlerPB(worldManager, entity.NewEntityManager(), userRepo)
+			gh.SetChunkWorkers(workers)
+			b.Cleanup(gh.StopChunkWorkers)
+
+			var delivered sync.WaitGroup
+			gh.SetNetworkSender(benchSender{delivered: &delivered})
+
+			// Генерация мира не входит в замер
+			for _, pos := range batch {
+				worldManager.GetChunk(vec.Vec2{X: int(pos.X), Y: int(pos.Y)})
+			}
+			msg := &protocol.GameMessage{Type: protocol.MessageType_CHUNK_BATCH_REQUEST}
+			msg.Payload, err = proto.Marshal(&protocol.ChunkBatchRequest{Chunks: batch})
+			require.NoError(b, err)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				delivered.Add(clients * len(batch))
+
+				var handlers sync.WaitGroup
+				for c := 0; c < clients; c++ {
+					handlers.Add(1)
+					go func(connID string) {
+						defer handlers.Done()
+						gh.handleChunkBatchRequest(context.Background(), connID, msg)
+					}(fmt.Sprintf("conn-%d", c))
+				}
+				handlers.Wait()
+				delivered.Wait()
+			}
+			b.ReportMetric(float64(b.N*clients*len(batch))/b.Elapsed().Seconds(), "chunks/s")
+		})
+	}
+}
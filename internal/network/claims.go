@@ -0,0 +1,29 @@
+package network
+
+import (
+	"fmt"
+
+	"github.com/annel0/mmo-game/internal/vec"
+)
+
+// claimDenial проверяет приват, в который входит блок: изменять блоки в нём
+// могут только владелец и администраторы. Возвращает сообщение для игрока и
+// true, если изменение запрещено
+func (gh *GameHandlerPB) claimDenial(connID string, pos vec.Vec2) (string, bool) {
+	claim, claimed := gh.worldManager.ClaimAt(pos)
+	if !claimed {
+		return "", false
+	}
+
+	gh.mu.RLock()
+	session := gh.sessions[connID]
+	gh.mu.RUnlock()
+
+	if session != nil && (session.IsAdmin || (claim.OwnerID != 0 && session.UserID == claim.OwnerID)) {
+		return "", false
+	}
+	if claim.Name != "" {
+		return fmt.Sprintf("Area %q is protected: only its owner can build here", claim.Name), true
+	}
+	return "Area is protected: only its owner can build here", true
+}
@@ -0,0 +1,90 @@
+package network
+
+import (
+	"testing"
+
+	"github.com/annel0/mmo-game/internal/protocol"
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/annel0/mmo-game/internal/world"
+	"github.com/annel0/mmo-game/internal/world/block"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockUpdate_ClaimRestrictsEditsToOwner(t *testing.T) {
+	gh := newTestGameHandler(t)
+	owner := connectTestClient(t, gh, "conn-1")
+	stranger := connectTestClient(t, gh, "conn-2")
+	addTestSession(gh, "conn-1", 1, 1, vec.Vec2{X: 0, Y: 0})
+	addTestSession(gh, "conn-2", 2, 2, vec.Vec2{X: 1, Y: 0})
+	registerTestBlock(t, block.StoneBlockID, "stone")
+
+	_, err := gh.worldManager.AddClaim(1, "Дом", vec.Vec2{X: 0, Y: 0}, vec.Vec2{X: 0, Y: 0})
+	require.NoError(t, err)
+
+	target := vec.Vec2{X: 2, Y: 2}
+	place := func(connID string) {
+		gh.HandleMessage(connID, newGameMessage(t, protocol.MessageType_BLOCK_UPDATE, &protocol.BlockUpdateRequest{
+			Position: &protocol.Vec2{X: int32(target.X), Y: int32(target.Y)},
+			BlockId:  uint32(block.StoneBlockID),
+			Layer:    protocol.BlockLayer_ACTIVE,
+			Action:   "place",
+		}))
+	}
+
+	// Чужой игрок не может строить в привате
+	place("conn-2")
+	rejected := &protocol.BlockUpdateResponseMessage{}
+	stranger.expect(t, protocol.MessageType_BLOCK_UPDATE_RESPONSE, rejected)
+	assert.False(t, rejected.Success)
+	assert.Contains(t, rejected.Message, "protected")
+	errMsg := &protocol.ErrorMessage{}
+	stranger.expect(t, protocol.MessageType_ERROR, errMsg)
+	assert.Equal(t, protocol.ErrorCode_FORBIDDEN, errMsg.Code)
+	assert.Equal(t, block.AirBlockID, gh.worldManager.GetBlockLayer(target, world.LayerActive).ID)
+
+	// Владелец строит
+	place("conn-1")
+	applied := &protocol.BlockUpdateResponseMessage{}
+	owner.expect(t, protocol.MessageType_BLOCK_UPDATE_RESPONSE, applied)
+	require.True(t, applied.Success, applied.Message)
+	assert.Equal(t, block.StoneBlockID, gh.worldManager.GetBlockLayer(target, world.LayerActive).ID)
+
+}
+
+func TestBuildActions_ClaimRestrictsEditsToOwner(t *testing.T) {
+	gh := newTestGameHandler(t)
+	connectTestClient(t, gh, "conn-1")
+	connectTestClient(t, gh, "conn-2")
+	addTestSession(gh, "conn-1", 1, 1, vec.Vec2{X: 0, Y: 0})
+	addTestSession(gh, "conn-2", 2, 2, vec.Vec2{X: 1, Y: 0})
+	registerTestBlock(t, block.StoneBlockID, "stone")
+
+	_, err := gh.worldManager.AddClaim(1, "Дом", vec.Vec2{X: 0, Y: 0}, vec.Vec2{X: 0, Y: 0})
+	require.NoError(t, err)
+
+	target := vec.Vec2{X: 2, Y: 2}
+	gh.worldManager.SetBlockLayer(target, world.LayerActive, world.NewBlock(block.AirBlockID))
+	act := func(connID string, actionType protocol.EntityActionType) (bool, string) {
+		actor := playerEntityFor(t, gh, connID)
+		success, message, _ := gh.processEntityAction(actor.ID, &protocol.EntityActionRequest{
+			ActionType: actionType,
+			Position:   &protocol.Vec2{X: int32(target.X), Y: int32(target.Y)},
+		})
+		return success, message
+	}
+
+	// Действия строительства не обходят приват
+	success, message := act("conn-2", protocol.EntityActionType_ACTION_BUILD_PLACE)
+	assert.False(t, success)
+	assert.Contains(t, message, "protected")
+	assert.Equal(t, block.AirBlockID, gh.worldManager.GetBlockLayer(target, world.LayerActive).ID)
+
+	success, message = act("conn-1", protocol.EntityActionType_ACTION_BUILD_PLACE)
+	require.True(t, success, message)
+	assert.Equal(t, block.StoneBlockID, gh.worldManager.GetBlockLayer(target, world.LayerActive).ID)
+
+	success, _ = act("conn-2", protocol.EntityActionType_ACTION_BUILD_BREAK)
+	assert.False(t, success)
+	assert.Equal(t, block.StoneBlockID, gh.worldManager.GetBlockLayer(target, world.LayerActive).ID)
+}
@@ -0,0 +1,109 @@
+package network
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultMessageTimeout - сколько может обрабатываться одно сообщение клиента
+// (загрузка из хранилища, ожидание места в очереди чанков)
+const DefaultMessageTimeout = 5 * time.Second
+
+// closedConnTTL - сколько помнится отключённое соединение: запоздавшие
+// обработчики и отправки не должны заново создавать для него контекст
+const closedConnTTL = time.Minute
+
+// closedConnCtx - уже отменённый контекст для отключённых соединений
+var closedConnCtx = func() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	return ctx
+}()
+
+// connContexts - контексты соединений: отменяются при отключении клиента,
+// чтобы незавершённая работа для него (отправка чанков, запросы к хранилищу) прекращалась
+type connContexts struct {
+	mu      sync.Mutex
+	byConn  map[string]connContext
+	closed  map[string]time.Time // Отключённые соединения -> время отключения
+	timeout time.Duration        // Предел обработки одного сообщения (0 - без предела)
+}
+
+type connContext struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// SetMessageTimeout ограничивает время обработки одного сообщения клиента:
+// 0 - DefaultMessageTimeout, отрицательное значение - без ограничения
+func (gh *GameHandlerPB) SetMessageTimeout(timeout time.Duration) {
+	if timeout == 0 {
+		timeout = DefaultMessageTimeout
+	}
+
+	gh.conns.mu.Lock()
+	gh.conns.timeout = max(timeout, 0)
+	gh.conns.mu.Unlock()
+}
+
+// connContext возвращает контекст соединения, создавая его при первом
+// обращении. Для уже отключённого соединения возвращается отменённый контекст
+func (gh *GameHandlerPB) connContext(connID string) context.Context {
+	gh.conns.mu.Lock()
+	defer gh.conns.mu.Unlock()
+
+	if cc, ok := gh.conns.byConn[connID]; ok {
+		return cc.ctx
+	}
+	if _, closed := gh.conns.closed[connID]; closed {
+		return closedConnCtx
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	gh.conns.byConn[connID] = connContext{ctx: ctx, cancel: cancel}
+	return ctx
+}
+
+// messageContext возвращает контекст обработки одного сообщения: отменяется
+// при отключении клиента или по истечении времени на сообщение
+func (gh *GameHandlerPB) messageContext(connID string) (context.Context, context.CancelFunc) {
+	ctx := gh.connContext(connID)
+
+	gh.conns.mu.Lock()
+	timeout := gh.conns.timeout
+	gh.conns.mu.Unlock()
+
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// openConnContext снимает отметку об отключении: идентификатор соединения
+// (адрес клиента) может достаться новому подключению
+func (gh *GameHandlerPB) openConnContext(connID string) {
+	gh.conns.mu.Lock()
+	delete(gh.conns.closed, connID)
+	gh.conns.mu.Unlock()
+}
+
+// cancelConnContext отменяет всю незавершённую работу для отключившегося
+// клиента и запоминает соединение как отключённое на closedConnTTL
+func (gh *GameHandlerPB) cancelConnContext(connID string) {
+	now := time.Now()
+
+	gh.conns.mu.Lock()
+	cc, ok := gh.conns.byConn[connID]
+	delete(gh.conns.byConn, connID)
+	for id, at := range gh.conns.closed {
+		if now.Sub(at) > closedConnTTL {
+			delete(gh.conns.closed, id)
+		}
+	}
+	gh.conns.closed[connID] = now
+	gh.conns.mu.Unlock()
+
+	if ok {
+		cc.cancel()
+	}
+}
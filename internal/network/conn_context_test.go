@@ -0,0 +1,144 @@
+package network
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/protocol"
+	"github.com/annel0/mmo-game/internal/storage"
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+// gatedSender задерживает отправку чанков, пока тест не откроет шлюз
+type gatedSender struct {
+	started chan struct{} // Сигнал о первой начатой отправке
+	gate    chan struct{}
+	once    sync.Once
+	chunks  atomic.Int32
+}
+
+func newGatedSender() *gatedSender {
+	return &gatedSender{started: make(chan struct{}), gate: make(chan struct{})}
+}
+
+func (s *gatedSender) SendToClient(_ string, msgType protocol.MessageType, _ proto.Message) {
+	if msgType != protocol.MessageType_CHUNK_DATA {
+		return
+	}
+	s.once.Do(func() { close(s.started) })
+	<-s.gate
+	s.chunks.Add(1)
+}
+
+func (*gatedSender) Broadcast(protocol.MessageType, proto.Message) {}
+func (*gatedSender) ConnectionIDs() []string                       { return nil }
+func (*gatedSender) BindPlayer(string, uint64)                     {}
+func (*gatedSender) Disconnect(string, DisconnectReason)           {}
+
+func waitStarted(t *testing.T, sender *gatedSender) {
+	t.Helper()
+	select {
+	case <-sender.started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("отправка чанков не началась")
+	}
+}
+
+func TestConnContext_DisconnectCancelsChunkBatch(t *testing.T) {
+	gh := newTestGameHandler(t)
+	gh.SetChunkWorkers(1)
+	t.Cleanup(gh.StopChunkWorkers)
+	sender := newGatedSender()
+	gh.SetNetworkSender(sender)
+	addTestSession(gh, "conn-1", 1, 100, vec.Vec2{})
+
+	batch := chunkBatch(3)
+	gh.HandleMessage("conn-1", newGameMessage(t, protocol.MessageType_CHUNK_BATCH_REQUEST,
+		&protocol.ChunkBatchRequest{Chunks: batch}))
+
+	// Первый чанк застрял в отправке, остальные ждут в очереди пула
+	waitStarted(t, sender)
+	gh.OnClientDisconnect("conn-1")
+	close(sender.gate)
+
+	gh.StopChunkWorkers()
+	assert.Equal(t, int32(1), sender.chunks.Load(), "после отключения чанки из очереди не отправляются")
+}
+
+func TestConnContext_DisconnectCancelsChunkStream(t *testing.T) {
+	gh := newTestGameHandler(t)
+	sender := newGatedSender()
+	gh.SetNetworkSender(sender)
+
+	stream := gh.startChunkStream("conn-1", vec.Vec2{}, 2)
+	waitStarted(t, sender)
+
+	// Отмена контекста соединения сама по себе останавливает отправку
+	gh.cancelConnContext("conn-1")
+	close(sender.gate)
+
+	select {
+	case <-stream.done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("отправка чанков не остановилась после отключения")
+	}
+	assert.Less(t, sender.chunks.Load(), int32(25))
+}
+
+// slowPositionRepo отвечает на Load только по отмене контекста
+type slowPositionRepo struct {
+	storage.PositionRepo
+}
+
+func (slowPositionRepo) Load(ctx context.Context, userID uint64) (vec.Vec3, bool, error) {
+	<-ctx.Done()
+	return vec.Vec3{}, false, ctx.Err()
+}
+
+func TestConnContext_MessageTimeoutAbortsAuth(t *testing.T) {
+	gh := newTestGameHandler(t)
+	gh.SetPositionRepo(slowPositionRepo{PositionRepo: storage.NewMemoryPositionRepo()})
+	gh.SetMessageTimeout(20 * time.Millisecond)
+	client := connectTestClient(t, gh, "conn-1")
+
+	password := "ChangeMe123!"
+	start := time.Now()
+	gh.HandleMessage(client.connID, newGameMessage(t, protocol.MessageType_AUTH, &protocol.AuthMessage{
+		Username:        "admin",
+		Password:        &password,
+		ProtocolVersion: ProtocolVersion,
+	}))
+
+	assert.Less(t, time.Since(start), time.Second)
+	gh.mu.RLock()
+	_, exists := gh.playerEntities["conn-1"]
+	gh.mu.RUnlock()
+	require.False(t, exists, "сессия не создаётся, если загрузка не уложилась во время")
+}
+
+func TestConnContext_RemovedConnectionNotRecreated(t *testing.T) {
+	gh := newTestGameHandler(t)
+	ctx := gh.connContext("conn-1")
+	gh.OnClientDisconnect("conn-1")
+	require.Error(t, ctx.Err())
+
+	// Запоздавшие обработчики получают отменённый контекст, а не новый
+	assert.Error(t, gh.connContext("conn-1").Err())
+	msgCtx, cancel := gh.messageContext("conn-1")
+	defer cancel()
+	assert.Error(t, msgCtx.Err())
+	gh.conns.mu.Lock()
+	_, recreated := gh.conns.byConn["conn-1"]
+	gh.conns.mu.Unlock()
+	assert.False(t, recreated)
+
+	// Новое подключение с тем же адресом получает рабочий контекст
+	gh.OnClientConnect("conn-1")
+	assert.NoError(t, gh.connContext("conn-1").Err())
+}
@@ -0,0 +1,172 @@
+package network
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// DefaultMaxConnections - лимит одновременных подключений к серверу по умолчанию
+	DefaultMaxConnections = 1000
+
+	// Коды служебных сообщений, с которыми закрываются отклонённые подключения
+	ServerMessageServerFull = "server_full"
+	ServerMessageIPLimit    = "ip_limit"
+
+	// rejectWriteTimeout - сколько ждать отправки уведомления отклонённому клиенту
+	rejectWriteTimeout = time.Second
+	// rejectFlushDelay - пауза перед закрытием KCP-канала, чтобы уведомление успело уйти
+	rejectFlushDelay = 100 * time.Millisecond
+)
+
+var (
+	connectionsCurrent = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "network",
+		Name:      "connections_current",
+		Help:      "Текущее число подключений к серверу.",
+	}, []string{"transport"})
+
+	connectionsPeak = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "network",
+		Name:      "connections_peak",
+		Help:      "Наибольшее число одновременных подключений с момента запуска.",
+	}, []string{"transport"})
+
+	// connectionsAccepted - принятые подключения по транспорту: доля tcp
+	// показывает, сколько клиентов пришло через TCP fallback вместо KCP
+	connectionsAccepted = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "network",
+		Name:      "connections_accepted_total",
+		Help:      "Принятые подключения по транспорту (kcp, tcp).",
+	}, []string{"transport"})
+
+	connectionsRejected = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "network",
+		Name:      "connections_rejected_total",
+		Help:      "Подключения, отклонённые из-за лимитов.",
+	}, []string{"transport", "reason"})
+)
+
+func init() {
+	prometheus.MustRegister(connectionsCurrent, connectionsPeak, connectionsAccepted, connectionsRejected)
+}
+
+// ConnectionLimitStats - счётчики подключений сервера и отказов по лимитам
+type ConnectionLimitStats struct {
+	Max      int   // Действующий лимит (<= 0 - без ограничения)
+	Current  int   // Открытые подключения
+	Peak     int   // Наибольшее число одновременных подключений
+	Rejected int64 // Отклонено из-за лимитов
+}
+
+// connectionLimiter ограничивает число одновременных подключений одного
+// транспорта. Нулевое значение использует DefaultMaxConnections. Транспорты
+// одного сервера (KCP и TCP fallback) разделяют общий лимит shared: клиент
+// занимает одно место, через какой бы транспорт он ни подключился
+type connectionLimiter struct {
+	transport string             // Метка метрик ("" - общий лимит, без метрик)
+	shared    *connectionLimiter // Общий лимит транспортов сервера (nil - свой)
+	max       atomic.Int32       // 0 - DefaultMaxConnections, < 0 - без ограничения
+	current   atomic.Int32
+	peak      atomic.Int32
+	rejected  atomic.Int64
+}
+
+// setMax задаёт лимит: 0 - DefaultMaxConnections, отрицательное значение снимает ограничение
+func (l *connectionLimiter) setMax(limit int) {
+	if l.shared != nil {
+		l.shared.setMax(limit)
+		return
+	}
+	l.max.Store(int32(limit))
+}
+
+func (l *connectionLimiter) limit() int {
+	if l.shared != nil {
+		return l.shared.limit()
+	}
+	if limit := int(l.max.Load()); limit != 0 {
+		return limit
+	}
+	return DefaultMaxConnections
+}
+
+// acquire резервирует место под подключение; false - лимит исчерпан
+func (l *connectionLimiter) acquire() bool {
+	limit := l.limit()
+	if l.shared != nil {
+		if !l.shared.acquire() {
+			return false
+		}
+		limit = 0 // Место уже занято в общем лимите
+	}
+	for {
+		current := l.current.Load()
+		if limit > 0 && int(current) >= limit {
+			return false
+		}
+		if l.current.CompareAndSwap(current, current+1) {
+			if l.transport != "" {
+				connectionsAccepted.WithLabelValues(l.transport).Inc()
+			}
+			l.observe(current + 1)
+			return true
+		}
+	}
+}
+
+// release освобождает место закрытого подключения
+func (l *connectionLimiter) release() {
+	current := l.current.Add(-1)
+	if l.transport != "" {
+		connectionsCurrent.WithLabelValues(l.transport).Set(float64(current))
+	}
+	if l.shared != nil {
+		l.shared.release()
+	}
+}
+
+// reject учитывает отклонённое подключение
+func (l *connectionLimiter) reject(reason string) {
+	l.rejected.Add(1)
+	if l.transport != "" {
+		connectionsRejected.WithLabelValues(l.transport, reason).Inc()
+	}
+}
+
+func (l *connectionLimiter) observe(current int32) {
+	if l.transport != "" {
+		connectionsCurrent.WithLabelValues(l.transport).Set(float64(current))
+	}
+	for {
+		peak := l.peak.Load()
+		if current <= peak {
+			return
+		}
+		if l.peak.CompareAndSwap(peak, current) {
+			if l.transport != "" {
+				connectionsPeak.WithLabelValues(l.transport).Set(float64(current))
+			}
+			return
+		}
+	}
+}
+
+func (l *connectionLimiter) stats() ConnectionLimitStats {
+	return ConnectionLimitStats{
+		Max:      l.limit(),
+		Current:  int(l.current.Load()),
+		Peak:     int(l.peak.Load()),
+		Rejected: l.rejected.Load(),
+	}
+}
+
+// rejectionText - текст уведомления для отклонённого подключения
+func rejectionText(reason string) string {
+	if reason == ServerMessageIPLimit {
+		return "Слишком много подключений с вашего адреса"
+	}
+	return "Сервер переполнен, попробуйте подключиться позже"
+}
@@ -0,0 +1,137 @@
+package network
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/protocol"
+	"github.com/annel0/mmo-game/internal/world"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+// startLimitedTCPServer запускает TCP-сервер на свободном порту с лимитом
+// подключений. Обработчик gh (nil - без обработчика) подключается до Start:
+// горутины соединений читают его без блокировок
+func startLimitedTCPServer(t *testing.T, limit int, gh *GameHandlerPB) *TCPServerPB {
+	t.Helper()
+
+	server, err := NewTCPServerPB("127.0.0.1:0", world.NewWorldManager(1234))
+	require.NoError(t, err)
+	server.SetMaxConnections(limit)
+	if gh != nil {
+		server.SetGameHandler(gh)
+		gh.SetTCPServer(server)
+	}
+	server.Start()
+	t.Cleanup(server.Stop)
+	return server
+}
+
+func dialTCP(t *testing.T, server *TCPServerPB) net.Conn {
+	t.Helper()
+
+	conn, err := net.Dial("tcp", server.listener.Addr().String())
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// readFrame читает одно сообщение протокола из TCP-соединения
+func readFrame(t *testing.T, conn net.Conn, serializer *protocol.MessageSerializer) (*protocol.GameMessage, error) {
+	t.Helper()
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return nil, err
+	}
+	body := make([]byte, binary.BigEndian.Uint32(header))
+	if _, err := io.ReadFull(conn, body); err != nil {
+		return nil, err
+	}
+	return serializer.DeserializeMessage(body)
+}
+
+func TestTCPServer_RejectsConnectionOverLimit(t *testing.T) {
+	server := startLimitedTCPServer(t, 2, nil)
+
+	first := dialTCP(t, server)
+	dialTCP(t, server)
+	require.Eventually(t, func() bool { return server.ConnectionLimitStats().Current == 2 },
+		5*time.Second, 10*time.Millisecond)
+
+	// Третье подключение получает причину отказа и закрывается
+	rejected := dialTCP(t, server)
+	msg, err := readFrame(t, rejected, server.serializer)
+	require.NoError(t, err)
+	require.Equal(t, protocol.MessageType_SERVER_MESSAGE, msg.Type)
+
+	notice := &protocol.ServerMessage{}
+	require.NoError(t, proto.Unmarshal(msg.Payload, notice))
+	assert.Equal(t, ServerMessageServerFull, notice.Code)
+	assert.NotEmpty(t, notice.Message)
+
+	_, err = readFrame(t, rejected, server.serializer)
+	assert.ErrorIs(t, err, io.EOF)
+
+	// Принятые подключения продолжают работать
+	stats := server.ConnectionLimitStats()
+	assert.Equal(t, 2, stats.Current)
+	assert.Equal(t, 2, stats.Peak)
+	assert.Equal(t, int64(1), stats.Rejected)
+
+	server.sendToClient(first.LocalAddr().String(), protocol.MessageType_SERVER_MESSAGE,
+		&protocol.ServerMessage{Code: "ping"})
+	msg, err = readFrame(t, first, server.serializer)
+	require.NoError(t, err)
+	assert.Equal(t, protocol.MessageType_SERVER_MESSAGE, msg.Type)
+
+	// Освободившееся место снова доступно
+	first.Close()
+	require.Eventually(t, func() bool { return server.ConnectionLimitStats().Current == 1 },
+		5*time.Second, 10*time.Millisecond)
+	dialTCP(t, server)
+	require.Eventually(t, func() bool { return server.ConnectionLimitStats().Current == 2 },
+		5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, int64(1), server.ConnectionLimitStats().Rejected)
+}
+
+func TestConnectionLimiter_Limits(t *testing.T) {
+	var defaults connectionLimiter
+	assert.Equal(t, DefaultMaxConnections, defaults.stats().Max)
+
+	unlimited := connectionLimiter{transport: "test"}
+	unlimited.setMax(-1)
+	for i := 0; i < DefaultMaxConnections+1; i++ {
+		require.True(t, unlimited.acquire())
+	}
+
+	limited := connectionLimiter{transport: "test"}
+	limited.setMax(1)
+	require.True(t, limited.acquire())
+	assert.False(t, limited.acquire())
+	limited.release()
+	assert.True(t, limited.acquire())
+	assert.Equal(t, 1, limited.stats().Peak)
+}
+
+func TestConnectionLimiter_SharedBetweenTransports(t *testing.T) {
+	shared := &connectionLimiter{}
+	kcp := connectionLimiter{transport: "test", shared: shared}
+	tcp := connectionLimiter{transport: "test", shared: shared}
+	tcp.setMax(1)
+
+	require.True(t, kcp.acquire())
+	assert.False(t, tcp.acquire(), "место занято подключением другого транспорта")
+	assert.Equal(t, 1, shared.stats().Current)
+
+	kcp.release()
+	require.True(t, tcp.acquire())
+	assert.Equal(t, 1, kcp.stats().Max)
+	assert.Equal(t, 1, shared.stats().Peak)
+}
@@ -0,0 +1,67 @@
+package network
+
+import (
+	"log"
+
+	"github.com/annel0/mmo-game/internal/protocol"
+)
+
+// despawnEntity оповещает всех игроков об удалении сущности с причиной reason
+func (gh *GameHandlerPB) despawnEntity(entityID uint64, reason protocol.DespawnReason) {
+	gh.broadcastMessage(protocol.MessageType_ENTITY_DESPAWN, protocol.NewEntityDespawnMessage(entityID, reason))
+}
+
+// Коды SERVER_MESSAGE при отключении сервером (см. также ServerMessageIdleKick)
+const (
+	ServerMessageKicked            = "kicked"             // Отключён администратором
+	ServerMessageProtocolViolation = "protocol_violation" // Поток некорректных сообщений
+)
+
+// disconnectClient отключает клиента по инициативе сервера (см.
+// NetworkSender.Disconnect). Клиент получает SERVER_MESSAGE с причиной,
+// другие игроки - EntityDespawn с reason.Despawn. Вызывается без gh.mu
+func (gh *GameHandlerPB) disconnectClient(connID string, reason DisconnectReason) {
+	if gh.sender == nil {
+		return
+	}
+
+	gh.mu.Lock()
+	gh.disconnectReasons[connID] = reason.Despawn
+	gh.mu.Unlock()
+
+	gh.sender.Disconnect(connID, reason)
+}
+
+// takeDisconnectReasonLocked возвращает и забывает причину отключения соединения
+// (DESPAWN_REASON_DISCONNECTED, если клиент отключился сам). Вызывается под gh.mu
+func (gh *GameHandlerPB) takeDisconnectReasonLocked(connID string) protocol.DespawnReason {
+	reason, ok := gh.disconnectReasons[connID]
+	if !ok {
+		return protocol.DespawnReason_DESPAWN_REASON_DISCONNECTED
+	}
+	delete(gh.disconnectReasons, connID)
+	return reason
+}
+
+// KickPlayer отключает все соединения пользователя по решению администратора.
+// Возвращает false, если пользователь не в игре
+func (gh *GameHandlerPB) KickPlayer(userID uint64) bool {
+	gh.mu.RLock()
+	var conns []string
+	for connID, session := range gh.sessions {
+		if session.UserID == userID {
+			conns = append(conns, connID)
+		}
+	}
+	gh.mu.RUnlock()
+
+	for _, connID := range conns {
+		log.Printf("🚫 Отключение %s (пользователь %d) администратором", connID, userID)
+		gh.disconnectClient(connID, DisconnectReason{
+			Code:    ServerMessageKicked,
+			Message: "Отключено администратором",
+			Despawn: protocol.DespawnReason_DESPAWN_REASON_KICKED,
+		})
+	}
+	return len(conns) > 0
+}
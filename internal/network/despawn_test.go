@@ -0,0 +1,142 @@
+package network
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/protocol"
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/annel0/mmo-game/internal/world/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+// despawnRecorder запоминает разосланные EntityDespawn и, как настоящий
+// сервер, вызывает OnClientDisconnect при принудительном отключении
+type despawnRecorder struct {
+	gh       *GameHandlerPB
+	mu       sync.Mutex
+	despawns []*protocol.EntityDespawnMessage
+}
+
+func (r *despawnRecorder) SendToClient(_ string, msgType protocol.MessageType, payload proto.Message) {
+	r.Broadcast(msgType, payload)
+}
+
+func (r *despawnRecorder) Broadcast(msgType protocol.MessageType, payload proto.Message) {
+	if msgType != protocol.MessageType_ENTITY_DESPAWN {
+		return
+	}
+	r.mu.Lock()
+	r.despawns = append(r.despawns, payload.(*protocol.EntityDespawnMessage))
+	r.mu.Unlock()
+}
+
+func (*despawnRecorder) ConnectionIDs() []string   { return nil }
+func (*despawnRecorder) BindPlayer(string, uint64) {}
+func (r *despawnRecorder) Disconnect(connID string, _ DisconnectReason) {
+	r.gh.OnClientDisconnect(connID)
+}
+
+// reasons возвращает коды причин удаления сущности в порядке отправки
+func (r *despawnRecorder) reasons(t *testing.T, entityID uint64) []protocol.DespawnReason {
+	t.Helper()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var reasons []protocol.DespawnReason
+	for _, msg := range r.despawns {
+		if msg.EntityId == entityID {
+			assert.Equal(t, msg.ReasonCode.LegacyString(), msg.Reason, "строковая причина соответствует коду")
+			reasons = append(reasons, msg.ReasonCode)
+		}
+	}
+	return reasons
+}
+
+func newDespawnTestHandler(t *testing.T) (*GameHandlerPB, *despawnRecorder) {
+	t.Helper()
+	gh := newTestGameHandler(t)
+	recorder := &despawnRecorder{gh: gh}
+	gh.SetNetworkSender(recorder)
+	return gh, recorder
+}
+
+func TestDespawnReason_DisconnectPaths(t *testing.T) {
+	gh, recorder := newDespawnTestHandler(t)
+	gh.SetMaxOversizedMessages(1)
+
+	addTestSession(gh, "quit", 1, 101, vec.Vec2{})
+	addTestSession(gh, "idle", 2, 102, vec.Vec2{})
+	addTestSession(gh, "flood", 3, 103, vec.Vec2{})
+	addTestSession(gh, "admin-kick", 4, 104, vec.Vec2{})
+
+	// Клиент отключился сам: ровно одно сообщение, без прежнего дубля "deleted"
+	gh.OnClientDisconnect("quit")
+	assert.Equal(t, []protocol.DespawnReason{protocol.DespawnReason_DESPAWN_REASON_DISCONNECTED}, recorder.reasons(t, 101))
+
+	// Отключение по бездействию
+	gh.SetIdleTimeout(time.Minute, 0)
+	gh.mu.Lock()
+	gh.sessions["idle"].LastActivity = gh.now().Add(-time.Hour)
+	gh.mu.Unlock()
+	gh.checkIdlePlayers()
+	assert.Equal(t, []protocol.DespawnReason{protocol.DespawnReason_DESPAWN_REASON_TIMEOUT}, recorder.reasons(t, 102))
+
+	// Отключение за слишком большие сообщения
+	gh.rejectOversized("flood", &protocol.PayloadTooLargeError{Size: 10, Limit: 1})
+	assert.Equal(t, []protocol.DespawnReason{protocol.DespawnReason_DESPAWN_REASON_KICKED}, recorder.reasons(t, 103))
+
+	// Отключение администратором
+	require.True(t, gh.KickPlayer(4))
+	assert.False(t, gh.KickPlayer(4), "игрок уже не в игре")
+	assert.Equal(t, []protocol.DespawnReason{protocol.DespawnReason_DESPAWN_REASON_KICKED}, recorder.reasons(t, 104))
+
+	gh.mu.RLock()
+	assert.Empty(t, gh.disconnectReasons)
+	gh.mu.RUnlock()
+}
+
+func TestDespawnReason_EntityPaths(t *testing.T) {
+	gh, recorder := newDespawnTestHandler(t)
+	gh.entityManager.RegisterBehavior(entity.EntityTypeAnimal, entity.NewAnimalBehavior(entity.AnimalTypeCow))
+	addTestSession(gh, "player", 1, 100, vec.Vec2{})
+
+	// Подбор предмета убирает его из мира
+	item := gh.SpawnEntity(entity.EntityTypeItem, vec.Vec2{X: 1})
+	success, _, _ := gh.processEntityAction(100, &protocol.EntityActionRequest{
+		ActionType: protocol.EntityActionType_ACTION_PICKUP,
+		TargetId:   &item,
+	})
+	require.True(t, success)
+	_, exists := gh.entityManager.GetEntity(item)
+	assert.False(t, exists)
+	assert.Equal(t, []protocol.DespawnReason{protocol.DespawnReason_DESPAWN_REASON_PICKED_UP}, recorder.reasons(t, item))
+
+	// Гибель животного
+	cow := gh.SpawnEntity(entity.EntityTypeAnimal, vec.Vec2{X: 1})
+	target, ok := gh.entityManager.GetEntity(cow)
+	require.True(t, ok)
+	target.Payload["health"] = 5
+	gh.processEntityAction(100, &protocol.EntityActionRequest{
+		ActionType: protocol.EntityActionType_ACTION_ATTACK,
+		TargetId:   &cow,
+	})
+	_, exists = gh.entityManager.GetEntity(cow)
+	assert.False(t, exists)
+	assert.Equal(t, []protocol.DespawnReason{protocol.DespawnReason_DESPAWN_REASON_DEATH}, recorder.reasons(t, cow))
+
+	// Сущности, удаляемые вместе с отключившимся владельцем
+	projectile := gh.SpawnEntity(entity.EntityTypeProjectile, vec.Vec2{X: 2})
+	require.True(t, gh.ClaimEntity(projectile, 100, entity.OwnerDespawn))
+	gh.OnClientDisconnect("player")
+	assert.Equal(t, []protocol.DespawnReason{protocol.DespawnReason_DESPAWN_REASON_OWNER_LEFT}, recorder.reasons(t, projectile))
+	assert.Equal(t, []protocol.DespawnReason{protocol.DespawnReason_DESPAWN_REASON_DISCONNECTED}, recorder.reasons(t, 100))
+
+	// Прямое удаление через EntityAPI
+	other := gh.SpawnEntity(entity.EntityTypeItem, vec.Vec2{X: 3})
+	gh.DespawnEntity(other)
+	assert.Equal(t, []protocol.DespawnReason{protocol.DespawnReason_DESPAWN_REASON_REMOVED}, recorder.reasons(t, other))
+}
@@ -0,0 +1,41 @@
+package network
+
+import (
+	"context"
+	"testing"
+
+	"github.com/annel0/mmo-game/internal/protocol"
+	"github.com/annel0/mmo-game/internal/storage"
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKickPlayer_SavesPositionAndNotifiesBeforeClose(t *testing.T) {
+	gh := newTestGameHandler(t)
+	repo := storage.NewMemoryPositionRepo()
+	gh.SetPositionRepo(repo)
+
+	client := connectTestClient(t, gh, "conn-1")
+	addTestSession(gh, "conn-1", 42, 7, vec.Vec2{X: 3, Y: 4})
+
+	require.True(t, gh.KickPlayer(42))
+
+	// Клиент получает причину до закрытия соединения
+	var notice protocol.ServerMessage
+	client.expect(t, protocol.MessageType_SERVER_MESSAGE, &notice)
+	assert.Equal(t, ServerMessageKicked, notice.Code)
+	assert.NotEmpty(t, notice.Message)
+
+	// Позиция сохранена и сессия закрыта к возврату из KickPlayer
+	pos, found, err := repo.Load(context.Background(), 42)
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.Equal(t, vec.Vec3{X: 3, Y: 4, Z: 1}, pos)
+
+	assert.False(t, gh.IsSessionValid("conn-1"))
+	gh.tcpServer.mu.RLock()
+	_, connected := gh.tcpServer.connections["conn-1"]
+	gh.tcpServer.mu.RUnlock()
+	assert.False(t, connected)
+}
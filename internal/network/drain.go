@@ -0,0 +1,95 @@
+package network
+
+import (
+	"log"
+	"time"
+)
+
+// ServerMessageDraining - ответ на вход, пока сервер выводится из работы
+const ServerMessageDraining = "Server draining, reconnect elsewhere"
+
+// drainState - режим вывода сервера из работы (защищён gh.mu)
+type drainState struct {
+	active    bool
+	startedAt time.Time
+	deadline  time.Time // Нулевое значение - без крайнего срока
+}
+
+// DrainStatus - ход вывода сервера из работы
+type DrainStatus struct {
+	Draining         bool       `json:"draining"`
+	StartedAt        *time.Time `json:"started_at,omitempty"`
+	Deadline         *time.Time `json:"deadline,omitempty"`
+	RemainingPlayers int        `json:"remaining_players"`
+	// SafeToShutdown - игроков не осталось или крайний срок истёк
+	SafeToShutdown bool `json:"safe_to_shutdown"`
+}
+
+// StartDrain переводит сервер в режим вывода из работы: новые входы
+// отклоняются, подключённые игроки продолжают играть. timeout > 0 задаёт
+// крайний срок, после которого сервер можно останавливать с игроками.
+// Повторный вызов обновляет крайний срок, не сбрасывая время начала
+func (gh *GameHandlerPB) StartDrain(timeout time.Duration) DrainStatus {
+	now := gh.now()
+
+	gh.mu.Lock()
+	if !gh.drain.active {
+		gh.drain = drainState{active: true, startedAt: now}
+	}
+	gh.drain.deadline = time.Time{}
+	if timeout > 0 {
+		gh.drain.deadline = now.Add(timeout)
+	}
+	status := gh.drainStatusLocked(now)
+	gh.mu.Unlock()
+
+	log.Printf("🚧 Сервер выводится из работы: новые входы отклоняются, осталось игроков: %d", status.RemainingPlayers)
+	return status
+}
+
+// StopDrain отменяет вывод из работы: сервер снова принимает игроков
+func (gh *GameHandlerPB) StopDrain() DrainStatus {
+	gh.mu.Lock()
+	gh.drain = drainState{}
+	status := gh.drainStatusLocked(gh.now())
+	gh.mu.Unlock()
+
+	log.Printf("✅ Вывод сервера из работы отменён")
+	return status
+}
+
+// DrainStatus возвращает ход вывода сервера из работы
+func (gh *GameHandlerPB) DrainStatus() DrainStatus {
+	gh.mu.RLock()
+	defer gh.mu.RUnlock()
+	return gh.drainStatusLocked(gh.now())
+}
+
+// isDraining сообщает, отклоняются ли новые входы
+func (gh *GameHandlerPB) isDraining() bool {
+	gh.mu.RLock()
+	defer gh.mu.RUnlock()
+	return gh.drain.active
+}
+
+// drainStatusLocked собирает DrainStatus. Вызывается под gh.mu
+func (gh *GameHandlerPB) drainStatusLocked(now time.Time) DrainStatus {
+	status := DrainStatus{
+		Draining:         gh.drain.active,
+		RemainingPlayers: len(gh.sessions),
+	}
+	if !gh.drain.active {
+		return status
+	}
+
+	startedAt := gh.drain.startedAt
+	status.StartedAt = &startedAt
+	expired := false
+	if !gh.drain.deadline.IsZero() {
+		deadline := gh.drain.deadline
+		status.Deadline = &deadline
+		expired = !now.Before(deadline)
+	}
+	status.SafeToShutdown = status.RemainingPlayers == 0 || expired
+	return status
+}
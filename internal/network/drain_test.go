@@ -0,0 +1,69 @@
+package network
+
+import (
+	"testing"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDrain_RejectsNewAuthAndKeepsPlayers(t *testing.T) {
+	gh := newTestGameHandler(t)
+	gh.entityManager.RegisterDefaultBehaviors()
+
+	first := connectTestClient(t, gh, "conn-1")
+	authTestClient(t, gh, first)
+
+	status := gh.StartDrain(0)
+	assert.True(t, status.Draining)
+	assert.Equal(t, 1, status.RemainingPlayers)
+	assert.False(t, status.SafeToShutdown)
+
+	// Новый вход отклоняется
+	second := connectTestClient(t, gh, "conn-2")
+	password := "ChangeMe123!"
+	gh.HandleMessage("conn-2", newGameMessage(t, protocol.MessageType_AUTH, &protocol.AuthMessage{
+		Username:        "admin",
+		Password:        &password,
+		ProtocolVersion: ProtocolVersion,
+	}))
+	resp := &protocol.AuthResponseMessage{}
+	second.expect(t, protocol.MessageType_AUTH_RESPONSE, resp)
+	assert.False(t, resp.Success)
+	assert.Equal(t, ServerMessageDraining, resp.Message)
+	assert.False(t, gh.IsSessionValid("conn-2"))
+
+	// Подключённый игрок продолжает играть
+	assert.True(t, gh.IsSessionValid("conn-1"))
+
+	gh.OnClientDisconnect("conn-1")
+	status = gh.DrainStatus()
+	assert.Equal(t, 0, status.RemainingPlayers)
+	assert.True(t, status.SafeToShutdown, "игроков не осталось")
+
+	// После отмены вход снова разрешён
+	gh.StopDrain()
+	authTestClient(t, gh, second)
+}
+
+func TestDrain_DeadlineMakesShutdownSafe(t *testing.T) {
+	gh := newTestGameHandler(t)
+	gh.entityManager.RegisterDefaultBehaviors()
+	now := time.Now()
+	gh.now = func() time.Time { return now }
+
+	client := connectTestClient(t, gh, "conn-1")
+	authTestClient(t, gh, client)
+
+	status := gh.StartDrain(time.Minute)
+	require.NotNil(t, status.Deadline)
+	assert.Equal(t, now.Add(time.Minute), *status.Deadline)
+	assert.False(t, status.SafeToShutdown)
+
+	now = now.Add(61 * time.Second)
+	status = gh.DrainStatus()
+	assert.Equal(t, 1, status.RemainingPlayers)
+	assert.True(t, status.SafeToShutdown, "крайний срок истёк")
+}
@@ -0,0 +1,111 @@
+package network
+
+import (
+	"sort"
+
+	"github.com/annel0/mmo-game/internal/protocol"
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// DefaultMaxEntitiesPerUpdate - сущностей в одном ENTITY_MOVE обновления мира
+	DefaultMaxEntitiesPerUpdate = 64
+	// DefaultMaxEntityUpdateMessages - ENTITY_MOVE одному игроку за обновление мира
+	DefaultMaxEntityUpdateMessages = 8
+)
+
+var deferredEntityUpdates = prometheus.NewCounter(prometheus.CounterOpts{
+	Namespace: "network",
+	Name:      "entity_updates_deferred_total",
+	Help:      "Сущности, не попавшие в обновление мира из-за лимита сообщений: их позиция уйдёт в следующих обновлениях.",
+})
+
+func init() {
+	prometheus.MustRegister(deferredEntityUpdates)
+}
+
+// entityUpdateLimits ограничивает ENTITY_MOVE обновления мира, под gh.mu.
+// 0 - без ограничения. Отложенные сущности получают приоритет в следующих
+// обновлениях (см. sortByPriority)
+type entityUpdateLimits struct {
+	perMessage  int // Сущностей в одном сообщении
+	maxMessages int // Сообщений одному игроку за обновление
+}
+
+// SetEntityUpdateLimits задаёт, сколько сущностей попадает в одно сообщение
+// ENTITY_MOVE обновления мира и сколько таких сообщений получает игрок за
+// обновление. Сущности рядом с игроком отправляются первыми, не вместившиеся
+// откладываются до следующего обновления. 0 - значения по умолчанию,
+// отрицательное значение - без ограничения
+func (gh *GameHandlerPB) SetEntityUpdateLimits(perMessage, maxMessages int) {
+	if perMessage == 0 {
+		perMessage = DefaultMaxEntitiesPerUpdate
+	}
+	if maxMessages == 0 {
+		maxMessages = DefaultMaxEntityUpdateMessages
+	}
+
+	gh.mu.Lock()
+	gh.entityUpdates = entityUpdateLimits{perMessage: max(perMessage, 0), maxMessages: max(maxMessages, 0)}
+	gh.mu.Unlock()
+}
+
+// split делит сущности на сообщения по лимитам. Возвращает сообщения и число
+// отложенных сущностей, не вместившихся в лимит сообщений
+func (l entityUpdateLimits) split(entities []*protocol.EntityData) ([][]*protocol.EntityData, int) {
+	if len(entities) == 0 {
+		return nil, 0
+	}
+	if l.perMessage <= 0 {
+		return [][]*protocol.EntityData{entities}, 0
+	}
+
+	var batches [][]*protocol.EntityData
+	for start := 0; start < len(entities); start += l.perMessage {
+		if l.maxMessages > 0 && len(batches) == l.maxMessages {
+			return batches, len(entities) - start
+		}
+		end := min(start+l.perMessage, len(entities))
+		batches = append(batches, entities[start:end])
+	}
+	return batches, 0
+}
+
+// sortByPriority упорядочивает сущности по приоритету отправки: от ближайшей
+// к origin, но каждое пропущенное подряд обновление (skipped) приближает
+// сущность в (1 + пропусков) раз, поэтому дальние сущности в толпе не
+// откладываются бесконечно. При равном приоритете - по ID, чтобы порядок не
+// менялся от тика к тику
+func sortByPriority(entities []*protocol.EntityData, origin vec.Vec2, skipped map[uint64]int) {
+	// Сравнение d²ᵢ/(1+sᵢ)² < d²ⱼ/(1+sⱼ)² без деления
+	distance := func(e *protocol.EntityData) int64 {
+		dx, dy := int64(e.Position.X)-int64(origin.X), int64(e.Position.Y)-int64(origin.Y)
+		return dx*dx + dy*dy
+	}
+	boost := func(e *protocol.EntityData) int64 {
+		b := int64(1 + skipped[e.Id])
+		return b * b
+	}
+	sort.SliceStable(entities, func(i, j int) bool {
+		pi, pj := distance(entities[i])*boost(entities[j]), distance(entities[j])*boost(entities[i])
+		if pi != pj {
+			return pi < pj
+		}
+		return entities[i].Id < entities[j].Id
+	})
+}
+
+// skippedAfter возвращает счётчики пропусков после обновления, в котором
+// отложены сущности deferred: отправленные сбрасываются, отложенные
+// увеличиваются на 1
+func skippedAfter(previous map[uint64]int, deferred []*protocol.EntityData) map[uint64]int {
+	if len(deferred) == 0 {
+		return nil
+	}
+	next := make(map[uint64]int, len(deferred))
+	for _, e := range deferred {
+		next[e.Id] = previous[e.Id] + 1
+	}
+	return next
+}
@@ -0,0 +1,144 @@
+package network
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/protocol"
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/annel0/mmo-game/internal/world/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+// collectEntityMoves вычитывает все ENTITY_MOVE, пришедшие клиенту
+func collectEntityMoves(t *testing.T, client *testClient) []*protocol.EntityMoveMessage {
+	t.Helper()
+
+	var moves []*protocol.EntityMoveMessage
+	for {
+		select {
+		case msg := <-client.messages:
+			if msg.Type != protocol.MessageType_ENTITY_MOVE {
+				continue
+			}
+			move := &protocol.EntityMoveMessage{}
+			require.NoError(t, proto.Unmarshal(msg.Payload, move))
+			moves = append(moves, move)
+		case <-time.After(200 * time.Millisecond):
+			return moves
+		}
+	}
+}
+
+func TestSendWorldUpdates_SplitsCrowdNearestFirst(t *testing.T) {
+	gh := newTestGameHandler(t)
+	gh.SetEntityUpdateLimits(100, 5)
+
+	// 1000 сущностей вокруг игрока в (0, 0), все в пределах дальности видимости
+	distance := func(p *protocol.Vec2) int { return int(p.X*p.X + p.Y*p.Y) }
+	var distances []int
+	for i := range 1000 {
+		pos := vec.Vec2{X: i%32 - 16, Y: i/32 - 16}
+		gh.spawnEntityWithID(entity.EntityTypeNPC, pos, uint64(1000+i))
+		distances = append(distances, pos.X*pos.X+pos.Y*pos.Y)
+	}
+	sort.Ints(distances)
+
+	client := connectTestClient(t, gh, "conn-1")
+	addTestSession(gh, "conn-1", 1, 1, vec.Vec2{X: 0, Y: 0})
+	client.drain(protocol.MessageType_ENTITY_MOVE)
+
+	gh.sendWorldUpdates()
+	moves := collectEntityMoves(t, client)
+	require.Len(t, moves, 5, "обновление делится на сообщения в пределах лимита")
+
+	last := -1
+	sent := make(map[uint64]bool)
+	for _, move := range moves {
+		assert.Len(t, move.Entities, 100)
+		for _, e := range move.Entities {
+			d := distance(e.Position)
+			assert.GreaterOrEqual(t, d, last, "сущности идут от ближайшей к дальней")
+			last = d
+			sent[e.Id] = true
+		}
+	}
+	assert.Len(t, sent, 500)
+	assert.LessOrEqual(t, last, distances[499], "отложены самые дальние сущности")
+
+	// Без ограничения всё уходит одним сообщением
+	gh.SetEntityUpdateLimits(-1, -1)
+	gh.sendWorldUpdates()
+	moves = collectEntityMoves(t, client)
+	require.Len(t, moves, 1)
+	assert.Len(t, moves[0].Entities, 1000)
+}
+
+func TestEntityUpdateLimits_Split(t *testing.T) {
+	entities := make([]*protocol.EntityData, 10)
+	for i := range entities {
+		entities[i] = &protocol.EntityData{Id: uint64(i)}
+	}
+
+	batches, deferred := entityUpdateLimits{perMessage: 4}.split(entities)
+	require.Len(t, batches, 3)
+	assert.Len(t, batches[2], 2)
+	assert.Zero(t, deferred)
+
+	batches, deferred = entityUpdateLimits{perMessage: 4, maxMessages: 2}.split(entities)
+	assert.Len(t, batches, 2)
+	assert.Equal(t, 2, deferred)
+
+	batches, _ = entityUpdateLimits{}.split(nil)
+	assert.Empty(t, batches)
+}
+
+func TestSendWorldUpdates_DeferredEntitiesNotStarved(t *testing.T) {
+	gh := newTestGameHandler(t)
+	gh.SetEntityUpdateLimits(100, 5)
+	for i := range 1000 {
+		gh.spawnEntityWithID(entity.EntityTypeNPC, vec.Vec2{X: i%32 - 16, Y: i/32 - 16}, uint64(1000+i))
+	}
+
+	client := connectTestClient(t, gh, "conn-1")
+	addTestSession(gh, "conn-1", 1, 1, vec.Vec2{X: 0, Y: 0})
+	client.drain(protocol.MessageType_ENTITY_MOVE)
+
+	// Толпа вдвое больше бюджета обновления: отложенные дальние сущности
+	// поднимаются в очереди и за несколько обновлений доходят до клиента
+	sent := make(map[uint64]bool)
+	for range 4 {
+		gh.sendWorldUpdates()
+		for _, move := range collectEntityMoves(t, client) {
+			for _, e := range move.Entities {
+				sent[e.Id] = true
+			}
+		}
+	}
+	assert.Len(t, sent, 1000, "каждая сущность попадает в обновления")
+
+	// Отключение забывает счётчики пропусков
+	gh.OnClientDisconnect("conn-1")
+	gh.mu.RLock()
+	assert.NotContains(t, gh.skippedEntities, "conn-1")
+	gh.mu.RUnlock()
+}
+
+func TestSortByPriority_SkippedEntitiesMoveUp(t *testing.T) {
+	near := &protocol.EntityData{Id: 1, Position: &protocol.Vec2{X: 2}}
+	far := &protocol.EntityData{Id: 2, Position: &protocol.Vec2{X: 5}}
+
+	entities := []*protocol.EntityData{far, near}
+	sortByPriority(entities, vec.Vec2{}, nil)
+	assert.Equal(t, []*protocol.EntityData{near, far}, entities)
+
+	// Дважды отложенная дальняя сущность обгоняет ближнюю: 25/9 < 4
+	sortByPriority(entities, vec.Vec2{}, map[uint64]int{far.Id: 2})
+	assert.Equal(t, []*protocol.EntityData{far, near}, entities)
+
+	next := skippedAfter(map[uint64]int{far.Id: 2, near.Id: 1}, []*protocol.EntityData{near})
+	assert.Equal(t, map[uint64]int{near.Id: 2}, next, "отправленные сбрасываются, отложенные копят пропуски")
+}
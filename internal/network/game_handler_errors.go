@@ -0,0 +1,16 @@
+package network
+
+import (
+	"github.com/annel0/mmo-game/internal/protocol"
+)
+
+// sendError сообщает клиенту, почему его запрос отклонён (сообщение ERROR).
+// Клиенты, не знающие этот тип, просто пропускают его, поэтому прежние ответы
+// (например, BlockUpdateResponse с Success=false) продолжают отправляться как раньше.
+func (gh *GameHandlerPB) sendError(connID string, code protocol.ErrorCode, refType protocol.MessageType, message string) {
+	gh.sendTCPMessage(connID, protocol.MessageType_ERROR, &protocol.ErrorMessage{
+		Code:    code,
+		Message: message,
+		RefType: refType,
+	})
+}
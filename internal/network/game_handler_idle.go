@@ -0,0 +1,108 @@
+package network
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/protocol"
+)
+
+const (
+	// DefaultIdleTimeout - время без игровых действий, после которого игрок отключается
+	DefaultIdleTimeout = 15 * time.Minute
+	// DefaultIdleWarning - за сколько до отключения игрок получает предупреждение
+	DefaultIdleWarning = 1 * time.Minute
+
+	// Коды служебных сообщений для неактивных игроков
+	ServerMessageIdleWarning = "idle_warning"
+	ServerMessageIdleKick    = "idle_kick"
+)
+
+// SetIdleTimeout настраивает отключение неактивных игроков.
+// timeout == 0 отключает проверку; warning - интервал до отключения,
+// за который игроку отправляется предупреждение (0 - без предупреждения).
+func (gh *GameHandlerPB) SetIdleTimeout(timeout, warning time.Duration) {
+	if warning >= timeout {
+		warning = 0
+	}
+
+	gh.mu.Lock()
+	gh.idleTimeout = timeout
+	gh.idleWarning = warning
+	gh.mu.Unlock()
+}
+
+// isGameplayMessage определяет, считается ли сообщение игровой активностью.
+// Пинги и служебные запросы не продлевают сессию неактивного игрока.
+func isGameplayMessage(msgType protocol.MessageType) bool {
+	switch msgType {
+	case protocol.MessageType_BLOCK_UPDATE,
+		protocol.MessageType_ENTITY_ACTION,
+		protocol.MessageType_ENTITY_MOVE,
+		protocol.MessageType_CHAT,
+		protocol.MessageType_TRADE_REQUEST:
+		return true
+	default:
+		return false
+	}
+}
+
+// touchActivity отмечает игровую активность игрока и снимает флаг предупреждения
+func (gh *GameHandlerPB) touchActivity(connID string) {
+	gh.mu.Lock()
+	if session, ok := gh.sessions[connID]; ok {
+		session.LastActivity = gh.now()
+		session.idleWarned = false
+	}
+	gh.mu.Unlock()
+}
+
+// checkIdlePlayers предупреждает и отключает игроков без игровой активности.
+// Не зависит от проверки «здоровья» соединения: пинги активностью не считаются.
+func (gh *GameHandlerPB) checkIdlePlayers() {
+	now := gh.now()
+
+	var toWarn, toKick []string
+
+	gh.mu.Lock()
+	if gh.idleTimeout <= 0 {
+		gh.mu.Unlock()
+		return
+	}
+	for connID, session := range gh.sessions {
+		idle := now.Sub(session.LastActivity)
+		switch {
+		case idle >= gh.idleTimeout:
+			toKick = append(toKick, connID)
+		case gh.idleWarning > 0 && !session.idleWarned && idle >= gh.idleTimeout-gh.idleWarning:
+			session.idleWarned = true
+			toWarn = append(toWarn, connID)
+		}
+	}
+	warning := gh.idleWarning
+	gh.mu.Unlock()
+
+	for _, connID := range toWarn {
+		gh.sendServerMessage(connID, ServerMessageIdleWarning,
+			fmt.Sprintf("Вы будете отключены за неактивность через %d сек.", int(warning.Seconds())))
+	}
+
+	for _, connID := range toKick {
+		log.Printf("💤 Отключение неактивного игрока %s", connID)
+		gh.disconnectClient(connID, DisconnectReason{
+			Code:    ServerMessageIdleKick,
+			Message: "Отключено за неактивность",
+			Despawn: protocol.DespawnReason_DESPAWN_REASON_TIMEOUT,
+		})
+	}
+}
+
+// sendServerMessage отправляет игроку служебное уведомление SERVER_MESSAGE
+func (gh *GameHandlerPB) sendServerMessage(connID, code, text string) {
+	gh.sendTCPMessage(connID, protocol.MessageType_SERVER_MESSAGE, &protocol.ServerMessage{
+		Code:      code,
+		Message:   text,
+		Timestamp: gh.now().UnixNano(),
+	})
+}
@@ -3,18 +3,22 @@ package network
 import (
 	"context"
 	"encoding/binary"
-	"encoding/json"
 	"fmt"
 	"hash/crc32"
 	"log"
 	"math"
 	"math/rand"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/annel0/mmo-game/internal/anticheat"
 	"github.com/annel0/mmo-game/internal/auth"
+	"github.com/annel0/mmo-game/internal/eventbus"
 	"github.com/annel0/mmo-game/internal/protocol"
 	"github.com/annel0/mmo-game/internal/storage"
+	"github.com/annel0/mmo-game/internal/trade"
 	"github.com/annel0/mmo-game/internal/vec"
 	"github.com/annel0/mmo-game/internal/world"
 	"github.com/annel0/mmo-game/internal/world/block"
@@ -25,6 +29,9 @@ import (
 // Размер чанка в блоках
 const ChunkSize = 16
 
+// DefaultMaxReachDistance - максимальная дистанция взаимодействия с блоками по умолчанию
+const DefaultMaxReachDistance = 10.0
+
 // GameHandlerPB обрабатывает сообщения Protocol Buffers
 type GameHandlerPB struct {
 	worldManager  *world.WorldManager
@@ -33,20 +40,118 @@ type GameHandlerPB struct {
 	gameAuth      *auth.GameAuthenticator
 	positionRepo  storage.PositionRepo // Репозиторий позиций игроков
 
+	playerStateRepo storage.PlayerStateRepo // Репозиторий прогресса игроков (уровень, опыт, эффекты)
+
+	protocolRange   ProtocolVersionRange // Поддерживаемые версии протокола клиентов
+	maxViewDistance int                  // Максимальная дальность видимости в чанках
+	maxReach        float64              // Максимальная дистанция взаимодействия с блоками
+
+	// Режимы игры (см. game_mode.go)
+	defaultGameMode GameMode
+	gameModes       map[GameMode]GameModeRules
+
+	voidRules VoidRules // Игроки над пропастью (см. void.go)
+
+	// Время добычи блоков (см. mining.go), под gh.mu
+	miningRules MiningRules
+	mining      map[string]*miningProgress // connID -> добываемый блок
+
+	// Предметы на земле (см. item_drops.go), под gh.mu
+	itemDropRules ItemDropRules
+	droppedItems  map[uint64]*droppedItem // entityID -> стопка
+
 	tcpServer *TCPServerPB
+	sender    NetworkSender // Исходящие сообщения (по умолчанию tcpServer)
 	udpServer *UDPServerPB
 
 	playerEntities map[string]uint64   // connID -> entityID
 	sessions       map[string]*Session // connID -> session
 
-	serializer   *protocol.MessageSerializer
-	lastEntityID uint64
-	mu           sync.RWMutex
+	serializer *protocol.MessageSerializer
+	mu         sync.RWMutex // Не удерживается при сетевой записи (см. lock_order.go)
 
 	// Оптимизация частоты обновлений
 	tickCounter         int     // Счетчик тиков
 	worldUpdateInterval int     // Интервал обновлений в тиках (20 тиков = 1 сек при 20 TPS)
 	lastUpdateTime      float64 // Время последнего обновления
+
+	// Автосохранение позиций и прогресса игроков
+	autoSaveInterval time.Duration
+	lastAutoSave     time.Time
+
+	// Отложенные до переподключения сохранения позиций (см. position_grace.go), под gh.mu
+	reconnectSaveGrace   time.Duration
+	pendingPositionSaves map[uint64]*pendingPositionSave // userID -> позиция
+
+	// Лимиты ENTITY_MOVE обновления мира (см. entity_update_limits.go), под gh.mu
+	entityUpdates entityUpdateLimits
+	// Сколько обновлений подряд сущность откладывалась для соединения, под gh.mu
+	skippedEntities map[string]map[uint64]int
+
+	// Отключение неактивных игроков
+	idleTimeout time.Duration    // Время без игровых действий до отключения (0 - выключено)
+	idleWarning time.Duration    // За сколько до отключения отправляется предупреждение
+	now         func() time.Time // Источник времени (подменяется в тестах)
+
+	// Метка времени в ENTITY_MOVE (см. move_timestamp.go)
+	startedAt         time.Time   // Начало отсчёта server_time_ms
+	moveTimestampsOff atomic.Bool // Метка отключена ради трафика
+
+	// Контексты соединений и предел обработки сообщения (см. conn_context.go)
+	conns connContexts
+
+	// Время обработки сообщений и предупреждения о медленных обработчиках (см. slow_handler.go)
+	slowHandlers slowHandlerDetector
+
+	// Фоновая отправка чанков игрокам
+	chunkStreams map[string]*chunkStream // connID -> активная отправка
+	streamsMu    sync.Mutex
+
+	// Чанки, уже доставленные клиентам (см. known_chunks.go)
+	knownChunks map[string]*knownChunkSet // connID -> чанки клиента
+	knownMu     sync.Mutex
+
+	// Сериализация запрошенных чанков (nil - в горутине обработчика)
+	chunkPool   *chunkWorkerPool
+	chunkPoolMu sync.RWMutex
+
+	// Вывод сервера из работы (см. drain.go)
+	drain drainState
+
+	// Накопление изменений блоков перед рассылкой (см. block_updates.go)
+	blockUpdates blockUpdateBuffer
+
+	// Ограничение запросов чанков (см. chunk_request_limit.go)
+	chunkRequests chunkRequestLimits
+
+	// Регионы многорегионального развёртывания (см. SetRegionRouting)
+	regions regionRouting
+
+	// Сущности с активными статус-эффектами (см. status_effects.go)
+	affected  map[uint64]struct{}
+	effectsMu sync.Mutex
+
+	// Сглаживание ввода движения (см. move_input.go)
+	moveInputs     map[string]*moveInputBuffer // connID -> очередь шагов
+	moveInputDepth int                         // Глубина буфера в тиках (0 - ввод применяется сразу)
+	moveInputsMu   sync.Mutex
+
+	// Защита от слишком больших сообщений
+	oversizedMessages    map[string]int // connID -> число отклонённых сообщений
+	maxOversizedMessages int            // Порог отключения (0 - не отключать)
+
+	// Защита от сообщений неизвестного типа (см. unknown_message_guard.go)
+	unknownMessages    map[string]int // connID -> число сообщений неизвестного типа
+	maxUnknownMessages int            // Порог отключения (0 - не отключать)
+
+	// Причины отключений по инициативе сервера (см. despawn.go), под gh.mu
+	disconnectReasons map[string]protocol.DespawnReason
+
+	trades     *trade.Manager    // Сделки между игроками (эскроу предметов)
+	anticheat  *anticheat.Engine // Обнаружение нарушений (скорость, дистанция, частота правок)
+	blockEdits blockEditLimit    // Предел правок блоков из SetRuntimeParams (см. runtime_params.go)
+
+	publish func(ctx context.Context, ev *eventbus.Envelope) error // Публикация событий мира (см. world_events.go)
 }
 
 // Session stores authenticated player data for the lifetime of a TCP connection.
@@ -58,6 +163,25 @@ type Session struct {
 	Username string
 	Token    string
 	IsAdmin  bool
+	Stealth  bool // Администратор скрыт от обычных игроков (см. SetStealth)
+
+	Spectator bool // Режим наблюдателя: без коллизий и проверки скорости (см. SetSpectator)
+
+	GameMode GameMode // Режим игры (пусто - режим по умолчанию, см. SetGameMode)
+
+	ViewDistance int // Дальность видимости в чанках, согласованная при авторизации
+
+	LastActivity time.Time // Время последнего игрового действия
+	idleWarned   bool      // Предупреждение о неактивности уже отправлено
+
+	borderWarned bool // Игрок в зоне предупреждения у границы мира и уже предупреждён
+
+	positionVersion uint64 // Версия владения позицией, полученная этим узлом при входе
+
+	legacyWorldState bool // Клиент не знает WORLD_STATE и ждёт состояние мира в CHUNK_DATA
+
+	LastProcessedInput uint32 // sequence последнего обработанного ENTITY_MOVE клиента
+	reconciledInput    uint32 // LastProcessedInput, уже отправленный клиенту в обновлении мира
 }
 
 // NewGameHandlerPB создает новый обработчик для Protocol Buffers
@@ -68,25 +192,84 @@ func NewGameHandlerPB(worldManager *world.WorldManager, entityManager *entity.En
 		userRepo:       userRepo,
 		playerEntities: make(map[string]uint64),
 		sessions:       make(map[string]*Session),
-
-		serializer:   createMessageSerializer(),
-		lastEntityID: 0,
+		chunkStreams:   make(map[string]*chunkStream),
+		knownChunks:    make(map[string]*knownChunkSet),
+		moveInputs:     make(map[string]*moveInputBuffer),
+
+		oversizedMessages:    make(map[string]int),
+		maxOversizedMessages: DefaultMaxOversizedMessages,
+		unknownMessages:      make(map[string]int),
+		maxUnknownMessages:   DefaultMaxUnknownMessages,
+		disconnectReasons:    make(map[string]protocol.DespawnReason),
+
+		serializer:      createMessageSerializer(),
+		protocolRange:   DefaultProtocolVersionRange(),
+		maxViewDistance: DefaultMaxViewDistance,
+		maxReach:        DefaultMaxReachDistance,
+		defaultGameMode: DefaultGameMode,
+		gameModes:       DefaultGameModeRules(),
+		voidRules:       DefaultVoidRules(),
+		miningRules:     DefaultMiningRules(),
+		mining:          make(map[string]*miningProgress),
+		itemDropRules:   DefaultItemDropRules(),
+		droppedItems:    make(map[uint64]*droppedItem),
+		publish:         eventbus.Publish,
 
 		// Инициализация оптимизации
 		tickCounter:         0,
-		worldUpdateInterval: 2, // Обновления каждые 10 тиков = 2 раза в секунду при 20 TPS
+		worldUpdateInterval: DefaultWorldUpdateInterval,
 		lastUpdateTime:      0,
+
+		idleTimeout: DefaultIdleTimeout,
+		idleWarning: DefaultIdleWarning,
+		now:         time.Now,
+		startedAt:   time.Now(),
+
+		autoSaveInterval: DefaultAutoSaveInterval,
+		lastAutoSave:     time.Now(),
+
+		reconnectSaveGrace: DefaultReconnectSaveGrace,
+		entityUpdates: entityUpdateLimits{
+			perMessage:  DefaultMaxEntitiesPerUpdate,
+			maxMessages: DefaultMaxEntityUpdateMessages,
+		},
+		skippedEntities: make(map[string]map[uint64]int),
+
+		blockUpdates: blockUpdateBuffer{window: DefaultBlockUpdateWindow},
+		chunkRequests: chunkRequestLimits{
+			rate:         DefaultChunkRequestRate,
+			resendWindow: DefaultChunkResendWindow,
+			byConn:       make(map[string]*chunkRequestBucket),
+		},
+		conns:    connContexts{byConn: make(map[string]connContext), closed: make(map[string]time.Time), timeout: DefaultMessageTimeout},
+		affected: make(map[uint64]struct{}),
+		slowHandlers: slowHandlerDetector{
+			threshold:  DefaultSlowHandlerThreshold,
+			lastWarn:   make(map[protocol.MessageType]time.Time),
+			suppressed: make(map[protocol.MessageType]int),
+		},
 	}
 
 	// Устанавливаем обработчик как сетевой менеджер для мира
 	worldManager.SetNetworkManager(handler)
 
+	// Мир и менеджер сущностей выдают ID из одного источника
+	entityManager.SetIDAllocator(worldManager.EntityIDAllocator())
+
+	// Игроки живут в менеджере сущностей: по нему BigChunk выбирают частоту тиков
+	worldManager.SetEntitySource(entityManager)
+
+	handler.chunkPool = newChunkWorkerPool(0, handler.deliverChunk)
+	handler.trades = trade.NewManager(handler.playerInventory)
+	handler.anticheat = anticheat.NewEngine(anticheat.Config{MaxReach: DefaultMaxReachDistance})
+
 	return handler
 }
 
 // SetTCPServer устанавливает TCP сервер
 func (gh *GameHandlerPB) SetTCPServer(server *TCPServerPB) {
 	gh.tcpServer = server
+	gh.sender = tcpSender{server: server}
 }
 
 // SetUDPServer устанавливает UDP сервер
@@ -99,11 +282,47 @@ func (gh *GameHandlerPB) SetGameAuthenticator(gameAuth *auth.GameAuthenticator)
 	gh.gameAuth = gameAuth
 }
 
+// SetAuthProvider задаёт проверку учётных данных игроков (пароль, токен OAuth)
+func (gh *GameHandlerPB) SetAuthProvider(provider auth.AuthProvider) {
+	if gh.gameAuth != nil {
+		gh.gameAuth = gh.gameAuth.WithProvider(provider)
+	}
+}
+
 // SetPositionRepo устанавливает репозиторий позиций
 func (gh *GameHandlerPB) SetPositionRepo(positionRepo storage.PositionRepo) {
 	gh.positionRepo = positionRepo
 }
 
+// SetPlayerStore подключает общие с REST API хранилища игроков. Аутентификатор
+// переключается на общий репозиторий пользователей с прежним секретом JWT
+func (gh *GameHandlerPB) SetPlayerStore(store *storage.PlayerStore) {
+	gh.userRepo = store.Users
+	if gh.gameAuth != nil {
+		gh.gameAuth = gh.gameAuth.WithUserRepository(store.Users)
+	}
+	gh.SetPositionRepo(store.Positions)
+	gh.SetPlayerStateRepo(store.States)
+}
+
+// SetProtocolVersionRange устанавливает диапазон поддерживаемых версий протокола.
+// Нулевые границы заменяются текущей версией ProtocolVersion.
+func (gh *GameHandlerPB) SetProtocolVersionRange(r ProtocolVersionRange) {
+	gh.protocolRange = r.withDefaults()
+}
+
+// SetMaxReachDistance задаёт максимальную дистанцию взаимодействия с блоками.
+// Неположительные значения заменяются DefaultMaxReachDistance.
+func (gh *GameHandlerPB) SetMaxReachDistance(distance float64) {
+	if distance <= 0 {
+		distance = DefaultMaxReachDistance
+	}
+
+	gh.mu.Lock()
+	gh.maxReach = distance
+	gh.mu.Unlock()
+}
+
 // GetEntityPosition возвращает позицию сущности в формате Vec3 (x, y, layer).
 // Используется для сохранения позиций игроков.
 //
@@ -141,81 +360,108 @@ func (gh *GameHandlerPB) GetEntityPosition(entityID uint64) (vec.Vec3, bool) {
 //
 // Возвращает:
 //
-//	vec.Vec3 - позиция спавна по умолчанию
+//	vec.Vec3 - точка спавна мира (см. WorldManager.GetWorldSpawn) в пределах границы мира на слое 1
 func (gh *GameHandlerPB) GetDefaultSpawnPosition() vec.Vec3 {
-	// Пока используем фиксированную позицию спавна
-	// В будущем можно добавить логику поиска безопасной позиции спавна
-	return vec.Vec3{X: 0, Y: 0, Z: 1}
+	// Точка спавна, оставшаяся за сдвинутой границей, переносится к её краю
+	spawn := gh.worldManager.ClampToWorldBorder(gh.worldManager.GetWorldSpawn())
+	return vec.Vec3{X: spawn.X, Y: spawn.Y, Z: 1}
 }
 
 // HandleMessage обрабатывает входящие сообщения от клиентов
 func (gh *GameHandlerPB) HandleMessage(connID string, msg *protocol.GameMessage) {
+	// Проверяем размер до десериализации полезной нагрузки
+	if err := gh.serializer.CheckPayloadSize(len(msg.Payload)); err != nil {
+		gh.rejectOversized(connID, err)
+		return
+	}
+
+	if isGameplayMessage(msg.Type) {
+		gh.touchActivity(connID)
+	}
+
+	// Обработка прерывается при отключении клиента или по истечении времени
+	ctx, cancel := gh.messageContext(connID)
+	defer cancel()
+	defer gh.observeHandler(connID, msg.Type, time.Now())
+
 	switch msg.Type {
 	case protocol.MessageType_AUTH:
-		gh.handleAuth(connID, msg)
+		gh.handleAuth(ctx, connID, msg)
 	case protocol.MessageType_BLOCK_UPDATE:
 		gh.handleBlockUpdate(connID, msg)
 	case protocol.MessageType_CHUNK_REQUEST:
-		gh.handleChunkRequest(connID, msg)
+		gh.handleChunkRequest(ctx, connID, msg)
 	case protocol.MessageType_CHUNK_BATCH_REQUEST:
-		gh.handleChunkBatchRequest(connID, msg)
+		gh.handleChunkBatchRequest(ctx, connID, msg)
 	case protocol.MessageType_ENTITY_ACTION:
 		gh.handleEntityAction(connID, msg)
 	case protocol.MessageType_ENTITY_MOVE:
 		gh.handleEntityMove(connID, msg)
 	case protocol.MessageType_CHAT:
 		gh.handleChat(connID, msg)
+	case protocol.MessageType_TRADE_REQUEST:
+		gh.handleTradeRequest(connID, msg)
 	default:
-		log.Printf("Неизвестный тип сообщения: %d", msg.Type)
+		gh.rejectUnknown(connID, msg.Type)
 	}
 }
 
 // OnClientConnect вызывается при подключении клиента
 func (gh *GameHandlerPB) OnClientConnect(connID string) {
+	gh.openConnContext(connID)
 	log.Printf("Клиент подключен: %s", connID)
 }
 
 // OnClientDisconnect вызывается при отключении клиента
 func (gh *GameHandlerPB) OnClientDisconnect(connID string) {
+	// Прерываем обработку сообщений и недоставленные чанки
+	gh.cancelConnContext(connID)
+	gh.cancelChunkStream(connID)
+	gh.forgetKnownChunks(connID)
+	gh.dropMoveInputs(connID)
+	gh.forgetChunkRequests(connID)
+
+	// Оповещения уходят после снятия gh.mu (defer выполняются в обратном порядке)
+	var out outbox
+	defer gh.flush(&out)
 	gh.mu.Lock()
 	defer gh.mu.Unlock()
 
+	delete(gh.oversizedMessages, connID)
+	delete(gh.unknownMessages, connID)
+	delete(gh.skippedEntities, connID)
+	delete(gh.mining, connID)
+	reason := gh.takeDisconnectReasonLocked(connID)
+
 	// Находим сессию игрока
 	session, sessionExists := gh.sessions[connID]
 	entityID, entityExists := gh.playerEntities[connID]
 
 	if sessionExists && entityExists {
-		// Сохраняем позицию игрока перед отключением
-		if gh.positionRepo != nil {
-			if currentPos, found := gh.GetEntityPosition(entityID); found {
-				ctx := context.Background()
-				if err := gh.positionRepo.Save(ctx, session.UserID, currentPos); err != nil {
-					log.Printf("❌ Ошибка сохранения позиции для пользователя %d: %v", session.UserID, err)
-				} else {
-					log.Printf("💾 Позиция игрока %s сохранена: (%d, %d, %d)", session.Username, currentPos.X, currentPos.Y, currentPos.Z)
-				}
-			} else {
-				log.Printf("⚠️ Не удалось получить позицию сущности %d для сохранения", entityID)
-			}
-		} else {
-			log.Printf("⚠️ Репозиторий позиций не настроен, позиция не сохранена")
-		}
+		// Сохраняем позицию игрока (или откладываем до истечения отсрочки переподключения)
+		gh.savePositionOnDisconnectLocked(session, entityID, reason)
+
+		// Возвращаем предметы из незавершённой сделки до сохранения и удаления сущности
+		gh.cancelTradeOnDisconnectLocked(entityID, &out)
+		gh.forgetAnticheatLocked(entityID)
+
+		// Сохраняем прогресс (уровень, опыт, эффекты) до удаления сущности
+		gh.savePlayerState(session.UserID, entityID)
+
+		// Питомцы и предметы остаются за игроком, турели становятся ничьими
+		gh.releaseOwnedEntitiesLocked(session.UserID, &out)
 
-		// Удаляем сущность из мира
-		gh.DespawnEntity(entityID)
+		// Удаляем сущность игрока: при следующем входе создаётся новая
+		gh.entityManager.DespawnEntity(entityID, gh)
 
 		// Удаляем привязки
 		delete(gh.playerEntities, connID)
 		delete(gh.sessions, connID)
 
 		// Оповещаем других игроков
-		despawnMsg := &protocol.EntityDespawnMessage{
-			EntityId: entityID,
-			Reason:   "disconnected",
-		}
-		gh.broadcastMessage(protocol.MessageType_ENTITY_DESPAWN, despawnMsg)
+		out.broadcastDespawn(entityID, reason)
 
-		log.Printf("🚪 Клиент %s (%s) отключен, позиция сохранена", connID, session.Username)
+		log.Printf("🚪 Клиент %s (%s) отключен", connID, session.Username)
 	} else {
 		log.Printf("🚪 Клиент %s отключен (сессия не найдена)", connID)
 	}
@@ -223,64 +469,75 @@ func (gh *GameHandlerPB) OnClientDisconnect(connID string) {
 
 // Tick обновляет состояние игрового мира
 func (gh *GameHandlerPB) Tick(dt float64) {
+	// Не больше одного шага буферизованного ввода движения на игрока
+	gh.consumeMoveInputs()
+
 	// Обновляем все сущности
 	gh.entityManager.UpdateEntities(dt, gh)
 
+	// Действие и истечение статус-эффектов
+	gh.tickStatusEffects(dt)
+
 	// Увеличиваем счетчик тиков
 	gh.tickCounter++
 
 	// ОПТИМИЗАЦИЯ: Отправляем обновления не каждый тик, а с заданным интервалом
 	// Это снижает нагрузку на сеть с 20 обновлений/сек до 2 обновлений/сек
-	if gh.tickCounter%gh.worldUpdateInterval == 0 {
+	gh.mu.RLock()
+	updateInterval := gh.worldUpdateInterval
+	gh.mu.RUnlock()
+	if gh.tickCounter%updateInterval == 0 {
 		gh.sendWorldUpdates()
 		//log.Printf("🔄 Тик %d: отправка world updates (интервал: %d тиков)", gh.tickCounter, gh.worldUpdateInterval)
 	}
 
-	// Периодическое автосохранение позиций (каждые 30 секунд)
+	// Периодическое автосохранение позиций (см. SetRuntimeParams)
 	gh.autoSavePositions()
+
+	// Позиции игроков, не переподключившихся за отсрочку
+	gh.savePendingPositions()
+
+	// Проверка неактивных игроков, игроков над пропастью, брошенной добычи
+	// и предметов на земле раз в секунду
+	if gh.tickCounter%20 == 0 {
+		gh.checkIdlePlayers()
+		gh.checkVoidPlayers()
+		gh.expireMining()
+		gh.sweepDroppedItems()
+	}
 }
 
 // autoSavePositions выполняет автосохранение позиций всех онлайн игроков.
 // Вызывается периодически из Tick для предотвращения потери данных.
 func (gh *GameHandlerPB) autoSavePositions() {
-	// Используем простой таймер - проверяем раз в 30 секунд
-	// В продакшене лучше использовать отдельный тикер
-	const autoSaveInterval = 30.0 // секунд
-
-	// Статическая переменная для отслеживания времени
-	// TODO: В будущем заменить на более элегантное решение
-	now := float64(time.Now().Unix())
-
-	// Читаем из контекста GameHandlerPB последнее время автосохранения
-	// Для простоты пока используем проверку по времени
-	gh.mu.RLock()
+	gh.mu.Lock()
 	sessionsCount := len(gh.sessions)
 	playerCount := len(gh.playerEntities)
-	gh.mu.RUnlock()
 
-	// Если нет игроков онлайн, пропускаем автосохранение
-	if sessionsCount == 0 || playerCount == 0 {
+	// Если нет игроков онлайн или интервал ещё не прошёл, пропускаем автосохранение
+	now := gh.now()
+	if sessionsCount == 0 || playerCount == 0 || now.Sub(gh.lastAutoSave) < gh.autoSaveInterval {
+		gh.mu.Unlock()
 		return
 	}
+	gh.lastAutoSave = now
+	gh.mu.Unlock()
 
-	// Простая проверка - автосохранение раз в 30 секунд
-	// В реальном коде стоит добавить поле lastAutoSave в структуру
-	if int(now)%int(autoSaveInterval) != 0 {
-		return
-	}
+	// Прогресс игроков сохраняется независимо от наличия репозитория позиций
+	gh.autoSavePlayerStates()
 
 	if gh.positionRepo == nil {
 		return // Репозиторий не настроен
 	}
 
 	// Собираем позиции всех онлайн игроков
-	positionsToSave := make(map[uint64]vec.Vec3)
+	positionsToSave := make(map[uint64]storage.VersionedPosition)
 
 	gh.mu.RLock()
 	for connID, session := range gh.sessions {
 		if entityID, exists := gh.playerEntities[connID]; exists {
 			if currentPos, found := gh.GetEntityPosition(entityID); found {
-				positionsToSave[session.UserID] = currentPos
+				positionsToSave[session.UserID] = storage.VersionedPosition{Pos: currentPos, Version: session.positionVersion}
 			}
 		}
 	}
@@ -289,8 +546,10 @@ func (gh *GameHandlerPB) autoSavePositions() {
 	// Выполняем пакетное сохранение позиций
 	if len(positionsToSave) > 0 {
 		ctx := context.Background()
-		if err := gh.positionRepo.BatchSave(ctx, positionsToSave); err != nil {
+		if stale, err := gh.positionRepo.BatchSaveVersioned(ctx, positionsToSave); err != nil {
 			log.Printf("❌ Ошибка автосохранения позиций игроков: %v", err)
+		} else if len(stale) > 0 {
+			log.Printf("⚠️ Автосохранение: позиции пользователей %v пропущены, ими владеют другие узлы", stale)
 		} else {
 			log.Printf("💾 Автосохранение выполнено для %d игроков", len(positionsToSave))
 		}
@@ -355,17 +614,28 @@ func (gh *GameHandlerPB) GetBehavior(entityType entity.EntityType) (entity.Entit
 	return gh.entityManager.GetBehavior(entityType)
 }
 
+// BehaviorFor реализует интерфейс EntityAPI
+func (gh *GameHandlerPB) BehaviorFor(entity *entity.Entity) (entity.EntityBehavior, bool) {
+	return gh.entityManager.BehaviorFor(entity)
+}
+
+// Rand реализует интерфейс EntityAPI: генератор менеджера сущностей,
+// сид которого выводится из сида мира (см. world.WorldManager.SetEntitySource)
+func (gh *GameHandlerPB) Rand() *rand.Rand {
+	return gh.entityManager.Rand()
+}
+
 // MoveEntity реализует интерфейс EntityAPI
 func (gh *GameHandlerPB) MoveEntity(entity *entity.Entity, direction entity.MovementDirection, dt float64) bool {
 	// Получаем поведение для данного типа сущности
-	behavior, exists := gh.GetBehavior(entity.Type)
+	behavior, exists := gh.BehaviorFor(entity)
 	if !exists {
 		log.Printf("Нет поведения для сущности типа %d", entity.Type)
 		return false
 	}
 
-	// Получаем скорость движения сущности
-	moveSpeed := behavior.GetMoveSpeed()
+	// Получаем скорость движения сущности с учётом статус-эффектов
+	moveSpeed := behavior.GetMoveSpeed() * entity.SpeedMultiplier()
 
 	// Вычисляем вектор направления
 	moveDir := vec.Vec2Float{X: 0, Y: 0}
@@ -400,6 +670,11 @@ func (gh *GameHandlerPB) MoveEntity(entity *entity.Entity, direction entity.Move
 	// Вычисляем новую позицию
 	newPos := entity.PrecisePos.Add(moveDir.Mul(moveSpeed * dt))
 
+	// Сущности не выходят за границу мира (оказавшиеся снаружи могут вернуться)
+	if !gh.worldManager.BorderAllowsMove(entity.Position, vec.Vec2{X: int(math.Floor(newPos.X)), Y: int(math.Floor(newPos.Y))}) {
+		return false
+	}
+
 	// Проверяем столкновения с блоками с учётом слоёв и проходимости
 	blockX := int(math.Floor(newPos.X))
 	blockY := int(math.Floor(newPos.Y))
@@ -433,9 +708,13 @@ func (gh *GameHandlerPB) MoveEntity(entity *entity.Entity, direction entity.Move
 		}
 	}
 
+	// В заполненный до предела BigChunk сущность не переходит, а остаётся на месте
+	if !gh.worldManager.CanEnterBigChunk(entity.Type, entity.Position, newPos.ToVec2()) {
+		return false
+	}
+
 	// Если коллизий нет, обновляем позицию
-	entity.PrecisePos = newPos
-	entity.Position = newPos.ToVec2()
+	gh.entityManager.MoveEntity(entity.ID, newPos)
 
 	// Оповещаем клиентов о перемещении
 	gh.sendEntityMoveUpdate(entity)
@@ -505,10 +784,10 @@ func (gh *GameHandlerPB) sendEntityMoveUpdate(entity *entity.Entity) {
 		Active:    entity.Active,
 	}
 
-	// Создаем сообщение о перемещении
-	moveMsg := &protocol.EntityMoveMessage{
+	// Создаем сообщение о перемещении (одна метка времени для всех получателей)
+	moveMsg := gh.stampMove(&protocol.EntityMoveMessage{
 		Entities: []*protocol.EntityData{entityData},
-	}
+	})
 
 	// Отправляем всем клиентам, кроме владельца сущности
 	gh.mu.RLock()
@@ -522,8 +801,12 @@ func (gh *GameHandlerPB) sendEntityMoveUpdate(entity *entity.Entity) {
 	gh.mu.RUnlock()
 
 	// Отправляем всем, кроме владельца
-	for connID := range gh.tcpServer.connections {
-		if connID != playerConnID {
+	if gh.sender == nil {
+		return
+	}
+	visibility := gh.visibilitySnapshot()
+	for _, connID := range gh.sender.ConnectionIDs() {
+		if connID != playerConnID && gh.canSee(visibility, connID, 0, entity) {
 			gh.sendTCPMessage(connID, protocol.MessageType_ENTITY_MOVE, moveMsg)
 		}
 	}
@@ -534,23 +817,57 @@ func (gh *GameHandlerPB) sendEntityMoveUpdate(entity *entity.Entity) {
 // клиента было отклонено (коллизия, непроходимая область и т.п.), чтобы клиент
 // «откатился» к авторитетной позиции сервера.
 func (gh *GameHandlerPB) sendEntityPositionCorrection(connID string, entity *entity.Entity) {
-	if connID == "" || gh.tcpServer == nil {
+	if connID == "" || gh.sender == nil {
 		return
 	}
 
-	// Формируем данные сущности
-	entityData := &protocol.EntityData{
-		Id:        entity.ID,
-		Type:      protocol.EntityType(entity.Type),
-		Position:  &protocol.Vec2{X: int32(entity.Position.X), Y: int32(entity.Position.Y)},
-		Active:    entity.Active,
-		Direction: int32(entity.Direction),
-		Velocity:  &protocol.Vec2Float{X: 0, Y: 0}, // после отката скорость обнуляем
-	}
+	// Формируем данные сущности; после отката скорость обнуляем
+	entityData := gh.ownerEntityData(connID, entity)
+	entityData.Velocity = &protocol.Vec2Float{X: 0, Y: 0}
 
 	// Создаём и отправляем сообщение
 	moveMsg := &protocol.EntityMoveMessage{Entities: []*protocol.EntityData{entityData}}
-	gh.sendTCPMessage(connID, protocol.MessageType_ENTITY_MOVE, moveMsg)
+	gh.sendTCPMessage(connID, protocol.MessageType_ENTITY_MOVE, gh.stampMove(moveMsg))
+}
+
+// ownerEntityData формирует данные собственной сущности игрока вместе с полями для сверки
+// клиентского предсказания: последним обработанным вводом, тиком сервера и авторитетной скоростью.
+// Обновления для других наблюдателей эти поля не содержат.
+func (gh *GameHandlerPB) ownerEntityData(connID string, entity *entity.Entity) *protocol.EntityData {
+	gh.mu.RLock()
+	var lastInput uint32
+	if session, ok := gh.sessions[connID]; ok {
+		lastInput = session.LastProcessedInput
+	}
+	gh.mu.RUnlock()
+
+	serverTick := gh.worldManager.CurrentTick()
+	return &protocol.EntityData{
+		Id:                 entity.ID,
+		Type:               protocol.EntityType(entity.Type),
+		Position:           &protocol.Vec2{X: int32(entity.Position.X), Y: int32(entity.Position.Y)},
+		Velocity:           &protocol.Vec2Float{X: float32(entity.Velocity.X), Y: float32(entity.Velocity.Y)},
+		Direction:          int32(entity.Direction),
+		Active:             entity.Active,
+		LastProcessedInput: &lastInput,
+		ServerTick:         &serverTick,
+	}
+}
+
+// recordProcessedInput запоминает sequence обработанного ENTITY_MOVE клиента.
+// Пакеты без номера (sequence == 0) не участвуют в сверке предсказания.
+func (gh *GameHandlerPB) recordProcessedInput(connID string, sequence uint32) {
+	if sequence == 0 {
+		return
+	}
+
+	gh.mu.Lock()
+	defer gh.mu.Unlock()
+	if session, ok := gh.sessions[connID]; ok {
+		if session.LastProcessedInput == 0 || sequenceNewer(sequence, session.LastProcessedInput) {
+			session.LastProcessedInput = sequence
+		}
+	}
 }
 
 // IsSessionValid проверяет, что для данного connID существует активная сессия.
@@ -564,8 +881,20 @@ func (gh *GameHandlerPB) IsSessionValid(connID string) bool {
 	return ok
 }
 
+// connIDForUser возвращает соединение игрока в сети по имени пользователя
+func (gh *GameHandlerPB) connIDForUser(username string) (string, error) {
+	gh.mu.RLock()
+	defer gh.mu.RUnlock()
+	for connID, session := range gh.sessions {
+		if session.Username == username {
+			return connID, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s", ErrPlayerOffline, username)
+}
+
 // handleAuth обрабатывает аутентификацию с использованием GameAuthenticator
-func (gh *GameHandlerPB) handleAuth(connID string, msg *protocol.GameMessage) {
+func (gh *GameHandlerPB) handleAuth(ctx context.Context, connID string, msg *protocol.GameMessage) {
 	// Проверяем, что GameAuthenticator инициализирован
 	if gh.gameAuth == nil {
 		log.Printf("❌ GameAuthenticator не инициализирован")
@@ -582,12 +911,35 @@ func (gh *GameHandlerPB) handleAuth(connID string, msg *protocol.GameMessage) {
 		return
 	}
 
+	// Проверяем совместимость версии протокола до любых действий с аккаунтом
+	if !gh.protocolRange.Supports(authMsg.ProtocolVersion) {
+		log.Printf("❌ Несовместимая версия протокола от %s: %d (поддерживается %s)",
+			connID, authMsg.ProtocolVersion, gh.protocolRange)
+		resp := &protocol.AuthResponseMessage{
+			Success: false,
+			Message: fmt.Sprintf("Unsupported protocol version %d: client must use protocol version %s",
+				authMsg.ProtocolVersion, gh.protocolRange),
+			MinProtocolVersion: gh.protocolRange.Min,
+			MaxProtocolVersion: gh.protocolRange.Max,
+		}
+		gh.sendTCPMessage(connID, protocol.MessageType_AUTH_RESPONSE, resp)
+		return
+	}
+
 	// Если уже имеется валидная сессия – пропускаем повторную авторизацию
 	if gh.IsSessionValid(connID) {
 		log.Printf("⚠️ Повторная авторизация от %s игнорируется", connID)
 		return
 	}
 
+	// Сервер выводится из работы: новых игроков не принимаем
+	if gh.isDraining() {
+		log.Printf("🚧 Вход %s отклонён: сервер выводится из работы", connID)
+		resp := &protocol.AuthResponseMessage{Success: false, Message: ServerMessageDraining}
+		gh.sendTCPMessage(connID, protocol.MessageType_AUTH_RESPONSE, resp)
+		return
+	}
+
 	// === НОВАЯ ЛОГИКА С GAME AUTHENTICATOR ===
 	// Выполняем аутентификацию через GameAuthenticator
 	password := ""
@@ -595,7 +947,7 @@ func (gh *GameHandlerPB) handleAuth(connID string, msg *protocol.GameMessage) {
 		password = *authMsg.Password
 	}
 
-	authResult, err := gh.gameAuth.AuthenticateUser(authMsg.Username, password)
+	authResult, err := gh.gameAuth.AuthenticateUser(ctx, authMsg.Username, password)
 	if err != nil {
 		log.Printf("❌ Ошибка при аутентификации: %v", err)
 		resp := &protocol.AuthResponseMessage{Success: false, Message: "Authentication service error"}
@@ -629,14 +981,68 @@ func (gh *GameHandlerPB) handleAuth(connID string, msg *protocol.GameMessage) {
 		}
 	}
 
-	// Создаем игровую сущность
-	var entityID uint64
+	// Дальность видимости ограничиваем до захвата блокировки
+	viewDistance := gh.resolveViewDistance(authMsg.ViewDistance)
+
+	// Позиция игрока определяет регион, который должен его обслуживать.
+	// Переподключение в пределах отсрочки продолжает позицию, ещё не
+	// записанную в хранилище, вместе с версией владения
+	resumed, resuming := gh.resumablePosition(authResult.UserID)
+	var spawnPos vec.Vec2
+	if resuming {
+		log.Printf("🔁 Игрок %s переподключился в пределах отсрочки сохранения позиции", username)
+		spawnPos = resumed.pos.ToVec2()
+	} else {
+		spawnPos = gh.loadSpawnPosition(ctx, authResult.UserID, username)
+	}
+	if !gh.worldManager.InsideWorldBorder(spawnPos) {
+		// Граница сдвинулась, пока игрок был вне сети: возвращаем его в мир
+		log.Printf("🧱 Игрок %s вне границы мира в (%d, %d), перенесён на спавн", username, spawnPos.X, spawnPos.Y)
+		spawnPos = gh.GetDefaultSpawnPosition().ToVec2()
+	}
+	if ctx.Err() != nil {
+		// Клиент отключился или загрузка не уложилась во время: сессию не создаём
+		log.Printf("⏹️ Вход %s прерван: %v", connID, context.Cause(ctx))
+		return
+	}
+	gh.mu.RLock()
+	regions := gh.regions
+	gh.mu.RUnlock()
+	if route, redirect := regions.redirectFor(spawnPos); redirect {
+		log.Printf("🔀 Игрок %s в (%d, %d) принадлежит региону %s, перенаправляем на %s",
+			username, spawnPos.X, spawnPos.Y, route.ID, route.GameAddress)
+		gh.sendTCPMessage(connID, protocol.MessageType_AUTH_RESPONSE, &protocol.AuthResponseMessage{
+			Success:            true,
+			Message:            fmt.Sprintf("Reconnect to region %s", route.ID),
+			WorldName:          "main_world",
+			MinProtocolVersion: gh.protocolRange.Min,
+			MaxProtocolVersion: gh.protocolRange.Max,
+			RegionId:           route.ID,
+			GameAddress:        route.GameAddress,
+			Redirect:           true,
+		})
+		return
+	}
+	localRegion, _ := regions.local()
+
+	// Узел становится владельцем позиции: сохранения прежнего региона отклоняются
+	positionVersion := resumed.version
+	if !resuming {
+		positionVersion = gh.acquirePositionVersion(ctx, authResult.UserID)
+	}
+
+	// Создаем игровую сущность. Ответ и оповещения отправляются после снятия gh.mu
+	var (
+		entityID uint64
+		created  bool
+		out      outbox
+	)
 	gh.mu.Lock()
 	if existingEntityID, exists := gh.playerEntities[connID]; !exists {
-		// НЕ используем gh.generateEntityID() потому что мы уже в блокировке!
-		gh.lastEntityID++
-		entityID = gh.lastEntityID
+		// Аллокатор не использует gh.mu, поэтому безопасен внутри блокировки
+		entityID = gh.generateEntityID()
 		gh.playerEntities[connID] = entityID
+		created = true
 
 		// Создаем AuthResponse с JWT токеном
 		authResp := &protocol.AuthResponseMessage{
@@ -649,6 +1055,11 @@ func (gh *GameHandlerPB) handleAuth(connID string, msg *protocol.GameMessage) {
 				Version:     "1.0.0",
 				Environment: "development",
 			},
+			MinProtocolVersion: gh.protocolRange.Min,
+			MaxProtocolVersion: gh.protocolRange.Max,
+			ViewDistance:       uint32(viewDistance),
+			RegionId:           localRegion.ID,
+			GameAddress:        localRegion.GameAddress,
 		}
 
 		gh.sessions[connID] = &Session{
@@ -657,46 +1068,31 @@ func (gh *GameHandlerPB) handleAuth(connID string, msg *protocol.GameMessage) {
 			Username: username,
 			Token:    authResult.Token,
 			IsAdmin:  isAdmin,
-		}
+			GameMode: gh.defaultGameMode,
 
-		log.Printf("✅ Создана игровая сущность %d для пользователя %s", entityID, username)
+			ViewDistance: viewDistance,
+			LastActivity: gh.now(),
 
-		// Загружаем сохраненную позицию игрока или используем дефолтную
-		var spawnPos vec.Vec2
-		if gh.positionRepo != nil {
-			if savedPos, found, err := gh.positionRepo.Load(context.Background(), authResult.UserID); err != nil {
-				log.Printf("⚠️ Ошибка загрузки позиции для пользователя %d: %v", authResult.UserID, err)
-				defaultPos := gh.GetDefaultSpawnPosition()
-				spawnPos = defaultPos.ToVec2()
-			} else if found {
-				log.Printf("📍 Загружена сохраненная позиция для %s: (%d, %d, %d)", username, savedPos.X, savedPos.Y, savedPos.Z)
-				spawnPos = savedPos.ToVec2()
-			} else {
-				log.Printf("🆕 Первый вход пользователя %s, используем позицию спавна по умолчанию", username)
-				defaultPos := gh.GetDefaultSpawnPosition()
-				spawnPos = defaultPos.ToVec2()
-			}
-		} else {
-			log.Printf("⚠️ Репозиторий позиций не настроен, используем позицию спавна по умолчанию")
-			defaultPos := gh.GetDefaultSpawnPosition()
-			spawnPos = defaultPos.ToVec2()
+			positionVersion: positionVersion,
+
+			legacyWorldState: !slices.Contains(authMsg.Capabilities, CapabilityWorldState),
 		}
 
-		// Создаем сущность игрока в мире
-		gh.spawnEntityWithID(entity.EntityTypePlayer, spawnPos, entityID)
+		log.Printf("✅ Создана игровая сущность %d для пользователя %s", entityID, username)
 
-		// Связываем TCP-соединение с playerID для дальнейших проверок
-		if gh.tcpServer != nil {
-			gh.tcpServer.mu.Lock()
-			if conn, ok := gh.tcpServer.connections[connID]; ok {
-				conn.playerID = entityID
-			}
-			gh.tcpServer.mu.Unlock()
+		// Позиция продолжена из отложенного сохранения, записывать её не нужно
+		if resuming {
+			gh.cancelPendingPositionSaveLocked(authResult.UserID)
 		}
 
-		// Отправляем успешный ответ
+		// Создаем сущность игрока в мире и восстанавливаем её прогресс
+		gh.addEntityWithID(entity.EntityTypePlayer, spawnPos, entityID, &out)
+		gh.restorePlayerState(ctx, authResult.UserID, entityID)
+		gh.reclaimOwnedEntities(authResult.UserID, entityID)
+		gh.resetAnticheatLocked(entityID, spawnPos)
+
 		log.Printf("✅ Аутентификация успешна для %s (ID: %d)", username, entityID)
-		gh.sendTCPMessage(connID, protocol.MessageType_AUTH_RESPONSE, authResp)
+		out.send(connID, protocol.MessageType_AUTH_RESPONSE, authResp)
 
 	} else {
 		entityID = existingEntityID
@@ -710,14 +1106,56 @@ func (gh *GameHandlerPB) handleAuth(connID string, msg *protocol.GameMessage) {
 			JwtToken:  &authResult.Token,
 			WorldName: "main_world",
 		}
-		gh.sendTCPMessage(connID, protocol.MessageType_AUTH_RESPONSE, authResp)
+		out.send(connID, protocol.MessageType_AUTH_RESPONSE, authResp)
 	}
 	gh.mu.Unlock()
 
+	// Связываем соединение с playerID для дальнейших проверок до отправки ответа
+	if created && gh.sender != nil {
+		gh.sender.BindPlayer(connID, entityID)
+	}
+	gh.flush(&out)
+
 	// Отправляем данные мира
-	if entityID, exists := gh.playerEntities[connID]; exists {
-		gh.sendWorldDataToPlayer(connID, entityID)
+	gh.sendWorldDataToPlayer(connID, entityID)
+}
+
+// loadSpawnPosition возвращает сохранённую позицию пользователя или позицию спавна по умолчанию
+func (gh *GameHandlerPB) loadSpawnPosition(ctx context.Context, userID uint64, username string) vec.Vec2 {
+	var spawnPos vec.Vec2
+	if gh.positionRepo != nil {
+		if savedPos, found, err := gh.positionRepo.Load(ctx, userID); err != nil {
+			log.Printf("⚠️ Ошибка загрузки позиции для пользователя %d: %v", userID, err)
+			defaultPos := gh.GetDefaultSpawnPosition()
+			spawnPos = defaultPos.ToVec2()
+		} else if found {
+			log.Printf("📍 Загружена сохраненная позиция для %s: (%d, %d, %d)", username, savedPos.X, savedPos.Y, savedPos.Z)
+			spawnPos = savedPos.ToVec2()
+		} else {
+			log.Printf("🆕 Первый вход пользователя %s, используем позицию спавна по умолчанию", username)
+			defaultPos := gh.GetDefaultSpawnPosition()
+			spawnPos = defaultPos.ToVec2()
+		}
+	} else {
+		log.Printf("⚠️ Репозиторий позиций не настроен, используем позицию спавна по умолчанию")
+		defaultPos := gh.GetDefaultSpawnPosition()
+		spawnPos = defaultPos.ToVec2()
+	}
+	return spawnPos
+}
+
+// acquirePositionVersion получает новую версию владения позицией пользователя.
+// При ошибке возвращает 0: сохранения узла пройдут, только пока версию никто не получил
+func (gh *GameHandlerPB) acquirePositionVersion(ctx context.Context, userID uint64) uint64 {
+	if gh.positionRepo == nil {
+		return 0
+	}
+	version, err := gh.positionRepo.AcquireVersion(ctx, userID)
+	if err != nil {
+		log.Printf("⚠️ Ошибка получения версии позиции для пользователя %d: %v", userID, err)
+		return 0
 	}
+	return version
 }
 
 // handleBlockUpdate обрабатывает обновление блока
@@ -725,12 +1163,14 @@ func (gh *GameHandlerPB) handleBlockUpdate(connID string, msg *protocol.GameMess
 	blockUpdate := &protocol.BlockUpdateRequest{}
 	if err := gh.serializer.DeserializePayload(msg, blockUpdate); err != nil {
 		log.Printf("Ошибка десериализации BlockUpdate: %v", err)
+		gh.sendError(connID, protocol.ErrorCode_INVALID, msg.Type, "Malformed block update")
 		return
 	}
 
 	// === Валидация входных данных ===
 	if blockUpdate.Position == nil {
 		log.Printf("Недействительное обновление блока: позиция nil")
+		gh.rejectBlockUpdate(connID, blockUpdate, protocol.ErrorCode_INVALID, "Block position is required")
 		return
 	}
 
@@ -739,10 +1179,14 @@ func (gh *GameHandlerPB) handleBlockUpdate(connID string, msg *protocol.GameMess
 	// Проверяем, что клиент авторизован
 	gh.mu.RLock()
 	playerEntityID, exists := gh.playerEntities[connID]
+	maxReachDistance := gh.maxReach
+	gameMode, modeRules := gh.gameModeRulesLocked(connID)
+	miningTime := !gh.miningRules.Disabled
 	gh.mu.RUnlock()
 
 	if !exists {
 		log.Printf("❌ Неавторизованный клиент пытается изменить блок: %s", connID)
+		gh.rejectBlockUpdate(connID, blockUpdate, protocol.ErrorCode_UNAUTHORIZED, "Not authorized")
 		return
 	}
 
@@ -750,28 +1194,67 @@ func (gh *GameHandlerPB) handleBlockUpdate(connID string, msg *protocol.GameMess
 	playerEntity, exists := gh.entityManager.GetEntity(playerEntityID)
 	if !exists || playerEntity == nil {
 		log.Printf("❌ Сущность игрока не найдена: %d", playerEntityID)
+		gh.rejectBlockUpdate(connID, blockUpdate, protocol.ErrorCode_UNAUTHORIZED, "Player entity not found")
+		return
+	}
+
+	// Действие должно быть разрешено режимом игры
+	action := blockUpdate.Action
+	if action == "" {
+		action = "place"
+	}
+	if !modeRules.allows(action) {
+		log.Printf("❌ Игрок %d: действие %q с блоком запрещено в режиме %s", playerEntityID, action, gameMode)
+		gh.rejectBlockUpdate(connID, blockUpdate, protocol.ErrorCode_FORBIDDEN,
+			fmt.Sprintf("Action %q is not allowed in %s mode", action, gameMode))
+		return
+	}
+
+	// За границей мира строить нельзя
+	if !gh.worldManager.InsideWorldBorder(pos) {
+		log.Printf("❌ Игрок %d пытается изменить блок %v за границей мира", playerEntityID, pos)
+		gh.rejectBlockUpdate(connID, blockUpdate, protocol.ErrorCode_FORBIDDEN, "Block is outside the world border")
+		return
+	}
+
+	// Блоки в привате изменяют только его владелец и администраторы
+	if message, denied := gh.claimDenial(connID, pos); denied {
+		log.Printf("❌ Игрок %d пытается изменить блок %v в привате", playerEntityID, pos)
+		gh.rejectBlockUpdate(connID, blockUpdate, protocol.ErrorCode_FORBIDDEN, message)
 		return
 	}
 
 	// Проверяем расстояние до блока (защита от читов)
 	blockPosFloat := vec.Vec2Float{X: float64(pos.X), Y: float64(pos.Y)}
+	if !modeRules.UnlimitedReach {
+		gh.checkAnticheat(connID, anticheat.Action{
+			PlayerID: playerEntityID,
+			Type:     anticheat.ActionBlockEdit,
+			Position: playerEntity.PrecisePos,
+			Target:   blockPosFloat,
+		})
+	}
 	distance := playerEntity.PrecisePos.DistanceTo(blockPosFloat)
-	const maxReachDistance = 10.0 // Максимальная дистанция взаимодействия
-	if distance > maxReachDistance {
+	if !modeRules.UnlimitedReach && distance > maxReachDistance {
 		log.Printf("❌ Игрок %d пытается изменить блок слишком далеко: %.2f > %.2f",
 			playerEntityID, distance, maxReachDistance)
+		gh.rejectBlockUpdate(connID, blockUpdate, protocol.ErrorCode_TOO_FAR,
+			fmt.Sprintf("Block is too far: %.1f > %.1f", distance, maxReachDistance))
 		return
 	}
 
-	// Валидация ID блока
-	if blockUpdate.BlockId > 1000 { // Разумный лимит для ID блока
-		log.Printf("❌ Недопустимый ID блока: %d", blockUpdate.BlockId)
+	// Валидация ID блока: допустимы только зарегистрированные блоки
+	if blockUpdate.BlockId > math.MaxUint16 || !block.IsValidBlockID(block.BlockID(blockUpdate.BlockId)) {
+		log.Printf("❌ Неизвестный ID блока: %d", blockUpdate.BlockId)
+		gh.rejectBlockUpdate(connID, blockUpdate, protocol.ErrorCode_INVALID,
+			fmt.Sprintf("Unknown block id %d", blockUpdate.BlockId))
 		return
 	}
 
 	// Валидация размера метаданных
 	if blockUpdate.Metadata != nil && len(blockUpdate.Metadata.JsonData) > 1024 {
 		log.Printf("❌ Слишком большие метаданные блока: %d байт", len(blockUpdate.Metadata.JsonData))
+		gh.rejectBlockUpdate(connID, blockUpdate, protocol.ErrorCode_INVALID, "Block metadata is too large")
 		return
 	}
 
@@ -784,18 +1267,46 @@ func (gh *GameHandlerPB) handleBlockUpdate(connID string, msg *protocol.GameMess
 	}
 
 	// Получаем текущий блок на указанном слое
-	oldBlock := gh.worldManager.GetBlockLayer(pos, layer)
+	oldBlock, oldVersion := gh.worldManager.GetBlockLayerVersion(pos, layer)
 	currentBehavior, _ := block.Get(oldBlock.ID)
 
-	// actionPayload из запроса
+	// Правка, основанная на устаревшей версии блока, отклоняется: клиент
+	// получает текущее состояние блока и может повторить её
+	if baseVersion := blockUpdate.BaseVersion; baseVersion != 0 && oldVersion > baseVersion {
+		log.Printf("❌ Игрок %d изменяет блок %v по устаревшей версии: %d > %d",
+			playerEntityID, pos, oldVersion, baseVersion)
+		gh.rejectStaleBlockUpdate(connID, blockUpdate, oldBlock, oldVersion)
+		return
+	}
+
+	// actionPayload из запроса при установке блока (place) становится его
+	// метаданными и проверяется по схеме нового блока, при остальных действиях -
+	// по схеме полезной нагрузки действия (инструмент)
 	var actionPayload map[string]interface{}
 	if blockUpdate.Metadata != nil && blockUpdate.Metadata.JsonData != "" {
-		actionPayload, _ = protocol.JsonToMap(blockUpdate.Metadata.JsonData)
-	}
+		rawPayload, err := protocol.JsonToMap(blockUpdate.Metadata.JsonData)
+		if err != nil {
+			log.Printf("❌ Некорректный JSON метаданных блока от %s: %v", connID, err)
+			gh.rejectBlockUpdate(connID, blockUpdate, protocol.ErrorCode_INVALID, "Invalid block metadata: malformed JSON")
+			return
+		}
 
-	action := blockUpdate.Action
-	if action == "" {
-		action = "place"
+		if action == "place" {
+			schemaID := block.BlockID(blockUpdate.BlockId)
+			actionPayload, err = block.ValidateMetadata(schemaID, rawPayload)
+			if err != nil {
+				log.Printf("❌ Метаданные блока %d от %s отклонены: %v", schemaID, connID, err)
+				gh.rejectBlockUpdate(connID, blockUpdate, protocol.ErrorCode_INVALID, fmt.Sprintf("Invalid block metadata: %v", err))
+				return
+			}
+		} else {
+			actionPayload, err = block.ActionPayloadSchema.Validate(rawPayload)
+			if err != nil {
+				log.Printf("❌ Параметры действия %q от %s отклонены: %v", action, connID, err)
+				gh.rejectBlockUpdate(connID, blockUpdate, protocol.ErrorCode_INVALID, fmt.Sprintf("Invalid action payload: %v", err))
+				return
+			}
+		}
 	}
 
 	var newID block.BlockID
@@ -810,9 +1321,48 @@ func (gh *GameHandlerPB) handleBlockUpdate(connID string, msg *protocol.GameMess
 		if newBehavior != nil {
 			newPayload = newBehavior.CreateMetadata()
 		}
+		// Проверенные по схеме значения клиента дополняют метаданные по умолчанию
+		if len(actionPayload) > 0 && newPayload == nil {
+			newPayload = make(map[string]interface{}, len(actionPayload))
+		}
+		for key, value := range actionPayload {
+			newPayload[key] = value
+		}
 		result = block.InteractionResult{Success: true}
 
 	case "mine", "break":
+		// "mine" добывает блок; "break" - тоже, если так велит режим игры
+		// (MineOnBreak), иначе сразу убирает блок
+		mining := !modeRules.InstantBreak && (action == "mine" || modeRules.MineOnBreak)
+
+		// Прочный блок разрушается, только когда удары игрока наберут его
+		// прочность (см. mining.go); до этого блок не меняется
+		if mining && miningTime {
+			if hardness := blockHardness(oldBlock); hardness > 0 {
+				if done, progress := gh.mineHit(connID, playerEntityID, pos, layer, oldBlock, hardness); !done {
+					// Блок не меняется: удар не пишется в мир (не увеличивает
+					// версию, не попадает в журнал и аудит), клиент получает
+					// текущее состояние блока и прогресс
+					gh.sendBlockUpdateResponse(connID, blockUpdate, oldBlock, oldVersion, block.InteractionResult{
+						Success: true,
+						Message: "Блок повреждён",
+						Effects: []string{breakProgressEffect(progress)},
+					})
+					return
+				}
+				newID = block.AirBlockID
+				result = block.InteractionResult{Success: true, Effects: []string{effectBreak}}
+				break
+			}
+		}
+		// Иначе (и для блоков без прочности) результат удара определяет поведение
+		// блока; блоки без своей логики добычи разрушаются сразу
+		if mining && currentBehavior != nil {
+			newID, newPayload, result = currentBehavior.HandleInteraction("mine", oldBlock.Payload, actionPayload)
+			if result.Success {
+				break
+			}
+		}
 		// OnBreak будет вызван автоматически в WorldManager при замене блока
 		newID = block.AirBlockID
 		newPayload = nil
@@ -828,46 +1378,113 @@ func (gh *GameHandlerPB) handleBlockUpdate(connID string, msg *protocol.GameMess
 		}
 	}
 
-	// Применяем изменения на указанном слое
+	// Применяем изменения на указанном слое, если блок не изменили, пока
+	// обрабатывался запрос
 	blockObj := world.NewBlock(newID)
 	blockObj.Payload = newPayload
-	gh.worldManager.SetBlockLayer(pos, layer, blockObj)
+	version, applied := gh.worldManager.SetBlockLayerIfVersion(pos, layer, blockObj, oldVersion)
+	if !applied {
+		current, currentVersion := gh.worldManager.GetBlockLayerVersion(pos, layer)
+		gh.rejectStaleBlockUpdate(connID, blockUpdate, current, currentVersion)
+		return
+	}
+	gh.publishBlockAudit(connID, playerEntityID, pos, layer, action, oldBlock, blockObj)
 
-	// Формируем ответ
-	metaStr, _ := protocol.MapToJsonMetadata(newPayload)
-	respMeta := &protocol.JsonMetadata{JsonData: metaStr}
+	gh.sendBlockUpdateResponse(connID, blockUpdate, blockObj, version, result)
+}
+
+// sendBlockUpdateResponse отправляет клиенту итог обновления блока: блок
+// после обработки запроса и его версию
+func (gh *GameHandlerPB) sendBlockUpdateResponse(connID string, req *protocol.BlockUpdateRequest, b world.Block, version uint64, result block.InteractionResult) {
+	metaStr, _ := protocol.MapToJsonMetadata(b.Payload)
 	response := &protocol.BlockUpdateResponseMessage{
 		Success:  result.Success,
 		Message:  result.Message,
-		BlockId:  uint32(newID),
-		Position: blockUpdate.Position,
-		Layer:    blockUpdate.Layer,
-		Metadata: respMeta,
+		BlockId:  uint32(b.ID),
+		Position: req.Position,
+		Layer:    req.Layer,
+		Metadata: &protocol.JsonMetadata{JsonData: metaStr},
 		Effects:  result.Effects,
+		Version:  version,
+	}
+
+	gh.sendTCPMessage(connID, protocol.MessageType_BLOCK_UPDATE_RESPONSE, response)
+}
+
+// rejectStaleBlockUpdate отклоняет правку блока, изменённого после версии
+// клиента: в ответе текущий блок и его версия, ErrorMessage с кодом CONFLICT
+func (gh *GameHandlerPB) rejectStaleBlockUpdate(connID string, req *protocol.BlockUpdateRequest, current world.Block, version uint64) {
+	const reason = "Block was changed by another player"
+	response := &protocol.BlockUpdateResponseMessage{
+		Success:  false,
+		Message:  reason,
+		BlockId:  uint32(current.ID),
+		Position: req.Position,
+		Layer:    req.Layer,
+		Version:  version,
+	}
+	if len(current.Payload) > 0 {
+		if metaStr, err := protocol.MapToJsonMetadata(current.Payload); err == nil {
+			response.Metadata = &protocol.JsonMetadata{JsonData: metaStr}
+		}
 	}
+	gh.sendTCPMessage(connID, protocol.MessageType_BLOCK_UPDATE_RESPONSE, response)
+	gh.sendError(connID, protocol.ErrorCode_CONFLICT, protocol.MessageType_BLOCK_UPDATE, reason)
+}
 
+// rejectBlockUpdate отправляет клиенту отказ в обновлении блока с причиной:
+// BlockUpdateResponse для существующих клиентов и ErrorMessage с кодом ошибки
+func (gh *GameHandlerPB) rejectBlockUpdate(connID string, req *protocol.BlockUpdateRequest, code protocol.ErrorCode, reason string) {
+	response := &protocol.BlockUpdateResponseMessage{
+		Success:  false,
+		Message:  reason,
+		BlockId:  req.BlockId,
+		Position: req.Position,
+		Layer:    req.Layer,
+	}
 	gh.sendTCPMessage(connID, protocol.MessageType_BLOCK_UPDATE_RESPONSE, response)
+	gh.sendError(connID, code, protocol.MessageType_BLOCK_UPDATE, reason)
 }
 
 // handleChunkBatchRequest обрабатывает запрос пакета чанков
-func (gh *GameHandlerPB) handleChunkBatchRequest(connID string, msg *protocol.GameMessage) {
+func (gh *GameHandlerPB) handleChunkBatchRequest(ctx context.Context, connID string, msg *protocol.GameMessage) {
 	batchReq := &protocol.ChunkBatchRequest{}
 	if err := gh.serializer.DeserializePayload(msg, batchReq); err != nil {
 		log.Printf("Ошибка десериализации ChunkBatchRequest: %v", err)
+		gh.sendError(connID, protocol.ErrorCode_INVALID, msg.Type, "Malformed chunk batch request")
 		return
 	}
 
-	// Обрабатываем каждый чанк в пакете
+	requested := make([]vec.Vec2, 0, len(batchReq.Chunks))
 	for _, chunk := range batchReq.Chunks {
-		gh.sendChunkToClient(connID, int(chunk.X), int(chunk.Y))
+		if chunk != nil {
+			requested = append(requested, vec.Vec2{X: int(chunk.X), Y: int(chunk.Y)})
+		}
+	}
+	chunks, duplicates, throttled := gh.admitChunkRequests(connID, requested, true)
+	if throttled {
+		gh.rejectChunkRequests(connID, msg.Type)
+	}
+	if len(duplicates) > 0 {
+		gh.rejectDuplicateChunks(connID, msg.Type, duplicates)
+	}
+
+	// Обрабатываем каждый чанк в пакете
+	for _, chunk := range chunks {
+		if ctx.Err() != nil {
+			log.Printf("⏹️ Пакет чанков для %s прерван: %v", connID, context.Cause(ctx))
+			return
+		}
+		gh.sendChunkToClient(ctx, connID, chunk.X, chunk.Y)
 	}
 }
 
 // handleChunkRequest обрабатывает запрос чанка
-func (gh *GameHandlerPB) handleChunkRequest(connID string, msg *protocol.GameMessage) {
+func (gh *GameHandlerPB) handleChunkRequest(ctx context.Context, connID string, msg *protocol.GameMessage) {
 	chunkRequest := &protocol.ChunkRequest{}
 	if err := gh.serializer.DeserializePayload(msg, chunkRequest); err != nil {
 		log.Printf("Ошибка десериализации ChunkRequest: %v", err)
+		gh.sendError(connID, protocol.ErrorCode_INVALID, msg.Type, "Malformed chunk request")
 		return
 	}
 
@@ -878,35 +1495,56 @@ func (gh *GameHandlerPB) handleChunkRequest(connID string, msg *protocol.GameMes
 
 	if !exists {
 		log.Printf("Неавторизованный клиент запрашивает чанк: %s", connID)
+		gh.sendError(connID, protocol.ErrorCode_UNAUTHORIZED, msg.Type, "Not authorized")
 		return
 	}
 
-	// Отправляем чанк клиенту
-	gh.sendChunkToClient(connID, int(chunkRequest.ChunkX), int(chunkRequest.ChunkY))
-}
+	// Недавно отправленный чанк повторно не сериализуется, если только клиент
+	// не сообщил свою версию: тогда он ждёт дельту
+	pos := vec.Vec2{X: int(chunkRequest.ChunkX), Y: int(chunkRequest.ChunkY)}
+	chunks, duplicates, throttled := gh.admitChunkRequests(connID, []vec.Vec2{pos}, chunkRequest.KnownVersion == 0)
+	if throttled {
+		gh.rejectChunkRequests(connID, msg.Type)
+		return
+	}
+	if len(duplicates) > 0 {
+		gh.rejectDuplicateChunks(connID, msg.Type, duplicates)
+	}
+	if len(chunks) == 0 {
+		return
+	}
 
-// sendChunkToClient отправляет чанк клиенту
-func (gh *GameHandlerPB) sendChunkToClient(connID string, chunkX, chunkY int) {
-	// Искусственная задержка 40–60 мс для сглаживания потока
-	jitter := 40 + rand.Intn(21) // 40..60
-	time.Sleep(time.Duration(jitter) * time.Millisecond)
+	// Отправляем чанк клиенту: дельтой, если у клиента есть его версия
+	gh.submitChunk(ctx, chunkJob{
+		ctx:          gh.connContext(connID),
+		connID:       connID,
+		pos:          pos,
+		knownVersion: chunkRequest.KnownVersion,
+	})
+}
 
-	// Получаем чанк из мира
-	chunkPos := vec.Vec2{X: chunkX, Y: chunkY}
-	chunk := gh.worldManager.GetChunk(chunkPos)
+// encodeChunkData преобразует чанк в ChunkData с каноническим хэшем содержимого
+// и версией (version, hash = chunk.VersionedHash()) и метаданными слоя ACTIVE.
+// Вызывается из горутин пула (см. chunk_workers.go)
+func encodeChunkData(chunkPos vec.Vec2, chunk *world.Chunk, version uint64, hash world.ChunkHash) *protocol.ChunkData {
+	chunkX, chunkY := chunkPos.X, chunkPos.Y
 
 	// Сериализуем чанк в Protocol Buffers (многослойная схема)
 	chunkData := &protocol.ChunkData{
-		ChunkX: int32(chunkX),
-		ChunkY: int32(chunkY),
+		ChunkX:      int32(chunkX),
+		ChunkY:      int32(chunkY),
+		Hash:        hash[:],
+		HashVersion: world.ChunkHashVersion,
+		Version:     version,
 	}
 
+	// Прежняя CRC только по ID блоков остаётся в метаданных для старых клиентов
 	crc := crc32.NewIEEE()
 	nonEmpty := 0
 
 	// Слои: FLOOR и ACTIVE
 	layers := []*protocol.ChunkLayer{}
-	for _, layerID := range []world.BlockLayer{world.LayerFloor, world.LayerActive} {
+	for _, layerID := range world.ClientLayers {
 		layerMsg := &protocol.ChunkLayer{Layer: uint32(layerID), Rows: make([]*protocol.BlockRow, 16)}
 		for blockY := 0; blockY < 16; blockY++ {
 			row := make([]uint32, 16)
@@ -928,6 +1566,7 @@ func (gh *GameHandlerPB) sendChunkToClient(connID string, chunkX, chunkY int) {
 	blockMetadata := &protocol.ChunkBlockMetadata{BlockMetadata: make(map[string]*protocol.JsonMetadata)}
 
 	// Заполняем blockMetadata из данных чанка (только слой ACTIVE)
+	chunk.Mu.RLock()
 	for coord, metadata := range chunk.Metadata3D {
 		if coord.Layer == world.LayerActive && len(metadata) > 0 {
 			jsonStr, err := protocol.MapToJsonMetadata(metadata)
@@ -937,6 +1576,7 @@ func (gh *GameHandlerPB) sendChunkToClient(connID string, chunkX, chunkY int) {
 			}
 		}
 	}
+	chunk.Mu.RUnlock()
 
 	// Подготовка финальной карты метаданных
 	metaMap := map[string]interface{}{
@@ -952,8 +1592,7 @@ func (gh *GameHandlerPB) sendChunkToClient(connID string, chunkX, chunkY int) {
 		chunkData.Metadata = &protocol.JsonMetadata{JsonData: metadataJson}
 	}
 
-	// Отправляем чанк
-	gh.sendTCPMessage(connID, protocol.MessageType_CHUNK_DATA, chunkData)
+	return chunkData
 }
 
 // handleEntityAction обрабатывает действия сущности
@@ -961,6 +1600,7 @@ func (gh *GameHandlerPB) handleEntityAction(connID string, msg *protocol.GameMes
 	action := &protocol.EntityActionRequest{}
 	if err := gh.serializer.DeserializePayload(msg, action); err != nil {
 		log.Printf("Ошибка десериализации EntityAction: %v", err)
+		gh.sendError(connID, protocol.ErrorCode_INVALID, msg.Type, "Malformed entity action")
 		return
 	}
 
@@ -971,6 +1611,7 @@ func (gh *GameHandlerPB) handleEntityAction(connID string, msg *protocol.GameMes
 
 	if !exists {
 		log.Printf("Неавторизованный клиент выполняет действие: %s", connID)
+		gh.sendError(connID, protocol.ErrorCode_UNAUTHORIZED, msg.Type, "Not authorized")
 		return
 	}
 
@@ -978,6 +1619,7 @@ func (gh *GameHandlerPB) handleEntityAction(connID string, msg *protocol.GameMes
 	_, exists = gh.entityManager.GetEntity(entityID)
 	if !exists {
 		log.Printf("Сущность %d не найдена", entityID)
+		gh.sendError(connID, protocol.ErrorCode_INVALID, msg.Type, "Player entity not found")
 		return
 	}
 
@@ -1004,6 +1646,7 @@ func (gh *GameHandlerPB) handleEntityMove(connID string, msg *protocol.GameMessa
 	moveMsg := &protocol.EntityMoveMessage{}
 	if err := gh.serializer.DeserializePayload(msg, moveMsg); err != nil {
 		log.Printf("Ошибка десериализации EntityMove: %v", err)
+		gh.sendError(connID, protocol.ErrorCode_INVALID, msg.Type, "Malformed entity move")
 		return
 	}
 
@@ -1013,14 +1656,23 @@ func (gh *GameHandlerPB) handleEntityMove(connID string, msg *protocol.GameMessa
 	gh.mu.RUnlock()
 	if !ok {
 		log.Printf("Неавторизованный клиент перемещает сущности: %s", connID)
+		gh.sendError(connID, protocol.ErrorCode_UNAUTHORIZED, msg.Type, "Not authorized")
 		return
 	}
 
+	// Ввод считается обработанным и при отказе: коррекция ниже уже несёт его номер
+	gh.recordProcessedInput(connID, msg.Sequence)
+
+	// Наблюдатель проходит сквозь блоки и не ограничен скоростью
+	spectator := gh.isSpectator(connID)
+
 	// Для каждой сущности в сообщении
 	for _, ed := range moveMsg.Entities {
 		// Пока разрешаем перемещать только собственную сущность
 		if ed.Id != ownerID {
 			log.Printf("Игрок %d пытается переместить чужую сущность %d", ownerID, ed.Id)
+			gh.sendError(connID, protocol.ErrorCode_UNAUTHORIZED, msg.Type,
+				fmt.Sprintf("Entity %d is not controlled by this player", ed.Id))
 			continue
 		}
 
@@ -1036,25 +1688,58 @@ func (gh *GameHandlerPB) handleEntityMove(connID string, msg *protocol.GameMessa
 			Y: int(ed.Position.Y),
 		}
 
-		// Проверяем коллизии с использованием многослойной логики
-		if !gh.isPositionWalkable(targetPos) {
-			log.Printf("Сущность %d попытка переместиться в непроходимую позицию (%d,%d)", ed.Id, targetPos.X, targetPos.Y)
-			// Отправляем корректирующее сообщение владельцу, чтобы клиент откатил позицию
-			gh.sendEntityPositionCorrection(connID, ent)
+		// При включённом буфере ввода шаг применит тик (см. move_input.go)
+		if gh.bufferMoveInput(connID, ent, targetPos) {
 			continue
 		}
+		gh.applyEntityMove(connID, ent, targetPos, spectator)
+	}
+}
 
-		// Обновляем позицию
-		oldPos := ent.PrecisePos
-		ent.PrecisePos = vec.Vec2Float{X: float64(targetPos.X), Y: float64(targetPos.Y)}
-		ent.Position = targetPos
+// applyEntityMove проверяет и применяет перемещение собственной сущности игрока
+func (gh *GameHandlerPB) applyEntityMove(connID string, ent *entity.Entity, targetPos vec.Vec2, spectator bool) {
+	// За границу мира не проходит никто, включая наблюдателей
+	if !gh.worldManager.BorderAllowsMove(ent.Position, targetPos) {
+		log.Printf("Сущность %d попытка выйти за границу мира (%d,%d)", ent.ID, targetPos.X, targetPos.Y)
+		gh.dropMoveInputs(connID)
+		gh.sendEntityPositionCorrection(connID, ent)
+		return
+	}
 
-		// Сообщаем worldManager о смене BigChunk
-		gh.worldManager.ProcessEntityMovement(ent.ID, vec.Vec2{X: int(oldPos.X), Y: int(oldPos.Y)}, targetPos)
+	// Проверяем коллизии с использованием многослойной логики
+	if !spectator && !gh.isPositionWalkable(targetPos) {
+		log.Printf("Сущность %d попытка переместиться в непроходимую позицию (%d,%d)", ent.ID, targetPos.X, targetPos.Y)
+		// Очередь ввода построена от отклонённой позиции и больше не годится
+		gh.dropMoveInputs(connID)
+		// Отправляем корректирующее сообщение владельцу, чтобы клиент откатил позицию
+		gh.sendEntityPositionCorrection(connID, ent)
+		return
+	}
 
-		// Рассылаем обновление другим игрокам
-		gh.sendEntityMoveUpdate(ent)
+	if !spectator {
+		// Ускоренный эффектами игрок ходит быстрее, не нарушая предел скорости
+		gh.checkAnticheat(connID, anticheat.Action{
+			PlayerID:        ent.ID,
+			Type:            anticheat.ActionMove,
+			Position:        vec.FromVec2(targetPos),
+			SpeedMultiplier: ent.SpeedMultiplier(),
+		})
 	}
+
+	// Обновляем позицию
+	oldPos := ent.PrecisePos
+	gh.entityManager.MoveEntity(ent.ID, vec.FromVec2(targetPos))
+
+	// Сообщаем worldManager о смене BigChunk
+	gh.worldManager.ProcessEntityMovement(ent.ID, ent.Type, vec.Vec2{X: int(oldPos.X), Y: int(oldPos.Y)}, targetPos)
+
+	// Рассылаем обновление другим игрокам
+	gh.sendEntityMoveUpdate(ent)
+
+	// Если игрок ушёл из области, где ещё грузятся чанки, начинаем загрузку заново
+	gh.updateChunkStream(connID, targetPos)
+
+	gh.warnNearWorldBorder(connID, targetPos)
 }
 
 // handleChat обрабатывает сообщения чата
@@ -1070,6 +1755,7 @@ func (gh *GameHandlerPB) handleChat(connID string, msg *protocol.GameMessage) {
 
 	if !exists || !sessionExists {
 		log.Printf("Неавторизованный клиент отправляет сообщение: %s", connID)
+		gh.sendError(connID, protocol.ErrorCode_UNAUTHORIZED, msg.Type, "Not authorized")
 		return
 	}
 
@@ -1091,29 +1777,7 @@ func (gh *GameHandlerPB) sendWorldDataToPlayer(connID string, playerID uint64) {
 	gh.sendInitialChunks(connID, playerID)
 
 	// Отправляем сведения о текущем состоянии мира
-	worldData := map[string]interface{}{
-		"time_of_day": 0.5,
-		"weather":     "clear",
-		"season":      "summer",
-		"game_mode":   "survival",
-		"world_id":    1234,
-		"world_name":  "default",
-	}
-
-	// Сериализуем метаданные в JSON
-	jsonData, err := json.Marshal(worldData)
-	if err != nil {
-		log.Printf("Ошибка сериализации данных мира: %v", err)
-		return
-	}
-
-	// Создаем сообщение о состоянии мира с метаданными
-	worldMetadata := &protocol.JsonMetadata{
-		JsonData: string(jsonData),
-	}
-
-	// Отправляем метаданные мира через сообщение с метаданными
-	gh.sendTCPMessage(connID, protocol.MessageType_CHUNK_DATA, worldMetadata)
+	gh.sendWorldState(connID)
 
 	// Отправляем данные о других игроках в зоне видимости
 	// Получаем сущность игрока
@@ -1122,16 +1786,20 @@ func (gh *GameHandlerPB) sendWorldDataToPlayer(connID string, playerID uint64) {
 		return
 	}
 
-	// Получаем сущности поблизости
-	nearbyEntities := gh.GetEntitiesInRange(playerEntity.Position, 100.0)
+	// Получаем сущности в зоне видимости игрока
+	nearbyEntities := gh.GetEntitiesInRange(playerEntity.Position, entityViewRange(gh.viewDistanceFor(connID)))
 
 	// Формируем данные для отправки
 	var spawnedEntities []*protocol.EntityData
+	visibility := gh.visibilitySnapshot()
 
 	for _, entity := range nearbyEntities {
 		if entity.ID == playerID {
 			continue // Пропускаем собственную сущность
 		}
+		if !gh.canSee(visibility, connID, playerID, entity) {
+			continue // Скрытые сущности видны только администраторам
+		}
 
 		entityData := &protocol.EntityData{
 			Id:        entity.ID,
@@ -1171,63 +1839,35 @@ func (gh *GameHandlerPB) sendWorldDataToPlayer(connID string, playerID uint64) {
 			Entities: spawnedEntities,
 		}
 
-		gh.sendTCPMessage(connID, protocol.MessageType_ENTITY_MOVE, spawnMsg)
+		gh.sendTCPMessage(connID, protocol.MessageType_ENTITY_MOVE, gh.stampMove(spawnMsg))
 	}
 }
 
-// sendInitialChunks отправляет начальные чанки игроку
-func (gh *GameHandlerPB) sendInitialChunks(connID string, playerID uint64) {
+// sendInitialChunks запускает отправку начальных чанков игроку.
+// Чанки ставятся в очередь по удалённости от игрока, поэтому область вокруг него загружается первой.
+func (gh *GameHandlerPB) sendInitialChunks(connID string, playerID uint64) *chunkStream {
 	// Получаем сущность игрока
 	playerEntity, exists := gh.entityManager.GetEntity(playerID)
 	if !exists {
-		return
+		return nil
 	}
 
-	// Получаем координаты чанка игрока
-	playerChunkCoords := playerEntity.Position.ToChunkCoords()
-
-	// Отправляем чанки в радиусе видимости (5 чанков)
-	chunkRadius := 5
-
-	for x := playerChunkCoords.X - chunkRadius; x <= playerChunkCoords.X+chunkRadius; x++ {
-		for y := playerChunkCoords.Y - chunkRadius; y <= playerChunkCoords.Y+chunkRadius; y++ {
-			chunkPos := vec.Vec2{X: x, Y: y}
-
-			// Получаем данные чанка из мира
-			chunk := gh.worldManager.GetChunk(chunkPos)
-			if chunk == nil {
-				continue
-			}
-
-			// Преобразуем данные чанка в протокольный формат
-			chunkData := &protocol.ChunkData{
-				ChunkX: int32(x),
-				ChunkY: int32(y),
-			}
-
-			// Слои: FLOOR и ACTIVE
-			layers := []*protocol.ChunkLayer{}
-			for _, layerID := range []world.BlockLayer{world.LayerFloor, world.LayerActive} {
-				layerMsg := &protocol.ChunkLayer{Layer: uint32(layerID), Rows: make([]*protocol.BlockRow, 16)}
-				for blockY := 0; blockY < 16; blockY++ {
-					row := make([]uint32, 16)
-					for blockX := 0; blockX < 16; blockX++ {
-						bID := uint32(chunk.GetBlockLayer(layerID, vec.Vec2{X: blockX, Y: blockY}))
-						row[blockX] = bID
-					}
-					layerMsg.Rows[blockY] = &protocol.BlockRow{BlockIds: row}
-				}
-				layers = append(layers, layerMsg)
-			}
-			chunkData.Layers = layers
+	// Отправляем чанки в радиусе видимости игрока
+	return gh.startChunkStream(connID, playerEntity.Position.ToChunkCoords(), gh.viewDistanceFor(connID))
+}
 
-			// Отправляем данные чанка
-			gh.sendTCPMessage(connID, protocol.MessageType_CHUNK_DATA, chunkData)
+// takeUnreconciledInput сообщает, есть ли обработанный ввод, о котором клиент ещё не знает,
+// и отмечает его как отправленный
+func (gh *GameHandlerPB) takeUnreconciledInput(connID string) bool {
+	gh.mu.Lock()
+	defer gh.mu.Unlock()
 
-			// Добавляем небольшую задержку, чтобы не перегружать клиента
-			time.Sleep(10 * time.Millisecond)
-		}
+	session, ok := gh.sessions[connID]
+	if !ok || session.LastProcessedInput == session.reconciledInput {
+		return false
 	}
+	session.reconciledInput = session.LastProcessedInput
+	return true
 }
 
 // sendWorldUpdates отправляет периодические обновления игрового мира всем клиентам
@@ -1239,7 +1879,9 @@ func (gh *GameHandlerPB) sendWorldUpdates() {
 	for connID, playerID := range gh.playerEntities {
 		playerConnections[connID] = playerID
 	}
+	limits := gh.entityUpdates
 	gh.mu.RUnlock()
+	visibility := gh.visibilitySnapshot()
 
 	// Для каждого клиента формируем и отправляем список видимых сущностей
 	for connID, playerID := range playerConnections {
@@ -1250,17 +1892,28 @@ func (gh *GameHandlerPB) sendWorldUpdates() {
 		}
 
 		// Получаем все сущности в радиусе видимости от игрока
-		// (используем радиус 100 блоков как зону видимости)
-		visibleEntities := gh.GetEntitiesInRange(playerEntity.Position, 100.0)
+		// (радиус зависит от согласованной дальности видимости)
+		visibleEntities := gh.GetEntitiesInRange(playerEntity.Position, entityViewRange(gh.viewDistanceFor(connID)))
 
 		// Формируем список данных сущностей для отправки
 		entityDataList := make([]*protocol.EntityData, 0, len(visibleEntities))
 
+		// Собственную сущность отправляем только после нового обработанного ввода:
+		// клиенту нужны поля сверки предсказания, а не повтор своей же позиции каждый тик
+		if gh.takeUnreconciledInput(connID) {
+			entityDataList = append(entityDataList, gh.ownerEntityData(connID, playerEntity))
+		}
+		own := len(entityDataList)
+
 		for _, entity := range visibleEntities {
-			// Не отправляем информацию о собственной сущности игрока
+			// Собственная сущность уже обработана выше
 			if entity.ID == playerID {
 				continue
 			}
+			// Скрытые сущности и невидимые администраторы не попадают к обычным игрокам
+			if !gh.canSee(visibility, connID, playerID, entity) {
+				continue
+			}
 
 			// Создаем данные сущности
 			entityData := &protocol.EntityData{
@@ -1282,29 +1935,45 @@ func (gh *GameHandlerPB) sendWorldUpdates() {
 			entityDataList = append(entityDataList, entityData)
 		}
 
-		// ИСПРАВЛЕНИЕ: Отправляем сообщение только если есть сущности для отправки
-		// Это предотвращает отправку пустых ENTITY_MOVE сообщений каждый тик
-		if len(entityDataList) > 0 {
-			updateMsg := &protocol.EntityMoveMessage{
-				Entities: entityDataList,
+		// Ближайшие сущности идут первыми: при лимите сообщений откладываются
+		// дальние, а отложенные ранее поднимаются в очереди
+		gh.mu.RLock()
+		skipped := gh.skippedEntities[connID]
+		gh.mu.RUnlock()
+		sortByPriority(entityDataList[own:], playerEntity.Position, skipped)
+		batches, deferred := limits.split(entityDataList)
+		if deferred > 0 {
+			deferredEntityUpdates.Add(float64(deferred))
+		}
+		if skipped != nil || deferred > 0 {
+			next := skippedAfter(skipped, entityDataList[len(entityDataList)-deferred:])
+			gh.mu.Lock()
+			if _, online := gh.playerEntities[connID]; online && next != nil {
+				gh.skippedEntities[connID] = next
+			} else {
+				delete(gh.skippedEntities, connID)
 			}
+			gh.mu.Unlock()
+		}
 
-			// Добавляем детальное логирование для диагностики (только первые 5 сущностей)
-			log.Printf("🔄 Отправка ENTITY_MOVE клиенту %s: %d сущностей", connID, len(entityDataList))
-			maxLog := len(entityDataList)
-			if maxLog > 3 { // Ограничиваем детальный лог до 3 сущностей
-				maxLog = 3
-			}
+		// ИСПРАВЛЕНИЕ: Отправляем сообщение только если есть сущности для отправки
+		// Это предотвращает отправку пустых ENTITY_MOVE сообщений каждый тик
+		if len(batches) > 0 {
+			// Добавляем детальное логирование для диагностики (только первые 3 сущности)
+			log.Printf("🔄 Отправка ENTITY_MOVE клиенту %s: %d сущностей в %d сообщениях (отложено: %d)",
+				connID, len(entityDataList)-deferred, len(batches), deferred)
+			maxLog := min(len(entityDataList), 3)
 			for i := 0; i < maxLog; i++ {
 				entityData := entityDataList[i]
 				log.Printf("  [%d] Entity ID=%d, Type=%v, Pos=(%d,%d)",
 					i, entityData.Id, entityData.Type, entityData.Position.X, entityData.Position.Y)
 			}
-			if len(entityDataList) > maxLog {
-				log.Printf("  ... и еще %d сущностей", len(entityDataList)-maxLog)
-			}
 
-			gh.sendTCPMessage(connID, protocol.MessageType_ENTITY_MOVE, updateMsg)
+			for _, batch := range batches {
+				gh.sendTCPMessage(connID, protocol.MessageType_ENTITY_MOVE, gh.stampMove(&protocol.EntityMoveMessage{
+					Entities: batch,
+				}))
+			}
 		} else {
 			// Логируем случаи, когда сообщение не отправляется (реже для снижения спама)
 			if gh.tickCounter%100 == 0 { // Логируем каждые 100 тиков = раз в 5 секунд
@@ -1314,8 +1983,24 @@ func (gh *GameHandlerPB) sendWorldUpdates() {
 	}
 }
 
-// SpawnEntity реализует интерфейс EntityAPI - изменяем сигнатуру
+// SpawnEntity реализует интерфейс EntityAPI - изменяем сигнатуру.
+// Мировые спавны ограничены по частоте (см. SetWorldSpawnRateLimit):
+// отклонённый спавн возвращает 0
 func (gh *GameHandlerPB) SpawnEntity(entityType entity.EntityType, position vec.Vec2) uint64 {
+	if !gh.entityManager.AdmitSpawn(entityType) {
+		return 0
+	}
+
+	// Предел сущностей: место освобождается за счёт самых старых предметов
+	evicted, admitted := gh.worldManager.AdmitEntity(entityType, position)
+	if !admitted {
+		return 0
+	}
+	for _, itemID := range evicted {
+		gh.forgetDroppedItem(itemID)
+		gh.removeDroppedItem(itemID, protocol.DespawnReason_DESPAWN_REASON_EXPIRED)
+	}
+
 	// Генерируем ID для новой сущности
 	entityID := gh.generateEntityID()
 
@@ -1325,6 +2010,15 @@ func (gh *GameHandlerPB) SpawnEntity(entityType entity.EntityType, position vec.
 
 // spawnEntityWithID - внутренний метод для создания сущности с указанным ID
 func (gh *GameHandlerPB) spawnEntityWithID(entityType entity.EntityType, position vec.Vec2, entityID uint64) uint64 {
+	var out outbox
+	gh.addEntityWithID(entityType, position, entityID, &out)
+	gh.flush(&out)
+	return entityID
+}
+
+// addEntityWithID создаёт сущность и откладывает в out оповещение о её появлении.
+// Может вызываться под gh.mu
+func (gh *GameHandlerPB) addEntityWithID(entityType entity.EntityType, position vec.Vec2, entityID uint64, out *outbox) {
 	log.Printf("Создание сущности типа %d с ID %d в позиции (%d, %d)",
 		entityType, entityID, position.X, position.Y)
 
@@ -1349,74 +2043,42 @@ func (gh *GameHandlerPB) spawnEntityWithID(entityType entity.EntityType, positio
 		Entity: entityData,
 	}
 
-	gh.broadcastMessage(protocol.MessageType_ENTITY_SPAWN, entitySpawn)
-
-	return entityID
+	out.broadcast(protocol.MessageType_ENTITY_SPAWN, entitySpawn)
 }
 
 // DespawnEntity удаляет сущность из мира
 func (gh *GameHandlerPB) DespawnEntity(entityID uint64) {
 	// Временная заглушка до полной реализации
 	log.Printf("Удаление сущности с ID %d", entityID)
+	gh.forgetDroppedItem(entityID)
 
 	// Оповещаем всех игроков
-	despawnMsg := &protocol.EntityDespawnMessage{
-		EntityId: entityID,
-		Reason:   "deleted",
-	}
-	gh.broadcastMessage(protocol.MessageType_ENTITY_DESPAWN, despawnMsg)
-}
-
-// SendBlockUpdate отправляет обновление блока всем клиентам
-func (gh *GameHandlerPB) SendBlockUpdate(blockPos vec.Vec2, block world.Block) {
-	// Создаем сообщение об обновлении блока
-	blockData := &protocol.BlockData{
-		Position: &protocol.Vec2{
-			X: int32(blockPos.X),
-			Y: int32(blockPos.Y),
-		},
-		BlockId: uint32(block.ID),
-	}
-
-	// Добавляем метаданные, если они есть
-	if block.Payload != nil && len(block.Payload) > 0 {
-		jsonStr, err := protocol.MapToJsonMetadata(block.Payload)
-		if err == nil {
-			blockData.Metadata = &protocol.JsonMetadata{
-				JsonData: jsonStr,
-			}
-		}
-	}
-
-	blockUpdate := &protocol.BlockUpdateMessage{
-		Blocks: []*protocol.BlockData{blockData},
-	}
-
-	// Отправляем всем клиентам
-	gh.broadcastMessage(protocol.MessageType_BLOCK_UPDATE, blockUpdate)
+	gh.despawnEntity(entityID, protocol.DespawnReason_DESPAWN_REASON_REMOVED)
 }
 
 // broadcastMessage отправляет сообщение всем подключенным клиентам
 func (gh *GameHandlerPB) broadcastMessage(msgType protocol.MessageType, payload proto.Message) {
-	if gh.tcpServer != nil {
-		gh.tcpServer.broadcastMessage(msgType, payload)
+	if gh.sender != nil {
+		gh.sender.Broadcast(msgType, payload)
 	}
 }
 
 // sendTCPMessage отправляет сообщение конкретному клиенту через TCP
 func (gh *GameHandlerPB) sendTCPMessage(connID string, msgType protocol.MessageType, payload proto.Message) {
-	if gh.tcpServer != nil {
-		gh.tcpServer.sendToClient(connID, msgType, payload)
+	if gh.sender != nil {
+		gh.sender.SendToClient(connID, msgType, payload)
 	}
 }
 
-// generateEntityID генерирует уникальный ID для сущности
+// generateEntityID генерирует уникальный ID для сущности через общий аллокатор мира
 func (gh *GameHandlerPB) generateEntityID() uint64 {
-	gh.mu.Lock()
-	defer gh.mu.Unlock()
+	return gh.worldManager.GenerateEntityID()
+}
 
-	gh.lastEntityID++
-	return gh.lastEntityID
+// SetEntityIDAllocator задаёт аллокатор ID сущностей региона для мира и менеджера сущностей
+func (gh *GameHandlerPB) SetEntityIDAllocator(allocator *entity.EntityIDAllocator) {
+	gh.worldManager.SetEntityIDAllocator(allocator)
+	gh.entityManager.SetIDAllocator(allocator)
 }
 
 // SendMessage реализует интерфейс EntityAPI
@@ -1441,6 +2103,12 @@ func (gh *GameHandlerPB) SendMessage(entityID uint64, messageType string, data i
 	log.Printf("Отправка сообщения типа %s игроку %s", messageType, connID)
 }
 
+// IsPositionWalkable сообщает, может ли игрок встать на позицию (та же
+// проверка, что и при перемещении)
+func (gh *GameHandlerPB) IsPositionWalkable(pos vec.Vec2) bool {
+	return gh.isPositionWalkable(pos)
+}
+
 // isPositionWalkable применяет логику слоёв: сначала ACTIVE, затем FLOOR.
 func (gh *GameHandlerPB) isPositionWalkable(pos vec.Vec2) bool {
 	// Проверяем ACTIVE слой
@@ -1508,6 +2176,9 @@ func (gh *GameHandlerPB) processEntityAction(actorID uint64, action *protocol.En
 	case protocol.EntityActionType_ACTION_RESPAWN:
 		return gh.handleRespawnAction(actor, action)
 
+	case protocol.EntityActionType_ACTION_SPECTATOR:
+		return gh.handleSpectatorAction(actor, action)
+
 	default:
 		return false, "Неизвестный тип действия", false
 	}
@@ -1594,9 +2265,13 @@ func (gh *GameHandlerPB) handleAttackAction(actor *entity.Entity, action *protoc
 	damage := 10
 
 	// Применяем урон к цели
-	if behavior, ok := gh.entityManager.GetBehavior(target.Type); ok {
+	if behavior, ok := gh.entityManager.BehaviorFor(target); ok {
 		if behavior.OnDamage(gh, target, damage, actor) {
-			// Цель получила урон
+			// Цель погибла; игроки остаются в мире до возрождения (ACTION_RESPAWN)
+			if target.Type != entity.EntityTypePlayer && gh.entityManager.DespawnEntity(target.ID, gh) {
+				gh.forgetDroppedItem(target.ID)
+				gh.despawnEntity(target.ID, protocol.DespawnReason_DESPAWN_REASON_DEATH)
+			}
 			return true, "Атака успешна", true
 		} else {
 			return false, "Атака заблокирована", false
@@ -1651,8 +2326,14 @@ func (gh *GameHandlerPB) handlePickupAction(actor *entity.Entity, action *protoc
 	}
 
 	// Удаляем предмет из мира
-	gh.DespawnEntity(target.ID)
+	if !gh.entityManager.DespawnEntity(target.ID, gh) {
+		return false, "Предмет уже подобран", false
+	}
+	gh.despawnEntity(target.ID, protocol.DespawnReason_DESPAWN_REASON_PICKED_UP)
 
+	if count := gh.takeDroppedItem(target.ID); count > 1 {
+		return true, fmt.Sprintf("Подобрано предметов: %d", count), true
+	}
 	return true, "Предмет подобран", true
 }
 
@@ -1679,8 +2360,12 @@ func (gh *GameHandlerPB) handleDropAction(actor *entity.Entity, action *protocol
 		return false, "Позиция занята", false
 	}
 
-	// Создаем предмет в мире
-	gh.SpawnEntity(entity.EntityTypeItem, dropPos)
+	// Создаем предмет в мире; он остаётся за игроком и после его отключения
+	itemID := gh.dropItem(*action.ItemId, 1, dropPos)
+	if itemID == 0 {
+		return false, "Сервер перегружен, попробуйте позже", false
+	}
+	gh.ClaimEntity(itemID, actor.ID, entity.DefaultOwnerPolicy(entity.EntityTypeItem))
 
 	return true, "Предмет выброшен", true
 }
@@ -1692,6 +2377,15 @@ func (gh *GameHandlerPB) handleBuildPlaceAction(actor *entity.Entity, action *pr
 	}
 
 	blockPos := vec.Vec2{X: int(action.Position.X), Y: int(action.Position.Y)}
+	if !gh.worldManager.InsideWorldBorder(blockPos) {
+		return false, "За границей мира", false
+	}
+
+	// Блоки в привате изменяют только его владелец и администраторы
+	connID, _ := gh.connForEntity(actor.ID)
+	if message, denied := gh.claimDenial(connID, blockPos); denied {
+		return false, message, false
+	}
 
 	// Проверяем расстояние
 	distance := gh.calculateDistance(actor.Position, blockPos)
@@ -1713,7 +2407,9 @@ func (gh *GameHandlerPB) handleBuildPlaceAction(actor *entity.Entity, action *pr
 	}
 
 	// Размещаем блок
-	gh.worldManager.SetBlock(blockPos, world.NewBlock(blockID))
+	placed := world.NewBlock(blockID)
+	gh.worldManager.SetBlock(blockPos, placed)
+	gh.publishBlockAudit(connID, actor.ID, blockPos, world.LayerActive, "place", currentBlock, placed)
 
 	return true, "Блок размещён", true
 }
@@ -1725,6 +2421,15 @@ func (gh *GameHandlerPB) handleBuildBreakAction(actor *entity.Entity, action *pr
 	}
 
 	blockPos := vec.Vec2{X: int(action.Position.X), Y: int(action.Position.Y)}
+	if !gh.worldManager.InsideWorldBorder(blockPos) {
+		return false, "За границей мира", false
+	}
+
+	// Блоки в привате изменяют только его владелец и администраторы
+	connID, _ := gh.connForEntity(actor.ID)
+	if message, denied := gh.claimDenial(connID, blockPos); denied {
+		return false, message, false
+	}
 
 	// Проверяем расстояние
 	distance := gh.calculateDistance(actor.Position, blockPos)
@@ -1748,10 +2453,12 @@ func (gh *GameHandlerPB) handleBuildBreakAction(actor *entity.Entity, action *pr
 	}
 
 	// Ломаем блок
-	gh.worldManager.SetBlock(blockPos, world.NewBlock(block.AirBlockID))
+	broken := world.NewBlock(block.AirBlockID)
+	gh.worldManager.SetBlock(blockPos, broken)
+	gh.publishBlockAudit(connID, actor.ID, blockPos, world.LayerActive, "break", currentBlock, broken)
 
-	// Можно добавить выпадение предметов
-	gh.SpawnEntity(entity.EntityTypeItem, blockPos)
+	// Выпадает предмет, соответствующий блоку
+	gh.dropItem(uint32(currentBlock.ID), 1, blockPos)
 
 	return true, "Блок сломан", true
 }
@@ -1769,12 +2476,21 @@ func (gh *GameHandlerPB) handleRespawnAction(actor *entity.Entity, action *proto
 		return false, "Игрок уже жив", false
 	}
 
-	// Возрождаем игрока на спавне
+	// Возрождаем игрока на спавне: перемещение через менеджер обновляет
+	// пространственный индекс
 	spawnPos := gh.GetDefaultSpawnPosition()
-	actor.Position = vec.Vec2{X: int(spawnPos.X), Y: int(spawnPos.Y)}
-	actor.PrecisePos = vec.Vec2Float{X: float64(spawnPos.X), Y: float64(spawnPos.Y)}
+	gh.entityManager.MoveEntity(actor.ID, vec.Vec2Float{X: float64(spawnPos.X), Y: float64(spawnPos.Y)})
 	actor.Active = true
 
+	// Буферизованные шаги и история античита относятся к месту гибели:
+	// переход на спавн - не телепорт
+	if connID, ok := gh.connForEntity(actor.ID); ok {
+		gh.dropMoveInputs(connID)
+	}
+	gh.mu.Lock()
+	gh.resetAnticheatLocked(actor.ID, spawnPos.ToVec2())
+	gh.mu.Unlock()
+
 	return true, "Игрок возрождён", true
 }
 
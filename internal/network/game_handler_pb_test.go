@@ -0,0 +1,976 @@
+package network
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/anticheat"
+	"github.com/annel0/mmo-game/internal/auth"
+	"github.com/annel0/mmo-game/internal/protocol"
+	"github.com/annel0/mmo-game/internal/storage"
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/annel0/mmo-game/internal/world"
+	"github.com/annel0/mmo-game/internal/world/block"
+	_ "github.com/annel0/mmo-game/internal/world/block/implementations"
+	"github.com/annel0/mmo-game/internal/world/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+// testClient перехватывает сообщения, которые обработчик отправляет в TCP-соединение
+type testClient struct {
+	connID   string
+	messages chan *protocol.GameMessage
+}
+
+// newTestGameHandler создаёт GameHandlerPB с in-memory зависимостями и TCP-сервером без слушателя
+func newTestGameHandler(t *testing.T) *GameHandlerPB {
+	t.Helper()
+
+	userRepo, err := auth.NewMemoryUserRepo()
+	require.NoError(t, err)
+
+	gh := NewGameHandlerPB(world.NewWorldManager(1234), entity.NewEntityManager(), userRepo)
+	gh.SetGameAuthenticator(auth.NewGameAuthenticator(userRepo, []byte("test-secret")))
+	gh.SetTCPServer(&TCPServerPB{
+		connections:     make(map[string]*TCPConnectionPB),
+		connectionsByIP: make(map[string]int32),
+		gameHandler:     gh,
+		serializer:      createMessageSerializer(),
+	})
+	return gh
+}
+
+// connectTestClient регистрирует соединение на базе net.Pipe и читает всё, что шлёт сервер
+func connectTestClient(t *testing.T, gh *GameHandlerPB, connID string) *testClient {
+	t.Helper()
+
+	serverSide, clientSide := net.Pipe()
+	ctx, cancel := context.WithCancel(context.Background())
+	conn := &TCPConnectionPB{
+		id:         connID,
+		conn:       serverSide,
+		server:     gh.tcpServer,
+		ctx:        ctx,
+		cancel:     cancel,
+		serializer: gh.tcpServer.serializer,
+	}
+
+	gh.tcpServer.mu.Lock()
+	gh.tcpServer.connections[connID] = conn
+	gh.tcpServer.limits.acquire()
+	gh.tcpServer.mu.Unlock()
+
+	client := &testClient{connID: connID, messages: make(chan *protocol.GameMessage, 4096)}
+	go func() {
+		header := make([]byte, 4)
+		for {
+			if _, err := io.ReadFull(clientSide, header); err != nil {
+				return
+			}
+			body := make([]byte, binary.BigEndian.Uint32(header))
+			if _, err := io.ReadFull(clientSide, body); err != nil {
+				return
+			}
+			msg, err := gh.tcpServer.serializer.DeserializeMessage(body)
+			if err != nil {
+				continue
+			}
+			select {
+			case client.messages <- msg:
+			default:
+			}
+		}
+	}()
+
+	t.Cleanup(func() {
+		// Останавливаем фоновую отправку чанков до закрытия соединения
+		if stream := gh.cancelChunkStream(connID); stream != nil {
+			<-stream.done
+		}
+		cancel()
+		serverSide.Close()
+		clientSide.Close()
+	})
+	return client
+}
+
+// addTestSession регистрирует авторизованного игрока без полного handshake
+func addTestSession(gh *GameHandlerPB, connID string, userID, entityID uint64, pos vec.Vec2) *Session {
+	session := &Session{
+		UserID:       userID,
+		EntityID:     entityID,
+		Username:     connID,
+		LastActivity: gh.now(),
+	}
+
+	gh.mu.Lock()
+	gh.sessions[connID] = session
+	gh.playerEntities[connID] = entityID
+	gh.mu.Unlock()
+
+	gh.spawnEntityWithID(entity.EntityTypePlayer, pos, entityID)
+	return session
+}
+
+// newGameMessage упаковывает payload в GameMessage так же, как это делает клиент
+func newGameMessage(t *testing.T, msgType protocol.MessageType, payload proto.Message) *protocol.GameMessage {
+	t.Helper()
+
+	data, err := proto.Marshal(payload)
+	require.NoError(t, err)
+	return &protocol.GameMessage{Type: msgType, Payload: data}
+}
+
+// expect ждёт первое сообщение указанного типа и декодирует его в out
+func (c *testClient) expect(t *testing.T, msgType protocol.MessageType, out proto.Message) {
+	t.Helper()
+
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case msg := <-c.messages:
+			if msg.Type != msgType {
+				continue
+			}
+			require.NoError(t, proto.Unmarshal(msg.Payload, out))
+			return
+		case <-timeout:
+			t.Fatalf("сообщение %v не получено", msgType)
+		}
+	}
+}
+
+// drain вычитывает накопившиеся сообщения и возвращает число сообщений указанного типа
+func (c *testClient) drain(msgType protocol.MessageType) int {
+	count := 0
+	for {
+		select {
+		case msg := <-c.messages:
+			if msg.Type == msgType {
+				count++
+			}
+		case <-time.After(200 * time.Millisecond):
+			return count
+		}
+	}
+}
+
+func TestHandleAuth_CompatibleProtocolVersion(t *testing.T) {
+	gh := newTestGameHandler(t)
+	client := connectTestClient(t, gh, "conn-1")
+
+	password := "ChangeMe123!"
+	msg := newGameMessage(t, protocol.MessageType_AUTH, &protocol.AuthMessage{
+		Username:        "admin",
+		Password:        &password,
+		ProtocolVersion: ProtocolVersion,
+	})
+	done := make(chan struct{})
+	go func() {
+		gh.HandleMessage(client.connID, msg)
+		close(done)
+	}()
+	// Дожидаемся отправки начальных данных мира, чтобы генератор не работал параллельно со следующими тестами
+	t.Cleanup(func() { <-done })
+
+	resp := &protocol.AuthResponseMessage{}
+	client.expect(t, protocol.MessageType_AUTH_RESPONSE, resp)
+	assert.True(t, resp.Success, resp.Message)
+	assert.Equal(t, ProtocolVersion, resp.MinProtocolVersion)
+	assert.Equal(t, ProtocolVersion, resp.MaxProtocolVersion)
+	assert.Equal(t, uint32(DefaultViewDistance), resp.ViewDistance)
+}
+
+func TestHandleAuth_IncompatibleProtocolVersion(t *testing.T) {
+	gh := newTestGameHandler(t)
+	gh.SetProtocolVersionRange(ProtocolVersionRange{Min: 2, Max: 3})
+	client := connectTestClient(t, gh, "conn-1")
+
+	password := "ChangeMe123!"
+	msg := newGameMessage(t, protocol.MessageType_AUTH, &protocol.AuthMessage{
+		Username:        "admin",
+		Password:        &password,
+		ProtocolVersion: 1,
+	})
+	go gh.HandleMessage(client.connID, msg)
+
+	resp := &protocol.AuthResponseMessage{}
+	client.expect(t, protocol.MessageType_AUTH_RESPONSE, resp)
+	assert.False(t, resp.Success)
+	assert.Contains(t, resp.Message, "2-3")
+	assert.Equal(t, uint32(2), resp.MinProtocolVersion)
+	assert.Equal(t, uint32(3), resp.MaxProtocolVersion)
+	assert.False(t, gh.IsSessionValid("conn-1"), "сессия не должна создаваться")
+}
+
+// tokenProvider - провайдер аутентификации, принимающий один токен
+type tokenProvider struct{ token string }
+
+func (p tokenProvider) Authenticate(_ context.Context, users auth.UserRepository, username, secret string) (*auth.User, error) {
+	if secret != p.token {
+		return nil, auth.ErrInvalidCredentials
+	}
+	return users.GetUserByUsername(username)
+}
+
+func TestHandleAuth_ProviderDecidesSession(t *testing.T) {
+	gh := newTestGameHandler(t)
+	gh.SetAuthProvider(tokenProvider{token: "valid-token"})
+	client := connectTestClient(t, gh, "conn-1")
+
+	login := func(secret string) *protocol.AuthResponseMessage {
+		msg := newGameMessage(t, protocol.MessageType_AUTH, &protocol.AuthMessage{
+			Username:        "admin",
+			Password:        &secret,
+			ProtocolVersion: ProtocolVersion,
+		})
+		done := make(chan struct{})
+		go func() {
+			gh.HandleMessage(client.connID, msg)
+			close(done)
+		}()
+		t.Cleanup(func() { <-done })
+
+		resp := &protocol.AuthResponseMessage{}
+		client.expect(t, protocol.MessageType_AUTH_RESPONSE, resp)
+		return resp
+	}
+
+	// Пароль локальной учётной записи больше не подходит
+	resp := login("ChangeMe123!")
+	assert.False(t, resp.Success)
+	assert.False(t, gh.IsSessionValid("conn-1"), "сессия не должна создаваться")
+
+	resp = login("valid-token")
+	assert.True(t, resp.Success, resp.Message)
+	assert.True(t, gh.IsSessionValid("conn-1"))
+}
+
+func TestIdleTimeout_WarnThenKick(t *testing.T) {
+	gh := newTestGameHandler(t)
+	positions := storage.NewMemoryPositionRepo()
+	gh.SetPositionRepo(positions)
+
+	now := time.Unix(1_700_000_000, 0)
+	gh.now = func() time.Time { return now }
+	gh.SetIdleTimeout(10*time.Minute, time.Minute)
+
+	client := connectTestClient(t, gh, "conn-1")
+	session := addTestSession(gh, "conn-1", 42, 7, vec.Vec2{X: 3, Y: 4})
+
+	// До порога предупреждения ничего не происходит
+	now = now.Add(8 * time.Minute)
+	gh.checkIdlePlayers()
+	assert.False(t, session.idleWarned)
+
+	// Игровое действие сбрасывает таймер
+	gh.touchActivity("conn-1")
+	now = now.Add(8 * time.Minute)
+	gh.checkIdlePlayers()
+	assert.False(t, session.idleWarned)
+
+	// За минуту до отключения приходит предупреждение
+	now = now.Add(time.Minute)
+	gh.checkIdlePlayers()
+	warning := &protocol.ServerMessage{}
+	client.expect(t, protocol.MessageType_SERVER_MESSAGE, warning)
+	assert.Equal(t, ServerMessageIdleWarning, warning.Code)
+	assert.True(t, gh.IsSessionValid("conn-1"))
+
+	now = now.Add(59 * time.Second)
+	gh.checkIdlePlayers()
+	assert.True(t, gh.IsSessionValid("conn-1"))
+
+	// По истечении таймаута игрок отключается, позиция сохраняется
+	now = now.Add(time.Second)
+	gh.checkIdlePlayers()
+	kick := &protocol.ServerMessage{}
+	client.expect(t, protocol.MessageType_SERVER_MESSAGE, kick)
+	assert.Equal(t, ServerMessageIdleKick, kick.Code)
+
+	assert.Eventually(t, func() bool { return !gh.IsSessionValid("conn-1") }, time.Second, 10*time.Millisecond)
+	saved, found, err := positions.Load(context.Background(), 42)
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.Equal(t, vec.Vec3{X: 3, Y: 4, Z: 1}, saved)
+}
+
+func TestResolveViewDistance(t *testing.T) {
+	gh := newTestGameHandler(t)
+	gh.SetMaxViewDistance(6)
+
+	assert.Equal(t, DefaultViewDistance, gh.resolveViewDistance(0), "0 - дальность по умолчанию")
+	assert.Equal(t, 2, gh.resolveViewDistance(2))
+	assert.Equal(t, 6, gh.resolveViewDistance(20), "запрос ограничивается максимумом сервера")
+}
+
+func TestSendInitialChunks_RespectsViewDistance(t *testing.T) {
+	gh := newTestGameHandler(t)
+
+	countChunks := func(connID string, entityID uint64, distance int) int {
+		client := connectTestClient(t, gh, connID)
+		session := addTestSession(gh, connID, entityID, entityID, vec.Vec2{X: 0, Y: 0})
+		gh.mu.Lock()
+		session.ViewDistance = distance
+		gh.mu.Unlock()
+
+		stream := gh.sendInitialChunks(connID, entityID)
+		require.NotNil(t, stream)
+		<-stream.done
+		return client.drain(protocol.MessageType_CHUNK_DATA)
+	}
+
+	near := countChunks("conn-near", 1, 2)
+	far := countChunks("conn-far", 2, 8)
+
+	assert.Equal(t, 25, near)
+	assert.Equal(t, 289, far)
+	assert.Less(t, near, far)
+}
+
+func TestSendWorldUpdates_EntityRangeFollowsViewDistance(t *testing.T) {
+	gh := newTestGameHandler(t)
+
+	near := connectTestClient(t, gh, "conn-near")
+	addTestSession(gh, "conn-near", 1, 1, vec.Vec2{X: 0, Y: 0}).ViewDistance = 2
+	far := connectTestClient(t, gh, "conn-far")
+	addTestSession(gh, "conn-far", 2, 2, vec.Vec2{X: 0, Y: 0}).ViewDistance = 8
+
+	// Сущность на расстоянии 60 блоков: дальше радиуса 2 чанков (40), ближе радиуса 8 (160)
+	gh.spawnEntityWithID(entity.EntityTypePlayer, vec.Vec2{X: 60, Y: 0}, 3)
+
+	gh.sendWorldUpdates()
+
+	farUpdate := &protocol.EntityMoveMessage{}
+	far.expect(t, protocol.MessageType_ENTITY_MOVE, farUpdate)
+	ids := make([]uint64, 0, len(farUpdate.Entities))
+	for _, e := range farUpdate.Entities {
+		ids = append(ids, e.Id)
+	}
+	assert.Contains(t, ids, uint64(3))
+
+	nearUpdate := &protocol.EntityMoveMessage{}
+	near.expect(t, protocol.MessageType_ENTITY_MOVE, nearUpdate)
+	for _, e := range nearUpdate.Entities {
+		assert.NotEqual(t, uint64(3), e.Id, "сущность вне дальности видимости не должна отправляться")
+	}
+}
+
+func TestChunksByDistance_CenterBeforeCorners(t *testing.T) {
+	center := vec.Vec2{X: 3, Y: -2}
+	chunks := chunksByDistance(center, 2)
+	require.Len(t, chunks, 25)
+
+	assert.Equal(t, center, chunks[0], "чанк игрока должен идти первым")
+
+	index := make(map[vec.Vec2]int, len(chunks))
+	for i, c := range chunks {
+		index[c] = i
+	}
+	corners := []vec.Vec2{
+		{X: center.X - 2, Y: center.Y - 2},
+		{X: center.X + 2, Y: center.Y - 2},
+		{X: center.X - 2, Y: center.Y + 2},
+		{X: center.X + 2, Y: center.Y + 2},
+	}
+	for _, corner := range corners {
+		assert.GreaterOrEqual(t, index[corner], len(chunks)-4, "угловой чанк %v должен идти последним", corner)
+	}
+}
+
+func TestSendInitialChunks_NearestChunkDeliveredFirst(t *testing.T) {
+	gh := newTestGameHandler(t)
+	client := connectTestClient(t, gh, "conn-1")
+	addTestSession(gh, "conn-1", 1, 1, vec.Vec2{X: 40, Y: 40}).ViewDistance = 1
+
+	stream := gh.sendInitialChunks("conn-1", 1)
+	require.NotNil(t, stream)
+
+	first := &protocol.ChunkData{}
+	client.expect(t, protocol.MessageType_CHUNK_DATA, first)
+	assert.Equal(t, int32(2), first.ChunkX)
+	assert.Equal(t, int32(2), first.ChunkY)
+}
+
+func TestChunkStream_RestartsWhenPlayerMovesAway(t *testing.T) {
+	gh := newTestGameHandler(t)
+	connectTestClient(t, gh, "conn-1")
+
+	stream := gh.startChunkStream("conn-1", vec.Vec2{X: 0, Y: 0}, 8)
+
+	// Перемещение внутри того же чанка не прерывает отправку
+	assert.Nil(t, gh.updateChunkStream("conn-1", vec.Vec2{X: 10, Y: 5}))
+	gh.streamsMu.Lock()
+	assert.Same(t, stream, gh.chunkStreams["conn-1"])
+	gh.streamsMu.Unlock()
+
+	// Уход за пределы области отменяет недоставленные чанки и запускает загрузку от новой позиции
+	gh.updateChunkStream("conn-1", vec.Vec2{X: 1000, Y: 0})
+	select {
+	case <-stream.done:
+	default:
+		t.Fatal("прежняя отправка должна быть остановлена")
+	}
+
+	gh.streamsMu.Lock()
+	restarted := gh.chunkStreams["conn-1"]
+	gh.streamsMu.Unlock()
+	require.NotNil(t, restarted)
+	assert.Equal(t, vec.Vec2{X: 62, Y: 0}, restarted.center)
+}
+
+// registerTestBlock регистрирует блок через JSON-загрузчик, если он ещё не зарегистрирован
+func registerTestBlock(t *testing.T, id block.BlockID, name string) {
+	t.Helper()
+
+	if block.IsValidBlockID(id) {
+		return
+	}
+	dir := t.TempDir()
+	spec := fmt.Sprintf(`{"id": %d, "name": %q}`, id, name)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name+".json"), []byte(spec), 0o644))
+	require.NoError(t, block.LoadJSONBlocks(dir))
+}
+
+func TestHandleBlockUpdate_RejectsInvalidMetadata(t *testing.T) {
+	gh := newTestGameHandler(t)
+	client := connectTestClient(t, gh, "conn-1")
+	addTestSession(gh, "conn-1", 1, 1, vec.Vec2{X: 0, Y: 0})
+
+	// Отдельный тестовый блок: схема не меняется у встроенных блоков
+	const signBlockID block.BlockID = 950
+	if !block.IsValidBlockID(signBlockID) {
+		dir := t.TempDir()
+		spec := fmt.Sprintf(`{"id": %d, "name": "test_sign", "metadata": {"rotation": {"type": "integer", "min": 0, "max": 3}}}`, signBlockID)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "test_sign.json"), []byte(spec), 0o644))
+		require.NoError(t, block.LoadJSONBlocks(dir))
+	}
+
+	msg := newGameMessage(t, protocol.MessageType_BLOCK_UPDATE, &protocol.BlockUpdateRequest{
+		Position: &protocol.Vec2{X: 1, Y: 1},
+		BlockId:  uint32(signBlockID),
+		Action:   "place",
+		Metadata: &protocol.JsonMetadata{JsonData: `{"rotation": 2, "hack": true}`},
+	})
+	gh.HandleMessage("conn-1", msg)
+
+	resp := &protocol.BlockUpdateResponseMessage{}
+	client.expect(t, protocol.MessageType_BLOCK_UPDATE_RESPONSE, resp)
+	assert.False(t, resp.Success)
+	assert.Contains(t, resp.Message, `unknown metadata key "hack"`)
+	assert.NotEqual(t, signBlockID, gh.worldManager.GetBlockLayer(vec.Vec2{X: 1, Y: 1}, world.LayerActive).ID,
+		"блок не должен устанавливаться при отклонённых метаданных")
+
+	// Корректные метаданные сохраняются в установленном блоке
+	msg = newGameMessage(t, protocol.MessageType_BLOCK_UPDATE, &protocol.BlockUpdateRequest{
+		Position: &protocol.Vec2{X: 1, Y: 1},
+		BlockId:  uint32(signBlockID),
+		Action:   "place",
+		Metadata: &protocol.JsonMetadata{JsonData: `{"rotation": 2, "light": 15}`},
+	})
+	gh.HandleMessage("conn-1", msg)
+
+	resp = &protocol.BlockUpdateResponseMessage{}
+	client.expect(t, protocol.MessageType_BLOCK_UPDATE_RESPONSE, resp)
+	assert.True(t, resp.Success, resp.Message)
+	assert.JSONEq(t, `{"rotation": 2}`, resp.Metadata.JsonData)
+}
+
+func TestHandleBlockUpdate_ValidatesActionTool(t *testing.T) {
+	gh := newTestGameHandler(t)
+	client := connectTestClient(t, gh, "conn-1")
+	addTestSession(gh, "conn-1", 1, 1, vec.Vec2{X: 0, Y: 0})
+	pos := vec.Vec2{X: 1, Y: 1}
+	gh.worldManager.SetBlockLayer(pos, world.LayerActive, world.NewBlock(block.DirtBlockID))
+
+	use := func(payload string) *protocol.BlockUpdateResponseMessage {
+		gh.HandleMessage("conn-1", newGameMessage(t, protocol.MessageType_BLOCK_UPDATE, &protocol.BlockUpdateRequest{
+			Position: &protocol.Vec2{X: int32(pos.X), Y: int32(pos.Y)},
+			BlockId:  uint32(block.DirtBlockID),
+			Action:   "use",
+			Metadata: &protocol.JsonMetadata{JsonData: payload},
+		}))
+		resp := &protocol.BlockUpdateResponseMessage{}
+		client.expect(t, protocol.MessageType_BLOCK_UPDATE_RESPONSE, resp)
+		return resp
+	}
+
+	resp := use(`{"tool": 7}`)
+	assert.False(t, resp.Success)
+	assert.Contains(t, resp.Message, `metadata key "tool" must be a string`)
+
+	resp = use(`{"tool": "seed", "moisture": 10}`)
+	assert.False(t, resp.Success, "сохраняемые метаданные блока не передаются в действии")
+	assert.Contains(t, resp.Message, `unknown metadata key "moisture"`)
+
+	resp = use(`{"tool": "seed"}`)
+	assert.True(t, resp.Success, resp.Message)
+	assert.Equal(t, uint32(block.GrassBlockID), resp.BlockId)
+	assert.JSONEq(t, `{"growth": 0}`, resp.Metadata.JsonData)
+}
+
+func TestSpawnEntity_UsesRegionAllocator(t *testing.T) {
+	gh := newTestGameHandler(t)
+	gh.SetEntityIDAllocator(entity.NewEntityIDAllocator(3))
+
+	handlerID := gh.SpawnEntity(entity.EntityTypeItem, vec.Vec2{X: 1, Y: 1})
+	worldID := gh.worldManager.GenerateEntityID()
+	managerID := gh.entityManager.SpawnEntity(entity.EntityTypeItem, vec.Vec2{X: 2, Y: 2}, gh)
+
+	for _, id := range []uint64{handlerID, worldID, managerID} {
+		assert.Equal(t, uint16(3), entity.RegionOfEntityID(id))
+	}
+	assert.Equal(t, []uint64{handlerID + 1, handlerID + 2}, []uint64{worldID, managerID}, "все пути спавна используют общий счётчик")
+}
+
+func TestOversizedPayload_RejectedThenDisconnected(t *testing.T) {
+	gh := newTestGameHandler(t)
+	gh.SetMaxPayloadSize(128)
+	gh.SetMaxOversizedMessages(2)
+
+	client := connectTestClient(t, gh, "conn-1")
+	addTestSession(gh, "conn-1", 42, 7, vec.Vec2{X: 3, Y: 4})
+
+	oversized := &protocol.GameMessage{Type: protocol.MessageType_CHAT, Payload: make([]byte, 129)}
+
+	// Первое нарушение: сообщение отклоняется, соединение сохраняется
+	gh.HandleMessage("conn-1", oversized)
+	notice := &protocol.ServerMessage{}
+	client.expect(t, protocol.MessageType_SERVER_MESSAGE, notice)
+	assert.Equal(t, ServerMessagePayloadTooLarge, notice.Code)
+	assert.Equal(t, 1, gh.OversizedMessageCount("conn-1"))
+	assert.True(t, gh.IsSessionValid("conn-1"))
+
+	// Сообщение в пределах лимита обрабатывается как обычно
+	gh.HandleMessage("conn-1", newGameMessage(t, protocol.MessageType_CHAT, &protocol.ChatMessage{Message: "hi"}))
+	assert.Equal(t, 1, gh.OversizedMessageCount("conn-1"))
+
+	// Повторное нарушение приводит к отключению
+	gh.HandleMessage("conn-1", oversized)
+	assert.Eventually(t, func() bool { return !gh.IsSessionValid("conn-1") }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, 0, gh.OversizedMessageCount("conn-1"))
+}
+
+func TestUnknownMessageType_ErrorOnceThenDisconnected(t *testing.T) {
+	gh := newTestGameHandler(t)
+	gh.SetMaxUnknownMessages(3)
+
+	client := connectTestClient(t, gh, "conn-1")
+	addTestSession(gh, "conn-1", 42, 7, vec.Vec2{X: 3, Y: 4})
+
+	unknown := &protocol.GameMessage{Type: protocol.MessageType(9999)}
+
+	// Первое сообщение: клиент получает ошибку протокола, соединение сохраняется
+	gh.HandleMessage("conn-1", unknown)
+	errMsg := &protocol.ErrorMessage{}
+	client.expect(t, protocol.MessageType_ERROR, errMsg)
+	assert.Equal(t, protocol.ErrorCode_INVALID, errMsg.Code)
+	assert.Equal(t, protocol.MessageType(9999), errMsg.RefType)
+	assert.Equal(t, 1, gh.UnknownMessageCount("conn-1"))
+
+	// Повторные сообщения не вызывают новых ошибок, пока не достигнут порог
+	gh.HandleMessage("conn-1", unknown)
+	assert.True(t, gh.IsSessionValid("conn-1"))
+	gh.HandleMessage("conn-1", newGameMessage(t, protocol.MessageType_CHAT, &protocol.ChatMessage{Message: "hi"}))
+	assert.Equal(t, 2, gh.UnknownMessageCount("conn-1"))
+
+	gh.HandleMessage("conn-1", unknown)
+	assert.Eventually(t, func() bool { return !gh.IsSessionValid("conn-1") }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, 0, gh.UnknownMessageCount("conn-1"))
+}
+
+func TestBlockUpdate_UnauthorizedReturnsError(t *testing.T) {
+	gh := newTestGameHandler(t)
+	client := connectTestClient(t, gh, "conn-1")
+
+	gh.HandleMessage("conn-1", newGameMessage(t, protocol.MessageType_BLOCK_UPDATE, &protocol.BlockUpdateRequest{
+		Position: &protocol.Vec2{X: 1, Y: 1},
+		BlockId:  uint32(block.StoneBlockID),
+	}))
+
+	// Прежний ответ сохраняется для совместимости со старыми клиентами
+	resp := &protocol.BlockUpdateResponseMessage{}
+	client.expect(t, protocol.MessageType_BLOCK_UPDATE_RESPONSE, resp)
+	assert.False(t, resp.Success)
+
+	errMsg := &protocol.ErrorMessage{}
+	client.expect(t, protocol.MessageType_ERROR, errMsg)
+	assert.Equal(t, protocol.ErrorCode_UNAUTHORIZED, errMsg.Code)
+	assert.Equal(t, protocol.MessageType_BLOCK_UPDATE, errMsg.RefType)
+	assert.NotEmpty(t, errMsg.Message)
+}
+
+func TestBlockUpdate_TooFarReturnsError(t *testing.T) {
+	gh := newTestGameHandler(t)
+	client := connectTestClient(t, gh, "conn-1")
+	addTestSession(gh, "conn-1", 42, 7, vec.Vec2{X: 0, Y: 0})
+	target := vec.Vec2{X: 50, Y: 50}
+	before := gh.worldManager.GetBlockLayer(target, world.LayerActive).ID
+
+	gh.HandleMessage("conn-1", newGameMessage(t, protocol.MessageType_BLOCK_UPDATE, &protocol.BlockUpdateRequest{
+		Position: &protocol.Vec2{X: 50, Y: 50},
+		BlockId:  uint32(block.StoneBlockID),
+	}))
+
+	errMsg := &protocol.ErrorMessage{}
+	client.expect(t, protocol.MessageType_ERROR, errMsg)
+	assert.Equal(t, protocol.ErrorCode_TOO_FAR, errMsg.Code)
+	assert.Equal(t, before, gh.worldManager.GetBlockLayer(target, world.LayerActive).ID, "блок не должен измениться")
+}
+
+func TestChunkRequest_UnauthorizedReturnsError(t *testing.T) {
+	gh := newTestGameHandler(t)
+	client := connectTestClient(t, gh, "conn-1")
+
+	gh.HandleMessage("conn-1", newGameMessage(t, protocol.MessageType_CHUNK_REQUEST, &protocol.ChunkRequest{ChunkX: 0, ChunkY: 0}))
+
+	errMsg := &protocol.ErrorMessage{}
+	client.expect(t, protocol.MessageType_ERROR, errMsg)
+	assert.Equal(t, protocol.ErrorCode_UNAUTHORIZED, errMsg.Code)
+	assert.Equal(t, protocol.MessageType_CHUNK_REQUEST, errMsg.RefType)
+}
+
+func TestBlockUpdate_ValidatesBlockIDAgainstRegistry(t *testing.T) {
+	gh := newTestGameHandler(t)
+	client := connectTestClient(t, gh, "conn-1")
+	addTestSession(gh, "conn-1", 1, 1, vec.Vec2{X: 0, Y: 0})
+
+	const highID block.BlockID = 4242
+	target := vec.Vec2{X: 2, Y: 2}
+	place := newGameMessage(t, protocol.MessageType_BLOCK_UPDATE, &protocol.BlockUpdateRequest{
+		Position: &protocol.Vec2{X: int32(target.X), Y: int32(target.Y)},
+		BlockId:  uint32(highID),
+		Layer:    protocol.BlockLayer_ACTIVE,
+		Action:   "place",
+	})
+
+	// Блок в пределах досягаемости, но не зарегистрирован
+	require.False(t, block.IsValidBlockID(highID))
+	gh.HandleMessage("conn-1", place)
+
+	resp := &protocol.BlockUpdateResponseMessage{}
+	client.expect(t, protocol.MessageType_BLOCK_UPDATE_RESPONSE, resp)
+	assert.False(t, resp.Success)
+	errMsg := &protocol.ErrorMessage{}
+	client.expect(t, protocol.MessageType_ERROR, errMsg)
+	assert.Equal(t, protocol.ErrorCode_INVALID, errMsg.Code)
+	assert.NotEqual(t, highID, gh.worldManager.GetBlockLayer(target, world.LayerActive).ID)
+
+	// ID за пределами uint16 не должен усекаться до зарегистрированного
+	gh.HandleMessage("conn-1", newGameMessage(t, protocol.MessageType_BLOCK_UPDATE, &protocol.BlockUpdateRequest{
+		Position: &protocol.Vec2{X: int32(target.X), Y: int32(target.Y)},
+		BlockId:  1<<16 + uint32(block.AirBlockID),
+		Action:   "place",
+	}))
+	resp = &protocol.BlockUpdateResponseMessage{}
+	client.expect(t, protocol.MessageType_BLOCK_UPDATE_RESPONSE, resp)
+	assert.False(t, resp.Success)
+
+	// После регистрации тот же ID (больше прежнего лимита 1000) принимается
+	registerTestBlock(t, highID, "test_high_block")
+	gh.HandleMessage("conn-1", place)
+
+	resp = &protocol.BlockUpdateResponseMessage{}
+	client.expect(t, protocol.MessageType_BLOCK_UPDATE_RESPONSE, resp)
+	assert.True(t, resp.Success, resp.Message)
+	assert.Equal(t, highID, gh.worldManager.GetBlockLayer(target, world.LayerActive).ID)
+}
+
+func TestBlockUpdate_ReachDistanceFromConfig(t *testing.T) {
+	gh := newTestGameHandler(t)
+	client := connectTestClient(t, gh, "conn-1")
+	addTestSession(gh, "conn-1", 1, 1, vec.Vec2{X: 0, Y: 0})
+	registerTestBlock(t, block.DoorBlockID, "door")
+	gh.SetMaxReachDistance(3)
+
+	gh.HandleMessage("conn-1", newGameMessage(t, protocol.MessageType_BLOCK_UPDATE, &protocol.BlockUpdateRequest{
+		Position: &protocol.Vec2{X: 4, Y: 0},
+		BlockId:  uint32(block.DoorBlockID),
+		Action:   "place",
+	}))
+	errMsg := &protocol.ErrorMessage{}
+	client.expect(t, protocol.MessageType_ERROR, errMsg)
+	assert.Equal(t, protocol.ErrorCode_TOO_FAR, errMsg.Code)
+}
+
+// authTestClient аутентифицирует соединение как admin и возвращает успешный ответ
+func authTestClient(t *testing.T, gh *GameHandlerPB, client *testClient) *protocol.AuthResponseMessage {
+	t.Helper()
+
+	password := "ChangeMe123!"
+	msg := newGameMessage(t, protocol.MessageType_AUTH, &protocol.AuthMessage{
+		Username:        "admin",
+		Password:        &password,
+		ProtocolVersion: ProtocolVersion,
+	})
+	done := make(chan struct{})
+	go func() {
+		gh.HandleMessage(client.connID, msg)
+		close(done)
+	}()
+	t.Cleanup(func() { <-done })
+
+	resp := &protocol.AuthResponseMessage{}
+	client.expect(t, protocol.MessageType_AUTH_RESPONSE, resp)
+	require.True(t, resp.Success, resp.Message)
+	<-done
+	return resp
+}
+
+// playerEntityFor возвращает сущность игрока, привязанную к соединению
+func playerEntityFor(t *testing.T, gh *GameHandlerPB, connID string) *entity.Entity {
+	t.Helper()
+
+	gh.mu.RLock()
+	entityID, exists := gh.playerEntities[connID]
+	gh.mu.RUnlock()
+	require.True(t, exists, "у соединения %s нет сущности игрока", connID)
+
+	playerEntity, found := gh.entityManager.GetEntity(entityID)
+	require.True(t, found)
+	return playerEntity
+}
+
+func TestPlayerState_ExperienceSurvivesReconnect(t *testing.T) {
+	gh := newTestGameHandler(t)
+	gh.entityManager.RegisterDefaultBehaviors()
+	states := storage.NewMemoryPlayerStateRepo()
+	gh.SetPlayerStateRepo(states)
+
+	first := connectTestClient(t, gh, "conn-1")
+	authTestClient(t, gh, first)
+
+	// Игрок набирает опыт и получает эффект
+	player := playerEntityFor(t, gh, "conn-1")
+	player.Payload[payloadLevel] = 3
+	player.Payload[payloadExperience] = 250
+	player.Payload[payloadStatusEffects] = map[string]float64{"regeneration": 30}
+
+	gh.OnClientDisconnect("conn-1")
+
+	saved, found, err := states.Load(context.Background(), 1)
+	require.NoError(t, err)
+	require.True(t, found, "прогресс должен сохраниться при отключении")
+	assert.Equal(t, 250, saved.Experience)
+
+	second := connectTestClient(t, gh, "conn-2")
+	authTestClient(t, gh, second)
+
+	restored := playerEntityFor(t, gh, "conn-2")
+	assert.NotEqual(t, player.ID, restored.ID, "после переподключения создаётся новая сущность")
+	assert.Equal(t, 3, restored.Payload[payloadLevel])
+	assert.Equal(t, 250, restored.Payload[payloadExperience])
+	assert.Equal(t, map[string]float64{"regeneration": 30}, restored.Payload[payloadStatusEffects])
+}
+
+func TestPlayerState_AutoSave(t *testing.T) {
+	gh := newTestGameHandler(t)
+	gh.entityManager.RegisterDefaultBehaviors()
+	states := storage.NewMemoryPlayerStateRepo()
+	gh.SetPlayerStateRepo(states)
+
+	client := connectTestClient(t, gh, "conn-1")
+	authTestClient(t, gh, client)
+	playerEntityFor(t, gh, "conn-1").Payload[payloadExperience] = 40
+
+	gh.autoSavePlayerStates()
+
+	saved, found, err := states.Load(context.Background(), 1)
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.Equal(t, 1, saved.Level)
+	assert.Equal(t, 40, saved.Experience)
+}
+
+// findEntityData возвращает данные сущности из обновления перемещения
+func findEntityData(t *testing.T, update *protocol.EntityMoveMessage, id uint64) *protocol.EntityData {
+	t.Helper()
+
+	for _, ed := range update.Entities {
+		if ed.Id == id {
+			return ed
+		}
+	}
+	t.Fatalf("сущность %d отсутствует в обновлении", id)
+	return nil
+}
+
+func TestEntityMove_ReconciliationFieldsOnlyForOwner(t *testing.T) {
+	gh := newTestGameHandler(t)
+	owner := connectTestClient(t, gh, "conn-owner")
+	addTestSession(gh, "conn-owner", 1, 1, vec.Vec2{X: 0, Y: 0})
+	observer := connectTestClient(t, gh, "conn-observer")
+	addTestSession(gh, "conn-observer", 2, 2, vec.Vec2{X: 0, Y: 0})
+	gh.worldManager.Step(7, 1.0/world.DefaultTickRate)
+
+	msg := newGameMessage(t, protocol.MessageType_ENTITY_MOVE, &protocol.EntityMoveMessage{
+		Entities: []*protocol.EntityData{{Id: 1, Position: &protocol.Vec2{X: 1, Y: 0}}},
+	})
+	msg.Sequence = 5
+	gh.HandleMessage("conn-owner", msg)
+
+	// Мгновенное обновление для наблюдателя остаётся компактным
+	moved := &protocol.EntityMoveMessage{}
+	observer.expect(t, protocol.MessageType_ENTITY_MOVE, moved)
+	observed := findEntityData(t, moved, 1)
+	assert.Equal(t, int32(1), observed.Position.X)
+	assert.Nil(t, observed.LastProcessedInput)
+	assert.Nil(t, observed.ServerTick)
+
+	gh.sendWorldUpdates()
+
+	// Владелец получает свою сущность с полями сверки предсказания
+	ownerUpdate := &protocol.EntityMoveMessage{}
+	owner.expect(t, protocol.MessageType_ENTITY_MOVE, ownerUpdate)
+	own := findEntityData(t, ownerUpdate, 1)
+	require.NotNil(t, own.LastProcessedInput)
+	require.NotNil(t, own.ServerTick)
+	assert.Equal(t, uint32(5), own.GetLastProcessedInput())
+	assert.Equal(t, uint64(7), own.GetServerTick())
+	assert.NotNil(t, own.Velocity, "владелец получает авторитетную скорость")
+
+	observerUpdate := &protocol.EntityMoveMessage{}
+	observer.expect(t, protocol.MessageType_ENTITY_MOVE, observerUpdate)
+	other := findEntityData(t, observerUpdate, 1)
+	assert.Nil(t, other.LastProcessedInput)
+	assert.Nil(t, other.ServerTick)
+
+	// Без нового ввода собственная сущность больше не повторяется
+	gh.sendWorldUpdates()
+	repeat := &protocol.EntityMoveMessage{}
+	owner.expect(t, protocol.MessageType_ENTITY_MOVE, repeat)
+	for _, ed := range repeat.Entities {
+		assert.NotEqual(t, uint64(1), ed.Id)
+	}
+}
+
+func TestAnticheat_ReportsSpeedHackAndReach(t *testing.T) {
+	gh := newTestGameHandler(t)
+	client := connectTestClient(t, gh, "conn-1")
+	addTestSession(gh, "conn-1", 1, 1, vec.Vec2{X: 0, Y: 0})
+
+	now := time.Now()
+	gh.now = func() time.Time { return now }
+
+	engine := anticheat.NewEngine(anticheat.Config{MaxSpeed: 10, MaxReach: 5})
+	var violations []anticheat.Violation
+	engine.SetReporter(func(v anticheat.Violation) { violations = append(violations, v) })
+	engine.ResetPosition(1, vec.Vec2Float{})
+	gh.SetAnticheat(engine)
+
+	// 4 блока за 100 мс = 40 блоков/с
+	now = now.Add(100 * time.Millisecond)
+	gh.HandleMessage("conn-1", newGameMessage(t, protocol.MessageType_ENTITY_MOVE, &protocol.EntityMoveMessage{
+		Entities: []*protocol.EntityData{{Id: 1, Position: &protocol.Vec2{X: 4, Y: 0}}},
+	}))
+	require.Len(t, violations, 1)
+	assert.Equal(t, anticheat.RuleSpeed, violations[0].Rule)
+	assert.Equal(t, "conn-1", violations[0].Username)
+
+	// Блок в 54 блоках от игрока: нарушение фиксируется, а запрос отклоняется проверкой дистанции
+	now = now.Add(time.Second)
+	gh.HandleMessage("conn-1", newGameMessage(t, protocol.MessageType_BLOCK_UPDATE, &protocol.BlockUpdateRequest{
+		Position: &protocol.Vec2{X: 58, Y: 0},
+		BlockId:  1,
+	}))
+	require.Len(t, violations, 2)
+	assert.Equal(t, anticheat.RuleReach, violations[1].Rule)
+	assert.Equal(t, anticheat.ActionBlockEdit, violations[1].Action)
+
+	errMsg := &protocol.ErrorMessage{}
+	client.expect(t, protocol.MessageType_ERROR, errMsg)
+	assert.Equal(t, protocol.ErrorCode_TOO_FAR, errMsg.Code)
+}
+
+func TestOwnership_PetSurvivesDisconnectAndIsReclaimed(t *testing.T) {
+	gh := newTestGameHandler(t)
+	gh.entityManager.RegisterDefaultBehaviors()
+
+	first := connectTestClient(t, gh, "conn-1")
+	authTestClient(t, gh, first)
+	player := playerEntityFor(t, gh, "conn-1")
+
+	petID := gh.SpawnEntity(entity.EntityTypeAnimal, vec.Vec2{X: 3, Y: 3})
+	turretID := gh.SpawnEntity(entity.EntityTypeNPC, vec.Vec2{X: 4, Y: 3})
+	require.True(t, gh.ClaimEntity(petID, player.ID, entity.OwnerKeep))
+	require.True(t, gh.ClaimEntity(turretID, player.ID, entity.OwnerRelease))
+
+	gh.OnClientDisconnect("conn-1")
+
+	pet, exists := gh.entityManager.GetEntity(petID)
+	require.True(t, exists, "питомец остаётся в мире без владельца в сети")
+	assert.Equal(t, uint64(1), pet.OwnerID)
+	assert.Zero(t, pet.OwnerEntity)
+	turret, _ := gh.entityManager.GetEntity(turretID)
+	assert.Zero(t, turret.OwnerID, "турель становится ничьей")
+
+	second := connectTestClient(t, gh, "conn-2")
+	authTestClient(t, gh, second)
+	restored := playerEntityFor(t, gh, "conn-2")
+
+	assert.Equal(t, restored.ID, pet.OwnerEntity, "питомец возвращается новой сущности игрока")
+	owned := gh.entityManager.OwnedBy(1)
+	require.Len(t, owned, 1)
+	assert.Equal(t, petID, owned[0].ID)
+}
+
+func TestRespawn_MovesPlayerThroughEntityManager(t *testing.T) {
+	gh := newTestGameHandler(t)
+	connectTestClient(t, gh, "conn-1")
+	addTestSession(gh, "conn-1", 1, 1, vec.Vec2{})
+	require.NoError(t, gh.worldManager.SetWorldSpawn(vec.Vec2{X: 40, Y: 3}))
+
+	actor := playerEntityFor(t, gh, "conn-1")
+	actor.Active = false
+	success, _, _ := gh.processEntityAction(actor.ID, &protocol.EntityActionRequest{
+		ActionType: protocol.EntityActionType_ACTION_RESPAWN,
+	})
+	require.True(t, success)
+	assert.True(t, actor.Active)
+	assert.Equal(t, vec.Vec2{X: 40, Y: 3}, actor.Position)
+
+	// Пространственный индекс видит игрока на спавне, а не на месте гибели
+	near := gh.entityManager.GetEntitiesInRange(vec.Vec2{X: 40, Y: 3}, 1)
+	require.Len(t, near, 1)
+	assert.Equal(t, actor.ID, near[0].ID)
+	assert.Empty(t, gh.entityManager.GetEntitiesInRange(vec.Vec2{}, 1))
+}
+
+func TestRespawn_ResetsAnticheatHistory(t *testing.T) {
+	gh := newTestGameHandler(t)
+	connectTestClient(t, gh, "conn-1")
+	addTestSession(gh, "conn-1", 1, 1, vec.Vec2{})
+	require.NoError(t, gh.worldManager.SetWorldSpawn(vec.Vec2{X: 40, Y: 3}))
+
+	now := time.Now()
+	gh.now = func() time.Time { return now }
+
+	engine := anticheat.NewEngine(anticheat.Config{MaxSpeed: 10})
+	var violations []anticheat.Violation
+	engine.SetReporter(func(v anticheat.Violation) { violations = append(violations, v) })
+	engine.ResetPosition(1, vec.Vec2Float{})
+	gh.SetAnticheat(engine)
+
+	actor := playerEntityFor(t, gh, "conn-1")
+	actor.Active = false
+	success, _, _ := gh.processEntityAction(actor.ID, &protocol.EntityActionRequest{
+		ActionType: protocol.EntityActionType_ACTION_RESPAWN,
+	})
+	require.True(t, success)
+
+	// Первый шаг со спавна сравнивается со спавном, а не с местом гибели
+	now = now.Add(time.Second)
+	gh.HandleMessage("conn-1", newGameMessage(t, protocol.MessageType_ENTITY_MOVE, &protocol.EntityMoveMessage{
+		Entities: []*protocol.EntityData{{Id: 1, Position: &protocol.Vec2{X: 41, Y: 3}}},
+	}))
+	assert.Empty(t, violations)
+}
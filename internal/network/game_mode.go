@@ -0,0 +1,151 @@
+package network
+
+import (
+	"fmt"
+	"log"
+	"slices"
+)
+
+// GameMode - режим игры, определяющий, что игрок может делать с блоками
+type GameMode string
+
+const (
+	GameModeSurvival  GameMode = "survival"  // Обычная игра: прочность блоков и дистанция взаимодействия
+	GameModeCreative  GameMode = "creative"  // Мгновенное разрушение, без ограничения дистанции
+	GameModeAdventure GameMode = "adventure" // Только взаимодействие с блоками, без строительства и разрушения
+)
+
+// DefaultGameMode - режим новых игроков, если в конфигурации не указан другой
+const DefaultGameMode = GameModeSurvival
+
+// ParseGameMode разбирает название режима; пустая строка - DefaultGameMode
+func ParseGameMode(name string) (GameMode, error) {
+	switch mode := GameMode(name); mode {
+	case "":
+		return DefaultGameMode, nil
+	case GameModeSurvival, GameModeCreative, GameModeAdventure:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("неизвестный режим игры %q", name)
+	}
+}
+
+// known сообщает, что режим - один из поддерживаемых
+func (m GameMode) known() bool {
+	_, err := ParseGameMode(string(m))
+	return err == nil && m != ""
+}
+
+// GameModeRules - правила работы с блоками в режиме игры
+type GameModeRules struct {
+	// Разрешённые действия BLOCK_UPDATE: place, mine, break, use и действия
+	// блоков (nil - любые)
+	AllowedActions []string
+	InstantBreak   bool // mine/break сразу убирает блок, не уменьшая прочность
+	MineOnBreak    bool // break добывает блок, как mine; иначе сразу убирает его
+	UnlimitedReach bool // Без ограничения дистанции и проверок античита
+}
+
+// DefaultGameModeRules возвращает правила режимов по умолчанию
+func DefaultGameModeRules() map[GameMode]GameModeRules {
+	return map[GameMode]GameModeRules{
+		GameModeSurvival:  {MineOnBreak: true},
+		GameModeCreative:  {InstantBreak: true, UnlimitedReach: true},
+		GameModeAdventure: {AllowedActions: []string{"use"}},
+	}
+}
+
+// allows сообщает, разрешено ли действие с блоком
+func (r GameModeRules) allows(action string) bool {
+	return r.AllowedActions == nil || slices.Contains(r.AllowedActions, action)
+}
+
+// SetDefaultGameMode задаёт режим игры для новых сессий
+func (gh *GameHandlerPB) SetDefaultGameMode(mode GameMode) error {
+	mode, err := ParseGameMode(string(mode))
+	if err != nil {
+		return err
+	}
+
+	gh.mu.Lock()
+	gh.defaultGameMode = mode
+	gh.mu.Unlock()
+	return nil
+}
+
+// SetGameModeActions заменяет список разрешённых действий с блоками в режиме
+// (nil - любые действия)
+func (gh *GameHandlerPB) SetGameModeActions(mode GameMode, actions []string) error {
+	if !mode.known() {
+		return fmt.Errorf("неизвестный режим игры %q", mode)
+	}
+
+	gh.mu.Lock()
+	rules := gh.gameModes[mode]
+	rules.AllowedActions = slices.Clone(actions)
+	gh.gameModes[mode] = rules
+	gh.mu.Unlock()
+	return nil
+}
+
+// SetGameModeMineOnBreak задаёт, добывает ли действие break блок в режиме
+// (прочность и время добычи, как mine) или сразу убирает его
+func (gh *GameHandlerPB) SetGameModeMineOnBreak(mode GameMode, mine bool) error {
+	if !mode.known() {
+		return fmt.Errorf("неизвестный режим игры %q", mode)
+	}
+
+	gh.mu.Lock()
+	rules := gh.gameModes[mode]
+	rules.MineOnBreak = mine
+	gh.gameModes[mode] = rules
+	gh.mu.Unlock()
+	return nil
+}
+
+// SetGameMode переключает режим игры подключённого игрока
+func (gh *GameHandlerPB) SetGameMode(connID string, mode GameMode) error {
+	if !mode.known() {
+		return fmt.Errorf("неизвестный режим игры %q", mode)
+	}
+
+	gh.mu.Lock()
+	session, exists := gh.sessions[connID]
+	if !exists {
+		gh.mu.Unlock()
+		return fmt.Errorf("сессия %s не найдена", connID)
+	}
+	session.GameMode = mode
+	username := session.Username
+	gh.mu.Unlock()
+
+	log.Printf("🎮 Режим игры %s: %s", username, mode)
+	return nil
+}
+
+// SetPlayerGameMode переключает режим игры игрока, находящегося в сети, по
+// имени пользователя
+func (gh *GameHandlerPB) SetPlayerGameMode(username string, mode GameMode) error {
+	connID, err := gh.connIDForUser(username)
+	if err != nil {
+		return err
+	}
+	return gh.SetGameMode(connID, mode)
+}
+
+// gameModeRulesLocked возвращает режим игрока и его правила. Вызывается под gh.mu
+func (gh *GameHandlerPB) gameModeRulesLocked(connID string) (GameMode, GameModeRules) {
+	mode := gh.defaultGameMode
+	if session, ok := gh.sessions[connID]; ok && session.GameMode != "" {
+		mode = session.GameMode
+	}
+	return mode, gh.gameModes[mode]
+}
+
+// gameModeOf возвращает режим игры игрока
+func (gh *GameHandlerPB) gameModeOf(connID string) GameMode {
+	gh.mu.RLock()
+	defer gh.mu.RUnlock()
+	mode, _ := gh.gameModeRulesLocked(connID)
+	return mode
+}
@@ -0,0 +1,103 @@
+package network
+
+import (
+	"testing"
+
+	"github.com/annel0/mmo-game/internal/protocol"
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/annel0/mmo-game/internal/world"
+	"github.com/annel0/mmo-game/internal/world/block"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// breakBlock отправляет разрушение блока и возвращает ответ сервера
+func breakBlock(t *testing.T, gh *GameHandlerPB, client *testClient, connID string, pos vec.Vec2) *protocol.BlockUpdateResponseMessage {
+	t.Helper()
+	gh.HandleMessage(connID, newGameMessage(t, protocol.MessageType_BLOCK_UPDATE, &protocol.BlockUpdateRequest{
+		Position: &protocol.Vec2{X: int32(pos.X), Y: int32(pos.Y)},
+		BlockId:  uint32(gh.worldManager.GetBlockLayer(pos, world.LayerActive).ID),
+		Layer:    protocol.BlockLayer_ACTIVE,
+		Action:   "break",
+	}))
+	resp := &protocol.BlockUpdateResponseMessage{}
+	client.expect(t, protocol.MessageType_BLOCK_UPDATE_RESPONSE, resp)
+	return resp
+}
+
+func TestGameMode_AdventureCannotBreakWhatSurvivalCan(t *testing.T) {
+	gh := newTestGameHandler(t)
+	registerTestBlock(t, block.DoorBlockID, "door")
+	survivor := connectTestClient(t, gh, "conn-survival")
+	addTestSession(gh, "conn-survival", 1, 1, vec.Vec2{X: 0, Y: 0})
+	adventurer := connectTestClient(t, gh, "conn-adventure")
+	addTestSession(gh, "conn-adventure", 2, 2, vec.Vec2{X: 0, Y: 0})
+	require.NoError(t, gh.SetGameMode("conn-adventure", GameModeAdventure))
+	assert.Error(t, gh.SetGameMode("conn-adventure", "hardcore"))
+
+	pos := vec.Vec2{X: 1, Y: 1}
+	gh.worldManager.SetBlockLayer(pos, world.LayerActive, world.NewBlock(block.DoorBlockID))
+
+	resp := breakBlock(t, gh, adventurer, "conn-adventure", pos)
+	assert.False(t, resp.Success)
+	errMsg := &protocol.ErrorMessage{}
+	adventurer.expect(t, protocol.MessageType_ERROR, errMsg)
+	assert.Equal(t, protocol.ErrorCode_FORBIDDEN, errMsg.Code)
+	assert.Equal(t, block.DoorBlockID, gh.worldManager.GetBlockLayer(pos, world.LayerActive).ID)
+
+	resp = breakBlock(t, gh, survivor, "conn-survival", pos)
+	assert.True(t, resp.Success, resp.Message)
+	assert.Equal(t, block.AirBlockID, gh.worldManager.GetBlockLayer(pos, world.LayerActive).ID)
+
+	// Разрешённые действия режима настраиваются
+	require.NoError(t, gh.SetGameModeActions(GameModeAdventure, []string{"use", "break"}))
+	gh.worldManager.SetBlockLayer(pos, world.LayerActive, world.NewBlock(block.DoorBlockID))
+	resp = breakBlock(t, gh, adventurer, "conn-adventure", pos)
+	assert.True(t, resp.Success, resp.Message)
+}
+
+func TestGameMode_CreativeIgnoresReach(t *testing.T) {
+	gh := newTestGameHandler(t)
+	registerTestBlock(t, block.DoorBlockID, "door")
+	require.NoError(t, gh.SetDefaultGameMode(GameModeCreative))
+	assert.Error(t, gh.SetDefaultGameMode("hardcore"))
+
+	client := connectTestClient(t, gh, "conn-1")
+	addTestSession(gh, "conn-1", 1, 1, vec.Vec2{X: 0, Y: 0})
+	assert.Equal(t, GameModeCreative, gh.gameModeOf("conn-1"), "сессия без режима получает режим по умолчанию")
+
+	far := vec.Vec2{X: 50, Y: 50}
+	gh.worldManager.SetBlockLayer(far, world.LayerActive, world.NewBlock(block.DoorBlockID))
+	resp := breakBlock(t, gh, client, "conn-1", far)
+	assert.True(t, resp.Success, resp.Message)
+	assert.Equal(t, block.AirBlockID, gh.worldManager.GetBlockLayer(far, world.LayerActive).ID)
+
+	// В режиме выживания тот же блок слишком далеко
+	require.NoError(t, gh.SetGameMode("conn-1", GameModeSurvival))
+	gh.worldManager.SetBlockLayer(far, world.LayerActive, world.NewBlock(block.DoorBlockID))
+	resp = breakBlock(t, gh, client, "conn-1", far)
+	assert.False(t, resp.Success)
+	assert.Equal(t, block.DoorBlockID, gh.worldManager.GetBlockLayer(far, world.LayerActive).ID)
+}
+
+func TestGameMode_BreakMinesOnlyWhenModeSaysSo(t *testing.T) {
+	gh := newTestGameHandler(t)
+	registerTestBlock(t, block.StoneBlockID, "stone")
+	client := connectTestClient(t, gh, "conn-1")
+	addTestSession(gh, "conn-1", 1, 1, vec.Vec2{})
+
+	pos := vec.Vec2{X: 1, Y: 1}
+	gh.worldManager.SetBlockLayer(pos, world.LayerActive, world.NewBlock(block.StoneBlockID))
+
+	// В выживании break добывает камень, как mine
+	resp := breakBlock(t, gh, client, "conn-1", pos)
+	assert.True(t, resp.Success, resp.Message)
+	assert.Equal(t, block.StoneBlockID, gh.worldManager.GetBlockLayer(pos, world.LayerActive).ID)
+
+	// Без MineOnBreak break снова убирает блок сразу
+	require.NoError(t, gh.SetGameModeMineOnBreak(GameModeSurvival, false))
+	assert.Error(t, gh.SetGameModeMineOnBreak("hardcore", false))
+	resp = breakBlock(t, gh, client, "conn-1", pos)
+	assert.True(t, resp.Success, resp.Message)
+	assert.Equal(t, block.AirBlockID, gh.worldManager.GetBlockLayer(pos, world.LayerActive).ID)
+}
@@ -6,7 +6,6 @@ import (
 	"encoding/base64"
 	"log"
 	"sync"
-	"time"
 
 	"github.com/annel0/mmo-game/internal/auth"
 	"github.com/annel0/mmo-game/internal/storage"
@@ -96,6 +95,11 @@ func NewGameServerPB(tcpAddr, udpAddr string) (*GameServerPB, error) {
 	}, nil
 }
 
+// SetJSONCodecAllowed разрешает отладочным клиентам кодек JSON на TCP
+func (gs *GameServerPB) SetJSONCodecAllowed(allowed bool) {
+	gs.tcpServer.SetJSONCodecAllowed(allowed)
+}
+
 // Start запускает игровой сервер
 func (gs *GameServerPB) Start() error {
 	// Запускаем TCP сервер
@@ -107,30 +111,12 @@ func (gs *GameServerPB) Start() error {
 	// Запускаем обработку мира
 	gs.worldManager.Run(gs.ctx)
 
-	// Создаем горутину для обработки игры
+	// Мир и игровой обработчик обновляются от единых часов
+	scheduler := newTickScheduler(gs.worldManager, gs.gameHandler)
 	gs.wg.Add(1)
 	go func() {
 		defer gs.wg.Done()
-
-		// Тикер для обновления игры с частотой 20 тиков в секунду
-		ticker := time.NewTicker(50 * time.Millisecond)
-		defer ticker.Stop()
-
-		var lastTick time.Time = time.Now()
-
-		for {
-			select {
-			case <-gs.ctx.Done():
-				return
-			case tickTime := <-ticker.C:
-				// Вычисляем дельту времени между тиками
-				dt := tickTime.Sub(lastTick).Seconds()
-				lastTick = tickTime
-
-				// Обновляем обработчик игры
-				gs.gameHandler.Tick(dt)
-			}
-		}
+		scheduler.Run(gs.ctx)
 	}()
 
 	log.Printf("Игровой сервер запущен (TCP: %s, UDP: %s)", gs.tcpServer.listener.Addr(), gs.udpServer.conn.LocalAddr())
@@ -154,6 +140,11 @@ func (s *GameServerPB) Stop() {
 	log.Println("Игровой сервер остановлен")
 }
 
+// SetMaxConnections задаёт лимит одновременных TCP-подключений
+func (gs *GameServerPB) SetMaxConnections(limit int) {
+	gs.tcpServer.SetMaxConnections(limit)
+}
+
 // SetPositionRepo устанавливает репозиторий позиций игроков
 func (gs *GameServerPB) SetPositionRepo(positionRepo storage.PositionRepo) {
 	if gs.gameHandler != nil {
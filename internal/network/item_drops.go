@@ -0,0 +1,201 @@
+package network
+
+import (
+	"sort"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/protocol"
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/annel0/mmo-game/internal/world/entity"
+)
+
+// Предметы на земле (выброшенные игроком или выпавшие из блока) исчезают по
+// истечении срока жизни, а одинаковые предметы рядом объединяются в одну
+// стопку, чтобы число сущностей не росло бесконечно
+const (
+	// DefaultItemLifetime - срок жизни предмета на земле
+	DefaultItemLifetime = 5 * time.Minute
+
+	// DefaultItemMergeRadius - расстояние в блоках, на котором одинаковые
+	// предметы объединяются
+	DefaultItemMergeRadius = 1.5
+)
+
+// ItemDropRules - параметры предметов на земле
+type ItemDropRules struct {
+	Lifetime    time.Duration // 0 - DefaultItemLifetime, отрицательное значение - не исчезают
+	MergeRadius float64       // 0 - DefaultItemMergeRadius, отрицательное значение - не объединяются
+}
+
+// droppedItem - стопка предметов на земле
+type droppedItem struct {
+	itemID    uint32
+	count     int
+	droppedAt time.Time // Срок жизни отсчитывается от последнего пополнения стопки
+}
+
+// DefaultItemDropRules возвращает параметры предметов на земле по умолчанию
+func DefaultItemDropRules() ItemDropRules {
+	return ItemDropRules{
+		Lifetime:    DefaultItemLifetime,
+		MergeRadius: DefaultItemMergeRadius,
+	}
+}
+
+// SetItemDropRules задаёт срок жизни и радиус объединения предметов на земле
+func (gh *GameHandlerPB) SetItemDropRules(rules ItemDropRules) {
+	if rules.Lifetime == 0 {
+		rules.Lifetime = DefaultItemLifetime
+	}
+	if rules.MergeRadius == 0 {
+		rules.MergeRadius = DefaultItemMergeRadius
+	}
+
+	gh.mu.Lock()
+	gh.itemDropRules = rules
+	gh.mu.Unlock()
+}
+
+// dropItem создаёт на земле сущность-предмет со стопкой count предметов itemID.
+// Возвращает 0, если спавн отклонён ограничением частоты
+func (gh *GameHandlerPB) dropItem(itemID uint32, count int, pos vec.Vec2) uint64 {
+	entityID := gh.SpawnEntity(entity.EntityTypeItem, pos)
+	if entityID == 0 {
+		return 0
+	}
+
+	gh.mu.Lock()
+	gh.droppedItems[entityID] = &droppedItem{itemID: itemID, count: max(count, 1), droppedAt: gh.now()}
+	gh.mu.Unlock()
+	return entityID
+}
+
+// takeDroppedItem забывает подобранный предмет и возвращает размер стопки
+// (1 для предметов, созданных не через dropItem)
+func (gh *GameHandlerPB) takeDroppedItem(entityID uint64) int {
+	gh.mu.Lock()
+	defer gh.mu.Unlock()
+
+	item, ok := gh.droppedItems[entityID]
+	if !ok {
+		return 1
+	}
+	delete(gh.droppedItems, entityID)
+	return item.count
+}
+
+// sweepDroppedItems удаляет предметы с истёкшим сроком жизни и объединяет
+// одинаковые предметы рядом. Учёт предметов, удалённых из мира в обход
+// (импорт региона и т.п.), тоже снимается здесь, в том числе для
+// неисчезающих предметов. Вызывается из Tick раз в секунду
+func (gh *GameHandlerPB) sweepDroppedItems() {
+	now := gh.now()
+
+	gh.mu.Lock()
+	rules := gh.itemDropRules
+	var expired []uint64
+	var remaining []uint64
+	for entityID, item := range gh.droppedItems {
+		if rules.Lifetime > 0 && now.Sub(item.droppedAt) >= rules.Lifetime {
+			delete(gh.droppedItems, entityID)
+			expired = append(expired, entityID)
+			continue
+		}
+		remaining = append(remaining, entityID)
+	}
+	gh.mu.Unlock()
+
+	for _, entityID := range expired {
+		gh.removeDroppedItem(entityID, protocol.DespawnReason_DESPAWN_REASON_EXPIRED)
+	}
+	remaining = gh.forgetVanishedItems(remaining)
+	if rules.MergeRadius > 0 && len(remaining) > 1 {
+		gh.mergeDroppedItems(remaining, rules.MergeRadius)
+	}
+}
+
+// forgetVanishedItems забывает предметы, которых уже нет в EntityManager, и
+// возвращает остальные. Сущности проверяются без gh.mu: EntityManager
+// вызывает обработчик под своей блокировкой
+func (gh *GameHandlerPB) forgetVanishedItems(entityIDs []uint64) []uint64 {
+	kept := entityIDs[:0]
+	var vanished []uint64
+	for _, entityID := range entityIDs {
+		if _, ok := gh.entityManager.GetEntity(entityID); ok {
+			kept = append(kept, entityID)
+		} else {
+			vanished = append(vanished, entityID)
+		}
+	}
+	if len(vanished) == 0 {
+		return kept
+	}
+
+	gh.mu.Lock()
+	for _, entityID := range vanished {
+		delete(gh.droppedItems, entityID)
+	}
+	gh.mu.Unlock()
+	return kept
+}
+
+// mergeDroppedItems объединяет одинаковые предметы одного владельца в
+// радиусе: стопку поглощает более старая сущность (с меньшим ID). Предметы
+// разных владельцев не объединяются, чтобы не передать чужую стопку
+func (gh *GameHandlerPB) mergeDroppedItems(candidates []uint64, radius float64) {
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i] < candidates[j] })
+
+	// Позиции запрашиваются без gh.mu: EntityManager вызывает обработчик под своей блокировкой
+	neighbours := make(map[uint64][]uint64, len(candidates))
+	for _, entityID := range candidates {
+		e, ok := gh.entityManager.GetEntity(entityID)
+		if !ok {
+			continue
+		}
+		for _, other := range gh.entityManager.GetEntitiesInRange(e.Position, radius) {
+			if other.ID > entityID && other.Type == entity.EntityTypeItem && other.OwnerID == e.OwnerID {
+				neighbours[entityID] = append(neighbours[entityID], other.ID)
+			}
+		}
+	}
+
+	var merged []uint64
+	gh.mu.Lock()
+	for _, entityID := range candidates {
+		survivor, ok := gh.droppedItems[entityID]
+		if !ok {
+			continue // Поглощён более старой стопкой или подобран
+		}
+		for _, otherID := range neighbours[entityID] {
+			other, ok := gh.droppedItems[otherID]
+			if !ok || other.itemID != survivor.itemID {
+				continue
+			}
+			survivor.count += other.count
+			if other.droppedAt.After(survivor.droppedAt) {
+				survivor.droppedAt = other.droppedAt
+			}
+			delete(gh.droppedItems, otherID)
+			merged = append(merged, otherID)
+		}
+	}
+	gh.mu.Unlock()
+
+	for _, entityID := range merged {
+		gh.removeDroppedItem(entityID, protocol.DespawnReason_DESPAWN_REASON_MERGED)
+	}
+}
+
+// forgetDroppedItem забывает выброшенный предмет, удаляемый в обход подбора
+func (gh *GameHandlerPB) forgetDroppedItem(entityID uint64) {
+	gh.mu.Lock()
+	delete(gh.droppedItems, entityID)
+	gh.mu.Unlock()
+}
+
+// removeDroppedItem удаляет предмет из мира и оповещает игроков
+func (gh *GameHandlerPB) removeDroppedItem(entityID uint64, reason protocol.DespawnReason) {
+	if gh.entityManager.DespawnEntity(entityID, gh) {
+		gh.despawnEntity(entityID, reason)
+	}
+}
@@ -0,0 +1,123 @@
+package network
+
+import (
+	"testing"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/protocol"
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/annel0/mmo-game/internal/world/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDroppedItems_ExpireAfterLifetime(t *testing.T) {
+	gh, recorder := newDespawnTestHandler(t)
+	gh.SetItemDropRules(ItemDropRules{Lifetime: time.Minute, MergeRadius: -1})
+	now := time.Now()
+	gh.now = func() time.Time { return now }
+
+	item := gh.dropItem(7, 1, vec.Vec2{X: 4, Y: 4})
+
+	now = now.Add(time.Minute - time.Second)
+	gh.sweepDroppedItems()
+	_, exists := gh.entityManager.GetEntity(item)
+	require.True(t, exists, "срок жизни ещё не истёк")
+	assert.Empty(t, recorder.reasons(t, item))
+
+	now = now.Add(time.Second)
+	gh.sweepDroppedItems()
+	_, exists = gh.entityManager.GetEntity(item)
+	assert.False(t, exists)
+	assert.Equal(t, []protocol.DespawnReason{protocol.DespawnReason_DESPAWN_REASON_EXPIRED}, recorder.reasons(t, item))
+
+	gh.mu.RLock()
+	assert.Empty(t, gh.droppedItems)
+	gh.mu.RUnlock()
+}
+
+func TestDroppedItems_NearbyIdenticalDropsMerge(t *testing.T) {
+	gh, recorder := newDespawnTestHandler(t)
+	now := time.Now()
+	gh.now = func() time.Time { return now }
+
+	first := gh.dropItem(7, 2, vec.Vec2{X: 10, Y: 10})
+	now = now.Add(time.Minute)
+	second := gh.dropItem(7, 3, vec.Vec2{X: 11, Y: 10})
+	other := gh.dropItem(8, 1, vec.Vec2{X: 10, Y: 11}) // Другой предмет рядом
+	far := gh.dropItem(7, 1, vec.Vec2{X: 30, Y: 10})   // Такой же, но далеко
+
+	gh.sweepDroppedItems()
+
+	_, exists := gh.entityManager.GetEntity(second)
+	assert.False(t, exists, "стопку поглощает более старый предмет")
+	assert.Equal(t, []protocol.DespawnReason{protocol.DespawnReason_DESPAWN_REASON_MERGED}, recorder.reasons(t, second))
+	for _, id := range []uint64{first, other, far} {
+		_, exists := gh.entityManager.GetEntity(id)
+		assert.True(t, exists)
+		assert.Empty(t, recorder.reasons(t, id))
+	}
+
+	gh.mu.RLock()
+	merged := *gh.droppedItems[first]
+	gh.mu.RUnlock()
+	assert.Equal(t, 5, merged.count)
+	assert.Equal(t, now, merged.droppedAt, "срок жизни стопки отсчитывается от нового предмета")
+
+	// Подбор забирает всю стопку
+	addTestSession(gh, "player", 1, 100, vec.Vec2{X: 10, Y: 10})
+	success, message, _ := gh.processEntityAction(100, &protocol.EntityActionRequest{
+		ActionType: protocol.EntityActionType_ACTION_PICKUP,
+		TargetId:   &first,
+	})
+	require.True(t, success)
+	assert.Equal(t, "Подобрано предметов: 5", message)
+}
+
+func TestDroppedItems_MergeOnlySameOwner(t *testing.T) {
+	gh, recorder := newDespawnTestHandler(t)
+
+	mine := gh.dropItem(7, 2, vec.Vec2{X: 10, Y: 10})
+	theirs := gh.dropItem(7, 3, vec.Vec2{X: 11, Y: 10})
+	alsoMine := gh.dropItem(7, 1, vec.Vec2{X: 10, Y: 11})
+	require.True(t, gh.entityManager.SetOwner(mine, 1, 100, entity.OwnerKeep))
+	require.True(t, gh.entityManager.SetOwner(theirs, 2, 200, entity.OwnerKeep))
+	require.True(t, gh.entityManager.SetOwner(alsoMine, 1, 100, entity.OwnerKeep))
+
+	gh.sweepDroppedItems()
+
+	_, exists := gh.entityManager.GetEntity(theirs)
+	assert.True(t, exists, "чужая стопка не поглощается")
+	assert.Empty(t, recorder.reasons(t, theirs))
+	assert.Equal(t, []protocol.DespawnReason{protocol.DespawnReason_DESPAWN_REASON_MERGED}, recorder.reasons(t, alsoMine))
+
+	gh.mu.RLock()
+	defer gh.mu.RUnlock()
+	assert.Equal(t, 3, gh.droppedItems[mine].count)
+	assert.Equal(t, 3, gh.droppedItems[theirs].count)
+}
+
+func TestDroppedItems_ForgottenOnEveryDespawnPath(t *testing.T) {
+	gh, _ := newDespawnTestHandler(t)
+	gh.SetItemDropRules(ItemDropRules{Lifetime: -1, MergeRadius: -1})
+
+	removed := gh.dropItem(7, 1, vec.Vec2{X: 4, Y: 4})
+	bypassed := gh.dropItem(7, 1, vec.Vec2{X: 8, Y: 4})
+	kept := gh.dropItem(7, 1, vec.Vec2{X: 12, Y: 4})
+
+	// Удаление через обработчик снимает учёт сразу
+	gh.DespawnEntity(removed)
+	gh.mu.RLock()
+	assert.NotContains(t, gh.droppedItems, removed)
+	gh.mu.RUnlock()
+
+	// Удаление в обход обработчика (импорт региона и т.п.) - при обходе,
+	// даже если предметы не исчезают по сроку жизни
+	require.True(t, gh.entityManager.DespawnEntity(bypassed, gh))
+	gh.sweepDroppedItems()
+
+	gh.mu.RLock()
+	defer gh.mu.RUnlock()
+	assert.NotContains(t, gh.droppedItems, bypassed)
+	assert.Contains(t, gh.droppedItems, kept)
+}
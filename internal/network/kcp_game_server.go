@@ -6,12 +6,16 @@ import (
 	"encoding/base64"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/annel0/mmo-game/internal/anticheat"
 	"github.com/annel0/mmo-game/internal/auth"
 	"github.com/annel0/mmo-game/internal/logging"
 	"github.com/annel0/mmo-game/internal/protocol"
 	"github.com/annel0/mmo-game/internal/storage"
+	"github.com/annel0/mmo-game/internal/storage_interface"
+	"github.com/annel0/mmo-game/internal/vec"
 	"github.com/annel0/mmo-game/internal/world"
 	"github.com/annel0/mmo-game/internal/world/entity"
 )
@@ -19,7 +23,10 @@ import (
 // KCPGameServer представляет игровой сервер с поддержкой KCP протокола
 type KCPGameServer struct {
 	kcpServer    *ChannelServer
-	udpServer    *UDPServerPB // Оставляем UDP для fallback
+	udpServer    *UDPServerPB       // Оставляем UDP для fallback
+	tcpServer    *TCPServerPB       // TCP для клиентов, у которых блокируется KCP (nil - выключен)
+	connLimit    *connectionLimiter // Общий лимит подключений KCP и TCP fallback
+	allowJSON    bool               // Кодек JSON на TCP fallback, см. SetJSONCodecAllowed
 	worldManager *world.WorldManager
 	gameHandler  *GameHandlerPB
 	gameAuth     *auth.GameAuthenticator
@@ -27,6 +34,10 @@ type KCPGameServer struct {
 	ctx          context.Context
 	cancel       context.CancelFunc
 	wg           sync.WaitGroup
+
+	// Планировщик тиков (nil до Start) и время его запуска, см. CheckWorld
+	scheduler atomic.Pointer[world.TickScheduler]
+	startedAt atomic.Int64
 }
 
 // NewKCPGameServer создает новый игровой сервер с поддержкой KCP
@@ -107,8 +118,12 @@ func NewKCPGameServer(kcpAddr, udpAddr string) (*KCPGameServer, error) {
 	gameHandler.SetGameAuthenticator(gameAuth)
 	udpServer.SetGameHandler(gameHandler)
 
+	connLimit := &connectionLimiter{}
+	kcpServer.limits.shared = connLimit
+
 	return &KCPGameServer{
 		kcpServer:    kcpServer,
+		connLimit:    connLimit,
 		udpServer:    udpServer,
 		worldManager: worldManager,
 		gameHandler:  gameHandler,
@@ -129,33 +144,23 @@ func (kgs *KCPGameServer) Start() error {
 	// Запускаем UDP сервер для fallback
 	kgs.udpServer.Start()
 
-	// Запускаем обработку мира
-	kgs.worldManager.Run(kgs.ctx)
+	// Запускаем TCP сервер для клиентов без KCP
+	if kgs.tcpServer != nil {
+		kgs.tcpServer.Start()
+		kgs.logger.Info("🔌 TCP fallback для клиентов без KCP: %s", kgs.tcpServer.listener.Addr())
+	}
+
+	// Запускаем мир: его события маршрутизирует фаза планировщика (Step)
+	kgs.worldManager.RunStepped(kgs.ctx)
 
-	// Создаем горутину для обновления игры
+	// Мир и игровой обработчик обновляются от единых часов
+	scheduler := newTickScheduler(kgs.worldManager, kgs.gameHandler)
+	kgs.startedAt.Store(time.Now().UnixNano())
+	kgs.scheduler.Store(scheduler)
 	kgs.wg.Add(1)
 	go func() {
 		defer kgs.wg.Done()
-
-		// Тикер для обновления игры с частотой 20 тиков в секунду
-		ticker := time.NewTicker(50 * time.Millisecond)
-		defer ticker.Stop()
-
-		var lastTick time.Time = time.Now()
-
-		for {
-			select {
-			case <-kgs.ctx.Done():
-				return
-			case tickTime := <-ticker.C:
-				// Вычисляем дельту времени между тиками
-				dt := tickTime.Sub(lastTick).Seconds()
-				lastTick = tickTime
-
-				// Обновляем обработчик игры
-				kgs.gameHandler.Tick(dt)
-			}
-		}
+		scheduler.Run(kgs.ctx)
 	}()
 
 	kgs.logger.Info("🎮 KCP игровой сервер запущен (KCP: %s, UDP fallback: %s)",
@@ -178,9 +183,21 @@ func (kgs *KCPGameServer) Stop() {
 	// Останавливаем UDP сервер
 	kgs.udpServer.Stop()
 
+	// Останавливаем TCP fallback
+	if kgs.tcpServer != nil {
+		kgs.tcpServer.Stop()
+	}
+
 	// Ждем завершения всех горутин
 	kgs.wg.Wait()
 
+	// Дожидаемся отправки уже запрошенных чанков и записываем позиции,
+	// сохранение которых ждало переподключения
+	if kgs.gameHandler != nil {
+		kgs.gameHandler.StopChunkWorkers()
+		kgs.gameHandler.FlushPendingPositionSaves()
+	}
+
 	kgs.logger.Info("✅ KCP игровой сервер остановлен")
 }
 
@@ -191,10 +208,367 @@ func (kgs *KCPGameServer) SetPositionRepo(positionRepo storage.PositionRepo) {
 	}
 }
 
+// SetPlayerStore подключает общие с REST API хранилища пользователей, позиций и прогресса
+func (kgs *KCPGameServer) SetPlayerStore(store *storage.PlayerStore) {
+	if kgs.gameHandler != nil {
+		kgs.gameHandler.SetPlayerStore(store)
+		kgs.gameAuth = kgs.gameHandler.gameAuth
+	}
+}
+
+// SetAuthProvider задаёт проверку учётных данных игроков
+func (kgs *KCPGameServer) SetAuthProvider(provider auth.AuthProvider) {
+	if kgs.gameHandler != nil {
+		kgs.gameHandler.SetAuthProvider(provider)
+		kgs.gameAuth = kgs.gameHandler.gameAuth
+	}
+}
+
+// SetPlayerStateRepo устанавливает репозиторий прогресса игроков
+func (kgs *KCPGameServer) SetPlayerStateRepo(repo storage.PlayerStateRepo) {
+	if kgs.gameHandler != nil {
+		kgs.gameHandler.SetPlayerStateRepo(repo)
+	}
+}
+
+// SetProtocolVersionRange устанавливает диапазон поддерживаемых версий протокола клиентов
+func (kgs *KCPGameServer) SetProtocolVersionRange(r ProtocolVersionRange) {
+	if kgs.gameHandler != nil {
+		kgs.gameHandler.SetProtocolVersionRange(r)
+	}
+}
+
+// SetMaxViewDistance ограничивает дальность видимости, запрашиваемую клиентами
+func (kgs *KCPGameServer) SetMaxViewDistance(maxDistance int) {
+	if kgs.gameHandler != nil {
+		kgs.gameHandler.SetMaxViewDistance(maxDistance)
+	}
+}
+
+// SetEntityUpdateLimits ограничивает размер и число ENTITY_MOVE обновления мира
+func (kgs *KCPGameServer) SetEntityUpdateLimits(perMessage, maxMessages int) {
+	if kgs.gameHandler != nil {
+		kgs.gameHandler.SetEntityUpdateLimits(perMessage, maxMessages)
+	}
+}
+
+// SetWorldSpawnRateLimit ограничивает частоту мировых спавнов
+func (kgs *KCPGameServer) SetWorldSpawnRateLimit(rate, burst int) {
+	if kgs.gameHandler != nil {
+		kgs.gameHandler.SetWorldSpawnRateLimit(rate, burst)
+	}
+}
+
+// SetMaxConnections задаёт общий лимит одновременных подключений KCP и TCP
+// fallback (0 - DefaultMaxConnections, отрицательное значение - без ограничения)
+func (kgs *KCPGameServer) SetMaxConnections(limit int) {
+	kgs.connLimit.setMax(limit)
+}
+
+// ConnectionLimitStats возвращает счётчики подключений и отказов по всем
+// транспортам сервера
+func (kgs *KCPGameServer) ConnectionLimitStats() ConnectionLimitStats {
+	stats := kgs.connLimit.stats()
+	stats.Rejected = kgs.kcpServer.ConnectionLimitStats().Rejected
+	if kgs.tcpServer != nil {
+		stats.Rejected += kgs.tcpServer.ConnectionLimitStats().Rejected
+	}
+	return stats
+}
+
+// RuntimeParams возвращает параметры, настраиваемые без перезапуска
+func (kgs *KCPGameServer) RuntimeParams() RuntimeParams {
+	return kgs.gameHandler.RuntimeParams()
+}
+
+// SetRuntimeParams применяет параметры к работающему серверу
+func (kgs *KCPGameServer) SetRuntimeParams(params RuntimeParams) error {
+	return kgs.gameHandler.SetRuntimeParams(params)
+}
+
+// SetRegionRouting задаёт таблицу регионов для перенаправления игроков при входе
+func (kgs *KCPGameServer) SetRegionRouting(localID string, routes []RegionRoute) error {
+	return kgs.gameHandler.SetRegionRouting(localID, routes)
+}
+
+// SetBlockUpdateWindow задаёт окно накопления изменений блоков перед рассылкой
+func (kgs *KCPGameServer) SetBlockUpdateWindow(window time.Duration) {
+	if kgs.gameHandler != nil {
+		kgs.gameHandler.SetBlockUpdateWindow(window)
+	}
+}
+
+// StartDrain отклоняет новые входы, оставляя подключённых игроков
+func (kgs *KCPGameServer) StartDrain(timeout time.Duration) DrainStatus {
+	return kgs.gameHandler.StartDrain(timeout)
+}
+
+// StopDrain снова открывает сервер для новых игроков
+func (kgs *KCPGameServer) StopDrain() DrainStatus {
+	return kgs.gameHandler.StopDrain()
+}
+
+// DrainStatus возвращает ход вывода сервера из работы
+func (kgs *KCPGameServer) DrainStatus() DrainStatus {
+	return kgs.gameHandler.DrainStatus()
+}
+
+// SetPlayerStealth включает или выключает невидимость администратора в сети
+func (kgs *KCPGameServer) SetPlayerStealth(username string, enabled bool) error {
+	return kgs.gameHandler.SetPlayerStealth(username, enabled)
+}
+
+// SetSpectator включает или выключает режим наблюдателя администратора
+func (kgs *KCPGameServer) SetSpectator(connID string, enabled bool) error {
+	return kgs.gameHandler.SetSpectator(connID, enabled)
+}
+
+// SetEntityHidden скрывает сущность от обычных игроков
+func (kgs *KCPGameServer) SetEntityHidden(entityID uint64, hidden bool) error {
+	return kgs.gameHandler.SetEntityHidden(entityID, hidden)
+}
+
+// TeleportPlayer перемещает игрока администратором с предзагрузкой чанков
+func (kgs *KCPGameServer) TeleportPlayer(username string, dest vec.Vec3) (TeleportResult, error) {
+	return kgs.gameHandler.TeleportPlayer(username, dest)
+}
+
+// BroadcastWorldEvent рассылает событие мира игрокам в target и записывает его в EventBus
+func (kgs *KCPGameServer) BroadcastWorldEvent(eventType string, data map[string]interface{}, target WorldEventTarget) (WorldEventResult, error) {
+	return kgs.gameHandler.BroadcastWorldEvent(eventType, data, target)
+}
+
+// ExportRegion выгружает снимок области игрового мира
+func (kgs *KCPGameServer) ExportRegion(topLeft, bottomRight vec.Vec2) ([]byte, error) {
+	return kgs.worldManager.ExportRegion(topLeft, bottomRight)
+}
+
+// ImportRegion заменяет область игрового мира снимком. Подключённые игроки
+// увидят изменения после повторной загрузки чанков
+func (kgs *KCPGameServer) ImportRegion(data []byte) error {
+	return kgs.worldManager.ImportRegion(data)
+}
+
+// AddClaim создаёт приват на прямоугольник чанков (см. world.WorldManager.AddClaim)
+func (kgs *KCPGameServer) AddClaim(ownerID uint64, name string, from, to vec.Vec2) (storage_interface.Claim, error) {
+	return kgs.worldManager.AddClaim(ownerID, name, from, to)
+}
+
+// RemoveClaim удаляет приват
+func (kgs *KCPGameServer) RemoveClaim(id uint64) error {
+	return kgs.worldManager.RemoveClaim(id)
+}
+
+// SetWorldMetaStore подключает хранилище метаданных игрового мира: сохранённые
+// точка спавна и приваты восстанавливаются (см. world.WorldManager.SetWorldMetaStore)
+func (kgs *KCPGameServer) SetWorldMetaStore(store storage_interface.WorldMetaStore) error {
+	return kgs.worldManager.SetWorldMetaStore(store)
+}
+
+// GetWorldSpawn возвращает точку спавна игрового мира
+func (kgs *KCPGameServer) GetWorldSpawn() vec.Vec2 {
+	return kgs.worldManager.GetWorldSpawn()
+}
+
+// SetWorldSpawn задаёт точку спавна игрового мира и сохраняет её в метаданных
+// мира (см. world.WorldManager.SetWorldSpawn)
+func (kgs *KCPGameServer) SetWorldSpawn(pos vec.Vec2) error {
+	return kgs.worldManager.SetWorldSpawn(pos)
+}
+
+// Claims возвращает приваты игрового мира
+func (kgs *KCPGameServer) Claims() []storage_interface.Claim {
+	return kgs.worldManager.Claims()
+}
+
+// SetWorldBorder задаёт границу мира (nil - снимает) и сообщает о ней игрокам
+func (kgs *KCPGameServer) SetWorldBorder(border *storage_interface.WorldBorder) error {
+	return kgs.gameHandler.SetWorldBorder(border)
+}
+
+// SaveWorld сохраняет игровой мир (force - независимо от времени последнего сохранения)
+func (kgs *KCPGameServer) SaveWorld(force bool) {
+	kgs.worldManager.SaveWorld(force)
+}
+
+// SetChunkWorkers задаёт число горутин сериализации чанков
+func (kgs *KCPGameServer) SetChunkWorkers(workers int) {
+	if kgs.gameHandler != nil {
+		kgs.gameHandler.SetChunkWorkers(workers)
+	}
+}
+
+// SetReconnectSaveGrace задаёт отсрочку сохранения позиции при отключении
+func (kgs *KCPGameServer) SetReconnectSaveGrace(grace time.Duration) {
+	if kgs.gameHandler != nil {
+		kgs.gameHandler.SetReconnectSaveGrace(grace)
+	}
+}
+
+// SetChunkRequestLimits задаёт частоту запросов чанков и окно отбрасывания повторов
+func (kgs *KCPGameServer) SetChunkRequestLimits(rate int, resendWindow time.Duration) {
+	if kgs.gameHandler != nil {
+		kgs.gameHandler.SetChunkRequestLimits(rate, resendWindow)
+	}
+}
+
+// SetAnticheat подключает движок античита
+func (kgs *KCPGameServer) SetAnticheat(engine *anticheat.Engine) {
+	if kgs.gameHandler != nil {
+		kgs.gameHandler.SetAnticheat(engine)
+	}
+}
+
+// SetMaxReachDistance задаёт максимальную дистанцию взаимодействия с блоками
+func (kgs *KCPGameServer) SetMaxReachDistance(distance float64) {
+	if kgs.gameHandler != nil {
+		kgs.gameHandler.SetMaxReachDistance(distance)
+	}
+}
+
+// SetEntityIDAllocator задаёт аллокатор ID сущностей региона
+func (kgs *KCPGameServer) SetEntityIDAllocator(allocator *entity.EntityIDAllocator) {
+	if kgs.gameHandler != nil {
+		kgs.gameHandler.SetEntityIDAllocator(allocator)
+	}
+}
+
+// SetWorldID задаёт ID мира, которым помечаются события в EventBus.
+// Вызывается до Start
+func (kgs *KCPGameServer) SetWorldID(worldID string) {
+	kgs.worldManager.SetWorldID(worldID)
+}
+
+// SetIdleTimeout настраивает отключение неактивных игроков
+func (kgs *KCPGameServer) SetIdleTimeout(timeout, warning time.Duration) {
+	if kgs.gameHandler != nil {
+		kgs.gameHandler.SetIdleTimeout(timeout, warning)
+	}
+}
+
+// SetDefaultGameMode задаёт режим игры новых игроков
+func (kgs *KCPGameServer) SetDefaultGameMode(mode GameMode) error {
+	return kgs.gameHandler.SetDefaultGameMode(mode)
+}
+
+// SetGameModeActions заменяет список разрешённых действий с блоками в режиме
+func (kgs *KCPGameServer) SetGameModeActions(mode GameMode, actions []string) error {
+	return kgs.gameHandler.SetGameModeActions(mode, actions)
+}
+
+// SetGameModeMineOnBreak задаёт, добывает ли break блок в режиме или сразу убирает его
+func (kgs *KCPGameServer) SetGameModeMineOnBreak(mode GameMode, mine bool) error {
+	return kgs.gameHandler.SetGameModeMineOnBreak(mode, mine)
+}
+
+// SetVoidRules задаёт обработку игроков над пропастью
+func (kgs *KCPGameServer) SetVoidRules(rules VoidRules) error {
+	return kgs.gameHandler.SetVoidRules(rules)
+}
+
+// SetMiningRules задаёт параметры времени добычи блоков
+func (kgs *KCPGameServer) SetMiningRules(rules MiningRules) {
+	kgs.gameHandler.SetMiningRules(rules)
+}
+
+// SetItemDropRules задаёт срок жизни и радиус объединения предметов на земле
+func (kgs *KCPGameServer) SetItemDropRules(rules ItemDropRules) {
+	kgs.gameHandler.SetItemDropRules(rules)
+}
+
+// SetPlayerGameMode переключает режим игры игрока в сети по имени пользователя
+func (kgs *KCPGameServer) SetPlayerGameMode(username string, mode GameMode) error {
+	return kgs.gameHandler.SetPlayerGameMode(username, mode)
+}
+
+// SetMoveInputBuffer задаёт глубину буфера ввода движения в тиках (0 - без буфера)
+func (kgs *KCPGameServer) SetMoveInputBuffer(depth int) {
+	if kgs.gameHandler != nil {
+		kgs.gameHandler.SetMoveInputBuffer(depth)
+	}
+}
+
+// SetMaxPayloadSize ограничивает размер входящих сообщений
+func (kgs *KCPGameServer) SetMaxPayloadSize(size int) {
+	if kgs.gameHandler != nil {
+		kgs.gameHandler.SetMaxPayloadSize(size)
+	}
+}
+
+// SetMaxOversizedMessages задаёт порог отключения за слишком большие сообщения
+func (kgs *KCPGameServer) SetMaxOversizedMessages(limit int) {
+	if kgs.gameHandler != nil {
+		kgs.gameHandler.SetMaxOversizedMessages(limit)
+	}
+}
+
+// SetMaxUnknownMessages задаёт порог отключения за сообщения неизвестного типа
+func (kgs *KCPGameServer) SetMaxUnknownMessages(limit int) {
+	if kgs.gameHandler != nil {
+		kgs.gameHandler.SetMaxUnknownMessages(limit)
+	}
+}
+
+// SetMessageTimeout ограничивает время обработки одного сообщения клиента
+func (kgs *KCPGameServer) SetMessageTimeout(timeout time.Duration) {
+	if kgs.gameHandler != nil {
+		kgs.gameHandler.SetMessageTimeout(timeout)
+	}
+}
+
+// SetSlowHandlerThreshold задаёт порог предупреждений о медленной обработке сообщений
+func (kgs *KCPGameServer) SetSlowHandlerThreshold(threshold time.Duration) {
+	if kgs.gameHandler != nil {
+		kgs.gameHandler.SetSlowHandlerThreshold(threshold)
+	}
+}
+
+// SetMoveTimestamps включает метку времени в ENTITY_MOVE для интерполяции на клиенте
+func (kgs *KCPGameServer) SetMoveTimestamps(enabled bool) {
+	if kgs.gameHandler != nil {
+		kgs.gameHandler.SetMoveTimestamps(enabled)
+	}
+}
+
+// SetWorldEventBufferSize задаёт размер буфера глобальных событий мира (до Start)
+func (kgs *KCPGameServer) SetWorldEventBufferSize(size int) error {
+	return kgs.worldManager.SetEventBufferSize(size)
+}
+
+// SetCriticalEventTimeout задаёт ожидание места в очереди для изменений блоков
+func (kgs *KCPGameServer) SetCriticalEventTimeout(timeout time.Duration) {
+	kgs.worldManager.SetCriticalEventTimeout(timeout)
+}
+
+// SetBigChunkWorkers переводит симуляцию BigChunk на пул воркеров (до Start,
+// 0 - горутина на каждый BigChunk)
+func (kgs *KCPGameServer) SetBigChunkWorkers(workers int) error {
+	return kgs.worldManager.SetBigChunkWorkers(workers)
+}
+
+// SetEntityCaps задаёт предел сущностей в одном BigChunk и во всём мире
+func (kgs *KCPGameServer) SetEntityCaps(perBigChunk, total int) {
+	kgs.worldManager.SetEntityCaps(perBigChunk, total)
+}
+
+// SetMaxBlockMetadataBytes задаёт предел суммарного размера метаданных одного блока
+func (kgs *KCPGameServer) SetMaxBlockMetadataBytes(limit int) {
+	world.SetMaxBlockMetadataBytes(limit)
+}
+
+// SetBigChunkTickPolicy задаёт пониженную частоту тиков для BigChunk без активности
+func (kgs *KCPGameServer) SetBigChunkTickPolicy(idleRate float64, idleAfter time.Duration) {
+	kgs.worldManager.SetTickPolicy(idleRate, idleAfter)
+}
+
 // GetConnectedClients возвращает количество подключенных клиентов
 func (kgs *KCPGameServer) GetConnectedClients() int {
+	clients := 0
 	if kgs.kcpServer != nil {
-		return kgs.kcpServer.GetClientCount()
+		clients += kgs.kcpServer.GetClientCount()
+	}
+	if kgs.tcpServer != nil {
+		clients += kgs.tcpServer.ConnectionLimitStats().Current
 	}
-	return 0
+	return clients
 }
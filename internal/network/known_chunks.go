@@ -0,0 +1,134 @@
+package network
+
+import (
+	"github.com/annel0/mmo-game/internal/protocol"
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/annel0/mmo-game/internal/world"
+)
+
+// knownChunksHeadroom - запас предела известных чанков сверх зоны видимости
+// в кольцах чанков: чанки, дошедшие до переноса центра, не вытесняют видимые
+const knownChunksHeadroom = 1
+
+// maxKnownChunks - сколько доставленных чанков отслеживается для игрока с
+// дальностью видимости radius: квадрат (2r+1)^2 и кольцо запаса вокруг него.
+// Предел следует за дальностью, поэтому растёт вместе с max_view_distance
+func maxKnownChunks(radius int) int {
+	side := 2*(radius+knownChunksHeadroom) + 1
+	return side * side
+}
+
+// knownChunkSet - чанки, которые уже есть у клиента, с хэшем отправленного содержимого
+type knownChunkSet struct {
+	center vec.Vec2 // Чанк игрока при последней отправке
+	radius int      // Дальность видимости в чанках
+	hashes map[vec.Vec2]world.ChunkHash
+}
+
+// chunkDistance - расстояние между чанками в метрике квадратной зоны видимости
+func chunkDistance(a, b vec.Vec2) int {
+	dx, dy := a.X-b.X, a.Y-b.Y
+	return max(dx, -dx, dy, -dy)
+}
+
+// knownSetLocked возвращает набор чанков игрока, создавая его при необходимости.
+// Вызывается под gh.knownMu
+func (gh *GameHandlerPB) knownSetLocked(connID string) *knownChunkSet {
+	set, ok := gh.knownChunks[connID]
+	if !ok {
+		set = &knownChunkSet{hashes: make(map[vec.Vec2]world.ChunkHash)}
+		gh.knownChunks[connID] = set
+	}
+	return set
+}
+
+// viewCenter возвращает чанк, вокруг которого игроку последний раз отправлялись чанки
+func (gh *GameHandlerPB) viewCenter(connID string) (vec.Vec2, int, bool) {
+	gh.knownMu.Lock()
+	defer gh.knownMu.Unlock()
+
+	set, ok := gh.knownChunks[connID]
+	if !ok {
+		return vec.Vec2{}, 0, false
+	}
+	return set.center, set.radius, true
+}
+
+// recenterKnownChunks переносит зону видимости игрока и забывает чанки за её
+// пределами. Возвращает забытые чанки, чтобы клиент мог их выгрузить
+func (gh *GameHandlerPB) recenterKnownChunks(connID string, center vec.Vec2, radius int) []vec.Vec2 {
+	gh.knownMu.Lock()
+	defer gh.knownMu.Unlock()
+
+	set := gh.knownSetLocked(connID)
+	set.center = center
+	set.radius = radius
+
+	var left []vec.Vec2
+	for pos := range set.hashes {
+		if chunkDistance(pos, center) > radius {
+			left = append(left, pos)
+			delete(set.hashes, pos)
+		}
+	}
+	return left
+}
+
+// isChunkKnown сообщает, есть ли у клиента чанк с тем же содержимым
+func (gh *GameHandlerPB) isChunkKnown(connID string, pos vec.Vec2, hash world.ChunkHash) bool {
+	gh.knownMu.Lock()
+	defer gh.knownMu.Unlock()
+
+	set, ok := gh.knownChunks[connID]
+	if !ok {
+		return false
+	}
+	known, ok := set.hashes[pos]
+	return ok && known == hash
+}
+
+// rememberChunk отмечает чанк доставленным клиенту. При превышении предела
+// забывается самый дальний от игрока чанк: если он понадобится, его пришлют заново.
+// Набор создаётся первой отправкой чанков (startChunkStream), поэтому чанки,
+// дошедшие после отключения игрока, не учитываются
+func (gh *GameHandlerPB) rememberChunk(connID string, pos vec.Vec2, hash world.ChunkHash) {
+	gh.knownMu.Lock()
+	defer gh.knownMu.Unlock()
+
+	set, ok := gh.knownChunks[connID]
+	if !ok {
+		return
+	}
+	set.hashes[pos] = hash
+
+	for len(set.hashes) > maxKnownChunks(set.radius) {
+		farthest, farthestDist := pos, -1
+		for known := range set.hashes {
+			if d := chunkDistance(known, set.center); d > farthestDist {
+				farthest, farthestDist = known, d
+			}
+		}
+		delete(set.hashes, farthest)
+	}
+}
+
+// forgetKnownChunks удаляет сведения о чанках отключившегося игрока
+func (gh *GameHandlerPB) forgetKnownChunks(connID string) {
+	gh.knownMu.Lock()
+	delete(gh.knownChunks, connID)
+	gh.knownMu.Unlock()
+}
+
+// sendChunkUnload сообщает клиенту, что чанки вышли из зоны видимости
+func (gh *GameHandlerPB) sendChunkUnload(connID string, chunks []vec.Vec2) {
+	if len(chunks) == 0 {
+		return
+	}
+	gh.unmarkChunksSent(connID, chunks)
+
+	msg := &protocol.ChunkUnload{Chunks: make([]*protocol.Vec2, 0, len(chunks))}
+	for _, pos := range chunks {
+		msg.Chunks = append(msg.Chunks, &protocol.Vec2{X: int32(pos.X), Y: int32(pos.Y)})
+	}
+	gh.sendTCPMessage(connID, protocol.MessageType_CHUNK_UNLOAD, msg)
+}
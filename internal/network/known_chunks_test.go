@@ -0,0 +1,138 @@
+package network
+
+import (
+	"testing"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/protocol"
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/annel0/mmo-game/internal/world"
+	"github.com/annel0/mmo-game/internal/world/block"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+// collectChunkMessages вычитывает накопившиеся сообщения и возвращает координаты
+// полученных чанков и чанков, предложенных к выгрузке
+func collectChunkMessages(t *testing.T, c *testClient) (sent, unloaded []vec.Vec2) {
+	t.Helper()
+
+	for {
+		select {
+		case msg := <-c.messages:
+			switch msg.Type {
+			case protocol.MessageType_CHUNK_DATA:
+				data := &protocol.ChunkData{}
+				require.NoError(t, proto.Unmarshal(msg.Payload, data))
+				sent = append(sent, vec.Vec2{X: int(data.ChunkX), Y: int(data.ChunkY)})
+			case protocol.MessageType_CHUNK_UNLOAD:
+				unload := &protocol.ChunkUnload{}
+				require.NoError(t, proto.Unmarshal(msg.Payload, unload))
+				for _, pos := range unload.Chunks {
+					unloaded = append(unloaded, vec.Vec2{X: int(pos.X), Y: int(pos.Y)})
+				}
+			}
+		case <-time.After(200 * time.Millisecond):
+			return sent, unloaded
+		}
+	}
+}
+
+func TestKnownChunks_OneTileMoveSendsOnlyNewStrip(t *testing.T) {
+	gh := newTestGameHandler(t)
+	client := connectTestClient(t, gh, "conn-1")
+	addTestSession(gh, "conn-1", 1, 1, vec.Vec2{X: 15, Y: 8}).ViewDistance = 2
+
+	stream := gh.sendInitialChunks("conn-1", 1)
+	require.NotNil(t, stream)
+	<-stream.done
+	sent, _ := collectChunkMessages(t, client)
+	require.Len(t, sent, 25)
+
+	// Шаг на один блок через границу чанка: (15,8) -> (16,8), чанк (0,0) -> (1,0)
+	stream = gh.updateChunkStream("conn-1", vec.Vec2{X: 16, Y: 8})
+	require.NotNil(t, stream)
+	<-stream.done
+
+	sent, unloaded := collectChunkMessages(t, client)
+	assert.Len(t, sent, 5, "досылается только полоса новых чанков")
+	for _, pos := range sent {
+		assert.Equal(t, 3, pos.X)
+	}
+	assert.Len(t, unloaded, 5)
+	for _, pos := range unloaded {
+		assert.Equal(t, -2, pos.X)
+	}
+
+	// Шаг внутри чанка ничего не отправляет
+	assert.Nil(t, gh.updateChunkStream("conn-1", vec.Vec2{X: 17, Y: 8}))
+}
+
+func TestKnownChunks_ChangedChunkResent(t *testing.T) {
+	gh := newTestGameHandler(t)
+	client := connectTestClient(t, gh, "conn-1")
+	addTestSession(gh, "conn-1", 1, 1, vec.Vec2{X: 0, Y: 0}).ViewDistance = 1
+
+	stream := gh.sendInitialChunks("conn-1", 1)
+	require.NotNil(t, stream)
+	<-stream.done
+	sent, _ := collectChunkMessages(t, client)
+	require.Len(t, sent, 9)
+
+	// Чанк остаётся в зоне видимости, но меняется, пока клиент его держит
+	changed := vec.Vec2{X: 0, Y: 1}
+	gh.worldManager.GetChunk(changed).SetBlockLayer(world.LayerActive, vec.Vec2{X: 2, Y: 2}, block.StoneBlockID)
+
+	stream = gh.updateChunkStream("conn-1", vec.Vec2{X: 0, Y: 16})
+	require.NotNil(t, stream)
+	<-stream.done
+
+	sent, _ = collectChunkMessages(t, client)
+	assert.Len(t, sent, 4, "три новых чанка и изменённый")
+	assert.Contains(t, sent, changed)
+}
+
+func TestKnownChunks_CapEvictsFarthest(t *testing.T) {
+	gh := newTestGameHandler(t)
+	gh.recenterKnownChunks("conn-1", vec.Vec2{X: 0, Y: 0}, 1)
+
+	// При дальности 1 отслеживается квадрат 5x5: зона 3x3 и кольцо запаса
+	hash := world.ChunkHash{1}
+	gh.rememberChunk("conn-1", vec.Vec2{X: 5, Y: 5}, hash)
+	for x := -2; x <= 2; x++ {
+		for y := -2; y <= 2; y++ {
+			gh.rememberChunk("conn-1", vec.Vec2{X: x, Y: y}, hash)
+		}
+	}
+
+	assert.False(t, gh.isChunkKnown("conn-1", vec.Vec2{X: 5, Y: 5}, hash), "самый дальний чанк забыт")
+	for _, pos := range []vec.Vec2{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: -2, Y: 2}} {
+		assert.True(t, gh.isChunkKnown("conn-1", pos, hash))
+	}
+	assert.False(t, gh.isChunkKnown("conn-1", vec.Vec2{X: 0, Y: 0}, world.ChunkHash{2}), "изменённый чанк не считается известным")
+
+	gh.forgetKnownChunks("conn-1")
+	assert.False(t, gh.isChunkKnown("conn-1", vec.Vec2{X: 0, Y: 0}, hash))
+}
+
+func TestKnownChunks_CapFollowsViewDistance(t *testing.T) {
+	gh := newTestGameHandler(t)
+	require.NoError(t, gh.SetRuntimeParams(RuntimeParams{
+		WorldUpdateInterval: 1, MaxViewDistance: 32, AutoSaveSeconds: 30,
+		MaxBlockEdits: 10, BlockEditWindowMs: 1000,
+	}))
+	radius := gh.resolveViewDistance(32)
+	require.Equal(t, 32, radius)
+	gh.recenterKnownChunks("conn-1", vec.Vec2{}, radius)
+
+	// Вся зона 65x65 остаётся известной клиенту
+	hash := world.ChunkHash{1}
+	for x := -radius; x <= radius; x++ {
+		for y := -radius; y <= radius; y++ {
+			gh.rememberChunk("conn-1", vec.Vec2{X: x, Y: y}, hash)
+		}
+	}
+	assert.True(t, gh.isChunkKnown("conn-1", vec.Vec2{X: -radius, Y: radius}, hash))
+	assert.True(t, gh.isChunkKnown("conn-1", vec.Vec2{X: radius, Y: -radius}, hash))
+}
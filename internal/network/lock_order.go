@@ -0,0 +1,52 @@
+package network
+
+import (
+	"github.com/annel0/mmo-game/internal/protocol"
+	"google.golang.org/protobuf/proto"
+)
+
+// Порядок блокировок сетевого слоя:
+//
+//	gh.mu -> tcpServer.mu (и блокировки остальных транспортов)
+//
+// Транспорт не вызывает GameHandlerPB, удерживая свою блокировку
+// (OnClientDisconnect запускается после снятия tcpServer.mu), а обработчик
+// не держит gh.mu во время сетевой записи: сообщения, сформированные под
+// gh.mu, складываются в outbox и отправляются через flush после снятия
+// блокировки. Так медленный клиент не задерживает остальных игроков, а
+// обратный порядок захвата блокировок невозможен.
+
+// outgoingMessage - сообщение, отложенное до снятия gh.mu
+type outgoingMessage struct {
+	connID  string // "" - всем соединениям
+	msgType protocol.MessageType
+	payload proto.Message
+}
+
+// outbox накапливает сообщения, сформированные под gh.mu
+type outbox struct {
+	messages []outgoingMessage
+}
+
+// send откладывает сообщение одному соединению
+func (o *outbox) send(connID string, msgType protocol.MessageType, payload proto.Message) {
+	o.messages = append(o.messages, outgoingMessage{connID: connID, msgType: msgType, payload: payload})
+}
+
+// broadcast откладывает сообщение всем соединениям
+func (o *outbox) broadcast(msgType protocol.MessageType, payload proto.Message) {
+	o.send("", msgType, payload)
+}
+
+// flush отправляет накопленные сообщения в порядке добавления.
+// Вызывается без gh.mu
+func (gh *GameHandlerPB) flush(o *outbox) {
+	for _, msg := range o.messages {
+		if msg.connID == "" {
+			gh.broadcastMessage(msg.msgType, msg.payload)
+		} else {
+			gh.sendTCPMessage(msg.connID, msg.msgType, msg.payload)
+		}
+	}
+	o.messages = nil
+}
@@ -0,0 +1,152 @@
+package network
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/protocol"
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/annel0/mmo-game/internal/world/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+// lockCheckSender проверяет порядок блокировок: при каждой отправке gh.mu
+// должен быть свободен. Чанки отправляет фоновая горутина, пока обработчик
+// может законно держать gh.mu, поэтому CHUNK_DATA не проверяется
+type lockCheckSender struct {
+	NetworkSender
+	gh *GameHandlerPB
+
+	mu       sync.Mutex
+	violated []protocol.MessageType // Сообщения, отправленные под gh.mu
+}
+
+func (s *lockCheckSender) check(msgType protocol.MessageType) {
+	if msgType == protocol.MessageType_CHUNK_DATA {
+		return
+	}
+	if s.gh.mu.TryLock() {
+		s.gh.mu.Unlock()
+		return
+	}
+	s.mu.Lock()
+	s.violated = append(s.violated, msgType)
+	s.mu.Unlock()
+}
+
+func (s *lockCheckSender) SendToClient(connID string, msgType protocol.MessageType, payload proto.Message) {
+	s.check(msgType)
+	s.NetworkSender.SendToClient(connID, msgType, payload)
+}
+
+func (s *lockCheckSender) Broadcast(msgType protocol.MessageType, payload proto.Message) {
+	s.check(msgType)
+	s.NetworkSender.Broadcast(msgType, payload)
+}
+
+func (s *lockCheckSender) violations() []protocol.MessageType {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]protocol.MessageType(nil), s.violated...)
+}
+
+func TestLockOrder_NoNetworkWritesUnderHandlerLock(t *testing.T) {
+	gh, _, _ := setupTradePlayers(t)
+	checker := &lockCheckSender{NetworkSender: gh.sender, gh: gh}
+	gh.SetNetworkSender(checker)
+
+	// Питомец остаётся за игроком, снаряд удаляется вместе с ним
+	pet := gh.SpawnEntity(entity.EntityTypeAnimal, vec.Vec2{X: 2})
+	projectile := gh.SpawnEntity(entity.EntityTypeProjectile, vec.Vec2{X: 3})
+	require.True(t, gh.ClaimEntity(pet, 101, entity.OwnerKeep))
+	require.True(t, gh.ClaimEntity(projectile, 101, entity.OwnerDespawn))
+
+	// Открытая сделка отменяется при отключении и рассылает TRADE_UPDATE
+	bobID := uint64(102)
+	sendTrade(t, gh, "conn-1", &protocol.TradeRequest{Action: protocol.TradeAction_TRADE_OPEN, TargetId: &bobID})
+	sendTrade(t, gh, "conn-1", &protocol.TradeRequest{Action: protocol.TradeAction_TRADE_OFFER, ItemId: "sword", Count: 1})
+	gh.OnClientDisconnect("conn-1")
+
+	// Авторизация: появление сущности и ответ уходят после снятия gh.mu
+	authTestClient(t, gh, connectTestClient(t, gh, "conn-3"))
+
+	assert.Empty(t, checker.violations(), "сообщения отправлены под gh.mu")
+}
+
+// TestLockOrder_MixedTrafficStress гоняет авторизацию, движение и отключения
+// параллельно с серверными проверками. Взаимоблокировка проявляется таймаутом,
+// гонки - под go test -race
+func TestLockOrder_MixedTrafficStress(t *testing.T) {
+	gh := newTestGameHandler(t)
+	password := "ChangeMe123!"
+	auth := newGameMessage(t, protocol.MessageType_AUTH, &protocol.AuthMessage{
+		Username:        "admin",
+		Password:        &password,
+		ProtocolVersion: ProtocolVersion,
+	})
+
+	const workers, rounds = 4, 3
+	stop := make(chan struct{})
+	var server sync.WaitGroup
+	server.Add(1)
+	go func() {
+		defer server.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			gh.checkIdlePlayers()
+			gh.autoSavePositions()
+			gh.KickPlayer(1000)
+		}
+	}()
+
+	var clients sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		clients.Add(1)
+		go func(w int) {
+			defer clients.Done()
+			for r := 0; r < rounds; r++ {
+				connID := fmt.Sprintf("stress-%d-%d", w, r)
+				connectTestClient(t, gh, connID)
+				gh.HandleMessage(connID, auth)
+
+				gh.mu.RLock()
+				entityID := gh.playerEntities[connID]
+				gh.mu.RUnlock()
+				for step := int32(1); step <= 3; step++ {
+					data, _ := proto.Marshal(&protocol.EntityMoveMessage{
+						Entities: []*protocol.EntityData{{Id: entityID, Position: &protocol.Vec2{X: step, Y: 0}}},
+					})
+					gh.HandleMessage(connID, &protocol.GameMessage{Type: protocol.MessageType_ENTITY_MOVE, Payload: data})
+				}
+				gh.cancelChunkStream(connID)
+				gh.OnClientDisconnect(connID)
+				gh.tcpServer.removeConnection(connID)
+			}
+		}(w)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		clients.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Minute):
+		t.Fatal("смешанная нагрузка не завершилась: вероятна взаимоблокировка")
+	}
+	close(stop)
+	server.Wait()
+
+	gh.mu.RLock()
+	defer gh.mu.RUnlock()
+	assert.Empty(t, gh.sessions)
+}
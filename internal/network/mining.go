@@ -0,0 +1,208 @@
+package network
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/protocol"
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/annel0/mmo-game/internal/world"
+	"github.com/annel0/mmo-game/internal/world/block"
+	"github.com/annel0/mmo-game/internal/world/entity"
+)
+
+// Время добычи блоков: каждый удар (BLOCK_UPDATE с действием mine/break)
+// добавляет к прогрессу силу, зависящую от уровня инструмента игрока. Блок
+// разрушается, когда прогресс достигает его прочности. Прогресс хранится
+// на сервере для пары игрок+блок и сбрасывается при смене цели или перерыве
+const (
+	// DefaultMineHitInterval - минимальный интервал между засчитываемыми ударами;
+	// удары чаще не добавляют прогресса
+	DefaultMineHitInterval = 250 * time.Millisecond
+
+	// DefaultMineResetAfter - перерыв в ударах, после которого прогресс сбрасывается
+	DefaultMineResetAfter = time.Second
+
+	// DefaultToolTierBonus - прибавка к силе удара за уровень инструмента
+	DefaultToolTierBonus = 1.0
+)
+
+// Эффекты добычи в BlockData.effects рассылки BLOCK_UPDATE
+const (
+	effectBreakProgress = "break_progress" // break_progress:<процент>
+	effectBreakCancel   = "break_cancel"   // Прогресс сброшен
+	effectBreak         = "particle_break" // Блок разрушен
+)
+
+// DefaultToolTiers - уровни инструментов по ID предмета инвентаря. Игрок бьёт
+// лучшим инструментом из имеющихся, без инструмента - рукой (уровень 0)
+var DefaultToolTiers = map[string]int{
+	"wooden_pickaxe": 1,
+	"stone_pickaxe":  2,
+	"iron_pickaxe":   3,
+}
+
+// defaultBlockHardness - прочность блоков без ключа hardness в метаданных
+// (например, сгенерированных миром). Блоки без записи разрушаются с первого удара
+var defaultBlockHardness = map[block.BlockID]float64{
+	block.StoneBlockID: 10, // Как StoneBehavior.CreateMetadata
+	block.TreeBlockID:  4,
+}
+
+// MiningRules - параметры модели времени добычи
+type MiningRules struct {
+	Disabled      bool           // Прочность уменьшает поведение блока, как до модели времени добычи
+	HitInterval   time.Duration  // 0 - DefaultMineHitInterval, отрицательное значение - без ограничения
+	ResetAfter    time.Duration  // 0 - DefaultMineResetAfter
+	ToolTierBonus float64        // 0 - DefaultToolTierBonus
+	ToolTiers     map[string]int // Уровень инструмента по ID предмета; nil - DefaultToolTiers
+}
+
+// miningProgress - прогресс добычи блока игроком
+type miningProgress struct {
+	pos      vec.Vec2
+	layer    world.BlockLayer
+	blockID  block.BlockID
+	progress float64   // Накопленная сила ударов
+	lastHit  time.Time // Последний засчитанный удар
+}
+
+// DefaultMiningRules возвращает параметры добычи по умолчанию
+func DefaultMiningRules() MiningRules {
+	return MiningRules{
+		HitInterval:   DefaultMineHitInterval,
+		ResetAfter:    DefaultMineResetAfter,
+		ToolTierBonus: DefaultToolTierBonus,
+		ToolTiers:     DefaultToolTiers,
+	}
+}
+
+// SetMiningRules задаёт параметры модели времени добычи
+func (gh *GameHandlerPB) SetMiningRules(rules MiningRules) {
+	if rules.HitInterval == 0 {
+		rules.HitInterval = DefaultMineHitInterval
+	}
+	if rules.ResetAfter <= 0 {
+		rules.ResetAfter = DefaultMineResetAfter
+	}
+	if rules.ToolTierBonus <= 0 {
+		rules.ToolTierBonus = DefaultToolTierBonus
+	}
+	if rules.ToolTiers == nil {
+		rules.ToolTiers = DefaultToolTiers
+	}
+
+	gh.mu.Lock()
+	gh.miningRules = rules
+	gh.mu.Unlock()
+}
+
+// blockHardness возвращает прочность блока: ключ hardness метаданных или
+// defaultBlockHardness
+func blockHardness(b world.Block) float64 {
+	if hardness, ok := block.MetadataFloat(b.Payload, "hardness"); ok {
+		return hardness
+	}
+	return defaultBlockHardness[b.ID]
+}
+
+// toolTier возвращает уровень лучшего инструмента в инвентаре игрока
+func toolTier(player *entity.Entity, tiers map[string]int) int {
+	inventory, ok := player.Payload[payloadInventory].(map[string]interface{})
+	if !ok {
+		return 0
+	}
+	best := 0
+	for itemID, tier := range tiers {
+		if tier > best && entityInventory(inventory).Count(itemID) > 0 {
+			best = tier
+		}
+	}
+	return best
+}
+
+// mineHit засчитывает удар игрока по блоку и рассылает прогресс. Возвращает
+// true, когда прогресс достиг прочности и блок пора разрушить, и долю
+// набранной прочности
+func (gh *GameHandlerPB) mineHit(connID string, playerEntityID uint64, pos vec.Vec2, layer world.BlockLayer, target world.Block, hardness float64) (bool, float64) {
+	gh.mu.RLock()
+	tiers := gh.miningRules.ToolTiers
+	gh.mu.RUnlock()
+
+	tier := 0
+	if e, ok := gh.entityManager.GetEntity(playerEntityID); ok {
+		tier = toolTier(e, tiers)
+	}
+
+	now := gh.now()
+	gh.mu.Lock()
+	rules := gh.miningRules
+	strength := 1 + float64(tier)*rules.ToolTierBonus
+	current := gh.mining[connID]
+	var cancelled *miningProgress
+	if current != nil && (current.pos != pos || current.layer != layer || current.blockID != target.ID ||
+		now.Sub(current.lastHit) > rules.ResetAfter) {
+		cancelled = current
+		current = nil
+	}
+	counted := true
+	if current == nil {
+		current = &miningProgress{pos: pos, layer: layer, blockID: target.ID}
+		gh.mining[connID] = current
+	} else if rules.HitInterval > 0 && now.Sub(current.lastHit) < rules.HitInterval {
+		counted = false // Удары чаще интервала не ускоряют добычу
+	}
+	if counted {
+		current.progress += strength
+		current.lastHit = now
+	}
+	done := current.progress >= hardness
+	if done {
+		delete(gh.mining, connID)
+	}
+	progress := current.progress / hardness
+	gh.mu.Unlock()
+
+	if cancelled != nil && (cancelled.pos != pos || cancelled.layer != layer) {
+		gh.broadcastMiningEffect(cancelled.pos, cancelled.blockID, effectBreakCancel)
+	}
+	if !done {
+		gh.broadcastMiningEffect(pos, target.ID, breakProgressEffect(progress))
+	}
+	return done, progress
+}
+
+// breakProgressEffect формирует эффект прогресса в процентах
+func breakProgressEffect(progress float64) string {
+	return fmt.Sprintf("%s:%d", effectBreakProgress, int(progress*100))
+}
+
+// expireMining сбрасывает прогресс игроков, переставших добывать блок
+func (gh *GameHandlerPB) expireMining() {
+	now := gh.now()
+	var expired []*miningProgress
+
+	gh.mu.Lock()
+	for connID, current := range gh.mining {
+		if now.Sub(current.lastHit) > gh.miningRules.ResetAfter {
+			expired = append(expired, current)
+			delete(gh.mining, connID)
+		}
+	}
+	gh.mu.Unlock()
+
+	for _, current := range expired {
+		gh.broadcastMiningEffect(current.pos, current.blockID, effectBreakCancel)
+	}
+}
+
+// broadcastMiningEffect рассылает эффект добычи блока без изменения самого блока
+func (gh *GameHandlerPB) broadcastMiningEffect(pos vec.Vec2, blockID block.BlockID, effect string) {
+	gh.broadcastMessage(protocol.MessageType_BLOCK_UPDATE, &protocol.BlockUpdateMessage{
+		Blocks: []*protocol.BlockData{{
+			Position: &protocol.Vec2{X: int32(pos.X), Y: int32(pos.Y)},
+			BlockId:  uint32(blockID),
+			Effects:  []string{effect},
+		}},
+	})
+}
@@ -0,0 +1,120 @@
+package network
+
+import (
+	"testing"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/protocol"
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/annel0/mmo-game/internal/world"
+	"github.com/annel0/mmo-game/internal/world/block"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// expectBlockEffect ждёт рассылку BLOCK_UPDATE с эффектом для позиции,
+// пропуская остальные обновления блоков
+func expectBlockEffect(t *testing.T, client *testClient, pos vec.Vec2, effect string) {
+	t.Helper()
+	for {
+		msg := &protocol.BlockUpdateMessage{}
+		client.expect(t, protocol.MessageType_BLOCK_UPDATE, msg)
+		for _, data := range msg.Blocks {
+			if int(data.Position.X) == pos.X && int(data.Position.Y) == pos.Y &&
+				len(data.Effects) == 1 && data.Effects[0] == effect {
+				return
+			}
+		}
+	}
+}
+
+func TestMining_HardBlockNeedsSeveralHits(t *testing.T) {
+	gh := newTestGameHandler(t)
+	registerTestBlock(t, block.StoneBlockID, "stone")
+	now := time.Now()
+	gh.now = func() time.Time { return now }
+	miner := connectTestClient(t, gh, "conn-miner")
+	addTestSession(gh, "conn-miner", 1, 1, vec.Vec2{})
+	observer := connectTestClient(t, gh, "conn-observer")
+	addTestSession(gh, "conn-observer", 2, 2, vec.Vec2{})
+
+	pos := vec.Vec2{X: 1, Y: 1}
+	gh.worldManager.SetBlockLayer(pos, world.LayerActive, world.NewBlock(block.StoneBlockID))
+	hardness := blockHardness(gh.worldManager.GetBlockLayer(pos, world.LayerActive))
+	require.Equal(t, 10.0, hardness)
+
+	// Кирка уровня 1 в инвентаре удваивает силу удара: 10 прочности за 5 ударов
+	e, _ := gh.entityManager.GetEntity(1)
+	e.Payload[payloadInventory] = map[string]interface{}{"wooden_pickaxe": 1}
+	_, version := gh.worldManager.GetBlockLayerVersion(pos, world.LayerActive)
+	for hit := 1; hit < 5; hit++ {
+		resp := breakBlock(t, gh, miner, "conn-miner", pos)
+		assert.True(t, resp.Success, resp.Message)
+		assert.Equal(t, uint32(block.StoneBlockID), resp.BlockId)
+		assert.Equal(t, version, resp.Version, "удар без разрушения не пишет блок")
+		assert.Equal(t, []string{breakProgressEffect(float64(hit*2) / hardness)}, resp.Effects)
+		assert.Equal(t, block.StoneBlockID, gh.worldManager.GetBlockLayer(pos, world.LayerActive).ID, "удар %d не должен разрушить камень", hit)
+		expectBlockEffect(t, observer, pos, breakProgressEffect(float64(hit*2)/hardness))
+
+		// Удар раньше интервала не добавляет прогресса
+		if hit == 2 {
+			breakBlock(t, gh, miner, "conn-miner", pos)
+			expectBlockEffect(t, observer, pos, breakProgressEffect(float64(hit*2)/hardness))
+		}
+		now = now.Add(DefaultMineHitInterval)
+	}
+
+	resp := breakBlock(t, gh, miner, "conn-miner", pos)
+	assert.True(t, resp.Success, resp.Message)
+	assert.Equal(t, []string{effectBreak}, resp.Effects)
+	assert.Equal(t, block.AirBlockID, gh.worldManager.GetBlockLayer(pos, world.LayerActive).ID)
+}
+
+func TestMining_SoftBlockBreaksImmediately(t *testing.T) {
+	gh := newTestGameHandler(t)
+	registerTestBlock(t, block.DoorBlockID, "door")
+	client := connectTestClient(t, gh, "conn-1")
+	addTestSession(gh, "conn-1", 1, 1, vec.Vec2{})
+
+	pos := vec.Vec2{X: 1, Y: 1}
+	gh.worldManager.SetBlockLayer(pos, world.LayerActive, world.NewBlock(block.DoorBlockID))
+
+	resp := breakBlock(t, gh, client, "conn-1", pos)
+	assert.True(t, resp.Success, resp.Message)
+	assert.Equal(t, block.AirBlockID, gh.worldManager.GetBlockLayer(pos, world.LayerActive).ID)
+}
+
+func TestMining_SwitchingTargetOrPausingResetsProgress(t *testing.T) {
+	gh := newTestGameHandler(t)
+	registerTestBlock(t, block.StoneBlockID, "stone")
+	now := time.Now()
+	gh.now = func() time.Time { return now }
+	client := connectTestClient(t, gh, "conn-1")
+	addTestSession(gh, "conn-1", 1, 1, vec.Vec2{})
+	observer := connectTestClient(t, gh, "conn-observer")
+	addTestSession(gh, "conn-observer", 2, 2, vec.Vec2{})
+
+	first, second := vec.Vec2{X: 1, Y: 1}, vec.Vec2{X: 1, Y: 2}
+	for _, pos := range []vec.Vec2{first, second} {
+		gh.worldManager.SetBlockLayer(pos, world.LayerActive, world.NewBlock(block.StoneBlockID))
+	}
+
+	for i := 0; i < 3; i++ {
+		breakBlock(t, gh, client, "conn-1", first)
+		now = now.Add(DefaultMineHitInterval)
+	}
+	expectBlockEffect(t, observer, first, breakProgressEffect(0.3))
+
+	// Другая цель: прогресс первого блока отменяется, второй начинается с нуля
+	breakBlock(t, gh, client, "conn-1", second)
+	expectBlockEffect(t, observer, first, effectBreakCancel)
+	expectBlockEffect(t, observer, second, breakProgressEffect(0.1))
+
+	// Игрок перестал бить: прогресс сбрасывается по истечении перерыва
+	now = now.Add(DefaultMineResetAfter + time.Millisecond)
+	gh.expireMining()
+	expectBlockEffect(t, observer, second, effectBreakCancel)
+	breakBlock(t, gh, client, "conn-1", second)
+	expectBlockEffect(t, observer, second, breakProgressEffect(0.1))
+	assert.Equal(t, block.StoneBlockID, gh.worldManager.GetBlockLayer(second, world.LayerActive).ID)
+}
@@ -0,0 +1,179 @@
+package network
+
+import (
+	"log"
+
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/annel0/mmo-game/internal/world/entity"
+)
+
+const (
+	// MaxMoveInputBuffer - верхняя граница глубины буфера ввода движения в тиках
+	MaxMoveInputBuffer = 20
+
+	// moveDesyncDistance - расстояние в блоках между соседними вводами, после
+	// которого буфер считается рассинхронизированным и сбрасывается
+	moveDesyncDistance = 8
+
+	// moveBufferOverflow - во сколько раз очередь может превысить глубину,
+	// прежде чем отставание считается рассинхронизацией
+	moveBufferOverflow = 4
+)
+
+// moveInputBuffer сглаживает ввод движения одного игрока: вводы ставятся в
+// очередь по мере прихода, а тик применяет не больше одного шага. Перемещение
+// больше чем на блок разбивается на шаги по одному блоку, поэтому после паузы
+// сущность не перепрыгивает, а проходит путь за несколько тиков.
+//
+// Воспроизведение начинается, когда в очереди накопилось depth шагов или
+// первый шаг прождал depth тиков: глубина - это задержка, в обмен на которую
+// всплески и паузы в доставке не видны другим игрокам.
+type moveInputBuffer struct {
+	depth   int
+	steps   []vec.Vec2 // Очередь шагов, не больше блока каждый
+	last    vec.Vec2   // Последняя поставленная в очередь позиция
+	playing bool       // Очередь воспроизводится по шагу за тик
+	waited  int        // Тиков ожидания накопления перед воспроизведением
+}
+
+func newMoveInputBuffer(depth int) *moveInputBuffer {
+	return &moveInputBuffer{depth: depth}
+}
+
+// push ставит в очередь перемещение в target; current - позиция сущности.
+// Возвращает false при рассинхронизации (слишком далёкий ввод или переполнение):
+// очередь сброшена, и ввод нужно применить сразу
+func (b *moveInputBuffer) push(current, target vec.Vec2) bool {
+	from := current
+	if len(b.steps) > 0 {
+		from = b.last
+	}
+
+	distance := chebyshevDistance(from, target)
+	if distance > moveDesyncDistance || len(b.steps)+distance > b.depth*moveBufferOverflow {
+		b.reset()
+		return false
+	}
+
+	for i := 1; i <= distance; i++ {
+		b.steps = append(b.steps, vec.Vec2{
+			X: from.X + (target.X-from.X)*i/distance,
+			Y: from.Y + (target.Y-from.Y)*i/distance,
+		})
+	}
+	b.last = target
+	return true
+}
+
+// next возвращает шаг текущего тика
+func (b *moveInputBuffer) next() (vec.Vec2, bool) {
+	if len(b.steps) == 0 {
+		b.playing = false
+		b.waited = 0
+		return vec.Vec2{}, false
+	}
+
+	if !b.playing {
+		b.waited++
+		if len(b.steps) < b.depth && b.waited < b.depth {
+			return vec.Vec2{}, false
+		}
+		b.playing = true
+	}
+
+	step := b.steps[0]
+	b.steps = b.steps[1:]
+	return step, true
+}
+
+// reset отбрасывает очередь
+func (b *moveInputBuffer) reset() {
+	b.steps = nil
+	b.playing = false
+	b.waited = 0
+}
+
+// chebyshevDistance - число шагов по одному блоку (включая диагональ) от a до b
+func chebyshevDistance(a, b vec.Vec2) int {
+	return max(absInt(b.X-a.X), absInt(b.Y-a.Y))
+}
+
+func absInt(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// SetMoveInputBuffer задаёт глубину буфера ввода движения в тиках: больше -
+// плавнее движение при неровной доставке, но выше задержка. 0 выключает буфер,
+// и ввод применяется сразу по приходу
+func (gh *GameHandlerPB) SetMoveInputBuffer(depth int) {
+	depth = min(max(depth, 0), MaxMoveInputBuffer)
+
+	gh.moveInputsMu.Lock()
+	gh.moveInputDepth = depth
+	// Очереди со старой глубиной применяются сразу же - проще начать заново
+	clear(gh.moveInputs)
+	gh.moveInputsMu.Unlock()
+}
+
+// bufferMoveInput ставит перемещение в очередь игрока. Возвращает false, если
+// ввод нужно применить сразу: буфер выключен или очередь рассинхронизирована
+func (gh *GameHandlerPB) bufferMoveInput(connID string, ent *entity.Entity, target vec.Vec2) bool {
+	gh.moveInputsMu.Lock()
+	defer gh.moveInputsMu.Unlock()
+
+	if gh.moveInputDepth == 0 {
+		return false
+	}
+
+	buf, ok := gh.moveInputs[connID]
+	if !ok {
+		buf = newMoveInputBuffer(gh.moveInputDepth)
+		gh.moveInputs[connID] = buf
+	}
+	if !buf.push(ent.Position, target) {
+		log.Printf("🔀 Ввод движения %s рассинхронизирован с очередью, применяется сразу", connID)
+		return false
+	}
+	return true
+}
+
+// consumeMoveInputs применяет по одному шагу из очереди каждого игрока
+func (gh *GameHandlerPB) consumeMoveInputs() {
+	type pendingStep struct {
+		connID string
+		step   vec.Vec2
+	}
+
+	gh.moveInputsMu.Lock()
+	steps := make([]pendingStep, 0, len(gh.moveInputs))
+	for connID, buf := range gh.moveInputs {
+		if step, ok := buf.next(); ok {
+			steps = append(steps, pendingStep{connID: connID, step: step})
+		}
+	}
+	gh.moveInputsMu.Unlock()
+
+	for _, p := range steps {
+		gh.mu.RLock()
+		entityID, ok := gh.playerEntities[p.connID]
+		gh.mu.RUnlock()
+		if !ok {
+			continue
+		}
+		ent, exists := gh.entityManager.GetEntity(entityID)
+		if !exists {
+			continue
+		}
+		gh.applyEntityMove(p.connID, ent, p.step, gh.isSpectator(p.connID))
+	}
+}
+
+// dropMoveInputs отбрасывает очередь ввода игрока
+func (gh *GameHandlerPB) dropMoveInputs(connID string) {
+	gh.moveInputsMu.Lock()
+	delete(gh.moveInputs, connID)
+	gh.moveInputsMu.Unlock()
+}
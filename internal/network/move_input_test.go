@@ -0,0 +1,109 @@
+package network
+
+import (
+	"testing"
+
+	"github.com/annel0/mmo-game/internal/protocol"
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/annel0/mmo-game/internal/world"
+	"github.com/annel0/mmo-game/internal/world/block"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// paveTestArea делает прямоугольник проходимым: воздух поверх каменного пола
+func paveTestArea(gh *GameHandlerPB, from, to vec.Vec2) {
+	for x := from.X; x <= to.X; x++ {
+		for y := from.Y; y <= to.Y; y++ {
+			pos := vec.Vec2{X: x, Y: y}
+			gh.worldManager.SetBlockLayer(pos, world.LayerActive, world.NewBlock(block.AirBlockID))
+			gh.worldManager.SetBlockLayer(pos, world.LayerFloor, world.NewBlock(block.StoneBlockID))
+		}
+	}
+}
+
+func TestMoveInputBuffer_SteadyConsumptionOfBurstyInput(t *testing.T) {
+	gh := newTestGameHandler(t)
+	connectTestClient(t, gh, "conn-1")
+	addTestSession(gh, "conn-1", 1, 1, vec.Vec2{X: 0, Y: 0})
+	paveTestArea(gh, vec.Vec2{}, vec.Vec2{X: 20})
+	gh.SetMoveInputBuffer(3)
+
+	// Ввод приходит пачками и с паузами, в среднем примерно по шагу за тик
+	bursts := []int{4, 0, 0, 1, 3, 0, 2, 0, 0, 1}
+	sent := 0
+	for tick, burst := range bursts {
+		for i := 0; i < burst; i++ {
+			sent++
+			moveTo(t, gh, "conn-1", 1, vec.Vec2{X: sent, Y: 0})
+		}
+		gh.Tick(0.05)
+
+		assert.Equal(t, vec.Vec2{X: tick + 1, Y: 0}, playerEntityFor(t, gh, "conn-1").Position,
+			"тик %d применяет ровно один шаг", tick)
+	}
+
+	// Остаток очереди доигрывается, затем сущность стоит на месте
+	gh.Tick(0.05)
+	gh.Tick(0.05)
+	assert.Equal(t, vec.Vec2{X: sent, Y: 0}, playerEntityFor(t, gh, "conn-1").Position)
+}
+
+func TestMoveInputBuffer_InterpolatesGapAndDropsOnDesync(t *testing.T) {
+	gh := newTestGameHandler(t)
+	connectTestClient(t, gh, "conn-1")
+	addTestSession(gh, "conn-1", 1, 1, vec.Vec2{X: 0, Y: 0})
+	paveTestArea(gh, vec.Vec2{}, vec.Vec2{X: 40, Y: 3})
+	gh.SetMoveInputBuffer(2)
+
+	// После паузы клиент сообщает позицию на три блока дальше: сущность
+	// проходит её по блоку за тик, а не прыгает
+	moveTo(t, gh, "conn-1", 1, vec.Vec2{X: 3, Y: 3})
+	var path []vec.Vec2
+	for i := 0; i < 3; i++ {
+		gh.Tick(0.05)
+		path = append(path, playerEntityFor(t, gh, "conn-1").Position)
+	}
+	assert.Equal(t, []vec.Vec2{{X: 1, Y: 1}, {X: 2, Y: 2}, {X: 3, Y: 3}}, path)
+
+	// Большое расхождение сбрасывает очередь и применяется сразу
+	moveTo(t, gh, "conn-1", 1, vec.Vec2{X: 4, Y: 3})
+	moveTo(t, gh, "conn-1", 1, vec.Vec2{X: 5, Y: 3})
+	far := vec.Vec2{X: 30, Y: 3}
+	moveTo(t, gh, "conn-1", 1, far)
+	assert.Equal(t, far, playerEntityFor(t, gh, "conn-1").Position)
+	for i := 0; i < 3; i++ {
+		gh.Tick(0.05)
+	}
+	assert.Equal(t, far, playerEntityFor(t, gh, "conn-1").Position, "устаревшие шаги отброшены")
+
+	// Отключение буфера возвращает немедленное применение
+	gh.SetMoveInputBuffer(0)
+	moveTo(t, gh, "conn-1", 1, vec.Vec2{X: 31, Y: 3})
+	require.Equal(t, vec.Vec2{X: 31, Y: 3}, playerEntityFor(t, gh, "conn-1").Position)
+}
+
+func TestMoveInputBuffer_DroppedOnRespawn(t *testing.T) {
+	gh := newTestGameHandler(t)
+	connectTestClient(t, gh, "conn-1")
+	addTestSession(gh, "conn-1", 1, 1, vec.Vec2{})
+	paveTestArea(gh, vec.Vec2{}, vec.Vec2{X: 40, Y: 3})
+	require.NoError(t, gh.worldManager.SetWorldSpawn(vec.Vec2{X: 40, Y: 3}))
+	gh.SetMoveInputBuffer(3)
+
+	moveTo(t, gh, "conn-1", 1, vec.Vec2{X: 1})
+	moveTo(t, gh, "conn-1", 1, vec.Vec2{X: 2})
+
+	actor := playerEntityFor(t, gh, "conn-1")
+	actor.Active = false
+	success, _, _ := gh.processEntityAction(actor.ID, &protocol.EntityActionRequest{
+		ActionType: protocol.EntityActionType_ACTION_RESPAWN,
+	})
+	require.True(t, success)
+
+	// Шаги, поставленные до гибели, не уводят игрока со спавна
+	for i := 0; i < 4; i++ {
+		gh.Tick(0.05)
+	}
+	assert.Equal(t, vec.Vec2{X: 40, Y: 3}, playerEntityFor(t, gh, "conn-1").Position)
+}
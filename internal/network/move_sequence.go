@@ -0,0 +1,38 @@
+package network
+
+// moveSequence отслеживает последовательность UDP-пакетов перемещения одного клиента.
+// Движение передаётся без гарантии доставки и порядка, поэтому применяется только
+// пакет новее последнего обработанного; опоздавшие и повторные отбрасываются.
+type moveSequence struct {
+	latest  uint32 // sequence последнего обработанного пакета
+	ackBits uint32 // бит i - обработан пакет latest-(i+1)
+	started bool
+}
+
+// sequenceNewer сообщает, новее ли a, чем b, с учётом переполнения uint32
+func sequenceNewer(a, b uint32) bool {
+	return int32(a-b) > 0
+}
+
+// accept регистрирует пакет с номером seq и возвращает false, если он устарел или повторный
+func (ms *moveSequence) accept(seq uint32) bool {
+	if !ms.started {
+		ms.started = true
+		ms.latest = seq
+		ms.ackBits = 0
+		return true
+	}
+
+	if !sequenceNewer(seq, ms.latest) {
+		return false
+	}
+
+	shift := seq - ms.latest
+	if shift <= 32 {
+		ms.ackBits = ms.ackBits<<shift | 1<<(shift-1)
+	} else {
+		ms.ackBits = 0
+	}
+	ms.latest = seq
+	return true
+}
@@ -0,0 +1,28 @@
+package network
+
+import (
+	"time"
+
+	"github.com/annel0/mmo-game/internal/protocol"
+)
+
+// SetMoveTimestamps включает или выключает метку времени в ENTITY_MOVE
+// (по умолчанию включена). Без неё сообщения короче, но клиент не может
+// интерполировать чужие сущности с постоянной задержкой
+func (gh *GameHandlerPB) SetMoveTimestamps(enabled bool) {
+	gh.moveTimestampsOff.Store(!enabled)
+}
+
+// stampMove добавляет в ENTITY_MOVE тик и монотонное время сервера, к
+// которым относятся позиции. Время отсчитывается от создания обработчика и
+// не зависит от перевода системных часов
+func (gh *GameHandlerPB) stampMove(msg *protocol.EntityMoveMessage) *protocol.EntityMoveMessage {
+	if gh.moveTimestampsOff.Load() {
+		return msg
+	}
+	tick := gh.worldManager.CurrentTick()
+	elapsed := time.Since(gh.startedAt).Milliseconds()
+	msg.ServerTick = &tick
+	msg.ServerTimeMs = &elapsed
+	return msg
+}
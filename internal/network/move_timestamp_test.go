@@ -0,0 +1,45 @@
+package network
+
+import (
+	"testing"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/protocol"
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMoveTimestamps_MonotonicAcrossUpdates(t *testing.T) {
+	gh := newTestGameHandler(t)
+	connectTestClient(t, gh, "mover")
+	addTestSession(gh, "mover", 10, 10, vec.Vec2{X: 1, Y: 1})
+	observer := connectTestClient(t, gh, "observer")
+	addTestSession(gh, "observer", 11, 11, vec.Vec2{})
+	mover, ok := gh.entityManager.GetEntity(10)
+	require.True(t, ok)
+
+	var lastTick uint64
+	var lastTime int64 = -1
+	for i := 1; i <= 3; i++ {
+		gh.worldManager.Step(uint64(i), 0.05)
+		time.Sleep(2 * time.Millisecond)
+		gh.sendEntityMoveUpdate(mover)
+
+		msg := &protocol.EntityMoveMessage{}
+		observer.expect(t, protocol.MessageType_ENTITY_MOVE, msg)
+		require.NotNil(t, msg.ServerTick)
+		require.NotNil(t, msg.ServerTimeMs)
+		assert.Greater(t, msg.GetServerTick(), lastTick)
+		assert.Greater(t, msg.GetServerTimeMs(), lastTime)
+		lastTick, lastTime = msg.GetServerTick(), msg.GetServerTimeMs()
+	}
+
+	// Без метки сообщение не содержит полей времени
+	gh.SetMoveTimestamps(false)
+	gh.sendEntityMoveUpdate(mover)
+	msg := &protocol.EntityMoveMessage{}
+	observer.expect(t, protocol.MessageType_ENTITY_MOVE, msg)
+	assert.Nil(t, msg.ServerTick)
+	assert.Nil(t, msg.ServerTimeMs)
+}
@@ -0,0 +1,84 @@
+package network
+
+import (
+	"time"
+
+	"github.com/annel0/mmo-game/internal/protocol"
+	"google.golang.org/protobuf/proto"
+)
+
+// NetworkSender доставляет исходящие сообщения GameHandlerPB клиентам.
+// По умолчанию это TCP-сервер (SetTCPServer); тесты подставляют
+// перехватчик через SetNetworkSender (см. пакет testharness).
+type NetworkSender interface {
+	// SendToClient отправляет сообщение одному соединению
+	SendToClient(connID string, msgType protocol.MessageType, payload proto.Message)
+	// Broadcast отправляет сообщение всем соединениям
+	Broadcast(msgType protocol.MessageType, payload proto.Message)
+	// ConnectionIDs возвращает активные соединения
+	ConnectionIDs() []string
+	// BindPlayer связывает соединение с сущностью игрока после аутентификации
+	BindPlayer(connID string, playerID uint64)
+	// Disconnect принудительно отключает клиента: отправляет ему SERVER_MESSAGE
+	// с причиной, вызывает OnClientDisconnect (сохранение позиции, EntityDespawn)
+	// и закрывает соединение
+	Disconnect(connID string, reason DisconnectReason)
+}
+
+// DisconnectReason - причина принудительного отключения клиента
+type DisconnectReason struct {
+	Code    string                 // Код SERVER_MESSAGE, например ServerMessageIdleKick
+	Message string                 // Текст для игрока
+	Despawn protocol.DespawnReason // Причина в EntityDespawn сущности игрока для других игроков
+}
+
+// ServerMessage возвращает последнее сообщение, которое клиент получает перед отключением
+func (r DisconnectReason) ServerMessage() *protocol.ServerMessage {
+	return &protocol.ServerMessage{
+		Code:      r.Code,
+		Message:   r.Message,
+		Timestamp: time.Now().UnixNano(),
+	}
+}
+
+// SetNetworkSender задаёт транспорт исходящих сообщений
+func (gh *GameHandlerPB) SetNetworkSender(sender NetworkSender) {
+	gh.sender = sender
+}
+
+// tcpSender реализует NetworkSender поверх TCPServerPB
+type tcpSender struct {
+	server *TCPServerPB
+}
+
+func (s tcpSender) SendToClient(connID string, msgType protocol.MessageType, payload proto.Message) {
+	s.server.sendToClient(connID, msgType, payload)
+}
+
+func (s tcpSender) Broadcast(msgType protocol.MessageType, payload proto.Message) {
+	s.server.broadcastMessage(msgType, payload)
+}
+
+func (s tcpSender) ConnectionIDs() []string {
+	s.server.mu.RLock()
+	defer s.server.mu.RUnlock()
+
+	ids := make([]string, 0, len(s.server.connections))
+	for connID := range s.server.connections {
+		ids = append(ids, connID)
+	}
+	return ids
+}
+
+func (s tcpSender) BindPlayer(connID string, playerID uint64) {
+	s.server.mu.Lock()
+	defer s.server.mu.Unlock()
+
+	if conn, ok := s.server.connections[connID]; ok {
+		conn.playerID = playerID
+	}
+}
+
+func (s tcpSender) Disconnect(connID string, reason DisconnectReason) {
+	s.server.disconnectClient(connID, reason)
+}
@@ -0,0 +1,47 @@
+package network
+
+import (
+	"log"
+
+	"github.com/annel0/mmo-game/internal/protocol"
+	"github.com/annel0/mmo-game/internal/world/entity"
+)
+
+// ClaimEntity закрепляет сущность за игроком, которым управляет сущность
+// ownerEntityID. Владение хранится по UserID, поэтому переживает переподключение:
+// при отключении игрока действует policy (см. entity.OwnerPolicy)
+func (gh *GameHandlerPB) ClaimEntity(entityID, ownerEntityID uint64, policy entity.OwnerPolicy) bool {
+	gh.mu.RLock()
+	var userID uint64
+	for _, session := range gh.sessions {
+		if session.EntityID == ownerEntityID {
+			userID = session.UserID
+			break
+		}
+	}
+	gh.mu.RUnlock()
+
+	if userID == 0 {
+		return false
+	}
+	return gh.entityManager.SetOwner(entityID, userID, ownerEntityID, policy)
+}
+
+// releaseOwnedEntitiesLocked применяет политики владения к сущностям
+// отключившегося пользователя. Вызывается под gh.mu, оповещения об удалении
+// откладываются в out
+func (gh *GameHandlerPB) releaseOwnedEntitiesLocked(userID uint64, out *outbox) {
+	for _, entityID := range gh.entityManager.OwnerDisconnected(userID) {
+		gh.entityManager.DespawnEntity(entityID, gh)
+		delete(gh.droppedItems, entityID)
+		out.broadcastDespawn(entityID, protocol.DespawnReason_DESPAWN_REASON_OWNER_LEFT)
+	}
+}
+
+// reclaimOwnedEntities возвращает игроку сущности, сохранившиеся за ним
+// с прошлой сессии (питомцы, предметы)
+func (gh *GameHandlerPB) reclaimOwnedEntities(userID, entityID uint64) {
+	if owned := gh.entityManager.ReclaimOwned(userID, entityID); len(owned) > 0 {
+		log.Printf("🐾 Пользователю %d возвращено сущностей: %d", userID, len(owned))
+	}
+}
@@ -0,0 +1,72 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/annel0/mmo-game/internal/protocol"
+)
+
+const (
+	// DefaultMaxOversizedMessages - сколько слишком больших сообщений допускается до отключения
+	DefaultMaxOversizedMessages = 3
+
+	// ServerMessagePayloadTooLarge - код уведомления об отклонённом сообщении
+	ServerMessagePayloadTooLarge = "payload_too_large"
+)
+
+// SetMaxPayloadSize задаёт максимальный размер входящего сообщения в байтах (<= 0 - по умолчанию)
+func (gh *GameHandlerPB) SetMaxPayloadSize(size int) {
+	gh.serializer.SetMaxPayloadSize(size)
+	if gh.tcpServer != nil {
+		gh.tcpServer.serializer.SetMaxPayloadSize(size)
+	}
+	if gh.udpServer != nil {
+		gh.udpServer.serializer.SetMaxPayloadSize(size)
+	}
+}
+
+// SetMaxOversizedMessages задаёт, после скольких слишком больших сообщений
+// соединение закрывается (0 - не отключать).
+func (gh *GameHandlerPB) SetMaxOversizedMessages(limit int) {
+	gh.mu.Lock()
+	gh.maxOversizedMessages = limit
+	gh.mu.Unlock()
+}
+
+// rejectOversized отклоняет слишком большое сообщение и отключает клиента при повторных нарушениях
+func (gh *GameHandlerPB) rejectOversized(connID string, err error) {
+	var tooLarge *protocol.PayloadTooLargeError
+	if !errors.As(err, &tooLarge) {
+		return
+	}
+
+	gh.mu.Lock()
+	gh.oversizedMessages[connID]++
+	count := gh.oversizedMessages[connID]
+	limit := gh.maxOversizedMessages
+	gh.mu.Unlock()
+
+	log.Printf("⚠️ Отклонено слишком большое сообщение от %s: %d байт (лимит %d), нарушений: %d",
+		connID, tooLarge.Size, tooLarge.Limit, count)
+
+	gh.sendServerMessage(connID, ServerMessagePayloadTooLarge,
+		fmt.Sprintf("Сообщение слишком большое: %d байт (максимум %d)", tooLarge.Size, tooLarge.Limit))
+
+	if limit > 0 && count >= limit {
+		log.Printf("🚫 Отключение %s: превышен лимит слишком больших сообщений", connID)
+		gh.disconnectClient(connID, DisconnectReason{
+			Code:    ServerMessageProtocolViolation,
+			Message: "Отключено: слишком много слишком больших сообщений",
+			Despawn: protocol.DespawnReason_DESPAWN_REASON_KICKED,
+		})
+	}
+}
+
+// OversizedMessageCount возвращает число отклонённых слишком больших сообщений соединения
+func (gh *GameHandlerPB) OversizedMessageCount(connID string) int {
+	gh.mu.RLock()
+	defer gh.mu.RUnlock()
+	return gh.oversizedMessages[connID]
+}
@@ -0,0 +1,154 @@
+package network
+
+import (
+	"context"
+	"log"
+
+	"github.com/annel0/mmo-game/internal/storage"
+	"github.com/annel0/mmo-game/internal/world/entity"
+)
+
+// Ключи Payload сущности игрока, которые сохраняются между сессиями
+const (
+	payloadLevel         = "level"
+	payloadExperience    = "experience"
+	payloadStatusEffects = "status_effects" // map[string]float64: эффект -> оставшаяся длительность (сек)
+)
+
+// SetPlayerStateRepo устанавливает репозиторий прогресса игроков (уровень, опыт, эффекты)
+func (gh *GameHandlerPB) SetPlayerStateRepo(repo storage.PlayerStateRepo) {
+	gh.playerStateRepo = repo
+}
+
+// playerStateFromEntity извлекает сохраняемое состояние из Payload сущности игрока
+func playerStateFromEntity(e *entity.Entity) storage.PlayerState {
+	state := storage.PlayerState{Level: 1}
+	if level, ok := payloadInt(e.Payload[payloadLevel]); ok && level > 0 {
+		state.Level = level
+	}
+	if experience, ok := payloadInt(e.Payload[payloadExperience]); ok && experience > 0 {
+		state.Experience = experience
+	}
+
+	state.StatusEffects = copyEffectMap(e.Payload[payloadStatusEffects])
+	state.EffectIntensity = copyEffectMap(e.Payload[payloadEffectIntensity])
+	return state
+}
+
+// applyPlayerState восстанавливает сохранённое состояние в Payload сущности игрока
+func applyPlayerState(e *entity.Entity, state storage.PlayerState) {
+	if e.Payload == nil {
+		e.Payload = make(map[string]interface{})
+	}
+	e.Payload[payloadLevel] = state.Level
+	e.Payload[payloadExperience] = state.Experience
+	if len(state.StatusEffects) > 0 {
+		restored := state.Clone()
+		e.Payload[payloadStatusEffects] = restored.StatusEffects
+		e.Payload[payloadEffectIntensity] = restored.EffectIntensity
+	}
+}
+
+// copyEffectMap копирует карту эффектов из Payload (nil, если её нет)
+func copyEffectMap(value interface{}) map[string]float64 {
+	switch value.(type) {
+	case map[string]float64, map[string]interface{}:
+	default:
+		return nil
+	}
+	source := floatMap(value)
+	copied := make(map[string]float64, len(source))
+	for effect, v := range source {
+		copied[effect] = v
+	}
+	return copied
+}
+
+// payloadInt приводит числовое значение Payload к int (после JSON числа приходят как float64)
+func payloadInt(value interface{}) (int, bool) {
+	switch v := value.(type) {
+	case int:
+		return v, true
+	case int32:
+		return int(v), true
+	case int64:
+		return int(v), true
+	case float64:
+		return int(v), true
+	default:
+		return 0, false
+	}
+}
+
+// restorePlayerState загружает прогресс пользователя в только что созданную сущность игрока
+func (gh *GameHandlerPB) restorePlayerState(ctx context.Context, userID, entityID uint64) {
+	if gh.playerStateRepo == nil {
+		return
+	}
+
+	state, found, err := gh.playerStateRepo.Load(ctx, userID)
+	if err != nil {
+		log.Printf("⚠️ Ошибка загрузки состояния игрока %d: %v", userID, err)
+		return
+	}
+	if !found {
+		return
+	}
+
+	playerEntity, exists := gh.entityManager.GetEntity(entityID)
+	if !exists {
+		return
+	}
+	applyPlayerState(playerEntity, state)
+	gh.trackStatusEffects(playerEntity)
+	log.Printf("📈 Восстановлен прогресс пользователя %d: уровень %d, опыт %d", userID, state.Level, state.Experience)
+}
+
+// savePlayerState сохраняет прогресс игрока (вызывается при отключении)
+func (gh *GameHandlerPB) savePlayerState(userID, entityID uint64) {
+	if gh.playerStateRepo == nil {
+		return
+	}
+
+	playerEntity, exists := gh.entityManager.GetEntity(entityID)
+	if !exists {
+		return
+	}
+
+	gh.effectsMu.Lock()
+	state := playerStateFromEntity(playerEntity)
+	gh.effectsMu.Unlock()
+
+	if err := gh.playerStateRepo.Save(context.Background(), userID, state); err != nil {
+		log.Printf("❌ Ошибка сохранения состояния игрока %d: %v", userID, err)
+	}
+}
+
+// autoSavePlayerStates сохраняет прогресс всех онлайн-игроков одним пакетом
+func (gh *GameHandlerPB) autoSavePlayerStates() {
+	if gh.playerStateRepo == nil {
+		return
+	}
+
+	states := make(map[uint64]storage.PlayerState)
+	gh.mu.RLock()
+	for connID, session := range gh.sessions {
+		entityID, exists := gh.playerEntities[connID]
+		if !exists {
+			continue
+		}
+		if playerEntity, found := gh.entityManager.GetEntity(entityID); found {
+			gh.effectsMu.Lock()
+			states[session.UserID] = playerStateFromEntity(playerEntity)
+			gh.effectsMu.Unlock()
+		}
+	}
+	gh.mu.RUnlock()
+
+	if len(states) == 0 {
+		return
+	}
+	if err := gh.playerStateRepo.BatchSave(context.Background(), states); err != nil {
+		log.Printf("❌ Ошибка автосохранения прогресса игроков: %v", err)
+	}
+}
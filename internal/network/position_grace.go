@@ -0,0 +1,168 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/protocol"
+	"github.com/annel0/mmo-game/internal/storage"
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// DefaultReconnectSaveGrace - сколько сохранение позиции отключившегося
+// игрока ждёт его переподключения
+const DefaultReconnectSaveGrace = 5 * time.Second
+
+// Результаты сохранения позиции при отключении (метка result)
+const (
+	positionSaveDeferred  = "deferred"  // Сохранение отложено до истечения отсрочки
+	positionSaveCancelled = "cancelled" // Игрок переподключился, сохранение отменено
+	positionSaveExecuted  = "executed"  // Позиция записана в хранилище
+)
+
+var disconnectPositionSaves = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "network",
+	Name:      "disconnect_position_saves_total",
+	Help:      "Сохранения позиции при отключении: отложенные, отменённые переподключением и выполненные.",
+}, []string{"result"})
+
+func init() {
+	prometheus.MustRegister(disconnectPositionSaves)
+}
+
+// pendingPositionSave - позиция отключившегося игрока, ещё не записанная в хранилище
+type pendingPositionSave struct {
+	userID   uint64
+	username string
+	pos      vec.Vec3
+	version  uint64    // Версия владения позицией (см. acquirePositionVersion)
+	due      time.Time // Когда сохранить, если игрок не переподключится
+}
+
+// SetReconnectSaveGrace задаёт отсрочку сохранения позиции при отключении:
+// переподключение в её пределах продолжает прежнюю позицию без записи и
+// чтения хранилища. 0 - DefaultReconnectSaveGrace, отрицательное значение -
+// сохранять сразу при отключении
+func (gh *GameHandlerPB) SetReconnectSaveGrace(grace time.Duration) {
+	if grace == 0 {
+		grace = DefaultReconnectSaveGrace
+	}
+
+	gh.mu.Lock()
+	gh.reconnectSaveGrace = max(grace, 0)
+	gh.mu.Unlock()
+
+	if grace < 0 {
+		gh.FlushPendingPositionSaves()
+	}
+}
+
+// savePositionOnDisconnectLocked сохраняет позицию отключающегося игрока.
+// При обрыве соединения сохранение откладывается на время отсрочки, при
+// отключении сервером (кик, бездействие) выполняется сразу. Вызывается под gh.mu
+func (gh *GameHandlerPB) savePositionOnDisconnectLocked(session *Session, entityID uint64, reason protocol.DespawnReason) {
+	if gh.positionRepo == nil {
+		log.Printf("⚠️ Репозиторий позиций не настроен, позиция не сохранена")
+		return
+	}
+	currentPos, found := gh.GetEntityPosition(entityID)
+	if !found {
+		log.Printf("⚠️ Не удалось получить позицию сущности %d для сохранения", entityID)
+		return
+	}
+
+	pending := &pendingPositionSave{
+		userID:   session.UserID,
+		username: session.Username,
+		pos:      currentPos,
+		version:  session.positionVersion,
+	}
+	if gh.reconnectSaveGrace <= 0 || reason != protocol.DespawnReason_DESPAWN_REASON_DISCONNECTED {
+		gh.executePositionSave(pending)
+		return
+	}
+
+	pending.due = gh.now().Add(gh.reconnectSaveGrace)
+	if gh.pendingPositionSaves == nil {
+		gh.pendingPositionSaves = make(map[uint64]*pendingPositionSave)
+	}
+	gh.pendingPositionSaves[session.UserID] = pending
+	disconnectPositionSaves.WithLabelValues(positionSaveDeferred).Inc()
+	log.Printf("⏳ Сохранение позиции игрока %s отложено на %v", session.Username, gh.reconnectSaveGrace)
+}
+
+// resumablePosition возвращает позицию и версию владения из отложенного
+// сохранения игрока, если он переподключается в пределах отсрочки
+func (gh *GameHandlerPB) resumablePosition(userID uint64) (pendingPositionSave, bool) {
+	gh.mu.RLock()
+	defer gh.mu.RUnlock()
+
+	pending, ok := gh.pendingPositionSaves[userID]
+	if !ok {
+		return pendingPositionSave{}, false
+	}
+	return *pending, true
+}
+
+// cancelPendingPositionSaveLocked отменяет отложенное сохранение
+// переподключившегося игрока. Вызывается под gh.mu
+func (gh *GameHandlerPB) cancelPendingPositionSaveLocked(userID uint64) {
+	if _, ok := gh.pendingPositionSaves[userID]; !ok {
+		return
+	}
+	delete(gh.pendingPositionSaves, userID)
+	disconnectPositionSaves.WithLabelValues(positionSaveCancelled).Inc()
+}
+
+// savePendingPositions записывает позиции, отсрочка которых истекла без переподключения
+func (gh *GameHandlerPB) savePendingPositions() {
+	now := gh.now()
+	var due []*pendingPositionSave
+
+	gh.mu.Lock()
+	for userID, pending := range gh.pendingPositionSaves {
+		if !now.Before(pending.due) {
+			due = append(due, pending)
+			delete(gh.pendingPositionSaves, userID)
+		}
+	}
+	gh.mu.Unlock()
+
+	for _, pending := range due {
+		gh.executePositionSave(pending)
+	}
+}
+
+// FlushPendingPositionSaves сразу записывает все отложенные сохранения
+// позиций (при остановке сервера)
+func (gh *GameHandlerPB) FlushPendingPositionSaves() {
+	gh.mu.Lock()
+	pending := gh.pendingPositionSaves
+	gh.pendingPositionSaves = nil
+	gh.mu.Unlock()
+
+	for _, save := range pending {
+		gh.executePositionSave(save)
+	}
+}
+
+// executePositionSave записывает позицию отключившегося игрока в хранилище
+func (gh *GameHandlerPB) executePositionSave(save *pendingPositionSave) {
+	if gh.positionRepo == nil {
+		return
+	}
+
+	err := gh.positionRepo.SaveVersioned(context.Background(), save.userID, save.pos, save.version)
+	switch {
+	case errors.Is(err, storage.ErrPositionConflict):
+		log.Printf("⚠️ Позиция игрока %s не сохранена: игроком уже владеет другой узел", save.username)
+	case err != nil:
+		log.Printf("❌ Ошибка сохранения позиции для пользователя %d: %v", save.userID, err)
+	default:
+		disconnectPositionSaves.WithLabelValues(positionSaveExecuted).Inc()
+		log.Printf("💾 Позиция игрока %s сохранена: (%d, %d, %d)", save.username, save.pos.X, save.pos.Y, save.pos.Z)
+	}
+}
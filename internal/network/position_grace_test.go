@@ -0,0 +1,131 @@
+package network
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/storage"
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingPositionRepo считает обращения к хранилищу позиций
+type countingPositionRepo struct {
+	storage.PositionRepo
+	loads, acquires, saves atomic.Int32
+}
+
+func (r *countingPositionRepo) Load(ctx context.Context, userID uint64) (vec.Vec3, bool, error) {
+	r.loads.Add(1)
+	return r.PositionRepo.Load(ctx, userID)
+}
+
+func (r *countingPositionRepo) AcquireVersion(ctx context.Context, userID uint64) (uint64, error) {
+	r.acquires.Add(1)
+	return r.PositionRepo.AcquireVersion(ctx, userID)
+}
+
+func (r *countingPositionRepo) SaveVersioned(ctx context.Context, userID uint64, pos vec.Vec3, version uint64) error {
+	r.saves.Add(1)
+	return r.PositionRepo.SaveVersioned(ctx, userID, pos, version)
+}
+
+func TestReconnectGrace_ReconnectSkipsIntermediateSave(t *testing.T) {
+	gh := newTestGameHandler(t)
+	now := time.Unix(1000, 0)
+	gh.now = func() time.Time { return now }
+	repo := &countingPositionRepo{PositionRepo: storage.NewMemoryPositionRepo()}
+	gh.SetPositionRepo(repo)
+	gh.SetReconnectSaveGrace(5 * time.Second)
+
+	deferred := testutil.ToFloat64(disconnectPositionSaves.WithLabelValues(positionSaveDeferred))
+	cancelled := testutil.ToFloat64(disconnectPositionSaves.WithLabelValues(positionSaveCancelled))
+	executed := testutil.ToFloat64(disconnectPositionSaves.WithLabelValues(positionSaveExecuted))
+
+	authTestClient(t, gh, connectTestClient(t, gh, "conn-1"))
+	gh.entityManager.MoveEntity(playerEntityFor(t, gh, "conn-1").ID, vec.Vec2Float{X: 120, Y: 20})
+	require.EqualValues(t, 1, repo.loads.Load())
+	require.EqualValues(t, 1, repo.acquires.Load())
+
+	// Связь оборвалась, игрок переподключается в пределах отсрочки
+	gh.OnClientDisconnect("conn-1")
+	now = now.Add(2 * time.Second)
+	authTestClient(t, gh, connectTestClient(t, gh, "conn-2"))
+
+	resumed := playerEntityFor(t, gh, "conn-2")
+	assert.Equal(t, vec.Vec2{X: 120, Y: 20}, resumed.Position, "позиция продолжается без чтения хранилища")
+	assert.EqualValues(t, 1, repo.loads.Load())
+	assert.EqualValues(t, 1, repo.acquires.Load())
+
+	// Отсрочка истекла, но игрок в сети: промежуточного сохранения нет
+	now = now.Add(10 * time.Second)
+	gh.savePendingPositions()
+	assert.Zero(t, repo.saves.Load())
+	assert.Equal(t, deferred+1, testutil.ToFloat64(disconnectPositionSaves.WithLabelValues(positionSaveDeferred)))
+	assert.Equal(t, cancelled+1, testutil.ToFloat64(disconnectPositionSaves.WithLabelValues(positionSaveCancelled)))
+
+	// Окончательное отключение сохраняет позицию после отсрочки
+	gh.entityManager.MoveEntity(resumed.ID, vec.Vec2Float{X: 130, Y: 25})
+	gh.OnClientDisconnect("conn-2")
+	gh.savePendingPositions()
+	assert.Zero(t, repo.saves.Load(), "до истечения отсрочки позиция не пишется")
+
+	now = now.Add(5 * time.Second)
+	gh.savePendingPositions()
+	assert.EqualValues(t, 1, repo.saves.Load())
+	assert.Equal(t, executed+1, testutil.ToFloat64(disconnectPositionSaves.WithLabelValues(positionSaveExecuted)))
+
+	saved, found, err := repo.PositionRepo.Load(context.Background(), 1)
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.Equal(t, vec.Vec3{X: 130, Y: 25, Z: 1}, saved)
+}
+
+func TestReconnectGrace_DisabledSavesImmediately(t *testing.T) {
+	gh := newTestGameHandler(t)
+	repo := &countingPositionRepo{PositionRepo: storage.NewMemoryPositionRepo()}
+	gh.SetPositionRepo(repo)
+	gh.SetReconnectSaveGrace(-1)
+
+	authTestClient(t, gh, connectTestClient(t, gh, "conn-1"))
+	gh.OnClientDisconnect("conn-1")
+	assert.EqualValues(t, 1, repo.saves.Load())
+}
+
+func TestReconnectGrace_FlushOnShutdown(t *testing.T) {
+	gh := newTestGameHandler(t)
+	repo := &countingPositionRepo{PositionRepo: storage.NewMemoryPositionRepo()}
+	gh.SetPositionRepo(repo)
+
+	authTestClient(t, gh, connectTestClient(t, gh, "conn-1"))
+	gh.OnClientDisconnect("conn-1")
+	require.Zero(t, repo.saves.Load())
+
+	gh.FlushPendingPositionSaves()
+	assert.EqualValues(t, 1, repo.saves.Load())
+	_, found, err := repo.PositionRepo.Load(context.Background(), 1)
+	require.NoError(t, err)
+	assert.True(t, found)
+}
+
+func TestReconnectGrace_ServerStopWaitsForDisconnects(t *testing.T) {
+	gh := newTestGameHandler(t)
+	server := startLimitedTCPServer(t, -1, gh)
+	repo := &countingPositionRepo{PositionRepo: storage.NewMemoryPositionRepo()}
+	gh.SetPositionRepo(repo)
+
+	dialTCP(t, server)
+	require.Eventually(t, func() bool { return len(gh.sender.ConnectionIDs()) == 1 },
+		time.Second, 10*time.Millisecond)
+	addTestSession(gh, gh.sender.ConnectionIDs()[0], 1, 1, vec.Vec2{X: 7, Y: 3})
+
+	// Stop возвращается только после отключения игрока в обработчике,
+	// поэтому сброс отложенных сохранений застаёт его позицию
+	server.Stop()
+	gh.FlushPendingPositionSaves()
+	assert.EqualValues(t, 1, repo.saves.Load())
+}
@@ -0,0 +1,46 @@
+package network
+
+import "fmt"
+
+// ProtocolVersion - текущая версия сетевого протокола сервера.
+// Увеличивается при любых несовместимых изменениях формата сообщений.
+const ProtocolVersion uint32 = 1
+
+// ProtocolVersionRange описывает диапазон версий протокола, с которыми
+// сервер готов работать. Границы включительные.
+type ProtocolVersionRange struct {
+	Min uint32
+	Max uint32
+}
+
+// DefaultProtocolVersionRange возвращает диапазон, состоящий только из текущей версии
+func DefaultProtocolVersionRange() ProtocolVersionRange {
+	return ProtocolVersionRange{Min: ProtocolVersion, Max: ProtocolVersion}
+}
+
+// withDefaults заполняет незаданные (нулевые) границы значениями по умолчанию
+func (r ProtocolVersionRange) withDefaults() ProtocolVersionRange {
+	if r.Min == 0 {
+		r.Min = ProtocolVersion
+	}
+	if r.Max == 0 {
+		r.Max = ProtocolVersion
+	}
+	if r.Max < r.Min {
+		r.Max = r.Min
+	}
+	return r
+}
+
+// Supports проверяет, входит ли версия клиента в поддерживаемый диапазон
+func (r ProtocolVersionRange) Supports(version uint32) bool {
+	return version >= r.Min && version <= r.Max
+}
+
+// String возвращает диапазон в человекочитаемом виде
+func (r ProtocolVersionRange) String() string {
+	if r.Min == r.Max {
+		return fmt.Sprintf("%d", r.Min)
+	}
+	return fmt.Sprintf("%d-%d", r.Min, r.Max)
+}
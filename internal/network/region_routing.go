@@ -0,0 +1,79 @@
+package network
+
+import (
+	"fmt"
+
+	"github.com/annel0/mmo-game/internal/eventbus"
+	"github.com/annel0/mmo-game/internal/vec"
+)
+
+// RegionRoute - регион многорегионального развёртывания и область мира, которой он владеет
+type RegionRoute struct {
+	ID          string          // Идентификатор региона (sync.region_id)
+	GameAddress string          // Адрес игрового сервера региона (host:port)
+	Bounds      eventbus.Bounds // Координаты блоков, принадлежащие региону
+}
+
+// regionRouting решает, какой регион обслуживает игрока
+type regionRouting struct {
+	localID string
+	routes  []RegionRoute
+}
+
+// SetRegionRouting задаёт текущий регион и таблицу регионов. При аутентификации
+// игрок, чья сохранённая позиция принадлежит другому региону, получает
+// AuthResponse с redirect и адресом этого региона. Пустая таблица отключает перенаправление
+func (gh *GameHandlerPB) SetRegionRouting(localID string, routes []RegionRoute) error {
+	seen := make(map[string]bool, len(routes))
+	for _, route := range routes {
+		if route.ID == "" || route.GameAddress == "" {
+			return fmt.Errorf("регион %q: требуются id и игровой адрес", route.ID)
+		}
+		if seen[route.ID] {
+			return fmt.Errorf("регион %q указан дважды", route.ID)
+		}
+		if route.Bounds.MinX > route.Bounds.MaxX || route.Bounds.MinY > route.Bounds.MaxY {
+			return fmt.Errorf("регион %q: пустая область %+v", route.ID, route.Bounds)
+		}
+		seen[route.ID] = true
+	}
+	if len(routes) > 0 && !seen[localID] {
+		return fmt.Errorf("текущий регион %q отсутствует в таблице регионов", localID)
+	}
+
+	gh.mu.Lock()
+	gh.regions = regionRouting{localID: localID, routes: append([]RegionRoute(nil), routes...)}
+	gh.mu.Unlock()
+	return nil
+}
+
+// owner возвращает регион, владеющий позицией. Позиции вне всех областей
+// обслуживает текущий регион. Первый подходящий регион в таблице имеет приоритет
+func (r regionRouting) owner(pos vec.Vec2) (RegionRoute, bool) {
+	for _, route := range r.routes {
+		if route.Bounds.Contains(pos.X, pos.Y) {
+			return route, true
+		}
+	}
+	return r.local()
+}
+
+// local возвращает запись текущего региона
+func (r regionRouting) local() (RegionRoute, bool) {
+	for _, route := range r.routes {
+		if route.ID == r.localID {
+			return route, true
+		}
+	}
+	return RegionRoute{ID: r.localID}, false
+}
+
+// redirectFor возвращает регион, к которому нужно переподключиться игроку с
+// указанной позицией; ok=false, если игрока обслуживает текущий узел
+func (r regionRouting) redirectFor(pos vec.Vec2) (RegionRoute, bool) {
+	route, found := r.owner(pos)
+	if !found || route.ID == r.localID {
+		return RegionRoute{}, false
+	}
+	return route, true
+}
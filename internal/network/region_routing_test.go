@@ -0,0 +1,96 @@
+package network
+
+import (
+	"context"
+	"testing"
+
+	"github.com/annel0/mmo-game/internal/eventbus"
+	"github.com/annel0/mmo-game/internal/storage"
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testRegions - два региона, разделённых по оси X
+var testRegions = []RegionRoute{
+	{ID: "eu-west-1", GameAddress: "eu.example.com:7777", Bounds: eventbus.Bounds{MinX: -1000, MinY: -1000, MaxX: 499, MaxY: 1000}},
+	{ID: "us-east-1", GameAddress: "us.example.com:7777", Bounds: eventbus.Bounds{MinX: 500, MinY: -1000, MaxX: 1000, MaxY: 1000}},
+}
+
+// newRegionTestHandler создаёт обработчик региона eu-west-1 с сохранённой позицией пользователя admin
+func newRegionTestHandler(t *testing.T, saved vec.Vec3) *GameHandlerPB {
+	t.Helper()
+
+	gh := newTestGameHandler(t)
+	gh.entityManager.RegisterDefaultBehaviors()
+	positions := storage.NewMemoryPositionRepo()
+	require.NoError(t, positions.Save(context.Background(), 1, saved))
+	gh.SetPositionRepo(positions)
+	require.NoError(t, gh.SetRegionRouting("eu-west-1", testRegions))
+	return gh
+}
+
+func TestRegionRouting_RedirectsToOwningRegion(t *testing.T) {
+	gh := newRegionTestHandler(t, vec.Vec3{X: 700, Y: 20})
+	client := connectTestClient(t, gh, "conn-1")
+
+	resp := authTestClient(t, gh, client)
+	assert.True(t, resp.Redirect)
+	assert.Equal(t, "us-east-1", resp.RegionId)
+	assert.Equal(t, "us.example.com:7777", resp.GameAddress)
+
+	// Сессия и сущность на этом узле не создаются
+	gh.mu.RLock()
+	_, hasEntity := gh.playerEntities["conn-1"]
+	gh.mu.RUnlock()
+	assert.False(t, hasEntity)
+	assert.False(t, gh.IsSessionValid("conn-1"))
+}
+
+func TestRegionRouting_LocalPlayerIsNotRedirected(t *testing.T) {
+	gh := newRegionTestHandler(t, vec.Vec3{X: 100, Y: 20})
+	client := connectTestClient(t, gh, "conn-1")
+
+	resp := authTestClient(t, gh, client)
+	assert.False(t, resp.Redirect)
+	assert.Equal(t, "eu-west-1", resp.RegionId)
+	assert.Equal(t, "eu.example.com:7777", resp.GameAddress)
+	assert.True(t, gh.IsSessionValid("conn-1"))
+	playerEntityFor(t, gh, "conn-1")
+}
+
+func TestRegionRouting_Validation(t *testing.T) {
+	gh := newTestGameHandler(t)
+
+	assert.Error(t, gh.SetRegionRouting("ap-south-1", testRegions), "текущий регион должен быть в таблице")
+	assert.Error(t, gh.SetRegionRouting("eu-west-1", append(testRegions, testRegions[0])), "повтор региона")
+	assert.Error(t, gh.SetRegionRouting("eu-west-1", []RegionRoute{{ID: "eu-west-1"}}), "нет адреса")
+	assert.NoError(t, gh.SetRegionRouting("", nil), "пустая таблица отключает перенаправление")
+}
+
+func TestRegionHandoff_StaleRegionCannotOverwritePosition(t *testing.T) {
+	positions := storage.NewMemoryPositionRepo()
+	require.NoError(t, positions.Save(context.Background(), 1, vec.Vec3{X: 100, Y: 20, Z: 1}))
+
+	// Игрок вошёл на первый узел, затем, не дождавшись его отключения, на второй
+	stale := newTestGameHandler(t)
+	stale.SetPositionRepo(positions)
+	authTestClient(t, stale, connectTestClient(t, stale, "conn-eu"))
+	stale.entityManager.MoveEntity(playerEntityFor(t, stale, "conn-eu").ID, vec.Vec2Float{X: 120, Y: 20})
+
+	current := newTestGameHandler(t)
+	current.SetPositionRepo(positions)
+	authTestClient(t, current, connectTestClient(t, current, "conn-us"))
+	current.entityManager.MoveEntity(playerEntityFor(t, current, "conn-us").ID, vec.Vec2Float{X: 130, Y: 25})
+
+	// Новый владелец сохраняет позицию, запоздалое сохранение прежнего отклоняется
+	current.OnClientDisconnect("conn-us")
+	current.FlushPendingPositionSaves()
+	stale.OnClientDisconnect("conn-eu")
+	stale.FlushPendingPositionSaves()
+
+	saved, found, err := positions.Load(context.Background(), 1)
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.Equal(t, vec.Vec3{X: 130, Y: 25, Z: 1}, saved)
+}
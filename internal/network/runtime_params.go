@@ -0,0 +1,227 @@
+package network
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/anticheat"
+)
+
+const (
+	// DefaultWorldUpdateInterval - интервал рассылки обновлений мира в тиках
+	// (2 тика = 10 раз в секунду при 20 TPS)
+	DefaultWorldUpdateInterval = 2
+	// DefaultAutoSaveInterval - период автосохранения позиций и прогресса игроков
+	DefaultAutoSaveInterval = 30 * time.Second
+)
+
+// RuntimeParams - параметры обработчика, которые можно менять без перезапуска
+type RuntimeParams struct {
+	WorldUpdateInterval int `json:"world_update_interval"`     // Тиков между рассылками обновлений мира
+	MaxViewDistance     int `json:"max_view_distance"`         // Максимальная дальность видимости в чанках
+	AutoSaveSeconds     int `json:"autosave_interval_seconds"` // Период автосохранения игроков
+	MaxBlockEdits       int `json:"max_block_edits"`           // Правок блоков за окно (античит)
+	BlockEditWindowMs   int `json:"block_edit_window_ms"`      // Окно подсчёта правок, мс
+}
+
+// Допустимые диапазоны RuntimeParams; name совпадает с JSON-именем поля
+var runtimeParamRanges = []struct {
+	name     string
+	min, max int
+	field    func(*RuntimeParams) *int
+}{
+	{"world_update_interval", 1, 100, func(p *RuntimeParams) *int { return &p.WorldUpdateInterval }},
+	{"max_view_distance", 1, 32, func(p *RuntimeParams) *int { return &p.MaxViewDistance }},
+	{"autosave_interval_seconds", 5, 3600, func(p *RuntimeParams) *int { return &p.AutoSaveSeconds }},
+	{"max_block_edits", 1, 1000, func(p *RuntimeParams) *int { return &p.MaxBlockEdits }},
+	{"block_edit_window_ms", 100, 60000, func(p *RuntimeParams) *int { return &p.BlockEditWindowMs }},
+}
+
+// blockEditLimit - предел частоты правок блоков, заданный через
+// SetRuntimeParams. Хранится в обработчике, а не только в движке античита:
+// без движка значения не теряются и применяются к движку, подключённому позже
+type blockEditLimit struct {
+	set      bool
+	maxEdits int
+	window   time.Duration
+}
+
+// RuntimeParamError - значение параметра вне допустимого диапазона
+type RuntimeParamError struct {
+	Param    string
+	Value    int
+	Min, Max int
+}
+
+func (e *RuntimeParamError) Error() string {
+	return fmt.Sprintf("%s: значение %d вне диапазона [%d, %d]", e.Param, e.Value, e.Min, e.Max)
+}
+
+// Validate проверяет, что все параметры в допустимых диапазонах
+func (p RuntimeParams) Validate() error {
+	for _, r := range runtimeParamRanges {
+		if v := *r.field(&p); v < r.min || v > r.max {
+			return &RuntimeParamError{Param: r.name, Value: v, Min: r.min, Max: r.max}
+		}
+	}
+	return nil
+}
+
+// RuntimeParams возвращает текущие значения настраиваемых параметров
+func (gh *GameHandlerPB) RuntimeParams() RuntimeParams {
+	gh.mu.RLock()
+	params := RuntimeParams{
+		WorldUpdateInterval: gh.worldUpdateInterval,
+		MaxViewDistance:     gh.maxViewDistance,
+		AutoSaveSeconds:     int(gh.autoSaveInterval / time.Second),
+	}
+	engine, limit := gh.anticheat, gh.blockEdits
+	gh.mu.RUnlock()
+
+	maxEdits, window := anticheat.DefaultMaxBlockEdits, anticheat.DefaultBlockEditWindow
+	switch {
+	case limit.set:
+		maxEdits, window = limit.maxEdits, limit.window
+	case engine != nil:
+		maxEdits, window = engine.BlockEditLimit()
+	}
+	params.MaxBlockEdits = maxEdits
+	params.BlockEditWindowMs = int(window / time.Millisecond)
+	return params
+}
+
+// SetRuntimeParams проверяет параметры и применяет их к работающему
+// обработчику. При ошибке валидации ни один параметр не меняется
+func (gh *GameHandlerPB) SetRuntimeParams(params RuntimeParams) error {
+	if err := params.Validate(); err != nil {
+		return err
+	}
+
+	gh.mu.Lock()
+	gh.worldUpdateInterval = params.WorldUpdateInterval
+	gh.maxViewDistance = params.MaxViewDistance
+	gh.autoSaveInterval = time.Duration(params.AutoSaveSeconds) * time.Second
+	gh.blockEdits = blockEditLimit{
+		set:      true,
+		maxEdits: params.MaxBlockEdits,
+		window:   time.Duration(params.BlockEditWindowMs) * time.Millisecond,
+	}
+	gh.applyBlockEditLimitLocked()
+	gh.mu.Unlock()
+	return nil
+}
+
+// applyBlockEditLimitLocked передаёт заданный предел правок блоков движку
+// античита. Вызывается под gh.mu
+func (gh *GameHandlerPB) applyBlockEditLimitLocked() {
+	if gh.anticheat != nil && gh.blockEdits.set {
+		gh.anticheat.SetBlockEditLimit(gh.blockEdits.maxEdits, gh.blockEdits.window)
+	}
+}
+
+// RuntimeOverrides - параметры, изменённые через /api/admin/runtime:
+// JSON-имя параметра -> значение. Сохраняются только они, поэтому остальные
+// параметры при перезапуске берутся из конфигурации
+type RuntimeOverrides map[string]int
+
+// DiffRuntimeParams возвращает параметры, значения которых в after отличаются от before
+func DiffRuntimeParams(before, after RuntimeParams) RuntimeOverrides {
+	changed := RuntimeOverrides{}
+	for _, r := range runtimeParamRanges {
+		if v := *r.field(&after); v != *r.field(&before) {
+			changed[r.name] = v
+		}
+	}
+	return changed
+}
+
+// Apply накладывает переопределения на base
+func (o RuntimeOverrides) Apply(base RuntimeParams) RuntimeParams {
+	for _, r := range runtimeParamRanges {
+		if v, ok := o[r.name]; ok {
+			*r.field(&base) = v
+		}
+	}
+	return base
+}
+
+// Merge добавляет к переопределениям более новые значения other
+func (o RuntimeOverrides) Merge(other RuntimeOverrides) RuntimeOverrides {
+	merged := make(RuntimeOverrides, len(o)+len(other))
+	for name, v := range o {
+		merged[name] = v
+	}
+	for name, v := range other {
+		merged[name] = v
+	}
+	return merged
+}
+
+// validateNames проверяет, что все переопределения - известные параметры
+func (o RuntimeOverrides) validateNames() error {
+	for name := range o {
+		known := false
+		for _, r := range runtimeParamRanges {
+			known = known || r.name == name
+		}
+		if !known {
+			return fmt.Errorf("неизвестный параметр %q", name)
+		}
+	}
+	return nil
+}
+
+// LoadRuntimeOverrides читает сохранённые переопределения параметров.
+// Возвращает nil без ошибки, если файла нет
+func LoadRuntimeOverrides(path string) (RuntimeOverrides, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	overrides := RuntimeOverrides{}
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return nil, fmt.Errorf("разбор %s: %w", path, err)
+	}
+	if err := overrides.validateNames(); err != nil {
+		return nil, fmt.Errorf("разбор %s: %w", path, err)
+	}
+	return overrides, nil
+}
+
+// SaveRuntimeOverrides сохраняет переопределения параметров (запись через
+// временный файл, чтобы сбой не оставил файл наполовину записанным)
+func SaveRuntimeOverrides(path string, overrides RuntimeOverrides) error {
+	data, err := json.MarshalIndent(overrides, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// DecodeRuntimeParams накладывает JSON с частью полей на base; неизвестные
+// поля считаются ошибкой
+func DecodeRuntimeParams(base RuntimeParams, body []byte) (RuntimeParams, error) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&base); err != nil {
+		return RuntimeParams{}, err
+	}
+	return base, nil
+}
@@ -0,0 +1,60 @@
+package network
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/anticheat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuntimeParams_BlockEditLimitWithoutAnticheat(t *testing.T) {
+	gh := newTestGameHandler(t)
+	gh.SetAnticheat(nil)
+
+	params := gh.RuntimeParams()
+	params.MaxBlockEdits = 40
+	params.BlockEditWindowMs = 2500
+	require.NoError(t, gh.SetRuntimeParams(params))
+
+	// Без движка античита значения не теряются
+	assert.Equal(t, params, gh.RuntimeParams())
+
+	// Подключённый позже движок получает заданный предел
+	engine := anticheat.NewEngine(anticheat.Config{})
+	gh.SetAnticheat(engine)
+	maxEdits, window := engine.BlockEditLimit()
+	assert.Equal(t, 40, maxEdits)
+	assert.Equal(t, 2500*time.Millisecond, window)
+}
+
+func TestRuntimeOverrides_OnlyChangedParams(t *testing.T) {
+	base := RuntimeParams{WorldUpdateInterval: 10, MaxViewDistance: 8, AutoSaveSeconds: 60, MaxBlockEdits: 20, BlockEditWindowMs: 1000}
+	changed := base
+	changed.MaxViewDistance = 12
+
+	overrides := DiffRuntimeParams(base, changed)
+	assert.Equal(t, RuntimeOverrides{"max_view_distance": 12}, overrides)
+
+	path := filepath.Join(t.TempDir(), "overrides.json")
+	missing, err := LoadRuntimeOverrides(path)
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+
+	require.NoError(t, SaveRuntimeOverrides(path, overrides))
+	loaded, err := LoadRuntimeOverrides(path)
+	require.NoError(t, err)
+	assert.Equal(t, overrides, loaded)
+
+	// Остальные параметры берутся из новой конфигурации
+	base.AutoSaveSeconds = 120
+	applied := loaded.Apply(base)
+	assert.Equal(t, 12, applied.MaxViewDistance)
+	assert.Equal(t, 120, applied.AutoSaveSeconds)
+
+	require.NoError(t, SaveRuntimeOverrides(path, RuntimeOverrides{"tick_rate": 30}))
+	_, err = LoadRuntimeOverrides(path)
+	assert.Error(t, err, "неизвестные параметры отклоняются")
+}
@@ -0,0 +1,100 @@
+package network
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/protocol"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// DefaultSlowHandlerThreshold - время обработки сообщения, после которого
+	// обработчик считается медленным и об этом пишется в лог
+	DefaultSlowHandlerThreshold = 100 * time.Millisecond
+
+	// slowHandlerLogInterval - не чаще одного предупреждения на тип сообщения за интервал
+	slowHandlerLogInterval = 10 * time.Second
+)
+
+var (
+	messageHandlerDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "network",
+		Name:      "message_handler_duration_seconds",
+		Help:      "Время обработки входящего сообщения по типу.",
+		Buckets:   []float64{0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1},
+	}, []string{"type"})
+
+	slowMessageHandlers = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "network",
+		Name:      "slow_message_handlers_total",
+		Help:      "Сообщения, обработка которых превысила порог медленного обработчика.",
+	}, []string{"type"})
+)
+
+func init() {
+	prometheus.MustRegister(messageHandlerDuration, slowMessageHandlers)
+}
+
+// slowHandlerDetector замечает медленную обработку сообщений. Предупреждения
+// ограничены по частоте отдельно для каждого типа сообщения
+type slowHandlerDetector struct {
+	mu         sync.Mutex
+	threshold  time.Duration // 0 - предупреждения выключены
+	lastWarn   map[protocol.MessageType]time.Time
+	suppressed map[protocol.MessageType]int // Медленные сообщения, не попавшие в лог
+}
+
+// SetSlowHandlerThreshold задаёт порог медленной обработки сообщения:
+// 0 - DefaultSlowHandlerThreshold, отрицательное значение - без предупреждений
+// (гистограмма времени обработки пишется всегда)
+func (gh *GameHandlerPB) SetSlowHandlerThreshold(threshold time.Duration) {
+	if threshold == 0 {
+		threshold = DefaultSlowHandlerThreshold
+	}
+
+	gh.slowHandlers.mu.Lock()
+	gh.slowHandlers.threshold = max(threshold, 0)
+	gh.slowHandlers.mu.Unlock()
+}
+
+// messageTypeLabel - метка типа сообщения; неизвестные типы сводятся в одну,
+// чтобы клиент не мог раздуть число временных рядов
+func messageTypeLabel(msgType protocol.MessageType) string {
+	if _, known := protocol.MessageType_name[int32(msgType)]; !known {
+		return "UNKNOWN"
+	}
+	return msgType.String()
+}
+
+// observeHandler записывает время обработки сообщения, начатой в start, и
+// предупреждает, если обработка заняла больше порога
+func (gh *GameHandlerPB) observeHandler(connID string, msgType protocol.MessageType, start time.Time) {
+	elapsed := time.Since(start)
+	label := messageTypeLabel(msgType)
+	messageHandlerDuration.WithLabelValues(label).Observe(elapsed.Seconds())
+
+	d := &gh.slowHandlers
+	d.mu.Lock()
+	if d.threshold <= 0 || elapsed < d.threshold {
+		d.mu.Unlock()
+		return
+	}
+	slowMessageHandlers.WithLabelValues(label).Inc()
+
+	now := time.Now()
+	if now.Sub(d.lastWarn[msgType]) < slowHandlerLogInterval {
+		d.suppressed[msgType]++
+		d.mu.Unlock()
+		return
+	}
+	suppressed := d.suppressed[msgType]
+	d.lastWarn[msgType] = now
+	delete(d.suppressed, msgType)
+	threshold := d.threshold
+	d.mu.Unlock()
+
+	log.Printf("🐢 Медленная обработка %s от %s: %v (порог %v, ещё %d медленных с прошлого предупреждения)",
+		label, connID, elapsed.Round(time.Microsecond), threshold, suppressed)
+}
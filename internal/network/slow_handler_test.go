@@ -0,0 +1,107 @@
+package network
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/protocol"
+	"github.com/annel0/mmo-game/internal/storage"
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sleepyPositionRepo загружает позицию с задержкой, как перегруженное хранилище
+type sleepyPositionRepo struct {
+	storage.PositionRepo
+	delay time.Duration
+}
+
+func (r sleepyPositionRepo) Load(ctx context.Context, userID uint64) (vec.Vec3, bool, error) {
+	select {
+	case <-time.After(r.delay):
+	case <-ctx.Done():
+	}
+	return r.PositionRepo.Load(ctx, userID)
+}
+
+// syncBuffer - буфер лога, безопасный для записи из нескольких горутин
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// captureLog перенаправляет стандартный лог в буфер до конца теста
+func captureLog(t *testing.T) *syncBuffer {
+	t.Helper()
+	buf := &syncBuffer{}
+	log.SetOutput(buf)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+	return buf
+}
+
+func handlerSamples(t *testing.T, label string) uint64 {
+	t.Helper()
+	m := &dto.Metric{}
+	require.NoError(t, messageHandlerDuration.WithLabelValues(label).(prometheus.Histogram).Write(m))
+	return m.GetHistogram().GetSampleCount()
+}
+
+func TestSlowHandler_WarnsAndRecordsLatency(t *testing.T) {
+	gh := newTestGameHandler(t)
+	gh.SetPositionRepo(sleepyPositionRepo{PositionRepo: storage.NewMemoryPositionRepo(), delay: 30 * time.Millisecond})
+	gh.SetSlowHandlerThreshold(10 * time.Millisecond)
+	client := connectTestClient(t, gh, "conn-1")
+	logs := captureLog(t)
+
+	samples := handlerSamples(t, "AUTH")
+	slow := testutil.ToFloat64(slowMessageHandlers.WithLabelValues("AUTH"))
+
+	authTestClient(t, gh, client)
+
+	assert.Equal(t, samples+1, handlerSamples(t, "AUTH"), "время обработки записано в гистограмму")
+	assert.Equal(t, slow+1, testutil.ToFloat64(slowMessageHandlers.WithLabelValues("AUTH")))
+	assert.Contains(t, logs.String(), "Медленная обработка AUTH от conn-1")
+
+	// Повторное предупреждение того же типа в пределах интервала не пишется, но учитывается
+	gh.observeHandler("conn-2", protocol.MessageType_AUTH, time.Now().Add(-time.Second))
+	assert.NotContains(t, logs.String(), "Медленная обработка AUTH от conn-2")
+	assert.Equal(t, slow+2, testutil.ToFloat64(slowMessageHandlers.WithLabelValues("AUTH")))
+	assert.Equal(t, 1, strings.Count(logs.String(), "Медленная обработка AUTH"))
+}
+
+func TestSlowHandler_FastAndUnknownMessages(t *testing.T) {
+	gh := newTestGameHandler(t)
+	logs := captureLog(t)
+
+	samples := handlerSamples(t, "UNKNOWN")
+	gh.HandleMessage("conn-1", &protocol.GameMessage{Type: protocol.MessageType(9999)})
+	assert.Equal(t, samples+1, handlerSamples(t, "UNKNOWN"), "неизвестные типы сводятся в одну метку")
+	assert.NotContains(t, logs.String(), "Медленная обработка")
+
+	// Отрицательный порог выключает предупреждения
+	gh.SetSlowHandlerThreshold(-1)
+	gh.observeHandler("conn-1", protocol.MessageType_CHAT, time.Now().Add(-time.Second))
+	assert.NotContains(t, logs.String(), "Медленная обработка")
+}
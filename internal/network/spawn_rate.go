@@ -0,0 +1,20 @@
+package network
+
+import (
+	"github.com/annel0/mmo-game/internal/protocol"
+)
+
+// SetWorldSpawnRateLimit задаёт частоту мировых спавнов и их начальный запас
+// (см. entity.EntityManager.SetWorldSpawnRateLimit). Ограничение действует во
+// всех точках спавна: менеджера сущностей, BigChunk и обработчика; спавн
+// игроков не ограничивается. 0 - значения по умолчанию, отрицательная
+// частота - без ограничения
+func (gh *GameHandlerPB) SetWorldSpawnRateLimit(rate, burst int) {
+	gh.entityManager.SetWorldSpawnRateLimit(rate, burst)
+}
+
+// broadcastDespawn откладывает оповещение всех игроков об удалении сущности.
+// Само удаление учитывается менеджером сущностей (entity_despawns_total)
+func (o *outbox) broadcastDespawn(entityID uint64, reason protocol.DespawnReason) {
+	o.broadcast(protocol.MessageType_ENTITY_DESPAWN, protocol.NewEntityDespawnMessage(entityID, reason))
+}
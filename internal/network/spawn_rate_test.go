@@ -0,0 +1,109 @@
+package network
+
+import (
+	"testing"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/protocol"
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/annel0/mmo-game/internal/world"
+	"github.com/annel0/mmo-game/internal/world/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorldSpawnRate_RejectedSpawnsAreNotBroadcast(t *testing.T) {
+	gh := newTestGameHandler(t)
+	gh.SetWorldSpawnRateLimit(1, 5)
+	client := connectTestClient(t, gh, "conn-1")
+
+	// Всплеск мировых спавнов упирается в начальный запас
+	spawned := 0
+	for i := 0; i < 20; i++ {
+		if gh.SpawnEntity(entity.EntityTypeAnimal, vec.Vec2{X: i}) != 0 {
+			spawned++
+		}
+	}
+	assert.Equal(t, 5, spawned)
+	assert.Equal(t, 5, client.drain(protocol.MessageType_ENTITY_SPAWN), "отклонённые спавны не рассылаются")
+
+	// Игроки появляются, даже когда запас исчерпан
+	for i := 0; i < 3; i++ {
+		require.NotZero(t, gh.SpawnEntity(entity.EntityTypePlayer, vec.Vec2{Y: i}))
+	}
+	assert.Equal(t, 3, client.drain(protocol.MessageType_ENTITY_SPAWN))
+
+	// Тот же запас расходуют спавны BigChunk
+	assert.Zero(t, gh.worldManager.SpawnEntity(uint16(world.EntityTypeAnimal), vec.Vec2{X: 100}, nil))
+}
+
+func TestWorldSpawnRate_NegativeRateDisablesLimit(t *testing.T) {
+	gh := newTestGameHandler(t)
+	gh.SetWorldSpawnRateLimit(-1, 0)
+
+	for i := 0; i < 2*entity.DefaultWorldSpawnBurst; i++ {
+		require.NotZero(t, gh.SpawnEntity(entity.EntityTypeItem, vec.Vec2{X: i}))
+	}
+}
+
+func TestDisconnect_DespawnsPlayerAndOwnedEntities(t *testing.T) {
+	gh := newTestGameHandler(t)
+	connectTestClient(t, gh, "conn-1")
+	addTestSession(gh, "conn-1", 1, 1, vec.Vec2{})
+	actor := playerEntityFor(t, gh, "conn-1")
+	item := gh.dropItem(7, 1, vec.Vec2{X: 1})
+	require.NotZero(t, item)
+	require.True(t, gh.ClaimEntity(item, actor.ID, entity.OwnerDespawn))
+
+	gh.OnClientDisconnect("conn-1")
+	_, playerLeft := gh.entityManager.GetEntity(actor.ID)
+	_, itemLeft := gh.entityManager.GetEntity(item)
+	assert.False(t, playerLeft, "сущность игрока удаляется из менеджера")
+	assert.False(t, itemLeft)
+}
+
+func TestWorldSpawnRate_RejectedDropKeepsNoItem(t *testing.T) {
+	gh := newTestGameHandler(t)
+	now := time.Now()
+	gh.now = func() time.Time { return now }
+	gh.SetWorldSpawnRateLimit(1, 1)
+
+	require.NotZero(t, gh.dropItem(7, 1, vec.Vec2{X: 1}))
+	assert.Zero(t, gh.dropItem(7, 1, vec.Vec2{X: 2}))
+
+	gh.mu.RLock()
+	defer gh.mu.RUnlock()
+	assert.Len(t, gh.droppedItems, 1)
+}
+
+func TestEntityCap_SpawnEvictsOldestDroppedItem(t *testing.T) {
+	gh := newTestGameHandler(t)
+	gh.worldManager.SetEntityCaps(2, -1)
+	client := connectTestClient(t, gh, "conn-1")
+
+	oldest := gh.dropItem(7, 1, vec.Vec2{X: 1})
+	newer := gh.dropItem(7, 1, vec.Vec2{X: 2})
+	require.NotZero(t, oldest)
+	require.NotZero(t, newer)
+	client.drain(protocol.MessageType_ENTITY_SPAWN)
+
+	// Предел BigChunk достигнут: новый предмет вытесняет самый старый
+	latest := gh.dropItem(8, 1, vec.Vec2{X: 3})
+	require.NotZero(t, latest)
+	_, exists := gh.entityManager.GetEntity(oldest)
+	assert.False(t, exists)
+	despawn := &protocol.EntityDespawnMessage{}
+	client.expect(t, protocol.MessageType_ENTITY_DESPAWN, despawn)
+	assert.Equal(t, oldest, despawn.EntityId)
+
+	gh.mu.RLock()
+	assert.NotContains(t, gh.droppedItems, oldest)
+	assert.Len(t, gh.droppedItems, 2)
+	gh.mu.RUnlock()
+
+	// NPC вытеснять нельзя: без предметов появление отклоняется
+	gh.worldManager.SetEntityCaps(1, -1)
+	npc := gh.SpawnEntity(entity.EntityTypeNPC, vec.Vec2{X: 4})
+	assert.NotZero(t, npc, "место освобождено за счёт предметов")
+	assert.Zero(t, gh.SpawnEntity(entity.EntityTypeNPC, vec.Vec2{X: 5}))
+}
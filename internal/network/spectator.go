@@ -0,0 +1,69 @@
+package network
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/annel0/mmo-game/internal/protocol"
+	"github.com/annel0/mmo-game/internal/world/entity"
+)
+
+// SetSpectator включает режим наблюдателя администратора: перемещения не
+// проверяются на проходимость и скорость, а сама сущность скрыта от обычных
+// игроков так же, как в режиме невидимости. При выходе античит начинает
+// отсчёт с текущей позиции, чтобы перелёт не засчитался нарушением
+func (gh *GameHandlerPB) SetSpectator(connID string, enabled bool) error {
+	gh.mu.Lock()
+	session, exists := gh.sessions[connID]
+	if !exists {
+		gh.mu.Unlock()
+		return fmt.Errorf("сессия %s не найдена", connID)
+	}
+	if enabled && !session.IsAdmin {
+		gh.mu.Unlock()
+		return fmt.Errorf("пользователь %s не администратор", session.Username)
+	}
+	changed := session.Spectator != enabled
+	session.Spectator = enabled
+	entityID, username := session.EntityID, session.Username
+	wasHidden := session.Stealth
+	if changed && !enabled {
+		if e, ok := gh.entityManager.GetEntity(entityID); ok {
+			gh.resetAnticheatLocked(entityID, e.Position)
+		}
+	}
+	gh.mu.Unlock()
+
+	if !changed {
+		return nil
+	}
+	log.Printf("👁️ Режим наблюдателя для %s: %v", username, enabled)
+	if enabled && !wasHidden {
+		gh.despawnForRegularViewers(entityID)
+	}
+	return nil
+}
+
+// isSpectator сообщает, находится ли соединение в режиме наблюдателя
+func (gh *GameHandlerPB) isSpectator(connID string) bool {
+	gh.mu.RLock()
+	defer gh.mu.RUnlock()
+	session, ok := gh.sessions[connID]
+	return ok && session.Spectator
+}
+
+// handleSpectatorAction переключает режим наблюдателя по запросу клиента-администратора
+func (gh *GameHandlerPB) handleSpectatorAction(actor *entity.Entity, _ *protocol.EntityActionRequest) (bool, string, bool) {
+	connID, ok := gh.connForEntity(actor.ID)
+	if !ok {
+		return false, "Сессия не найдена", false
+	}
+	enabled := !gh.isSpectator(connID)
+	if err := gh.SetSpectator(connID, enabled); err != nil {
+		return false, "Режим наблюдателя доступен только администраторам", false
+	}
+	if enabled {
+		return true, "Режим наблюдателя включён", false
+	}
+	return true, "Режим наблюдателя выключен", false
+}
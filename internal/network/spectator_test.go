@@ -0,0 +1,103 @@
+package network
+
+import (
+	"testing"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/anticheat"
+	"github.com/annel0/mmo-game/internal/protocol"
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/annel0/mmo-game/internal/world"
+	"github.com/annel0/mmo-game/internal/world/block"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// moveTo отправляет перемещение собственной сущности соединения
+func moveTo(t *testing.T, gh *GameHandlerPB, connID string, entityID uint64, pos vec.Vec2) {
+	t.Helper()
+	gh.HandleMessage(connID, newGameMessage(t, protocol.MessageType_ENTITY_MOVE, &protocol.EntityMoveMessage{
+		Entities: []*protocol.EntityData{{Id: entityID, Position: &protocol.Vec2{X: int32(pos.X), Y: int32(pos.Y)}}},
+	}))
+}
+
+func TestSpectator_BypassesCollisionAndSpeed(t *testing.T) {
+	gh := newTestGameHandler(t)
+	connectTestClient(t, gh, "conn-admin")
+	addTestSession(gh, "conn-admin", 1, 1, vec.Vec2{X: 0, Y: 0}).IsAdmin = true
+	observer := connectTestClient(t, gh, "conn-player")
+	addTestSession(gh, "conn-player", 2, 2, vec.Vec2{X: 0, Y: 2})
+
+	now := time.Now()
+	gh.now = func() time.Time { return now }
+	engine := anticheat.NewEngine(anticheat.Config{MaxSpeed: 10, MaxReach: 5})
+	var violations []anticheat.Violation
+	engine.SetReporter(func(v anticheat.Violation) { violations = append(violations, v) })
+	engine.ResetPosition(1, vec.Vec2Float{})
+	engine.ResetPosition(2, vec.Vec2Float{Y: 2})
+	gh.SetAnticheat(engine)
+
+	wall := vec.Vec2{X: 1, Y: 0}
+	gh.worldManager.SetBlockLayer(wall, world.LayerActive, world.NewBlock(block.StoneBlockID))
+	require.False(t, gh.isPositionWalkable(wall))
+
+	// Обычный игрок не может войти в стену
+	moveTo(t, gh, "conn-player", 2, wall)
+	player := playerEntityFor(t, gh, "conn-player")
+	assert.Equal(t, vec.Vec2{X: 0, Y: 2}, player.Position)
+
+	// Обычный игрок не может стать наблюдателем
+	assert.Error(t, gh.SetSpectator("conn-player", true))
+
+	require.NoError(t, gh.SetSpectator("conn-admin", true))
+	observer.drain(protocol.MessageType_ENTITY_MOVE)
+
+	// Наблюдатель проходит сквозь стену и перемещается быстрее лимита
+	now = now.Add(100 * time.Millisecond)
+	moveTo(t, gh, "conn-admin", 1, wall)
+	assert.Equal(t, wall, playerEntityFor(t, gh, "conn-admin").Position)
+	far := vec.Vec2{X: 40, Y: 0}
+	now = now.Add(100 * time.Millisecond)
+	moveTo(t, gh, "conn-admin", 1, far)
+	assert.Equal(t, far, playerEntityFor(t, gh, "conn-admin").Position)
+	assert.Empty(t, violations, "перемещения наблюдателя не проверяются античитом")
+
+	// Позиция наблюдателя не уходит обычным игрокам
+	assert.Zero(t, observer.drain(protocol.MessageType_ENTITY_MOVE))
+
+	// После выхода античит считает от текущей позиции, а коллизии снова действуют
+	require.NoError(t, gh.SetSpectator("conn-admin", false))
+	now = now.Add(time.Second)
+	moveTo(t, gh, "conn-admin", 1, vec.Vec2{X: 41, Y: 0})
+	assert.Empty(t, violations)
+	moveTo(t, gh, "conn-admin", 1, wall)
+	assert.Equal(t, vec.Vec2{X: 41, Y: 0}, playerEntityFor(t, gh, "conn-admin").Position)
+}
+
+func TestSpectator_ToggleAction(t *testing.T) {
+	gh := newTestGameHandler(t)
+	admin := connectTestClient(t, gh, "conn-admin")
+	addTestSession(gh, "conn-admin", 1, 1, vec.Vec2{}).IsAdmin = true
+	player := connectTestClient(t, gh, "conn-player")
+	addTestSession(gh, "conn-player", 2, 2, vec.Vec2{})
+
+	toggle := newGameMessage(t, protocol.MessageType_ENTITY_ACTION, &protocol.EntityActionRequest{
+		ActionType: protocol.EntityActionType_ACTION_SPECTATOR,
+	})
+
+	resp := &protocol.EntityActionResponse{}
+	gh.HandleMessage("conn-admin", toggle)
+	admin.expect(t, protocol.MessageType_ENTITY_ACTION_RESPONSE, resp)
+	assert.True(t, resp.Success)
+	assert.True(t, gh.isSpectator("conn-admin"))
+
+	gh.HandleMessage("conn-admin", toggle)
+	admin.expect(t, protocol.MessageType_ENTITY_ACTION_RESPONSE, resp)
+	assert.True(t, resp.Success)
+	assert.False(t, gh.isSpectator("conn-admin"))
+
+	gh.HandleMessage("conn-player", toggle)
+	player.expect(t, protocol.MessageType_ENTITY_ACTION_RESPONSE, resp)
+	assert.False(t, resp.Success)
+	assert.False(t, gh.isSpectator("conn-player"))
+}
@@ -0,0 +1,272 @@
+package network
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/annel0/mmo-game/internal/protocol"
+	"github.com/annel0/mmo-game/internal/world/entity"
+)
+
+// Статус-эффекты сущностей. Срабатывают раз в секунду действия эффекта
+const (
+	EffectPoison       = "poison"       // Урон: сила ед. здоровья в секунду (не убивает)
+	EffectRegeneration = "regeneration" // Лечение: сила ед. здоровья в секунду
+	EffectHaste        = "haste"        // Скорость: +hasteSpeedPerLevel за единицу силы
+
+	hasteSpeedPerLevel = 0.2
+)
+
+// Ключи Payload, связанные с эффектами (длительности - payloadStatusEffects)
+const (
+	payloadEffectIntensity = "effect_intensity" // map[string]float64: эффект -> сила
+	payloadSpeedMultiplier = "speed_multiplier"
+	payloadHealth          = "health"
+	payloadMaxHealth       = "max_health"
+)
+
+// knownEffects - эффекты, которые можно наложить
+var knownEffects = map[string]bool{
+	EffectPoison:       true,
+	EffectRegeneration: true,
+	EffectHaste:        true,
+}
+
+// ApplyStatusEffect накладывает эффект на сущность. Повторное наложение
+// заменяет силу и длительность. Игрок получает обновлённые PLAYER_STATS
+func (gh *GameHandlerPB) ApplyStatusEffect(entityID uint64, effect string, intensity, seconds float64) error {
+	if !knownEffects[effect] {
+		return fmt.Errorf("неизвестный эффект %q", effect)
+	}
+	if intensity <= 0 || seconds <= 0 {
+		return fmt.Errorf("эффект %s: сила и длительность должны быть положительными", effect)
+	}
+
+	e, exists := gh.entityManager.GetEntity(entityID)
+	if !exists {
+		return fmt.Errorf("сущность %d не найдена", entityID)
+	}
+
+	gh.effectsMu.Lock()
+	remaining, strength := effectMaps(e)
+	remaining[effect] = seconds
+	strength[effect] = intensity
+	updateSpeedMultiplier(e, strength)
+	gh.affected[entityID] = struct{}{}
+	gh.effectsMu.Unlock()
+
+	gh.sendPlayerStats(entityID)
+	return nil
+}
+
+// StatusEffects возвращает активные эффекты сущности, отсортированные по имени
+func (gh *GameHandlerPB) StatusEffects(entityID uint64) []*protocol.StatusEffect {
+	e, exists := gh.entityManager.GetEntity(entityID)
+	if !exists {
+		return nil
+	}
+
+	gh.effectsMu.Lock()
+	defer gh.effectsMu.Unlock()
+	return statusEffectsOf(e)
+}
+
+// tickStatusEffects уменьшает длительность эффектов на dt секунд, применяет
+// урон и лечение и снимает истёкшие эффекты
+func (gh *GameHandlerPB) tickStatusEffects(dt float64) {
+	var changed []uint64
+
+	gh.effectsMu.Lock()
+	for entityID := range gh.affected {
+		e, exists := gh.entityManager.GetEntity(entityID)
+		if !exists {
+			delete(gh.affected, entityID)
+			continue
+		}
+		if tickEntityEffects(e, dt) {
+			changed = append(changed, entityID)
+		}
+		if remaining, _ := e.Payload[payloadStatusEffects].(map[string]float64); len(remaining) == 0 {
+			delete(gh.affected, entityID)
+		}
+	}
+	gh.effectsMu.Unlock()
+
+	for _, entityID := range changed {
+		gh.sendPlayerStats(entityID)
+	}
+}
+
+// trackStatusEffects подключает эффекты, восстановленные в Payload, к ежетиковой обработке
+func (gh *GameHandlerPB) trackStatusEffects(e *entity.Entity) {
+	gh.effectsMu.Lock()
+	defer gh.effectsMu.Unlock()
+
+	remaining, strength := effectMaps(e)
+	if len(remaining) == 0 {
+		return
+	}
+	updateSpeedMultiplier(e, strength)
+	gh.affected[e.ID] = struct{}{}
+}
+
+// tickEntityEffects обрабатывает эффекты одной сущности. Возвращает true,
+// если изменилось здоровье или набор эффектов. Вызывается под effectsMu
+func tickEntityEffects(e *entity.Entity, dt float64) bool {
+	remaining, strength := effectMaps(e)
+	changed := false
+
+	for effect, before := range remaining {
+		after := before - dt
+
+		// Эффект срабатывает каждый раз, когда остаток пересекает целую секунду
+		if pulses := int(math.Ceil(before)) - int(math.Ceil(math.Max(after, 0))); pulses > 0 {
+			amount := pulses * max(int(math.Round(strength[effect])), 1)
+			switch effect {
+			case EffectPoison:
+				changed = changeHealth(e, -amount) || changed
+			case EffectRegeneration:
+				changed = changeHealth(e, amount) || changed
+			}
+		}
+
+		if after <= 0 {
+			delete(remaining, effect)
+			delete(strength, effect)
+			changed = true
+			continue
+		}
+		remaining[effect] = after
+	}
+
+	updateSpeedMultiplier(e, strength)
+	return changed
+}
+
+// changeHealth меняет здоровье в пределах [1, max_health]. Эффекты не убивают
+func changeHealth(e *entity.Entity, delta int) bool {
+	health, ok := payloadInt(e.Payload[payloadHealth])
+	if !ok {
+		return false
+	}
+	updated := max(health+delta, 1)
+	if maxHealth, ok := payloadInt(e.Payload[payloadMaxHealth]); ok && maxHealth > 0 {
+		updated = min(updated, maxHealth)
+	}
+	if updated == health {
+		return false
+	}
+	e.Payload[payloadHealth] = updated
+	return true
+}
+
+// effectMaps возвращает карты длительностей и силы эффектов, приводя
+// значения после JSON к map[string]float64
+func effectMaps(e *entity.Entity) (remaining, strength map[string]float64) {
+	if e.Payload == nil {
+		e.Payload = make(map[string]interface{})
+	}
+	remaining = floatMap(e.Payload[payloadStatusEffects])
+	strength = floatMap(e.Payload[payloadEffectIntensity])
+	e.Payload[payloadStatusEffects] = remaining
+	e.Payload[payloadEffectIntensity] = strength
+	return remaining, strength
+}
+
+func floatMap(value interface{}) map[string]float64 {
+	switch m := value.(type) {
+	case map[string]float64:
+		if m != nil {
+			return m
+		}
+		return make(map[string]float64)
+	case map[string]interface{}:
+		converted := make(map[string]float64, len(m))
+		for key, v := range m {
+			if f, ok := v.(float64); ok {
+				converted[key] = f
+			}
+		}
+		return converted
+	default:
+		return make(map[string]float64)
+	}
+}
+
+// updateSpeedMultiplier пересчитывает множитель скорости от haste
+func updateSpeedMultiplier(e *entity.Entity, strength map[string]float64) {
+	if intensity, ok := strength[EffectHaste]; ok {
+		e.Payload[payloadSpeedMultiplier] = 1 + hasteSpeedPerLevel*intensity
+		return
+	}
+	delete(e.Payload, payloadSpeedMultiplier)
+}
+
+// statusEffectsOf собирает эффекты сущности для протокола. Вызывается под effectsMu
+func statusEffectsOf(e *entity.Entity) []*protocol.StatusEffect {
+	remaining := floatMap(e.Payload[payloadStatusEffects])
+	strength := floatMap(e.Payload[payloadEffectIntensity])
+
+	effects := make([]*protocol.StatusEffect, 0, len(remaining))
+	for effect, seconds := range remaining {
+		intensity, ok := strength[effect]
+		if !ok {
+			intensity = 1
+		}
+		effects = append(effects, &protocol.StatusEffect{
+			Type:             effect,
+			Intensity:        float32(intensity),
+			RemainingSeconds: float32(seconds),
+		})
+	}
+	sort.Slice(effects, func(i, j int) bool { return effects[i].Type < effects[j].Type })
+	return effects
+}
+
+// sendPlayerStats отправляет игроку его характеристики и активные эффекты.
+// Для сущностей, не принадлежащих игрокам, ничего не делает
+func (gh *GameHandlerPB) sendPlayerStats(entityID uint64) {
+	connID, ok := gh.connForEntity(entityID)
+	if !ok {
+		return
+	}
+	e, exists := gh.entityManager.GetEntity(entityID)
+	if !exists {
+		return
+	}
+
+	gh.effectsMu.Lock()
+	stats := &protocol.PlayerStatsMessage{
+		EntityId:        entityID,
+		SpeedMultiplier: float32(e.SpeedMultiplier()),
+		Effects:         statusEffectsOf(e),
+	}
+	if health, ok := payloadInt(e.Payload[payloadHealth]); ok {
+		stats.Health = int32(health)
+	}
+	if maxHealth, ok := payloadInt(e.Payload[payloadMaxHealth]); ok {
+		stats.MaxHealth = int32(maxHealth)
+	}
+	if level, ok := payloadInt(e.Payload[payloadLevel]); ok {
+		stats.Level = int32(level)
+	}
+	if experience, ok := payloadInt(e.Payload[payloadExperience]); ok {
+		stats.Experience = int32(experience)
+	}
+	gh.effectsMu.Unlock()
+
+	gh.sendTCPMessage(connID, protocol.MessageType_PLAYER_STATS, stats)
+}
+
+// connForEntity находит соединение игрока по ID его сущности
+func (gh *GameHandlerPB) connForEntity(entityID uint64) (string, bool) {
+	gh.mu.RLock()
+	defer gh.mu.RUnlock()
+	for connID, id := range gh.playerEntities {
+		if id == entityID {
+			return connID, true
+		}
+	}
+	return "", false
+}
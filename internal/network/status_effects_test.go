@@ -0,0 +1,149 @@
+package network
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/anticheat"
+	"github.com/annel0/mmo-game/internal/protocol"
+	"github.com/annel0/mmo-game/internal/storage"
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/annel0/mmo-game/internal/world/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatusEffects_RegenerationHealsAndExpires(t *testing.T) {
+	gh := newTestGameHandler(t)
+	gh.entityManager.RegisterDefaultBehaviors()
+
+	client := connectTestClient(t, gh, "conn-1")
+	authTestClient(t, gh, client)
+	player := playerEntityFor(t, gh, "conn-1")
+	player.Payload[payloadHealth] = 50
+	player.Payload[payloadMaxHealth] = 100
+
+	require.NoError(t, gh.ApplyStatusEffect(player.ID, EffectRegeneration, 5, 3))
+	require.Error(t, gh.ApplyStatusEffect(player.ID, "levitation", 1, 3))
+
+	stats := &protocol.PlayerStatsMessage{}
+	client.expect(t, protocol.MessageType_PLAYER_STATS, stats)
+	require.Len(t, stats.Effects, 1)
+	assert.Equal(t, EffectRegeneration, stats.Effects[0].Type)
+	assert.EqualValues(t, 5, stats.Effects[0].Intensity)
+
+	// 2 секунды по 4 тика: два срабатывания по 5 ед.
+	for i := 0; i < 8; i++ {
+		gh.tickStatusEffects(0.25)
+	}
+	assert.Equal(t, 60, player.Payload[payloadHealth])
+	assert.Len(t, gh.StatusEffects(player.ID), 1)
+
+	// Третье срабатывание и истечение; лечение не превышает max_health
+	player.Payload[payloadHealth] = 98
+	for i := 0; i < 4; i++ {
+		gh.tickStatusEffects(0.25)
+	}
+	assert.Equal(t, 100, player.Payload[payloadHealth])
+	assert.Empty(t, gh.StatusEffects(player.ID))
+	assert.Empty(t, gh.affected, "сущность без эффектов не обрабатывается")
+}
+
+func TestStatusEffects_PoisonAndHaste(t *testing.T) {
+	gh := newTestGameHandler(t)
+	gh.entityManager.RegisterDefaultBehaviors()
+
+	client := connectTestClient(t, gh, "conn-1")
+	authTestClient(t, gh, client)
+	player := playerEntityFor(t, gh, "conn-1")
+	player.Payload[payloadHealth] = 3
+
+	require.NoError(t, gh.ApplyStatusEffect(player.ID, EffectPoison, 2, 5))
+	require.NoError(t, gh.ApplyStatusEffect(player.ID, EffectHaste, 2, 1))
+	assert.InDelta(t, 1.4, player.SpeedMultiplier(), 1e-9)
+
+	gh.tickStatusEffects(1)
+	assert.Equal(t, 1, player.Payload[payloadHealth])
+	assert.Equal(t, 1.0, player.SpeedMultiplier(), "скорость возвращается после окончания haste")
+
+	gh.tickStatusEffects(1)
+	assert.Equal(t, 1, player.Payload[payloadHealth], "яд не убивает")
+}
+
+func TestStatusEffects_IntensitySurvivesReconnect(t *testing.T) {
+	gh := newTestGameHandler(t)
+	gh.entityManager.RegisterDefaultBehaviors()
+	states := storage.NewMemoryPlayerStateRepo()
+	gh.SetPlayerStateRepo(states)
+
+	first := connectTestClient(t, gh, "conn-1")
+	authTestClient(t, gh, first)
+	player := playerEntityFor(t, gh, "conn-1")
+	require.NoError(t, gh.ApplyStatusEffect(player.ID, EffectHaste, 3, 60))
+
+	gh.OnClientDisconnect("conn-1")
+
+	saved, found, err := states.Load(context.Background(), 1)
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.Equal(t, map[string]float64{EffectHaste: 3}, saved.EffectIntensity)
+
+	second := connectTestClient(t, gh, "conn-2")
+	authTestClient(t, gh, second)
+	restored := playerEntityFor(t, gh, "conn-2")
+
+	effects := gh.StatusEffects(restored.ID)
+	require.Len(t, effects, 1)
+	assert.EqualValues(t, 3, effects[0].Intensity)
+	assert.InDelta(t, 1.6, restored.SpeedMultiplier(), 1e-6)
+
+	gh.tickStatusEffects(1)
+	assert.InDelta(t, 59, gh.StatusEffects(restored.ID)[0].RemainingSeconds, 1e-6, "восстановленный эффект продолжает тикать")
+}
+
+func TestStatusEffects_HasteSpeedsUpMovement(t *testing.T) {
+	gh := newTestGameHandler(t)
+	gh.entityManager.RegisterDefaultBehaviors()
+	paveTestArea(gh, vec.Vec2{X: -2, Y: -2}, vec.Vec2{X: 20, Y: 12})
+
+	plain := gh.SpawnEntity(entity.EntityTypeAnimal, vec.Vec2{X: 0, Y: 0})
+	hasted := gh.SpawnEntity(entity.EntityTypeAnimal, vec.Vec2{X: 0, Y: 10})
+	require.NoError(t, gh.ApplyStatusEffect(hasted, EffectHaste, 2, 60))
+
+	step := func(id uint64) float64 {
+		e, ok := gh.entityManager.GetEntity(id)
+		require.True(t, ok)
+		from := e.PrecisePos.X
+		require.True(t, gh.MoveEntity(e, entity.MovementDirection{Right: true}, 0.1))
+		return e.PrecisePos.X - from
+	}
+	assert.InDelta(t, 1.4*step(plain), step(hasted), 1e-9)
+}
+
+func TestStatusEffects_HasteRaisesAnticheatSpeedLimit(t *testing.T) {
+	gh := newTestGameHandler(t)
+	connectTestClient(t, gh, "conn-1")
+	addTestSession(gh, "conn-1", 1, 1, vec.Vec2{})
+	paveTestArea(gh, vec.Vec2{}, vec.Vec2{X: 30})
+
+	now := time.Now()
+	gh.now = func() time.Time { return now }
+	engine := anticheat.NewEngine(anticheat.Config{MaxSpeed: 10})
+	var violations []anticheat.Violation
+	engine.SetReporter(func(v anticheat.Violation) { violations = append(violations, v) })
+	engine.ResetPosition(1, vec.Vec2Float{})
+	gh.SetAnticheat(engine)
+
+	// 13 блоков/с: с haste 2 (x1.4) - в пределе, после его окончания - нарушение
+	require.NoError(t, gh.ApplyStatusEffect(1, EffectHaste, 2, 1))
+	now = now.Add(time.Second)
+	moveTo(t, gh, "conn-1", 1, vec.Vec2{X: 13})
+	assert.Empty(t, violations)
+
+	gh.tickStatusEffects(1)
+	now = now.Add(time.Second)
+	moveTo(t, gh, "conn-1", 1, vec.Vec2{X: 26})
+	require.Len(t, violations, 1)
+	assert.Equal(t, anticheat.RuleSpeed, violations[0].Rule)
+}
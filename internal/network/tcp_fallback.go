@@ -0,0 +1,44 @@
+package network
+
+import "fmt"
+
+// EnableTCPFallback поднимает рядом с KCP обычный TCP-сервер на addr. Клиент,
+// у которого KCP/UDP блокируется сетью, переподключается по TCP; соединения
+// обоих транспортов обслуживает один GameHandlerPB. Подключения по
+// транспортам - в network_connections_current{transport} и
+// network_connections_accepted_total{transport}; лимит подключений
+// (SetMaxConnections) у транспортов общий. Вызывается до Start
+func (kgs *KCPGameServer) EnableTCPFallback(addr string) error {
+	if kgs.tcpServer != nil {
+		return fmt.Errorf("TCP fallback уже включён на %s", kgs.tcpServer.listener.Addr())
+	}
+
+	server, err := NewTCPServerPB(addr, kgs.worldManager)
+	if err != nil {
+		return fmt.Errorf("failed to create TCP fallback server: %w", err)
+	}
+	server.limits.shared = kgs.connLimit
+	server.SetGameHandler(kgs.gameHandler)
+	server.SetJSONCodecAllowed(kgs.allowJSON)
+	kgs.gameHandler.SetTCPServer(server)
+	kgs.tcpServer = server
+	return nil
+}
+
+// TCPFallbackAddr возвращает адрес TCP fallback ("" - выключен)
+func (kgs *KCPGameServer) TCPFallbackAddr() string {
+	if kgs.tcpServer == nil {
+		return ""
+	}
+	return kgs.tcpServer.listener.Addr().String()
+}
+
+// SetJSONCodecAllowed разрешает отладочным клиентам кодек JSON на TCP
+// fallback (KCP всегда использует Protocol Buffers). Можно вызывать до и
+// после EnableTCPFallback
+func (kgs *KCPGameServer) SetJSONCodecAllowed(allowed bool) {
+	kgs.allowJSON = allowed
+	if kgs.tcpServer != nil {
+		kgs.tcpServer.SetJSONCodecAllowed(allowed)
+	}
+}
@@ -0,0 +1,103 @@
+package network
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestKCPGameServer_TCPFallbackAuthAndChunks(t *testing.T) {
+	kgs, err := NewKCPGameServer("127.0.0.1:0", "127.0.0.1:0")
+	require.NoError(t, err)
+	require.Empty(t, kgs.TCPFallbackAddr())
+	require.NoError(t, kgs.EnableTCPFallback("127.0.0.1:0"))
+	assert.Error(t, kgs.EnableTCPFallback("127.0.0.1:0"), "TCP fallback включается один раз")
+	require.NoError(t, kgs.Start())
+	t.Cleanup(kgs.Stop)
+
+	// Клиент, у которого не установился KCP, подключается по TCP
+	conn, err := net.Dial("tcp", kgs.TCPFallbackAddr())
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	password := "ChangeMe123!"
+	data, err := kgs.tcpServer.serializer.SerializeMessage(protocol.MessageType_AUTH, &protocol.AuthMessage{
+		Username:        "admin",
+		Password:        &password,
+		ProtocolVersion: ProtocolVersion,
+	})
+	require.NoError(t, err)
+	frame := binary.BigEndian.AppendUint32(nil, uint32(len(data)))
+	_, err = conn.Write(append(frame, data...))
+	require.NoError(t, err)
+
+	// Ответ на авторизацию и чанки приходят по TCP от общего GameHandlerPB
+	authorized, chunks := false, 0
+	for chunks == 0 {
+		msg, err := readFrame(t, conn, kgs.tcpServer.serializer)
+		require.NoError(t, err)
+		switch msg.Type {
+		case protocol.MessageType_AUTH_RESPONSE:
+			resp := &protocol.AuthResponseMessage{}
+			require.NoError(t, proto.Unmarshal(msg.Payload, resp))
+			require.True(t, resp.Success, resp.Message)
+			authorized = true
+		case protocol.MessageType_CHUNK_DATA:
+			require.True(t, authorized, "чанки отправляются после авторизации")
+			chunks++
+		}
+	}
+	assert.Equal(t, 1, kgs.GetConnectedClients())
+}
+
+func TestKCPGameServer_JSONCodecOnTCPFallback(t *testing.T) {
+	kgs, err := NewKCPGameServer("127.0.0.1:0", "127.0.0.1:0")
+	require.NoError(t, err)
+	kgs.SetJSONCodecAllowed(true) // Флаг применяется и к включённому позже TCP
+	require.NoError(t, kgs.EnableTCPFallback("127.0.0.1:0"))
+	require.NoError(t, kgs.Start())
+	t.Cleanup(kgs.Stop)
+
+	conn, err := net.Dial("tcp", kgs.TCPFallbackAddr())
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	password := "ChangeMe123!"
+	data, err := protocol.NewJSONCodec(kgs.tcpServer.serializer).Encode(protocol.MessageType_AUTH, &protocol.AuthMessage{
+		Username:        "admin",
+		Password:        &password,
+		ProtocolVersion: ProtocolVersion,
+	})
+	require.NoError(t, err)
+	_, err = conn.Write(append(binary.BigEndian.AppendUint32(nil, uint32(len(data))), data...))
+	require.NoError(t, err)
+
+	// Сервер отвечает тем же кодеком
+	var wire struct {
+		Type    string          `json:"type"`
+		Payload json.RawMessage `json:"payload"`
+	}
+	for wire.Type != protocol.MessageType_AUTH_RESPONSE.String() {
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+		header := make([]byte, 4)
+		_, err = io.ReadFull(conn, header)
+		require.NoError(t, err)
+		body := make([]byte, binary.BigEndian.Uint32(header))
+		_, err = io.ReadFull(conn, body)
+		require.NoError(t, err)
+		require.Equal(t, protocol.CodecJSON, protocol.DetectCodec(body))
+		require.NoError(t, json.Unmarshal(body, &wire))
+	}
+	resp := &protocol.AuthResponseMessage{}
+	require.NoError(t, protojson.Unmarshal(wire.Payload, resp))
+	assert.True(t, resp.Success, resp.Message)
+}
@@ -3,6 +3,8 @@ package network
 import (
 	"context"
 	"encoding/binary"
+	"errors"
+	"fmt"
 	"io"
 	"log"
 	"net"
@@ -17,8 +19,6 @@ import (
 )
 
 const (
-	// MaxConnections - максимальное количество одновременных подключений
-	MaxConnections = 1000
 	// MaxConnectionsPerIP - максимальное количество подключений с одного IP
 	MaxConnectionsPerIP = 5
 	// ConnectionTimeout - таймаут для неактивных подключений
@@ -27,16 +27,18 @@ const (
 
 // TCPServerPB представляет TCP сервер с поддержкой Protocol Buffers
 type TCPServerPB struct {
-	listener         net.Listener
-	connections      map[string]*TCPConnectionPB
-	connectionsByIP  map[string]int32 // IP -> count of connections
-	totalConnections int32
-	worldManager     *world.WorldManager
-	gameHandler      *GameHandlerPB
-	mu               sync.RWMutex
-	ctx              context.Context
-	cancel           context.CancelFunc
-	serializer       *protocol.MessageSerializer
+	listener        net.Listener
+	connections     map[string]*TCPConnectionPB
+	connectionsByIP map[string]int32 // IP -> count of connections
+	limits          connectionLimiter
+	worldManager    *world.WorldManager
+	gameHandler     *GameHandlerPB
+	mu              sync.RWMutex // Берётся после gh.mu, не наоборот (см. lock_order.go)
+	ctx             context.Context
+	cancel          context.CancelFunc
+	serializer      *protocol.MessageSerializer
+	allowJSON       atomic.Bool    // Разрешить клиентам кодек JSON (для отладки)
+	connWG          sync.WaitGroup // Циклы чтения соединений и их отключение в игровом обработчике
 }
 
 // TCPConnectionPB представляет подключение клиента по TCP
@@ -44,10 +46,15 @@ type TCPConnectionPB struct {
 	id         string
 	conn       net.Conn
 	server     *TCPServerPB
-	playerID   uint64
+	playerID   uint64 // Защищён server.mu (см. tcpSender.BindPlayer)
 	ctx        context.Context
 	cancel     context.CancelFunc
 	serializer *protocol.MessageSerializer
+
+	codecMu sync.RWMutex
+	codec   protocol.Codec // Кодек соединения, выбирается по первому сообщению клиента
+
+	writeMu sync.Mutex // Сообщения из разных горутин (пул чанков, рассылки) не перемешиваются
 }
 
 // NewTCPServerPB создает новый TCP сервер с поддержкой Protocol Buffers
@@ -63,6 +70,7 @@ func NewTCPServerPB(address string, worldManager *world.WorldManager) (*TCPServe
 		listener:        listener,
 		connections:     make(map[string]*TCPConnectionPB),
 		connectionsByIP: make(map[string]int32),
+		limits:          connectionLimiter{transport: "tcp"},
 		worldManager:    worldManager,
 		ctx:             ctx,
 		cancel:          cancel,
@@ -79,7 +87,6 @@ func (s *TCPServerPB) Start() {
 func (s *TCPServerPB) Stop() {
 	s.cancel()
 	s.mu.Lock()
-	defer s.mu.Unlock()
 
 	// Закрываем все соединения
 	for _, conn := range s.connections {
@@ -88,6 +95,11 @@ func (s *TCPServerPB) Stop() {
 
 	// Закрываем слушатель
 	s.listener.Close()
+	s.mu.Unlock()
+
+	// Дожидаемся отключения игроков в обработчике: после Stop их позиции
+	// уже попали в отложенные сохранения и сбрасываются вместе с ними
+	s.connWG.Wait()
 }
 
 // SetGameHandler устанавливает обработчик игры
@@ -95,6 +107,13 @@ func (s *TCPServerPB) SetGameHandler(handler *GameHandlerPB) {
 	s.gameHandler = handler
 }
 
+// SetJSONCodecAllowed разрешает клиентам общаться в JSON вместо Protocol Buffers.
+// Кодек выбирается по первому сообщению соединения; JSON нужен для отладки
+// отдельных клиентов и в рабочем режиме обычно выключен
+func (s *TCPServerPB) SetJSONCodecAllowed(allowed bool) {
+	s.allowJSON.Store(allowed)
+}
+
 // acceptLoop принимает входящие соединения
 func (s *TCPServerPB) acceptLoop() {
 	for {
@@ -110,9 +129,9 @@ func (s *TCPServerPB) acceptLoop() {
 			}
 
 			// Проверяем лимиты перед принятием соединения
-			if !s.canAcceptConnection(conn) {
-				logging.Warn("Соединение отклонено из-за лимитов: %s", conn.RemoteAddr().String())
-				conn.Close()
+			if reason, ok := s.admitConnection(conn); !ok {
+				logging.Warn("Соединение отклонено из-за лимитов (%s): %s", reason, conn.RemoteAddr().String())
+				s.rejectConnection(conn, reason)
 				continue
 			}
 
@@ -144,23 +163,50 @@ func (s *TCPServerPB) handleConnection(conn net.Conn) {
 	// Обновляем счетчики
 	ip := getIPFromAddr(conn.RemoteAddr())
 	s.connectionsByIP[ip]++
-	atomic.AddInt32(&s.totalConnections, 1)
 	s.mu.Unlock()
 
+	if s.gameHandler != nil {
+		s.gameHandler.OnClientConnect(connID)
+	}
+
 	// Запускаем обработку сообщений
-	go connection.readLoop()
+	s.connWG.Add(1)
+	go func() {
+		defer s.connWG.Done()
+		connection.readLoop()
+	}()
 
-	totalConns := atomic.LoadInt32(&s.totalConnections)
+	totalConns := s.limits.stats().Current
 	logging.Info("Новое TCP соединение: %s (всего: %d)", connID, totalConns)
 	log.Printf("Новое TCP соединение: %s (всего: %d)", connID, totalConns)
 }
 
 // removeConnection удаляет соединение из списка
 func (s *TCPServerPB) removeConnection(connID string) {
+	if !s.detachConnection(connID) {
+		return
+	}
+
+	// Оповещаем игровой обработчик о разрыве соединения, чтобы очистить карты playerEntities и т.д.
+	// Вызываем вне блокировки s.mu, чтобы избежать возможных дедлоков.
+	// removeConnection вызывается из цикла чтения, поэтому счётчик connWG ещё не обнулён
+	s.connWG.Add(1)
+	go func(handler *GameHandlerPB, id string) {
+		defer s.connWG.Done()
+		if handler != nil {
+			handler.OnClientDisconnect(id)
+		}
+	}(s.gameHandler, connID)
+}
+
+// detachConnection удаляет соединение из учёта сервера. Возвращает false,
+// если соединение уже удалено
+func (s *TCPServerPB) detachConnection(connID string) bool {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if conn, exists := s.connections[connID]; exists {
+	conn, exists := s.connections[connID]
+	if exists {
 		// Обновляем счетчики
 		ip := getIPFromAddr(conn.conn.RemoteAddr())
 		if count := s.connectionsByIP[ip]; count > 0 {
@@ -169,58 +215,84 @@ func (s *TCPServerPB) removeConnection(connID string) {
 				delete(s.connectionsByIP, ip)
 			}
 		}
-		atomic.AddInt32(&s.totalConnections, -1)
+		s.limits.release()
 
 		delete(s.connections, connID)
-		remaining := atomic.LoadInt32(&s.totalConnections)
+		remaining := s.limits.stats().Current
 		logging.Info("TCP соединение закрыто: %s (осталось: %d)", connID, remaining)
 		log.Printf("TCP соединение закрыто: %s (осталось: %d)", connID, remaining)
-
-		// Оповещаем игровой обработчик о разрыве соединения, чтобы очистить карты playerEntities и т.д.
-		// Вызываем вне блокировки s.mu, чтобы избежать возможных дедлоков.
-		go func(handler *GameHandlerPB, id string) {
-			if handler != nil {
-				handler.OnClientDisconnect(id)
-			}
-		}(s.gameHandler, connID)
 	}
+	return exists
 }
 
-// broadcastMessage отправляет сообщение всем подключенным клиентам
+// broadcastMessage отправляет сообщение всем подключенным клиентам.
+// Запись в сокеты выполняется без s.mu, чтобы медленный клиент не
+// задерживал подключения и отключения
 func (s *TCPServerPB) broadcastMessage(msgType protocol.MessageType, payload proto.Message) {
 	s.mu.RLock()
-	defer s.mu.RUnlock()
-
+	connections := make([]*TCPConnectionPB, 0, len(s.connections))
 	for _, conn := range s.connections {
+		connections = append(connections, conn)
+	}
+	s.mu.RUnlock()
+
+	for _, conn := range connections {
 		conn.sendMessage(msgType, payload)
 	}
 }
 
 // sendToPlayer отправляет сообщение конкретному игроку
 func (s *TCPServerPB) sendToPlayer(playerID uint64, msgType protocol.MessageType, payload proto.Message) {
+	var target *TCPConnectionPB
 	s.mu.RLock()
-	defer s.mu.RUnlock()
-
 	for _, conn := range s.connections {
 		if conn.playerID == playerID {
-			conn.sendMessage(msgType, payload)
-			return
+			target = conn
+			break
 		}
 	}
+	s.mu.RUnlock()
+
+	if target != nil {
+		target.sendMessage(msgType, payload)
+	}
 }
 
 // sendToClient отправляет сообщение конкретному клиенту по ID соединения
 func (s *TCPServerPB) sendToClient(connID string, msgType protocol.MessageType, payload proto.Message) {
 	s.mu.RLock()
-	defer s.mu.RUnlock()
-
-	// Находим соединение по ID
-	if conn, exists := s.connections[connID]; exists {
+	conn, exists := s.connections[connID]
+	s.mu.RUnlock()
 
-		conn.sendMessage(msgType, payload)
-	} else {
+	if !exists {
 		log.Printf("❌ TCP: Соединение %s не найдено!", connID)
+		return
+	}
+	conn.sendMessage(msgType, payload)
+}
+
+// disconnectWriteTimeout - предел записи сообщения с причиной отключения
+const disconnectWriteTimeout = time.Second
+
+// disconnectClient принудительно отключает клиента. Сообщение с причиной
+// записывается в сокет до закрытия, сессия очищается (OnClientDisconnect)
+// синхронно: к возврату позиция игрока сохранена, а другие игроки оповещены
+func (s *TCPServerPB) disconnectClient(connID string, reason DisconnectReason) {
+	s.mu.RLock()
+	conn, exists := s.connections[connID]
+	s.mu.RUnlock()
+
+	if !exists {
+		return
+	}
+
+	// Клиент, который не читает сокет, не должен задерживать отключение
+	_ = conn.conn.SetWriteDeadline(time.Now().Add(disconnectWriteTimeout))
+	conn.sendMessage(protocol.MessageType_SERVER_MESSAGE, reason.ServerMessage())
+	if s.detachConnection(connID) && s.gameHandler != nil {
+		s.gameHandler.OnClientDisconnect(connID)
 	}
+	conn.close()
 }
 
 // readLoop обрабатывает входящие сообщения от клиента
@@ -231,6 +303,7 @@ func (c *TCPConnectionPB) readLoop() {
 	}()
 
 	headerBuffer := make([]byte, 4) // 4 байта для размера сообщения
+	negotiated := false
 
 	for {
 		select {
@@ -253,6 +326,18 @@ func (c *TCPConnectionPB) readLoop() {
 				return
 			}
 
+			// Сообщения сверх лимита сериализатора пропускаем без выделения буфера
+			if sizeErr := c.serializer.CheckMessageSize(int(messageSize)); sizeErr != nil {
+				if _, err := io.CopyN(io.Discard, c.conn, int64(messageSize)); err != nil {
+					log.Printf("Ошибка чтения тела сообщения: %v", err)
+					return
+				}
+				if c.server.gameHandler != nil {
+					c.server.gameHandler.rejectOversized(c.id, sizeErr)
+				}
+				continue
+			}
+
 			// Читаем тело сообщения
 			messageBuffer := make([]byte, messageSize)
 			_, err = io.ReadFull(c.conn, messageBuffer)
@@ -261,6 +346,15 @@ func (c *TCPConnectionPB) readLoop() {
 				return
 			}
 
+			// Первое сообщение определяет кодек соединения
+			if !negotiated {
+				if err := c.negotiateCodec(messageBuffer); err != nil {
+					log.Printf("❌ TCP: %s: %v", c.id, err)
+					return
+				}
+				negotiated = true
+			}
+
 			// Обрабатываем сообщение
 			go c.handleMessage(messageBuffer)
 		}
@@ -273,8 +367,13 @@ func (c *TCPConnectionPB) handleMessage(data []byte) {
 	logging.LogMessage("RECEIVED", protocol.MessageType_AUTH, data, c.id)
 
 	// Десериализуем сообщение
-	msg, err := c.serializer.DeserializeMessage(data)
+	msg, err := c.currentCodec().Decode(data)
 	if err != nil {
+		var tooLarge *protocol.PayloadTooLargeError
+		if errors.As(err, &tooLarge) && c.server.gameHandler != nil {
+			c.server.gameHandler.rejectOversized(c.id, err)
+			return
+		}
 		logging.LogProtocolError("TCP Deserialization", err, data)
 		log.Printf("Ошибка десериализации сообщения: %v", err)
 		return
@@ -311,65 +410,115 @@ func (c *TCPConnectionPB) handleMessage(data []byte) {
 	// Передаем сообщение в игровой обработчик
 	c.server.gameHandler.HandleMessage(c.id, msg)
 
-	// Устанавливаем playerID для соединения если это сообщение авторизации
+	// playerID связывается с соединением в BindPlayer при успешной авторизации
 	if msg.Type == protocol.MessageType_AUTH {
-		// После обработки авторизации получаем ID игрока из карты соединений
 		c.server.mu.RLock()
-		for id, conn := range c.server.connections {
-			if id == c.id && conn.playerID != 0 {
-				c.playerID = conn.playerID
-				logging.Info("TCP: Установлен playerID %d для соединения %s", conn.playerID, c.id)
-				break
-			}
-		}
+		playerID := c.playerID
 		c.server.mu.RUnlock()
+		if playerID != 0 {
+			logging.Info("TCP: Установлен playerID %d для соединения %s", playerID, c.id)
+		}
 	}
 }
 
-// sendMessage отправляет сообщение клиенту
+// sendMessage отправляет сообщение клиенту. Кадр собирается в буфере из
+// пула protocol.GetBuffer и возвращается в пул после записи: запись в
+// net.Conn синхронна, после возврата Write буфер сокетом не используется
 func (c *TCPConnectionPB) sendMessage(msgType protocol.MessageType, payload proto.Message) {
+	buf := protocol.GetBuffer()
+	defer protocol.PutBuffer(buf)
+
 	// Сериализуем сообщение
-	data, err := c.serializer.SerializeMessage(msgType, payload)
+	frame, err := c.encodeFrame(*buf, msgType, payload)
 	if err != nil {
 		logging.Error("❌ TCP: Ошибка сериализации сообщения %v для %s: %v", msgType, c.id, err)
 		log.Printf("❌ TCP: Ошибка сериализации сообщения: %v", err)
 		return
 	}
+	*buf = frame
 
 	// Логируем отправку сообщения
-	logging.LogMessage("SENDING", msgType, data, c.id)
-
-	// Отправляем размер сообщения (4 байта)
-	header := make([]byte, 4)
-	binary.BigEndian.PutUint32(header, uint32(len(data)))
+	logging.LogMessage("SENDING", msgType, frame[4:], c.id)
 
-	// Отправляем сообщение
-	_, err1 := c.conn.Write(header)
-	_, err2 := c.conn.Write(data)
+	// Размер сообщения (4 байта) и само сообщение уходят одной записью
+	c.writeMu.Lock()
+	_, err = c.conn.Write(frame)
+	c.writeMu.Unlock()
 
-	if err1 != nil || err2 != nil {
-		logging.Error("❌ TCP: Ошибка отправки сообщения %v клиенту %s: %v, %v", msgType, c.id, err1, err2)
-		log.Printf("❌ TCP: Ошибка отправки сообщения клиенту %s: %v, %v", c.id, err1, err2)
+	if err != nil {
+		logging.Error("❌ TCP: Ошибка отправки сообщения %v клиенту %s: %v", msgType, c.id, err)
+		log.Printf("❌ TCP: Ошибка отправки сообщения клиенту %s: %v", c.id, err)
 		return
 	}
 
 	logging.Debug("✅ TCP: Сообщение %v отправлено клиенту %s", msgType, c.id)
 }
 
+// encodeFrame дописывает в dst кадр сообщения: размер (4 байта) и сообщение
+// в кодеке соединения
+func (c *TCPConnectionPB) encodeFrame(dst []byte, msgType protocol.MessageType, payload proto.Message) ([]byte, error) {
+	start := len(dst)
+	frame := append(dst, 0, 0, 0, 0)
+
+	codec := c.currentCodec()
+	if encoder, ok := codec.(protocol.AppendEncoder); ok {
+		var err error
+		if frame, err = encoder.AppendEncode(frame, msgType, payload); err != nil {
+			return dst, err
+		}
+	} else {
+		data, err := codec.Encode(msgType, payload)
+		if err != nil {
+			return dst, err
+		}
+		frame = append(frame, data...)
+	}
+
+	binary.BigEndian.PutUint32(frame[start:], uint32(len(frame)-start-4))
+	return frame, nil
+}
+
+// negotiateCodec выбирает кодек соединения по первому сообщению клиента
+func (c *TCPConnectionPB) negotiateCodec(first []byte) error {
+	name := protocol.DetectCodec(first)
+	if name == protocol.CodecJSON && !c.server.allowJSON.Load() {
+		return fmt.Errorf("кодек %s не разрешён", name)
+	}
+
+	codec, err := protocol.NewCodec(name, c.serializer)
+	if err != nil {
+		return err
+	}
+
+	c.codecMu.Lock()
+	c.codec = codec
+	c.codecMu.Unlock()
+
+	if name != protocol.CodecProtobuf {
+		log.Printf("🔤 TCP: соединение %s использует кодек %s", c.id, name)
+	}
+	return nil
+}
+
+// currentCodec возвращает кодек соединения (Protocol Buffers, пока не выбран другой)
+func (c *TCPConnectionPB) currentCodec() protocol.Codec {
+	c.codecMu.RLock()
+	defer c.codecMu.RUnlock()
+	if c.codec == nil {
+		return protocol.NewProtobufCodec(c.serializer)
+	}
+	return c.codec
+}
+
 // close закрывает соединение
 func (c *TCPConnectionPB) close() {
 	c.cancel()
 	c.conn.Close()
 }
 
-// canAcceptConnection проверяет, можем ли мы принять новое соединение
-func (s *TCPServerPB) canAcceptConnection(conn net.Conn) bool {
-	// Проверяем общий лимит подключений
-	if atomic.LoadInt32(&s.totalConnections) >= MaxConnections {
-		log.Printf("Превышен лимит подключений: %d", MaxConnections)
-		return false
-	}
-
+// admitConnection резервирует место под новое соединение. При отказе
+// возвращает код причины для SERVER_MESSAGE
+func (s *TCPServerPB) admitConnection(conn net.Conn) (string, bool) {
 	// Проверяем лимит подключений с одного IP
 	ip := getIPFromAddr(conn.RemoteAddr())
 	s.mu.RLock()
@@ -378,10 +527,50 @@ func (s *TCPServerPB) canAcceptConnection(conn net.Conn) bool {
 
 	if count >= MaxConnectionsPerIP {
 		log.Printf("Превышен лимит подключений с IP %s: %d", ip, MaxConnectionsPerIP)
-		return false
+		s.limits.reject(ServerMessageIPLimit)
+		return ServerMessageIPLimit, false
+	}
+
+	// Проверяем общий лимит подключений
+	if !s.limits.acquire() {
+		log.Printf("Превышен лимит подключений: %d", s.limits.limit())
+		s.limits.reject(ServerMessageServerFull)
+		return ServerMessageServerFull, false
 	}
 
-	return true
+	return "", true
+}
+
+// rejectConnection по возможности сообщает клиенту причину отказа и закрывает соединение
+func (s *TCPServerPB) rejectConnection(conn net.Conn, reason string) {
+	defer conn.Close()
+
+	data, err := s.serializer.SerializeMessage(protocol.MessageType_SERVER_MESSAGE, &protocol.ServerMessage{
+		Code:      reason,
+		Message:   rejectionText(reason),
+		Timestamp: time.Now().UnixNano(),
+	})
+	if err != nil {
+		return
+	}
+
+	frame := make([]byte, 4+len(data))
+	binary.BigEndian.PutUint32(frame, uint32(len(data)))
+	copy(frame[4:], data)
+
+	_ = conn.SetWriteDeadline(time.Now().Add(rejectWriteTimeout))
+	_, _ = conn.Write(frame)
+}
+
+// SetMaxConnections задаёт лимит одновременных подключений
+// (0 - DefaultMaxConnections, отрицательное значение - без ограничения)
+func (s *TCPServerPB) SetMaxConnections(limit int) {
+	s.limits.setMax(limit)
+}
+
+// ConnectionLimitStats возвращает счётчики подключений и отказов
+func (s *TCPServerPB) ConnectionLimitStats() ConnectionLimitStats {
+	return s.limits.stats()
 }
 
 // getIPFromAddr извлекает IP адрес из net.Addr
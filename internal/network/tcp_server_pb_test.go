@@ -0,0 +1,150 @@
+package network
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/protocol"
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/annel0/mmo-game/internal/world/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+// TestTCPServer_BroadcastDuringConnectChurn рассылает сообщения, пока клиенты
+// подключаются и отключаются. Гонки ловит go test -race
+func TestTCPServer_BroadcastDuringConnectChurn(t *testing.T) {
+	gh := newTestGameHandler(t)
+	server := startLimitedTCPServer(t, -1, gh)
+	mover, ok := gh.entityManager.GetEntity(gh.SpawnEntity(entity.EntityTypeNPC, vec.Vec2{X: 1, Y: 1}))
+	require.True(t, ok)
+	addr := server.listener.Addr().String()
+
+	stop := make(chan struct{})
+	var churn sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		churn.Add(1)
+		go func() {
+			defer churn.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				conn, err := net.Dial("tcp", addr)
+				if err != nil {
+					continue
+				}
+				time.Sleep(time.Millisecond)
+				conn.Close()
+			}
+		}()
+	}
+
+	assert.NotPanics(t, func() {
+		deadline := time.Now().Add(500 * time.Millisecond)
+		for i := uint64(1); time.Now().Before(deadline); i++ {
+			gh.broadcastMessage(protocol.MessageType_CHAT_BROADCAST, &protocol.ChatMessage{Message: "tick"})
+			gh.sendEntityMoveUpdate(mover)
+			for _, connID := range gh.sender.ConnectionIDs() {
+				gh.sender.BindPlayer(connID, i)
+				gh.sendTCPMessage(connID, protocol.MessageType_CHAT_BROADCAST, &protocol.ChatMessage{Message: "direct"})
+			}
+			server.sendToPlayer(i, protocol.MessageType_CHAT_BROADCAST, &protocol.ChatMessage{Message: "player"})
+		}
+	})
+
+	close(stop)
+	churn.Wait()
+	require.Eventually(t, func() bool { return server.ConnectionLimitStats().Current == 0 },
+		5*time.Second, 10*time.Millisecond)
+}
+
+// TestTCPServer_ConcurrentBroadcastsWithPooledBuffers рассылает сообщения из
+// нескольких горутин: каждый клиент должен получить все сообщения целыми, без
+// перемешивания содержимого переиспользуемых буферов. Гонки ловит go test -race
+func TestTCPServer_ConcurrentBroadcastsWithPooledBuffers(t *testing.T) {
+	const clients, senders, perSender = 4, 8, 50
+
+	server := startLimitedTCPServer(t, -1, nil)
+	conns := make([]net.Conn, clients)
+	for i := range conns {
+		conns[i] = dialTCP(t, server)
+	}
+	require.Eventually(t, func() bool { return server.ConnectionLimitStats().Current == clients },
+		5*time.Second, 10*time.Millisecond)
+
+	var wg sync.WaitGroup
+	for g := range senders {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range perSender {
+				// Длина сообщений разная, чтобы буферы пула переиспользовались с остатками
+				text := fmt.Sprintf("sender-%d-msg-%d-%s", g, i, strings.Repeat("x", i*7))
+				server.broadcastMessage(protocol.MessageType_CHAT_BROADCAST, &protocol.ChatMessage{Message: text})
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, conn := range conns {
+		received := make(map[string]bool, senders*perSender)
+		for range senders * perSender {
+			msg, err := readFrame(t, conn, server.serializer)
+			require.NoError(t, err)
+			chat := &protocol.ChatMessage{}
+			require.NoError(t, proto.Unmarshal(msg.Payload, chat))
+			received[chat.Message] = true
+		}
+		for g := range senders {
+			for i := range perSender {
+				assert.True(t, received[fmt.Sprintf("sender-%d-msg-%d-%s", g, i, strings.Repeat("x", i*7))])
+			}
+		}
+	}
+}
+
+// BenchmarkTCPServer_Broadcast измеряет аллокации рассылки ENTITY_MOVE
+// нескольким клиентам из параллельных горутин
+func BenchmarkTCPServer_Broadcast(b *testing.B) {
+	server, err := NewTCPServerPB("127.0.0.1:0", nil)
+	require.NoError(b, err)
+	server.SetMaxConnections(-1)
+	server.Start()
+	defer server.Stop()
+
+	// Не больше лимита подключений с одного IP
+	for range 4 {
+		conn, err := net.Dial("tcp", server.listener.Addr().String())
+		require.NoError(b, err)
+		defer conn.Close()
+		go io.Copy(io.Discard, conn)
+	}
+	require.Eventually(b, func() bool { return server.ConnectionLimitStats().Current == 4 },
+		5*time.Second, 10*time.Millisecond)
+
+	msg := &protocol.EntityMoveMessage{}
+	for i := range 32 {
+		msg.Entities = append(msg.Entities, &protocol.EntityData{
+			Id:       uint64(i + 1),
+			Position: &protocol.Vec2{X: int32(i), Y: int32(-i)},
+			Active:   true,
+		})
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			server.broadcastMessage(protocol.MessageType_ENTITY_MOVE, msg)
+		}
+	})
+}
@@ -0,0 +1,139 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/annel0/mmo-game/internal/world"
+	"github.com/annel0/mmo-game/internal/world/entity"
+)
+
+// teleportChunkRadius - радиус чанков вокруг точки назначения, которые клиент
+// получает до корректировки позиции. Остальная зона видимости досылается фоном
+const teleportChunkRadius = 1
+
+var (
+	// ErrPlayerOffline - у пользователя нет игровой сессии на этом узле
+	ErrPlayerOffline = errors.New("игрок не в сети")
+
+	// ErrInvalidTeleportDestination - точку назначения нельзя загрузить на этом узле
+	ErrInvalidTeleportDestination = errors.New("недопустимая точка назначения")
+)
+
+// TeleportResult - итог перемещения игрока администратором
+type TeleportResult struct {
+	UserID          uint64   `json:"user_id"`
+	EntityID        uint64   `json:"entity_id"`
+	From            vec.Vec2 `json:"from"`
+	To              vec.Vec3 `json:"to"`               // Z - слой
+	PreloadedChunks int      `json:"preloaded_chunks"` // Чанков в зоне видимости точки назначения
+}
+
+// TeleportPlayer перемещает игрока в точку dest (Z - слой, игроки бывают
+// только на слое ACTIVE, другие слои отклоняются). Чанки вокруг
+// точки назначения загружаются заранее, ближайшие из них отправляются клиенту
+// до корректировки позиции, чтобы он не оказался в незагруженной области
+func (gh *GameHandlerPB) TeleportPlayer(username string, dest vec.Vec3) (TeleportResult, error) {
+	target := dest.ToVec2()
+	if err := gh.validateTeleportDestination(dest); err != nil {
+		return TeleportResult{}, err
+	}
+
+	gh.mu.RLock()
+	connID, userID, entityID := "", uint64(0), uint64(0)
+	for id, session := range gh.sessions {
+		if session.Username == username {
+			connID, userID, entityID = id, session.UserID, session.EntityID
+			break
+		}
+	}
+	gh.mu.RUnlock()
+
+	e, exists := gh.entityManager.GetEntity(entityID)
+	if connID == "" || !exists {
+		return TeleportResult{}, fmt.Errorf("%w: %s", ErrPlayerOffline, username)
+	}
+
+	// Зона видимости в точке назначения загружается до перемещения
+	center := target.ToChunkCoords()
+	radius := gh.viewDistanceFor(connID)
+	progress := gh.worldManager.PrefetchChunks(
+		vec.Vec2{X: center.X - radius, Y: center.Y - radius},
+		vec.Vec2{X: center.X + radius, Y: center.Y + radius},
+	)
+	if gh.worldManager.GetChunk(center) == nil {
+		return TeleportResult{}, fmt.Errorf("%w: чанк (%d, %d) не загружается", ErrInvalidTeleportDestination, center.X, center.Y)
+	}
+
+	from := e.Position
+	gh.relocatePlayer(connID, e, target)
+	log.Printf("🌀 Игрок %s телепортирован администратором: (%d, %d) -> (%d, %d, слой %d)",
+		username, from.X, from.Y, target.X, target.Y, dest.Z)
+
+	return TeleportResult{
+		UserID:          userID,
+		EntityID:        e.ID,
+		From:            from,
+		To:              dest,
+		PreloadedChunks: progress.Total,
+	}, nil
+}
+
+// relocatePlayer переносит игрока в target и перезапускает отправку чанков
+// вокруг новой позиции: ближайшие чанки отправляются до коррекции позиции,
+// остальные - фоновым потоком. Общий шаг телепорта и возврата из пропасти
+func (gh *GameHandlerPB) relocatePlayer(connID string, e *entity.Entity, target vec.Vec2) {
+	center := target.ToChunkCoords()
+	radius := gh.viewDistanceFor(connID)
+
+	// Буферизованные шаги относятся к старой позиции: отбрасываются до
+	// перемещения, чтобы не примениться уже к новой
+	gh.dropMoveInputs(connID)
+	gh.entityManager.MoveEntity(e.ID, vec.Vec2Float{X: float64(target.X), Y: float64(target.Y)})
+
+	// История античита тоже относится к старой позиции
+	gh.mu.Lock()
+	gh.resetAnticheatLocked(e.ID, target)
+	gh.mu.Unlock()
+
+	// Прежняя отправка чанков относится к старой позиции
+	if prev := gh.cancelChunkStream(connID); prev != nil {
+		<-prev.done
+	}
+	gh.sendChunkUnload(connID, gh.recenterKnownChunks(connID, center, radius))
+	gh.streamChunks(gh.connContext(connID), connID, chunksByDistance(center, min(teleportChunkRadius, radius)))
+
+	gh.sendEntityPositionCorrection(connID, e)
+	gh.sendEntityMoveUpdate(e)
+	gh.startChunkStream(connID, center, radius)
+}
+
+// validateTeleportDestination проверяет слой, диапазон координат протокола и
+// то, что точку назначения обслуживает этот узел
+func (gh *GameHandlerPB) validateTeleportDestination(dest vec.Vec3) error {
+	if dest.Z < 0 || dest.Z >= int(world.MaxLayers) {
+		return fmt.Errorf("%w: слой %d (допустимо 0..%d)", ErrInvalidTeleportDestination, dest.Z, world.MaxLayers-1)
+	}
+	if dest.Z != int(world.LayerActive) {
+		return fmt.Errorf("%w: слой %d, игроки находятся только на слое ACTIVE (%d)",
+			ErrInvalidTeleportDestination, dest.Z, world.LayerActive)
+	}
+	if dest.X < math.MinInt32 || dest.X > math.MaxInt32 || dest.Y < math.MinInt32 || dest.Y > math.MaxInt32 {
+		return fmt.Errorf("%w: координаты (%d, %d) вне диапазона протокола", ErrInvalidTeleportDestination, dest.X, dest.Y)
+	}
+
+	if !gh.worldManager.InsideWorldBorder(dest.ToVec2()) {
+		return fmt.Errorf("%w: точка (%d, %d) за границей мира", ErrInvalidTeleportDestination, dest.X, dest.Y)
+	}
+
+	gh.mu.RLock()
+	route, redirect := gh.regions.redirectFor(dest.ToVec2())
+	gh.mu.RUnlock()
+	if redirect {
+		return fmt.Errorf("%w: точка принадлежит региону %s", ErrInvalidTeleportDestination, route.ID)
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package testharness
+
+import (
+	"github.com/annel0/mmo-game/internal/network"
+	"github.com/annel0/mmo-game/internal/protocol"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+// Conn - виртуальное соединение клиента
+type Conn struct {
+	h        *Harness
+	ID       string
+	PlayerID uint64 // ID сущности игрока после успешного Auth
+
+	inbox    *inbox
+	sequence uint32
+}
+
+// Send передаёт обработчику сообщение от имени соединения. Обработка
+// синхронна: после возврата ответы на сообщение уже перехвачены
+func (c *Conn) Send(msgType protocol.MessageType, payload proto.Message) {
+	c.h.t.Helper()
+
+	data, err := proto.Marshal(payload)
+	require.NoError(c.h.t, err)
+
+	c.sequence++
+	c.h.Handler.HandleMessage(c.ID, &protocol.GameMessage{
+		Type:     msgType,
+		Payload:  data,
+		Sequence: c.sequence,
+	})
+}
+
+// Auth аутентифицирует соединение и требует успешного ответа
+func (c *Conn) Auth(username, password string) *protocol.AuthResponseMessage {
+	c.h.t.Helper()
+
+	resp := c.TryAuth(username, password)
+	require.True(c.h.t, resp.Success, "аутентификация %s: %s", username, resp.Message)
+	return resp
+}
+
+// TryAuth отправляет AUTH и возвращает ответ сервера, успешный или нет
+func (c *Conn) TryAuth(username, password string) *protocol.AuthResponseMessage {
+	c.h.t.Helper()
+
+	c.Send(protocol.MessageType_AUTH, &protocol.AuthMessage{
+		Username:        username,
+		Password:        &password,
+		ProtocolVersion: network.ProtocolVersion,
+		Capabilities:    []string{network.CapabilityWorldState},
+	})
+
+	resp := &protocol.AuthResponseMessage{}
+	c.Expect(protocol.MessageType_AUTH_RESPONSE, resp)
+	if resp.Success {
+		c.PlayerID = resp.PlayerId
+	}
+	return resp
+}
+
+// Move просит переместить сущность игрока в (x, y)
+func (c *Conn) Move(x, y int32) {
+	c.h.t.Helper()
+
+	c.Send(protocol.MessageType_ENTITY_MOVE, &protocol.EntityMoveMessage{
+		Entities: []*protocol.EntityData{{
+			Id:       c.PlayerID,
+			Position: &protocol.Vec2{X: x, Y: y},
+		}},
+	})
+}
+
+// PlaceBlock отправляет BLOCK_UPDATE для ACTIVE слоя
+func (c *Conn) PlaceBlock(x, y int32, blockID uint32) {
+	c.h.t.Helper()
+
+	c.Send(protocol.MessageType_BLOCK_UPDATE, &protocol.BlockUpdateRequest{
+		Position: &protocol.Vec2{X: x, Y: y},
+		BlockId:  blockID,
+		Layer:    protocol.BlockLayer_ACTIVE,
+		Action:   "place",
+	})
+}
+
+// Expect ждёт следующее сообщение указанного типа и декодирует его в out.
+// Сообщения других типов, пришедшие раньше, пропускаются
+func (c *Conn) Expect(msgType protocol.MessageType, out proto.Message) {
+	c.h.t.Helper()
+
+	msg := c.h.sender.next(c.inbox, msgType)
+	require.NotNil(c.h.t, msg, "%s: сообщение %v не получено", c.ID, msgType)
+	require.NoError(c.h.t, proto.Unmarshal(msg.Payload, out))
+}
+
+// ExpectError ждёт сообщение об ошибке и проверяет её код
+func (c *Conn) ExpectError(code protocol.ErrorCode) *protocol.ErrorMessage {
+	c.h.t.Helper()
+
+	errMsg := &protocol.ErrorMessage{}
+	c.Expect(protocol.MessageType_ERROR, errMsg)
+	require.Equal(c.h.t, code, errMsg.Code, errMsg.Message)
+	return errMsg
+}
+
+// Messages возвращает все сообщения, полученные соединением
+func (c *Conn) Messages() []*protocol.GameMessage {
+	c.h.sender.mu.Lock()
+	defer c.h.sender.mu.Unlock()
+	return append([]*protocol.GameMessage(nil), c.inbox.messages...)
+}
+
+// Count возвращает число полученных сообщений указанного типа
+func (c *Conn) Count(msgType protocol.MessageType) int {
+	count := 0
+	for _, msg := range c.Messages() {
+		if msg.Type == msgType {
+			count++
+		}
+	}
+	return count
+}
+
+// Disconnect закрывает соединение со стороны клиента
+func (c *Conn) Disconnect() {
+	c.h.sender.close(c.ID)
+}
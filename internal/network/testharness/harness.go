@@ -0,0 +1,98 @@
+// Package testharness прогоняет полный цикл сообщений GameHandlerPB в
+// памяти, без TCP/KCP серверов. Тест подключает виртуальные соединения,
+// отправляет protocol.GameMessage от их имени и проверяет перехваченные
+// исходящие сообщения.
+//
+//	h := testharness.New(t)
+//	player := h.Connect("conn-1")
+//	player.Auth("admin", "ChangeMe123!")
+//	player.Move(3, 0)
+//	player.Expect(protocol.MessageType_ENTITY_MOVE, &protocol.EntityMoveMessage{})
+package testharness
+
+import (
+	"testing"
+
+	"github.com/annel0/mmo-game/internal/auth"
+	"github.com/annel0/mmo-game/internal/network"
+	"github.com/annel0/mmo-game/internal/protocol"
+	"github.com/annel0/mmo-game/internal/world"
+	"github.com/annel0/mmo-game/internal/world/entity"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+// DefaultSeed - сид мира харнесса; одинаковый мир во всех тестах
+const DefaultSeed = 1234
+
+// Harness связывает GameHandlerPB с перехватывающим NetworkSender
+type Harness struct {
+	t        *testing.T
+	Handler  *network.GameHandlerPB
+	World    *world.WorldManager
+	Entities *entity.EntityManager
+	Users    auth.UserRepository
+
+	sender *captureSender
+}
+
+// New создаёт обработчик с in-memory зависимостями. Соединения
+// отключаются, а мир останавливается при завершении теста
+func New(t *testing.T) *Harness {
+	t.Helper()
+
+	users, err := auth.NewMemoryUserRepo()
+	require.NoError(t, err)
+
+	worldManager := world.NewWorldManager(DefaultSeed)
+	entities := entity.NewEntityManager()
+	handler := network.NewGameHandlerPB(worldManager, entities, users)
+	handler.SetGameAuthenticator(auth.NewGameAuthenticator(users, []byte("testharness-secret")))
+
+	h := &Harness{
+		t:        t,
+		Handler:  handler,
+		World:    worldManager,
+		Entities: entities,
+		Users:    users,
+		sender:   newCaptureSender(),
+	}
+	h.sender.onDisconnect = handler.OnClientDisconnect
+	handler.SetNetworkSender(h.sender)
+
+	t.Cleanup(func() {
+		for _, connID := range h.sender.ConnectionIDs() {
+			h.sender.close(connID)
+		}
+		worldManager.Stop()
+	})
+	return h
+}
+
+// Connect регистрирует виртуальное соединение
+func (h *Harness) Connect(connID string) *Conn {
+	h.t.Helper()
+
+	inbox := h.sender.connect(connID)
+	h.Handler.OnClientConnect(connID)
+	return &Conn{h: h, ID: connID, inbox: inbox}
+}
+
+// Broadcasts возвращает сообщения, разосланные всем соединениям
+func (h *Harness) Broadcasts() []*protocol.GameMessage {
+	return h.sender.broadcasts()
+}
+
+// ExpectBroadcast ждёт рассылку указанного типа и декодирует её в out
+func (h *Harness) ExpectBroadcast(msgType protocol.MessageType, out proto.Message) {
+	h.t.Helper()
+
+	msg := h.sender.waitBroadcast(msgType)
+	require.NotNil(h.t, msg, "рассылка %v не получена", msgType)
+	require.NoError(h.t, proto.Unmarshal(msg.Payload, out))
+}
+
+// Disconnected сообщает, закрыл ли сервер соединение
+func (h *Harness) Disconnected(connID string) bool {
+	return h.sender.disconnected(connID)
+}
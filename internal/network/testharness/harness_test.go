@@ -0,0 +1,87 @@
+package testharness
+
+import (
+	"testing"
+
+	"github.com/annel0/mmo-game/internal/protocol"
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	adminUser     = "admin"
+	adminPassword = "ChangeMe123!"
+)
+
+// blockedPosition ищет непроходимую позицию рядом с точкой спавна
+func blockedPosition(t *testing.T, h *Harness) vec.Vec2 {
+	t.Helper()
+	for radius := 1; radius < 64; radius++ {
+		for x := -radius; x <= radius; x++ {
+			for _, y := range []int{-radius, radius} {
+				pos := vec.Vec2{X: x, Y: y}
+				if !h.Handler.IsPositionWalkable(pos) {
+					return pos
+				}
+			}
+		}
+	}
+	t.Fatal("в мире нет непроходимых позиций рядом со спавном")
+	return vec.Vec2{}
+}
+
+func TestHarness_InvalidMoveIsCorrected(t *testing.T) {
+	h := New(t)
+	player := h.Connect("conn-1")
+
+	resp := player.Auth(adminUser, adminPassword)
+	require.NotZero(t, resp.PlayerId)
+
+	playerEntity, ok := h.Entities.GetEntity(player.PlayerID)
+	require.True(t, ok)
+	spawn := playerEntity.Position
+
+	target := blockedPosition(t, h)
+	player.Move(int32(target.X), int32(target.Y))
+
+	correction := &protocol.EntityMoveMessage{}
+	player.Expect(protocol.MessageType_ENTITY_MOVE, correction)
+	require.Len(t, correction.Entities, 1)
+
+	corrected := correction.Entities[0]
+	assert.Equal(t, player.PlayerID, corrected.Id)
+	assert.Equal(t, int32(spawn.X), corrected.Position.X)
+	assert.Equal(t, int32(spawn.Y), corrected.Position.Y)
+	assert.Equal(t, uint32(2), corrected.GetLastProcessedInput(), "коррекция несёт номер отклонённого ввода")
+
+	// Сущность на сервере не сдвинулась
+	playerEntity, _ = h.Entities.GetEntity(player.PlayerID)
+	assert.Equal(t, spawn, playerEntity.Position)
+}
+
+func TestHarness_UnauthenticatedMoveRejected(t *testing.T) {
+	h := New(t)
+	guest := h.Connect("guest")
+
+	guest.Move(1, 0)
+	guest.ExpectError(protocol.ErrorCode_UNAUTHORIZED)
+}
+
+func TestHarness_DisconnectBroadcastsDespawn(t *testing.T) {
+	h := New(t)
+	player := h.Connect("conn-1")
+	observer := h.Connect("conn-2")
+	player.Auth(adminUser, adminPassword)
+
+	player.Disconnect()
+	assert.True(t, h.Disconnected("conn-1"))
+
+	despawn := &protocol.EntityDespawnMessage{}
+	observer.Expect(protocol.MessageType_ENTITY_DESPAWN, despawn)
+	assert.Equal(t, player.PlayerID, despawn.EntityId)
+
+	broadcast := &protocol.EntityDespawnMessage{}
+	h.ExpectBroadcast(protocol.MessageType_ENTITY_DESPAWN, broadcast)
+	assert.Equal(t, player.PlayerID, broadcast.EntityId)
+}
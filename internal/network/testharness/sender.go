@@ -0,0 +1,188 @@
+package testharness
+
+import (
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/network"
+	"github.com/annel0/mmo-game/internal/protocol"
+	"google.golang.org/protobuf/proto"
+)
+
+// WaitTimeout - сколько Expect ждёт сообщение (отправка чанков идёт в фоне)
+var WaitTimeout = 5 * time.Second
+
+// captureSender реализует network.NetworkSender: сообщения попадают во
+// входящие очереди виртуальных соединений вместо сокетов
+type captureSender struct {
+	mu      sync.Mutex
+	changed *sync.Cond
+	inboxes map[string]*inbox
+	closed  map[string]bool
+	sent    []*protocol.GameMessage // Рассылки Broadcast
+
+	onDisconnect func(connID string)
+}
+
+// inbox - сообщения одного соединения в порядке отправки
+type inbox struct {
+	messages []*protocol.GameMessage
+	read     int // Сколько сообщений уже просмотрено Expect
+	playerID uint64
+}
+
+func newCaptureSender() *captureSender {
+	s := &captureSender{
+		inboxes: make(map[string]*inbox),
+		closed:  make(map[string]bool),
+	}
+	s.changed = sync.NewCond(&s.mu)
+	return s
+}
+
+func (s *captureSender) connect(connID string) *inbox {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	box := &inbox{}
+	s.inboxes[connID] = box
+	delete(s.closed, connID)
+	return box
+}
+
+// encode упаковывает payload так же, как это делает сериализатор сервера
+func encode(msgType protocol.MessageType, payload proto.Message) *protocol.GameMessage {
+	data, err := proto.Marshal(payload)
+	if err != nil {
+		log.Printf("testharness: не удалось сериализовать %v: %v", msgType, err)
+	}
+	return &protocol.GameMessage{Type: msgType, Payload: data, Timestamp: time.Now().UnixNano()}
+}
+
+func (s *captureSender) SendToClient(connID string, msgType protocol.MessageType, payload proto.Message) {
+	msg := encode(msgType, payload)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if box, ok := s.inboxes[connID]; ok {
+		box.messages = append(box.messages, msg)
+		s.changed.Broadcast()
+	}
+}
+
+func (s *captureSender) Broadcast(msgType protocol.MessageType, payload proto.Message) {
+	msg := encode(msgType, payload)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sent = append(s.sent, msg)
+	for _, box := range s.inboxes {
+		box.messages = append(box.messages, msg)
+	}
+	s.changed.Broadcast()
+}
+
+func (s *captureSender) ConnectionIDs() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids := make([]string, 0, len(s.inboxes))
+	for connID := range s.inboxes {
+		ids = append(ids, connID)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func (s *captureSender) BindPlayer(connID string, playerID uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if box, ok := s.inboxes[connID]; ok {
+		box.playerID = playerID
+	}
+}
+
+// Disconnect отключает клиента по инициативе сервера: как TCP-сервер,
+// отправляет SERVER_MESSAGE с причиной и вызывает OnClientDisconnect
+func (s *captureSender) Disconnect(connID string, reason network.DisconnectReason) {
+	s.SendToClient(connID, protocol.MessageType_SERVER_MESSAGE, reason.ServerMessage())
+	s.close(connID)
+}
+
+// close закрывает соединение и вызывает OnClientDisconnect
+func (s *captureSender) close(connID string) {
+	s.mu.Lock()
+	_, exists := s.inboxes[connID]
+	delete(s.inboxes, connID)
+	if exists {
+		s.closed[connID] = true
+	}
+	s.changed.Broadcast()
+	s.mu.Unlock()
+
+	if exists && s.onDisconnect != nil {
+		s.onDisconnect(connID)
+	}
+}
+
+func (s *captureSender) disconnected(connID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed[connID]
+}
+
+func (s *captureSender) broadcasts() []*protocol.GameMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*protocol.GameMessage(nil), s.sent...)
+}
+
+// waitFor ждёт, пока match найдёт сообщение, не дольше WaitTimeout
+func (s *captureSender) waitFor(match func() *protocol.GameMessage) *protocol.GameMessage {
+	deadline := time.Now().Add(WaitTimeout)
+	timer := time.AfterFunc(WaitTimeout, func() {
+		s.mu.Lock()
+		s.changed.Broadcast()
+		s.mu.Unlock()
+	})
+	defer timer.Stop()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for {
+		if msg := match(); msg != nil {
+			return msg
+		}
+		if !time.Now().Before(deadline) {
+			return nil
+		}
+		s.changed.Wait()
+	}
+}
+
+// next возвращает первое непросмотренное сообщение типа msgType в inbox
+// и помечает просмотренными все сообщения до него включительно
+func (s *captureSender) next(box *inbox, msgType protocol.MessageType) *protocol.GameMessage {
+	return s.waitFor(func() *protocol.GameMessage {
+		for i := box.read; i < len(box.messages); i++ {
+			if box.messages[i].Type == msgType {
+				box.read = i + 1
+				return box.messages[i]
+			}
+		}
+		return nil
+	})
+}
+
+func (s *captureSender) waitBroadcast(msgType protocol.MessageType) *protocol.GameMessage {
+	return s.waitFor(func() *protocol.GameMessage {
+		for _, msg := range s.sent {
+			if msg.Type == msgType {
+				return msg
+			}
+		}
+		return nil
+	})
+}
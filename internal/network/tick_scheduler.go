@@ -0,0 +1,17 @@
+package network
+
+import "github.com/annel0/mmo-game/internal/world"
+
+// NetworkTickRate - частота обновления игрового обработчика (тиков в секунду)
+const NetworkTickRate = 20
+
+// newTickScheduler создаёт единые часы сервера: мир обновляется каждый тик,
+// игровой обработчик - с частотой NetworkTickRate, после фазы мира.
+func newTickScheduler(worldManager *world.WorldManager, gameHandler *GameHandlerPB) *world.TickScheduler {
+	scheduler := world.NewTickScheduler(world.DefaultTickRate)
+	scheduler.Register("world", 1, worldManager.Step)
+	scheduler.Register("network", scheduler.Rate()/NetworkTickRate, func(_ uint64, dt float64) {
+		gameHandler.Tick(dt)
+	})
+	return scheduler
+}
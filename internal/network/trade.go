@@ -0,0 +1,203 @@
+package network
+
+import (
+	"errors"
+	"log"
+	"sort"
+
+	"github.com/annel0/mmo-game/internal/protocol"
+	"github.com/annel0/mmo-game/internal/trade"
+	"github.com/annel0/mmo-game/internal/world/entity"
+)
+
+// MaxTradeDistance - максимальное расстояние между игроками при начале сделки
+const MaxTradeDistance = 3.0
+
+// payloadInventory - ключ Payload с инвентарём игрока (itemID -> количество)
+const payloadInventory = "inventory"
+
+// entityInventory адаптирует инвентарь из Payload сущности игрока к trade.Inventory
+type entityInventory map[string]interface{}
+
+func (inv entityInventory) Count(itemID string) int {
+	count, _ := payloadInt(inv[itemID])
+	return count
+}
+
+func (inv entityInventory) Add(itemID string, count int) {
+	inv[itemID] = inv.Count(itemID) + count
+}
+
+func (inv entityInventory) Remove(itemID string, count int) bool {
+	current := inv.Count(itemID)
+	if current < count {
+		return false
+	}
+	if current == count {
+		delete(inv, itemID)
+	} else {
+		inv[itemID] = current - count
+	}
+	return true
+}
+
+// playerInventory возвращает инвентарь сущности игрока для менеджера сделок
+func (gh *GameHandlerPB) playerInventory(playerID uint64) (trade.Inventory, bool) {
+	playerEntity, exists := gh.entityManager.GetEntity(playerID)
+	if !exists || playerEntity.Type != entity.EntityTypePlayer {
+		return nil, false
+	}
+	inventory, ok := playerEntity.Payload[payloadInventory].(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	return entityInventory(inventory), true
+}
+
+// handleTradeRequest обрабатывает действия игрока в сделке
+func (gh *GameHandlerPB) handleTradeRequest(connID string, msg *protocol.GameMessage) {
+	req := &protocol.TradeRequest{}
+	if err := gh.serializer.DeserializePayload(msg, req); err != nil {
+		log.Printf("Ошибка десериализации TradeRequest: %v", err)
+		gh.sendError(connID, protocol.ErrorCode_INVALID, msg.Type, "Malformed trade request")
+		return
+	}
+
+	gh.mu.RLock()
+	entityID, exists := gh.playerEntities[connID]
+	gh.mu.RUnlock()
+
+	if !exists {
+		gh.sendError(connID, protocol.ErrorCode_UNAUTHORIZED, msg.Type, "Not authorized")
+		return
+	}
+
+	var (
+		result trade.Trade
+		err    error
+	)
+	switch req.Action {
+	case protocol.TradeAction_TRADE_OPEN:
+		if req.TargetId == nil {
+			gh.sendError(connID, protocol.ErrorCode_INVALID, msg.Type, "Trade partner not specified")
+			return
+		}
+		if code, reason := gh.validateTradePartner(entityID, *req.TargetId); code != protocol.ErrorCode_ERROR_CODE_UNSPECIFIED {
+			gh.sendError(connID, code, msg.Type, reason)
+			return
+		}
+		result, err = gh.trades.Open(entityID, *req.TargetId)
+	case protocol.TradeAction_TRADE_OFFER:
+		result, err = gh.trades.Offer(entityID, req.ItemId, int(req.Count))
+	case protocol.TradeAction_TRADE_WITHDRAW:
+		result, err = gh.trades.Withdraw(entityID, req.ItemId, int(req.Count))
+	case protocol.TradeAction_TRADE_CONFIRM:
+		result, err = gh.trades.Confirm(entityID)
+	case protocol.TradeAction_TRADE_CANCEL:
+		result, err = gh.trades.Cancel(entityID, "cancelled")
+	default:
+		gh.sendError(connID, protocol.ErrorCode_INVALID, msg.Type, "Unknown trade action")
+		return
+	}
+
+	if err != nil {
+		log.Printf("⚠️ Сделка игрока %d отклонена: %v", entityID, err)
+		gh.sendError(connID, protocol.ErrorCode_INVALID, msg.Type, err.Error())
+		// Неудачный обмен сбрасывает подтверждения - сообщаем обеим сторонам
+		if !errors.Is(err, trade.ErrStackLimit) {
+			return
+		}
+	}
+
+	var out outbox
+	gh.mu.RLock()
+	gh.sendTradeUpdatesLocked(result, &out)
+	gh.mu.RUnlock()
+	gh.flush(&out)
+}
+
+// validateTradePartner проверяет, что партнёр - игрок в сети рядом с инициатором
+func (gh *GameHandlerPB) validateTradePartner(entityID, partnerID uint64) (protocol.ErrorCode, string) {
+	if gh.connIDForEntity(partnerID) == "" {
+		return protocol.ErrorCode_INVALID, "Trade partner is not online"
+	}
+
+	actor, actorExists := gh.entityManager.GetEntity(entityID)
+	partner, partnerExists := gh.entityManager.GetEntity(partnerID)
+	if !actorExists || !partnerExists {
+		return protocol.ErrorCode_INVALID, "Player entity not found"
+	}
+	if gh.calculateDistance(actor.Position, partner.Position) > MaxTradeDistance {
+		return protocol.ErrorCode_TOO_FAR, "Trade partner is too far"
+	}
+	return protocol.ErrorCode_ERROR_CODE_UNSPECIFIED, ""
+}
+
+// connIDForEntity возвращает соединение игрока по ID его сущности
+func (gh *GameHandlerPB) connIDForEntity(entityID uint64) string {
+	gh.mu.RLock()
+	defer gh.mu.RUnlock()
+	return gh.connIDForEntityLocked(entityID)
+}
+
+// connIDForEntityLocked - то же, что connIDForEntity; вызывается под gh.mu
+func (gh *GameHandlerPB) connIDForEntityLocked(entityID uint64) string {
+	for connID, eid := range gh.playerEntities {
+		if eid == entityID {
+			return connID
+		}
+	}
+	return ""
+}
+
+// sendTradeUpdatesLocked откладывает в out состояние сделки для обоих участников;
+// вызывается под gh.mu
+func (gh *GameHandlerPB) sendTradeUpdatesLocked(t trade.Trade, out *outbox) {
+	for side, playerID := range t.Parties {
+		connID := gh.connIDForEntityLocked(playerID)
+		if connID == "" {
+			continue
+		}
+		out.send(connID, protocol.MessageType_TRADE_UPDATE, &protocol.TradeUpdate{
+			TradeId:          t.ID,
+			Status:           tradeStatusToProto(t.Status),
+			PartnerId:        t.Partner(playerID),
+			MyOffer:          tradeItemsToProto(t.Offers[side]),
+			PartnerOffer:     tradeItemsToProto(t.Offers[1-side]),
+			MyConfirmed:      t.Confirmed[side],
+			PartnerConfirmed: t.Confirmed[1-side],
+			Reason:           t.Reason,
+		})
+	}
+}
+
+// cancelTradeOnDisconnectLocked возвращает предметы из эскроу отключившегося игрока
+// и его партнёра; вызывается под gh.mu до удаления сущности
+func (gh *GameHandlerPB) cancelTradeOnDisconnectLocked(entityID uint64, out *outbox) {
+	t, err := gh.trades.Cancel(entityID, trade.ReasonDisconnect)
+	if err != nil {
+		return
+	}
+	log.Printf("🤝 Сделка %s отменена: игрок %d отключился", t.ID, entityID)
+	gh.sendTradeUpdatesLocked(t, out)
+}
+
+func tradeStatusToProto(status trade.Status) protocol.TradeStatus {
+	switch status {
+	case trade.StatusCompleted:
+		return protocol.TradeStatus_TRADE_STATUS_COMPLETED
+	case trade.StatusCancelled:
+		return protocol.TradeStatus_TRADE_STATUS_CANCELLED
+	default:
+		return protocol.TradeStatus_TRADE_STATUS_OPEN
+	}
+}
+
+func tradeItemsToProto(items map[string]int) []*protocol.TradeItem {
+	result := make([]*protocol.TradeItem, 0, len(items))
+	for itemID, count := range items {
+		result = append(result, &protocol.TradeItem{ItemId: itemID, Count: int32(count)})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ItemId < result[j].ItemId })
+	return result
+}
@@ -0,0 +1,118 @@
+package network
+
+import (
+	"testing"
+
+	"github.com/annel0/mmo-game/internal/protocol"
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupTradePlayers создаёт двух игроков рядом друг с другом с предметами в инвентаре
+func setupTradePlayers(t *testing.T) (*GameHandlerPB, *testClient, *testClient) {
+	t.Helper()
+
+	gh := newTestGameHandler(t)
+	gh.entityManager.RegisterDefaultBehaviors()
+
+	alice := connectTestClient(t, gh, "conn-1")
+	bob := connectTestClient(t, gh, "conn-2")
+	addTestSession(gh, "conn-1", 1, 101, vec.Vec2{X: 0, Y: 0})
+	addTestSession(gh, "conn-2", 2, 102, vec.Vec2{X: 1, Y: 0})
+
+	inv, ok := gh.playerInventory(101)
+	require.True(t, ok)
+	inv.Add("sword", 1)
+	inv, ok = gh.playerInventory(102)
+	require.True(t, ok)
+	inv.Add("gold", 50)
+
+	return gh, alice, bob
+}
+
+func sendTrade(t *testing.T, gh *GameHandlerPB, connID string, req *protocol.TradeRequest) {
+	t.Helper()
+	gh.HandleMessage(connID, newGameMessage(t, protocol.MessageType_TRADE_REQUEST, req))
+}
+
+func inventoryCount(t *testing.T, gh *GameHandlerPB, playerID uint64, itemID string) int {
+	t.Helper()
+	inv, ok := gh.playerInventory(playerID)
+	require.True(t, ok)
+	return inv.Count(itemID)
+}
+
+func TestTrade_SwapBetweenPlayers(t *testing.T) {
+	gh, alice, bob := setupTradePlayers(t)
+
+	bobID := uint64(102)
+	sendTrade(t, gh, "conn-1", &protocol.TradeRequest{Action: protocol.TradeAction_TRADE_OPEN, TargetId: &bobID})
+	sendTrade(t, gh, "conn-1", &protocol.TradeRequest{Action: protocol.TradeAction_TRADE_OFFER, ItemId: "sword", Count: 1})
+	sendTrade(t, gh, "conn-2", &protocol.TradeRequest{Action: protocol.TradeAction_TRADE_OFFER, ItemId: "gold", Count: 30})
+	sendTrade(t, gh, "conn-1", &protocol.TradeRequest{Action: protocol.TradeAction_TRADE_CONFIRM})
+	sendTrade(t, gh, "conn-2", &protocol.TradeRequest{Action: protocol.TradeAction_TRADE_CONFIRM})
+
+	assert.Equal(t, 0, inventoryCount(t, gh, 101, "sword"))
+	assert.Equal(t, 30, inventoryCount(t, gh, 101, "gold"))
+	assert.Equal(t, 1, inventoryCount(t, gh, 102, "sword"))
+	assert.Equal(t, 20, inventoryCount(t, gh, 102, "gold"))
+
+	// Последнее обновление у обоих игроков - завершённая сделка
+	var update *protocol.TradeUpdate
+	for i := 0; i < 5; i++ {
+		update = &protocol.TradeUpdate{}
+		bob.expect(t, protocol.MessageType_TRADE_UPDATE, update)
+	}
+	assert.Equal(t, protocol.TradeStatus_TRADE_STATUS_COMPLETED, update.Status)
+	assert.Equal(t, uint64(101), update.PartnerId)
+	require.Len(t, update.PartnerOffer, 1)
+	assert.Equal(t, "sword", update.PartnerOffer[0].ItemId)
+	assert.Equal(t, int32(1), update.PartnerOffer[0].Count)
+
+	assert.Equal(t, 5, alice.drain(protocol.MessageType_TRADE_UPDATE))
+}
+
+func TestTrade_DisconnectReturnsStagedItems(t *testing.T) {
+	gh, alice, _ := setupTradePlayers(t)
+
+	bobID := uint64(102)
+	sendTrade(t, gh, "conn-1", &protocol.TradeRequest{Action: protocol.TradeAction_TRADE_OPEN, TargetId: &bobID})
+	sendTrade(t, gh, "conn-1", &protocol.TradeRequest{Action: protocol.TradeAction_TRADE_OFFER, ItemId: "sword", Count: 1})
+	sendTrade(t, gh, "conn-2", &protocol.TradeRequest{Action: protocol.TradeAction_TRADE_OFFER, ItemId: "gold", Count: 50})
+	sendTrade(t, gh, "conn-1", &protocol.TradeRequest{Action: protocol.TradeAction_TRADE_CONFIRM})
+
+	bobInventory, ok := gh.playerInventory(102)
+	require.True(t, ok)
+	assert.Equal(t, 0, bobInventory.Count("gold"), "предметы в эскроу изъяты из инвентаря")
+
+	gh.OnClientDisconnect("conn-2")
+
+	assert.Equal(t, 1, inventoryCount(t, gh, 101, "sword"))
+	assert.Equal(t, 50, bobInventory.Count("gold"), "предметы возвращаются до удаления сущности")
+	_, active := gh.trades.Active(101)
+	assert.False(t, active)
+
+	var update *protocol.TradeUpdate
+	for i := 0; i < 5; i++ {
+		update = &protocol.TradeUpdate{}
+		alice.expect(t, protocol.MessageType_TRADE_UPDATE, update)
+	}
+	assert.Equal(t, protocol.TradeStatus_TRADE_STATUS_CANCELLED, update.Status)
+	assert.Equal(t, "disconnect", update.Reason)
+}
+
+func TestTrade_RejectsDistantPartner(t *testing.T) {
+	gh, alice, _ := setupTradePlayers(t)
+	addTestSession(gh, "conn-3", 3, 103, vec.Vec2{X: 50, Y: 50})
+
+	farID := uint64(103)
+	sendTrade(t, gh, "conn-1", &protocol.TradeRequest{Action: protocol.TradeAction_TRADE_OPEN, TargetId: &farID})
+
+	errMsg := &protocol.ErrorMessage{}
+	alice.expect(t, protocol.MessageType_ERROR, errMsg)
+	assert.Equal(t, protocol.ErrorCode_TOO_FAR, errMsg.Code)
+	assert.Equal(t, protocol.MessageType_TRADE_REQUEST, errMsg.RefType)
+	_, active := gh.trades.Active(101)
+	assert.False(t, active)
+}
@@ -7,6 +7,7 @@ import (
 	"log"
 	"net"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/annel0/mmo-game/internal/logging"
@@ -24,6 +25,7 @@ type UDPServerPB struct {
 	cancel       context.CancelFunc
 	serializer   *protocol.MessageSerializer
 	gameHandler  *GameHandlerPB
+	staleMoves   atomic.Uint64 // отброшенные устаревшие пакеты перемещения
 }
 
 // UDPClientPB представляет клиента, подключенного через UDP
@@ -32,6 +34,10 @@ type UDPClientPB struct {
 	addr     *net.UDPAddr
 	playerID uint64
 	lastSeen time.Time
+
+	moveMu       sync.Mutex   // упорядочивает обработку пакетов перемещения клиента
+	moves        moveSequence // последовательность принятых пакетов перемещения
+	sendSequence uint32       // номер последнего отправленного клиенту пакета с подтверждением
 }
 
 // NewUDPServerPB создает новый UDP сервер с поддержкой Protocol Buffers
@@ -117,8 +123,12 @@ func (s *UDPServerPB) readLoop() {
 			}
 			s.mu.Unlock()
 
+			// Копируем пакет: буфер чтения переиспользуется следующей итерацией
+			packet := make([]byte, n-8)
+			copy(packet, buffer[8:n])
+
 			// Обрабатываем пакет асинхронно
-			go s.handlePacket(client, buffer[8:n])
+			go s.handlePacket(client, packet)
 		}
 	}
 }
@@ -262,15 +272,70 @@ func (s *UDPServerPB) handleEntityMove(client *UDPClientPB, msg *protocol.GameMe
 			return
 		}
 
+		// Пакеты обрабатываются по одному, чтобы более старый не применился после более нового
+		client.moveMu.Lock()
+		defer client.moveMu.Unlock()
+
+		// Пакеты без номера (старые клиенты) применяются как есть
+		if msg.Sequence != 0 && !client.moves.accept(msg.Sequence) {
+			s.staleMoves.Add(1)
+			logging.Debug("UDP: отброшен устаревший ENTITY_MOVE #%d от игрока %d (последний #%d)",
+				msg.Sequence, client.playerID, client.moves.latest)
+			return
+		}
+
 		logging.Debug("Перенаправление ENTITY_MOVE от игрока %d в GameHandler (connID: %s)", client.playerID, connID)
 		// Передаем сообщение в обработчик игры
 		s.gameHandler.HandleMessage(connID, msg)
+
+		if msg.Sequence != 0 {
+			s.sendMoveAck(client, connID)
+		}
 	} else {
 		logging.Error("GameHandler не инициализирован, пропуск обработки движения")
 		log.Printf("GameHandler не инициализирован, пропуск обработки движения")
 	}
 }
 
+// sendMoveAck подтверждает клиенту последний обработанный пакет перемещения.
+// В ответе - серверная позиция сущности игрока, по которой клиент сверяет предсказание.
+// Вызывается под client.moveMu.
+func (s *UDPServerPB) sendMoveAck(client *UDPClientPB, connID string) {
+	ent, exists := s.gameHandler.entityManager.GetEntity(client.playerID)
+	if !exists {
+		return
+	}
+
+	moveMsg := s.gameHandler.stampMove(&protocol.EntityMoveMessage{
+		Entities: []*protocol.EntityData{s.gameHandler.ownerEntityData(connID, ent)},
+	})
+
+	client.sendSequence++
+	data, err := s.serializer.SerializeSequencedMessage(protocol.MessageType_ENTITY_MOVE, moveMsg,
+		client.sendSequence, client.moves.latest, client.moves.ackBits)
+	if err != nil {
+		logging.LogProtocolError("UDP ENTITY_MOVE ack serialization", err, nil)
+		return
+	}
+
+	// Создаем заголовок с ID игрока
+	header := make([]byte, 8)
+	binary.BigEndian.PutUint64(header, client.playerID)
+
+	s.mu.RLock()
+	addr := client.addr
+	s.mu.RUnlock()
+
+	if _, err := s.conn.WriteToUDP(append(header, data...), addr); err != nil {
+		logging.Error("Ошибка отправки подтверждения перемещения игроку %d: %v", client.playerID, err)
+	}
+}
+
+// StaleMovesDropped возвращает число отброшенных устаревших пакетов перемещения
+func (s *UDPServerPB) StaleMovesDropped() uint64 {
+	return s.staleMoves.Load()
+}
+
 // findConnectionIDByPlayerID находит ID соединения по ID игрока
 func (s *UDPServerPB) findConnectionIDByPlayerID(playerID uint64) string {
 	if s.gameHandler == nil {
@@ -332,6 +397,9 @@ func (s *UDPServerPB) SendEntityUpdatesPB(playerID uint64, entities []world.Enti
 	moveMessage := &protocol.EntityMoveMessage{
 		Entities: entityDataList,
 	}
+	if s.gameHandler != nil {
+		s.gameHandler.stampMove(moveMessage)
+	}
 
 	// Сериализуем сообщение для отправки
 	data, err := s.serializer.SerializeMessage(protocol.MessageType_ENTITY_MOVE, moveMessage)
@@ -0,0 +1,93 @@
+package network
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/protocol"
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestMoveSequence_Accept(t *testing.T) {
+	var seq moveSequence
+
+	assert.True(t, seq.accept(10))
+	assert.True(t, seq.accept(12))
+	assert.False(t, seq.accept(11), "опоздавший пакет отбрасывается")
+	assert.False(t, seq.accept(12), "повторный пакет отбрасывается")
+	assert.Equal(t, uint32(12), seq.latest)
+	assert.Equal(t, uint32(0b10), seq.ackBits, "подтверждён только #10")
+
+	// Переполнение счётчика: 2 новее, чем 0xFFFFFFFE
+	seq = moveSequence{}
+	assert.True(t, seq.accept(0xFFFFFFFE))
+	assert.True(t, seq.accept(2))
+	assert.False(t, seq.accept(0xFFFFFFFF))
+}
+
+func TestUDPServer_DiscardsOutOfOrderMoves(t *testing.T) {
+	gh := newTestGameHandler(t)
+	addTestSession(gh, "conn-1", 1, 1, vec.Vec2{X: 0, Y: 0})
+
+	server, err := NewUDPServerPB("127.0.0.1:0", gh.worldManager)
+	require.NoError(t, err)
+	defer server.Stop()
+	server.SetGameHandler(gh)
+
+	// Сокет клиента принимает подтверждения сервера
+	clientConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+	defer clientConn.Close()
+
+	client := &UDPClientPB{id: 1, addr: clientConn.LocalAddr().(*net.UDPAddr), playerID: 1, lastSeen: time.Now()}
+	server.clients[client.id] = client
+
+	move := func(seq uint32, x int32) {
+		msg := newGameMessage(t, protocol.MessageType_ENTITY_MOVE, &protocol.EntityMoveMessage{
+			Entities: []*protocol.EntityData{{Id: 1, Position: &protocol.Vec2{X: x, Y: 0}}},
+		})
+		msg.Sequence = seq
+		data, err := proto.Marshal(msg)
+		require.NoError(t, err)
+		server.handlePacket(client, data)
+	}
+
+	// #2 приходит раньше #1 и #3 позже #4: опоздавшие пакеты не должны откатывать позицию
+	move(2, 2)
+	move(1, 1)
+	move(4, 4)
+	move(3, 3)
+
+	ent, ok := gh.entityManager.GetEntity(1)
+	require.True(t, ok)
+	assert.Equal(t, vec.Vec2{X: 4, Y: 0}, ent.Position)
+	assert.Equal(t, uint64(2), server.StaleMovesDropped())
+
+	// Последнее подтверждение относится к #4 и отмечает обработанный #2
+	var last *protocol.GameMessage
+	buf := make([]byte, 2048)
+	require.NoError(t, clientConn.SetReadDeadline(time.Now().Add(2*time.Second)))
+	for i := 0; i < 2; i++ {
+		n, _, err := clientConn.ReadFromUDP(buf)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(1), binary.BigEndian.Uint64(buf[:8]))
+
+		last = &protocol.GameMessage{}
+		require.NoError(t, proto.Unmarshal(buf[8:n], last))
+	}
+	require.NotNil(t, last.Ack)
+	assert.Equal(t, uint32(4), last.GetAck())
+	assert.Equal(t, uint32(0b10), last.GetAckBits())
+	assert.Equal(t, uint32(2), last.Sequence)
+
+	update := &protocol.EntityMoveMessage{}
+	require.NoError(t, proto.Unmarshal(last.Payload, update))
+	require.Len(t, update.Entities, 1)
+	assert.Equal(t, int32(4), update.Entities[0].Position.X)
+	assert.Equal(t, uint32(4), update.Entities[0].GetLastProcessedInput())
+}
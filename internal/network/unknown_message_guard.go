@@ -0,0 +1,51 @@
+package network
+
+import (
+	"log"
+
+	"github.com/annel0/mmo-game/internal/protocol"
+)
+
+// DefaultMaxUnknownMessages - сколько сообщений неизвестного типа допускается до
+// отключения: поток таких сообщений означает несовместимый протокол или атаку
+const DefaultMaxUnknownMessages = 10
+
+// SetMaxUnknownMessages задаёт, после скольких сообщений неизвестного типа
+// соединение закрывается (0 - не отключать).
+func (gh *GameHandlerPB) SetMaxUnknownMessages(limit int) {
+	gh.mu.Lock()
+	gh.maxUnknownMessages = limit
+	gh.mu.Unlock()
+}
+
+// rejectUnknown учитывает сообщение неизвестного типа. Об ошибке протокола
+// клиент узнаёт один раз, при повторных нарушениях соединение закрывается
+func (gh *GameHandlerPB) rejectUnknown(connID string, msgType protocol.MessageType) {
+	gh.mu.Lock()
+	gh.unknownMessages[connID]++
+	count := gh.unknownMessages[connID]
+	limit := gh.maxUnknownMessages
+	gh.mu.Unlock()
+
+	log.Printf("Неизвестный тип сообщения %d от %s, нарушений: %d", msgType, connID, count)
+
+	if count == 1 {
+		gh.sendError(connID, protocol.ErrorCode_INVALID, msgType, "Неизвестный тип сообщения")
+	}
+
+	if limit > 0 && count >= limit {
+		log.Printf("🚫 Отключение %s: превышен лимит сообщений неизвестного типа", connID)
+		gh.disconnectClient(connID, DisconnectReason{
+			Code:    ServerMessageProtocolViolation,
+			Message: "Отключено: слишком много сообщений неизвестного типа",
+			Despawn: protocol.DespawnReason_DESPAWN_REASON_KICKED,
+		})
+	}
+}
+
+// UnknownMessageCount возвращает число сообщений неизвестного типа от соединения
+func (gh *GameHandlerPB) UnknownMessageCount(connID string) int {
+	gh.mu.RLock()
+	defer gh.mu.RUnlock()
+	return gh.unknownMessages[connID]
+}
@@ -0,0 +1,53 @@
+package network
+
+const (
+	// DefaultViewDistance - дальность видимости в чанках, если клиент её не запросил
+	DefaultViewDistance = 5
+	// DefaultMaxViewDistance - верхняя граница дальности видимости по умолчанию
+	DefaultMaxViewDistance = 8
+
+	// entityRangePerChunk - радиус видимости сущностей (в блоках) на один чанк дальности.
+	// При дальности по умолчанию даёт прежние 100 блоков.
+	entityRangePerChunk = 20.0
+)
+
+// SetMaxViewDistance ограничивает дальность видимости, которую могут запросить клиенты.
+// Значения меньше 1 заменяются DefaultMaxViewDistance.
+func (gh *GameHandlerPB) SetMaxViewDistance(maxDistance int) {
+	if maxDistance < 1 {
+		maxDistance = DefaultMaxViewDistance
+	}
+
+	gh.mu.Lock()
+	gh.maxViewDistance = maxDistance
+	gh.mu.Unlock()
+}
+
+// resolveViewDistance приводит запрошенную клиентом дальность к допустимому диапазону
+func (gh *GameHandlerPB) resolveViewDistance(requested uint32) int {
+	gh.mu.RLock()
+	maxDistance := gh.maxViewDistance
+	gh.mu.RUnlock()
+
+	distance := int(requested)
+	if requested == 0 {
+		distance = DefaultViewDistance
+	}
+	return max(1, min(distance, maxDistance))
+}
+
+// viewDistanceFor возвращает дальность видимости игрока в чанках
+func (gh *GameHandlerPB) viewDistanceFor(connID string) int {
+	gh.mu.RLock()
+	defer gh.mu.RUnlock()
+
+	if session, ok := gh.sessions[connID]; ok && session.ViewDistance > 0 {
+		return session.ViewDistance
+	}
+	return min(DefaultViewDistance, gh.maxViewDistance)
+}
+
+// entityViewRange возвращает радиус (в блоках), в котором игрок видит сущности
+func entityViewRange(viewDistance int) float64 {
+	return float64(viewDistance) * entityRangePerChunk
+}
@@ -0,0 +1,130 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/annel0/mmo-game/internal/protocol"
+	"github.com/annel0/mmo-game/internal/world/entity"
+)
+
+// ErrStealthNotAdmin - невидимость доступна только администраторам
+var ErrStealthNotAdmin = errors.New("невидимость доступна только администраторам")
+
+// payloadHidden - флаг Payload, скрывающий сущность от обычных игроков
+const payloadHidden = "hidden"
+
+// SetEntityHidden скрывает сущность от игроков без права видеть скрытое
+// (например, замаскированного NPC) или снова делает её видимой
+func (gh *GameHandlerPB) SetEntityHidden(entityID uint64, hidden bool) error {
+	e, exists := gh.entityManager.GetEntity(entityID)
+	if !exists {
+		return fmt.Errorf("сущность %d не найдена", entityID)
+	}
+
+	gh.effectsMu.Lock()
+	if hidden {
+		e.Payload[payloadHidden] = true
+	} else {
+		delete(e.Payload, payloadHidden)
+	}
+	gh.effectsMu.Unlock()
+
+	if hidden {
+		gh.despawnForRegularViewers(entityID)
+	}
+	return nil
+}
+
+// SetStealth включает режим невидимости администратора: его сущность не
+// попадает в обновления мира обычных игроков. Состояние хранится в сессии
+func (gh *GameHandlerPB) SetStealth(connID string, enabled bool) error {
+	gh.mu.Lock()
+	session, exists := gh.sessions[connID]
+	if !exists {
+		gh.mu.Unlock()
+		return fmt.Errorf("сессия %s не найдена", connID)
+	}
+	if enabled && !session.IsAdmin {
+		gh.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrStealthNotAdmin, session.Username)
+	}
+	changed := session.Stealth != enabled
+	session.Stealth = enabled
+	entityID := session.EntityID
+	gh.mu.Unlock()
+
+	if enabled && changed {
+		gh.despawnForRegularViewers(entityID)
+	}
+	return nil
+}
+
+// SetPlayerStealth включает или выключает невидимость администратора,
+// находящегося в сети, по имени пользователя
+func (gh *GameHandlerPB) SetPlayerStealth(username string, enabled bool) error {
+	connID, err := gh.connIDForUser(username)
+	if err != nil {
+		return err
+	}
+	return gh.SetStealth(connID, enabled)
+}
+
+// entityVisibility - снимок правил видимости на одну рассылку обновлений
+type entityVisibility struct {
+	privileged map[string]bool // Соединения, которым видны все сущности (администраторы)
+	stealthed  map[uint64]bool // Сущности администраторов в режиме невидимости или наблюдателя
+}
+
+// visibilitySnapshot собирает правила видимости из текущих сессий
+func (gh *GameHandlerPB) visibilitySnapshot() entityVisibility {
+	gh.mu.RLock()
+	defer gh.mu.RUnlock()
+
+	v := entityVisibility{
+		privileged: make(map[string]bool),
+		stealthed:  make(map[uint64]bool),
+	}
+	for connID, session := range gh.sessions {
+		if session.IsAdmin {
+			v.privileged[connID] = true
+		}
+		if session.Stealth || session.Spectator {
+			v.stealthed[session.EntityID] = true
+		}
+	}
+	return v
+}
+
+// canSee решает, попадает ли сущность в список видимых для зрителя.
+// Администраторы видят всех, игрок всегда видит собственную сущность
+func (gh *GameHandlerPB) canSee(v entityVisibility, viewerConnID string, viewerEntityID uint64, e *entity.Entity) bool {
+	if e.ID == viewerEntityID || v.privileged[viewerConnID] {
+		return true
+	}
+	if v.stealthed[e.ID] {
+		return false
+	}
+
+	gh.effectsMu.Lock()
+	defer gh.effectsMu.Unlock()
+	return !e.Hidden()
+}
+
+// despawnForRegularViewers убирает только что скрытую сущность у обычных игроков,
+// иначе она осталась бы у них на последней известной позиции
+func (gh *GameHandlerPB) despawnForRegularViewers(entityID uint64) {
+	gh.mu.RLock()
+	var viewers []string
+	for connID, session := range gh.sessions {
+		if !session.IsAdmin && session.EntityID != entityID {
+			viewers = append(viewers, connID)
+		}
+	}
+	gh.mu.RUnlock()
+
+	for _, connID := range viewers {
+		gh.sendTCPMessage(connID, protocol.MessageType_ENTITY_DESPAWN,
+			protocol.NewEntityDespawnMessage(entityID, protocol.DespawnReason_DESPAWN_REASON_OUT_OF_VIEW))
+	}
+}
@@ -0,0 +1,115 @@
+package network
+
+import (
+	"testing"
+
+	"github.com/annel0/mmo-game/internal/protocol"
+	"github.com/annel0/mmo-game/internal/world/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// visibleEntityIDs запускает рассылку обновлений мира и возвращает ID сущностей,
+// полученных клиентом
+func visibleEntityIDs(t *testing.T, gh *GameHandlerPB, client *testClient) []uint64 {
+	t.Helper()
+
+	client.drain(protocol.MessageType_ENTITY_MOVE)
+	gh.sendWorldUpdates()
+
+	update := &protocol.EntityMoveMessage{}
+	client.expect(t, protocol.MessageType_ENTITY_MOVE, update)
+	ids := make([]uint64, 0, len(update.Entities))
+	for _, e := range update.Entities {
+		ids = append(ids, e.Id)
+	}
+	return ids
+}
+
+// setupViewers подключает администратора conn-admin и обычного игрока conn-player
+func setupViewers(t *testing.T, gh *GameHandlerPB) (admin, player *testClient) {
+	t.Helper()
+
+	admin = connectTestClient(t, gh, "conn-admin")
+	authTestClient(t, gh, admin)
+	player = connectTestClient(t, gh, "conn-player")
+	authTestClient(t, gh, player)
+
+	gh.mu.Lock()
+	gh.sessions["conn-player"].IsAdmin = false
+	gh.mu.Unlock()
+	return admin, player
+}
+
+func TestVisibility_HiddenEntityOnlyForAdmins(t *testing.T) {
+	gh := newTestGameHandler(t)
+	admin, player := setupViewers(t, gh)
+
+	pos := playerEntityFor(t, gh, "conn-player").Position
+	npcID := gh.SpawnEntity(entity.EntityTypeNPC, pos)
+	require.NoError(t, gh.SetEntityHidden(npcID, true))
+
+	assert.NotContains(t, visibleEntityIDs(t, gh, player), npcID, "скрытая сущность не видна обычному игроку")
+	assert.Contains(t, visibleEntityIDs(t, gh, admin), npcID, "администратор видит скрытые сущности")
+
+	require.NoError(t, gh.SetEntityHidden(npcID, false))
+	assert.Contains(t, visibleEntityIDs(t, gh, player), npcID)
+}
+
+func TestVisibility_StealthAdminHiddenFromPlayers(t *testing.T) {
+	gh := newTestGameHandler(t)
+	admin, player := setupViewers(t, gh)
+	adminEntity := playerEntityFor(t, gh, "conn-admin").ID
+	// Видимый NPC рядом, чтобы обновление мира не было пустым
+	gh.SpawnEntity(entity.EntityTypeNPC, playerEntityFor(t, gh, "conn-player").Position)
+
+	// Обычный игрок не может включить невидимость
+	assert.Error(t, gh.SetStealth("conn-player", true))
+	assert.Error(t, gh.SetStealth("conn-unknown", true))
+
+	require.NoError(t, gh.SetStealth("conn-admin", true))
+	despawn := &protocol.EntityDespawnMessage{}
+	player.expect(t, protocol.MessageType_ENTITY_DESPAWN, despawn)
+	assert.Equal(t, adminEntity, despawn.EntityId)
+
+	gh.mu.RLock()
+	assert.True(t, gh.sessions["conn-admin"].Stealth, "состояние хранится в сессии")
+	gh.mu.RUnlock()
+
+	ids := visibleEntityIDs(t, gh, player)
+	assert.NotContains(t, ids, adminEntity)
+
+	// Невидимый администратор по-прежнему видит игрока
+	assert.Contains(t, visibleEntityIDs(t, gh, admin), playerEntityFor(t, gh, "conn-player").ID)
+
+	require.NoError(t, gh.SetStealth("conn-admin", false))
+	assert.Contains(t, visibleEntityIDs(t, gh, player), adminEntity)
+}
+
+func TestVisibility_InitialWorldDataSkipsHidden(t *testing.T) {
+	gh := newTestGameHandler(t)
+	admin, player := setupViewers(t, gh)
+	require.NoError(t, gh.SetStealth("conn-admin", true))
+	adminEntity := playerEntityFor(t, gh, "conn-admin").ID
+
+	pos := playerEntityFor(t, gh, "conn-player").Position
+	hiddenID := gh.SpawnEntity(entity.EntityTypeNPC, pos)
+	require.NoError(t, gh.SetEntityHidden(hiddenID, true))
+	visibleID := gh.SpawnEntity(entity.EntityTypeNPC, pos)
+
+	player.drain(protocol.MessageType_ENTITY_MOVE)
+	gh.sendWorldDataToPlayer("conn-player", playerEntityFor(t, gh, "conn-player").ID)
+
+	spawned := &protocol.EntityMoveMessage{}
+	player.expect(t, protocol.MessageType_ENTITY_MOVE, spawned)
+	ids := make([]uint64, 0, len(spawned.Entities))
+	for _, e := range spawned.Entities {
+		ids = append(ids, e.Id)
+	}
+	assert.Equal(t, []uint64{visibleID}, ids)
+
+	admin.drain(protocol.MessageType_ENTITY_MOVE)
+	gh.sendWorldDataToPlayer("conn-admin", adminEntity)
+	admin.expect(t, protocol.MessageType_ENTITY_MOVE, spawned)
+	assert.Len(t, spawned.Entities, 3, "администратор видит игрока и обоих NPC")
+}
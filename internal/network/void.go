@@ -0,0 +1,143 @@
+package network
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/annel0/mmo-game/internal/world"
+	"github.com/annel0/mmo-game/internal/world/block"
+	"github.com/annel0/mmo-game/internal/world/entity"
+)
+
+// VoidPolicy - что происходит с игроком, оказавшимся над пропастью (воздух
+// и на ACTIVE, и на FLOOR). Обычным движением туда не попасть, но игрока
+// может оставить над пропастью разрушенный под ним пол или телепорт
+type VoidPolicy string
+
+const (
+	VoidPolicyNone   VoidPolicy = "none"   // Игрок остаётся на месте
+	VoidPolicySpawn  VoidPolicy = "spawn"  // Игрок сразу возвращается на точку спавна
+	VoidPolicyDamage VoidPolicy = "damage" // Урон падения каждую секунду, на спавн - при низком здоровье
+)
+
+const (
+	// DefaultVoidPolicy - обработка пропасти, если в конфигурации не указана другая
+	DefaultVoidPolicy = VoidPolicySpawn
+
+	// DefaultVoidFallDamage - урон за секунду над пропастью для VoidPolicyDamage
+	DefaultVoidFallDamage = 5
+)
+
+// ParseVoidPolicy разбирает название политики; пустая строка - DefaultVoidPolicy
+func ParseVoidPolicy(name string) (VoidPolicy, error) {
+	switch policy := VoidPolicy(name); policy {
+	case "":
+		return DefaultVoidPolicy, nil
+	case VoidPolicyNone, VoidPolicySpawn, VoidPolicyDamage:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("неизвестная политика пропасти %q", name)
+	}
+}
+
+// VoidRules - параметры обработки игроков над пропастью
+type VoidRules struct {
+	Policy VoidPolicy // "" - DefaultVoidPolicy
+
+	// Только для VoidPolicyDamage
+	FallDamage  int // Урон за секунду над пропастью (0 - DefaultVoidFallDamage)
+	SpawnHealth int // Игрок возвращается на спавн, когда здоровье падает до этого значения
+}
+
+// DefaultVoidRules возвращает параметры обработки пропасти по умолчанию
+func DefaultVoidRules() VoidRules {
+	return VoidRules{Policy: DefaultVoidPolicy, FallDamage: DefaultVoidFallDamage}
+}
+
+// SetVoidRules задаёт обработку игроков над пропастью
+func (gh *GameHandlerPB) SetVoidRules(rules VoidRules) error {
+	policy, err := ParseVoidPolicy(string(rules.Policy))
+	if err != nil {
+		return err
+	}
+	rules.Policy = policy
+	if rules.FallDamage <= 0 {
+		rules.FallDamage = DefaultVoidFallDamage
+	}
+
+	gh.mu.Lock()
+	gh.voidRules = rules
+	gh.mu.Unlock()
+	return nil
+}
+
+// isVoid сообщает, что под позицией нет опоры: воздух на ACTIVE и на FLOOR
+func (gh *GameHandlerPB) isVoid(pos vec.Vec2) bool {
+	return gh.worldManager.GetBlockLayer(pos, world.LayerActive).ID == block.AirBlockID &&
+		gh.worldManager.GetBlockLayer(pos, world.LayerFloor).ID == block.AirBlockID
+}
+
+// checkVoidPlayers применяет политику пропасти к игрокам без опоры под ногами.
+// Наблюдатели и погибшие игроки не затрагиваются
+func (gh *GameHandlerPB) checkVoidPlayers() {
+	type player struct {
+		connID   string
+		entityID uint64
+	}
+
+	gh.mu.RLock()
+	rules := gh.voidRules
+	var players []player
+	if rules.Policy != VoidPolicyNone {
+		for connID, session := range gh.sessions {
+			if !session.Spectator {
+				players = append(players, player{connID: connID, entityID: session.EntityID})
+			}
+		}
+	}
+	gh.mu.RUnlock()
+
+	for _, p := range players {
+		e, exists := gh.entityManager.GetEntity(p.entityID)
+		if !exists || !e.Active || !gh.isVoid(e.Position) {
+			continue
+		}
+
+		if rules.Policy == VoidPolicyDamage && !gh.applyFallDamage(e, rules) {
+			gh.sendPlayerStats(e.ID)
+			continue
+		}
+		gh.returnToSpawn(p.connID, e)
+	}
+}
+
+// applyFallDamage наносит урон падения. Возвращает true, если игрока пора
+// вернуть на спавн; тогда здоровье восстанавливается полностью
+func (gh *GameHandlerPB) applyFallDamage(e *entity.Entity, rules VoidRules) bool {
+	gh.effectsMu.Lock()
+	defer gh.effectsMu.Unlock()
+
+	health, ok := payloadInt(e.Payload[payloadHealth])
+	if !ok {
+		return true // Без здоровья урон не на что наносить
+	}
+	health -= rules.FallDamage
+	if health > rules.SpawnHealth {
+		e.Payload[payloadHealth] = health
+		return false
+	}
+	if maxHealth, ok := payloadInt(e.Payload[payloadMaxHealth]); ok && maxHealth > 0 {
+		e.Payload[payloadHealth] = maxHealth
+	}
+	return true
+}
+
+// returnToSpawn переносит игрока на точку спавна и рассылает новую позицию.
+// Чанки вокруг спавна отправляются так же, как при телепорте (relocatePlayer)
+func (gh *GameHandlerPB) returnToSpawn(connID string, e *entity.Entity) {
+	from := e.Position
+	gh.relocatePlayer(connID, e, gh.GetDefaultSpawnPosition().ToVec2())
+	log.Printf("🕳️ Игрок %s над пропастью в (%d, %d), возвращён на спавн", connID, from.X, from.Y)
+	gh.sendPlayerStats(e.ID)
+}
@@ -0,0 +1,136 @@
+package network
+
+import (
+	"testing"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/protocol"
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/annel0/mmo-game/internal/world"
+	"github.com/annel0/mmo-game/internal/world/block"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+// digPit убирает пол и поверхность под позицией
+func digPit(gh *GameHandlerPB, pos vec.Vec2) {
+	gh.worldManager.SetBlockLayer(pos, world.LayerActive, world.NewBlock(block.AirBlockID))
+	gh.worldManager.SetBlockLayer(pos, world.LayerFloor, world.NewBlock(block.AirBlockID))
+}
+
+// playerOverPit добавляет игрока со здоровьем 100, стоящего над пропастью
+func playerOverPit(t *testing.T, gh *GameHandlerPB, connID string, entityID uint64) *testClient {
+	t.Helper()
+	pit := vec.Vec2{X: 40, Y: 40}
+	digPit(gh, pit)
+
+	client := connectTestClient(t, gh, connID)
+	addTestSession(gh, connID, entityID, entityID, pit)
+	e, ok := gh.entityManager.GetEntity(entityID)
+	require.True(t, ok)
+	e.Payload[payloadHealth] = 100
+	e.Payload[payloadMaxHealth] = 100
+	require.True(t, gh.isVoid(e.Position))
+	return client
+}
+
+func TestVoid_SpawnPolicyTeleportsAndBroadcasts(t *testing.T) {
+	gh := newTestGameHandler(t)
+	require.NoError(t, gh.SetVoidRules(VoidRules{Policy: VoidPolicySpawn}))
+	client := playerOverPit(t, gh, "faller", 10)
+	observer := connectTestClient(t, gh, "observer")
+	addTestSession(gh, "observer", 11, 11, vec.Vec2{})
+
+	gh.checkVoidPlayers()
+
+	e, _ := gh.entityManager.GetEntity(10)
+	spawn := gh.GetDefaultSpawnPosition().ToVec2()
+	assert.Equal(t, spawn, e.Position)
+
+	// Владелец получает корректировку, остальные - новую позицию
+	correction := &protocol.EntityMoveMessage{}
+	client.expect(t, protocol.MessageType_ENTITY_MOVE, correction)
+	require.Len(t, correction.Entities, 1)
+	assert.Equal(t, int32(spawn.X), correction.Entities[0].Position.X)
+	assert.Equal(t, int32(spawn.Y), correction.Entities[0].Position.Y)
+
+	moved := &protocol.EntityMoveMessage{}
+	observer.expect(t, protocol.MessageType_ENTITY_MOVE, moved)
+	require.Len(t, moved.Entities, 1)
+	assert.Equal(t, uint64(10), moved.Entities[0].Id)
+}
+
+func TestVoid_ReturnToSpawnStreamsSpawnChunks(t *testing.T) {
+	gh := newTestGameHandler(t)
+	require.NoError(t, gh.SetVoidRules(VoidRules{Policy: VoidPolicySpawn}))
+	client := playerOverPit(t, gh, "faller", 10)
+	spawnChunk := gh.GetDefaultSpawnPosition().ToVec2().ToChunkCoords()
+
+	gh.checkVoidPlayers()
+
+	// Чанк спавна приходит до коррекции позиции, как при телепорте
+	sawSpawnChunk := false
+	timeout := time.After(5 * time.Second)
+	for {
+		var msg *protocol.GameMessage
+		select {
+		case msg = <-client.messages:
+		case <-timeout:
+			t.Fatal("коррекция позиции не получена")
+		}
+		if msg.Type == protocol.MessageType_CHUNK_DATA {
+			chunk := &protocol.ChunkData{}
+			require.NoError(t, proto.Unmarshal(msg.Payload, chunk))
+			sawSpawnChunk = sawSpawnChunk || (int(chunk.ChunkX) == spawnChunk.X && int(chunk.ChunkY) == spawnChunk.Y)
+		}
+		if msg.Type == protocol.MessageType_ENTITY_MOVE {
+			break
+		}
+	}
+	assert.True(t, sawSpawnChunk)
+
+	// Остальная зона видимости вокруг спавна досылается фоном
+	assert.Positive(t, client.drain(protocol.MessageType_CHUNK_DATA))
+}
+
+func TestVoid_DamagePolicyHurtsThenTeleports(t *testing.T) {
+	gh := newTestGameHandler(t)
+	require.NoError(t, gh.SetVoidRules(VoidRules{Policy: VoidPolicyDamage, FallDamage: 30, SpawnHealth: 20}))
+	playerOverPit(t, gh, "faller", 10)
+	e, _ := gh.entityManager.GetEntity(10)
+	pit := e.Position
+
+	gh.checkVoidPlayers()
+	assert.Equal(t, 70, e.Payload[payloadHealth])
+	gh.checkVoidPlayers()
+	assert.Equal(t, 40, e.Payload[payloadHealth])
+	assert.Equal(t, pit, e.Position, "пока здоровья достаточно, игрок остаётся на месте")
+
+	// Здоровье упало до порога: возврат на спавн с полным здоровьем
+	gh.checkVoidPlayers()
+	assert.Equal(t, gh.GetDefaultSpawnPosition().ToVec2(), e.Position)
+	assert.Equal(t, 100, e.Payload[payloadHealth])
+}
+
+func TestVoid_NonePolicyAndExemptPlayers(t *testing.T) {
+	gh := newTestGameHandler(t)
+	require.NoError(t, gh.SetVoidRules(VoidRules{Policy: VoidPolicyNone}))
+	playerOverPit(t, gh, "faller", 10)
+	e, _ := gh.entityManager.GetEntity(10)
+	pit := e.Position
+
+	gh.checkVoidPlayers()
+	assert.Equal(t, pit, e.Position)
+
+	// Наблюдатели не падают и при политике spawn
+	require.NoError(t, gh.SetVoidRules(VoidRules{}))
+	gh.mu.Lock()
+	gh.sessions["faller"].Spectator = true
+	gh.mu.Unlock()
+	gh.checkVoidPlayers()
+	assert.Equal(t, pit, e.Position)
+
+	_, err := ParseVoidPolicy("lava")
+	assert.Error(t, err)
+}
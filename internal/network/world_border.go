@@ -0,0 +1,72 @@
+package network
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/annel0/mmo-game/internal/protocol"
+	"github.com/annel0/mmo-game/internal/storage_interface"
+	"github.com/annel0/mmo-game/internal/vec"
+)
+
+// ServerMessageWorldBorder - код SERVER_MESSAGE: игрок вошёл в зону
+// предупреждения у границы мира
+const ServerMessageWorldBorder = "world_border_warning"
+
+// SetWorldBorder задаёт границу мира (nil - снимает), сохраняет её в
+// метаданных мира и рассылает игрокам обновлённое состояние мира
+func (gh *GameHandlerPB) SetWorldBorder(border *storage_interface.WorldBorder) error {
+	if err := gh.worldManager.SetWorldBorder(border); err != nil {
+		return err
+	}
+
+	gh.mu.Lock()
+	conns := make([]string, 0, len(gh.sessions))
+	for connID, session := range gh.sessions {
+		session.borderWarned = false
+		conns = append(conns, connID)
+	}
+	gh.mu.Unlock()
+
+	for _, connID := range conns {
+		gh.sendWorldState(connID)
+	}
+	return nil
+}
+
+// worldBorderState возвращает границу мира для WORLD_STATE (nil - мир бесконечен)
+func (gh *GameHandlerPB) worldBorderState() *protocol.WorldBorder {
+	border, ok := gh.worldManager.WorldBorder()
+	if !ok {
+		return nil
+	}
+	return &protocol.WorldBorder{
+		CenterX:         int32(border.Center.X),
+		CenterY:         int32(border.Center.Y),
+		Radius:          int32(border.Radius),
+		WarningDistance: int32(border.Warning),
+	}
+}
+
+// warnNearWorldBorder предупреждает игрока, вошедшего в зону у границы мира.
+// Предупреждение отправляется один раз, пока игрок не покинет зону
+func (gh *GameHandlerPB) warnNearWorldBorder(connID string, pos vec.Vec2) {
+	near := gh.worldManager.NearWorldBorder(pos)
+
+	gh.mu.Lock()
+	session, exists := gh.sessions[connID]
+	if !exists || session.borderWarned == near {
+		gh.mu.Unlock()
+		return
+	}
+	session.borderWarned = near
+	gh.mu.Unlock()
+
+	if !near {
+		return
+	}
+	border, _ := gh.worldManager.WorldBorder()
+	log.Printf("Игрок %s у границы мира в позиции (%d,%d)", connID, pos.X, pos.Y)
+	gh.sendServerMessage(connID, ServerMessageWorldBorder,
+		fmt.Sprintf("Граница мира рядом: дальше %d блоков от (%d, %d) пройти нельзя", border.Radius, border.Center.X, border.Center.Y))
+}
@@ -0,0 +1,159 @@
+package network
+
+import (
+	"context"
+	"testing"
+
+	"github.com/annel0/mmo-game/internal/protocol"
+	"github.com/annel0/mmo-game/internal/storage"
+	"github.com/annel0/mmo-game/internal/storage_interface"
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/annel0/mmo-game/internal/world"
+	"github.com/annel0/mmo-game/internal/world/block"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setTestWorldBorder задаёт границу мира и расчищает ряд блоков от центра до
+// края и за ним, чтобы перемещения проверяли только границу
+func setTestWorldBorder(t *testing.T, gh *GameHandlerPB, border storage_interface.WorldBorder) {
+	t.Helper()
+	require.NoError(t, gh.SetWorldBorder(&border))
+	for x := border.Center.X; x <= border.Center.X+border.Radius+2; x++ {
+		pos := vec.Vec2{X: x, Y: border.Center.Y}
+		gh.worldManager.SetBlockLayer(pos, world.LayerActive, world.NewBlock(block.AirBlockID))
+		gh.worldManager.SetBlockLayer(pos, world.LayerFloor, world.NewBlock(block.StoneBlockID))
+		require.True(t, gh.isPositionWalkable(pos))
+	}
+}
+
+func TestWorldBorder_BlocksMovementBeyondBorder(t *testing.T) {
+	gh := newTestGameHandler(t)
+	client := connectTestClient(t, gh, "conn-1")
+	addTestSession(gh, "conn-1", 1, 1, vec.Vec2{})
+	setTestWorldBorder(t, gh, storage_interface.WorldBorder{Radius: 5, Warning: 2})
+
+	// Внутри границы игрок ходит свободно
+	moveTo(t, gh, "conn-1", 1, vec.Vec2{X: 3})
+	assert.Equal(t, vec.Vec2{X: 3}, playerEntityFor(t, gh, "conn-1").Position)
+	assert.Zero(t, client.drain(protocol.MessageType_SERVER_MESSAGE))
+
+	// У края - одно предупреждение
+	moveTo(t, gh, "conn-1", 1, vec.Vec2{X: 4})
+	moveTo(t, gh, "conn-1", 1, vec.Vec2{X: 5})
+	warning := &protocol.ServerMessage{}
+	client.expect(t, protocol.MessageType_SERVER_MESSAGE, warning)
+	assert.Equal(t, ServerMessageWorldBorder, warning.Code)
+	assert.Zero(t, client.drain(protocol.MessageType_SERVER_MESSAGE), "повторно в той же зоне не предупреждает")
+	assert.Equal(t, vec.Vec2{X: 5}, playerEntityFor(t, gh, "conn-1").Position, "край границы проходим")
+
+	// За границу не пройти: позиция откатывается
+	client.drain(protocol.MessageType_ENTITY_MOVE)
+	moveTo(t, gh, "conn-1", 1, vec.Vec2{X: 6})
+	assert.Equal(t, vec.Vec2{X: 5}, playerEntityFor(t, gh, "conn-1").Position)
+	assert.Equal(t, 1, client.drain(protocol.MessageType_ENTITY_MOVE), "клиент получает коррекцию позиции")
+
+	// Наблюдатель тоже остаётся в пределах границы
+	gh.mu.Lock()
+	gh.sessions["conn-1"].IsAdmin = true
+	gh.mu.Unlock()
+	require.NoError(t, gh.SetSpectator("conn-1", true))
+	moveTo(t, gh, "conn-1", 1, vec.Vec2{X: 7})
+	assert.Equal(t, vec.Vec2{X: 5}, playerEntityFor(t, gh, "conn-1").Position)
+}
+
+func TestWorldBorder_BlocksBuildingBeyondBorder(t *testing.T) {
+	gh := newTestGameHandler(t)
+	client := connectTestClient(t, gh, "conn-1")
+	addTestSession(gh, "conn-1", 1, 1, vec.Vec2{X: 4})
+	registerTestBlock(t, block.StoneBlockID, "stone")
+	setTestWorldBorder(t, gh, storage_interface.WorldBorder{Radius: 5})
+
+	place := func(pos vec.Vec2) *protocol.BlockUpdateResponseMessage {
+		gh.HandleMessage("conn-1", newGameMessage(t, protocol.MessageType_BLOCK_UPDATE, &protocol.BlockUpdateRequest{
+			Position: &protocol.Vec2{X: int32(pos.X), Y: int32(pos.Y)},
+			BlockId:  uint32(block.StoneBlockID),
+			Layer:    protocol.BlockLayer_ACTIVE,
+			Action:   "place",
+		}))
+		response := &protocol.BlockUpdateResponseMessage{}
+		client.expect(t, protocol.MessageType_BLOCK_UPDATE_RESPONSE, response)
+		return response
+	}
+
+	inside, outside := vec.Vec2{X: 5}, vec.Vec2{X: 6}
+	require.True(t, place(inside).Success)
+	assert.Equal(t, block.StoneBlockID, gh.worldManager.GetBlockLayer(inside, world.LayerActive).ID)
+
+	rejected := place(outside)
+	assert.False(t, rejected.Success)
+	assert.Contains(t, rejected.Message, "world border")
+	errMsg := &protocol.ErrorMessage{}
+	client.expect(t, protocol.MessageType_ERROR, errMsg)
+	assert.Equal(t, protocol.ErrorCode_FORBIDDEN, errMsg.Code)
+	assert.Equal(t, block.AirBlockID, gh.worldManager.GetBlockLayer(outside, world.LayerActive).ID)
+
+	// Действия строительства проверяют границу так же
+	actor := playerEntityFor(t, gh, "conn-1")
+	ok, _, _ := gh.handleBuildPlaceAction(actor, &protocol.EntityActionRequest{Position: &protocol.Vec2{X: int32(outside.X)}})
+	assert.False(t, ok)
+	assert.Equal(t, block.AirBlockID, gh.worldManager.GetBlockLayer(outside, world.LayerActive).ID)
+}
+
+func TestWorldBorder_SentInWorldState(t *testing.T) {
+	gh := newTestGameHandler(t)
+	client := connectTestClient(t, gh, "conn-1")
+	addTestSession(gh, "conn-1", 1, 1, vec.Vec2{})
+
+	gh.sendWorldState("conn-1")
+	state := &protocol.WorldStateMessage{}
+	client.expect(t, protocol.MessageType_WORLD_STATE, state)
+	assert.Nil(t, state.Border, "мир без границы")
+
+	// Новая граница сразу рассылается игрокам
+	require.NoError(t, gh.SetWorldBorder(&storage_interface.WorldBorder{Center: vec.Vec2{X: 10, Y: -20}, Radius: 500, Warning: 16}))
+	client.expect(t, protocol.MessageType_WORLD_STATE, state)
+	require.NotNil(t, state.Border)
+	assert.Equal(t, int32(10), state.Border.CenterX)
+	assert.Equal(t, int32(-20), state.Border.CenterY)
+	assert.Equal(t, int32(500), state.Border.Radius)
+	assert.Equal(t, int32(16), state.Border.WarningDistance)
+}
+
+func TestWorldBorder_PlayerOutsideCanWalkBack(t *testing.T) {
+	gh := newTestGameHandler(t)
+	connectTestClient(t, gh, "conn-1")
+	addTestSession(gh, "conn-1", 1, 1, vec.Vec2{X: 7})
+	setTestWorldBorder(t, gh, storage_interface.WorldBorder{Radius: 5})
+	gh.worldManager.SetBlockLayer(vec.Vec2{X: 8}, world.LayerActive, world.NewBlock(block.AirBlockID))
+	gh.worldManager.SetBlockLayer(vec.Vec2{X: 8}, world.LayerFloor, world.NewBlock(block.StoneBlockID))
+
+	// Граница сдвинулась за спину игрока: дальше от неё не уйти, назад - можно
+	moveTo(t, gh, "conn-1", 1, vec.Vec2{X: 8})
+	assert.Equal(t, vec.Vec2{X: 7}, playerEntityFor(t, gh, "conn-1").Position)
+	moveTo(t, gh, "conn-1", 1, vec.Vec2{X: 6})
+	assert.Equal(t, vec.Vec2{X: 6}, playerEntityFor(t, gh, "conn-1").Position)
+	moveTo(t, gh, "conn-1", 1, vec.Vec2{X: 5})
+	assert.Equal(t, vec.Vec2{X: 5}, playerEntityFor(t, gh, "conn-1").Position)
+}
+
+func TestWorldBorder_SpawnsStayInside(t *testing.T) {
+	gh := newTestGameHandler(t)
+	repo := storage.NewMemoryPositionRepo()
+	gh.SetPositionRepo(repo)
+	require.NoError(t, gh.worldManager.SetWorldSpawn(vec.Vec2{X: 50, Y: 3}))
+	require.NoError(t, gh.SetWorldBorder(&storage_interface.WorldBorder{Radius: 10}))
+
+	// Спавн за границей переносится к её краю: так возрождаются и
+	// возвращаются из пропасти
+	assert.Equal(t, vec.Vec3{X: 10, Y: 3, Z: 1}, gh.GetDefaultSpawnPosition())
+
+	// Игрок, сохранённый за границей, входит на спавн
+	require.NoError(t, repo.Save(context.Background(), 1, vec.Vec3{X: 400, Y: -400, Z: 1}))
+	authTestClient(t, gh, connectTestClient(t, gh, "conn-1"))
+	assert.Equal(t, vec.Vec2{X: 10, Y: 3}, playerEntityFor(t, gh, "conn-1").Position)
+
+	// Телепорт за границу отклоняется
+	_, err := gh.TeleportPlayer("admin", vec.Vec3{X: 11, Y: 0, Z: 1})
+	assert.ErrorIs(t, err, ErrInvalidTeleportDestination)
+}
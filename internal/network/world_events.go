@@ -0,0 +1,214 @@
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/eventbus"
+	"github.com/annel0/mmo-game/internal/logging"
+	"github.com/annel0/mmo-game/internal/protocol"
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/google/uuid"
+)
+
+// Типы событий мира, которые администраторы и скрипты запускают через
+// BroadcastWorldEvent. Клиент получает их в WORLD_EVENT (WorldEventMessage)
+const (
+	WorldEventMeteorShower = "meteor_shower" // Метеоритный дождь
+	WorldEventBossSpawn    = "boss_spawn"    // Появление босса
+	WorldEventAnnouncement = "announcement"  // Объявление для всех игроков
+	WorldEventDayNight     = "day_night"     // Смена времени суток
+	WorldEventWeather      = "weather"       // Смена погоды
+)
+
+// EventTypeWorldEvent - тип события EventBus: события мира попадают в журнал
+// воспроизведения наравне с BlockEvent и EntityEvent
+const EventTypeWorldEvent = "WorldEvent"
+
+// MaxWorldEventDataBytes - предел размера данных события в JSON
+const MaxWorldEventDataBytes = 16 * 1024
+
+var (
+	// ErrUnknownWorldEvent - тип события не входит в WorldEventTypes
+	ErrUnknownWorldEvent = errors.New("неизвестный тип события мира")
+	// ErrInvalidWorldEvent - некорректные данные или область события
+	ErrInvalidWorldEvent = errors.New("некорректное событие мира")
+)
+
+var worldEventTypes = map[string]bool{
+	WorldEventMeteorShower: true,
+	WorldEventBossSpawn:    true,
+	WorldEventAnnouncement: true,
+	WorldEventDayNight:     true,
+	WorldEventWeather:      true,
+}
+
+// WorldEventTypes возвращает допустимые типы событий мира
+func WorldEventTypes() []string {
+	types := make([]string, 0, len(worldEventTypes))
+	for eventType := range worldEventTypes {
+		types = append(types, eventType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// WorldEventTarget ограничивает круг получателей события. Пустая цель -
+// все игроки на узле; при заданных RegionID и Area игрок должен быть в обеих
+type WorldEventTarget struct {
+	RegionID string           // Область региона из таблицы регионов (см. SetRegionRouting)
+	Area     *eventbus.Bounds // Область в координатах блоков, границы включены
+}
+
+// WorldEventResult - итог рассылки события мира
+type WorldEventResult struct {
+	EventID    string `json:"event_id"` // ID события в EventBus
+	EventType  string `json:"event_type"`
+	Recipients int    `json:"recipients"` // Игроков, получивших событие
+}
+
+// worldEventRecord - полезная нагрузка события мира в EventBus
+type worldEventRecord struct {
+	EventType  string                 `json:"event_type"`
+	Data       map[string]interface{} `json:"data,omitempty"`
+	RegionID   string                 `json:"region_id,omitempty"`
+	Area       *eventbus.Bounds       `json:"area,omitempty"`
+	Recipients int                    `json:"recipients"`
+}
+
+// BroadcastWorldEvent проверяет тип события, публикует его в EventBus для
+// журнала воспроизведения и рассылает WorldEventMessage игрокам в target
+func (gh *GameHandlerPB) BroadcastWorldEvent(eventType string, data map[string]interface{}, target WorldEventTarget) (WorldEventResult, error) {
+	if !worldEventTypes[eventType] {
+		return WorldEventResult{}, fmt.Errorf("%w: %q", ErrUnknownWorldEvent, eventType)
+	}
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return WorldEventResult{}, fmt.Errorf("%w: %v", ErrInvalidWorldEvent, err)
+	}
+	if len(jsonData) > MaxWorldEventDataBytes {
+		return WorldEventResult{}, fmt.Errorf("%w: данные больше %d байт", ErrInvalidWorldEvent, MaxWorldEventDataBytes)
+	}
+	areas, err := gh.worldEventAreas(target)
+	if err != nil {
+		return WorldEventResult{}, err
+	}
+
+	msg := &protocol.WorldEventMessage{
+		EventType: eventType,
+		Metadata:  &protocol.JsonMetadata{JsonData: string(jsonData)},
+	}
+	if target.Area != nil {
+		center := areaCenter(*target.Area)
+		msg.Position = &protocol.Vec2{X: int32(center.X), Y: int32(center.Y)}
+	}
+
+	recipients := gh.worldEventRecipients(areas)
+	for _, connID := range recipients {
+		gh.sendTCPMessage(connID, protocol.MessageType_WORLD_EVENT, msg)
+	}
+
+	result := WorldEventResult{EventID: uuid.NewString(), EventType: eventType, Recipients: len(recipients)}
+	gh.publishWorldEvent(result, worldEventRecord{
+		EventType:  eventType,
+		Data:       data,
+		RegionID:   target.RegionID,
+		Area:       target.Area,
+		Recipients: len(recipients),
+	})
+	logging.Info("🌠 Событие мира %s (%s) отправлено %d игрокам", eventType, result.EventID, len(recipients))
+	return result, nil
+}
+
+// worldEventAreas возвращает области, в которых должен находиться получатель
+func (gh *GameHandlerPB) worldEventAreas(target WorldEventTarget) ([]eventbus.Bounds, error) {
+	var areas []eventbus.Bounds
+	if target.Area != nil {
+		if target.Area.MinX > target.Area.MaxX || target.Area.MinY > target.Area.MaxY {
+			return nil, fmt.Errorf("%w: пустая область %+v", ErrInvalidWorldEvent, *target.Area)
+		}
+		areas = append(areas, *target.Area)
+	}
+	if target.RegionID == "" {
+		return areas, nil
+	}
+
+	gh.mu.RLock()
+	routes := gh.regions.routes
+	gh.mu.RUnlock()
+	for _, route := range routes {
+		if route.ID == target.RegionID {
+			return append(areas, route.Bounds), nil
+		}
+	}
+	return nil, fmt.Errorf("%w: регион %q отсутствует в таблице регионов", ErrInvalidWorldEvent, target.RegionID)
+}
+
+// worldEventRecipients возвращает соединения игроков, находящихся во всех areas
+func (gh *GameHandlerPB) worldEventRecipients(areas []eventbus.Bounds) []string {
+	gh.mu.RLock()
+	players := make(map[string]uint64, len(gh.playerEntities))
+	for connID, entityID := range gh.playerEntities {
+		players[connID] = entityID
+	}
+	gh.mu.RUnlock()
+
+	// Позиции запрашиваются без gh.mu: EntityManager вызывает обработчик под своей блокировкой
+	recipients := make([]string, 0, len(players))
+	for connID, entityID := range players {
+		if len(areas) > 0 {
+			e, ok := gh.entityManager.GetEntity(entityID)
+			if !ok || !insideAll(areas, e.Position) {
+				continue
+			}
+		}
+		recipients = append(recipients, connID)
+	}
+	sort.Strings(recipients)
+	return recipients
+}
+
+// publishWorldEvent записывает событие мира в EventBus
+func (gh *GameHandlerPB) publishWorldEvent(result WorldEventResult, record worldEventRecord) {
+	payload, err := json.Marshal(record)
+	if err != nil {
+		return
+	}
+	envelope := &eventbus.Envelope{
+		ID:        result.EventID,
+		Timestamp: time.Now().UTC(),
+		Source:    "game_handler",
+		EventType: EventTypeWorldEvent,
+		Version:   1,
+		Priority:  7,
+		Payload:   payload,
+		Metadata:  map[string]string{"world_event_type": record.EventType},
+	}
+	if record.Area != nil {
+		center := areaCenter(*record.Area)
+		envelope.SetPosition("", center.X, center.Y)
+	}
+	if err := gh.publish(context.Background(), envelope); err != nil {
+		logging.Warn("Событие мира %s не записано в EventBus: %v", result.EventID, err)
+	}
+}
+
+func insideAll(areas []eventbus.Bounds, pos vec.Vec2) bool {
+	for _, area := range areas {
+		if !area.Contains(pos.X, pos.Y) {
+			return false
+		}
+	}
+	return true
+}
+
+func areaCenter(area eventbus.Bounds) vec.Vec2 {
+	return vec.Vec2{X: area.MinX + (area.MaxX-area.MinX)/2, Y: area.MinY + (area.MaxY-area.MinY)/2}
+}
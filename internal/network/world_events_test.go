@@ -0,0 +1,112 @@
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"github.com/annel0/mmo-game/internal/eventbus"
+	"github.com/annel0/mmo-game/internal/protocol"
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordPublished подменяет публикацию в EventBus и возвращает журнал событий
+func recordPublished(gh *GameHandlerPB) func() []*eventbus.Envelope {
+	var mu sync.Mutex
+	var published []*eventbus.Envelope
+	gh.publish = func(ctx context.Context, ev *eventbus.Envelope) error {
+		mu.Lock()
+		published = append(published, ev)
+		mu.Unlock()
+		return nil
+	}
+	return func() []*eventbus.Envelope {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]*eventbus.Envelope(nil), published...)
+	}
+}
+
+func TestBroadcastWorldEvent_ReachesClientsAndReplayLog(t *testing.T) {
+	gh := newTestGameHandler(t)
+	published := recordPublished(gh)
+	near := connectTestClient(t, gh, "near")
+	far := connectTestClient(t, gh, "far")
+	addTestSession(gh, "near", 1, 101, vec.Vec2{X: 10, Y: 10})
+	addTestSession(gh, "far", 2, 102, vec.Vec2{X: 500, Y: 500})
+
+	data := map[string]interface{}{"intensity": "high"}
+	result, err := gh.BroadcastWorldEvent(WorldEventMeteorShower, data, WorldEventTarget{
+		Area: &eventbus.Bounds{MinX: 0, MinY: 0, MaxX: 20, MaxY: 20},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Recipients)
+
+	var msg protocol.WorldEventMessage
+	near.expect(t, protocol.MessageType_WORLD_EVENT, &msg)
+	assert.Equal(t, WorldEventMeteorShower, msg.EventType)
+	assert.JSONEq(t, `{"intensity": "high"}`, msg.Metadata.JsonData)
+	require.NotNil(t, msg.Position)
+	assert.Equal(t, []int32{10, 10}, []int32{msg.Position.X, msg.Position.Y}, "позиция события - центр области")
+	assert.Zero(t, far.drain(protocol.MessageType_WORLD_EVENT), "игрок вне области не получает событие")
+
+	// Событие записано в журнал воспроизведения
+	log := published()
+	require.Len(t, log, 1)
+	assert.Equal(t, EventTypeWorldEvent, log[0].EventType)
+	assert.Equal(t, result.EventID, log[0].ID)
+	assert.Equal(t, WorldEventMeteorShower, log[0].Metadata["world_event_type"])
+	var record worldEventRecord
+	require.NoError(t, json.Unmarshal(log[0].Payload, &record))
+	assert.Equal(t, data, record.Data)
+	assert.Equal(t, 1, record.Recipients)
+
+	// Без цели событие получают все
+	result, err = gh.BroadcastWorldEvent(WorldEventAnnouncement, map[string]interface{}{"text": "Сервер перезапустится"}, WorldEventTarget{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Recipients)
+	far.expect(t, protocol.MessageType_WORLD_EVENT, &msg)
+	assert.Equal(t, WorldEventAnnouncement, msg.EventType)
+	assert.Nil(t, msg.Position)
+}
+
+func TestBroadcastWorldEvent_TargetsRegion(t *testing.T) {
+	gh := newTestGameHandler(t)
+	recordPublished(gh)
+	connectTestClient(t, gh, "west")
+	connectTestClient(t, gh, "east")
+	addTestSession(gh, "west", 1, 101, vec.Vec2{X: -50, Y: 0})
+	addTestSession(gh, "east", 2, 102, vec.Vec2{X: 50, Y: 0})
+	require.NoError(t, gh.SetRegionRouting("west", []RegionRoute{
+		{ID: "west", GameAddress: "west:7777", Bounds: eventbus.Bounds{MinX: -1000, MinY: -1000, MaxX: -1, MaxY: 1000}},
+		{ID: "east", GameAddress: "east:7777", Bounds: eventbus.Bounds{MinX: 0, MinY: -1000, MaxX: 1000, MaxY: 1000}},
+	}))
+
+	result, err := gh.BroadcastWorldEvent(WorldEventBossSpawn, nil, WorldEventTarget{RegionID: "east"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Recipients)
+
+	_, err = gh.BroadcastWorldEvent(WorldEventBossSpawn, nil, WorldEventTarget{RegionID: "north"})
+	assert.ErrorIs(t, err, ErrInvalidWorldEvent)
+}
+
+func TestBroadcastWorldEvent_Validation(t *testing.T) {
+	gh := newTestGameHandler(t)
+	published := recordPublished(gh)
+
+	_, err := gh.BroadcastWorldEvent("alien_invasion", nil, WorldEventTarget{})
+	assert.ErrorIs(t, err, ErrUnknownWorldEvent)
+
+	_, err = gh.BroadcastWorldEvent(WorldEventWeather, nil, WorldEventTarget{Area: &eventbus.Bounds{MinX: 10, MaxX: 0}})
+	assert.ErrorIs(t, err, ErrInvalidWorldEvent)
+
+	huge := map[string]interface{}{"text": string(make([]byte, MaxWorldEventDataBytes))}
+	_, err = gh.BroadcastWorldEvent(WorldEventAnnouncement, huge, WorldEventTarget{})
+	assert.ErrorIs(t, err, ErrInvalidWorldEvent)
+
+	assert.Empty(t, published(), "отклонённые события не попадают в журнал")
+	assert.Contains(t, WorldEventTypes(), WorldEventDayNight)
+}
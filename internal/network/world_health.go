@@ -0,0 +1,37 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/world"
+)
+
+// WorldStallThreshold - мир, не завершивший ни одного тика за это время, считается зависшим
+const WorldStallThreshold = 5 * time.Second
+
+// CheckWorld проверяет живость мира для проверки готовности: сервер запущен
+// и планировщик продолжает выполнять тики
+func (kgs *KCPGameServer) CheckWorld(ctx context.Context) error {
+	scheduler := kgs.scheduler.Load()
+	if scheduler == nil || kgs.ctx.Err() != nil {
+		return errors.New("world is not running")
+	}
+	return checkTicking(scheduler, time.Unix(0, kgs.startedAt.Load()), time.Now())
+}
+
+// checkTicking возвращает ошибку, если с последнего тика (или с запуска,
+// если тиков ещё не было) прошло больше WorldStallThreshold
+func checkTicking(scheduler *world.TickScheduler, startedAt, now time.Time) error {
+	last := scheduler.LastTickAt()
+	if last.IsZero() {
+		last = startedAt
+	}
+	if stalled := now.Sub(last); stalled > WorldStallThreshold {
+		// CurrentTick не вызывается: он ждёт мьютекс, который держит зависший тик
+		return fmt.Errorf("world tick stalled for %s", stalled.Round(time.Millisecond))
+	}
+	return nil
+}
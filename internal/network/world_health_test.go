@@ -0,0 +1,22 @@
+package network
+
+import (
+	"testing"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/world"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckTicking(t *testing.T) {
+	scheduler := world.NewTickScheduler(world.DefaultTickRate)
+	started := time.Now()
+
+	assert.NoError(t, checkTicking(scheduler, started, started.Add(time.Second)), "мир только запущен")
+	assert.Error(t, checkTicking(scheduler, started, started.Add(WorldStallThreshold+time.Second)), "ни одного тика с запуска")
+
+	scheduler.Step()
+	now := scheduler.LastTickAt()
+	assert.NoError(t, checkTicking(scheduler, started, now.Add(time.Second)))
+	assert.ErrorContains(t, checkTicking(scheduler, started, now.Add(2*WorldStallThreshold)), "stalled")
+}
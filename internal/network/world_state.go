@@ -0,0 +1,63 @@
+package network
+
+import (
+	"encoding/json"
+	"log"
+
+	"github.com/annel0/mmo-game/internal/protocol"
+)
+
+// CapabilityWorldState - возможность клиента из AuthMessage.capabilities:
+// клиент понимает WORLD_STATE и не ждёт состояние мира в CHUNK_DATA
+const CapabilityWorldState = "world_state"
+
+// worldState собирает состояние мира для игрока
+func (gh *GameHandlerPB) worldState(connID string) *protocol.WorldStateMessage {
+	return &protocol.WorldStateMessage{
+		TimeOfDay: 0.5,
+		Weather:   "clear",
+		Season:    "summer",
+		GameMode:  string(gh.gameModeOf(connID)),
+		WorldId:   1234,
+		WorldName: "default",
+		Border:    gh.worldBorderState(),
+	}
+}
+
+// sendWorldState отправляет игроку состояние мира. Клиентам без возможности
+// CapabilityWorldState оно дублируется прежним способом - JsonMetadata в CHUNK_DATA
+func (gh *GameHandlerPB) sendWorldState(connID string) {
+	state := gh.worldState(connID)
+	gh.sendTCPMessage(connID, protocol.MessageType_WORLD_STATE, state)
+
+	gh.mu.RLock()
+	session, exists := gh.sessions[connID]
+	legacy := exists && session.legacyWorldState
+	gh.mu.RUnlock()
+	if !legacy {
+		return
+	}
+
+	legacyState := map[string]interface{}{
+		"time_of_day": state.TimeOfDay,
+		"weather":     state.Weather,
+		"season":      state.Season,
+		"game_mode":   state.GameMode,
+		"world_id":    state.WorldId,
+		"world_name":  state.WorldName,
+	}
+	if border := state.Border; border != nil {
+		legacyState["world_border"] = map[string]int32{
+			"center_x":         border.CenterX,
+			"center_y":         border.CenterY,
+			"radius":           border.Radius,
+			"warning_distance": border.WarningDistance,
+		}
+	}
+	jsonData, err := json.Marshal(legacyState)
+	if err != nil {
+		log.Printf("Ошибка сериализации данных мира: %v", err)
+		return
+	}
+	gh.sendTCPMessage(connID, protocol.MessageType_CHUNK_DATA, &protocol.JsonMetadata{JsonData: string(jsonData)})
+}
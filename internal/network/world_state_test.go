@@ -0,0 +1,47 @@
+package network
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/annel0/mmo-game/internal/protocol"
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorldState_SentAsTypedMessage(t *testing.T) {
+	gh := newTestGameHandler(t)
+	client := connectTestClient(t, gh, "conn-1")
+	addTestSession(gh, "conn-1", 1, 1, vec.Vec2{X: 0, Y: 0})
+	require.NoError(t, gh.SetGameMode("conn-1", GameModeCreative))
+
+	gh.sendWorldState("conn-1")
+
+	state := &protocol.WorldStateMessage{}
+	client.expect(t, protocol.MessageType_WORLD_STATE, state)
+	assert.Equal(t, string(GameModeCreative), state.GameMode)
+	assert.Equal(t, "clear", state.Weather)
+	assert.Equal(t, "default", state.WorldName)
+	assert.Zero(t, client.drain(protocol.MessageType_CHUNK_DATA), "состояние мира не маскируется под чанк")
+}
+
+func TestWorldState_LegacyClientAlsoGetsChunkData(t *testing.T) {
+	gh := newTestGameHandler(t)
+	client := connectTestClient(t, gh, "conn-old")
+	session := addTestSession(gh, "conn-old", 1, 1, vec.Vec2{X: 0, Y: 0})
+	gh.mu.Lock()
+	session.legacyWorldState = true // Клиент не заявил CapabilityWorldState
+	gh.mu.Unlock()
+
+	gh.sendWorldState("conn-old")
+
+	client.expect(t, protocol.MessageType_WORLD_STATE, &protocol.WorldStateMessage{})
+	legacy := &protocol.JsonMetadata{}
+	client.expect(t, protocol.MessageType_CHUNK_DATA, legacy)
+
+	var data map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(legacy.JsonData), &data))
+	assert.Equal(t, string(GameModeSurvival), data["game_mode"])
+	assert.Equal(t, "summer", data["season"])
+}
@@ -0,0 +1,274 @@
+package observability
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/logging"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// DefaultMetricsEndpoint - OTLP/HTTP приёмник метрик коллектора (тот же, что и для трейсов)
+	DefaultMetricsEndpoint = "http://localhost:4318/v1/metrics"
+	// DefaultPushInterval - период отправки метрик
+	DefaultPushInterval = 15 * time.Second
+)
+
+// MetricsPushConfig настройки отправки метрик в OTLP-коллектор
+type MetricsPushConfig struct {
+	Endpoint    string            // URL приёмника OTLP/HTTP (по умолчанию DefaultMetricsEndpoint)
+	Interval    time.Duration     // Период отправки (по умолчанию DefaultPushInterval)
+	ServiceName string            // Атрибут service.name ресурса
+	Headers     map[string]string // Дополнительные заголовки (например, авторизация коллектора)
+	Timeout     time.Duration     // Таймаут одного запроса (по умолчанию 10с)
+}
+
+// MetricsPusher периодически отправляет метрики из Prometheus-регистра в
+// OTLP-коллектор. Нужен короткоживущим региональным узлам за NAT, которые
+// Prometheus не может опросить. Метрики не регистрируются повторно: пушер
+// читает тот же регистр, что и /metrics
+type MetricsPusher struct {
+	gatherer prometheus.Gatherer
+	cfg      MetricsPushConfig
+	client   *http.Client
+	start    time.Time // Начало накопления кумулятивных счётчиков
+
+	quit     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewMetricsPusher создаёт пушер; nil gatherer - глобальный регистр Prometheus
+func NewMetricsPusher(gatherer prometheus.Gatherer, cfg MetricsPushConfig) *MetricsPusher {
+	if gatherer == nil {
+		gatherer = prometheus.DefaultGatherer
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = DefaultMetricsEndpoint
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = DefaultPushInterval
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+
+	return &MetricsPusher{
+		gatherer: gatherer,
+		cfg:      cfg,
+		client:   &http.Client{Timeout: cfg.Timeout},
+		start:    time.Now(),
+		quit:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Start запускает периодическую отправку в отдельной горутине
+func (p *MetricsPusher) Start() {
+	logging.Info("📤 Отправка метрик OTLP → %s каждые %s", p.cfg.Endpoint, p.cfg.Interval)
+	go p.loop()
+}
+
+// Stop останавливает отправку, предварительно отправив последний пакет,
+// чтобы метрики завершающегося узла не потерялись
+func (p *MetricsPusher) Stop() {
+	p.stopOnce.Do(func() {
+		close(p.quit)
+		<-p.done
+	})
+}
+
+func (p *MetricsPusher) loop() {
+	ticker := time.NewTicker(p.cfg.Interval)
+	defer ticker.Stop()
+	defer close(p.done)
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := p.Push(context.Background()); err != nil {
+				logging.Warn("Не удалось отправить метрики: %v", err)
+			}
+		case <-p.quit:
+			if err := p.Push(context.Background()); err != nil {
+				logging.Warn("Не удалось отправить последние метрики: %v", err)
+			}
+			return
+		}
+	}
+}
+
+// Push собирает метрики и отправляет их одним пакетом
+func (p *MetricsPusher) Push(ctx context.Context) error {
+	families, err := p.gatherer.Gather()
+	if err != nil {
+		return fmt.Errorf("gather metrics: %w", err)
+	}
+
+	body, err := proto.Marshal(exportRequest(families, p.cfg.ServiceName, p.start, time.Now()))
+	if err != nil {
+		return fmt.Errorf("encode metrics: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.cfg.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	for key, value := range p.cfg.Headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send metrics: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("collector responded %s", resp.Status)
+	}
+	return nil
+}
+
+// exportRequest преобразует семейства метрик Prometheus в запрос OTLP
+func exportRequest(families []*dto.MetricFamily, serviceName string, start, now time.Time) *colmetricspb.ExportMetricsServiceRequest {
+	startNano := uint64(start.UnixNano())
+	nowNano := uint64(now.UnixNano())
+
+	metrics := make([]*metricspb.Metric, 0, len(families))
+	for _, family := range families {
+		metric := &metricspb.Metric{Name: family.GetName(), Description: family.GetHelp()}
+
+		switch family.GetType() {
+		case dto.MetricType_COUNTER:
+			sum := &metricspb.Sum{
+				AggregationTemporality: metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+				IsMonotonic:            true,
+			}
+			for _, m := range family.GetMetric() {
+				sum.DataPoints = append(sum.DataPoints, numberPoint(m, m.GetCounter().GetValue(), startNano, nowNano))
+			}
+			metric.Data = &metricspb.Metric_Sum{Sum: sum}
+
+		case dto.MetricType_GAUGE, dto.MetricType_UNTYPED:
+			gauge := &metricspb.Gauge{}
+			for _, m := range family.GetMetric() {
+				value := m.GetGauge().GetValue()
+				if family.GetType() == dto.MetricType_UNTYPED {
+					value = m.GetUntyped().GetValue()
+				}
+				gauge.DataPoints = append(gauge.DataPoints, numberPoint(m, value, 0, nowNano))
+			}
+			metric.Data = &metricspb.Metric_Gauge{Gauge: gauge}
+
+		case dto.MetricType_HISTOGRAM:
+			histogram := &metricspb.Histogram{
+				AggregationTemporality: metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+			}
+			for _, m := range family.GetMetric() {
+				histogram.DataPoints = append(histogram.DataPoints, histogramPoint(m, startNano, nowNano))
+			}
+			metric.Data = &metricspb.Metric_Histogram{Histogram: histogram}
+
+		case dto.MetricType_SUMMARY:
+			summary := &metricspb.Summary{}
+			for _, m := range family.GetMetric() {
+				point := &metricspb.SummaryDataPoint{
+					Attributes:        attributes(m),
+					StartTimeUnixNano: startNano,
+					TimeUnixNano:      nowNano,
+					Count:             m.GetSummary().GetSampleCount(),
+					Sum:               m.GetSummary().GetSampleSum(),
+				}
+				for _, q := range m.GetSummary().GetQuantile() {
+					point.QuantileValues = append(point.QuantileValues, &metricspb.SummaryDataPoint_ValueAtQuantile{
+						Quantile: q.GetQuantile(),
+						Value:    q.GetValue(),
+					})
+				}
+				summary.DataPoints = append(summary.DataPoints, point)
+			}
+			metric.Data = &metricspb.Metric_Summary{Summary: summary}
+
+		default:
+			continue
+		}
+
+		metrics = append(metrics, metric)
+	}
+
+	return &colmetricspb.ExportMetricsServiceRequest{
+		ResourceMetrics: []*metricspb.ResourceMetrics{{
+			Resource: &resourcepb.Resource{Attributes: []*commonpb.KeyValue{stringAttr("service.name", serviceName)}},
+			ScopeMetrics: []*metricspb.ScopeMetrics{{
+				Scope:   &commonpb.InstrumentationScope{Name: "github.com/annel0/mmo-game/internal/observability"},
+				Metrics: metrics,
+			}},
+		}},
+	}
+}
+
+func numberPoint(m *dto.Metric, value float64, startNano, nowNano uint64) *metricspb.NumberDataPoint {
+	return &metricspb.NumberDataPoint{
+		Attributes:        attributes(m),
+		StartTimeUnixNano: startNano,
+		TimeUnixNano:      nowNano,
+		Value:             &metricspb.NumberDataPoint_AsDouble{AsDouble: value},
+	}
+}
+
+// histogramPoint переводит кумулятивные корзины Prometheus в счётчики
+// отдельных корзин OTLP (последняя корзина - до +Inf)
+func histogramPoint(m *dto.Metric, startNano, nowNano uint64) *metricspb.HistogramDataPoint {
+	h := m.GetHistogram()
+	sum := h.GetSampleSum()
+	point := &metricspb.HistogramDataPoint{
+		Attributes:        attributes(m),
+		StartTimeUnixNano: startNano,
+		TimeUnixNano:      nowNano,
+		Count:             h.GetSampleCount(),
+		Sum:               &sum,
+	}
+
+	var prev uint64
+	for _, bucket := range h.GetBucket() {
+		if math.IsInf(bucket.GetUpperBound(), +1) {
+			continue
+		}
+		point.ExplicitBounds = append(point.ExplicitBounds, bucket.GetUpperBound())
+		point.BucketCounts = append(point.BucketCounts, bucket.GetCumulativeCount()-prev)
+		prev = bucket.GetCumulativeCount()
+	}
+	point.BucketCounts = append(point.BucketCounts, h.GetSampleCount()-prev)
+	return point
+}
+
+func attributes(m *dto.Metric) []*commonpb.KeyValue {
+	attrs := make([]*commonpb.KeyValue, 0, len(m.GetLabel()))
+	for _, label := range m.GetLabel() {
+		attrs = append(attrs, stringAttr(label.GetName(), label.GetValue()))
+	}
+	return attrs
+}
+
+func stringAttr(key, value string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{
+		Key:   key,
+		Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: value}},
+	}
+}
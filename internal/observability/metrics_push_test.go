@@ -0,0 +1,144 @@
+package observability
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// mockCollector принимает OTLP/HTTP запросы и сохраняет полученные пакеты
+type mockCollector struct {
+	mu       sync.Mutex
+	requests []*colmetricspb.ExportMetricsServiceRequest
+	headers  []http.Header
+}
+
+func (c *mockCollector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	req := &colmetricspb.ExportMetricsServiceRequest{}
+	if r.URL.Path != "/v1/metrics" || r.Header.Get("Content-Type") != "application/x-protobuf" || proto.Unmarshal(body, req) != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+
+	c.mu.Lock()
+	c.requests = append(c.requests, req)
+	c.headers = append(c.headers, r.Header.Clone())
+	c.mu.Unlock()
+	w.WriteHeader(http.StatusOK)
+}
+
+func (c *mockCollector) batches() []*colmetricspb.ExportMetricsServiceRequest {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]*colmetricspb.ExportMetricsServiceRequest(nil), c.requests...)
+}
+
+func metricsByName(req *colmetricspb.ExportMetricsServiceRequest) map[string]*metricspb.Metric {
+	result := make(map[string]*metricspb.Metric)
+	for _, rm := range req.GetResourceMetrics() {
+		for _, sm := range rm.GetScopeMetrics() {
+			for _, m := range sm.GetMetrics() {
+				result[m.GetName()] = m
+			}
+		}
+	}
+	return result
+}
+
+func testRegistry(t *testing.T) *prometheus.Registry {
+	t.Helper()
+	registry := prometheus.NewRegistry()
+
+	events := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "test_events_total", Help: "events"}, []string{"region"})
+	players := prometheus.NewGauge(prometheus.GaugeOpts{Name: "test_players", Help: "players"})
+	latency := prometheus.NewHistogram(prometheus.HistogramOpts{Name: "test_latency_seconds", Help: "latency", Buckets: []float64{0.1, 1}})
+	require.NoError(t, registry.Register(events))
+	require.NoError(t, registry.Register(players))
+	require.NoError(t, registry.Register(latency))
+
+	events.WithLabelValues("eu-west").Add(3)
+	players.Set(42)
+	latency.Observe(0.05)
+	latency.Observe(0.5)
+	latency.Observe(5)
+	return registry
+}
+
+func TestMetricsPusher_PushSendsBatch(t *testing.T) {
+	collector := &mockCollector{}
+	server := httptest.NewServer(collector)
+	defer server.Close()
+
+	pusher := NewMetricsPusher(testRegistry(t), MetricsPushConfig{
+		Endpoint:    server.URL + "/v1/metrics",
+		ServiceName: "region-node",
+		Headers:     map[string]string{"X-Api-Key": "secret"},
+	})
+	require.NoError(t, pusher.Push(context.Background()))
+
+	batches := collector.batches()
+	require.Len(t, batches, 1)
+	assert.Equal(t, "secret", collector.headers[0].Get("X-Api-Key"))
+
+	resource := batches[0].GetResourceMetrics()[0].GetResource()
+	assert.Equal(t, "service.name", resource.GetAttributes()[0].GetKey())
+	assert.Equal(t, "region-node", resource.GetAttributes()[0].GetValue().GetStringValue())
+
+	metrics := metricsByName(batches[0])
+
+	sum := metrics["test_events_total"].GetSum()
+	require.NotNil(t, sum)
+	assert.True(t, sum.GetIsMonotonic())
+	require.Len(t, sum.GetDataPoints(), 1)
+	assert.Equal(t, 3.0, sum.GetDataPoints()[0].GetAsDouble())
+	assert.Equal(t, "eu-west", sum.GetDataPoints()[0].GetAttributes()[0].GetValue().GetStringValue())
+
+	gauge := metrics["test_players"].GetGauge()
+	require.NotNil(t, gauge)
+	assert.Equal(t, 42.0, gauge.GetDataPoints()[0].GetAsDouble())
+
+	histogram := metrics["test_latency_seconds"].GetHistogram()
+	require.NotNil(t, histogram)
+	point := histogram.GetDataPoints()[0]
+	assert.Equal(t, uint64(3), point.GetCount())
+	assert.Equal(t, []float64{0.1, 1}, point.GetExplicitBounds())
+	assert.Equal(t, []uint64{1, 1, 1}, point.GetBucketCounts())
+}
+
+func TestMetricsPusher_StopSendsFinalBatch(t *testing.T) {
+	collector := &mockCollector{}
+	server := httptest.NewServer(collector)
+	defer server.Close()
+
+	pusher := NewMetricsPusher(testRegistry(t), MetricsPushConfig{
+		Endpoint: server.URL + "/v1/metrics",
+		Interval: time.Hour,
+	})
+	pusher.Start()
+	pusher.Stop()
+	pusher.Stop() // повторная остановка безопасна
+
+	assert.Len(t, collector.batches(), 1)
+}
+
+func TestMetricsPusher_CollectorError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	pusher := NewMetricsPusher(testRegistry(t), MetricsPushConfig{Endpoint: server.URL})
+	assert.Error(t, pusher.Push(context.Background()))
+}
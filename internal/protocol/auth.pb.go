@@ -28,12 +28,14 @@ type AuthMessage struct {
 	Password *string                `protobuf:"bytes,2,opt,name=password,proto3,oneof" json:"password,omitempty"`
 	Token    *string                `protobuf:"bytes,3,opt,name=token,proto3,oneof" json:"token,omitempty"`
 	// === НОВЫЕ ПОЛЯ ===
-	JwtToken      *string  `protobuf:"bytes,4,opt,name=jwt_token,json=jwtToken,proto3,oneof" json:"jwt_token,omitempty"`          // JWT токен для повторной аутентификации
-	RequestJwt    bool     `protobuf:"varint,5,opt,name=request_jwt,json=requestJwt,proto3" json:"request_jwt,omitempty"`         // Запрос на получение JWT токена
-	ClientVersion string   `protobuf:"bytes,6,opt,name=client_version,json=clientVersion,proto3" json:"client_version,omitempty"` // Версия клиента
-	Capabilities  []string `protobuf:"bytes,7,rep,name=capabilities,proto3" json:"capabilities,omitempty"`                        // Возможности клиента ["jwt", "rest", "webhooks"]
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	JwtToken        *string  `protobuf:"bytes,4,opt,name=jwt_token,json=jwtToken,proto3,oneof" json:"jwt_token,omitempty"`                 // JWT токен для повторной аутентификации
+	RequestJwt      bool     `protobuf:"varint,5,opt,name=request_jwt,json=requestJwt,proto3" json:"request_jwt,omitempty"`                // Запрос на получение JWT токена
+	ClientVersion   string   `protobuf:"bytes,6,opt,name=client_version,json=clientVersion,proto3" json:"client_version,omitempty"`        // Версия клиента
+	Capabilities    []string `protobuf:"bytes,7,rep,name=capabilities,proto3" json:"capabilities,omitempty"`                               // Возможности клиента ["jwt", "rest", "webhooks"]
+	ProtocolVersion uint32   `protobuf:"varint,8,opt,name=protocol_version,json=protocolVersion,proto3" json:"protocol_version,omitempty"` // Версия сетевого протокола клиента
+	ViewDistance    uint32   `protobuf:"varint,9,opt,name=view_distance,json=viewDistance,proto3" json:"view_distance,omitempty"`          // Запрошенная дальность видимости в чанках (0 - по умолчанию)
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
 }
 
 func (x *AuthMessage) Reset() {
@@ -115,6 +117,20 @@ func (x *AuthMessage) GetCapabilities() []string {
 	return nil
 }
 
+func (x *AuthMessage) GetProtocolVersion() uint32 {
+	if x != nil {
+		return x.ProtocolVersion
+	}
+	return 0
+}
+
+func (x *AuthMessage) GetViewDistance() uint32 {
+	if x != nil {
+		return x.ViewDistance
+	}
+	return 0
+}
+
 // Ответ на аутентификацию
 type AuthResponseMessage struct {
 	state     protoimpl.MessageState `protogen:"open.v1"`
@@ -124,12 +140,19 @@ type AuthResponseMessage struct {
 	Token     string                 `protobuf:"bytes,4,opt,name=token,proto3" json:"token,omitempty"` // Токен аутентификации
 	WorldName string                 `protobuf:"bytes,5,opt,name=world_name,json=worldName,proto3" json:"world_name,omitempty"`
 	// === НОВЫЕ ПОЛЯ ===
-	JwtToken           *string     `protobuf:"bytes,6,opt,name=jwt_token,json=jwtToken,proto3,oneof" json:"jwt_token,omitempty"`                         // JWT токен
-	JwtExpiresAt       int64       `protobuf:"varint,7,opt,name=jwt_expires_at,json=jwtExpiresAt,proto3" json:"jwt_expires_at,omitempty"`                // Время истечения JWT (Unix timestamp)
-	ServerCapabilities []string    `protobuf:"bytes,8,rep,name=server_capabilities,json=serverCapabilities,proto3" json:"server_capabilities,omitempty"` // Возможности сервера
-	ServerInfo         *ServerInfo `protobuf:"bytes,9,opt,name=server_info,json=serverInfo,proto3" json:"server_info,omitempty"`                         // Информация о сервере
-	unknownFields      protoimpl.UnknownFields
-	sizeCache          protoimpl.SizeCache
+	JwtToken           *string     `protobuf:"bytes,6,opt,name=jwt_token,json=jwtToken,proto3,oneof" json:"jwt_token,omitempty"`                             // JWT токен
+	JwtExpiresAt       int64       `protobuf:"varint,7,opt,name=jwt_expires_at,json=jwtExpiresAt,proto3" json:"jwt_expires_at,omitempty"`                    // Время истечения JWT (Unix timestamp)
+	ServerCapabilities []string    `protobuf:"bytes,8,rep,name=server_capabilities,json=serverCapabilities,proto3" json:"server_capabilities,omitempty"`     // Возможности сервера
+	ServerInfo         *ServerInfo `protobuf:"bytes,9,opt,name=server_info,json=serverInfo,proto3" json:"server_info,omitempty"`                             // Информация о сервере
+	MinProtocolVersion uint32      `protobuf:"varint,10,opt,name=min_protocol_version,json=minProtocolVersion,proto3" json:"min_protocol_version,omitempty"` // Минимальная поддерживаемая версия протокола
+	MaxProtocolVersion uint32      `protobuf:"varint,11,opt,name=max_protocol_version,json=maxProtocolVersion,proto3" json:"max_protocol_version,omitempty"` // Максимальная поддерживаемая версия протокола
+	ViewDistance       uint32      `protobuf:"varint,12,opt,name=view_distance,json=viewDistance,proto3" json:"view_distance,omitempty"`                     // Назначенная дальность видимости в чанках
+	// Маршрутизация между регионами
+	RegionId      string `protobuf:"bytes,13,opt,name=region_id,json=regionId,proto3" json:"region_id,omitempty"`          // Регион, владеющий позицией игрока
+	GameAddress   string `protobuf:"bytes,14,opt,name=game_address,json=gameAddress,proto3" json:"game_address,omitempty"` // Игровой адрес этого региона (host:port)
+	Redirect      bool   `protobuf:"varint,15,opt,name=redirect,proto3" json:"redirect,omitempty"`                         // true - переподключиться к game_address; сессия не создана
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *AuthResponseMessage) Reset() {
@@ -225,6 +248,48 @@ func (x *AuthResponseMessage) GetServerInfo() *ServerInfo {
 	return nil
 }
 
+func (x *AuthResponseMessage) GetMinProtocolVersion() uint32 {
+	if x != nil {
+		return x.MinProtocolVersion
+	}
+	return 0
+}
+
+func (x *AuthResponseMessage) GetMaxProtocolVersion() uint32 {
+	if x != nil {
+		return x.MaxProtocolVersion
+	}
+	return 0
+}
+
+func (x *AuthResponseMessage) GetViewDistance() uint32 {
+	if x != nil {
+		return x.ViewDistance
+	}
+	return 0
+}
+
+func (x *AuthResponseMessage) GetRegionId() string {
+	if x != nil {
+		return x.RegionId
+	}
+	return ""
+}
+
+func (x *AuthResponseMessage) GetGameAddress() string {
+	if x != nil {
+		return x.GameAddress
+	}
+	return ""
+}
+
+func (x *AuthResponseMessage) GetRedirect() bool {
+	if x != nil {
+		return x.Redirect
+	}
+	return false
+}
+
 // Информация о сервере
 type ServerInfo struct {
 	state            protoimpl.MessageState `protogen:"open.v1"`
@@ -307,7 +372,7 @@ var File_auth_proto protoreflect.FileDescriptor
 const file_auth_proto_rawDesc = "" +
 	"\n" +
 	"\n" +
-	"auth.proto\x12\bprotocol\"\x98\x02\n" +
+	"auth.proto\x12\bprotocol\"\xe8\x02\n" +
 	"\vAuthMessage\x12\x1a\n" +
 	"\busername\x18\x01 \x01(\tR\busername\x12\x1f\n" +
 	"\bpassword\x18\x02 \x01(\tH\x00R\bpassword\x88\x01\x01\x12\x19\n" +
@@ -316,11 +381,13 @@ const file_auth_proto_rawDesc = "" +
 	"\vrequest_jwt\x18\x05 \x01(\bR\n" +
 	"requestJwt\x12%\n" +
 	"\x0eclient_version\x18\x06 \x01(\tR\rclientVersion\x12\"\n" +
-	"\fcapabilities\x18\a \x03(\tR\fcapabilitiesB\v\n" +
+	"\fcapabilities\x18\a \x03(\tR\fcapabilities\x12)\n" +
+	"\x10protocol_version\x18\b \x01(\rR\x0fprotocolVersion\x12#\n" +
+	"\rview_distance\x18\t \x01(\rR\fviewDistanceB\v\n" +
 	"\t_passwordB\b\n" +
 	"\x06_tokenB\f\n" +
 	"\n" +
-	"_jwt_token\"\xd9\x02\n" +
+	"_jwt_token\"\xbe\x04\n" +
 	"\x13AuthResponseMessage\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x12\x1b\n" +
@@ -332,7 +399,14 @@ const file_auth_proto_rawDesc = "" +
 	"\x0ejwt_expires_at\x18\a \x01(\x03R\fjwtExpiresAt\x12/\n" +
 	"\x13server_capabilities\x18\b \x03(\tR\x12serverCapabilities\x125\n" +
 	"\vserver_info\x18\t \x01(\v2\x14.protocol.ServerInfoR\n" +
-	"serverInfoB\f\n" +
+	"serverInfo\x120\n" +
+	"\x14min_protocol_version\x18\n" +
+	" \x01(\rR\x12minProtocolVersion\x120\n" +
+	"\x14max_protocol_version\x18\v \x01(\rR\x12maxProtocolVersion\x12#\n" +
+	"\rview_distance\x18\f \x01(\rR\fviewDistance\x12\x1b\n" +
+	"\tregion_id\x18\r \x01(\tR\bregionId\x12!\n" +
+	"\fgame_address\x18\x0e \x01(\tR\vgameAddress\x12\x1a\n" +
+	"\bredirect\x18\x0f \x01(\bR\bredirectB\f\n" +
 	"\n" +
 	"_jwt_token\"\xbe\x01\n" +
 	"\n" +
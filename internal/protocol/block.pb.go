@@ -27,8 +27,9 @@ type BlockUpdateRequest struct {
 	Position      *Vec2                  `protobuf:"bytes,1,opt,name=position,proto3" json:"position,omitempty"`
 	BlockId       uint32                 `protobuf:"varint,2,opt,name=block_id,json=blockId,proto3" json:"block_id,omitempty"`
 	Layer         BlockLayer             `protobuf:"varint,3,opt,name=layer,proto3,enum=protocol.BlockLayer" json:"layer,omitempty"`
-	Metadata      *JsonMetadata          `protobuf:"bytes,4,opt,name=metadata,proto3" json:"metadata,omitempty"` // JSON метаданные для блока
-	Action        string                 `protobuf:"bytes,5,opt,name=action,proto3" json:"action,omitempty"`     // Тип действия с блоком (mine, use, place, и т.д.)
+	Metadata      *JsonMetadata          `protobuf:"bytes,4,opt,name=metadata,proto3" json:"metadata,omitempty"`                           // JSON метаданные для блока
+	Action        string                 `protobuf:"bytes,5,opt,name=action,proto3" json:"action,omitempty"`                               // Тип действия с блоком (mine, use, place, и т.д.)
+	BaseVersion   uint64                 `protobuf:"varint,6,opt,name=base_version,json=baseVersion,proto3" json:"base_version,omitempty"` // Версия блока, на которой основано изменение (0 - без проверки)
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -98,6 +99,13 @@ func (x *BlockUpdateRequest) GetAction() string {
 	return ""
 }
 
+func (x *BlockUpdateRequest) GetBaseVersion() uint64 {
+	if x != nil {
+		return x.BaseVersion
+	}
+	return 0
+}
+
 // Ответ на обновление блока
 type BlockUpdateResponseMessage struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -108,6 +116,7 @@ type BlockUpdateResponseMessage struct {
 	Layer         BlockLayer             `protobuf:"varint,5,opt,name=layer,proto3,enum=protocol.BlockLayer" json:"layer,omitempty"`
 	Metadata      *JsonMetadata          `protobuf:"bytes,6,opt,name=metadata,proto3" json:"metadata,omitempty"` // JSON метаданные для блока
 	Effects       []string               `protobuf:"bytes,7,rep,name=effects,proto3" json:"effects,omitempty"`   // Эффекты, связанные с обновлением блока
+	Version       uint64                 `protobuf:"varint,8,opt,name=version,proto3" json:"version,omitempty"`  // Версия блока после изменения или текущая при отказе
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -191,6 +200,13 @@ func (x *BlockUpdateResponseMessage) GetEffects() []string {
 	return nil
 }
 
+func (x *BlockUpdateResponseMessage) GetVersion() uint64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
 // Данные о блоке
 type BlockData struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -198,6 +214,7 @@ type BlockData struct {
 	BlockId       uint32                 `protobuf:"varint,2,opt,name=block_id,json=blockId,proto3" json:"block_id,omitempty"`
 	Metadata      *JsonMetadata          `protobuf:"bytes,3,opt,name=metadata,proto3" json:"metadata,omitempty"` // JSON метаданные для блока
 	Effects       []string               `protobuf:"bytes,4,rep,name=effects,proto3" json:"effects,omitempty"`   // Визуальные эффекты
+	Version       uint64                 `protobuf:"varint,5,opt,name=version,proto3" json:"version,omitempty"`  // Версия блока на сервере
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -260,6 +277,13 @@ func (x *BlockData) GetEffects() []string {
 	return nil
 }
 
+func (x *BlockData) GetVersion() uint64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
 // Сообщение с обновлениями блоков
 type BlockUpdateMessage struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -309,13 +333,14 @@ var File_block_proto protoreflect.FileDescriptor
 
 const file_block_proto_rawDesc = "" +
 	"\n" +
-	"\vblock.proto\x12\bprotocol\x1a\fcommon.proto\"\xd3\x01\n" +
+	"\vblock.proto\x12\bprotocol\x1a\fcommon.proto\"\xf6\x01\n" +
 	"\x12BlockUpdateRequest\x12*\n" +
 	"\bposition\x18\x01 \x01(\v2\x0e.protocol.Vec2R\bposition\x12\x19\n" +
 	"\bblock_id\x18\x02 \x01(\rR\ablockId\x12*\n" +
 	"\x05layer\x18\x03 \x01(\x0e2\x14.protocol.BlockLayerR\x05layer\x122\n" +
 	"\bmetadata\x18\x04 \x01(\v2\x16.protocol.JsonMetadataR\bmetadata\x12\x16\n" +
-	"\x06action\x18\x05 \x01(\tR\x06action\"\x91\x02\n" +
+	"\x06action\x18\x05 \x01(\tR\x06action\x12!\n" +
+	"\fbase_version\x18\x06 \x01(\x04R\vbaseVersion\"\xab\x02\n" +
 	"\x1aBlockUpdateResponseMessage\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x12\x19\n" +
@@ -323,12 +348,14 @@ const file_block_proto_rawDesc = "" +
 	"\bposition\x18\x04 \x01(\v2\x0e.protocol.Vec2R\bposition\x12*\n" +
 	"\x05layer\x18\x05 \x01(\x0e2\x14.protocol.BlockLayerR\x05layer\x122\n" +
 	"\bmetadata\x18\x06 \x01(\v2\x16.protocol.JsonMetadataR\bmetadata\x12\x18\n" +
-	"\aeffects\x18\a \x03(\tR\aeffects\"\xa0\x01\n" +
+	"\aeffects\x18\a \x03(\tR\aeffects\x12\x18\n" +
+	"\aversion\x18\b \x01(\x04R\aversion\"\xba\x01\n" +
 	"\tBlockData\x12*\n" +
 	"\bposition\x18\x01 \x01(\v2\x0e.protocol.Vec2R\bposition\x12\x19\n" +
 	"\bblock_id\x18\x02 \x01(\rR\ablockId\x122\n" +
 	"\bmetadata\x18\x03 \x01(\v2\x16.protocol.JsonMetadataR\bmetadata\x12\x18\n" +
-	"\aeffects\x18\x04 \x03(\tR\aeffects\"A\n" +
+	"\aeffects\x18\x04 \x03(\tR\aeffects\x12\x18\n" +
+	"\aversion\x18\x05 \x01(\x04R\aversion\"A\n" +
 	"\x12BlockUpdateMessage\x12+\n" +
 	"\x06blocks\x18\x01 \x03(\v2\x13.protocol.BlockDataR\x06blocksB.Z,github.com/annel0/mmo-game/internal/protocolb\x06proto3"
 
@@ -0,0 +1,78 @@
+package protocol
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// MaxPooledBufferSize - буферы больше этого размера не возвращаются в пул,
+// чтобы редкие крупные сообщения (пакеты чанков) не удерживали память
+const MaxPooledBufferSize = 64 * 1024
+
+// initialBufferSize - ёмкость нового буфера пула: в неё помещаются обычные
+// сообщения (ENTITY_MOVE, чат) без перевыделения
+const initialBufferSize = 1024
+
+var bufferPool = sync.Pool{
+	New: func() any {
+		buf := make([]byte, 0, initialBufferSize)
+		return &buf
+	},
+}
+
+// GetBuffer берёт из пула пустой буфер для сериализации. Буфер возвращается
+// PutBuffer, когда его содержимое больше не используется (например, после
+// синхронной записи в сокет)
+func GetBuffer() *[]byte {
+	buf := bufferPool.Get().(*[]byte)
+	*buf = (*buf)[:0]
+	return buf
+}
+
+// PutBuffer возвращает буфер в пул. После вызова буфер и срезы его
+// содержимого использовать нельзя
+func PutBuffer(buf *[]byte) {
+	if buf == nil || cap(*buf) > MaxPooledBufferSize {
+		return
+	}
+	*buf = (*buf)[:0]
+	bufferPool.Put(buf)
+}
+
+// AppendEncoder - кодек, который дописывает сообщение в буфер вызывающего
+// вместо выделения нового (см. GetBuffer)
+type AppendEncoder interface {
+	AppendEncode(dst []byte, msgType MessageType, payload proto.Message) ([]byte, error)
+}
+
+// AppendMessage дописывает в dst сообщение, как его сериализует
+// SerializeMessage. Полезная нагрузка сериализуется во временный буфер пула
+func (ms *MessageSerializer) AppendMessage(dst []byte, msgType MessageType, payload proto.Message) ([]byte, error) {
+	scratch := GetBuffer()
+	defer PutBuffer(scratch)
+
+	payloadData, err := proto.MarshalOptions{}.MarshalAppend(*scratch, payload)
+	if err != nil {
+		return dst, fmt.Errorf("ошибка сериализации полезной нагрузки: %w", err)
+	}
+	*scratch = payloadData
+
+	gameMessage := &GameMessage{
+		Type:      msgType,
+		Timestamp: time.Now().UnixNano(),
+		Payload:   payloadData,
+	}
+	data, err := proto.MarshalOptions{}.MarshalAppend(dst, gameMessage)
+	if err != nil {
+		return dst, fmt.Errorf("ошибка сериализации сообщения: %w", err)
+	}
+	return data, nil
+}
+
+// AppendEncode дописывает GameMessage в dst (см. AppendMessage)
+func (c ProtobufCodec) AppendEncode(dst []byte, msgType MessageType, payload proto.Message) ([]byte, error) {
+	return c.serializer.AppendMessage(dst, msgType, payload)
+}
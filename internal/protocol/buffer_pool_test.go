@@ -0,0 +1,70 @@
+package protocol
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+// testEntityMove - типичное обновление мира на 32 сущности
+func testEntityMove() *EntityMoveMessage {
+	msg := &EntityMoveMessage{}
+	for i := range 32 {
+		msg.Entities = append(msg.Entities, &EntityData{
+			Id:       uint64(i + 1),
+			Position: &Vec2{X: int32(i), Y: int32(-i)},
+			Velocity: &Vec2Float{X: 1.5, Y: -0.5},
+			Active:   true,
+		})
+	}
+	return msg
+}
+
+func TestAppendMessage_AppendsDecodableMessage(t *testing.T) {
+	ms := newTestSerializer(t)
+	payload := testEntityMove()
+
+	data, err := ms.AppendMessage([]byte("hdr"), MessageType_ENTITY_MOVE, payload)
+	require.NoError(t, err)
+	assert.Equal(t, "hdr", string(data[:3]), "содержимое dst сохраняется")
+
+	msg, err := ms.DeserializeMessage(data[3:])
+	require.NoError(t, err)
+	assert.Equal(t, MessageType_ENTITY_MOVE, msg.Type)
+	decoded := &EntityMoveMessage{}
+	require.NoError(t, ms.DeserializePayload(msg, decoded))
+	assert.True(t, proto.Equal(payload, decoded))
+}
+
+func TestAppendMessage_PooledBufferAllocatesLess(t *testing.T) {
+	ms := newTestSerializer(t)
+	codec := NewProtobufCodec(ms)
+	payload := testEntityMove()
+
+	// Прежний путь отправки: новое сообщение и новый кадр на каждую запись
+	fresh := testing.AllocsPerRun(200, func() {
+		data, _ := ms.SerializeMessage(MessageType_ENTITY_MOVE, payload)
+		frame := make([]byte, 4+len(data))
+		copy(frame[4:], data)
+	})
+	pooled := testing.AllocsPerRun(200, func() {
+		buf := GetBuffer()
+		*buf, _ = codec.AppendEncode(append(*buf, 0, 0, 0, 0), MessageType_ENTITY_MOVE, payload)
+		PutBuffer(buf)
+	})
+	t.Logf("аллокаций на сообщение: %.0f без пула, %.0f с пулом", fresh, pooled)
+	assert.Less(t, pooled, fresh)
+}
+
+func TestPutBuffer_DropsOversizedBuffers(t *testing.T) {
+	big := make([]byte, 0, MaxPooledBufferSize+1)
+	PutBuffer(&big)
+	PutBuffer(nil)
+
+	buf := GetBuffer()
+	defer PutBuffer(buf)
+	assert.Empty(t, *buf)
+	assert.LessOrEqual(t, cap(*buf), MaxPooledBufferSize)
+}
@@ -26,6 +26,7 @@ type ChunkRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	ChunkX        int32                  `protobuf:"varint,1,opt,name=chunk_x,json=chunkX,proto3" json:"chunk_x,omitempty"`
 	ChunkY        int32                  `protobuf:"varint,2,opt,name=chunk_y,json=chunkY,proto3" json:"chunk_y,omitempty"`
+	KnownVersion  uint64                 `protobuf:"varint,3,opt,name=known_version,json=knownVersion,proto3" json:"known_version,omitempty"` // Версия чанка у клиента (ChunkData.version); 0 - чанка нет
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -74,6 +75,13 @@ func (x *ChunkRequest) GetChunkY() int32 {
 	return 0
 }
 
+func (x *ChunkRequest) GetKnownVersion() uint64 {
+	if x != nil {
+		return x.KnownVersion
+	}
+	return 0
+}
+
 // Запрос списка чанков
 type ChunkBatchRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -177,9 +185,12 @@ type ChunkData struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	ChunkX        int32                  `protobuf:"varint,1,opt,name=chunk_x,json=chunkX,proto3" json:"chunk_x,omitempty"`
 	ChunkY        int32                  `protobuf:"varint,2,opt,name=chunk_y,json=chunkY,proto3" json:"chunk_y,omitempty"`
-	Layers        []*ChunkLayer          `protobuf:"bytes,3,rep,name=layers,proto3" json:"layers,omitempty"`     // Все слои чанка
-	Entities      []*EntityData          `protobuf:"bytes,4,rep,name=entities,proto3" json:"entities,omitempty"` // Сущности в чанке
-	Metadata      *JsonMetadata          `protobuf:"bytes,5,opt,name=metadata,proto3" json:"metadata,omitempty"` // JSON-метаданные чанка
+	Layers        []*ChunkLayer          `protobuf:"bytes,3,rep,name=layers,proto3" json:"layers,omitempty"`                               // Все слои чанка
+	Entities      []*EntityData          `protobuf:"bytes,4,rep,name=entities,proto3" json:"entities,omitempty"`                           // Сущности в чанке
+	Metadata      *JsonMetadata          `protobuf:"bytes,5,opt,name=metadata,proto3" json:"metadata,omitempty"`                           // JSON-метаданные чанка
+	Hash          []byte                 `protobuf:"bytes,6,opt,name=hash,proto3" json:"hash,omitempty"`                                   // Канонический хэш слоёв и метаданных блоков (см. world.Chunk.Hash)
+	HashVersion   uint32                 `protobuf:"varint,7,opt,name=hash_version,json=hashVersion,proto3" json:"hash_version,omitempty"` // Версия алгоритма хэша (world.ChunkHashVersion)
+	Version       uint64                 `protobuf:"varint,8,opt,name=version,proto3" json:"version,omitempty"`                            // Версия чанка для запроса дельты (ChunkRequest.known_version)
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -249,6 +260,27 @@ func (x *ChunkData) GetMetadata() *JsonMetadata {
 	return nil
 }
 
+func (x *ChunkData) GetHash() []byte {
+	if x != nil {
+		return x.Hash
+	}
+	return nil
+}
+
+func (x *ChunkData) GetHashVersion() uint32 {
+	if x != nil {
+		return x.HashVersion
+	}
+	return 0
+}
+
+func (x *ChunkData) GetVersion() uint64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
 // Строка блоков в чанке
 type BlockRow struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -676,26 +708,76 @@ func (x *UnsubscribeBlockUpdates) GetRadius() int32 {
 	return 0
 }
 
+// Чанки вышли из зоны видимости игрока: клиент может их выгрузить, сервер
+// считает их недоставленными и пришлёт заново при возвращении игрока
+type ChunkUnload struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Chunks        []*Vec2                `protobuf:"bytes,1,rep,name=chunks,proto3" json:"chunks,omitempty"` // Координаты чанков
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChunkUnload) Reset() {
+	*x = ChunkUnload{}
+	mi := &file_chunk_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChunkUnload) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChunkUnload) ProtoMessage() {}
+
+func (x *ChunkUnload) ProtoReflect() protoreflect.Message {
+	mi := &file_chunk_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChunkUnload.ProtoReflect.Descriptor instead.
+func (*ChunkUnload) Descriptor() ([]byte, []int) {
+	return file_chunk_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ChunkUnload) GetChunks() []*Vec2 {
+	if x != nil {
+		return x.Chunks
+	}
+	return nil
+}
+
 var File_chunk_proto protoreflect.FileDescriptor
 
 const file_chunk_proto_rawDesc = "" +
 	"\n" +
-	"\vchunk.proto\x12\bprotocol\x1a\fcommon.proto\x1a\fentity.proto\"@\n" +
+	"\vchunk.proto\x12\bprotocol\x1a\fcommon.proto\x1a\fentity.proto\"e\n" +
 	"\fChunkRequest\x12\x17\n" +
 	"\achunk_x\x18\x01 \x01(\x05R\x06chunkX\x12\x17\n" +
-	"\achunk_y\x18\x02 \x01(\x05R\x06chunkY\";\n" +
+	"\achunk_y\x18\x02 \x01(\x05R\x06chunkY\x12#\n" +
+	"\rknown_version\x18\x03 \x01(\x04R\fknownVersion\";\n" +
 	"\x11ChunkBatchRequest\x12&\n" +
 	"\x06chunks\x18\x01 \x03(\v2\x0e.protocol.Vec2R\x06chunks\"J\n" +
 	"\n" +
 	"ChunkLayer\x12\x14\n" +
 	"\x05layer\x18\x01 \x01(\rR\x05layer\x12&\n" +
-	"\x04rows\x18\x02 \x03(\v2\x12.protocol.BlockRowR\x04rows\"\xd1\x01\n" +
+	"\x04rows\x18\x02 \x03(\v2\x12.protocol.BlockRowR\x04rows\"\xa2\x02\n" +
 	"\tChunkData\x12\x17\n" +
 	"\achunk_x\x18\x01 \x01(\x05R\x06chunkX\x12\x17\n" +
 	"\achunk_y\x18\x02 \x01(\x05R\x06chunkY\x12,\n" +
 	"\x06layers\x18\x03 \x03(\v2\x14.protocol.ChunkLayerR\x06layers\x120\n" +
 	"\bentities\x18\x04 \x03(\v2\x14.protocol.EntityDataR\bentities\x122\n" +
-	"\bmetadata\x18\x05 \x01(\v2\x16.protocol.JsonMetadataR\bmetadata\"'\n" +
+	"\bmetadata\x18\x05 \x01(\v2\x16.protocol.JsonMetadataR\bmetadata\x12\x12\n" +
+	"\x04hash\x18\x06 \x01(\fR\x04hash\x12!\n" +
+	"\fhash_version\x18\a \x01(\rR\vhashVersion\x12\x18\n" +
+	"\aversion\x18\b \x01(\x04R\aversion\"'\n" +
 	"\bBlockRow\x12\x1b\n" +
 	"\tblock_ids\x18\x01 \x03(\rR\bblockIds\"\xc6\x01\n" +
 	"\x12ChunkBlockMetadata\x12V\n" +
@@ -728,7 +810,9 @@ const file_chunk_proto_rawDesc = "" +
 	"\x06radius\x18\x02 \x01(\x05R\x06radius\"Y\n" +
 	"\x17UnsubscribeBlockUpdates\x12&\n" +
 	"\x06center\x18\x01 \x01(\v2\x0e.protocol.Vec2R\x06center\x12\x16\n" +
-	"\x06radius\x18\x02 \x01(\x05R\x06radiusB.Z,github.com/annel0/mmo-game/internal/protocolb\x06proto3"
+	"\x06radius\x18\x02 \x01(\x05R\x06radius\"5\n" +
+	"\vChunkUnload\x12&\n" +
+	"\x06chunks\x18\x01 \x03(\v2\x0e.protocol.Vec2R\x06chunksB.Z,github.com/annel0/mmo-game/internal/protocolb\x06proto3"
 
 var (
 	file_chunk_proto_rawDescOnce sync.Once
@@ -742,7 +826,7 @@ func file_chunk_proto_rawDescGZIP() []byte {
 	return file_chunk_proto_rawDescData
 }
 
-var file_chunk_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_chunk_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
 var file_chunk_proto_goTypes = []any{
 	(*ChunkRequest)(nil),            // 0: protocol.ChunkRequest
 	(*ChunkBatchRequest)(nil),       // 1: protocol.ChunkBatchRequest
@@ -755,32 +839,34 @@ var file_chunk_proto_goTypes = []any{
 	(*BlockEventMessage)(nil),       // 8: protocol.BlockEventMessage
 	(*SubscribeBlockUpdates)(nil),   // 9: protocol.SubscribeBlockUpdates
 	(*UnsubscribeBlockUpdates)(nil), // 10: protocol.UnsubscribeBlockUpdates
-	nil,                             // 11: protocol.ChunkBlockMetadata.BlockMetadataEntry
-	(*Vec2)(nil),                    // 12: protocol.Vec2
-	(*EntityData)(nil),              // 13: protocol.EntityData
-	(*JsonMetadata)(nil),            // 14: protocol.JsonMetadata
+	(*ChunkUnload)(nil),             // 11: protocol.ChunkUnload
+	nil,                             // 12: protocol.ChunkBlockMetadata.BlockMetadataEntry
+	(*Vec2)(nil),                    // 13: protocol.Vec2
+	(*EntityData)(nil),              // 14: protocol.EntityData
+	(*JsonMetadata)(nil),            // 15: protocol.JsonMetadata
 }
 var file_chunk_proto_depIdxs = []int32{
-	12, // 0: protocol.ChunkBatchRequest.chunks:type_name -> protocol.Vec2
+	13, // 0: protocol.ChunkBatchRequest.chunks:type_name -> protocol.Vec2
 	4,  // 1: protocol.ChunkLayer.rows:type_name -> protocol.BlockRow
 	2,  // 2: protocol.ChunkData.layers:type_name -> protocol.ChunkLayer
-	13, // 3: protocol.ChunkData.entities:type_name -> protocol.EntityData
-	14, // 4: protocol.ChunkData.metadata:type_name -> protocol.JsonMetadata
-	11, // 5: protocol.ChunkBlockMetadata.block_metadata:type_name -> protocol.ChunkBlockMetadata.BlockMetadataEntry
-	12, // 6: protocol.ChunkBlockDelta.chunk_coords:type_name -> protocol.Vec2
+	14, // 3: protocol.ChunkData.entities:type_name -> protocol.EntityData
+	15, // 4: protocol.ChunkData.metadata:type_name -> protocol.JsonMetadata
+	12, // 5: protocol.ChunkBlockMetadata.block_metadata:type_name -> protocol.ChunkBlockMetadata.BlockMetadataEntry
+	13, // 6: protocol.ChunkBlockDelta.chunk_coords:type_name -> protocol.Vec2
 	7,  // 7: protocol.ChunkBlockDelta.block_changes:type_name -> protocol.BlockChange
-	12, // 8: protocol.BlockChange.local_pos:type_name -> protocol.Vec2
-	14, // 9: protocol.BlockChange.metadata:type_name -> protocol.JsonMetadata
-	12, // 10: protocol.BlockEventMessage.world_pos:type_name -> protocol.Vec2
-	14, // 11: protocol.BlockEventMessage.metadata:type_name -> protocol.JsonMetadata
-	12, // 12: protocol.SubscribeBlockUpdates.center:type_name -> protocol.Vec2
-	12, // 13: protocol.UnsubscribeBlockUpdates.center:type_name -> protocol.Vec2
-	14, // 14: protocol.ChunkBlockMetadata.BlockMetadataEntry.value:type_name -> protocol.JsonMetadata
-	15, // [15:15] is the sub-list for method output_type
-	15, // [15:15] is the sub-list for method input_type
-	15, // [15:15] is the sub-list for extension type_name
-	15, // [15:15] is the sub-list for extension extendee
-	0,  // [0:15] is the sub-list for field type_name
+	13, // 8: protocol.BlockChange.local_pos:type_name -> protocol.Vec2
+	15, // 9: protocol.BlockChange.metadata:type_name -> protocol.JsonMetadata
+	13, // 10: protocol.BlockEventMessage.world_pos:type_name -> protocol.Vec2
+	15, // 11: protocol.BlockEventMessage.metadata:type_name -> protocol.JsonMetadata
+	13, // 12: protocol.SubscribeBlockUpdates.center:type_name -> protocol.Vec2
+	13, // 13: protocol.UnsubscribeBlockUpdates.center:type_name -> protocol.Vec2
+	13, // 14: protocol.ChunkUnload.chunks:type_name -> protocol.Vec2
+	15, // 15: protocol.ChunkBlockMetadata.BlockMetadataEntry.value:type_name -> protocol.JsonMetadata
+	16, // [16:16] is the sub-list for method output_type
+	16, // [16:16] is the sub-list for method input_type
+	16, // [16:16] is the sub-list for extension type_name
+	16, // [16:16] is the sub-list for extension extendee
+	0,  // [0:16] is the sub-list for field type_name
 }
 
 func init() { file_chunk_proto_init() }
@@ -796,7 +882,7 @@ func file_chunk_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_chunk_proto_rawDesc), len(file_chunk_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   12,
+			NumMessages:   13,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
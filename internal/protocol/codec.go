@@ -0,0 +1,182 @@
+package protocol
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// Названия кодеков сообщений
+const (
+	CodecProtobuf = "protobuf" // Компактный бинарный формат (по умолчанию)
+	CodecJSON     = "json"     // Человекочитаемый формат для отладочных клиентов
+)
+
+// Codec кодирует сообщения протокола для одного соединения. Decode всегда
+// возвращает GameMessage с полезной нагрузкой в Protocol Buffers, поэтому
+// обработчики сообщений не зависят от кодека соединения.
+type Codec interface {
+	// Name возвращает название кодека (CodecProtobuf, CodecJSON)
+	Name() string
+	// Encode кодирует сообщение указанного типа
+	Encode(msgType MessageType, payload proto.Message) ([]byte, error)
+	// Decode декодирует сообщение клиента
+	Decode(data []byte) (*GameMessage, error)
+}
+
+// NewCodec создаёт кодек по названию. Ограничения размера берутся из ms
+func NewCodec(name string, ms *MessageSerializer) (Codec, error) {
+	switch name {
+	case CodecProtobuf, "":
+		return NewProtobufCodec(ms), nil
+	case CodecJSON:
+		return NewJSONCodec(ms), nil
+	default:
+		return nil, fmt.Errorf("неизвестный кодек %q", name)
+	}
+}
+
+// DetectCodec определяет кодек по первому сообщению соединения: JSON-объект
+// начинается с '{', а этот байт не может начинать корректный GameMessage
+func DetectCodec(data []byte) string {
+	if trimmed := bytes.TrimLeft(data, " \t\r\n"); len(trimmed) > 0 && trimmed[0] == '{' {
+		return CodecJSON
+	}
+	return CodecProtobuf
+}
+
+// ProtobufCodec - кодек Protocol Buffers поверх MessageSerializer
+type ProtobufCodec struct {
+	serializer *MessageSerializer
+}
+
+// NewProtobufCodec создаёт кодек Protocol Buffers с ограничениями размера ms
+func NewProtobufCodec(ms *MessageSerializer) ProtobufCodec {
+	return ProtobufCodec{serializer: ms}
+}
+
+// Name возвращает CodecProtobuf
+func (c ProtobufCodec) Name() string { return CodecProtobuf }
+
+// Encode сериализует сообщение в GameMessage
+func (c ProtobufCodec) Encode(msgType MessageType, payload proto.Message) ([]byte, error) {
+	return c.serializer.SerializeMessage(msgType, payload)
+}
+
+// Decode десериализует GameMessage с проверкой размера
+func (c ProtobufCodec) Decode(data []byte) (*GameMessage, error) {
+	return c.serializer.DeserializeMessage(data)
+}
+
+// jsonGameMessage - JSON-представление GameMessage: тип названием, полезная
+// нагрузка объектом в формате protojson
+type jsonGameMessage struct {
+	Type      string          `json:"type"`
+	Timestamp int64           `json:"timestamp,omitempty"`
+	Sequence  uint32          `json:"sequence,omitempty"`
+	Payload   json.RawMessage `json:"payload,omitempty"`
+}
+
+// JSONCodec - человекочитаемый кодек для отладки отдельных клиентов
+type JSONCodec struct {
+	serializer *MessageSerializer
+}
+
+// NewJSONCodec создаёт кодек JSON с ограничениями размера ms
+func NewJSONCodec(ms *MessageSerializer) JSONCodec {
+	return JSONCodec{serializer: ms}
+}
+
+// Name возвращает CodecJSON
+func (c JSONCodec) Name() string { return CodecJSON }
+
+// Encode кодирует сообщение в JSON
+func (c JSONCodec) Encode(msgType MessageType, payload proto.Message) ([]byte, error) {
+	payloadData, err := protojson.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка сериализации полезной нагрузки: %w", err)
+	}
+
+	data, err := json.Marshal(jsonGameMessage{
+		Type:      msgType.String(),
+		Timestamp: time.Now().UnixNano(),
+		Payload:   payloadData,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка сериализации сообщения: %w", err)
+	}
+	return data, nil
+}
+
+// Decode разбирает JSON-сообщение клиента и перекодирует полезную нагрузку в
+// Protocol Buffers по типу сообщения
+func (c JSONCodec) Decode(data []byte) (*GameMessage, error) {
+	if err := c.serializer.CheckMessageSize(len(data)); err != nil {
+		return nil, err
+	}
+
+	var wire jsonGameMessage
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return nil, fmt.Errorf("ошибка десериализации сообщения: %w", err)
+	}
+	value, ok := MessageType_value[wire.Type]
+	if !ok {
+		return nil, fmt.Errorf("неизвестный тип сообщения %q", wire.Type)
+	}
+	msgType := MessageType(value)
+
+	payload, ok := NewRequestPayload(msgType)
+	if !ok {
+		return nil, fmt.Errorf("сообщение %s не поддерживается в JSON", msgType)
+	}
+	if len(wire.Payload) > 0 {
+		if err := protojson.Unmarshal(wire.Payload, payload); err != nil {
+			return nil, fmt.Errorf("ошибка десериализации полезной нагрузки: %w", err)
+		}
+	}
+	payloadData, err := proto.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка сериализации полезной нагрузки: %w", err)
+	}
+
+	return &GameMessage{
+		Type:      msgType,
+		Timestamp: wire.Timestamp,
+		Sequence:  wire.Sequence,
+		Payload:   payloadData,
+	}, nil
+}
+
+// NewRequestPayload возвращает пустую полезную нагрузку сообщения клиента указанного типа
+func NewRequestPayload(msgType MessageType) (proto.Message, bool) {
+	switch msgType {
+	case MessageType_AUTH:
+		return &AuthMessage{}, true
+	case MessageType_CHUNK_REQUEST:
+		return &ChunkRequest{}, true
+	case MessageType_CHUNK_BATCH_REQUEST:
+		return &ChunkBatchRequest{}, true
+	case MessageType_PING:
+		return &PingMessage{}, true
+	case MessageType_BLOCK_UPDATE:
+		return &BlockUpdateRequest{}, true
+	case MessageType_ENTITY_MOVE:
+		return &EntityMoveMessage{}, true
+	case MessageType_ENTITY_ACTION:
+		return &EntityActionRequest{}, true
+	case MessageType_CHAT:
+		return &ChatMessage{}, true
+	case MessageType_SUBSCRIBE_BLOCK_UPDATES:
+		return &SubscribeBlockUpdates{}, true
+	case MessageType_UNSUBSCRIBE_BLOCK_UPDATES:
+		return &UnsubscribeBlockUpdates{}, true
+	case MessageType_TRADE_REQUEST:
+		return &TradeRequest{}, true
+	default:
+		return nil, false
+	}
+}
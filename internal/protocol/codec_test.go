@@ -0,0 +1,68 @@
+package protocol
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestCodecs_RoundTripSameMessage(t *testing.T) {
+	ms := newTestSerializer(t)
+	original := &EntityMoveMessage{Entities: []*EntityData{{
+		Id:       42,
+		Position: &Vec2{X: 3, Y: -7},
+		Velocity: &Vec2Float{X: 0.5, Y: 0},
+	}}}
+
+	for _, name := range []string{CodecProtobuf, CodecJSON} {
+		t.Run(name, func(t *testing.T) {
+			codec, err := NewCodec(name, ms)
+			require.NoError(t, err)
+			assert.Equal(t, name, codec.Name())
+
+			data, err := codec.Encode(MessageType_ENTITY_MOVE, original)
+			require.NoError(t, err)
+			assert.Equal(t, name, DetectCodec(data), "кодек определяется по первому сообщению")
+
+			msg, err := codec.Decode(data)
+			require.NoError(t, err)
+			assert.Equal(t, MessageType_ENTITY_MOVE, msg.Type)
+
+			// Полезная нагрузка всегда приходит в Protocol Buffers
+			decoded := &EntityMoveMessage{}
+			require.NoError(t, ms.DeserializePayload(msg, decoded))
+			assert.True(t, proto.Equal(original, decoded), "получено %v", decoded)
+		})
+	}
+}
+
+func TestJSONCodec_HumanReadable(t *testing.T) {
+	ms := newTestSerializer(t)
+	codec := NewJSONCodec(ms)
+
+	data, err := codec.Encode(MessageType_CHAT, &ChatMessage{Message: "привет"})
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"type":"CHAT"`)
+	assert.Contains(t, string(data), "привет")
+
+	// Отладочный клиент пишет сообщения вручную
+	msg, err := codec.Decode([]byte(`{"type":"CHAT","payload":{"message":"hello"}}`))
+	require.NoError(t, err)
+	chat := &ChatMessage{}
+	require.NoError(t, ms.DeserializePayload(msg, chat))
+	assert.Equal(t, "hello", chat.Message)
+
+	_, err = codec.Decode([]byte(`{"type":"CHAT_BROADCAST"}`))
+	assert.Error(t, err, "сообщения сервера от клиента не принимаются")
+	_, err = codec.Decode([]byte(`{"type":"NOPE"}`))
+	assert.Error(t, err)
+
+	ms.SetMaxPayloadSize(8)
+	_, err = codec.Decode([]byte(`{"type":"CHAT","payload":{"message":"` + strings.Repeat("a", 128) + `"}}`))
+	var tooLarge *PayloadTooLargeError
+	assert.True(t, errors.As(err, &tooLarge), "ожидалась PayloadTooLargeError, получено %v", err)
+}
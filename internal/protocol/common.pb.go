@@ -51,6 +51,12 @@ const (
 	MessageType_BLOCK_EVENT               MessageType = 22 // Событие изменения блока
 	MessageType_SUBSCRIBE_BLOCK_UPDATES   MessageType = 23 // Подписка на обновления блоков
 	MessageType_UNSUBSCRIBE_BLOCK_UPDATES MessageType = 24 // Отписка от обновлений блоков
+	MessageType_ERROR                     MessageType = 25 // Ошибка обработки запроса клиента (ErrorMessage)
+	// Обмен предметами между игроками
+	MessageType_TRADE_REQUEST MessageType = 26 // Действие игрока в сделке (TradeRequest)
+	MessageType_TRADE_UPDATE  MessageType = 27 // Текущее состояние сделки (TradeUpdate)
+	MessageType_WORLD_STATE   MessageType = 28 // Состояние мира: время суток, погода, режим игры (WorldStateMessage)
+	MessageType_CHUNK_UNLOAD  MessageType = 29 // Чанки вышли из зоны видимости игрока (ChunkUnload)
 )
 
 // Enum value maps for MessageType.
@@ -81,6 +87,11 @@ var (
 		22: "BLOCK_EVENT",
 		23: "SUBSCRIBE_BLOCK_UPDATES",
 		24: "UNSUBSCRIBE_BLOCK_UPDATES",
+		25: "ERROR",
+		26: "TRADE_REQUEST",
+		27: "TRADE_UPDATE",
+		28: "WORLD_STATE",
+		29: "CHUNK_UNLOAD",
 	}
 	MessageType_value = map[string]int32{
 		"UNKNOWN":                   0,
@@ -108,6 +119,11 @@ var (
 		"BLOCK_EVENT":               22,
 		"SUBSCRIBE_BLOCK_UPDATES":   23,
 		"UNSUBSCRIBE_BLOCK_UPDATES": 24,
+		"ERROR":                     25,
+		"TRADE_REQUEST":             26,
+		"TRADE_UPDATE":              27,
+		"WORLD_STATE":               28,
+		"CHUNK_UNLOAD":              29,
 	}
 )
 
@@ -238,6 +254,68 @@ func (Layer) EnumDescriptor() ([]byte, []int) {
 	return file_common_proto_rawDescGZIP(), []int{2}
 }
 
+// Коды ошибок обработки запросов клиента
+type ErrorCode int32
+
+const (
+	ErrorCode_ERROR_CODE_UNSPECIFIED ErrorCode = 0
+	ErrorCode_UNAUTHORIZED           ErrorCode = 1 // Клиент не авторизован
+	ErrorCode_TOO_FAR                ErrorCode = 2 // Цель вне досягаемости игрока
+	ErrorCode_RATE_LIMITED           ErrorCode = 3 // Превышена частота запросов
+	ErrorCode_INVALID                ErrorCode = 4 // Некорректные данные запроса
+	ErrorCode_FORBIDDEN              ErrorCode = 5 // Действие запрещено правилами (например, режимом игры)
+	ErrorCode_CONFLICT               ErrorCode = 6 // Объект изменён после версии, на которой основан запрос
+)
+
+// Enum value maps for ErrorCode.
+var (
+	ErrorCode_name = map[int32]string{
+		0: "ERROR_CODE_UNSPECIFIED",
+		1: "UNAUTHORIZED",
+		2: "TOO_FAR",
+		3: "RATE_LIMITED",
+		4: "INVALID",
+		5: "FORBIDDEN",
+		6: "CONFLICT",
+	}
+	ErrorCode_value = map[string]int32{
+		"ERROR_CODE_UNSPECIFIED": 0,
+		"UNAUTHORIZED":           1,
+		"TOO_FAR":                2,
+		"RATE_LIMITED":           3,
+		"INVALID":                4,
+		"FORBIDDEN":              5,
+		"CONFLICT":               6,
+	}
+)
+
+func (x ErrorCode) Enum() *ErrorCode {
+	p := new(ErrorCode)
+	*p = x
+	return p
+}
+
+func (x ErrorCode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ErrorCode) Descriptor() protoreflect.EnumDescriptor {
+	return file_common_proto_enumTypes[3].Descriptor()
+}
+
+func (ErrorCode) Type() protoreflect.EnumType {
+	return &file_common_proto_enumTypes[3]
+}
+
+func (x ErrorCode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ErrorCode.Descriptor instead.
+func (ErrorCode) EnumDescriptor() ([]byte, []int) {
+	return file_common_proto_rawDescGZIP(), []int{3}
+}
+
 // Общая структура сообщения, которая содержит тип и сериализованные данные
 type GameMessage struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -474,6 +552,128 @@ func (x *Vec2Float) GetY() float32 {
 	return 0
 }
 
+// ServerMessage - служебное уведомление сервера игроку (тип SERVER_MESSAGE)
+type ServerMessage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`            // Машиночитаемый код уведомления, например "idle_warning"
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`      // Текст для отображения игроку
+	Timestamp     int64                  `protobuf:"varint,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"` // Время отправки (Unix nano)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ServerMessage) Reset() {
+	*x = ServerMessage{}
+	mi := &file_common_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServerMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServerMessage) ProtoMessage() {}
+
+func (x *ServerMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_common_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServerMessage.ProtoReflect.Descriptor instead.
+func (*ServerMessage) Descriptor() ([]byte, []int) {
+	return file_common_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ServerMessage) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *ServerMessage) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ServerMessage) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+// ErrorMessage - ответ на запрос, который сервер отклонил (тип ERROR)
+type ErrorMessage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          ErrorCode              `protobuf:"varint,1,opt,name=code,proto3,enum=protocol.ErrorCode" json:"code,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`                                           // Описание причины для логов и отображения
+	RefType       MessageType            `protobuf:"varint,3,opt,name=ref_type,json=refType,proto3,enum=protocol.MessageType" json:"ref_type,omitempty"` // Тип сообщения, вызвавшего ошибку
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ErrorMessage) Reset() {
+	*x = ErrorMessage{}
+	mi := &file_common_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ErrorMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ErrorMessage) ProtoMessage() {}
+
+func (x *ErrorMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_common_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ErrorMessage.ProtoReflect.Descriptor instead.
+func (*ErrorMessage) Descriptor() ([]byte, []int) {
+	return file_common_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ErrorMessage) GetCode() ErrorCode {
+	if x != nil {
+		return x.Code
+	}
+	return ErrorCode_ERROR_CODE_UNSPECIFIED
+}
+
+func (x *ErrorMessage) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ErrorMessage) GetRefType() MessageType {
+	if x != nil {
+		return x.RefType
+	}
+	return MessageType_UNKNOWN
+}
+
 var File_common_proto protoreflect.FileDescriptor
 
 const file_common_proto_rawDesc = "" +
@@ -495,7 +695,15 @@ const file_common_proto_rawDesc = "" +
 	"\x01y\x18\x02 \x01(\x05R\x01y\"'\n" +
 	"\tVec2Float\x12\f\n" +
 	"\x01x\x18\x01 \x01(\x02R\x01x\x12\f\n" +
-	"\x01y\x18\x02 \x01(\x02R\x01y*\xef\x03\n" +
+	"\x01y\x18\x02 \x01(\x02R\x01y\"[\n" +
+	"\rServerMessage\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x1c\n" +
+	"\ttimestamp\x18\x03 \x01(\x03R\ttimestamp\"\x83\x01\n" +
+	"\fErrorMessage\x12'\n" +
+	"\x04code\x18\x01 \x01(\x0e2\x13.protocol.ErrorCodeR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x120\n" +
+	"\bref_type\x18\x03 \x01(\x0e2\x15.protocol.MessageTypeR\arefType*\xc2\x04\n" +
 	"\vMessageType\x12\v\n" +
 	"\aUNKNOWN\x10\x00\x12\b\n" +
 	"\x04AUTH\x10\x01\x12\x11\n" +
@@ -524,7 +732,12 @@ const file_common_proto_rawDesc = "" +
 	"\x11CHUNK_BLOCK_DELTA\x10\x15\x12\x0f\n" +
 	"\vBLOCK_EVENT\x10\x16\x12\x1b\n" +
 	"\x17SUBSCRIBE_BLOCK_UPDATES\x10\x17\x12\x1d\n" +
-	"\x19UNSUBSCRIBE_BLOCK_UPDATES\x10\x18*0\n" +
+	"\x19UNSUBSCRIBE_BLOCK_UPDATES\x10\x18\x12\t\n" +
+	"\x05ERROR\x10\x19\x12\x11\n" +
+	"\rTRADE_REQUEST\x10\x1a\x12\x10\n" +
+	"\fTRADE_UPDATE\x10\x1b\x12\x0f\n" +
+	"\vWORLD_STATE\x10\x1c\x12\x10\n" +
+	"\fCHUNK_UNLOAD\x10\x1d*0\n" +
 	"\n" +
 	"BlockLayer\x12\t\n" +
 	"\x05FLOOR\x10\x00\x12\n" +
@@ -534,7 +747,15 @@ const file_common_proto_rawDesc = "" +
 	"\x05Layer\x12\x0f\n" +
 	"\vLAYER_FLOOR\x10\x00\x12\x10\n" +
 	"\fLAYER_ACTIVE\x10\x01\x12\x11\n" +
-	"\rLAYER_CEILING\x10\x02B.Z,github.com/annel0/mmo-game/internal/protocolb\x06proto3"
+	"\rLAYER_CEILING\x10\x02*\x82\x01\n" +
+	"\tErrorCode\x12\x1a\n" +
+	"\x16ERROR_CODE_UNSPECIFIED\x10\x00\x12\x10\n" +
+	"\fUNAUTHORIZED\x10\x01\x12\v\n" +
+	"\aTOO_FAR\x10\x02\x12\x10\n" +
+	"\fRATE_LIMITED\x10\x03\x12\v\n" +
+	"\aINVALID\x10\x04\x12\r\n" +
+	"\tFORBIDDEN\x10\x05\x12\f\n" +
+	"\bCONFLICT\x10\x06B.Z,github.com/annel0/mmo-game/internal/protocolb\x06proto3"
 
 var (
 	file_common_proto_rawDescOnce sync.Once
@@ -548,24 +769,29 @@ func file_common_proto_rawDescGZIP() []byte {
 	return file_common_proto_rawDescData
 }
 
-var file_common_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
-var file_common_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_common_proto_enumTypes = make([]protoimpl.EnumInfo, 4)
+var file_common_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
 var file_common_proto_goTypes = []any{
-	(MessageType)(0),     // 0: protocol.MessageType
-	(BlockLayer)(0),      // 1: protocol.BlockLayer
-	(Layer)(0),           // 2: protocol.Layer
-	(*GameMessage)(nil),  // 3: protocol.GameMessage
-	(*JsonMetadata)(nil), // 4: protocol.JsonMetadata
-	(*Vec2)(nil),         // 5: protocol.Vec2
-	(*Vec2Float)(nil),    // 6: protocol.Vec2Float
+	(MessageType)(0),      // 0: protocol.MessageType
+	(BlockLayer)(0),       // 1: protocol.BlockLayer
+	(Layer)(0),            // 2: protocol.Layer
+	(ErrorCode)(0),        // 3: protocol.ErrorCode
+	(*GameMessage)(nil),   // 4: protocol.GameMessage
+	(*JsonMetadata)(nil),  // 5: protocol.JsonMetadata
+	(*Vec2)(nil),          // 6: protocol.Vec2
+	(*Vec2Float)(nil),     // 7: protocol.Vec2Float
+	(*ServerMessage)(nil), // 8: protocol.ServerMessage
+	(*ErrorMessage)(nil),  // 9: protocol.ErrorMessage
 }
 var file_common_proto_depIdxs = []int32{
 	0, // 0: protocol.GameMessage.type:type_name -> protocol.MessageType
-	1, // [1:1] is the sub-list for method output_type
-	1, // [1:1] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	3, // 1: protocol.ErrorMessage.code:type_name -> protocol.ErrorCode
+	0, // 2: protocol.ErrorMessage.ref_type:type_name -> protocol.MessageType
+	3, // [3:3] is the sub-list for method output_type
+	3, // [3:3] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
 }
 
 func init() { file_common_proto_init() }
@@ -579,8 +805,8 @@ func file_common_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_common_proto_rawDesc), len(file_common_proto_rawDesc)),
-			NumEnums:      3,
-			NumMessages:   4,
+			NumEnums:      4,
+			NumMessages:   6,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -0,0 +1,52 @@
+package protocol
+
+// Строковые причины EntityDespawnMessage.reason. Прежние версии сервера
+// заполняли только строку, поэтому она по-прежнему отправляется вместе с
+// reason_code для клиентов, которые о коде не знают
+var despawnReasonStrings = map[DespawnReason]string{
+	DespawnReason_DESPAWN_REASON_REMOVED:      "deleted",
+	DespawnReason_DESPAWN_REASON_DISCONNECTED: "disconnected",
+	DespawnReason_DESPAWN_REASON_DEATH:        "death",
+	DespawnReason_DESPAWN_REASON_PICKED_UP:    "picked_up",
+	DespawnReason_DESPAWN_REASON_TIMEOUT:      "timeout",
+	DespawnReason_DESPAWN_REASON_KICKED:       "kicked",
+	DespawnReason_DESPAWN_REASON_OUT_OF_VIEW:  "hidden",
+	DespawnReason_DESPAWN_REASON_OWNER_LEFT:   "owner_left",
+	DespawnReason_DESPAWN_REASON_EXPIRED:      "expired",
+	DespawnReason_DESPAWN_REASON_MERGED:       "merged",
+}
+
+// LegacyString возвращает строковую причину, соответствующую коду ("" для неизвестного)
+func (x DespawnReason) LegacyString() string {
+	return despawnReasonStrings[x]
+}
+
+// DespawnReasonFromString сопоставляет строковую причину коду
+// (DESPAWN_REASON_UNSPECIFIED для неизвестной строки)
+func DespawnReasonFromString(reason string) DespawnReason {
+	for code, legacy := range despawnReasonStrings {
+		if legacy == reason {
+			return code
+		}
+	}
+	return DespawnReason_DESPAWN_REASON_UNSPECIFIED
+}
+
+// NewEntityDespawnMessage создаёт сообщение об удалении сущности с кодом
+// причины и соответствующей ему строкой
+func NewEntityDespawnMessage(entityID uint64, reason DespawnReason) *EntityDespawnMessage {
+	return &EntityDespawnMessage{
+		EntityId:   entityID,
+		Reason:     reason.LegacyString(),
+		ReasonCode: reason,
+	}
+}
+
+// ResolvedReason возвращает код причины удаления, а для сообщений старых
+// серверов без reason_code - код, восстановленный по строке
+func (x *EntityDespawnMessage) ResolvedReason() DespawnReason {
+	if code := x.GetReasonCode(); code != DespawnReason_DESPAWN_REASON_UNSPECIFIED {
+		return code
+	}
+	return DespawnReasonFromString(x.GetReason())
+}
@@ -0,0 +1,42 @@
+package protocol
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestDespawnReason_LegacyStringsRoundTrip(t *testing.T) {
+	for code := range DespawnReason_name {
+		reason := DespawnReason(code)
+		if reason == DespawnReason_DESPAWN_REASON_UNSPECIFIED {
+			continue
+		}
+		legacy := reason.LegacyString()
+		require.NotEmpty(t, legacy, "у %v нет строковой причины", reason)
+		assert.Equal(t, reason, DespawnReasonFromString(legacy))
+	}
+
+	// Строки, которые отправляли прежние версии сервера
+	assert.Equal(t, DespawnReason_DESPAWN_REASON_REMOVED, DespawnReasonFromString("deleted"))
+	assert.Equal(t, DespawnReason_DESPAWN_REASON_DISCONNECTED, DespawnReasonFromString("disconnected"))
+	assert.Equal(t, DespawnReason_DESPAWN_REASON_OUT_OF_VIEW, DespawnReasonFromString("hidden"))
+	assert.Equal(t, DespawnReason_DESPAWN_REASON_UNSPECIFIED, DespawnReasonFromString("whatever"))
+}
+
+func TestDespawnReason_MessageCarriesCodeAndString(t *testing.T) {
+	data, err := proto.Marshal(NewEntityDespawnMessage(42, DespawnReason_DESPAWN_REASON_PICKED_UP))
+	require.NoError(t, err)
+
+	msg := &EntityDespawnMessage{}
+	require.NoError(t, proto.Unmarshal(data, msg))
+	assert.Equal(t, uint64(42), msg.EntityId)
+	assert.Equal(t, "picked_up", msg.Reason)
+	assert.Equal(t, DespawnReason_DESPAWN_REASON_PICKED_UP, msg.ReasonCode)
+
+	// Сообщение старого сервера без кода
+	legacy := &EntityDespawnMessage{EntityId: 42, Reason: "disconnected"}
+	assert.Equal(t, DespawnReason_DESPAWN_REASON_DISCONNECTED, legacy.ResolvedReason())
+}
@@ -96,6 +96,7 @@ const (
 	EntityActionType_ACTION_RESPAWN     EntityActionType = 9
 	EntityActionType_ACTION_BUILD_PLACE EntityActionType = 10
 	EntityActionType_ACTION_BUILD_BREAK EntityActionType = 11
+	EntityActionType_ACTION_SPECTATOR   EntityActionType = 12 // Переключение режима наблюдателя (только администраторы)
 )
 
 // Enum value maps for EntityActionType.
@@ -113,6 +114,7 @@ var (
 		9:  "ACTION_RESPAWN",
 		10: "ACTION_BUILD_PLACE",
 		11: "ACTION_BUILD_BREAK",
+		12: "ACTION_SPECTATOR",
 	}
 	EntityActionType_value = map[string]int32{
 		"ACTION_UNKNOWN":     0,
@@ -127,6 +129,7 @@ var (
 		"ACTION_RESPAWN":     9,
 		"ACTION_BUILD_PLACE": 10,
 		"ACTION_BUILD_BREAK": 11,
+		"ACTION_SPECTATOR":   12,
 	}
 )
 
@@ -157,20 +160,97 @@ func (EntityActionType) EnumDescriptor() ([]byte, []int) {
 	return file_entity_proto_rawDescGZIP(), []int{1}
 }
 
+// Причина удаления сущности у клиента (EntityDespawnMessage.reason_code)
+type DespawnReason int32
+
+const (
+	DespawnReason_DESPAWN_REASON_UNSPECIFIED  DespawnReason = 0  // Причина не указана (старые серверы)
+	DespawnReason_DESPAWN_REASON_REMOVED      DespawnReason = 1  // Сущность удалена из мира
+	DespawnReason_DESPAWN_REASON_DISCONNECTED DespawnReason = 2  // Игрок отключился сам
+	DespawnReason_DESPAWN_REASON_DEATH        DespawnReason = 3  // Сущность погибла
+	DespawnReason_DESPAWN_REASON_PICKED_UP    DespawnReason = 4  // Предмет подобран
+	DespawnReason_DESPAWN_REASON_TIMEOUT      DespawnReason = 5  // Игрок отключён по бездействию
+	DespawnReason_DESPAWN_REASON_KICKED       DespawnReason = 6  // Игрок отключён сервером
+	DespawnReason_DESPAWN_REASON_OUT_OF_VIEW  DespawnReason = 7  // Сущность вышла из области видимости
+	DespawnReason_DESPAWN_REASON_OWNER_LEFT   DespawnReason = 8  // Владелец сущности покинул игру
+	DespawnReason_DESPAWN_REASON_EXPIRED      DespawnReason = 9  // Истёк срок жизни предмета на земле
+	DespawnReason_DESPAWN_REASON_MERGED       DespawnReason = 10 // Предмет объединён с соседней стопкой
+)
+
+// Enum value maps for DespawnReason.
+var (
+	DespawnReason_name = map[int32]string{
+		0:  "DESPAWN_REASON_UNSPECIFIED",
+		1:  "DESPAWN_REASON_REMOVED",
+		2:  "DESPAWN_REASON_DISCONNECTED",
+		3:  "DESPAWN_REASON_DEATH",
+		4:  "DESPAWN_REASON_PICKED_UP",
+		5:  "DESPAWN_REASON_TIMEOUT",
+		6:  "DESPAWN_REASON_KICKED",
+		7:  "DESPAWN_REASON_OUT_OF_VIEW",
+		8:  "DESPAWN_REASON_OWNER_LEFT",
+		9:  "DESPAWN_REASON_EXPIRED",
+		10: "DESPAWN_REASON_MERGED",
+	}
+	DespawnReason_value = map[string]int32{
+		"DESPAWN_REASON_UNSPECIFIED":  0,
+		"DESPAWN_REASON_REMOVED":      1,
+		"DESPAWN_REASON_DISCONNECTED": 2,
+		"DESPAWN_REASON_DEATH":        3,
+		"DESPAWN_REASON_PICKED_UP":    4,
+		"DESPAWN_REASON_TIMEOUT":      5,
+		"DESPAWN_REASON_KICKED":       6,
+		"DESPAWN_REASON_OUT_OF_VIEW":  7,
+		"DESPAWN_REASON_OWNER_LEFT":   8,
+		"DESPAWN_REASON_EXPIRED":      9,
+		"DESPAWN_REASON_MERGED":       10,
+	}
+)
+
+func (x DespawnReason) Enum() *DespawnReason {
+	p := new(DespawnReason)
+	*p = x
+	return p
+}
+
+func (x DespawnReason) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (DespawnReason) Descriptor() protoreflect.EnumDescriptor {
+	return file_entity_proto_enumTypes[2].Descriptor()
+}
+
+func (DespawnReason) Type() protoreflect.EnumType {
+	return &file_entity_proto_enumTypes[2]
+}
+
+func (x DespawnReason) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use DespawnReason.Descriptor instead.
+func (DespawnReason) EnumDescriptor() ([]byte, []int) {
+	return file_entity_proto_rawDescGZIP(), []int{2}
+}
+
 // Данные о сущности
 type EntityData struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
-	Type          EntityType             `protobuf:"varint,2,opt,name=type,proto3,enum=protocol.EntityType" json:"type,omitempty"`
-	Position      *Vec2                  `protobuf:"bytes,3,opt,name=position,proto3" json:"position,omitempty"`
-	Velocity      *Vec2Float             `protobuf:"bytes,4,opt,name=velocity,proto3" json:"velocity,omitempty"`
-	Direction     int32                  `protobuf:"varint,5,opt,name=direction,proto3" json:"direction,omitempty"`
-	Active        bool                   `protobuf:"varint,6,opt,name=active,proto3" json:"active,omitempty"`
-	Attributes    *JsonMetadata          `protobuf:"bytes,7,opt,name=attributes,proto3" json:"attributes,omitempty"` // JSON-метаданные атрибутов сущности
-	Animation     *string                `protobuf:"bytes,8,opt,name=animation,proto3,oneof" json:"animation,omitempty"`
-	Effects       []string               `protobuf:"bytes,9,rep,name=effects,proto3" json:"effects,omitempty"` // Визуальные эффекты
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Id         uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Type       EntityType             `protobuf:"varint,2,opt,name=type,proto3,enum=protocol.EntityType" json:"type,omitempty"`
+	Position   *Vec2                  `protobuf:"bytes,3,opt,name=position,proto3" json:"position,omitempty"`
+	Velocity   *Vec2Float             `protobuf:"bytes,4,opt,name=velocity,proto3" json:"velocity,omitempty"`
+	Direction  int32                  `protobuf:"varint,5,opt,name=direction,proto3" json:"direction,omitempty"`
+	Active     bool                   `protobuf:"varint,6,opt,name=active,proto3" json:"active,omitempty"`
+	Attributes *JsonMetadata          `protobuf:"bytes,7,opt,name=attributes,proto3" json:"attributes,omitempty"` // JSON-метаданные атрибутов сущности
+	Animation  *string                `protobuf:"bytes,8,opt,name=animation,proto3,oneof" json:"animation,omitempty"`
+	Effects    []string               `protobuf:"bytes,9,rep,name=effects,proto3" json:"effects,omitempty"` // Визуальные эффекты
+	// Данные для сверки клиентского предсказания; заполняются только для собственной сущности игрока
+	LastProcessedInput *uint32 `protobuf:"varint,10,opt,name=last_processed_input,json=lastProcessedInput,proto3,oneof" json:"last_processed_input,omitempty"` // sequence последнего обработанного ENTITY_MOVE клиента
+	ServerTick         *uint64 `protobuf:"varint,11,opt,name=server_tick,json=serverTick,proto3,oneof" json:"server_tick,omitempty"`                           // Тик сервера, на котором получено состояние
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
 }
 
 func (x *EntityData) Reset() {
@@ -266,6 +346,20 @@ func (x *EntityData) GetEffects() []string {
 	return nil
 }
 
+func (x *EntityData) GetLastProcessedInput() uint32 {
+	if x != nil && x.LastProcessedInput != nil {
+		return *x.LastProcessedInput
+	}
+	return 0
+}
+
+func (x *EntityData) GetServerTick() uint64 {
+	if x != nil && x.ServerTick != nil {
+		return *x.ServerTick
+	}
+	return 0
+}
+
 // Сообщение о создании сущности
 type EntitySpawnMessage struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -313,8 +407,11 @@ func (x *EntitySpawnMessage) GetEntity() *EntityData {
 
 // Сообщение о перемещении сущности
 type EntityMoveMessage struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Entities      []*EntityData          `protobuf:"bytes,1,rep,name=entities,proto3" json:"entities,omitempty"`
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Entities []*EntityData          `protobuf:"bytes,1,rep,name=entities,proto3" json:"entities,omitempty"`
+	// Метка времени позиций для интерполяции на клиенте; не заполняется, если отключена
+	ServerTick    *uint64 `protobuf:"varint,2,opt,name=server_tick,json=serverTick,proto3,oneof" json:"server_tick,omitempty"`         // Тик сервера, на котором сняты позиции
+	ServerTimeMs  *int64  `protobuf:"varint,3,opt,name=server_time_ms,json=serverTimeMs,proto3,oneof" json:"server_time_ms,omitempty"` // Монотонное время сервера, мс
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -356,11 +453,26 @@ func (x *EntityMoveMessage) GetEntities() []*EntityData {
 	return nil
 }
 
+func (x *EntityMoveMessage) GetServerTick() uint64 {
+	if x != nil && x.ServerTick != nil {
+		return *x.ServerTick
+	}
+	return 0
+}
+
+func (x *EntityMoveMessage) GetServerTimeMs() int64 {
+	if x != nil && x.ServerTimeMs != nil {
+		return *x.ServerTimeMs
+	}
+	return 0
+}
+
 // Сообщение об удалении сущности
 type EntityDespawnMessage struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	EntityId      uint64                 `protobuf:"varint,1,opt,name=entity_id,json=entityId,proto3" json:"entity_id,omitempty"`
-	Reason        string                 `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	Reason        string                 `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"` // Устаревшая строковая причина, см. reason_code
+	ReasonCode    DespawnReason          `protobuf:"varint,3,opt,name=reason_code,json=reasonCode,proto3,enum=protocol.DespawnReason" json:"reason_code,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -409,6 +521,13 @@ func (x *EntityDespawnMessage) GetReason() string {
 	return ""
 }
 
+func (x *EntityDespawnMessage) GetReasonCode() DespawnReason {
+	if x != nil {
+		return x.ReasonCode
+	}
+	return DespawnReason_DESPAWN_REASON_UNSPECIFIED
+}
+
 // Запрос на действие сущности
 type EntityActionRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -547,11 +666,165 @@ func (x *EntityActionResponse) GetResults() *JsonMetadata {
 	return nil
 }
 
+// Активный статус-эффект сущности
+type StatusEffect struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Type             string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`                                                   // poison, regeneration, haste
+	Intensity        float32                `protobuf:"fixed32,2,opt,name=intensity,proto3" json:"intensity,omitempty"`                                       // Сила эффекта
+	RemainingSeconds float32                `protobuf:"fixed32,3,opt,name=remaining_seconds,json=remainingSeconds,proto3" json:"remaining_seconds,omitempty"` // Оставшаяся длительность
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *StatusEffect) Reset() {
+	*x = StatusEffect{}
+	mi := &file_entity_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatusEffect) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatusEffect) ProtoMessage() {}
+
+func (x *StatusEffect) ProtoReflect() protoreflect.Message {
+	mi := &file_entity_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatusEffect.ProtoReflect.Descriptor instead.
+func (*StatusEffect) Descriptor() ([]byte, []int) {
+	return file_entity_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *StatusEffect) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *StatusEffect) GetIntensity() float32 {
+	if x != nil {
+		return x.Intensity
+	}
+	return 0
+}
+
+func (x *StatusEffect) GetRemainingSeconds() float32 {
+	if x != nil {
+		return x.RemainingSeconds
+	}
+	return 0
+}
+
+// Характеристики игрока (тип PLAYER_STATS)
+type PlayerStatsMessage struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	EntityId        uint64                 `protobuf:"varint,1,opt,name=entity_id,json=entityId,proto3" json:"entity_id,omitempty"`
+	Health          int32                  `protobuf:"varint,2,opt,name=health,proto3" json:"health,omitempty"`
+	MaxHealth       int32                  `protobuf:"varint,3,opt,name=max_health,json=maxHealth,proto3" json:"max_health,omitempty"`
+	SpeedMultiplier float32                `protobuf:"fixed32,4,opt,name=speed_multiplier,json=speedMultiplier,proto3" json:"speed_multiplier,omitempty"` // Множитель скорости от эффектов
+	Effects         []*StatusEffect        `protobuf:"bytes,5,rep,name=effects,proto3" json:"effects,omitempty"`
+	Level           int32                  `protobuf:"varint,6,opt,name=level,proto3" json:"level,omitempty"`
+	Experience      int32                  `protobuf:"varint,7,opt,name=experience,proto3" json:"experience,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *PlayerStatsMessage) Reset() {
+	*x = PlayerStatsMessage{}
+	mi := &file_entity_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlayerStatsMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlayerStatsMessage) ProtoMessage() {}
+
+func (x *PlayerStatsMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_entity_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlayerStatsMessage.ProtoReflect.Descriptor instead.
+func (*PlayerStatsMessage) Descriptor() ([]byte, []int) {
+	return file_entity_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *PlayerStatsMessage) GetEntityId() uint64 {
+	if x != nil {
+		return x.EntityId
+	}
+	return 0
+}
+
+func (x *PlayerStatsMessage) GetHealth() int32 {
+	if x != nil {
+		return x.Health
+	}
+	return 0
+}
+
+func (x *PlayerStatsMessage) GetMaxHealth() int32 {
+	if x != nil {
+		return x.MaxHealth
+	}
+	return 0
+}
+
+func (x *PlayerStatsMessage) GetSpeedMultiplier() float32 {
+	if x != nil {
+		return x.SpeedMultiplier
+	}
+	return 0
+}
+
+func (x *PlayerStatsMessage) GetEffects() []*StatusEffect {
+	if x != nil {
+		return x.Effects
+	}
+	return nil
+}
+
+func (x *PlayerStatsMessage) GetLevel() int32 {
+	if x != nil {
+		return x.Level
+	}
+	return 0
+}
+
+func (x *PlayerStatsMessage) GetExperience() int32 {
+	if x != nil {
+		return x.Experience
+	}
+	return 0
+}
+
 var File_entity_proto protoreflect.FileDescriptor
 
 const file_entity_proto_rawDesc = "" +
 	"\n" +
-	"\fentity.proto\x12\bprotocol\x1a\fcommon.proto\"\xdc\x02\n" +
+	"\fentity.proto\x12\bprotocol\x1a\fcommon.proto\"\xe2\x03\n" +
 	"\n" +
 	"EntityData\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x04R\x02id\x12(\n" +
@@ -564,16 +837,29 @@ const file_entity_proto_rawDesc = "" +
 	"attributes\x18\a \x01(\v2\x16.protocol.JsonMetadataR\n" +
 	"attributes\x12!\n" +
 	"\tanimation\x18\b \x01(\tH\x00R\tanimation\x88\x01\x01\x12\x18\n" +
-	"\aeffects\x18\t \x03(\tR\aeffectsB\f\n" +
+	"\aeffects\x18\t \x03(\tR\aeffects\x125\n" +
+	"\x14last_processed_input\x18\n" +
+	" \x01(\rH\x01R\x12lastProcessedInput\x88\x01\x01\x12$\n" +
+	"\vserver_tick\x18\v \x01(\x04H\x02R\n" +
+	"serverTick\x88\x01\x01B\f\n" +
 	"\n" +
-	"_animation\"B\n" +
+	"_animationB\x17\n" +
+	"\x15_last_processed_inputB\x0e\n" +
+	"\f_server_tick\"B\n" +
 	"\x12EntitySpawnMessage\x12,\n" +
-	"\x06entity\x18\x01 \x01(\v2\x14.protocol.EntityDataR\x06entity\"E\n" +
+	"\x06entity\x18\x01 \x01(\v2\x14.protocol.EntityDataR\x06entity\"\xb9\x01\n" +
 	"\x11EntityMoveMessage\x120\n" +
-	"\bentities\x18\x01 \x03(\v2\x14.protocol.EntityDataR\bentities\"K\n" +
+	"\bentities\x18\x01 \x03(\v2\x14.protocol.EntityDataR\bentities\x12$\n" +
+	"\vserver_tick\x18\x02 \x01(\x04H\x00R\n" +
+	"serverTick\x88\x01\x01\x12)\n" +
+	"\x0eserver_time_ms\x18\x03 \x01(\x03H\x01R\fserverTimeMs\x88\x01\x01B\x0e\n" +
+	"\f_server_tickB\x11\n" +
+	"\x0f_server_time_ms\"\x85\x01\n" +
 	"\x14EntityDespawnMessage\x12\x1b\n" +
 	"\tentity_id\x18\x01 \x01(\x04R\bentityId\x12\x16\n" +
-	"\x06reason\x18\x02 \x01(\tR\x06reason\"\x9a\x02\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\x128\n" +
+	"\vreason_code\x18\x03 \x01(\x0e2\x17.protocol.DespawnReasonR\n" +
+	"reasonCode\"\x9a\x02\n" +
 	"\x13EntityActionRequest\x12;\n" +
 	"\vaction_type\x18\x01 \x01(\x0e2\x1a.protocol.EntityActionTypeR\n" +
 	"actionType\x12 \n" +
@@ -589,7 +875,22 @@ const file_entity_proto_rawDesc = "" +
 	"\x14EntityActionResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x120\n" +
-	"\aresults\x18\x03 \x01(\v2\x16.protocol.JsonMetadataR\aresults*\x7f\n" +
+	"\aresults\x18\x03 \x01(\v2\x16.protocol.JsonMetadataR\aresults\"m\n" +
+	"\fStatusEffect\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x1c\n" +
+	"\tintensity\x18\x02 \x01(\x02R\tintensity\x12+\n" +
+	"\x11remaining_seconds\x18\x03 \x01(\x02R\x10remainingSeconds\"\xfb\x01\n" +
+	"\x12PlayerStatsMessage\x12\x1b\n" +
+	"\tentity_id\x18\x01 \x01(\x04R\bentityId\x12\x16\n" +
+	"\x06health\x18\x02 \x01(\x05R\x06health\x12\x1d\n" +
+	"\n" +
+	"max_health\x18\x03 \x01(\x05R\tmaxHealth\x12)\n" +
+	"\x10speed_multiplier\x18\x04 \x01(\x02R\x0fspeedMultiplier\x120\n" +
+	"\aeffects\x18\x05 \x03(\v2\x16.protocol.StatusEffectR\aeffects\x12\x14\n" +
+	"\x05level\x18\x06 \x01(\x05R\x05level\x12\x1e\n" +
+	"\n" +
+	"experience\x18\a \x01(\x05R\n" +
+	"experience*\x7f\n" +
 	"\n" +
 	"EntityType\x12\x12\n" +
 	"\x0eENTITY_UNKNOWN\x10\x00\x12\x11\n" +
@@ -598,7 +899,7 @@ const file_entity_proto_rawDesc = "" +
 	"ENTITY_NPC\x10\x02\x12\x12\n" +
 	"\x0eENTITY_MONSTER\x10\x03\x12\x0f\n" +
 	"\vENTITY_ITEM\x10\x04\x12\x15\n" +
-	"\x11ENTITY_PROJECTILE\x10\x05*\x81\x02\n" +
+	"\x11ENTITY_PROJECTILE\x10\x05*\x97\x02\n" +
 	"\x10EntityActionType\x12\x12\n" +
 	"\x0eACTION_UNKNOWN\x10\x00\x12\x13\n" +
 	"\x0fACTION_INTERACT\x10\x01\x12\x11\n" +
@@ -612,7 +913,21 @@ const file_entity_proto_rawDesc = "" +
 	"\x0eACTION_RESPAWN\x10\t\x12\x16\n" +
 	"\x12ACTION_BUILD_PLACE\x10\n" +
 	"\x12\x16\n" +
-	"\x12ACTION_BUILD_BREAK\x10\vB.Z,github.com/annel0/mmo-game/internal/protocolb\x06proto3"
+	"\x12ACTION_BUILD_BREAK\x10\v\x12\x14\n" +
+	"\x10ACTION_SPECTATOR\x10\f*\xd1\x02\n" +
+	"\rDespawnReason\x12\x1e\n" +
+	"\x1aDESPAWN_REASON_UNSPECIFIED\x10\x00\x12\x1a\n" +
+	"\x16DESPAWN_REASON_REMOVED\x10\x01\x12\x1f\n" +
+	"\x1bDESPAWN_REASON_DISCONNECTED\x10\x02\x12\x18\n" +
+	"\x14DESPAWN_REASON_DEATH\x10\x03\x12\x1c\n" +
+	"\x18DESPAWN_REASON_PICKED_UP\x10\x04\x12\x1a\n" +
+	"\x16DESPAWN_REASON_TIMEOUT\x10\x05\x12\x19\n" +
+	"\x15DESPAWN_REASON_KICKED\x10\x06\x12\x1e\n" +
+	"\x1aDESPAWN_REASON_OUT_OF_VIEW\x10\a\x12\x1d\n" +
+	"\x19DESPAWN_REASON_OWNER_LEFT\x10\b\x12\x1a\n" +
+	"\x16DESPAWN_REASON_EXPIRED\x10\t\x12\x19\n" +
+	"\x15DESPAWN_REASON_MERGED\x10\n" +
+	"B.Z,github.com/annel0/mmo-game/internal/protocolb\x06proto3"
 
 var (
 	file_entity_proto_rawDescOnce sync.Once
@@ -626,37 +941,42 @@ func file_entity_proto_rawDescGZIP() []byte {
 	return file_entity_proto_rawDescData
 }
 
-var file_entity_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
-var file_entity_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_entity_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
+var file_entity_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
 var file_entity_proto_goTypes = []any{
 	(EntityType)(0),              // 0: protocol.EntityType
 	(EntityActionType)(0),        // 1: protocol.EntityActionType
-	(*EntityData)(nil),           // 2: protocol.EntityData
-	(*EntitySpawnMessage)(nil),   // 3: protocol.EntitySpawnMessage
-	(*EntityMoveMessage)(nil),    // 4: protocol.EntityMoveMessage
-	(*EntityDespawnMessage)(nil), // 5: protocol.EntityDespawnMessage
-	(*EntityActionRequest)(nil),  // 6: protocol.EntityActionRequest
-	(*EntityActionResponse)(nil), // 7: protocol.EntityActionResponse
-	(*Vec2)(nil),                 // 8: protocol.Vec2
-	(*Vec2Float)(nil),            // 9: protocol.Vec2Float
-	(*JsonMetadata)(nil),         // 10: protocol.JsonMetadata
+	(DespawnReason)(0),           // 2: protocol.DespawnReason
+	(*EntityData)(nil),           // 3: protocol.EntityData
+	(*EntitySpawnMessage)(nil),   // 4: protocol.EntitySpawnMessage
+	(*EntityMoveMessage)(nil),    // 5: protocol.EntityMoveMessage
+	(*EntityDespawnMessage)(nil), // 6: protocol.EntityDespawnMessage
+	(*EntityActionRequest)(nil),  // 7: protocol.EntityActionRequest
+	(*EntityActionResponse)(nil), // 8: protocol.EntityActionResponse
+	(*StatusEffect)(nil),         // 9: protocol.StatusEffect
+	(*PlayerStatsMessage)(nil),   // 10: protocol.PlayerStatsMessage
+	(*Vec2)(nil),                 // 11: protocol.Vec2
+	(*Vec2Float)(nil),            // 12: protocol.Vec2Float
+	(*JsonMetadata)(nil),         // 13: protocol.JsonMetadata
 }
 var file_entity_proto_depIdxs = []int32{
 	0,  // 0: protocol.EntityData.type:type_name -> protocol.EntityType
-	8,  // 1: protocol.EntityData.position:type_name -> protocol.Vec2
-	9,  // 2: protocol.EntityData.velocity:type_name -> protocol.Vec2Float
-	10, // 3: protocol.EntityData.attributes:type_name -> protocol.JsonMetadata
-	2,  // 4: protocol.EntitySpawnMessage.entity:type_name -> protocol.EntityData
-	2,  // 5: protocol.EntityMoveMessage.entities:type_name -> protocol.EntityData
-	1,  // 6: protocol.EntityActionRequest.action_type:type_name -> protocol.EntityActionType
-	8,  // 7: protocol.EntityActionRequest.position:type_name -> protocol.Vec2
-	10, // 8: protocol.EntityActionRequest.params:type_name -> protocol.JsonMetadata
-	10, // 9: protocol.EntityActionResponse.results:type_name -> protocol.JsonMetadata
-	10, // [10:10] is the sub-list for method output_type
-	10, // [10:10] is the sub-list for method input_type
-	10, // [10:10] is the sub-list for extension type_name
-	10, // [10:10] is the sub-list for extension extendee
-	0,  // [0:10] is the sub-list for field type_name
+	11, // 1: protocol.EntityData.position:type_name -> protocol.Vec2
+	12, // 2: protocol.EntityData.velocity:type_name -> protocol.Vec2Float
+	13, // 3: protocol.EntityData.attributes:type_name -> protocol.JsonMetadata
+	3,  // 4: protocol.EntitySpawnMessage.entity:type_name -> protocol.EntityData
+	3,  // 5: protocol.EntityMoveMessage.entities:type_name -> protocol.EntityData
+	2,  // 6: protocol.EntityDespawnMessage.reason_code:type_name -> protocol.DespawnReason
+	1,  // 7: protocol.EntityActionRequest.action_type:type_name -> protocol.EntityActionType
+	11, // 8: protocol.EntityActionRequest.position:type_name -> protocol.Vec2
+	13, // 9: protocol.EntityActionRequest.params:type_name -> protocol.JsonMetadata
+	13, // 10: protocol.EntityActionResponse.results:type_name -> protocol.JsonMetadata
+	9,  // 11: protocol.PlayerStatsMessage.effects:type_name -> protocol.StatusEffect
+	12, // [12:12] is the sub-list for method output_type
+	12, // [12:12] is the sub-list for method input_type
+	12, // [12:12] is the sub-list for extension type_name
+	12, // [12:12] is the sub-list for extension extendee
+	0,  // [0:12] is the sub-list for field type_name
 }
 
 func init() { file_entity_proto_init() }
@@ -666,14 +986,15 @@ func file_entity_proto_init() {
 	}
 	file_common_proto_init()
 	file_entity_proto_msgTypes[0].OneofWrappers = []any{}
+	file_entity_proto_msgTypes[2].OneofWrappers = []any{}
 	file_entity_proto_msgTypes[4].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_entity_proto_rawDesc), len(file_entity_proto_rawDesc)),
-			NumEnums:      2,
-			NumMessages:   6,
+			NumEnums:      3,
+			NumMessages:   8,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -13,6 +13,8 @@ const (
 	EventTypeBlock EventType = "block"
 	// EventTypeChat - события чата
 	EventTypeChat EventType = "chat"
+	// EventTypeAnticheatEvidence - действия игрока перед нарушением античита
+	EventTypeAnticheatEvidence EventType = "anticheat.evidence"
 )
 
 // Event представляет базовое событие
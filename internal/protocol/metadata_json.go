@@ -0,0 +1,114 @@
+package protocol
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Метаданные блоков и сущностей передаются и хранятся в JSON, где у чисел
+// нет типа: стандартный encoding/json превращает любое число в float64, а
+// float64(2) кодирует как "2". Чтобы целые значения оставались int после
+// сохранения/загрузки и передачи по сети, дробные числа всегда кодируются с
+// дробной частью ("2.0"), а при декодировании числа без дробной части и
+// экспоненты восстанавливаются как int.
+
+// MarshalMetadata кодирует метаданные в JSON с сохранением типов чисел
+func MarshalMetadata(metadata map[string]interface{}) ([]byte, error) {
+	if metadata == nil {
+		return []byte("null"), nil
+	}
+	return json.Marshal(preserveNumberTypes(metadata))
+}
+
+// UnmarshalMetadata декодирует метаданные из JSON, восстанавливая целые числа
+// как int, а дробные как float64. Пустые данные и null дают nil
+func UnmarshalMetadata(data []byte) (map[string]interface{}, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || bytes.Equal(data, []byte("null")) {
+		return nil, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var result map[string]interface{}
+	if err := decoder.Decode(&result); err != nil {
+		return nil, err
+	}
+	return restoreNumberTypes(result).(map[string]interface{}), nil
+}
+
+// preserveNumberTypes рекурсивно заменяет дробные числа на json.Number с
+// явной дробной частью, чтобы float64(2) не превратился в int при чтении
+func preserveNumberTypes(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			result[key] = preserveNumberTypes(item)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = preserveNumberTypes(item)
+		}
+		return result
+	case []float64:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = preserveNumberTypes(item)
+		}
+		return result
+	case float32:
+		return floatNumber(float64(v), 32)
+	case float64:
+		return floatNumber(v, 64)
+	default:
+		return value
+	}
+}
+
+// floatNumber форматирует дробное число так, чтобы в нём всегда была точка
+// или экспонента. NaN и бесконечности возвращаются как есть — json.Marshal
+// сообщит о них ошибкой
+func floatNumber(f float64, bitSize int) interface{} {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return f
+	}
+	s := strconv.FormatFloat(f, 'g', -1, bitSize)
+	if !strings.ContainsAny(s, ".eE") {
+		s += ".0"
+	}
+	return json.Number(s)
+}
+
+// restoreNumberTypes рекурсивно переводит json.Number в int или float64
+func restoreNumberTypes(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = restoreNumberTypes(item)
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = restoreNumberTypes(item)
+		}
+		return v
+	case json.Number:
+		s := v.String()
+		if !strings.ContainsAny(s, ".eE") {
+			if i, err := strconv.ParseInt(s, 10, 0); err == nil {
+				return int(i)
+			}
+		}
+		f, _ := v.Float64()
+		return f
+	default:
+		return value
+	}
+}
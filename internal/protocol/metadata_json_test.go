@@ -0,0 +1,73 @@
+package protocol
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetadataJSON_RoundTripPreservesTypes(t *testing.T) {
+	original := map[string]interface{}{
+		"level":    7,
+		"negative": -3,
+		"speed":    1.5,
+		"whole":    2.0,
+		"tiny":     1e-9,
+		"lit":      true,
+		"text":     "hello",
+		"items":    []interface{}{1, 2.0, "three", false},
+		"nested":   map[string]interface{}{"count": 4, "ratio": 0.25},
+	}
+
+	jsonStr, err := MapToJsonMetadata(original)
+	require.NoError(t, err)
+
+	restored, err := JsonToMap(jsonStr)
+	require.NoError(t, err)
+	assert.Equal(t, original, restored)
+
+	// Повторный цикл не должен менять ни значения, ни типы
+	again, err := MapToJsonMetadata(restored)
+	require.NoError(t, err)
+	assert.JSONEq(t, jsonStr, again)
+}
+
+func TestMetadataJSON_WholeFloatKeepsFractionalPart(t *testing.T) {
+	jsonStr, err := MapToJsonMetadata(map[string]interface{}{"whole": 2.0, "count": 2})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"whole": 2.0, "count": 2}`, jsonStr)
+	assert.Contains(t, jsonStr, `"whole":2.0`)
+}
+
+func TestMetadataJSON_FloatSliceBecomesFloats(t *testing.T) {
+	jsonStr, err := MapToJsonMetadata(map[string]interface{}{"path": []float64{1, 2.5}})
+	require.NoError(t, err)
+
+	restored, err := JsonToMap(jsonStr)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{1.0, 2.5}, restored["path"])
+}
+
+func TestMetadataJSON_ClientNumbers(t *testing.T) {
+	restored, err := JsonToMap(`{"a": 3, "b": 3.0, "c": 3e2, "d": 99999999999999999999}`)
+	require.NoError(t, err)
+	assert.Equal(t, 3, restored["a"])
+	assert.Equal(t, 3.0, restored["b"])
+	assert.Equal(t, 300.0, restored["c"])
+	// Не помещается в int — остаётся float64
+	assert.IsType(t, float64(0), restored["d"])
+}
+
+func TestMetadataJSON_NilAndInvalid(t *testing.T) {
+	jsonStr, err := MapToJsonMetadata(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "null", jsonStr)
+
+	restored, err := JsonToMap(jsonStr)
+	require.NoError(t, err)
+	assert.Nil(t, restored)
+
+	_, err = JsonToMap(`{"broken":`)
+	assert.Error(t, err)
+}
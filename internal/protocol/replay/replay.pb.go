@@ -647,6 +647,229 @@ func (x *EventTypeInfo) GetRegions() []string {
 	return nil
 }
 
+// Запрос состояния сервиса
+type HealthRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HealthRequest) Reset() {
+	*x = HealthRequest{}
+	mi := &file_internal_protocol_proto_replay_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HealthRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HealthRequest) ProtoMessage() {}
+
+func (x *HealthRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_protocol_proto_replay_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HealthRequest.ProtoReflect.Descriptor instead.
+func (*HealthRequest) Descriptor() ([]byte, []int) {
+	return file_internal_protocol_proto_replay_proto_rawDescGZIP(), []int{7}
+}
+
+// Состояние сервиса воспроизведения
+type HealthResponse struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Healthy         bool                   `protobuf:"varint,1,opt,name=healthy,proto3" json:"healthy,omitempty"`                                                                                                                    // Сервис готов отвечать на запросы
+	Backend         *BackendStatus         `protobuf:"bytes,2,opt,name=backend,proto3" json:"backend,omitempty"`                                                                                                                     // Состояние бэкенда событий
+	TotalEvents     int64                  `protobuf:"varint,3,opt,name=total_events,json=totalEvents,proto3" json:"total_events,omitempty"`                                                                                         // Всего событий (если бэкенд доступен)
+	EventTypeCounts map[string]int64       `protobuf:"bytes,4,rep,name=event_type_counts,json=eventTypeCounts,proto3" json:"event_type_counts,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"` // Количество событий по типам
+	CheckedAt       *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=checked_at,json=checkedAt,proto3" json:"checked_at,omitempty"`                                                                                                // Время проверки
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *HealthResponse) Reset() {
+	*x = HealthResponse{}
+	mi := &file_internal_protocol_proto_replay_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HealthResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HealthResponse) ProtoMessage() {}
+
+func (x *HealthResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_protocol_proto_replay_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HealthResponse.ProtoReflect.Descriptor instead.
+func (*HealthResponse) Descriptor() ([]byte, []int) {
+	return file_internal_protocol_proto_replay_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *HealthResponse) GetHealthy() bool {
+	if x != nil {
+		return x.Healthy
+	}
+	return false
+}
+
+func (x *HealthResponse) GetBackend() *BackendStatus {
+	if x != nil {
+		return x.Backend
+	}
+	return nil
+}
+
+func (x *HealthResponse) GetTotalEvents() int64 {
+	if x != nil {
+		return x.TotalEvents
+	}
+	return 0
+}
+
+func (x *HealthResponse) GetEventTypeCounts() map[string]int64 {
+	if x != nil {
+		return x.EventTypeCounts
+	}
+	return nil
+}
+
+func (x *HealthResponse) GetCheckedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CheckedAt
+	}
+	return nil
+}
+
+// Состояние бэкенда событий (NATS JetStream и т.п.)
+type BackendStatus struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Backend          string                 `protobuf:"bytes,1,opt,name=backend,proto3" json:"backend,omitempty"`                                            // Тип бэкенда
+	Connected        bool                   `protobuf:"varint,2,opt,name=connected,proto3" json:"connected,omitempty"`                                       // Бэкенд доступен
+	Error            string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`                                                // Причина недоступности
+	Stream           string                 `protobuf:"bytes,4,opt,name=stream,proto3" json:"stream,omitempty"`                                              // Имя стрима
+	Messages         uint64                 `protobuf:"varint,5,opt,name=messages,proto3" json:"messages,omitempty"`                                         // Сообщений в стриме
+	Bytes            uint64                 `protobuf:"varint,6,opt,name=bytes,proto3" json:"bytes,omitempty"`                                               // Объём стрима в байтах
+	RetentionSeconds int64                  `protobuf:"varint,7,opt,name=retention_seconds,json=retentionSeconds,proto3" json:"retention_seconds,omitempty"` // Максимальный возраст событий (0 = без ограничения)
+	FirstEvent       *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=first_event,json=firstEvent,proto3" json:"first_event,omitempty"`                    // Самое старое событие в стриме
+	LastEvent        *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=last_event,json=lastEvent,proto3" json:"last_event,omitempty"`                       // Самое новое событие в стриме
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *BackendStatus) Reset() {
+	*x = BackendStatus{}
+	mi := &file_internal_protocol_proto_replay_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BackendStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BackendStatus) ProtoMessage() {}
+
+func (x *BackendStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_protocol_proto_replay_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BackendStatus.ProtoReflect.Descriptor instead.
+func (*BackendStatus) Descriptor() ([]byte, []int) {
+	return file_internal_protocol_proto_replay_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *BackendStatus) GetBackend() string {
+	if x != nil {
+		return x.Backend
+	}
+	return ""
+}
+
+func (x *BackendStatus) GetConnected() bool {
+	if x != nil {
+		return x.Connected
+	}
+	return false
+}
+
+func (x *BackendStatus) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *BackendStatus) GetStream() string {
+	if x != nil {
+		return x.Stream
+	}
+	return ""
+}
+
+func (x *BackendStatus) GetMessages() uint64 {
+	if x != nil {
+		return x.Messages
+	}
+	return 0
+}
+
+func (x *BackendStatus) GetBytes() uint64 {
+	if x != nil {
+		return x.Bytes
+	}
+	return 0
+}
+
+func (x *BackendStatus) GetRetentionSeconds() int64 {
+	if x != nil {
+		return x.RetentionSeconds
+	}
+	return 0
+}
+
+func (x *BackendStatus) GetFirstEvent() *timestamppb.Timestamp {
+	if x != nil {
+		return x.FirstEvent
+	}
+	return nil
+}
+
+func (x *BackendStatus) GetLastEvent() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastEvent
+	}
+	return nil
+}
+
 var File_internal_protocol_proto_replay_proto protoreflect.FileDescriptor
 
 const file_internal_protocol_proto_replay_proto_rawDesc = "" +
@@ -717,11 +940,35 @@ const file_internal_protocol_proto_replay_proto_rawDesc = "" +
 	"\n" +
 	"first_seen\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tfirstSeen\x127\n" +
 	"\tlast_seen\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\blastSeen\x12\x18\n" +
-	"\aregions\x18\x06 \x03(\tR\aregions2\xd9\x01\n" +
+	"\aregions\x18\x06 \x03(\tR\aregions\"\x0f\n" +
+	"\rHealthRequest\"\xd6\x02\n" +
+	"\x0eHealthResponse\x12\x18\n" +
+	"\ahealthy\x18\x01 \x01(\bR\ahealthy\x12/\n" +
+	"\abackend\x18\x02 \x01(\v2\x15.replay.BackendStatusR\abackend\x12!\n" +
+	"\ftotal_events\x18\x03 \x01(\x03R\vtotalEvents\x12W\n" +
+	"\x11event_type_counts\x18\x04 \x03(\v2+.replay.HealthResponse.EventTypeCountsEntryR\x0feventTypeCounts\x129\n" +
+	"\n" +
+	"checked_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tcheckedAt\x1aB\n" +
+	"\x14EventTypeCountsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x03R\x05value:\x028\x01\"\xcc\x02\n" +
+	"\rBackendStatus\x12\x18\n" +
+	"\abackend\x18\x01 \x01(\tR\abackend\x12\x1c\n" +
+	"\tconnected\x18\x02 \x01(\bR\tconnected\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\x12\x16\n" +
+	"\x06stream\x18\x04 \x01(\tR\x06stream\x12\x1a\n" +
+	"\bmessages\x18\x05 \x01(\x04R\bmessages\x12\x14\n" +
+	"\x05bytes\x18\x06 \x01(\x04R\x05bytes\x12+\n" +
+	"\x11retention_seconds\x18\a \x01(\x03R\x10retentionSeconds\x12;\n" +
+	"\vfirst_event\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"firstEvent\x129\n" +
+	"\n" +
+	"last_event\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\tlastEvent2\x92\x02\n" +
 	"\rReplayService\x128\n" +
 	"\x06Replay\x12\x15.replay.ReplayRequest\x1a\x15.events.EventEnvelope0\x01\x12F\n" +
 	"\rGetEventStats\x12\x19.replay.EventStatsRequest\x1a\x1a.replay.EventStatsResponse\x12F\n" +
-	"\rGetEventTypes\x12\x19.replay.EventTypesRequest\x1a\x1a.replay.EventTypesResponseB5Z3github.com/annel0/mmo-game/internal/protocol/replayb\x06proto3"
+	"\rGetEventTypes\x12\x19.replay.EventTypesRequest\x1a\x1a.replay.EventTypesResponse\x127\n" +
+	"\x06Health\x12\x15.replay.HealthRequest\x1a\x16.replay.HealthResponseB5Z3github.com/annel0/mmo-game/internal/protocol/replayb\x06proto3"
 
 var (
 	file_internal_protocol_proto_replay_proto_rawDescOnce sync.Once
@@ -736,7 +983,7 @@ func file_internal_protocol_proto_replay_proto_rawDescGZIP() []byte {
 }
 
 var file_internal_protocol_proto_replay_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
-var file_internal_protocol_proto_replay_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_internal_protocol_proto_replay_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
 var file_internal_protocol_proto_replay_proto_goTypes = []any{
 	(ReplayRequest_SortOrder)(0),        // 0: replay.ReplayRequest.SortOrder
 	(EventStatsRequest_StatsGroupBy)(0), // 1: replay.EventStatsRequest.StatsGroupBy
@@ -747,39 +994,50 @@ var file_internal_protocol_proto_replay_proto_goTypes = []any{
 	(*EventTypesRequest)(nil),           // 6: replay.EventTypesRequest
 	(*EventTypesResponse)(nil),          // 7: replay.EventTypesResponse
 	(*EventTypeInfo)(nil),               // 8: replay.EventTypeInfo
-	nil,                                 // 9: replay.EventStat.EventTypeCountsEntry
-	(*timestamppb.Timestamp)(nil),       // 10: google.protobuf.Timestamp
-	(*events.EventEnvelope)(nil),        // 11: events.EventEnvelope
+	(*HealthRequest)(nil),               // 9: replay.HealthRequest
+	(*HealthResponse)(nil),              // 10: replay.HealthResponse
+	(*BackendStatus)(nil),               // 11: replay.BackendStatus
+	nil,                                 // 12: replay.EventStat.EventTypeCountsEntry
+	nil,                                 // 13: replay.HealthResponse.EventTypeCountsEntry
+	(*timestamppb.Timestamp)(nil),       // 14: google.protobuf.Timestamp
+	(*events.EventEnvelope)(nil),        // 15: events.EventEnvelope
 }
 var file_internal_protocol_proto_replay_proto_depIdxs = []int32{
-	10, // 0: replay.ReplayRequest.start_time:type_name -> google.protobuf.Timestamp
-	10, // 1: replay.ReplayRequest.end_time:type_name -> google.protobuf.Timestamp
+	14, // 0: replay.ReplayRequest.start_time:type_name -> google.protobuf.Timestamp
+	14, // 1: replay.ReplayRequest.end_time:type_name -> google.protobuf.Timestamp
 	0,  // 2: replay.ReplayRequest.sort_order:type_name -> replay.ReplayRequest.SortOrder
-	10, // 3: replay.EventStatsRequest.start_time:type_name -> google.protobuf.Timestamp
-	10, // 4: replay.EventStatsRequest.end_time:type_name -> google.protobuf.Timestamp
+	14, // 3: replay.EventStatsRequest.start_time:type_name -> google.protobuf.Timestamp
+	14, // 4: replay.EventStatsRequest.end_time:type_name -> google.protobuf.Timestamp
 	1,  // 5: replay.EventStatsRequest.group_by:type_name -> replay.EventStatsRequest.StatsGroupBy
 	5,  // 6: replay.EventStatsResponse.stats:type_name -> replay.EventStat
-	10, // 7: replay.EventStatsResponse.oldest_event:type_name -> google.protobuf.Timestamp
-	10, // 8: replay.EventStatsResponse.newest_event:type_name -> google.protobuf.Timestamp
-	10, // 9: replay.EventStat.period_start:type_name -> google.protobuf.Timestamp
-	10, // 10: replay.EventStat.period_end:type_name -> google.protobuf.Timestamp
-	9,  // 11: replay.EventStat.event_type_counts:type_name -> replay.EventStat.EventTypeCountsEntry
-	10, // 12: replay.EventTypesRequest.start_time:type_name -> google.protobuf.Timestamp
-	10, // 13: replay.EventTypesRequest.end_time:type_name -> google.protobuf.Timestamp
+	14, // 7: replay.EventStatsResponse.oldest_event:type_name -> google.protobuf.Timestamp
+	14, // 8: replay.EventStatsResponse.newest_event:type_name -> google.protobuf.Timestamp
+	14, // 9: replay.EventStat.period_start:type_name -> google.protobuf.Timestamp
+	14, // 10: replay.EventStat.period_end:type_name -> google.protobuf.Timestamp
+	12, // 11: replay.EventStat.event_type_counts:type_name -> replay.EventStat.EventTypeCountsEntry
+	14, // 12: replay.EventTypesRequest.start_time:type_name -> google.protobuf.Timestamp
+	14, // 13: replay.EventTypesRequest.end_time:type_name -> google.protobuf.Timestamp
 	8,  // 14: replay.EventTypesResponse.event_types:type_name -> replay.EventTypeInfo
-	10, // 15: replay.EventTypeInfo.first_seen:type_name -> google.protobuf.Timestamp
-	10, // 16: replay.EventTypeInfo.last_seen:type_name -> google.protobuf.Timestamp
-	2,  // 17: replay.ReplayService.Replay:input_type -> replay.ReplayRequest
-	3,  // 18: replay.ReplayService.GetEventStats:input_type -> replay.EventStatsRequest
-	6,  // 19: replay.ReplayService.GetEventTypes:input_type -> replay.EventTypesRequest
-	11, // 20: replay.ReplayService.Replay:output_type -> events.EventEnvelope
-	4,  // 21: replay.ReplayService.GetEventStats:output_type -> replay.EventStatsResponse
-	7,  // 22: replay.ReplayService.GetEventTypes:output_type -> replay.EventTypesResponse
-	20, // [20:23] is the sub-list for method output_type
-	17, // [17:20] is the sub-list for method input_type
-	17, // [17:17] is the sub-list for extension type_name
-	17, // [17:17] is the sub-list for extension extendee
-	0,  // [0:17] is the sub-list for field type_name
+	14, // 15: replay.EventTypeInfo.first_seen:type_name -> google.protobuf.Timestamp
+	14, // 16: replay.EventTypeInfo.last_seen:type_name -> google.protobuf.Timestamp
+	11, // 17: replay.HealthResponse.backend:type_name -> replay.BackendStatus
+	13, // 18: replay.HealthResponse.event_type_counts:type_name -> replay.HealthResponse.EventTypeCountsEntry
+	14, // 19: replay.HealthResponse.checked_at:type_name -> google.protobuf.Timestamp
+	14, // 20: replay.BackendStatus.first_event:type_name -> google.protobuf.Timestamp
+	14, // 21: replay.BackendStatus.last_event:type_name -> google.protobuf.Timestamp
+	2,  // 22: replay.ReplayService.Replay:input_type -> replay.ReplayRequest
+	3,  // 23: replay.ReplayService.GetEventStats:input_type -> replay.EventStatsRequest
+	6,  // 24: replay.ReplayService.GetEventTypes:input_type -> replay.EventTypesRequest
+	9,  // 25: replay.ReplayService.Health:input_type -> replay.HealthRequest
+	15, // 26: replay.ReplayService.Replay:output_type -> events.EventEnvelope
+	4,  // 27: replay.ReplayService.GetEventStats:output_type -> replay.EventStatsResponse
+	7,  // 28: replay.ReplayService.GetEventTypes:output_type -> replay.EventTypesResponse
+	10, // 29: replay.ReplayService.Health:output_type -> replay.HealthResponse
+	26, // [26:30] is the sub-list for method output_type
+	22, // [22:26] is the sub-list for method input_type
+	22, // [22:22] is the sub-list for extension type_name
+	22, // [22:22] is the sub-list for extension extendee
+	0,  // [0:22] is the sub-list for field type_name
 }
 
 func init() { file_internal_protocol_proto_replay_proto_init() }
@@ -793,7 +1051,7 @@ func file_internal_protocol_proto_replay_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_internal_protocol_proto_replay_proto_rawDesc), len(file_internal_protocol_proto_replay_proto_rawDesc)),
 			NumEnums:      2,
-			NumMessages:   8,
+			NumMessages:   12,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
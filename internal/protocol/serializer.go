@@ -4,16 +4,38 @@ import (
 	"encoding/binary"
 	"encoding/json"
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/klauspost/compress/zstd"
 	"google.golang.org/protobuf/proto"
 )
 
+// DefaultMaxPayloadSize - максимальный размер входящего сообщения по умолчанию (1MB)
+const DefaultMaxPayloadSize = 1 << 20
+
+// GameMessageOverhead - запас на служебные поля GameMessage поверх полезной нагрузки
+const GameMessageOverhead = 64
+
+// maxDecompressedSize - жёсткий предел памяти декомпрессора, защищает от zstd-бомб
+const maxDecompressedSize = 64 << 20
+
+// PayloadTooLargeError возвращается, если сообщение превышает допустимый размер.
+// Проверка выполняется до десериализации, поэтому большие буферы не выделяются.
+type PayloadTooLargeError struct {
+	Size  int // Заявленный или фактический размер сообщения
+	Limit int // Действующее ограничение
+}
+
+func (e *PayloadTooLargeError) Error() string {
+	return fmt.Sprintf("payload too large: %d bytes (limit %d)", e.Size, e.Limit)
+}
+
 // MessageSerializer предоставляет методы сериализации и десериализации сообщений
 type MessageSerializer struct {
-	compressor   *zstd.Encoder
-	decompressor *zstd.Decoder
+	compressor     *zstd.Encoder
+	decompressor   *zstd.Decoder
+	maxPayloadSize atomic.Int64
 }
 
 // NewMessageSerializer создаёт новый сериализатор сообщений
@@ -30,34 +52,64 @@ func NewMessageSerializer() (*MessageSerializer, error) {
 	// Создаём декомпрессор
 	decompressor, err := zstd.NewReader(nil,
 		zstd.WithDecoderConcurrency(1), // Меньше потоков для низкой латентности
+		zstd.WithDecoderMaxMemory(maxDecompressedSize),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create decompressor: %w", err)
 	}
 
-	return &MessageSerializer{
+	ms := &MessageSerializer{
 		compressor:   compressor,
 		decompressor: decompressor,
-	}, nil
+	}
+	ms.maxPayloadSize.Store(DefaultMaxPayloadSize)
+	return ms, nil
+}
+
+// SetMaxPayloadSize задаёт максимальный размер входящего сообщения (<= 0 - значение по умолчанию)
+func (ms *MessageSerializer) SetMaxPayloadSize(limit int) {
+	if limit <= 0 {
+		limit = DefaultMaxPayloadSize
+	}
+	ms.maxPayloadSize.Store(int64(limit))
+}
+
+// MaxPayloadSize возвращает максимальный размер входящего сообщения
+func (ms *MessageSerializer) MaxPayloadSize() int {
+	return int(ms.maxPayloadSize.Load())
+}
+
+// CheckPayloadSize возвращает *PayloadTooLargeError, если размер превышает ограничение
+func (ms *MessageSerializer) CheckPayloadSize(size int) error {
+	if limit := ms.MaxPayloadSize(); size > limit {
+		return &PayloadTooLargeError{Size: size, Limit: limit}
+	}
+	return nil
 }
 
 // SerializeMessage сериализует сообщение в формат Protocol Buffers
 func (ms *MessageSerializer) SerializeMessage(msgType MessageType, payload proto.Message) ([]byte, error) {
-	// Сериализуем полезную нагрузку
+	// Полезная нагрузка сериализуется во временный буфер пула (см. AppendMessage)
+	return ms.AppendMessage(nil, msgType, payload)
+}
+
+// SerializeSequencedMessage сериализует сообщение с номером последовательности и подтверждением
+// последнего принятого пакета собеседника (для ненадёжных UDP-каналов)
+func (ms *MessageSerializer) SerializeSequencedMessage(msgType MessageType, payload proto.Message, sequence, ack, ackBits uint32) ([]byte, error) {
 	payloadData, err := proto.Marshal(payload)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка сериализации полезной нагрузки: %w", err)
 	}
 
-	// Создаем GameMessage в соответствии с proto-определением
 	gameMessage := &GameMessage{
 		Type:      msgType,
 		Timestamp: time.Now().UnixNano(),
-		Sequence:  0, // Для простых сообщений не используем sequence
+		Sequence:  sequence,
+		Ack:       &ack,
+		AckBits:   &ackBits,
 		Payload:   payloadData,
 	}
 
-	// Сериализуем GameMessage с использованием proto
 	messageData, err := proto.Marshal(gameMessage)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка сериализации сообщения: %w", err)
@@ -66,8 +118,20 @@ func (ms *MessageSerializer) SerializeMessage(msgType MessageType, payload proto
 	return messageData, nil
 }
 
+// CheckMessageSize проверяет размер GameMessage целиком (полезная нагрузка + служебные поля)
+func (ms *MessageSerializer) CheckMessageSize(size int) error {
+	if limit := ms.MaxPayloadSize(); size > limit+GameMessageOverhead {
+		return &PayloadTooLargeError{Size: size, Limit: limit}
+	}
+	return nil
+}
+
 // DeserializeMessage десериализует данные в GameMessage
 func (ms *MessageSerializer) DeserializeMessage(data []byte) (*GameMessage, error) {
+	if err := ms.CheckMessageSize(len(data)); err != nil {
+		return nil, err
+	}
+
 	// Десериализуем в GameMessage из proto-определения
 	protoMessage := &GameMessage{}
 	if err := proto.Unmarshal(data, protoMessage); err != nil {
@@ -79,6 +143,9 @@ func (ms *MessageSerializer) DeserializeMessage(data []byte) (*GameMessage, erro
 
 // DeserializePayload десериализует полезную нагрузку сообщения в указанный тип
 func (ms *MessageSerializer) DeserializePayload(msg *GameMessage, payload proto.Message) error {
+	if err := ms.CheckPayloadSize(len(msg.Payload)); err != nil {
+		return err
+	}
 	if err := proto.Unmarshal(msg.Payload, payload); err != nil {
 		return fmt.Errorf("ошибка десериализации полезной нагрузки: %w", err)
 	}
@@ -118,22 +185,20 @@ func (ms *MessageSerializer) DeserializeData(data []byte, result interface{}) er
 	return nil
 }
 
-// MapToJsonMetadata преобразует map в структуру JsonMetadata
+// MapToJsonMetadata преобразует map в структуру JsonMetadata, сохраняя
+// различие между целыми и дробными числами (см. MarshalMetadata)
 func MapToJsonMetadata(metadata map[string]interface{}) (string, error) {
-	jsonData, err := json.Marshal(metadata)
+	jsonData, err := MarshalMetadata(metadata)
 	if err != nil {
 		return "", err
 	}
 	return string(jsonData), nil
 }
 
-// JsonToMap преобразует строку JSON в map
+// JsonToMap преобразует строку JSON в map; целые числа возвращаются как int,
+// дробные — как float64
 func JsonToMap(jsonStr string) (map[string]interface{}, error) {
-	var result map[string]interface{}
-	if err := json.Unmarshal([]byte(jsonStr), &result); err != nil {
-		return nil, err
-	}
-	return result, nil
+	return UnmarshalMetadata([]byte(jsonStr))
 }
 
 // Вспомогательные функции для работы с бинарными данными
@@ -196,6 +261,9 @@ func (ms *MessageSerializer) Deserialize(data []byte) (*NetGameMessage, error) {
 
 	// Читаем длину из заголовка
 	length := binary.LittleEndian.Uint32(data[:4])
+	if err := ms.CheckPayloadSize(int(length)); err != nil {
+		return nil, err
+	}
 
 	// Проверяем, что длина соответствует данным
 	if uint32(len(data)-4) != length {
@@ -215,6 +283,9 @@ func (ms *MessageSerializer) Deserialize(data []byte) (*NetGameMessage, error) {
 			if err != nil {
 				return nil, fmt.Errorf("decompression failed: %w", err)
 			}
+			if err := ms.CheckPayloadSize(len(decompressed)); err != nil {
+				return nil, err
+			}
 			payload = decompressed
 		}
 	}
@@ -295,6 +366,9 @@ func (ms *MessageSerializer) DeserializeBatch(data []byte) ([]*NetGameMessage, e
 
 		// Читаем длину сообщения
 		msgLength := binary.LittleEndian.Uint32(data[offset : offset+4])
+		if err := ms.CheckPayloadSize(int(msgLength)); err != nil {
+			return nil, fmt.Errorf("message %d: %w", i, err)
+		}
 		totalMsgLength := 4 + int(msgLength)
 
 		// Проверяем, что у нас есть все данные сообщения
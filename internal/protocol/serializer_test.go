@@ -0,0 +1,76 @@
+package protocol
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func newTestSerializer(t *testing.T) *MessageSerializer {
+	t.Helper()
+
+	ms, err := NewMessageSerializer()
+	require.NoError(t, err)
+	t.Cleanup(func() { ms.Close() })
+	return ms
+}
+
+func TestDeserializeRejectsOversizedDeclaredLength(t *testing.T) {
+	ms := newTestSerializer(t)
+
+	// Заголовок заявляет 4GB, но данных всего несколько байт
+	data := make([]byte, 8)
+	binary.LittleEndian.PutUint32(data, math.MaxUint32)
+
+	_, err := ms.Deserialize(data)
+	var tooLarge *PayloadTooLargeError
+	require.True(t, errors.As(err, &tooLarge), "ожидалась PayloadTooLargeError, получено %v", err)
+	assert.Equal(t, math.MaxUint32, tooLarge.Size)
+	assert.Equal(t, DefaultMaxPayloadSize, tooLarge.Limit)
+}
+
+func TestDeserializeBatchRejectsOversizedMessage(t *testing.T) {
+	ms := newTestSerializer(t)
+
+	data := make([]byte, 12)
+	binary.LittleEndian.PutUint32(data[:4], 1)
+	binary.LittleEndian.PutUint32(data[4:8], math.MaxUint32)
+
+	_, err := ms.DeserializeBatch(data)
+	var tooLarge *PayloadTooLargeError
+	assert.True(t, errors.As(err, &tooLarge), "ожидалась PayloadTooLargeError, получено %v", err)
+}
+
+func TestMaxPayloadSizeIsConfigurable(t *testing.T) {
+	ms := newTestSerializer(t)
+	ms.SetMaxPayloadSize(16)
+	assert.Equal(t, 16, ms.MaxPayloadSize())
+
+	small := &ChatMessage{Message: "hi"}
+	data, err := ms.SerializeMessage(MessageType_CHAT, small)
+	require.NoError(t, err)
+
+	msg, err := ms.DeserializeMessage(data)
+	require.NoError(t, err)
+	require.NoError(t, ms.DeserializePayload(msg, &ChatMessage{}))
+
+	large := &ChatMessage{Message: strings.Repeat("a", 64)}
+	payload, err := proto.Marshal(large)
+	require.NoError(t, err)
+
+	err = ms.DeserializePayload(&GameMessage{Payload: payload}, &ChatMessage{})
+	var tooLarge *PayloadTooLargeError
+	require.True(t, errors.As(err, &tooLarge))
+	assert.Equal(t, len(payload), tooLarge.Size)
+	assert.Equal(t, 16, tooLarge.Limit)
+
+	// Неположительное значение возвращает лимит по умолчанию
+	ms.SetMaxPayloadSize(0)
+	assert.Equal(t, DefaultMaxPayloadSize, ms.MaxPayloadSize())
+}
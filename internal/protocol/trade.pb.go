@@ -0,0 +1,447 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v3.21.12
+// source: trade.proto
+
+package protocol
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Действия игрока в сделке
+type TradeAction int32
+
+const (
+	TradeAction_TRADE_OPEN     TradeAction = 0 // Предложить сделку игроку target_id
+	TradeAction_TRADE_OFFER    TradeAction = 1 // Выставить предметы (переносятся из инвентаря в эскроу)
+	TradeAction_TRADE_WITHDRAW TradeAction = 2 // Забрать выставленные предметы обратно
+	TradeAction_TRADE_CONFIRM  TradeAction = 3 // Подтвердить текущие условия
+	TradeAction_TRADE_CANCEL   TradeAction = 4 // Отменить сделку
+)
+
+// Enum value maps for TradeAction.
+var (
+	TradeAction_name = map[int32]string{
+		0: "TRADE_OPEN",
+		1: "TRADE_OFFER",
+		2: "TRADE_WITHDRAW",
+		3: "TRADE_CONFIRM",
+		4: "TRADE_CANCEL",
+	}
+	TradeAction_value = map[string]int32{
+		"TRADE_OPEN":     0,
+		"TRADE_OFFER":    1,
+		"TRADE_WITHDRAW": 2,
+		"TRADE_CONFIRM":  3,
+		"TRADE_CANCEL":   4,
+	}
+)
+
+func (x TradeAction) Enum() *TradeAction {
+	p := new(TradeAction)
+	*p = x
+	return p
+}
+
+func (x TradeAction) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (TradeAction) Descriptor() protoreflect.EnumDescriptor {
+	return file_trade_proto_enumTypes[0].Descriptor()
+}
+
+func (TradeAction) Type() protoreflect.EnumType {
+	return &file_trade_proto_enumTypes[0]
+}
+
+func (x TradeAction) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use TradeAction.Descriptor instead.
+func (TradeAction) EnumDescriptor() ([]byte, []int) {
+	return file_trade_proto_rawDescGZIP(), []int{0}
+}
+
+// Состояние сделки
+type TradeStatus int32
+
+const (
+	TradeStatus_TRADE_STATUS_OPEN      TradeStatus = 0
+	TradeStatus_TRADE_STATUS_COMPLETED TradeStatus = 1
+	TradeStatus_TRADE_STATUS_CANCELLED TradeStatus = 2
+)
+
+// Enum value maps for TradeStatus.
+var (
+	TradeStatus_name = map[int32]string{
+		0: "TRADE_STATUS_OPEN",
+		1: "TRADE_STATUS_COMPLETED",
+		2: "TRADE_STATUS_CANCELLED",
+	}
+	TradeStatus_value = map[string]int32{
+		"TRADE_STATUS_OPEN":      0,
+		"TRADE_STATUS_COMPLETED": 1,
+		"TRADE_STATUS_CANCELLED": 2,
+	}
+)
+
+func (x TradeStatus) Enum() *TradeStatus {
+	p := new(TradeStatus)
+	*p = x
+	return p
+}
+
+func (x TradeStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (TradeStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_trade_proto_enumTypes[1].Descriptor()
+}
+
+func (TradeStatus) Type() protoreflect.EnumType {
+	return &file_trade_proto_enumTypes[1]
+}
+
+func (x TradeStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use TradeStatus.Descriptor instead.
+func (TradeStatus) EnumDescriptor() ([]byte, []int) {
+	return file_trade_proto_rawDescGZIP(), []int{1}
+}
+
+// Запрос игрока по сделке
+type TradeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Action        TradeAction            `protobuf:"varint,1,opt,name=action,proto3,enum=protocol.TradeAction" json:"action,omitempty"`
+	TargetId      *uint64                `protobuf:"varint,2,opt,name=target_id,json=targetId,proto3,oneof" json:"target_id,omitempty"` // Партнёр (только для TRADE_OPEN)
+	ItemId        string                 `protobuf:"bytes,3,opt,name=item_id,json=itemId,proto3" json:"item_id,omitempty"`              // Предмет (для TRADE_OFFER и TRADE_WITHDRAW)
+	Count         int32                  `protobuf:"varint,4,opt,name=count,proto3" json:"count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TradeRequest) Reset() {
+	*x = TradeRequest{}
+	mi := &file_trade_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TradeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TradeRequest) ProtoMessage() {}
+
+func (x *TradeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trade_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TradeRequest.ProtoReflect.Descriptor instead.
+func (*TradeRequest) Descriptor() ([]byte, []int) {
+	return file_trade_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *TradeRequest) GetAction() TradeAction {
+	if x != nil {
+		return x.Action
+	}
+	return TradeAction_TRADE_OPEN
+}
+
+func (x *TradeRequest) GetTargetId() uint64 {
+	if x != nil && x.TargetId != nil {
+		return *x.TargetId
+	}
+	return 0
+}
+
+func (x *TradeRequest) GetItemId() string {
+	if x != nil {
+		return x.ItemId
+	}
+	return ""
+}
+
+func (x *TradeRequest) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+// Предмет, выставленный в сделку
+type TradeItem struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ItemId        string                 `protobuf:"bytes,1,opt,name=item_id,json=itemId,proto3" json:"item_id,omitempty"`
+	Count         int32                  `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TradeItem) Reset() {
+	*x = TradeItem{}
+	mi := &file_trade_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TradeItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TradeItem) ProtoMessage() {}
+
+func (x *TradeItem) ProtoReflect() protoreflect.Message {
+	mi := &file_trade_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TradeItem.ProtoReflect.Descriptor instead.
+func (*TradeItem) Descriptor() ([]byte, []int) {
+	return file_trade_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *TradeItem) GetItemId() string {
+	if x != nil {
+		return x.ItemId
+	}
+	return ""
+}
+
+func (x *TradeItem) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+// Состояние сделки глазами получателя
+type TradeUpdate struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	TradeId          string                 `protobuf:"bytes,1,opt,name=trade_id,json=tradeId,proto3" json:"trade_id,omitempty"`
+	Status           TradeStatus            `protobuf:"varint,2,opt,name=status,proto3,enum=protocol.TradeStatus" json:"status,omitempty"`
+	PartnerId        uint64                 `protobuf:"varint,3,opt,name=partner_id,json=partnerId,proto3" json:"partner_id,omitempty"`
+	MyOffer          []*TradeItem           `protobuf:"bytes,4,rep,name=my_offer,json=myOffer,proto3" json:"my_offer,omitempty"`
+	PartnerOffer     []*TradeItem           `protobuf:"bytes,5,rep,name=partner_offer,json=partnerOffer,proto3" json:"partner_offer,omitempty"`
+	MyConfirmed      bool                   `protobuf:"varint,6,opt,name=my_confirmed,json=myConfirmed,proto3" json:"my_confirmed,omitempty"`
+	PartnerConfirmed bool                   `protobuf:"varint,7,opt,name=partner_confirmed,json=partnerConfirmed,proto3" json:"partner_confirmed,omitempty"`
+	Reason           string                 `protobuf:"bytes,8,opt,name=reason,proto3" json:"reason,omitempty"` // Причина отмены
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *TradeUpdate) Reset() {
+	*x = TradeUpdate{}
+	mi := &file_trade_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TradeUpdate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TradeUpdate) ProtoMessage() {}
+
+func (x *TradeUpdate) ProtoReflect() protoreflect.Message {
+	mi := &file_trade_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TradeUpdate.ProtoReflect.Descriptor instead.
+func (*TradeUpdate) Descriptor() ([]byte, []int) {
+	return file_trade_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *TradeUpdate) GetTradeId() string {
+	if x != nil {
+		return x.TradeId
+	}
+	return ""
+}
+
+func (x *TradeUpdate) GetStatus() TradeStatus {
+	if x != nil {
+		return x.Status
+	}
+	return TradeStatus_TRADE_STATUS_OPEN
+}
+
+func (x *TradeUpdate) GetPartnerId() uint64 {
+	if x != nil {
+		return x.PartnerId
+	}
+	return 0
+}
+
+func (x *TradeUpdate) GetMyOffer() []*TradeItem {
+	if x != nil {
+		return x.MyOffer
+	}
+	return nil
+}
+
+func (x *TradeUpdate) GetPartnerOffer() []*TradeItem {
+	if x != nil {
+		return x.PartnerOffer
+	}
+	return nil
+}
+
+func (x *TradeUpdate) GetMyConfirmed() bool {
+	if x != nil {
+		return x.MyConfirmed
+	}
+	return false
+}
+
+func (x *TradeUpdate) GetPartnerConfirmed() bool {
+	if x != nil {
+		return x.PartnerConfirmed
+	}
+	return false
+}
+
+func (x *TradeUpdate) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+var File_trade_proto protoreflect.FileDescriptor
+
+const file_trade_proto_rawDesc = "" +
+	"\n" +
+	"\vtrade.proto\x12\bprotocol\"\x9c\x01\n" +
+	"\fTradeRequest\x12-\n" +
+	"\x06action\x18\x01 \x01(\x0e2\x15.protocol.TradeActionR\x06action\x12 \n" +
+	"\ttarget_id\x18\x02 \x01(\x04H\x00R\btargetId\x88\x01\x01\x12\x17\n" +
+	"\aitem_id\x18\x03 \x01(\tR\x06itemId\x12\x14\n" +
+	"\x05count\x18\x04 \x01(\x05R\x05countB\f\n" +
+	"\n" +
+	"_target_id\":\n" +
+	"\tTradeItem\x12\x17\n" +
+	"\aitem_id\x18\x01 \x01(\tR\x06itemId\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\x05R\x05count\"\xc8\x02\n" +
+	"\vTradeUpdate\x12\x19\n" +
+	"\btrade_id\x18\x01 \x01(\tR\atradeId\x12-\n" +
+	"\x06status\x18\x02 \x01(\x0e2\x15.protocol.TradeStatusR\x06status\x12\x1d\n" +
+	"\n" +
+	"partner_id\x18\x03 \x01(\x04R\tpartnerId\x12.\n" +
+	"\bmy_offer\x18\x04 \x03(\v2\x13.protocol.TradeItemR\amyOffer\x128\n" +
+	"\rpartner_offer\x18\x05 \x03(\v2\x13.protocol.TradeItemR\fpartnerOffer\x12!\n" +
+	"\fmy_confirmed\x18\x06 \x01(\bR\vmyConfirmed\x12+\n" +
+	"\x11partner_confirmed\x18\a \x01(\bR\x10partnerConfirmed\x12\x16\n" +
+	"\x06reason\x18\b \x01(\tR\x06reason*g\n" +
+	"\vTradeAction\x12\x0e\n" +
+	"\n" +
+	"TRADE_OPEN\x10\x00\x12\x0f\n" +
+	"\vTRADE_OFFER\x10\x01\x12\x12\n" +
+	"\x0eTRADE_WITHDRAW\x10\x02\x12\x11\n" +
+	"\rTRADE_CONFIRM\x10\x03\x12\x10\n" +
+	"\fTRADE_CANCEL\x10\x04*\\\n" +
+	"\vTradeStatus\x12\x15\n" +
+	"\x11TRADE_STATUS_OPEN\x10\x00\x12\x1a\n" +
+	"\x16TRADE_STATUS_COMPLETED\x10\x01\x12\x1a\n" +
+	"\x16TRADE_STATUS_CANCELLED\x10\x02B.Z,github.com/annel0/mmo-game/internal/protocolb\x06proto3"
+
+var (
+	file_trade_proto_rawDescOnce sync.Once
+	file_trade_proto_rawDescData []byte
+)
+
+func file_trade_proto_rawDescGZIP() []byte {
+	file_trade_proto_rawDescOnce.Do(func() {
+		file_trade_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_trade_proto_rawDesc), len(file_trade_proto_rawDesc)))
+	})
+	return file_trade_proto_rawDescData
+}
+
+var file_trade_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_trade_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_trade_proto_goTypes = []any{
+	(TradeAction)(0),     // 0: protocol.TradeAction
+	(TradeStatus)(0),     // 1: protocol.TradeStatus
+	(*TradeRequest)(nil), // 2: protocol.TradeRequest
+	(*TradeItem)(nil),    // 3: protocol.TradeItem
+	(*TradeUpdate)(nil),  // 4: protocol.TradeUpdate
+}
+var file_trade_proto_depIdxs = []int32{
+	0, // 0: protocol.TradeRequest.action:type_name -> protocol.TradeAction
+	1, // 1: protocol.TradeUpdate.status:type_name -> protocol.TradeStatus
+	3, // 2: protocol.TradeUpdate.my_offer:type_name -> protocol.TradeItem
+	3, // 3: protocol.TradeUpdate.partner_offer:type_name -> protocol.TradeItem
+	4, // [4:4] is the sub-list for method output_type
+	4, // [4:4] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_trade_proto_init() }
+func file_trade_proto_init() {
+	if File_trade_proto != nil {
+		return
+	}
+	file_trade_proto_msgTypes[0].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_trade_proto_rawDesc), len(file_trade_proto_rawDesc)),
+			NumEnums:      2,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_trade_proto_goTypes,
+		DependencyIndexes: file_trade_proto_depIdxs,
+		EnumInfos:         file_trade_proto_enumTypes,
+		MessageInfos:      file_trade_proto_msgTypes,
+	}.Build()
+	File_trade_proto = out.File
+	file_trade_proto_goTypes = nil
+	file_trade_proto_depIdxs = nil
+}
@@ -0,0 +1,72 @@
+package regional
+
+import "time"
+
+// JetStream доставляет SyncBatch как минимум один раз, поэтому узел помнит
+// ID недавно применённых изменений и отбрасывает их повторные доставки.
+const (
+	DefaultDedupeWindow   = 10 * time.Minute
+	DefaultDedupeCapacity = 100000
+)
+
+// appliedEntry - ID применённого изменения и время применения
+type appliedEntry struct {
+	id        string
+	appliedAt time.Time
+}
+
+// appliedChanges - ограниченное множество недавно применённых изменений.
+// ID забывается по истечении window или при вытеснении самым новым сверх capacity.
+// Не потокобезопасно: вызывается под RegionalNodeImpl.mu.
+type appliedChanges struct {
+	window   time.Duration
+	capacity int
+	ids      map[string]struct{}
+	order    []appliedEntry // FIFO в порядке применения
+}
+
+func newAppliedChanges(window time.Duration, capacity int) *appliedChanges {
+	if window <= 0 {
+		window = DefaultDedupeWindow
+	}
+	if capacity <= 0 {
+		capacity = DefaultDedupeCapacity
+	}
+	return &appliedChanges{
+		window:   window,
+		capacity: capacity,
+		ids:      make(map[string]struct{}),
+	}
+}
+
+// contains сообщает, применялось ли изменение с этим ID в пределах окна
+func (a *appliedChanges) contains(id string, now time.Time) bool {
+	a.expire(now)
+	_, ok := a.ids[id]
+	return ok
+}
+
+// add запоминает ID применённого изменения
+func (a *appliedChanges) add(id string, now time.Time) {
+	if _, ok := a.ids[id]; ok {
+		return
+	}
+	a.ids[id] = struct{}{}
+	a.order = append(a.order, appliedEntry{id: id, appliedAt: now})
+	for len(a.order) > a.capacity {
+		a.evictOldest()
+	}
+}
+
+// expire забывает изменения старше окна
+func (a *appliedChanges) expire(now time.Time) {
+	for len(a.order) > 0 && now.Sub(a.order[0].appliedAt) > a.window {
+		a.evictOldest()
+	}
+}
+
+func (a *appliedChanges) evictOldest() {
+	delete(a.ids, a.order[0].id)
+	a.order[0] = appliedEntry{}
+	a.order = a.order[1:]
+}
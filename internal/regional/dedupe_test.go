@@ -0,0 +1,82 @@
+package regional
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/eventbus"
+	syncpkg "github.com/annel0/mmo-game/internal/sync"
+	"github.com/annel0/mmo-game/internal/world"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// remoteWinsResolver всегда принимает удалённое изменение
+type remoteWinsResolver struct{}
+
+func (remoteWinsResolver) Resolve(conflict *Conflict) (*syncpkg.Change, error) {
+	return conflict.RemoteChange, nil
+}
+
+func newTestNode(t *testing.T) *RegionalNodeImpl {
+	t.Helper()
+	bus := eventbus.NewMemoryBus(10)
+	bm := syncpkg.NewBatchManager(bus, "region-a", 10, time.Hour, nil)
+	t.Cleanup(bm.Stop)
+
+	node, err := NewRegionalNode(NodeConfig{
+		RegionID:     "region-a",
+		WorldManager: world.NewWorldManager(1),
+		EventBus:     bus,
+		BatchManager: bm,
+		Resolver:     remoteWinsResolver{},
+	})
+	require.NoError(t, err)
+	return node
+}
+
+func TestApplyRemoteChange_DropsRedelivery(t *testing.T) {
+	node := newTestNode(t)
+	change := syncpkg.Change{
+		ID:        "change-1",
+		Data:      []byte(`{"type":"chunk_load","position":{"chunk_x":1,"chunk_y":2}}`),
+		Timestamp: time.Now(),
+	}
+
+	first, second := change, change
+	require.NoError(t, node.ApplyRemoteChange(&first))
+	require.NoError(t, node.ApplyRemoteChange(&second))
+	assert.Equal(t, 1.0, testutil.ToFloat64(node.metrics.RemoteChanges), "изменение применено один раз")
+	assert.Equal(t, 1.0, testutil.ToFloat64(node.metrics.DuplicatesDropped))
+
+	// Повторная доставка того же пакета через SyncBatch тоже отсеивается
+	payload, err := syncpkg.NewPassthroughCompressor().Compress([]syncpkg.Change{change})
+	require.NoError(t, err)
+	node.handleSyncBatch(context.Background(), &eventbus.Envelope{Source: "region-b", EventType: "SyncBatch", Payload: payload})
+	assert.Equal(t, 1.0, testutil.ToFloat64(node.metrics.RemoteChanges))
+	assert.Equal(t, 2.0, testutil.ToFloat64(node.metrics.DuplicatesDropped))
+
+	// Изменения с другим ID применяются
+	other := change
+	other.ID = "change-2"
+	require.NoError(t, node.ApplyRemoteChange(&other))
+	assert.Equal(t, 2.0, testutil.ToFloat64(node.metrics.RemoteChanges))
+}
+
+func TestAppliedChanges_Bounded(t *testing.T) {
+	applied := newAppliedChanges(time.Minute, 2)
+	now := time.Now()
+
+	applied.add("a", now)
+	applied.add("b", now)
+	applied.add("c", now)
+	assert.False(t, applied.contains("a", now), "самый старый вытеснен сверх ёмкости")
+	assert.True(t, applied.contains("b", now))
+	assert.True(t, applied.contains("c", now))
+
+	later := now.Add(2 * time.Minute)
+	assert.False(t, applied.contains("c", later), "забыт по истечении окна")
+	assert.Empty(t, applied.ids)
+}
@@ -0,0 +1,192 @@
+package regional
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/eventbus"
+	"github.com/annel0/mmo-game/internal/logging"
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/annel0/mmo-game/internal/world"
+	"github.com/annel0/mmo-game/internal/world/block"
+)
+
+// DefaultIntegrityInterval - период сверки мира с журналом событий по умолчанию
+const DefaultIntegrityInterval = time.Minute
+
+// IntegrityReport - результат сверки состояния мира с журналом событий
+type IntegrityReport struct {
+	RegionID       string     `json:"region_id"`
+	WorldHash      string     `json:"world_hash"` // Хэш затронутых событиями чанков живого мира
+	EventHash      string     `json:"event_hash"` // Хэш тех же чанков, восстановленных из событий
+	CheckedChunks  int        `json:"checked_chunks"`
+	DivergedChunks []vec.Vec2 `json:"diverged_chunks,omitempty"`
+	AppliedEvents  uint64     `json:"applied_events"`
+	CheckedAt      time.Time  `json:"checked_at"`
+}
+
+// Diverged сообщает, расходится ли мир с журналом событий
+func (r IntegrityReport) Diverged() bool {
+	return len(r.DivergedChunks) > 0
+}
+
+// IntegrityChecker сверяет загруженные чанки мира с теневым миром,
+// в который применяются только события блоков из журнала.
+// Теневой мир генерируется с тем же сидом, поэтому без событий чанки совпадают.
+type IntegrityChecker struct {
+	mu      sync.Mutex
+	live    *world.WorldManager
+	shadow  *world.WorldManager
+	touched map[vec.Vec2]struct{} // Чанки, затронутые событиями
+	applied uint64
+}
+
+// NewIntegrityChecker создаёт проверку целостности для мира
+func NewIntegrityChecker(live *world.WorldManager) *IntegrityChecker {
+	shadow := world.NewWorldManager(live.Seed())
+	// Изменения теневого мира не должны возвращаться в журнал событий
+	shadow.SetEventPublishing(false)
+
+	return &IntegrityChecker{
+		live:    live,
+		shadow:  shadow,
+		touched: make(map[vec.Vec2]struct{}),
+	}
+}
+
+// Close останавливает теневой мир
+func (ic *IntegrityChecker) Close() {
+	ic.shadow.Stop()
+}
+
+// RecordBlockEvent применяет событие блока из журнала к теневому миру
+func (ic *IntegrityChecker) RecordBlockEvent(pos vec.Vec2, layer world.BlockLayer, b world.Block) {
+	ic.mu.Lock()
+	defer ic.mu.Unlock()
+
+	ic.shadow.SetBlockLayer(pos, layer, b)
+	ic.touched[pos.ToChunkCoords()] = struct{}{}
+	ic.applied++
+}
+
+// Check сравнивает хэши затронутых событиями чанков живого и теневого мира.
+// Чанки, выгруженные из живого мира, пропускаются.
+func (ic *IntegrityChecker) Check() IntegrityReport {
+	ic.mu.Lock()
+	defer ic.mu.Unlock()
+
+	liveHashes := make(map[vec.Vec2]world.ChunkHash, len(ic.touched))
+	eventHashes := make(map[vec.Vec2]world.ChunkHash, len(ic.touched))
+	var diverged []vec.Vec2
+
+	for coords := range ic.touched {
+		liveHash, loaded := ic.live.LoadedChunkHash(coords)
+		if !loaded {
+			continue
+		}
+		eventHash, _ := ic.shadow.LoadedChunkHash(coords)
+
+		liveHashes[coords] = liveHash
+		eventHashes[coords] = eventHash
+		if liveHash != eventHash {
+			diverged = append(diverged, coords)
+		}
+	}
+
+	sort.Slice(diverged, func(i, j int) bool {
+		if diverged[i].X != diverged[j].X {
+			return diverged[i].X < diverged[j].X
+		}
+		return diverged[i].Y < diverged[j].Y
+	})
+
+	return IntegrityReport{
+		WorldHash:      world.CombineChunkHashes(liveHashes),
+		EventHash:      world.CombineChunkHashes(eventHashes),
+		CheckedChunks:  len(liveHashes),
+		DivergedChunks: diverged,
+		AppliedEvents:  ic.applied,
+		CheckedAt:      time.Now(),
+	}
+}
+
+// blockEventPayload - JSON события блока, публикуемого WorldManager
+type blockEventPayload struct {
+	EventType world.EventType
+	Position  vec.Vec2
+	Block     struct {
+		ID      block.BlockID
+		Payload map[string]interface{}
+	}
+	Data interface{}
+}
+
+// handleBlockEvent применяет событие блока из шины к проверке целостности
+func (n *RegionalNodeImpl) handleBlockEvent(ctx context.Context, envelope *eventbus.Envelope) {
+	var evt blockEventPayload
+	if err := json.Unmarshal(envelope.Payload, &evt); err != nil {
+		logging.Warn("🔄 Regional[%s]: некорректное событие блока: %v", n.regionID, err)
+		return
+	}
+	if evt.EventType != world.EventTypeBlockChange {
+		return
+	}
+
+	layer := world.LayerActive
+	if data, ok := evt.Data.(map[string]interface{}); ok {
+		if value, ok := data["layer"].(float64); ok {
+			layer = world.BlockLayer(value)
+		}
+	}
+
+	b := world.NewBlock(evt.Block.ID)
+	if evt.Block.Payload != nil {
+		b.Payload = evt.Block.Payload
+	}
+	n.integrity.RecordBlockEvent(evt.Position, layer, b)
+}
+
+// CheckIntegrity сверяет мир с журналом событий и сообщает о расхождении
+func (n *RegionalNodeImpl) CheckIntegrity() IntegrityReport {
+	report := n.integrity.Check()
+	report.RegionID = n.regionID
+
+	if report.Diverged() {
+		n.metrics.IntegrityMismatches.Inc()
+		logging.Error("🚨 Regional[%s]: состояние мира расходится с журналом событий: чанки %v (world=%s, events=%s)",
+			n.regionID, report.DivergedChunks, report.WorldHash, report.EventHash)
+		if n.onIntegrityMismatch != nil {
+			n.onIntegrityMismatch(report)
+		}
+	} else {
+		logging.Debug("🔄 Regional[%s]: целостность подтверждена, чанков=%d, hash=%s",
+			n.regionID, report.CheckedChunks, report.WorldHash)
+	}
+
+	return report
+}
+
+// WorldStateHash возвращает хэш всех загруженных чанков мира региона
+func (n *RegionalNodeImpl) WorldStateHash() (string, int) {
+	return n.worldManager.StateHash()
+}
+
+// runIntegrityChecks периодически сверяет мир с журналом событий
+func (n *RegionalNodeImpl) runIntegrityChecks(ctx context.Context, interval time.Duration) {
+	defer n.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n.CheckIntegrity()
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package regional
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/eventbus"
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/annel0/mmo-game/internal/world"
+	"github.com/annel0/mmo-game/internal/world/block"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegrityCheckerDetectsDivergedChunk(t *testing.T) {
+	live := world.NewWorldManager(42)
+	checker := NewIntegrityChecker(live)
+
+	changes := []vec.Vec2{{X: 1, Y: 1}, {X: 20, Y: 3}, {X: -5, Y: 40}}
+	for _, pos := range changes {
+		b := world.NewBlock(block.StoneBlockID)
+		live.SetBlockLayer(pos, world.LayerActive, b)
+		checker.RecordBlockEvent(pos, world.LayerActive, b)
+	}
+
+	report := checker.Check()
+	require.False(t, report.Diverged(), "мир совпадает с журналом событий")
+	assert.Equal(t, len(changes), report.CheckedChunks)
+	assert.Equal(t, uint64(len(changes)), report.AppliedEvents)
+	assert.Equal(t, report.WorldHash, report.EventHash)
+
+	// Хэш стабилен между проверками
+	assert.Equal(t, report.WorldHash, checker.Check().WorldHash)
+
+	// Меняем чанк в обход журнала событий
+	diverged := vec.Vec2{X: 21, Y: 4}
+	live.SetBlockLayer(diverged, world.LayerActive, world.NewBlock(block.AirBlockID))
+	live.SetBlockLayer(diverged, world.LayerFloor, world.NewBlock(block.GrassBlockID))
+
+	report = checker.Check()
+	require.True(t, report.Diverged())
+	assert.Equal(t, []vec.Vec2{diverged.ToChunkCoords()}, report.DivergedChunks)
+	assert.NotEqual(t, report.WorldHash, report.EventHash)
+}
+
+func TestCombineChunkHashesIndependentOfOrder(t *testing.T) {
+	wm := world.NewWorldManager(7)
+	for _, pos := range []vec.Vec2{{X: 0, Y: 0}, {X: 40, Y: 0}, {X: 0, Y: -40}, {X: 100, Y: 100}} {
+		wm.SetBlock(pos, world.NewBlock(block.StoneBlockID))
+	}
+
+	hashes := wm.ChunkHashes()
+	require.NotEmpty(t, hashes)
+
+	expected, chunks := wm.StateHash()
+	assert.Equal(t, len(hashes), chunks)
+	for i := 0; i < 10; i++ {
+		copied := make(map[vec.Vec2]world.ChunkHash, len(hashes))
+		for k, v := range hashes {
+			copied[k] = v
+		}
+		assert.Equal(t, expected, world.CombineChunkHashes(copied))
+	}
+}
+
+func TestIntegrityChecker_JournalsDirectBlockWrites(t *testing.T) {
+	node := newTestNode(t)
+	eventbus.Init(node.eventBus)
+	t.Cleanup(func() { eventbus.Init(nil) })
+	require.NoError(t, node.Start(context.Background()))
+	t.Cleanup(func() { _ = node.Stop() })
+
+	live := node.worldManager
+	sign := world.NewBlock(block.StoneBlockID)
+	sign.Payload["text"] = "привет"
+	live.SetBlockLayer(vec.Vec2{X: 3, Y: 3}, world.LayerActive, sign)
+	live.SetBlockLayer(vec.Vec2{X: 4, Y: 3}, world.LayerFloor, world.NewBlock(block.GrassBlockID))
+
+	pos := vec.Vec2{X: 40, Y: -8}
+	_, version := live.GetBlockLayerVersion(pos, world.LayerActive)
+	_, ok := live.SetBlockLayerIfVersion(pos, world.LayerActive, world.NewBlock(block.StoneBlockID), version)
+	require.True(t, ok)
+	live.SetBlockMetadataValue(pos, "rotation", 2)
+
+	require.Eventually(t, func() bool {
+		return node.integrity.Check().AppliedEvents == 4
+	}, time.Second, 10*time.Millisecond)
+
+	report := node.CheckIntegrity()
+	assert.False(t, report.Diverged(), "прямые записи и метаданные попадают в журнал: %v", report.DivergedChunks)
+	assert.Equal(t, 2, report.CheckedChunks)
+}
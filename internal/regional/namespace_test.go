@@ -0,0 +1,93 @@
+package regional
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/eventbus"
+	syncpkg "github.com/annel0/mmo-game/internal/sync"
+	"github.com/annel0/mmo-game/internal/world"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startNamespacedNode запускает узел в пространстве имён поверх общей шины
+func startNamespacedNode(t *testing.T, shared eventbus.EventBus, namespace, regionID string) (*RegionalNodeImpl, *syncpkg.BatchManager) {
+	t.Helper()
+	bus, err := eventbus.WithNamespace(shared, namespace)
+	require.NoError(t, err)
+	bm := syncpkg.NewBatchManager(bus, regionID, 1, time.Hour, nil)
+	t.Cleanup(bm.Stop)
+
+	node, err := NewRegionalNode(NodeConfig{
+		RegionID:     regionID,
+		WorldManager: world.NewWorldManager(1),
+		EventBus:     shared,
+		BatchManager: bm,
+		Resolver:     remoteWinsResolver{},
+		Namespace:    namespace,
+	})
+	require.NoError(t, err)
+	require.NoError(t, node.Start(context.Background()))
+	t.Cleanup(func() { _ = node.Stop() })
+	return node, bm
+}
+
+func TestRegionalNode_NamespacesIsolateSharedBus(t *testing.T) {
+	// Одна шина имитирует NATS, общий для production и staging
+	shared := eventbus.NewMemoryBus(100)
+
+	prodA, _ := startNamespacedNode(t, shared, "prod", "region-a")
+	prodB, _ := startNamespacedNode(t, shared, "prod", "region-b")
+	staging, stagingBM := startNamespacedNode(t, shared, "staging", "region-c")
+
+	// Пакет staging доходит только до узлов staging
+	stagingBM.AddChange(syncpkg.Change{
+		Data:      []byte(`{"type":"chunk_load","position":{"chunk_x":1,"chunk_y":2}}`),
+		Timestamp: time.Now(),
+	})
+
+	// Пакет production доходит до другого региона production
+	require.NoError(t, prodA.BroadcastLocalChange(&syncpkg.Change{
+		Data:      []byte(`{"type":"chunk_load","position":{"chunk_x":3,"chunk_y":4}}`),
+		Timestamp: time.Now(),
+	}))
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(prodB.metrics.RemoteChanges) == 1
+	}, time.Second, 5*time.Millisecond)
+
+	// Доставка в памяти упорядочена: к этому моменту оба пакета обработаны
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, 1.0, testutil.ToFloat64(prodB.metrics.RemoteChanges), "пакет staging не дошёл до production")
+	assert.Zero(t, testutil.ToFloat64(staging.metrics.RemoteChanges), "пакет production не дошёл до staging")
+	assert.Zero(t, testutil.ToFloat64(prodA.metrics.RemoteChanges))
+}
+
+func TestNewRegionalNode_RejectsNamespaceMismatch(t *testing.T) {
+	shared := eventbus.NewMemoryBus(10)
+	prodBus, err := eventbus.WithNamespace(shared, "prod")
+	require.NoError(t, err)
+	bm := syncpkg.NewBatchManager(prodBus, "region-a", 10, time.Hour, nil)
+	t.Cleanup(bm.Stop)
+
+	cfg := NodeConfig{
+		RegionID:     "region-a",
+		WorldManager: world.NewWorldManager(1),
+		BatchManager: bm,
+		Namespace:    "staging",
+	}
+
+	cfg.EventBus = prodBus
+	_, err = NewRegionalNode(cfg)
+	assert.ErrorIs(t, err, eventbus.ErrNamespaceMismatch, "шина другого окружения")
+
+	cfg.EventBus = shared
+	_, err = NewRegionalNode(cfg)
+	assert.ErrorIs(t, err, eventbus.ErrNamespaceMismatch, "BatchManager публикует в другое окружение")
+
+	cfg.Namespace = "Staging.EU"
+	_, err = NewRegionalNode(cfg)
+	assert.ErrorIs(t, err, eventbus.ErrInvalidNamespace)
+}
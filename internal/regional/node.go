@@ -20,6 +20,9 @@ type NodeMetrics struct {
 	RemoteChanges     prometheus.Counter
 	ConflictsResolved prometheus.Counter
 	ReplicationLag    prometheus.Gauge
+
+	IntegrityMismatches prometheus.Counter
+	DuplicatesDropped   prometheus.Counter
 }
 
 // NewNodeMetrics создаёт новые метрики для регионального узла
@@ -41,6 +44,14 @@ func NewNodeMetrics() *NodeMetrics {
 			Name: "regional_node_replication_lag_ms",
 			Help: "Задержка репликации в миллисекундах",
 		}),
+		IntegrityMismatches: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "regional_node_integrity_mismatches_total",
+			Help: "Количество расхождений состояния мира с журналом событий",
+		}),
+		DuplicatesDropped: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "regional_node_duplicate_changes_dropped_total",
+			Help: "Количество отброшенных повторных доставок удалённых изменений",
+		}),
 	}
 }
 
@@ -201,11 +212,29 @@ type RegionalNodeImpl struct {
 	resolver   ConflictResolver
 	metrics    *NodeMetrics
 
+	// ID недавно применённых удалённых изменений; переживает перезапуск
+	// подписки, когда JetStream повторно доставляет неподтверждённые пакеты
+	applied *appliedChanges
+
 	// Интеграция с sync системой
 	eventBus     eventbus.EventBus
 	batchManager *syncpkg.BatchManager
 	subscription eventbus.Subscription
 
+	// Сверка мира с журналом событий
+	worldManager        *world.WorldManager
+	integrity           *IntegrityChecker
+	integrityInterval   time.Duration
+	onIntegrityMismatch func(IntegrityReport)
+	blockSubscription   eventbus.Subscription
+	worldID             string
+	area                *eventbus.Bounds
+
+	// Контроль задержки репликации
+	lag                   *lagMonitor
+	onReplicationLag      func(LagAlert)
+	heartbeatSubscription eventbus.Subscription
+
 	// Управление жизненным циклом
 	ctx    context.Context
 	cancel context.CancelFunc
@@ -218,6 +247,31 @@ type NodeConfig struct {
 	EventBus     eventbus.EventBus
 	BatchManager *syncpkg.BatchManager
 	Resolver     ConflictResolver
+
+	// Пространство имён окружения: узел получает события только своего
+	// пространства, BatchManager должен публиковать в него же
+	Namespace string
+
+	// Период сверки мира с журналом событий (0 - DefaultIntegrityInterval, <0 - выключено)
+	IntegrityInterval time.Duration
+	// Вызывается при обнаружении расхождения (например, для отправки webhook)
+	OnIntegrityMismatch func(IntegrityReport)
+
+	// Мир и область координат региона: узел получает события блоков только
+	// из своей области (пусто/nil - все события)
+	WorldID string
+	Area    *eventbus.Bounds
+
+	// Окно и ёмкость отсева повторных доставок (0 - значения по умолчанию)
+	DedupeWindow   time.Duration
+	DedupeCapacity int
+
+	// Порог средней задержки репликации (0 - DefaultLagThreshold, <0 - выключено)
+	// и окно, в течение которого она должна держаться выше порога (0 - DefaultLagWindow)
+	LagThreshold time.Duration
+	LagWindow    time.Duration
+	// Вызывается при отставании региона и при его восстановлении
+	OnReplicationLag func(LagAlert)
 }
 
 func NewRegionalNode(cfg NodeConfig) (*RegionalNodeImpl, error) {
@@ -233,6 +287,14 @@ func NewRegionalNode(cfg NodeConfig) (*RegionalNodeImpl, error) {
 	if cfg.BatchManager == nil {
 		return nil, fmt.Errorf("batch_manager обязателен")
 	}
+	bus, err := eventbus.WithNamespace(cfg.EventBus, cfg.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Namespace != "" && cfg.BatchManager.Namespace() != cfg.Namespace {
+		return nil, fmt.Errorf("%w: batch_manager публикует в %q, требуется %q",
+			eventbus.ErrNamespaceMismatch, cfg.BatchManager.Namespace(), cfg.Namespace)
+	}
 
 	resolver := cfg.Resolver
 	if resolver == nil {
@@ -244,8 +306,22 @@ func NewRegionalNode(cfg NodeConfig) (*RegionalNodeImpl, error) {
 		localWorld:   NewWorldWrapper(cfg.WorldManager),
 		resolver:     resolver,
 		metrics:      NewNodeMetrics(),
-		eventBus:     cfg.EventBus,
+		applied:      newAppliedChanges(cfg.DedupeWindow, cfg.DedupeCapacity),
+		eventBus:     bus,
 		batchManager: cfg.BatchManager,
+
+		worldManager:        cfg.WorldManager,
+		integrity:           NewIntegrityChecker(cfg.WorldManager),
+		integrityInterval:   cfg.IntegrityInterval,
+		onIntegrityMismatch: cfg.OnIntegrityMismatch,
+		worldID:             cfg.WorldID,
+		area:                cfg.Area,
+
+		lag:              newLagMonitor(cfg.LagThreshold, cfg.LagWindow),
+		onReplicationLag: cfg.OnReplicationLag,
+	}
+	if node.integrityInterval == 0 {
+		node.integrityInterval = DefaultIntegrityInterval
 	}
 
 	// Регистрируем Prometheus метрики (игнорируем ошибки дублирования)
@@ -254,6 +330,8 @@ func NewRegionalNode(cfg NodeConfig) (*RegionalNodeImpl, error) {
 		node.metrics.RemoteChanges,
 		node.metrics.ConflictsResolved,
 		node.metrics.ReplicationLag,
+		node.metrics.IntegrityMismatches,
+		node.metrics.DuplicatesDropped,
 	}
 
 	for _, collector := range collectors {
@@ -282,6 +360,14 @@ func (n *RegionalNodeImpl) ApplyRemoteChange(change *syncpkg.Change) error {
 	n.mu.Lock()
 	defer n.mu.Unlock()
 
+	// Повторная доставка уже применённого изменения (изменения без ID не отсеиваются)
+	changeID := change.ID
+	if changeID != "" && n.applied.contains(changeID, time.Now()) {
+		n.metrics.DuplicatesDropped.Inc()
+		logging.Debug("🔄 Regional[%s]: повторная доставка изменения %s отброшена", n.regionID, changeID)
+		return nil
+	}
+
 	// Проверяем на конфликт (упрощённая логика - пока без реальной проверки)
 	if n.hasConflict(change) {
 		conflict := &Conflict{
@@ -297,6 +383,7 @@ func (n *RegionalNodeImpl) ApplyRemoteChange(change *syncpkg.Change) error {
 
 		if resolved == nil {
 			logging.Debug("🔄 Regional[%s]: изменение отклонено при разрешении конфликта", n.regionID)
+			n.markApplied(changeID)
 			return nil
 		}
 
@@ -312,10 +399,14 @@ func (n *RegionalNodeImpl) ApplyRemoteChange(change *syncpkg.Change) error {
 		return fmt.Errorf("failed to apply change: %w", err)
 	}
 
+	// Запоминаем ID входящего изменения: резолвер мог вернуть другое
+	n.markApplied(changeID)
+
 	// Обновляем метрики
 	n.metrics.RemoteChanges.Inc()
 	replicationLag := time.Since(change.Timestamp).Milliseconds()
 	n.metrics.ReplicationLag.Set(float64(replicationLag))
+	n.lag.record(time.Duration(replicationLag) * time.Millisecond)
 
 	logging.Debug("🔄 Regional[%s]: применено удалённое изменение, lag=%dms",
 		n.regionID, replicationLag)
@@ -323,6 +414,13 @@ func (n *RegionalNodeImpl) ApplyRemoteChange(change *syncpkg.Change) error {
 	return nil
 }
 
+// markApplied запоминает обработанное изменение (вызывается под n.mu)
+func (n *RegionalNodeImpl) markApplied(changeID string) {
+	if changeID != "" {
+		n.applied.add(changeID, time.Now())
+	}
+}
+
 func (n *RegionalNodeImpl) BroadcastLocalChange(change *syncpkg.Change) error {
 	n.mu.Lock()
 	defer n.mu.Unlock()
@@ -360,6 +458,44 @@ func (n *RegionalNodeImpl) Start(ctx context.Context) error {
 	}
 	n.subscription = sub
 
+	// Подписываемся на события блоков локального мира для сверки целостности
+	blockFilter := eventbus.Filter{
+		Types:  []string{"BlockEvent"},
+		Bounds: n.area,
+	}
+	if n.worldID != "" {
+		blockFilter.WorldIDs = []string{n.worldID}
+	}
+	blockSub, err := n.eventBus.Subscribe(n.ctx, blockFilter, n.handleBlockEvent)
+	if err != nil {
+		n.subscription.Unsubscribe()
+		n.cancel()
+		return fmt.Errorf("failed to subscribe to BlockEvent: %w", err)
+	}
+	n.blockSubscription = blockSub
+
+	if n.lag.enabled() {
+		heartbeatSub, err := n.eventBus.Subscribe(n.ctx, eventbus.Filter{
+			Types: []string{RegionHeartbeatEvent},
+		}, n.handleHeartbeat)
+		if err != nil {
+			n.subscription.Unsubscribe()
+			n.blockSubscription.Unsubscribe()
+			n.cancel()
+			return fmt.Errorf("failed to subscribe to %s: %w", RegionHeartbeatEvent, err)
+		}
+		n.heartbeatSubscription = heartbeatSub
+	}
+
+	if n.integrityInterval > 0 {
+		n.wg.Add(1)
+		go n.runIntegrityChecks(n.ctx, n.integrityInterval)
+	}
+	if n.lag.enabled() {
+		n.wg.Add(1)
+		go n.runLagMonitor(n.ctx, min(DefaultLagHeartbeat, n.lag.window))
+	}
+
 	logging.Info("🔄 Regional[%s]: узел запущен", n.regionID)
 	return nil
 }
@@ -375,8 +511,15 @@ func (n *RegionalNodeImpl) Stop() error {
 	if n.subscription != nil {
 		n.subscription.Unsubscribe()
 	}
+	if n.blockSubscription != nil {
+		n.blockSubscription.Unsubscribe()
+	}
+	if n.heartbeatSubscription != nil {
+		n.heartbeatSubscription.Unsubscribe()
+	}
 
 	n.wg.Wait()
+	n.integrity.Close()
 
 	logging.Info("🔄 Regional[%s]: узел остановлен", n.regionID)
 	return nil
@@ -481,6 +624,9 @@ func (n *RegionalNodeImpl) handleSyncBatch(ctx context.Context, envelope *eventb
 	if envelope.Source == n.regionID {
 		return
 	}
+	if envelope.Source != "" {
+		n.lag.seen(envelope.Source, time.Now())
+	}
 
 	n.wg.Add(1)
 	defer n.wg.Done()
@@ -0,0 +1,212 @@
+package regional
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/eventbus"
+	"github.com/annel0/mmo-game/internal/logging"
+)
+
+// Отставание региона определяется по скользящему среднему задержки
+// репликации: одиночные всплески не поднимают тревогу.
+const (
+	DefaultLagThreshold = 5 * time.Second  // Допустимая средняя задержка репликации
+	DefaultLagWindow    = 30 * time.Second // Окно усреднения и удержания превышения
+	DefaultLagHeartbeat = 5 * time.Second  // Период оценки задержки и пульса узла
+)
+
+// RegionHeartbeatEvent - пульс регионального узла в шине. Узлы публикуют его
+// каждый такт оценки задержки: по задержке доставки пульса видно отставание
+// и без изменений мира, а по его отсутствию - замолчавший регион
+const RegionHeartbeatEvent = "RegionHeartbeat"
+
+// LagAlert - переход регионального узла в состояние отставания или выход из него
+type LagAlert struct {
+	RegionID   string        `json:"region_id"`
+	Lagging    bool          `json:"lagging"` // false - задержка восстановилась
+	AverageLag time.Duration `json:"average_lag"`
+	Threshold  time.Duration `json:"threshold"`
+	Window     time.Duration `json:"window"`
+	Since      time.Time     `json:"since"` // Начало превышения порога
+	At         time.Time     `json:"at"`
+
+	// Регионы, от которых дольше порога нет ни пульса, ни изменений
+	StaleRegions []string `json:"stale_regions,omitempty"`
+}
+
+// lagBucket - сумма задержек изменений, применённых между двумя тактами
+type lagBucket struct {
+	closedAt time.Time
+	total    time.Duration
+	count    int
+}
+
+// lagMonitor накапливает задержки применённых изменений и на каждом такте
+// сравнивает их среднее за окно с порогом
+type lagMonitor struct {
+	mu        sync.Mutex
+	threshold time.Duration
+	window    time.Duration
+	buckets   []lagBucket // Закрытые такты в пределах окна
+	current   lagBucket
+	lastSeen  map[string]time.Time // Последний пульс или пакет изменений от региона
+
+	aboveSince time.Time // Нулевое - среднее не выше порога
+	lagging    bool
+}
+
+func newLagMonitor(threshold, window time.Duration) *lagMonitor {
+	if threshold == 0 {
+		threshold = DefaultLagThreshold
+	}
+	if window <= 0 {
+		window = DefaultLagWindow
+	}
+	return &lagMonitor{threshold: threshold, window: window, lastSeen: make(map[string]time.Time)}
+}
+
+// enabled сообщает, включён ли контроль задержки (отрицательный порог - выключен)
+func (m *lagMonitor) enabled() bool {
+	return m.threshold > 0
+}
+
+// record учитывает задержку применённого изменения
+func (m *lagMonitor) record(lag time.Duration) {
+	m.mu.Lock()
+	m.current.total += max(lag, 0)
+	m.current.count++
+	m.mu.Unlock()
+}
+
+// seen отмечает, что от региона region пришёл пульс или пакет изменений
+func (m *lagMonitor) seen(region string, now time.Time) {
+	m.mu.Lock()
+	m.lastSeen[region] = now
+	m.mu.Unlock()
+}
+
+// heartbeat закрывает текущий такт и возвращает LagAlert, если состояние
+// узла изменилось. Молчание известного региона дольше порога учитывается
+// как задержка, равная времени с его последнего пульса: иначе без
+// применённых изменений задержка считалась бы нулевой
+func (m *lagMonitor) heartbeat(now time.Time) (LagAlert, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var stale []string
+	for _, region := range slices.Sorted(maps.Keys(m.lastSeen)) {
+		if silence := now.Sub(m.lastSeen[region]); silence > m.threshold {
+			stale = append(stale, region)
+			m.current.total += silence
+			m.current.count++
+		}
+	}
+
+	m.current.closedAt = now
+	m.buckets = append(m.buckets, m.current)
+	m.current = lagBucket{}
+
+	var total time.Duration
+	var count int
+	kept := m.buckets[:0]
+	for _, b := range m.buckets {
+		if now.Sub(b.closedAt) >= m.window {
+			continue
+		}
+		kept = append(kept, b)
+		total += b.total
+		count += b.count
+	}
+	m.buckets = kept
+
+	var average time.Duration
+	if count > 0 {
+		average = total / time.Duration(count)
+	}
+
+	alert := LagAlert{AverageLag: average, Threshold: m.threshold, Window: m.window, Since: m.aboveSince, At: now, StaleRegions: stale}
+	if average <= m.threshold {
+		m.aboveSince = time.Time{}
+		if !m.lagging {
+			return LagAlert{}, false
+		}
+		m.lagging = false
+		return alert, true
+	}
+
+	if m.aboveSince.IsZero() {
+		m.aboveSince = now
+	}
+	if m.lagging || now.Sub(m.aboveSince) < m.window {
+		return LagAlert{}, false
+	}
+	m.lagging = true
+	alert.Lagging = true
+	alert.Since = m.aboveSince
+	return alert, true
+}
+
+// checkReplicationLag оценивает задержку репликации и сообщает об отставании
+// региона или его восстановлении
+func (n *RegionalNodeImpl) checkReplicationLag(now time.Time) {
+	alert, changed := n.lag.heartbeat(now)
+	if !changed {
+		return
+	}
+	alert.RegionID = n.regionID
+
+	if alert.Lagging {
+		logging.Error("🐢 Regional[%s]: средняя задержка репликации %v выше порога %v дольше %v (молчат: %v)",
+			n.regionID, alert.AverageLag, alert.Threshold, alert.Window, alert.StaleRegions)
+	} else {
+		logging.Info("🔄 Regional[%s]: задержка репликации восстановилась (%v)", n.regionID, alert.AverageLag)
+	}
+	if n.onReplicationLag != nil {
+		n.onReplicationLag(alert)
+	}
+}
+
+// publishHeartbeat публикует пульс узла для контроля задержки в других регионах
+func (n *RegionalNodeImpl) publishHeartbeat(ctx context.Context, now time.Time) {
+	err := n.eventBus.Publish(ctx, &eventbus.Envelope{
+		ID:        n.regionID + "-" + now.Format("20060102150405.000000000"),
+		Timestamp: now.UTC(),
+		Source:    n.regionID,
+		EventType: RegionHeartbeatEvent,
+	})
+	if err != nil {
+		logging.Debug("🔄 Regional[%s]: не удалось опубликовать пульс: %v", n.regionID, err)
+	}
+}
+
+// handleHeartbeat учитывает задержку доставки пульса другого региона
+func (n *RegionalNodeImpl) handleHeartbeat(_ context.Context, envelope *eventbus.Envelope) {
+	if envelope.Source == n.regionID || envelope.Source == "" {
+		return
+	}
+	now := time.Now()
+	n.lag.record(now.Sub(envelope.Timestamp))
+	n.lag.seen(envelope.Source, now)
+}
+
+// runLagMonitor периодически публикует пульс узла и оценивает задержку репликации
+func (n *RegionalNodeImpl) runLagMonitor(ctx context.Context, interval time.Duration) {
+	defer n.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			n.publishHeartbeat(ctx, now)
+			n.checkReplicationLag(now)
+		}
+	}
+}
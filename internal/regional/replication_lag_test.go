@@ -0,0 +1,134 @@
+package regional
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/eventbus"
+	syncpkg "github.com/annel0/mmo-game/internal/sync"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplicationLag_SustainedLagAlertsOnceAndRecovers(t *testing.T) {
+	node := newTestNode(t)
+	var alerts []LagAlert
+	node.onReplicationLag = func(alert LagAlert) { alerts = append(alerts, alert) }
+
+	seq := 0
+	apply := func(lag time.Duration) {
+		seq++
+		change := syncpkg.Change{
+			ID:        fmt.Sprintf("change-%d", seq),
+			Data:      []byte(`{"type":"chunk_load","position":{"chunk_x":1,"chunk_y":2}}`),
+			Timestamp: time.Now().Add(-lag),
+		}
+		require.NoError(t, node.ApplyRemoteChange(&change))
+	}
+
+	now := time.Now()
+	tick := func() {
+		now = now.Add(DefaultLagHeartbeat)
+		node.checkReplicationLag(now)
+	}
+
+	// Одиночный всплеск теряется в среднем
+	apply(20 * time.Second)
+	for i := 0; i < 10; i++ {
+		apply(0)
+	}
+	for i := 0; i < 9; i++ {
+		tick()
+	}
+	assert.Empty(t, alerts, "всплеск не поднимает тревогу")
+
+	// Задержка держится выше порога: одна тревога после окна
+	for i := 0; i < 20; i++ {
+		apply(10 * time.Second)
+		tick()
+	}
+	require.Len(t, alerts, 1)
+	assert.True(t, alerts[0].Lagging)
+	assert.Equal(t, "region-a", alerts[0].RegionID)
+	assert.GreaterOrEqual(t, alerts[0].At.Sub(alerts[0].Since), DefaultLagWindow)
+	assert.Greater(t, alerts[0].AverageLag, DefaultLagThreshold)
+
+	// Задержка восстановилась: одно сообщение о восстановлении
+	for i := 0; i < 20; i++ {
+		apply(0)
+		tick()
+	}
+	require.Len(t, alerts, 2)
+	assert.False(t, alerts[1].Lagging)
+	assert.LessOrEqual(t, alerts[1].AverageLag, DefaultLagThreshold)
+}
+
+func TestReplicationLag_SilentRegionAlertsFromHeartbeats(t *testing.T) {
+	node := newTestNode(t)
+	var alerts []LagAlert
+	node.onReplicationLag = func(alert LagAlert) { alerts = append(alerts, alert) }
+
+	heartbeat := func() {
+		node.handleHeartbeat(context.Background(), &eventbus.Envelope{
+			Source:    "region-b",
+			EventType: RegionHeartbeatEvent,
+			Timestamp: time.Now(),
+		})
+	}
+
+	// Пульс приходит вовремя - регион не отстаёт, хотя изменений нет
+	now := time.Now()
+	for i := 0; i < 10; i++ {
+		heartbeat()
+		now = time.Now()
+		node.checkReplicationLag(now)
+	}
+	assert.Empty(t, alerts)
+
+	// Регион замолчал: его молчание считается задержкой
+	for i := 0; i < 20; i++ {
+		now = now.Add(DefaultLagHeartbeat)
+		node.checkReplicationLag(now)
+	}
+	require.Len(t, alerts, 1)
+	assert.True(t, alerts[0].Lagging)
+	assert.Equal(t, []string{"region-b"}, alerts[0].StaleRegions)
+
+	// Пульс вернулся - регион восстановился
+	for i := 0; i < 20; i++ {
+		now = now.Add(DefaultLagHeartbeat)
+		node.lag.seen("region-b", now)
+		node.checkReplicationLag(now)
+	}
+	require.Len(t, alerts, 2)
+	assert.False(t, alerts[1].Lagging)
+	assert.Empty(t, alerts[1].StaleRegions)
+}
+
+func TestReplicationLag_NodesExchangeHeartbeats(t *testing.T) {
+	node := newTestNode(t)
+	require.NoError(t, node.Start(context.Background()))
+	t.Cleanup(func() { _ = node.Stop() })
+
+	// Собственный пульс не учитывается, пульс другого региона - да
+	node.publishHeartbeat(context.Background(), time.Now())
+	require.NoError(t, node.eventBus.Publish(context.Background(), &eventbus.Envelope{
+		ID:        "hb-b",
+		Source:    "region-b",
+		EventType: RegionHeartbeatEvent,
+		Timestamp: time.Now(),
+	}))
+	require.Eventually(t, func() bool {
+		node.lag.mu.Lock()
+		defer node.lag.mu.Unlock()
+		_, seen := node.lag.lastSeen["region-b"]
+		return seen
+	}, time.Second, 5*time.Millisecond)
+
+	node.lag.mu.Lock()
+	_, self := node.lag.lastSeen["region-a"]
+	node.lag.mu.Unlock()
+	assert.False(t, self)
+}
@@ -0,0 +1,212 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	_ "github.com/go-sql-driver/mysql"
+)
+
+// MariaPlayerStateRepo реализует PlayerStateRepo для MariaDB/MySQL.
+// Использует таблицу player_states; эффекты хранятся в JSON-колонке.
+type MariaPlayerStateRepo struct {
+	db *sql.DB
+}
+
+// NewMariaPlayerStateRepo создает репозиторий состояний игроков для MariaDB.
+// Автоматически создает таблицу, если она не существует.
+func NewMariaPlayerStateRepo(dsn string) (*MariaPlayerStateRepo, error) {
+	db, err := sql.Open("mysql", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось подключиться к MariaDB: %w", err)
+	}
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("не удалось проверить соединение с MariaDB: %w", err)
+	}
+
+	repo := &MariaPlayerStateRepo{db: db}
+	if err := repo.createTable(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("не удалось создать таблицу: %w", err)
+	}
+
+	return repo, nil
+}
+
+// createTable создает таблицу player_states, если она не существует.
+func (r *MariaPlayerStateRepo) createTable() error {
+	query := `
+		CREATE TABLE IF NOT EXISTS player_states (
+			user_id          BIGINT      PRIMARY KEY,
+			level            INT         NOT NULL DEFAULT 1,
+			experience       INT         NOT NULL DEFAULT 0,
+			status_effects   JSON        NULL,
+			effect_intensity JSON        NULL,
+			updated_at       TIMESTAMP   DEFAULT CURRENT_TIMESTAMP
+			                 ON UPDATE   CURRENT_TIMESTAMP
+		) ENGINE=InnoDB
+	`
+
+	if _, err := r.db.Exec(query); err != nil {
+		return fmt.Errorf("ошибка создания таблицы player_states: %w", err)
+	}
+
+	// Таблицы, созданные до появления силы эффектов
+	if _, err := r.db.Exec(`ALTER TABLE player_states ADD COLUMN IF NOT EXISTS effect_intensity JSON NULL`); err != nil {
+		return fmt.Errorf("ошибка обновления таблицы player_states: %w", err)
+	}
+	return nil
+}
+
+const upsertPlayerStateQuery = `
+	INSERT INTO player_states (user_id, level, experience, status_effects, effect_intensity)
+	VALUES (?, ?, ?, ?, ?)
+	ON DUPLICATE KEY UPDATE
+		level = VALUES(level),
+		experience = VALUES(experience),
+		status_effects = VALUES(status_effects),
+		effect_intensity = VALUES(effect_intensity),
+		updated_at = CURRENT_TIMESTAMP
+`
+
+// Save сохраняет состояние игрока в базе данных.
+func (r *MariaPlayerStateRepo) Save(ctx context.Context, userID uint64, state PlayerState) error {
+	if userID == 0 {
+		return fmt.Errorf("недействительный userID: %d", userID)
+	}
+	if err := state.Validate(); err != nil {
+		return err
+	}
+
+	effects, intensity, err := marshalEffects(state)
+	if err != nil {
+		return err
+	}
+
+	if _, err := r.db.ExecContext(ctx, upsertPlayerStateQuery, userID, state.Level, state.Experience, effects, intensity); err != nil {
+		return fmt.Errorf("ошибка сохранения состояния для пользователя %d: %w", userID, err)
+	}
+	return nil
+}
+
+// Load загружает состояние игрока из базы данных.
+func (r *MariaPlayerStateRepo) Load(ctx context.Context, userID uint64) (PlayerState, bool, error) {
+	if userID == 0 {
+		return PlayerState{}, false, fmt.Errorf("недействительный userID: %d", userID)
+	}
+
+	query := `SELECT level, experience, status_effects, effect_intensity FROM player_states WHERE user_id = ?`
+
+	var state PlayerState
+	var effects, intensity sql.NullString
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(&state.Level, &state.Experience, &effects, &intensity)
+	if err == sql.ErrNoRows {
+		return PlayerState{}, false, nil
+	}
+	if err != nil {
+		return PlayerState{}, false, fmt.Errorf("ошибка загрузки состояния для пользователя %d: %w", userID, err)
+	}
+
+	if effects.Valid && effects.String != "" {
+		if err := json.Unmarshal([]byte(effects.String), &state.StatusEffects); err != nil {
+			return PlayerState{}, false, fmt.Errorf("ошибка разбора эффектов пользователя %d: %w", userID, err)
+		}
+	}
+	if intensity.Valid && intensity.String != "" {
+		if err := json.Unmarshal([]byte(intensity.String), &state.EffectIntensity); err != nil {
+			return PlayerState{}, false, fmt.Errorf("ошибка разбора силы эффектов пользователя %d: %w", userID, err)
+		}
+	}
+
+	return state, true, nil
+}
+
+// Delete удаляет сохраненное состояние игрока.
+func (r *MariaPlayerStateRepo) Delete(ctx context.Context, userID uint64) error {
+	if userID == 0 {
+		return fmt.Errorf("недействительный userID: %d", userID)
+	}
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM player_states WHERE user_id = ?`, userID)
+	if err != nil {
+		return fmt.Errorf("ошибка удаления состояния для пользователя %d: %w", userID, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("ошибка получения количества затронутых строк: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("состояние для пользователя %d не найдено", userID)
+	}
+	return nil
+}
+
+// BatchSave сохраняет состояния нескольких игроков в одной транзакции.
+func (r *MariaPlayerStateRepo) BatchSave(ctx context.Context, states map[uint64]PlayerState) error {
+	if len(states) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, upsertPlayerStateQuery)
+	if err != nil {
+		return fmt.Errorf("ошибка подготовки запроса: %w", err)
+	}
+	defer stmt.Close()
+
+	for userID, state := range states {
+		if userID == 0 {
+			return fmt.Errorf("недействительный userID в batch: %d", userID)
+		}
+		if err := state.Validate(); err != nil {
+			return fmt.Errorf("пользователь %d: %w", userID, err)
+		}
+
+		effects, intensity, err := marshalEffects(state)
+		if err != nil {
+			return fmt.Errorf("пользователь %d: %w", userID, err)
+		}
+		if _, err := stmt.ExecContext(ctx, userID, state.Level, state.Experience, effects, intensity); err != nil {
+			return fmt.Errorf("ошибка сохранения состояния для пользователя %d в batch: %w", userID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("ошибка фиксации транзакции: %w", err)
+	}
+	return nil
+}
+
+// marshalEffects сериализует эффекты и их силу для JSON-колонок
+func marshalEffects(state PlayerState) (effects, intensity []byte, err error) {
+	if effects, err = json.Marshal(state.StatusEffects); err != nil {
+		return nil, nil, fmt.Errorf("ошибка сериализации эффектов: %w", err)
+	}
+	if intensity, err = json.Marshal(state.EffectIntensity); err != nil {
+		return nil, nil, fmt.Errorf("ошибка сериализации силы эффектов: %w", err)
+	}
+	return effects, intensity, nil
+}
+
+// Ping проверяет доступность базы данных.
+func (r *MariaPlayerStateRepo) Ping(ctx context.Context) error {
+	return r.db.PingContext(ctx)
+}
+
+// Close закрывает соединение с базой данных.
+func (r *MariaPlayerStateRepo) Close() error {
+	if r.db != nil {
+		return r.db.Close()
+	}
+	return nil
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/annel0/mmo-game/internal/vec"
 	_ "github.com/go-sql-driver/mysql"
@@ -68,6 +70,18 @@ func (r *MariaPositionRepo) createTable() error {
 		return fmt.Errorf("ошибка создания таблицы player_positions: %w", err)
 	}
 
+	// Версии владения хранятся отдельно: AcquireVersion не должен создавать
+	// позицию для игрока, у которого её ещё нет
+	versionsQuery := `
+		CREATE TABLE IF NOT EXISTS player_position_versions (
+			user_id BIGINT          PRIMARY KEY,
+			version BIGINT UNSIGNED NOT NULL
+		) ENGINE=InnoDB
+	`
+	if _, err := r.db.Exec(versionsQuery); err != nil {
+		return fmt.Errorf("ошибка создания таблицы player_position_versions: %w", err)
+	}
+
 	return nil
 }
 
@@ -126,6 +140,30 @@ func (r *MariaPositionRepo) Load(ctx context.Context, userID uint64) (vec.Vec3,
 	return pos, true, nil
 }
 
+// ListPositions реализует PositionLister: постраничный обход по первичному ключу.
+func (r *MariaPositionRepo) ListPositions(ctx context.Context, afterUserID uint64, limit int) ([]UserPosition, error) {
+	if limit <= 0 {
+		limit = positionBatchRows
+	}
+
+	query := `SELECT user_id, x, y, layer FROM player_positions WHERE user_id > ? ORDER BY user_id LIMIT ?`
+	rows, err := r.db.QueryContext(ctx, query, afterUserID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения позиций после пользователя %d: %w", afterUserID, err)
+	}
+	defer rows.Close()
+
+	var page []UserPosition
+	for rows.Next() {
+		var entry UserPosition
+		if err := rows.Scan(&entry.UserID, &entry.Pos.X, &entry.Pos.Y, &entry.Pos.Z); err != nil {
+			return nil, fmt.Errorf("ошибка чтения позиции: %w", err)
+		}
+		page = append(page, entry)
+	}
+	return page, rows.Err()
+}
+
 // Delete удаляет сохраненную позицию игрока.
 func (r *MariaPositionRepo) Delete(ctx context.Context, userID uint64) error {
 	// Валидация входных данных
@@ -152,13 +190,33 @@ func (r *MariaPositionRepo) Delete(ctx context.Context, userID uint64) error {
 	return nil
 }
 
-// BatchSave сохраняет позиции нескольких игроков в одной транзакции.
+// positionBatchRows - строк в одном многострочном INSERT при BatchSave.
+// Ограничивает размер запроса (max_allowed_packet) и число плейсхолдеров
+const positionBatchRows = 500
+
+// BatchSave сохраняет позиции нескольких игроков в одной транзакции
+// многострочными INSERT ... ON DUPLICATE KEY UPDATE. При любой ошибке
+// транзакция откатывается целиком: позиции либо записаны все, либо ни одна.
 // Это оптимизация для автосохранения всех онлайн игроков.
 func (r *MariaPositionRepo) BatchSave(ctx context.Context, positions map[uint64]vec.Vec3) error {
 	if len(positions) == 0 {
 		return nil // Нечего сохранять
 	}
 
+	// Проверяем все записи до начала транзакции; порядок по userID
+	// одинаков у всех автосохранений и не даёт взаимных блокировок
+	userIDs := make([]uint64, 0, len(positions))
+	for userID, pos := range positions {
+		if userID == 0 {
+			return fmt.Errorf("недействительный userID в batch: %d", userID)
+		}
+		if pos.Z < 0 || pos.Z > 255 {
+			return fmt.Errorf("недействительный layer для пользователя %d: %d", userID, pos.Z)
+		}
+		userIDs = append(userIDs, userID)
+	}
+	slices.Sort(userIDs)
+
 	// Начинаем транзакцию
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -166,45 +224,181 @@ func (r *MariaPositionRepo) BatchSave(ctx context.Context, positions map[uint64]
 	}
 	defer tx.Rollback() // Откат в случае ошибки
 
-	// Подготавливаем запрос
-	query := `
-		INSERT INTO player_positions (user_id, x, y, layer) 
-		VALUES (?, ?, ?, ?)
-		ON DUPLICATE KEY UPDATE 
-			x = VALUES(x), 
-			y = VALUES(y), 
+	if err := upsertPositions(ctx, tx, userIDs, positions); err != nil {
+		return err
+	}
+
+	// Фиксируем транзакцию
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("ошибка фиксации транзакции: %w", err)
+	}
+
+	return nil
+}
+
+// upsertPositions записывает позиции пользователей userIDs пачками по positionBatchRows
+func upsertPositions(ctx context.Context, tx *sql.Tx, userIDs []uint64, positions map[uint64]vec.Vec3) error {
+	for start := 0; start < len(userIDs); start += positionBatchRows {
+		batch := userIDs[start:min(start+positionBatchRows, len(userIDs))]
+
+		args := make([]interface{}, 0, len(batch)*4)
+		for _, userID := range batch {
+			pos := positions[userID]
+			args = append(args, userID, pos.X, pos.Y, pos.Z)
+		}
+
+		if _, err := tx.ExecContext(ctx, batchUpsertPositionsQuery(len(batch)), args...); err != nil {
+			return fmt.Errorf("ошибка сохранения позиций пользователей %d..%d в batch: %w",
+				batch[0], batch[len(batch)-1], err)
+		}
+	}
+	return nil
+}
+
+// batchUpsertPositionsQuery строит INSERT ... ON DUPLICATE KEY UPDATE на rows строк
+func batchUpsertPositionsQuery(rows int) string {
+	var query strings.Builder
+	query.WriteString("INSERT INTO player_positions (user_id, x, y, layer) VALUES ")
+	for i := 0; i < rows; i++ {
+		if i > 0 {
+			query.WriteString(", ")
+		}
+		query.WriteString("(?, ?, ?, ?)")
+	}
+	query.WriteString(` ON DUPLICATE KEY UPDATE
+			x = VALUES(x),
+			y = VALUES(y),
 			layer = VALUES(layer),
-			updated_at = CURRENT_TIMESTAMP
+			updated_at = CURRENT_TIMESTAMP`)
+	return query.String()
+}
+
+// AcquireVersion увеличивает версию владения позицией игрока.
+// LAST_INSERT_ID(expr) возвращает новую версию тем же запросом.
+func (r *MariaPositionRepo) AcquireVersion(ctx context.Context, userID uint64) (uint64, error) {
+	if userID == 0 {
+		return 0, fmt.Errorf("недействительный userID: %d", userID)
+	}
+
+	query := `
+		INSERT INTO player_position_versions (user_id, version)
+		VALUES (?, LAST_INSERT_ID(1))
+		ON DUPLICATE KEY UPDATE version = LAST_INSERT_ID(version + 1)
 	`
 
-	stmt, err := tx.PrepareContext(ctx, query)
+	result, err := r.db.ExecContext(ctx, query, userID)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка обновления версии позиции для пользователя %d: %w", userID, err)
+	}
+	version, err := result.LastInsertId()
 	if err != nil {
-		return fmt.Errorf("ошибка подготовки запроса: %w", err)
+		return 0, fmt.Errorf("ошибка получения версии позиции для пользователя %d: %w", userID, err)
 	}
-	defer stmt.Close()
+	return uint64(version), nil
+}
 
-	// Выполняем запросы для каждой позиции
-	for userID, pos := range positions {
-		// Валидация каждой записи
+// SaveVersioned сохраняет позицию, если версия владения не устарела.
+func (r *MariaPositionRepo) SaveVersioned(ctx context.Context, userID uint64, pos vec.Vec3, version uint64) error {
+	stale, err := r.BatchSaveVersioned(ctx, map[uint64]VersionedPosition{userID: {Pos: pos, Version: version}})
+	if err != nil {
+		return err
+	}
+	if len(stale) > 0 {
+		return fmt.Errorf("пользователь %d, версия %d: %w", userID, version, ErrPositionConflict)
+	}
+	return nil
+}
+
+// BatchSaveVersioned сохраняет позиции с неустаревшей версией владения в
+// одной транзакции. Версии блокируются (SELECT ... FOR UPDATE) до фиксации,
+// поэтому AcquireVersion нового владельца не проскочит между проверкой и записью.
+func (r *MariaPositionRepo) BatchSaveVersioned(ctx context.Context, positions map[uint64]VersionedPosition) ([]uint64, error) {
+	if len(positions) == 0 {
+		return nil, nil
+	}
+
+	userIDs := make([]uint64, 0, len(positions))
+	for userID, entry := range positions {
 		if userID == 0 {
-			return fmt.Errorf("недействительный userID в batch: %d", userID)
+			return nil, fmt.Errorf("недействительный userID в batch: %d", userID)
 		}
-		if pos.Z < 0 || pos.Z > 255 {
-			return fmt.Errorf("недействительный layer для пользователя %d: %d", userID, pos.Z)
+		if entry.Pos.Z < 0 || entry.Pos.Z > 255 {
+			return nil, fmt.Errorf("недействительный layer для пользователя %d: %d", userID, entry.Pos.Z)
 		}
+		userIDs = append(userIDs, userID)
+	}
+	slices.Sort(userIDs)
 
-		_, err = stmt.ExecContext(ctx, userID, pos.X, pos.Y, pos.Z)
-		if err != nil {
-			return fmt.Errorf("ошибка сохранения позиции для пользователя %d в batch: %w", userID, err)
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback()
+
+	versions, err := lockPositionVersions(ctx, tx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var stale []uint64
+	fresh := make([]uint64, 0, len(userIDs))
+	toSave := make(map[uint64]vec.Vec3, len(userIDs))
+	for _, userID := range userIDs {
+		entry := positions[userID]
+		if entry.Version < versions[userID] {
+			stale = append(stale, userID)
+			continue
 		}
+		fresh = append(fresh, userID)
+		toSave[userID] = entry.Pos
 	}
 
-	// Фиксируем транзакцию
+	if err := upsertPositions(ctx, tx, fresh, toSave); err != nil {
+		return nil, err
+	}
 	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("ошибка фиксации транзакции: %w", err)
+		return nil, fmt.Errorf("ошибка фиксации транзакции: %w", err)
+	}
+	return stale, nil
+}
+
+// lockPositionVersions читает и блокирует версии владения пользователей до конца транзакции
+func lockPositionVersions(ctx context.Context, tx *sql.Tx, userIDs []uint64) (map[uint64]uint64, error) {
+	versions := make(map[uint64]uint64, len(userIDs))
+	for start := 0; start < len(userIDs); start += positionBatchRows {
+		batch := userIDs[start:min(start+positionBatchRows, len(userIDs))]
+
+		args := make([]interface{}, len(batch))
+		for i, userID := range batch {
+			args[i] = userID
+		}
+		query := "SELECT user_id, version FROM player_position_versions WHERE user_id IN (?" +
+			strings.Repeat(", ?", len(batch)-1) + ") FOR UPDATE"
+
+		rows, err := tx.QueryContext(ctx, query, args...)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка чтения версий позиций: %w", err)
+		}
+		for rows.Next() {
+			var userID, version uint64
+			if err := rows.Scan(&userID, &version); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("ошибка чтения версий позиций: %w", err)
+			}
+			versions[userID] = version
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, fmt.Errorf("ошибка чтения версий позиций: %w", err)
+		}
 	}
+	return versions, nil
+}
 
-	return nil
+// Ping проверяет доступность базы данных.
+func (r *MariaPositionRepo) Ping(ctx context.Context) error {
+	return r.db.PingContext(ctx)
 }
 
 // Close закрывает соединение с базой данных.
@@ -0,0 +1,212 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/vec"
+)
+
+// fakePositionDB - таблица player_positions в памяти за драйвером database/sql,
+// чтобы проверять SQL-репозиторий без сервера MariaDB. Изменения внутри
+// транзакции видны только после COMMIT
+type fakePositionDB struct {
+	mu       sync.Mutex
+	rows     map[uint64]vec.Vec3
+	inserts  int           // Выполненные INSERT
+	failOn   int           // Номер INSERT, завершающегося ошибкой (0 - без ошибок)
+	latency  time.Duration // Имитация сетевой задержки каждого запроса
+	commits  int
+	rollback int
+}
+
+func (db *fakePositionDB) Connect(context.Context) (driver.Conn, error) {
+	return &fakePositionConn{db: db}, nil
+}
+
+func (db *fakePositionDB) Driver() driver.Driver { return nil }
+
+func (db *fakePositionDB) snapshot() map[uint64]vec.Vec3 {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	rows := make(map[uint64]vec.Vec3, len(db.rows))
+	for id, pos := range db.rows {
+		rows[id] = pos
+	}
+	return rows
+}
+
+type fakePositionConn struct {
+	db      *fakePositionDB
+	pending map[uint64]vec.Vec3 // Изменения открытой транзакции (nil - вне транзакции)
+}
+
+func (c *fakePositionConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("fake: prepared statements не поддерживаются")
+}
+
+func (c *fakePositionConn) Close() error { return nil }
+
+func (c *fakePositionConn) Begin() (driver.Tx, error) {
+	c.pending = make(map[uint64]vec.Vec3)
+	return c, nil
+}
+
+func (c *fakePositionConn) Commit() error {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	for id, pos := range c.pending {
+		c.db.rows[id] = pos
+	}
+	c.db.commits++
+	c.pending = nil
+	return nil
+}
+
+func (c *fakePositionConn) Rollback() error {
+	c.db.mu.Lock()
+	c.db.rollback++
+	c.db.mu.Unlock()
+	c.pending = nil
+	return nil
+}
+
+// ExecContext выполняет INSERT INTO player_positions с любым числом строк
+func (c *fakePositionConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if !strings.HasPrefix(strings.TrimSpace(query), "INSERT INTO player_positions") || len(args)%4 != 0 {
+		return nil, errors.New("fake: неподдерживаемый запрос")
+	}
+	time.Sleep(c.db.latency)
+
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	c.db.inserts++
+	if c.db.inserts == c.db.failOn {
+		return nil, errors.New("fake: deadlock found when trying to get lock")
+	}
+
+	target := c.pending
+	if target == nil {
+		target = c.db.rows // autocommit
+	}
+	for i := 0; i < len(args); i += 4 {
+		target[uint64(args[i].Value.(int64))] = vec.Vec3{
+			X: int(args[i+1].Value.(int64)),
+			Y: int(args[i+2].Value.(int64)),
+			Z: int(args[i+3].Value.(int64)),
+		}
+	}
+	return driver.RowsAffected(len(args) / 4), nil
+}
+
+func newFakeMariaPositionRepo(tb testing.TB) (*MariaPositionRepo, *fakePositionDB) {
+	tb.Helper()
+	fake := &fakePositionDB{rows: make(map[uint64]vec.Vec3)}
+	db := sql.OpenDB(fake)
+	tb.Cleanup(func() { db.Close() })
+	return &MariaPositionRepo{db: db}, fake
+}
+
+func positionsFor(count int) map[uint64]vec.Vec3 {
+	positions := make(map[uint64]vec.Vec3, count)
+	for i := 1; i <= count; i++ {
+		positions[uint64(i)] = vec.Vec3{X: i, Y: -i, Z: 1}
+	}
+	return positions
+}
+
+func TestMariaPositionRepo_BatchSave(t *testing.T) {
+	repo, fake := newFakeMariaPositionRepo(t)
+	ctx := context.Background()
+
+	positions := positionsFor(2*positionBatchRows + 1)
+	if err := repo.BatchSave(ctx, positions); err != nil {
+		t.Fatalf("Ошибка пакетного сохранения: %v", err)
+	}
+
+	if fake.inserts != 3 {
+		t.Errorf("Ожидалось 3 многострочных INSERT, выполнено %d", fake.inserts)
+	}
+	if fake.commits != 1 {
+		t.Errorf("Ожидалась одна транзакция, зафиксировано %d", fake.commits)
+	}
+	rows := fake.snapshot()
+	if len(rows) != len(positions) {
+		t.Fatalf("Сохранено %d позиций из %d", len(rows), len(positions))
+	}
+	for id, pos := range positions {
+		if rows[id] != pos {
+			t.Errorf("Пользователь %d: ожидалась %+v, сохранена %+v", id, pos, rows[id])
+		}
+	}
+}
+
+func TestMariaPositionRepo_BatchSavePartialFailureWritesNothing(t *testing.T) {
+	repo, fake := newFakeMariaPositionRepo(t)
+	ctx := context.Background()
+
+	original := vec.Vec3{X: 7, Y: 7, Z: 1}
+	if err := repo.Save(ctx, 1, original); err != nil {
+		t.Fatalf("Ошибка сохранения позиции: %v", err)
+	}
+
+	// Вторая пачка падает, когда первая уже выполнена внутри транзакции
+	fake.failOn = fake.inserts + 2
+	if err := repo.BatchSave(ctx, positionsFor(2*positionBatchRows)); err == nil {
+		t.Fatal("Ожидалась ошибка пакетного сохранения")
+	}
+
+	rows := fake.snapshot()
+	if len(rows) != 1 || rows[1] != original {
+		t.Errorf("После ошибки позиции не должны меняться, сохранено: %d, пользователь 1: %+v", len(rows), rows[1])
+	}
+	if fake.commits != 0 || fake.rollback != 1 {
+		t.Errorf("Ожидался откат транзакции: commits=%d, rollbacks=%d", fake.commits, fake.rollback)
+	}
+
+	// Недействительная запись отклоняется до обращения к базе
+	inserts := fake.inserts
+	invalid := positionsFor(10)
+	invalid[5] = vec.Vec3{Z: 300}
+	if err := repo.BatchSave(ctx, invalid); err == nil {
+		t.Fatal("Ожидалась ошибка для недействительного layer")
+	}
+	if fake.inserts != inserts {
+		t.Error("Запросы к базе не должны выполняться для недействительного batch")
+	}
+}
+
+// BenchmarkMariaPositionRepo_Autosave сравнивает сохранение 500 позиций
+// отдельными запросами и одним пакетом при задержке 50мкс на запрос
+func BenchmarkMariaPositionRepo_Autosave(b *testing.B) {
+	positions := positionsFor(500)
+	ctx := context.Background()
+
+	b.Run("Serial", func(b *testing.B) {
+		repo, fake := newFakeMariaPositionRepo(b)
+		fake.latency = 50 * time.Microsecond
+		for i := 0; i < b.N; i++ {
+			for userID, pos := range positions {
+				if err := repo.Save(ctx, userID, pos); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+
+	b.Run("Batched", func(b *testing.B) {
+		repo, fake := newFakeMariaPositionRepo(b)
+		fake.latency = 50 * time.Microsecond
+		for i := 0; i < b.N; i++ {
+			if err := repo.BatchSave(ctx, positions); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,110 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// MemoryPlayerStateRepo реализует PlayerStateRepo в памяти.
+// ВНИМАНИЕ: Данные теряются при перезапуске сервера!
+type MemoryPlayerStateRepo struct {
+	mu   sync.RWMutex
+	data map[uint64]PlayerState // userID -> состояние
+}
+
+// NewMemoryPlayerStateRepo создает новый репозиторий состояний игроков в памяти.
+func NewMemoryPlayerStateRepo() *MemoryPlayerStateRepo {
+	return &MemoryPlayerStateRepo{
+		data: make(map[uint64]PlayerState),
+	}
+}
+
+// Save сохраняет состояние игрока в памяти.
+func (r *MemoryPlayerStateRepo) Save(ctx context.Context, userID uint64, state PlayerState) error {
+	if userID == 0 {
+		return fmt.Errorf("недействительный userID: %d", userID)
+	}
+	if err := state.Validate(); err != nil {
+		return err
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.data[userID] = state.Clone()
+	return nil
+}
+
+// Load загружает состояние игрока из памяти.
+func (r *MemoryPlayerStateRepo) Load(ctx context.Context, userID uint64) (PlayerState, bool, error) {
+	if userID == 0 {
+		return PlayerState{}, false, fmt.Errorf("недействительный userID: %d", userID)
+	}
+
+	select {
+	case <-ctx.Done():
+		return PlayerState{}, false, ctx.Err()
+	default:
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	state, exists := r.data[userID]
+	return state.Clone(), exists, nil
+}
+
+// Delete удаляет сохраненное состояние игрока из памяти.
+func (r *MemoryPlayerStateRepo) Delete(ctx context.Context, userID uint64) error {
+	if userID == 0 {
+		return fmt.Errorf("недействительный userID: %d", userID)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.data[userID]; !exists {
+		return fmt.Errorf("состояние для пользователя %d не найдено", userID)
+	}
+
+	delete(r.data, userID)
+	return nil
+}
+
+// BatchSave сохраняет состояния нескольких игроков в памяти.
+func (r *MemoryPlayerStateRepo) BatchSave(ctx context.Context, states map[uint64]PlayerState) error {
+	if len(states) == 0 {
+		return nil
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	// Валидация всех записей перед сохранением
+	for userID, state := range states {
+		if userID == 0 {
+			return fmt.Errorf("недействительный userID в batch: %d", userID)
+		}
+		if err := state.Validate(); err != nil {
+			return fmt.Errorf("пользователь %d: %w", userID, err)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for userID, state := range states {
+		r.data[userID] = state.Clone()
+	}
+	return nil
+}
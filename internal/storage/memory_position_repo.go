@@ -1,8 +1,10 @@
 package storage
 
 import (
+	"cmp"
 	"context"
 	"fmt"
+	"slices"
 	"sync"
 
 	"github.com/annel0/mmo-game/internal/vec"
@@ -13,8 +15,9 @@ import (
 // или для CI/локальной разработки без БД.
 // ВНИМАНИЕ: Данные теряются при перезапуске сервера!
 type MemoryPositionRepo struct {
-	mu   sync.RWMutex
-	data map[uint64]vec.Vec3 // userID -> позиция
+	mu       sync.RWMutex
+	data     map[uint64]vec.Vec3 // userID -> позиция
+	versions map[uint64]uint64   // userID -> версия владения
 }
 
 // NewMemoryPositionRepo создает новый репозиторий позиций в памяти.
@@ -24,7 +27,8 @@ type MemoryPositionRepo struct {
 //	*MemoryPositionRepo - экземпляр репозитория
 func NewMemoryPositionRepo() *MemoryPositionRepo {
 	return &MemoryPositionRepo{
-		data: make(map[uint64]vec.Vec3),
+		data:     make(map[uint64]vec.Vec3),
+		versions: make(map[uint64]uint64),
 	}
 }
 
@@ -134,6 +138,93 @@ func (r *MemoryPositionRepo) BatchSave(ctx context.Context, positions map[uint64
 	return nil
 }
 
+// AcquireVersion увеличивает версию владения позицией игрока.
+func (r *MemoryPositionRepo) AcquireVersion(ctx context.Context, userID uint64) (uint64, error) {
+	if userID == 0 {
+		return 0, fmt.Errorf("недействительный userID: %d", userID)
+	}
+
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	default:
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.versions[userID]++
+	return r.versions[userID], nil
+}
+
+// SaveVersioned сохраняет позицию, если версия владения не устарела.
+func (r *MemoryPositionRepo) SaveVersioned(ctx context.Context, userID uint64, pos vec.Vec3, version uint64) error {
+	stale, err := r.BatchSaveVersioned(ctx, map[uint64]VersionedPosition{userID: {Pos: pos, Version: version}})
+	if err != nil {
+		return err
+	}
+	if len(stale) > 0 {
+		return fmt.Errorf("пользователь %d, версия %d: %w", userID, version, ErrPositionConflict)
+	}
+	return nil
+}
+
+// BatchSaveVersioned сохраняет позиции с неустаревшей версией владения.
+func (r *MemoryPositionRepo) BatchSaveVersioned(ctx context.Context, positions map[uint64]VersionedPosition) ([]uint64, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	for userID, entry := range positions {
+		if userID == 0 {
+			return nil, fmt.Errorf("недействительный userID в batch: %d", userID)
+		}
+		if entry.Pos.Z < 0 || entry.Pos.Z > 255 {
+			return nil, fmt.Errorf("недействительный layer для пользователя %d: %d", userID, entry.Pos.Z)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var stale []uint64
+	for userID, entry := range positions {
+		if entry.Version < r.versions[userID] {
+			stale = append(stale, userID)
+			continue
+		}
+		r.data[userID] = entry.Pos
+	}
+	slices.Sort(stale)
+	return stale, nil
+}
+
+// ListPositions реализует PositionLister.
+func (r *MemoryPositionRepo) ListPositions(ctx context.Context, afterUserID uint64, limit int) ([]UserPosition, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var page []UserPosition
+	for userID, pos := range r.data {
+		if userID > afterUserID {
+			page = append(page, UserPosition{UserID: userID, Pos: pos})
+		}
+	}
+	slices.SortFunc(page, func(a, b UserPosition) int { return cmp.Compare(a.UserID, b.UserID) })
+	if limit > 0 && len(page) > limit {
+		page = page[:limit]
+	}
+	return page, nil
+}
+
 // GetAllPositions возвращает все сохраненные позиции (для отладки).
 // Этот метод не входит в интерфейс PositionRepo, но полезен для тестирования.
 func (r *MemoryPositionRepo) GetAllPositions() map[uint64]vec.Vec3 {
@@ -161,4 +252,5 @@ func (r *MemoryPositionRepo) Clear() {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	r.data = make(map[uint64]vec.Vec3)
+	r.versions = make(map[uint64]uint64)
 }
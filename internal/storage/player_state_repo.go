@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+)
+
+// PlayerState содержит прогресс игрока, который должен переживать переподключение.
+// Как и позиции, состояние привязано к UserID, а не к EntityID текущей сессии.
+type PlayerState struct {
+	Level           int                `json:"level"`
+	Experience      int                `json:"experience"`
+	StatusEffects   map[string]float64 `json:"status_effects,omitempty"`   // эффект -> оставшаяся длительность (сек)
+	EffectIntensity map[string]float64 `json:"effect_intensity,omitempty"` // эффект -> сила (нет записи - сила 1)
+}
+
+// Validate проверяет корректность состояния перед сохранением
+func (s PlayerState) Validate() error {
+	if s.Level < 1 {
+		return fmt.Errorf("недействительный уровень: %d", s.Level)
+	}
+	if s.Experience < 0 {
+		return fmt.Errorf("недействительный опыт: %d", s.Experience)
+	}
+	for effect, remaining := range s.StatusEffects {
+		if effect == "" || remaining < 0 {
+			return fmt.Errorf("недействительный эффект %q: %.2f", effect, remaining)
+		}
+	}
+	for effect, intensity := range s.EffectIntensity {
+		if intensity <= 0 {
+			return fmt.Errorf("недействительная сила эффекта %q: %.2f", effect, intensity)
+		}
+	}
+	return nil
+}
+
+// Clone возвращает копию состояния с независимой картой эффектов
+func (s PlayerState) Clone() PlayerState {
+	clone := s
+	if s.StatusEffects != nil {
+		clone.StatusEffects = make(map[string]float64, len(s.StatusEffects))
+		for effect, remaining := range s.StatusEffects {
+			clone.StatusEffects[effect] = remaining
+		}
+	}
+	if s.EffectIntensity != nil {
+		clone.EffectIntensity = make(map[string]float64, len(s.EffectIntensity))
+		for effect, intensity := range s.EffectIntensity {
+			clone.EffectIntensity[effect] = intensity
+		}
+	}
+	return clone
+}
+
+// PlayerStateRepo определяет интерфейс для сохранения и загрузки прогресса игроков
+// (уровень, опыт, активные эффекты). Используется вместе с PositionRepo.
+type PlayerStateRepo interface {
+	// Save сохраняет состояние игрока.
+	Save(ctx context.Context, userID uint64, state PlayerState) error
+
+	// Load загружает состояние игрока.
+	// Возвращает false, если состояние не найдено (первый вход).
+	Load(ctx context.Context, userID uint64) (PlayerState, bool, error)
+
+	// Delete удаляет сохраненное состояние игрока.
+	Delete(ctx context.Context, userID uint64) error
+
+	// BatchSave сохраняет состояния нескольких игроков (для автосохранения).
+	BatchSave(ctx context.Context, states map[uint64]PlayerState) error
+}
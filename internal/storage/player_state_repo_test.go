@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"context"
+	"testing"
+)
+
+// TestMemoryPlayerStateRepo тестирует in-memory репозиторий прогресса игроков
+func TestMemoryPlayerStateRepo(t *testing.T) {
+	repo := NewMemoryPlayerStateRepo()
+	ctx := context.Background()
+
+	t.Run("Save and Load", func(t *testing.T) {
+		state := PlayerState{
+			Level:         3,
+			Experience:    250,
+			StatusEffects: map[string]float64{"regeneration": 12.5},
+		}
+		if err := repo.Save(ctx, 1, state); err != nil {
+			t.Fatalf("Ошибка сохранения состояния: %v", err)
+		}
+
+		// Изменение исходной карты не должно влиять на сохранённое состояние
+		state.StatusEffects["regeneration"] = 0
+
+		loaded, found, err := repo.Load(ctx, 1)
+		if err != nil {
+			t.Fatalf("Ошибка загрузки состояния: %v", err)
+		}
+		if !found {
+			t.Fatal("Состояние не найдено")
+		}
+		if loaded.Level != 3 || loaded.Experience != 250 {
+			t.Errorf("Неверное состояние: %+v", loaded)
+		}
+		if loaded.StatusEffects["regeneration"] != 12.5 {
+			t.Errorf("Эффект изменился после сохранения: %v", loaded.StatusEffects)
+		}
+	})
+
+	t.Run("Load Not Found", func(t *testing.T) {
+		_, found, err := repo.Load(ctx, 999)
+		if err != nil {
+			t.Fatalf("Неожиданная ошибка: %v", err)
+		}
+		if found {
+			t.Error("Состояние не должно быть найдено")
+		}
+	})
+
+	t.Run("Invalid State", func(t *testing.T) {
+		invalid := []PlayerState{
+			{Level: 0},
+			{Level: 1, Experience: -1},
+			{Level: 1, StatusEffects: map[string]float64{"poison": -1}},
+			{Level: 1, EffectIntensity: map[string]float64{"poison": 0}},
+		}
+		for _, state := range invalid {
+			if err := repo.Save(ctx, 2, state); err == nil {
+				t.Errorf("Ожидалась ошибка для %+v", state)
+			}
+		}
+		if err := repo.Save(ctx, 0, PlayerState{Level: 1}); err == nil {
+			t.Error("Ожидалась ошибка для нулевого userID")
+		}
+	})
+
+	t.Run("BatchSave", func(t *testing.T) {
+		states := map[uint64]PlayerState{
+			10: {Level: 2, Experience: 100},
+			11: {Level: 5, Experience: 900},
+		}
+		if err := repo.BatchSave(ctx, states); err != nil {
+			t.Fatalf("Ошибка пакетного сохранения: %v", err)
+		}
+		for userID, expected := range states {
+			loaded, found, err := repo.Load(ctx, userID)
+			if err != nil || !found {
+				t.Fatalf("Состояние %d не загружено: found=%v err=%v", userID, found, err)
+			}
+			if loaded.Level != expected.Level || loaded.Experience != expected.Experience {
+				t.Errorf("Пользователь %d: ожидалось %+v, получено %+v", userID, expected, loaded)
+			}
+		}
+
+		// Одна некорректная запись отклоняет весь пакет
+		err := repo.BatchSave(ctx, map[uint64]PlayerState{
+			12: {Level: 1},
+			13: {Level: 0},
+		})
+		if err == nil {
+			t.Fatal("Ожидалась ошибка для некорректного пакета")
+		}
+		if _, found, _ := repo.Load(ctx, 12); found {
+			t.Error("Некорректный пакет не должен сохраняться частично")
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		if err := repo.Delete(ctx, 1); err != nil {
+			t.Fatalf("Ошибка удаления: %v", err)
+		}
+		if _, found, _ := repo.Load(ctx, 1); found {
+			t.Error("Состояние должно быть удалено")
+		}
+	})
+}
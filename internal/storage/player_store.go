@@ -0,0 +1,13 @@
+package storage
+
+import "github.com/annel0/mmo-game/internal/auth"
+
+// PlayerStore объединяет хранилища, в которых живут данные игрока: учётные
+// записи, позиции и прогресс. Создаётся один раз и передаётся и REST API, и
+// игровому серверу, поэтому вход через REST и авторизация в игре находят
+// одного и того же пользователя с тем же ID
+type PlayerStore struct {
+	Users     auth.UserRepository
+	Positions PositionRepo
+	States    PlayerStateRepo
+}
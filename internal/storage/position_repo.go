@@ -2,10 +2,21 @@ package storage
 
 import (
 	"context"
+	"errors"
 
 	"github.com/annel0/mmo-game/internal/vec"
 )
 
+// ErrPositionConflict возвращается, если позиция в хранилище записана с более
+// новой версией владения: сохраняющий узел больше не отвечает за игрока
+var ErrPositionConflict = errors.New("позиция игрока сохранена с более новой версией")
+
+// VersionedPosition - позиция игрока с версией владения (см. PositionRepo.AcquireVersion)
+type VersionedPosition struct {
+	Pos     vec.Vec3
+	Version uint64
+}
+
 // PositionRepo определяет интерфейс для сохранения и загрузки позиций игроков.
 // Позиции привязаны к UserID (постоянный идентификатор аккаунта), а не к EntityID.
 // Это позволяет сохранять позицию между сессиями игры.
@@ -44,4 +55,42 @@ type PositionRepo interface {
 	// Возвращает:
 	//   error - ошибка при сохранении
 	BatchSave(ctx context.Context, positions map[uint64]vec.Vec3) error
+
+	// AcquireVersion увеличивает версию владения позицией игрока. Вызывается
+	// узлом, который принимает игрока (в том числе при переходе из другого
+	// региона): сохранения предыдущего владельца после этого отклоняются.
+	// Параметры:
+	//   ctx - контекст для отмены операции
+	//   userID - уникальный идентификатор пользователя
+	// Возвращает:
+	//   uint64 - новая версия, с которой узел сохраняет позицию
+	//   error - ошибка при обновлении версии
+	AcquireVersion(ctx context.Context, userID uint64) (uint64, error)
+
+	// SaveVersioned сохраняет позицию, только если version не меньше текущей
+	// версии владения игроком. Иначе позиция не меняется.
+	// Возвращает:
+	//   error - ErrPositionConflict для устаревшей версии или ошибка при сохранении
+	SaveVersioned(ctx context.Context, userID uint64, pos vec.Vec3, version uint64) error
+
+	// BatchSaveVersioned сохраняет позиции с проверкой версий (для автосохранения).
+	// Позиции с устаревшей версией пропускаются.
+	// Возвращает:
+	//   []uint64 - пользователи, позиции которых пропущены из-за версии
+	//   error - ошибка при сохранении (тогда не сохраняется ни одна позиция)
+	BatchSaveVersioned(ctx context.Context, positions map[uint64]VersionedPosition) ([]uint64, error)
+}
+
+// UserPosition - сохранённая позиция игрока
+type UserPosition struct {
+	UserID uint64
+	Pos    vec.Vec3
+}
+
+// PositionLister перечисляет сохранённые позиции по возрастанию userID.
+// Не входит в PositionRepo: нужен только для переноса позиций между
+// хранилищами (cmd/tools/pos-migrate)
+type PositionLister interface {
+	// ListPositions возвращает до limit позиций пользователей с ID больше afterUserID
+	ListPositions(ctx context.Context, afterUserID uint64, limit int) ([]UserPosition, error)
 }
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -303,3 +304,57 @@ func TestConcurrentAccess(t *testing.T) {
 			expectedCount, actualCount)
 	}
 }
+
+// TestMemoryPositionRepo_Versioning проверяет, что устаревший владелец не
+// перезаписывает позицию, сохранённую узлом с более новой версией
+func TestMemoryPositionRepo_Versioning(t *testing.T) {
+	repo := NewMemoryPositionRepo()
+	ctx := context.Background()
+	userID := uint64(42)
+
+	stale, err := repo.AcquireVersion(ctx, userID)
+	if err != nil {
+		t.Fatalf("Ошибка получения версии: %v", err)
+	}
+	current, err := repo.AcquireVersion(ctx, userID) // Игрок перешёл в другой регион
+	if err != nil {
+		t.Fatalf("Ошибка получения версии: %v", err)
+	}
+	if current <= stale {
+		t.Fatalf("Версия должна расти: %d -> %d", stale, current)
+	}
+
+	newer := vec.Vec3{X: 100, Y: 200, Z: 1}
+	if err := repo.SaveVersioned(ctx, userID, newer, current); err != nil {
+		t.Fatalf("Ошибка сохранения текущим владельцем: %v", err)
+	}
+
+	// Прежний регион сохраняет позицию позже нового
+	err = repo.SaveVersioned(ctx, userID, vec.Vec3{X: 1, Y: 2, Z: 1}, stale)
+	if !errors.Is(err, ErrPositionConflict) {
+		t.Fatalf("Ожидался ErrPositionConflict, получено: %v", err)
+	}
+	skipped, err := repo.BatchSaveVersioned(ctx, map[uint64]VersionedPosition{
+		userID: {Pos: vec.Vec3{X: 3, Y: 4, Z: 1}, Version: stale},
+		7:      {Pos: vec.Vec3{X: 5, Y: 6, Z: 1}, Version: 0},
+	})
+	if err != nil {
+		t.Fatalf("Ошибка пакетного сохранения: %v", err)
+	}
+	if len(skipped) != 1 || skipped[0] != userID {
+		t.Errorf("Ожидался пропуск только пользователя %d, пропущены: %v", userID, skipped)
+	}
+
+	pos, _, _ := repo.Load(ctx, userID)
+	if pos != newer {
+		t.Errorf("Позиция перезаписана устаревшим владельцем: %+v", pos)
+	}
+	if pos, found, _ := repo.Load(ctx, 7); !found || pos != (vec.Vec3{X: 5, Y: 6, Z: 1}) {
+		t.Errorf("Позиция пользователя без версии не сохранена: %+v", pos)
+	}
+
+	// Текущий владелец продолжает сохранять с той же версией
+	if err := repo.SaveVersioned(ctx, userID, vec.Vec3{X: 101, Y: 201, Z: 1}, current); err != nil {
+		t.Errorf("Повторное сохранение текущим владельцем отклонено: %v", err)
+	}
+}
@@ -5,8 +5,11 @@ import (
 	"fmt"
 	"log"
 	"path/filepath"
+	"sort"
 	"sync"
 
+	"github.com/annel0/mmo-game/internal/protocol"
+	"github.com/annel0/mmo-game/internal/storage_interface"
 	"github.com/annel0/mmo-game/internal/vec"
 	"github.com/annel0/mmo-game/internal/world"
 	"github.com/annel0/mmo-game/internal/world/block"
@@ -33,18 +36,46 @@ type BlockDelta struct {
 	Payload map[string]interface{} `json:"payload,omitempty"`
 }
 
-// EntityDelta содержит данные о сущности для сохранения
-type EntityDelta struct {
-	ID       uint64                 `json:"id"`       // Уникальный ID сущности
-	Type     uint16                 `json:"type"`     // Тип сущности
-	Position vec.Vec2               `json:"position"` // Позиция в мире
-	Payload  map[string]interface{} `json:"payload"`  // Метаданные сущности
-}
-
 // BigChunkEntities содержит информацию о сущностях в BigChunk
 type BigChunkEntities struct {
-	Coords   vec.Vec2               `json:"coords"`   // Координаты BigChunk
-	Entities map[uint64]EntityDelta `json:"entities"` // Карта сущностей по ID
+	Coords   vec.Vec2                                    `json:"coords"`   // Координаты BigChunk
+	Entities map[uint64]storage_interface.EntitySnapshot `json:"entities"` // Снимки сущностей по ID
+}
+
+// MarshalJSON кодирует дельту блока, сохраняя различие между целыми и
+// дробными числами в Payload
+func (d BlockDelta) MarshalJSON() ([]byte, error) {
+	type alias BlockDelta
+	var payload json.RawMessage
+	if len(d.Payload) > 0 {
+		data, err := protocol.MarshalMetadata(d.Payload)
+		if err != nil {
+			return nil, err
+		}
+		payload = data
+	}
+	return json.Marshal(struct {
+		alias
+		Payload json.RawMessage `json:"payload,omitempty"`
+	}{alias: alias(d), Payload: payload})
+}
+
+// UnmarshalJSON декодирует дельту блока; целые числа в Payload остаются int
+func (d *BlockDelta) UnmarshalJSON(data []byte) error {
+	type alias BlockDelta
+	aux := struct {
+		*alias
+		Payload json.RawMessage `json:"payload,omitempty"`
+	}{alias: (*alias)(d)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	payload, err := protocol.UnmarshalMetadata(aux.Payload)
+	if err != nil {
+		return err
+	}
+	d.Payload = payload
+	return nil
 }
 
 // NewWorldStorage создает новое хранилище мира
@@ -241,8 +272,8 @@ func (ws *WorldStorage) LoadAndApplyChunk(chunk *world.Chunk) error {
 	return ws.ApplyDeltaToChunk(chunk, delta)
 }
 
-// SaveEntities сохраняет данные о сущностях из BigChunk
-func (ws *WorldStorage) SaveEntities(bigChunkCoords vec.Vec2, entities map[uint64]interface{}) error {
+// SaveEntities сохраняет снимки сущностей BigChunk
+func (ws *WorldStorage) SaveEntities(bigChunkCoords vec.Vec2, entities []storage_interface.EntitySnapshot) error {
 	ws.mutex.RLock()
 	defer ws.mutex.RUnlock()
 
@@ -253,43 +284,10 @@ func (ws *WorldStorage) SaveEntities(bigChunkCoords vec.Vec2, entities map[uint6
 	// Создаем структуру для сохранения
 	delta := BigChunkEntities{
 		Coords:   bigChunkCoords,
-		Entities: make(map[uint64]EntityDelta),
-	}
-
-	// Преобразуем сущности в формат для сохранения
-	for id, entity := range entities {
-		// Обрабатываем разные типы EntityData
-		if entityData, ok := entity.(world.EntityData); ok {
-			delta.Entities[id] = EntityDelta{
-				ID:       entityData.ID,
-				Type:     entityData.Type,
-				Position: entityData.Position,
-				Payload:  entityData.Metadata,
-			}
-		} else if entityMap, ok := entity.(map[string]interface{}); ok {
-			// Альтернативный формат из карты
-			position := vec.Vec2{X: 0, Y: 0}
-			if pos, ok := entityMap["position"].(vec.Vec2); ok {
-				position = pos
-			}
-
-			entityType := uint16(0)
-			if typeVal, ok := entityMap["type"].(uint16); ok {
-				entityType = typeVal
-			}
-
-			payload := make(map[string]interface{})
-			if pl, ok := entityMap["payload"].(map[string]interface{}); ok {
-				payload = pl
-			}
-
-			delta.Entities[id] = EntityDelta{
-				ID:       id,
-				Type:     entityType,
-				Position: position,
-				Payload:  payload,
-			}
-		}
+		Entities: make(map[uint64]storage_interface.EntitySnapshot, len(entities)),
+	}
+	for _, entity := range entities {
+		delta.Entities[entity.ID] = entity
 	}
 
 	// Если нет сущностей для сохранения, пропускаем
@@ -318,8 +316,9 @@ func (ws *WorldStorage) SaveEntities(bigChunkCoords vec.Vec2, entities map[uint6
 	return nil
 }
 
-// LoadEntities загружает данные о сущностях для BigChunk
-func (ws *WorldStorage) LoadEntities(bigChunkCoords vec.Vec2) (*BigChunkEntities, error) {
+// LoadEntities загружает снимки сущностей BigChunk, упорядоченные по ID.
+// Читаются записи всех версий формата EntitySnapshot
+func (ws *WorldStorage) LoadEntities(bigChunkCoords vec.Vec2) ([]storage_interface.EntitySnapshot, error) {
 	ws.mutex.RLock()
 	defer ws.mutex.RUnlock()
 
@@ -343,12 +342,9 @@ func (ws *WorldStorage) LoadEntities(bigChunkCoords vec.Vec2) (*BigChunkEntities
 		})
 	})
 
-	// Если сущности не найдены, возвращаем пустую структуру
+	// Если сущности не найдены, возвращаем пустой список
 	if err == badger.ErrKeyNotFound {
-		return &BigChunkEntities{
-			Coords:   bigChunkCoords,
-			Entities: make(map[uint64]EntityDelta),
-		}, nil
+		return []storage_interface.EntitySnapshot{}, nil
 	}
 
 	if err != nil {
@@ -361,26 +357,74 @@ func (ws *WorldStorage) LoadEntities(bigChunkCoords vec.Vec2) (*BigChunkEntities
 		return nil, fmt.Errorf("ошибка десериализации сущностей: %w", err)
 	}
 
-	return &delta, nil
+	entities := make([]storage_interface.EntitySnapshot, 0, len(delta.Entities))
+	for id, entity := range delta.Entities {
+		if entity.ID == 0 {
+			entity.ID = id
+		}
+		entities = append(entities, entity)
+	}
+	sort.Slice(entities, func(i, j int) bool { return entities[i].ID < entities[j].ID })
+
+	return entities, nil
 }
 
-// ApplyEntityDeltaToBigChunk применяет загруженные данные о сущностях к BigChunk
-func (ws *WorldStorage) ApplyEntityDeltaToBigChunk(entities map[uint64]interface{}, delta *BigChunkEntities) {
-	if delta == nil || len(delta.Entities) == 0 {
-		return
+// worldMetaKey - ключ метаданных мира в BadgerDB
+const worldMetaKey = "world:meta"
+
+// SaveWorldMeta сохраняет метаданные мира
+func (ws *WorldStorage) SaveWorldMeta(meta storage_interface.WorldMeta) error {
+	ws.mutex.RLock()
+	defer ws.mutex.RUnlock()
+
+	if !ws.isReady {
+		return fmt.Errorf("хранилище не готово")
+	}
+
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return fmt.Errorf("ошибка сериализации метаданных мира: %w", err)
+	}
+
+	err = ws.db.Update(func(txn *badger.Txn) error {
+		return txn.Set([]byte(worldMetaKey), data)
+	})
+	if err != nil {
+		return fmt.Errorf("ошибка сохранения метаданных мира в BadgerDB: %w", err)
+	}
+	return nil
+}
+
+// LoadWorldMeta загружает метаданные мира (false, если они не сохранялись)
+func (ws *WorldStorage) LoadWorldMeta() (storage_interface.WorldMeta, bool, error) {
+	ws.mutex.RLock()
+	defer ws.mutex.RUnlock()
+
+	var meta storage_interface.WorldMeta
+	if !ws.isReady {
+		return meta, false, fmt.Errorf("хранилище не готово")
 	}
 
-	// Применяем данные к карте сущностей
-	for id, entityDelta := range delta.Entities {
-		// Создаем экземпляр EntityData
-		entityData := world.EntityData{
-			ID:       entityDelta.ID,
-			Type:     entityDelta.Type,
-			Position: entityDelta.Position,
-			Metadata: entityDelta.Payload,
+	var data []byte
+	err := ws.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get([]byte(worldMetaKey))
+		if err != nil {
+			return err
 		}
+		return item.Value(func(val []byte) error {
+			data = append([]byte{}, val...)
+			return nil
+		})
+	})
+	if err == badger.ErrKeyNotFound {
+		return meta, false, nil
+	}
+	if err != nil {
+		return meta, false, fmt.Errorf("ошибка чтения метаданных мира из BadgerDB: %w", err)
+	}
 
-		// Добавляем в карту сущностей
-		entities[id] = entityData
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return meta, false, fmt.Errorf("ошибка десериализации метаданных мира: %w", err)
 	}
+	return meta, true, nil
 }
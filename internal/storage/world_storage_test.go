@@ -92,19 +92,11 @@ func TestSaveAndLoadChunk(t *testing.T) {
 	if !exists {
 		t.Error("Метаданные 'level' не найдены")
 	} else {
-		levelVal, ok := level.(float64) // JSON десериализует числа как float64
-		if ok {
-			if int(levelVal) != 7 {
-				t.Errorf("Неверное значение метаданных: %v (%.1f), ожидалось 7", level, levelVal)
-			}
-		} else {
-			// Пробуем как int
-			levelInt, ok := level.(int)
-			if !ok {
-				t.Errorf("Неверный тип метаданных: %T, ожидался float64 или int", level)
-			} else if levelInt != 7 {
-				t.Errorf("Неверное значение метаданных: %d, ожидалось 7", levelInt)
-			}
+		levelInt, ok := level.(int) // целые числа переживают сохранение как int
+		if !ok {
+			t.Errorf("Неверный тип метаданных: %T, ожидался int", level)
+		} else if levelInt != 7 {
+			t.Errorf("Неверное значение метаданных: %d, ожидалось 7", levelInt)
 		}
 	}
 
@@ -117,19 +109,11 @@ func TestSaveAndLoadChunk(t *testing.T) {
 	if !exists {
 		t.Error("Метаданные 'growth' не найдены")
 	} else {
-		growthVal, ok := growth.(float64) // JSON десериализует числа как float64
-		if ok {
-			if int(growthVal) != 3 {
-				t.Errorf("Неверное значение метаданных: %v (%.1f), ожидалось 3", growth, growthVal)
-			}
-		} else {
-			// Пробуем как int
-			growthInt, ok := growth.(int)
-			if !ok {
-				t.Errorf("Неверный тип метаданных: %T, ожидался float64 или int", growth)
-			} else if growthInt != 3 {
-				t.Errorf("Неверное значение метаданных: %d, ожидалось 3", growthInt)
-			}
+		growthInt, ok := growth.(int) // целые числа переживают сохранение как int
+		if !ok {
+			t.Errorf("Неверный тип метаданных: %T, ожидался int", growth)
+		} else if growthInt != 3 {
+			t.Errorf("Неверное значение метаданных: %d, ожидалось 3", growthInt)
 		}
 	}
 }
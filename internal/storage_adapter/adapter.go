@@ -23,56 +23,24 @@ func NewStorageProvider(dataPath string) (storage_interface.StorageProvider, err
 	}, nil
 }
 
-// SaveEntities сохраняет данные о сущностях из BigChunk
-func (a *WorldStorageAdapter) SaveEntities(bigChunkCoords vec.Vec2, entities map[uint64]interface{}) error {
+// SaveEntities сохраняет снимки сущностей BigChunk
+func (a *WorldStorageAdapter) SaveEntities(bigChunkCoords vec.Vec2, entities []storage_interface.EntitySnapshot) error {
 	return a.storage.SaveEntities(bigChunkCoords, entities)
 }
 
-// LoadEntities загружает данные о сущностях для BigChunk и адаптирует их к интерфейсу
-func (a *WorldStorageAdapter) LoadEntities(bigChunkCoords vec.Vec2) (*storage_interface.EntitiesData, error) {
-	// Вызываем исходную функцию из WorldStorage
-	entities, err := a.storage.LoadEntities(bigChunkCoords)
-	if err != nil {
-		return nil, err
-	}
-
-	// Адаптируем результат к формату EntitiesData
-	result := &storage_interface.EntitiesData{
-		Coords:   bigChunkCoords,
-		Entities: make(map[uint64]storage_interface.EntityStorageData),
-	}
-
-	// Копируем данные сущностей
-	for id, entity := range entities.Entities {
-		result.Entities[id] = storage_interface.EntityStorageData{
-			ID:       entity.ID,
-			Type:     entity.Type,
-			Position: entity.Position,
-			Payload:  entity.Payload,
-		}
-	}
-
-	return result, nil
+// LoadEntities загружает снимки сущностей BigChunk
+func (a *WorldStorageAdapter) LoadEntities(bigChunkCoords vec.Vec2) ([]storage_interface.EntitySnapshot, error) {
+	return a.storage.LoadEntities(bigChunkCoords)
 }
 
-// ApplyEntitiesToBigChunk применяет загруженные данные к карте сущностей BigChunk
-func (a *WorldStorageAdapter) ApplyEntitiesToBigChunk(entities map[uint64]interface{}, data *storage_interface.EntitiesData) {
-	if data == nil || len(data.Entities) == 0 {
-		return
-	}
-
-	// Применяем данные к карте сущностей
-	for id, entityData := range data.Entities {
-		mapped := map[string]interface{}{
-			"ID":       entityData.ID,
-			"Type":     entityData.Type,
-			"Position": entityData.Position,
-			"Metadata": entityData.Payload,
-		}
+// SaveWorldMeta сохраняет метаданные мира
+func (a *WorldStorageAdapter) SaveWorldMeta(meta storage_interface.WorldMeta) error {
+	return a.storage.SaveWorldMeta(meta)
+}
 
-		// Добавляем в карту сущностей
-		entities[id] = mapped
-	}
+// LoadWorldMeta загружает метаданные мира
+func (a *WorldStorageAdapter) LoadWorldMeta() (storage_interface.WorldMeta, bool, error) {
+	return a.storage.LoadWorldMeta()
 }
 
 // Close закрывает хранилище
@@ -9,6 +9,8 @@ import (
 	"sync"
 	"time"
 
+	"github.com/annel0/mmo-game/internal/protocol"
+	"github.com/annel0/mmo-game/internal/storage_interface"
 	"github.com/annel0/mmo-game/internal/vec"
 )
 
@@ -36,6 +38,51 @@ type ChunkData struct {
 	LastModified int64                             `json:"last_modified"`
 }
 
+// MarshalJSON кодирует чанк, сохраняя различие между целыми и дробными
+// числами в метаданных блоков
+func (c ChunkData) MarshalJSON() ([]byte, error) {
+	type alias ChunkData
+	var metadata map[string]json.RawMessage
+	if c.Metadata != nil {
+		metadata = make(map[string]json.RawMessage, len(c.Metadata))
+		for key, blockMetadata := range c.Metadata {
+			data, err := protocol.MarshalMetadata(blockMetadata)
+			if err != nil {
+				return nil, err
+			}
+			metadata[key] = data
+		}
+	}
+	return json.Marshal(struct {
+		alias
+		Metadata map[string]json.RawMessage `json:"metadata"`
+	}{alias: alias(c), Metadata: metadata})
+}
+
+// UnmarshalJSON декодирует чанк; целые числа в метаданных остаются int
+func (c *ChunkData) UnmarshalJSON(data []byte) error {
+	type alias ChunkData
+	aux := struct {
+		*alias
+		Metadata map[string]json.RawMessage `json:"metadata"`
+	}{alias: (*alias)(c)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	c.Metadata = nil
+	if aux.Metadata != nil {
+		c.Metadata = make(map[string]map[string]interface{}, len(aux.Metadata))
+		for key, raw := range aux.Metadata {
+			blockMetadata, err := protocol.UnmarshalMetadata(raw)
+			if err != nil {
+				return fmt.Errorf("metadata %s: %w", key, err)
+			}
+			c.Metadata[key] = blockMetadata
+		}
+	}
+	return nil
+}
+
 // NewFileStorageAdapter создаёт новый файловый адаптер хранилища
 func NewFileStorageAdapter(basePath string, autoSave bool) (*FileStorageAdapter, error) {
 	// Создаём директорию если её нет
@@ -299,6 +346,19 @@ func (fsa *FileStorageAdapter) SaveChunk(chunkCoords vec.Vec2, blocks []BlockDat
 	return fsa.saveChunkToFile(chunkCoords, data)
 }
 
+// HasChunk проверяет, сохранён ли чанк (в кеше или на диске)
+func (fsa *FileStorageAdapter) HasChunk(chunkCoords vec.Vec2) bool {
+	fsa.mu.RLock()
+	_, cached := fsa.chunkCache[chunkCoords]
+	fsa.mu.RUnlock()
+	if cached {
+		return true
+	}
+
+	_, err := os.Stat(fsa.getChunkFilename(chunkCoords))
+	return err == nil
+}
+
 // FlushCache принудительно сохраняет все закешированные чанки
 func (fsa *FileStorageAdapter) FlushCache() error {
 	fsa.mu.RLock()
@@ -341,6 +401,86 @@ func (fsa *FileStorageAdapter) GetStorageStats() map[string]interface{} {
 	}
 }
 
+// fileEntities - формат файла сущностей BigChunk
+type fileEntities struct {
+	Coords   vec.Vec2                           `json:"coords"`
+	Entities []storage_interface.EntitySnapshot `json:"entities"`
+}
+
+// SaveEntities сохраняет снимки сущностей BigChunk в отдельный файл
+func (fsa *FileStorageAdapter) SaveEntities(bigChunkCoords vec.Vec2, entities []storage_interface.EntitySnapshot) error {
+	data, err := json.Marshal(fileEntities{Coords: bigChunkCoords, Entities: entities})
+	if err != nil {
+		return fmt.Errorf("ошибка сериализации сущностей %v: %w", bigChunkCoords, err)
+	}
+
+	return fsa.writeFile(fsa.getEntitiesFilename(bigChunkCoords), data)
+}
+
+// LoadEntities загружает снимки сущностей BigChunk (пустой срез, если файла нет)
+func (fsa *FileStorageAdapter) LoadEntities(bigChunkCoords vec.Vec2) ([]storage_interface.EntitySnapshot, error) {
+	filename := fsa.getEntitiesFilename(bigChunkCoords)
+	data, err := os.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return []storage_interface.EntitySnapshot{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения файла сущностей %s: %w", filename, err)
+	}
+
+	var stored fileEntities
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("ошибка десериализации сущностей %v: %w", bigChunkCoords, err)
+	}
+	if stored.Entities == nil {
+		stored.Entities = []storage_interface.EntitySnapshot{}
+	}
+	return stored.Entities, nil
+}
+
+// SaveWorldMeta сохраняет метаданные мира в world_meta.json
+func (fsa *FileStorageAdapter) SaveWorldMeta(meta storage_interface.WorldMeta) error {
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return fmt.Errorf("ошибка сериализации метаданных мира: %w", err)
+	}
+
+	return fsa.writeFile(fsa.getWorldMetaFilename(), data)
+}
+
+// LoadWorldMeta загружает метаданные мира (false, если файла нет)
+func (fsa *FileStorageAdapter) LoadWorldMeta() (storage_interface.WorldMeta, bool, error) {
+	var meta storage_interface.WorldMeta
+	filename := fsa.getWorldMetaFilename()
+	data, err := os.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return meta, false, nil
+	}
+	if err != nil {
+		return meta, false, fmt.Errorf("ошибка чтения файла метаданных мира %s: %w", filename, err)
+	}
+
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return meta, false, fmt.Errorf("ошибка десериализации метаданных мира: %w", err)
+	}
+	return meta, true, nil
+}
+
+// Close сбрасывает закешированные чанки на диск
+func (fsa *FileStorageAdapter) Close() error {
+	return fsa.FlushCache()
+}
+
+// getEntitiesFilename возвращает имя файла сущностей BigChunk
+func (fsa *FileStorageAdapter) getEntitiesFilename(bigChunkCoords vec.Vec2) string {
+	return filepath.Join(fsa.basePath, fmt.Sprintf("entities_%d_%d.json", bigChunkCoords.X, bigChunkCoords.Y))
+}
+
+// getWorldMetaFilename возвращает имя файла метаданных мира
+func (fsa *FileStorageAdapter) getWorldMetaFilename() string {
+	return filepath.Join(fsa.basePath, "world_meta.json")
+}
+
 // getChunkFilename возвращает имя файла для чанка
 func (fsa *FileStorageAdapter) getChunkFilename(chunkCoords vec.Vec2) string {
 	return filepath.Join(fsa.basePath, fmt.Sprintf("chunk_%d_%d.json", chunkCoords.X, chunkCoords.Y))
@@ -348,8 +488,11 @@ func (fsa *FileStorageAdapter) getChunkFilename(chunkCoords vec.Vec2) string {
 
 // saveChunkToFile сохраняет данные чанка в файл
 func (fsa *FileStorageAdapter) saveChunkToFile(chunkCoords vec.Vec2, data []byte) error {
-	filename := fsa.getChunkFilename(chunkCoords)
+	return fsa.writeFile(fsa.getChunkFilename(chunkCoords), data)
+}
 
+// writeFile записывает файл, создавая директорию при необходимости
+func (fsa *FileStorageAdapter) writeFile(filename string, data []byte) error {
 	// Создаём директорию если нужно
 	dir := filepath.Dir(filename)
 	if err := os.MkdirAll(dir, 0755); err != nil {
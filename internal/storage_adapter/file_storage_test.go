@@ -0,0 +1,88 @@
+package storage_adapter
+
+import (
+	"testing"
+
+	"github.com/annel0/mmo-game/internal/storage_interface"
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/annel0/mmo-game/internal/world"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// FileStorageAdapter должен подходить как хранилище сущностей для WorldManager
+var _ storage_interface.StorageProvider = (*FileStorageAdapter)(nil)
+
+func TestFileStorageAdapter_EntityRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	fsa, err := NewFileStorageAdapter(dir, false)
+	require.NoError(t, err)
+
+	coords := vec.Vec2{X: 16, Y: -32}
+	entities := []storage_interface.EntitySnapshot{
+		{
+			Version:   storage_interface.EntitySnapshotVersion,
+			ID:        1,
+			Type:      0,
+			Position:  vec.Vec2Float{X: 16.5, Y: -30.25},
+			Direction: 3,
+			Velocity:  vec.Vec2Float{X: 0.5, Y: -1},
+			Health:    100,
+			Payload:   map[string]interface{}{"username": "alice", "inventory": map[string]interface{}{"wood": 12}},
+		},
+		{
+			Version:  storage_interface.EntitySnapshotVersion,
+			ID:       2,
+			Type:     3,
+			Position: vec.Vec2Float{X: 20, Y: -20},
+			Health:   35,
+			Payload:  map[string]interface{}{"hunger": 4, "actionTimer": 1.0, "tame": false},
+		},
+		{
+			Version:  storage_interface.EntitySnapshotVersion,
+			ID:       3,
+			Type:     1,
+			Position: vec.Vec2Float{X: 17, Y: -31},
+		},
+	}
+
+	require.NoError(t, fsa.SaveEntities(coords, entities))
+
+	// Новый адаптер читает файл с диска, без кеша
+	reopened, err := NewFileStorageAdapter(dir, false)
+	require.NoError(t, err)
+	loaded, err := reopened.LoadEntities(coords)
+	require.NoError(t, err)
+	assert.Equal(t, entities, loaded)
+
+	// Для BigChunk без сохранённых сущностей возвращается пустой список
+	empty, err := reopened.LoadEntities(vec.Vec2{X: 0, Y: 0})
+	require.NoError(t, err)
+	assert.Empty(t, empty)
+	require.NoError(t, reopened.Close())
+}
+
+func TestFileStorageAdapter_WorldSpawnSurvivesReload(t *testing.T) {
+	dir := t.TempDir()
+	fsa, err := NewFileStorageAdapter(dir, false)
+	require.NoError(t, err)
+
+	wm := world.NewWorldManager(99)
+	defer wm.Stop()
+	require.NoError(t, wm.InitStorageAdapter(fsa))
+	spawn := vec.Vec2{X: 12, Y: -3}
+	require.NoError(t, wm.SetWorldSpawn(spawn))
+	require.NoError(t, fsa.Close())
+
+	reopened, err := NewFileStorageAdapter(dir, false)
+	require.NoError(t, err)
+	meta, found, err := reopened.LoadWorldMeta()
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.Equal(t, int64(99), meta.Seed)
+
+	restored := world.NewWorldManager(99)
+	defer restored.Stop()
+	require.NoError(t, restored.InitStorageAdapter(reopened))
+	assert.Equal(t, spawn, restored.GetWorldSpawn())
+}
@@ -0,0 +1,97 @@
+package storage_interface
+
+import (
+	"encoding/json"
+
+	"github.com/annel0/mmo-game/internal/protocol"
+	"github.com/annel0/mmo-game/internal/vec"
+)
+
+// EntitySnapshotVersion - текущая версия формата EntitySnapshot.
+//
+// Версия 0 - исходный формат без поля version (id, type, целочисленная
+// position, payload). Версия 1 добавляет точную позицию, направление,
+// скорость и здоровье.
+const EntitySnapshotVersion = 1
+
+// EntitySnapshot - сохраняемое состояние сущности.
+//
+// Совместимость обеспечивается полем Version: записи версии 0 читаются с
+// точной позицией, восстановленной из целочисленной. Записи более новых
+// версий читаются по известным полям, неизвестные поля пропускаются, а
+// Version сохраняет исходное значение
+type EntitySnapshot struct {
+	Version   int                    // Версия формата, в которой запись была прочитана/создана
+	ID        uint64                 // Уникальный ID сущности
+	Type      uint16                 // Тип сущности
+	Position  vec.Vec2Float          // Точная позиция в мире
+	Direction int                    // Направление взгляда
+	Velocity  vec.Vec2Float          // Текущая скорость
+	Health    int                    // Здоровье
+	Payload   map[string]interface{} // Метаданные сущности
+}
+
+// entitySnapshotJSON - представление EntitySnapshot в JSON
+type entitySnapshotJSON struct {
+	Version   int             `json:"version"`
+	ID        uint64          `json:"id"`
+	Type      uint16          `json:"type"`
+	Position  vec.Vec2        `json:"position"` // Целочисленная позиция, понятная читателям версии 0
+	Precise   *vec.Vec2Float  `json:"precise_position,omitempty"`
+	Direction int             `json:"direction,omitempty"`
+	Velocity  vec.Vec2Float   `json:"velocity"`
+	Health    int             `json:"health,omitempty"`
+	Payload   json.RawMessage `json:"payload"`
+}
+
+// BlockPosition возвращает позицию сущности в координатах блоков
+func (s EntitySnapshot) BlockPosition() vec.Vec2 {
+	return s.Position.ToVec2()
+}
+
+// MarshalJSON кодирует снимок в текущей версии формата
+func (s EntitySnapshot) MarshalJSON() ([]byte, error) {
+	payload, err := protocol.MarshalMetadata(s.Payload)
+	if err != nil {
+		return nil, err
+	}
+	precise := s.Position
+	return json.Marshal(entitySnapshotJSON{
+		Version:   EntitySnapshotVersion,
+		ID:        s.ID,
+		Type:      s.Type,
+		Position:  s.BlockPosition(),
+		Precise:   &precise,
+		Direction: s.Direction,
+		Velocity:  s.Velocity,
+		Health:    s.Health,
+		Payload:   payload,
+	})
+}
+
+// UnmarshalJSON декодирует снимок любой версии формата
+func (s *EntitySnapshot) UnmarshalJSON(data []byte) error {
+	var raw entitySnapshotJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	payload, err := protocol.UnmarshalMetadata(raw.Payload)
+	if err != nil {
+		return err
+	}
+
+	*s = EntitySnapshot{
+		Version:   raw.Version,
+		ID:        raw.ID,
+		Type:      raw.Type,
+		Position:  vec.FromVec2(raw.Position),
+		Direction: raw.Direction,
+		Velocity:  raw.Velocity,
+		Health:    raw.Health,
+		Payload:   payload,
+	}
+	if raw.Precise != nil {
+		s.Position = *raw.Precise
+	}
+	return nil
+}
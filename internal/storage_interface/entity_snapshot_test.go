@@ -0,0 +1,62 @@
+package storage_interface
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEntitySnapshot_RoundTrip(t *testing.T) {
+	original := EntitySnapshot{
+		Version:   EntitySnapshotVersion,
+		ID:        42,
+		Type:      3,
+		Position:  vec.Vec2Float{X: 10.25, Y: -3.5},
+		Direction: 2,
+		Velocity:  vec.Vec2Float{X: 1.5, Y: 0},
+		Health:    80,
+		Payload:   map[string]interface{}{"hunger": 5, "speed": 2.0, "name": "cow", "tame": true},
+	}
+
+	data, err := json.Marshal(original)
+	require.NoError(t, err)
+
+	var restored EntitySnapshot
+	require.NoError(t, json.Unmarshal(data, &restored))
+	assert.Equal(t, original, restored)
+	assert.Equal(t, vec.Vec2{X: 10, Y: -3}, restored.BlockPosition())
+}
+
+func TestEntitySnapshot_ReadsVersion0(t *testing.T) {
+	// Формат до введения EntitySnapshot: без версии и точной позиции
+	legacy := `{"id": 7, "type": 1, "position": {"X": 4, "Y": 9}, "payload": {"health": 100}}`
+
+	var snapshot EntitySnapshot
+	require.NoError(t, json.Unmarshal([]byte(legacy), &snapshot))
+	assert.Equal(t, 0, snapshot.Version)
+	assert.Equal(t, uint64(7), snapshot.ID)
+	assert.Equal(t, vec.Vec2Float{X: 4, Y: 9}, snapshot.Position)
+	assert.Equal(t, map[string]interface{}{"health": 100}, snapshot.Payload)
+}
+
+func TestEntitySnapshot_ReadsNewerVersion(t *testing.T) {
+	// Запись будущей версии с неизвестными полями читается по известным полям
+	newer := `{"version": 5, "id": 9, "type": 2, "position": {"X": 1, "Y": 1},
+		"precise_position": {"X": 1.75, "Y": 1.25}, "health": 12, "mana": 30,
+		"payload": null}`
+
+	var snapshot EntitySnapshot
+	require.NoError(t, json.Unmarshal([]byte(newer), &snapshot))
+	assert.Equal(t, 5, snapshot.Version)
+	assert.Equal(t, vec.Vec2Float{X: 1.75, Y: 1.25}, snapshot.Position)
+	assert.Equal(t, 12, snapshot.Health)
+	assert.Nil(t, snapshot.Payload)
+
+	// Повторное сохранение записывает текущую версию
+	data, err := json.Marshal(snapshot)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"version":1`)
+}
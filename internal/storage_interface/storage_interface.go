@@ -6,29 +6,49 @@ import (
 
 // StorageProvider определяет интерфейс для взаимодействия с хранилищем
 type StorageProvider interface {
-	// SaveEntities сохраняет данные о сущностях из BigChunk
-	SaveEntities(bigChunkCoords vec.Vec2, entities map[uint64]interface{}) error
+	// SaveEntities сохраняет снимки сущностей BigChunk
+	SaveEntities(bigChunkCoords vec.Vec2, entities []EntitySnapshot) error
 
-	// LoadEntities загружает данные о сущностях для BigChunk
-	LoadEntities(bigChunkCoords vec.Vec2) (*EntitiesData, error)
-
-	// ApplyEntitiesToBigChunk применяет загруженные данные о сущностей к BigChunk
-	ApplyEntitiesToBigChunk(entities map[uint64]interface{}, data *EntitiesData)
+	// LoadEntities загружает снимки сущностей BigChunk (пустой срез, если их нет)
+	LoadEntities(bigChunkCoords vec.Vec2) ([]EntitySnapshot, error)
 
 	// Close закрывает хранилище
 	Close() error
 }
 
-// EntitiesData содержит информацию о сущностях, загруженных из хранилища
-type EntitiesData struct {
-	Coords   vec.Vec2
-	Entities map[uint64]EntityStorageData
+// WorldMeta - сохраняемые параметры мира
+type WorldMeta struct {
+	Seed   int64     `json:"seed"`             // Сид, с которым мир был создан
+	Spawn  *vec.Vec2 `json:"spawn,omitempty"`  // Точка спавна (nil - выводится из сида)
+	Claims []Claim   `json:"claims,omitempty"` // Приваты
+
+	Border *WorldBorder `json:"border,omitempty"` // Граница мира (nil - мир бесконечен)
+}
+
+// WorldBorder - граница мира: квадрат блоков с центром Center, в котором
+// игроки перемещаются и строят
+type WorldBorder struct {
+	Center  vec.Vec2 `json:"center"`
+	Radius  int      `json:"radius"`            // Блоков от центра до края включительно
+	Warning int      `json:"warning,omitempty"` // Ширина зоны предупреждения у края, блоков
 }
 
-// EntityStorageData содержит данные о сущности для хранения
-type EntityStorageData struct {
-	ID       uint64                 // Уникальный ID сущности
-	Type     uint16                 // Тип сущности
-	Position vec.Vec2               // Позиция в мире
-	Payload  map[string]interface{} // Метаданные сущности
+// Claim - приват: прямоугольник чанков, в котором изменять блоки могут
+// только владелец и администраторы
+type Claim struct {
+	ID      uint64   `json:"id"`
+	OwnerID uint64   `json:"owner_id"`       // Владелец (0 - только администраторы, например защита спавна)
+	Name    string   `json:"name,omitempty"` // Название для сообщений игрокам
+	From    vec.Vec2 `json:"from"`           // Угол в координатах чанков (включительно)
+	To      vec.Vec2 `json:"to"`             // Противоположный угол (включительно)
+}
+
+// WorldMetaStore - хранилище метаданных мира. Необязательное расширение
+// StorageProvider: WorldManager использует его, если провайдер его реализует
+type WorldMetaStore interface {
+	// SaveWorldMeta сохраняет метаданные мира
+	SaveWorldMeta(meta WorldMeta) error
+
+	// LoadWorldMeta загружает метаданные мира (false, если мир ещё не сохранялся)
+	LoadWorldMeta() (WorldMeta, bool, error)
 }
@@ -7,12 +7,15 @@ import (
 
 	"github.com/annel0/mmo-game/internal/eventbus"
 	"github.com/annel0/mmo-game/internal/logging"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 // Change содержит сериализованное изменение состояния (protobuf/json/avro).
 // Тип определяется полем EventType в Envelope, поэтому здесь просто []byte.
 
 type Change struct {
+	ID           string    // Уникальный ID: получатель отсеивает повторные доставки
 	Data         []byte    // Сериализованные данные изменения
 	Priority     int       // приоритизация для сброса при перегрузке
 	Timestamp    time.Time // Время создания изменения
@@ -20,6 +23,36 @@ type Change struct {
 	ChangeType   string    // Тип изменения: "BlockEvent", "EntityEvent"
 }
 
+// Политика отправки: пакет уходит, как только наберётся capacity изменений
+// или пройдёт flushEvery с момента постановки первого изменения в очередь -
+// что наступит раньше. После отправки отсчёт времени начинается заново.
+const (
+	DefaultBatchSize  = 100
+	DefaultFlushEvery = 3 * time.Second
+
+	// maxPendingBatches - сколько полных пакетов может ждать отправки, пока
+	// EventBus занят; сверх этого отбрасываются низкоприоритетные изменения
+	maxPendingBatches = 4
+)
+
+// Причины отправки пакета (метка reason)
+const (
+	flushReasonSize = "size"
+	flushReasonTime = "time"
+	flushReasonStop = "stop"
+)
+
+// batchFlushes считает отправленные пакеты по причине отправки
+var batchFlushes = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "sync",
+	Name:      "batch_flushes_total",
+	Help:      "Пакеты изменений, отправленные BatchManager, по причине: size, time, stop.",
+}, []string{"reason"})
+
+func init() {
+	prometheus.MustRegister(batchFlushes)
+}
+
 // BatchManager накапливает изменения и отправляет их пакетами через EventBus.
 // Каждый региональный узел имеет собственный экземпляр.
 
@@ -27,38 +60,61 @@ type BatchManager struct {
 	mu       sync.Mutex
 	buf      []Change
 	capacity int
+	firstAt  time.Time // Когда в пустой буфер попало первое изменение
 
 	flushEvery time.Duration
 	bus        eventbus.EventBus
 	source     string // имя текущего узла/region-id
 	compressor DeltaCompressor
 
+	wake chan struct{} // Сигнал циклу отправки: буфер заполнен или начат новый пакет
 	quit chan struct{}
+	done chan struct{}
 }
 
-// NewBatchManager создаёт менеджер с указанным лимитом буфера и интервалом отправки.
+// NewBatchManager создаёт менеджер с указанным размером пакета и максимальной
+// задержкой отправки (нулевые значения заменяются значениями по умолчанию).
 func NewBatchManager(bus eventbus.EventBus, source string, capacity int, flushEvery time.Duration, compressor DeltaCompressor) *BatchManager {
 	if compressor == nil {
 		compressor = NewPassthroughCompressor()
 	}
+	if capacity <= 0 {
+		capacity = DefaultBatchSize
+	}
+	if flushEvery <= 0 {
+		flushEvery = DefaultFlushEvery
+	}
 	bm := &BatchManager{
 		capacity:   capacity,
 		flushEvery: flushEvery,
 		bus:        bus,
 		source:     source,
 		compressor: compressor,
+		wake:       make(chan struct{}, 1),
 		quit:       make(chan struct{}),
+		done:       make(chan struct{}),
 	}
 	go bm.loop()
 	return bm
 }
 
-// AddChange добавляет изменение в буфер; при переполнении низкоприоритетные изменения отбрасываются.
+// Namespace возвращает пространство имён шины, в которую публикуются пакеты
+func (bm *BatchManager) Namespace() string {
+	return eventbus.NamespaceOf(bm.bus)
+}
+
+// AddChange добавляет изменение в буфер. Заполненный пакет отправляется сразу;
+// если EventBus не успевает, низкоприоритетные изменения отбрасываются.
+// Изменению без ID присваивается новый уникальный ID.
 func (bm *BatchManager) AddChange(ch Change) {
+	if ch.ID == "" {
+		ch.ID = uuid.NewString()
+	}
+
 	bm.mu.Lock()
 	defer bm.mu.Unlock()
 
-	if len(bm.buf) >= bm.capacity {
+	if len(bm.buf) >= bm.capacity*maxPendingBatches {
 		// ищем самое низкое Priority и заменяем, если новый выше.
 		lowIdx := -1
 		lowPri := ch.Priority
@@ -70,42 +126,99 @@ func (bm *BatchManager) AddChange(ch Change) {
 		}
 		if lowIdx >= 0 {
 			bm.buf[lowIdx] = ch
-		} else {
-			// все изменения >= чем новый — дропаём новый
-			return
 		}
-	} else {
-		bm.buf = append(bm.buf, ch)
+		// иначе все изменения >= чем новый — дропаём новый
+		return
+	}
+
+	bm.buf = append(bm.buf, ch)
+	if len(bm.buf) == 1 {
+		bm.firstAt = time.Now()
+		bm.signal()
+	} else if len(bm.buf) == bm.capacity {
+		bm.signal()
+	}
+}
+
+// signal будит цикл отправки, не блокируясь
+func (bm *BatchManager) signal() {
+	select {
+	case bm.wake <- struct{}{}:
+	default:
 	}
 }
 
+// loop отправляет пакеты по заполнению или по истечении flushEvery
 func (bm *BatchManager) loop() {
-	ticker := time.NewTicker(bm.flushEvery)
-	defer ticker.Stop()
+	defer close(bm.done)
+
+	timer := time.NewTimer(bm.flushEvery)
+	if !timer.Stop() {
+		<-timer.C
+	}
+	defer timer.Stop()
 
 	for {
 		select {
-		case <-ticker.C:
-			bm.flush()
+		case <-bm.wake:
+		case <-timer.C:
+			bm.flush(flushReasonTime)
 		case <-bm.quit:
 			return
 		}
+
+		// Полные пакеты уходят сразу, остаток ждёт своего срока
+		for bm.pending() >= bm.capacity {
+			bm.flush(flushReasonSize)
+		}
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		if wait, ok := bm.untilDeadline(); ok {
+			timer.Reset(wait)
+		}
+	}
+}
+
+// pending возвращает число изменений в буфере
+func (bm *BatchManager) pending() int {
+	bm.mu.Lock()
+	defer bm.mu.Unlock()
+	return len(bm.buf)
+}
+
+// untilDeadline возвращает время до отправки неполного пакета; ok=false для пустого буфера
+func (bm *BatchManager) untilDeadline() (time.Duration, bool) {
+	bm.mu.Lock()
+	defer bm.mu.Unlock()
+	if len(bm.buf) == 0 {
+		return 0, false
 	}
+	return max(bm.flushEvery-time.Since(bm.firstAt), 0), true
 }
 
-// flush отсылает накопленные изменения единым сообщением.
-func (bm *BatchManager) flush() {
+// flush отсылает до capacity накопленных изменений единым сообщением.
+func (bm *BatchManager) flush(reason string) {
 	bm.mu.Lock()
 	if len(bm.buf) == 0 {
 		bm.mu.Unlock()
 		return
 	}
 	// компрессия через DeltaCompressor
-	changes := make([]Change, len(bm.buf))
+	n := min(len(bm.buf), bm.capacity)
+	changes := make([]Change, n)
 	copy(changes, bm.buf)
-	bm.buf = bm.buf[:0]
+	bm.buf = append(bm.buf[:0], bm.buf[n:]...)
+	// Отсчёт для оставшихся изменений начинается заново
+	bm.firstAt = time.Now()
 	bm.mu.Unlock()
 
+	batchFlushes.WithLabelValues(reason).Inc()
+
 	batchPayload, err := bm.compressor.Compress(changes)
 	if err != nil {
 		logging.Warn("BatchManager compress error: %v", err)
@@ -117,7 +230,7 @@ func (bm *BatchManager) flush() {
 		Timestamp: time.Now().UTC(),
 		Source:    bm.source,
 		EventType: "SyncBatch",
-		Version:   1,
+		Version:   SyncBatchFormatVersion,
 		Priority:  5,
 		Payload:   batchPayload,
 	}
@@ -131,5 +244,8 @@ func (bm *BatchManager) flush() {
 // Stop завершает работу менеджера и отправляет оставшиеся изменения.
 func (bm *BatchManager) Stop() {
 	close(bm.quit)
-	bm.flush()
+	<-bm.done
+	for bm.pending() > 0 {
+		bm.flush(flushReasonStop)
+	}
 }
@@ -0,0 +1,115 @@
+package sync
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/eventbus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingBus запоминает опубликованные пакеты
+type recordingBus struct {
+	mu        sync.Mutex
+	envelopes []*eventbus.Envelope
+	published chan struct{}
+}
+
+func newRecordingBus() *recordingBus {
+	return &recordingBus{published: make(chan struct{}, 64)}
+}
+
+func (b *recordingBus) Publish(_ context.Context, ev *eventbus.Envelope) error {
+	b.mu.Lock()
+	b.envelopes = append(b.envelopes, ev)
+	b.mu.Unlock()
+	b.published <- struct{}{}
+	return nil
+}
+
+func (b *recordingBus) Subscribe(context.Context, eventbus.Filter, eventbus.Handler) (eventbus.Subscription, error) {
+	return nil, nil
+}
+
+func (b *recordingBus) Metrics() eventbus.Stats { return eventbus.Stats{} }
+
+// batchSizes возвращает размеры опубликованных пакетов
+func (b *recordingBus) batchSizes(t *testing.T) []int {
+	t.Helper()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	sizes := make([]int, 0, len(b.envelopes))
+	for _, env := range b.envelopes {
+		changes, err := NewPassthroughCompressor().Decompress(env.Payload)
+		require.NoError(t, err)
+		sizes = append(sizes, len(changes))
+	}
+	return sizes
+}
+
+// waitPublished ждёт публикации пакета не дольше timeout
+func (b *recordingBus) waitPublished(timeout time.Duration) bool {
+	select {
+	case <-b.published:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+func TestBatchManager_FullBatchFlushesImmediately(t *testing.T) {
+	bus := newRecordingBus()
+	bm := NewBatchManager(bus, "region-a", 3, time.Hour, nil)
+	defer bm.Stop()
+	bySize := testutil.ToFloat64(batchFlushes.WithLabelValues(flushReasonSize))
+
+	for i := 0; i < 7; i++ {
+		bm.AddChange(Change{Data: []byte{byte(i)}, ChangeType: "BlockEvent"})
+	}
+
+	require.True(t, bus.waitPublished(time.Second), "полный пакет должен уйти без ожидания таймера")
+	require.True(t, bus.waitPublished(time.Second))
+	assert.False(t, bus.waitPublished(50*time.Millisecond), "неполный остаток ждёт таймера")
+	assert.Equal(t, []int{3, 3}, bus.batchSizes(t))
+	assert.Equal(t, bySize+2, testutil.ToFloat64(batchFlushes.WithLabelValues(flushReasonSize)))
+}
+
+func TestBatchManager_PartialBatchFlushesOnTimer(t *testing.T) {
+	bus := newRecordingBus()
+	flushEvery := 80 * time.Millisecond
+	bm := NewBatchManager(bus, "region-a", 100, flushEvery, nil)
+	defer bm.Stop()
+	byTime := testutil.ToFloat64(batchFlushes.WithLabelValues(flushReasonTime))
+
+	start := time.Now()
+	bm.AddChange(Change{Data: []byte("a")})
+	time.Sleep(flushEvery / 2)
+	bm.AddChange(Change{Data: []byte("b")})
+
+	require.True(t, bus.waitPublished(time.Second))
+	elapsed := time.Since(start)
+	assert.GreaterOrEqual(t, elapsed, flushEvery, "неполный пакет ждёт flushEvery")
+	assert.Less(t, elapsed, 3*flushEvery/2, "срок отсчитывается от первого изменения, а не от последнего")
+	assert.Equal(t, []int{2}, bus.batchSizes(t))
+	assert.Equal(t, byTime+1, testutil.ToFloat64(batchFlushes.WithLabelValues(flushReasonTime)))
+
+	// После отправки отсчёт начинается заново
+	bm.AddChange(Change{Data: []byte("c")})
+	assert.False(t, bus.waitPublished(flushEvery/2))
+	require.True(t, bus.waitPublished(time.Second))
+	assert.Equal(t, []int{2, 1}, bus.batchSizes(t))
+}
+
+func TestBatchManager_StopFlushesRemainder(t *testing.T) {
+	bus := newRecordingBus()
+	bm := NewBatchManager(bus, "region-a", 10, time.Hour, nil)
+
+	bm.AddChange(Change{Data: []byte("a")})
+	bm.Stop()
+	assert.Equal(t, []int{1}, bus.batchSizes(t))
+}
@@ -3,9 +3,26 @@ package sync
 import (
 	"bytes"
 	"compress/gzip"
+	"fmt"
 	"io"
 )
 
+// maxChangeIDLen - предел длины Change.ID в кадре (длина кодируется одним байтом)
+const maxChangeIDLen = 255
+
+// Формат SyncBatch. Версия 1: [len(data) uint32] [data] ... без заголовка.
+// Версия 2 (с ID изменений) начинается с заголовка [syncFormatFlag] [версия].
+// Старший байт длины первого изменения версии 1 равен 0 для изменений меньше
+// 16 МБ, поэтому флаг однозначно отличает версии, и узлы новой версии читают
+// пакеты узлов старой при поэтапном обновлении кластера
+const (
+	syncFormatFlag = 0xFF
+
+	// SyncBatchFormatVersion - версия формата, в которой пишутся пакеты;
+	// передаётся и в Envelope.Version события SyncBatch
+	SyncBatchFormatVersion = 2
+)
+
 // DeltaCompressor кодирует/декодирует изменения (Change) в компактный вид.
 // На первом этапе используем passthrough-компрессию — просто возвращаем вход.
 // Позже планируется алгоритм XOR + GZip/VarInt для блоков/энтити.
@@ -20,9 +37,17 @@ type passthroughCompressor struct{}
 func NewPassthroughCompressor() DeltaCompressor { return &passthroughCompressor{} }
 
 func (p *passthroughCompressor) Compress(changes []Change) ([]byte, error) {
-	// очень простой формат: [len(uint32)] [data] ...
+	// очень простой формат: [флаг] [версия] [len(id) uint8] [id] [len(data) uint32] [data] ...
 	buf := make([]byte, 0)
+	if len(changes) > 0 {
+		buf = append(buf, syncFormatFlag, SyncBatchFormatVersion)
+	}
 	for _, c := range changes {
+		if len(c.ID) > maxChangeIDLen {
+			return nil, fmt.Errorf("change id too long: %d bytes", len(c.ID))
+		}
+		buf = append(buf, byte(len(c.ID)))
+		buf = append(buf, c.ID...)
 		n := uint32(len(c.Data))
 		buf = append(buf, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
 		buf = append(buf, c.Data...)
@@ -31,23 +56,54 @@ func (p *passthroughCompressor) Compress(changes []Change) ([]byte, error) {
 }
 
 func (p *passthroughCompressor) Decompress(payload []byte) ([]Change, error) {
+	if len(payload) == 0 || payload[0] != syncFormatFlag {
+		return decodeChangesV1(payload), nil
+	}
+	if len(payload) < 2 {
+		return nil, fmt.Errorf("truncated sync batch header")
+	}
+	if payload[1] != SyncBatchFormatVersion {
+		return nil, fmt.Errorf("unsupported sync batch format version %d", payload[1])
+	}
+	payload = payload[2:]
+
 	var res []Change
 	i := 0
 	for i < len(payload) {
-		if i+4 > len(payload) {
+		idLen := int(payload[i])
+		i++
+		if i+idLen+4 > len(payload) {
 			break // corrupt, игнорируем хвост
 		}
+		id := string(payload[i : i+idLen])
+		i += idLen
 		n := uint32(payload[i])<<24 | uint32(payload[i+1])<<16 | uint32(payload[i+2])<<8 | uint32(payload[i+3])
 		i += 4
 		if i+int(n) > len(payload) {
 			break
 		}
-		res = append(res, Change{Data: payload[i : i+int(n)]})
+		res = append(res, Change{ID: id, Data: payload[i : i+int(n)]})
 		i += int(n)
 	}
 	return res, nil
 }
 
+// decodeChangesV1 читает пакет версии 1: изменения без ID
+func decodeChangesV1(payload []byte) []Change {
+	var res []Change
+	i := 0
+	for i+4 <= len(payload) {
+		n := uint32(payload[i])<<24 | uint32(payload[i+1])<<16 | uint32(payload[i+2])<<8 | uint32(payload[i+3])
+		i += 4
+		if i+int(n) > len(payload) {
+			break // corrupt, игнорируем хвост
+		}
+		res = append(res, Change{Data: payload[i : i+int(n)]})
+		i += int(n)
+	}
+	return res
+}
+
 // smartCompressor применяет gzip к serialized changes для лучшего сжатия
 type smartCompressor struct{}
 
@@ -0,0 +1,41 @@
+package sync
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPassthroughCompressor_RoundTripWithIDs(t *testing.T) {
+	c := NewPassthroughCompressor()
+	changes := []Change{{ID: "a", Data: []byte("first")}, {ID: "", Data: []byte{}}, {ID: "c", Data: []byte{0xFF, 0x02}}}
+
+	payload, err := c.Compress(changes)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{syncFormatFlag, SyncBatchFormatVersion}, payload[:2], "пакет помечен версией формата")
+
+	decoded, err := c.Decompress(payload)
+	require.NoError(t, err)
+	require.Len(t, decoded, 3)
+	for i := range changes {
+		assert.Equal(t, changes[i].ID, decoded[i].ID)
+		assert.Equal(t, changes[i].Data, decoded[i].Data)
+	}
+}
+
+func TestPassthroughCompressor_ReadsVersion1Batches(t *testing.T) {
+	// Пакет узла прежней версии: [len uint32] [data] без заголовка и ID
+	legacy := []byte{0, 0, 0, 2, 'h', 'i', 0, 0, 0, 1, '!'}
+
+	decoded, err := NewPassthroughCompressor().Decompress(legacy)
+	require.NoError(t, err)
+	require.Len(t, decoded, 2)
+	assert.Equal(t, []byte("hi"), decoded[0].Data)
+	assert.Empty(t, decoded[0].ID)
+	assert.Equal(t, []byte("!"), decoded[1].Data)
+
+	// Неизвестная версия формата отклоняется, а не читается как мусор
+	_, err = NewPassthroughCompressor().Decompress([]byte{syncFormatFlag, SyncBatchFormatVersion + 1, 0})
+	assert.ErrorContains(t, err, "unsupported")
+}
@@ -22,9 +22,17 @@ type SyncConfig struct {
 	BatchSize    int
 	FlushEvery   time.Duration
 	UseGzipCompr bool
+	// Пространство имён окружения: регион обменивается пакетами только с
+	// регионами того же пространства (см. eventbus.WithNamespace)
+	Namespace string
 }
 
 func NewSyncManager(cfg SyncConfig) (*SyncManager, error) {
+	bus, err := eventbus.WithNamespace(cfg.Bus, cfg.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
 	var compressor DeltaCompressor
 	if cfg.UseGzipCompr {
 		compressor = NewSmartCompressor()
@@ -34,20 +42,20 @@ func NewSyncManager(cfg SyncConfig) (*SyncManager, error) {
 		logging.Info("🔄 SyncManager: компрессия отключена")
 	}
 
-	bm := NewBatchManager(cfg.Bus, cfg.RegionID, cfg.BatchSize, cfg.FlushEvery, compressor)
-	producer, err := NewSyncProducer(cfg.Bus, bm)
+	bm := NewBatchManager(bus, cfg.RegionID, cfg.BatchSize, cfg.FlushEvery, compressor)
+	producer, err := NewSyncProducer(bus, bm)
 	if err != nil {
 		return nil, err
 	}
 
-	consumer, err := NewSyncConsumer(cfg.Bus, compressor)
+	consumer, err := NewSyncConsumer(bus, compressor)
 	if err != nil {
 		producer.Stop()
 		return nil, err
 	}
 
-	logging.Info("✅ SyncManager инициализирован: region=%s, batch=%d, flush=%v",
-		cfg.RegionID, cfg.BatchSize, cfg.FlushEvery)
+	logging.Info("✅ SyncManager инициализирован: region=%s, namespace=%q, batch=%d, flush=%v",
+		cfg.RegionID, cfg.Namespace, cfg.BatchSize, cfg.FlushEvery)
 
 	return &SyncManager{
 		bm:       bm,
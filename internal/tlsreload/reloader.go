@@ -0,0 +1,120 @@
+// Package tlsreload загружает сертификаты TLS для REST API и gRPC и
+// перечитывает их без перезапуска сервера (например, по SIGHUP после
+// продления сертификата)
+package tlsreload
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync/atomic"
+)
+
+// ErrCertificateRequired - TLS обязателен, но сертификат не задан
+var ErrCertificateRequired = errors.New("tls: сертификат обязателен (required), но cert_file не задан")
+
+// Config - файлы сертификатов сервера (PEM)
+type Config struct {
+	CertFile string // Пусто - сервер работает открытым текстом
+	KeyFile  string
+
+	// CA клиентских сертификатов для взаимной аутентификации (mTLS).
+	// Пусто - сертификаты клиентов не запрашиваются
+	ClientCAFile string
+
+	// Required запрещает запуск без сертификата (production). Иначе без
+	// cert_file сервер работает открытым текстом - только для локальной отладки
+	Required bool
+}
+
+// material - загруженные из файлов сертификат и CA клиентов
+type material struct {
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool // nil - без mTLS
+}
+
+// Reloader хранит текущий сертификат сервера. Соединения, установленные до
+// Reload, продолжают работать со старым сертификатом, новые получают новый
+type Reloader struct {
+	config  Config
+	current atomic.Pointer[material]
+}
+
+// New загружает сертификаты. Без CertFile возвращает nil (сервер работает
+// открытым текстом), а при Required - ErrCertificateRequired
+func New(config Config) (*Reloader, error) {
+	if config.CertFile == "" {
+		if config.Required {
+			return nil, ErrCertificateRequired
+		}
+		return nil, nil
+	}
+
+	r := &Reloader{config: config}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload перечитывает файлы сертификатов. При ошибке продолжает
+// использоваться прежний сертификат
+func (r *Reloader) Reload() error {
+	cert, err := tls.LoadX509KeyPair(r.config.CertFile, r.config.KeyFile)
+	if err != nil {
+		return fmt.Errorf("tls: загрузка сертификата %s: %w", r.config.CertFile, err)
+	}
+
+	next := &material{cert: &cert}
+	if r.config.ClientCAFile != "" {
+		pem, err := os.ReadFile(r.config.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("tls: чтение client_ca_file: %w", err)
+		}
+		next.clientCAs = x509.NewCertPool()
+		if !next.clientCAs.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("tls: в %s нет сертификатов", r.config.ClientCAFile)
+		}
+	}
+
+	r.current.Store(next)
+	return nil
+}
+
+// Certificate возвращает текущий сертификат сервера
+func (r *Reloader) Certificate() *tls.Certificate {
+	return r.current.Load().cert
+}
+
+// MutualTLS сообщает, проверяются ли сертификаты клиентов
+func (r *Reloader) MutualTLS() bool {
+	return r.config.ClientCAFile != ""
+}
+
+// ServerConfig возвращает конфигурацию TLS, которая при каждом рукопожатии
+// берёт текущий сертификат. clientAuth применяется только при заданном
+// ClientCAFile; nextProtos - протоколы ALPN ("h2" для gRPC)
+func (r *Reloader) ServerConfig(clientAuth tls.ClientAuthType, nextProtos ...string) *tls.Config {
+	base := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		NextProtos: nextProtos,
+	}
+
+	config := base.Clone()
+	config.GetCertificate = func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+		return r.Certificate(), nil
+	}
+	config.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		current := r.current.Load()
+		handshake := base.Clone()
+		handshake.Certificates = []tls.Certificate{*current.cert}
+		if current.clientCAs != nil {
+			handshake.ClientCAs = current.clientCAs
+			handshake.ClientAuth = clientAuth
+		}
+		return handshake, nil
+	}
+	return config
+}
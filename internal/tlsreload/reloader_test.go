@@ -0,0 +1,148 @@
+package tlsreload
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeSelfSigned записывает самоподписанный сертификат для 127.0.0.1 и его
+// ключ в dir и возвращает пути файлов и сам сертификат
+func writeSelfSigned(t *testing.T, dir, name string) (certFile, keyFile string, cert *x509.Certificate) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err = x509.ParseCertificate(der)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile = filepath.Join(dir, name+".pem")
+	keyFile = filepath.Join(dir, name+"-key.pem")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return certFile, keyFile, cert
+}
+
+// serve принимает TLS-соединения до закрытия listener
+func serve(t *testing.T, config *tls.Config) string {
+	t.Helper()
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", config)
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			_ = conn.(*tls.Conn).Handshake()
+			conn.Close()
+		}
+	}()
+	return ln.Addr().String()
+}
+
+// servedCertificate возвращает сертификат, предъявленный сервером
+func servedCertificate(t *testing.T, addr string) *x509.Certificate {
+	t.Helper()
+	conn, err := tls.Dial("tcp", addr, &tls.Config{InsecureSkipVerify: true})
+	require.NoError(t, err)
+	defer conn.Close()
+	return conn.ConnectionState().PeerCertificates[0]
+}
+
+func TestNew_PlaintextFallback(t *testing.T) {
+	r, err := New(Config{})
+	require.NoError(t, err)
+	assert.Nil(t, r, "без сертификата сервер работает открытым текстом")
+
+	_, err = New(Config{Required: true})
+	assert.ErrorIs(t, err, ErrCertificateRequired)
+
+	_, err = New(Config{CertFile: "/nonexistent/cert.pem", KeyFile: "/nonexistent/key.pem"})
+	assert.Error(t, err)
+}
+
+func TestReload_ServesNewCertificate(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile, first := writeSelfSigned(t, dir, "server")
+
+	r, err := New(Config{CertFile: certFile, KeyFile: keyFile})
+	require.NoError(t, err)
+	require.NotNil(t, r)
+	addr := serve(t, r.ServerConfig(tls.NoClientCert))
+
+	assert.Equal(t, first.Raw, servedCertificate(t, addr).Raw)
+
+	// Продлённый сертификат записан поверх старого
+	_, _, second := writeSelfSigned(t, dir, "server")
+	require.NoError(t, r.Reload())
+	assert.Equal(t, second.Raw, servedCertificate(t, addr).Raw)
+
+	// Повреждённый файл не заменяет рабочий сертификат
+	require.NoError(t, os.WriteFile(certFile, []byte("broken"), 0o600))
+	assert.Error(t, r.Reload())
+	assert.Equal(t, second.Raw, servedCertificate(t, addr).Raw)
+}
+
+func TestServerConfig_MutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile, serverCert := writeSelfSigned(t, dir, "server")
+	clientCertFile, clientKeyFile, _ := writeSelfSigned(t, dir, "client")
+
+	r, err := New(Config{CertFile: certFile, KeyFile: keyFile, ClientCAFile: clientCertFile})
+	require.NoError(t, err)
+	assert.True(t, r.MutualTLS())
+	addr := serve(t, r.ServerConfig(tls.RequireAndVerifyClientCert))
+
+	roots := x509.NewCertPool()
+	roots.AddCert(serverCert)
+	clientCert, err := tls.LoadX509KeyPair(clientCertFile, clientKeyFile)
+	require.NoError(t, err)
+
+	// Сервер закрывает соединение после рукопожатия: успех - io.EOF при чтении
+	conn, err := tls.Dial("tcp", addr, &tls.Config{RootCAs: roots, Certificates: []tls.Certificate{clientCert}})
+	require.NoError(t, err)
+	_, err = conn.Read(make([]byte, 1))
+	conn.Close()
+	assert.ErrorIs(t, err, io.EOF)
+
+	// Без сертификата клиента рукопожатие отклоняется (в TLS 1.3 - при первом чтении)
+	conn, err = tls.Dial("tcp", addr, &tls.Config{RootCAs: roots})
+	if err == nil {
+		_, err = conn.Read(make([]byte, 1))
+		conn.Close()
+	}
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, io.EOF)
+}
@@ -0,0 +1,66 @@
+package trade
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/eventbus"
+	"github.com/google/uuid"
+)
+
+// EventTypeTrade - тип события сделки в EventBus
+const EventTypeTrade = "TradeEvent"
+
+// EventKind - этап сделки, о котором сообщает событие
+type EventKind string
+
+const (
+	EventOpened    EventKind = "opened"
+	EventOffer     EventKind = "offer"
+	EventWithdraw  EventKind = "withdraw"
+	EventConfirmed EventKind = "confirmed"
+	EventCompleted EventKind = "completed"
+	EventCancelled EventKind = "cancelled"
+)
+
+// Event - запись аудита сделки, публикуемая в EventBus
+type Event struct {
+	Kind     EventKind         `json:"kind"`
+	TradeID  string            `json:"trade_id"`
+	Parties  [2]uint64         `json:"parties"`
+	PlayerID uint64            `json:"player_id"` // Игрок, выполнивший действие
+	ItemID   string            `json:"item_id,omitempty"`
+	Count    int               `json:"count,omitempty"`
+	Offers   [2]map[string]int `json:"offers"` // Содержимое эскроу после действия
+	Reason   string            `json:"reason,omitempty"`
+}
+
+// emit публикует событие сделки. Вызывается под m.mu, поэтому снимок эскроу
+// соответствует моменту действия.
+func (m *Manager) emit(kind EventKind, t *Trade, playerID uint64, itemID string, count int) {
+	snapshot := t.snapshot()
+	payload, err := json.Marshal(Event{
+		Kind:     kind,
+		TradeID:  t.ID,
+		Parties:  t.Parties,
+		PlayerID: playerID,
+		ItemID:   itemID,
+		Count:    count,
+		Offers:   snapshot.Offers,
+		Reason:   t.Reason,
+	})
+	if err != nil {
+		return
+	}
+
+	_ = m.publish(context.Background(), &eventbus.Envelope{
+		ID:        uuid.NewString(),
+		Timestamp: time.Now().UTC(),
+		Source:    "trade_manager",
+		EventType: EventTypeTrade,
+		Version:   1,
+		Priority:  7,
+		Payload:   payload,
+	})
+}
@@ -0,0 +1,250 @@
+package trade
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/annel0/mmo-game/internal/eventbus"
+	"github.com/annel0/mmo-game/internal/logging"
+	"github.com/google/uuid"
+)
+
+// Manager ведёт активные сделки и выполняет обмен атомарно под общей блокировкой
+type Manager struct {
+	mu          sync.Mutex
+	inventories InventoryProvider
+	maxStack    int
+	trades      map[uint64]*Trade // playerID -> активная сделка (обе стороны указывают на одну)
+
+	publish func(ctx context.Context, ev *eventbus.Envelope) error // Публикация событий аудита
+}
+
+// NewManager создаёт менеджер сделок поверх инвентарей игроков
+func NewManager(inventories InventoryProvider) *Manager {
+	return &Manager{
+		inventories: inventories,
+		maxStack:    DefaultMaxStack,
+		trades:      make(map[uint64]*Trade),
+		publish:     eventbus.Publish,
+	}
+}
+
+// SetMaxStack задаёт максимальное количество одного предмета в инвентаре
+func (m *Manager) SetMaxStack(maxStack int) {
+	if maxStack <= 0 {
+		maxStack = DefaultMaxStack
+	}
+	m.mu.Lock()
+	m.maxStack = maxStack
+	m.mu.Unlock()
+}
+
+// Active возвращает активную сделку игрока
+func (m *Manager) Active(playerID uint64) (Trade, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t, exists := m.trades[playerID]
+	if !exists {
+		return Trade{}, false
+	}
+	return t.snapshot(), true
+}
+
+// Open начинает сделку между двумя игроками
+func (m *Manager) Open(initiator, partner uint64) (Trade, error) {
+	if initiator == partner {
+		return Trade{}, ErrSelfTrade
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, busy := m.trades[initiator]; busy {
+		return Trade{}, ErrAlreadyTrading
+	}
+	if _, busy := m.trades[partner]; busy {
+		return Trade{}, fmt.Errorf("партнёр %d: %w", partner, ErrAlreadyTrading)
+	}
+
+	t := &Trade{
+		ID:      uuid.NewString(),
+		Parties: [2]uint64{initiator, partner},
+		Offers:  [2]map[string]int{make(map[string]int), make(map[string]int)},
+	}
+	m.trades[initiator] = t
+	m.trades[partner] = t
+
+	m.emit(EventOpened, t, initiator, "", 0)
+	return t.snapshot(), nil
+}
+
+// Offer переносит предметы из инвентаря игрока в эскроу сделки.
+// Любое изменение условий сбрасывает подтверждения обеих сторон.
+func (m *Manager) Offer(playerID uint64, itemID string, count int) (Trade, error) {
+	if itemID == "" || count <= 0 {
+		return Trade{}, ErrInvalidCount
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t, side, err := m.activeLocked(playerID)
+	if err != nil {
+		return Trade{}, err
+	}
+
+	inv, ok := m.inventories(playerID)
+	if !ok {
+		return Trade{}, ErrNoInventory
+	}
+	if !inv.Remove(itemID, count) {
+		return Trade{}, fmt.Errorf("%s x%d: %w", itemID, count, ErrNotEnoughItems)
+	}
+
+	t.Offers[side][itemID] += count
+	t.Confirmed = [2]bool{}
+
+	m.emit(EventOffer, t, playerID, itemID, count)
+	return t.snapshot(), nil
+}
+
+// Withdraw возвращает выставленные предметы из эскроу в инвентарь владельца
+func (m *Manager) Withdraw(playerID uint64, itemID string, count int) (Trade, error) {
+	if itemID == "" || count <= 0 {
+		return Trade{}, ErrInvalidCount
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t, side, err := m.activeLocked(playerID)
+	if err != nil {
+		return Trade{}, err
+	}
+	if t.Offers[side][itemID] < count {
+		return Trade{}, fmt.Errorf("%s x%d: %w", itemID, count, ErrNotEnoughItems)
+	}
+
+	inv, ok := m.inventories(playerID)
+	if !ok {
+		return Trade{}, ErrNoInventory
+	}
+
+	inv.Add(itemID, count)
+	t.Offers[side][itemID] -= count
+	if t.Offers[side][itemID] == 0 {
+		delete(t.Offers[side], itemID)
+	}
+	t.Confirmed = [2]bool{}
+
+	m.emit(EventWithdraw, t, playerID, itemID, count)
+	return t.snapshot(), nil
+}
+
+// Confirm подтверждает текущие условия. Когда подтвердили обе стороны,
+// предметы из эскроу передаются получателям и сделка завершается.
+// Если обмен невозможен (превышен предел стака), подтверждения сбрасываются,
+// а предметы остаются в эскроу.
+func (m *Manager) Confirm(playerID uint64) (Trade, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t, side, err := m.activeLocked(playerID)
+	if err != nil {
+		return Trade{}, err
+	}
+
+	t.Confirmed[side] = true
+	m.emit(EventConfirmed, t, playerID, "", 0)
+
+	if !t.Confirmed[0] || !t.Confirmed[1] {
+		return t.snapshot(), nil
+	}
+
+	if err := m.swapLocked(t); err != nil {
+		t.Confirmed = [2]bool{}
+		return t.snapshot(), err
+	}
+
+	t.Status = StatusCompleted
+	delete(m.trades, t.Parties[0])
+	delete(m.trades, t.Parties[1])
+
+	m.emit(EventCompleted, t, playerID, "", 0)
+	logging.Info("🤝 Сделка %s завершена: %d <-> %d", t.ID, t.Parties[0], t.Parties[1])
+	return t.snapshot(), nil
+}
+
+// swapLocked проверяет инвентари получателей и передаёт им предметы из эскроу.
+// Проверка выполняется целиком до первого изменения, поэтому обмен либо
+// проходит полностью, либо не меняет ни одного инвентаря.
+func (m *Manager) swapLocked(t *Trade) error {
+	var receivers [2]Inventory
+	for side, playerID := range t.Parties {
+		inv, ok := m.inventories(playerID)
+		if !ok {
+			return fmt.Errorf("игрок %d: %w", playerID, ErrNoInventory)
+		}
+		receivers[side] = inv
+	}
+
+	for side, inv := range receivers {
+		for itemID, count := range t.Offers[1-side] {
+			if inv.Count(itemID)+count > m.maxStack {
+				return fmt.Errorf("игрок %d, %s: %w", t.Parties[side], itemID, ErrStackLimit)
+			}
+		}
+	}
+
+	for side, inv := range receivers {
+		for itemID, count := range t.Offers[1-side] {
+			inv.Add(itemID, count)
+		}
+	}
+	return nil
+}
+
+// Cancel отменяет сделку игрока и возвращает предметы из эскроу владельцам
+func (m *Manager) Cancel(playerID uint64, reason string) (Trade, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t, _, err := m.activeLocked(playerID)
+	if err != nil {
+		return Trade{}, err
+	}
+
+	for side, owner := range t.Parties {
+		if len(t.Offers[side]) == 0 {
+			continue
+		}
+		inv, ok := m.inventories(owner)
+		if !ok {
+			// Инвентарь владельца уже недоступен - фиксируем потерю для аудита
+			logging.Error("❌ Сделка %s: не удалось вернуть предметы игроку %d: %v", t.ID, owner, t.Offers[side])
+			continue
+		}
+		for itemID, count := range t.Offers[side] {
+			inv.Add(itemID, count)
+		}
+	}
+
+	t.Status = StatusCancelled
+	t.Reason = reason
+	delete(m.trades, t.Parties[0])
+	delete(m.trades, t.Parties[1])
+
+	m.emit(EventCancelled, t, playerID, "", 0)
+	return t.snapshot(), nil
+}
+
+// activeLocked возвращает активную сделку игрока и его сторону
+func (m *Manager) activeLocked(playerID uint64) (*Trade, int, error) {
+	t, exists := m.trades[playerID]
+	if !exists {
+		return nil, -1, ErrNoTrade
+	}
+	return t, t.Side(playerID), nil
+}
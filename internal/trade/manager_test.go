@@ -0,0 +1,211 @@
+package trade
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"github.com/annel0/mmo-game/internal/eventbus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testInventory - инвентарь на основе map
+type testInventory map[string]int
+
+func (inv testInventory) Count(itemID string) int { return inv[itemID] }
+
+func (inv testInventory) Add(itemID string, count int) { inv[itemID] += count }
+
+func (inv testInventory) Remove(itemID string, count int) bool {
+	if inv[itemID] < count {
+		return false
+	}
+	inv[itemID] -= count
+	if inv[itemID] == 0 {
+		delete(inv, itemID)
+	}
+	return true
+}
+
+// newTestManager создаёт менеджер с инвентарями игроков и журналом событий
+func newTestManager(inventories map[uint64]testInventory) (*Manager, *[]Event) {
+	m := NewManager(func(playerID uint64) (Inventory, bool) {
+		inv, ok := inventories[playerID]
+		return inv, ok
+	})
+
+	var mu sync.Mutex
+	events := &[]Event{}
+	m.publish = func(ctx context.Context, ev *eventbus.Envelope) error {
+		var evt Event
+		if err := json.Unmarshal(ev.Payload, &evt); err != nil {
+			return err
+		}
+		mu.Lock()
+		*events = append(*events, evt)
+		mu.Unlock()
+		return nil
+	}
+	return m, events
+}
+
+func eventKinds(events []Event) []EventKind {
+	kinds := make([]EventKind, 0, len(events))
+	for _, evt := range events {
+		kinds = append(kinds, evt.Kind)
+	}
+	return kinds
+}
+
+func TestTrade_SuccessfulSwap(t *testing.T) {
+	alice := testInventory{"sword": 1, "apple": 10}
+	bob := testInventory{"gold": 50}
+	m, events := newTestManager(map[uint64]testInventory{1: alice, 2: bob})
+
+	_, err := m.Open(1, 2)
+	require.NoError(t, err)
+
+	_, err = m.Offer(1, "sword", 1)
+	require.NoError(t, err)
+	_, err = m.Offer(2, "gold", 30)
+	require.NoError(t, err)
+
+	// Предметы в эскроу уже изъяты из инвентарей
+	assert.Equal(t, 0, alice.Count("sword"))
+	assert.Equal(t, 20, bob.Count("gold"))
+
+	tr, err := m.Confirm(1)
+	require.NoError(t, err)
+	assert.Equal(t, StatusOpen, tr.Status)
+
+	tr, err = m.Confirm(2)
+	require.NoError(t, err)
+	assert.Equal(t, StatusCompleted, tr.Status)
+
+	assert.Equal(t, testInventory{"apple": 10, "gold": 30}, alice)
+	assert.Equal(t, testInventory{"gold": 20, "sword": 1}, bob)
+
+	_, active := m.Active(1)
+	assert.False(t, active, "завершённая сделка не должна оставаться активной")
+
+	assert.Equal(t, []EventKind{EventOpened, EventOffer, EventOffer, EventConfirmed, EventConfirmed, EventCompleted}, eventKinds(*events))
+	last := (*events)[len(*events)-1]
+	assert.Equal(t, map[string]int{"sword": 1}, last.Offers[0])
+	assert.Equal(t, map[string]int{"gold": 30}, last.Offers[1])
+}
+
+func TestTrade_DisconnectRollback(t *testing.T) {
+	alice := testInventory{"sword": 1}
+	bob := testInventory{"gold": 50}
+	m, events := newTestManager(map[uint64]testInventory{1: alice, 2: bob})
+
+	_, err := m.Open(1, 2)
+	require.NoError(t, err)
+	_, err = m.Offer(1, "sword", 1)
+	require.NoError(t, err)
+	_, err = m.Offer(2, "gold", 50)
+	require.NoError(t, err)
+	_, err = m.Confirm(1)
+	require.NoError(t, err)
+
+	// Боб отключается до подтверждения - всё возвращается владельцам
+	tr, err := m.Cancel(2, ReasonDisconnect)
+	require.NoError(t, err)
+	assert.Equal(t, StatusCancelled, tr.Status)
+	assert.Equal(t, ReasonDisconnect, tr.Reason)
+
+	assert.Equal(t, testInventory{"sword": 1}, alice)
+	assert.Equal(t, testInventory{"gold": 50}, bob)
+
+	_, err = m.Confirm(2)
+	assert.ErrorIs(t, err, ErrNoTrade)
+
+	last := (*events)[len(*events)-1]
+	assert.Equal(t, EventCancelled, last.Kind)
+	assert.Equal(t, ReasonDisconnect, last.Reason)
+	assert.Equal(t, uint64(2), last.PlayerID)
+}
+
+func TestTrade_OfferValidation(t *testing.T) {
+	alice := testInventory{"apple": 3}
+	m, _ := newTestManager(map[uint64]testInventory{1: alice, 2: {}, 3: {}})
+
+	_, err := m.Open(1, 1)
+	assert.ErrorIs(t, err, ErrSelfTrade)
+
+	_, err = m.Offer(1, "apple", 1)
+	assert.ErrorIs(t, err, ErrNoTrade)
+
+	_, err = m.Open(1, 2)
+	require.NoError(t, err)
+	_, err = m.Open(3, 2)
+	assert.ErrorIs(t, err, ErrAlreadyTrading)
+
+	_, err = m.Offer(1, "apple", 4)
+	assert.ErrorIs(t, err, ErrNotEnoughItems)
+	_, err = m.Offer(1, "apple", 0)
+	assert.ErrorIs(t, err, ErrInvalidCount)
+	assert.Equal(t, 3, alice.Count("apple"), "отклонённое предложение не должно менять инвентарь")
+
+	// Нельзя выставить одни и те же предметы дважды
+	_, err = m.Offer(1, "apple", 3)
+	require.NoError(t, err)
+	_, err = m.Offer(1, "apple", 1)
+	assert.ErrorIs(t, err, ErrNotEnoughItems)
+
+	_, err = m.Withdraw(1, "apple", 4)
+	assert.ErrorIs(t, err, ErrNotEnoughItems)
+	tr, err := m.Withdraw(1, "apple", 2)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"apple": 1}, tr.Offers[0])
+	assert.Equal(t, 2, alice.Count("apple"))
+}
+
+func TestTrade_ChangingOfferResetsConfirmation(t *testing.T) {
+	m, _ := newTestManager(map[uint64]testInventory{1: {"apple": 5}, 2: {"gold": 5}})
+
+	_, err := m.Open(1, 2)
+	require.NoError(t, err)
+	_, err = m.Offer(2, "gold", 5)
+	require.NoError(t, err)
+	_, err = m.Confirm(1)
+	require.NoError(t, err)
+
+	// Боб меняет условия после подтверждения Алисы
+	tr, err := m.Withdraw(2, "gold", 4)
+	require.NoError(t, err)
+	assert.Equal(t, [2]bool{false, false}, tr.Confirmed)
+
+	tr, err = m.Confirm(2)
+	require.NoError(t, err)
+	assert.Equal(t, StatusOpen, tr.Status, "сделка не должна завершиться по старому подтверждению")
+}
+
+func TestTrade_StackLimitKeepsEscrow(t *testing.T) {
+	alice := testInventory{"arrow": 60}
+	bob := testInventory{"arrow": 10}
+	m, _ := newTestManager(map[uint64]testInventory{1: alice, 2: bob})
+	m.SetMaxStack(64)
+
+	_, err := m.Open(1, 2)
+	require.NoError(t, err)
+	_, err = m.Offer(2, "arrow", 10)
+	require.NoError(t, err)
+	_, err = m.Confirm(1)
+	require.NoError(t, err)
+
+	tr, err := m.Confirm(2)
+	assert.ErrorIs(t, err, ErrStackLimit)
+	assert.Equal(t, StatusOpen, tr.Status)
+	assert.Equal(t, [2]bool{false, false}, tr.Confirmed)
+
+	// Ни один инвентарь не изменился, предметы остались в эскроу
+	assert.Equal(t, 60, alice.Count("arrow"))
+	assert.Equal(t, 0, bob.Count("arrow"))
+
+	_, err = m.Cancel(1, "")
+	require.NoError(t, err)
+	assert.Equal(t, 10, bob.Count("arrow"))
+}
@@ -0,0 +1,91 @@
+// Package trade реализует обмен предметами между игроками через эскроу.
+//
+// Выставленные в сделку предметы сразу изымаются из инвентаря владельца и
+// хранятся в сделке до её завершения. Поэтому один и тот же предмет нельзя
+// одновременно продать, выбросить или выставить в другую сделку, а при
+// отмене или отключении игрока предметы просто возвращаются владельцу.
+package trade
+
+import "errors"
+
+// DefaultMaxStack - максимальное количество одного предмета в инвентаре по умолчанию
+const DefaultMaxStack = 64
+
+// ReasonDisconnect - причина отмены сделки при отключении участника
+const ReasonDisconnect = "disconnect"
+
+var (
+	ErrSelfTrade      = errors.New("нельзя торговать с самим собой")
+	ErrAlreadyTrading = errors.New("игрок уже участвует в сделке")
+	ErrNoTrade        = errors.New("игрок не участвует в сделке")
+	ErrNoInventory    = errors.New("инвентарь игрока недоступен")
+	ErrInvalidCount   = errors.New("недопустимое количество предметов")
+	ErrNotEnoughItems = errors.New("недостаточно предметов")
+	ErrStackLimit     = errors.New("превышен предел стака в инвентаре получателя")
+)
+
+// Inventory - инвентарь участника сделки.
+// Реализация не обязана быть потокобезопасной: Manager обращается к
+// инвентарям только под собственной блокировкой.
+type Inventory interface {
+	// Count возвращает количество предмета в инвентаре
+	Count(itemID string) int
+	// Add добавляет предметы в инвентарь
+	Add(itemID string, count int)
+	// Remove изымает предметы; возвращает false, если их недостаточно
+	Remove(itemID string, count int) bool
+}
+
+// InventoryProvider возвращает инвентарь игрока по ID его сущности
+type InventoryProvider func(playerID uint64) (Inventory, bool)
+
+// Status - состояние сделки
+type Status int
+
+const (
+	StatusOpen Status = iota
+	StatusCompleted
+	StatusCancelled
+)
+
+// Trade - снимок сделки между двумя игроками
+type Trade struct {
+	ID        string
+	Parties   [2]uint64         // Инициатор и партнёр
+	Offers    [2]map[string]int // Предметы в эскроу каждой стороны
+	Confirmed [2]bool
+	Status    Status
+	Reason    string // Причина отмены
+}
+
+// Side возвращает индекс стороны игрока в сделке (-1, если игрок не участник)
+func (t *Trade) Side(playerID uint64) int {
+	switch playerID {
+	case t.Parties[0]:
+		return 0
+	case t.Parties[1]:
+		return 1
+	default:
+		return -1
+	}
+}
+
+// Partner возвращает ID второго участника сделки
+func (t *Trade) Partner(playerID uint64) uint64 {
+	if t.Parties[0] == playerID {
+		return t.Parties[1]
+	}
+	return t.Parties[0]
+}
+
+// snapshot возвращает копию сделки, безопасную для использования вне блокировки
+func (t *Trade) snapshot() Trade {
+	clone := *t
+	for side := range t.Offers {
+		clone.Offers[side] = make(map[string]int, len(t.Offers[side]))
+		for itemID, count := range t.Offers[side] {
+			clone.Offers[side][itemID] = count
+		}
+	}
+	return clone
+}
@@ -4,14 +4,32 @@ import (
 	"github.com/aquilax/go-perlin"
 )
 
-var perlinNoise *perlin.Perlin
+// PerlinNoise - генератор шума Перлина с собственным сидом. В отличие от
+// глобального генератора (InitPerlinNoise), экземпляры с разными сидами не
+// влияют друг на друга; чтение потокобезопасно
+type PerlinNoise struct {
+	p *perlin.Perlin
+}
 
-// InitPerlinNoise инициализирует генератор шума Перлина с указанным сидом
-func InitPerlinNoise(seed int64) {
+// NewPerlinNoise создаёт генератор шума Перлина с указанным сидом
+func NewPerlinNoise(seed int64) *PerlinNoise {
 	alpha := 2.0  // Сглаживание шума
 	beta := 2.0   // Частота шума
 	n := int32(3) // Количество октав
-	perlinNoise = perlin.NewPerlin(alpha, beta, n, seed)
+	return &PerlinNoise{p: perlin.NewPerlin(alpha, beta, n, seed)}
+}
+
+// Noise2D возвращает значение шума для указанных координат (от 0 до 1)
+func (pn *PerlinNoise) Noise2D(x, y float64) float64 {
+	// Получаем значение шума (от -1 до 1) и преобразуем в диапазон от 0 до 1
+	return (pn.p.Noise2D(x, y) + 1.0) / 2.0
+}
+
+var perlinNoise *PerlinNoise
+
+// InitPerlinNoise инициализирует генератор шума Перлина с указанным сидом
+func InitPerlinNoise(seed int64) {
+	perlinNoise = NewPerlinNoise(seed)
 }
 
 // PerlinNoise2D возвращает значение шума Перлина для указанных координат (от 0 до 1)
@@ -21,9 +39,5 @@ func PerlinNoise2D(x, y float64, seed int64) float64 {
 		InitPerlinNoise(seed)
 	}
 
-	// Получаем значение шума (от -1 до 1)
-	noise := perlinNoise.Noise2D(x, y)
-
-	// Преобразуем в диапазон от 0 до 1
-	return (noise + 1.0) / 2.0
+	return perlinNoise.Noise2D(x, y)
 }
@@ -2,83 +2,204 @@ package world
 
 import (
 	"context"
+	"maps"
+	"math"
 	"math/rand"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/annel0/mmo-game/internal/physics"
 	"github.com/annel0/mmo-game/internal/vec"
 	"github.com/annel0/mmo-game/internal/world/block"
+	entitypkg "github.com/annel0/mmo-game/internal/world/entity"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
+const (
+	// DefaultMaxBlockUpdatesPerTick - предел обновлений блоков за тик для каждой очереди
+	// (постоянно и разово тикаемые блоки). Остаток переносится на следующие тики.
+	DefaultMaxBlockUpdatesPerTick = 2048
+
+	// DefaultBlockTickBudget - время на обновление блоков за тик (тик при 60 TPS длится ~16 мс)
+	DefaultBlockTickBudget = 8 * time.Millisecond
+)
+
+// deferredBlockUpdates считает обновления блоков, перенесённые на следующий тик из-за бюджета
+var deferredBlockUpdates = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "world",
+	Name:      "bigchunk_deferred_block_updates_total",
+	Help:      "Обновления блоков, перенесённые на следующий тик из-за превышения бюджета тика.",
+}, []string{"queue"})
+
+func init() {
+	prometheus.MustRegister(deferredBlockUpdates)
+}
+
+// TickStats - статистика обновления блоков BigChunk
+type TickStats struct {
+	LastTickUpdates int    // Обновлений блоков в последнем тике
+	Deferred        uint64 // Всего обновлений, перенесённых на следующие тики
+}
+
 // BigChunk представляет собой единицу симуляции, которая содержит 32x32 чанка
 type BigChunk struct {
-	coords        vec.Vec2               // Координаты BigChunk в мире
-	chunks        map[vec.Vec2]*Chunk    // Чанки, принадлежащие этому BigChunk
-	eventsIn      chan Event             // Входящие события
-	eventsOut     chan<- Event           // Исходящие события (в WorldManager)
-	tickables     map[vec.Vec2]struct{}  // Постоянно тикаемые блоки в этом BigChunk
-	onceTickables map[vec.Vec2]struct{}  // Блоки для разового обновления в следующем тике
-	entities      map[uint64]interface{} // Сущности в этом BigChunk (игроки, NPC)
-	world         *WorldManager          // Ссылка на WorldManager
-	mu            sync.RWMutex           // Мьютекс для безопасного доступа
-	tickID        uint64                 // Текущий номер тика для этого BigChunk
+	coords        vec.Vec2              // Координаты BigChunk в мире
+	chunks        map[vec.Vec2]*Chunk   // Чанки, принадлежащие этому BigChunk
+	eventsIn      chan Event            // Входящие события
+	eventsOut     chan<- Event          // Исходящие события (в WorldManager)
+	ticks         chan tickRequest      // Тики от планировщика мира
+	tickables     map[vec.Vec2]struct{} // Постоянно тикаемые блоки в этом BigChunk
+	onceTickables map[vec.Vec2]struct{} // Блоки, ожидающие разового обновления (для дедупликации)
+	onceQueue     []vec.Vec2            // Очередь разовых обновлений в порядке поступления
+	tickQueue     []vec.Vec2            // Остаток текущего обхода tickables (round-robin между тиками)
+	entities      map[uint64]EntityData // Сущности в этом BigChunk (игроки, NPC)
+	itemOrder     []uint64              // Выброшенные предметы в порядке появления (для вытеснения)
+	world         *WorldManager         // Ссылка на WorldManager
+	mu            sync.RWMutex          // Мьютекс для безопасного доступа
+	tickID        uint64                // Текущий номер тика для этого BigChunk
+	rng           *rand.Rand            // Случайные решения симуляции (см. bigChunkSeed)
+
+	maxBlockUpdates int           // Предел обновлений блоков за тик для каждой очереди
+	tickBudget      time.Duration // Время на обновление блоков за тик
+	tickStats       TickStats
+
+	// Адаптивная частота тиков (см. tick_policy.go)
+	idle      bool          // Нет активности: тики обрабатываются с частотой TickPolicy.IdleRate
+	idleFor   float64       // Секунд без активности подряд
+	pendingDt float64       // Время, накопленное с последнего обработанного тика
+	lastDt    atomic.Uint64 // dt последнего обработанного тика (биты float64), см. deltaTime
+
+	// Выполнение в пуле воркеров (см. bigchunk_pool.go); nil - собственная горутина Run
+	pool        *bigChunkPool
+	scheduled   atomic.Bool  // BigChunk в очереди пула или обслуживается воркером
+	pendingTick *tickRequest // Тик, ожидающий воркера
+	tickMu      sync.Mutex   // Защищает pendingTick
 }
 
 // EntityData представляет данные о сущности внутри BigChunk
 type EntityData struct {
-	ID       uint64                 // Уникальный ID сущности
-	Type     uint16                 // Тип сущности
-	Position vec.Vec2               // Текущая позиция
-	Metadata map[string]interface{} // Дополнительные данные
+	ID         uint64                 // Уникальный ID сущности
+	Type       uint16                 // Тип сущности
+	Position   vec.Vec2               // Текущая позиция (в координатах блоков)
+	PrecisePos vec.Vec2Float          // Точная позиция
+	Direction  int                    // Направление взгляда
+	Velocity   vec.Vec2Float          // Текущая скорость
+	Health     int                    // Здоровье
+	Metadata   map[string]interface{} // Дополнительные данные
 }
 
-// NewBigChunk создаёт новый BigChunk с указанными координатами
+// NewBigChunk создаёт новый BigChunk с указанными координатами. Генератор
+// случайных чисел симуляции выводится из сида мира и координат
 func NewBigChunk(coords vec.Vec2, world *WorldManager, eventsOut chan<- Event) *BigChunk {
+	var worldSeed int64
+	if world != nil {
+		worldSeed = world.seed
+	}
+
 	return &BigChunk{
 		coords:        coords,
 		chunks:        make(map[vec.Vec2]*Chunk),
 		eventsIn:      make(chan Event, 1000),
+		ticks:         make(chan tickRequest),
 		eventsOut:     eventsOut,
 		tickables:     make(map[vec.Vec2]struct{}),
 		onceTickables: make(map[vec.Vec2]struct{}),
-		entities:      make(map[uint64]interface{}),
+		entities:      make(map[uint64]EntityData),
 		world:         world,
 		mu:            sync.RWMutex{},
 		tickID:        0,
+		rng:           newSimRand(bigChunkSeed(worldSeed, coords)),
+
+		maxBlockUpdates: DefaultMaxBlockUpdatesPerTick,
+		tickBudget:      DefaultBlockTickBudget,
 	}
 }
 
-// Run запускает горутину обработки для BigChunk
-func (bc *BigChunk) Run(ctx context.Context) {
-	ticker := time.NewTicker(time.Second / 60) // 60 TPS
-	defer ticker.Stop()
+// SetTickBudget задаёт предел обновлений блоков за тик и время на них (0 - значение по умолчанию)
+func (bc *BigChunk) SetTickBudget(maxUpdates int, budget time.Duration) {
+	if maxUpdates <= 0 {
+		maxUpdates = DefaultMaxBlockUpdatesPerTick
+	}
+	if budget <= 0 {
+		budget = DefaultBlockTickBudget
+	}
+
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+	bc.maxBlockUpdates = maxUpdates
+	bc.tickBudget = budget
+}
+
+// TickStats возвращает статистику обновления блоков
+func (bc *BigChunk) TickStats() TickStats {
+	bc.mu.RLock()
+	defer bc.mu.RUnlock()
+	return bc.tickStats
+}
 
+// tickRequest - запрос на выполнение тика от WorldManager.Step
+type tickRequest struct {
+	tick   TickEvent
+	policy TickPolicy
+	done   *sync.WaitGroup
+}
+
+// Run запускает горутину обработки для BigChunk.
+// Собственного таймера у BigChunk нет: тики приходят от WorldManager.Step,
+// который вызывается планировщиком TickScheduler.
+func (bc *BigChunk) Run(ctx context.Context) {
 	for {
 		select {
 		case <-ctx.Done():
 			return
 		case event := <-bc.eventsIn:
 			bc.handleEvent(event)
-		case <-ticker.C:
-			bc.processTick()
+		case req := <-bc.ticks:
+			bc.runTick(req)
 		}
 	}
 }
 
-// processTick обрабатывает один игровой тик для этого BigChunk
+// runTick выполняет тик планировщика и сообщает о его завершении
+func (bc *BigChunk) runTick(req tickRequest) {
+	// События, пришедшие до тика, применяются до него: иначе порядок
+	// зависел бы от того, какую ветку select выберет рантайм
+	bc.processPendingEvents()
+	if dt, run := bc.scheduleTick(req.tick.DeltaTime, req.policy); run {
+		bc.processTickAt(req.tick.TickID, dt)
+	}
+	req.done.Done()
+}
+
+// processTick обрабатывает следующий по порядку тик (для тестов без планировщика)
 func (bc *BigChunk) processTick() {
+	bc.mu.RLock()
+	next := bc.tickID + 1
+	bc.mu.RUnlock()
+	bc.processTickAt(next, 1.0/DefaultTickRate)
+}
+
+// processTickAt обрабатывает тик планировщика с указанным номером;
+// dt - время симуляции, прошедшее с предыдущего обработанного тика
+func (bc *BigChunk) processTickAt(tickID uint64, dt float64) {
 	bc.mu.Lock()
-	bc.tickID++
-	// Используем tickID, если нужно
-	_ = bc.tickID
+	bc.tickID = tickID
+	bc.lastDt.Store(math.Float64bits(dt))
+	// Бюджет общий для обеих очередей блоков, чтобы тик не выходил за свои 16 мс
+	deadline := time.Now().Add(bc.tickBudget)
 	bc.mu.Unlock()
 
 	// 1. Обновление постоянно тикаемых блоков
-	bc.updateBlocks()
+	updates := bc.updateBlocks(deadline)
 
 	// 2. Обновление блоков для разового обновления
-	bc.updateOnceBlocks()
+	updates += bc.updateOnceBlocks(deadline)
+
+	bc.mu.Lock()
+	bc.tickStats.LastTickUpdates = updates
+	bc.mu.Unlock()
 
 	// 3. Обновление сущностей
 	bc.updateEntities()
@@ -87,77 +208,109 @@ func (bc *BigChunk) processTick() {
 	bc.processPendingEvents()
 }
 
-// updateBlocks обновляет все постоянно тикаемые блоки в BigChunk
-func (bc *BigChunk) updateBlocks() {
-	bc.mu.RLock()
-	defer bc.mu.RUnlock()
-
-	// Создаем BlockAPI для доступа к миру из блоков
-	api := bc.createBlockAPI()
-
-	// Обходим все тикаемые блоки и вызываем их TickUpdate
-	for pos := range bc.tickables {
-		chunkCoords := pos.ToChunkCoords()
-		chunk, exists := bc.chunks[chunkCoords]
-		if !exists {
-			continue
+// updateBlocks обновляет постоянно тикаемые блоки в пределах бюджета тика.
+// Блоки обходятся по кругу: новый обход начинается, только когда предыдущий
+// завершён, поэтому каждый блок обновляется хотя бы раз за
+// ceil(len(tickables)/maxBlockUpdates) тиков и ни один не голодает.
+func (bc *BigChunk) updateBlocks(deadline time.Time) int {
+	bc.mu.Lock()
+	if len(bc.tickQueue) == 0 {
+		for pos := range bc.tickables {
+			bc.tickQueue = append(bc.tickQueue, pos)
 		}
+	}
+	bc.mu.Unlock()
 
-		localPos := pos.LocalInChunk()
-		blockID := chunk.GetBlock(localPos)
-		block := Block{ID: blockID, Payload: chunk.GetBlockMetadata(localPos)}
+	return bc.drainBlockQueue(&bc.tickQueue, "tick", deadline, func(api *bigChunkBlockAPI, pos vec.Vec2) {
+		// Блок мог перестать быть тикаемым, пока ждал своей очереди
+		bc.mu.RLock()
+		_, tickable := bc.tickables[pos]
+		bc.mu.RUnlock()
+		if !tickable {
+			return
+		}
 
-		behavior, exists := block.GetBehavior()
+		behavior, exists := bc.blockBehaviorAt(pos)
 		if !exists || !behavior.NeedsTick() {
 			// Блок больше не требует тиков, удаляем из списка тикаемых
+			bc.mu.Lock()
 			delete(bc.tickables, pos)
-			continue
+			bc.mu.Unlock()
+			return
 		}
 
-		// Вызываем TickUpdate для блока
 		behavior.TickUpdate(api, pos)
-	}
+	})
+}
+
+// updateOnceBlocks обновляет блоки, помеченные для разового обновления, в порядке поступления.
+// Блоки, не уместившиеся в бюджет, обновляются в следующих тиках раньше новых.
+func (bc *BigChunk) updateOnceBlocks(deadline time.Time) int {
+	return bc.drainBlockQueue(&bc.onceQueue, "once", deadline, func(api *bigChunkBlockAPI, pos vec.Vec2) {
+		// Снимаем отметку до обновления, чтобы блок мог запланировать себя снова
+		bc.mu.Lock()
+		delete(bc.onceTickables, pos)
+		bc.mu.Unlock()
+
+		behavior, exists := bc.blockBehaviorAt(pos)
+		if !exists {
+			return
+		}
+
+		// Вызываем TickUpdate для блока (даже если NeedsTick() == false)
+		behavior.TickUpdate(api, pos)
+	})
 }
 
-// updateOnceBlocks обновляет все блоки, помеченные для разового обновления
-func (bc *BigChunk) updateOnceBlocks() {
-	// Копируем список блоков для обновления, чтобы избежать блокировок
+// drainBlockQueue обновляет блоки из начала очереди, пока не исчерпан бюджет тика.
+// Хотя бы один блок обновляется всегда, чтобы очередь продвигалась даже при перегрузке.
+// Очередь изменяется только под bc.mu, блоки обновляются без блокировки.
+func (bc *BigChunk) drainBlockQueue(queue *[]vec.Vec2, name string, deadline time.Time, update func(api *bigChunkBlockAPI, pos vec.Vec2)) int {
 	bc.mu.Lock()
-	blocksToUpdate := make([]vec.Vec2, 0, len(bc.onceTickables))
-	for pos := range bc.onceTickables {
-		blocksToUpdate = append(blocksToUpdate, pos)
+	limit := bc.maxBlockUpdates
+	if limit > len(*queue) {
+		limit = len(*queue)
 	}
-	// Очищаем список после копирования
-	bc.onceTickables = make(map[vec.Vec2]struct{})
+	batch := append([]vec.Vec2(nil), (*queue)[:limit]...)
+	*queue = (*queue)[limit:]
 	bc.mu.Unlock()
 
-	// Создаем BlockAPI для доступа к миру из блоков
 	api := bc.createBlockAPI()
-
-	// Обрабатываем каждый блок
-	for _, pos := range blocksToUpdate {
-		chunkCoords := pos.ToChunkCoords()
-
-		bc.mu.RLock()
-		chunk, exists := bc.chunks[chunkCoords]
-		bc.mu.RUnlock()
-
-		if !exists {
-			continue
+	processed := 0
+	for _, pos := range batch {
+		if processed > 0 && time.Now().After(deadline) {
+			break
 		}
+		update(api, pos)
+		processed++
+	}
 
-		localPos := pos.LocalInChunk()
-		blockID := chunk.GetBlock(localPos)
-		block := Block{ID: blockID, Payload: chunk.GetBlockMetadata(localPos)}
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
 
-		behavior, exists := block.GetBehavior()
-		if !exists {
-			continue
-		}
+	if processed < len(batch) {
+		// Возвращаем необработанное в начало очереди
+		*queue = append(batch[processed:], *queue...)
+	}
+	if deferred := len(*queue); deferred > 0 {
+		bc.tickStats.Deferred += uint64(deferred)
+		deferredBlockUpdates.WithLabelValues(name).Add(float64(deferred))
+	}
+	return processed
+}
 
-		// Вызываем TickUpdate для блока (даже если NeedsTick() == false)
-		behavior.TickUpdate(api, pos)
+// blockBehaviorAt возвращает поведение блока на ACTIVE-слое по глобальным координатам
+func (bc *BigChunk) blockBehaviorAt(pos vec.Vec2) (block.BlockBehavior, bool) {
+	bc.mu.RLock()
+	chunk, exists := bc.chunks[pos.ToChunkCoords()]
+	bc.mu.RUnlock()
+	if !exists {
+		return nil, false
 	}
+
+	localPos := pos.LocalInChunk()
+	b := Block{ID: chunk.GetBlock(localPos), Payload: chunk.GetBlockMetadata(localPos)}
+	return b.GetBehavior()
 }
 
 // updateEntities обновляет все сущности в BigChunk
@@ -169,20 +322,21 @@ func (bc *BigChunk) updateEntities() {
 	// Например, вызов AI для NPC, обработка физики и т.д.
 
 	// В полной реализации здесь будет цикл по всем сущностям
-	// и вызов соответствующих методов обновления
-	for entityID, entityData := range bc.entities {
-		if data, ok := entityData.(EntityData); ok {
-			// Обработка в зависимости от типа сущности
-			switch data.Type {
-			case 0: // EntityTypePlayer
-				// Обновление игрока (если нужно)
-			case 1: // EntityTypeNPC
-				// Обновление NPC
-				bc.updateNPC(entityID, data)
-			case 2: // EntityTypeMonster
-				// Обновление монстра
-				bc.updateMonster(entityID, data)
-			}
+	// и вызов соответствующих методов обновления. Обход по возрастанию ID:
+	// сущности тянут решения из общего bc.rng, и порядок обхода карты
+	// раздавал бы их по-разному в каждом прогоне
+	for _, entityID := range slices.Sorted(maps.Keys(bc.entities)) {
+		data := bc.entities[entityID]
+		// Обработка в зависимости от типа сущности
+		switch data.Type {
+		case 0: // EntityTypePlayer
+			// Обновление игрока (если нужно)
+		case 1: // EntityTypeNPC
+			// Обновление NPC
+			bc.updateNPC(entityID, data)
+		case 2: // EntityTypeMonster
+			// Обновление монстра
+			bc.updateMonster(entityID, data)
 		}
 	}
 }
@@ -193,7 +347,7 @@ func (bc *BigChunk) updateNPC(entityID uint64, data EntityData) {
 	// Например, перемещение, диалоги, торговля и т.д.
 
 	// Пример: случайное перемещение
-	if rand.Float32() < 0.01 { // 1% шанс в тик
+	if bc.rng.Float32() < float32(block.TickChance(0.01, bc.deltaTime())) { // 1% шанс за тик при 60 TPS
 		// Генерируем случайное направление
 		directions := []vec.Vec2{
 			{X: 0, Y: 1},  // Вниз
@@ -201,7 +355,7 @@ func (bc *BigChunk) updateNPC(entityID uint64, data EntityData) {
 			{X: 0, Y: -1}, // Вверх
 			{X: -1, Y: 0}, // Влево
 		}
-		dir := directions[rand.Intn(len(directions))]
+		dir := directions[bc.rng.Intn(len(directions))]
 
 		// Вычисляем новую позицию
 		newPos := vec.Vec2{
@@ -213,6 +367,7 @@ func (bc *BigChunk) updateNPC(entityID uint64, data EntityData) {
 		if bc.canEntityMoveTo(entityID, newPos) {
 			// Обновляем позицию
 			data.Position = newPos
+			data.PrecisePos = vec.FromVec2(newPos)
 			bc.entities[entityID] = data
 
 			// Если нужно, отправляем событие о перемещении
@@ -278,16 +433,11 @@ func (bc *BigChunk) canEntityMoveTo(entityID uint64, newPos vec.Vec2) bool {
 	}
 
 	// Проверяем коллизии с другими сущностями
-	for otherID, otherData := range bc.entities {
+	for otherID, otherEntity := range bc.entities {
 		if otherID == entityID {
 			continue // Пропускаем саму сущность
 		}
 
-		otherEntity, ok := otherData.(EntityData)
-		if !ok {
-			continue
-		}
-
 		// Создаем коллайдер для другой сущности
 		otherCollider := physics.NewBoxCollider(1, 1)
 
@@ -372,11 +522,8 @@ func (bc *BigChunk) saveState(forced bool) {
 		chunks = append(chunks, chunk)
 	}
 
-	// Копируем сущности для сохранения, чтобы не держать блокировку
-	entitiesCopy := make(map[uint64]interface{}, len(bc.entities))
-	for id, entity := range bc.entities {
-		entitiesCopy[id] = entity
-	}
+	// Снимки сущностей для сохранения, чтобы не держать блокировку
+	snapshots := bc.entitySnapshotsLocked()
 	bc.mu.RUnlock()
 
 	// Отправляем событие сохранения с чанками
@@ -385,29 +532,17 @@ func (bc *BigChunk) saveState(forced bool) {
 		Chunks: chunks,
 	}
 
-	// Отправляем событие сохранения
-	select {
-	case bc.eventsOut <- saveEvent:
-		// Успешно отправлено
-	default:
-		// Канал переполнен, логируем
-		// log.Printf("Не удалось отправить событие сохранения: канал переполнен")
-	}
+	// Отправляем событие сохранения (при переполнении учитывается как отброшенное)
+	bc.sendToWorld(saveEvent)
 
 	// Отправляем отдельное событие для сохранения сущностей
-	if len(entitiesCopy) > 0 {
+	if len(snapshots) > 0 {
 		entitySaveEvent := EntitySaveEvent{
 			BigChunkCoords: bc.coords,
-			Entities:       entitiesCopy,
+			Entities:       snapshots,
 		}
 
-		select {
-		case bc.eventsOut <- entitySaveEvent:
-			// Успешно отправлено
-		default:
-			// Канал переполнен, логируем
-			// log.Printf("Не удалось отправить событие сохранения сущностей: канал переполнен")
-		}
+		bc.sendToWorld(entitySaveEvent)
 	}
 }
 
@@ -525,9 +660,7 @@ func (bc *BigChunk) setBlockLayer(pos vec.Vec2, layer BlockLayer, block Block) {
 
 	// Если есть метаданные - устанавливаем их
 	if len(block.Payload) > 0 {
-		for key, value := range block.Payload {
-			chunk.SetBlockMetadataLayer(layer, localPos, key, value)
-		}
+		chunk.SetBlockMetadataLayerMap(layer, localPos, block.Payload)
 	}
 
 	// Обновляем список тикаемых блоков (только для активного слоя)
@@ -556,19 +689,26 @@ func (bc *BigChunk) spawnEntity(event EntityEvent) {
 	// Если ID равен 0, генерируем новый ID
 	entityID := event.EntityID
 	if entityID == 0 {
-		// В реальной реализации ID должен быть уникальным глобально
-		entityID = uint64(rand.Int63())
+		// ID выдаёт аллокатор мира: он уникален между регионами и не повторяется
+		// после перезапуска (загруженные ID учитываются в addEntityLocked).
+		// Генератор bc.rng служит только решениям симуляции
+		if bc.world == nil {
+			return
+		}
+		entityID = bc.world.GenerateEntityID()
 	}
 
 	// Создаем данные сущности
 	entityData := EntityData{
-		ID:       entityID,
-		Type:     uint16(0), // По умолчанию тип 0
-		Position: event.Position,
-		Metadata: make(map[string]interface{}),
+		ID:         entityID,
+		Type:       uint16(0), // По умолчанию тип 0
+		Position:   event.Position,
+		PrecisePos: vec.FromVec2(event.Position),
+		Metadata:   make(map[string]interface{}),
 	}
 
 	// Если есть дополнительные данные, обрабатываем их
+	typed := false
 	if event.Data != nil {
 		if data, ok := event.Data.(map[string]interface{}); ok {
 			// Копируем данные
@@ -579,12 +719,23 @@ func (bc *BigChunk) spawnEntity(event EntityEvent) {
 			// Если указан тип, используем его
 			if typeVal, ok := data["type"].(uint16); ok {
 				entityData.Type = typeVal
+				typed = true
+			} else if typeVal, ok := block.MetadataInt(data, "type"); ok {
+				entityData.Type = uint16(typeVal)
+				typed = true
+			}
+			if health, ok := block.MetadataInt(data, "health"); ok {
+				entityData.Health = health
 			}
 		}
 	}
 
-	// Добавляем сущность в BigChunk
-	bc.entities[entityID] = entityData
+	// Добавляем сущность в BigChunk, если позволяет предел
+	player := typed && entityData.Type == uint16(entitypkg.EntityTypePlayer)
+	if _, exists := bc.entities[entityID]; !exists && !bc.admitEntityLocked(entityData.Type, player) {
+		return
+	}
+	bc.addEntityLocked(entityData)
 
 	// Отправляем подтверждение создания
 	confirmEvent := EntityEvent{
@@ -604,9 +755,10 @@ func (bc *BigChunk) despawnEntity(event EntityEvent) {
 	entityID := event.EntityID
 
 	// Проверяем, существует ли сущность
-	if _, exists := bc.entities[entityID]; exists {
+	if data, exists := bc.entities[entityID]; exists {
 		// Удаляем сущность
-		delete(bc.entities, entityID)
+		bc.removeEntityLocked(entityID)
+		entitypkg.CountDespawn(entitypkg.EntityType(data.Type))
 
 		// Отправляем подтверждение удаления
 		confirmEvent := EntityEvent{
@@ -626,33 +778,32 @@ func (bc *BigChunk) moveEntity(event EntityEvent) {
 	entityID := event.EntityID
 
 	// Проверяем, существует ли сущность
-	if entityData, exists := bc.entities[entityID]; exists {
-		if data, ok := entityData.(EntityData); ok {
-			// Обновляем позицию
-			newPos := event.Position
+	if data, exists := bc.entities[entityID]; exists {
+		// Обновляем позицию
+		newPos := event.Position
+
+		// Проверяем, не выходит ли сущность за пределы BigChunk
+		if newPos.ToBigChunkCoords() != bc.coords {
+			// Сущность перемещается в другой BigChunk
+			// В этом случае обработка должна быть на уровне WorldManager
+			return
+		}
 
-			// Проверяем, не выходит ли сущность за пределы BigChunk
-			if newPos.ToBigChunkCoords() != bc.coords {
-				// Сущность перемещается в другой BigChunk
-				// В этом случае обработка должна быть на уровне WorldManager
-				return
-			}
+		// Проверяем, можно ли переместиться
+		if bc.canEntityMoveTo(entityID, newPos) {
+			// Обновляем позицию
+			data.Position = newPos
+			data.PrecisePos = vec.FromVec2(newPos)
+			bc.entities[entityID] = data
 
-			// Проверяем, можно ли переместиться
-			if bc.canEntityMoveTo(entityID, newPos) {
-				// Обновляем позицию
-				data.Position = newPos
-				bc.entities[entityID] = data
-
-				// Отправляем подтверждение перемещения
-				confirmEvent := EntityEvent{
-					EventType: EventTypeEntityMove,
-					EntityID:  entityID,
-					Position:  newPos,
-					Data:      data,
-				}
-				bc.eventsOut <- confirmEvent
+			// Отправляем подтверждение перемещения
+			confirmEvent := EntityEvent{
+				EventType: EventTypeEntityMove,
+				EntityID:  entityID,
+				Position:  newPos,
+				Data:      data,
 			}
+			bc.eventsOut <- confirmEvent
 		}
 	}
 }
@@ -668,7 +819,11 @@ func (bc *BigChunk) AddOnceTickable(pos vec.Vec2) {
 	bc.mu.Lock()
 	defer bc.mu.Unlock()
 
+	if _, pending := bc.onceTickables[pos]; pending {
+		return
+	}
 	bc.onceTickables[pos] = struct{}{}
+	bc.onceQueue = append(bc.onceQueue, pos)
 }
 
 // handleBlockInteraction обрабатывает взаимодействие с блоком
@@ -0,0 +1,217 @@
+package world
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	// bigChunkPoolActiveWorkers - воркеры пула, обслуживающие BigChunk прямо сейчас
+	bigChunkPoolActiveWorkers = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "world",
+		Name:      "bigchunk_pool_active_workers",
+		Help:      "Воркеры пула BigChunk, занятые обработкой событий и тиков.",
+	})
+	// bigChunkPoolQueued - BigChunk, ожидающие свободного воркера
+	bigChunkPoolQueued = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "world",
+		Name:      "bigchunk_pool_queued_chunks",
+		Help:      "BigChunk с событиями или тиком, ожидающие свободного воркера пула.",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(bigChunkPoolActiveWorkers, bigChunkPoolQueued)
+}
+
+// BigChunkPoolStats - состояние пула воркеров BigChunk
+type BigChunkPoolStats struct {
+	Workers int // Воркеров в пуле (0 - горутина на каждый BigChunk)
+	Active  int // Воркеров, обслуживающих BigChunk
+	Queued  int // BigChunk, ожидающих воркера
+}
+
+// bigChunkPool выполняет симуляцию BigChunk на ограниченном числе воркеров
+// вместо отдельной горутины на каждый. BigChunk ставится в очередь, когда у
+// него появляются события или тик, и обслуживается одним воркером за раз,
+// поэтому события одного BigChunk обрабатываются по порядку
+type bigChunkPool struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	queue   []*BigChunk
+	workers int
+	active  int
+	stopped bool
+	wg      sync.WaitGroup
+}
+
+// newBigChunkPool создаёт пул и запускает его воркеры
+func newBigChunkPool(workers int) *bigChunkPool {
+	p := &bigChunkPool{workers: workers}
+	p.cond = sync.NewCond(&p.mu)
+
+	p.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go p.worker()
+	}
+	return p
+}
+
+// schedule ставит BigChunk в очередь. false - пул остановлен
+func (p *bigChunkPool) schedule(bc *BigChunk) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.stopped {
+		return false
+	}
+	p.queue = append(p.queue, bc)
+	bigChunkPoolQueued.Inc()
+	p.cond.Signal()
+	return true
+}
+
+// worker обслуживает BigChunk из очереди. После остановки пула очередь
+// дорабатывается, чтобы ожидающие тики завершились
+func (p *bigChunkPool) worker() {
+	defer p.wg.Done()
+
+	for {
+		p.mu.Lock()
+		for len(p.queue) == 0 && !p.stopped {
+			p.cond.Wait()
+		}
+		if len(p.queue) == 0 {
+			p.mu.Unlock()
+			return
+		}
+		bc := p.queue[0]
+		p.queue[0] = nil
+		p.queue = p.queue[1:]
+		p.active++
+		p.mu.Unlock()
+
+		bigChunkPoolQueued.Dec()
+		bigChunkPoolActiveWorkers.Inc()
+		bc.service()
+		bigChunkPoolActiveWorkers.Dec()
+
+		p.mu.Lock()
+		p.active--
+		p.mu.Unlock()
+	}
+}
+
+// stop останавливает пул после обработки очереди
+func (p *bigChunkPool) stop() {
+	p.mu.Lock()
+	p.stopped = true
+	p.cond.Broadcast()
+	p.mu.Unlock()
+
+	p.wg.Wait()
+}
+
+// stats возвращает состояние пула
+func (p *bigChunkPool) stats() BigChunkPoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return BigChunkPoolStats{Workers: p.workers, Active: p.active, Queued: len(p.queue)}
+}
+
+// wake ставит BigChunk в очередь пула, если он ещё не там
+func (bc *BigChunk) wake() {
+	if bc.pool == nil || !bc.scheduled.CompareAndSwap(false, true) {
+		return
+	}
+	if !bc.pool.schedule(bc) {
+		bc.scheduled.Store(false)
+		bc.abandonTick()
+	}
+}
+
+// submitTick передаёт тик BigChunk в пуле воркеров
+func (bc *BigChunk) submitTick(req tickRequest) {
+	bc.tickMu.Lock()
+	bc.pendingTick = &req
+	bc.tickMu.Unlock()
+
+	bc.wake()
+}
+
+// abandonTick завершает тик, который уже не будет обработан (пул остановлен),
+// чтобы WorldManager.Step не ждал его
+func (bc *BigChunk) abandonTick() {
+	bc.tickMu.Lock()
+	req := bc.pendingTick
+	bc.pendingTick = nil
+	bc.tickMu.Unlock()
+
+	if req != nil {
+		req.done.Done()
+	}
+}
+
+// service обрабатывает накопившиеся события и тик BigChunk - то же, что
+// одна итерация Run. Если за время обработки появилась новая работа,
+// BigChunk снова ставится в конец очереди, чтобы не занимать воркер
+func (bc *BigChunk) service() {
+	bc.processPendingEvents()
+
+	bc.tickMu.Lock()
+	req := bc.pendingTick
+	bc.pendingTick = nil
+	bc.tickMu.Unlock()
+	if req != nil {
+		bc.runTick(*req)
+	}
+
+	bc.scheduled.Store(false)
+	if bc.hasPendingWork() {
+		bc.wake()
+	}
+}
+
+// hasPendingWork сообщает, есть ли у BigChunk необработанные события или тик
+func (bc *BigChunk) hasPendingWork() bool {
+	if len(bc.eventsIn) > 0 {
+		return true
+	}
+	bc.tickMu.Lock()
+	defer bc.tickMu.Unlock()
+	return bc.pendingTick != nil
+}
+
+// SetBigChunkWorkers переводит симуляцию BigChunk на пул из workers
+// воркеров вместо горутины на каждый BigChunk (0 или меньше - горутина на
+// каждый). Вызывается до создания первого BigChunk
+func (wm *WorldManager) SetBigChunkWorkers(workers int) error {
+	wm.mu.Lock()
+	defer wm.mu.Unlock()
+
+	if len(wm.bigChunks) > 0 {
+		return fmt.Errorf("пул воркеров BigChunk нельзя изменить после создания BigChunk (%d активно)", len(wm.bigChunks))
+	}
+	if wm.bigChunkPool != nil {
+		wm.bigChunkPool.stop()
+		wm.bigChunkPool = nil
+	}
+	if workers > 0 {
+		wm.bigChunkPool = newBigChunkPool(workers)
+	}
+	return nil
+}
+
+// BigChunkPoolStats возвращает состояние пула воркеров BigChunk
+func (wm *WorldManager) BigChunkPoolStats() BigChunkPoolStats {
+	wm.mu.RLock()
+	pool := wm.bigChunkPool
+	wm.mu.RUnlock()
+
+	if pool == nil {
+		return BigChunkPoolStats{}
+	}
+	return pool.stats()
+}
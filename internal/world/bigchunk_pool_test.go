@@ -0,0 +1,119 @@
+package world
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/annel0/mmo-game/internal/world/block"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// loadBigChunks создаёт count BigChunk подряд по строкам
+func loadBigChunks(wm *WorldManager, count int) []*BigChunk {
+	wm.mu.Lock()
+	defer wm.mu.Unlock()
+
+	bigChunks := make([]*BigChunk, 0, count)
+	for i := range count {
+		bigChunks = append(bigChunks, wm.createBigChunk(vec.Vec2{X: i % 64, Y: i / 64}))
+	}
+	return bigChunks
+}
+
+// stopBigChunks останавливает горутины и пул BigChunk без сохранения мира
+func stopBigChunks(wm *WorldManager) {
+	wm.cancelFunc()
+	if wm.bigChunkPool != nil {
+		wm.bigChunkPool.stop()
+	}
+}
+
+func TestBigChunkPool_PreservesEventOrderAndTicks(t *testing.T) {
+	wm := NewWorldManager(1)
+	require.NoError(t, wm.SetBigChunkWorkers(2))
+	defer stopBigChunks(wm)
+	wm.SetTickPolicy(-1, 0)
+
+	bigChunks := loadBigChunks(wm, 16)
+	assert.Error(t, wm.SetBigChunkWorkers(4), "после создания BigChunk пул не меняется")
+
+	// Изменения одного блока применяются в порядке отправки (ID без поведения,
+	// чтобы проверять только порядок)
+	first, last := block.BlockID(1000), block.BlockID(1001)
+	pos := vec.Vec2{X: 5, Y: 7}
+	target := wm.bigChunks[pos.ToBigChunkCoords()]
+	require.NotNil(t, target)
+	for i := range 500 {
+		id := first
+		if i%2 == 1 {
+			id = last
+		}
+		require.True(t, target.sendToSelf(BlockEvent{EventType: EventTypeBlockChange, Position: pos, Block: NewBlock(id)}))
+	}
+
+	// Тик применяет события, пришедшие до него, и проходит во всех BigChunk
+	for tick := uint64(1); tick <= 3; tick++ {
+		wm.Step(tick, 1.0/DefaultTickRate)
+	}
+	for _, bc := range bigChunks {
+		bc.mu.RLock()
+		assert.Equal(t, uint64(3), bc.tickID, "BigChunk %v", bc.coords)
+		bc.mu.RUnlock()
+	}
+
+	target.mu.RLock()
+	chunk := target.chunks[pos.ToChunkCoords()]
+	target.mu.RUnlock()
+	require.NotNil(t, chunk)
+	assert.Equal(t, last, chunk.GetBlockLayer(LayerActive, pos.LocalInChunk()), "последнее изменение должно победить")
+
+	assert.Eventually(t, func() bool {
+		return wm.BigChunkPoolStats() == BigChunkPoolStats{Workers: 2}
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestBigChunkPool_StopReleasesPendingTicks(t *testing.T) {
+	wm := NewWorldManager(1)
+	require.NoError(t, wm.SetBigChunkWorkers(1))
+	loadBigChunks(wm, 4)
+	stopBigChunks(wm)
+
+	done := make(chan struct{})
+	go func() {
+		wm.Step(1, 1.0/DefaultTickRate)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Step не должен ждать тиков остановленного пула")
+	}
+}
+
+// BenchmarkWorldStep_2000BigChunks сравнивает тик мира с горутиной на каждый
+// BigChunk и с пулом воркеров по числу CPU
+func BenchmarkWorldStep_2000BigChunks(b *testing.B) {
+	for _, bench := range []struct {
+		name    string
+		workers int
+	}{
+		{"goroutine-per-chunk", 0},
+		{"pooled", runtime.NumCPU()},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			wm := NewWorldManager(1)
+			require.NoError(b, wm.SetBigChunkWorkers(bench.workers))
+			wm.SetTickPolicy(-1, 0)
+			loadBigChunks(wm, 2000)
+			defer stopBigChunks(wm)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				wm.Step(uint64(i+1), 1.0/DefaultTickRate)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package world
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/annel0/mmo-game/internal/world/block"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const countingBlockID block.BlockID = 9100
+
+// countingBlock - тикаемый блок, считающий свои обновления
+type countingBlock struct {
+	mu    sync.Mutex
+	ticks map[vec.Vec2]int
+}
+
+func (b *countingBlock) ID() block.BlockID { return countingBlockID }
+func (b *countingBlock) Name() string      { return "counting" }
+func (b *countingBlock) NeedsTick() bool   { return true }
+func (b *countingBlock) TickUpdate(api block.BlockAPI, pos vec.Vec2) {
+	b.mu.Lock()
+	b.ticks[pos]++
+	b.mu.Unlock()
+}
+func (b *countingBlock) OnPlace(api block.BlockAPI, pos vec.Vec2) {}
+func (b *countingBlock) OnBreak(api block.BlockAPI, pos vec.Vec2) {}
+func (b *countingBlock) CreateMetadata() block.Metadata           { return nil }
+func (b *countingBlock) HandleInteraction(action string, currentPayload, actionPayload map[string]interface{}) (block.BlockID, map[string]interface{}, block.InteractionResult) {
+	return countingBlockID, currentPayload, block.InteractionResult{}
+}
+
+func (b *countingBlock) reset() {
+	b.mu.Lock()
+	b.ticks = make(map[vec.Vec2]int)
+	b.mu.Unlock()
+}
+
+func (b *countingBlock) snapshot() map[vec.Vec2]int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	result := make(map[vec.Vec2]int, len(b.ticks))
+	for pos, n := range b.ticks {
+		result[pos] = n
+	}
+	return result
+}
+
+// newCountingBigChunk создаёт BigChunk, в котором все блоки одного чанка (256 шт.) тикаемые
+func newCountingBigChunk(t *testing.T) (*BigChunk, *countingBlock, []vec.Vec2) {
+	t.Helper()
+
+	behavior := &countingBlock{}
+	behavior.reset()
+	block.Register(countingBlockID, behavior)
+
+	bc := NewBigChunk(vec.Vec2{}, nil, make(chan Event, 16))
+	chunk := NewChunk(vec.Vec2{})
+	var positions []vec.Vec2
+	for x := 0; x < 16; x++ {
+		for y := 0; y < 16; y++ {
+			pos := vec.Vec2{X: x, Y: y}
+			chunk.SetBlock(pos, countingBlockID)
+			positions = append(positions, pos)
+		}
+	}
+	bc.chunks[vec.Vec2{}] = chunk
+	return bc, behavior, positions
+}
+
+func TestBigChunk_TickablesRoundRobinWithinBudget(t *testing.T) {
+	bc, behavior, positions := newCountingBigChunk(t)
+	for _, pos := range positions {
+		bc.tickables[pos] = struct{}{}
+	}
+	bc.SetTickBudget(100, time.Hour)
+
+	// 256 блоков при бюджете 100 обновляются за три тика
+	for tick := 0; tick < 3; tick++ {
+		bc.processTick()
+		assert.LessOrEqual(t, bc.TickStats().LastTickUpdates, 100, "тик %d превысил бюджет", tick)
+	}
+
+	ticks := behavior.snapshot()
+	require.Len(t, ticks, len(positions), "каждый блок должен обновиться за один обход")
+	for pos, n := range ticks {
+		assert.Equal(t, 1, n, "блок %v обновлён повторно до завершения обхода", pos)
+	}
+	assert.Equal(t, uint64(156+56), bc.TickStats().Deferred)
+
+	// Следующий обход снова покрывает все блоки
+	for tick := 0; tick < 3; tick++ {
+		bc.processTick()
+	}
+	for pos, n := range behavior.snapshot() {
+		assert.Equal(t, 2, n, "блок %v", pos)
+	}
+}
+
+func TestBigChunk_OnceTickablesDeferredInOrder(t *testing.T) {
+	bc, behavior, positions := newCountingBigChunk(t)
+	bc.SetTickBudget(100, time.Hour)
+
+	for _, pos := range positions {
+		bc.AddOnceTickable(pos)
+	}
+	// Повторная отметка ожидающего блока не ставит его в очередь дважды
+	bc.AddOnceTickable(positions[0])
+
+	bc.processTick()
+	assert.Equal(t, 100, bc.TickStats().LastTickUpdates)
+	ticks := behavior.snapshot()
+	for _, pos := range positions[:100] {
+		assert.Equal(t, 1, ticks[pos], "первые блоки очереди обновляются первыми")
+	}
+
+	// Уже обновлённый блок, отмеченный снова, ждёт, пока не обработаются отложенные
+	late := positions[0]
+	bc.AddOnceTickable(late)
+
+	bc.processTick()
+	assert.Equal(t, 1, behavior.snapshot()[late])
+
+	bc.processTick()
+	assert.Equal(t, 57, bc.TickStats().LastTickUpdates)
+	ticks = behavior.snapshot()
+	require.Len(t, ticks, len(positions))
+	for _, pos := range positions[1:] {
+		assert.Equal(t, 1, ticks[pos], "блок %v", pos)
+	}
+	assert.Equal(t, 2, ticks[late])
+	assert.Empty(t, bc.onceQueue)
+}
+
+func TestBigChunk_TimeBudgetStillMakesProgress(t *testing.T) {
+	bc, behavior, positions := newCountingBigChunk(t)
+	for _, pos := range positions {
+		bc.tickables[pos] = struct{}{}
+	}
+	// Бюджет, который истекает сразу: за тик обновляется минимум один блок
+	bc.SetTickBudget(1000, time.Nanosecond)
+
+	for tick := 0; tick < len(positions); tick++ {
+		bc.processTick()
+		assert.GreaterOrEqual(t, bc.TickStats().LastTickUpdates, 1)
+	}
+	assert.Len(t, behavior.snapshot(), len(positions), "все блоки должны в итоге обновиться")
+}
@@ -1,6 +1,9 @@
 package block
 
 import (
+	"math"
+	"math/rand"
+
 	"github.com/annel0/mmo-game/internal/vec"
 )
 
@@ -34,4 +37,27 @@ type BlockAPI interface {
 	// TriggerNeighborUpdates запускает разовое обновление для всех соседних блоков.
 	// Обновляет блоки сверху, снизу, слева и справа от указанной позиции.
 	TriggerNeighborUpdates(pos vec.Vec2)
+
+	// Rand возвращает детерминированный генератор BigChunk. Блоки используют его
+	// вместо глобального math/rand, чтобы симуляция совпадала на всех регионах.
+	Rand() *rand.Rand
+
+	// DeltaTime возвращает время симуляции (в секундах), прошедшее с предыдущего
+	// тика BigChunk. BigChunk без активности тикают реже, поэтому вероятности
+	// "за тик" нужно пересчитывать через TickChance.
+	DeltaTime() float64
+}
+
+// NominalTickDelta - длительность тика (60 TPS), для которой в поведении блоков
+// заданы вероятности "за тик"
+const NominalTickDelta = 1.0 / 60
+
+// TickChance пересчитывает вероятность события perTick за номинальный тик в
+// вероятность того, что оно произойдёт хотя бы раз за dt секунд. При dt,
+// равном NominalTickDelta (или неизвестном), возвращает perTick без изменений.
+func TickChance(perTick, dt float64) float64 {
+	if dt <= 0 || dt == NominalTickDelta {
+		return perTick
+	}
+	return 1 - math.Pow(1-perTick, dt/NominalTickDelta)
 }
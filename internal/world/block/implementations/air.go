@@ -52,7 +52,7 @@ func (b *AirBehavior) IsPassable() bool {
 func (b *AirBehavior) HandleInteraction(action string, currentPayload, actionPayload map[string]interface{}) (block.BlockID, map[string]interface{}, block.InteractionResult) {
 	// Воздух нельзя изменить взаимодействием, но можно поставить блок
 	if action == "place" {
-		if blockID, ok := actionPayload["block_id"].(float64); ok {
+		if blockID, ok := block.MetadataInt(actionPayload, "block_id"); ok {
 			newBlockID := block.BlockID(uint16(blockID))
 
 			// Получаем поведение для создаваемого блока
@@ -1,6 +1,7 @@
 package implementations
 
 import (
+	"math/rand"
 	"testing"
 
 	"github.com/annel0/mmo-game/internal/vec"
@@ -59,6 +60,14 @@ func (m *mockBlockAPI) ScheduleUpdateOnce(pos vec.Vec2) {
 	m.scheduledUpdates[pos] = true
 }
 
+func (m *mockBlockAPI) Rand() *rand.Rand {
+	return rand.New(rand.NewSource(1))
+}
+
+func (m *mockBlockAPI) DeltaTime() float64 {
+	return block.NominalTickDelta
+}
+
 func (m *mockBlockAPI) TriggerNeighborUpdates(pos vec.Vec2) {
 	neighbors := []vec.Vec2{
 		{X: pos.X + 1, Y: pos.Y},
@@ -315,6 +324,14 @@ func (api *testLayeredBlockAPI) ScheduleUpdateOnce(pos vec.Vec2) {
 	api.scheduledOnce[pos] = true
 }
 
+func (api *testLayeredBlockAPI) Rand() *rand.Rand {
+	return rand.New(rand.NewSource(1))
+}
+
+func (api *testLayeredBlockAPI) DeltaTime() float64 {
+	return block.NominalTickDelta
+}
+
 func (api *testLayeredBlockAPI) TriggerNeighborUpdates(pos vec.Vec2) {
 	neighbors := []vec.Vec2{
 		{X: pos.X + 1, Y: pos.Y},
@@ -66,8 +66,8 @@ func (b *DirtBehavior) HandleInteraction(action string, currentPayload, actionPa
 			} else if tool == "water" {
 				// Увеличиваем влажность земли
 				moisture := 0
-				if m, ok := currentPayload["moisture"].(float64); ok {
-					moisture = int(m)
+				if m, ok := block.MetadataInt(currentPayload, "moisture"); ok {
+					moisture = m
 				}
 
 				if moisture < 10 {
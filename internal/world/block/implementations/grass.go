@@ -1,8 +1,6 @@
 package implementations
 
 import (
-	"math/rand"
-
 	"github.com/annel0/mmo-game/internal/vec"
 	"github.com/annel0/mmo-game/internal/world/block"
 )
@@ -36,14 +34,14 @@ func (b *GrassBehavior) TickUpdate(api block.BlockAPI, pos vec.Vec2) {
 		return
 	}
 
-	// Шанс роста 10% каждый тик, максимальный рост 5
-	if growth < 5 && rand.Float32() < 0.1 {
+	// Шанс роста 10% каждый тик (при 60 TPS), максимальный рост 5
+	if growth < 5 && api.Rand().Float32() < float32(block.TickChance(0.1, api.DeltaTime())) {
 		growth++
 		api.SetBlockMetadata(pos, "growth", growth)
 	}
 
 	// Если трава достаточно выросла, пытаемся распространиться на соседние блоки земли
-	if growth >= 3 && rand.Float32() < 0.05 {
+	if growth >= 3 && api.Rand().Float32() < float32(block.TickChance(0.05, api.DeltaTime())) {
 		// Проверяем соседние блоки
 		directions := []vec.Vec2{
 			{X: pos.X + 1, Y: pos.Y}, // право
@@ -53,7 +51,7 @@ func (b *GrassBehavior) TickUpdate(api block.BlockAPI, pos vec.Vec2) {
 		}
 
 		// Случайно выбираем направление для распространения
-		targetPos := directions[rand.Intn(len(directions))]
+		targetPos := directions[api.Rand().Intn(len(directions))]
 
 		// Проверяем, что блок по указанному направлению - земля
 		if api.GetBlockID(targetPos) == block.DirtBlockID {
@@ -105,8 +103,8 @@ func (b *GrassBehavior) HandleInteraction(action string, currentPayload, actionP
 		if tool, ok := actionPayload["tool"].(string); ok && tool == "fertilizer" {
 			// Получаем текущий рост
 			growth := 0
-			if g, ok := currentPayload["growth"].(float64); ok {
-				growth = int(g)
+			if g, ok := block.MetadataInt(currentPayload, "growth"); ok {
+				growth = g
 			}
 
 			// Увеличиваем рост
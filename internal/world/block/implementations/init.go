@@ -10,4 +10,13 @@ func init() {
 	block.Register(block.GrassBlockID, &GrassBehavior{})
 	block.Register(block.WaterBlockID, &WaterBehavior{})
 	block.Register(block.DirtBlockID, &DirtBehavior{})
+
+	// Метаданные, которые блоки хранят сами. Их ведёт симуляция, поэтому
+	// клиент не может задать их при установке блока
+	integer := func(lo, hi float64) block.MetadataField {
+		return block.MetadataField{Type: block.MetadataInteger, Min: &lo, Max: &hi, ServerManaged: true}
+	}
+	block.RegisterMetadataSchema(block.DirtBlockID, block.MetadataSchema{"moisture": integer(0, 10)})
+	block.RegisterMetadataSchema(block.GrassBlockID, block.MetadataSchema{"growth": integer(0, 5)})
+	block.RegisterMetadataSchema(block.WaterBlockID, block.MetadataSchema{"level": integer(0, 7)})
 }
@@ -0,0 +1,44 @@
+package implementations
+
+import (
+	"testing"
+
+	"github.com/annel0/mmo-game/internal/world/block"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockSchemas_DescribeStoredMetadata(t *testing.T) {
+	for id, behavior := range map[block.BlockID]block.BlockBehavior{
+		block.DirtBlockID:  &DirtBehavior{},
+		block.GrassBlockID: &GrassBehavior{},
+		block.WaterBlockID: &WaterBehavior{},
+	} {
+		schema, ok := block.GetMetadataSchema(id)
+		require.True(t, ok, "блок %d", id)
+		for key := range behavior.CreateMetadata() {
+			assert.Contains(t, schema, key, "блок %d хранит %q", id, key)
+		}
+		assert.NotContains(t, schema, "tool", "инструмент - параметр действия, а не метаданные блока %d", id)
+
+		// Состояние симуляции не задаётся клиентом при установке
+		for key := range schema {
+			payload, err := block.ValidateMetadata(id, map[string]interface{}{key: float64(1)})
+			require.NoError(t, err)
+			assert.Empty(t, payload, "блок %d: %q ведёт сервер", id, key)
+		}
+	}
+}
+
+func TestActionPayloadSchema_ValidatesTool(t *testing.T) {
+	payload, err := block.ActionPayloadSchema.Validate(map[string]interface{}{"tool": "seed"})
+	require.NoError(t, err)
+	newID, _, result := (&DirtBehavior{}).HandleInteraction("use", nil, payload)
+	assert.True(t, result.Success)
+	assert.Equal(t, block.GrassBlockID, newID)
+
+	_, err = block.ActionPayloadSchema.Validate(map[string]interface{}{"tool": float64(1)})
+	assert.Error(t, err)
+	_, err = block.ActionPayloadSchema.Validate(map[string]interface{}{"tool": "seed", "growth": float64(9)})
+	assert.Error(t, err, "действие принимает только tool")
+}
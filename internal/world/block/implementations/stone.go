@@ -66,14 +66,14 @@ func (b *StoneBehavior) HandleInteraction(action string, currentPayload, actionP
 	if action == "mine" {
 		// Получаем текущую прочность
 		hardness := 10
-		if h, ok := currentPayload["hardness"].(float64); ok {
-			hardness = int(h)
+		if h, ok := block.MetadataInt(currentPayload, "hardness"); ok {
+			hardness = h
 		}
 
 		// Сила воздействия (по умолчанию 1)
 		strength := 1
-		if s, ok := actionPayload["strength"].(float64); ok {
-			strength = int(s)
+		if s, ok := block.MetadataInt(actionPayload, "strength"); ok {
+			strength = s
 		}
 
 		// Уменьшаем прочность
@@ -105,8 +105,8 @@ func (b *WaterBehavior) HandleInteraction(action string, currentPayload, actionP
 		if tool, ok := actionPayload["tool"].(string); ok && tool == "bucket" {
 			// Уменьшаем уровень воды
 			level := 7
-			if l, ok := currentPayload["level"].(float64); ok {
-				level = int(l)
+			if l, ok := block.MetadataInt(currentPayload, "level"); ok {
+				level = l
 			}
 
 			// Уменьшаем уровень
@@ -35,12 +35,45 @@ func (b *simpleBlockBehavior) HandleInteraction(action string, cur, act map[stri
 type jsonBlockSpec struct {
 	ID   uint16 `json:"id"`
 	Name string `json:"name"`
+	// Схема метаданных, которые клиент может передавать при обновлении блока
+	Metadata MetadataSchema `json:"metadata,omitempty"`
 	// Дополнительно можно добавить поля solid, hardness и т.д.
 }
 
-// LoadJSONBlocks сканирует каталог и регистрирует блоки.
+// LoadJSONBlocks сканирует каталог и регистрирует блоки. Блоки каталога
+// добавляются в реестр разом: при ошибке в любом файле реестр не меняется
 func LoadJSONBlocks(dir string) error {
-	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+	specs, err := readJSONBlocks(dir)
+	if err != nil {
+		return err
+	}
+	return updateRegistry(func(next *registrySnapshot) error {
+		return addJSONBlocks(next, specs)
+	})
+}
+
+// ReloadJSONBlocks заменяет ранее загруженные из JSON блоки содержимым
+// каталога. Блоки, зарегистрированные кодом, не затрагиваются. Читатели
+// видят либо прежний набор блоков, либо новый целиком
+func ReloadJSONBlocks(dir string) error {
+	specs, err := readJSONBlocks(dir)
+	if err != nil {
+		return err
+	}
+	return updateRegistry(func(next *registrySnapshot) error {
+		for id := range next.fromJSON {
+			delete(next.behaviors, id)
+			delete(next.schemas, id)
+		}
+		clear(next.fromJSON)
+		return addJSONBlocks(next, specs)
+	})
+}
+
+// readJSONBlocks читает и проверяет описания блоков каталога
+func readJSONBlocks(dir string) (map[string]jsonBlockSpec, error) {
+	specs := make(map[string]jsonBlockSpec)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
 		if err != nil || d.IsDir() || filepath.Ext(path) != ".json" {
 			return err
 		}
@@ -54,11 +87,27 @@ func LoadJSONBlocks(dir string) error {
 		if err := dec.Decode(&spec); err != nil {
 			return fmt.Errorf("block json %s: %w", path, err)
 		}
-		id := BlockID(spec.ID)
-		if IsValidBlockID(id) {
-			return fmt.Errorf("duplicate block id %d in %s", spec.ID, path)
+		if err := spec.Metadata.check(); err != nil {
+			return fmt.Errorf("block json %s: %w", path, err)
 		}
-		Register(id, &simpleBlockBehavior{id: id, name: spec.Name})
+		specs[path] = spec
 		return nil
 	})
+	return specs, err
+}
+
+// addJSONBlocks регистрирует блоки в снимке реестра (path -> описание)
+func addJSONBlocks(next *registrySnapshot, specs map[string]jsonBlockSpec) error {
+	for path, spec := range specs {
+		id := BlockID(spec.ID)
+		if _, exists := next.behaviors[id]; exists {
+			return fmt.Errorf("duplicate block id %d in %s", spec.ID, path)
+		}
+		next.behaviors[id] = &simpleBlockBehavior{id: id, name: spec.Name}
+		next.fromJSON[id] = struct{}{}
+		if spec.Metadata != nil {
+			next.schemas[id] = spec.Metadata
+		}
+	}
+	return nil
 }
@@ -0,0 +1,206 @@
+package block
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// MetadataFieldType - тип значения поля метаданных блока
+type MetadataFieldType string
+
+const (
+	MetadataNumber  MetadataFieldType = "number"
+	MetadataInteger MetadataFieldType = "integer"
+	MetadataString  MetadataFieldType = "string"
+	MetadataBool    MetadataFieldType = "bool"
+)
+
+// ServerManagedMetadataKeys - ключи, которые выставляет только сервер.
+// Они молча удаляются из клиентского ввода для любого блока.
+// hardness определяет время добычи и не может задаваться клиентом
+var ServerManagedMetadataKeys = []string{"light", "hardness"}
+
+// MetadataField описывает одно поле метаданных блока.
+// Min/Max ограничивают числовые значения, MaxLength - длину строк.
+type MetadataField struct {
+	Type          MetadataFieldType `json:"type"`
+	Min           *float64          `json:"min,omitempty"`
+	Max           *float64          `json:"max,omitempty"`
+	MaxLength     int               `json:"max_length,omitempty"`
+	ServerManaged bool              `json:"server_managed,omitempty"` // Поле выставляется только сервером
+}
+
+// MetadataSchema - допустимые поля метаданных блока (ключ -> описание)
+type MetadataSchema map[string]MetadataField
+
+// ActionPayloadSchema - поля полезной нагрузки действия с блоком (use, mine
+// и др., кроме place): клиент передаёт только название инструмента. В
+// метаданных блока эти поля не сохраняются
+var ActionPayloadSchema = MetadataSchema{"tool": {Type: MetadataString, MaxLength: 32}}
+
+// RegisterMetadataSchema задаёт схему метаданных для типа блока
+func RegisterMetadataSchema(id BlockID, schema MetadataSchema) {
+	_ = updateRegistry(func(next *registrySnapshot) error {
+		next.schemas[id] = schema
+		return nil
+	})
+}
+
+// GetMetadataSchema возвращает схему метаданных типа блока
+func GetMetadataSchema(id BlockID) (MetadataSchema, bool) {
+	schema, exists := registry.Load().schemas[id]
+	return schema, exists
+}
+
+// ValidateMetadata проверяет клиентские метаданные по схеме типа блока.
+// Блок без схемы не принимает от клиента ни одного ключа.
+func ValidateMetadata(id BlockID, input map[string]interface{}) (map[string]interface{}, error) {
+	schema, _ := GetMetadataSchema(id)
+	return schema.Validate(input)
+}
+
+// check проверяет корректность описания самой схемы
+func (s MetadataSchema) check() error {
+	for key, field := range s {
+		switch field.Type {
+		case MetadataNumber, MetadataInteger, MetadataString, MetadataBool:
+		default:
+			return fmt.Errorf("metadata field %q: unknown type %q", key, field.Type)
+		}
+		if field.Min != nil && field.Max != nil && *field.Min > *field.Max {
+			return fmt.Errorf("metadata field %q: min %v is greater than max %v", key, *field.Min, *field.Max)
+		}
+	}
+	return nil
+}
+
+// Validate возвращает копию input без серверных ключей.
+// Неизвестные ключи, неверные типы и значения вне диапазона приводят к ошибке.
+func (s MetadataSchema) Validate(input map[string]interface{}) (map[string]interface{}, error) {
+	result := make(map[string]interface{}, len(input))
+
+	// Сортируем ключи, чтобы ошибка для одного и того же ввода была одинаковой
+	keys := make([]string, 0, len(input))
+	for key := range input {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if isServerManagedKey(key) {
+			continue
+		}
+
+		field, known := s[key]
+		if !known {
+			return nil, fmt.Errorf("unknown metadata key %q", key)
+		}
+		if field.ServerManaged {
+			continue
+		}
+		if err := field.validate(key, input[key]); err != nil {
+			return nil, err
+		}
+		result[key] = field.normalize(input[key])
+	}
+
+	return result, nil
+}
+
+// validate проверяет тип и диапазон значения поля
+func (f MetadataField) validate(key string, value interface{}) error {
+	switch f.Type {
+	case MetadataBool:
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("metadata key %q must be a bool", key)
+		}
+
+	case MetadataString:
+		str, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("metadata key %q must be a string", key)
+		}
+		if f.MaxLength > 0 && len(str) > f.MaxLength {
+			return fmt.Errorf("metadata key %q is longer than %d characters", key, f.MaxLength)
+		}
+
+	case MetadataNumber, MetadataInteger:
+		num, ok := toFloat(value)
+		if !ok {
+			return fmt.Errorf("metadata key %q must be a %s", key, f.Type)
+		}
+		if f.Type == MetadataInteger && num != math.Trunc(num) {
+			return fmt.Errorf("metadata key %q must be an integer", key)
+		}
+		if f.Min != nil && num < *f.Min {
+			return fmt.Errorf("metadata key %q value %v is below minimum %v", key, num, *f.Min)
+		}
+		if f.Max != nil && num > *f.Max {
+			return fmt.Errorf("metadata key %q value %v is above maximum %v", key, num, *f.Max)
+		}
+	}
+
+	return nil
+}
+
+// normalize приводит проверенное числовое значение к типу поля: int для
+// MetadataInteger и float64 для MetadataNumber, независимо от того, как
+// число пришло из JSON
+func (f MetadataField) normalize(value interface{}) interface{} {
+	num, ok := toFloat(value)
+	if !ok {
+		return value
+	}
+	switch f.Type {
+	case MetadataInteger:
+		return int(num)
+	case MetadataNumber:
+		return num
+	default:
+		return value
+	}
+}
+
+// isServerManagedKey проверяет, управляется ли ключ только сервером
+func isServerManagedKey(key string) bool {
+	for _, managed := range ServerManagedMetadataKeys {
+		if key == managed {
+			return true
+		}
+	}
+	return false
+}
+
+// MetadataInt возвращает целое значение ключа метаданных. Принимает как int,
+// так и числа, пришедшие из JSON или старых сохранений как float64
+func MetadataInt(payload map[string]interface{}, key string) (int, bool) {
+	num, ok := toFloat(payload[key])
+	if !ok {
+		return 0, false
+	}
+	return int(num), true
+}
+
+// MetadataFloat возвращает числовое значение ключа метаданных как float64
+func MetadataFloat(payload map[string]interface{}, key string) (float64, bool) {
+	return toFloat(payload[key])
+}
+
+// toFloat приводит числовое значение (в т.ч. из JSON) к float64
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
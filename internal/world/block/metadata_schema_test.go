@@ -0,0 +1,103 @@
+package block
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ptr(v float64) *float64 { return &v }
+
+func testSignSchema() MetadataSchema {
+	return MetadataSchema{
+		"text":     {Type: MetadataString, MaxLength: 16},
+		"rotation": {Type: MetadataInteger, Min: ptr(0), Max: ptr(3)},
+		"lit":      {Type: MetadataBool},
+		"owner":    {Type: MetadataString, ServerManaged: true},
+	}
+}
+
+func TestMetadataSchema_ValidMetadata(t *testing.T) {
+	result, err := testSignSchema().Validate(map[string]interface{}{
+		"text":     "hello",
+		"rotation": float64(2), // целое поле приводится к int, даже если пришло как float64
+		"lit":      true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"text": "hello", "rotation": 2, "lit": true}, result)
+}
+
+func TestMetadataSchema_StripsServerManagedKeys(t *testing.T) {
+	result, err := testSignSchema().Validate(map[string]interface{}{
+		"text":  "hi",
+		"light": float64(15),
+		"owner": "mallory",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"text": "hi"}, result)
+}
+
+func TestMetadataSchema_UnknownKey(t *testing.T) {
+	_, err := testSignSchema().Validate(map[string]interface{}{"text": "hi", "script": "rm -rf"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown metadata key "script"`)
+}
+
+func TestMetadataSchema_OutOfRange(t *testing.T) {
+	_, err := testSignSchema().Validate(map[string]interface{}{"rotation": float64(7)})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "above maximum 3")
+
+	_, err = testSignSchema().Validate(map[string]interface{}{"rotation": 1.5})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "must be an integer")
+
+	_, err = testSignSchema().Validate(map[string]interface{}{"text": "this text is far too long"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "longer than 16")
+}
+
+func TestMetadataSchema_WrongType(t *testing.T) {
+	_, err := testSignSchema().Validate(map[string]interface{}{"lit": "yes"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `"lit" must be a bool`)
+}
+
+func TestValidateMetadata_NoSchemaRejectsClientKeys(t *testing.T) {
+	const id BlockID = 990
+
+	result, err := ValidateMetadata(id, map[string]interface{}{"light": float64(3)})
+	require.NoError(t, err)
+	assert.Empty(t, result)
+
+	_, err = ValidateMetadata(id, map[string]interface{}{"anything": true})
+	assert.Error(t, err)
+}
+
+func TestLoadJSONBlocks_RegistersMetadataSchema(t *testing.T) {
+	dir := t.TempDir()
+	spec := `{"id": 991, "name": "Sign", "metadata": {"rotation": {"type": "integer", "min": 0, "max": 3}}}`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "sign.json"), []byte(spec), 0o644))
+
+	require.NoError(t, LoadJSONBlocks(dir))
+
+	schema, ok := GetMetadataSchema(991)
+	require.True(t, ok)
+	assert.Equal(t, MetadataInteger, schema["rotation"].Type)
+
+	_, err := ValidateMetadata(991, map[string]interface{}{"rotation": float64(4)})
+	assert.Error(t, err)
+}
+
+func TestLoadJSONBlocks_RejectsInvalidSchema(t *testing.T) {
+	dir := t.TempDir()
+	spec := `{"id": 992, "name": "Broken", "metadata": {"color": {"type": "colour"}}}`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "broken.json"), []byte(spec), 0o644))
+
+	err := LoadJSONBlocks(dir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown type "colour"`)
+}
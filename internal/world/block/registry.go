@@ -1,21 +1,70 @@
 package block
 
-var registry = make(map[BlockID]BlockBehavior)
+import (
+	"maps"
+	"sync"
+	"sync/atomic"
+)
+
+// registrySnapshot - неизменяемое состояние реестра блоков. Get вызывается
+// при каждой проверке проходимости и сериализации чанка из всех горутин
+// BigChunk, поэтому читается без блокировок: изменения (регистрация,
+// перезагрузка JSON-блоков) копируют снимок и подменяют его целиком
+type registrySnapshot struct {
+	behaviors map[BlockID]BlockBehavior
+	schemas   map[BlockID]MetadataSchema
+	fromJSON  map[BlockID]struct{} // Блоки из assets/blocks (см. LoadJSONBlocks)
+}
+
+var (
+	registry   atomic.Pointer[registrySnapshot]
+	registryMu sync.Mutex // Сериализует изменения реестра
+)
+
+func init() {
+	registry.Store(&registrySnapshot{
+		behaviors: make(map[BlockID]BlockBehavior),
+		schemas:   make(map[BlockID]MetadataSchema),
+		fromJSON:  make(map[BlockID]struct{}),
+	})
+}
+
+// updateRegistry применяет change к копии текущего снимка и публикует её.
+// Если change возвращает ошибку, реестр не меняется
+func updateRegistry(change func(next *registrySnapshot) error) error {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	current := registry.Load()
+	next := &registrySnapshot{
+		behaviors: maps.Clone(current.behaviors),
+		schemas:   maps.Clone(current.schemas),
+		fromJSON:  maps.Clone(current.fromJSON),
+	}
+	if err := change(next); err != nil {
+		return err
+	}
+	registry.Store(next)
+	return nil
+}
 
 // Register добавляет поведение блока в регистр
 func Register(id BlockID, behavior BlockBehavior) {
-	registry[id] = behavior
+	_ = updateRegistry(func(next *registrySnapshot) error {
+		next.behaviors[id] = behavior
+		return nil
+	})
 }
 
 // Get возвращает поведение для указанного ID
 func Get(id BlockID) (BlockBehavior, bool) {
-	behavior, exists := registry[id]
+	behavior, exists := registry.Load().behaviors[id]
 	return behavior, exists
 }
 
 // IsValidBlockID проверяет, является ли ID допустимым идентификатором блока
 func IsValidBlockID(id BlockID) bool {
-	_, exists := registry[id]
+	_, exists := registry.Load().behaviors[id]
 	return exists
 }
 
@@ -0,0 +1,118 @@
+package block
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeBlockSpec(t *testing.T, dir, name, spec string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(spec), 0o644))
+}
+
+func TestReloadJSONBlocks_SwapsJSONBlocksAtomically(t *testing.T) {
+	Register(995, &simpleBlockBehavior{id: 995, name: "code"})
+
+	first := t.TempDir()
+	writeBlockSpec(t, first, "lamp.json", `{"id": 993, "name": "Lamp", "metadata": {"lit": {"type": "bool"}}}`)
+	require.NoError(t, LoadJSONBlocks(first))
+	require.True(t, IsValidBlockID(993))
+
+	// Читатели не блокируются и видят согласованный реестр во время перезагрузок
+	second := t.TempDir()
+	writeBlockSpec(t, second, "post.json", `{"id": 994, "name": "Post"}`)
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				_, code := Get(995)
+				assert.True(t, code, "блоки из кода переживают перезагрузку")
+				_, lamp := Get(993)
+				_, post := Get(994)
+				assert.True(t, lamp != post, "виден либо прежний набор, либо новый")
+			}
+		}()
+	}
+	for i := 0; i < 50; i++ {
+		dir := first
+		if i%2 == 0 {
+			dir = second
+		}
+		require.NoError(t, ReloadJSONBlocks(dir))
+	}
+	close(stop)
+	wg.Wait()
+
+	require.NoError(t, ReloadJSONBlocks(second))
+	assert.False(t, IsValidBlockID(993))
+	_, hasSchema := GetMetadataSchema(993)
+	assert.False(t, hasSchema, "схема удалённого блока тоже удаляется")
+	behavior, ok := Get(994)
+	require.True(t, ok)
+	assert.Equal(t, "Post", behavior.Name())
+
+	// Конфликт с блоком из кода: реестр не меняется
+	broken := t.TempDir()
+	writeBlockSpec(t, broken, "ok.json", `{"id": 996, "name": "Fine"}`)
+	writeBlockSpec(t, broken, "clash.json", `{"id": 995, "name": "Clash"}`)
+	require.Error(t, ReloadJSONBlocks(broken))
+	assert.True(t, IsValidBlockID(994))
+	assert.False(t, IsValidBlockID(996))
+	behavior, _ = Get(995)
+	assert.Equal(t, "code", behavior.Name())
+}
+
+// lockedRegistry - реестр за RWMutex: так выглядел бы потокобезопасный реестр
+// без снимков, для сравнения в BenchmarkGetParallel
+type lockedRegistry struct {
+	mu        sync.RWMutex
+	behaviors map[BlockID]BlockBehavior
+}
+
+func (r *lockedRegistry) get(id BlockID) (BlockBehavior, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	behavior, exists := r.behaviors[id]
+	return behavior, exists
+}
+
+func BenchmarkGetParallel(b *testing.B) {
+	ids := []BlockID{990, 991, 992, 993, 994, 995, 996, 997}
+	locked := &lockedRegistry{behaviors: make(map[BlockID]BlockBehavior)}
+	for _, id := range ids {
+		Register(id, &simpleBlockBehavior{id: id})
+		locked.behaviors[id] = &simpleBlockBehavior{id: id}
+	}
+
+	b.Run("snapshot", func(b *testing.B) {
+		b.RunParallel(func(pb *testing.PB) {
+			for i := 0; pb.Next(); i++ {
+				if _, ok := Get(ids[i%len(ids)]); !ok {
+					b.Fatal("block not found")
+				}
+			}
+		})
+	})
+	b.Run("rwmutex", func(b *testing.B) {
+		b.RunParallel(func(pb *testing.PB) {
+			for i := 0; pb.Next(); i++ {
+				if _, ok := locked.get(ids[i%len(ids)]); !ok {
+					b.Fatal("block not found")
+				}
+			}
+		})
+	})
+}
@@ -1,7 +1,7 @@
 package world
 
 import (
-	"log"
+	"math/rand"
 
 	"github.com/annel0/mmo-game/internal/vec"
 	"github.com/annel0/mmo-game/internal/world/block"
@@ -90,12 +90,7 @@ func (api *bigChunkBlockAPI) SetBlock(pos vec.Vec2, id block.BlockID) {
 	}
 
 	// Отправляем событие в мировой менеджер
-	select {
-	case api.bigChunk.eventsOut <- event:
-		// Успешно отправлено
-	default:
-		// Канал переполнен, пропускаем
-	}
+	api.bigChunk.sendToWorld(event)
 }
 
 // GetBlockMetadata возвращает метаданные блока по ключу
@@ -160,13 +155,7 @@ func (api *bigChunkBlockAPI) SetBlockMetadata(pos vec.Vec2, key string, value in
 	}
 
 	// Отправляем событие в мировой менеджер
-	select {
-	case api.bigChunk.eventsOut <- event:
-		// Успешно отправлено
-	default:
-		// Канал переполнен, логируем
-		log.Printf("Канал событий переполнен, событие обновления метаданных блока отброшено")
-	}
+	api.bigChunk.sendToWorld(event)
 }
 
 // SendEvent отправляет событие блока
@@ -183,20 +172,10 @@ func (api *bigChunkBlockAPI) SendEvent(eventType EventType, data interface{}, ta
 	// Проверяем, находится ли целевой блок в этом же BigChunk
 	if targetPos.ToBigChunkCoords() == api.bigChunk.coords {
 		// Отправляем событие внутри этого же BigChunk
-		select {
-		case api.bigChunk.eventsIn <- event:
-			// Успешно отправлено
-		default:
-			// Канал переполнен, пропускаем
-		}
+		api.bigChunk.sendToSelf(event)
 	} else {
 		// Отправляем событие через WorldManager
-		select {
-		case api.bigChunk.eventsOut <- event:
-			// Успешно отправлено
-		default:
-			// Канал переполнен, пропускаем
-		}
+		api.bigChunk.sendToWorld(event)
 	}
 }
 
@@ -229,13 +208,7 @@ func (api *bigChunkBlockAPI) SetBlockLayer(layer uint8, pos vec.Vec2, id block.B
 	}
 
 	// Отправляем событие в мировой менеджер
-	select {
-	case api.bigChunk.eventsOut <- event:
-		// Успешно отправлено
-	default:
-		// Канал переполнен, пропускаем
-		log.Printf("Канал событий переполнен, событие установки блока на слое %d отброшено", layer)
-	}
+	api.bigChunk.sendToWorld(event)
 }
 
 // ScheduleUpdateOnce помечает блок для разового обновления в следующем тике
@@ -280,3 +253,23 @@ func (api *chunkBlockAPI) TriggerNeighborUpdates(pos vec.Vec2) {
 }
 
 // ... другие методы
+
+// Rand возвращает генератор случайных чисел BigChunk
+func (api *bigChunkBlockAPI) Rand() *rand.Rand {
+	return api.bigChunk.rng
+}
+
+// Rand возвращает генератор случайных чисел BigChunk, которому принадлежит чанк
+func (api *chunkBlockAPI) Rand() *rand.Rand {
+	return api.bigChunk.rng
+}
+
+// DeltaTime возвращает dt последнего обработанного тика BigChunk
+func (api *bigChunkBlockAPI) DeltaTime() float64 {
+	return api.bigChunk.deltaTime()
+}
+
+// DeltaTime возвращает dt последнего обработанного тика BigChunk, которому принадлежит чанк
+func (api *chunkBlockAPI) DeltaTime() float64 {
+	return api.bigChunk.deltaTime()
+}
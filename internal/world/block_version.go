@@ -0,0 +1,88 @@
+package world
+
+import (
+	"github.com/annel0/mmo-game/internal/vec"
+)
+
+// setBlockVersionLocked запоминает версию изменённого блока. Вызывается под c.Mu
+func (c *Chunk) setBlockVersionLocked(coord BlockCoord, version uint64) {
+	if c.blockVersions == nil {
+		c.blockVersions = make(map[BlockCoord]uint64)
+	}
+	c.blockVersions[coord] = version
+}
+
+// blockVersionLocked возвращает версию блока. Вызывается под c.Mu
+func (c *Chunk) blockVersionLocked(coord BlockCoord) uint64 {
+	if version, ok := c.blockVersions[coord]; ok {
+		return version
+	}
+	return c.createdVersion
+}
+
+// BlockVersion возвращает версию блока - версию чанка (см. Chunk.Version)
+// после его последнего изменения. Версия блока не больше версии чанка,
+// поэтому клиент может сверять с ней и версию полученного чанка
+func (c *Chunk) BlockVersion(layer BlockLayer, local vec.Vec2) uint64 {
+	c.Mu.RLock()
+	defer c.Mu.RUnlock()
+
+	return c.blockVersionLocked(BlockCoord{Layer: layer, Pos: local})
+}
+
+// CompareAndSetBlockLayer устанавливает блок с метаданными, только если он
+// не изменялся после baseVersion. Метаданные объединяются с текущими, как в
+// WorldManager.SetBlockLayer. Возвращает новую версию блока и true либо
+// текущую версию и false, если блок изменён после baseVersion
+func (c *Chunk) CompareAndSetBlockLayer(layer BlockLayer, local vec.Vec2, b Block, baseVersion uint64) (uint64, bool) {
+	coord := BlockCoord{Layer: layer, Pos: local}
+	if layer >= MaxLayers {
+		return 0, false
+	}
+
+	c.Mu.Lock()
+	defer c.Mu.Unlock()
+
+	if current := c.blockVersionLocked(coord); current > baseVersion {
+		return current, false
+	}
+
+	c.setBlockLayerLocked(layer, local, b.ID)
+	c.setBlockMetadataLayerMapLocked(layer, local, b.Payload)
+	return c.blockVersionLocked(coord), true
+}
+
+// GetBlockLayerVersion возвращает блок на слое вместе с его версией,
+// прочитанные атомарно (см. Chunk.BlockVersion)
+func (wm *WorldManager) GetBlockLayerVersion(pos vec.Vec2, layer BlockLayer) (Block, uint64) {
+	chunk := wm.chunkForBlock(pos)
+	coord := BlockCoord{Layer: layer, Pos: pos.LocalInChunk()}
+
+	chunk.Mu.RLock()
+	defer chunk.Mu.RUnlock()
+
+	b := Block{Payload: make(map[string]interface{})}
+	if layer < MaxLayers {
+		b.ID = chunk.Blocks3D[layer][coord.Pos.X][coord.Pos.Y]
+	}
+	for k, v := range chunk.Metadata3D[coord] {
+		b.Payload[k] = v
+	}
+	return b, chunk.blockVersionLocked(coord)
+}
+
+// GetBlockVersion возвращает версию блока на слое
+func (wm *WorldManager) GetBlockVersion(pos vec.Vec2, layer BlockLayer) uint64 {
+	return wm.chunkForBlock(pos).BlockVersion(layer, pos.LocalInChunk())
+}
+
+// SetBlockLayerIfVersion устанавливает блок на слое, если он не изменялся
+// после baseVersion (см. Chunk.CompareAndSetBlockLayer). Так правки двух
+// игроков, основанные на одной версии блока, не перезаписывают друг друга
+func (wm *WorldManager) SetBlockLayerIfVersion(pos vec.Vec2, layer BlockLayer, b Block, baseVersion uint64) (uint64, bool) {
+	version, ok := wm.chunkForBlock(pos).CompareAndSetBlockLayer(layer, pos.LocalInChunk(), b, baseVersion)
+	if ok {
+		wm.publishBlockWrite(pos, layer, b)
+	}
+	return version, ok
+}
@@ -0,0 +1,50 @@
+package world
+
+import (
+	"testing"
+
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/annel0/mmo-game/internal/world/block"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChunk_BlockVersionTracksChanges(t *testing.T) {
+	chunk := NewChunk(vec.Vec2{X: 0, Y: 0})
+	local := vec.Vec2{X: 3, Y: 4}
+	initial := chunk.BlockVersion(LayerActive, local)
+	assert.Equal(t, chunk.Version, initial, "неизменённый блок имеет версию создания чанка")
+
+	chunk.SetBlockLayer(LayerActive, local, block.StoneBlockID)
+	changed := chunk.BlockVersion(LayerActive, local)
+	assert.Greater(t, changed, initial)
+	assert.Equal(t, chunk.Version, changed)
+
+	// Изменение соседнего блока не меняет версию блока
+	chunk.SetBlockLayer(LayerActive, vec.Vec2{X: 5, Y: 5}, block.StoneBlockID)
+	assert.Equal(t, changed, chunk.BlockVersion(LayerActive, local))
+	assert.Equal(t, initial, chunk.BlockVersion(LayerFloor, local), "версии слоёв независимы")
+}
+
+func TestWorldManager_SetBlockLayerIfVersion(t *testing.T) {
+	wm := NewWorldManager(1)
+	pos := vec.Vec2{X: 7, Y: 9}
+	_, base := wm.GetBlockLayerVersion(pos, LayerActive)
+
+	version, ok := wm.SetBlockLayerIfVersion(pos, LayerActive, Block{ID: block.StoneBlockID, Payload: map[string]interface{}{"owner": "alice"}}, base)
+	require.True(t, ok)
+	assert.Greater(t, version, base)
+
+	// Правка по устаревшей версии не применяется и возвращает текущую версию
+	current, ok := wm.SetBlockLayerIfVersion(pos, LayerActive, Block{ID: block.AirBlockID}, base)
+	assert.False(t, ok)
+	assert.Equal(t, version, current)
+
+	b, got := wm.GetBlockLayerVersion(pos, LayerActive)
+	assert.Equal(t, block.StoneBlockID, b.ID)
+	assert.Equal(t, "alice", b.Payload["owner"])
+	assert.Equal(t, version, got)
+
+	_, ok = wm.SetBlockLayerIfVersion(pos, LayerActive, Block{ID: block.AirBlockID}, current)
+	assert.True(t, ok)
+}
@@ -0,0 +1,91 @@
+package world
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/annel0/mmo-game/internal/storage_interface"
+	"github.com/annel0/mmo-game/internal/vec"
+)
+
+// ErrInvalidWorldBorder - граница мира с неположительным радиусом или
+// отрицательной зоной предупреждения
+var ErrInvalidWorldBorder = errors.New("недопустимая граница мира")
+
+// borderDistance возвращает, сколько блоков осталось от позиции до края
+// границы: 0 - позиция на краю, отрицательное значение - за границей
+func borderDistance(b storage_interface.WorldBorder, pos vec.Vec2) int {
+	dx, dy := pos.X-b.Center.X, pos.Y-b.Center.Y
+	return b.Radius - max(dx, -dx, dy, -dy)
+}
+
+// SetWorldBorder задаёт границу мира и сохраняет её в метаданных мира.
+// nil снимает границу
+func (wm *WorldManager) SetWorldBorder(border *storage_interface.WorldBorder) error {
+	if border != nil {
+		if border.Radius <= 0 || border.Warning < 0 {
+			return fmt.Errorf("%w: радиус %d, зона предупреждения %d", ErrInvalidWorldBorder, border.Radius, border.Warning)
+		}
+		copied := *border
+		border = &copied
+	}
+
+	// spawnMu упорядочивает только сохранение метаданных: проверки границы
+	// читают её атомарно и не ждут поиска точки спавна
+	wm.spawnMu.Lock()
+	defer wm.spawnMu.Unlock()
+
+	wm.border.Store(border)
+	return wm.saveWorldMetaLocked()
+}
+
+// WorldBorder возвращает границу мира (false - мир бесконечен)
+func (wm *WorldManager) WorldBorder() (storage_interface.WorldBorder, bool) {
+	border := wm.border.Load()
+	if border == nil {
+		return storage_interface.WorldBorder{}, false
+	}
+	return *border, true
+}
+
+// InsideWorldBorder сообщает, что блок находится в пределах границы мира
+// (в мире без границы - всегда)
+func (wm *WorldManager) InsideWorldBorder(pos vec.Vec2) bool {
+	border, ok := wm.WorldBorder()
+	return !ok || borderDistance(border, pos) >= 0
+}
+
+// BorderAllowsMove сообщает, разрешён ли шаг from -> to: внутри границы - да,
+// за границей - только если шаг приближает к ней. Так игрок, оказавшийся
+// снаружи (граница сдвинулась, пока он был вне сети), может вернуться в мир
+func (wm *WorldManager) BorderAllowsMove(from, to vec.Vec2) bool {
+	border, ok := wm.WorldBorder()
+	if !ok {
+		return true
+	}
+	distance := borderDistance(border, to)
+	return distance >= 0 || distance > borderDistance(border, from)
+}
+
+// ClampToWorldBorder возвращает ближайший к pos блок в пределах границы мира
+func (wm *WorldManager) ClampToWorldBorder(pos vec.Vec2) vec.Vec2 {
+	border, ok := wm.WorldBorder()
+	if !ok {
+		return pos
+	}
+	return vec.Vec2{
+		X: min(max(pos.X, border.Center.X-border.Radius), border.Center.X+border.Radius),
+		Y: min(max(pos.Y, border.Center.Y-border.Radius), border.Center.Y+border.Radius),
+	}
+}
+
+// NearWorldBorder сообщает, что блок внутри границы, но в зоне
+// предупреждения у её края
+func (wm *WorldManager) NearWorldBorder(pos vec.Vec2) bool {
+	border, ok := wm.WorldBorder()
+	if !ok {
+		return false
+	}
+	distance := borderDistance(border, pos)
+	return distance >= 0 && distance < border.Warning
+}
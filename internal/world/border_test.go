@@ -0,0 +1,60 @@
+package world
+
+import (
+	"testing"
+
+	"github.com/annel0/mmo-game/internal/storage_interface"
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorldBorder_PersistedInWorldMeta(t *testing.T) {
+	store := &memoryWorldMeta{}
+
+	wm := NewWorldManager(7)
+	defer wm.cancelFunc()
+	require.NoError(t, wm.SetWorldMetaStore(store))
+
+	// Без границы мир бесконечен
+	assert.True(t, wm.InsideWorldBorder(vec.Vec2{X: 1 << 20, Y: -1 << 20}))
+	assert.False(t, wm.NearWorldBorder(vec.Vec2{}))
+
+	assert.ErrorIs(t, wm.SetWorldBorder(&storage_interface.WorldBorder{Radius: 0}), ErrInvalidWorldBorder)
+	assert.ErrorIs(t, wm.SetWorldBorder(&storage_interface.WorldBorder{Radius: 10, Warning: -1}), ErrInvalidWorldBorder)
+
+	border := storage_interface.WorldBorder{Center: vec.Vec2{X: 100, Y: -50}, Radius: 10, Warning: 3}
+	require.NoError(t, wm.SetWorldBorder(&border))
+
+	// Граница - квадрат, край входит в мир
+	assert.True(t, wm.InsideWorldBorder(vec.Vec2{X: 110, Y: -60}))
+	assert.True(t, wm.InsideWorldBorder(vec.Vec2{X: 90, Y: -45}))
+	assert.False(t, wm.InsideWorldBorder(vec.Vec2{X: 111, Y: -50}))
+	assert.False(t, wm.InsideWorldBorder(vec.Vec2{X: 100, Y: -61}))
+
+	// Зона предупреждения - последние Warning блоков перед краем
+	assert.False(t, wm.NearWorldBorder(vec.Vec2{X: 107, Y: -50}))
+	assert.True(t, wm.NearWorldBorder(vec.Vec2{X: 108, Y: -50}))
+	assert.True(t, wm.NearWorldBorder(vec.Vec2{X: 110, Y: -50}))
+	assert.False(t, wm.NearWorldBorder(vec.Vec2{X: 111, Y: -50}), "за границей - уже не предупреждение")
+
+	// Снаружи можно только приближаться к границе
+	assert.True(t, wm.BorderAllowsMove(vec.Vec2{X: 115, Y: -50}, vec.Vec2{X: 114, Y: -50}))
+	assert.False(t, wm.BorderAllowsMove(vec.Vec2{X: 115, Y: -50}, vec.Vec2{X: 116, Y: -50}))
+	assert.False(t, wm.BorderAllowsMove(vec.Vec2{X: 110, Y: -50}, vec.Vec2{X: 111, Y: -50}))
+	assert.Equal(t, vec.Vec2{X: 110, Y: -60}, wm.ClampToWorldBorder(vec.Vec2{X: 500, Y: -500}))
+	assert.Equal(t, vec.Vec2{X: 105, Y: -50}, wm.ClampToWorldBorder(vec.Vec2{X: 105, Y: -50}))
+
+	// Граница восстанавливается при загрузке мира
+	reloaded := NewWorldManager(7)
+	defer reloaded.cancelFunc()
+	require.NoError(t, reloaded.SetWorldMetaStore(store))
+	restored, ok := reloaded.WorldBorder()
+	require.True(t, ok)
+	assert.Equal(t, border, restored)
+
+	require.NoError(t, reloaded.SetWorldBorder(nil))
+	assert.Nil(t, store.meta.Border)
+	_, ok = reloaded.WorldBorder()
+	assert.False(t, ok)
+}
@@ -1,6 +1,8 @@
 package world
 
 import (
+	"maps"
+	"slices"
 	"sync"
 
 	"github.com/annel0/mmo-game/internal/vec"
@@ -21,17 +23,31 @@ type Chunk struct {
 
 	ChangeCounter int          // Счетчик изменений
 	Mu            sync.RWMutex // Мьютекс для безопасного доступа
+
+	// Version растёт при каждом изменении блока и не сбрасывается при
+	// сохранении; история последних изменений хранится для ChunkDiffSince
+	Version   uint64
+	changeLog chunkChangeLog
+
+	// Версии изменённых блоков (см. BlockVersion); у остальных блоков
+	// версия равна версии, с которой создан чанк
+	blockVersions  map[BlockCoord]uint64
+	createdVersion uint64
 }
 
 // NewChunk создаёт новый чанк с указанными координатами
 func NewChunk(coords vec.Vec2) *Chunk {
+	version := nextChunkVersion()
 	return &Chunk{
-		Coords:        coords,
-		Metadata3D:    make(map[BlockCoord]map[string]interface{}),
-		Changes3D:     make(map[BlockCoord]struct{}),
-		Tickable3D:    make(map[BlockCoord]struct{}),
-		Mu:            sync.RWMutex{},
-		ChangeCounter: 0,
+		Coords:         coords,
+		Metadata3D:     make(map[BlockCoord]map[string]interface{}),
+		Changes3D:      make(map[BlockCoord]struct{}),
+		Tickable3D:     make(map[BlockCoord]struct{}),
+		Mu:             sync.RWMutex{},
+		ChangeCounter:  0,
+		Version:        version,
+		changeLog:      chunkChangeLog{floor: version},
+		createdVersion: version,
 	}
 }
 
@@ -42,9 +58,7 @@ func (c *Chunk) SetBlockMetadata(local vec.Vec2, key string, value interface{})
 
 // SetBlockMetadataMap устанавливает несколько метаданных для блока (слой ACTIVE)
 func (c *Chunk) SetBlockMetadataMap(local vec.Vec2, metadata map[string]interface{}) {
-	for key, value := range metadata {
-		c.SetBlockMetadataLayer(LayerActive, local, key, value)
-	}
+	c.SetBlockMetadataLayerMap(LayerActive, local, metadata)
 }
 
 // GetBlockMetadata возвращает метаданные для блока (слой ACTIVE)
@@ -88,8 +102,7 @@ func (c *Chunk) SetBlockWithBehavior(local vec.Vec2, behavior block.BlockBehavio
 
 	// Обновляем блок
 	c.Blocks3D[LayerActive][local.X][local.Y] = behavior.ID()
-	c.Changes3D[BlockCoord{Layer: LayerActive, Pos: local}] = struct{}{}
-	c.ChangeCounter++
+	c.markChangedLocked(BlockCoord{Layer: LayerActive, Pos: local})
 
 	// Обновляем тикаемые блоки
 	if behavior.NeedsTick() {
@@ -146,9 +159,13 @@ func (c *Chunk) SetBlockLayer(layer BlockLayer, local vec.Vec2, blockID block.Bl
 	c.Mu.Lock()
 	defer c.Mu.Unlock()
 
+	c.setBlockLayerLocked(layer, local, blockID)
+}
+
+// setBlockLayerLocked устанавливает блок на слое. Вызывается под c.Mu
+func (c *Chunk) setBlockLayerLocked(layer BlockLayer, local vec.Vec2, blockID block.BlockID) {
 	c.Blocks3D[layer][local.X][local.Y] = blockID
-	c.Changes3D[BlockCoord{Layer: layer, Pos: local}] = struct{}{}
-	c.ChangeCounter++
+	c.markChangedLocked(BlockCoord{Layer: layer, Pos: local})
 
 	// Обновляем тикаемые блоки для активного слоя
 	if layer == LayerActive {
@@ -162,18 +179,50 @@ func (c *Chunk) SetBlockLayer(layer BlockLayer, local vec.Vec2, blockID block.Bl
 }
 
 // SetBlockMetadataLayer устанавливает метаданные блока в заданном слое.
-func (c *Chunk) SetBlockMetadataLayer(layer BlockLayer, local vec.Vec2, key string, value interface{}) {
+// Запись, с которой метаданные блока превысили бы MaxBlockMetadataBytes,
+// отклоняется; возвращает false в этом случае.
+func (c *Chunk) SetBlockMetadataLayer(layer BlockLayer, local vec.Vec2, key string, value interface{}) bool {
+	c.Mu.Lock()
+	defer c.Mu.Unlock()
+
+	return c.setBlockMetadataLayerLocked(layer, local, key, value)
+}
+
+// setBlockMetadataLayerLocked - SetBlockMetadataLayer под c.Mu
+func (c *Chunk) setBlockMetadataLayerLocked(layer BlockLayer, local vec.Vec2, key string, value interface{}) bool {
 	coord := BlockCoord{Layer: layer, Pos: local}
 
+	meta, exists := c.Metadata3D[coord]
+	if !exists {
+		meta = make(map[string]interface{})
+	}
+	if size, fits := metadataWriteFits(meta, key, value); !fits {
+		rejectOversizedMetadata(coord, c, key, size)
+		return false
+	}
+
+	meta[key] = value
+	c.Metadata3D[coord] = meta
+	c.markChangedLocked(coord)
+	return true
+}
+
+// SetBlockMetadataLayerMap устанавливает несколько метаданных блока на слое.
+// Ключи записываются по возрастанию: если предел размера метаданных (см.
+// metadata_budget.go) не вмещает все значения, на любом узле отклоняются одни
+// и те же ключи
+func (c *Chunk) SetBlockMetadataLayerMap(layer BlockLayer, local vec.Vec2, metadata map[string]interface{}) {
 	c.Mu.Lock()
 	defer c.Mu.Unlock()
 
-	if _, exists := c.Metadata3D[coord]; !exists {
-		c.Metadata3D[coord] = make(map[string]interface{})
+	c.setBlockMetadataLayerMapLocked(layer, local, metadata)
+}
+
+// setBlockMetadataLayerMapLocked - SetBlockMetadataLayerMap под c.Mu
+func (c *Chunk) setBlockMetadataLayerMapLocked(layer BlockLayer, local vec.Vec2, metadata map[string]interface{}) {
+	for _, key := range slices.Sorted(maps.Keys(metadata)) {
+		c.setBlockMetadataLayerLocked(layer, local, key, metadata[key])
 	}
-	c.Metadata3D[coord][key] = value
-	c.Changes3D[coord] = struct{}{}
-	c.ChangeCounter++
 }
 
 // GetBlockMetadataLayer возвращает метаданные блока на указанном слое.
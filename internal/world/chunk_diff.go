@@ -0,0 +1,160 @@
+package world
+
+import (
+	"errors"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/annel0/mmo-game/internal/world/block"
+)
+
+// MaxChunkChangeLog - сколько последних изменений чанка хранится для
+// ChunkDiffSince. Клиенту с более старой версией отправляется весь чанк
+const MaxChunkChangeLog = 256
+
+// ErrChunkDiffUnavailable - дельту с указанной версии построить нельзя:
+// версия старше сохранённой истории, относится к другому экземпляру чанка
+// (выгружен и загружен заново, перезапуск сервера) или из будущего
+var ErrChunkDiffUnavailable = errors.New("дельта чанка недоступна для этой версии")
+
+// chunkVersionClock выдаёт версии чанков. Счётчик общий для всех чанков и
+// начинается с текущего времени, поэтому версия нового экземпляра чанка
+// больше любой версии, выданной до его создания, в том числе до перезапуска
+var chunkVersionClock atomic.Uint64
+
+func init() {
+	chunkVersionClock.Store(uint64(time.Now().UnixNano()))
+}
+
+// nextChunkVersion возвращает следующую версию чанка
+func nextChunkVersion() uint64 {
+	return chunkVersionClock.Add(1)
+}
+
+// chunkChange - запись истории изменений чанка
+type chunkChange struct {
+	version uint64
+	coord   BlockCoord
+}
+
+// chunkChangeLog - ограниченная история изменений чанка. Дельта строится для
+// любой версии от floor до текущей
+type chunkChangeLog struct {
+	floor   uint64
+	entries []chunkChange
+}
+
+// record добавляет изменение; при переполнении отбрасывается старшая половина истории
+func (l *chunkChangeLog) record(version uint64, coord BlockCoord) {
+	if len(l.entries) >= MaxChunkChangeLog {
+		half := len(l.entries) / 2
+		l.floor = l.entries[half-1].version
+		l.entries = append(l.entries[:0], l.entries[half:]...)
+	}
+	l.entries = append(l.entries, chunkChange{version: version, coord: coord})
+}
+
+// markChangedLocked отмечает изменение блока: для сохранения (Changes3D),
+// для дельт клиентам (Version) и для проверки версии блока при его
+// изменении клиентом (BlockVersion). Вызывается под c.Mu
+func (c *Chunk) markChangedLocked(coord BlockCoord) {
+	c.Changes3D[coord] = struct{}{}
+	c.ChangeCounter++
+
+	c.Version = nextChunkVersion()
+	c.changeLog.record(c.Version, coord)
+	c.setBlockVersionLocked(coord, c.Version)
+}
+
+// BlockDiff - текущее состояние блока, изменённого после версии клиента
+type BlockDiff struct {
+	Layer    BlockLayer
+	Pos      vec.Vec2 // Локальные координаты в чанке
+	BlockID  block.BlockID
+	Metadata map[string]interface{} // Копия метаданных; nil, если их нет
+}
+
+// ChunkDiff - изменения чанка между версией клиента и текущей
+type ChunkDiff struct {
+	Coords      vec.Vec2
+	FromVersion uint64    // Версия клиента
+	Version     uint64    // Версия чанка после применения дельты
+	Hash        ChunkHash // Хэш содержимого версии Version (см. Chunk.Hash)
+	Changes     []BlockDiff
+}
+
+// DiffSince возвращает блоки слоёв ClientLayers, изменённые после version,
+// в порядке (слой, y, x). Каждый блок входит один раз с текущим состоянием.
+// Если version совпадает с текущей, список изменений пуст
+func (c *Chunk) DiffSince(version uint64) (*ChunkDiff, error) {
+	c.Mu.RLock()
+	defer c.Mu.RUnlock()
+
+	if version < c.changeLog.floor || version > c.Version {
+		return nil, ErrChunkDiffUnavailable
+	}
+
+	diff := &ChunkDiff{
+		Coords:      c.Coords,
+		FromVersion: version,
+		Version:     c.Version,
+		Hash:        c.hashLocked(),
+	}
+
+	changed := make(map[BlockCoord]struct{})
+	for i := len(c.changeLog.entries) - 1; i >= 0 && c.changeLog.entries[i].version > version; i-- {
+		changed[c.changeLog.entries[i].coord] = struct{}{}
+	}
+
+	for coord := range changed {
+		if !isClientLayer(coord.Layer) {
+			continue
+		}
+		change := BlockDiff{
+			Layer:   coord.Layer,
+			Pos:     coord.Pos,
+			BlockID: c.Blocks3D[coord.Layer][coord.Pos.X][coord.Pos.Y],
+		}
+		if meta, ok := c.Metadata3D[coord]; ok && len(meta) > 0 {
+			change.Metadata = make(map[string]interface{}, len(meta))
+			for k, v := range meta {
+				change.Metadata[k] = v
+			}
+		}
+		diff.Changes = append(diff.Changes, change)
+	}
+
+	sort.Slice(diff.Changes, func(i, j int) bool {
+		a, b := diff.Changes[i], diff.Changes[j]
+		if a.Layer != b.Layer {
+			return a.Layer < b.Layer
+		}
+		if a.Pos.Y != b.Pos.Y {
+			return a.Pos.Y < b.Pos.Y
+		}
+		return a.Pos.X < b.Pos.X
+	})
+	return diff, nil
+}
+
+// isClientLayer сообщает, передаётся ли слой клиентам
+func isClientLayer(layer BlockLayer) bool {
+	for _, clientLayer := range ClientLayers {
+		if layer == clientLayer {
+			return true
+		}
+	}
+	return false
+}
+
+// ChunkDiffSince возвращает изменения чанка после версии клиента (см.
+// Chunk.DiffSince). ErrChunkDiffUnavailable означает, что клиенту нужен весь чанк
+func (wm *WorldManager) ChunkDiffSince(coords vec.Vec2, version uint64) (*ChunkDiff, error) {
+	chunk := wm.GetChunk(coords)
+	if chunk == nil {
+		return nil, ErrChunkDiffUnavailable
+	}
+	return chunk.DiffSince(version)
+}
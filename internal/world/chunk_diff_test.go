@@ -0,0 +1,79 @@
+package world
+
+import (
+	"testing"
+
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/annel0/mmo-game/internal/world/block"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChunkDiffSince_ReturnsOnlyChangedBlocks(t *testing.T) {
+	wm := NewWorldManager(12345)
+	coords := vec.Vec2{X: 2, Y: -1}
+	chunk := wm.GetChunk(coords)
+	version, _ := chunk.VersionedHash()
+
+	// Небольшая правка: два блока, один из них дважды, плюс метаданные
+	chunk.SetBlockLayer(LayerActive, vec.Vec2{X: 4, Y: 5}, block.DirtBlockID)
+	chunk.SetBlockLayer(LayerActive, vec.Vec2{X: 4, Y: 5}, block.StoneBlockID)
+	chunk.SetBlockMetadata(vec.Vec2{X: 4, Y: 5}, "durability", 7)
+	chunk.SetBlockLayer(LayerFloor, vec.Vec2{X: 0, Y: 9}, block.StoneBlockID)
+
+	diff, err := wm.ChunkDiffSince(coords, version)
+	require.NoError(t, err)
+	assert.Equal(t, coords, diff.Coords)
+	assert.Equal(t, version, diff.FromVersion)
+	assert.Greater(t, diff.Version, version)
+	assert.Equal(t, chunk.Hash(), diff.Hash)
+
+	require.Len(t, diff.Changes, 2, "каждый изменённый блок входит один раз")
+	assert.Equal(t, BlockDiff{Layer: LayerFloor, Pos: vec.Vec2{X: 0, Y: 9}, BlockID: block.StoneBlockID}, diff.Changes[0])
+	assert.Equal(t, LayerActive, diff.Changes[1].Layer)
+	assert.Equal(t, vec.Vec2{X: 4, Y: 5}, diff.Changes[1].Pos)
+	assert.Equal(t, block.StoneBlockID, diff.Changes[1].BlockID)
+	assert.Equal(t, 7, diff.Changes[1].Metadata["durability"])
+
+	// Клиент с актуальной версией получает пустую дельту
+	diff, err = wm.ChunkDiffSince(coords, diff.Version)
+	require.NoError(t, err)
+	assert.Empty(t, diff.Changes)
+}
+
+func TestChunkDiffSince_SkipsLayersNotSentToClients(t *testing.T) {
+	chunk := NewChunk(vec.Vec2{})
+	version, _ := chunk.VersionedHash()
+
+	chunk.SetBlockLayer(LayerCeiling, vec.Vec2{X: 1, Y: 1}, block.StoneBlockID)
+
+	diff, err := chunk.DiffSince(version)
+	require.NoError(t, err)
+	assert.Empty(t, diff.Changes)
+	assert.Greater(t, diff.Version, version)
+}
+
+func TestChunkDiffSince_UnavailableVersionsRequireFullChunk(t *testing.T) {
+	chunk := NewChunk(vec.Vec2{})
+	oldVersion, _ := chunk.VersionedHash()
+
+	// Версия из будущего или другого экземпляра чанка
+	_, err := chunk.DiffSince(oldVersion + 1)
+	assert.ErrorIs(t, err, ErrChunkDiffUnavailable)
+	_, err = chunk.DiffSince(oldVersion - 1)
+	assert.ErrorIs(t, err, ErrChunkDiffUnavailable)
+
+	// История ограничена: слишком старая версия больше не поддерживается
+	for i := 0; i <= MaxChunkChangeLog; i++ {
+		chunk.SetBlockMetadata(vec.Vec2{X: 3, Y: 3}, "counter", i)
+	}
+	_, err = chunk.DiffSince(oldVersion)
+	assert.ErrorIs(t, err, ErrChunkDiffUnavailable)
+
+	recent, _ := chunk.VersionedHash()
+	chunk.SetBlockLayer(LayerActive, vec.Vec2{X: 8, Y: 8}, block.StoneBlockID)
+	diff, err := chunk.DiffSince(recent)
+	require.NoError(t, err)
+	require.Len(t, diff.Changes, 1)
+	assert.Equal(t, vec.Vec2{X: 8, Y: 8}, diff.Changes[0].Pos)
+}
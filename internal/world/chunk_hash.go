@@ -0,0 +1,95 @@
+package world
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"sort"
+
+	"github.com/annel0/mmo-game/internal/vec"
+)
+
+// ChunkHash - хэш содержимого чанка (см. Chunk.Hash)
+type ChunkHash [sha256.Size]byte
+
+// ChunkHashVersion - версия алгоритма Chunk.Hash. Увеличивается при любом
+// изменении того, что и в каком порядке входит в хэш, чтобы клиенты и
+// хранилища не сравнивали хэши разных версий
+const ChunkHashVersion uint32 = 1
+
+// ClientLayers - слои чанка, которые передаются клиентам и входят в Chunk.Hash
+var ClientLayers = []BlockLayer{LayerFloor, LayerActive}
+
+// Hash вычисляет канонический хэш содержимого чанка, передаваемого клиентам:
+// блоки слоёв ClientLayers и метаданные блоков слоя ACTIVE. Хэш не зависит от
+// порядка изменений и от числовых типов метаданных (int и float64 после
+// JSON-репликации дают одинаковый хэш), поэтому подходит для проверки
+// "изменился ли чанк" на клиенте, в хранилище и при синхронизации.
+//
+// Формат (версия 1, big-endian): версия uint32; для каждого слоя из ClientLayers -
+// номер слоя uint8 и 256 ID блоков uint16 построчно (y, затем x); затем
+// метаданные ACTIVE по возрастанию (y, x): x uint8, y uint8, длина uint32 и
+// JSON с отсортированными ключами.
+func (c *Chunk) Hash() ChunkHash {
+	c.Mu.RLock()
+	defer c.Mu.RUnlock()
+
+	return c.hashLocked()
+}
+
+// VersionedHash возвращает версию чанка и хэш содержимого этой версии
+func (c *Chunk) VersionedHash() (uint64, ChunkHash) {
+	c.Mu.RLock()
+	defer c.Mu.RUnlock()
+
+	return c.Version, c.hashLocked()
+}
+
+// hashLocked вычисляет Hash. Вызывается под c.Mu
+func (c *Chunk) hashLocked() ChunkHash {
+	h := sha256.New()
+	buf := make([]byte, 4)
+
+	binary.BigEndian.PutUint32(buf, ChunkHashVersion)
+	h.Write(buf)
+
+	for _, layer := range ClientLayers {
+		h.Write([]byte{byte(layer)})
+		for y := 0; y < 16; y++ {
+			for x := 0; x < 16; x++ {
+				binary.BigEndian.PutUint16(buf[:2], uint16(c.Blocks3D[layer][x][y]))
+				h.Write(buf[:2])
+			}
+		}
+	}
+
+	positions := make([]vec.Vec2, 0, len(c.Metadata3D))
+	for coord, metadata := range c.Metadata3D {
+		if coord.Layer == LayerActive && len(metadata) > 0 {
+			positions = append(positions, coord.Pos)
+		}
+	}
+	sort.Slice(positions, func(i, j int) bool {
+		if positions[i].Y != positions[j].Y {
+			return positions[i].Y < positions[j].Y
+		}
+		return positions[i].X < positions[j].X
+	})
+
+	for _, pos := range positions {
+		// encoding/json сортирует ключи map, поэтому представление каноническое.
+		// Значения, не представимые в JSON, клиенту не передаются и в хэш не входят
+		data, err := json.Marshal(c.Metadata3D[BlockCoord{Layer: LayerActive, Pos: pos}])
+		if err != nil {
+			data = nil
+		}
+		h.Write([]byte{byte(pos.X), byte(pos.Y)})
+		binary.BigEndian.PutUint32(buf, uint32(len(data)))
+		h.Write(buf)
+		h.Write(data)
+	}
+
+	var sum ChunkHash
+	copy(sum[:], h.Sum(nil))
+	return sum
+}
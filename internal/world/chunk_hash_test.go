@@ -0,0 +1,65 @@
+package world
+
+import (
+	"testing"
+
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/annel0/mmo-game/internal/world/block"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChunkHash_MetadataChangesAlterHash(t *testing.T) {
+	chunk := NewChunk(vec.Vec2{})
+	chunk.SetBlockLayer(LayerActive, vec.Vec2{X: 3, Y: 4}, block.StoneBlockID)
+	before := chunk.Hash()
+
+	chunk.SetBlockMetadata(vec.Vec2{X: 3, Y: 4}, "durability", 5)
+	withMeta := chunk.Hash()
+	assert.NotEqual(t, before, withMeta, "изменение только метаданных меняет хэш")
+
+	chunk.SetBlockMetadata(vec.Vec2{X: 3, Y: 4}, "durability", 4)
+	assert.NotEqual(t, withMeta, chunk.Hash())
+
+	// Слой FLOOR тоже передаётся клиентам
+	floorBefore := chunk.Hash()
+	chunk.SetBlockLayer(LayerFloor, vec.Vec2{X: 0, Y: 0}, block.StoneBlockID)
+	assert.NotEqual(t, floorBefore, chunk.Hash())
+}
+
+func TestChunkHash_IndependentOfChangeOrderAndNumericTypes(t *testing.T) {
+	positions := []vec.Vec2{{X: 0, Y: 0}, {X: 15, Y: 1}, {X: 7, Y: 7}, {X: 1, Y: 15}}
+
+	forward := NewChunk(vec.Vec2{})
+	for i, pos := range positions {
+		forward.SetBlockLayer(LayerActive, pos, block.StoneBlockID)
+		forward.SetBlockMetadata(pos, "durability", i)
+		forward.SetBlockMetadata(pos, "owner", "alice")
+	}
+
+	backward := NewChunk(vec.Vec2{X: 9, Y: 9})
+	for i := len(positions) - 1; i >= 0; i-- {
+		backward.SetBlockMetadata(positions[i], "owner", "alice")
+		backward.SetBlockMetadata(positions[i], "durability", float64(i)) // Как после JSON-репликации
+		backward.SetBlockLayer(LayerActive, positions[i], block.StoneBlockID)
+	}
+
+	assert.Equal(t, forward.Hash(), backward.Hash())
+	assert.Equal(t, uint32(1), ChunkHashVersion)
+}
+
+func TestChunkHash_UsedByWorldHashes(t *testing.T) {
+	wm := NewWorldManager(1)
+	defer wm.cancelFunc()
+	pos := vec.Vec2{X: 3, Y: 4}
+	wm.SetBlockLayer(pos, LayerActive, NewBlock(block.StoneBlockID))
+	before, loaded := wm.LoadedChunkHash(pos.ToChunkCoords())
+	assert.True(t, loaded)
+
+	// Проверка целостности и синхронизация видят изменение метаданных
+	wm.SetBlockMetadataValue(pos, "durability", 5)
+	chunk := wm.GetChunk(pos.ToChunkCoords())
+	after, _ := wm.LoadedChunkHash(pos.ToChunkCoords())
+	assert.NotEqual(t, before, after)
+	assert.Equal(t, chunk.Hash(), after)
+	assert.Equal(t, chunk.Hash(), wm.ChunkHashes()[pos.ToChunkCoords()])
+}
@@ -0,0 +1,101 @@
+package world
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/annel0/mmo-game/internal/storage_interface"
+	"github.com/annel0/mmo-game/internal/vec"
+)
+
+// MaxClaimChunks - предел площади одного привата, чанков
+const MaxClaimChunks = 4096
+
+var (
+	// ErrInvalidClaim - приват слишком большой
+	ErrInvalidClaim = errors.New("недопустимый приват")
+	// ErrClaimOverlap - приват пересекается с существующим
+	ErrClaimOverlap = errors.New("приват пересекается с существующим")
+	// ErrClaimNotFound - привата с таким ID нет
+	ErrClaimNotFound = errors.New("приват не найден")
+)
+
+// claimContains сообщает, входит ли чанк в приват
+func claimContains(c storage_interface.Claim, chunk vec.Vec2) bool {
+	return chunk.X >= c.From.X && chunk.X <= c.To.X && chunk.Y >= c.From.Y && chunk.Y <= c.To.Y
+}
+
+// claimsOverlap сообщает, пересекаются ли приваты
+func claimsOverlap(a, b storage_interface.Claim) bool {
+	return a.From.X <= b.To.X && b.From.X <= a.To.X && a.From.Y <= b.To.Y && b.From.Y <= a.To.Y
+}
+
+// AddClaim создаёт приват на прямоугольник чанков между from и to
+// (включительно, в любом порядке углов) и сохраняет его в метаданных мира.
+// ownerID 0 - изменять блоки могут только администраторы
+func (wm *WorldManager) AddClaim(ownerID uint64, name string, from, to vec.Vec2) (storage_interface.Claim, error) {
+	claim := storage_interface.Claim{
+		OwnerID: ownerID,
+		Name:    name,
+		From:    vec.Vec2{X: min(from.X, to.X), Y: min(from.Y, to.Y)},
+		To:      vec.Vec2{X: max(from.X, to.X), Y: max(from.Y, to.Y)},
+	}
+	width, height := claim.To.X-claim.From.X+1, claim.To.Y-claim.From.Y+1
+	if width > MaxClaimChunks || height > MaxClaimChunks || width*height > MaxClaimChunks {
+		return storage_interface.Claim{}, fmt.Errorf("%w: %dx%d чанков, допустимо не больше %d", ErrInvalidClaim, width, height, MaxClaimChunks)
+	}
+
+	wm.spawnMu.Lock()
+	defer wm.spawnMu.Unlock()
+
+	for _, existing := range wm.claims {
+		if claimsOverlap(claim, existing) {
+			return storage_interface.Claim{}, fmt.Errorf("%w: %d", ErrClaimOverlap, existing.ID)
+		}
+		claim.ID = max(claim.ID, existing.ID)
+	}
+	claim.ID++
+
+	// Срез не изменяется на месте: он мог быть передан хранилищу метаданных
+	wm.claims = append(append([]storage_interface.Claim(nil), wm.claims...), claim)
+	return claim, wm.saveWorldMetaLocked()
+}
+
+// RemoveClaim удаляет приват и сохраняет метаданные мира
+func (wm *WorldManager) RemoveClaim(id uint64) error {
+	wm.spawnMu.Lock()
+	defer wm.spawnMu.Unlock()
+
+	for i, claim := range wm.claims {
+		if claim.ID != id {
+			continue
+		}
+		claims := make([]storage_interface.Claim, 0, len(wm.claims)-1)
+		wm.claims = append(append(claims, wm.claims[:i]...), wm.claims[i+1:]...)
+		return wm.saveWorldMetaLocked()
+	}
+	return fmt.Errorf("%w: %d", ErrClaimNotFound, id)
+}
+
+// Claims возвращает приваты мира по возрастанию ID
+func (wm *WorldManager) Claims() []storage_interface.Claim {
+	wm.spawnMu.Lock()
+	defer wm.spawnMu.Unlock()
+
+	return append([]storage_interface.Claim(nil), wm.claims...)
+}
+
+// ClaimAt возвращает приват, в который входит блок
+func (wm *WorldManager) ClaimAt(pos vec.Vec2) (storage_interface.Claim, bool) {
+	chunk := pos.ToChunkCoords()
+
+	wm.spawnMu.Lock()
+	defer wm.spawnMu.Unlock()
+
+	for _, claim := range wm.claims {
+		if claimContains(claim, chunk) {
+			return claim, true
+		}
+	}
+	return storage_interface.Claim{}, false
+}
@@ -0,0 +1,49 @@
+package world
+
+import (
+	"testing"
+
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClaims_PersistedInWorldMeta(t *testing.T) {
+	store := &memoryWorldMeta{}
+
+	wm := NewWorldManager(7)
+	defer wm.cancelFunc()
+	require.NoError(t, wm.SetWorldMetaStore(store))
+
+	// Углы можно задать в любом порядке
+	home, err := wm.AddClaim(42, "Дом", vec.Vec2{X: 2, Y: 1}, vec.Vec2{X: 0, Y: -1})
+	require.NoError(t, err)
+	assert.Equal(t, vec.Vec2{X: 0, Y: -1}, home.From)
+	assert.Equal(t, vec.Vec2{X: 2, Y: 1}, home.To)
+	spawn, err := wm.AddClaim(0, "Спавн", vec.Vec2{X: 10, Y: 10}, vec.Vec2{X: 11, Y: 11})
+	require.NoError(t, err)
+	assert.Greater(t, spawn.ID, home.ID)
+
+	_, err = wm.AddClaim(7, "", vec.Vec2{X: 2, Y: 1}, vec.Vec2{X: 3, Y: 3})
+	assert.ErrorIs(t, err, ErrClaimOverlap)
+	_, err = wm.AddClaim(7, "", vec.Vec2{X: 100, Y: 100}, vec.Vec2{X: 100 + MaxClaimChunks, Y: 100})
+	assert.ErrorIs(t, err, ErrInvalidClaim)
+
+	// Приват определяется по чанку блока
+	claim, ok := wm.ClaimAt(vec.Vec2{X: 5, Y: -3})
+	require.True(t, ok)
+	assert.Equal(t, home.ID, claim.ID)
+	_, ok = wm.ClaimAt(vec.Vec2{X: -5, Y: 5})
+	assert.False(t, ok)
+
+	// Приваты восстанавливаются при загрузке мира
+	reloaded := NewWorldManager(7)
+	defer reloaded.cancelFunc()
+	require.NoError(t, reloaded.SetWorldMetaStore(store))
+	assert.Equal(t, wm.Claims(), reloaded.Claims())
+
+	require.NoError(t, reloaded.RemoveClaim(home.ID))
+	assert.ErrorIs(t, reloaded.RemoveClaim(home.ID), ErrClaimNotFound)
+	require.Len(t, store.meta.Claims, 1)
+	assert.Equal(t, spawn.ID, store.meta.Claims[0].ID)
+}
@@ -3,7 +3,6 @@ package entity
 import (
 	"math"
 	"math/rand"
-	"time"
 
 	"github.com/annel0/mmo-game/internal/vec"
 	"github.com/annel0/mmo-game/internal/world/block"
@@ -66,8 +65,10 @@ func NewAnimalBehavior(animalType AnimalType) *AnimalBehavior {
 
 // Update обновляет состояние животного
 func (ab *AnimalBehavior) Update(api EntityAPI, entity *Entity, dt float64) {
+	rng := behaviorRand(api)
+
 	// Инициализация данных животного, если нужно
-	ab.initAnimalData(entity)
+	ab.initAnimalData(rng, entity)
 
 	// Обновляем голод
 	hunger := entity.Payload["hunger"].(int)
@@ -87,7 +88,7 @@ func (ab *AnimalBehavior) Update(api EntityAPI, entity *Entity, dt float64) {
 	} else {
 		// Обновляем таймер действия для остальных животных
 		if actionTimer <= 0 {
-			ab.updateBaseState(entity, state, &actionTimer)
+			ab.updateBaseState(rng, entity, state, &actionTimer)
 		} else {
 			entity.Payload["actionTimer"] = actionTimer
 			ab.processState(api, entity, state, dt)
@@ -97,6 +98,8 @@ func (ab *AnimalBehavior) Update(api EntityAPI, entity *Entity, dt float64) {
 
 // updateCowState обновляет состояние коровы
 func (ab *AnimalBehavior) updateCowState(api EntityAPI, entity *Entity, dt float64, state string, actionTimer *float64) {
+	rng := behaviorRand(api)
+
 	// Проверяем, голодна ли корова (голод > 40%)
 	hunger := entity.Payload["hunger"].(int)
 
@@ -118,7 +121,7 @@ func (ab *AnimalBehavior) updateCowState(api EntityAPI, entity *Entity, dt float
 		case "eating":
 			// Корова закончила есть, переходим в состояние покоя
 			entity.Payload["state"] = "idle"
-			*actionTimer = ab.getRandomInRange(ab.idleTimeRange)
+			*actionTimer = ab.getRandomInRange(rng, ab.idleTimeRange)
 
 			// Уменьшаем голод
 			hunger = hunger - 30*100 // Уменьшаем голод на 30% от максимума
@@ -129,10 +132,10 @@ func (ab *AnimalBehavior) updateCowState(api EntityAPI, entity *Entity, dt float
 		case "moving_to_grass":
 			// Не достигли травы за отведенное время, переходим к блужданию
 			entity.Payload["state"] = "idle"
-			*actionTimer = ab.getRandomInRange(ab.idleTimeRange)
+			*actionTimer = ab.getRandomInRange(rng, ab.idleTimeRange)
 		default:
 			// Обычное обновление состояния
-			ab.updateBaseState(entity, state, actionTimer)
+			ab.updateBaseState(rng, entity, state, actionTimer)
 		}
 	} else {
 		// Продолжаем текущее действие
@@ -174,7 +177,7 @@ func (ab *AnimalBehavior) updateCowState(api EntityAPI, entity *Entity, dt float
 				} else {
 					// Трава уже исчезла, переходим в состояние покоя
 					entity.Payload["state"] = "idle"
-					entity.Payload["actionTimer"] = ab.getRandomInRange(ab.idleTimeRange)
+					entity.Payload["actionTimer"] = ab.getRandomInRange(rng, ab.idleTimeRange)
 				}
 			} else {
 				// Продолжаем движение к траве
@@ -227,13 +230,13 @@ func (ab *AnimalBehavior) findNearbyGrass(api EntityAPI, entity *Entity) vec.Vec
 }
 
 // updateBaseState обновляет базовое состояние животного
-func (ab *AnimalBehavior) updateBaseState(entity *Entity, state string, actionTimer *float64) {
+func (ab *AnimalBehavior) updateBaseState(rng *rand.Rand, entity *Entity, state string, actionTimer *float64) {
 	// Время действия истекло, меняем состояние
 	switch state {
 	case "idle":
 		// Переходим в состояние движения
 		entity.Payload["state"] = "moving"
-		*actionTimer = ab.getRandomInRange(ab.moveTimeRange)
+		*actionTimer = ab.getRandomInRange(rng, ab.moveTimeRange)
 
 		// Выбираем случайную точку для движения
 		homePos, ok := entity.Payload["homePosition"].(vec.Vec2)
@@ -242,17 +245,19 @@ func (ab *AnimalBehavior) updateBaseState(entity *Entity, state string, actionTi
 			entity.Payload["homePosition"] = homePos
 		}
 
-		targetPos := ab.getRandomPositionInRadius(homePos, ab.wanderRadius)
+		targetPos := ab.getRandomPositionInRadius(rng, homePos, ab.wanderRadius)
 		entity.Payload["targetPosition"] = targetPos
 	case "moving":
 		// Переходим в состояние покоя
 		entity.Payload["state"] = "idle"
-		*actionTimer = ab.getRandomInRange(ab.idleTimeRange)
+		*actionTimer = ab.getRandomInRange(rng, ab.idleTimeRange)
 	}
 }
 
 // processState обрабатывает текущее состояние животного
 func (ab *AnimalBehavior) processState(api EntityAPI, entity *Entity, state string, dt float64) {
+	rng := behaviorRand(api)
+
 	switch state {
 	case "idle":
 		// В состоянии покоя просто стоим
@@ -272,7 +277,7 @@ func (ab *AnimalBehavior) processState(api EntityAPI, entity *Entity, state stri
 		if entity.PrecisePos.DistanceTo(targetPosFloat) < 0.5 {
 			// Цель достигнута, переходим в состояние покоя
 			entity.Payload["state"] = "idle"
-			entity.Payload["actionTimer"] = ab.getRandomInRange(ab.idleTimeRange)
+			entity.Payload["actionTimer"] = ab.getRandomInRange(rng, ab.idleTimeRange)
 			entity.Velocity = vec.Vec2Float{X: 0, Y: 0}
 		} else {
 			// Продолжаем движение
@@ -287,20 +292,22 @@ func (ab *AnimalBehavior) processState(api EntityAPI, entity *Entity, state stri
 // OnSpawn вызывается при создании животного
 func (ab *AnimalBehavior) OnSpawn(api EntityAPI, entity *Entity) {
 	// Инициализация данных животного
-	ab.initAnimalData(entity)
+	ab.initAnimalData(behaviorRand(api), entity)
 }
 
-// initAnimalData инициализирует данные животного, если нужно
-func (ab *AnimalBehavior) initAnimalData(entity *Entity) {
-	if entity.Payload["animalType"] == nil {
+// initAnimalData инициализирует данные животного, если нужно. SpawnAnimal
+// заранее записывает только animalType, поэтому признак - отсутствие состояния
+func (ab *AnimalBehavior) initAnimalData(rng *rand.Rand, entity *Entity) {
+	if entity.Payload["state"] == nil {
 		entity.Payload["animalType"] = int(ab.animalType)
 		entity.Payload["health"] = ab.maxHealth
+		entity.Payload["max_health"] = ab.maxHealth
 		entity.Payload["state"] = "idle"
-		entity.Payload["actionTimer"] = ab.getRandomInRange(ab.idleTimeRange)
+		entity.Payload["actionTimer"] = ab.getRandomInRange(rng, ab.idleTimeRange)
 		entity.Payload["homePosition"] = entity.Position
 		entity.Payload["hunger"] = 0 // Начальный голод
 		entity.Payload["lastEatTime"] = 0.0
-		entity.Payload["randomSeed"] = time.Now().UnixNano()
+		entity.Payload["randomSeed"] = rng.Int63()
 	}
 }
 
@@ -340,7 +347,7 @@ func (ab *AnimalBehavior) OnCollision(api EntityAPI, entity *Entity, other inter
 	if entity.Payload["state"] == "moving" {
 		// При столкновении во время движения меняем направление
 		entity.Payload["state"] = "idle"
-		entity.Payload["actionTimer"] = ab.getRandomInRange(ab.idleTimeRange)
+		entity.Payload["actionTimer"] = ab.getRandomInRange(behaviorRand(api), ab.idleTimeRange)
 	}
 }
 
@@ -352,16 +359,16 @@ func (ab *AnimalBehavior) GetMoveSpeed() float64 {
 // Вспомогательные функции
 
 // getRandomInRange возвращает случайное число в указанном диапазоне
-func (ab *AnimalBehavior) getRandomInRange(r [2]float64) float64 {
-	return r[0] + rand.Float64()*(r[1]-r[0])
+func (ab *AnimalBehavior) getRandomInRange(rng *rand.Rand, r [2]float64) float64 {
+	return r[0] + rng.Float64()*(r[1]-r[0])
 }
 
 // getRandomPositionInRadius возвращает случайную позицию в указанном радиусе от центра
-func (ab *AnimalBehavior) getRandomPositionInRadius(center vec.Vec2, radius float64) vec.Vec2 {
+func (ab *AnimalBehavior) getRandomPositionInRadius(rng *rand.Rand, center vec.Vec2, radius float64) vec.Vec2 {
 	// Случайный угол
-	angle := rand.Float64() * 2 * math.Pi
+	angle := rng.Float64() * 2 * math.Pi
 	// Случайное расстояние (корень для равномерного распределения по площади)
-	distance := radius * math.Sqrt(rand.Float64())
+	distance := radius * math.Sqrt(rng.Float64())
 
 	// Преобразуем в координаты
 	x := float64(center.X) + distance*math.Cos(angle)
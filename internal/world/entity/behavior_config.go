@@ -0,0 +1,171 @@
+package entity
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// BehaviorConfig - параметры поведения типа сущности из JSON-файла
+// (assets/entities/*.json). Реализация поведения остаётся в Go и выбирается
+// по Type и Subtype; нулевые параметры оставляют значения реализации:
+//
+//	{"type": "npc", "subtype": "guard", "move_speed": 4.5, "aggro_radius": 14, "health": 80, "damage": 12}
+type BehaviorConfig struct {
+	Type    string `json:"type"`              // player, npc, animal
+	Subtype string `json:"subtype,omitempty"` // Подтип NPC (villager, trader, guard) или животного (cow, sheep, ...)
+
+	MoveSpeed   float64 `json:"move_speed,omitempty"`   // Блоков в секунду
+	AggroRadius float64 `json:"aggro_radius,omitempty"` // Радиус обнаружения игроков, блоков (NPC и животные)
+	Health      int     `json:"health,omitempty"`       // Максимальное здоровье
+	Damage      int     `json:"damage,omitempty"`       // Урон атаки (игрок и NPC)
+}
+
+var (
+	behaviorTypes = map[string]EntityType{
+		"player": EntityTypePlayer,
+		"npc":    EntityTypeNPC,
+		"animal": EntityTypeAnimal,
+	}
+
+	npcSubtypes = map[string]bool{"villager": true, "trader": true, "guard": true}
+
+	animalSubtypes = map[string]AnimalType{
+		"cow":     AnimalTypeCow,
+		"sheep":   AnimalTypeSheep,
+		"chicken": AnimalTypeChicken,
+		"pig":     AnimalTypePig,
+		"horse":   AnimalTypeHorse,
+	}
+)
+
+// validate проверяет параметры и возвращает тип сущности
+func (c BehaviorConfig) validate() (EntityType, error) {
+	entityType, ok := behaviorTypes[c.Type]
+	if !ok {
+		return 0, fmt.Errorf("неизвестный тип сущности %q", c.Type)
+	}
+	if c.MoveSpeed < 0 || c.AggroRadius < 0 || c.Health < 0 || c.Damage < 0 {
+		return 0, errors.New("параметры поведения не могут быть отрицательными")
+	}
+
+	switch entityType {
+	case EntityTypePlayer:
+		if c.Subtype != "" {
+			return 0, fmt.Errorf("у игрока нет подтипов, указан %q", c.Subtype)
+		}
+		if c.AggroRadius != 0 {
+			return 0, errors.New("aggro_radius не применяется к игроку")
+		}
+	case EntityTypeNPC:
+		if !npcSubtypes[c.Subtype] {
+			return 0, fmt.Errorf("неизвестный подтип NPC %q", c.Subtype)
+		}
+	case EntityTypeAnimal:
+		if _, ok := animalSubtypes[c.Subtype]; !ok {
+			return 0, fmt.Errorf("неизвестный подтип животного %q", c.Subtype)
+		}
+		if c.Damage != 0 {
+			return 0, errors.New("damage не применяется к животным")
+		}
+	}
+	return entityType, nil
+}
+
+// newBehavior создаёт Go-реализацию поведения и применяет к ней параметры.
+// Конфигурация должна быть проверена validate
+func (c BehaviorConfig) newBehavior(entityType EntityType) EntityBehavior {
+	switch entityType {
+	case EntityTypePlayer:
+		b := NewPlayerBehavior()
+		setIfPositive(&b.baseSpeed, c.MoveSpeed)
+		setIfPositive(&b.maxHealth, c.Health)
+		setIfPositive(&b.attackDamage, c.Damage)
+		return b
+	case EntityTypeNPC:
+		b := NewNPCBehavior(c.Subtype)
+		setIfPositive(&b.baseSpeed, c.MoveSpeed)
+		setIfPositive(&b.detectionRadius, c.AggroRadius)
+		setIfPositive(&b.maxHealth, c.Health)
+		setIfPositive(&b.attackDamage, c.Damage)
+		return b
+	default:
+		b := NewAnimalBehavior(animalSubtypes[c.Subtype])
+		setIfPositive(&b.baseSpeed, c.MoveSpeed)
+		setIfPositive(&b.detectionRadius, c.AggroRadius)
+		setIfPositive(&b.maxHealth, c.Health)
+		return b
+	}
+}
+
+// setIfPositive заменяет значение реализации заданным в файле параметром
+func setIfPositive[T int | float64](dst *T, value T) {
+	if value > 0 {
+		*dst = value
+	}
+}
+
+// LoadBehaviorConfigs читает все *.json каталога dir. Каждый тип сущности
+// вместе с подтипом может быть описан только одним файлом
+func LoadBehaviorConfigs(dir string) (map[behaviorKey]BehaviorConfig, error) {
+	configs := make(map[behaviorKey]BehaviorConfig)
+	sources := make(map[behaviorKey]string)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Ext(path) != ".json" {
+			return err
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		var cfg BehaviorConfig
+		dec := json.NewDecoder(file)
+		dec.DisallowUnknownFields() // Опечатка в имени параметра не должна молча давать значение по умолчанию
+		if err := dec.Decode(&cfg); err != nil {
+			return fmt.Errorf("entity json %s: %w", path, err)
+		}
+		entityType, err := cfg.validate()
+		if err != nil {
+			return fmt.Errorf("entity json %s: %w", path, err)
+		}
+		key := behaviorKey{entityType, cfg.Subtype}
+		if prev, dup := sources[key]; dup {
+			return fmt.Errorf("entity json %s: тип %q (%q) уже описан в %s", path, cfg.Type, cfg.Subtype, prev)
+		}
+		configs[key] = cfg
+		sources[key] = path
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return configs, nil
+}
+
+// RegisterBehaviorsFromConfig регистрирует поведения по умолчанию и заменяет
+// их настроенными из каталога dir: игрока - как поведение типа, NPC и
+// животных - как поведение своего подтипа. Типы и подтипы без файла сохраняют
+// поведение RegisterDefaultBehaviors. При ошибке в любом файле ничего не
+// регистрируется
+func (em *EntityManager) RegisterBehaviorsFromConfig(dir string) error {
+	configs, err := LoadBehaviorConfigs(dir)
+	if err != nil {
+		return err
+	}
+
+	em.RegisterDefaultBehaviors()
+	for key, cfg := range configs {
+		behavior := cfg.newBehavior(key.Type)
+		if key.Subtype == "" {
+			em.RegisterBehavior(key.Type, behavior)
+		} else {
+			em.RegisterSubtypeBehavior(key.Type, key.Subtype, behavior)
+		}
+	}
+	return nil
+}
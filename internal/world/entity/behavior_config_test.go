@@ -0,0 +1,153 @@
+package entity
+
+import (
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeBehaviorFiles создаёт каталог с JSON-описаниями поведения
+func writeBehaviorFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+	return dir
+}
+
+func TestRegisterBehaviorsFromConfig_AppliesParams(t *testing.T) {
+	dir := writeBehaviorFiles(t, map[string]string{
+		"guard.json": `{"type": "npc", "subtype": "guard", "move_speed": 6.5, "aggro_radius": 20, "health": 80, "damage": 12}`,
+		"cow.json":   `{"type": "animal", "subtype": "cow", "move_speed": 0.75}`,
+		"notes.txt":  `не JSON-описание`,
+	})
+
+	em := NewEntityManager()
+	require.NoError(t, em.RegisterBehaviorsFromConfig(dir))
+
+	guardNPC := NewEntity(1, EntityTypeNPC, vec.Vec2{})
+	guardNPC.Payload["npcType"] = "guard"
+	npc, ok := em.BehaviorFor(guardNPC)
+	require.True(t, ok)
+	assert.Equal(t, 6.5, npc.GetMoveSpeed())
+	guard := npc.(*NPCBehavior)
+	assert.Equal(t, 20.0, guard.detectionRadius)
+	assert.Equal(t, 15.0, guard.wanderRadius, "параметры вне файла берутся из реализации")
+
+	npc.OnSpawn(nil, guardNPC)
+	assert.Equal(t, 80, guardNPC.Payload["max_health"])
+	assert.Equal(t, 12, guardNPC.Payload["attack_damage"])
+
+	// Файл подтипа не меняет остальные подтипы того же типа
+	villager := NewEntity(2, EntityTypeNPC, vec.Vec2{})
+	villager.Payload["npcType"] = "villager"
+	npc, ok = em.BehaviorFor(villager)
+	require.True(t, ok)
+	assert.Equal(t, NewNPCBehavior("villager").GetMoveSpeed(), npc.GetMoveSpeed())
+
+	// Пропущенные параметры - значения реализации подтипа
+	cow := em.SpawnAnimal(AnimalTypeCow, vec.Vec2{}, nil)
+	cowEntity, _ := em.GetEntity(cow)
+	animal, ok := em.BehaviorFor(cowEntity)
+	require.True(t, ok)
+	assert.Equal(t, 0.75, animal.GetMoveSpeed())
+	assert.Equal(t, 40, animal.(*AnimalBehavior).maxHealth)
+	pig := em.SpawnAnimal(AnimalTypePig, vec.Vec2{}, nil)
+	pigEntity, _ := em.GetEntity(pig)
+	animal, ok = em.BehaviorFor(pigEntity)
+	require.True(t, ok)
+	assert.Equal(t, NewAnimalBehavior(AnimalTypePig).GetMoveSpeed(), animal.GetMoveSpeed())
+
+	// Типы без файла получают поведение по умолчанию
+	player, ok := em.GetBehavior(EntityTypePlayer)
+	require.True(t, ok)
+	assert.Equal(t, NewPlayerBehavior().GetMoveSpeed(), player.GetMoveSpeed())
+}
+
+func TestRegisterBehaviorsFromConfig_RejectsInvalidFiles(t *testing.T) {
+	cases := map[string]string{
+		"unknown type":       `{"type": "dragon"}`,
+		"unknown subtype":    `{"type": "animal", "subtype": "wolf"}`,
+		"negative speed":     `{"type": "npc", "subtype": "villager", "move_speed": -1}`,
+		"typo in parameter":  `{"type": "npc", "subtype": "villager", "movespeed": 3}`,
+		"inapplicable param": `{"type": "animal", "subtype": "pig", "damage": 4}`,
+	}
+	for name, content := range cases {
+		t.Run(name, func(t *testing.T) {
+			em := NewEntityManager()
+			err := em.RegisterBehaviorsFromConfig(writeBehaviorFiles(t, map[string]string{"mob.json": content}))
+			assert.Error(t, err)
+			_, registered := em.GetBehavior(EntityTypePlayer)
+			assert.False(t, registered, "при ошибке поведения не регистрируются")
+		})
+	}
+
+	// Подтипы одного типа описываются разными файлами, но каждый - одним
+	dir := writeBehaviorFiles(t, map[string]string{
+		"a.json": `{"type": "npc", "subtype": "guard"}`,
+		"b.json": `{"type": "npc", "subtype": "trader"}`,
+	})
+	configs, err := LoadBehaviorConfigs(dir)
+	require.NoError(t, err)
+	assert.Len(t, configs, 2)
+
+	dir = writeBehaviorFiles(t, map[string]string{
+		"a.json": `{"type": "npc", "subtype": "guard"}`,
+		"b.json": `{"type": "npc", "subtype": "guard", "health": 5}`,
+	})
+	_, err = LoadBehaviorConfigs(dir)
+	assert.ErrorContains(t, err, "уже описан")
+}
+
+// attackTestAPI - мир из менеджера сущностей для проверки атак NPC
+type attackTestAPI struct {
+	EntityAPI
+	em *EntityManager
+}
+
+func (a attackTestAPI) GetEntitiesInRange(center vec.Vec2, radius float64) []*Entity {
+	return a.em.GetEntitiesInRange(center, radius)
+}
+
+func (a attackTestAPI) BehaviorFor(entity *Entity) (EntityBehavior, bool) {
+	return a.em.BehaviorFor(entity)
+}
+
+func (a attackTestAPI) Rand() *rand.Rand {
+	return a.em.Rand()
+}
+
+func TestNPCBehavior_AttackUsesConfiguredDamage(t *testing.T) {
+	em := NewEntityManager()
+	require.NoError(t, em.RegisterBehaviorsFromConfig(writeBehaviorFiles(t, map[string]string{
+		"guard.json": `{"type": "npc", "subtype": "guard", "damage": 12}`,
+	})))
+	api := attackTestAPI{em: em}
+
+	guardID := em.SpawnNPC("guard", vec.Vec2{}, api)
+	guard, _ := em.GetEntity(guardID)
+	require.Equal(t, "guard", guard.Subtype())
+	attackerID := em.SpawnEntity(EntityTypePlayer, vec.Vec2{X: 1}, api)
+	attacker, _ := em.GetEntity(attackerID)
+	health := attacker.Payload["health"].(int)
+
+	// Охранник отвечает на урон атакой с уроном из параметров
+	behavior, _ := em.BehaviorFor(guard)
+	behavior.OnDamage(api, guard, 1, attacker)
+	em.UpdateEntities(0.1, api)
+	assert.Equal(t, health-12, attacker.Payload["health"])
+
+	// Следующая атака - после перезарядки
+	em.UpdateEntities(0.1, api)
+	assert.Equal(t, health-12, attacker.Payload["health"])
+	for i := 0; i < 10; i++ {
+		em.UpdateEntities(0.1, api)
+	}
+	assert.Equal(t, health-24, attacker.Payload["health"])
+}
@@ -0,0 +1,125 @@
+package entity
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/annel0/mmo-game/internal/vec"
+)
+
+// bigChunkCensus учитывает сущности каждого типа по BigChunk. Обновляется
+// вместе с пространственным индексом (под мьютексом EntityManager), но читается
+// под собственным мьютексом: мир опрашивает его из тиков BigChunk, не
+// задерживая обновление сущностей.
+type bigChunkCensus struct {
+	mu      sync.RWMutex
+	placeOf map[uint64]censusPlace                          // ID сущности -> где и как она учтена
+	members map[vec.Vec2]map[EntityType]map[uint64]struct{} // BigChunk -> ID сущностей по типам
+}
+
+// censusPlace - BigChunk и тип, под которыми учтена сущность
+type censusPlace struct {
+	coords     vec.Vec2
+	entityType EntityType
+}
+
+func newBigChunkCensus() *bigChunkCensus {
+	return &bigChunkCensus{
+		placeOf: make(map[uint64]censusPlace),
+		members: make(map[vec.Vec2]map[EntityType]map[uint64]struct{}),
+	}
+}
+
+// track учитывает сущность в её текущем BigChunk (или переносит учёт)
+func (c *bigChunkCensus) track(e *Entity) {
+	place := censusPlace{coords: e.Position.ToBigChunkCoords(), entityType: e.Type}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if old, exists := c.placeOf[e.ID]; exists {
+		if old == place {
+			return
+		}
+		c.removeLocked(e.ID, old)
+	}
+	c.placeOf[e.ID] = place
+	byType, exists := c.members[place.coords]
+	if !exists {
+		byType = make(map[EntityType]map[uint64]struct{})
+		c.members[place.coords] = byType
+	}
+	ids, exists := byType[place.entityType]
+	if !exists {
+		ids = make(map[uint64]struct{})
+		byType[place.entityType] = ids
+	}
+	ids[e.ID] = struct{}{}
+}
+
+// untrack снимает сущность с учёта
+func (c *bigChunkCensus) untrack(entityID uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if place, exists := c.placeOf[entityID]; exists {
+		c.removeLocked(entityID, place)
+		delete(c.placeOf, entityID)
+	}
+}
+
+func (c *bigChunkCensus) removeLocked(entityID uint64, place censusPlace) {
+	byType := c.members[place.coords]
+	delete(byType[place.entityType], entityID)
+	if len(byType[place.entityType]) == 0 {
+		delete(byType, place.entityType)
+	}
+	if len(byType) == 0 {
+		delete(c.members, place.coords)
+	}
+}
+
+// CountInBigChunk возвращает число сущностей типа в BigChunk с координатами coords
+func (em *EntityManager) CountInBigChunk(coords vec.Vec2, entityType EntityType) int {
+	em.census.mu.RLock()
+	defer em.census.mu.RUnlock()
+	return len(em.census.members[coords][entityType])
+}
+
+// HasPlayersInBigChunk сообщает, есть ли в BigChunk хотя бы один игрок
+func (em *EntityManager) HasPlayersInBigChunk(coords vec.Vec2) bool {
+	return em.CountInBigChunk(coords, EntityTypePlayer) > 0
+}
+
+// BigChunkPopulation возвращает число сущностей всех типов в BigChunk
+func (em *EntityManager) BigChunkPopulation(coords vec.Vec2) int {
+	em.census.mu.RLock()
+	defer em.census.mu.RUnlock()
+
+	total := 0
+	for _, ids := range em.census.members[coords] {
+		total += len(ids)
+	}
+	return total
+}
+
+// Population возвращает число сущностей в менеджере
+func (em *EntityManager) Population() int {
+	em.census.mu.RLock()
+	defer em.census.mu.RUnlock()
+	return len(em.census.placeOf)
+}
+
+// OldestInBigChunk возвращает ID сущностей типа в BigChunk от старых к новым.
+// ID выдаются по возрастанию, поэтому меньший ID - более старая сущность
+func (em *EntityManager) OldestInBigChunk(coords vec.Vec2, entityType EntityType) []uint64 {
+	em.census.mu.RLock()
+	ids := make([]uint64, 0, len(em.census.members[coords][entityType]))
+	for id := range em.census.members[coords][entityType] {
+		ids = append(ids, id)
+	}
+	em.census.mu.RUnlock()
+
+	slices.Sort(ids)
+	return ids
+}
@@ -0,0 +1,29 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCountInBigChunk_FollowsMovesAndDespawns(t *testing.T) {
+	em := NewEntityManager()
+	home, away := vec.Vec2{}, vec.Vec2{X: 1}
+
+	em.AddEntity(NewEntity(1, EntityTypePlayer, vec.Vec2{X: 5, Y: 5}))
+	em.AddEntity(NewEntity(2, EntityTypeNPC, vec.Vec2{X: 6, Y: 5}))
+	em.AddEntity(NewEntity(3, EntityTypeNPC, vec.Vec2{X: 7, Y: 5}))
+	assert.True(t, em.HasPlayersInBigChunk(home))
+	assert.Equal(t, 2, em.CountInBigChunk(home, EntityTypeNPC))
+
+	// Переход в соседний BigChunk (512 блоков) переносит учёт
+	em.MoveEntity(1, vec.Vec2Float{X: 520, Y: 5})
+	assert.False(t, em.HasPlayersInBigChunk(home))
+	assert.True(t, em.HasPlayersInBigChunk(away))
+
+	em.DespawnEntity(2, nil)
+	assert.Equal(t, 1, em.CountInBigChunk(home, EntityTypeNPC))
+	em.DespawnEntity(1, nil)
+	assert.False(t, em.HasPlayersInBigChunk(away))
+}
@@ -1,6 +1,8 @@
 package entity
 
 import (
+	"math/rand"
+
 	"github.com/annel0/mmo-game/internal/vec"
 	"github.com/annel0/mmo-game/internal/world/block"
 )
@@ -31,6 +33,24 @@ const (
 	AnimalTypeHorse
 )
 
+// String возвращает имя подтипа животного (как в assets/entities)
+func (t AnimalType) String() string {
+	switch t {
+	case AnimalTypeCow:
+		return "cow"
+	case AnimalTypeSheep:
+		return "sheep"
+	case AnimalTypeChicken:
+		return "chicken"
+	case AnimalTypePig:
+		return "pig"
+	case AnimalTypeHorse:
+		return "horse"
+	default:
+		return ""
+	}
+}
+
 // MovementDirection представляет направление движения, отправляемое клиентом
 type MovementDirection struct {
 	Up    bool
@@ -50,6 +70,11 @@ type Entity struct {
 	Payload    map[string]interface{} // Дополнительные данные сущности
 	Active     bool                   // Активна ли сущность
 	Direction  int                    // Направление взгляда (0-3 или 0-7 для 8 направлений)
+
+	// Владение (см. ownership.go); изменяется под блокировкой EntityManager
+	OwnerID     uint64      // UserID владельца (0 - ничья сущность)
+	OwnerEntity uint64      // Сущность игрока-владельца в текущей сессии (0 - владелец не в сети)
+	OwnerPolicy OwnerPolicy // Что происходит с сущностью при отключении владельца
 }
 
 // NewEntity создаёт новую сущность
@@ -67,6 +92,41 @@ func NewEntity(id uint64, entityType EntityType, position vec.Vec2) *Entity {
 	}
 }
 
+// SpeedMultiplier возвращает множитель скорости от статус-эффектов (Payload "speed_multiplier")
+func (e *Entity) SpeedMultiplier() float64 {
+	if multiplier, ok := e.Payload["speed_multiplier"].(float64); ok && multiplier > 0 {
+		return multiplier
+	}
+	return 1
+}
+
+// Hidden сообщает, скрыта ли сущность от обычных игроков (Payload "hidden")
+func (e *Entity) Hidden() bool {
+	hidden, _ := e.Payload["hidden"].(bool)
+	return hidden
+}
+
+// Subtype возвращает подтип сущности: тип NPC (Payload "npcType") или имя
+// животного (Payload "animalType"). "" - у сущности нет подтипа
+func (e *Entity) Subtype() string {
+	switch e.Type {
+	case EntityTypeNPC:
+		npcType, _ := e.Payload["npcType"].(string)
+		return npcType
+	case EntityTypeAnimal:
+		if animalType, ok := e.Payload["animalType"].(int); ok {
+			return AnimalType(animalType).String()
+		}
+	}
+	return ""
+}
+
+// behaviorKey - тип и подтип сущности, для которых зарегистрировано поведение
+type behaviorKey struct {
+	Type    EntityType
+	Subtype string
+}
+
 // EntityBehavior определяет поведение сущности
 type EntityBehavior interface {
 	// Update обновляет состояние сущности
@@ -119,4 +179,11 @@ type EntityAPI interface {
 
 	// GetBehavior возвращает поведение для типа сущности
 	GetBehavior(entityType EntityType) (EntityBehavior, bool)
+
+	// BehaviorFor возвращает поведение сущности с учётом её подтипа
+	BehaviorFor(entity *Entity) (EntityBehavior, bool)
+
+	// Rand возвращает детерминированный генератор менеджера сущностей.
+	// Поведения используют его вместо глобального math/rand
+	Rand() *rand.Rand
 }
@@ -0,0 +1,73 @@
+package entity
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// Раскладка ID сущности: старшие 16 бит - номер региона, младшие 48 бит - локальный счётчик.
+// Регион 0 выдаёт обычные последовательные ID (1, 2, 3...), совместимые с одно-региональным режимом.
+const (
+	RegionIDBits = 16
+	LocalIDBits  = 64 - RegionIDBits
+
+	// MaxLocalEntityID - максимальное значение локального счётчика региона
+	MaxLocalEntityID uint64 = 1<<LocalIDBits - 1
+)
+
+// EntityIDAllocator выдаёт глобально уникальные ID сущностей для одного региона.
+// ID разных регионов не пересекаются, пока у регионов различные номера.
+type EntityIDAllocator struct {
+	regionID uint16
+	counter  atomic.Uint64 // Последний выданный локальный ID
+}
+
+// NewEntityIDAllocator создаёт аллокатор ID для региона с указанным номером
+func NewEntityIDAllocator(regionID uint16) *EntityIDAllocator {
+	return &EntityIDAllocator{regionID: regionID}
+}
+
+// RegionID возвращает номер региона аллокатора
+func (a *EntityIDAllocator) RegionID() uint16 {
+	return a.regionID
+}
+
+// Next выдаёт следующий ID. Безопасен для конкурентного использования.
+func (a *EntityIDAllocator) Next() uint64 {
+	local := a.counter.Add(1)
+	if local > MaxLocalEntityID {
+		panic(fmt.Sprintf("entity id space exhausted for region %d", a.regionID))
+	}
+	return ComposeEntityID(a.regionID, local)
+}
+
+// Observe учитывает ID, выданный вне аллокатора (например, загруженный из хранилища),
+// чтобы следующие ID его не повторили. ID чужих регионов игнорируются.
+func (a *EntityIDAllocator) Observe(id uint64) {
+	if RegionOfEntityID(id) != a.regionID {
+		return
+	}
+
+	local := LocalEntityID(id)
+	for {
+		current := a.counter.Load()
+		if local <= current || a.counter.CompareAndSwap(current, local) {
+			return
+		}
+	}
+}
+
+// ComposeEntityID собирает ID сущности из номера региона и локального счётчика
+func ComposeEntityID(regionID uint16, local uint64) uint64 {
+	return uint64(regionID)<<LocalIDBits | local&MaxLocalEntityID
+}
+
+// RegionOfEntityID возвращает номер региона, выдавшего ID
+func RegionOfEntityID(id uint64) uint16 {
+	return uint16(id >> LocalIDBits)
+}
+
+// LocalEntityID возвращает локальную часть ID сущности
+func LocalEntityID(id uint64) uint64 {
+	return id & MaxLocalEntityID
+}
@@ -0,0 +1,86 @@
+package entity
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/annel0/mmo-game/internal/vec"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEntityIDAllocator_RegionsNeverCollide(t *testing.T) {
+	eu := NewEntityIDAllocator(1)
+	us := NewEntityIDAllocator(2)
+
+	seen := make(map[uint64]uint16)
+	for i := 0; i < 10000; i++ {
+		for _, a := range []*EntityIDAllocator{eu, us} {
+			id := a.Next()
+			if region, dup := seen[id]; dup {
+				t.Fatalf("ID %d выдан и регионом %d, и регионом %d", id, region, a.RegionID())
+			}
+			seen[id] = a.RegionID()
+			assert.Equal(t, a.RegionID(), RegionOfEntityID(id))
+		}
+	}
+}
+
+func TestEntityIDAllocator_ConcurrentUnique(t *testing.T) {
+	a := NewEntityIDAllocator(7)
+
+	const workers, perWorker = 8, 1000
+	ids := make(chan uint64, workers*perWorker)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				ids <- a.Next()
+			}
+		}()
+	}
+	wg.Wait()
+	close(ids)
+
+	seen := make(map[uint64]struct{}, workers*perWorker)
+	for id := range ids {
+		_, dup := seen[id]
+		require.False(t, dup, "повторный ID %d", id)
+		seen[id] = struct{}{}
+	}
+	assert.Len(t, seen, workers*perWorker)
+}
+
+func TestEntityIDAllocator_RegionZeroIsSequential(t *testing.T) {
+	a := NewEntityIDAllocator(0)
+	assert.Equal(t, uint64(1), a.Next())
+	assert.Equal(t, uint64(2), a.Next())
+}
+
+func TestEntityIDAllocator_Observe(t *testing.T) {
+	a := NewEntityIDAllocator(3)
+
+	a.Observe(ComposeEntityID(3, 500))
+	assert.Equal(t, ComposeEntityID(3, 501), a.Next(), "загруженный ID не должен выдаваться повторно")
+
+	// ID другого региона не влияет на счётчик
+	a.Observe(ComposeEntityID(4, 10_000))
+	assert.Equal(t, ComposeEntityID(3, 502), a.Next())
+
+	// Меньший ID не откатывает счётчик назад
+	a.Observe(ComposeEntityID(3, 5))
+	assert.Equal(t, ComposeEntityID(3, 503), a.Next())
+}
+
+func TestEntityManager_SharedAllocator(t *testing.T) {
+	allocator := NewEntityIDAllocator(9)
+	em := NewEntityManager()
+	em.SetIDAllocator(allocator)
+
+	id := em.SpawnEntity(EntityTypeItem, vec.Vec2{}, nil)
+	assert.Equal(t, uint16(9), RegionOfEntityID(id))
+	assert.Equal(t, id+1, allocator.Next(), "менеджер и аллокатор должны использовать общий счётчик")
+}
@@ -1,10 +1,13 @@
 package entity
 
 import (
+	"cmp"
 	"fmt"
+	"maps"
 	"math"
+	"math/rand"
+	"slices"
 	"sync"
-	"sync/atomic"
 
 	"github.com/annel0/mmo-game/internal/vec"
 	"github.com/annel0/mmo-game/internal/world/block"
@@ -12,22 +15,48 @@ import (
 
 // EntityManager управляет всеми сущностями в мире
 type EntityManager struct {
-	entities     map[uint64]*Entity            // Хранилище всех сущностей
-	behaviors    map[EntityType]EntityBehavior // Реестр поведений сущностей
-	nextEntityID uint64                        // Счетчик для генерации ID
-	mu           sync.RWMutex                  // Мьютекс для безопасного доступа
+	entities    map[uint64]*Entity             // Хранилище всех сущностей
+	behaviors   map[EntityType]EntityBehavior  // Реестр поведений сущностей
+	subtypes    map[behaviorKey]EntityBehavior // Поведения подтипов NPC и животных
+	idAllocator *EntityIDAllocator             // Генератор ID сущностей
+	index       *spatialIndex                  // Пространственный индекс для запросов по радиусу
+	census      *bigChunkCensus                // Число сущностей по типам в каждом BigChunk
+	updateOrder []uint64                       // ID сущностей по возрастанию для UpdateEntities (nil - пересобрать)
+	updates     uint64                         // Вызовов UpdateEntities: сдвиг начала обхода
+	spawnRate   *spawnRateLimit                // Ограничение частоты мировых спавнов (см. spawn_rate.go)
+	rng         *rand.Rand                     // Случайные решения поведений (см. rand.go)
+	mu          sync.RWMutex                   // Мьютекс для безопасного доступа
 }
 
 // NewEntityManager создаёт новый менеджер сущностей
 func NewEntityManager() *EntityManager {
 	return &EntityManager{
-		entities:     make(map[uint64]*Entity),
-		behaviors:    make(map[EntityType]EntityBehavior),
-		nextEntityID: 1,
-		mu:           sync.RWMutex{},
+		entities:    make(map[uint64]*Entity),
+		behaviors:   make(map[EntityType]EntityBehavior),
+		subtypes:    make(map[behaviorKey]EntityBehavior),
+		idAllocator: NewEntityIDAllocator(0),
+		index:       newSpatialIndex(DefaultSpatialCellSize),
+		census:      newBigChunkCensus(),
+		spawnRate:   newSpawnRateLimit(),
+		rng:         newBehaviorRand(0),
+		mu:          sync.RWMutex{},
 	}
 }
 
+// SetIDAllocator задаёт общий для всех подсистем генератор ID сущностей
+func (em *EntityManager) SetIDAllocator(allocator *EntityIDAllocator) {
+	em.mu.Lock()
+	defer em.mu.Unlock()
+	em.idAllocator = allocator
+}
+
+// IDAllocator возвращает генератор ID сущностей
+func (em *EntityManager) IDAllocator() *EntityIDAllocator {
+	em.mu.RLock()
+	defer em.mu.RUnlock()
+	return em.idAllocator
+}
+
 // RegisterBehavior регистрирует поведение для типа сущности
 func (em *EntityManager) RegisterBehavior(entityType EntityType, behavior EntityBehavior) {
 	em.mu.Lock()
@@ -35,43 +64,67 @@ func (em *EntityManager) RegisterBehavior(entityType EntityType, behavior Entity
 	em.behaviors[entityType] = behavior
 }
 
+// RegisterSubtypeBehavior регистрирует поведение подтипа NPC (npcType) или
+// животного (cow, sheep, ...). Сущности подтипа без своего поведения
+// используют поведение типа
+func (em *EntityManager) RegisterSubtypeBehavior(entityType EntityType, subtype string, behavior EntityBehavior) {
+	em.mu.Lock()
+	defer em.mu.Unlock()
+	em.subtypes[behaviorKey{entityType, subtype}] = behavior
+}
+
 // RegisterDefaultBehaviors регистрирует поведения по умолчанию
 func (em *EntityManager) RegisterDefaultBehaviors() {
 	// Регистрируем базовые типы сущностей
 	em.RegisterBehavior(EntityTypePlayer, NewPlayerBehavior())
-	em.RegisterBehavior(EntityTypeNPC, NewNPCBehavior("villager"))
-	em.RegisterBehavior(EntityTypeNPC, NewNPCBehavior("trader"))
-	em.RegisterBehavior(EntityTypeNPC, NewNPCBehavior("guard"))
+	for _, npcType := range []string{"villager", "trader", "guard"} {
+		behavior := NewNPCBehavior(npcType)
+		em.RegisterBehavior(EntityTypeNPC, behavior)
+		em.RegisterSubtypeBehavior(EntityTypeNPC, npcType, behavior)
+	}
 
 	// Регистрируем животных
-	em.RegisterBehavior(EntityTypeAnimal, NewAnimalBehavior(AnimalTypeCow))
-	em.RegisterBehavior(EntityTypeAnimal, NewAnimalBehavior(AnimalTypeSheep))
-	em.RegisterBehavior(EntityTypeAnimal, NewAnimalBehavior(AnimalTypeChicken))
-	em.RegisterBehavior(EntityTypeAnimal, NewAnimalBehavior(AnimalTypePig))
+	for _, animalType := range []AnimalType{AnimalTypeCow, AnimalTypeSheep, AnimalTypeChicken, AnimalTypePig} {
+		behavior := NewAnimalBehavior(animalType)
+		em.RegisterBehavior(EntityTypeAnimal, behavior)
+		em.RegisterSubtypeBehavior(EntityTypeAnimal, animalType.String(), behavior)
+	}
 }
 
-// SpawnEntity создаёт новую сущность в мире
+// SpawnEntity создаёт новую сущность в мире. 0 - спавн отклонён
+// ограничением частоты (см. AdmitSpawn)
 func (em *EntityManager) SpawnEntity(entityType EntityType, position vec.Vec2, api EntityAPI) uint64 {
+	if !em.AdmitSpawn(entityType) {
+		return 0
+	}
+
 	em.mu.Lock()
 	defer em.mu.Unlock()
 
 	// Генерируем уникальный ID
-	entityID := atomic.AddUint64(&em.nextEntityID, 1)
+	entityID := em.idAllocator.Next()
 
 	// Создаём сущность
 	entity := NewEntity(entityID, entityType, position)
 	em.entities[entityID] = entity
+	em.updateOrder = nil
 
 	// Вызываем OnSpawn, если есть поведение
 	if behavior, exists := em.behaviors[entityType]; exists {
 		behavior.OnSpawn(api, entity)
 	}
+	em.index.insert(entity)
+	em.census.track(entity)
 
 	return entityID
 }
 
-// SpawnAnimal создает новое животное указанного типа
+// SpawnAnimal создает новое животное указанного типа (0 - спавн отклонён)
 func (em *EntityManager) SpawnAnimal(animalType AnimalType, position vec.Vec2, api EntityAPI) uint64 {
+	if !em.AdmitWorldSpawn() {
+		return 0
+	}
+
 	// Создаем сущность с типом животного
 	entity := &Entity{
 		Type:       EntityTypeAnimal,
@@ -88,16 +141,44 @@ func (em *EntityManager) SpawnAnimal(animalType AnimalType, position vec.Vec2, a
 
 	// Получаем ID и добавляем в карту сущностей
 	em.mu.Lock()
-	entity.ID = em.nextEntityID
-	em.nextEntityID++
+	entity.ID = em.idAllocator.Next()
 	em.entities[entity.ID] = entity
+	em.updateOrder = nil
+	em.index.insert(entity)
+	em.census.track(entity)
 	em.mu.Unlock()
 
 	// Получаем поведение для животного
-	behavior, _ := em.GetBehavior(EntityTypeAnimal)
+	behavior, exists := em.BehaviorFor(entity)
 
 	// Инициализируем сущность
-	behavior.OnSpawn(api, entity)
+	if exists {
+		behavior.OnSpawn(api, entity)
+	}
+
+	return entity.ID
+}
+
+// SpawnNPC создаёт NPC указанного типа (villager, trader, guard) с поведением
+// этого подтипа (0 - спавн отклонён)
+func (em *EntityManager) SpawnNPC(npcType string, position vec.Vec2, api EntityAPI) uint64 {
+	if !em.AdmitWorldSpawn() {
+		return 0
+	}
+
+	em.mu.Lock()
+	defer em.mu.Unlock()
+
+	entity := NewEntity(em.idAllocator.Next(), EntityTypeNPC, position)
+	entity.Payload["npcType"] = npcType
+	em.entities[entity.ID] = entity
+	em.updateOrder = nil
+
+	if behavior, exists := em.behaviorForLocked(entity); exists {
+		behavior.OnSpawn(api, entity)
+	}
+	em.index.insert(entity)
+	em.census.track(entity)
 
 	return entity.ID
 }
@@ -113,12 +194,16 @@ func (em *EntityManager) DespawnEntity(entityID uint64, api EntityAPI) bool {
 	}
 
 	// Вызываем OnDespawn, если есть поведение
-	if behavior, exists := em.behaviors[entity.Type]; exists {
+	if behavior, exists := em.behaviorForLocked(entity); exists {
 		behavior.OnDespawn(api, entity)
 	}
 
 	// Удаляем сущность
 	delete(em.entities, entityID)
+	em.updateOrder = nil
+	em.index.remove(entityID)
+	em.census.untrack(entityID)
+	CountDespawn(entity.Type)
 	return true
 }
 
@@ -131,10 +216,32 @@ func (em *EntityManager) GetEntity(entityID uint64) (*Entity, bool) {
 	return entity, exists
 }
 
-// GetEntitiesInRange возвращает сущности в указанном радиусе
+// GetEntitiesInRange возвращает сущности в указанном радиусе.
+// Использует пространственный индекс, поэтому просматривает только ближайшие ячейки.
 func (em *EntityManager) GetEntitiesInRange(center vec.Vec2, radius float64) []*Entity {
 	em.mu.RLock()
 	defer em.mu.RUnlock()
+	return em.entitiesInRangeLocked(center, radius)
+}
+
+// entitiesInRangeLocked - GetEntitiesInRange под em.mu
+func (em *EntityManager) entitiesInRangeLocked(center vec.Vec2, radius float64) []*Entity {
+	var result []*Entity
+	centerFloat := vec.FromVec2(center)
+
+	em.index.queryRadius(centerFloat, radius, func(entity *Entity) {
+		if entity.Active && centerFloat.DistanceTo(entity.PrecisePos) <= radius {
+			result = append(result, entity)
+		}
+	})
+
+	return result
+}
+
+// getEntitiesInRangeLinear - прежний линейный поиск по всем сущностям (эталон для тестов и бенчмарков)
+func (em *EntityManager) getEntitiesInRangeLinear(center vec.Vec2, radius float64) []*Entity {
+	em.mu.RLock()
+	defer em.mu.RUnlock()
 
 	var result []*Entity
 	centerFloat := vec.FromVec2(center)
@@ -157,22 +264,113 @@ func (em *EntityManager) GetBehavior(entityType EntityType) (EntityBehavior, boo
 	return behavior, exists
 }
 
-// UpdateEntities обновляет все активные сущности
+// BehaviorFor возвращает поведение сущности: её подтипа, если оно
+// зарегистрировано, иначе - её типа
+func (em *EntityManager) BehaviorFor(entity *Entity) (EntityBehavior, bool) {
+	em.mu.RLock()
+	defer em.mu.RUnlock()
+	return em.behaviorForLocked(entity)
+}
+
+// behaviorForLocked - BehaviorFor под em.mu
+func (em *EntityManager) behaviorForLocked(entity *Entity) (EntityBehavior, bool) {
+	if subtype := entity.Subtype(); subtype != "" {
+		if behavior, exists := em.subtypes[behaviorKey{entity.Type, subtype}]; exists {
+			return behavior, true
+		}
+	}
+	behavior, exists := em.behaviors[entity.Type]
+	return behavior, exists
+}
+
+// UpdateEntities обновляет все активные сущности. Порядок обновления
+// детерминирован: сущности обходятся по возрастанию ID, а начало обхода
+// сдвигается на одну сущность с каждым вызовом. Так одинаковые состояния при
+// одинаковых вызовах дают одинаковый результат на любом узле, и ни одна
+// сущность не получает постоянного преимущества в столкновениях и
+// взаимодействиях внутри тика. Случайные решения поведений берутся из
+// генератора менеджера (см. SetRandSeed), а не из глобального math/rand.
+// Каждая активная сущность обновляется в каждом вызове
 func (em *EntityManager) UpdateEntities(dt float64, api EntityAPI) {
 	// Держим блокировку на всё время обновления для избежания race conditions
 	em.mu.Lock()
 	defer em.mu.Unlock()
 
+	order := em.updateOrderLocked()
+	if len(order) == 0 {
+		return
+	}
+	start := int(em.updates % uint64(len(order)))
+	em.updates++
+	if api != nil {
+		api = lockedEntityAPI{EntityAPI: api, em: em}
+	}
+
 	// Обновляем каждую сущность
-	for _, entity := range em.entities {
-		if entity.Active {
-			if behavior, exists := em.behaviors[entity.Type]; exists {
+	for i := range order {
+		entity := em.entities[order[(start+i)%len(order)]]
+		if entity != nil && entity.Active {
+			if behavior, exists := em.behaviorForLocked(entity); exists {
 				behavior.Update(api, entity, dt)
+				// Поведение может сдвинуть сущность (например, патрулирование NPC)
+				em.index.update(entity)
+				em.census.track(entity)
 			}
 		}
 	}
 }
 
+// lockedEntityAPI - EntityAPI для поведений внутри UpdateEntities: запросы к
+// менеджеру обслуживаются без повторного захвата em.mu, который уже удерживается
+type lockedEntityAPI struct {
+	EntityAPI
+	em *EntityManager
+}
+
+func (a lockedEntityAPI) GetEntitiesInRange(center vec.Vec2, radius float64) []*Entity {
+	return a.em.entitiesInRangeLocked(center, radius)
+}
+
+func (a lockedEntityAPI) Rand() *rand.Rand {
+	return a.em.rng
+}
+
+func (a lockedEntityAPI) BehaviorFor(entity *Entity) (EntityBehavior, bool) {
+	return a.em.behaviorForLocked(entity)
+}
+
+func (a lockedEntityAPI) GetBehavior(entityType EntityType) (EntityBehavior, bool) {
+	behavior, exists := a.em.behaviors[entityType]
+	return behavior, exists
+}
+
+// updateOrderLocked возвращает ID сущностей по возрастанию, пересобирая
+// список после добавления или удаления сущностей. Вызывается под em.mu
+func (em *EntityManager) updateOrderLocked() []uint64 {
+	if em.updateOrder == nil {
+		em.updateOrder = slices.Sorted(maps.Keys(em.entities))
+	}
+	return em.updateOrder
+}
+
+// MoveEntity перемещает сущность в указанную точку и обновляет пространственный индекс.
+// Позицию сущностей менеджера следует менять только через него.
+func (em *EntityManager) MoveEntity(entityID uint64, pos vec.Vec2Float) bool {
+	em.mu.Lock()
+	defer em.mu.Unlock()
+
+	entity, exists := em.entities[entityID]
+	if !exists {
+		return false
+	}
+
+	entity.PrecisePos = pos
+	entity.Position = pos.ToVec2()
+	em.index.update(entity)
+	em.census.track(entity)
+	return true
+}
+
 // ProcessMovement обрабатывает перемещение сущности
 func (em *EntityManager) ProcessMovement(entityID uint64, direction MovementDirection, dt float64, api EntityAPI) bool {
 	entity, exists := em.GetEntity(entityID)
@@ -180,13 +378,13 @@ func (em *EntityManager) ProcessMovement(entityID uint64, direction MovementDire
 		return false
 	}
 
-	behavior, exists := em.GetBehavior(entity.Type)
+	behavior, exists := em.BehaviorFor(entity)
 	if !exists {
 		return false
 	}
 
-	// Получаем скорость движения из поведения
-	moveSpeed := behavior.GetMoveSpeed()
+	// Получаем скорость движения из поведения с учётом эффектов
+	moveSpeed := behavior.GetMoveSpeed() * entity.SpeedMultiplier()
 
 	// Вычисляем вектор направления
 	moveDir := vec.Vec2Float{X: 0, Y: 0}
@@ -241,6 +439,8 @@ func (em *EntityManager) ProcessMovement(entityID uint64, direction MovementDire
 	if entityInMap, exists := em.entities[entity.ID]; exists {
 		entityInMap.PrecisePos = finalPos
 		entityInMap.Position = finalPos.ToVec2()
+		em.index.update(entityInMap)
+		em.census.track(entityInMap)
 
 		// Устанавливаем скорость только по осям без коллизий
 		if !collisionX && !collisionY {
@@ -293,7 +493,7 @@ func (em *EntityManager) checkCollision(entity *Entity, newPos vec.Vec2Float, ap
 		// Проверяем, является ли блок препятствием
 		if !isPassableBlock(blockID) {
 			// Вызываем обработчик коллизии, если есть поведение
-			if behavior, exists := em.GetBehavior(entity.Type); exists {
+			if behavior, exists := em.BehaviorFor(entity); exists {
 				behavior.OnCollision(api, entity, blockID, corner)
 			}
 			return true
@@ -301,23 +501,38 @@ func (em *EntityManager) checkCollision(entity *Entity, newPos vec.Vec2Float, ap
 	}
 
 	// Также проверяем коллизии с другими сущностями
-	for _, otherEntity := range em.entities {
+	if otherEntity := em.firstCollidingEntity(entity, newPos); otherEntity != nil {
+		// Вызываем обработчик коллизии, если есть поведение
+		if behavior, exists := em.BehaviorFor(entity); exists {
+			collisionPoint := calculateCollisionPoint(newPos, otherEntity.PrecisePos)
+			behavior.OnCollision(api, entity, otherEntity, collisionPoint)
+		}
+		return true
+	}
+
+	return false
+}
+
+// firstCollidingEntity возвращает активную сущность с наименьшим ID, хитбокс
+// которой пересекается с хитбоксом entity в точке newPos. Обход по ID, а не
+// по карте: при нескольких пересечениях OnCollision получает одну и ту же
+// сущность при каждом прогоне
+func (em *EntityManager) firstCollidingEntity(entity *Entity, newPos vec.Vec2Float) *Entity {
+	em.mu.Lock()
+	defer em.mu.Unlock()
+
+	for _, id := range em.updateOrderLocked() {
+		otherEntity := em.entities[id]
 		if otherEntity.ID == entity.ID || !otherEntity.Active {
 			continue // Пропускаем себя и неактивные сущности
 		}
 
 		// Простая проверка пересечения хитбоксов (можно улучшить)
 		if entitiesCollide(newPos, entity.Size, otherEntity.PrecisePos, otherEntity.Size) {
-			// Вызываем обработчик коллизии, если есть поведение
-			if behavior, exists := em.GetBehavior(entity.Type); exists {
-				collisionPoint := calculateCollisionPoint(newPos, otherEntity.PrecisePos)
-				behavior.OnCollision(api, entity, otherEntity, collisionPoint)
-			}
-			return true
+			return otherEntity
 		}
 	}
-
-	return false
+	return nil
 }
 
 // calculateDirection определяет направление взгляда по вектору движения
@@ -428,7 +643,68 @@ func (em *EntityManager) AddEntity(entity *Entity) {
 	em.mu.Lock()
 	defer em.mu.Unlock()
 	em.entities[entity.ID] = entity
-	if entity.ID >= em.nextEntityID {
-		em.nextEntityID = entity.ID + 1
+	em.updateOrder = nil
+	em.index.insert(entity)
+	em.census.track(entity)
+	em.idAllocator.Observe(entity.ID)
+}
+
+// EntitiesInArea возвращает копии мировых сущностей (кроме игроков), позиции
+// которых лежат в области inside, упорядоченные по ID
+func (em *EntityManager) EntitiesInArea(inside func(vec.Vec2) bool) []Entity {
+	em.mu.RLock()
+	defer em.mu.RUnlock()
+
+	var result []Entity
+	for _, entity := range em.entities {
+		if entity.Type != EntityTypePlayer && inside(entity.Position) {
+			copied := *entity
+			copied.Payload = maps.Clone(entity.Payload)
+			result = append(result, copied)
+		}
 	}
+	slices.SortFunc(result, func(a, b Entity) int { return cmp.Compare(a.ID, b.ID) })
+	return result
+}
+
+// ReplaceEntitiesInArea заменяет мировые сущности области inside сущностями
+// entities с их исходными ID; игроки не затрагиваются, а сущность, чей ID
+// занят игроком, пропускается. OnSpawn и OnDespawn не вызываются: сущности
+// восстанавливаются в сохранённом состоянии. apply (если задан) вызывается
+// под блокировкой менеджера после замены, чтобы связанные изменения мира
+// применялись вместе с ней и читатели не видели область частично заменённой
+func (em *EntityManager) ReplaceEntitiesInArea(inside func(vec.Vec2) bool, entities []*Entity, apply func()) {
+	em.mu.Lock()
+	defer em.mu.Unlock()
+
+	for id, entity := range em.entities {
+		if entity.Type != EntityTypePlayer && inside(entity.Position) {
+			em.removeEntityLocked(id)
+		}
+	}
+	for _, entity := range entities {
+		if existing, exists := em.entities[entity.ID]; exists {
+			if existing.Type == EntityTypePlayer {
+				continue
+			}
+			em.removeEntityLocked(entity.ID)
+		}
+		em.entities[entity.ID] = entity
+		em.index.insert(entity)
+		em.census.track(entity)
+		em.idAllocator.Observe(entity.ID)
+	}
+	em.updateOrder = nil
+
+	if apply != nil {
+		apply()
+	}
+}
+
+// removeEntityLocked удаляет сущность без вызова OnDespawn. Вызывающий должен держать em.mu
+func (em *EntityManager) removeEntityLocked(entityID uint64) {
+	delete(em.entities, entityID)
+	em.updateOrder = nil
+	em.index.remove(entityID)
+	em.census.untrack(entityID)
 }
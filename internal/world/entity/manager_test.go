@@ -0,0 +1,171 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/annel0/mmo-game/internal/world/block"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// orderSensitiveBehavior - поведение, результат которого зависит от порядка
+// обновления: сущность занимает клетку, если её ещё никто не занял в этом
+// тике, иначе уступает и сдвигается
+type orderSensitiveBehavior struct {
+	taken map[vec.Vec2]bool
+	order []uint64 // ID в порядке вызовов Update
+}
+
+func (b *orderSensitiveBehavior) Update(api EntityAPI, entity *Entity, dt float64) {
+	b.order = append(b.order, entity.ID)
+	target := vec.Vec2{X: entity.Position.X / 2, Y: entity.Position.Y}
+	if b.taken[target] {
+		target.Y++
+	}
+	b.taken[target] = true
+	entity.Position = target
+	entity.PrecisePos = vec.Vec2Float{X: float64(target.X), Y: float64(target.Y)}
+	entity.Payload["moves"] = len(b.order)
+}
+
+func (b *orderSensitiveBehavior) OnSpawn(api EntityAPI, entity *Entity)   {}
+func (b *orderSensitiveBehavior) OnDespawn(api EntityAPI, entity *Entity) {}
+func (b *orderSensitiveBehavior) OnDamage(api EntityAPI, entity *Entity, damage int, source interface{}) bool {
+	return false
+}
+func (b *orderSensitiveBehavior) OnCollision(api EntityAPI, entity *Entity, other interface{}, collisionPoint vec.Vec2Float) {
+}
+func (b *orderSensitiveBehavior) GetMoveSpeed() float64 { return 1 }
+
+// simulate создаёт одинаковый набор сущностей (в порядке ids) и проводит ticks обновлений
+func simulate(ids []uint64, ticks int) (map[uint64]Entity, *orderSensitiveBehavior) {
+	em := NewEntityManager()
+	behavior := &orderSensitiveBehavior{}
+	em.RegisterBehavior(EntityTypeNPC, behavior)
+	for _, id := range ids {
+		em.AddEntity(NewEntity(id, EntityTypeNPC, vec.Vec2{X: int(id % 7), Y: int(id % 3)}))
+	}
+
+	for range ticks {
+		behavior.taken = make(map[vec.Vec2]bool)
+		em.UpdateEntities(0.05, nil)
+	}
+
+	states := make(map[uint64]Entity, len(ids))
+	for _, id := range ids {
+		e, _ := em.GetEntity(id)
+		states[id] = *e
+	}
+	return states, behavior
+}
+
+func TestUpdateEntities_IdenticalStatesStayIdentical(t *testing.T) {
+	ids := make([]uint64, 64)
+	reversed := make([]uint64, 64)
+	for i := range ids {
+		ids[i] = uint64(i + 1)
+		reversed[len(ids)-1-i] = uint64(i + 1)
+	}
+
+	// Порядок добавления и порядок обхода карты не влияют на результат
+	first, firstBehavior := simulate(ids, 50)
+	second, secondBehavior := simulate(reversed, 50)
+	assert.Equal(t, firstBehavior.order, secondBehavior.order)
+	for _, id := range ids {
+		assert.Equal(t, first[id].Position, second[id].Position, "сущность %d", id)
+		assert.Equal(t, first[id].Payload, second[id].Payload, "сущность %d", id)
+	}
+}
+
+func TestUpdateEntities_RotatesStartForFairness(t *testing.T) {
+	ids := []uint64{3, 1, 2}
+	_, behavior := simulate(ids, 3)
+
+	// Каждый тик обновляет все сущности по возрастанию ID, начиная со следующей
+	require.Len(t, behavior.order, 9)
+	assert.Equal(t, []uint64{1, 2, 3, 2, 3, 1, 3, 1, 2}, behavior.order)
+}
+
+// simulateWander проводит ticks обновлений свиней и жителей с сидом seed и
+// возвращает полезную нагрузку сущностей
+func simulateWander(seed int64, ticks int) map[uint64]map[string]interface{} {
+	em := NewEntityManager()
+	em.RegisterDefaultBehaviors()
+	em.SetRandSeed(seed)
+	api := attackTestAPI{em: em}
+
+	var ids []uint64
+	for i := range 3 {
+		ids = append(ids, em.SpawnAnimal(AnimalTypePig, vec.Vec2{X: i * 20}, api))
+		ids = append(ids, em.SpawnNPC("villager", vec.Vec2{Y: i * 20}, api))
+	}
+	for range ticks {
+		em.UpdateEntities(0.5, api)
+	}
+
+	payloads := make(map[uint64]map[string]interface{}, len(ids))
+	for _, id := range ids {
+		e, _ := em.GetEntity(id)
+		payloads[id] = e.Payload
+	}
+	return payloads
+}
+
+func TestUpdateEntities_BehaviorRandomnessFollowsSeed(t *testing.T) {
+	first := simulateWander(42, 100)
+	assert.Equal(t, first, simulateWander(42, 100), "один сид - одинаковые решения поведений")
+	assert.NotEqual(t, first, simulateWander(43, 100))
+}
+
+// collisionRecorder запоминает ID сущностей, с которыми столкнулась движущаяся
+type collisionRecorder struct {
+	orderSensitiveBehavior
+	hits []uint64
+}
+
+func (b *collisionRecorder) OnCollision(api EntityAPI, entity *Entity, other interface{}, collisionPoint vec.Vec2Float) {
+	if otherEntity, ok := other.(*Entity); ok {
+		b.hits = append(b.hits, otherEntity.ID)
+	}
+}
+
+// openFieldAPI - мир из одного воздуха: сталкиваться можно только с сущностями
+type openFieldAPI struct {
+	attackTestAPI
+}
+
+func (openFieldAPI) GetBlock(pos vec.Vec2) block.BlockID {
+	return block.AirBlockID
+}
+
+// simulateCrowdCollisions ticks раз двигает сущность 1 в толпу, хитбоксы
+// которой все пересекаются с её новой позицией, и возвращает, с кем она
+// сталкивалась
+func simulateCrowdCollisions(ticks int) []uint64 {
+	em := NewEntityManager()
+	behavior := &collisionRecorder{}
+	em.RegisterBehavior(EntityTypeNPC, behavior)
+	api := openFieldAPI{attackTestAPI{em: em}}
+
+	em.AddEntity(NewEntity(1, EntityTypeNPC, vec.Vec2{}))
+	for id := uint64(9); id >= 2; id-- {
+		e := NewEntity(id, EntityTypeNPC, vec.Vec2{})
+		e.PrecisePos = vec.Vec2Float{X: 1, Y: float64(id%5)*0.1 - 0.2}
+		em.AddEntity(e)
+	}
+
+	for range ticks {
+		em.ProcessMovement(1, MovementDirection{Right: true}, 0.5, api)
+	}
+	return behavior.hits
+}
+
+func TestProcessMovement_CrowdCollisionsFollowIDOrder(t *testing.T) {
+	hits := simulateCrowdCollisions(50)
+	require.Len(t, hits, 50, "сущность упирается в толпу каждый тик")
+	for _, id := range hits {
+		require.EqualValues(t, 2, id, "из нескольких пересечений выбирается сущность с наименьшим ID")
+	}
+	assert.Equal(t, hits, simulateCrowdCollisions(50))
+}
@@ -3,7 +3,6 @@ package entity
 import (
 	"math"
 	"math/rand"
-	"time"
 
 	"github.com/annel0/mmo-game/internal/vec"
 )
@@ -18,6 +17,7 @@ type NPCBehavior struct {
 	idleTimeRange   [2]float64 // Мин/макс время простоя
 	moveTimeRange   [2]float64 // Мин/макс время движения
 	npcType         string     // Тип NPC (например, "villager", "trader", "guard")
+	attackDamage    int        // Урон атаки (Payload "attack_damage")
 }
 
 // NewNPCBehavior создает новое поведение NPC
@@ -30,6 +30,7 @@ func NewNPCBehavior(npcType string) *NPCBehavior {
 		idleTimeRange:   [2]float64{1.0, 5.0}, // 1-5 секунд простоя
 		moveTimeRange:   [2]float64{1.0, 3.0}, // 1-3 секунды движения
 		npcType:         npcType,
+		attackDamage:    2,
 	}
 
 	// Настройка поведения в зависимости от типа NPC
@@ -45,6 +46,7 @@ func NewNPCBehavior(npcType string) *NPCBehavior {
 		behavior.baseSpeed = 4.0
 		behavior.detectionRadius = 12.0
 		behavior.wanderRadius = 15.0
+		behavior.attackDamage = 8
 	}
 
 	return behavior
@@ -52,13 +54,19 @@ func NewNPCBehavior(npcType string) *NPCBehavior {
 
 // Update обновляет состояние NPC
 func (nb *NPCBehavior) Update(api EntityAPI, entity *Entity, dt float64) {
+	rng := behaviorRand(api)
+
 	// Обновляем таймеры и состояния
 	if entity.Payload["actionTimer"] == nil {
 		entity.Payload["actionTimer"] = 0.0
 		entity.Payload["state"] = "idle"
 		entity.Payload["homePosition"] = entity.Position
 		entity.Payload["targetPosition"] = entity.Position
-		entity.Payload["randomSeed"] = time.Now().UnixNano()
+		entity.Payload["randomSeed"] = rng.Int63()
+	}
+
+	if cooldown, ok := entity.Payload["attackCooldown"].(float64); ok && cooldown > 0 {
+		entity.Payload["attackCooldown"] = cooldown - dt
 	}
 
 	// Получаем текущее состояние
@@ -72,7 +80,7 @@ func (nb *NPCBehavior) Update(api EntityAPI, entity *Entity, dt float64) {
 		case "idle":
 			// Переходим в состояние движения
 			entity.Payload["state"] = "moving"
-			entity.Payload["actionTimer"] = nb.getRandomInRange(nb.moveTimeRange)
+			entity.Payload["actionTimer"] = nb.getRandomInRange(rng, nb.moveTimeRange)
 
 			// Выбираем случайную точку для движения в пределах радиуса блуждания
 			homePos, ok := entity.Payload["homePosition"].(vec.Vec2)
@@ -81,12 +89,12 @@ func (nb *NPCBehavior) Update(api EntityAPI, entity *Entity, dt float64) {
 				entity.Payload["homePosition"] = homePos
 			}
 
-			targetPos := nb.getRandomPositionInRadius(homePos, nb.wanderRadius)
+			targetPos := nb.getRandomPositionInRadius(rng, homePos, nb.wanderRadius)
 			entity.Payload["targetPosition"] = targetPos
 		case "moving":
 			// Переходим в состояние покоя
 			entity.Payload["state"] = "idle"
-			entity.Payload["actionTimer"] = nb.getRandomInRange(nb.idleTimeRange)
+			entity.Payload["actionTimer"] = nb.getRandomInRange(rng, nb.idleTimeRange)
 		case "following":
 			// Возвращаемся к блужданию, если цель пропала
 			// Проверяем, есть ли еще игроки в радиусе обнаружения
@@ -104,8 +112,12 @@ func (nb *NPCBehavior) Update(api EntityAPI, entity *Entity, dt float64) {
 			if !playerFound {
 				// Возвращаемся к обычному поведению
 				entity.Payload["state"] = "idle"
-				entity.Payload["actionTimer"] = nb.getRandomInRange(nb.idleTimeRange)
+				entity.Payload["actionTimer"] = nb.getRandomInRange(rng, nb.idleTimeRange)
 			}
+		case "attacking":
+			// Время ответной атаки истекло
+			entity.Payload["state"] = "idle"
+			entity.Payload["actionTimer"] = nb.getRandomInRange(rng, nb.idleTimeRange)
 		}
 	} else {
 		// Продолжаем текущее действие
@@ -131,7 +143,7 @@ func (nb *NPCBehavior) Update(api EntityAPI, entity *Entity, dt float64) {
 			if entity.PrecisePos.DistanceTo(targetPosFloat) < 0.5 {
 				// Цель достигнута, переходим в состояние покоя
 				entity.Payload["state"] = "idle"
-				entity.Payload["actionTimer"] = nb.getRandomInRange(nb.idleTimeRange)
+				entity.Payload["actionTimer"] = nb.getRandomInRange(rng, nb.idleTimeRange)
 				entity.Velocity = vec.Vec2Float{X: 0, Y: 0}
 			} else {
 				// Продолжаем движение
@@ -144,7 +156,7 @@ func (nb *NPCBehavior) Update(api EntityAPI, entity *Entity, dt float64) {
 				entity.PrecisePos = entity.PrecisePos.Add(entity.Velocity.Mul(dt))
 				entity.Position = entity.PrecisePos.ToVec2()
 			}
-		case "following":
+		case "following", "attacking":
 			// Следуем за целевой сущностью (например, игроком)
 			targetID, ok := entity.Payload["targetEntityID"].(uint64)
 			if !ok {
@@ -156,7 +168,7 @@ func (nb *NPCBehavior) Update(api EntityAPI, entity *Entity, dt float64) {
 			if !exists {
 				// Цель потеряна, возвращаемся к обычному поведению
 				entity.Payload["state"] = "idle"
-				entity.Payload["actionTimer"] = nb.getRandomInRange(nb.idleTimeRange)
+				entity.Payload["actionTimer"] = nb.getRandomInRange(rng, nb.idleTimeRange)
 				return
 			}
 
@@ -172,16 +184,19 @@ func (nb *NPCBehavior) Update(api EntityAPI, entity *Entity, dt float64) {
 				// Смотрим в сторону цели
 				entity.Direction = calculateDirectionFromVector(direction)
 
-				// Выполняем действие в зависимости от типа NPC
-				switch nb.npcType {
-				case "trader":
-					// Может предложить торговлю
-					if rand.Float64() < 0.01 { // 1% шанс в кадр
-						api.SendMessage(targetID, "trade_offer", entity.ID)
+				// Атакующий NPC бьёт цель, остальные действуют по типу
+				if state == "attacking" {
+					nb.attack(api, entity, targetEntity)
+				} else {
+					switch nb.npcType {
+					case "trader":
+						// Может предложить торговлю
+						if rng.Float64() < 0.01 { // 1% шанс в кадр
+							api.SendMessage(targetID, "trade_offer", entity.ID)
+						}
+					case "guard":
+						// Предупреждает игрока; нападает только в ответ на урон (OnDamage)
 					}
-				case "guard":
-					// Может предупредить игрока или напасть
-					// Логика решения пока не реализована
 				}
 			} else {
 				// Продолжаем движение к цели
@@ -199,7 +214,7 @@ func (nb *NPCBehavior) Update(api EntityAPI, entity *Entity, dt float64) {
 
 	// Проверяем, нет ли игроков в радиусе обнаружения (для NPC, которые реагируют на игроков)
 	if nb.npcType == "guard" || nb.npcType == "trader" {
-		if state != "following" {
+		if state != "following" && state != "attacking" {
 			players := api.GetEntitiesInRange(entity.Position, nb.detectionRadius)
 			for _, potentialTarget := range players {
 				if potentialTarget.Type == EntityTypePlayer {
@@ -214,15 +229,38 @@ func (nb *NPCBehavior) Update(api EntityAPI, entity *Entity, dt float64) {
 	}
 }
 
+// npcAttackCooldown - секунды между атаками NPC
+const npcAttackCooldown = 1.0
+
+// attack наносит цели урон атаки NPC (Payload "attack_damage", задаётся при
+// появлении из параметров поведения) не чаще раза в npcAttackCooldown
+func (nb *NPCBehavior) attack(api EntityAPI, entity, target *Entity) {
+	if cooldown, ok := entity.Payload["attackCooldown"].(float64); ok && cooldown > 0 {
+		return
+	}
+	damage, ok := entity.Payload["attack_damage"].(int)
+	if !ok || damage <= 0 {
+		return
+	}
+	if behavior, exists := api.BehaviorFor(target); exists {
+		behavior.OnDamage(api, target, damage, entity)
+	}
+	entity.Payload["attackCooldown"] = npcAttackCooldown
+}
+
 // OnSpawn вызывается при создании NPC
 func (nb *NPCBehavior) OnSpawn(api EntityAPI, entity *Entity) {
+	rng := behaviorRand(api)
+
 	// Инициализация данных NPC
 	entity.Payload["health"] = nb.maxHealth
+	entity.Payload["max_health"] = nb.maxHealth
 	entity.Payload["npcType"] = nb.npcType
+	entity.Payload["attack_damage"] = nb.attackDamage
 	entity.Payload["state"] = "idle"
-	entity.Payload["actionTimer"] = nb.getRandomInRange(nb.idleTimeRange)
+	entity.Payload["actionTimer"] = nb.getRandomInRange(rng, nb.idleTimeRange)
 	entity.Payload["homePosition"] = entity.Position
-	entity.Payload["randomSeed"] = time.Now().UnixNano()
+	entity.Payload["randomSeed"] = rng.Int63()
 
 	// Дополнительные данные в зависимости от типа NPC
 	switch nb.npcType {
@@ -284,7 +322,7 @@ func (nb *NPCBehavior) OnCollision(api EntityAPI, entity *Entity, other interfac
 	if entity.Payload["state"] == "moving" {
 		// При столкновении во время движения меняем направление
 		entity.Payload["state"] = "idle"
-		entity.Payload["actionTimer"] = nb.getRandomInRange(nb.idleTimeRange)
+		entity.Payload["actionTimer"] = nb.getRandomInRange(behaviorRand(api), nb.idleTimeRange)
 	}
 }
 
@@ -296,16 +334,16 @@ func (nb *NPCBehavior) GetMoveSpeed() float64 {
 // Вспомогательные функции
 
 // getRandomInRange возвращает случайное число в указанном диапазоне
-func (nb *NPCBehavior) getRandomInRange(r [2]float64) float64 {
-	return r[0] + rand.Float64()*(r[1]-r[0])
+func (nb *NPCBehavior) getRandomInRange(rng *rand.Rand, r [2]float64) float64 {
+	return r[0] + rng.Float64()*(r[1]-r[0])
 }
 
 // getRandomPositionInRadius возвращает случайную позицию в указанном радиусе от центра
-func (nb *NPCBehavior) getRandomPositionInRadius(center vec.Vec2, radius float64) vec.Vec2 {
+func (nb *NPCBehavior) getRandomPositionInRadius(rng *rand.Rand, center vec.Vec2, radius float64) vec.Vec2 {
 	// Случайный угол
-	angle := rand.Float64() * 2 * math.Pi
+	angle := rng.Float64() * 2 * math.Pi
 	// Случайное расстояние (корень для равномерного распределения по площади)
-	distance := radius * math.Sqrt(rand.Float64())
+	distance := radius * math.Sqrt(rng.Float64())
 
 	// Преобразуем в координаты
 	x := float64(center.X) + distance*math.Cos(angle)
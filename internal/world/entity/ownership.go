@@ -0,0 +1,101 @@
+package entity
+
+import "sort"
+
+// OwnerPolicy определяет судьбу сущности, когда её владелец отключается
+type OwnerPolicy uint8
+
+const (
+	// OwnerKeep - сущность остаётся в мире за владельцем и возвращается ему
+	// при переподключении (питомцы, выброшенные предметы)
+	OwnerKeep OwnerPolicy = iota
+	// OwnerRelease - сущность остаётся в мире и становится ничьей (турели)
+	OwnerRelease
+	// OwnerDespawn - сущность удаляется вместе с владельцем (снаряды)
+	OwnerDespawn
+)
+
+// DefaultOwnerPolicy возвращает политику владения по умолчанию для типа сущности
+func DefaultOwnerPolicy(entityType EntityType) OwnerPolicy {
+	switch entityType {
+	case EntityTypeAnimal, EntityTypeItem:
+		return OwnerKeep
+	case EntityTypeProjectile:
+		return OwnerDespawn
+	default:
+		return OwnerRelease
+	}
+}
+
+// SetOwner закрепляет сущность за пользователем ownerID, которым сейчас
+// управляет сущность игрока ownerEntity. Возвращает false, если сущность не найдена
+func (em *EntityManager) SetOwner(entityID, ownerID, ownerEntity uint64, policy OwnerPolicy) bool {
+	em.mu.Lock()
+	defer em.mu.Unlock()
+
+	entity, exists := em.entities[entityID]
+	if !exists {
+		return false
+	}
+	entity.OwnerID = ownerID
+	entity.OwnerEntity = ownerEntity
+	entity.OwnerPolicy = policy
+	return true
+}
+
+// OwnedBy возвращает сущности пользователя, упорядоченные по ID
+func (em *EntityManager) OwnedBy(ownerID uint64) []*Entity {
+	em.mu.RLock()
+	defer em.mu.RUnlock()
+
+	return em.ownedByLocked(ownerID)
+}
+
+// ownedByLocked - OwnedBy под уже захваченной блокировкой em.mu
+func (em *EntityManager) ownedByLocked(ownerID uint64) []*Entity {
+	if ownerID == 0 {
+		return nil
+	}
+
+	var owned []*Entity
+	for _, entity := range em.entities {
+		if entity.OwnerID == ownerID {
+			owned = append(owned, entity)
+		}
+	}
+	sort.Slice(owned, func(i, j int) bool { return owned[i].ID < owned[j].ID })
+	return owned
+}
+
+// OwnerDisconnected применяет политики владения к сущностям отключившегося
+// пользователя. Сущности с OwnerDespawn не удаляются здесь: их ID возвращаются,
+// чтобы вызывающий удалил их с оповещением клиентов
+func (em *EntityManager) OwnerDisconnected(ownerID uint64) []uint64 {
+	em.mu.Lock()
+	defer em.mu.Unlock()
+
+	var despawn []uint64
+	for _, entity := range em.ownedByLocked(ownerID) {
+		entity.OwnerEntity = 0
+		switch entity.OwnerPolicy {
+		case OwnerRelease:
+			entity.OwnerID = 0
+		case OwnerDespawn:
+			despawn = append(despawn, entity.ID)
+		}
+	}
+	return despawn
+}
+
+// ReclaimOwned привязывает сохранившиеся сущности пользователя к его новой
+// сущности игрока и возвращает их
+func (em *EntityManager) ReclaimOwned(ownerID, ownerEntity uint64) []*Entity {
+	em.mu.Lock()
+	defer em.mu.Unlock()
+
+	owned := em.ownedByLocked(ownerID)
+	for _, entity := range owned {
+		entity.OwnerEntity = ownerEntity
+	}
+	return owned
+}
@@ -0,0 +1,47 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOwnerDisconnected_AppliesPolicies(t *testing.T) {
+	em := NewEntityManager()
+	for id, entityType := range map[uint64]EntityType{1: EntityTypeAnimal, 2: EntityTypeNPC, 3: EntityTypeProjectile, 4: EntityTypeItem} {
+		em.AddEntity(NewEntity(id, entityType, vec.Vec2{}))
+		require.True(t, em.SetOwner(id, 42, 100, DefaultOwnerPolicy(entityType)))
+	}
+	assert.False(t, em.SetOwner(99, 42, 100, OwnerKeep), "несуществующую сущность нельзя присвоить")
+
+	despawn := em.OwnerDisconnected(42)
+	assert.Equal(t, []uint64{3}, despawn, "снаряд удаляется вместе с владельцем")
+
+	pet, _ := em.GetEntity(1)
+	assert.Equal(t, uint64(42), pet.OwnerID, "питомец остаётся за владельцем")
+	assert.Zero(t, pet.OwnerEntity, "владелец не в сети")
+
+	turret, _ := em.GetEntity(2)
+	assert.Zero(t, turret.OwnerID, "турель становится ничьей")
+
+	assert.Equal(t, []uint64{1, 3, 4}, entityIDs(em.OwnedBy(42)))
+}
+
+func TestReclaimOwned_BindsToNewPlayerEntity(t *testing.T) {
+	em := NewEntityManager()
+	em.AddEntity(NewEntity(1, EntityTypeAnimal, vec.Vec2{}))
+	em.AddEntity(NewEntity(2, EntityTypeAnimal, vec.Vec2{}))
+	require.True(t, em.SetOwner(1, 42, 100, OwnerKeep))
+	require.True(t, em.SetOwner(2, 7, 200, OwnerKeep))
+
+	em.OwnerDisconnected(42)
+	reclaimed := em.ReclaimOwned(42, 300)
+
+	assert.Equal(t, []uint64{1}, entityIDs(reclaimed))
+	pet, _ := em.GetEntity(1)
+	assert.Equal(t, uint64(300), pet.OwnerEntity)
+	other, _ := em.GetEntity(2)
+	assert.Equal(t, uint64(200), other.OwnerEntity, "чужие сущности не затрагиваются")
+}
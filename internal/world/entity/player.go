@@ -44,6 +44,7 @@ func (pb *PlayerBehavior) Update(api EntityAPI, entity *Entity, dt float64) {
 func (pb *PlayerBehavior) OnSpawn(api EntityAPI, entity *Entity) {
 	// Инициализация данных игрока
 	entity.Payload["health"] = pb.maxHealth
+	entity.Payload["max_health"] = pb.maxHealth
 	entity.Payload["inventory"] = make(map[string]interface{})
 	entity.Payload["experience"] = 0
 	entity.Payload["level"] = 1
@@ -132,7 +133,7 @@ func (pb *PlayerBehavior) Attack(api EntityAPI, entity *Entity) bool {
 
 		// Проверка, находится ли цель в конусе атаки
 		if isInAttackCone(entity.PrecisePos, target.PrecisePos, attackDirection, pb.attackRange, 90) {
-			if behavior, exists := api.GetBehavior(target.Type); exists {
+			if behavior, exists := api.BehaviorFor(target); exists {
 				behavior.OnDamage(api, target, pb.attackDamage, entity)
 				hitSomething = true
 			}
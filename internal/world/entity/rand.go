@@ -0,0 +1,67 @@
+package entity
+
+import (
+	"math/rand"
+	"sync"
+)
+
+// fallbackRand - генератор поведений, вызванных без мира (api == nil):
+// фиксированный сид сохраняет воспроизводимость и в этом случае
+var fallbackRand = newBehaviorRand(0)
+
+// newBehaviorRand создаёт генератор случайных решений поведений. Источник
+// защищён мьютексом: поведения вызываются из тика и из обработчиков игроков
+func newBehaviorRand(seed int64) *rand.Rand {
+	return rand.New(NewLockedSource(seed))
+}
+
+// SetRandSeed задаёт сид генератора поведений (см. Rand). Вызывается при
+// подключении к миру: одинаковый сид и одинаковые вызовы дают одинаковые
+// решения NPC и животных на любом узле
+func (em *EntityManager) SetRandSeed(seed int64) {
+	em.rng.Seed(seed)
+}
+
+// Rand возвращает генератор случайных решений поведений этого менеджера.
+// Поведения получают его через EntityAPI.Rand вместо глобального math/rand
+func (em *EntityManager) Rand() *rand.Rand {
+	return em.rng
+}
+
+// behaviorRand возвращает генератор мира api (fallbackRand без мира)
+func behaviorRand(api EntityAPI) *rand.Rand {
+	if api == nil {
+		return fallbackRand
+	}
+	return api.Rand()
+}
+
+// LockedSource - потокобезопасный rand.Source64. Общий для генераторов
+// поведений и симуляции BigChunk
+type LockedSource struct {
+	mu  sync.Mutex
+	src rand.Source64
+}
+
+// NewLockedSource создаёт потокобезопасный источник с сидом seed
+func NewLockedSource(seed int64) *LockedSource {
+	return &LockedSource{src: rand.NewSource(seed).(rand.Source64)}
+}
+
+func (s *LockedSource) Int63() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.src.Int63()
+}
+
+func (s *LockedSource) Uint64() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.src.Uint64()
+}
+
+func (s *LockedSource) Seed(seed int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.src.Seed(seed)
+}
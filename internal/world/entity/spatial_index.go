@@ -0,0 +1,127 @@
+package entity
+
+import (
+	"cmp"
+	"math"
+	"slices"
+
+	"github.com/annel0/mmo-game/internal/vec"
+)
+
+// DefaultSpatialCellSize - размер ячейки пространственного индекса в блоках.
+// Совпадает с размером чанка: типичные радиусы запросов (коллизии, атака, AOI)
+// покрывают от одной до нескольких десятков ячеек.
+const DefaultSpatialCellSize = 16
+
+// spatialIndex - равномерная сетка сущностей по точной позиции.
+// Не потокобезопасен: доступ защищается мьютексом EntityManager.
+type spatialIndex struct {
+	cellSize float64
+	cells    map[vec.Vec2]map[uint64]*Entity // Ячейка -> сущности в ней
+	cellOf   map[uint64]vec.Vec2             // ID сущности -> ячейка, в которой она проиндексирована
+}
+
+// newSpatialIndex создаёт пустой индекс с указанным размером ячейки
+func newSpatialIndex(cellSize int) *spatialIndex {
+	if cellSize <= 0 {
+		cellSize = DefaultSpatialCellSize
+	}
+	return &spatialIndex{
+		cellSize: float64(cellSize),
+		cells:    make(map[vec.Vec2]map[uint64]*Entity),
+		cellOf:   make(map[uint64]vec.Vec2),
+	}
+}
+
+// cellFor возвращает ячейку, содержащую точку
+func (si *spatialIndex) cellFor(pos vec.Vec2Float) vec.Vec2 {
+	return vec.Vec2{
+		X: int(math.Floor(pos.X / si.cellSize)),
+		Y: int(math.Floor(pos.Y / si.cellSize)),
+	}
+}
+
+// insert добавляет сущность в индекс (или переносит, если она уже проиндексирована)
+func (si *spatialIndex) insert(e *Entity) {
+	cell := si.cellFor(e.PrecisePos)
+	if old, exists := si.cellOf[e.ID]; exists {
+		if old == cell {
+			si.cells[cell][e.ID] = e
+			return
+		}
+		si.removeFromCell(old, e.ID)
+	}
+
+	bucket, exists := si.cells[cell]
+	if !exists {
+		bucket = make(map[uint64]*Entity)
+		si.cells[cell] = bucket
+	}
+	bucket[e.ID] = e
+	si.cellOf[e.ID] = cell
+}
+
+// update переносит сущность в новую ячейку, если она её покинула
+func (si *spatialIndex) update(e *Entity) {
+	if cell, exists := si.cellOf[e.ID]; exists && cell == si.cellFor(e.PrecisePos) {
+		return
+	}
+	si.insert(e)
+}
+
+// remove удаляет сущность из индекса
+func (si *spatialIndex) remove(entityID uint64) {
+	cell, exists := si.cellOf[entityID]
+	if !exists {
+		return
+	}
+	si.removeFromCell(cell, entityID)
+	delete(si.cellOf, entityID)
+}
+
+func (si *spatialIndex) removeFromCell(cell vec.Vec2, entityID uint64) {
+	bucket := si.cells[cell]
+	delete(bucket, entityID)
+	if len(bucket) == 0 {
+		delete(si.cells, cell)
+	}
+}
+
+// queryRadius вызывает fn для каждой сущности в ячейках, пересекающих круг,
+// по возрастанию ID: ячейки и корзины - карты, и без сортировки порядок (а за
+// ним и решения поведений, берущих первую сущность) менялся бы от прогона к
+// прогону. Точную проверку расстояния выполняет вызывающий код.
+func (si *spatialIndex) queryRadius(center vec.Vec2Float, radius float64, fn func(*Entity)) {
+	minCell := si.cellFor(vec.Vec2Float{X: center.X - radius, Y: center.Y - radius})
+	maxCell := si.cellFor(vec.Vec2Float{X: center.X + radius, Y: center.Y + radius})
+
+	var found []*Entity
+
+	// Для очень больших радиусов дешевле обойти занятые ячейки, чем все ячейки квадрата
+	area := (int64(maxCell.X-minCell.X) + 1) * (int64(maxCell.Y-minCell.Y) + 1)
+	if area > int64(len(si.cells)) {
+		for cell, bucket := range si.cells {
+			if cell.X < minCell.X || cell.X > maxCell.X || cell.Y < minCell.Y || cell.Y > maxCell.Y {
+				continue
+			}
+			for _, e := range bucket {
+				found = append(found, e)
+			}
+		}
+	} else {
+		for x := minCell.X; x <= maxCell.X; x++ {
+			for y := minCell.Y; y <= maxCell.Y; y++ {
+				for _, e := range si.cells[vec.Vec2{X: x, Y: y}] {
+					found = append(found, e)
+				}
+			}
+		}
+	}
+
+	slices.SortFunc(found, func(a, b *Entity) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	for _, e := range found {
+		fn(e)
+	}
+}
@@ -0,0 +1,134 @@
+package entity
+
+import (
+	"cmp"
+	"math/rand"
+	"slices"
+	"sort"
+	"testing"
+
+	"github.com/annel0/mmo-game/internal/vec"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// populateManager создаёт менеджер со случайно расставленными сущностями
+func populateManager(rng *rand.Rand, count int, worldSize float64) *EntityManager {
+	em := NewEntityManager()
+	for i := 0; i < count; i++ {
+		e := NewEntity(uint64(i+1), EntityTypeNPC, vec.Vec2{})
+		e.PrecisePos = vec.Vec2Float{X: (rng.Float64() - 0.5) * worldSize, Y: (rng.Float64() - 0.5) * worldSize}
+		e.Position = e.PrecisePos.ToVec2()
+		em.AddEntity(e)
+	}
+	return em
+}
+
+func entityIDs(entities []*Entity) []uint64 {
+	ids := make([]uint64, 0, len(entities))
+	for _, e := range entities {
+		ids = append(ids, e.ID)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+func TestGetEntitiesInRange_MatchesLinearScan(t *testing.T) {
+	rng := rand.New(rand.NewSource(42))
+	em := populateManager(rng, 2000, 1000)
+
+	check := func() {
+		t.Helper()
+		for i := 0; i < 200; i++ {
+			center := vec.Vec2{X: rng.Intn(1200) - 600, Y: rng.Intn(1200) - 600}
+			radius := []float64{0, 1.5, 2, 10, 33, 100, 2000}[i%7]
+			require.Equal(t,
+				entityIDs(em.getEntitiesInRangeLinear(center, radius)),
+				entityIDs(em.GetEntitiesInRange(center, radius)),
+				"center=%v radius=%.1f", center, radius)
+		}
+	}
+	check()
+
+	// Перемещения, деспаун и деактивация должны отражаться в индексе
+	for id := uint64(1); id <= 2000; id++ {
+		switch id % 4 {
+		case 0:
+			em.MoveEntity(id, vec.Vec2Float{X: (rng.Float64() - 0.5) * 1000, Y: (rng.Float64() - 0.5) * 1000})
+		case 1:
+			em.DespawnEntity(id, nil)
+		case 2:
+			e, _ := em.GetEntity(id)
+			e.Active = false
+		}
+	}
+	check()
+}
+
+func TestGetEntitiesInRange_FollowsBehaviorMovement(t *testing.T) {
+	em := NewEntityManager()
+	e := NewEntity(1, EntityTypeNPC, vec.Vec2{X: 0, Y: 0})
+	em.AddEntity(e)
+	em.RegisterBehavior(EntityTypeNPC, &driftBehavior{velocity: vec.Vec2Float{X: 100, Y: 0}})
+
+	em.UpdateEntities(1, nil)
+
+	assert.Empty(t, em.GetEntitiesInRange(vec.Vec2{X: 0, Y: 0}, 5))
+	found := em.GetEntitiesInRange(vec.Vec2{X: 100, Y: 0}, 5)
+	require.Len(t, found, 1)
+	assert.Equal(t, uint64(1), found[0].ID)
+}
+
+// driftBehavior сдвигает сущность напрямую, минуя MoveEntity, как это делают поведения NPC
+type driftBehavior struct {
+	velocity vec.Vec2Float
+}
+
+func (d *driftBehavior) Update(api EntityAPI, entity *Entity, dt float64) {
+	entity.PrecisePos = entity.PrecisePos.Add(d.velocity.Mul(dt))
+	entity.Position = entity.PrecisePos.ToVec2()
+}
+func (d *driftBehavior) OnSpawn(api EntityAPI, entity *Entity)   {}
+func (d *driftBehavior) OnDespawn(api EntityAPI, entity *Entity) {}
+func (d *driftBehavior) OnDamage(api EntityAPI, entity *Entity, damage int, source interface{}) bool {
+	return false
+}
+func (d *driftBehavior) OnCollision(api EntityAPI, entity *Entity, other interface{}, collisionPoint vec.Vec2Float) {
+}
+func (d *driftBehavior) GetMoveSpeed() float64 { return 0 }
+
+func benchmarkRangeQuery(b *testing.B, query func(em *EntityManager, center vec.Vec2, radius float64) []*Entity) {
+	rng := rand.New(rand.NewSource(1))
+	em := populateManager(rng, 5000, 2000)
+	centers := make([]vec.Vec2, 1024)
+	for i := range centers {
+		centers[i] = vec.Vec2{X: rng.Intn(2000) - 1000, Y: rng.Intn(2000) - 1000}
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		query(em, centers[i%len(centers)], 32)
+	}
+}
+
+func BenchmarkGetEntitiesInRange_Linear(b *testing.B) {
+	benchmarkRangeQuery(b, (*EntityManager).getEntitiesInRangeLinear)
+}
+
+func BenchmarkGetEntitiesInRange_Indexed(b *testing.B) {
+	benchmarkRangeQuery(b, (*EntityManager).GetEntitiesInRange)
+}
+
+func TestGetEntitiesInRange_OrderedByID(t *testing.T) {
+	em := populateManager(rand.New(rand.NewSource(7)), 500, 200)
+
+	// 10 и 50 обходят ячейки квадрата, 5000 - только занятые ячейки
+	for _, radius := range []float64{10, 50, 5000} {
+		found := em.GetEntitiesInRange(vec.Vec2{}, radius)
+		require.NotEmpty(t, found, "radius=%v", radius)
+		assert.True(t, slices.IsSortedFunc(found, func(a, b *Entity) int {
+			return cmp.Compare(a.ID, b.ID)
+		}), "radius=%v", radius)
+	}
+}
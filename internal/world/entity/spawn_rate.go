@@ -0,0 +1,153 @@
+package entity
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// DefaultWorldSpawnRate - сколько мировых сущностей (животные, NPC,
+	// предметы, снаряды) в секунду может появиться после начального запаса
+	DefaultWorldSpawnRate = 50
+
+	// DefaultWorldSpawnBurst - начальный запас мировых спавнов
+	DefaultWorldSpawnBurst = 100
+)
+
+// Виды спавнов в метриках: игроки не ограничиваются, мировые сущности - да
+const (
+	SpawnKindPlayer = "player"
+	SpawnKindWorld  = "world"
+)
+
+var (
+	entitySpawns = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "entity",
+		Name:      "spawns_total",
+		Help:      "Созданные сущности по виду: player или world (менеджер сущностей и BigChunk).",
+	}, []string{"kind"})
+
+	rejectedWorldSpawns = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "entity",
+		Name:      "spawns_rejected_total",
+		Help:      "Мировые спавны, отклонённые ограничением частоты.",
+	})
+
+	entityDespawns = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "entity",
+		Name:      "despawns_total",
+		Help:      "Удалённые сущности по виду: player или world (менеджер сущностей и BigChunk).",
+	}, []string{"kind"})
+)
+
+func init() {
+	prometheus.MustRegister(entitySpawns, rejectedWorldSpawns, entityDespawns)
+}
+
+// spawnKind возвращает вид сущности в метриках спавнов и удалений
+func spawnKind(entityType EntityType) string {
+	if entityType == EntityTypePlayer {
+		return SpawnKindPlayer
+	}
+	return SpawnKindWorld
+}
+
+// CountDespawn учитывает удаление сущности entityType в entity_despawns_total.
+// Менеджер сущностей вызывает его в DespawnEntity, BigChunk - при удалении
+// и вытеснении своих сущностей; замена сущностей области снимком не учитывается
+func CountDespawn(entityType EntityType) {
+	entityDespawns.WithLabelValues(spawnKind(entityType)).Inc()
+}
+
+// spawnRateLimit ограничивает частоту мировых спавнов на весь сервер: каждый
+// спавн рассылается всем клиентам, и всплеск спавнов (размножение животных,
+// разрушение постройки) забивает очереди отправки. Игроки не ограничиваются
+type spawnRateLimit struct {
+	mu     sync.Mutex
+	now    func() time.Time
+	rate   float64 // Спавнов в секунду (0 - без ограничения)
+	burst  float64 // Начальный и максимальный запас
+	tokens float64
+	last   time.Time
+
+	lastWarn time.Time // Последнее сообщение об отклонённом спавне
+}
+
+// newSpawnRateLimit создаёт ограничение со значениями по умолчанию
+func newSpawnRateLimit() *spawnRateLimit {
+	return &spawnRateLimit{
+		now:    time.Now,
+		rate:   DefaultWorldSpawnRate,
+		burst:  DefaultWorldSpawnBurst,
+		tokens: DefaultWorldSpawnBurst,
+	}
+}
+
+// SetWorldSpawnRateLimit задаёт частоту мировых спавнов и их начальный запас.
+// Спавны сверх лимита отклоняются (см. AdmitSpawn). 0 - значения по
+// умолчанию, отрицательная частота - без ограничения
+func (em *EntityManager) SetWorldSpawnRateLimit(rate, burst int) {
+	if rate == 0 {
+		rate = DefaultWorldSpawnRate
+	}
+	if burst <= 0 {
+		burst = DefaultWorldSpawnBurst
+	}
+
+	l := em.spawnRate
+	l.mu.Lock()
+	l.rate = float64(max(rate, 0))
+	l.burst = float64(burst)
+	l.tokens = l.burst
+	l.last = l.now()
+	l.mu.Unlock()
+}
+
+// AdmitSpawn учитывает спавн сущности entityType. Игроки проходят всегда;
+// false - мировой спавн отклонён ограничением частоты, и сущность не
+// создаётся. Вызывается во всех точках спавна: менеджера сущностей, BigChunk
+// (WorldManager.SpawnEntity) и сетевого обработчика
+func (em *EntityManager) AdmitSpawn(entityType EntityType) bool {
+	if entityType == EntityTypePlayer {
+		entitySpawns.WithLabelValues(SpawnKindPlayer).Inc()
+		return true
+	}
+	return em.AdmitWorldSpawn()
+}
+
+// AdmitWorldSpawn - AdmitSpawn для мировой сущности
+func (em *EntityManager) AdmitWorldSpawn() bool {
+	l := em.spawnRate
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.rate > 0 {
+		now := l.now()
+		if l.last.IsZero() {
+			l.last = now
+		}
+		l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+		l.last = now
+		if l.tokens < 1 {
+			rejectedWorldSpawns.Inc()
+			l.warnLocked(now)
+			return false
+		}
+		l.tokens--
+	}
+	entitySpawns.WithLabelValues(SpawnKindWorld).Inc()
+	return true
+}
+
+// warnLocked сообщает об отклонённом спавне не чаще раза в секунду.
+// Вызывается под l.mu
+func (l *spawnRateLimit) warnLocked(now time.Time) {
+	if now.Sub(l.lastWarn) < time.Second {
+		return
+	}
+	l.lastWarn = now
+	log.Printf("⚠️ Мировой спавн отклонён: превышена частота мировых спавнов (%.0f/с)", l.rate)
+}
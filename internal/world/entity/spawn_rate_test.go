@@ -0,0 +1,81 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorldSpawnRate_CapsWorldSpawnsButNotPlayers(t *testing.T) {
+	em := NewEntityManager()
+	em.RegisterDefaultBehaviors()
+	now := time.Now()
+	em.spawnRate.now = func() time.Time { return now }
+	em.SetWorldSpawnRateLimit(2, 5)
+
+	worldBefore := testutil.ToFloat64(entitySpawns.WithLabelValues(SpawnKindWorld))
+	playersBefore := testutil.ToFloat64(entitySpawns.WithLabelValues(SpawnKindPlayer))
+	rejectedBefore := testutil.ToFloat64(rejectedWorldSpawns)
+
+	// Всплеск мировых спавнов упирается в начальный запас, какой бы точкой
+	// спавна он ни шёл
+	spawned := 0
+	for i := 0; i < 20; i++ {
+		var id uint64
+		switch i % 3 {
+		case 0:
+			id = em.SpawnEntity(EntityTypeItem, vec.Vec2{X: i}, nil)
+		case 1:
+			id = em.SpawnAnimal(AnimalTypeCow, vec.Vec2{X: i}, nil)
+		default:
+			id = em.SpawnNPC("villager", vec.Vec2{X: i}, nil)
+		}
+		if id != 0 {
+			spawned++
+		}
+	}
+	assert.Equal(t, 5, spawned)
+	assert.Equal(t, 5, em.Population())
+	assert.Equal(t, 5.0, testutil.ToFloat64(entitySpawns.WithLabelValues(SpawnKindWorld))-worldBefore)
+	assert.Equal(t, 15.0, testutil.ToFloat64(rejectedWorldSpawns)-rejectedBefore)
+
+	// Игроки появляются, даже когда запас исчерпан
+	for i := 0; i < 3; i++ {
+		require.NotZero(t, em.SpawnEntity(EntityTypePlayer, vec.Vec2{Y: i}, nil))
+	}
+	assert.Equal(t, 3.0, testutil.ToFloat64(entitySpawns.WithLabelValues(SpawnKindPlayer))-playersBefore)
+
+	// Запас восполняется со временем
+	now = now.Add(time.Second)
+	spawned = 0
+	for i := 0; i < 5; i++ {
+		if em.AdmitWorldSpawn() {
+			spawned++
+		}
+	}
+	assert.Equal(t, 2, spawned)
+}
+
+func TestEntityDespawns_CountedByKind(t *testing.T) {
+	em := NewEntityManager()
+	em.RegisterDefaultBehaviors()
+	em.SetWorldSpawnRateLimit(-1, 0)
+	player := em.SpawnEntity(EntityTypePlayer, vec.Vec2{}, nil)
+	item := em.SpawnEntity(EntityTypeItem, vec.Vec2{X: 1}, nil)
+	require.NotZero(t, player)
+	require.NotZero(t, item)
+
+	worldBefore := testutil.ToFloat64(entityDespawns.WithLabelValues(SpawnKindWorld))
+	playersBefore := testutil.ToFloat64(entityDespawns.WithLabelValues(SpawnKindPlayer))
+
+	require.True(t, em.DespawnEntity(player, nil))
+	require.True(t, em.DespawnEntity(item, nil))
+	assert.False(t, em.DespawnEntity(item, nil), "повторное удаление не учитывается")
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(entityDespawns.WithLabelValues(SpawnKindPlayer))-playersBefore)
+	assert.Equal(t, 1.0, testutil.ToFloat64(entityDespawns.WithLabelValues(SpawnKindWorld))-worldBefore)
+}
@@ -0,0 +1,327 @@
+package world
+
+import (
+	"log"
+	"slices"
+	"sync"
+
+	"github.com/annel0/mmo-game/internal/vec"
+	entitypkg "github.com/annel0/mmo-game/internal/world/entity"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// DefaultMaxEntitiesPerBigChunk - предел сущностей в одном BigChunk по умолчанию
+	DefaultMaxEntitiesPerBigChunk = 2000
+
+	// DefaultMaxWorldEntities - предел сущностей во всём мире по умолчанию
+	DefaultMaxWorldEntities = 100000
+)
+
+// Области, в которых действует предел сущностей
+const (
+	entityCapBigChunk = "bigchunk"
+	entityCapWorld    = "world"
+)
+
+// rejectedEntitySpawns считает появления сущностей, отклонённые из-за предела
+var rejectedEntitySpawns = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "world",
+	Name:      "entity_spawns_rejected_total",
+	Help:      "Появления сущностей, отклонённые из-за предела числа сущностей.",
+}, []string{"scope"})
+
+// evictedEntities считает выброшенные предметы, удалённые ради новых сущностей
+var evictedEntities = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "world",
+	Name:      "entities_evicted_total",
+	Help:      "Самые старые выброшенные предметы, удалённые при достижении предела сущностей.",
+}, []string{"scope"})
+
+// rejectedEntityMoves считает переходы сущностей в заполненный BigChunk,
+// отклонённые вместо потери сущности
+var rejectedEntityMoves = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "world",
+	Name:      "entity_moves_rejected_total",
+	Help:      "Переходы сущностей в BigChunk, отклонённые из-за предела числа сущностей.",
+}, []string{"scope"})
+
+// worldEntitiesDesc - число сущностей мира; считается при сборе метрик
+// отдельно для каждого WorldManager с подключённым менеджером сущностей
+var worldEntitiesDesc = prometheus.NewDesc("world_entities",
+	"Число сущностей мира: сущности BigChunk и менеджера сущностей.", []string{"world"}, nil)
+
+// entityCollector отдаёт world_entities всех миров, обслуживающих игроков
+type entityCollector struct {
+	mu     sync.Mutex
+	worlds map[*WorldManager]struct{}
+}
+
+var worldEntityCollector = &entityCollector{worlds: make(map[*WorldManager]struct{})}
+
+func (c *entityCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- worldEntitiesDesc
+}
+
+func (c *entityCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	byWorld := make(map[string]int, len(c.worlds))
+	for wm := range c.worlds {
+		byWorld[wm.metricsLabel()] += wm.EntityCount()
+	}
+	c.mu.Unlock()
+
+	for world, count := range byWorld {
+		ch <- prometheus.MustNewConstMetric(worldEntitiesDesc, prometheus.GaugeValue, float64(count), world)
+	}
+}
+
+func (c *entityCollector) add(wm *WorldManager) {
+	c.mu.Lock()
+	c.worlds[wm] = struct{}{}
+	c.mu.Unlock()
+}
+
+func (c *entityCollector) remove(wm *WorldManager) {
+	c.mu.Lock()
+	delete(c.worlds, wm)
+	c.mu.Unlock()
+}
+
+func init() {
+	prometheus.MustRegister(rejectedEntitySpawns, evictedEntities, rejectedEntityMoves, worldEntityCollector)
+}
+
+// SetEntitySource подключает менеджер сущностей, в котором живут игроки, NPC
+// и выброшенные предметы. По нему BigChunk узнают о присутствии игроков, а
+// пределы сущностей учитывают его сущности наравне с сущностями BigChunk.
+// Генератор поведений менеджера получает сид мира
+func (wm *WorldManager) SetEntitySource(em *entitypkg.EntityManager) {
+	wm.entities.Store(em)
+	if em != nil {
+		em.SetRandSeed(wm.seed)
+		worldEntityCollector.add(wm)
+	} else {
+		worldEntityCollector.remove(wm)
+	}
+}
+
+// metricsLabel возвращает метку мира в метриках
+func (wm *WorldManager) metricsLabel() string {
+	if wm.worldID == "" {
+		return "default"
+	}
+	return wm.worldID
+}
+
+// SetEntityCaps задаёт предел сущностей в одном BigChunk и во всём мире
+// (0 - значение по умолчанию, отрицательное - без ограничения)
+func (wm *WorldManager) SetEntityCaps(perBigChunk, total int) {
+	if perBigChunk == 0 {
+		perBigChunk = DefaultMaxEntitiesPerBigChunk
+	}
+	if total == 0 {
+		total = DefaultMaxWorldEntities
+	}
+	wm.chunkEntityCap.Store(int64(max(perBigChunk, 0)))
+	wm.worldEntityCap.Store(int64(max(total, 0)))
+}
+
+// EntityCount возвращает число сущностей мира: в BigChunk и в менеджере сущностей
+func (wm *WorldManager) EntityCount() int {
+	count := int(wm.entityCount.Load())
+	if em := wm.entities.Load(); em != nil {
+		count += em.Population()
+	}
+	return count
+}
+
+// isEvictableEntity сообщает, можно ли удалить сущность ради новой:
+// выброшенные предметы - единственные сущности, потеря которых некритична
+func isEvictableEntity(entityType uint16) bool {
+	return entityType == uint16(entitypkg.EntityTypeItem)
+}
+
+// overEntityCap возвращает область, предел которой не даёт добавить ещё одну
+// сущность в BigChunk coords ("" - место есть). local - сущности самого
+// BigChunk, released - сущности менеджера, уже выбранные для вытеснения
+func (wm *WorldManager) overEntityCap(coords vec.Vec2, local, released int) string {
+	managed, total := 0, int(wm.entityCount.Load())
+	if em := wm.entities.Load(); em != nil {
+		managed = em.BigChunkPopulation(coords)
+		total += em.Population()
+	}
+	if limit := wm.chunkEntityCap.Load(); limit > 0 && int64(local+managed-released) >= limit {
+		return entityCapBigChunk
+	}
+	if limit := wm.worldEntityCap.Load(); limit > 0 && int64(total-released) >= limit {
+		return entityCapWorld
+	}
+	return ""
+}
+
+// bigChunkEntities возвращает число сущностей BigChunk (0 - BigChunk не загружен)
+func (wm *WorldManager) bigChunkEntities(coords vec.Vec2) int {
+	wm.mu.RLock()
+	bc, exists := wm.bigChunks[coords]
+	wm.mu.RUnlock()
+	if !exists {
+		return 0
+	}
+
+	bc.mu.RLock()
+	defer bc.mu.RUnlock()
+	return len(bc.entities)
+}
+
+// AdmitEntity решает, можно ли создать в менеджере сущностей сущность типа
+// entityType в позиции pos. При достижении предела освобождается место за
+// счёт самых старых выброшенных предметов BigChunk: их ID возвращаются, и
+// вызывающий должен удалить их. Игрок допускается и сверх предела
+func (wm *WorldManager) AdmitEntity(entityType entitypkg.EntityType, pos vec.Vec2) ([]uint64, bool) {
+	coords := pos.ToBigChunkCoords()
+	local := wm.bigChunkEntities(coords)
+
+	var evict, candidates []uint64
+	var scopes []string
+	for {
+		scope := wm.overEntityCap(coords, local, len(evict))
+		if scope == "" {
+			for _, scope := range scopes {
+				evictedEntities.WithLabelValues(scope).Inc()
+			}
+			return evict, true
+		}
+
+		if candidates == nil {
+			if em := wm.entities.Load(); em != nil {
+				candidates = em.OldestInBigChunk(coords, entitypkg.EntityTypeItem)
+			}
+		}
+		if len(evict) < len(candidates) {
+			evict = append(evict, candidates[len(evict)])
+			scopes = append(scopes, scope)
+			continue
+		}
+
+		if entityType == entitypkg.EntityTypePlayer {
+			log.Printf("⚠️ BigChunk %v: предел сущностей (%s) достигнут, игрок допущен сверх него", coords, scope)
+			return evict, true
+		}
+		rejectedEntitySpawns.WithLabelValues(scope).Inc()
+		log.Printf("⚠️ BigChunk %v: предел сущностей (%s) достигнут, сущность типа %d отклонена", coords, scope, entityType)
+		return nil, false
+	}
+}
+
+// CanEnterBigChunk сообщает, может ли сущность перейти из from в to: переход
+// в другой BigChunk, заполненный до предела, отклоняется, чтобы сущность
+// осталась на месте, а не потерялась. Игроки переходят всегда
+func (wm *WorldManager) CanEnterBigChunk(entityType entitypkg.EntityType, from, to vec.Vec2) bool {
+	coords := to.ToBigChunkCoords()
+	if entityType == entitypkg.EntityTypePlayer || from.ToBigChunkCoords() == coords {
+		return true
+	}
+	scope := wm.overEntityCap(coords, wm.bigChunkEntities(coords), 0)
+	if scope == "" {
+		return true
+	}
+	rejectedEntityMoves.WithLabelValues(scope).Inc()
+	return false
+}
+
+// admitEntityLocked освобождает место под новую сущность BigChunk, удаляя
+// самые старые выброшенные предметы BigChunk. Если удалять нечего, игрок всё
+// равно допускается сверх предела, остальные сущности отклоняются. Сущность
+// без указанного типа игроком не считается.
+// Вызывающий должен держать bc.mu на запись
+func (bc *BigChunk) admitEntityLocked(entityType uint16, player bool) bool {
+	if bc.world == nil {
+		return true
+	}
+	for {
+		scope := bc.world.overEntityCap(bc.coords, len(bc.entities), 0)
+		if scope == "" {
+			return true
+		}
+		if bc.evictOldestItemLocked(scope) {
+			continue
+		}
+
+		if player {
+			log.Printf("⚠️ BigChunk %v: предел сущностей (%s) достигнут, игрок допущен сверх него", bc.coords, scope)
+			return true
+		}
+		rejectedEntitySpawns.WithLabelValues(scope).Inc()
+		log.Printf("⚠️ BigChunk %v: предел сущностей (%s) достигнут, сущность типа %d отклонена", bc.coords, scope, entityType)
+		return false
+	}
+}
+
+// evictOldestItemLocked удаляет самый старый выброшенный предмет BigChunk.
+// Вызывающий должен держать bc.mu на запись
+func (bc *BigChunk) evictOldestItemLocked(scope string) bool {
+	for len(bc.itemOrder) > 0 {
+		entityID := bc.itemOrder[0]
+		bc.itemOrder = bc.itemOrder[1:]
+
+		data, exists := bc.entities[entityID]
+		if !exists || !isEvictableEntity(data.Type) {
+			continue // Предмет уже удалён другим путём
+		}
+
+		bc.removeEntityLocked(entityID)
+		evictedEntities.WithLabelValues(scope).Inc()
+		entitypkg.CountDespawn(entitypkg.EntityType(data.Type))
+		bc.sendToWorld(EntityEvent{
+			EventType: EventTypeEntityDespawn,
+			EntityID:  entityID,
+			Position:  data.Position,
+		})
+		return true
+	}
+	return false
+}
+
+// addEntityLocked добавляет или заменяет сущность BigChunk с учётом счётчиков
+// пределов и сообщает её ID аллокатору мира, чтобы новые ID его не повторили.
+// Вызывающий должен держать bc.mu на запись
+func (bc *BigChunk) addEntityLocked(data EntityData) {
+	if bc.world != nil {
+		bc.world.EntityIDAllocator().Observe(data.ID)
+	}
+	if _, exists := bc.entities[data.ID]; !exists {
+		bc.trackEntities(1)
+	}
+	bc.entities[data.ID] = data
+	if isEvictableEntity(data.Type) {
+		bc.itemOrder = append(bc.itemOrder, data.ID)
+	}
+}
+
+// removeEntityLocked удаляет сущность BigChunk с учётом счётчиков пределов.
+// Вызывающий должен держать bc.mu на запись
+func (bc *BigChunk) removeEntityLocked(entityID uint64) {
+	if _, exists := bc.entities[entityID]; !exists {
+		return
+	}
+	delete(bc.entities, entityID)
+	bc.trackEntities(-1)
+
+	// Удалённые предметы остаются в очереди до вытеснения; очередь
+	// сжимается, когда устаревших записей становится больше живых
+	if len(bc.itemOrder) > 2*len(bc.entities)+16 {
+		bc.itemOrder = slices.DeleteFunc(bc.itemOrder, func(id uint64) bool {
+			_, alive := bc.entities[id]
+			return !alive
+		})
+	}
+}
+
+// trackEntities изменяет счётчик сущностей BigChunk мира
+func (bc *BigChunk) trackEntities(delta int64) {
+	if bc.world == nil {
+		return
+	}
+	bc.world.entityCount.Add(delta)
+}
@@ -0,0 +1,207 @@
+package world
+
+import (
+	"testing"
+
+	"github.com/annel0/mmo-game/internal/vec"
+	entitypkg "github.com/annel0/mmo-game/internal/world/entity"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// spawnTestEntity создаёт сущность указанного типа напрямую в BigChunk
+func spawnTestEntity(bc *BigChunk, id uint64, entityType entitypkg.EntityType) {
+	bc.spawnEntity(EntityEvent{
+		EventType: EventTypeEntitySpawn,
+		EntityID:  id,
+		Position:  vec.Vec2{X: int(id % 16), Y: 0},
+		Data:      map[string]interface{}{"type": uint16(entityType)},
+	})
+}
+
+func hasEntity(bc *BigChunk, id uint64) bool {
+	bc.mu.RLock()
+	defer bc.mu.RUnlock()
+	_, exists := bc.entities[id]
+	return exists
+}
+
+func TestEntityCap_RejectsSpawnsOverBigChunkCap(t *testing.T) {
+	wm := NewWorldManager(1)
+	wm.SetEntityCaps(3, -1)
+	bc := NewBigChunk(vec.Vec2{}, wm, make(chan Event, 64))
+
+	rejected := testutil.ToFloat64(rejectedEntitySpawns.WithLabelValues(entityCapBigChunk))
+	for id := uint64(1); id <= 4; id++ {
+		spawnTestEntity(bc, id, entitypkg.EntityTypeNPC)
+	}
+
+	assert.True(t, hasEntity(bc, 3))
+	assert.False(t, hasEntity(bc, 4), "сущность сверх предела отклонена")
+	assert.Equal(t, 3, wm.EntityCount())
+	assert.Equal(t, rejected+1, testutil.ToFloat64(rejectedEntitySpawns.WithLabelValues(entityCapBigChunk)))
+
+	// Игрока не вытеснить нечем, но он всё равно допускается
+	spawnTestEntity(bc, 5, entitypkg.EntityTypePlayer)
+	assert.True(t, hasEntity(bc, 5))
+
+	// Удаление освобождает место
+	bc.despawnEntity(EntityEvent{EventType: EventTypeEntityDespawn, EntityID: 1})
+	bc.despawnEntity(EntityEvent{EventType: EventTypeEntityDespawn, EntityID: 2})
+	spawnTestEntity(bc, 6, entitypkg.EntityTypeNPC)
+	assert.True(t, hasEntity(bc, 6))
+	assert.Equal(t, 3, wm.EntityCount())
+}
+
+func TestEntityCap_EvictsOldestItemsFirst(t *testing.T) {
+	wm := NewWorldManager(1)
+	wm.SetEntityCaps(4, -1)
+	events := make(chan Event, 64)
+	bc := NewBigChunk(vec.Vec2{}, wm, events)
+
+	spawnTestEntity(bc, 1, entitypkg.EntityTypeItem)
+	spawnTestEntity(bc, 2, entitypkg.EntityTypeNPC)
+	spawnTestEntity(bc, 3, entitypkg.EntityTypeItem)
+	spawnTestEntity(bc, 4, entitypkg.EntityTypeItem)
+
+	evicted := testutil.ToFloat64(evictedEntities.WithLabelValues(entityCapBigChunk))
+	spawnTestEntity(bc, 10, entitypkg.EntityTypePlayer)
+	spawnTestEntity(bc, 11, entitypkg.EntityTypeNPC)
+
+	assert.True(t, hasEntity(bc, 10))
+	assert.True(t, hasEntity(bc, 11))
+	assert.False(t, hasEntity(bc, 1), "самый старый предмет вытеснен первым")
+	assert.False(t, hasEntity(bc, 3))
+	assert.True(t, hasEntity(bc, 4))
+	assert.True(t, hasEntity(bc, 2), "NPC не вытесняются")
+	assert.Equal(t, evicted+2, testutil.ToFloat64(evictedEntities.WithLabelValues(entityCapBigChunk)))
+	assert.Equal(t, 4, wm.EntityCount())
+
+	// Клиенты узнают о вытеснении из обычного события удаления
+	var despawned []uint64
+	for len(events) > 0 {
+		if ev, ok := (<-events).(EntityEvent); ok && ev.EventType == EventTypeEntityDespawn {
+			despawned = append(despawned, ev.EntityID)
+		}
+	}
+	assert.Equal(t, []uint64{1, 3}, despawned)
+}
+
+func TestEntityCap_WorldCapSpansBigChunks(t *testing.T) {
+	wm := NewWorldManager(1)
+	wm.SetEntityCaps(-1, 2)
+	first := NewBigChunk(vec.Vec2{}, wm, make(chan Event, 64))
+	second := NewBigChunk(vec.Vec2{X: 1}, wm, make(chan Event, 64))
+
+	spawnTestEntity(first, 1, entitypkg.EntityTypeNPC)
+	spawnTestEntity(first, 2, entitypkg.EntityTypeNPC)
+	spawnTestEntity(second, 3, entitypkg.EntityTypeMonster)
+
+	require.Equal(t, 2, wm.EntityCount())
+	assert.False(t, hasEntity(second, 3), "предел мира учитывает все BigChunk")
+}
+
+func TestEntityCap_UntypedSpawnIsNotPlayer(t *testing.T) {
+	wm := NewWorldManager(1)
+	wm.SetEntityCaps(1, -1)
+	bc := NewBigChunk(vec.Vec2{}, wm, make(chan Event, 64))
+
+	spawnTestEntity(bc, 1, entitypkg.EntityTypeNPC)
+	bc.spawnEntity(EntityEvent{EventType: EventTypeEntitySpawn, EntityID: 2, Position: vec.Vec2{X: 2}})
+	assert.False(t, hasEntity(bc, 2), "сущность без типа не проходит сверх предела как игрок")
+}
+
+func TestEntityCap_CountsEntityManagerAndEvictsItsItems(t *testing.T) {
+	wm := NewWorldManager(1)
+	wm.SetEntityCaps(3, -1)
+	em := entitypkg.NewEntityManager()
+	wm.SetEntitySource(em)
+	t.Cleanup(func() { wm.SetEntitySource(nil) })
+
+	em.AddEntity(entitypkg.NewEntity(10, entitypkg.EntityTypeItem, vec.Vec2{X: 1}))
+	em.AddEntity(entitypkg.NewEntity(11, entitypkg.EntityTypeNPC, vec.Vec2{X: 2}))
+	em.AddEntity(entitypkg.NewEntity(12, entitypkg.EntityTypeItem, vec.Vec2{X: 3}))
+	assert.Equal(t, 3, wm.EntityCount())
+
+	// Новая сущность вытесняет самый старый предмет менеджера
+	evicted := testutil.ToFloat64(evictedEntities.WithLabelValues(entityCapBigChunk))
+	evict, ok := wm.AdmitEntity(entitypkg.EntityTypeMonster, vec.Vec2{X: 4})
+	require.True(t, ok)
+	assert.Equal(t, []uint64{10}, evict)
+	assert.Equal(t, evicted+1, testutil.ToFloat64(evictedEntities.WithLabelValues(entityCapBigChunk)))
+
+	// Без предметов сущность отклоняется, игрок допускается
+	em.DespawnEntity(10, nil)
+	em.DespawnEntity(12, nil)
+	em.AddEntity(entitypkg.NewEntity(13, entitypkg.EntityTypeNPC, vec.Vec2{X: 5}))
+	em.AddEntity(entitypkg.NewEntity(14, entitypkg.EntityTypeNPC, vec.Vec2{X: 6}))
+	_, ok = wm.AdmitEntity(entitypkg.EntityTypeNPC, vec.Vec2{X: 7})
+	assert.False(t, ok)
+	_, ok = wm.AdmitEntity(entitypkg.EntityTypePlayer, vec.Vec2{X: 7})
+	assert.True(t, ok)
+
+	// Сущности BigChunk учитываются вместе с сущностями менеджера
+	bc := NewBigChunk(vec.Vec2{}, wm, make(chan Event, 64))
+	spawnTestEntity(bc, 20, entitypkg.EntityTypeNPC)
+	assert.False(t, hasEntity(bc, 20))
+}
+
+func TestEntityCap_MoveIntoFullBigChunkIsRejected(t *testing.T) {
+	wm := NewWorldManager(1)
+	wm.SetEntityCaps(1, -1)
+	em := entitypkg.NewEntityManager()
+	wm.SetEntitySource(em)
+	t.Cleanup(func() { wm.SetEntitySource(nil) })
+
+	full := vec.Vec2{X: 600}
+	em.AddEntity(entitypkg.NewEntity(1, entitypkg.EntityTypeNPC, full))
+
+	rejected := testutil.ToFloat64(rejectedEntityMoves.WithLabelValues(entityCapBigChunk))
+	assert.False(t, wm.CanEnterBigChunk(entitypkg.EntityTypeNPC, vec.Vec2{X: 511}, full))
+	assert.False(t, wm.ProcessEntityMovement(2, entitypkg.EntityTypeAnimal, vec.Vec2{X: 511}, full))
+	assert.Equal(t, rejected+2, testutil.ToFloat64(rejectedEntityMoves.WithLabelValues(entityCapBigChunk)))
+
+	assert.True(t, wm.CanEnterBigChunk(entitypkg.EntityTypeNPC, vec.Vec2{X: 601}, full), "внутри BigChunk ходить можно")
+	assert.True(t, wm.CanEnterBigChunk(entitypkg.EntityTypePlayer, vec.Vec2{X: 511}, full))
+}
+
+func TestEntityCap_GaugePerWorld(t *testing.T) {
+	first, second := NewWorldManager(1), NewWorldManager(2)
+	first.SetWorldID("gauge-first")
+	second.SetWorldID("gauge-second")
+	for _, wm := range []*WorldManager{first, second} {
+		wm.SetEntitySource(entitypkg.NewEntityManager())
+		t.Cleanup(func() { wm.SetEntitySource(nil) })
+	}
+	first.entities.Load().AddEntity(entitypkg.NewEntity(1, entitypkg.EntityTypeNPC, vec.Vec2{}))
+
+	gauges := map[string]float64{}
+	metrics := make(chan prometheus.Metric, 16)
+	worldEntityCollector.Collect(metrics)
+	close(metrics)
+	for metric := range metrics {
+		var m dto.Metric
+		require.NoError(t, metric.Write(&m))
+		gauges[m.GetLabel()[0].GetValue()] = m.GetGauge().GetValue()
+	}
+	assert.Equal(t, 1.0, gauges["gauge-first"])
+	assert.Equal(t, 0.0, gauges["gauge-second"])
+}
+
+func TestBigChunkSpawn_AllocatesIDsFromWorldAllocator(t *testing.T) {
+	wm := NewWorldManager(1)
+	bc := NewBigChunk(vec.Vec2{}, wm, make(chan Event, 64))
+
+	// Сущность с явным ID (например, загруженная из хранилища) учитывается
+	// аллокатором, и новые ID её не повторяют
+	spawnTestEntity(bc, 40, entitypkg.EntityTypeNPC)
+	spawnTestEntity(bc, 0, entitypkg.EntityTypeNPC)
+	spawnTestEntity(bc, 0, entitypkg.EntityTypeNPC)
+
+	assert.True(t, hasEntity(bc, 41))
+	assert.True(t, hasEntity(bc, 42))
+	assert.Equal(t, uint64(43), wm.GenerateEntityID())
+}
@@ -0,0 +1,71 @@
+package world
+
+import (
+	"sort"
+
+	"github.com/annel0/mmo-game/internal/storage_interface"
+)
+
+// Snapshot возвращает сохраняемый снимок сущности. Метаданные копируются,
+// чтобы снимок можно было сериализовать без блокировки BigChunk
+func (d EntityData) Snapshot() storage_interface.EntitySnapshot {
+	return storage_interface.EntitySnapshot{
+		Version:   storage_interface.EntitySnapshotVersion,
+		ID:        d.ID,
+		Type:      d.Type,
+		Position:  d.PrecisePos,
+		Direction: d.Direction,
+		Velocity:  d.Velocity,
+		Health:    d.Health,
+		Payload:   copyMetadata(d.Metadata),
+	}
+}
+
+// entityDataFromSnapshot восстанавливает сущность из снимка
+func entityDataFromSnapshot(s storage_interface.EntitySnapshot) EntityData {
+	metadata := s.Payload
+	if metadata == nil {
+		metadata = make(map[string]interface{})
+	}
+	return EntityData{
+		ID:         s.ID,
+		Type:       s.Type,
+		Position:   s.BlockPosition(),
+		PrecisePos: s.Position,
+		Direction:  s.Direction,
+		Velocity:   s.Velocity,
+		Health:     s.Health,
+		Metadata:   metadata,
+	}
+}
+
+// entitySnapshotsLocked возвращает снимки сущностей BigChunk, упорядоченные
+// по ID. Вызывающий должен держать bc.mu
+func (bc *BigChunk) entitySnapshotsLocked() []storage_interface.EntitySnapshot {
+	snapshots := make([]storage_interface.EntitySnapshot, 0, len(bc.entities))
+	for _, entity := range bc.entities {
+		snapshots = append(snapshots, entity.Snapshot())
+	}
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].ID < snapshots[j].ID })
+	return snapshots
+}
+
+// applyEntitySnapshotsLocked добавляет загруженные сущности в BigChunk.
+// Вызывающий должен держать bc.mu на запись
+func (bc *BigChunk) applyEntitySnapshotsLocked(snapshots []storage_interface.EntitySnapshot) {
+	for _, snapshot := range snapshots {
+		bc.addEntityLocked(entityDataFromSnapshot(snapshot))
+	}
+}
+
+// copyMetadata возвращает поверхностную копию метаданных (nil остаётся nil)
+func copyMetadata(metadata map[string]interface{}) map[string]interface{} {
+	if metadata == nil {
+		return nil
+	}
+	result := make(map[string]interface{}, len(metadata))
+	for k, v := range metadata {
+		result[k] = v
+	}
+	return result
+}
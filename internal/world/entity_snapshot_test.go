@@ -0,0 +1,57 @@
+package world
+
+import (
+	"testing"
+
+	"github.com/annel0/mmo-game/internal/storage_interface"
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEntitySnapshots_SaveAndLoadThroughWorldManager(t *testing.T) {
+	saved := make(map[vec.Vec2][]storage_interface.EntitySnapshot)
+	save := func(coords vec.Vec2, entities []storage_interface.EntitySnapshot) error {
+		saved[coords] = entities
+		return nil
+	}
+	load := func(coords vec.Vec2) ([]storage_interface.EntitySnapshot, error) {
+		return saved[coords], nil
+	}
+
+	wm := NewWorldManager(1)
+	defer wm.cancelFunc()
+	wm.SetStorageFunctions(save, load)
+
+	wm.GetChunk(vec.Vec2{X: 0, Y: 0})
+	bigChunk := wm.bigChunks[chunkBigChunkCoords(vec.Vec2{X: 0, Y: 0})]
+	require.NotNil(t, bigChunk)
+
+	npc := EntityData{
+		ID:         5,
+		Type:       1,
+		Position:   vec.Vec2{X: 3, Y: 4},
+		PrecisePos: vec.Vec2Float{X: 3.5, Y: 4.25},
+		Direction:  1,
+		Velocity:   vec.Vec2Float{X: 0.5},
+		Health:     60,
+		Metadata:   map[string]interface{}{"dialog": "hello"},
+	}
+	bigChunk.mu.Lock()
+	bigChunk.entities[npc.ID] = npc
+	bigChunk.mu.Unlock()
+
+	wm.SaveWorld(true)
+	require.Len(t, saved[bigChunk.coords], 1)
+
+	// Новый мир загружает сущности при создании BigChunk
+	restored := NewWorldManager(1)
+	defer restored.cancelFunc()
+	restored.SetStorageFunctions(save, load)
+	restored.GetChunk(vec.Vec2{X: 0, Y: 0})
+
+	restoredChunk := restored.bigChunks[bigChunk.coords]
+	restoredChunk.mu.RLock()
+	defer restoredChunk.mu.RUnlock()
+	assert.Equal(t, npc, restoredChunk.entities[npc.ID])
+}
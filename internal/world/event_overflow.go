@@ -0,0 +1,180 @@
+package world
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// DefaultEventBufferSize - размер буфера глобальных событий WorldManager по умолчанию
+	DefaultEventBufferSize = 5000
+
+	// DefaultCriticalEventTimeout - сколько ждать места в переполненном канале
+	// для критичного события (изменение блока), прежде чем отбросить его
+	DefaultCriticalEventTimeout = 100 * time.Millisecond
+
+	// dropWarnInterval - не чаще одного сообщения об отброшенных событиях за интервал
+	dropWarnInterval = 10 * time.Second
+)
+
+// Каналы, в которых может переполниться очередь событий
+const (
+	eventChannelGlobal   = "global"   // WorldManager.globalEvents
+	eventChannelBigChunk = "bigchunk" // BigChunk.eventsIn
+)
+
+// droppedEvents считает события, отброшенные из-за переполнения каналов
+var droppedEvents = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "world",
+	Name:      "events_dropped_total",
+	Help:      "События мира, отброшенные из-за переполнения канала.",
+}, []string{"channel", "event"})
+
+// delayedCriticalEvents считает критичные события, доставленные после ожидания места в канале
+var delayedCriticalEvents = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "world",
+	Name:      "critical_events_delayed_total",
+	Help:      "Критичные события мира, которым пришлось ждать места в переполненном канале.",
+}, []string{"channel"})
+
+func init() {
+	prometheus.MustRegister(droppedEvents, delayedCriticalEvents)
+}
+
+// SetEventBufferSize задаёт размер буфера глобальных событий.
+// Вызывается до Run и до создания первого BigChunk: созданные BigChunk
+// продолжают писать в прежний канал.
+func (wm *WorldManager) SetEventBufferSize(size int) error {
+	if size <= 0 {
+		size = DefaultEventBufferSize
+	}
+
+	wm.mu.Lock()
+	defer wm.mu.Unlock()
+
+	if len(wm.bigChunks) > 0 {
+		return fmt.Errorf("буфер событий нельзя изменить после создания BigChunk (%d активно)", len(wm.bigChunks))
+	}
+	if len(wm.globalEvents) > 0 {
+		return fmt.Errorf("в буфере событий %d необработанных событий", len(wm.globalEvents))
+	}
+	wm.globalEvents = make(chan Event, size)
+	return nil
+}
+
+// SetCriticalEventTimeout задаёт время ожидания места в канале для критичных событий
+// (0 - значение по умолчанию, отрицательное - не ждать и отбрасывать как остальные)
+func (wm *WorldManager) SetCriticalEventTimeout(timeout time.Duration) {
+	if timeout == 0 {
+		timeout = DefaultCriticalEventTimeout
+	}
+	wm.criticalTimeout.Store(int64(max(timeout, 0)))
+}
+
+// DroppedEvents возвращает число событий, отброшенных этим WorldManager и его BigChunk
+func (wm *WorldManager) DroppedEvents() uint64 {
+	return wm.droppedEvents.Load()
+}
+
+// isCriticalEvent сообщает, нельзя ли молча отбрасывать событие:
+// потерянное изменение блока расходится с состоянием у клиентов и в журнале событий
+func isCriticalEvent(event Event) bool {
+	blockEvent, ok := event.(BlockEvent)
+	return ok && blockEvent.EventType == EventTypeBlockChange
+}
+
+// eventKind возвращает метку типа события для метрик
+func eventKind(event Event) string {
+	switch event.(type) {
+	case BlockEvent:
+		return "block"
+	case EntityEvent:
+		return "entity"
+	case TickEvent:
+		return "tick"
+	case SaveEvent, EntitySaveEvent:
+		return "save"
+	default:
+		return "other"
+	}
+}
+
+// deliverEvent отправляет событие в канал. Если канал переполнен, обычное событие
+// отбрасывается, а критичное ждёт место не дольше criticalTimeout.
+// Каждое отброшенное событие учитывается в метрике и счётчике DroppedEvents.
+func (wm *WorldManager) deliverEvent(ch chan<- Event, event Event, channel string) bool {
+	select {
+	case ch <- event:
+		return true
+	default:
+	}
+
+	if timeout := time.Duration(wm.criticalTimeout.Load()); timeout > 0 && isCriticalEvent(event) {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+
+		select {
+		case ch <- event:
+			delayedCriticalEvents.WithLabelValues(channel).Inc()
+			return true
+		case <-timer.C:
+		case <-wm.ctx.Done():
+		}
+	}
+
+	wm.droppedEvents.Add(1)
+	droppedEvents.WithLabelValues(channel, eventKind(event)).Inc()
+	wm.warnDroppedEvent(channel, event)
+	return false
+}
+
+// warnDroppedEvent сообщает об отброшенном событии не чаще раза в
+// dropWarnInterval: при переполнении события отбрасываются тысячами, и
+// построчный лог сам замедлил бы обработку. Сообщение содержит число
+// событий, отброшенных с предыдущего
+func (wm *WorldManager) warnDroppedEvent(channel string, event Event) {
+	wm.unwarnedDrops.Add(1)
+
+	now := time.Now().UnixNano()
+	last := wm.lastDropWarn.Load()
+	if now-last < int64(dropWarnInterval) || !wm.lastDropWarn.CompareAndSwap(last, now) {
+		return
+	}
+	log.Printf("⚠️ Канал событий %s переполнен, событие %T отброшено (всего отброшено с прошлого сообщения: %d)",
+		channel, event, wm.unwarnedDrops.Swap(0))
+}
+
+// sendToWorld отправляет событие из BigChunk в WorldManager с учётом политики переполнения
+func (bc *BigChunk) sendToWorld(event Event) bool {
+	if bc.world == nil {
+		select {
+		case bc.eventsOut <- event:
+			return true
+		default:
+			return false
+		}
+	}
+	return bc.world.deliverEvent(bc.eventsOut, event, eventChannelGlobal)
+}
+
+// sendToSelf ставит событие во входящую очередь BigChunk с учётом политики
+// переполнения. BigChunk в пуле воркеров ставится в очередь на обработку
+func (bc *BigChunk) sendToSelf(event Event) bool {
+	var delivered bool
+	if bc.world == nil {
+		select {
+		case bc.eventsIn <- event:
+			delivered = true
+		default:
+		}
+	} else {
+		delivered = bc.world.deliverEvent(bc.eventsIn, event, eventChannelBigChunk)
+	}
+	if delivered {
+		bc.wake()
+	}
+	return delivered
+}
@@ -0,0 +1,134 @@
+package world
+
+import (
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleEntityEvent_CountsDropsWhenGlobalQueueFull(t *testing.T) {
+	wm := NewWorldManager(1)
+	require.NoError(t, wm.SetEventBufferSize(10))
+
+	// Обработчик не запущен, поэтому всё сверх буфера отбрасывается
+	for i := 0; i < 50; i++ {
+		wm.HandleEntityEvent(EntityEvent{EventType: EventTypeEntityMove, EntityID: uint64(i)})
+	}
+
+	assert.Equal(t, uint64(40), wm.DroppedEvents())
+	assert.Len(t, wm.globalEvents, 10)
+}
+
+func TestSetEventBufferSize_RejectedAfterBigChunkCreated(t *testing.T) {
+	wm := NewWorldManager(1)
+	wm.GetBlock(vec.Vec2{X: 0, Y: 0})
+
+	assert.Error(t, wm.SetEventBufferSize(10))
+}
+
+func TestRouteEvents_FloodKeepsBlockChanges(t *testing.T) {
+	wm := NewWorldManager(1)
+	wm.SetCriticalEventTimeout(time.Second)
+
+	// BigChunk с маленькой очередью и медленным потребителем
+	bc := NewBigChunk(vec.Vec2{}, wm, wm.globalEvents)
+	bc.eventsIn = make(chan Event, 4)
+	wm.bigChunks[vec.Vec2{}] = bc
+
+	var (
+		mu       sync.Mutex
+		received []Event
+	)
+	stop := make(chan struct{})
+	consumerDone := make(chan struct{})
+	go func() {
+		defer close(consumerDone)
+		for {
+			select {
+			case <-stop:
+				return
+			case event := <-bc.eventsIn:
+				mu.Lock()
+				received = append(received, event)
+				mu.Unlock()
+				time.Sleep(time.Millisecond)
+			}
+		}
+	}()
+
+	const blockChanges = 100
+	for i := 0; i < blockChanges; i++ {
+		// Между изменениями блоков - поток некритичных событий сущностей
+		for j := 0; j < 10; j++ {
+			wm.routeEntityEvent(EntityEvent{EventType: EventTypeEntityMove, EntityID: uint64(j), Position: vec.Vec2{X: 1, Y: 1}})
+		}
+		wm.routeBlockEvent(BlockEvent{
+			EventType: EventTypeBlockChange,
+			Position:  vec.Vec2{X: i % 16, Y: i / 16},
+			Block:     NewBlock(1),
+		})
+	}
+
+	// Дожидаемся, пока потребитель вычитает очередь
+	require.Eventually(t, func() bool { return len(bc.eventsIn) == 0 }, 5*time.Second, time.Millisecond)
+	close(stop)
+	<-consumerDone
+
+	mu.Lock()
+	defer mu.Unlock()
+	blocks := 0
+	for _, event := range received {
+		if isCriticalEvent(event) {
+			blocks++
+		}
+	}
+	assert.Equal(t, blockChanges, blocks, "изменения блоков не должны теряться")
+	assert.Positive(t, wm.DroppedEvents(), "переполнение должно учитываться")
+	assert.Equal(t, uint64(blockChanges*10+blockChanges-len(received)), wm.DroppedEvents(),
+		"каждое недоставленное событие учтено как отброшенное")
+}
+
+// lockedBuffer - буфер лога, безопасный для записи из нескольких горутин
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf strings.Builder
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestDeliverEvent_DropWarningsRateLimited(t *testing.T) {
+	logs := &lockedBuffer{}
+	log.SetOutput(logs)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	wm := NewWorldManager(1)
+	require.NoError(t, wm.SetEventBufferSize(10))
+	for i := 0; i < 1000; i++ {
+		wm.HandleEntityEvent(EntityEvent{EventType: EventTypeEntityMove, EntityID: uint64(i)})
+	}
+	require.Equal(t, uint64(990), wm.DroppedEvents())
+	assert.Equal(t, 1, strings.Count(logs.String(), "переполнен"), "одно сообщение на интервал, а не на событие")
+
+	// По истечении интервала сообщение сводит все отброшенные за это время
+	wm.lastDropWarn.Store(time.Now().Add(-dropWarnInterval).UnixNano())
+	wm.HandleEntityEvent(EntityEvent{EventType: EventTypeEntityMove})
+	assert.Equal(t, 2, strings.Count(logs.String(), "переполнен"))
+	assert.Contains(t, logs.String(), "с прошлого сообщения: 990)")
+}
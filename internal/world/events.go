@@ -1,6 +1,7 @@
 package world
 
 import (
+	"github.com/annel0/mmo-game/internal/storage_interface"
 	"github.com/annel0/mmo-game/internal/vec"
 )
 
@@ -80,8 +81,8 @@ func (e TickEvent) GetType() EventType {
 
 // EntitySaveEvent представляет событие сохранения сущностей
 type EntitySaveEvent struct {
-	BigChunkCoords vec.Vec2               // Координаты BigChunk
-	Entities       map[uint64]interface{} // Сущности для сохранения
+	BigChunkCoords vec.Vec2                           // Координаты BigChunk
+	Entities       []storage_interface.EntitySnapshot // Снимки сущностей для сохранения
 }
 
 // GetType возвращает тип события
@@ -28,35 +28,43 @@ const (
 	MountainStart   = 0.80 // Выше - горы с рудами
 )
 
-// WorldGenerator генерирует ландшафт мира
+// WorldGenerator генерирует ландшафт мира. Чанк определяется только сидом
+// и координатами: повторная генерация (после выгрузки, на другом узле)
+// даёт то же содержимое
 type WorldGenerator struct {
 	Seed          int64   // Сид для генерации шума
 	NoiseScale    float64 // Масштаб основного шума (высота)
 	BiomeScale    float64 // Масштаб шума биомов
 	ForestDensity float64 // Плотность лесов (от 0 до 1)
+
+	noise *util.PerlinNoise // Шум высот и биомов по Seed
 }
 
 // NewWorldGenerator создаёт новый генератор мира
 func NewWorldGenerator(seed int64) *WorldGenerator {
-	// Инициализируем генератор шума
-	util.InitPerlinNoise(seed)
-
 	return &WorldGenerator{
 		Seed:          seed,
 		NoiseScale:    0.05, // Настройка сглаженности ландшафта
 		BiomeScale:    0.02, // Настройка размера биомов
 		ForestDensity: 0.05, // 5% шанс появления деревьев на равнинах
+		noise:         util.NewPerlinNoise(seed),
 	}
 }
 
-// GenerateChunk генерирует чанк по его координатам
+// chunkGenSeed выводит сид случайных решений генерации (деревья, руда) из
+// сида мира и координат чанка. Формула входит в формат мира: её изменение
+// меняет несохранённый ландшафт существующих миров
+func chunkGenSeed(worldSeed int64, coords vec.Vec2) int64 {
+	return worldSeed + int64(coords.X*31) + int64(coords.Y*17)
+}
+
+// GenerateChunk генерирует чанк по его координатам. Вся случайность
+// выводится из Seed и coords, глобальное состояние не используется
 func (wg *WorldGenerator) GenerateChunk(coords vec.Vec2) *Chunk {
 	chunk := NewChunk(coords)
 
-	// Создаем локальный генератор случайных чисел для детерминированности
-	// Для каждого чанка создаем уникальный сид на основе глобального сида и координат
-	chunkSeed := wg.Seed + int64(coords.X*31) + int64(coords.Y*17)
-	rng := rand.New(rand.NewSource(chunkSeed))
+	// Локальный генератор случайных чисел с сидом чанка
+	rng := rand.New(rand.NewSource(chunkGenSeed(wg.Seed, coords)))
 
 	globalStartX := coords.X << 4 // chunkX * 16
 	globalStartY := coords.Y << 4 // chunkY * 16
@@ -71,14 +79,15 @@ func (wg *WorldGenerator) GenerateChunk(coords vec.Vec2) *Chunk {
 			noiseY := float64(globalY) * wg.NoiseScale
 
 			// Генерация высоты на основе шума Перлина
-			height := util.PerlinNoise2D(noiseX, noiseY, wg.Seed)
+			height := wg.noise.Noise2D(noiseX, noiseY)
 
 			// Координаты для шума биомов (другой масштаб)
 			biomeNoiseX := float64(globalX) * wg.BiomeScale
 			biomeNoiseY := float64(globalY) * wg.BiomeScale
 
-			// Генерация значения для определения биома
-			biomeValue := util.PerlinNoise2D(biomeNoiseX, biomeNoiseY, wg.Seed+42)
+			// Генерация значения для определения биома: тот же шум сида в
+			// другом масштабе, как генерировались существующие миры
+			biomeValue := wg.noise.Noise2D(biomeNoiseX, biomeNoiseY)
 
 			// Определяем биом на основе высоты и значения биома
 			biome := wg.getBiomeType(height, biomeValue)
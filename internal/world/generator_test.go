@@ -0,0 +1,55 @@
+package world
+
+import (
+	"testing"
+
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// assertSameGeneratedChunk сравнивает всё содержимое чанков, кроме версии
+func assertSameGeneratedChunk(t *testing.T, expected, actual *Chunk) {
+	t.Helper()
+	assert.Equal(t, expected.Coords, actual.Coords)
+	assert.Equal(t, expected.Blocks3D, actual.Blocks3D)
+	assert.Equal(t, expected.Metadata3D, actual.Metadata3D)
+	assert.Equal(t, expected.Tickable3D, actual.Tickable3D)
+	assert.Equal(t, expected.Hash(), actual.Hash())
+}
+
+func TestGenerateChunk_Deterministic(t *testing.T) {
+	coords := []vec.Vec2{{X: 0, Y: 0}, {X: 7, Y: -3}, {X: -40, Y: 25}}
+	for _, pos := range coords {
+		first := NewWorldGenerator(12345).GenerateChunk(pos)
+
+		// Генератор другого мира не влияет на этот (шум не глобальный)
+		NewWorldGenerator(999).GenerateChunk(pos)
+
+		second := NewWorldGenerator(12345).GenerateChunk(pos)
+		assertSameGeneratedChunk(t, first, second)
+	}
+
+	// Разные сиды дают разный ландшафт
+	assert.NotEqual(t, NewWorldGenerator(1).GenerateChunk(vec.Vec2{}).Hash(), NewWorldGenerator(2).GenerateChunk(vec.Vec2{}).Hash())
+}
+
+func TestGetChunk_RegenerationAfterEvictionReproducesChunk(t *testing.T) {
+	wm := NewWorldManager(4242)
+	coords := vec.Vec2{X: 3, Y: -5}
+	original := wm.GetChunk(coords)
+
+	// Другой мир в том же процессе между генерациями
+	NewWorldManager(1).GetChunk(coords)
+
+	// Выгружаем никогда не изменённый чанк и генерируем заново
+	bigChunk := wm.bigChunks[chunkBigChunkCoords(coords)]
+	require.NotNil(t, bigChunk)
+	bigChunk.mu.Lock()
+	delete(bigChunk.chunks, coords)
+	bigChunk.mu.Unlock()
+
+	regenerated := wm.GetChunk(coords)
+	require.NotSame(t, original, regenerated)
+	assertSameGeneratedChunk(t, original, regenerated)
+}
@@ -0,0 +1,99 @@
+package world
+
+import (
+	"encoding/json"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// DefaultMaxBlockMetadataBytes - предел суммарного размера метаданных одного
+	// блока (в JSON, как они уходят в сохранения, синхронизацию и клиентам)
+	DefaultMaxBlockMetadataBytes = 4096
+
+	// metadataWarnInterval - не чаще одного предупреждения о превышении за интервал
+	metadataWarnInterval = 10 * time.Second
+)
+
+var (
+	// maxBlockMetadataBytes - действующий предел (<= 0 - без ограничения)
+	maxBlockMetadataBytes atomic.Int64
+
+	// lastMetadataWarn - UnixNano последнего предупреждения о превышении
+	lastMetadataWarn atomic.Int64
+
+	oversizedBlockMetadata = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "world",
+		Name:      "block_metadata_rejected_total",
+		Help:      "Записи метаданных блоков, отклонённые из-за превышения предела размера.",
+	})
+)
+
+func init() {
+	maxBlockMetadataBytes.Store(DefaultMaxBlockMetadataBytes)
+	prometheus.MustRegister(oversizedBlockMetadata)
+}
+
+// SetMaxBlockMetadataBytes задаёт предел суммарного размера метаданных блока:
+// 0 - DefaultMaxBlockMetadataBytes, отрицательное значение - без ограничения.
+// Действует на все чанки процесса
+func SetMaxBlockMetadataBytes(limit int) {
+	if limit == 0 {
+		limit = DefaultMaxBlockMetadataBytes
+	}
+	maxBlockMetadataBytes.Store(int64(max(limit, 0)))
+}
+
+// MaxBlockMetadataBytes возвращает действующий предел размера метаданных блока (0 - без ограничения)
+func MaxBlockMetadataBytes() int {
+	return int(maxBlockMetadataBytes.Load())
+}
+
+// metadataSize возвращает размер метаданных в JSON
+func metadataSize(meta map[string]interface{}) int {
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return 0 // Несериализуемые значения не уходят ни в сохранения, ни клиентам
+	}
+	return len(data)
+}
+
+// metadataWriteFits сообщает, можно ли записать key = value в метаданные meta.
+// Запись, не увеличивающая размер, допускается всегда: так блок, уже
+// превысивший уменьшенный предел, может освобождать место
+func metadataWriteFits(meta map[string]interface{}, key string, value interface{}) (int, bool) {
+	limit := maxBlockMetadataBytes.Load()
+	if limit <= 0 {
+		return 0, true
+	}
+
+	prev, had := meta[key]
+	meta[key] = value
+	after := metadataSize(meta)
+	if had {
+		meta[key] = prev
+	} else {
+		delete(meta, key)
+	}
+
+	if int64(after) <= limit {
+		return after, true
+	}
+	return after, after <= metadataSize(meta)
+}
+
+// rejectOversizedMetadata учитывает отклонённую запись и изредка предупреждает в логе
+func rejectOversizedMetadata(coord BlockCoord, chunk *Chunk, key string, size int) {
+	oversizedBlockMetadata.Inc()
+
+	now := time.Now().UnixNano()
+	last := lastMetadataWarn.Load()
+	if now-last < int64(metadataWarnInterval) || !lastMetadataWarn.CompareAndSwap(last, now) {
+		return
+	}
+	log.Printf("⚠️ Метаданные блока %v (слой %d) в чанке %v превысили бы предел: %d > %d байт, запись %q отклонена",
+		coord.Pos, coord.Layer, chunk.Coords, size, MaxBlockMetadataBytes(), key)
+}
@@ -0,0 +1,111 @@
+package world
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/annel0/mmo-game/internal/world/block"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withMetadataBudget задаёт предел метаданных блока на время теста
+func withMetadataBudget(t *testing.T, limit int) {
+	t.Helper()
+	SetMaxBlockMetadataBytes(limit)
+	t.Cleanup(func() { SetMaxBlockMetadataBytes(0) })
+}
+
+func TestMetadataBudget_AccumulationHitsCap(t *testing.T) {
+	withMetadataBudget(t, 256)
+	chunk := NewChunk(vec.Vec2{})
+	local := vec.Vec2{X: 3, Y: 4}
+	rejected := testutil.ToFloat64(oversizedBlockMetadata)
+
+	// Каждое взаимодействие добавляет новый ключ, пока блок не упрётся в предел
+	written := 0
+	for i := 0; i < 50; i++ {
+		if !chunk.SetBlockMetadataLayer(LayerActive, local, fmt.Sprintf("note_%02d", i), strings.Repeat("x", 16)) {
+			break
+		}
+		written++
+	}
+
+	require.Greater(t, written, 0)
+	require.Less(t, written, 50, "запись сверх предела должна быть отклонена")
+	meta := chunk.GetBlockMetadataLayer(LayerActive, local)
+	assert.Len(t, meta, written)
+	assert.LessOrEqual(t, metadataSize(meta), 256)
+	assert.Equal(t, rejected+1, testutil.ToFloat64(oversizedBlockMetadata))
+
+	// Перезапись существующего ключа меньшим значением допускается
+	assert.True(t, chunk.SetBlockMetadataLayer(LayerActive, local, "note_00", "y"))
+
+	// Другие блоки и слои считаются отдельно
+	assert.True(t, chunk.SetBlockMetadataLayer(LayerFloor, local, "note_00", strings.Repeat("x", 16)))
+	assert.True(t, chunk.SetBlockMetadataLayer(LayerActive, vec.Vec2{X: 5, Y: 5}, "note_00", strings.Repeat("x", 16)))
+}
+
+func TestMetadataBudget_ShrinkingWritesAllowedOverLoweredCap(t *testing.T) {
+	withMetadataBudget(t, -1)
+	chunk := NewChunk(vec.Vec2{})
+	local := vec.Vec2{X: 1, Y: 1}
+	require.True(t, chunk.SetBlockMetadataLayer(LayerActive, local, "log", strings.Repeat("x", 500)))
+
+	SetMaxBlockMetadataBytes(100)
+	assert.False(t, chunk.SetBlockMetadataLayer(LayerActive, local, "extra", 1), "рост сверх предела отклоняется")
+	assert.True(t, chunk.SetBlockMetadataLayer(LayerActive, local, "log", "short"), "уменьшение разрешено")
+	assert.True(t, chunk.SetBlockMetadataLayer(LayerActive, local, "extra", 1))
+}
+
+func TestMetadataBudget_WorldManagerPaths(t *testing.T) {
+	withMetadataBudget(t, 128)
+	wm := NewWorldManager(1)
+	pos := vec.Vec2{X: 40, Y: -7}
+
+	for i := 0; i < 20; i++ {
+		wm.SetBlockMetadataValue(pos, fmt.Sprintf("k%02d", i), strings.Repeat("v", 10))
+	}
+	meta := wm.GetChunk(pos.ToChunkCoords()).GetBlockMetadataLayer(LayerActive, pos.LocalInChunk())
+	assert.LessOrEqual(t, metadataSize(meta), 128)
+	assert.Less(t, len(meta), 20)
+
+	// SetBlockLayer с раздутым payload применяет только помещающиеся ключи
+	big := Block{ID: block.StoneBlockID, Payload: map[string]interface{}{"blob": strings.Repeat("z", 200)}}
+	other := vec.Vec2{X: 41, Y: -7}
+	wm.SetBlockLayer(other, LayerActive, big)
+	assert.Equal(t, block.StoneBlockID, wm.GetBlockLayer(other, LayerActive).ID)
+	_, stored := wm.GetChunk(other.ToChunkCoords()).GetBlockMetadataLayer(LayerActive, other.LocalInChunk())["blob"]
+	assert.False(t, stored)
+}
+
+func TestMetadataBudget_PayloadKeysAppliedInOrder(t *testing.T) {
+	withMetadataBudget(t, 64)
+	payload := map[string]interface{}{}
+	for i := 0; i < 8; i++ {
+		payload[fmt.Sprintf("k%d", i)] = strings.Repeat("v", 10)
+	}
+
+	// Предел вмещает лишь часть ключей: на каждом узле и при каждом повторе
+	// сохраняются одни и те же - первые по порядку
+	var want map[string]interface{}
+	for run := 0; run < 20; run++ {
+		wm := NewWorldManager(1)
+		pos := vec.Vec2{X: 3, Y: 3}
+		wm.SetBlockLayer(pos, LayerActive, Block{ID: block.StoneBlockID, Payload: payload})
+		meta := wm.GetChunk(pos.ToChunkCoords()).GetBlockMetadataLayer(LayerActive, pos.LocalInChunk())
+		require.NotEmpty(t, meta)
+		require.Less(t, len(meta), len(payload))
+		if want == nil {
+			want = meta
+			for i := 0; i < len(meta); i++ {
+				assert.Contains(t, meta, fmt.Sprintf("k%d", i))
+			}
+			continue
+		}
+		assert.Equal(t, want, meta)
+	}
+}
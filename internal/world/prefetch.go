@@ -0,0 +1,111 @@
+package world
+
+import (
+	"context"
+	"sync"
+
+	"github.com/annel0/mmo-game/internal/vec"
+)
+
+// DefaultPrefetchParallelism ограничивает число чанков, которые PrefetchChunks
+// генерирует одновременно
+const DefaultPrefetchParallelism = 8
+
+// PrefetchProgress описывает ход предзагрузки области чанков
+type PrefetchProgress struct {
+	Total     int // Всего чанков в области
+	Done      int // Обработано чанков (уже загруженные + сгенерированные)
+	Generated int // Сгенерировано новых чанков
+}
+
+// PrefetchChunks загружает или генерирует все чанки в прямоугольнике
+// [topLeft, bottomRight] (в координатах чанков, включительно) и возвращается,
+// когда вся область загружена. Уже загруженные чанки не генерируются повторно
+func (wm *WorldManager) PrefetchChunks(topLeft, bottomRight vec.Vec2) PrefetchProgress {
+	progress, _ := wm.PrefetchChunksWithProgress(context.Background(), topLeft, bottomRight, nil)
+	return progress
+}
+
+// PrefetchChunksWithProgress работает как PrefetchChunks, но останавливается
+// при отмене ctx и сообщает о ходе работы через onProgress (если он задан).
+// onProgress вызывается последовательно после каждого обработанного чанка
+func (wm *WorldManager) PrefetchChunksWithProgress(ctx context.Context, topLeft, bottomRight vec.Vec2, onProgress func(PrefetchProgress)) (PrefetchProgress, error) {
+	minX, maxX := topLeft.X, bottomRight.X
+	if minX > maxX {
+		minX, maxX = maxX, minX
+	}
+	minY, maxY := topLeft.Y, bottomRight.Y
+	if minY > maxY {
+		minY, maxY = maxY, minY
+	}
+
+	progress := PrefetchProgress{Total: (maxX - minX + 1) * (maxY - minY + 1)}
+
+	// Уже загруженные чанки пропускаем сразу, остальные отдаём воркерам
+	missing := make([]vec.Vec2, 0, progress.Total)
+	for x := minX; x <= maxX; x++ {
+		for y := minY; y <= maxY; y++ {
+			coords := vec.Vec2{X: x, Y: y}
+			if wm.isChunkLoaded(coords) {
+				progress.Done++
+				continue
+			}
+			missing = append(missing, coords)
+		}
+	}
+
+	var progressMu sync.Mutex
+	report := func(generated bool) {
+		progressMu.Lock()
+		defer progressMu.Unlock()
+		progress.Done++
+		if generated {
+			progress.Generated++
+		}
+		if onProgress != nil {
+			onProgress(progress)
+		}
+	}
+
+	sem := make(chan struct{}, DefaultPrefetchParallelism)
+	var wg sync.WaitGroup
+
+	for _, coords := range missing {
+		select {
+		case <-ctx.Done():
+		case sem <- struct{}{}:
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(coords vec.Vec2) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			_, generated := wm.loadChunk(coords)
+			report(generated)
+		}(coords)
+	}
+	wg.Wait()
+
+	progressMu.Lock()
+	defer progressMu.Unlock()
+	return progress, ctx.Err()
+}
+
+// isChunkLoaded проверяет, загружен ли чанк, не создавая BigChunk
+func (wm *WorldManager) isChunkLoaded(coords vec.Vec2) bool {
+	wm.mu.RLock()
+	bigChunk, exists := wm.bigChunks[chunkBigChunkCoords(coords)]
+	wm.mu.RUnlock()
+	if !exists {
+		return false
+	}
+
+	bigChunk.mu.RLock()
+	_, exists = bigChunk.chunks[coords]
+	bigChunk.mu.RUnlock()
+	return exists
+}
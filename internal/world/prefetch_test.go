@@ -0,0 +1,58 @@
+package world
+
+import (
+	"context"
+	"testing"
+
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrefetchChunks_LoadsRegion(t *testing.T) {
+	wm := NewWorldManager(42)
+	defer wm.cancelFunc()
+
+	// Область 10x10 пересекает границу BigChunk (16 чанков)
+	topLeft := vec.Vec2{X: 10, Y: -2}
+	bottomRight := vec.Vec2{X: 19, Y: 7}
+
+	// Один чанк загружен заранее — повторно он не генерируется
+	preloaded := wm.GetChunk(vec.Vec2{X: 12, Y: 0})
+
+	progress := wm.PrefetchChunks(topLeft, bottomRight)
+	assert.Equal(t, PrefetchProgress{Total: 100, Done: 100, Generated: 99}, progress)
+
+	for x := topLeft.X; x <= bottomRight.X; x++ {
+		for y := topLeft.Y; y <= bottomRight.Y; y++ {
+			assert.True(t, wm.isChunkLoaded(vec.Vec2{X: x, Y: y}), "чанк (%d,%d) не загружен", x, y)
+		}
+	}
+	assert.Same(t, preloaded, wm.GetChunk(vec.Vec2{X: 12, Y: 0}))
+
+	// Повторная предзагрузка ничего не генерирует
+	again := wm.PrefetchChunks(bottomRight, topLeft)
+	assert.Equal(t, PrefetchProgress{Total: 100, Done: 100}, again)
+}
+
+func TestPrefetchChunks_ReportsProgressAndStopsOnCancel(t *testing.T) {
+	wm := NewWorldManager(7)
+	defer wm.cancelFunc()
+
+	var reports []PrefetchProgress
+	progress, err := wm.PrefetchChunksWithProgress(context.Background(), vec.Vec2{X: 0, Y: 0}, vec.Vec2{X: 2, Y: 2}, func(p PrefetchProgress) {
+		reports = append(reports, p)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 9, progress.Generated)
+	require.Len(t, reports, 9)
+	for i, report := range reports {
+		assert.Equal(t, i+1, report.Done)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	progress, err = wm.PrefetchChunksWithProgress(ctx, vec.Vec2{X: 100, Y: 100}, vec.Vec2{X: 109, Y: 109}, nil)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, progress.Done, progress.Total)
+}
@@ -0,0 +1,401 @@
+package world
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/annel0/mmo-game/internal/protocol"
+	"github.com/annel0/mmo-game/internal/storage_interface"
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/annel0/mmo-game/internal/world/block"
+	entitypkg "github.com/annel0/mmo-game/internal/world/entity"
+)
+
+// RegionSnapshotVersion - текущая версия формата снимка области мира
+const RegionSnapshotVersion = 1
+
+// MaxRegionSnapshotArea ограничивает площадь области (в блоках) одного снимка
+const MaxRegionSnapshotArea = 512 * 512
+
+// ErrRegionSnapshotVersion - снимок записан в неподдерживаемой версии формата
+var ErrRegionSnapshotVersion = errors.New("неподдерживаемая версия снимка области")
+
+// regionSnapshot - самодостаточный снимок прямоугольной области мира:
+// все слои блоков, их метаданные и сущности внутри области
+type regionSnapshot struct {
+	Version     int                                `json:"version"`
+	Seed        int64                              `json:"seed"`
+	TopLeft     vec.Vec2                           `json:"top_left"`
+	BottomRight vec.Vec2                           `json:"bottom_right"`
+	Layers      [][]block.BlockID                  `json:"layers"` // [слой][(y-minY)*ширина + (x-minX)]
+	Metadata    []regionBlockMetadata              `json:"metadata,omitempty"`
+	Entities    []storage_interface.EntitySnapshot `json:"entities"` // Сущности BigChunk
+
+	// Сущности менеджера сущностей (NPC, животные, выброшенные предметы).
+	// Игроки не входят в снимок: они принадлежат своим сессиям
+	ManagedEntities []storage_interface.EntitySnapshot `json:"managed_entities,omitempty"`
+}
+
+// regionBlockMetadata - метаданные одного блока снимка
+type regionBlockMetadata struct {
+	X     int             `json:"x"`
+	Y     int             `json:"y"`
+	Layer BlockLayer      `json:"layer"`
+	Data  json.RawMessage `json:"data"`
+}
+
+// width и height - размеры области снимка в блоках
+func (s *regionSnapshot) width() int  { return s.BottomRight.X - s.TopLeft.X + 1 }
+func (s *regionSnapshot) height() int { return s.BottomRight.Y - s.TopLeft.Y + 1 }
+
+// contains проверяет, лежит ли позиция внутри области снимка
+func (s *regionSnapshot) contains(pos vec.Vec2) bool {
+	return pos.X >= s.TopLeft.X && pos.X <= s.BottomRight.X &&
+		pos.Y >= s.TopLeft.Y && pos.Y <= s.BottomRight.Y
+}
+
+// index возвращает индекс блока в массиве слоя
+func (s *regionSnapshot) index(pos vec.Vec2) int {
+	return (pos.Y-s.TopLeft.Y)*s.width() + (pos.X - s.TopLeft.X)
+}
+
+// checkRegionBounds проверяет углы области и её площадь
+func checkRegionBounds(topLeft, bottomRight vec.Vec2) error {
+	if topLeft.X > bottomRight.X || topLeft.Y > bottomRight.Y {
+		return fmt.Errorf("пустая область (%d,%d)-(%d,%d)", topLeft.X, topLeft.Y, bottomRight.X, bottomRight.Y)
+	}
+	width := int64(bottomRight.X) - int64(topLeft.X) + 1
+	height := int64(bottomRight.Y) - int64(topLeft.Y) + 1
+	if width*height > MaxRegionSnapshotArea {
+		return fmt.Errorf("область %dx%d превышает предел %d блоков", width, height, MaxRegionSnapshotArea)
+	}
+	return nil
+}
+
+// ExportRegion сохраняет прямоугольник [topLeft, bottomRight] (включительно)
+// в версионированный снимок: все слои блоков, метаданные и сущности.
+// Ещё не сгенерированные чанки области генерируются
+func (wm *WorldManager) ExportRegion(topLeft, bottomRight vec.Vec2) ([]byte, error) {
+	if err := checkRegionBounds(topLeft, bottomRight); err != nil {
+		return nil, err
+	}
+
+	snapshot := &regionSnapshot{
+		Version:     RegionSnapshotVersion,
+		Seed:        wm.seed,
+		TopLeft:     topLeft,
+		BottomRight: bottomRight,
+		Layers:      make([][]block.BlockID, MaxLayers),
+		Entities:    []storage_interface.EntitySnapshot{},
+	}
+	for layer := range snapshot.Layers {
+		snapshot.Layers[layer] = make([]block.BlockID, snapshot.width()*snapshot.height())
+	}
+
+	for _, chunk := range wm.regionChunks(topLeft, bottomRight) {
+		chunk.Mu.RLock()
+		err := forEachRegionBlock(snapshot, chunk, func(pos, local vec.Vec2) error {
+			for layer := BlockLayer(0); layer < MaxLayers; layer++ {
+				snapshot.Layers[layer][snapshot.index(pos)] = chunk.Blocks3D[layer][local.X][local.Y]
+
+				metadata := chunk.Metadata3D[BlockCoord{Layer: layer, Pos: local}]
+				if len(metadata) == 0 {
+					continue
+				}
+				data, err := protocol.MarshalMetadata(metadata)
+				if err != nil {
+					return fmt.Errorf("метаданные блока (%d,%d): %w", pos.X, pos.Y, err)
+				}
+				snapshot.Metadata = append(snapshot.Metadata, regionBlockMetadata{X: pos.X, Y: pos.Y, Layer: layer, Data: data})
+			}
+			return nil
+		})
+		chunk.Mu.RUnlock()
+		if err != nil {
+			return nil, err
+		}
+	}
+	sort.Slice(snapshot.Metadata, func(i, j int) bool {
+		a, b := snapshot.Metadata[i], snapshot.Metadata[j]
+		if a.Y != b.Y {
+			return a.Y < b.Y
+		}
+		if a.X != b.X {
+			return a.X < b.X
+		}
+		return a.Layer < b.Layer
+	})
+
+	wm.mu.RLock()
+	for _, bc := range wm.bigChunks {
+		bc.mu.RLock()
+		for _, entity := range bc.entities {
+			if snapshot.contains(entity.Position) {
+				snapshot.Entities = append(snapshot.Entities, entity.Snapshot())
+			}
+		}
+		bc.mu.RUnlock()
+	}
+	wm.mu.RUnlock()
+	sort.Slice(snapshot.Entities, func(i, j int) bool { return snapshot.Entities[i].ID < snapshot.Entities[j].ID })
+
+	if em := wm.entities.Load(); em != nil {
+		for _, entity := range em.EntitiesInArea(snapshot.contains) {
+			snapshot.ManagedEntities = append(snapshot.ManagedEntities, managedEntitySnapshot(entity))
+		}
+	}
+
+	return json.Marshal(snapshot)
+}
+
+// ImportRegion заменяет область мира содержимым снимка ExportRegion. Снимок
+// полностью проверяется до изменений: при любой ошибке мир остаётся прежним.
+// Блоки и сущности области заменяются за один шаг - под блокировками
+// менеджера сущностей, всех BigChunk и затронутых чанков сразу (в этом
+// порядке, как и при обновлении сущностей), поэтому читатели не видят
+// область частично импортированной. Сущности получают свои исходные ID
+func (wm *WorldManager) ImportRegion(data []byte) error {
+	snapshot, metadata, err := decodeRegionSnapshot(data)
+	if err != nil {
+		return err
+	}
+
+	wm.saveMu.Lock()
+	defer wm.saveMu.Unlock()
+
+	// Чанки и BigChunk создаются заранее: под блокировками импорта их
+	// генерация захватывала бы те же блокировки
+	chunks := wm.regionChunks(snapshot.TopLeft, snapshot.BottomRight)
+	bigChunks := wm.regionBigChunks(snapshot)
+
+	apply := func() {
+		for _, bc := range bigChunks {
+			bc.mu.Lock()
+		}
+		for _, chunk := range chunks {
+			chunk.Mu.Lock()
+		}
+		for _, chunk := range chunks {
+			applyRegionBlocksLocked(snapshot, metadata, chunk)
+		}
+		replaceRegionEntitiesLocked(snapshot, bigChunks)
+		for _, chunk := range chunks {
+			chunk.Mu.Unlock()
+		}
+		for _, bc := range bigChunks {
+			bc.mu.Unlock()
+		}
+	}
+
+	em := wm.entities.Load()
+	if em == nil {
+		apply()
+		return nil
+	}
+	managed := make([]*entitypkg.Entity, 0, len(snapshot.ManagedEntities))
+	for _, e := range snapshot.ManagedEntities {
+		managed = append(managed, managedEntityFromSnapshot(e))
+	}
+	em.ReplaceEntitiesInArea(snapshot.contains, managed, apply)
+	return nil
+}
+
+// applyRegionBlocksLocked записывает блоки снимка в чанк. Вызывающий должен
+// держать chunk.Mu на запись
+func applyRegionBlocksLocked(snapshot *regionSnapshot, metadata map[regionBlockKey]map[string]interface{}, chunk *Chunk) {
+	_ = forEachRegionBlock(snapshot, chunk, func(pos, local vec.Vec2) error {
+		for layer := BlockLayer(0); layer < MaxLayers; layer++ {
+			coord := BlockCoord{Layer: layer, Pos: local}
+			blockID := snapshot.Layers[layer][snapshot.index(pos)]
+			chunk.Blocks3D[layer][local.X][local.Y] = blockID
+			chunk.markChangedLocked(coord)
+
+			if meta, ok := metadata[regionBlockKey{pos: pos, layer: layer}]; ok {
+				chunk.Metadata3D[coord] = meta
+			} else {
+				delete(chunk.Metadata3D, coord)
+			}
+
+			if layer == LayerActive {
+				if behavior, exists := block.Get(blockID); exists && behavior.NeedsTick() {
+					chunk.Tickable3D[coord] = struct{}{}
+				} else {
+					delete(chunk.Tickable3D, coord)
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// regionBlockKey - блок снимка на конкретном слое
+type regionBlockKey struct {
+	pos   vec.Vec2
+	layer BlockLayer
+}
+
+// decodeRegionSnapshot разбирает и проверяет снимок до применения
+func decodeRegionSnapshot(data []byte) (*regionSnapshot, map[regionBlockKey]map[string]interface{}, error) {
+	var header struct {
+		Version int `json:"version"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, nil, fmt.Errorf("некорректный снимок области: %w", err)
+	}
+	if header.Version != RegionSnapshotVersion {
+		return nil, nil, fmt.Errorf("%w: %d (поддерживается %d)", ErrRegionSnapshotVersion, header.Version, RegionSnapshotVersion)
+	}
+
+	snapshot := &regionSnapshot{}
+	if err := json.Unmarshal(data, snapshot); err != nil {
+		return nil, nil, fmt.Errorf("некорректный снимок области: %w", err)
+	}
+	if err := checkRegionBounds(snapshot.TopLeft, snapshot.BottomRight); err != nil {
+		return nil, nil, err
+	}
+	if len(snapshot.Layers) != int(MaxLayers) {
+		return nil, nil, fmt.Errorf("снимок содержит %d слоёв вместо %d", len(snapshot.Layers), MaxLayers)
+	}
+	area := snapshot.width() * snapshot.height()
+	for layer, blocks := range snapshot.Layers {
+		if len(blocks) != area {
+			return nil, nil, fmt.Errorf("слой %d: %d блоков вместо %d", layer, len(blocks), area)
+		}
+	}
+
+	metadata := make(map[regionBlockKey]map[string]interface{}, len(snapshot.Metadata))
+	for _, m := range snapshot.Metadata {
+		pos := vec.Vec2{X: m.X, Y: m.Y}
+		if !snapshot.contains(pos) || m.Layer >= MaxLayers {
+			return nil, nil, fmt.Errorf("метаданные блока (%d,%d) слоя %d вне области", m.X, m.Y, m.Layer)
+		}
+		values, err := protocol.UnmarshalMetadata(m.Data)
+		if err != nil {
+			return nil, nil, fmt.Errorf("метаданные блока (%d,%d): %w", m.X, m.Y, err)
+		}
+		if len(values) > 0 {
+			metadata[regionBlockKey{pos: pos, layer: m.Layer}] = values
+		}
+	}
+
+	for _, e := range snapshot.ManagedEntities {
+		if e.Type == uint16(entitypkg.EntityTypePlayer) {
+			return nil, nil, fmt.Errorf("сущность %d - игрок", e.ID)
+		}
+	}
+	seen := make(map[uint64]bool, len(snapshot.Entities)+len(snapshot.ManagedEntities))
+	for _, e := range append(slices.Clip(snapshot.Entities), snapshot.ManagedEntities...) {
+		if !snapshot.contains(e.BlockPosition()) {
+			return nil, nil, fmt.Errorf("сущность %d вне области", e.ID)
+		}
+		if seen[e.ID] {
+			return nil, nil, fmt.Errorf("сущность %d указана дважды", e.ID)
+		}
+		seen[e.ID] = true
+	}
+
+	return snapshot, metadata, nil
+}
+
+// regionChunks возвращает чанки, пересекающие область, упорядоченные по
+// координатам (единый порядок захвата блокировок)
+func (wm *WorldManager) regionChunks(topLeft, bottomRight vec.Vec2) []*Chunk {
+	minChunk, maxChunk := topLeft.ToChunkCoords(), bottomRight.ToChunkCoords()
+	chunks := make([]*Chunk, 0, (maxChunk.X-minChunk.X+1)*(maxChunk.Y-minChunk.Y+1))
+	for cy := minChunk.Y; cy <= maxChunk.Y; cy++ {
+		for cx := minChunk.X; cx <= maxChunk.X; cx++ {
+			chunks = append(chunks, wm.chunkForBlock(vec.Vec2{X: cx * 16, Y: cy * 16}))
+		}
+	}
+	return chunks
+}
+
+// forEachRegionBlock вызывает fn для каждого блока чанка, лежащего в области снимка
+func forEachRegionBlock(s *regionSnapshot, chunk *Chunk, fn func(pos, local vec.Vec2) error) error {
+	for lx := 0; lx < 16; lx++ {
+		for ly := 0; ly < 16; ly++ {
+			pos := vec.Vec2{X: chunk.Coords.X*16 + lx, Y: chunk.Coords.Y*16 + ly}
+			if !s.contains(pos) {
+				continue
+			}
+			if err := fn(pos, vec.Vec2{X: lx, Y: ly}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// regionBigChunks создаёт BigChunk для сущностей снимка и возвращает все
+// BigChunk мира, упорядоченные по координатам (единый порядок захвата блокировок)
+func (wm *WorldManager) regionBigChunks(snapshot *regionSnapshot) []*BigChunk {
+	wm.mu.Lock()
+	defer wm.mu.Unlock()
+
+	for _, e := range snapshot.Entities {
+		if coords := e.BlockPosition().ToBigChunkCoords(); wm.bigChunks[coords] == nil {
+			wm.createBigChunk(coords)
+		}
+	}
+	bigChunks := make([]*BigChunk, 0, len(wm.bigChunks))
+	for _, bc := range wm.bigChunks {
+		bigChunks = append(bigChunks, bc)
+	}
+	sort.Slice(bigChunks, func(i, j int) bool {
+		a, b := bigChunks[i].coords, bigChunks[j].coords
+		if a.Y != b.Y {
+			return a.Y < b.Y
+		}
+		return a.X < b.X
+	})
+	return bigChunks
+}
+
+// replaceRegionEntitiesLocked удаляет сущности BigChunk внутри области и
+// добавляет сущности снимка. Вызывающий должен держать bc.mu всех bigChunks
+func replaceRegionEntitiesLocked(snapshot *regionSnapshot, bigChunks []*BigChunk) {
+	byBigChunk := make(map[vec.Vec2][]storage_interface.EntitySnapshot)
+	for _, e := range snapshot.Entities {
+		coords := e.BlockPosition().ToBigChunkCoords()
+		byBigChunk[coords] = append(byBigChunk[coords], e)
+	}
+
+	for _, bc := range bigChunks {
+		for id, entity := range bc.entities {
+			if snapshot.contains(entity.Position) {
+				bc.removeEntityLocked(id)
+			}
+		}
+		bc.applyEntitySnapshotsLocked(byBigChunk[bc.coords])
+	}
+}
+
+// managedEntitySnapshot переводит сущность менеджера сущностей в запись снимка
+func managedEntitySnapshot(e entitypkg.Entity) storage_interface.EntitySnapshot {
+	health, _ := e.Payload["health"].(int)
+	return storage_interface.EntitySnapshot{
+		Version:   storage_interface.EntitySnapshotVersion,
+		ID:        e.ID,
+		Type:      uint16(e.Type),
+		Position:  e.PrecisePos,
+		Direction: e.Direction,
+		Velocity:  e.Velocity,
+		Health:    health,
+		Payload:   e.Payload,
+	}
+}
+
+// managedEntityFromSnapshot восстанавливает сущность менеджера сущностей из снимка
+func managedEntityFromSnapshot(s storage_interface.EntitySnapshot) *entitypkg.Entity {
+	e := entitypkg.NewEntity(s.ID, entitypkg.EntityType(s.Type), s.BlockPosition())
+	e.PrecisePos = s.Position
+	e.Direction = s.Direction
+	e.Velocity = s.Velocity
+	if s.Payload != nil {
+		e.Payload = s.Payload
+	}
+	return e
+}
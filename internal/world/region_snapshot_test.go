@@ -0,0 +1,189 @@
+package world
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/annel0/mmo-game/internal/world/block"
+	entitypkg "github.com/annel0/mmo-game/internal/world/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// addTestEntity помещает сущность в BigChunk её позиции
+func addTestEntity(wm *WorldManager, data EntityData) {
+	wm.chunkForBlock(data.Position)
+	wm.mu.RLock()
+	bc := wm.bigChunks[data.Position.ToBigChunkCoords()]
+	wm.mu.RUnlock()
+
+	bc.mu.Lock()
+	bc.entities[data.ID] = data
+	bc.mu.Unlock()
+}
+
+func TestRegionSnapshot_RoundTrip(t *testing.T) {
+	src := NewWorldManager(42)
+	defer src.cancelFunc()
+
+	// Область пересекает границу чанков и отрицательные координаты
+	topLeft, bottomRight := vec.Vec2{X: -3, Y: 10}, vec.Vec2{X: 20, Y: 18}
+	src.SetBlockLayer(vec.Vec2{X: -3, Y: 10}, LayerActive, NewBlock(block.StoneBlockID))
+	src.SetBlockLayer(vec.Vec2{X: 20, Y: 18}, LayerFloor, NewBlock(block.StoneBlockID))
+	src.SetBlockLayer(vec.Vec2{X: 5, Y: 12}, LayerCeiling, Block{
+		ID:      block.StoneBlockID,
+		Payload: map[string]interface{}{"owner": "alice", "level": 3},
+	})
+	addTestEntity(src, EntityData{
+		ID: 77, Type: 1,
+		Position:   vec.Vec2{X: 4, Y: 11},
+		PrecisePos: vec.Vec2Float{X: 4.5, Y: 11.25},
+		Health:     40,
+		Metadata:   map[string]interface{}{"name": "guard"},
+	})
+	addTestEntity(src, EntityData{ID: 78, Type: 1, Position: vec.Vec2{X: 100, Y: 100}})
+
+	data, err := src.ExportRegion(topLeft, bottomRight)
+	require.NoError(t, err)
+
+	dst := NewWorldManager(7)
+	defer dst.cancelFunc()
+	addTestEntity(dst, EntityData{ID: 5, Type: 1, Position: vec.Vec2{X: 0, Y: 15}})
+	require.NoError(t, dst.ImportRegion(data))
+
+	for x := topLeft.X; x <= bottomRight.X; x++ {
+		for y := topLeft.Y; y <= bottomRight.Y; y++ {
+			pos := vec.Vec2{X: x, Y: y}
+			for layer := BlockLayer(0); layer < MaxLayers; layer++ {
+				require.Equal(t, src.GetBlockLayer(pos, layer).ID, dst.GetBlockLayer(pos, layer).ID,
+					"блок (%d,%d) слоя %d", x, y, layer)
+			}
+		}
+	}
+	meta := dst.GetBlockLayer(vec.Vec2{X: 5, Y: 12}, LayerCeiling).Payload
+	assert.Equal(t, "alice", meta["owner"])
+	assert.Equal(t, 3, meta["level"], "типы чисел сохраняются")
+
+	// Сущности области заменены сущностями снимка, внешние не переносятся
+	again, err := dst.ExportRegion(topLeft, bottomRight)
+	require.NoError(t, err)
+	var snapshot regionSnapshot
+	require.NoError(t, json.Unmarshal(again, &snapshot))
+	require.Len(t, snapshot.Entities, 1)
+	assert.Equal(t, uint64(77), snapshot.Entities[0].ID)
+	assert.Equal(t, vec.Vec2Float{X: 4.5, Y: 11.25}, snapshot.Entities[0].Position)
+	assert.Equal(t, 40, snapshot.Entities[0].Health)
+	assert.Equal(t, "guard", snapshot.Entities[0].Payload["name"])
+
+	// Повторный экспорт совпадает с исходным (сид у миров разный)
+	assert.Equal(t, int64(7), snapshot.Seed)
+	snapshot.Seed = 42
+	normalized, err := json.Marshal(&snapshot)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(data), string(normalized))
+}
+
+func TestRegionSnapshot_RejectsInvalidWithoutChanges(t *testing.T) {
+	wm := NewWorldManager(1)
+	defer wm.cancelFunc()
+
+	_, err := wm.ExportRegion(vec.Vec2{X: 5, Y: 5}, vec.Vec2{X: 4, Y: 5})
+	assert.Error(t, err, "пустая область")
+	_, err = wm.ExportRegion(vec.Vec2{}, vec.Vec2{X: 1000, Y: 1000})
+	assert.Error(t, err, "слишком большая область")
+
+	pos := vec.Vec2{X: 1, Y: 1}
+	wm.SetBlockLayer(pos, LayerActive, NewBlock(block.StoneBlockID))
+	data, err := wm.ExportRegion(vec.Vec2{}, vec.Vec2{X: 3, Y: 3})
+	require.NoError(t, err)
+	// После экспорта блок меняется: отклонённый импорт не должен вернуть камень
+	wm.SetBlockLayer(pos, LayerActive, NewBlock(block.AirBlockID))
+
+	var raw map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &raw))
+
+	corrupt := func(mutate func(map[string]interface{})) []byte {
+		copied := make(map[string]interface{}, len(raw))
+		for k, v := range raw {
+			copied[k] = v
+		}
+		mutate(copied)
+		out, err := json.Marshal(copied)
+		require.NoError(t, err)
+		return out
+	}
+
+	err = wm.ImportRegion(corrupt(func(m map[string]interface{}) { m["version"] = 99 }))
+	assert.ErrorIs(t, err, ErrRegionSnapshotVersion)
+
+	// Слой неверной длины отклоняет весь снимок, даже если остальные слои корректны
+	layers := raw["layers"].([]interface{})
+	err = wm.ImportRegion(corrupt(func(m map[string]interface{}) {
+		m["layers"] = []interface{}{layers[0], []interface{}{0}, layers[2]}
+	}))
+	assert.Error(t, err)
+	err = wm.ImportRegion(corrupt(func(m map[string]interface{}) {
+		m["entities"] = []interface{}{map[string]interface{}{"version": 1, "id": 1, "position": map[string]int{"X": 50, "Y": 50}}}
+	}))
+	assert.Error(t, err, "сущность вне области")
+	assert.Error(t, wm.ImportRegion([]byte("not json")))
+
+	assert.Equal(t, block.AirBlockID, wm.GetBlockLayer(pos, LayerActive).ID, "мир не изменился")
+
+	require.NoError(t, wm.ImportRegion(data))
+	assert.Equal(t, block.StoneBlockID, wm.GetBlockLayer(pos, LayerActive).ID)
+}
+
+func TestRegionSnapshot_ManagedEntities(t *testing.T) {
+	topLeft, bottomRight := vec.Vec2{X: 0, Y: 0}, vec.Vec2{X: 20, Y: 20}
+
+	src := NewWorldManager(42)
+	defer src.cancelFunc()
+	srcEntities := entitypkg.NewEntityManager()
+	src.SetEntitySource(srcEntities)
+	t.Cleanup(func() { src.SetEntitySource(nil) })
+
+	cow := entitypkg.NewEntity(100, entitypkg.EntityTypeAnimal, vec.Vec2{X: 3, Y: 4})
+	cow.Payload["health"] = 35
+	cow.Payload["state"] = "idle"
+	srcEntities.AddEntity(cow)
+	srcEntities.AddEntity(entitypkg.NewEntity(101, entitypkg.EntityTypePlayer, vec.Vec2{X: 5, Y: 5}))
+	srcEntities.AddEntity(entitypkg.NewEntity(102, entitypkg.EntityTypeNPC, vec.Vec2{X: 50, Y: 50}))
+
+	data, err := src.ExportRegion(topLeft, bottomRight)
+	require.NoError(t, err)
+	var snapshot regionSnapshot
+	require.NoError(t, json.Unmarshal(data, &snapshot))
+	require.Len(t, snapshot.ManagedEntities, 1, "игроки и сущности вне области не входят в снимок")
+	assert.Equal(t, uint64(100), snapshot.ManagedEntities[0].ID)
+	assert.Equal(t, 35, snapshot.ManagedEntities[0].Health)
+
+	dst := NewWorldManager(7)
+	defer dst.cancelFunc()
+	dstEntities := entitypkg.NewEntityManager()
+	dst.SetEntitySource(dstEntities)
+	t.Cleanup(func() { dst.SetEntitySource(nil) })
+	dstEntities.AddEntity(entitypkg.NewEntity(7, entitypkg.EntityTypeItem, vec.Vec2{X: 10, Y: 10}))
+	dstEntities.AddEntity(entitypkg.NewEntity(8, entitypkg.EntityTypePlayer, vec.Vec2{X: 11, Y: 10}))
+	dstEntities.AddEntity(entitypkg.NewEntity(9, entitypkg.EntityTypeItem, vec.Vec2{X: 40, Y: 40}))
+
+	require.NoError(t, dst.ImportRegion(data))
+
+	// Мировые сущности области заменены, игроки и сущности снаружи остались
+	_, exists := dstEntities.GetEntity(7)
+	assert.False(t, exists)
+	_, exists = dstEntities.GetEntity(8)
+	assert.True(t, exists)
+	_, exists = dstEntities.GetEntity(9)
+	assert.True(t, exists)
+	imported, exists := dstEntities.GetEntity(100)
+	require.True(t, exists)
+	assert.Equal(t, entitypkg.EntityTypeAnimal, imported.Type)
+	assert.Equal(t, vec.Vec2{X: 3, Y: 4}, imported.Position)
+	assert.Equal(t, 35, imported.Payload["health"])
+	assert.Equal(t, "idle", imported.Payload["state"])
+	nearby := dstEntities.GetEntitiesInRange(vec.Vec2{X: 3, Y: 4}, 0.5)
+	require.Len(t, nearby, 1, "импортированная сущность видна в пространственном индексе")
+	assert.Equal(t, uint64(100), nearby[0].ID)
+}
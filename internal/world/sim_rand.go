@@ -0,0 +1,33 @@
+package world
+
+import (
+	"math/rand"
+
+	"github.com/annel0/mmo-game/internal/vec"
+	entitypkg "github.com/annel0/mmo-game/internal/world/entity"
+)
+
+// bigChunkSeed выводит сид симуляции BigChunk из сида мира и координат.
+// Одинаковые сид и координаты дают одинаковую последовательность случайных
+// решений на любом узле, поэтому регионы могут воспроизводить симуляцию друг друга
+func bigChunkSeed(worldSeed int64, coords vec.Vec2) int64 {
+	h := uint64(worldSeed)
+	h = splitmix64(h ^ uint64(int64(coords.X)))
+	h = splitmix64(h ^ uint64(int64(coords.Y)))
+	return int64(h)
+}
+
+// splitmix64 перемешивает биты значения (SplitMix64), чтобы соседние
+// координаты давали несвязанные сиды
+func splitmix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
+
+// newSimRand создаёт генератор для симуляции BigChunk. Источник защищён
+// мьютексом: блоки и сущности могут обращаться к нему из разных горутин
+func newSimRand(seed int64) *rand.Rand {
+	return rand.New(entitypkg.NewLockedSource(seed))
+}
@@ -0,0 +1,72 @@
+package world
+
+import (
+	"testing"
+
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// npcMove - перемещение NPC, отправленное BigChunk
+type npcMove struct {
+	ID  uint64
+	Pos vec.Vec2
+}
+
+// npcMoves прогоняет ticks обновлений NPC в BigChunk и возвращает их перемещения.
+// NPC стоят вплотную друг к другу: кто куда сдвинется, зависит и от того,
+// какой из них какое решение получил из общего генератора
+func npcMoves(t *testing.T, seed int64, coords vec.Vec2, ticks int) []npcMove {
+	t.Helper()
+
+	const npcs = 8
+	events := make(chan Event, ticks*npcs)
+	bc := NewBigChunk(coords, NewWorldManager(seed), events)
+	for i := range npcs {
+		id := uint64(7 + i)
+		bc.entities[id] = EntityData{ID: id, Type: 1, Position: vec.Vec2{X: 100 + i, Y: 100}}
+	}
+
+	var moves []npcMove
+	for i := 0; i < ticks; i++ {
+		bc.updateEntities()
+	drain:
+		for {
+			select {
+			case ev := <-events:
+				move := ev.(EntityEvent)
+				moves = append(moves, npcMove{ID: move.EntityID, Pos: move.Position})
+			default:
+				break drain
+			}
+		}
+	}
+	return moves
+}
+
+func TestBigChunkRand_SameSeedAndCoordsReproduceNPCMovement(t *testing.T) {
+	coords := vec.Vec2{X: 3, Y: -2}
+
+	first := npcMoves(t, 42, coords, 3000)
+	second := npcMoves(t, 42, coords, 3000)
+	require.NotEmpty(t, first, "NPC должен перемещаться")
+	assert.Equal(t, first, second, "одинаковые сид и координаты дают одинаковую симуляцию")
+
+	assert.NotEqual(t, first, npcMoves(t, 42, vec.Vec2{X: 4, Y: -2}, 3000), "соседний BigChunk использует другую последовательность")
+	assert.NotEqual(t, first, npcMoves(t, 43, coords, 3000), "другой сид мира даёт другую последовательность")
+}
+
+func TestBigChunkSeed_DistinctForNeighbours(t *testing.T) {
+	seeds := make(map[int64]vec.Vec2)
+	for x := -8; x <= 8; x++ {
+		for y := -8; y <= 8; y++ {
+			coords := vec.Vec2{X: x, Y: y}
+			seed := bigChunkSeed(1234, coords)
+			prev, dup := seeds[seed]
+			require.False(t, dup, "совпадение сидов %v и %v", prev, coords)
+			seeds[seed] = coords
+		}
+	}
+	assert.NotEqual(t, bigChunkSeed(1, vec.Vec2{X: 1, Y: 2}), bigChunkSeed(1, vec.Vec2{X: 2, Y: 1}))
+}
@@ -0,0 +1,127 @@
+package world
+
+import (
+	"log"
+	"math/rand"
+
+	"github.com/annel0/mmo-game/internal/storage_interface"
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/annel0/mmo-game/internal/world/block"
+)
+
+const (
+	// spawnOriginSpread - насколько далеко от (0, 0) сид может сдвинуть начало поиска спавна, блоков
+	spawnOriginSpread = 128
+
+	// spawnSearchRadius - предел поиска безопасной позиции вокруг начала, блоков
+	spawnSearchRadius = 256
+)
+
+// FindSpawn находит безопасную точку спавна, определяемую только сидом:
+// начало поиска сдвигается от (0, 0) случайно по сиду, затем позиции
+// перебираются квадратными кольцами в фиксированном порядке. Проверяется
+// сгенерированный ландшафт, изменения игроков не учитываются. Если безопасной
+// позиции в радиусе нет, возвращается начало поиска
+func (wg *WorldGenerator) FindSpawn() vec.Vec2 {
+	rng := rand.New(rand.NewSource(wg.Seed))
+	origin := vec.Vec2{
+		X: rng.Intn(2*spawnOriginSpread+1) - spawnOriginSpread,
+		Y: rng.Intn(2*spawnOriginSpread+1) - spawnOriginSpread,
+	}
+
+	chunks := make(map[vec.Vec2]*Chunk)
+	safe := func(pos vec.Vec2) bool {
+		coords := pos.ToChunkCoords()
+		chunk, ok := chunks[coords]
+		if !ok {
+			chunk = wg.GenerateChunk(coords)
+			chunks[coords] = chunk
+		}
+		return isSafeSpawn(chunk, pos.LocalInChunk())
+	}
+
+	if safe(origin) {
+		return origin
+	}
+	for r := 1; r <= spawnSearchRadius; r++ {
+		for d := -r; d <= r; d++ {
+			for _, pos := range [...]vec.Vec2{
+				{X: origin.X + d, Y: origin.Y - r},
+				{X: origin.X + d, Y: origin.Y + r},
+				{X: origin.X - r, Y: origin.Y + d},
+				{X: origin.X + r, Y: origin.Y + d},
+			} {
+				if safe(pos) {
+					return pos
+				}
+			}
+		}
+	}
+	return origin
+}
+
+// isSafeSpawn сообщает, что на позиции можно появиться: ACTIVE свободен,
+// а FLOOR - суша (не вода и не пропасть)
+func isSafeSpawn(chunk *Chunk, local vec.Vec2) bool {
+	if chunk.GetBlockLayer(LayerActive, local) != block.AirBlockID {
+		return false
+	}
+	switch chunk.GetBlockLayer(LayerFloor, local) {
+	case block.AirBlockID, block.WaterBlockID, block.DeepWaterBlockID:
+		return false
+	}
+	return true
+}
+
+// GetWorldSpawn возвращает точку спавна мира: заданную SetWorldSpawn,
+// сохранённую в метаданных или найденную FindSpawn по сиду при создании мира
+func (wm *WorldManager) GetWorldSpawn() vec.Vec2 {
+	return *wm.spawn.Load()
+}
+
+// SetWorldSpawn задаёт точку спавна мира и сохраняет её в метаданных мира,
+// если хранилище метаданных подключено. Новая точка действует сразу, даже
+// если сохранить её не удалось
+func (wm *WorldManager) SetWorldSpawn(pos vec.Vec2) error {
+	wm.spawnMu.Lock()
+	defer wm.spawnMu.Unlock()
+
+	wm.spawn.Store(&pos)
+	return wm.saveWorldMetaLocked()
+}
+
+// SetWorldMetaStore подключает хранилище метаданных мира. Сохранённые точка
+// спавна, приваты и граница мира восстанавливаются; для нового мира она выводится из сида и сразу
+// сохраняется, чтобы не зависеть от последующих изменений генератора
+func (wm *WorldManager) SetWorldMetaStore(store storage_interface.WorldMetaStore) error {
+	meta, found, err := store.LoadWorldMeta()
+	if err != nil {
+		return err
+	}
+	if found && meta.Seed != wm.seed {
+		log.Printf("⚠️ Сид мира %d отличается от сохранённого %d", wm.seed, meta.Seed)
+	}
+
+	wm.spawnMu.Lock()
+	defer wm.spawnMu.Unlock()
+
+	wm.metaStore = store
+	if found {
+		wm.claims = append([]storage_interface.Claim(nil), meta.Claims...)
+		wm.border.Store(meta.Border)
+	}
+	if found && meta.Spawn != nil {
+		spawn := *meta.Spawn
+		wm.spawn.Store(&spawn)
+		return nil
+	}
+	return wm.saveWorldMetaLocked()
+}
+
+// saveWorldMetaLocked сохраняет метаданные мира. Вызывается под spawnMu
+func (wm *WorldManager) saveWorldMetaLocked() error {
+	if wm.metaStore == nil {
+		return nil
+	}
+	return wm.metaStore.SaveWorldMeta(storage_interface.WorldMeta{Seed: wm.seed, Spawn: wm.spawn.Load(), Claims: wm.claims, Border: wm.border.Load()})
+}
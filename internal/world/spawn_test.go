@@ -0,0 +1,67 @@
+package world
+
+import (
+	"testing"
+
+	"github.com/annel0/mmo-game/internal/storage_interface"
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/annel0/mmo-game/internal/world/block"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryWorldMeta - хранилище метаданных мира в памяти
+type memoryWorldMeta struct {
+	meta  *storage_interface.WorldMeta
+	saves int
+}
+
+func (m *memoryWorldMeta) SaveWorldMeta(meta storage_interface.WorldMeta) error {
+	m.meta = &meta
+	m.saves++
+	return nil
+}
+
+func (m *memoryWorldMeta) LoadWorldMeta() (storage_interface.WorldMeta, bool, error) {
+	if m.meta == nil {
+		return storage_interface.WorldMeta{}, false, nil
+	}
+	return *m.meta, true, nil
+}
+
+func TestWorldSpawn_DerivedFromSeedIsStableAndSafe(t *testing.T) {
+	first := NewWorldManager(12345)
+	defer first.cancelFunc()
+	spawn := first.GetWorldSpawn()
+
+	second := NewWorldManager(12345)
+	defer second.cancelFunc()
+	assert.Equal(t, spawn, second.GetWorldSpawn(), "один сид - одна точка спавна")
+	assert.Equal(t, spawn, first.GetWorldSpawn())
+
+	assert.Equal(t, block.AirBlockID, first.GetBlockLayer(spawn, LayerActive).ID)
+	floor := first.GetBlockLayer(spawn, LayerFloor).ID
+	assert.NotContains(t, []block.BlockID{block.AirBlockID, block.WaterBlockID, block.DeepWaterBlockID}, floor)
+}
+
+func TestWorldSpawn_PersistedInWorldMeta(t *testing.T) {
+	store := &memoryWorldMeta{}
+
+	// Новый мир сразу сохраняет выведенную из сида точку
+	wm := NewWorldManager(7)
+	defer wm.cancelFunc()
+	require.NoError(t, wm.SetWorldMetaStore(store))
+	require.NotNil(t, store.meta)
+	assert.Equal(t, int64(7), store.meta.Seed)
+	assert.Equal(t, wm.GetWorldSpawn(), *store.meta.Spawn)
+
+	custom := vec.Vec2{X: -40, Y: 95}
+	require.NoError(t, wm.SetWorldSpawn(custom))
+
+	// После перезапуска используется заданная точка, а не выведенная из сида
+	restored := NewWorldManager(7)
+	defer restored.cancelFunc()
+	require.NoError(t, restored.SetWorldMetaStore(store))
+	assert.Equal(t, custom, restored.GetWorldSpawn())
+	assert.Equal(t, 2, store.saves, "загрузка сохранённой точки не перезаписывает метаданные")
+}
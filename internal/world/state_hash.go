@@ -0,0 +1,93 @@
+package world
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"sort"
+
+	"github.com/annel0/mmo-game/internal/vec"
+)
+
+// Seed возвращает сид генератора мира
+func (wm *WorldManager) Seed() int64 {
+	return wm.seed
+}
+
+// LoadedChunkHash возвращает хэш чанка (Chunk.Hash), если он загружен (без генерации нового)
+func (wm *WorldManager) LoadedChunkHash(coords vec.Vec2) (ChunkHash, bool) {
+	bigChunkCoords := vec.Vec2{X: coords.X * 16, Y: coords.Y * 16}.ToBigChunkCoords()
+
+	wm.mu.RLock()
+	bigChunk, exists := wm.bigChunks[bigChunkCoords]
+	wm.mu.RUnlock()
+	if !exists {
+		return ChunkHash{}, false
+	}
+
+	bigChunk.mu.RLock()
+	chunk, exists := bigChunk.chunks[coords]
+	bigChunk.mu.RUnlock()
+	if !exists {
+		return ChunkHash{}, false
+	}
+
+	return chunk.Hash(), true
+}
+
+// ChunkHashes возвращает хэши всех загруженных чанков
+func (wm *WorldManager) ChunkHashes() map[vec.Vec2]ChunkHash {
+	wm.mu.RLock()
+	bigChunks := make([]*BigChunk, 0, len(wm.bigChunks))
+	for _, bc := range wm.bigChunks {
+		bigChunks = append(bigChunks, bc)
+	}
+	wm.mu.RUnlock()
+
+	hashes := make(map[vec.Vec2]ChunkHash)
+	for _, bc := range bigChunks {
+		bc.mu.RLock()
+		chunks := make([]*Chunk, 0, len(bc.chunks))
+		for _, chunk := range bc.chunks {
+			chunks = append(chunks, chunk)
+		}
+		bc.mu.RUnlock()
+
+		for _, chunk := range chunks {
+			hashes[chunk.Coords] = chunk.Hash()
+		}
+	}
+	return hashes
+}
+
+// StateHash возвращает хэш всех загруженных чанков мира и их количество
+func (wm *WorldManager) StateHash() (string, int) {
+	hashes := wm.ChunkHashes()
+	return CombineChunkHashes(hashes), len(hashes)
+}
+
+// CombineChunkHashes объединяет хэши чанков в один хэш.
+// Чанки сортируются по координатам, поэтому результат не зависит от порядка обхода map.
+func CombineChunkHashes(hashes map[vec.Vec2]ChunkHash) string {
+	coords := make([]vec.Vec2, 0, len(hashes))
+	for c := range hashes {
+		coords = append(coords, c)
+	}
+	sort.Slice(coords, func(i, j int) bool {
+		if coords[i].X != coords[j].X {
+			return coords[i].X < coords[j].X
+		}
+		return coords[i].Y < coords[j].Y
+	})
+
+	h := sha256.New()
+	buf := make([]byte, 16)
+	for _, c := range coords {
+		binary.BigEndian.PutUint64(buf[:8], uint64(int64(c.X)))
+		binary.BigEndian.PutUint64(buf[8:], uint64(int64(c.Y)))
+		h.Write(buf)
+		sum := hashes[c]
+		h.Write(sum[:])
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
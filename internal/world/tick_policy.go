@@ -0,0 +1,154 @@
+package world
+
+import (
+	"math"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// DefaultIdleTickRate - частота тиков BigChunk без активности (тиков в секунду)
+	DefaultIdleTickRate = 1.0
+
+	// DefaultIdleTickDelay - сколько BigChunk должен простоять без активности,
+	// прежде чем перейти на пониженную частоту
+	DefaultIdleTickDelay = 5 * time.Second
+)
+
+// Режимы тиков BigChunk в метриках
+const (
+	tickModeActive = "active"
+	tickModeIdle   = "idle"
+)
+
+var (
+	// bigChunkTicks считает обработанные тики BigChunk по режимам; rate() даёт частоту тиков
+	bigChunkTicks = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "world",
+		Name:      "bigchunk_ticks_total",
+		Help:      "Тики, обработанные BigChunk, по режиму (active - полная частота, idle - пониженная).",
+	}, []string{"mode"})
+
+	// idleBigChunks показывает, сколько BigChunk тикает с пониженной частотой
+	idleBigChunks = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "world",
+		Name:      "bigchunks_idle",
+		Help:      "Число BigChunk без активности, тикающих с пониженной частотой.",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(bigChunkTicks, idleBigChunks)
+}
+
+// TickPolicy - правило адаптивной частоты тиков BigChunk. BigChunk без игроков
+// и без ожидающих разовых обновлений блоков через IdleAfter переходит на
+// частоту IdleRate и возвращается к полной частоте с первым же признаком
+// активности. Пропущенное время не теряется: следующий тик получает dt,
+// накопленный с предыдущего обработанного тика.
+type TickPolicy struct {
+	IdleRate  float64       // Тиков в секунду в простое (<= 0 - адаптивность выключена)
+	IdleAfter time.Duration // Время без активности до перехода в простой
+}
+
+// DefaultTickPolicy возвращает политику по умолчанию: простой через 5 секунд, 1 TPS
+func DefaultTickPolicy() TickPolicy {
+	return TickPolicy{IdleRate: DefaultIdleTickRate, IdleAfter: DefaultIdleTickDelay}
+}
+
+// enabled сообщает, включена ли адаптивная частота
+func (p TickPolicy) enabled() bool {
+	return p.IdleRate > 0
+}
+
+// SetTickPolicy задаёт адаптивную частоту тиков BigChunk: idleRate - тиков в
+// секунду в простое (0 - по умолчанию, отрицательное - всегда полная частота),
+// idleAfter - время без активности до простоя (0 - по умолчанию)
+func (wm *WorldManager) SetTickPolicy(idleRate float64, idleAfter time.Duration) {
+	policy := DefaultTickPolicy()
+	if idleRate != 0 {
+		policy.IdleRate = max(idleRate, 0)
+	}
+	if idleAfter > 0 {
+		policy.IdleAfter = idleAfter
+	}
+
+	wm.mu.Lock()
+	wm.tickPolicy = policy
+	wm.mu.Unlock()
+}
+
+// TickPolicy возвращает текущую политику частоты тиков BigChunk
+func (wm *WorldManager) TickPolicy() TickPolicy {
+	wm.mu.RLock()
+	defer wm.mu.RUnlock()
+	return wm.tickPolicy
+}
+
+// hasActivityLocked сообщает, требует ли BigChunk полной частоты тиков: в нём
+// есть игрок или блоки ждут разового обновления. Вызывающий должен держать bc.mu
+func (bc *BigChunk) hasActivityLocked() bool {
+	if len(bc.onceQueue) > 0 {
+		return true
+	}
+	entities := bc.world.entities.Load()
+	return entities != nil && entities.HasPlayersInBigChunk(bc.coords)
+}
+
+// scheduleTick решает, обрабатывать ли очередной тик планировщика длительностью
+// dt секунд, и возвращает время, накопленное с предыдущего обработанного тика
+func (bc *BigChunk) scheduleTick(dt float64, policy TickPolicy) (float64, bool) {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+
+	bc.pendingDt += dt
+	if !policy.enabled() || bc.hasActivityLocked() {
+		bc.idleFor = 0
+		bc.setIdleLocked(false)
+	} else {
+		bc.idleFor += dt
+		// Допуски защищают от накопленной ошибки округления (60 * 1/60 < 1)
+		if bc.idleFor+1e-9 >= policy.IdleAfter.Seconds() {
+			bc.setIdleLocked(true)
+		}
+	}
+
+	if bc.idle && bc.pendingDt+1e-9 < 1/policy.IdleRate {
+		return 0, false
+	}
+
+	elapsed := bc.pendingDt
+	bc.pendingDt = 0
+	if bc.idle {
+		bigChunkTicks.WithLabelValues(tickModeIdle).Inc()
+	} else {
+		bigChunkTicks.WithLabelValues(tickModeActive).Inc()
+	}
+	return elapsed, true
+}
+
+// setIdleLocked переключает режим простоя. Вызывающий должен держать bc.mu
+func (bc *BigChunk) setIdleLocked(idle bool) {
+	if bc.idle == idle {
+		return
+	}
+	bc.idle = idle
+	if idle {
+		idleBigChunks.Inc()
+	} else {
+		idleBigChunks.Dec()
+	}
+}
+
+// Idle сообщает, тикает ли BigChunk с пониженной частотой
+func (bc *BigChunk) Idle() bool {
+	bc.mu.RLock()
+	defer bc.mu.RUnlock()
+	return bc.idle
+}
+
+// deltaTime возвращает dt последнего обработанного тика (секунды)
+func (bc *BigChunk) deltaTime() float64 {
+	return math.Float64frombits(bc.lastDt.Load())
+}
@@ -0,0 +1,102 @@
+package world
+
+import (
+	"testing"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/annel0/mmo-game/internal/world/block"
+	entitypkg "github.com/annel0/mmo-game/internal/world/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+// runScheduler прогоняет ticks тиков планировщика и возвращает число обработанных
+// и суммарный dt, полученный BigChunk
+func runScheduler(bc *BigChunk, policy TickPolicy, ticks int) (int, float64) {
+	processed, total := 0, 0.0
+	for range ticks {
+		if dt, ok := bc.scheduleTick(1.0/DefaultTickRate, policy); ok {
+			processed++
+			total += dt
+		}
+	}
+	return processed, total
+}
+
+func TestTickPolicy_EmptyBigChunkSlowsDown(t *testing.T) {
+	wm := NewWorldManager(1)
+	bc := NewBigChunk(vec.Vec2{}, wm, make(chan Event, 64))
+	policy := TickPolicy{IdleRate: 1, IdleAfter: time.Second}
+
+	// До IdleAfter BigChunk тикает с полной частотой, тик перехода в простой пропускается
+	processed, _ := runScheduler(bc, policy, DefaultTickRate-1)
+	assert.Equal(t, DefaultTickRate-1, processed)
+	assert.False(t, bc.Idle())
+	processed, _ = runScheduler(bc, policy, 1)
+	assert.Equal(t, 0, processed)
+	assert.True(t, bc.Idle())
+
+	// В простое - раз в секунду, но время симуляции не теряется
+	processed, total := runScheduler(bc, policy, 3*DefaultTickRate)
+	assert.Equal(t, 3, processed)
+	assert.InDelta(t, 3.0, total, 1e-6)
+}
+
+func TestTickPolicy_ActivityRestoresFullRate(t *testing.T) {
+	wm := NewWorldManager(1)
+	bc := NewBigChunk(vec.Vec2{}, wm, make(chan Event, 64))
+	policy := TickPolicy{IdleRate: 1, IdleAfter: time.Second}
+
+	runScheduler(bc, policy, 2*DefaultTickRate)
+	assert.True(t, bc.Idle())
+
+	// Разовое обновление блока возвращает полную частоту сразу
+	bc.AddOnceTickable(vec.Vec2{X: 1, Y: 1})
+	processed, _ := runScheduler(bc, policy, 1)
+	assert.Equal(t, 1, processed)
+	assert.False(t, bc.Idle())
+
+	// Как и игрок в BigChunk
+	bc.mu.Lock()
+	bc.onceQueue = nil
+	bc.mu.Unlock()
+	runScheduler(bc, policy, 2*DefaultTickRate)
+	assert.True(t, bc.Idle())
+
+	// Игрок в сущностях BigChunk не появляется: присутствие берётся из менеджера сущностей
+	players := entitypkg.NewEntityManager()
+	wm.SetEntitySource(players)
+	player := &entitypkg.Entity{ID: 1, Type: entitypkg.EntityTypePlayer, Position: vec.Vec2{X: 600, Y: 10}}
+	players.AddEntity(player)
+	runScheduler(bc, policy, 1)
+	assert.True(t, bc.Idle(), "игрок в соседнем BigChunk не будит этот")
+
+	players.MoveEntity(player.ID, vec.Vec2Float{X: 10, Y: 10})
+	processed, _ = runScheduler(bc, policy, DefaultTickRate)
+	assert.Equal(t, DefaultTickRate, processed)
+	assert.False(t, bc.Idle())
+
+	// Игрок ушёл - BigChunk снова засыпает
+	players.MoveEntity(player.ID, vec.Vec2Float{X: 600, Y: 10})
+	runScheduler(bc, policy, 2*DefaultTickRate)
+	assert.True(t, bc.Idle())
+}
+
+func TestTickPolicy_DisabledKeepsFullRate(t *testing.T) {
+	wm := NewWorldManager(1)
+	wm.SetTickPolicy(-1, 0)
+	bc := NewBigChunk(vec.Vec2{}, wm, make(chan Event, 64))
+
+	processed, _ := runScheduler(bc, wm.TickPolicy(), 10*DefaultTickRate)
+	assert.Equal(t, 10*DefaultTickRate, processed)
+	assert.False(t, bc.Idle())
+}
+
+func TestTickChance_ScalesWithDeltaTime(t *testing.T) {
+	assert.Equal(t, 0.1, block.TickChance(0.1, block.NominalTickDelta))
+	assert.Equal(t, 0.1, block.TickChance(0.1, 0))
+
+	// За секунду простоя шанс равен шансу хотя бы одного срабатывания за 60 тиков
+	assert.InDelta(t, 0.99820, block.TickChance(0.1, 1), 1e-5)
+	assert.Greater(t, block.TickChance(0.01, 1), 0.01)
+}
@@ -0,0 +1,150 @@
+package world
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// DefaultTickRate - частота симуляции по умолчанию (тиков в секунду)
+const DefaultTickRate = 60
+
+var (
+	tickDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: "world",
+		Name:      "tick_duration_seconds",
+		Help:      "Длительность одного тика планировщика (все фазы).",
+		Buckets:   []float64{0.001, 0.002, 0.004, 0.008, 0.016, 0.032, 0.064, 0.128},
+	})
+	tickOverruns = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "world",
+		Name:      "tick_overruns_total",
+		Help:      "Тики, не уложившиеся в свой интервал.",
+	})
+	currentTick = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "world",
+		Name:      "tick_current",
+		Help:      "Номер последнего выполненного тика.",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(tickDuration, tickOverruns, currentTick)
+}
+
+// TickFunc - фаза тика. tick - номер тика планировщика, dt - время,
+// прошедшее с предыдущего вызова этой фазы (в секундах, фиксированное).
+type TickFunc func(tick uint64, dt float64)
+
+// tickPhase - зарегистрированная фаза тика
+type tickPhase struct {
+	name  string
+	every uint64
+	fn    TickFunc
+}
+
+// TickScheduler - единые часы симуляции. Каждый тик вызывает фазы
+// (мир, сеть и т.д.) строго в порядке регистрации и с фиксированным dt,
+// поэтому одинаковая последовательность входных данных даёт одинаковое
+// состояние, а номера тиков в метриках и журнале событий согласованы.
+type TickScheduler struct {
+	rate     int
+	interval time.Duration
+
+	mu     sync.Mutex
+	phases []tickPhase
+	tick   uint64
+
+	lastTickAt atomic.Int64 // UnixNano завершения последнего тика (читается без mu)
+}
+
+// NewTickScheduler создаёт планировщик с указанной частотой (тиков в секунду)
+func NewTickScheduler(rate int) *TickScheduler {
+	if rate <= 0 {
+		rate = DefaultTickRate
+	}
+	return &TickScheduler{
+		rate:     rate,
+		interval: time.Second / time.Duration(rate),
+	}
+}
+
+// Register добавляет фазу, выполняемую раз в every тиков (every <= 1 - каждый тик).
+// Фазы выполняются в порядке регистрации.
+func (s *TickScheduler) Register(name string, every int, fn TickFunc) {
+	if every < 1 {
+		every = 1
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.phases = append(s.phases, tickPhase{name: name, every: uint64(every), fn: fn})
+}
+
+// Rate возвращает частоту планировщика (тиков в секунду)
+func (s *TickScheduler) Rate() int {
+	return s.rate
+}
+
+// CurrentTick возвращает номер последнего выполненного тика
+func (s *TickScheduler) CurrentTick() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tick
+}
+
+// Step синхронно выполняет один тик и возвращает его номер.
+// Используется циклом Run, а также тестами и воспроизведением.
+func (s *TickScheduler) Step() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tick++
+	baseDt := 1.0 / float64(s.rate)
+	for _, phase := range s.phases {
+		if s.tick%phase.every == 0 {
+			phase.fn(s.tick, baseDt*float64(phase.every))
+		}
+	}
+
+	currentTick.Set(float64(s.tick))
+	s.lastTickAt.Store(time.Now().UnixNano())
+	return s.tick
+}
+
+// LastTickAt возвращает время завершения последнего тика (нулевое, если тиков
+// ещё не было). Не блокируется зависшим тиком, поэтому подходит для проверки живости
+func (s *TickScheduler) LastTickAt() time.Time {
+	nanos := s.lastTickAt.Load()
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos)
+}
+
+// Run выполняет тики с частотой планировщика до отмены контекста.
+// Если тик не укладывается в интервал, пропущенные тики не догоняются:
+// время симуляции замедляется, но dt остаётся фиксированным.
+func (s *TickScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			start := time.Now()
+			s.Step()
+
+			elapsed := time.Since(start)
+			tickDuration.Observe(elapsed.Seconds())
+			if elapsed > s.interval {
+				tickOverruns.Inc()
+			}
+		}
+	}
+}
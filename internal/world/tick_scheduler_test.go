@@ -0,0 +1,118 @@
+package world
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/annel0/mmo-game/internal/vec"
+	"github.com/annel0/mmo-game/internal/world/block"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	spreadingBlockID block.BlockID = 9101
+	// wallBlockID - незарегистрированный блок без поведения, преграда для spreadingBlock
+	wallBlockID block.BlockID = 9102
+)
+
+// spreadingBlock - тикаемый блок, каждый тик занимающий пустую клетку справа (в пределах чанка)
+type spreadingBlock struct{}
+
+func (spreadingBlock) ID() block.BlockID { return spreadingBlockID }
+func (spreadingBlock) Name() string      { return "spreading" }
+func (spreadingBlock) NeedsTick() bool   { return true }
+func (spreadingBlock) TickUpdate(api block.BlockAPI, pos vec.Vec2) {
+	next := vec.Vec2{X: pos.X + 1, Y: pos.Y}
+	if next.ToChunkCoords() == pos.ToChunkCoords() && api.GetBlockID(next) == 0 {
+		api.SetBlock(next, spreadingBlockID)
+	}
+}
+func (spreadingBlock) OnPlace(api block.BlockAPI, pos vec.Vec2) {}
+func (spreadingBlock) OnBreak(api block.BlockAPI, pos vec.Vec2) {}
+func (spreadingBlock) CreateMetadata() block.Metadata           { return nil }
+func (spreadingBlock) HandleInteraction(action string, currentPayload, actionPayload map[string]interface{}) (block.BlockID, map[string]interface{}, block.InteractionResult) {
+	return spreadingBlockID, currentPayload, block.InteractionResult{}
+}
+
+func TestTickScheduler_PhaseOrderAndDt(t *testing.T) {
+	scheduler := NewTickScheduler(60)
+
+	var calls []string
+	var networkDt []float64
+	scheduler.Register("world", 1, func(tick uint64, dt float64) {
+		assert.InDelta(t, 1.0/60, dt, 1e-9)
+		calls = append(calls, "world")
+	})
+	scheduler.Register("network", 3, func(tick uint64, dt float64) {
+		assert.Zero(t, tick%3, "Фаза с every=3 вызывается на каждом третьем тике")
+		networkDt = append(networkDt, dt)
+		calls = append(calls, "network")
+	})
+
+	for i := 0; i < 6; i++ {
+		scheduler.Step()
+	}
+
+	assert.Equal(t, uint64(6), scheduler.CurrentTick())
+	assert.Equal(t, []string{"world", "world", "world", "network", "world", "world", "world", "network"}, calls)
+	require.Len(t, networkDt, 2)
+	assert.InDelta(t, 3.0/60, networkDt[0], 1e-9, "dt фазы соответствует её периоду")
+}
+
+// runScripted прогоняет мир по фиксированному сценарию и возвращает хэш состояния после каждого тика
+func runScripted(t *testing.T) ([]string, uint64) {
+	t.Helper()
+
+	wm := NewWorldManager(4242)
+	defer wm.Stop()
+
+	scheduler := NewTickScheduler(DefaultTickRate)
+	scheduler.Register("world", 1, wm.Step)
+
+	// Входные данные привязаны к номерам тиков, а не ко времени
+	inputs := map[uint64]map[vec.Vec2]Block{
+		1:  {{X: 0, Y: 0}: {ID: spreadingBlockID}, {X: 40, Y: 3}: {ID: spreadingBlockID}},
+		5:  {{X: 3, Y: 0}: {ID: wallBlockID}, {X: 70, Y: 9}: {ID: spreadingBlockID}},
+		12: {{X: 0, Y: 7}: {ID: spreadingBlockID}, {X: 44, Y: 3}: {ID: 0}},
+	}
+
+	var hashes []string
+	for i := 0; i < 30; i++ {
+		if updates, ok := inputs[scheduler.CurrentTick()+1]; ok {
+			require.NoError(t, wm.BatchUpdate(updates))
+		}
+		scheduler.Step()
+
+		hash, _ := wm.StateHash()
+		hashes = append(hashes, hash)
+	}
+	return hashes, wm.CurrentTick()
+}
+
+func TestTickScheduler_DeterministicReplay(t *testing.T) {
+	block.Register(spreadingBlockID, spreadingBlock{})
+
+	first, firstTick := runScripted(t)
+	second, secondTick := runScripted(t)
+
+	assert.Equal(t, uint64(30), firstTick)
+	assert.Equal(t, firstTick, secondTick)
+	assert.Equal(t, first, second, "Одинаковые входные данные должны давать одинаковое состояние на каждом тике")
+	assert.NotEqual(t, first[0], first[len(first)-1], "Сценарий должен изменять мир")
+}
+
+func TestRunStepped_OnlyStepReadsGlobalEvents(t *testing.T) {
+	wm := NewWorldManager(1)
+	wm.RunStepped(context.Background())
+	defer wm.Stop()
+
+	// Без фазы планировщика события ждут в канале: свободного читателя нет
+	wm.HandleEntityEvent(EntityEvent{EventType: EventTypeEntityMove, EntityID: 1})
+	time.Sleep(50 * time.Millisecond)
+	assert.Len(t, wm.globalEvents, 1)
+
+	wm.Step(1, 1.0/DefaultTickRate)
+	assert.Empty(t, wm.globalEvents, "событие маршрутизируется в тике, который его забрал")
+}
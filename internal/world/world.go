@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"log"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/annel0/mmo-game/internal/eventbus"
@@ -26,23 +27,41 @@ type NetworkManager interface {
 
 // WorldManager управляет миром игры и координирует все процессы
 type WorldManager struct {
-	bigChunks         map[vec.Vec2]*BigChunk                       // Активные BigChunk'и
-	globalEvents      chan Event                                   // Глобальные события
-	seed              int64                                        // Глобальный сид для генерации
-	generator         *WorldGenerator                              // Генератор мира
-	currentTick       uint64                                       // Текущий глобальный тик
-	lastSaveTime      time.Time                                    // Время последнего сохранения
-	saveMu            sync.Mutex                                   // Мьютекс для операций сохранения
-	mu                sync.RWMutex                                 // Мьютекс для общего доступа
-	dataPath          string                                       // Путь к директории данных
-	nextEntityID      uint64                                       // Счетчик для генерации уникальных ID сущностей
-	entityIDMu        sync.Mutex                                   // Мьютекс для генерации ID
-	ctx               context.Context                              // Контекст для управления жизненным циклом
-	cancelFunc        context.CancelFunc                           // Функция отмены контекста
-	saveEntitiesFunc  func(vec.Vec2, map[uint64]interface{}) error // Функция для сохранения сущностей
-	loadEntitiesFunc  func(vec.Vec2) (interface{}, error)          // Функция для загрузки сущностей
-	applyEntitiesFunc func(map[uint64]interface{}, interface{})    // Функция для применения загруженных сущностей
-	networkManager    NetworkManager                               // Менеджер сети
+	bigChunks        map[vec.Vec2]*BigChunk                                     // Активные BigChunk'и
+	globalEvents     chan Event                                                 // Глобальные события
+	seed             int64                                                      // Глобальный сид для генерации
+	generator        *WorldGenerator                                            // Генератор мира
+	currentTick      uint64                                                     // Текущий глобальный тик
+	lastSaveTime     time.Time                                                  // Время последнего сохранения
+	saveMu           sync.Mutex                                                 // Мьютекс для операций сохранения
+	mu               sync.RWMutex                                               // Мьютекс для общего доступа
+	dataPath         string                                                     // Путь к директории данных
+	entityIDs        atomic.Pointer[entitypkg.EntityIDAllocator]                // Генератор уникальных ID сущностей
+	ctx              context.Context                                            // Контекст для управления жизненным циклом
+	cancelFunc       context.CancelFunc                                         // Функция отмены контекста
+	saveEntitiesFunc func(vec.Vec2, []storage_interface.EntitySnapshot) error   // Функция для сохранения сущностей
+	loadEntitiesFunc func(vec.Vec2) ([]storage_interface.EntitySnapshot, error) // Функция для загрузки сущностей
+	networkManager   NetworkManager                                             // Менеджер сети
+	criticalTimeout  atomic.Int64                                               // Ожидание места в канале для критичных событий (нс)
+	droppedEvents    atomic.Uint64                                              // Отброшено событий из-за переполнения каналов
+	unwarnedDrops    atomic.Uint64                                              // Отброшено с последнего сообщения в лог
+	lastDropWarn     atomic.Int64                                               // UnixNano последнего сообщения об отброшенных событиях
+	worldID          string                                                     // ID мира в событиях EventBus ("" - не указывается)
+	unpublished      atomic.Bool                                                // События мира не публикуются в EventBus
+	entityCount      atomic.Int64                                               // Сущностей во всех BigChunk
+	chunkEntityCap   atomic.Int64                                               // Предел сущностей в одном BigChunk (0 - без ограничения)
+	worldEntityCap   atomic.Int64                                               // Предел сущностей в мире (0 - без ограничения)
+	tickPolicy       TickPolicy                                                 // Адаптивная частота тиков BigChunk
+	entities         atomic.Pointer[entitypkg.EntityManager]                    // Менеджер сущностей игроков, NPC и предметов (см. SetEntitySource)
+	bigChunkPool     *bigChunkPool                                              // Пул воркеров BigChunk (nil - горутина на каждый BigChunk)
+
+	// Метаданные мира: точка спавна (см. spawn.go), приваты (см. claims.go)
+	// и граница мира (см. border.go)
+	spawn     atomic.Pointer[vec.Vec2]                      // Определяется при создании мира; читается без spawnMu
+	claims    []storage_interface.Claim                     // Приваты по возрастанию ID
+	border    atomic.Pointer[storage_interface.WorldBorder] // nil - мир бесконечен; читается без spawnMu
+	metaStore storage_interface.WorldMetaStore              // Хранилище метаданных мира (nil - не сохраняются)
+	spawnMu   sync.Mutex
 }
 
 // NewWorldManager создаёт новый менеджер мира с указанным сидом
@@ -52,17 +71,28 @@ func NewWorldManager(seed int64) *WorldManager {
 	// Создаем генератор мира
 	generator := NewWorldGenerator(seed)
 
-	return &WorldManager{
+	wm := &WorldManager{
 		bigChunks:    make(map[vec.Vec2]*BigChunk),
-		globalEvents: make(chan Event, 5000),
+		globalEvents: make(chan Event, DefaultEventBufferSize),
 		seed:         seed,
 		generator:    generator,
 		currentTick:  0,
 		lastSaveTime: time.Now(),
-		nextEntityID: 1000, // Начинаем с 1000, чтобы избежать конфликтов с малыми ID
 		ctx:          ctx,
 		cancelFunc:   cancel,
+		tickPolicy:   DefaultTickPolicy(),
 	}
+	// Регион 0 по умолчанию; в многорегиональном режиме заменяется через SetEntityIDAllocator
+	wm.entityIDs.Store(entitypkg.NewEntityIDAllocator(0))
+	wm.criticalTimeout.Store(int64(DefaultCriticalEventTimeout))
+	wm.SetEntityCaps(DefaultMaxEntitiesPerBigChunk, DefaultMaxWorldEntities)
+
+	// Точка спавна ищется сразу, а не при первом входе игрока: поиск
+	// генерирует чанки и не должен задерживать авторизацию
+	spawn := generator.FindSpawn()
+	wm.spawn.Store(&spawn)
+
+	return wm
 }
 
 // InitStorage инициализирует хранилище данных мира
@@ -71,8 +101,25 @@ func (wm *WorldManager) InitStorage(dataPath string) error {
 	return nil
 }
 
-// Run запускает обработку событий в WorldManager
+// Run запускает обработку событий в WorldManager: глобальные события
+// маршрутизируются по мере поступления. Мир, которым управляет TickScheduler,
+// запускается через RunStepped
 func (wm *WorldManager) Run(parentCtx context.Context) {
+	wm.start(parentCtx)
+
+	// Запускаем обработку глобальных событий
+	go wm.processGlobalEvents()
+}
+
+// RunStepped запускает WorldManager, которым управляет TickScheduler:
+// глобальные события читает только Step, поэтому тик, в который попадает
+// событие, не зависит от гонки двух читателей канала
+func (wm *WorldManager) RunStepped(parentCtx context.Context) {
+	wm.start(parentCtx)
+}
+
+// start готовит контекст мира и запускает автосохранение
+func (wm *WorldManager) start(parentCtx context.Context) {
 	// Если parentCtx != nil, создаем новый контекст отменяемый от него
 	if parentCtx != nil {
 		childCtx, cancel := context.WithCancel(parentCtx)
@@ -80,9 +127,6 @@ func (wm *WorldManager) Run(parentCtx context.Context) {
 		wm.cancelFunc = cancel
 	}
 
-	// Запускаем обработку глобальных событий
-	go wm.processGlobalEvents()
-
 	// Запускаем автоматическое сохранение мира
 	go wm.autoSaveLoop()
 }
@@ -94,19 +138,23 @@ func (wm *WorldManager) processGlobalEvents() {
 		case <-wm.ctx.Done():
 			return
 		case event := <-wm.globalEvents:
-			// Обрабатываем событие в зависимости от типа
-			switch e := event.(type) {
-			case BlockEvent:
-				wm.routeBlockEvent(e)
-			case EntityEvent:
-				wm.routeEntityEvent(e)
-			default:
-				log.Printf("Неизвестный тип события: %T", event)
-			}
+			wm.dispatchGlobalEvent(event)
 		}
 	}
 }
 
+// dispatchGlobalEvent маршрутизирует глобальное событие в зависимости от типа
+func (wm *WorldManager) dispatchGlobalEvent(event Event) {
+	switch e := event.(type) {
+	case BlockEvent:
+		wm.routeBlockEvent(e)
+	case EntityEvent:
+		wm.routeEntityEvent(e)
+	default:
+		log.Printf("Неизвестный тип события: %T", event)
+	}
+}
+
 // autoSaveLoop запускает периодическое сохранение мира
 func (wm *WorldManager) autoSaveLoop() {
 	ticker := time.NewTicker(5 * time.Minute)
@@ -122,30 +170,61 @@ func (wm *WorldManager) autoSaveLoop() {
 	}
 }
 
-// processTick обрабатывает один глобальный тик
-func (wm *WorldManager) processTick() {
+// Step выполняет тик мира с номером планировщика: сначала маршрутизирует накопленные
+// глобальные события, затем параллельно тикает все BigChunk и ждёт их завершения.
+// Регистрируется как фаза TickScheduler, поэтому мир и сеть работают от одних часов.
+func (wm *WorldManager) Step(tick uint64, dt float64) {
 	wm.mu.Lock()
-	wm.currentTick++
-	tickID := wm.currentTick
+	wm.currentTick = tick
 	wm.mu.Unlock()
 
-	// Создаем событие тика
-	tickEvent := TickEvent{
-		TickID:    tickID,
-		DeltaTime: 1.0 / 60.0,
-	}
+	wm.drainGlobalEvents()
 
-	// Отправляем тик всем активным BigChunk'ам
 	wm.mu.RLock()
+	bigChunks := make([]*BigChunk, 0, len(wm.bigChunks))
 	for _, bc := range wm.bigChunks {
+		bigChunks = append(bigChunks, bc)
+	}
+	policy := wm.tickPolicy
+	wm.mu.RUnlock()
+
+	req := tickRequest{
+		tick:   TickEvent{TickID: tick, DeltaTime: dt},
+		policy: policy,
+		done:   &sync.WaitGroup{},
+	}
+	for _, bc := range bigChunks {
+		req.done.Add(1)
+		if bc.pool != nil {
+			bc.submitTick(req)
+			continue
+		}
+		select {
+		case bc.ticks <- req:
+		case <-wm.ctx.Done():
+			req.done.Done()
+		}
+	}
+	req.done.Wait()
+}
+
+// CurrentTick возвращает номер последнего тика мира
+func (wm *WorldManager) CurrentTick() uint64 {
+	wm.mu.RLock()
+	defer wm.mu.RUnlock()
+	return wm.currentTick
+}
+
+// drainGlobalEvents маршрутизирует события, накопившиеся в globalEvents
+func (wm *WorldManager) drainGlobalEvents() {
+	for {
 		select {
-		case bc.eventsIn <- tickEvent:
-			// Успешно отправлено
+		case event := <-wm.globalEvents:
+			wm.dispatchGlobalEvent(event)
 		default:
-			// Канал переполнен, пропускаем (можно добавить логирование)
+			return
 		}
 	}
-	wm.mu.RUnlock()
 }
 
 // handleEvent обрабатывает глобальное событие
@@ -182,26 +261,48 @@ func (wm *WorldManager) processSaveEvent(event Event) {
 
 // GenerateEntityID генерирует уникальный ID для сущности
 func (wm *WorldManager) GenerateEntityID() uint64 {
-	wm.entityIDMu.Lock()
-	defer wm.entityIDMu.Unlock()
+	return wm.EntityIDAllocator().Next()
+}
+
+// SetEntityIDAllocator задаёт генератор ID сущностей (с номером региона в старших битах)
+func (wm *WorldManager) SetEntityIDAllocator(allocator *entitypkg.EntityIDAllocator) {
+	wm.entityIDs.Store(allocator)
+}
+
+// SetEventPublishing включает или отключает публикацию событий мира в
+// EventBus (по умолчанию включена). Отключается у вспомогательных миров,
+// изменения которых не должны попадать в журнал событий
+func (wm *WorldManager) SetEventPublishing(enabled bool) {
+	wm.unpublished.Store(!enabled)
+}
+
+// SetWorldID задаёт ID мира, которым помечаются события в EventBus
+// (для подписок с фильтром WorldIDs). Вызывается до начала работы с миром
+func (wm *WorldManager) SetWorldID(worldID string) {
+	wm.worldID = worldID
+}
+
+// WorldID возвращает ID мира, которым помечаются события в EventBus
+func (wm *WorldManager) WorldID() string {
+	return wm.worldID
+}
 
-	wm.nextEntityID++
-	return wm.nextEntityID
+// EntityIDAllocator возвращает генератор ID сущностей мира
+func (wm *WorldManager) EntityIDAllocator() *entitypkg.EntityIDAllocator {
+	return wm.entityIDs.Load()
 }
 
 // SetStorageFunctions устанавливает функции для работы с хранилищем сущностей
 func (wm *WorldManager) SetStorageFunctions(
-	saveFunc func(vec.Vec2, map[uint64]interface{}) error,
-	loadFunc func(vec.Vec2) (interface{}, error),
-	applyFunc func(map[uint64]interface{}, interface{}),
+	saveFunc func(vec.Vec2, []storage_interface.EntitySnapshot) error,
+	loadFunc func(vec.Vec2) ([]storage_interface.EntitySnapshot, error),
 ) {
 	wm.saveEntitiesFunc = saveFunc
 	wm.loadEntitiesFunc = loadFunc
-	wm.applyEntitiesFunc = applyFunc
 }
 
 // SaveEntities сохраняет сущности из BigChunk
-func (wm *WorldManager) SaveEntities(bigChunkCoords vec.Vec2, entities map[uint64]interface{}) {
+func (wm *WorldManager) SaveEntities(bigChunkCoords vec.Vec2, entities []storage_interface.EntitySnapshot) {
 	if wm.saveEntitiesFunc != nil {
 		if err := wm.saveEntitiesFunc(bigChunkCoords, entities); err != nil {
 			log.Printf("Ошибка сохранения сущностей для BigChunk %v: %v", bigChunkCoords, err)
@@ -228,14 +329,8 @@ func (wm *WorldManager) routeBlockEvent(event BlockEvent) {
 		wm.mu.Unlock()
 	}
 
-	// Отправляем событие в BigChunk
-	select {
-	case targetChunk.eventsIn <- event:
-		// Успешно отправлено
-	default:
-		// Канал переполнен, логируем
-		log.Printf("Переполнен канал событий для BigChunk %v, событие блока отброшено", targetChunk.coords)
-	}
+	// Отправляем событие в BigChunk (изменения блоков ждут места, а не отбрасываются)
+	targetChunk.sendToSelf(event)
 
 	// Если это событие изменения блока, уведомляем NetworkManager
 	if event.EventType == EventTypeBlockChange && wm.networkManager != nil {
@@ -243,9 +338,16 @@ func (wm *WorldManager) routeBlockEvent(event BlockEvent) {
 		wm.networkManager.SendBlockUpdate(event.Position, event.Block)
 	}
 
-	// Публикуем в EventBus
+	wm.publishBlockEvent(event)
+}
+
+// publishBlockEvent публикует событие блока в EventBus
+func (wm *WorldManager) publishBlockEvent(event BlockEvent) {
+	if wm.unpublished.Load() {
+		return
+	}
 	if payload, err := json.Marshal(event); err == nil {
-		_ = eventbus.Publish(context.Background(), &eventbus.Envelope{
+		envelope := &eventbus.Envelope{
 			ID:        uuid.NewString(),
 			Timestamp: time.Now().UTC(),
 			Source:    "world_manager",
@@ -253,10 +355,23 @@ func (wm *WorldManager) routeBlockEvent(event BlockEvent) {
 			Version:   1,
 			Priority:  5,
 			Payload:   payload,
-		})
+		}
+		envelope.SetPosition(wm.worldID, event.Position.X, event.Position.Y)
+		_ = eventbus.Publish(context.Background(), envelope)
 	}
 }
 
+// publishBlockWrite публикует прямую запись блока на слой, чтобы журнал
+// событий видел все изменения мира, а не только прошедшие через BigChunk
+func (wm *WorldManager) publishBlockWrite(pos vec.Vec2, layer BlockLayer, b Block) {
+	wm.publishBlockEvent(BlockEvent{
+		EventType: EventTypeBlockChange,
+		Position:  pos,
+		Block:     b,
+		Data:      map[string]interface{}{"layer": layer},
+	})
+}
+
 // routeEntityEvent маршрутизирует событие сущности в соответствующий BigChunk
 func (wm *WorldManager) routeEntityEvent(event EntityEvent) {
 	// Аналогично routeBlockEvent
@@ -275,17 +390,14 @@ func (wm *WorldManager) routeEntityEvent(event EntityEvent) {
 		wm.mu.Unlock()
 	}
 
-	select {
-	case targetChunk.eventsIn <- event:
-		// Успешно отправлено
-	default:
-		// Канал переполнен, логируем
-		log.Printf("Переполнен канал событий для BigChunk %v, событие сущности отброшено", targetChunk.coords)
-	}
+	targetChunk.sendToSelf(event)
 
 	// Публикуем в EventBus
+	if wm.unpublished.Load() {
+		return
+	}
 	if payload, err := json.Marshal(event); err == nil {
-		_ = eventbus.Publish(context.Background(), &eventbus.Envelope{
+		envelope := &eventbus.Envelope{
 			ID:        uuid.NewString(),
 			Timestamp: time.Now().UTC(),
 			Source:    "world_manager",
@@ -293,7 +405,9 @@ func (wm *WorldManager) routeEntityEvent(event EntityEvent) {
 			Version:   1,
 			Priority:  5,
 			Payload:   payload,
-		})
+		}
+		envelope.SetPosition(wm.worldID, event.Position.X, event.Position.Y)
+		_ = eventbus.Publish(context.Background(), envelope)
 	}
 }
 
@@ -302,8 +416,12 @@ func (wm *WorldManager) createBigChunk(coords vec.Vec2) *BigChunk {
 	bigChunk := NewBigChunk(coords, wm, wm.globalEvents)
 	wm.bigChunks[coords] = bigChunk
 
-	// Запускаем BigChunk в отдельной горутине
-	go bigChunk.Run(wm.ctx)
+	// Запускаем BigChunk в пуле воркеров или в отдельной горутине
+	if wm.bigChunkPool != nil {
+		bigChunk.pool = wm.bigChunkPool
+	} else {
+		go bigChunk.Run(wm.ctx)
+	}
 
 	// Загружаем сущности из хранилища (если есть)
 	wm.loadEntities(bigChunk)
@@ -313,22 +431,20 @@ func (wm *WorldManager) createBigChunk(coords vec.Vec2) *BigChunk {
 
 // loadEntities загружает сохраненные сущности для BigChunk
 func (wm *WorldManager) loadEntities(bigChunk *BigChunk) {
-	if wm.loadEntitiesFunc == nil || wm.applyEntitiesFunc == nil {
+	if wm.loadEntitiesFunc == nil {
 		return // Функции не установлены
 	}
 
-	entitiesData, err := wm.loadEntitiesFunc(bigChunk.coords)
+	snapshots, err := wm.loadEntitiesFunc(bigChunk.coords)
 	if err != nil {
 		log.Printf("Ошибка загрузки сущностей для BigChunk %v: %v", bigChunk.coords, err)
 		return
 	}
 
-	if entitiesData != nil {
-		// Применяем данные к BigChunk
-		bigChunk.mu.Lock()
-		wm.applyEntitiesFunc(bigChunk.entities, entitiesData)
-		bigChunk.mu.Unlock()
-	}
+	// Применяем данные к BigChunk
+	bigChunk.mu.Lock()
+	bigChunk.applyEntitySnapshotsLocked(snapshots)
+	bigChunk.mu.Unlock()
 }
 
 // SaveWorld сохраняет все активные чанки и метаданные мира
@@ -350,17 +466,14 @@ func (wm *WorldManager) SaveWorld(force bool) {
 		saveEvent := SaveEvent{Forced: force}
 		select {
 		case bigChunk.eventsIn <- saveEvent:
-			// Успешно отправлено
+			bigChunk.wake()
 		default:
 			log.Printf("Переполнен канал событий для BigChunk %v, событие сохранения отброшено", bigChunk.coords)
 		}
 
 		// Сохраняем сущности
 		bigChunk.mu.RLock()
-		entities := make(map[uint64]interface{})
-		for id, entity := range bigChunk.entities {
-			entities[id] = entity
-		}
+		entities := bigChunk.entitySnapshotsLocked()
 		bigChunk.mu.RUnlock()
 
 		wm.SaveEntities(coords, entities)
@@ -371,13 +484,9 @@ func (wm *WorldManager) SaveWorld(force bool) {
 	log.Printf("Сохранение мира завершено")
 }
 
-// GetBlock возвращает блок по глобальным координатам
-func (wm *WorldManager) GetBlock(pos vec.Vec2) Block {
-	return wm.GetBlockLayer(pos, LayerActive)
-}
-
-// GetBlockLayer возвращает блок на указанном слое.
-func (wm *WorldManager) GetBlockLayer(pos vec.Vec2, layer BlockLayer) Block {
+// chunkForBlock возвращает чанк, содержащий блок, создавая BigChunk и
+// генерируя чанк при необходимости
+func (wm *WorldManager) chunkForBlock(pos vec.Vec2) *Chunk {
 	bigChunkCoords := pos.ToBigChunkCoords()
 
 	wm.mu.RLock()
@@ -394,21 +503,35 @@ func (wm *WorldManager) GetBlockLayer(pos vec.Vec2, layer BlockLayer) Block {
 	}
 
 	chunkCoords := pos.ToChunkCoords()
-	localPos := pos.LocalInChunk()
 
 	bigChunk.mu.RLock()
 	chunk, exists := bigChunk.chunks[chunkCoords]
 	bigChunk.mu.RUnlock()
 
 	if !exists {
-		chunk = wm.generateChunk(chunkCoords)
+		generated := wm.generateChunk(chunkCoords)
 		bigChunk.mu.Lock()
-		if _, exists := bigChunk.chunks[chunkCoords]; !exists {
+		// Чанк мог сгенерировать параллельный вызов - используем сохранённый
+		if chunk, exists = bigChunk.chunks[chunkCoords]; !exists {
+			chunk = generated
 			bigChunk.chunks[chunkCoords] = chunk
 		}
 		bigChunk.mu.Unlock()
 	}
 
+	return chunk
+}
+
+// GetBlock возвращает блок по глобальным координатам
+func (wm *WorldManager) GetBlock(pos vec.Vec2) Block {
+	return wm.GetBlockLayer(pos, LayerActive)
+}
+
+// GetBlockLayer возвращает блок на указанном слое.
+func (wm *WorldManager) GetBlockLayer(pos vec.Vec2, layer BlockLayer) Block {
+	chunk := wm.chunkForBlock(pos)
+	localPos := pos.LocalInChunk()
+
 	chunk.Mu.RLock()
 	blockID := chunk.GetBlockLayer(layer, localPos)
 	var metadata map[string]interface{}
@@ -426,67 +549,39 @@ func (wm *WorldManager) SetBlock(pos vec.Vec2, block Block) {
 
 // SetBlockLayer устанавливает блок на указанном слое (пока без событий).
 func (wm *WorldManager) SetBlockLayer(pos vec.Vec2, layer BlockLayer, block Block) {
-	bigChunkCoords := pos.ToBigChunkCoords()
-
-	wm.mu.RLock()
-	bigChunk, exists := wm.bigChunks[bigChunkCoords]
-	wm.mu.RUnlock()
-
-	if !exists {
-		wm.mu.Lock()
-		bigChunk, exists = wm.bigChunks[bigChunkCoords]
-		if !exists {
-			bigChunk = wm.createBigChunk(bigChunkCoords)
-		}
-		wm.mu.Unlock()
-	}
-
-	chunkCoords := pos.ToChunkCoords()
+	chunk := wm.chunkForBlock(pos)
 	localPos := pos.LocalInChunk()
 
-	bigChunk.mu.RLock()
-	chunk, exists := bigChunk.chunks[chunkCoords]
-	bigChunk.mu.RUnlock()
-
-	if !exists {
-		chunk = wm.generateChunk(chunkCoords)
-		bigChunk.mu.Lock()
-		if _, exists := bigChunk.chunks[chunkCoords]; !exists {
-			bigChunk.chunks[chunkCoords] = chunk
-		}
-		bigChunk.mu.Unlock()
-	}
-
 	chunk.SetBlockLayer(layer, localPos, block.ID)
 
-	// Сохраняем метаданные поключно
+	// Сохраняем метаданные поключно, в порядке ключей
 	if block.Payload != nil {
-		for key, value := range block.Payload {
-			chunk.SetBlockMetadataLayer(layer, localPos, key, value)
-		}
+		chunk.SetBlockMetadataLayerMap(layer, localPos, block.Payload)
 	}
+
+	wm.publishBlockWrite(pos, layer, block)
 }
 
 // HandleEntityEvent обрабатывает глобальное событие сущности
 func (wm *WorldManager) HandleEntityEvent(event EntityEvent) {
-	// Отправляем событие в globalEvents для обработки
-	select {
-	case wm.globalEvents <- event:
-		// Успешно отправлено
-	default:
-		// Канал переполнен, можно добавить логирование
-	}
+	// Отправляем событие в globalEvents для обработки (при переполнении учитывается как отброшенное)
+	wm.deliverEvent(wm.globalEvents, event, eventChannelGlobal)
 }
 
-// ProcessEntityMovement обрабатывает перемещение сущности между BigChunk'ами
-func (wm *WorldManager) ProcessEntityMovement(entityID uint64, oldPos, newPos vec.Vec2) {
+// ProcessEntityMovement обрабатывает перемещение сущности между BigChunk'ами.
+// Возвращает false, если новый BigChunk заполнен до предела: сущность остаётся
+// в прежнем BigChunk, а вызывающий должен отменить перемещение
+func (wm *WorldManager) ProcessEntityMovement(entityID uint64, entityType entitypkg.EntityType, oldPos, newPos vec.Vec2) bool {
 	// Получаем координаты BigChunk для старой и новой позиции
 	oldBCCoords := oldPos.ToBigChunkCoords()
 	newBCCoords := newPos.ToBigChunkCoords()
 
 	// Если BigChunk не изменился, ничего не делаем
 	if oldBCCoords == newBCCoords {
-		return
+		return true
+	}
+	if !wm.CanEnterBigChunk(entityType, oldPos, newPos) {
+		return false
 	}
 
 	// Отправляем событие выхода из старого BigChunk
@@ -505,18 +600,36 @@ func (wm *WorldManager) ProcessEntityMovement(entityID uint64, oldPos, newPos ve
 		Position:    newPos,
 		SourceChunk: newBCCoords,
 		TargetChunk: newBCCoords,
+		Data:        map[string]interface{}{"type": uint16(entityType)},
 	}
 
 	// Маршрутизируем события
 	wm.routeEntityEvent(exitEvent)
 	wm.routeEntityEvent(enterEvent)
+	return true
 }
 
-// SpawnEntity создает новую сущность в мире
+// SpawnEntity создает новую сущность в мире. Спавн учитывается ограничением
+// частоты мировых спавнов подключённого менеджера сущностей (SetEntitySource);
+// 0 - спавн отклонён
 func (wm *WorldManager) SpawnEntity(entityType uint16, position vec.Vec2, entityData interface{}) uint64 {
+	if em := wm.entities.Load(); em != nil && !em.AdmitWorldSpawn() {
+		return 0
+	}
+
 	// Генерируем новый ID для сущности
 	entityID := wm.GenerateEntityID()
 
+	// Тип передаётся в данных события: без него BigChunk не отличит сущность от игрока
+	switch data := entityData.(type) {
+	case nil:
+		entityData = map[string]interface{}{"type": entityType}
+	case map[string]interface{}:
+		if _, typed := data["type"]; !typed {
+			data["type"] = entityType
+		}
+	}
+
 	// Создаем событие создания сущности
 	spawnEvent := EntityEvent{
 		EventType:   EventTypeEntitySpawn,
@@ -554,6 +667,10 @@ func (wm *WorldManager) Stop() {
 
 	// Отменяем контекст, что приведет к остановке всех BigChunk
 	wm.cancelFunc()
+	worldEntityCollector.remove(wm)
+	if wm.bigChunkPool != nil {
+		wm.bigChunkPool.stop()
+	}
 }
 
 // generateChunk генерирует новый чанк с указанными координатами
@@ -564,11 +681,22 @@ func (wm *WorldManager) generateChunk(coords vec.Vec2) *Chunk {
 
 // GetChunk возвращает чанк по координатам
 func (wm *WorldManager) GetChunk(coords vec.Vec2) *Chunk {
-	// Получаем координаты BigChunk, в котором находится чанк
-	bigChunkCoords := vec.Vec2{
+	chunk, _ := wm.loadChunk(coords)
+	return chunk
+}
+
+// chunkBigChunkCoords возвращает координаты BigChunk, в котором находится чанк
+func chunkBigChunkCoords(coords vec.Vec2) vec.Vec2 {
+	return vec.Vec2{
 		X: (coords.X >> 4) * 4, // Преобразуем координаты чанка в координаты BigChunk
 		Y: (coords.Y >> 4) * 4,
 	}
+}
+
+// loadChunk возвращает чанк, при необходимости генерируя его. Второе значение
+// сообщает, был ли чанк сгенерирован этим вызовом
+func (wm *WorldManager) loadChunk(coords vec.Vec2) (*Chunk, bool) {
+	bigChunkCoords := chunkBigChunkCoords(coords)
 
 	wm.mu.RLock()
 	bigChunk, exists := wm.bigChunks[bigChunkCoords]
@@ -590,20 +718,22 @@ func (wm *WorldManager) GetChunk(coords vec.Vec2) *Chunk {
 	chunk, exists := bigChunk.chunks[coords]
 	bigChunk.mu.RUnlock()
 
-	if !exists {
-		// Если чанк не существует, генерируем его
-		chunk = wm.generateChunk(coords)
-
-		bigChunk.mu.Lock()
-		// Проверяем еще раз под блокировкой записи
-		_, exists := bigChunk.chunks[coords]
-		if !exists {
-			bigChunk.chunks[coords] = chunk
-		}
-		bigChunk.mu.Unlock()
+	if exists {
+		return chunk, false
 	}
 
-	return chunk
+	// Если чанк не существует, генерируем его вне блокировки
+	generated := wm.generateChunk(coords)
+
+	bigChunk.mu.Lock()
+	defer bigChunk.mu.Unlock()
+	// Проверяем еще раз под блокировкой записи: чанк мог сгенерировать
+	// параллельный вызов, и тогда возвращаем уже сохранённый экземпляр
+	if chunk, exists := bigChunk.chunks[coords]; exists {
+		return chunk, false
+	}
+	bigChunk.chunks[coords] = generated
+	return generated, true
 }
 
 // SetNetworkManager устанавливает сетевой менеджер для отправки обновлений клиентам
@@ -684,12 +814,7 @@ func (wm *WorldManager) BatchUpdate(updates map[vec.Vec2]Block) error {
 			}
 
 			// Отправляем событие в BigChunk
-			select {
-			case bigChunk.eventsIn <- blockEvent:
-				// Успешно отправлено
-			default:
-				log.Printf("Канал событий BigChunk %v переполнен, пропускаем обновление блока %v", bigChunkCoords, pos)
-			}
+			bigChunk.sendToSelf(blockEvent)
 		}
 	}
 
@@ -742,6 +867,9 @@ func (wm *WorldManager) SetBlockMetadataValue(pos vec.Vec2, key string, value in
 
 	// Устанавливаем метаданные напрямую в чанке
 	chunk.SetBlockMetadataLayer(LayerActive, localPos, key, value)
+
+	b := wm.GetBlockLayer(pos, LayerActive)
+	wm.publishBlockWrite(pos, LayerActive, b)
 }
 
 // RemoveBlock удаляет блок, заменяя его на воздух
@@ -777,18 +905,12 @@ func (wm *WorldManager) IsBlockLoaded(pos vec.Vec2) bool {
 // InitStorageAdapter инициализирует StorageAdapter
 func (wm *WorldManager) InitStorageAdapter(storageProvider storage_interface.StorageProvider) error {
 	// Устанавливаем функции для работы с хранилищем
-	wm.SetStorageFunctions(
-		storageProvider.SaveEntities,
-		func(coords vec.Vec2) (interface{}, error) {
-			return storageProvider.LoadEntities(coords)
-		},
-		func(entities map[uint64]interface{}, data interface{}) {
-			if entitiesData, ok := data.(*storage_interface.EntitiesData); ok {
-				storageProvider.ApplyEntitiesToBigChunk(entities, entitiesData)
-			}
-		},
-	)
+	wm.SetStorageFunctions(storageProvider.SaveEntities, storageProvider.LoadEntities)
 
+	// Метаданные мира (точка спавна), если провайдер их хранит
+	if metaStore, ok := storageProvider.(storage_interface.WorldMetaStore); ok {
+		return wm.SetWorldMetaStore(metaStore)
+	}
 	return nil
 }
 
@@ -799,7 +921,7 @@ func (wm *WorldManager) LoadBigChunkFromStorage(coords vec.Vec2) error {
 	}
 
 	// Загружаем данные сущностей
-	data, err := wm.loadEntitiesFunc(coords)
+	snapshots, err := wm.loadEntitiesFunc(coords)
 	if err != nil {
 		log.Printf("Ошибка загрузки сущностей для BigChunk %v: %v", coords, err)
 		return err
@@ -820,11 +942,9 @@ func (wm *WorldManager) LoadBigChunkFromStorage(coords vec.Vec2) error {
 	}
 
 	// Применяем загруженные данные
-	if wm.applyEntitiesFunc != nil && data != nil {
-		bigChunk.mu.Lock()
-		wm.applyEntitiesFunc(bigChunk.entities, data)
-		bigChunk.mu.Unlock()
-	}
+	bigChunk.mu.Lock()
+	bigChunk.applyEntitySnapshotsLocked(snapshots)
+	bigChunk.mu.Unlock()
 
 	return nil
 }
@@ -5,6 +5,7 @@ import (
 
 	"github.com/annel0/mmo-game/internal/vec"
 	"github.com/annel0/mmo-game/internal/world/block"
+	entitypkg "github.com/annel0/mmo-game/internal/world/entity"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -16,7 +17,7 @@ func TestWorldManager_Creation(t *testing.T) {
 	assert.Equal(t, int64(12345), wm.seed, "Seed должен быть установлен правильно")
 	assert.NotNil(t, wm.bigChunks, "Карта BigChunk должна быть инициализирована")
 	assert.NotNil(t, wm.globalEvents, "Канал событий должен быть инициализирован")
-	assert.Equal(t, uint64(1000), wm.nextEntityID, "Начальный ID сущности должен быть 1000")
+	assert.Equal(t, uint16(0), wm.EntityIDAllocator().RegionID(), "По умолчанию используется регион 0")
 }
 
 func TestWorldManager_BlockOperations(t *testing.T) {
@@ -202,8 +203,10 @@ func TestWorldManager_GenerateEntityID(t *testing.T) {
 	assert.Equal(t, id1+1, id2, "ID сущностей должны быть последовательными")
 	assert.Equal(t, id2+1, id3, "ID сущностей должны быть последовательными")
 
-	// Проверяем, что все ID больше начального значения
-	assert.Greater(t, id1, uint64(1000), "ID сущности должен быть больше начального значения")
+	// Проверяем, что ID выдаются аллокатором мира
+	allocator := entitypkg.NewEntityIDAllocator(5)
+	wm.SetEntityIDAllocator(allocator)
+	assert.Equal(t, uint16(5), entitypkg.RegionOfEntityID(wm.GenerateEntityID()), "ID должен содержать номер региона")
 }
 
 // Benchmarks
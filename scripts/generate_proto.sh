@@ -12,9 +12,12 @@ PROTO_DIR="internal/protocol/proto"
 echo "Compiling proto files..."
 protoc \
   --plugin=protoc-gen-go=/home/${USER}/go/bin/protoc-gen-go \
+  --plugin=protoc-gen-go-grpc=/home/${USER}/go/bin/protoc-gen-go-grpc \
   --proto_path=internal/protocol/proto \
   --go_out=internal/protocol \
   --go_opt=paths=source_relative \
+  --go-grpc_out=internal/protocol \
+  --go-grpc_opt=paths=source_relative \
   internal/protocol/proto/*.proto
 
 